@@ -4,11 +4,15 @@ import { API_CONFIG, BACKEND_ENDPOINTS } from "@/app/api/config"
 export async function POST(request: NextRequest): Promise<NextResponse> {
   try {
     const body = await request.json()
+    const authHeader = request.headers.get("Authorization") || ""
 
     // Proxy to Go backend
     const response = await fetch(`${API_CONFIG.baseURL}${BACKEND_ENDPOINTS.createRedPocket}`, {
       method: "POST",
-      headers: API_CONFIG.headers,
+      headers: {
+        ...API_CONFIG.headers,
+        Authorization: authHeader,
+      },
       body: JSON.stringify(body),
     })
 
@@ -0,0 +1,97 @@
+// Command replay-events replays a time range of the event log into a staging
+// database, to reproduce an incident such as claims failing for twenty
+// minutes. Every pocket, claim, payout job and refund the range touches is
+// first restored to its state at -from, then each recorded change is applied
+// in order up to -to. A staging server started on the target afterwards
+// picks up where production stood at -to, so its payout, receipt and refund
+// jobs show how the incident is recovered from.
+//
+// Events are read from DATABASE_URL (a read replica will do) and written to
+// -target, whose role must be allowed to set session_replication_role.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"github.com/protocolbank/redpocket-backend/internal/secrets"
+)
+
+func main() {
+	target := flag.String("target", "", "connection string of the staging database to replay into")
+	from := flag.String("from", "", "start of the range, RFC 3339")
+	to := flag.String("to", "", "end of the range, RFC 3339")
+	batch := flag.Int("batch", 500, "events per transaction")
+	dryRun := flag.Bool("dry-run", false, "count the events in the range without replaying them")
+	flag.Parse()
+
+	start, err := time.Parse(time.RFC3339, *from)
+	if err != nil {
+		fatal("invalid -from", "error", err)
+	}
+	end, err := time.Parse(time.RFC3339, *to)
+	if err != nil {
+		fatal("invalid -to", "error", err)
+	}
+	if !start.Before(end) {
+		fatal("-from must be before -to")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		slog.Info("no .env file found, using environment variables")
+	}
+	cfg := config.Load()
+	secretsMgr, err := secrets.New(cfg)
+	if err != nil {
+		fatal("invalid secrets configuration", "error", err)
+	}
+	if err := secretsMgr.Load(context.Background(), cfg); err != nil {
+		fatal("failed to load secrets", "error", err)
+	}
+
+	db, err := repository.NewPostgresDB(cfg.DatabaseURL)
+	if err != nil {
+		fatal("failed to connect to database", "error", err)
+	}
+	defer db.Close()
+	eventLog := repository.NewEventLogRepository(db)
+
+	if *dryRun {
+		n, err := eventLog.Count(context.Background(), start, end)
+		if err != nil {
+			fatal("failed to count events", "error", err)
+		}
+		slog.Info("events in range", "events", n, "from", start.Format(time.RFC3339), "to", end.Format(time.RFC3339))
+		return
+	}
+
+	if *target == "" {
+		fatal("-target must be set to replay")
+	}
+	if *target == cfg.DatabaseURL {
+		fatal("-target is DATABASE_URL; replay into a staging database")
+	}
+	staging, err := repository.NewPostgresDB(*target)
+	if err != nil {
+		fatal("failed to connect to target database", "error", err)
+	}
+	defer staging.Close()
+
+	restored, replayed, err := eventLog.Replay(context.Background(), staging, start, end, *batch)
+	if err != nil {
+		fatal("replay stopped", "restored", restored, "replayed", replayed, "error", err)
+	}
+	slog.Info("replay complete", "restored", restored, "restored_to", start.Format(time.RFC3339), "replayed", replayed)
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,64 @@
+// Command rotate-keys re-seals wallet private keys with the active encryption
+// provider and key. Run it after changing WALLET_KEY_PROVIDER or
+// WALLET_ENCRYPTION_KEY (keeping the previous key in WALLET_ENCRYPTION_OLD_KEYS
+// and the previous provider's credentials configured until it finishes).
+// Plaintext keys stored before encryption was enabled are sealed as well.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/encryption"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"github.com/protocolbank/redpocket-backend/internal/secrets"
+)
+
+func main() {
+	all := flag.Bool("all", false, "re-seal every key, even those already sealed with the active key")
+	batch := flag.Int("batch", 100, "wallets per page")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		slog.Info("no .env file found, using environment variables")
+	}
+	cfg := config.Load()
+	secretsMgr, err := secrets.New(cfg)
+	if err != nil {
+		fatal("invalid secrets configuration", "error", err)
+	}
+	if err := secretsMgr.Load(context.Background(), cfg); err != nil {
+		fatal("failed to load secrets", "error", err)
+	}
+	if cfg.WalletKeyProvider == "" {
+		fatal("WALLET_KEY_PROVIDER must be set to rotate keys")
+	}
+
+	enc, err := encryption.New(cfg)
+	if err != nil {
+		fatal("failed to initialize wallet key encryption", "error", err)
+	}
+
+	db, err := repository.NewPostgresDB(cfg.DatabaseURL)
+	if err != nil {
+		fatal("failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	walletRepo := repository.NewWalletRepository(db, enc)
+	checked, rotated, err := walletRepo.RotateKeys(context.Background(), *batch, *all)
+	if err != nil {
+		fatal("key rotation stopped", "checked", checked, "rotated", rotated, "error", err)
+	}
+	slog.Info("key rotation complete", "checked", checked, "rotated", rotated)
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
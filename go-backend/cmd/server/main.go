@@ -2,7 +2,8 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,60 +14,328 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/protocolbank/redpocket-backend/internal/bot"
 	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/encryption"
+	"github.com/protocolbank/redpocket-backend/internal/grpcapi"
 	"github.com/protocolbank/redpocket-backend/internal/handler"
+	"github.com/protocolbank/redpocket-backend/internal/logging"
+	"github.com/protocolbank/redpocket-backend/internal/metrics"
 	"github.com/protocolbank/redpocket-backend/internal/middleware"
+	"github.com/protocolbank/redpocket-backend/internal/mtls"
 	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"github.com/protocolbank/redpocket-backend/internal/secrets"
 	"github.com/protocolbank/redpocket-backend/internal/service"
+	"github.com/protocolbank/redpocket-backend/internal/tracing"
 )
 
 func main() {
 	// Load .env
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+		slog.Info("no .env file found, using environment variables")
 	}
 
 	// Load config
 	cfg := config.Load()
+	logging.Setup(cfg)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("invalid tracing configuration", "error", err)
+	}
+	// Production pockets are paid out of their sender's deposit; test-mode
+	// and ledger-funded pockets skip it whatever the setting
+	if cfg.Env == "production" && !cfg.PocketDepositRequired {
+		fatal("POCKET_DEPOSIT_REQUIRED cannot be turned off in production")
+	}
+
+	// Replace secrets from the secrets manager, if one is configured
+	secretsMgr, err := secrets.New(cfg)
+	if err != nil {
+		fatal("invalid secrets configuration", "error", err)
+	}
+	if err := secretsMgr.Load(context.Background(), cfg); err != nil {
+		fatal("failed to load secrets", "error", err)
+	}
+	jwtSecret := secrets.NewValue(cfg.JWTSecret)
+	smtpPassword := secrets.NewValue(cfg.SMTPPassword)
+
+	// `server migrate ...` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
+	if cfg.MigrateOnStart {
+		if err := migrateUp(cfg); err != nil {
+			fatal("failed to migrate database", "error", err)
+		}
+	}
 
 	// Initialize database
-	db, err := repository.NewPostgresDB(cfg.DatabaseURL)
+	db, err := repository.OpenPostgresDB(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("invalid database URL", "error", err)
 	}
 	defer db.Close()
+	metrics.RegisterDBPool(db.Pool)
 
 	// Initialize Redis
-	rdb, err := repository.NewRedisClient(cfg.RedisURL)
+	rdb, err := repository.OpenRedisClient(cfg.RedisURL)
 	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+		fatal("invalid Redis URL", "error", err)
 	}
 	defer rdb.Close()
+	rdb.Client.AddHook(service.NewRedisBreaker(cfg))
+
+	// Wait for both, then start read-only if they are not up in time, or on
+	// fallbacks if only Redis is down and REDIS_FALLBACK is on
+	readiness := service.NewReadiness(cfg)
+	readiness.Check("database", db.Ping)
+	if cfg.RedisFallback {
+		readiness.Optional("redis", rdb.Ping)
+	} else {
+		readiness.Check("redis", rdb.Ping)
+	}
+	if !readiness.Preflight(context.Background()) {
+		if readiness.Ready() {
+			slog.Warn("starting without optional dependencies: claims lock in Postgres until Redis answers", "down", readiness.Down())
+		} else {
+			slog.Warn("starting degraded: writes are refused until the database and Redis answer", "down", readiness.Down())
+		}
+	}
+
+	// Initialize wallet key encryption
+	enc, err := encryption.New(cfg)
+	if err != nil {
+		fatal("failed to initialize wallet key encryption", "error", err)
+	}
 
 	// Initialize repositories
 	redPocketRepo := repository.NewRedPocketRepository(db)
-	walletRepo := repository.NewWalletRepository(db)
+	walletRepo := repository.NewWalletRepository(db, enc)
 	claimRepo := repository.NewClaimRepository(db)
 	campaignRepo := repository.NewCampaignRepository(db)
+	audienceRepo := repository.NewAudienceRepository(db)
+	eligibilityRepo := repository.NewEligibilityRepository(db)
+	summaryRepo := repository.NewSummaryRepository(db)
+	ipfsPinRepo := repository.NewIPFSPinRepository(db)
+	receiptRepo := repository.NewReceiptRepository(db)
+	userOpRepo := repository.NewUserOpRepository(db)
+	payoutJobRepo := repository.NewPayoutJobRepository(db)
+	payoutBatchRepo := repository.NewPayoutBatchRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	bridgeRepo := repository.NewBridgeTransferRepository(db)
+	sanctionsRepo := repository.NewSanctionsRepository(db)
+	discoveryRepo := repository.NewDiscoveryRepository(db)
+	payoutPrefRepo := repository.NewPayoutPreferenceRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	savingsRepo := repository.NewSavingsRepository(db)
+	fraudRepo := repository.NewFraudRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	fundingRepo := repository.NewFundingRepository(db)
+	pocketDepositRepo := repository.NewPocketDepositRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db, enc)
+	enterpriseRepo := repository.NewEnterpriseRepository(db)
+	slackRepo := repository.NewSlackInstallationRepository(db, enc)
+	jwtKeyRepo := repository.NewJWTKeyRepository(db, enc)
+	claimFailureRepo := repository.NewClaimFailureRepository(db)
+	partnerRepo := repository.NewPartnerRepository(db)
+	announcementRepo := repository.NewPocketAnnouncementRepository(db)
+	huntRepo := repository.NewTreasureHuntRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	campaignRuleRepo := repository.NewCampaignRuleRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	luckyDrawRepo := repository.NewLuckyDrawRepository(db)
+	eventLogRepo := repository.NewEventLogRepository(db)
+	digestRepo := repository.NewDigestRepository(db)
+	weeklyReportRepo := repository.NewWeeklyReportRepository(db)
 
 	// Initialize services
-	walletSvc := service.NewWalletService(walletRepo, cfg)
-	redPocketSvc := service.NewRedPocketService(redPocketRepo, claimRepo, walletSvc, rdb, cfg)
-	campaignSvc := service.NewCampaignService(campaignRepo, claimRepo, cfg)
 	xcmBridge := service.NewXCMBridge(cfg)
-	hyperbridgeSvc := service.NewHyperbridgeService(xcmBridge)
+	hyperbridgeSvc := service.NewHyperbridgeService(xcmBridge, bridgeRepo, service.NewBridgeEvents(rdb), cfg)
+	walletSvc := service.NewWalletService(walletRepo, userOpRepo, cfg)
+	hyperbridgeSvc.UseWallets(walletSvc)
+	walletOverviewSvc := service.NewWalletOverviewService(walletRepo, claimRepo, hyperbridgeSvc)
+	consolidationSvc := service.NewConsolidationService(walletRepo, hyperbridgeSvc)
+	tokenRegistry := service.NewTokenRegistry(tokenRepo, cfg)
+	notifier := service.NewNotifier(cfg)
+	sanctionsScreener := service.NewSanctionsScreener(sanctionsRepo, notifier, cfg)
+	audienceSvc := service.NewAudienceService(audienceRepo, campaignRepo, xcmBridge)
+	eligibilitySvc := service.NewEligibilityService(eligibilityRepo, campaignRepo, claimRepo, walletSvc)
+	savingsSvc := service.NewSavingsService(savingsRepo, walletSvc, tokenRegistry, cfg)
+	webhookSvc := service.NewWebhookService(webhookRepo, claimRepo, redPocketRepo, cfg)
+	apiKeySvc := service.NewAPIKeyService(apiKeyRepo, cfg)
+	enterpriseKeys := service.NewEnterpriseKeys(enterpriseRepo, cfg)
+	partnerSvc := service.NewPartnerService(partnerRepo, enterpriseRepo)
+	reportSvc := service.NewReportService(reportRepo, campaignRepo, cfg)
+	sandboxSvc := service.NewSandboxService(enterpriseRepo, campaignRepo, cfg)
+	analyticsSvc := service.NewAnalyticsService(analyticsRepo, campaignRepo, cfg)
+	luckyDrawSvc := service.NewLuckyDrawService(luckyDrawRepo, redPocketRepo)
+	eventLog := service.NewEventLog(eventLogRepo, cfg)
+	jwtKeys := service.NewJWTKeys(jwtKeyRepo, cfg, jwtSecret.Get)
+	payoutQueue := service.NewPayoutQueue(payoutJobRepo, payoutBatchRepo, claimRepo, redPocketRepo, userOpRepo, walletSvc, savingsSvc, sanctionsScreener, webhookSvc, notifier, cfg)
+	payoutSplitSvc := service.NewPayoutSplitService(payoutPrefRepo, walletSvc, cfg)
+	pocketCache := service.NewPocketCache(redPocketRepo, rdb, cfg)
+	pocketEvents := service.NewPocketEvents(rdb, redPocketRepo, pocketCache, webhookSvc)
+	ledgerSvc := service.NewLedgerService(ledgerRepo, campaignRepo, walletSvc, sanctionsScreener, tokenRegistry, cfg)
+	offRampSvc := service.NewOffRampService(ledgerRepo, ledgerSvc, cfg)
+	fundingSvc := service.NewFundingService(fundingRepo, campaignRepo, walletSvc, tokenRegistry, cfg)
+	pocketFunding := service.NewPocketFunding(pocketDepositRepo, redPocketRepo, walletSvc, ledgerSvc, pocketEvents, cfg)
+	refundSvc := service.NewRefundService(refundRepo, redPocketRepo, campaignRepo, walletSvc, pocketEvents, cfg)
+	captchaVerifier := service.NewCaptchaVerifier(cfg)
+	claimFailures := service.NewClaimFailureLog(claimFailureRepo, cfg)
+	fraudSvc := service.NewFraudService(fraudRepo, redPocketRepo, claimRepo, payoutQueue, pocketCache, captchaVerifier, cfg)
+	claimLinks := service.NewClaimLinks(redPocketRepo, campaignRepo, claimRepo, cfg)
+	claimLimiter := service.NewClaimRateLimiter(rdb, cfg)
+	redPocketSvc := service.NewRedPocketService(redPocketRepo, claimRepo, campaignRepo, walletSvc, audienceSvc, eligibilitySvc, fraudSvc, payoutQueue, payoutSplitSvc, savingsSvc, tokenRegistry, ledgerSvc, pocketFunding, pocketEvents, pocketCache, captchaVerifier, claimFailures, claimLinks, claimLimiter, rdb, cfg)
+	huntSvc := service.NewTreasureHuntService(huntRepo, redPocketRepo, campaignRepo, claimLinks)
+	redPocketSvc.UseHunts(huntSvc)
+	teamSvc := service.NewTeamService(teamRepo, campaignRepo)
+	redPocketSvc.UseTeams(teamSvc)
+	campaignSvc := service.NewCampaignService(campaignRepo, claimRepo, cfg)
+	claimHistorySvc := service.NewClaimHistoryService(claimRepo, pocketCache, rdb, cfg)
+	ipfsSvc := service.NewIPFSService(ipfsPinRepo, cfg)
+	receiptTracker := service.NewReceiptTracker(receiptRepo, claimRepo, walletSvc, savingsSvc, xcmBridge, webhookSvc, cfg)
+	claimDiagnostics := service.NewClaimDiagnostics(payoutQueue, receiptTracker, claimFailures)
+	userOpMonitor := service.NewUserOpMonitor(userOpRepo, claimRepo, payoutBatchRepo, walletSvc, webhookSvc, cfg)
+	paymasterMonitor := service.NewPaymasterMonitor(walletSvc, notifier, cfg)
+	summarySvc := service.NewSummaryService(redPocketRepo, claimRepo, summaryRepo, xcmBridge, ipfsSvc, receiptTracker, pocketEvents)
+	discoverySvc := service.NewDiscoveryService(discoveryRepo, redPocketRepo)
 
 	// Initialize handlers
-	redPocketHandler := handler.NewRedPocketHandler(redPocketSvc)
-	walletHandler := handler.NewWalletHandler(walletSvc)
+	redPocketHandler := handler.NewRedPocketHandler(redPocketSvc, cfg.GeoCountryHeader)
+	refundHandler := handler.NewRefundHandler(refundSvc)
+	streamHandler := handler.NewStreamHandler(redPocketSvc, pocketEvents)
+	walletHandler := handler.NewWalletHandler(walletSvc, ledgerSvc, payoutSplitSvc, walletOverviewSvc, consolidationSvc)
 	campaignHandler := handler.NewCampaignHandler(campaignSvc)
 	xcmHandler := handler.NewXCMHandler(xcmBridge)
 	hyperbridgeHandler := handler.NewHyperbridgeHandler(hyperbridgeSvc)
-	healthHandler := handler.NewHealthHandler(db, rdb)
+	audienceHandler := handler.NewAudienceHandler(audienceSvc)
+	eligibilityHandler := handler.NewEligibilityHandler(eligibilitySvc)
+	fraudHandler := handler.NewFraudHandler(fraudSvc)
+	summaryHandler := handler.NewSummaryHandler(summarySvc)
+	mediaHandler := handler.NewMediaHandler(ipfsSvc)
+	receiptHandler := handler.NewReceiptHandler(receiptTracker)
+	payoutHandler := handler.NewPayoutHandler(payoutQueue, claimDiagnostics)
+	cluster := service.NewCluster(rdb, cfg)
+	healthHandler := handler.NewHealthHandler(db, rdb, cluster, readiness)
+	discoveryHandler := handler.NewDiscoveryHandler(discoverySvc)
+	tokenHandler := handler.NewTokenHandler(tokenRegistry)
+	savingsHandler := handler.NewSavingsHandler(savingsSvc)
+	offRampHandler := handler.NewOffRampHandler(offRampSvc)
+	webhookHandler := handler.NewWebhookHandler(webhookSvc)
+	fundingHandler := handler.NewFundingHandler(fundingSvc)
+	pocketFundingHandler := handler.NewPocketFundingHandler(pocketFunding)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeySvc)
+	enterpriseKeyHandler := handler.NewEnterpriseKeyHandler(enterpriseKeys)
+	authHandler := handler.NewAuthHandler(jwtKeys)
+	supportHandler := handler.NewSupportHandler(claimFailures)
+	claimLinkHandler := handler.NewClaimLinkHandler(claimLinks)
+	partnerHandler := handler.NewPartnerHandler(partnerSvc)
+	claimHistoryHandler := handler.NewClaimHistoryHandler(claimHistorySvc)
+	reportHandler := handler.NewReportHandler(reportSvc)
+	sandboxHandler := handler.NewSandboxHandler(sandboxSvc)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsSvc)
+	luckyDrawHandler := handler.NewLuckyDrawHandler(luckyDrawSvc)
+	eventLogHandler := handler.NewEventLogHandler(eventLog)
+	huntHandler := handler.NewTreasureHuntHandler(huntSvc)
+	teamHandler := handler.NewTeamHandler(teamSvc)
 
 	// Initialize bots
-	telegramBot := bot.NewTelegramBot(cfg)
-	discordBot := bot.NewDiscordBot(cfg)
-	botHandler := handler.NewBotHandler(telegramBot, discordBot)
+	telegramBot := bot.NewTelegramBot(cfg, rdb)
+	telegramBot.UseClaimer(redPocketSvc)
+	telegramBot.UseAnnouncements(announcementRepo)
+	telegramBot.UseHunts(huntSvc)
+	redPocketSvc.UseAnnouncer(telegramBot)
+	discordBot := bot.NewDiscordBot(cfg, rdb)
+	redPocketSvc.UseMemberRoles(discordBot)
+	slackBot := bot.NewSlackBot(cfg, rdb, slackRepo)
+	slackBot.UseClaimer(redPocketSvc)
+	claimerSessionHandler := handler.NewClaimerSessionHandler(service.NewClaimerSessions(rdb, jwtKeys, telegramBot, discordBot, cfg))
+
+	// Pick up rotated secrets without a restart
+	secretsMgr.OnRotate("JWT_SECRET", jwtSecret.Set)
+	secretsMgr.OnRotate("SMTP_PASSWORD", smtpPassword.Set)
+	secretsMgr.OnRotate("TELEGRAM_BOT_TOKEN", telegramBot.SetToken)
+	secretsMgr.OnRotate("DISCORD_BOT_TOKEN", discordBot.SetToken)
+	secretsMgr.OnRotate("DATABASE_URL", func(url string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := db.Redial(ctx, url); err != nil {
+			slog.ErrorContext(ctx, "secrets: failed to redial the database", "error", err)
+			return
+		}
+		slog.InfoContext(ctx, "secrets: database connections now use the rotated credentials")
+	})
+	secretsMgr.OnRotate("WALLET_ENCRYPTION_KEY", func(key string) {
+		if err := enc.RotateAESKey(key); err != nil {
+			slog.Error("secrets: failed to rotate wallet encryption key", "error", err)
+		}
+	})
+	botHandler := handler.NewBotHandler(telegramBot, discordBot, slackBot, slackRepo)
+	pocketScheduler := service.NewPocketScheduler(redPocketRepo, campaignRepo, pocketFunding, pocketEvents, telegramBot, discordBot, slackBot, claimLinks, cfg)
+	scheduleHandler := handler.NewScheduleHandler(pocketScheduler)
+	ruleEngine := service.NewRuleEngine(campaignRuleRepo, campaignRepo, redPocketRepo, redPocketSvc, pocketScheduler, webhookSvc, cfg)
+	redPocketSvc.UseRules(ruleEngine)
+	campaignRuleHandler := handler.NewCampaignRuleHandler(ruleEngine)
+	expiryReminders := service.NewExpiryReminders(redPocketRepo, webhookSvc, telegramBot, discordBot, slackBot, claimLinks, cfg)
+	digestSvc := service.NewDigestService(digestRepo, telegramBot, discordBot, slackBot)
+	digestHandler := handler.NewDigestHandler(digestSvc)
+	weeklyReportSvc := service.NewWeeklyReportService(weeklyReportRepo, analyticsRepo, enterpriseRepo, service.NewMailer(cfg, smtpPassword.Get), cfg)
+	weeklyReportHandler := handler.NewWeeklyReportHandler(weeklyReportSvc)
+
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	payoutsDone := make(chan struct{})
+	cluster.Go(jobsCtx, "secrets", service.ReplicaLocal, "each replica holds its own copies", secretsMgr.Start)
+	cluster.Go(jobsCtx, "jwt-keys", service.ReplicaLocal, "each replica reloads the shared keys", jwtKeys.Start)
+	cluster.Go(jobsCtx, "jwt-key-rotation", service.ReplicaLeader, "concurrent rotations would retire each other's keys", jwtKeys.StartRotation)
+	// SIGHUP re-reads the secrets manager at once, e.g. from a rotation hook
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			secretsMgr.Refresh()
+		}
+	}()
+	cluster.Go(jobsCtx, "readiness", service.ReplicaLocal, "each replica checks its own connections", readiness.Start)
+	cluster.Go(jobsCtx, "claim-failures", service.ReplicaLocal, "each replica stores the failures it recorded", claimFailures.Start)
+	cluster.Go(jobsCtx, "payouts", service.ReplicaSafe, "jobs are leased with SKIP LOCKED", func(ctx context.Context) {
+		payoutQueue.Start(ctx)
+		close(payoutsDone)
+	})
+	if cfg.ChangeFeedEnabled {
+		changeFeed := service.NewChangeFeed(db)
+		pocketEvents.UseChangeFeed(changeFeed)
+		pocketCache.UseChangeFeed(changeFeed)
+		cluster.Go(jobsCtx, "change-feed", service.ReplicaLocal, "each replica feeds its own streams and cache", changeFeed.Start)
+	}
+	if cluster.Enabled() {
+		walletSvc.SharePaymasterState(rdb)
+	}
+	cluster.Go(jobsCtx, "pocket-cache", service.ReplicaLocal, "invalidated on every replica over Redis", pocketCache.Start)
+	cluster.Go(jobsCtx, "summaries", service.ReplicaLeader, "archiving would pin summaries twice", summarySvc.Start)
+	cluster.Go(jobsCtx, "withdrawals", service.ReplicaLeader, "", ledgerSvc.Start)
+	cluster.Go(jobsCtx, "offramp-expiry", service.ReplicaLeader, "", offRampSvc.Start)
+	cluster.Go(jobsCtx, "webhooks", service.ReplicaSafe, "deliveries are dequeued with SKIP LOCKED", webhookSvc.Start)
+	cluster.Go(jobsCtx, "funding", service.ReplicaLeader, "concurrent sweeps would swap a deposit twice", fundingSvc.Start)
+	cluster.Go(jobsCtx, "pocket-deposits", service.ReplicaLeader, "", pocketFunding.Start)
+	cluster.Go(jobsCtx, "refunds", service.ReplicaLeader, "", refundSvc.Start)
+	cluster.Go(jobsCtx, "receipts", service.ReplicaLeader, "", receiptTracker.Start)
+	cluster.Go(jobsCtx, "userop-monitor", service.ReplicaLeader, "concurrent monitors would bump fees twice", userOpMonitor.Start)
+	cluster.Go(jobsCtx, "paymaster-monitor", service.ReplicaLeader, "mode and sponsorship failures are shared over Redis", paymasterMonitor.Start)
+	cluster.Go(jobsCtx, "bridge", service.ReplicaLeader, "", hyperbridgeSvc.Start)
+	cluster.Go(jobsCtx, "sanctions-sync", service.ReplicaLeader, "", sanctionsScreener.Start)
+	cluster.Go(jobsCtx, "scheduler", service.ReplicaSafe, "pockets are opened with SKIP LOCKED", pocketScheduler.Start)
+	cluster.Go(jobsCtx, "expiry-reminders", service.ReplicaSafe, "each reminder is taken with a conditional insert", expiryReminders.Start)
+	cluster.Go(jobsCtx, "discovery", service.ReplicaLeader, "", discoverySvc.Start)
+	cluster.Go(jobsCtx, "report-exports", service.ReplicaSafe, "exports are dequeued with SKIP LOCKED", reportSvc.Start)
+	cluster.Go(jobsCtx, "analytics-rollup", service.ReplicaLeader, "", analyticsSvc.Start)
+	cluster.Go(jobsCtx, "event-log-purge", service.ReplicaLeader, "", eventLog.Start)
+	cluster.Go(jobsCtx, "digests", service.ReplicaSafe, "each day's digest is taken with a conditional update", digestSvc.Start)
+	cluster.Go(jobsCtx, "campaign-rules", service.ReplicaSafe, "each run is taken with a conditional update", ruleEngine.Start)
+	cluster.Go(jobsCtx, "weekly-reports", service.ReplicaSafe, "each week's report is taken with a conditional insert", weeklyReportSvc.Start)
+	cluster.Go(jobsCtx, "metrics-push", service.ReplicaLocal, "grouped by hostname", metrics.NewPusher(cfg).Start)
 
 	// Setup Gin
 	if cfg.Env == "production" {
@@ -74,52 +343,151 @@ func main() {
 	}
 
 	r := gin.New()
+	r.Use(middleware.Tracing(cfg.OTelServiceName))
+	r.Use(middleware.RequestID())
 	r.Use(gin.Recovery())
 	r.Use(middleware.Logger())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Locale())
 	r.Use(middleware.CORS())
+	r.Use(middleware.ReadOnlyWhenDegraded(readiness))
 	r.Use(middleware.RateLimit(rdb, cfg.RateLimitRPS))
 
 	// Routes
 	r.GET("/health", healthHandler.Health)
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+	if cfg.AdminPort == "" {
+		r.GET("/health/replicas", healthHandler.Replicas)
+		r.GET("/metrics", gin.WrapH(metrics.Handler(cfg.MetricsToken)))
+		if cfg.AdminToken != "" {
+			r.GET("/admin/claims/failures/:reference", middleware.AdminToken(cfg.AdminToken), supportHandler.ClaimFailure)
+			r.POST("/admin/partners", middleware.AdminToken(cfg.AdminToken), partnerHandler.Create)
+			r.POST("/admin/sandbox/enterprises", middleware.AdminToken(cfg.AdminToken), sandboxHandler.CreateEnterprise)
+			r.GET("/admin/events", middleware.AdminToken(cfg.AdminToken), eventLogHandler.List)
+		}
+	}
+
+	// Withdraw, transfer and bridge requests may be signed with an API key;
+	// signed requests are accepted once
+	signature := middleware.Signature(apiKeySvc)
+	replayProtection := middleware.ReplayProtection(service.NewReplayGuard(rdb, cfg))
+	// Routes that move a claimer's funds need their session token
+	claimerAuth := middleware.ClaimerAuth(jwtKeys)
 
 	api := r.Group("/api/v1")
 	{
-		// RedPocket routes (public)
+		// RedPocket routes (public; creating one needs the campaign's
+		// enterprise token or API key)
 		rp := api.Group("/redpocket")
 		{
-			rp.POST("/create", redPocketHandler.Create)
+			rp.POST("/create", middleware.APIKeyAuth(enterpriseKeys), middleware.Auth(jwtKeys, apiKeySvc), redPocketHandler.Create)
 			rp.POST("/claim", redPocketHandler.Claim)
 			rp.GET("/:id", redPocketHandler.Get)
+			rp.GET("/:id/summary", summaryHandler.Get)
+			rp.GET("/:id/history", redPocketHandler.History)
+			rp.GET("/:id/leaderboard", claimHistoryHandler.Leaderboard)
+			rp.GET("/:id/fairness", luckyDrawHandler.Proof)
+			rp.POST("/:id/refund", refundHandler.Refund)
+			rp.GET("/:id/funding-status", pocketFundingHandler.Status)
+			rp.GET("/:id/stream", streamHandler.Stream)
+			rp.POST("/:id/share", discoveryHandler.Share)
+
+			// For the pocket's enterprise: per-recipient one-time claim links,
+			// pausing claims, extending the expiry, changing the supply and
+			// cancelling with a refund
+			owned := rp.Group("/:id", middleware.APIKeyAuth(enterpriseKeys), middleware.Auth(jwtKeys, apiKeySvc))
+			owned.POST("/links", claimLinkHandler.Mint)
+			owned.POST("/pause", redPocketHandler.Pause)
+			owned.POST("/resume", redPocketHandler.Resume)
+			owned.POST("/extend", redPocketHandler.Extend)
+			owned.POST("/topup", redPocketHandler.TopUp)
+			owned.POST("/shrink", redPocketHandler.Shrink)
+			owned.POST("/cancel", refundHandler.Cancel)
 		}
 
+		// Claim payout status (public)
+		api.GET("/claim/:id", payoutHandler.Get)
+		api.GET("/claim/:id/diagnose", payoutHandler.Diagnose)
+
+		// A claimer's claim history, treasure hunts and teams (public)
+		api.GET("/users/:platform/:platformId/claims", claimHistoryHandler.UserClaims)
+		api.GET("/users/:platform/:platformId/hunts", huntHandler.Progress)
+		api.GET("/users/:platform/:platformId/teams", teamHandler.UserTeams)
+
+		// Campaign teams: forming, joining and leaderboards (public)
+		api.GET("/campaigns/:id/teams", teamHandler.Leaderboard)
+		api.POST("/campaigns/:id/teams", teamHandler.Create)
+		api.POST("/teams/join", teamHandler.Join)
+		api.GET("/teams/:id", teamHandler.Get)
+
+		// Live pockets of discoverable campaigns (public)
+		api.GET("/discover", discoveryHandler.Discover)
+
+		// Tokens red pockets can be created in (public)
+		api.GET("/tokens", tokenHandler.List)
+
+		// Yield vaults claimers can save payouts in (public)
+		api.GET("/savings/vaults", savingsHandler.ListVaults)
+
+		// Claimer sign-in with a code sent by their chat bot (public)
+		api.POST("/claimers/login-code", claimerSessionHandler.RequestCode)
+		api.POST("/claimers/session", claimerSessionHandler.CreateSession)
+
 		// Wallet routes (public)
 		wallet := api.Group("/wallet")
 		{
 			wallet.GET("/:userId", walletHandler.GetOrCreate)
-			wallet.POST("/withdraw", walletHandler.Withdraw)
+			wallet.GET("/:userId/balance", walletHandler.Balance)
+			wallet.GET("/:userId/overview", walletHandler.Overview)
+			wallet.POST("/:userId/consolidate", claimerAuth, signature, replayProtection, walletHandler.Consolidate)
+			wallet.GET("/consolidation/:id", walletHandler.GetConsolidation)
+			wallet.GET("/:userId/withdrawals", walletHandler.ListWithdrawals)
+			wallet.GET("/:userId/payout-splits", walletHandler.GetPayoutSplits)
+			wallet.PUT("/:userId/payout-splits", claimerAuth, walletHandler.UpdatePayoutSplits)
+			wallet.GET("/:userId/savings", savingsHandler.Get)
+			wallet.PUT("/:userId/savings", claimerAuth, savingsHandler.Update)
+			wallet.POST("/:userId/savings/withdraw", claimerAuth, signature, replayProtection, savingsHandler.Withdraw)
+			wallet.POST("/withdraw", claimerAuth, signature, replayProtection, walletHandler.Withdraw)
+			wallet.GET("/withdraw/quote", walletHandler.QuoteWithdrawal)
+			wallet.GET("/withdrawal/:id", walletHandler.GetWithdrawal)
+			wallet.GET("/offramp/quote", offRampHandler.Quote)
+			wallet.POST("/:userId/offramp", claimerAuth, signature, replayProtection, offRampHandler.CreateOrder)
 		}
 
+		// Enterprise report downloads (verified by signed URL)
+		api.GET("/exports/:id/download", reportHandler.Download)
+
+		// Weekly report subscriptions (verified by signed URL)
+		api.GET("/reports/subscribers/:id/confirm", weeklyReportHandler.Confirm)
+		api.GET("/reports/subscribers/:id/unsubscribe", weeklyReportHandler.Unsubscribe)
+		api.POST("/reports/subscribers/:id/unsubscribe", weeklyReportHandler.Unsubscribe)
+
+		// Fiat off-ramp provider webhooks (verified by signature)
+		api.POST("/offramp/webhook", offRampHandler.Webhook)
+
 		// XCM Cross-chain routes (public)
 		xcm := api.Group("/xcm")
 		{
 			xcm.GET("/chains", xcmHandler.GetSupportedChains)
 			xcm.GET("/assets/:asset", xcmHandler.GetAssetInfo)
 			xcm.GET("/optimal-chain", xcmHandler.GetOptimalChain)
-			xcm.POST("/transfer", xcmHandler.InitiateTransfer)
+			xcm.POST("/transfer", signature, replayProtection, xcmHandler.InitiateTransfer)
 			xcm.GET("/transfer/:bridgeId", xcmHandler.GetTransferStatus)
 			xcm.GET("/balance", xcmHandler.GetBalance)
 			xcm.GET("/estimate-fee", xcmHandler.EstimateFee)
 			xcm.GET("/health/:chainId", xcmHandler.HealthCheck)
 		}
 
-		// Hyperbridge - Polkadot cross-chain bridge (public)
+		// Hyperbridge - Polkadot cross-chain bridge (public; transfers move
+		// the claimer's funds and need their session token)
 		bridge := api.Group("/bridge")
 		{
 			bridge.GET("/balances", hyperbridgeHandler.GetMultiChainBalances)      // 并行查询多链余额
 			bridge.GET("/quotes", hyperbridgeHandler.GetBridgeQuotes)              // 获取所有协议报价
-			bridge.POST("/transfer", hyperbridgeHandler.InitiateBridgeTransfer)   // 发起跨链转账
+			bridge.POST("/transfer", claimerAuth, signature, replayProtection, hyperbridgeHandler.InitiateBridgeTransfer)   // 发起跨链转账
 			bridge.GET("/status/:bridgeId", hyperbridgeHandler.GetBridgeStatus)   // 查询转账状态
-			bridge.POST("/auto", hyperbridgeHandler.AutoBridge)                   // 自动选择最优路径
+			bridge.GET("/status/:bridgeId/stream", hyperbridgeHandler.StreamBridgeStatus) // 实时推送转账状态 (SSE)
+			bridge.POST("/auto", claimerAuth, signature, replayProtection, hyperbridgeHandler.AutoBridge)                   // 自动选择最优路径
 			bridge.GET("/best-source", hyperbridgeHandler.FindBestSource)         // 查找最佳源链
 		}
 
@@ -127,6 +495,7 @@ func main() {
 		botRoutes := api.Group("/bot")
 		{
 			botRoutes.GET("/status", botHandler.GetBotStatus)
+			botRoutes.POST("/locale", botHandler.SetChatLocale)
 			// Telegram
 			botRoutes.POST("/telegram/webhook", botHandler.TelegramWebhook)
 			botRoutes.POST("/telegram/set-webhook", botHandler.SetTelegramWebhook)
@@ -135,22 +504,115 @@ func main() {
 			// Discord
 			botRoutes.POST("/discord/notify", botHandler.SendDiscordNotification)
 			botRoutes.POST("/discord/webhook", botHandler.SendDiscordWebhook)
+			// Slack
+			botRoutes.POST("/slack/events", botHandler.SlackEvents)
+			botRoutes.POST("/slack/commands", botHandler.SlackCommand)
+			botRoutes.GET("/slack/oauth/callback", botHandler.SlackOAuthCallback)
+			botRoutes.POST("/slack/notify", botHandler.SendSlackNotification)
 		}
 
-		// Enterprise routes (requires auth)
+		// Enterprise routes (requires a bearer token or an API key signature)
 		enterprise := api.Group("/enterprise")
-		enterprise.Use(middleware.Auth(cfg.JWTSecret))
+		enterprise.Use(middleware.APIKeyAuth(enterpriseKeys), middleware.Auth(jwtKeys, apiKeySvc))
 		{
 			enterprise.GET("/campaigns", campaignHandler.List)
 			enterprise.POST("/campaigns", campaignHandler.Create)
 			enterprise.GET("/campaigns/:id", campaignHandler.Get)
 			enterprise.PUT("/campaigns/:id/status", campaignHandler.UpdateStatus)
 			enterprise.DELETE("/campaigns/:id", campaignHandler.Delete)
+			enterprise.GET("/campaigns/:id/claim-page", campaignHandler.GetClaimPage)
+			enterprise.PUT("/campaigns/:id/claim-page", campaignHandler.UpdateClaimPage)
+			enterprise.GET("/campaigns/:id/terms", campaignHandler.GetTerms)
+			enterprise.PUT("/campaigns/:id/terms", campaignHandler.UpdateTerms)
+			enterprise.GET("/campaigns/:id/visibility", campaignHandler.GetVisibility)
+			enterprise.PUT("/campaigns/:id/visibility", campaignHandler.UpdateVisibility)
+			enterprise.GET("/campaigns/:id/charity", campaignHandler.GetCharity)
+			enterprise.PUT("/campaigns/:id/charity", campaignHandler.UpdateCharity)
+			enterprise.GET("/campaigns/:id/claim-weights", campaignHandler.GetClaimWeights)
+			enterprise.PUT("/campaigns/:id/claim-weights", campaignHandler.UpdateClaimWeights)
+			enterprise.GET("/campaigns/:id/eligibility", eligibilityHandler.GetCampaignRules)
+			enterprise.PUT("/campaigns/:id/eligibility", eligibilityHandler.UpdateCampaignRules)
+			enterprise.GET("/campaigns/:id/hunts", huntHandler.List)
+			enterprise.POST("/campaigns/:id/hunts", huntHandler.Create)
+			enterprise.DELETE("/campaigns/:id/hunts/:huntId", huntHandler.Delete)
+			enterprise.GET("/campaigns/:id/team-milestones", teamHandler.ListMilestones)
+			enterprise.POST("/campaigns/:id/team-milestones", teamHandler.CreateMilestone)
+			enterprise.DELETE("/campaigns/:id/team-milestones/:milestoneId", teamHandler.DeleteMilestone)
+			enterprise.GET("/campaigns/:id/rules", campaignRuleHandler.List)
+			enterprise.POST("/campaigns/:id/rules", campaignRuleHandler.Create)
+			enterprise.POST("/campaigns/:id/rules/preview", campaignRuleHandler.Preview)
+			enterprise.GET("/campaigns/:id/rules/:ruleId", campaignRuleHandler.Get)
+			enterprise.PUT("/campaigns/:id/rules/:ruleId", campaignRuleHandler.Update)
+			enterprise.DELETE("/campaigns/:id/rules/:ruleId", campaignRuleHandler.Delete)
+			enterprise.POST("/campaigns/:id/rules/:ruleId/dry-run", campaignRuleHandler.DryRun)
+			enterprise.GET("/campaigns/:id/funding", fundingHandler.Get)
+			enterprise.POST("/campaigns/:id/funding/convert", fundingHandler.Convert)
+			enterprise.GET("/campaigns/:id/audience", audienceHandler.List)
+			enterprise.POST("/campaigns/:id/audience", audienceHandler.Upload)
+			enterprise.POST("/campaigns/:id/audience/compute", audienceHandler.Compute)
+			enterprise.GET("/campaigns/:id/audience/:snapshotId/coverage", audienceHandler.Coverage)
+			enterprise.POST("/media/cover", mediaHandler.UploadCover)
+			enterprise.POST("/media/nft-metadata", mediaHandler.PinNFTMetadata)
+			enterprise.GET("/media/pins", mediaHandler.ListPins)
+			enterprise.GET("/media/pins/:id", mediaHandler.GetPin)
 			enterprise.GET("/claims", campaignHandler.ListClaims)
+			enterprise.GET("/claims/export", reportHandler.ExportClaims)
+			enterprise.GET("/claims/reorgs", receiptHandler.ListReorgs)
+			enterprise.POST("/claims/:id/retry", payoutHandler.Retry)
+			enterprise.GET("/fraud/claims", fraudHandler.ListFlagged)
+			enterprise.POST("/fraud/claims/:id/review", fraudHandler.Review)
 			enterprise.GET("/analytics", campaignHandler.Analytics)
+			enterprise.GET("/analytics/export", reportHandler.ExportAnalytics)
+			enterprise.GET("/analytics/timeseries", analyticsHandler.TimeSeries)
+			enterprise.GET("/exports/:id", reportHandler.GetExport)
+			enterprise.GET("/scheduled", scheduleHandler.List)
+			enterprise.DELETE("/scheduled/:id", scheduleHandler.Cancel)
+			enterprise.GET("/withdrawals", walletHandler.ListEnterpriseWithdrawals)
+			enterprise.POST("/withdrawals", replayProtection, walletHandler.EnterpriseWithdraw)
+			enterprise.GET("/withdrawals/:id/travel-rule", walletHandler.TravelRuleExport)
+			enterprise.GET("/webhooks", webhookHandler.List)
+			enterprise.POST("/webhooks", webhookHandler.Create)
+			enterprise.GET("/webhooks/:id", webhookHandler.Get)
+			enterprise.PUT("/webhooks/:id", webhookHandler.Update)
+			enterprise.DELETE("/webhooks/:id", webhookHandler.Delete)
+			enterprise.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+			enterprise.GET("/digest", digestHandler.Get)
+			enterprise.PUT("/digest", digestHandler.Update)
+			enterprise.DELETE("/digest", digestHandler.Delete)
+			enterprise.GET("/reports/weekly", weeklyReportHandler.Weekly)
+			enterprise.GET("/reports/subscribers", weeklyReportHandler.ListSubscribers)
+			enterprise.POST("/reports/subscribers", weeklyReportHandler.AddSubscriber)
+			enterprise.DELETE("/reports/subscribers/:id", weeklyReportHandler.RemoveSubscriber)
+			enterprise.GET("/api-keys", apiKeyHandler.List)
+			enterprise.POST("/api-keys", apiKeyHandler.Create)
+			enterprise.DELETE("/api-keys/:id", apiKeyHandler.Revoke)
+			enterprise.GET("/api-key", enterpriseKeyHandler.Get)
+			enterprise.POST("/api-key", enterpriseKeyHandler.Create)
+			enterprise.POST("/api-key/rotate", enterpriseKeyHandler.Rotate)
+			enterprise.DELETE("/api-key", enterpriseKeyHandler.Revoke)
+			enterprise.POST("/token", authHandler.Issue)
+			enterprise.GET("/slack/install", botHandler.SlackInstallURL)
+			enterprise.GET("/slack/installations", botHandler.ListSlackInstallations)
+			enterprise.GET("/partner", partnerHandler.GetEnterprisePartner)
+			enterprise.POST("/partner", partnerHandler.Attach)
+			enterprise.POST("/sandbox/faucet", sandboxHandler.Faucet)
+		}
+
+		// Partner reporting, authenticated by the partner's X-Partner-Key
+		partner := api.Group("/partner", middleware.PartnerAuth(partnerSvc))
+		{
+			partner.GET("", partnerHandler.Get)
+			partner.GET("/enterprises", partnerHandler.ListEnterprises)
+			partner.GET("/statements", partnerHandler.Statements)
 		}
 	}
 
+	// Client certificates for the admin listener and gRPC
+	tlsCfg, err := mtls.ServerConfig(cfg)
+	if err != nil {
+		fatal("failed to load mTLS configuration", "error", err)
+	}
+
 	// Server
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -162,22 +624,110 @@ func main() {
 
 	// Graceful shutdown
 	go func() {
-		log.Printf("Server starting on port %s", cfg.Port)
+		slog.Info("server starting", "port", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed: %v", err)
+			fatal("server failed", "error", err)
 		}
 	}()
 
+	// Operator endpoints on their own listener
+	var adminSrv *http.Server
+	if cfg.AdminPort != "" {
+		adminRouter := gin.New()
+		adminRouter.Use(middleware.RequestID())
+		adminRouter.Use(gin.Recovery())
+		adminRouter.Use(middleware.Logger())
+		admin := adminRouter.Group("/admin")
+		admin.GET("/health", healthHandler.Health)
+		admin.GET("/health/replicas", healthHandler.Replicas)
+		admin.GET("/metrics", gin.WrapH(metrics.Handler(cfg.MetricsToken)))
+		// Without a token these would be open to anyone who reaches the
+		// port, mTLS or not, as on the public router
+		if cfg.AdminToken != "" {
+			admin.GET("/claims/failures/:reference", middleware.AdminToken(cfg.AdminToken), supportHandler.ClaimFailure)
+			admin.POST("/partners", middleware.AdminToken(cfg.AdminToken), partnerHandler.Create)
+			admin.POST("/sandbox/enterprises", middleware.AdminToken(cfg.AdminToken), sandboxHandler.CreateEnterprise)
+			admin.GET("/events", middleware.AdminToken(cfg.AdminToken), eventLogHandler.List)
+		} else {
+			slog.Warn("ADMIN_TOKEN is not set; support, partner, sandbox and event log endpoints are disabled")
+		}
+
+		adminSrv = &http.Server{
+			Addr:         ":" + cfg.AdminPort,
+			Handler:      adminRouter,
+			TLSConfig:    tlsCfg,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+		go func() {
+			var err error
+			if tlsCfg != nil {
+				slog.Info("admin server starting with mTLS", "port", cfg.AdminPort)
+				err = adminSrv.ListenAndServeTLS("", "")
+			} else {
+				slog.Warn("admin server starting without mTLS", "port", cfg.AdminPort)
+				err = adminSrv.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				fatal("admin server failed", "error", err)
+			}
+		}()
+	}
+
+	// gRPC API for internal services
+	grpcSrv, grpcHealth := grpcapi.New(&grpcapi.Services{
+		RedPocket: redPocketSvc,
+		Campaign:  campaignSvc,
+		Wallet:    walletSvc,
+		Ledger:    ledgerSvc,
+		Payouts:   payoutQueue,
+		Readiness: readiness,
+	}, jwtKeys, tlsCfg)
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			fatal("failed to listen for gRPC", "error", err)
+		}
+		go func() {
+			slog.Info("gRPC server starting", "port", cfg.GRPCPort)
+			if err := grpcSrv.Serve(lis); err != nil {
+				fatal("gRPC server failed", "error", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("shutting down server")
+	stopJobs()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("server forced to shutdown", "error", err)
+	}
+	if adminSrv != nil {
+		adminSrv.Shutdown(ctx)
+	}
+	grpcHealth.Shutdown()
+	grpcSrv.GracefulStop()
+	// Give in-flight payouts the rest of the grace period
+	select {
+	case <-payoutsDone:
+	case <-ctx.Done():
+		slog.WarnContext(ctx, "payout workers did not finish in time")
 	}
-	log.Println("Server exited")
+	if err := shutdownTracing(ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to flush traces", "error", err)
+	}
+	slog.Info("server exited")
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
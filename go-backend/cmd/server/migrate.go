@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// runMigrate runs `server migrate [up|status|force <version>]`: applies the
+// pending migrations, reports the version the database is at, or records a
+// version as applied without running anything
+func runMigrate(cfg *config.Config, args []string) {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		if err := migrateUp(cfg); err != nil {
+			fatal("failed to migrate database", "error", err)
+		}
+	case "status":
+		m, err := repository.NewMigrator(cfg.DatabaseURL)
+		if err != nil {
+			fatal("failed to open migrations", "error", err)
+		}
+		defer m.Close()
+		version, dirty, err := m.Version()
+		if err != nil {
+			fatal("failed to read migration version", "error", err)
+		}
+		latest, err := m.Latest()
+		if err != nil {
+			fatal("failed to read migrations", "error", err)
+		}
+		switch {
+		case dirty:
+			slog.Warn("migration failed part way: finish or undo it by hand, then run `server migrate force <version>`", "version", version)
+		case version < latest:
+			slog.Info("migrations pending", "version", version, "pending", latest-version, "latest", latest)
+		default:
+			slog.Info("database schema up to date", "version", version)
+		}
+	case "force":
+		if len(args) != 2 {
+			fatal("usage: server migrate force <version>")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 1 {
+			fatal("invalid version", "version", args[1])
+		}
+		m, err := repository.NewMigrator(cfg.DatabaseURL)
+		if err != nil {
+			fatal("failed to open migrations", "error", err)
+		}
+		defer m.Close()
+		if err := m.Force(version); err != nil {
+			fatal("failed to force migration version", "error", err)
+		}
+		slog.Info("recorded migration as applied", "version", version)
+	default:
+		fatal("unknown migrate command: use up, status or force <version>", "command", cmd)
+	}
+}
+
+// migrateUp applies the pending migrations
+func migrateUp(cfg *config.Config) error {
+	m, err := repository.NewMigrator(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	before, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil {
+		return err
+	}
+	after, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if after != before {
+		slog.Info("migrated database", "from", before, "to", after)
+	} else {
+		slog.Info("database schema up to date", "version", after)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Package awsjson calls AWS JSON APIs without the AWS SDK
+package awsjson
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Call POSTs in to an AWS JSON 1.1 API such as KMS or Secrets Manager and
+// decodes the response into out. Credentials come from the standard
+// AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN variables;
+// the request is signed with SigV4 for service in region.
+func Call(ctx context.Context, client *http.Client, endpoint, region, service, target string, in, out interface{}) error {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return errors.New("AWS credentials not set")
+	}
+	sessionToken := os.Getenv("AWS_SESSION_TOKEN")
+
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	headers := map[string]string{
+		"content-type": "application/x-amz-json-1.1",
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": target,
+	}
+	signed := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if sessionToken != "" {
+		headers["x-amz-security-token"] = sessionToken
+		signed = []string{"content-type", "host", "x-amz-date", "x-amz-security-token", "x-amz-target"}
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signed {
+		canonicalHeaders.WriteString(h + ":" + headers[h] + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost, "/", "", canonicalHeaders.String(), signedHeaders, hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	for h, v := range headers {
+		if h != "host" {
+			req.Header.Set(h, v)
+		}
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("aws %s error %d: %s", service, resp.StatusCode, string(respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
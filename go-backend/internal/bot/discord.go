@@ -2,22 +2,27 @@ package bot
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
 )
 
 // DiscordBot handles Discord bot integration
 type DiscordBot struct {
 	cfg        *config.Config
+	tokenMu    sync.RWMutex
 	token      string
 	httpClient *http.Client
 	baseURL    string
+	locales    LocaleStore
 }
 
 // DiscordEmbed represents a Discord embed
@@ -56,10 +61,10 @@ type DiscordMessage struct {
 }
 
 // NewDiscordBot creates a new Discord bot instance
-func NewDiscordBot(cfg *config.Config) *DiscordBot {
+func NewDiscordBot(cfg *config.Config, locales LocaleStore) *DiscordBot {
 	token := cfg.DiscordBotToken
 	if token == "" {
-		log.Println("Warning: DISCORD_BOT_TOKEN not set")
+		slog.Warn("discord: DISCORD_BOT_TOKEN not set")
 	}
 
 	return &DiscordBot{
@@ -69,12 +74,34 @@ func NewDiscordBot(cfg *config.Config) *DiscordBot {
 			Timeout: 30 * time.Second,
 		},
 		baseURL: "https://discord.com/api/v10",
+		locales: locales,
 	}
 }
 
+// SetChannelLocale sets the language used for messages sent to a channel
+func (b *DiscordBot) SetChannelLocale(channelID, locale string) error {
+	if b.locales == nil {
+		return fmt.Errorf("locale store not configured")
+	}
+	return b.locales.SetChatLocale(context.Background(), discordChannelKey(channelID), locale)
+}
+
+// SetToken replaces the bot token, as when it is rotated
+func (b *DiscordBot) SetToken(token string) {
+	b.tokenMu.Lock()
+	b.token = token
+	b.tokenMu.Unlock()
+}
+
+func (b *DiscordBot) botToken() string {
+	b.tokenMu.RLock()
+	defer b.tokenMu.RUnlock()
+	return b.token
+}
+
 // IsConfigured returns true if the bot is properly configured
 func (b *DiscordBot) IsConfigured() bool {
-	return b.token != ""
+	return b.botToken() != ""
 }
 
 // SendMessage sends a message to a Discord channel
@@ -87,7 +114,7 @@ func (b *DiscordBot) SendMessage(channelID string, message *DiscordMessage) erro
 	url := fmt.Sprintf("%s/channels/%s/messages", b.baseURL, channelID)
 
 	req, _ := http.NewRequest("POST", url, bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bot "+b.token)
+	req.Header.Set("Authorization", "Bot "+b.botToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := b.httpClient.Do(req)
@@ -104,27 +131,107 @@ func (b *DiscordBot) SendMessage(channelID string, message *DiscordMessage) erro
 	return nil
 }
 
+// SendDirectMessage sends a message to a user in their DM channel with the
+// bot, which Discord opens on first use
+func (b *DiscordBot) SendDirectMessage(ctx context.Context, userID string, message *DiscordMessage) error {
+	if !b.IsConfigured() {
+		return fmt.Errorf("discord bot not configured")
+	}
+
+	body, _ := json.Marshal(map[string]string{"recipient_id": userID})
+	req, err := http.NewRequestWithContext(ctx, "POST", b.baseURL+"/users/@me/channels", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+b.botToken())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to open DM channel: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("discord API error: %s", string(respBody))
+	}
+	var channel struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&channel); err != nil {
+		return err
+	}
+	return b.SendMessage(channel.ID, message)
+}
+
+// MemberRoles returns the role IDs of a member of a guild the bot is in,
+// none when the user is not a member
+func (b *DiscordBot) MemberRoles(ctx context.Context, guildID, userID string) ([]string, error) {
+	if !b.IsConfigured() {
+		return nil, fmt.Errorf("discord bot not configured")
+	}
+
+	url := fmt.Sprintf("%s/guilds/%s/members/%s", b.baseURL, guildID, userID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bot "+b.botToken())
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guild member: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("discord API error: %s", string(respBody))
+	}
+	var member struct {
+		Roles []string `json:"roles"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&member); err != nil {
+		return nil, err
+	}
+	return member.Roles, nil
+}
+
 // SendRedPocketNotification sends a red pocket notification to a channel
 func (b *DiscordBot) SendRedPocketNotification(channelID string, senderName string, amount float64, token string, claimLink string, message string) error {
+	locale := resolveLocale(b.locales, discordChannelKey(channelID), "")
+	msg := &DiscordMessage{
+		Embeds: []DiscordEmbed{redPocketEmbed(locale, senderName, amount, token, claimLink, message)},
+	}
+
+	return b.SendMessage(channelID, msg)
+}
+
+// SendClaimNotification notifies when someone claims a red pocket
+func (b *DiscordBot) SendClaimNotification(channelID string, claimerName string, amount float64, token string, remaining int) error {
+	locale := resolveLocale(b.locales, discordChannelKey(channelID), "")
 	embed := DiscordEmbed{
-		Title:       "🧧 Red Pocket Alert!",
-		Description: fmt.Sprintf("**%s** sent a red pocket!\n\n%s", senderName, message),
-		URL:         claimLink,
-		Color:       0xFF6B35, // Orange color
+		Title:       i18n.T(locale, "bot.discord.claimed_title"),
+		Description: i18n.T(locale, "bot.discord.claimed_desc", claimerName),
+		Color:       0x00FF00, // Green color
 		Fields: []DiscordEmbedField{
 			{
-				Name:   "💰 Amount",
+				Name:   i18n.T(locale, "bot.discord.received"),
 				Value:  fmt.Sprintf("%.2f %s", amount, token),
 				Inline: true,
 			},
 			{
-				Name:   "🎁 Claim",
-				Value:  fmt.Sprintf("[Click Here](%s)", claimLink),
+				Name:   i18n.T(locale, "bot.discord.remaining"),
+				Value:  i18n.T(locale, "bot.discord.remaining_value", remaining),
 				Inline: true,
 			},
 		},
 		Footer: &DiscordEmbedFooter{
-			Text: "Powered by Protocol Bank",
+			Text: i18n.T(locale, "bot.footer"),
 		},
 	}
 
@@ -135,34 +242,29 @@ func (b *DiscordBot) SendRedPocketNotification(channelID string, senderName stri
 	return b.SendMessage(channelID, msg)
 }
 
-// SendClaimNotification notifies when someone claims a red pocket
-func (b *DiscordBot) SendClaimNotification(channelID string, claimerName string, amount float64, token string, remaining int) error {
-	embed := DiscordEmbed{
-		Title:       "🎉 Red Pocket Claimed!",
-		Description: fmt.Sprintf("**%s** claimed a red pocket!", claimerName),
-		Color:       0x00FF00, // Green color
+// redPocketEmbed builds the localized red pocket announcement embed
+func redPocketEmbed(locale, senderName string, amount float64, token, claimLink, message string) DiscordEmbed {
+	return DiscordEmbed{
+		Title:       i18n.T(locale, "bot.discord.red_pocket_title"),
+		Description: i18n.T(locale, "bot.discord.red_pocket_desc", senderName, message),
+		URL:         claimLink,
+		Color:       0xFF6B35, // Orange color
 		Fields: []DiscordEmbedField{
 			{
-				Name:   "💰 Received",
+				Name:   i18n.T(locale, "bot.discord.amount"),
 				Value:  fmt.Sprintf("%.2f %s", amount, token),
 				Inline: true,
 			},
 			{
-				Name:   "📦 Remaining",
-				Value:  fmt.Sprintf("%d pockets", remaining),
+				Name:   i18n.T(locale, "bot.discord.claim"),
+				Value:  i18n.T(locale, "bot.discord.click_here", claimLink),
 				Inline: true,
 			},
 		},
 		Footer: &DiscordEmbedFooter{
-			Text: "Powered by Protocol Bank",
+			Text: i18n.T(locale, "bot.footer"),
 		},
 	}
-
-	msg := &DiscordMessage{
-		Embeds: []DiscordEmbed{embed},
-	}
-
-	return b.SendMessage(channelID, msg)
 }
 
 // SendWebhookMessage sends a message via Discord webhook (no bot token needed)
@@ -183,32 +285,15 @@ func (b *DiscordBot) SendWebhookMessage(webhookURL string, message *DiscordMessa
 	return nil
 }
 
-// SendRedPocketWebhook sends a red pocket notification via webhook
-func (b *DiscordBot) SendRedPocketWebhook(webhookURL string, senderName string, amount float64, token string, claimLink string, message string) error {
-	embed := DiscordEmbed{
-		Title:       "🧧 Red Pocket Alert!",
-		Description: fmt.Sprintf("**%s** sent a red pocket!\n\n%s", senderName, message),
-		URL:         claimLink,
-		Color:       0xFF6B35,
-		Fields: []DiscordEmbedField{
-			{
-				Name:   "💰 Amount",
-				Value:  fmt.Sprintf("%.2f %s", amount, token),
-				Inline: true,
-			},
-			{
-				Name:   "🎁 Claim",
-				Value:  fmt.Sprintf("[Click Here](%s)", claimLink),
-				Inline: true,
-			},
-		},
-		Footer: &DiscordEmbedFooter{
-			Text: "Powered by Protocol Bank",
-		},
+// SendRedPocketWebhook sends a red pocket notification via webhook.
+// Webhooks are not tied to a stored channel, so the locale is passed explicitly.
+func (b *DiscordBot) SendRedPocketWebhook(webhookURL string, locale string, senderName string, amount float64, token string, claimLink string, message string) error {
+	if locale = i18n.Normalize(locale); locale == "" {
+		locale = i18n.DefaultLocale
 	}
 
 	msg := &DiscordMessage{
-		Embeds: []DiscordEmbed{embed},
+		Embeds: []DiscordEmbed{redPocketEmbed(locale, senderName, amount, token, claimLink, message)},
 	}
 
 	return b.SendWebhookMessage(webhookURL, msg)
@@ -248,7 +333,7 @@ func (b *DiscordBot) CreateSlashCommands(applicationID string) error {
 	url := fmt.Sprintf("%s/applications/%s/commands", b.baseURL, applicationID)
 
 	req, _ := http.NewRequest("PUT", url, bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bot "+b.token)
+	req.Header.Set("Authorization", "Bot "+b.botToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := b.httpClient.Do(req)
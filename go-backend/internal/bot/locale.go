@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+)
+
+// LocaleStore persists the preferred language of a chat or channel
+type LocaleStore interface {
+	GetChatLocale(ctx context.Context, chatKey string) (string, error)
+	SetChatLocale(ctx context.Context, chatKey, locale string) error
+}
+
+func telegramChatKey(chatID int64) string {
+	return fmt.Sprintf("telegram:%d", chatID)
+}
+
+func discordChannelKey(channelID string) string {
+	return "discord:" + channelID
+}
+
+// resolveLocale returns the stored chat locale, then the fallback tag, then the default
+func resolveLocale(store LocaleStore, chatKey, fallbackTag string) string {
+	if store != nil {
+		if locale, err := store.GetChatLocale(context.Background(), chatKey); err == nil && locale != "" {
+			return locale
+		}
+	}
+	if locale := i18n.Normalize(fallbackTag); locale != "" {
+		return locale
+	}
+	return i18n.DefaultLocale
+}
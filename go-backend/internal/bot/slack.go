@@ -0,0 +1,496 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// Scopes the app asks for when installed: the /redpocket command, posting
+// to channels and hearing mentions
+const slackScopes = "commands,chat:write,chat:write.public,app_mentions:read"
+
+const (
+	// Slack retries a request whose timestamp is older than this
+	slackRequestWindow = 5 * time.Minute
+	// How long an install link stays valid
+	slackInstallStateTTL = 10 * time.Minute
+)
+
+// ErrInvalidSlackRequest is returned for requests not signed with the app's
+// signing secret
+var ErrInvalidSlackRequest = errors.New("invalid slack request signature")
+
+// SlackInstallStore persists the workspaces the app is installed in
+type SlackInstallStore interface {
+	SaveSlackInstallation(ctx context.Context, in *model.SlackInstallation) error
+	GetSlackInstallation(ctx context.Context, teamID string) (*model.SlackInstallation, error)
+	DeleteSlackInstallation(ctx context.Context, teamID string) error
+}
+
+// SlackBot handles the Slack app: installing it into workspaces, its events
+// and /redpocket command, and Block Kit announcements posted with each
+// workspace's own bot token
+type SlackBot struct {
+	cfg        *config.Config
+	httpClient *http.Client
+	baseURL    string
+	locales    LocaleStore
+	installs   SlackInstallStore
+	claimer    PocketClaimer
+}
+
+// SlackCommand is a slash command invocation, as form-posted by Slack
+type SlackCommand struct {
+	TeamID    string
+	ChannelID string
+	UserID    string
+	Command   string
+	Text      string
+}
+
+// SlackEnvelope is an Events API request
+type SlackEnvelope struct {
+	Type      string      `json:"type"`
+	Challenge string      `json:"challenge,omitempty"`
+	TeamID    string      `json:"team_id"`
+	Event     *SlackEvent `json:"event,omitempty"`
+}
+
+// SlackEvent is the event an Events API request carries
+type SlackEvent struct {
+	Type    string `json:"type"`
+	User    string `json:"user,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// SlackMessage is a message posted to a channel or returned to a command
+type SlackMessage struct {
+	Channel      string       `json:"channel,omitempty"`
+	ResponseType string       `json:"response_type,omitempty"` // ephemeral or in_channel, for command responses
+	Text         string       `json:"text"`                    // notification and fallback text
+	Blocks       []SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a Block Kit layout block
+type SlackBlock struct {
+	Type     string        `json:"type"`
+	Text     *SlackText    `json:"text,omitempty"`
+	Fields   []SlackText   `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"` // SlackButton in actions blocks, SlackText in context blocks
+}
+
+// SlackText is a Block Kit text object
+type SlackText struct {
+	Type string `json:"type"` // plain_text or mrkdwn
+	Text string `json:"text"`
+}
+
+// SlackButton is a button of an actions block
+type SlackButton struct {
+	Type  string     `json:"type"` // button
+	Text  *SlackText `json:"text"`
+	URL   string     `json:"url,omitempty"`
+	Style string     `json:"style,omitempty"`
+}
+
+// NewSlackBot creates the Slack app
+func NewSlackBot(cfg *config.Config, locales LocaleStore, installs SlackInstallStore) *SlackBot {
+	if cfg.SlackClientID == "" {
+		slog.Warn("slack: SLACK_CLIENT_ID not set")
+	}
+
+	return &SlackBot{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL:  "https://slack.com",
+		locales:  locales,
+		installs: installs,
+	}
+}
+
+// UseClaimer lets channel members claim pockets with /redpocket claim
+func (b *SlackBot) UseClaimer(claimer PocketClaimer) {
+	b.claimer = claimer
+}
+
+// IsConfigured returns true if the app's credentials are set
+func (b *SlackBot) IsConfigured() bool {
+	return b.cfg.SlackClientID != "" && b.cfg.SlackClientSecret != "" && b.cfg.SlackSigningSecret != ""
+}
+
+func slackChannelKey(teamID, channelID string) string {
+	return "slack:" + teamID + ":" + channelID
+}
+
+// ParseSlackChannel splits a red pocket's Slack channel, stored as
+// TEAM_ID:CHANNEL_ID since channel IDs are only unique within a workspace
+func ParseSlackChannel(channel string) (teamID, channelID string, ok bool) {
+	teamID, channelID, ok = strings.Cut(channel, ":")
+	return teamID, channelID, ok && teamID != "" && channelID != ""
+}
+
+// SetChannelLocale sets the language used for messages sent to a channel
+func (b *SlackBot) SetChannelLocale(teamID, channelID, locale string) error {
+	if b.locales == nil {
+		return fmt.Errorf("locale store not configured")
+	}
+	return b.locales.SetChatLocale(context.Background(), slackChannelKey(teamID, channelID), locale)
+}
+
+// VerifyRequest checks that a request came from Slack: its
+// X-Slack-Signature must be the HMAC of the timestamp and body keyed with
+// the signing secret, and X-Slack-Request-Timestamp recent
+func (b *SlackBot) VerifyRequest(timestamp, signature string, body []byte) error {
+	if b.cfg.SlackSigningSecret == "" {
+		return fmt.Errorf("slack app not configured")
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSlackRequest
+	}
+	if skew := time.Since(time.Unix(sec, 0)); skew > slackRequestWindow || skew < -slackRequestWindow {
+		return ErrInvalidSlackRequest
+	}
+
+	mac := hmac.New(sha256.New, []byte(b.cfg.SlackSigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "v0="))
+	if err != nil || !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSlackRequest
+	}
+	return nil
+}
+
+// InstallURL returns the link that adds the app to a workspace on behalf of
+// an enterprise. It expires after a few minutes.
+func (b *SlackBot) InstallURL(enterpriseID string) (string, error) {
+	if !b.IsConfigured() {
+		return "", fmt.Errorf("slack app not configured")
+	}
+	q := url.Values{
+		"client_id": {b.cfg.SlackClientID},
+		"scope":     {slackScopes},
+		"state":     {b.installState(enterpriseID, time.Now().Add(slackInstallStateTTL))},
+	}
+	if b.cfg.SlackRedirectURL != "" {
+		q.Set("redirect_uri", b.cfg.SlackRedirectURL)
+	}
+	return b.baseURL + "/oauth/v2/authorize?" + q.Encode(), nil
+}
+
+// installState ties an install to the enterprise that started it:
+// ENTERPRISE.EXPIRY.HMAC, keyed with the client secret
+func (b *SlackBot) installState(enterpriseID string, expires time.Time) string {
+	payload := enterpriseID + "." + strconv.FormatInt(expires.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(b.cfg.SlackClientSecret))
+	mac.Write([]byte(payload))
+	return payload + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (b *SlackBot) parseInstallState(state string) (string, error) {
+	i := strings.LastIndex(state, ".")
+	if i < 0 {
+		return "", errors.New("malformed install state")
+	}
+	payload := state[:i]
+	j := strings.LastIndex(payload, ".")
+	if j < 0 {
+		return "", errors.New("malformed install state")
+	}
+	enterpriseID, expiry := payload[:j], payload[j+1:]
+	sec, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", errors.New("malformed install state")
+	}
+	if !hmac.Equal([]byte(state), []byte(b.installState(enterpriseID, time.Unix(sec, 0)))) {
+		return "", errors.New("install state does not match")
+	}
+	if time.Now().Unix() > sec {
+		return "", errors.New("install link expired, start the install again")
+	}
+	return enterpriseID, nil
+}
+
+// CompleteInstall exchanges the code Slack redirected back with for the
+// workspace's bot token and stores it for the enterprise that started the
+// install
+func (b *SlackBot) CompleteInstall(ctx context.Context, code, state string) (*model.SlackInstallation, error) {
+	if !b.IsConfigured() {
+		return nil, fmt.Errorf("slack app not configured")
+	}
+	enterpriseID, err := b.parseInstallState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"client_id":     {b.cfg.SlackClientID},
+		"client_secret": {b.cfg.SlackClientSecret},
+		"code":          {code},
+	}
+	if b.cfg.SlackRedirectURL != "" {
+		form.Set("redirect_uri", b.cfg.SlackRedirectURL)
+	}
+	req, _ := http.NewRequestWithContext(ctx, "POST", b.baseURL+"/api/oauth.v2.access", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		Scope       string `json:"scope"`
+		BotUserID   string `json:"bot_user_id"`
+		Team        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"team"`
+		AuthedUser struct {
+			ID string `json:"id"`
+		} `json:"authed_user"`
+	}
+	if err := b.do(req, &result); err != nil {
+		return nil, fmt.Errorf("failed to exchange install code: %w", err)
+	}
+
+	in := &model.SlackInstallation{
+		TeamID:       result.Team.ID,
+		TeamName:     result.Team.Name,
+		EnterpriseID: enterpriseID,
+		BotUserID:    result.BotUserID,
+		BotToken:     result.AccessToken,
+		Scope:        result.Scope,
+		InstalledBy:  result.AuthedUser.ID,
+		InstalledAt:  time.Now(),
+	}
+	if err := b.installs.SaveSlackInstallation(ctx, in); err != nil {
+		return nil, fmt.Errorf("failed to save installation: %w", err)
+	}
+	return in, nil
+}
+
+// do sends a Web API request and decodes its response into out. Slack
+// reports failures as ok: false with a 200 status.
+func (b *SlackBot) do(req *http.Request, out interface{}) error {
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("slack API error: status %d", resp.StatusCode)
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return err
+	}
+	if !status.OK {
+		return fmt.Errorf("slack API error: %s", status.Error)
+	}
+	if out != nil {
+		return json.Unmarshal(raw, out)
+	}
+	return nil
+}
+
+// PostMessage posts a message to a channel of an installed workspace
+func (b *SlackBot) PostMessage(ctx context.Context, teamID string, msg *SlackMessage) error {
+	in, err := b.installs.GetSlackInstallation(ctx, teamID)
+	if err != nil {
+		return fmt.Errorf("slack workspace %s is not installed: %w", teamID, err)
+	}
+
+	body, _ := json.Marshal(msg)
+	req, _ := http.NewRequestWithContext(ctx, "POST", b.baseURL+"/api/chat.postMessage", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+in.BotToken)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	if err := b.do(req, nil); err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	return nil
+}
+
+// SendRedPocketNotification announces a red pocket in a channel. Given the
+// pocket's ID, the message also tells members how to claim it with the
+// /redpocket command.
+func (b *SlackBot) SendRedPocketNotification(ctx context.Context, teamID, channelID, redPocketID, senderName string, amount float64, token, claimLink, message string) error {
+	locale := resolveLocale(b.locales, slackChannelKey(teamID, channelID), "")
+	msg := redPocketBlocks(locale, redPocketID != "" && b.claimer != nil, redPocketID, senderName, amount, token, claimLink, message)
+	msg.Channel = channelID
+	return b.PostMessage(ctx, teamID, msg)
+}
+
+// SendClaimNotification tells a channel that someone claimed a red pocket
+func (b *SlackBot) SendClaimNotification(ctx context.Context, teamID, channelID, claimerName string, amount float64, token string, remaining int) error {
+	locale := resolveLocale(b.locales, slackChannelKey(teamID, channelID), "")
+	text := i18n.T(locale, "bot.slack.claimed", claimerName)
+	msg := &SlackMessage{
+		Channel: channelID,
+		Text:    text,
+		Blocks: []SlackBlock{
+			{Type: "section", Text: mrkdwn(text), Fields: []SlackText{
+				*mrkdwn(i18n.T(locale, "bot.slack.received") + "\n" + fmt.Sprintf("%.2f %s", amount, token)),
+				*mrkdwn(i18n.T(locale, "bot.slack.remaining") + "\n" + i18n.T(locale, "bot.slack.remaining_value", remaining)),
+			}},
+			footerBlock(locale),
+		},
+	}
+	return b.PostMessage(ctx, teamID, msg)
+}
+
+// redPocketBlocks builds the localized red pocket announcement
+func redPocketBlocks(locale string, claimable bool, redPocketID, senderName string, amount float64, token, claimLink, message string) *SlackMessage {
+	title := i18n.T(locale, "bot.slack.red_pocket_title")
+	desc := i18n.T(locale, "bot.slack.red_pocket_desc", senderName)
+	if message != "" {
+		desc += "\n> " + message
+	}
+
+	blocks := []SlackBlock{
+		{Type: "header", Text: &SlackText{Type: "plain_text", Text: title}},
+		{Type: "section", Text: mrkdwn(desc), Fields: []SlackText{
+			*mrkdwn(i18n.T(locale, "bot.slack.amount") + "\n" + fmt.Sprintf("*%.2f %s*", amount, token)),
+		}},
+		{Type: "actions", Elements: []interface{}{SlackButton{
+			Type:  "button",
+			Text:  &SlackText{Type: "plain_text", Text: i18n.T(locale, "bot.slack.claim_button")},
+			URL:   claimLink,
+			Style: "primary",
+		}}},
+	}
+	if claimable {
+		blocks = append(blocks, SlackBlock{Type: "context", Elements: []interface{}{
+			mrkdwn(i18n.T(locale, "bot.slack.claim_hint", redPocketID)),
+		}})
+	}
+	blocks = append(blocks, footerBlock(locale))
+	return &SlackMessage{Text: title + " " + desc, Blocks: blocks}
+}
+
+func mrkdwn(text string) *SlackText {
+	return &SlackText{Type: "mrkdwn", Text: text}
+}
+
+func footerBlock(locale string) SlackBlock {
+	return SlackBlock{Type: "context", Elements: []interface{}{
+		mrkdwn(i18n.T(locale, "bot.footer")),
+	}}
+}
+
+// HandleEvent processes an Events API request. For the URL verification
+// Slack sends when the events URL is set, it returns the challenge to echo.
+func (b *SlackBot) HandleEvent(ctx context.Context, env *SlackEnvelope) (string, error) {
+	switch env.Type {
+	case "url_verification":
+		return env.Challenge, nil
+	case "event_callback":
+	default:
+		return "", nil
+	}
+	if env.Event == nil {
+		return "", nil
+	}
+
+	switch env.Event.Type {
+	case "app_uninstalled", "tokens_revoked":
+		if err := b.installs.DeleteSlackInstallation(ctx, env.TeamID); err != nil {
+			return "", fmt.Errorf("failed to remove workspace %s: %w", env.TeamID, err)
+		}
+		slog.InfoContext(ctx, "slack: workspace uninstalled the app", "team_id", env.TeamID)
+	case "app_mention":
+		locale := resolveLocale(b.locales, slackChannelKey(env.TeamID, env.Event.Channel), "")
+		return "", b.PostMessage(ctx, env.TeamID, &SlackMessage{
+			Channel: env.Event.Channel,
+			Text:    b.helpText(locale),
+		})
+	}
+	return "", nil
+}
+
+// HandleCommand answers /redpocket. The response is only shown to the user
+// who ran the command.
+//
+//	/redpocket help
+//	/redpocket claim <red pocket ID>
+//	/redpocket language <code>
+func (b *SlackBot) HandleCommand(ctx context.Context, cmd *SlackCommand) (*SlackMessage, error) {
+	locale := resolveLocale(b.locales, slackChannelKey(cmd.TeamID, cmd.ChannelID), "")
+	args := strings.Fields(cmd.Text)
+	sub := ""
+	if len(args) > 0 {
+		sub = strings.ToLower(args[0])
+	}
+
+	switch sub {
+	case "claim":
+		if len(args) < 2 || b.claimer == nil {
+			return ephemeral(i18n.T(locale, "bot.slack.claim_usage")), nil
+		}
+		return b.handleClaim(ctx, locale, cmd, args[1])
+	case "language":
+		supported := strings.Join(i18n.Supported(), ", ")
+		if len(args) < 2 || b.locales == nil {
+			return ephemeral(i18n.T(locale, "bot.slack.language_usage", supported)), nil
+		}
+		next := i18n.Normalize(args[1])
+		if next == "" {
+			return ephemeral(i18n.T(locale, "bot.slack.language_usage", supported)), nil
+		}
+		if err := b.SetChannelLocale(cmd.TeamID, cmd.ChannelID, next); err != nil {
+			return nil, err
+		}
+		return ephemeral(i18n.T(next, "bot.slack.language_set", next)), nil
+	default:
+		return ephemeral(b.helpText(locale)), nil
+	}
+}
+
+// handleClaim claims a pocket for the user who ran the command and, when
+// they received something, tells the channel
+func (b *SlackBot) handleClaim(ctx context.Context, locale string, cmd *SlackCommand, redPocketID string) (*SlackMessage, error) {
+	result, err := b.claimer.ClaimForChat(i18n.WithLocale(ctx, locale), "slack", cmd.UserID, cmd.TeamID+":"+cmd.ChannelID, redPocketID, ChatMember{})
+	if err != nil {
+		slog.ErrorContext(ctx, "slack: failed to claim red pocket", "red_pocket_id", redPocketID, "error", err)
+		return ephemeral(claimErrorText(locale, "bot.slack.claim_error", err)), nil
+	}
+	if result.Amount == 0 {
+		return ephemeral(result.Error), nil
+	}
+
+	rp := result.RedPocket
+	if err := b.SendClaimNotification(ctx, cmd.TeamID, cmd.ChannelID, "<@"+cmd.UserID+">", result.Amount, rp.Token, rp.TotalCount-rp.ClaimedCount); err != nil {
+		slog.ErrorContext(ctx, "slack: failed to announce claim", "red_pocket_id", redPocketID, "error", err)
+	}
+	return ephemeral(i18n.T(locale, "bot.slack.claim_success", result.Amount, rp.Token)), nil
+}
+
+func (b *SlackBot) helpText(locale string) string {
+	return i18n.T(locale, "bot.slack.help", strings.Join(i18n.Supported(), ", "))
+}
+
+func ephemeral(text string) *SlackMessage {
+	return &SlackMessage{ResponseType: "ephemeral", Text: text}
+}
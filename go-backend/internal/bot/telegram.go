@@ -2,29 +2,120 @@ package bot
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/model"
 )
 
 // TelegramBot handles Telegram bot integration
 type TelegramBot struct {
 	cfg        *config.Config
+	tokenMu    sync.RWMutex
 	token      string
 	httpClient *http.Client
 	baseURL    string
+	locales    LocaleStore
+	claimer    PocketClaimer
+	announced  AnnouncementStore
+	hunts      HuntGuide
 }
 
+// AnnouncementStore remembers the messages pockets were announced in, so
+// they can be edited when a pocket's supply changes
+type AnnouncementStore interface {
+	SaveAnnouncement(ctx context.Context, a *model.PocketAnnouncement) error
+	ListAnnouncements(ctx context.Context, redPocketID, platform string) ([]*model.PocketAnnouncement, error)
+}
+
+// PocketClaimer claims red pockets for chat users who tap a pocket's claim
+// button. Failure reasons are localized to the locale carried by ctx, and
+// errors may carry a support reference for the user to quote.
+type PocketClaimer interface {
+	ClaimForChat(ctx context.Context, platform, platformUserID, channelID, redPocketID string, member ChatMember) (*ChatClaim, error)
+}
+
+// ChatMember is what a bot vouches for about a claimer in the chat the
+// claim was made from
+type ChatMember struct {
+	Admin bool // owns or administers the chat
+}
+
+// ChatClaim is the outcome of a claim made from a chat
+type ChatClaim struct {
+	Amount    float64 // received; zero when the claim failed
+	Error     string  // why the claim failed
+	RedPocket *model.RedPocket
+	ClaimLink string
+}
+
+// claimErrorText tells a chat user their claim failed, with the support
+// reference of the failure when err has one
+func claimErrorText(locale, key string, err error) string {
+	text := i18n.T(locale, key)
+	var ref interface{ SupportReference() string }
+	if errors.As(err, &ref) && ref.SupportReference() != "" {
+		text += "\n" + i18n.T(locale, "error.support_reference", ref.SupportReference())
+	}
+	return text
+}
+
+// HuntGuide tells chat users where they stand in the treasure hunts they
+// have started
+type HuntGuide interface {
+	HuntsForChat(ctx context.Context, platform, platformUserID string) ([]*ChatHunt, error)
+}
+
+// ChatHunt is a chat user's progress through a treasure hunt. Clue and
+// RevealsAt describe the next step; ClaimLink is set once it is revealed.
+type ChatHunt struct {
+	Name       string
+	Step       int // steps claimed
+	TotalSteps int
+	Clue       string
+	RevealsAt  time.Time
+	ClaimLink  string
+}
+
+// Callback data of a pocket's claim button: claimCallbackPrefix + pocket ID
+const claimCallbackPrefix = "claim:"
+
 // TelegramUpdate represents an incoming update from Telegram
 type TelegramUpdate struct {
-	UpdateID int              `json:"update_id"`
-	Message  *TelegramMessage `json:"message,omitempty"`
+	UpdateID      int                    `json:"update_id"`
+	Message       *TelegramMessage       `json:"message,omitempty"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query,omitempty"`
+}
+
+// TelegramCallbackQuery represents a tap on an inline keyboard button
+type TelegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	From    *TelegramUser    `json:"from"`
+	Message *TelegramMessage `json:"message,omitempty"`
+	Data    string           `json:"data,omitempty"`
+}
+
+// TelegramInlineKeyboard is an inline keyboard attached to a message
+type TelegramInlineKeyboard struct {
+	InlineKeyboard [][]TelegramInlineButton `json:"inline_keyboard"`
+}
+
+// TelegramInlineButton is a button of an inline keyboard
+type TelegramInlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data,omitempty"`
+	URL          string `json:"url,omitempty"`
 }
 
 // TelegramMessage represents a Telegram message
@@ -43,6 +134,8 @@ type TelegramUser struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name,omitempty"`
 	Username  string `json:"username,omitempty"`
+	// IETF language tag of the user's Telegram client
+	LanguageCode string `json:"language_code,omitempty"`
 }
 
 // TelegramChat represents a Telegram chat
@@ -53,10 +146,10 @@ type TelegramChat struct {
 }
 
 // NewTelegramBot creates a new Telegram bot instance
-func NewTelegramBot(cfg *config.Config) *TelegramBot {
+func NewTelegramBot(cfg *config.Config, locales LocaleStore) *TelegramBot {
 	token := cfg.TelegramBotToken
 	if token == "" {
-		log.Println("Warning: TELEGRAM_BOT_TOKEN not set")
+		slog.Warn("telegram: TELEGRAM_BOT_TOKEN not set")
 	}
 
 	return &TelegramBot{
@@ -66,32 +159,80 @@ func NewTelegramBot(cfg *config.Config) *TelegramBot {
 			Timeout: 30 * time.Second,
 		},
 		baseURL: "https://api.telegram.org/bot",
+		locales: locales,
 	}
 }
 
+// chatLocale returns the locale for a chat, falling back to the sender's client language
+func (b *TelegramBot) chatLocale(msg *TelegramMessage) string {
+	fallback := ""
+	if msg.From != nil {
+		fallback = msg.From.LanguageCode
+	}
+	return resolveLocale(b.locales, telegramChatKey(msg.Chat.ID), fallback)
+}
+
+// UseClaimer lets chat users claim pockets with the claim button on their
+// announcements. Without a claimer announcements only carry the claim link.
+func (b *TelegramBot) UseClaimer(claimer PocketClaimer) {
+	b.claimer = claimer
+}
+
+// UseAnnouncements records the messages pockets are announced in, and the
+// ones whose claim button is tapped, so RefreshAnnouncements can edit them
+func (b *TelegramBot) UseAnnouncements(store AnnouncementStore) {
+	b.announced = store
+}
+
+// UseHunts answers /hunt with the user's treasure hunt progress
+func (b *TelegramBot) UseHunts(guide HuntGuide) {
+	b.hunts = guide
+}
+
+// SetToken replaces the bot token, as when it is rotated
+func (b *TelegramBot) SetToken(token string) {
+	b.tokenMu.Lock()
+	b.token = token
+	b.tokenMu.Unlock()
+}
+
+func (b *TelegramBot) botToken() string {
+	b.tokenMu.RLock()
+	defer b.tokenMu.RUnlock()
+	return b.token
+}
+
 // IsConfigured returns true if the bot is properly configured
 func (b *TelegramBot) IsConfigured() bool {
-	return b.token != ""
+	return b.botToken() != ""
 }
 
 // SendMessage sends a message to a Telegram chat
 func (b *TelegramBot) SendMessage(chatID int64, text string, parseMode string) error {
-	if !b.IsConfigured() {
-		return fmt.Errorf("telegram bot not configured")
-	}
-
-	payload := map[string]interface{}{
+	return b.call("sendMessage", map[string]interface{}{
 		"chat_id":    chatID,
 		"text":       text,
 		"parse_mode": parseMode,
+	})
+}
+
+// call invokes a Bot API method
+func (b *TelegramBot) call(method string, payload map[string]interface{}) error {
+	return b.callFor(method, payload, nil)
+}
+
+// callFor invokes a Bot API method and decodes its result into out, if given
+func (b *TelegramBot) callFor(method string, payload map[string]interface{}, out interface{}) error {
+	if !b.IsConfigured() {
+		return fmt.Errorf("telegram bot not configured")
 	}
 
 	body, _ := json.Marshal(payload)
-	url := fmt.Sprintf("%s%s/sendMessage", b.baseURL, b.token)
+	url := fmt.Sprintf("%s%s/%s", b.baseURL, b.botToken(), method)
 
 	resp, err := b.httpClient.Post(url, "application/json", bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to call %s: %w", method, err)
 	}
 	defer resp.Body.Close()
 
@@ -99,39 +240,134 @@ func (b *TelegramBot) SendMessage(chatID int64, text string, parseMode string) e
 		respBody, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("telegram API error: %s", string(respBody))
 	}
+	if out == nil {
+		return nil
+	}
 
-	return nil
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	return json.Unmarshal(result.Result, out)
 }
 
-// SendRedPocketNotification sends a red pocket notification to a chat
-func (b *TelegramBot) SendRedPocketNotification(chatID int64, senderName string, amount float64, token string, claimLink string, message string) error {
-	text := fmt.Sprintf(`🧧 *Red Pocket Alert!*
+// isChatAdmin reports whether a user owns or administers a group
+func (b *TelegramBot) isChatAdmin(chatID, userID int64) (bool, error) {
+	if !b.IsConfigured() {
+		return false, fmt.Errorf("telegram bot not configured")
+	}
 
-*%s* sent a red pocket!
+	body, _ := json.Marshal(map[string]interface{}{"chat_id": chatID, "user_id": userID})
+	url := fmt.Sprintf("%s%s/getChatMember", b.baseURL, b.botToken())
+	resp, err := b.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to call getChatMember: %w", err)
+	}
+	defer resp.Body.Close()
 
-💰 Amount: *%.2f %s*
-%s
-[🎁 Claim Now](%s)
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("telegram API error: %s", string(respBody))
+	}
+	var result struct {
+		Result struct {
+			Status string `json:"status"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Result.Status == "creator" || result.Result.Status == "administrator", nil
+}
 
-_Powered by Protocol Bank_`, senderName, amount, token, message, claimLink)
+// SendRedPocketNotification sends a red pocket notification to a chat. Given
+// the pocket's ID, the message carries a claim button too.
+func (b *TelegramBot) SendRedPocketNotification(chatID int64, redPocketID string, senderName string, amount float64, token string, claimLink string, message string) error {
+	locale := resolveLocale(b.locales, telegramChatKey(chatID), "")
+	text := i18n.T(locale, "bot.telegram.red_pocket", senderName, amount, token, message, claimLink)
 
-	return b.SendMessage(chatID, text, "Markdown")
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"text":       text,
+		"parse_mode": "Markdown",
+	}
+	if redPocketID != "" && b.claimer != nil {
+		payload["reply_markup"] = claimKeyboard(locale, redPocketID)
+	}
+	var sent TelegramMessage
+	if err := b.callFor("sendMessage", payload, &sent); err != nil {
+		return err
+	}
+	if redPocketID != "" {
+		b.saveAnnouncement(context.Background(), redPocketID, chatID, sent.MessageID)
+	}
+	return nil
 }
 
-// SendClaimNotification notifies when someone claims a red pocket
-func (b *TelegramBot) SendClaimNotification(chatID int64, claimerName string, amount float64, token string, remaining int) error {
-	text := fmt.Sprintf(`🎉 *%s* claimed a red pocket!
+// saveAnnouncement records that a pocket was announced in a message
+func (b *TelegramBot) saveAnnouncement(ctx context.Context, redPocketID string, chatID int64, messageID int) {
+	if b.announced == nil || messageID == 0 {
+		return
+	}
+	a := &model.PocketAnnouncement{
+		RedPocketID: redPocketID,
+		Platform:    "telegram",
+		ChannelID:   strconv.FormatInt(chatID, 10),
+		MessageID:   strconv.Itoa(messageID),
+	}
+	if err := b.announced.SaveAnnouncement(ctx, a); err != nil {
+		slog.ErrorContext(ctx, "telegram: failed to record announcement", "red_pocket_id", redPocketID, "error", err)
+	}
+}
 
-💰 Received: *%.2f %s*
-📦 Remaining: *%d* pockets
+// RefreshAnnouncements edits every recorded announcement of a pocket to show
+// its amount and what is left, as after a change to its supply
+func (b *TelegramBot) RefreshAnnouncements(ctx context.Context, rp *model.RedPocket, claimLink string) error {
+	if b.announced == nil || !b.IsConfigured() {
+		return nil
+	}
+	announcements, err := b.announced.ListAnnouncements(ctx, rp.ID, "telegram")
+	if err != nil {
+		return fmt.Errorf("failed to list announcements: %w", err)
+	}
+	var failed error
+	for _, a := range announcements {
+		chatID, err := strconv.ParseInt(a.ChannelID, 10, 64)
+		if err != nil {
+			continue
+		}
+		messageID, err := strconv.Atoi(a.MessageID)
+		if err != nil {
+			continue
+		}
+		if err := b.editAnnouncement(chatID, messageID, rp, claimLink); err != nil {
+			failed = err
+		}
+	}
+	return failed
+}
 
-_Powered by Protocol Bank_`, claimerName, amount, token, remaining)
+func claimKeyboard(locale, redPocketID string) *TelegramInlineKeyboard {
+	return &TelegramInlineKeyboard{InlineKeyboard: [][]TelegramInlineButton{{
+		{Text: i18n.T(locale, "bot.telegram.claim_button"), CallbackData: claimCallbackPrefix + redPocketID},
+	}}}
+}
+
+// SendClaimNotification notifies when someone claims a red pocket
+func (b *TelegramBot) SendClaimNotification(chatID int64, claimerName string, amount float64, token string, remaining int) error {
+	locale := resolveLocale(b.locales, telegramChatKey(chatID), "")
+	text := i18n.T(locale, "bot.telegram.claimed", claimerName, amount, token, remaining)
 
 	return b.SendMessage(chatID, text, "Markdown")
 }
 
 // HandleWebhook processes incoming webhook updates
-func (b *TelegramBot) HandleWebhook(update *TelegramUpdate) error {
+func (b *TelegramBot) HandleWebhook(ctx context.Context, update *TelegramUpdate) error {
+	if update.CallbackQuery != nil {
+		return b.handleCallback(ctx, update.CallbackQuery)
+	}
 	if update.Message == nil {
 		return nil
 	}
@@ -141,13 +377,106 @@ func (b *TelegramBot) HandleWebhook(update *TelegramUpdate) error {
 
 	// Handle commands
 	if strings.HasPrefix(text, "/") {
-		return b.handleCommand(msg)
+		return b.handleCommand(ctx, msg)
+	}
+
+	return nil
+}
+
+// handleCallback claims the pocket whose claim button was tapped for the
+// user who tapped it, tells them the outcome and updates the announcement
+// with what is left
+func (b *TelegramBot) handleCallback(ctx context.Context, q *TelegramCallbackQuery) error {
+	redPocketID, ok := strings.CutPrefix(q.Data, claimCallbackPrefix)
+	if !ok || b.claimer == nil || q.From == nil || q.From.IsBot {
+		return b.answerCallback(q.ID, "", false)
+	}
+
+	// The answer is for the tapping user, in their client's language
+	locale := i18n.Normalize(q.From.LanguageCode)
+	if locale == "" {
+		locale = i18n.DefaultLocale
+		if q.Message != nil {
+			locale = b.chatLocale(q.Message)
+		}
 	}
 
+	var chatID string
+	var member ChatMember
+	if q.Message != nil && q.Message.Chat != nil {
+		chatID = strconv.FormatInt(q.Message.Chat.ID, 10)
+		if q.Message.Chat.Type == "group" || q.Message.Chat.Type == "supergroup" {
+			admin, err := b.isChatAdmin(q.Message.Chat.ID, q.From.ID)
+			if err != nil {
+				slog.WarnContext(ctx, "telegram: failed to look up chat member", "error", err)
+			}
+			member.Admin = admin
+		}
+	}
+	result, err := b.claimer.ClaimForChat(i18n.WithLocale(ctx, locale), "telegram", strconv.FormatInt(q.From.ID, 10), chatID, redPocketID, member)
+	if err != nil {
+		if answerErr := b.answerCallback(q.ID, claimErrorText(locale, "bot.telegram.claim_error", err), true); answerErr != nil {
+			slog.WarnContext(ctx, "telegram: failed to answer callback", "error", answerErr)
+		}
+		return err
+	}
+	if result.Amount == 0 {
+		if err := b.answerCallback(q.ID, result.Error, true); err != nil {
+			return err
+		}
+	} else {
+		rp := result.RedPocket
+		if err := b.answerCallback(q.ID, i18n.T(locale, "bot.telegram.claim_success", result.Amount, rp.Token), false); err != nil {
+			slog.WarnContext(ctx, "telegram: failed to answer callback", "error", err)
+		}
+	}
+
+	if q.Message != nil && q.Message.Chat != nil {
+		b.saveAnnouncement(ctx, redPocketID, q.Message.Chat.ID, q.Message.MessageID)
+	}
+
+	// Refresh the counts after a claim, and drop the button once the pocket
+	// is no longer open
+	if q.Message != nil && (result.Amount > 0 || result.RedPocket.Status != "active") {
+		if err := b.editAnnouncement(q.Message.Chat.ID, q.Message.MessageID, result.RedPocket, result.ClaimLink); err != nil {
+			slog.WarnContext(ctx, "telegram: failed to update red pocket message", "red_pocket_id", redPocketID, "error", err)
+		}
+	}
 	return nil
 }
 
-func (b *TelegramBot) handleCommand(msg *TelegramMessage) error {
+// editAnnouncement rewrites a pocket's announcement with its remaining count
+func (b *TelegramBot) editAnnouncement(chatID int64, messageID int, rp *model.RedPocket, claimLink string) error {
+	locale := resolveLocale(b.locales, telegramChatKey(chatID), "")
+	text := i18n.T(locale, "bot.telegram.red_pocket", rp.SenderName, rp.Amount, rp.Token, rp.Message, claimLink)
+
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"parse_mode": "Markdown",
+	}
+	if rp.Status == "active" {
+		text += "\n\n" + i18n.T(locale, "bot.telegram.remaining", rp.TotalCount-rp.ClaimedCount, rp.TotalCount)
+		payload["reply_markup"] = claimKeyboard(locale, rp.ID)
+	} else {
+		text += "\n\n" + i18n.T(locale, "bot.telegram.closed", rp.ClaimedCount, rp.TotalCount)
+	}
+	payload["text"] = text
+	return b.call("editMessageText", payload)
+}
+
+// answerCallback stops the client's spinner, showing text as a toast or, with
+// alert, a dialog
+func (b *TelegramBot) answerCallback(queryID, text string, alert bool) error {
+	payload := map[string]interface{}{"callback_query_id": queryID}
+	if text != "" {
+		payload["text"] = text
+		payload["show_alert"] = alert
+	}
+	return b.call("answerCallbackQuery", payload)
+}
+
+func (b *TelegramBot) handleCommand(ctx context.Context, msg *TelegramMessage) error {
 	parts := strings.Fields(msg.Text)
 	if len(parts) == 0 {
 		return nil
@@ -164,76 +493,94 @@ func (b *TelegramBot) handleCommand(msg *TelegramMessage) error {
 		return b.handleCreate(msg)
 	case "/balance":
 		return b.handleBalance(msg)
+	case "/language":
+		return b.handleLanguage(msg, parts[1:])
+	case "/hunt":
+		return b.handleHunt(ctx, msg)
 	default:
 		return nil
 	}
 }
 
 func (b *TelegramBot) handleStart(msg *TelegramMessage) error {
-	text := `🧧 *Welcome to Protocol Bank Red Pocket Bot!*
-
-I can help you create and manage red pockets for your community.
-
-*Commands:*
-/create - Create a new red pocket
-/balance - Check your wallet balance
-/help - Show help message
-
-Visit our dashboard to create campaigns:
-https://redpocket.protocolbanks.com/dashboard`
-
-	return b.SendMessage(msg.Chat.ID, text, "Markdown")
+	return b.SendMessage(msg.Chat.ID, i18n.T(b.chatLocale(msg), "bot.telegram.start"), "Markdown")
 }
 
 func (b *TelegramBot) handleHelp(msg *TelegramMessage) error {
-	text := `🧧 *Red Pocket Bot Help*
-
-*Available Commands:*
-• /start - Start the bot
-• /create - Create a new red pocket
-• /balance - Check wallet balance
-• /help - Show this help
-
-*How to create a red pocket:*
-1. Visit the dashboard
-2. Connect your wallet
-3. Create a campaign
-4. Share the link in your group
-
-*Need support?*
-Contact: @protocolbank_support`
-
-	return b.SendMessage(msg.Chat.ID, text, "Markdown")
+	supported := strings.Join(i18n.Supported(), ", ")
+	return b.SendMessage(msg.Chat.ID, i18n.T(b.chatLocale(msg), "bot.telegram.help", supported), "Markdown")
 }
 
 func (b *TelegramBot) handleCreate(msg *TelegramMessage) error {
-	text := `🧧 *Create a Red Pocket*
-
-To create a red pocket campaign:
+	return b.SendMessage(msg.Chat.ID, i18n.T(b.chatLocale(msg), "bot.telegram.create"), "Markdown")
+}
 
-1. 🔗 Visit: https://redpocket.protocolbanks.com/dashboard
-2. 💳 Connect your wallet
-3. 📝 Fill in campaign details
-4. 💰 Deposit funds
-5. 📤 Share the link here!
+func (b *TelegramBot) handleBalance(msg *TelegramMessage) error {
+	return b.SendMessage(msg.Chat.ID, i18n.T(b.chatLocale(msg), "bot.telegram.balance"), "Markdown")
+}
 
-_Creating red pockets requires a connected wallet for security._`
+// handleHunt lists the sender's treasure hunts with the next step's clue,
+// and its claim link once revealed
+func (b *TelegramBot) handleHunt(ctx context.Context, msg *TelegramMessage) error {
+	locale := b.chatLocale(msg)
+	if b.hunts == nil || msg.From == nil {
+		return b.SendMessage(msg.Chat.ID, i18n.T(locale, "bot.telegram.hunt_none"), "")
+	}
+	hunts, err := b.hunts.HuntsForChat(i18n.WithLocale(ctx, locale), "telegram", strconv.FormatInt(msg.From.ID, 10))
+	if err != nil {
+		return err
+	}
+	if len(hunts) == 0 {
+		return b.SendMessage(msg.Chat.ID, i18n.T(locale, "bot.telegram.hunt_none"), "")
+	}
 
-	return b.SendMessage(msg.Chat.ID, text, "Markdown")
+	var text strings.Builder
+	for i, h := range hunts {
+		if i > 0 {
+			text.WriteString("\n\n")
+		}
+		if h.Step >= h.TotalSteps {
+			text.WriteString(i18n.T(locale, "bot.telegram.hunt_done", h.Name, h.TotalSteps))
+			continue
+		}
+		text.WriteString(i18n.T(locale, "bot.telegram.hunt_progress", h.Name, h.Step, h.TotalSteps))
+		if h.Clue != "" {
+			text.WriteString("\n" + i18n.T(locale, "bot.telegram.hunt_clue", h.Clue))
+		}
+		if h.ClaimLink != "" {
+			text.WriteString("\n" + i18n.T(locale, "bot.telegram.hunt_link", h.ClaimLink))
+		} else {
+			text.WriteString("\n" + i18n.T(locale, "bot.telegram.hunt_reveal", h.RevealsAt.UTC().Format("2006-01-02 15:04 UTC")))
+		}
+	}
+	// Clues are the enterprise's own text, so they go out unformatted
+	return b.SendMessage(msg.Chat.ID, text.String(), "")
 }
 
-func (b *TelegramBot) handleBalance(msg *TelegramMessage) error {
-	text := `💰 *Check Your Balance*
-
-To check your wallet balance:
+// handleLanguage stores the chat's preferred language: /language zh
+func (b *TelegramBot) handleLanguage(msg *TelegramMessage, args []string) error {
+	supported := strings.Join(i18n.Supported(), ", ")
+	if len(args) == 0 || b.locales == nil {
+		return b.SendMessage(msg.Chat.ID, i18n.T(b.chatLocale(msg), "bot.telegram.language_usage", supported), "")
+	}
 
-1. Visit: https://redpocket.protocolbanks.com/dashboard/wallet
-2. Connect your wallet
-3. View your balances across all chains
+	locale := i18n.Normalize(args[0])
+	if locale == "" {
+		return b.SendMessage(msg.Chat.ID, i18n.T(b.chatLocale(msg), "bot.telegram.language_usage", supported), "")
+	}
 
-_Your wallet, your keys, your funds._`
+	if err := b.SetChatLocale(msg.Chat.ID, locale); err != nil {
+		return err
+	}
+	return b.SendMessage(msg.Chat.ID, i18n.T(locale, "bot.telegram.language_set", locale), "Markdown")
+}
 
-	return b.SendMessage(msg.Chat.ID, text, "Markdown")
+// SetChatLocale sets the language used for messages sent to a chat
+func (b *TelegramBot) SetChatLocale(chatID int64, locale string) error {
+	if b.locales == nil {
+		return fmt.Errorf("locale store not configured")
+	}
+	return b.locales.SetChatLocale(context.Background(), telegramChatKey(chatID), locale)
 }
 
 // SetWebhook sets the webhook URL for the bot
@@ -247,7 +594,7 @@ func (b *TelegramBot) SetWebhook(webhookURL string) error {
 	}
 
 	body, _ := json.Marshal(payload)
-	url := fmt.Sprintf("%s%s/setWebhook", b.baseURL, b.token)
+	url := fmt.Sprintf("%s%s/setWebhook", b.baseURL, b.botToken())
 
 	resp, err := b.httpClient.Post(url, "application/json", bytes.NewReader(body))
 	if err != nil {
@@ -269,7 +616,7 @@ func (b *TelegramBot) GetWebhookInfo() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("telegram bot not configured")
 	}
 
-	url := fmt.Sprintf("%s%s/getWebhookInfo", b.baseURL, b.token)
+	url := fmt.Sprintf("%s%s/getWebhookInfo", b.baseURL, b.botToken())
 	resp, err := b.httpClient.Get(url)
 	if err != nil {
 		return nil, err
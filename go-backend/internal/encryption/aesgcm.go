@@ -0,0 +1,109 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AESGCMProvider encrypts with a 256-bit key held in the environment. Retired
+// keys can be kept for decryption while values are rotated to the new one.
+type AESGCMProvider struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewAESGCMProvider takes the current key and any retired keys, each 32 bytes
+// encoded as hex or base64
+func NewAESGCMProvider(currentKey string, oldKeys []string) (*AESGCMProvider, error) {
+	p := &AESGCMProvider{keys: make(map[string]cipher.AEAD)}
+	for i, encoded := range append([]string{currentKey}, oldKeys...) {
+		id, aead, err := parseAESKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("aes key %d: %w", i, err)
+		}
+		if i == 0 {
+			p.current = id
+		}
+		p.keys[id] = aead
+	}
+	return p, nil
+}
+
+func parseAESKey(encoded string) (string, cipher.AEAD, error) {
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		key, err = base64.StdEncoding.DecodeString(encoded)
+	}
+	if err != nil || len(key) != 32 {
+		return "", nil, errors.New("key must be 32 bytes, hex or base64 encoded")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", nil, err
+	}
+	// Identify keys by a fingerprint so the key itself never leaves the process
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4]), aead, nil
+}
+
+// Rotate makes key the current key. The previous keys are kept for
+// decryption until values are re-sealed by the rotation command.
+func (p *AESGCMProvider) Rotate(key string) error {
+	id, aead, err := parseAESKey(key)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[id] = aead
+	p.current = id
+	return nil
+}
+
+func (p *AESGCMProvider) Name() string {
+	return "aesgcm"
+}
+
+func (p *AESGCMProvider) CurrentKeyID() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current
+}
+
+func (p *AESGCMProvider) Encrypt(ctx context.Context, plaintext []byte) (string, []byte, error) {
+	p.mu.RLock()
+	current, aead := p.current, p.keys[p.current]
+	p.mu.RUnlock()
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return current, aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (p *AESGCMProvider) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	p.mu.RLock()
+	aead, ok := p.keys[keyID]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", keyID)
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
@@ -0,0 +1,65 @@
+package encryption
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/awsjson"
+)
+
+// AWSKMSProvider encrypts with an AWS KMS symmetric key. Credentials come from
+// the standard AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN
+// variables; requests are signed with SigV4.
+type AWSKMSProvider struct {
+	keyID      string
+	region     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+func NewAWSKMSProvider(keyID, region, endpoint string) *AWSKMSProvider {
+	if endpoint == "" {
+		endpoint = "https://kms." + region + ".amazonaws.com"
+	}
+	return &AWSKMSProvider{
+		keyID:    keyID,
+		region:   region,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (p *AWSKMSProvider) Name() string {
+	return "awskms"
+}
+
+func (p *AWSKMSProvider) Encrypt(ctx context.Context, plaintext []byte) (string, []byte, error) {
+	var out struct {
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+		KeyID          string `json:"KeyId"`
+	}
+	in := map[string]interface{}{"KeyId": p.keyID, "Plaintext": plaintext}
+	if err := p.call(ctx, "TrentService.Encrypt", in, &out); err != nil {
+		return "", nil, err
+	}
+	return out.KeyID, out.CiphertextBlob, nil
+}
+
+func (p *AWSKMSProvider) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	in := map[string]interface{}{"KeyId": keyID, "CiphertextBlob": ciphertext}
+	if err := p.call(ctx, "TrentService.Decrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+func (p *AWSKMSProvider) call(ctx context.Context, target string, in, out interface{}) error {
+	return awsjson.Call(ctx, p.httpClient, p.endpoint, p.region, "kms", target, in, out)
+}
@@ -0,0 +1,176 @@
+// Package encryption seals secrets such as wallet private keys before they are
+// written to the database. Providers are pluggable (local AES-GCM key, AWS KMS,
+// GCP KMS, HashiCorp Vault transit) and every sealed value records the
+// provider and key that produced it, so old values stay readable after the
+// active key or provider changes and can be re-sealed by the rotation command.
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// Sealed values look like enc:v1:<provider>:<base64url key id>:<base64 ciphertext>
+const sealedPrefix = "enc:v1:"
+
+var ErrUnknownProvider = errors.New("encryption provider not configured")
+
+// Provider encrypts and decrypts small payloads with a managed key
+type Provider interface {
+	Name() string
+	// Encrypt seals plaintext with the provider's current key and returns the
+	// ID of the key used
+	Encrypt(ctx context.Context, plaintext []byte) (keyID string, ciphertext []byte, err error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KeyRing is implemented by providers that hold the key material themselves
+// and so know which of their keys is current. Remote providers version keys
+// on their side and decrypt any version.
+type KeyRing interface {
+	CurrentKeyID() string
+}
+
+// Encryptor seals values with the active provider and opens values sealed by
+// any configured provider
+type Encryptor struct {
+	active    Provider
+	providers map[string]Provider
+}
+
+// New builds an Encryptor from config. Every provider with credentials is
+// registered for decryption; WALLET_KEY_PROVIDER picks the one that encrypts.
+func New(cfg *config.Config) (*Encryptor, error) {
+	e := &Encryptor{providers: make(map[string]Provider)}
+
+	if cfg.WalletEncryptionKey != "" {
+		p, err := NewAESGCMProvider(cfg.WalletEncryptionKey, splitList(cfg.WalletEncryptionOldKeys))
+		if err != nil {
+			return nil, err
+		}
+		e.providers[p.Name()] = p
+	}
+	if cfg.AWSKMSKeyID != "" {
+		p := NewAWSKMSProvider(cfg.AWSKMSKeyID, cfg.AWSRegion, cfg.AWSKMSEndpoint)
+		e.providers[p.Name()] = p
+	}
+	if cfg.GCPKMSKeyName != "" {
+		p := NewGCPKMSProvider(cfg.GCPKMSKeyName, cfg.GCPAccessToken)
+		e.providers[p.Name()] = p
+	}
+	if cfg.VaultAddr != "" && cfg.VaultTransitKey != "" {
+		p := NewVaultProvider(cfg.VaultAddr, cfg.VaultToken, cfg.VaultTransitMount, cfg.VaultTransitKey)
+		e.providers[p.Name()] = p
+	}
+
+	if cfg.WalletKeyProvider == "" {
+		if cfg.Env == "production" {
+			return nil, errors.New("WALLET_KEY_PROVIDER must be set in production")
+		}
+		slog.Warn("encryption: WALLET_KEY_PROVIDER not set, wallet keys are stored unencrypted")
+		return e, nil
+	}
+	active, ok := e.providers[cfg.WalletKeyProvider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.WalletKeyProvider)
+	}
+	e.active = active
+	return e, nil
+}
+
+// Encrypt seals plaintext with the active provider. Without one the value is
+// returned as is.
+func (e *Encryptor) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	if e.active == nil {
+		return plaintext, nil
+	}
+	keyID, ciphertext, err := e.active.Encrypt(ctx, []byte(plaintext))
+	if err != nil {
+		return "", fmt.Errorf("%s encrypt: %w", e.active.Name(), err)
+	}
+	return sealedPrefix + e.active.Name() + ":" +
+		base64.RawURLEncoding.EncodeToString([]byte(keyID)) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a sealed value. Values written before encryption was enabled
+// are returned unchanged.
+func (e *Encryptor) Decrypt(ctx context.Context, stored string) (string, error) {
+	name, keyID, ciphertext, sealed, err := parseSealed(stored)
+	if err != nil {
+		return "", err
+	}
+	if !sealed {
+		return stored, nil
+	}
+	p, ok := e.providers[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	plaintext, err := p.Decrypt(ctx, keyID, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("%s decrypt: %w", name, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value was not sealed by the active
+// provider's current key
+func (e *Encryptor) NeedsRotation(stored string) bool {
+	if e.active == nil {
+		return false
+	}
+	name, keyID, _, sealed, err := parseSealed(stored)
+	if err != nil || !sealed || name != e.active.Name() {
+		return true
+	}
+	if c, ok := e.active.(KeyRing); ok {
+		return keyID != c.CurrentKeyID()
+	}
+	return false
+}
+
+// RotateAESKey makes key the current WALLET_ENCRYPTION_KEY of the aesgcm
+// provider. Values sealed with earlier keys still open.
+func (e *Encryptor) RotateAESKey(key string) error {
+	p, ok := e.providers["aesgcm"].(*AESGCMProvider)
+	if !ok {
+		return errors.New("aesgcm provider is not configured")
+	}
+	return p.Rotate(key)
+}
+
+func parseSealed(stored string) (name, keyID string, ciphertext []byte, sealed bool, err error) {
+	if !strings.HasPrefix(stored, sealedPrefix) {
+		return "", "", nil, false, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(stored, sealedPrefix), ":", 3)
+	if len(parts) != 3 {
+		return "", "", nil, false, errors.New("malformed sealed value")
+	}
+	rawKeyID, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", "", nil, false, fmt.Errorf("malformed key id: %w", err)
+	}
+	ciphertext, err = base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", "", nil, false, fmt.Errorf("malformed ciphertext: %w", err)
+	}
+	return parts[0], string(rawKeyID), ciphertext, true, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProvider encrypts with a HashiCorp Vault transit key. Vault versions
+// the key itself, and its ciphertext (vault:vN:...) carries the version.
+type VaultProvider struct {
+	addr       string
+	token      string
+	mount      string
+	key        string
+	httpClient *http.Client
+}
+
+func NewVaultProvider(addr, token, mount, key string) *VaultProvider {
+	if mount == "" {
+		mount = "transit"
+	}
+	return &VaultProvider{
+		addr:  strings.TrimRight(addr, "/"),
+		token: token,
+		mount: strings.Trim(mount, "/"),
+		key:   key,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+func (p *VaultProvider) Encrypt(ctx context.Context, plaintext []byte) (string, []byte, error) {
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	in := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}
+	if err := p.call(ctx, "encrypt", in, &out); err != nil {
+		return "", nil, err
+	}
+	return p.key, []byte(out.Data.Ciphertext), nil
+}
+
+func (p *VaultProvider) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := p.call(ctx, "decrypt", map[string]string{"ciphertext": string(ciphertext)}, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Data.Plaintext)
+}
+
+func (p *VaultProvider) call(ctx context.Context, op string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", p.addr, p.mount, op, p.key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("vault error %d: %s", resp.StatusCode, string(respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
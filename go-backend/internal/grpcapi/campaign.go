@@ -0,0 +1,112 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+	redpocketv1 "github.com/protocolbank/redpocket-backend/proto/redpocket/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type campaignServer struct {
+	redpocketv1.UnimplementedCampaignServiceServer
+	svc *service.CampaignService
+}
+
+func (s *campaignServer) CreateCampaign(ctx context.Context, req *redpocketv1.CreateCampaignRequest) (*redpocketv1.Campaign, error) {
+	create := &service.CreateCampaignRequest{
+		EnterpriseID: enterpriseID(ctx),
+		Name:         req.Name,
+		Description:  req.Description,
+		TotalBudget:  req.TotalBudget,
+		Token:        req.Token,
+		TokenAddress: req.TokenAddress,
+		Platform:     req.Platform,
+		Tag:          req.Tag,
+		PayoutMode:   req.PayoutMode,
+	}
+	if err := validate(create); err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.svc.Create(ctx, create)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return campaignToProto(campaign), nil
+}
+
+func (s *campaignServer) GetCampaign(ctx context.Context, req *redpocketv1.GetCampaignRequest) (*redpocketv1.Campaign, error) {
+	campaign, err := s.svc.Get(ctx, req.Id)
+	if err != nil {
+		return nil, statusError(ctx, service.ErrCampaignNotFound)
+	}
+	return campaignToProto(campaign), nil
+}
+
+func (s *campaignServer) ListCampaigns(ctx context.Context, req *redpocketv1.ListCampaignsRequest) (*redpocketv1.ListCampaignsResponse, error) {
+	campaigns, total, err := s.svc.List(ctx, enterpriseID(ctx), int(req.Page), int(req.Limit))
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	resp := &redpocketv1.ListCampaignsResponse{Campaigns: make([]*redpocketv1.Campaign, 0, len(campaigns)), Total: total}
+	for _, c := range campaigns {
+		resp.Campaigns = append(resp.Campaigns, campaignToProto(c))
+	}
+	return resp, nil
+}
+
+func (s *campaignServer) UpdateCampaignStatus(ctx context.Context, req *redpocketv1.UpdateCampaignStatusRequest) (*redpocketv1.Campaign, error) {
+	if req.Status == "" {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+	if _, err := s.svc.Get(ctx, req.Id); err != nil {
+		return nil, statusError(ctx, service.ErrCampaignNotFound)
+	}
+	if err := s.svc.UpdateStatus(ctx, req.Id, req.Status); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return s.GetCampaign(ctx, &redpocketv1.GetCampaignRequest{Id: req.Id})
+}
+
+func (s *campaignServer) GetAnalytics(ctx context.Context, req *redpocketv1.GetAnalyticsRequest) (*redpocketv1.CampaignAnalytics, error) {
+	a, err := s.svc.GetAnalytics(ctx, enterpriseID(ctx))
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &redpocketv1.CampaignAnalytics{
+		TotalCampaigns:  a.TotalCampaigns,
+		TotalBudget:     a.TotalBudget,
+		TotalSpent:      a.TotalSpent,
+		TotalClaims:     a.TotalClaims,
+		TotalPockets:    a.TotalPockets,
+		ActiveCampaigns: a.ActiveCampaigns,
+		TotalDonated:    a.TotalDonated,
+	}, nil
+}
+
+func campaignToProto(c *model.Campaign) *redpocketv1.Campaign {
+	return &redpocketv1.Campaign{
+		Id:              c.ID,
+		EnterpriseId:    c.EnterpriseID,
+		Name:            c.Name,
+		Description:     c.Description,
+		TotalBudget:     c.TotalBudget,
+		SpentBudget:     c.SpentBudget,
+		Token:           c.Token,
+		TokenAddress:    c.TokenAddress,
+		ChainId:         c.ChainID,
+		Platform:        c.Platform,
+		TotalRedPockets: int32(c.TotalPockets),
+		TotalClaims:     int32(c.TotalClaims),
+		Tag:             c.Tag,
+		Status:          c.Status,
+		CreatedAt:       timestamppb.New(c.CreatedAt),
+		UpdatedAt:       timestamppb.New(c.UpdatedAt),
+		PayoutMode:      c.PayoutMode,
+	}
+}
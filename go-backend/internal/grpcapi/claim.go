@@ -0,0 +1,83 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+	redpocketv1 "github.com/protocolbank/redpocket-backend/proto/redpocket/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type claimServer struct {
+	redpocketv1.UnimplementedClaimServiceServer
+	payouts   *service.PayoutQueue
+	campaigns *service.CampaignService
+}
+
+func (s *claimServer) GetPayoutStatus(ctx context.Context, req *redpocketv1.GetPayoutStatusRequest) (*redpocketv1.PayoutStatus, error) {
+	st, err := s.payouts.Status(ctx, req.ClaimId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	resp := &redpocketv1.PayoutStatus{
+		ClaimId:       st.ClaimID,
+		RedPocketId:   st.RedPocketID,
+		Status:        string(st.Status),
+		Amount:        st.Amount,
+		WalletAddress: st.WalletAddress,
+		TxHash:        st.TxHash,
+		UserOpHash:    st.UserOpHash,
+		Attempts:      int32(st.Attempts),
+		LastError:     st.LastError,
+		NextRetryAt:   timeToProto(st.NextRetryAt),
+		ClaimedAt:     timestamppb.New(st.ClaimedAt),
+		CompletedAt:   timeToProto(st.CompletedAt),
+	}
+	if st.Batch != nil {
+		resp.BatchId = st.Batch.ID
+	}
+	return resp, nil
+}
+
+func (s *claimServer) ListClaims(ctx context.Context, req *redpocketv1.ListClaimsRequest) (*redpocketv1.ListClaimsResponse, error) {
+	var claims []*model.Claim
+	var total int64
+	var err error
+	if req.CampaignId == "" {
+		claims, total, err = s.campaigns.GetAllClaims(ctx, enterpriseID(ctx), int(req.Page), int(req.Limit))
+	} else {
+		claims, total, err = s.campaigns.GetClaims(ctx, req.CampaignId, int(req.Page), int(req.Limit))
+	}
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	resp := &redpocketv1.ListClaimsResponse{Claims: make([]*redpocketv1.Claim, 0, len(claims)), Total: total}
+	for _, c := range claims {
+		resp.Claims = append(resp.Claims, claimToProto(c))
+	}
+	return resp, nil
+}
+
+func claimToProto(c *model.Claim) *redpocketv1.Claim {
+	return &redpocketv1.Claim{
+		Id:              c.ID,
+		RedPocketId:     c.RedPocketID,
+		ClaimerId:       c.ClaimerID,
+		PlatformId:      c.PlatformID,
+		Platform:        c.Platform,
+		WalletAddress:   c.WalletAddress,
+		Amount:          c.Amount,
+		TxHash:          c.TxHash,
+		Status:          string(c.Status),
+		CreatedAt:       timestamppb.New(c.CreatedAt),
+		CompletedAt:     timeToProto(c.CompletedAt),
+		Attempts:        int32(c.Attempts),
+		TermsVersion:    c.TermsVersion,
+		ScreeningResult: c.ScreeningResult,
+		CharityAddress:  c.CharityAddress,
+		Donation:        c.Donation,
+	}
+}
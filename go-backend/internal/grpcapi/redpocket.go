@@ -0,0 +1,278 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+	redpocketv1 "github.com/protocolbank/redpocket-backend/proto/redpocket/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type redPocketServer struct {
+	redpocketv1.UnimplementedRedPocketServiceServer
+	svc *service.RedPocketService
+}
+
+func (s *redPocketServer) CreateRedPocket(ctx context.Context, req *redpocketv1.CreateRedPocketRequest) (*redpocketv1.CreateRedPocketResponse, error) {
+	create := &service.CreateRedPocketRequest{
+		CampaignID:      req.CampaignId,
+		SenderName:      req.SenderName,
+		SenderAvatar:    req.SenderAvatar,
+		Amount:          json.Number(req.Amount),
+		Token:           req.Token,
+		TokenAddress:    req.TokenAddress,
+		Platform:        req.Platform,
+		ChannelID:       req.ChannelId,
+		Message:         req.Message,
+		CoverImage:      req.CoverImage,
+		Tag:             req.Tag,
+		TotalCount:      int(req.TotalCount),
+		IsLuckyDraw:     req.IsLuckyDraw,
+		MinAmount:       req.MinAmount,
+		MaxAmount:       req.MaxAmount,
+		ExpiresIn:       req.ExpiresIn,
+		ClaimPassword:   req.ClaimPassword,
+		CaptchaMode:     req.CaptchaMode,
+		SignedLinks:     req.SignedLinks,
+		StartsAt:        timeFromProto(req.StartsAt),
+		Recurrence:      req.Recurrence,
+		RecurrenceUntil: timeFromProto(req.RecurrenceUntil),
+		Locale:          req.Locale,
+		Eligibility:     eligibilityFromProto(req.Eligibility),
+		ChannelLimits:   channelLimitsFromProto(req.ChannelLimits),
+	}
+	if err := validate(create); err != nil {
+		return nil, err
+	}
+
+	rp, err := s.svc.Create(ctx, enterpriseID(ctx), create)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &redpocketv1.CreateRedPocketResponse{
+		RedPocket: redPocketToProto(rp),
+		ClaimLink: s.svc.ClaimLink(rp),
+		Fees:      pocketFeesToProto(s.svc.Fees(rp)),
+	}, nil
+}
+
+func (s *redPocketServer) GetRedPocket(ctx context.Context, req *redpocketv1.GetRedPocketRequest) (*redpocketv1.GetRedPocketResponse, error) {
+	rp, err := s.svc.Get(ctx, req.Id)
+	if err != nil {
+		return nil, statusError(ctx, service.ErrRedPocketNotFound)
+	}
+
+	resp := &redpocketv1.GetRedPocketResponse{RedPocket: redPocketToProto(rp)}
+	if terms := s.svc.Terms(ctx, rp); terms != nil {
+		resp.Terms = &redpocketv1.CampaignTerms{Version: terms.Version, Text: terms.Text}
+	}
+	return resp, nil
+}
+
+func (s *redPocketServer) ClaimRedPocket(ctx context.Context, req *redpocketv1.ClaimRedPocketRequest) (*redpocketv1.ClaimRedPocketResponse, error) {
+	claim := &service.ClaimRequest{
+		RedPocketID:          req.RedPocketId,
+		PlatformID:           req.PlatformId,
+		Platform:             req.Platform,
+		WalletAddress:        req.WalletAddress,
+		Signature:            req.Signature,
+		AcceptedTermsVersion: req.AcceptedTermsVersion,
+		ClientIP:             req.ClientIp,
+		Country:              req.ClientCountry,
+		Password:             req.Password,
+		CaptchaToken:         req.CaptchaToken,
+		LinkToken:            req.LinkToken,
+		DeviceFingerprint:    req.DeviceFingerprint,
+		ChannelID:            req.ChannelId,
+	}
+	if err := validate(claim); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.svc.Claim(ctx, claim)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	if !resp.Success {
+		return nil, claimFailure(resp)
+	}
+	return &redpocketv1.ClaimRedPocketResponse{
+		ClaimId:       resp.ClaimID,
+		ClaimedAmount: resp.ClaimedAmount,
+		Donated:       resp.Donated,
+		Fee:           resp.Fee,
+		Multiplier:    resp.Multiplier,
+		WalletAddress: resp.WalletAddress,
+		Status:        resp.Status,
+	}, nil
+}
+
+// claimFailure converts a refused claim, already localized by the service,
+// to a gRPC status
+func claimFailure(resp *service.ClaimResponse) error {
+	code := codes.FailedPrecondition
+	switch resp.ErrorCode {
+	case service.ErrorCode(service.ErrRedPocketNotFound):
+		code = codes.NotFound
+	case service.ErrorCode(service.ErrClaimLockFailed):
+		code = codes.Aborted
+	case service.ErrorCode(service.ErrClaimBlocked):
+		code = codes.PermissionDenied
+	case service.ErrorCode(service.ErrClaimRateLimited):
+		code = codes.ResourceExhausted
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   resp.ErrorCode,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"reference": resp.Reference},
+	}
+	if resp.Captcha != "" {
+		// The CAPTCHA fraud scoring asks for, for the client to render
+		info.Metadata["captcha"] = resp.Captcha
+		info.Metadata["captchaSiteKey"] = resp.CaptchaSiteKey
+	}
+	st := status.New(code, resp.Error)
+	if withInfo, err := st.WithDetails(info); err == nil {
+		st = withInfo
+	}
+	if resp.RetryAfter > 0 {
+		retry := &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(resp.RetryAfter) * time.Second)}
+		if withRetry, err := st.WithDetails(retry); err == nil {
+			st = withRetry
+		}
+	}
+	return st.Err()
+}
+
+func redPocketToProto(rp *model.RedPocket) *redpocketv1.RedPocket {
+	return &redpocketv1.RedPocket{
+		Id:                rp.ID,
+		CampaignId:        rp.CampaignID,
+		SenderName:        rp.SenderName,
+		SenderAvatar:      rp.SenderAvatar,
+		Amount:            rp.Amount,
+		RemainingAmount:   rp.RemainingAmount,
+		Token:             rp.Token,
+		TokenAddress:      rp.TokenAddress,
+		ChainId:           rp.ChainID,
+		Platform:          rp.Platform,
+		ChannelId:         rp.ChannelID,
+		Message:           rp.Message,
+		Tag:               rp.Tag,
+		TotalCount:        int32(rp.TotalCount),
+		ClaimedCount:      int32(rp.ClaimedCount),
+		IsLuckyDraw:       rp.IsLuckyDraw,
+		MinAmount:         rp.MinAmount,
+		MaxAmount:         rp.MaxAmount,
+		ExpiresAt:         timestamppb.New(rp.ExpiresAt),
+		CreatedAt:         timestamppb.New(rp.CreatedAt),
+		Status:            string(rp.Status),
+		CoverImage:        rp.CoverImage,
+		PasswordProtected: rp.PasswordProtected(),
+		CaptchaMode:       rp.CaptchaMode,
+		StartsAt:          timeToProto(rp.StartsAt),
+		Recurrence:        rp.Recurrence,
+		RecurrenceUntil:   timeToProto(rp.RecurrenceUntil),
+		SeriesId:          rp.SeriesID,
+		Locale:            rp.Locale,
+		Decimals:          int32(rp.Decimals),
+		AmountUnits:       rp.AmountUnits.String(),
+		RemainingUnits:    rp.RemainingUnits.String(),
+		SignedLinks:       rp.SignedLinks,
+		ChannelLimits:     channelLimitsToProto(rp.ChannelLimits),
+	}
+}
+
+func eligibilityFromProto(rules []*redpocketv1.EligibilityRule) []service.EligibilityRuleRequest {
+	if len(rules) == 0 {
+		return nil
+	}
+	out := make([]service.EligibilityRuleRequest, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, service.EligibilityRuleRequest{
+			Type: r.Type,
+			Params: model.EligibilityParams{
+				Accounts:          r.Accounts,
+				TokenAddress:      r.TokenAddress,
+				MinBalance:        r.MinBalance,
+				MinAccountAgeDays: int(r.MinAccountAgeDays),
+				AllowCountries:    r.AllowCountries,
+				BlockCountries:    r.BlockCountries,
+				Days:              r.Days,
+				StartTime:         r.StartTime,
+				EndTime:           r.EndTime,
+				Timezone:          r.Timezone,
+			},
+		})
+	}
+	return out
+}
+
+func channelLimitsFromProto(limits []*redpocketv1.ChannelLimit) []service.ChannelLimitRequest {
+	if len(limits) == 0 {
+		return nil
+	}
+	out := make([]service.ChannelLimitRequest, 0, len(limits))
+	for _, l := range limits {
+		out = append(out, service.ChannelLimitRequest{
+			ChannelID: l.ChannelId,
+			MaxClaims: int(l.MaxClaims),
+			Budget:    json.Number(l.Budget),
+		})
+	}
+	return out
+}
+
+func channelLimitsToProto(limits []model.ChannelLimit) []*redpocketv1.ChannelLimit {
+	if len(limits) == 0 {
+		return nil
+	}
+	out := make([]*redpocketv1.ChannelLimit, 0, len(limits))
+	for _, l := range limits {
+		out = append(out, &redpocketv1.ChannelLimit{
+			ChannelId:   l.ChannelID,
+			MaxClaims:   int32(l.MaxClaims),
+			Budget:      strconv.FormatFloat(l.Budget, 'f', -1, 64),
+			BudgetUnits: l.BudgetUnits.String(),
+		})
+	}
+	return out
+}
+
+func pocketFeesToProto(f *service.PocketFees) *redpocketv1.PocketFees {
+	return &redpocketv1.PocketFees{
+		Plan:              f.Plan,
+		CreationFee:       f.CreationFee,
+		CreationFeeUnits:  f.CreationFeeUnits.String(),
+		TotalCharged:      f.TotalCharged,
+		TotalChargedUnits: f.TotalChargedUnits.String(),
+		ClaimFee:          f.ClaimFee,
+		ClaimFeeUnits:     f.ClaimFeeUnits.String(),
+		ClaimFeeBps:       int32(f.ClaimFeeBps),
+	}
+}
+
+// timeToProto converts an optional time; nil stays unset
+func timeToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func timeFromProto(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
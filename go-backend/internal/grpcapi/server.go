@@ -0,0 +1,267 @@
+// Package grpcapi serves the backend's service layer over gRPC for other
+// internal services, next to the REST API
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/logging"
+	"github.com/protocolbank/redpocket-backend/internal/middleware"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+	redpocketv1 "github.com/protocolbank/redpocket-backend/proto/redpocket/v1"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain qualifies the REST error codes sent as ErrorInfo reasons
+const errorDomain = "redpocket.protocolbanks.com"
+
+type Services struct {
+	RedPocket *service.RedPocketService
+	Campaign  *service.CampaignService
+	Wallet    *service.WalletService
+	Ledger    *service.LedgerService
+	Payouts   *service.PayoutQueue
+	Readiness *service.Readiness
+}
+
+// New returns a gRPC server with the RedPocket, Claim, Wallet and Campaign
+// services, the standard health service and reflection, tracing each call. Given a TLS
+// configuration, it serves TLS and clients must pass its certificate checks
+// as well as present a token.
+func New(svcs *Services, tokens *service.JWTKeys, tlsCfg *tls.Config) (*grpc.Server, *health.Server) {
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			requestIDInterceptor,
+			localeInterceptor,
+			readOnlyInterceptor(svcs.Readiness),
+			authInterceptor(tokens),
+		),
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	srv := grpc.NewServer(opts...)
+
+	redpocketv1.RegisterRedPocketServiceServer(srv, &redPocketServer{svc: svcs.RedPocket})
+	redpocketv1.RegisterClaimServiceServer(srv, &claimServer{payouts: svcs.Payouts, campaigns: svcs.Campaign})
+	redpocketv1.RegisterWalletServiceServer(srv, &walletServer{svc: svcs.Wallet, ledger: svcs.Ledger})
+	redpocketv1.RegisterCampaignServiceServer(srv, &campaignServer{svc: svcs.Campaign})
+
+	healthSrv := health.NewServer()
+	for name := range srv.GetServiceInfo() {
+		healthSrv.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	reflection.Register(srv)
+	return srv, healthSrv
+}
+
+// requestIDInterceptor tags the call's context with the caller's
+// x-request-id metadata, or a new ID, and a traceparent's trace ID, returns
+// the ID in x-request-id header metadata and logs the call, as the REST
+// RequestID and Logger middleware do
+func requestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := firstValue(md, "x-request-id")
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	ctx = logging.WithRequestID(ctx, id)
+	if traceID := logging.TraceIDFromTraceparent(firstValue(md, "traceparent")); traceID != "" {
+		ctx = logging.WithTraceID(ctx, traceID)
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown {
+		level = slog.LevelError
+	}
+	slog.LogAttrs(ctx, level, "rpc",
+		slog.String("method", info.FullMethod),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+	return resp, err
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// localeInterceptor localizes errors to the caller's accept-language metadata
+func localeInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	locale := i18n.DefaultLocale
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("accept-language"); len(values) > 0 {
+			locale = i18n.Negotiate(values[0])
+		}
+	}
+	return handler(i18n.WithLocale(ctx, locale), req)
+}
+
+// writeMethods are refused while the service is degraded
+var writeMethods = map[string]bool{
+	redpocketv1.RedPocketService_CreateRedPocket_FullMethodName:     true,
+	redpocketv1.RedPocketService_ClaimRedPocket_FullMethodName:      true,
+	redpocketv1.WalletService_Withdraw_FullMethodName:               true,
+	redpocketv1.CampaignService_CreateCampaign_FullMethodName:       true,
+	redpocketv1.CampaignService_UpdateCampaignStatus_FullMethodName: true,
+}
+
+// readOnlyInterceptor refuses writes with Unavailable while a required
+// dependency is down, as the REST API does with 503
+func readOnlyInterceptor(readiness *service.Readiness) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if writeMethods[info.FullMethod] && !readiness.Ready() {
+			return nil, statusError(ctx, service.ErrServiceDegraded)
+		}
+		return handler(ctx, req)
+	}
+}
+
+type enterpriseKey struct{}
+
+type claimerKey struct{}
+
+// authInterceptor requires an enterprise token on the enterprise methods,
+// the same tokens the REST /api/v1/enterprise routes take, and a claimer
+// session token on the methods that move a claimer's funds, as the REST
+// wallet routes do
+func authInterceptor(tokens *service.JWTKeys) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		enterprise, claimer := enterpriseMethod(info.FullMethod), claimerMethods[info.FullMethod]
+		if !enterprise && !claimer {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, i18n.Tc(ctx, "error.unauthorized"))
+		}
+		token := strings.TrimPrefix(values[0], "Bearer ")
+		if claimer {
+			userID, ok := tokens.Claimer(token)
+			if !ok {
+				return nil, status.Error(codes.Unauthenticated, i18n.Tc(ctx, "error.invalid_token"))
+			}
+			ctx = service.WithAccount(ctx, userID)
+			return handler(context.WithValue(ctx, claimerKey{}, userID), req)
+		}
+		enterpriseID, ok := middleware.EnterpriseID(tokens, token)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, i18n.Tc(ctx, "error.invalid_token"))
+		}
+		return handler(context.WithValue(ctx, enterpriseKey{}, enterpriseID), req)
+	}
+}
+
+// claimerMethods move a claimer's funds and need their session token
+var claimerMethods = map[string]bool{
+	redpocketv1.WalletService_Withdraw_FullMethodName: true,
+}
+
+func enterpriseMethod(method string) bool {
+	return strings.HasPrefix(method, "/redpocket.v1.CampaignService/") ||
+		method == redpocketv1.ClaimService_ListClaims_FullMethodName ||
+		method == redpocketv1.RedPocketService_CreateRedPocket_FullMethodName
+}
+
+// enterpriseID returns the enterprise the call was authenticated as
+func enterpriseID(ctx context.Context) string {
+	id, _ := ctx.Value(enterpriseKey{}).(string)
+	return id
+}
+
+// claimerID returns the claimer the call was authenticated as
+func claimerID(ctx context.Context) string {
+	id, _ := ctx.Value(claimerKey{}).(string)
+	return id
+}
+
+// validate applies the request's binding tags, as gin does for REST bodies
+func validate(req interface{}) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// statusError converts a service error to a gRPC status. Coded errors carry
+// their REST error code as the reason of an ErrorInfo detail.
+func statusError(ctx context.Context, err error) error {
+	var coded *service.CodedError
+	errors.As(err, &coded)
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, service.ErrRedPocketNotFound),
+		errors.Is(err, service.ErrClaimNotFound),
+		errors.Is(err, service.ErrCampaignNotFound),
+		errors.Is(err, service.ErrWithdrawalNotFound):
+		code = codes.NotFound
+	case errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrInvalidAddress),
+		errors.Is(err, service.ErrUnsupportedToken),
+		errors.Is(err, service.ErrNativeTokenUnsupported),
+		errors.Is(err, service.ErrInvalidRecurrence),
+		errors.Is(err, service.ErrInvalidStartTime),
+		errors.Is(err, service.ErrCaptchaUnavailable),
+		errors.Is(err, service.ErrClaimLinksUnavailable),
+		errors.Is(err, service.ErrWithdrawalBelowMinimum),
+		service.ErrorCode(err) == "invalid_eligibility_rule",
+		service.ErrorCode(err) == "invalid_channel_limit",
+		service.ErrorCode(err) == "native_share_below_fee":
+		code = codes.InvalidArgument
+	case errors.Is(err, service.ErrWalletNotOwned),
+		errors.Is(err, service.ErrAddressSanctioned):
+		code = codes.PermissionDenied
+	case errors.Is(err, service.ErrClaimLockFailed):
+		code = codes.Aborted
+	case errors.Is(err, service.ErrServiceDegraded),
+		errors.Is(err, service.ErrDownstreamUnavailable):
+		code = codes.Unavailable
+	case coded != nil:
+		code = codes.FailedPrecondition
+	}
+
+	st := status.New(code, service.LocalizedError(ctx, err))
+	info := &errdetails.ErrorInfo{Reason: "internal", Domain: errorDomain}
+	if coded != nil {
+		info.Reason = coded.Code
+	}
+	// The request ID, and a failed claim's reference, the caller can quote
+	// to support
+	info.Metadata = map[string]string{"request_id": logging.RequestID(ctx)}
+	if ref := service.SupportReference(err); ref != "" {
+		info.Metadata["reference"] = ref
+	}
+	if withInfo, err := st.WithDetails(info); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
@@ -0,0 +1,165 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+	redpocketv1 "github.com/protocolbank/redpocket-backend/proto/redpocket/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultChainID is the chain wallets are created on when none is given, as
+// on the REST wallet route
+const defaultChainID = 8453
+
+type walletServer struct {
+	redpocketv1.UnimplementedWalletServiceServer
+	svc    *service.WalletService
+	ledger *service.LedgerService
+}
+
+func (s *walletServer) GetWallet(ctx context.Context, req *redpocketv1.GetWalletRequest) (*redpocketv1.Wallet, error) {
+	chainID := req.ChainId
+	if chainID == 0 {
+		chainID = defaultChainID
+	}
+
+	w, err := s.svc.GetOrCreate(ctx, req.UserId, chainID)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &redpocketv1.Wallet{
+		Id:         w.ID,
+		UserId:     w.UserID,
+		Address:    w.Address,
+		ChainId:    w.ChainID,
+		Type:       w.Type,
+		IsDeployed: w.IsDeployed,
+		CreatedAt:  timestamppb.New(w.CreatedAt),
+	}, nil
+}
+
+func (s *walletServer) GetBalances(ctx context.Context, req *redpocketv1.GetBalancesRequest) (*redpocketv1.GetBalancesResponse, error) {
+	balances, err := s.ledger.Balances(ctx, req.UserId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	resp := &redpocketv1.GetBalancesResponse{Balances: make([]*redpocketv1.LedgerBalance, 0, len(balances))}
+	for _, b := range balances {
+		resp.Balances = append(resp.Balances, &redpocketv1.LedgerBalance{
+			ChainId:      b.ChainID,
+			Token:        b.Token,
+			TokenAddress: b.TokenAddress,
+			Balance:      b.Balance,
+			UpdatedAt:    timestamppb.New(b.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *walletServer) Withdraw(ctx context.Context, req *redpocketv1.WithdrawRequest) (*redpocketv1.WithdrawResponse, error) {
+	// Only the claimer the session token was issued to can withdraw
+	if req.UserId != "" && req.UserId != claimerID(ctx) {
+		return nil, statusError(ctx, service.ErrWalletNotOwned)
+	}
+	withdraw := &service.WithdrawRequest{
+		UserID:        claimerID(ctx),
+		Amount:        req.Amount,
+		Address:       req.Address,
+		Token:         req.Token,
+		ChainID:       req.ChainId,
+		Speed:         req.Speed,
+		Source:        req.Source,
+		WalletAddress: req.WalletAddress,
+		TokenAddress:  req.TokenAddress,
+		TravelRule:    travelRuleFromProto(req.TravelRule),
+	}
+	if err := validate(withdraw); err != nil {
+		return nil, err
+	}
+
+	withdrawal, quote, err := s.ledger.Withdraw(ctx, withdraw)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	resp := &redpocketv1.WithdrawResponse{Withdrawal: withdrawalToProto(withdrawal)}
+	if quote != nil {
+		resp.Quote = &redpocketv1.WithdrawalQuote{
+			Speed:       quote.Speed,
+			Fee:         quote.Fee,
+			NetAmount:   quote.NetAmount,
+			EstimatedAt: timestamppb.New(quote.EstimatedAt),
+		}
+	}
+	return resp, nil
+}
+
+func (s *walletServer) GetWithdrawal(ctx context.Context, req *redpocketv1.GetWithdrawalRequest) (*redpocketv1.Withdrawal, error) {
+	withdrawal, err := s.ledger.GetWithdrawal(ctx, req.Id)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return withdrawalToProto(withdrawal), nil
+}
+
+func withdrawalToProto(w *model.Withdrawal) *redpocketv1.Withdrawal {
+	return &redpocketv1.Withdrawal{
+		Id:           w.ID,
+		UserId:       w.UserID,
+		Source:       w.Source,
+		FromAddress:  w.FromAddress,
+		ChainId:      w.ChainID,
+		Token:        w.Token,
+		TokenAddress: w.TokenAddress,
+		Amount:       w.Amount,
+		ToAddress:    w.ToAddress,
+		Speed:        w.Speed,
+		Fee:          w.Fee,
+		Status:       w.Status,
+		TxHash:       w.TxHash,
+		UserOpHash:   w.UserOpHash,
+		Error:        w.Error,
+		ScheduledAt:  timestamppb.New(w.ScheduledAt),
+		CreatedAt:    timestamppb.New(w.CreatedAt),
+		CompletedAt:  timeToProto(w.CompletedAt),
+	}
+}
+
+func travelRuleFromProto(info *redpocketv1.TravelRuleInfo) *model.TravelRuleInfo {
+	if info == nil {
+		return nil
+	}
+	out := &model.TravelRuleInfo{
+		Originator:  travelRulePartyFromProto(info.Originator),
+		Beneficiary: travelRulePartyFromProto(info.Beneficiary),
+	}
+	if vasp := info.BeneficiaryVasp; vasp != nil {
+		out.BeneficiaryVASP = &model.TravelRuleVASP{Name: vasp.Name, LEI: vasp.Lei, Country: vasp.Country}
+	}
+	return out
+}
+
+func travelRulePartyFromProto(p *redpocketv1.TravelRuleParty) model.TravelRuleParty {
+	if p == nil {
+		return model.TravelRuleParty{}
+	}
+	return model.TravelRuleParty{
+		Type:               p.Type,
+		FirstName:          p.FirstName,
+		LastName:           p.LastName,
+		LegalName:          p.LegalName,
+		StreetName:         p.StreetName,
+		BuildingNumber:     p.BuildingNumber,
+		PostCode:           p.PostCode,
+		TownName:           p.TownName,
+		Country:            p.Country,
+		NationalID:         p.NationalId,
+		NationalIDType:     p.NationalIdType,
+		DateOfBirth:        p.DateOfBirth,
+		PlaceOfBirth:       p.PlaceOfBirth,
+		CountryOfResidence: p.CountryOfResidence,
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type AnalyticsHandler struct {
+	svc *service.AnalyticsService
+}
+
+func NewAnalyticsHandler(svc *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{svc: svc}
+}
+
+// TimeSeries returns the enterprise's activity per ?interval= (hour, day or
+// week) between ?from= and ?to=, for ?campaignId= and broken down ?by=
+// platform or token
+// GET /api/v1/enterprise/analytics/timeseries
+func (h *AnalyticsHandler) TimeSeries(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	series, err := h.svc.TimeSeries(ctx, enterpriseIDFrom(c), &service.TimeSeriesQuery{
+		Interval:   c.Query("interval"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+		CampaignID: c.Query("campaignId"),
+		By:         c.Query("by"),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrInvalidAnalyticsInterval),
+			errors.Is(err, service.ErrInvalidAnalyticsBreakdown),
+			errors.Is(err, service.ErrInvalidReportRange):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"series":  series,
+	})
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type APIKeyHandler struct {
+	svc *service.APIKeyService
+}
+
+func NewAPIKeyHandler(svc *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc}
+}
+
+// List returns the enterprise's API keys
+// GET /api/v1/enterprise/api-keys
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.svc.List(c.Request.Context(), enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"apiKeys": keys,
+	})
+}
+
+// Create issues an API key for signing requests. The response carries the
+// secret, which is not shown again.
+// POST /api/v1/enterprise/api-keys
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.svc.Create(ctx, enterpriseIDFrom(c), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"apiKey":  key,
+	})
+}
+
+// Revoke stops an API key from authenticating
+// DELETE /api/v1/enterprise/api-keys/:id
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := h.svc.Revoke(ctx, enterpriseIDFrom(c), c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+type EnterpriseKeyHandler struct {
+	keys *service.EnterpriseKeys
+}
+
+func NewEnterpriseKeyHandler(keys *service.EnterpriseKeys) *EnterpriseKeyHandler {
+	return &EnterpriseKeyHandler{keys: keys}
+}
+
+// Get reports whether the enterprise has an X-API-Key, when it was created
+// and until when a rotated one still works
+// GET /api/v1/enterprise/api-key
+func (h *EnterpriseKeyHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	e, err := h.keys.Get(ctx, enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(enterpriseKeyErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":           true,
+		"hasKey":            e.APIKeyCreatedAt != nil,
+		"createdAt":         e.APIKeyCreatedAt,
+		"previousExpiresAt": e.APIKeyPreviousExpiresAt,
+	})
+}
+
+// Create issues the enterprise's X-API-Key. The response carries the key,
+// which is not shown again.
+// POST /api/v1/enterprise/api-key
+func (h *EnterpriseKeyHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	key, err := h.keys.Create(ctx, enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(enterpriseKeyErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"apiKey":  key,
+	})
+}
+
+// Rotate replaces the enterprise's X-API-Key; the replaced key keeps working
+// until apiKey.previousExpiresAt
+// POST /api/v1/enterprise/api-key/rotate
+func (h *EnterpriseKeyHandler) Rotate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	key, err := h.keys.Rotate(ctx, enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(enterpriseKeyErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"apiKey":  key,
+	})
+}
+
+// Revoke stops the enterprise's X-API-Key from authenticating at once
+// DELETE /api/v1/enterprise/api-key
+func (h *EnterpriseKeyHandler) Revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := h.keys.Revoke(ctx, enterpriseIDFrom(c)); err != nil {
+		c.JSON(enterpriseKeyErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func enterpriseKeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrEnterpriseNotFound), errors.Is(err, service.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrAPIKeyExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type AudienceHandler struct {
+	svc *service.AudienceService
+}
+
+func NewAudienceHandler(svc *service.AudienceService) *AudienceHandler {
+	return &AudienceHandler{svc: svc}
+}
+
+// authorize checks the campaign in the path belongs to the calling enterprise
+func (h *AudienceHandler) authorize(c *gin.Context, campaignID string) bool {
+	enterpriseID := "enterprise_default"
+	if id, exists := c.Get("enterpriseId"); exists {
+		enterpriseID = id.(string)
+	}
+
+	if err := h.svc.AuthorizeCampaign(c.Request.Context(), campaignID, enterpriseID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// Upload stores a holder list as the campaign audience
+// POST /api/v1/enterprise/campaigns/:id/audience
+func (h *AudienceHandler) Upload(c *gin.Context) {
+	campaignID := c.Param("id")
+	if !h.authorize(c, campaignID) {
+		return
+	}
+
+	var req service.UploadAudienceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot, err := h.svc.Upload(c.Request.Context(), campaignID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"snapshot": snapshot,
+	})
+}
+
+// Compute starts an on-chain holder snapshot for the campaign audience
+// POST /api/v1/enterprise/campaigns/:id/audience/compute
+func (h *AudienceHandler) Compute(c *gin.Context) {
+	campaignID := c.Param("id")
+	if !h.authorize(c, campaignID) {
+		return
+	}
+
+	var req service.ComputeAudienceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot, err := h.svc.Compute(c.Request.Context(), campaignID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":  true,
+		"snapshot": snapshot,
+	})
+}
+
+// List returns all audience snapshots of a campaign
+// GET /api/v1/enterprise/campaigns/:id/audience
+func (h *AudienceHandler) List(c *gin.Context) {
+	campaignID := c.Param("id")
+	if !h.authorize(c, campaignID) {
+		return
+	}
+
+	snapshots, err := h.svc.ListSnapshots(c.Request.Context(), campaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"snapshots": snapshots,
+	})
+}
+
+// Coverage reports claimed vs eligible addresses for a snapshot
+// GET /api/v1/enterprise/campaigns/:id/audience/:snapshotId/coverage
+func (h *AudienceHandler) Coverage(c *gin.Context) {
+	campaignID := c.Param("id")
+	if !h.authorize(c, campaignID) {
+		return
+	}
+
+	snapshot, err := h.svc.GetSnapshot(c.Request.Context(), c.Param("snapshotId"))
+	if err != nil || snapshot.CampaignID != campaignID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshot not found"})
+		return
+	}
+
+	coverage, err := h.svc.GetCoverage(c.Request.Context(), snapshot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"snapshot": snapshot,
+		"coverage": coverage,
+	})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type AuthHandler struct {
+	keys *service.JWTKeys
+}
+
+func NewAuthHandler(keys *service.JWTKeys) *AuthHandler {
+	return &AuthHandler{keys: keys}
+}
+
+// Issue exchanges a request signed with an API key for a short-lived bearer
+// token
+// POST /api/v1/enterprise/token
+func (h *AuthHandler) Issue(c *gin.Context) {
+	if c.GetString("apiKeyId") == "" {
+		err := service.ErrAPIKeyRequired
+		c.JSON(http.StatusForbidden, gin.H{"error": service.LocalizedError(c.Request.Context(), err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	token, expiresAt, err := h.keys.Issue(enterpriseIDFrom(c))
+	if errors.Is(err, service.ErrNoSigningKey) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":     token,
+		"tokenType": "Bearer",
+		"expiresAt": expiresAt,
+	})
+}
+
+// JWKS publishes the public keys that verify enterprise tokens
+// GET /.well-known/jwks.json
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	// A rotated key signs as soon as it is created, so verifiers should only
+	// cache the set briefly and refetch it on an unknown kid
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -1,21 +1,32 @@
 package handler
 
 import (
+	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/protocolbank/redpocket-backend/internal/bot"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
 )
 
 type BotHandler struct {
 	telegramBot *bot.TelegramBot
 	discordBot  *bot.DiscordBot
+	slackBot    *bot.SlackBot
+	slackStore  *repository.SlackInstallationRepository
 }
 
-func NewBotHandler(telegramBot *bot.TelegramBot, discordBot *bot.DiscordBot) *BotHandler {
+func NewBotHandler(telegramBot *bot.TelegramBot, discordBot *bot.DiscordBot, slackBot *bot.SlackBot, slackStore *repository.SlackInstallationRepository) *BotHandler {
 	return &BotHandler{
 		telegramBot: telegramBot,
 		discordBot:  discordBot,
+		slackBot:    slackBot,
+		slackStore:  slackStore,
 	}
 }
 
@@ -28,7 +39,7 @@ func (h *BotHandler) TelegramWebhook(c *gin.Context) {
 		return
 	}
 
-	if err := h.telegramBot.HandleWebhook(&update); err != nil {
+	if err := h.telegramBot.HandleWebhook(c.Request.Context(), &update); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -71,19 +82,20 @@ func (h *BotHandler) GetTelegramWebhookInfo(c *gin.Context) {
 // POST /api/v1/bot/telegram/notify
 func (h *BotHandler) SendTelegramNotification(c *gin.Context) {
 	var req struct {
-		ChatID     int64   `json:"chatId" binding:"required"`
-		SenderName string  `json:"senderName" binding:"required"`
-		Amount     float64 `json:"amount" binding:"required"`
-		Token      string  `json:"token" binding:"required"`
-		ClaimLink  string  `json:"claimLink" binding:"required"`
-		Message    string  `json:"message"`
+		ChatID      int64   `json:"chatId" binding:"required"`
+		RedPocketID string  `json:"redPocketId"` // adds a claim button
+		SenderName  string  `json:"senderName" binding:"required"`
+		Amount      float64 `json:"amount" binding:"required"`
+		Token       string  `json:"token" binding:"required"`
+		ClaimLink   string  `json:"claimLink" binding:"required"`
+		Message     string  `json:"message"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.telegramBot.SendRedPocketNotification(req.ChatID, req.SenderName, req.Amount, req.Token, req.ClaimLink, req.Message); err != nil {
+	if err := h.telegramBot.SendRedPocketNotification(req.ChatID, req.RedPocketID, req.SenderName, req.Amount, req.Token, req.ClaimLink, req.Message); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -120,6 +132,7 @@ func (h *BotHandler) SendDiscordNotification(c *gin.Context) {
 func (h *BotHandler) SendDiscordWebhook(c *gin.Context) {
 	var req struct {
 		WebhookURL string  `json:"webhookUrl" binding:"required"`
+		Locale     string  `json:"locale"`
 		SenderName string  `json:"senderName" binding:"required"`
 		Amount     float64 `json:"amount" binding:"required"`
 		Token      string  `json:"token" binding:"required"`
@@ -131,7 +144,7 @@ func (h *BotHandler) SendDiscordWebhook(c *gin.Context) {
 		return
 	}
 
-	if err := h.discordBot.SendRedPocketWebhook(req.WebhookURL, req.SenderName, req.Amount, req.Token, req.ClaimLink, req.Message); err != nil {
+	if err := h.discordBot.SendRedPocketWebhook(req.WebhookURL, req.Locale, req.SenderName, req.Amount, req.Token, req.ClaimLink, req.Message); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -139,6 +152,52 @@ func (h *BotHandler) SendDiscordWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "webhook sent"})
 }
 
+// SetChatLocale sets the language a bot uses for a Telegram chat or Discord channel
+// POST /api/v1/bot/locale
+func (h *BotHandler) SetChatLocale(c *gin.Context) {
+	var req struct {
+		Platform string `json:"platform" binding:"required,oneof=telegram discord slack"`
+		ChatID   string `json:"chatId" binding:"required"` // Slack: TEAM_ID:CHANNEL_ID
+		Locale   string `json:"locale" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	locale := i18n.Normalize(req.Locale)
+	if locale == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported locale", "supported": i18n.Supported()})
+		return
+	}
+
+	var err error
+	switch req.Platform {
+	case "telegram":
+		chatID, parseErr := strconv.ParseInt(req.ChatID, 10, 64)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid telegram chatId"})
+			return
+		}
+		err = h.telegramBot.SetChatLocale(chatID, locale)
+	case "discord":
+		err = h.discordBot.SetChannelLocale(req.ChatID, locale)
+	case "slack":
+		teamID, channelID, ok := bot.ParseSlackChannel(req.ChatID)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "slack chatId must be TEAM_ID:CHANNEL_ID"})
+			return
+		}
+		err = h.slackBot.SetChannelLocale(teamID, channelID, locale)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "locale set", "locale": locale})
+}
+
 // GetBotStatus returns the status of configured bots
 // GET /api/v1/bot/status
 func (h *BotHandler) GetBotStatus(c *gin.Context) {
@@ -149,5 +208,143 @@ func (h *BotHandler) GetBotStatus(c *gin.Context) {
 		"discord": gin.H{
 			"configured": h.discordBot.IsConfigured(),
 		},
+		"slack": gin.H{
+			"configured": h.slackBot.IsConfigured(),
+		},
 	})
 }
+
+// slackBody reads the body of a request from Slack and checks its signature
+func (h *BotHandler) slackBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+		return nil, false
+	}
+	if err := h.slackBot.VerifyRequest(c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return body, true
+}
+
+// SlackEvents handles the Slack app's event subscriptions
+// POST /api/v1/bot/slack/events
+func (h *BotHandler) SlackEvents(c *gin.Context) {
+	body, ok := h.slackBody(c)
+	if !ok {
+		return
+	}
+	var env bot.SlackEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	challenge, err := h.slackBot.HandleEvent(c.Request.Context(), &env)
+	if err != nil {
+		// Slack retries failed deliveries; an event that failed once is
+		// logged rather than handled again
+		slog.ErrorContext(c.Request.Context(), "slack: failed to handle event", "type", env.Type, "error", err)
+	}
+	if challenge != "" {
+		c.JSON(http.StatusOK, gin.H{"challenge": challenge})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// SlackCommand handles the /redpocket slash command
+// POST /api/v1/bot/slack/commands
+func (h *BotHandler) SlackCommand(c *gin.Context) {
+	body, ok := h.slackBody(c)
+	if !ok {
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.slackBot.HandleCommand(c.Request.Context(), &bot.SlackCommand{
+		TeamID:    form.Get("team_id"),
+		ChannelID: form.Get("channel_id"),
+		UserID:    form.Get("user_id"),
+		Command:   form.Get("command"),
+		Text:      form.Get("text"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// SlackOAuthCallback finishes installing the Slack app into a workspace
+// GET /api/v1/bot/slack/oauth/callback
+func (h *BotHandler) SlackOAuthCallback(c *gin.Context) {
+	if denied := c.Query("error"); denied != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "install cancelled: " + denied})
+		return
+	}
+	in, err := h.slackBot.CompleteInstall(c.Request.Context(), c.Query("code"), c.Query("state"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "installed", "installation": in})
+}
+
+// SendSlackNotification posts a red pocket announcement to a Slack channel
+// POST /api/v1/bot/slack/notify
+func (h *BotHandler) SendSlackNotification(c *gin.Context) {
+	var req struct {
+		TeamID      string  `json:"teamId" binding:"required"`
+		ChannelID   string  `json:"channelId" binding:"required"`
+		RedPocketID string  `json:"redPocketId"` // adds how to claim with /redpocket
+		SenderName  string  `json:"senderName" binding:"required"`
+		Amount      float64 `json:"amount" binding:"required"`
+		Token       string  `json:"token" binding:"required"`
+		ClaimLink   string  `json:"claimLink" binding:"required"`
+		Message     string  `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.slackBot.SendRedPocketNotification(c.Request.Context(), req.TeamID, req.ChannelID, req.RedPocketID, req.SenderName, req.Amount, req.Token, req.ClaimLink, req.Message); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "notification sent"})
+}
+
+// SlackInstallURL returns the link that installs the Slack app into a
+// workspace for the enterprise
+// GET /api/v1/enterprise/slack/install
+func (h *BotHandler) SlackInstallURL(c *gin.Context) {
+	installURL, err := h.slackBot.InstallURL(enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": installURL})
+}
+
+// ListSlackInstallations lists the workspaces the enterprise installed the
+// Slack app in
+// GET /api/v1/enterprise/slack/installations
+func (h *BotHandler) ListSlackInstallations(c *gin.Context) {
+	installs, err := h.slackStore.ListSlackInstallations(c.Request.Context(), enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"installations": installs})
+}
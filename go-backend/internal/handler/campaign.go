@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -31,7 +32,12 @@ func (h *CampaignHandler) Create(c *gin.Context) {
 		req.EnterpriseID = "enterprise_default"
 	}
 
-	campaign, err := h.svc.Create(c.Request.Context(), &req)
+	ctx := c.Request.Context()
+	campaign, err := h.svc.Create(ctx, &req)
+	if errors.Is(err, service.ErrSandboxCreditPayout) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -167,9 +173,14 @@ func (h *CampaignHandler) Analytics(c *gin.Context) {
 		enterpriseID = id.(string)
 	}
 
-	analytics, err := h.svc.GetAnalytics(c.Request.Context(), enterpriseID)
+	ctx := c.Request.Context()
+	analytics, err := h.svc.GetAnalytics(ctx, enterpriseID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrDownstreamUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
 		return
 	}
 
@@ -178,3 +189,238 @@ func (h *CampaignHandler) Analytics(c *gin.Context) {
 		"data":    analytics,
 	})
 }
+
+// GetClaimPage returns the hosted claim page branding of a campaign
+// GET /api/v1/enterprise/campaigns/:id/claim-page
+func (h *CampaignHandler) GetClaimPage(c *gin.Context) {
+	page, err := h.svc.GetClaimPage(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"claimPage": page,
+	})
+}
+
+// UpdateClaimPage sets the logo, colors, copy, post-claim link and
+// disclaimer of the hosted claim page. Takes effect immediately.
+// PUT /api/v1/enterprise/campaigns/:id/claim-page
+func (h *CampaignHandler) UpdateClaimPage(c *gin.Context) {
+	var req service.ClaimPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.svc.UpdateClaimPage(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidClaimPageURL):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"claimPage": page,
+	})
+}
+
+// GetTerms returns the current terms of service of a campaign
+// GET /api/v1/enterprise/campaigns/:id/terms
+func (h *CampaignHandler) GetTerms(c *gin.Context) {
+	terms, err := h.svc.GetTerms(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"terms":   terms,
+	})
+}
+
+// UpdateTerms sets the terms claimers must accept; an empty version removes them
+// PUT /api/v1/enterprise/campaigns/:id/terms
+func (h *CampaignHandler) UpdateTerms(c *gin.Context) {
+	var req service.TermsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	terms, err := h.svc.UpdateTerms(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"terms":   terms,
+	})
+}
+
+// GetVisibility returns whether a campaign is listed on the discovery feed
+// GET /api/v1/enterprise/campaigns/:id/visibility
+func (h *CampaignHandler) GetVisibility(c *gin.Context) {
+	visibility, err := h.svc.GetVisibility(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"visibility": visibility,
+	})
+}
+
+// UpdateVisibility lists or unlists a campaign's active pockets on the
+// discovery feed, optionally without their amounts
+// PUT /api/v1/enterprise/campaigns/:id/visibility
+func (h *CampaignHandler) UpdateVisibility(c *gin.Context) {
+	var req service.VisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	visibility, err := h.svc.UpdateVisibility(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"visibility": visibility,
+	})
+}
+
+// GetCharity returns a campaign's charity round-up and its total donated
+// GET /api/v1/enterprise/campaigns/:id/charity
+func (h *CampaignHandler) GetCharity(c *gin.Context) {
+	charity, err := h.svc.GetCharity(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"charity": charity,
+	})
+}
+
+// UpdateCharity routes a share of every claim of a campaign to a charity
+// address; bps 0 turns it off
+// PUT /api/v1/enterprise/campaigns/:id/charity
+func (h *CampaignHandler) UpdateCharity(c *gin.Context) {
+	var req service.CharityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	charity, err := h.svc.UpdateCharity(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidCharityAddress):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"charity": charity,
+	})
+}
+
+// GetClaimWeights returns the claim amount multipliers of a campaign
+// GET /api/v1/enterprise/campaigns/:id/claim-weights
+func (h *CampaignHandler) GetClaimWeights(c *gin.Context) {
+	weights, err := h.svc.GetClaimWeights(c.Request.Context(), c.Param("id"), enterpriseIDFrom(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"claimWeights": weights,
+	})
+}
+
+// UpdateClaimWeights replaces the claim amount multipliers a campaign gives
+// claimers by Discord role, Telegram admin rights or NFT tier; an empty
+// list removes them
+// PUT /api/v1/enterprise/campaigns/:id/claim-weights
+func (h *CampaignHandler) UpdateClaimWeights(c *gin.Context) {
+	var req service.ClaimWeightsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	weights, err := h.svc.UpdateClaimWeights(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case service.ErrorCode(err) == "invalid_claim_weight":
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"claimWeights": weights,
+	})
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type CampaignRuleHandler struct {
+	svc *service.RuleEngine
+}
+
+func NewCampaignRuleHandler(svc *service.RuleEngine) *CampaignRuleHandler {
+	return &CampaignRuleHandler{svc: svc}
+}
+
+// ruleErrorStatus maps rule errors to their HTTP status
+func ruleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCampaignNotFound), errors.Is(err, service.ErrRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrTooManyRules):
+		return http.StatusConflict
+	case service.ErrorCode(err) == "invalid_rule", service.ErrorCode(err) == "invalid_claim_weight",
+		errors.Is(err, service.ErrInvalidAmount), errors.Is(err, service.ErrUnsupportedToken):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// List returns a campaign's automation rules
+// GET /api/v1/enterprise/campaigns/:id/rules
+func (h *CampaignRuleHandler) List(c *gin.Context) {
+	rules, err := h.svc.List(c.Request.Context(), c.Param("id"), enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"rules":   rules,
+	})
+}
+
+// Create adds an automation rule to a campaign
+// POST /api/v1/enterprise/campaigns/:id/rules
+func (h *CampaignRuleHandler) Create(c *gin.Context) {
+	var req service.RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	rule, err := h.svc.Create(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"rule":    rule,
+	})
+}
+
+// Preview works out what a rule would do now without saving or running it
+// POST /api/v1/enterprise/campaigns/:id/rules/preview
+func (h *CampaignRuleHandler) Preview(c *gin.Context) {
+	var req service.RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	preview, err := h.svc.Preview(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"preview": preview,
+	})
+}
+
+// Get returns a rule with its latest runs
+// GET /api/v1/enterprise/campaigns/:id/rules/:ruleId
+func (h *CampaignRuleHandler) Get(c *gin.Context) {
+	rule, err := h.svc.Get(c.Request.Context(), c.Param("id"), c.Param("ruleId"), enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"rule":    rule,
+	})
+}
+
+// Update replaces a rule's definition
+// PUT /api/v1/enterprise/campaigns/:id/rules/:ruleId
+func (h *CampaignRuleHandler) Update(c *gin.Context) {
+	var req service.RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	rule, err := h.svc.Update(ctx, c.Param("id"), c.Param("ruleId"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"rule":    rule,
+	})
+}
+
+// Delete removes a rule with its runs
+// DELETE /api/v1/enterprise/campaigns/:id/rules/:ruleId
+func (h *CampaignRuleHandler) Delete(c *gin.Context) {
+	err := h.svc.Delete(c.Request.Context(), c.Param("id"), c.Param("ruleId"), enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// DryRun works out what a saved rule would do now without running it
+// POST /api/v1/enterprise/campaigns/:id/rules/:ruleId/dry-run
+func (h *CampaignRuleHandler) DryRun(c *gin.Context) {
+	preview, err := h.svc.DryRun(c.Request.Context(), c.Param("id"), c.Param("ruleId"), enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"preview": preview,
+	})
+}
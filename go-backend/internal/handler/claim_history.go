@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type ClaimHistoryHandler struct {
+	svc *service.ClaimHistoryService
+}
+
+func NewClaimHistoryHandler(svc *service.ClaimHistoryService) *ClaimHistoryHandler {
+	return &ClaimHistoryHandler{svc: svc}
+}
+
+// UserClaims returns a claimer's claims, newest first, with what they have
+// been paid per token
+// GET /api/v1/users/:platform/:platformId/claims
+func (h *ClaimHistoryHandler) UserClaims(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	history, err := h.svc.UserClaims(c.Request.Context(), c.Param("platform"), c.Param("platformId"), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"claims":  history.Claims,
+		"totals":  history.Totals,
+		"total":   history.Total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// Leaderboard returns a red pocket's top claimers, at most ?limit= (10 by
+// default, up to 50), and for a lucky draw its biggest win
+// GET /api/v1/redpocket/:id/leaderboard
+func (h *ClaimHistoryHandler) Leaderboard(c *gin.Context) {
+	ctx := c.Request.Context()
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	board, err := h.svc.Leaderboard(ctx, c.Param("id"), limit)
+	if errors.Is(err, service.ErrRedPocketNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"leaderboard": board,
+	})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type ClaimLinkHandler struct {
+	links *service.ClaimLinks
+}
+
+func NewClaimLinkHandler(links *service.ClaimLinks) *ClaimLinkHandler {
+	return &ClaimLinkHandler{links: links}
+}
+
+// Mint issues one-time claim links, optionally bound to recipients, for one
+// of the calling enterprise's red pockets with signed links
+// POST /api/v1/redpocket/:id/links
+func (h *ClaimLinkHandler) Mint(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.MintClaimLinksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	links, err := h.links.Mint(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidClaimLinkCount),
+			errors.Is(err, service.ErrClaimLinksUnavailable):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrPocketLinksUnsigned),
+			errors.Is(err, service.ErrRedPocketExpired):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"links":   links,
+	})
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type ClaimerSessionHandler struct {
+	sessions *service.ClaimerSessions
+}
+
+func NewClaimerSessionHandler(sessions *service.ClaimerSessions) *ClaimerSessionHandler {
+	return &ClaimerSessionHandler{sessions: sessions}
+}
+
+type LoginCodeRequest struct {
+	Platform   string `json:"platform" binding:"required"`
+	PlatformID string `json:"platformId" binding:"required"`
+}
+
+type ClaimerSessionRequest struct {
+	Platform   string `json:"platform" binding:"required"`
+	PlatformID string `json:"platformId" binding:"required"`
+	Code       string `json:"code" binding:"required"`
+}
+
+// RequestCode has the platform's bot send the account a one-time login code
+// POST /api/v1/claimers/login-code
+func (h *ClaimerSessionHandler) RequestCode(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req LoginCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.sessions.RequestCode(ctx, req.Platform, req.PlatformID); err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, service.ErrLoginCodeThrottled):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, service.ErrLoginPlatformUnsupported):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// CreateSession exchanges a login code for a claimer session token
+// POST /api/v1/claimers/session
+func (h *ClaimerSessionHandler) CreateSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req ClaimerSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, expiresAt, err := h.sessions.VerifyCode(ctx, req.Platform, req.PlatformID, req.Code)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidLoginCode):
+			status = http.StatusUnauthorized
+		case errors.Is(err, service.ErrNoSigningKey):
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":     token,
+		"tokenType": "Bearer",
+		"expiresAt": expiresAt,
+	})
+}
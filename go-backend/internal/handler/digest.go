@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type DigestHandler struct {
+	svc *service.DigestService
+}
+
+func NewDigestHandler(svc *service.DigestService) *DigestHandler {
+	return &DigestHandler{svc: svc}
+}
+
+// Get returns the enterprise's daily digest subscription
+// GET /api/v1/enterprise/digest
+func (h *DigestHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	digest, err := h.svc.Get(ctx, enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"digest":  digest,
+	})
+}
+
+// Update subscribes the enterprise to the daily digest, or changes where
+// and when it is posted
+// PUT /api/v1/enterprise/digest
+func (h *DigestHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.DigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	digest, err := h.svc.Update(ctx, enterpriseIDFrom(c), &req)
+	if err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"digest":  digest,
+	})
+}
+
+// Delete unsubscribes the enterprise from the daily digest
+// DELETE /api/v1/enterprise/digest
+func (h *DigestHandler) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := h.svc.Delete(ctx, enterpriseIDFrom(c)); err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func digestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrDigestNotFound):
+		return http.StatusNotFound
+	case service.ErrorCode(err) == "invalid_digest_channel":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
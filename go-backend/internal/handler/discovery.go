@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type DiscoveryHandler struct {
+	svc *service.DiscoveryService
+}
+
+func NewDiscoveryHandler(svc *service.DiscoveryService) *DiscoveryHandler {
+	return &DiscoveryHandler{svc: svc}
+}
+
+// Discover lists live public pockets for a "live drops" page. Trending order
+// is personalized by platform, language (?lang= or Accept-Language) and, with
+// platformId, the tokens the viewer claimed before.
+// GET /api/v1/discover?sort=trending&platform=telegram&platformId=123&page=1&limit=20
+func (h *DiscoveryHandler) Discover(c *gin.Context) {
+	ctx := c.Request.Context()
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	filter := repository.DiscoveryFilter{
+		Sort:     c.DefaultQuery("sort", "trending"),
+		Platform: c.Query("platform"),
+		Locale:   i18n.FromContext(ctx),
+	}
+	if filter.Platform != "" {
+		filter.PlatformID = c.Query("platformId")
+	}
+
+	pockets, total, err := h.svc.Discover(ctx, filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"pockets": pockets,
+		"total":   total,
+	})
+}
+
+// Share counts a share of a pocket towards its trending score
+// POST /api/v1/redpocket/:id/share
+func (h *DiscoveryHandler) Share(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req struct {
+		Platform   string `json:"platform" binding:"max=32"`
+		PlatformID string `json:"platformId" binding:"max=64"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sharer := "ip:" + c.ClientIP()
+	if req.Platform != "" && req.PlatformID != "" {
+		sharer = req.Platform + ":" + req.PlatformID
+	}
+	if err := h.svc.Share(ctx, c.Param("id"), sharer, req.Platform); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrRedPocketNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
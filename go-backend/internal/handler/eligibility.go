@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type EligibilityHandler struct {
+	svc *service.EligibilityService
+}
+
+func NewEligibilityHandler(svc *service.EligibilityService) *EligibilityHandler {
+	return &EligibilityHandler{svc: svc}
+}
+
+// GetCampaignRules returns the eligibility rules of a campaign
+// GET /api/v1/enterprise/campaigns/:id/eligibility
+func (h *EligibilityHandler) GetCampaignRules(c *gin.Context) {
+	rules, err := h.svc.ListForCampaign(c.Request.Context(), c.Param("id"), enterpriseIDFrom(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"rules":   rules,
+	})
+}
+
+// UpdateCampaignRules replaces the eligibility rules of a campaign; an empty
+// list removes them
+// PUT /api/v1/enterprise/campaigns/:id/eligibility
+func (h *EligibilityHandler) UpdateCampaignRules(c *gin.Context) {
+	var req service.EligibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	rules, err := h.svc.SetForCampaign(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case service.ErrorCode(err) == "invalid_eligibility_rule":
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"rules":   rules,
+	})
+}
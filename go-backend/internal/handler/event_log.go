@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type EventLogHandler struct {
+	events *service.EventLog
+}
+
+func NewEventLogHandler(events *service.EventLog) *EventLogHandler {
+	return &EventLogHandler{events: events}
+}
+
+// List returns the events recorded between ?from= and ?to=, optionally of
+// one ?redPocketId=, a page of ?limit= at a time after ?afterId=
+// GET /admin/events
+func (h *EventLogHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
+	afterID, _ := strconv.ParseInt(c.Query("afterId"), 10, 64)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	events, err := h.events.List(ctx, &service.EventLogQuery{
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		RedPocketID: c.Query("redPocketId"),
+		AfterID:     afterID,
+		Limit:       limit,
+	})
+	if errors.Is(err, service.ErrInvalidEventRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"events":  events,
+	})
+}
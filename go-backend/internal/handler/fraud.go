@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type FraudHandler struct {
+	svc *service.FraudService
+}
+
+func NewFraudHandler(svc *service.FraudService) *FraudHandler {
+	return &FraudHandler{svc: svc}
+}
+
+// ListFlagged lists claim attempts fraud scoring challenged, held or blocked
+// GET /api/v1/enterprise/fraud/claims?status=open
+func (h *FraudHandler) ListFlagged(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != "open" && status != "approved" && status != "rejected" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, approved or rejected"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	assessments, total, err := h.svc.ListFlagged(c.Request.Context(), enterpriseIDFrom(c), status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"claims":  assessments,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// Review approves or rejects a flagged claim attempt. Approving a held
+// claim pays it out now; rejecting it cancels the payout.
+// POST /api/v1/enterprise/fraud/claims/:id/review
+func (h *FraudHandler) Review(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assessment, err := h.svc.Review(ctx, enterpriseIDFrom(c), c.Param("id"), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrAssessmentNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrAssessmentReviewed),
+			errors.Is(err, service.ErrPayoutAlreadyReleased):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"claim":   assessment,
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type FundingHandler struct {
+	svc *service.FundingService
+}
+
+func NewFundingHandler(svc *service.FundingService) *FundingHandler {
+	return &FundingHandler{svc: svc}
+}
+
+// Get returns the campaign's funding wallet, its payout token balance and
+// recent conversions
+// GET /api/v1/enterprise/campaigns/:id/funding
+func (h *FundingHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	funding, err := h.svc.Funding(ctx, enterpriseIDFrom(c), c.Param("id"))
+	if err != nil {
+		c.JSON(fundingErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"funding": funding,
+	})
+}
+
+// Convert swaps other tokens in the funding wallet into the payout token now
+// POST /api/v1/enterprise/campaigns/:id/funding/convert
+func (h *FundingHandler) Convert(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	conversions, err := h.svc.Convert(ctx, enterpriseIDFrom(c), c.Param("id"))
+	if err != nil {
+		c.JSON(fundingErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"conversions": conversions,
+	})
+}
+
+func fundingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCampaignNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSwapUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrUnsupportedToken),
+		errors.Is(err, service.ErrNativeTokenUnsupported):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -7,15 +7,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"github.com/protocolbank/redpocket-backend/internal/service"
 )
 
 type HealthHandler struct {
-	db    *repository.PostgresDB
-	redis *repository.RedisClient
+	db        *repository.PostgresDB
+	redis     *repository.RedisClient
+	cluster   *service.Cluster
+	readiness *service.Readiness
 }
 
-func NewHealthHandler(db *repository.PostgresDB, redis *repository.RedisClient) *HealthHandler {
-	return &HealthHandler{db: db, redis: redis}
+func NewHealthHandler(db *repository.PostgresDB, redis *repository.RedisClient, cluster *service.Cluster, readiness *service.Readiness) *HealthHandler {
+	return &HealthHandler{db: db, redis: redis, cluster: cluster, readiness: readiness}
 }
 
 func (h *HealthHandler) Health(c *gin.Context) {
@@ -33,9 +36,14 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		checks["database"] = "ok"
 	}
 
-	// Check Redis
+	// Check Redis. Without it claims fall back to Postgres if REDIS_FALLBACK
+	// is on, which leaves the service degraded rather than unhealthy.
 	if err := h.redis.Ping(ctx); err != nil {
-		status = "unhealthy"
+		if h.readiness.Required("redis") {
+			status = "unhealthy"
+		} else if status == "healthy" {
+			status = "degraded"
+		}
 		checks["redis"] = "error: " + err.Error()
 	} else {
 		checks["redis"] = "ok"
@@ -46,9 +54,19 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		statusCode = http.StatusServiceUnavailable
 	}
 
+	state, since := h.readiness.Status()
 	c.JSON(statusCode, gin.H{
-		"status":    status,
-		"checks":    checks,
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"status":     status,
+		"checks":     checks,
+		"state":      state,
+		"stateSince": since.UTC().Format(time.RFC3339),
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
 	})
 }
+
+// Replicas reports how the answering replica runs each background feature
+// and whether it is safe to run several replicas
+// GET /health/replicas
+func (h *HealthHandler) Replicas(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cluster.Report())
+}
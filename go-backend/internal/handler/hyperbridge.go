@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/protocolbank/redpocket-backend/internal/service"
@@ -106,7 +109,8 @@ func (h *HyperbridgeHandler) InitiateBridgeTransfer(c *gin.Context) {
 	amount := new(big.Int)
 	amount.SetString(req.Amount, 10)
 
-	status, err := h.hyperbridge.InitiateHyperbridgeTransfer(c.Request.Context(), &service.CrossChainTransferRequest{
+	ctx := c.Request.Context()
+	status, err := h.hyperbridge.InitiateHyperbridgeTransfer(ctx, &service.CrossChainTransferRequest{
 		FromChain: service.ChainID(req.FromChain),
 		ToChain:   service.ChainID(req.ToChain),
 		Asset:     req.Asset,
@@ -115,6 +119,10 @@ func (h *HyperbridgeHandler) InitiateBridgeTransfer(c *gin.Context) {
 		Recipient: req.Recipient,
 	})
 
+	if errors.Is(err, service.ErrWalletNotOwned) {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -138,7 +146,7 @@ func (h *HyperbridgeHandler) InitiateBridgeTransfer(c *gin.Context) {
 func (h *HyperbridgeHandler) GetBridgeStatus(c *gin.Context) {
 	bridgeID := c.Param("bridgeId")
 
-	status, err := h.hyperbridge.GetTransferStatus(bridgeID)
+	status, err := h.hyperbridge.GetTransferStatus(c.Request.Context(), bridgeID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -147,6 +155,74 @@ func (h *HyperbridgeHandler) GetBridgeStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
+// StreamBridgeStatus pushes the status changes of a bridge transfer as
+// server-sent events as the bridge job advances it, starting with a
+// snapshot of its current state. The stream ends once the transfer
+// completes or fails.
+// GET /api/v1/bridge/status/:bridgeId/stream
+func (h *HyperbridgeHandler) StreamBridgeStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	bridgeID := c.Param("bridgeId")
+
+	if _, err := h.hyperbridge.GetTransferStatus(ctx, bridgeID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Subscribe before taking the snapshot so no transition falls in between
+	events, closeSub, err := h.hyperbridge.SubscribeTransfer(ctx, bridgeID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeSub()
+	snapshot, err := h.hyperbridge.TransferSnapshot(ctx, bridgeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Streams outlive the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("snapshot", snapshot)
+	c.Writer.Flush()
+	if bridgeFinished(snapshot.Transfer.Status) {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case ev, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(ev.Type, ev)
+			return !bridgeFinished(ev.Transfer.Status)
+		}
+	})
+}
+
+func bridgeFinished(status string) bool {
+	return status == "completed" || status == "failed"
+}
+
 type AutoBridgeRequest struct {
 	Account     string `json:"account" binding:"required"`
 	Asset       string `json:"asset" binding:"required"`
@@ -166,14 +242,19 @@ func (h *HyperbridgeHandler) AutoBridge(c *gin.Context) {
 	amount := new(big.Int)
 	amount.SetString(req.Amount, 10)
 
+	ctx := c.Request.Context()
 	status, err := h.hyperbridge.AutoBridge(
-		c.Request.Context(),
+		ctx,
 		req.Account,
 		req.Asset,
 		amount,
 		service.ChainID(req.TargetChain),
 	)
 
+	if errors.Is(err, service.ErrWalletNotOwned) {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type LuckyDrawHandler struct {
+	svc *service.LuckyDrawService
+}
+
+func NewLuckyDrawHandler(svc *service.LuckyDrawService) *LuckyDrawHandler {
+	return &LuckyDrawHandler{svc: svc}
+}
+
+// Proof returns what anyone needs to recompute a lucky draw pocket's
+// draws, with its seed once the pocket has closed
+// GET /api/v1/redpocket/:id/fairness
+func (h *LuckyDrawHandler) Proof(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	proof, err := h.svc.Proof(ctx, c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrNotLuckyDraw):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"proof":   proof,
+	})
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+// Max cover image upload size
+const maxCoverImageSize = 5 << 20
+
+type MediaHandler struct {
+	svc *service.IPFSService
+}
+
+func NewMediaHandler(svc *service.IPFSService) *MediaHandler {
+	return &MediaHandler{svc: svc}
+}
+
+// UploadCover pins a red pocket cover image; pass the returned gatewayUrl as coverImage when creating the pocket
+// POST /api/v1/enterprise/media/cover
+func (h *MediaHandler) UploadCover(c *gin.Context) {
+	if !h.svc.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": service.ErrIPFSDisabled.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size > maxCoverImageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cover image must be 5MB or smaller"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxCoverImageSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cover must be an image"})
+		return
+	}
+
+	pin, err := h.svc.Pin(c.Request.Context(), service.PinKindCoverImage, c.PostForm("refId"), fileHeader.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"pin":     pin,
+	})
+}
+
+type PinNFTMetadataRequest struct {
+	RefID    string          `json:"refId"`
+	Name     string          `json:"name" binding:"required"`
+	Metadata json.RawMessage `json:"metadata" binding:"required"`
+}
+
+// PinNFTMetadata pins a claim NFT metadata document (ERC-721/1155 JSON)
+// POST /api/v1/enterprise/media/nft-metadata
+func (h *MediaHandler) PinNFTMetadata(c *gin.Context) {
+	if !h.svc.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": service.ErrIPFSDisabled.Error()})
+		return
+	}
+
+	var req PinNFTMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(req.Metadata, &metadata); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metadata must be a JSON object"})
+		return
+	}
+
+	pin, err := h.svc.Pin(c.Request.Context(), service.PinKindNFTMetadata, req.RefID, req.Name+".json", req.Metadata)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"pin":      pin,
+		"tokenUri": "ipfs://" + pin.CID,
+	})
+}
+
+// GetPin returns a pin's status; ?refresh=true re-checks it with the provider
+// GET /api/v1/enterprise/media/pins/:id
+func (h *MediaHandler) GetPin(c *gin.Context) {
+	get := h.svc.Get
+	if c.Query("refresh") == "true" {
+		get = h.svc.Refresh
+	}
+
+	pin, err := get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pin not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"pin":     pin,
+	})
+}
+
+// ListPins lists pins attached to an object
+// GET /api/v1/enterprise/media/pins?kind=cover_image&refId=rp_xxx
+func (h *MediaHandler) ListPins(c *gin.Context) {
+	kind := c.Query("kind")
+	refID := c.Query("refId")
+	if kind == "" || refID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind and refId are required"})
+		return
+	}
+
+	pins, err := h.svc.ListByRef(c.Request.Context(), kind, refID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"pins":    pins,
+	})
+}
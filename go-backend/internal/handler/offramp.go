@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type OffRampHandler struct {
+	svc *service.OffRampService
+}
+
+func NewOffRampHandler(svc *service.OffRampService) *OffRampHandler {
+	return &OffRampHandler{svc: svc}
+}
+
+// Quote returns how much fiat an amount of tokens cashes out to
+// GET /api/v1/wallet/offramp/quote?amount=10&fiatCurrency=EUR
+func (h *OffRampHandler) Quote(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.OffRampQuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.svc.Quote(ctx, &req)
+	if err != nil {
+		c.JSON(offRampErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"quote":   quote,
+	})
+}
+
+// CreateOrder starts cashing out from the user's wallet and returns the
+// provider widget URL where the user places the order
+// POST /api/v1/wallet/:userId/offramp
+func (h *OffRampHandler) CreateOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.OffRampOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = c.Param("userId")
+
+	withdrawal, widgetURL, err := h.svc.CreateOrder(ctx, &req)
+	if err != nil {
+		c.JSON(offRampErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"withdrawal": withdrawal,
+		"widgetUrl":  widgetURL,
+	})
+}
+
+// Webhook receives order updates from the off-ramp provider
+// POST /api/v1/offramp/webhook
+func (h *OffRampHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.HandleWebhook(c.Request.Context(), body); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidOffRampWebhook):
+			status = http.StatusUnauthorized
+		case errors.Is(err, service.ErrOffRampUnavailable):
+			status = http.StatusNotFound
+		default:
+			slog.ErrorContext(c.Request.Context(), "offramp: webhook failed", "error", err)
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func offRampErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrOffRampUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrOffRampNetworkUnsupported):
+		return http.StatusBadRequest
+	}
+	return withdrawalErrorStatus(err)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type PartnerHandler struct {
+	svc *service.PartnerService
+}
+
+func NewPartnerHandler(svc *service.PartnerService) *PartnerHandler {
+	return &PartnerHandler{svc: svc}
+}
+
+// partnerFrom returns the partner PartnerAuth authenticated
+func partnerFrom(c *gin.Context) *model.Partner {
+	p, _ := c.MustGet("partner").(*model.Partner)
+	return p
+}
+
+// Create records a partner. The response carries its reporting key, which
+// is not shown again.
+// POST /admin/partners
+func (h *PartnerHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.CreatePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partner, key, err := h.svc.Create(ctx, &req)
+	if errors.Is(err, service.ErrPartnerCodeTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"partner": partner,
+		"apiKey":  key,
+	})
+}
+
+// GetEnterprisePartner returns the partner the enterprise is attributed to,
+// null when it has none
+// GET /api/v1/enterprise/partner
+func (h *PartnerHandler) GetEnterprisePartner(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	partner, err := h.svc.EnterprisePartner(ctx, enterpriseIDFrom(c))
+	if errors.Is(err, service.ErrEnterpriseNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"partner": partner,
+	})
+}
+
+// Attach attributes the enterprise to the partner with a partner code
+// POST /api/v1/enterprise/partner
+func (h *PartnerHandler) Attach(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req struct {
+		Code string `json:"code" binding:"required,max=32"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partner, err := h.svc.Attach(ctx, enterpriseIDFrom(c), req.Code)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrPartnerNotFound), errors.Is(err, service.ErrEnterpriseNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPartnerAttached):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"partner": partner,
+	})
+}
+
+// Get returns the authenticated partner
+// GET /api/v1/partner
+func (h *PartnerHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"partner": partnerFrom(c),
+	})
+}
+
+// ListEnterprises returns the enterprises attributed to the partner
+// GET /api/v1/partner/enterprises
+func (h *PartnerHandler) ListEnterprises(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	enterprises, total, err := h.svc.ListEnterprises(c.Request.Context(), partnerFrom(c).ID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"enterprises": enterprises,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+// Statements returns the partner's monthly statements, optionally between
+// ?from= and ?to= months (YYYY-MM)
+// GET /api/v1/partner/statements
+func (h *PartnerHandler) Statements(c *gin.Context) {
+	ctx := c.Request.Context()
+	partner := partnerFrom(c)
+
+	statements, err := h.svc.Statements(ctx, partner, c.Query("from"), c.Query("to"))
+	if errors.Is(err, service.ErrInvalidStatementRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":         true,
+		"revenueShareBps": partner.RevenueShareBps,
+		"statements":      statements,
+	})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type PayoutHandler struct {
+	queue       *service.PayoutQueue
+	diagnostics *service.ClaimDiagnostics
+}
+
+func NewPayoutHandler(queue *service.PayoutQueue, diagnostics *service.ClaimDiagnostics) *PayoutHandler {
+	return &PayoutHandler{queue: queue, diagnostics: diagnostics}
+}
+
+// Get returns the payout status of a claim
+// GET /api/v1/claim/:id
+func (h *PayoutHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	status, err := h.queue.Status(ctx, c.Param("id"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrClaimNotFound) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"payout":  status,
+	})
+}
+
+// Diagnose tells the claimer which step of their claim failed or is pending
+// and what they can do. :id is a claim ID, or the support reference a claim
+// that was never recorded failed with.
+// GET /api/v1/claim/:id/diagnose
+func (h *PayoutHandler) Diagnose(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	diagnosis, err := h.diagnostics.Diagnose(ctx, c.Param("id"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrClaimNotFound) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"diagnosis": diagnosis,
+	})
+}
+
+// Retry queues the dead-lettered payout of one of the enterprise's failed
+// claims again
+// POST /api/v1/enterprise/claims/:id/retry
+func (h *PayoutHandler) Retry(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	status, err := h.queue.Retry(ctx, enterpriseIDFrom(c), c.Param("id"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrClaimNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, service.ErrPayoutNotRetryable):
+			code = http.StatusConflict
+		}
+		c.JSON(code, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"payout":  status,
+	})
+}
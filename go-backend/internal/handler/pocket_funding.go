@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type PocketFundingHandler struct {
+	svc *service.PocketFunding
+}
+
+func NewPocketFundingHandler(svc *service.PocketFunding) *PocketFundingHandler {
+	return &PocketFundingHandler{svc: svc}
+}
+
+// Status returns where a red pocket's deposit goes, how much of it has been
+// confirmed and the transfer that covers the rest
+// GET /api/v1/redpocket/:id/funding-status
+func (h *PocketFundingHandler) Status(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	status, err := h.svc.Status(ctx, c.Param("id"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrRedPocketNotFound) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"funding": status,
+	})
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type ReceiptHandler struct {
+	tracker *service.ReceiptTracker
+}
+
+func NewReceiptHandler(tracker *service.ReceiptTracker) *ReceiptHandler {
+	return &ReceiptHandler{tracker: tracker}
+}
+
+// ListReorgs lists claim payouts that dropped out of the chain and how they were handled
+// GET /api/v1/enterprise/claims/reorgs
+func (h *ReceiptHandler) ListReorgs(c *gin.Context) {
+	enterpriseID := "enterprise_default"
+	if id, exists := c.Get("enterpriseId"); exists {
+		enterpriseID = id.(string)
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	reorgs, err := h.tracker.ListReorgs(c.Request.Context(), enterpriseID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"reorgs":  reorgs,
+		"page":    page,
+		"limit":   limit,
+	})
+}
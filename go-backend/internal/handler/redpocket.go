@@ -1,18 +1,23 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
 	"github.com/protocolbank/redpocket-backend/internal/service"
 )
 
 type RedPocketHandler struct {
-	svc *service.RedPocketService
+	svc           *service.RedPocketService
+	countryHeader string // request header with the client's country, set by the CDN
 }
 
-func NewRedPocketHandler(svc *service.RedPocketService) *RedPocketHandler {
-	return &RedPocketHandler{svc: svc}
+func NewRedPocketHandler(svc *service.RedPocketService, countryHeader string) *RedPocketHandler {
+	return &RedPocketHandler{svc: svc, countryHeader: countryHeader}
 }
 
 func (h *RedPocketHandler) Create(c *gin.Context) {
@@ -22,15 +27,33 @@ func (h *RedPocketHandler) Create(c *gin.Context) {
 		return
 	}
 
-	rp, err := h.svc.Create(c.Request.Context(), &req)
+	rp, err := h.svc.Create(c.Request.Context(), enterpriseIDFrom(c), &req)
+	if errors.Is(err, service.ErrCaptchaUnavailable) ||
+		errors.Is(err, service.ErrClaimLinksUnavailable) ||
+		errors.Is(err, service.ErrInvalidRecurrence) ||
+		errors.Is(err, service.ErrInvalidStartTime) ||
+		errors.Is(err, service.ErrUnsupportedToken) ||
+		errors.Is(err, service.ErrNativeTokenUnsupported) ||
+		errors.Is(err, service.ErrInvalidAmount) ||
+		errors.Is(err, service.ErrBudgetExceeded) ||
+		errors.Is(err, service.ErrCampaignTokenMismatch) ||
+		service.ErrorCode(err) == "native_share_below_fee" ||
+		service.ErrorCode(err) == "invalid_eligibility_rule" ||
+		service.ErrorCode(err) == "invalid_channel_limit" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.LocalizedError(c.Request.Context(), err), "code": service.ErrorCode(err)})
+		return
+	}
+	if errors.Is(err, service.ErrCampaignNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Generate claim link - use the red pocket ID
-	baseURL := "https://protocolbanks.com"
-	claimLink := baseURL + "/claim/" + rp.ID
+	// Generate claim link - use the red pocket ID, signed if it needs to be
+	claimLink := h.svc.ClaimLink(rp)
 
 	// Platform-specific share links
 	shareLinks := map[string]string{
@@ -46,6 +69,7 @@ func (h *RedPocketHandler) Create(c *gin.Context) {
 		"claimLink":  claimLink,
 		"shareLink":  shareLinks[rp.Platform],
 		"embedLink":  claimLink,
+		"fees":       h.svc.Fees(rp),
 	})
 }
 
@@ -56,9 +80,19 @@ func (h *RedPocketHandler) Claim(c *gin.Context) {
 		return
 	}
 
+	req.ClientIP = c.ClientIP()
+	if h.countryHeader != "" {
+		req.Country = c.GetHeader(h.countryHeader)
+	}
+
 	resp, err := h.svc.Claim(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "reference": service.SupportReference(err)})
+		return
+	}
+	if resp.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(resp.RetryAfter))
+		c.JSON(http.StatusTooManyRequests, resp)
 		return
 	}
 
@@ -68,13 +102,185 @@ func (h *RedPocketHandler) Claim(c *gin.Context) {
 func (h *RedPocketHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.Tc(c.Request.Context(), "error.id_required"), "code": "id_required"})
 		return
 	}
 
 	rp, err := h.svc.Get(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "red pocket not found"})
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": service.LocalizedError(c.Request.Context(), service.ErrRedPocketNotFound),
+			"code":  service.ErrorCode(service.ErrRedPocketNotFound),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":         true,
+		"redPocket":       rp,
+		"claimPage":       h.svc.ClaimPage(c.Request.Context(), rp),
+		"terms":           h.svc.Terms(c.Request.Context(), rp),
+		"claimProtection": h.svc.ClaimProtection(rp),
+		"eligibility":     h.svc.Eligibility(c.Request.Context(), rp),
+		"channels":        h.svc.Channels(c.Request.Context(), rp),
+	})
+}
+
+// History returns every status a red pocket has been in and when, with
+// why it changed
+// GET /api/v1/redpocket/:id/history
+func (h *RedPocketHandler) History(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	rp, err := h.svc.GetLatest(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": service.LocalizedError(ctx, service.ErrRedPocketNotFound),
+			"code":  service.ErrorCode(service.ErrRedPocketNotFound),
+		})
+		return
+	}
+	history, err := h.svc.History(ctx, rp.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"redPocketId": rp.ID,
+		"status":      rp.Status,
+		"history":     history,
+	})
+}
+
+// Pause blocks claims on one of the calling enterprise's red pockets until
+// it is resumed
+// POST /api/v1/redpocket/:id/pause
+func (h *RedPocketHandler) Pause(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+// Resume reopens a paused red pocket for claims
+// POST /api/v1/redpocket/:id/resume
+func (h *RedPocketHandler) Resume(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+func (h *RedPocketHandler) setPaused(c *gin.Context, pause bool) {
+	ctx := c.Request.Context()
+
+	// The body is optional
+	var req service.PocketStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change := h.svc.Resume
+	if pause {
+		change = h.svc.Pause
+	}
+	rp, err := change(ctx, c.Param("id"), enterpriseIDFrom(c), req.Reason)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPocketNotPausable),
+			errors.Is(err, service.ErrPocketNotPaused),
+			errors.Is(err, service.ErrRedPocketExpired):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"redPocket": rp,
+	})
+}
+
+// Extend moves one of the calling enterprise's open or paused red pockets'
+// expiry later, to expiresAt or by extendBy seconds
+// POST /api/v1/redpocket/:id/extend
+func (h *RedPocketHandler) Extend(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.ExtendPocketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rp, err := h.svc.Extend(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidExpiry):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrPocketNotExtendable),
+			errors.Is(err, service.ErrRedPocketExpired):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"redPocket": rp,
+	})
+}
+
+// TopUp adds amount and shares to one of the calling enterprise's open,
+// paused or scheduled red pockets, out of its campaign's budget
+// POST /api/v1/redpocket/:id/topup
+func (h *RedPocketHandler) TopUp(c *gin.Context) {
+	h.resize(c, true)
+}
+
+// Shrink takes unclaimed amount and shares back from one of the calling
+// enterprise's open, paused or scheduled red pockets
+// POST /api/v1/redpocket/:id/shrink
+func (h *RedPocketHandler) Shrink(c *gin.Context) {
+	h.resize(c, false)
+}
+
+func (h *RedPocketHandler) resize(c *gin.Context, grow bool) {
+	ctx := c.Request.Context()
+
+	var req service.PocketSupplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change := h.svc.Shrink
+	if grow {
+		change = h.svc.TopUp
+	}
+	rp, err := change(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound),
+			errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidAmount),
+			errors.Is(err, service.ErrInvalidSupplyChange),
+			errors.Is(err, service.ErrBudgetExceeded),
+			service.ErrorCode(err) == "native_share_below_fee":
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrPocketNotResizable),
+			errors.Is(err, service.ErrRedPocketExpired),
+			errors.Is(err, service.ErrSupplyTransferFailed):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
 		return
 	}
 
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type RefundHandler struct {
+	svc *service.RefundService
+}
+
+func NewRefundHandler(svc *service.RefundService) *RefundHandler {
+	return &RefundHandler{svc: svc}
+}
+
+// Refund returns the unclaimed remainder of an expired red pocket to the
+// enterprise that funded it, or retries a failed refund
+// POST /api/v1/redpocket/:id/refund
+func (h *RefundHandler) Refund(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	refund, err := h.svc.Refund(ctx, c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPocketNotExpired), errors.Is(err, service.ErrNothingToRefund):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"refund":  refund,
+	})
+}
+
+// Cancel cancels one of the calling enterprise's open or paused red pockets
+// and queues the refund of its remainder
+// POST /api/v1/redpocket/:id/cancel
+func (h *RefundHandler) Cancel(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// The body is optional
+	var req service.PocketStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rp, refund, err := h.svc.Cancel(ctx, c.Param("id"), enterpriseIDFrom(c), req.Reason)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPocketNotCancellable):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"redPocket": rp,
+		"refund":    refund,
+	})
+}
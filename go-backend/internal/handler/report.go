@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+// reportStreamTimeout bounds how long one streamed report may take to send
+const reportStreamTimeout = 10 * time.Minute
+
+type ReportHandler struct {
+	svc *service.ReportService
+}
+
+func NewReportHandler(svc *service.ReportService) *ReportHandler {
+	return &ReportHandler{svc: svc}
+}
+
+// ExportClaims downloads the enterprise's claims
+// GET /api/v1/enterprise/claims/export
+func (h *ReportHandler) ExportClaims(c *gin.Context) {
+	h.export(c, service.ReportClaims)
+}
+
+// ExportAnalytics downloads the enterprise's activity per day, campaign and
+// token
+// GET /api/v1/enterprise/analytics/export
+func (h *ReportHandler) ExportAnalytics(c *gin.Context) {
+	h.export(c, service.ReportAnalytics)
+}
+
+// export streams a report as ?format= (csv or xlsx) for ?campaignId= between
+// ?from= and ?to=, or answers 202 with a queued export when it is too large
+// to stream
+func (h *ReportHandler) export(c *gin.Context, kind string) {
+	ctx := c.Request.Context()
+	format := c.DefaultQuery("format", service.ReportCSV)
+
+	filter, err := h.svc.Filter(ctx, enterpriseIDFrom(c), c.Query("campaignId"), c.Query("from"), c.Query("to"))
+	if errors.Is(err, service.ErrCampaignNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	export, err := h.svc.Queue(ctx, kind, format, filter)
+	if errors.Is(err, service.ErrInvalidReportFormat) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if export != nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"success": true,
+			"export":  export,
+		})
+		return
+	}
+
+	// Large reports take longer than the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(reportStreamTimeout)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", service.ReportContentType(format))
+	c.Header("X-Accel-Buffering", "no")
+	c.Header("Content-Disposition", `attachment; filename="`+service.ReportFileName(kind, format, time.Now())+`"`)
+	c.Status(http.StatusOK)
+	if _, err := h.svc.Stream(ctx, c.Writer, kind, format, filter); err != nil {
+		// The status is sent; the client sees a truncated file
+		slog.ErrorContext(ctx, "reports: streaming export failed", "kind", kind, "enterprise_id", filter.EnterpriseID, "error", err)
+	}
+}
+
+// GetExport returns a queued export, with a signed download URL once it is
+// ready
+// GET /api/v1/enterprise/exports/:id
+func (h *ReportHandler) GetExport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	export, err := h.svc.GetExport(ctx, c.Param("id"), enterpriseIDFrom(c))
+	if errors.Is(err, service.ErrReportExportNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"export":  export,
+	})
+}
+
+// Download serves a generated export through its signed URL
+// GET /api/v1/exports/:id/download
+func (h *ReportHandler) Download(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	export, content, err := h.svc.Download(ctx, c.Param("id"), c.Query("expires"), c.Query("sig"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidDownloadLink):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrReportExportNotFound):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+service.ReportFileName(export.Kind, export.Format, export.CreatedAt)+`"`)
+	c.Header("Content-Length", strconv.Itoa(len(content)))
+	c.Data(http.StatusOK, service.ReportContentType(export.Format), content)
+}
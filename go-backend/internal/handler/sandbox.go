@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type SandboxHandler struct {
+	svc *service.SandboxService
+}
+
+func NewSandboxHandler(svc *service.SandboxService) *SandboxHandler {
+	return &SandboxHandler{svc: svc}
+}
+
+// CreateEnterprise records a sandbox enterprise. The response carries its
+// API key, which is not shown again.
+// POST /admin/sandbox/enterprises
+func (h *SandboxHandler) CreateEnterprise(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.CreateSandboxEnterpriseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enterprise, key, err := h.svc.CreateEnterprise(ctx, &req)
+	if errors.Is(err, service.ErrEnterpriseEmailTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":    true,
+		"enterprise": enterprise,
+		"apiKey":     key,
+	})
+}
+
+// Faucet adds fake budget to one of the sandbox enterprise's campaigns
+// POST /api/v1/enterprise/sandbox/faucet
+func (h *SandboxHandler) Faucet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.FaucetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign, grant, err := h.svc.Faucet(ctx, enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrSandboxOnly):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidFaucetAmount):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrFaucetLimitReached):
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"campaign": campaign,
+		"grant":    grant,
+	})
+}
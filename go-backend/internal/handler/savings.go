@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type SavingsHandler struct {
+	svc *service.SavingsService
+}
+
+func NewSavingsHandler(svc *service.SavingsService) *SavingsHandler {
+	return &SavingsHandler{svc: svc}
+}
+
+// ListVaults returns the vaults claimers can save their payouts in
+// GET /api/v1/savings/vaults?chainId=8453
+func (h *SavingsHandler) ListVaults(c *gin.Context) {
+	chainID, _ := strconv.ParseInt(c.Query("chainId"), 10, 64)
+
+	vaults, err := h.svc.Vaults(c.Request.Context(), chainID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"vaults":  vaults,
+	})
+}
+
+// Get returns a user's savings vault, positions and recent withdrawals
+// GET /api/v1/wallet/:userId/savings
+func (h *SavingsHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	savings, err := h.svc.Get(ctx, c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"savings": savings,
+	})
+}
+
+// Update sets or clears the vault a user's payouts are saved in. It applies
+// to claims made from now on.
+// PUT /api/v1/wallet/:userId/savings
+func (h *SavingsHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.SavingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = c.Param("userId")
+
+	savings, err := h.svc.Update(ctx, &req)
+	if err != nil {
+		c.JSON(savingsErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"savings": savings,
+	})
+}
+
+// Withdraw moves savings from a vault back to the user's wallet. A
+// withdrawal still in the mempool is returned as pending.
+// POST /api/v1/wallet/:userId/savings/withdraw
+func (h *SavingsHandler) Withdraw(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.VaultWithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = c.Param("userId")
+
+	withdrawal, err := h.svc.Withdraw(ctx, &req)
+	if err != nil {
+		c.JSON(savingsErrorStatus(err), gin.H{
+			"error":      service.LocalizedError(ctx, err),
+			"code":       service.ErrorCode(err),
+			"withdrawal": withdrawal,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"withdrawal": withdrawal,
+	})
+}
+
+func savingsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrWalletNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrVaultNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrVaultUnavailable),
+		errors.Is(err, service.ErrInvalidAmount):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInsufficientBalance):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type ScheduleHandler struct {
+	scheduler *service.PocketScheduler
+}
+
+func NewScheduleHandler(scheduler *service.PocketScheduler) *ScheduleHandler {
+	return &ScheduleHandler{scheduler: scheduler}
+}
+
+// List returns the calling enterprise's red pockets that have not opened yet
+// GET /api/v1/enterprise/scheduled
+func (h *ScheduleHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	pockets, total, err := h.scheduler.ListScheduled(c.Request.Context(), enterpriseIDFrom(c), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"redPockets": pockets,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	})
+}
+
+// Cancel cancels a scheduled red pocket, ending its series if it recurs
+// DELETE /api/v1/enterprise/scheduled/:id
+func (h *ScheduleHandler) Cancel(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := h.scheduler.Cancel(ctx, c.Param("id"), enterpriseIDFrom(c)); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPocketNotScheduled):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+// Keeps idle streams open through proxies that drop silent connections
+const streamHeartbeat = 25 * time.Second
+
+type StreamHandler struct {
+	rpSvc  *service.RedPocketService
+	events *service.PocketEvents
+}
+
+func NewStreamHandler(rpSvc *service.RedPocketService, events *service.PocketEvents) *StreamHandler {
+	return &StreamHandler{rpSvc: rpSvc, events: events}
+}
+
+// Stream pushes live claim events and status changes of a red pocket as
+// server-sent events, starting with a snapshot of its current state. The
+// stream ends once the pocket is depleted, expired or cancelled.
+// GET /api/v1/redpocket/:id/stream
+func (h *StreamHandler) Stream(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	rp, err := h.rpSvc.Get(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": service.LocalizedError(ctx, service.ErrRedPocketNotFound),
+			"code":  service.ErrorCode(service.ErrRedPocketNotFound),
+		})
+		return
+	}
+
+	// Subscribe before taking the snapshot so no claim falls in between
+	events, closeSub, err := h.events.Subscribe(ctx, rp.ID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeSub()
+	if latest, err := h.rpSvc.GetLatest(ctx, rp.ID); err == nil {
+		rp = latest
+	}
+
+	// Streams outlive the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("snapshot", h.events.Snapshot(rp))
+	c.Writer.Flush()
+	if rp.Status != "active" {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case ev, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(ev.Type, ev)
+			return ev.Status == "active"
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type SummaryHandler struct {
+	svc *service.SummaryService
+}
+
+func NewSummaryHandler(svc *service.SummaryService) *SummaryHandler {
+	return &SummaryHandler{svc: svc}
+}
+
+// Get returns the immutable archive of a finished red pocket
+// GET /api/v1/redpocket/:id/summary
+func (h *SummaryHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	archive, err := h.svc.Get(ctx, c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRedPocketNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrPocketNotFinished), errors.Is(err, service.ErrPocketNotSettled):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	// Archives never change, so clients and CDNs may cache them indefinitely
+	etag := `"` + archive.ContentHash + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"summary":     archive.Summary,
+		"contentHash": archive.ContentHash,
+		"ipfsCid":     archive.IPFSCID,
+		"archivedAt":  archive.CreatedAt,
+	})
+}
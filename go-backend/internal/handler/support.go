@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type SupportHandler struct {
+	failures *service.ClaimFailureLog
+}
+
+func NewSupportHandler(failures *service.ClaimFailureLog) *SupportHandler {
+	return &SupportHandler{failures: failures}
+}
+
+// ClaimFailure returns the failed claim a user quoted the support reference of
+// GET /admin/claims/failures/:reference
+func (h *SupportHandler) ClaimFailure(c *gin.Context) {
+	failure, err := h.failures.Lookup(c.Request.Context(), c.Param("reference"))
+	if errors.Is(err, service.ErrClaimFailureNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.LocalizedError(c.Request.Context(), err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"failure": failure,
+	})
+}
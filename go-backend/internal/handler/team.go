@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type TeamHandler struct {
+	svc *service.TeamService
+}
+
+func NewTeamHandler(svc *service.TeamService) *TeamHandler {
+	return &TeamHandler{svc: svc}
+}
+
+// Create forms a team in a campaign with the claimer as its first member
+// POST /api/v1/campaigns/:id/teams
+func (h *TeamHandler) Create(c *gin.Context) {
+	var req service.CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	team, err := h.svc.Create(ctx, c.Param("id"), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrAlreadyInTeam):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"team":    team,
+	})
+}
+
+// Join adds the claimer to the team with a join code
+// POST /api/v1/teams/join
+func (h *TeamHandler) Join(c *gin.Context) {
+	var req service.JoinTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	team, err := h.svc.Join(ctx, &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrTeamNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrAlreadyInTeam):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"team":    team,
+	})
+}
+
+// Leaderboard ranks a campaign's teams by amount claimed
+// GET /api/v1/campaigns/:id/teams
+func (h *TeamHandler) Leaderboard(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	board, err := h.svc.Leaderboard(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"leaderboard": board,
+	})
+}
+
+// Get returns a team's standing and the bonus pools paid to it
+// GET /api/v1/teams/:id
+func (h *TeamHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	team, err := h.svc.Get(ctx, c.Param("id"))
+	if errors.Is(err, service.ErrTeamNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"team":    team,
+	})
+}
+
+// UserTeams returns the teams a claimer is in, with their join codes
+// GET /api/v1/users/:platform/:platformId/teams
+func (h *TeamHandler) UserTeams(c *gin.Context) {
+	teams, err := h.svc.UserTeams(c.Request.Context(), c.Param("platform"), c.Param("platformId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"teams":   teams,
+	})
+}
+
+// ListMilestones returns the team milestones of a campaign
+// GET /api/v1/enterprise/campaigns/:id/team-milestones
+func (h *TeamHandler) ListMilestones(c *gin.Context) {
+	milestones, err := h.svc.ListMilestones(c.Request.Context(), c.Param("id"), enterpriseIDFrom(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"milestones": milestones,
+	})
+}
+
+// CreateMilestone adds a team milestone with its bonus pool to a campaign
+// POST /api/v1/enterprise/campaigns/:id/team-milestones
+func (h *TeamHandler) CreateMilestone(c *gin.Context) {
+	var req service.TeamMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	milestone, err := h.svc.CreateMilestone(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidTeamMilestone):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":   true,
+		"milestone": milestone,
+	})
+}
+
+// DeleteMilestone removes a team milestone no team has reached yet
+// DELETE /api/v1/enterprise/campaigns/:id/team-milestones/:milestoneId
+func (h *TeamHandler) DeleteMilestone(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := h.svc.DeleteMilestone(ctx, c.Param("id"), c.Param("milestoneId"), enterpriseIDFrom(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound), errors.Is(err, service.ErrTeamMilestoneNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrTeamMilestoneAwarded):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
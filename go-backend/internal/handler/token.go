@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type TokenHandler struct {
+	tokens *service.TokenRegistry
+}
+
+func NewTokenHandler(tokens *service.TokenRegistry) *TokenHandler {
+	return &TokenHandler{tokens: tokens}
+}
+
+// List returns the tokens red pockets can be created in on a chain
+// GET /api/v1/tokens?chainId=8453
+func (h *TokenHandler) List(c *gin.Context) {
+	chainID, _ := strconv.ParseInt(c.Query("chainId"), 10, 64)
+
+	tokens, err := h.tokens.List(c.Request.Context(), chainID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"tokens":  tokens,
+	})
+}
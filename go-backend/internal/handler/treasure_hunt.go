@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type TreasureHuntHandler struct {
+	svc *service.TreasureHuntService
+}
+
+func NewTreasureHuntHandler(svc *service.TreasureHuntService) *TreasureHuntHandler {
+	return &TreasureHuntHandler{svc: svc}
+}
+
+// List returns the treasure hunts of a campaign
+// GET /api/v1/enterprise/campaigns/:id/hunts
+func (h *TreasureHuntHandler) List(c *gin.Context) {
+	hunts, err := h.svc.List(c.Request.Context(), c.Param("id"), enterpriseIDFrom(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"hunts":   hunts,
+	})
+}
+
+// Create links red pockets of a campaign into a treasure hunt
+// POST /api/v1/enterprise/campaigns/:id/hunts
+func (h *TreasureHuntHandler) Create(c *gin.Context) {
+	var req service.CreateHuntRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	hunt, err := h.svc.Create(ctx, c.Param("id"), enterpriseIDFrom(c), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case service.ErrorCode(err) == "invalid_hunt":
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"hunt":    hunt,
+	})
+}
+
+// Delete removes a treasure hunt; its pockets take claims from anyone again
+// DELETE /api/v1/enterprise/campaigns/:id/hunts/:huntId
+func (h *TreasureHuntHandler) Delete(c *gin.Context) {
+	err := h.svc.Delete(c.Request.Context(), c.Param("id"), c.Param("huntId"), enterpriseIDFrom(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCampaignNotFound) || errors.Is(err, service.ErrHuntNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// Progress returns the treasure hunts a claimer has started, with the clue
+// of each next step and its claim link once revealed
+// GET /api/v1/users/:platform/:platformId/hunts
+func (h *TreasureHuntHandler) Progress(c *gin.Context) {
+	hunts, err := h.svc.Progress(c.Request.Context(), c.Param("platform"), c.Param("platformId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"hunts":   hunts,
+	})
+}
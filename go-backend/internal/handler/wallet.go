@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -9,11 +10,27 @@ import (
 )
 
 type WalletHandler struct {
-	svc *service.WalletService
+	svc              *service.WalletService
+	ledgerSvc        *service.LedgerService
+	splitSvc         *service.PayoutSplitService
+	overviewSvc      *service.WalletOverviewService
+	consolidationSvc *service.ConsolidationService
 }
 
-func NewWalletHandler(svc *service.WalletService) *WalletHandler {
-	return &WalletHandler{svc: svc}
+func NewWalletHandler(
+	svc *service.WalletService,
+	ledgerSvc *service.LedgerService,
+	splitSvc *service.PayoutSplitService,
+	overviewSvc *service.WalletOverviewService,
+	consolidationSvc *service.ConsolidationService,
+) *WalletHandler {
+	return &WalletHandler{
+		svc:              svc,
+		ledgerSvc:        ledgerSvc,
+		splitSvc:         splitSvc,
+		overviewSvc:      overviewSvc,
+		consolidationSvc: consolidationSvc,
+	}
 }
 
 func (h *WalletHandler) GetOrCreate(c *gin.Context) {
@@ -41,25 +58,325 @@ func (h *WalletHandler) GetOrCreate(c *gin.Context) {
 	})
 }
 
+// Overview lists a user's wallets on every chain with their balances, and
+// their pending and confirmed claims per chain
+// GET /api/v1/wallet/:userId/overview
+func (h *WalletHandler) Overview(c *gin.Context) {
+	overview, err := h.overviewSvc.Overview(c.Request.Context(), c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"overview": overview,
+	})
+}
+
+// Consolidate bridges a user's balances of an asset on all other chains to
+// their wallet on one chain, as one consolidation job
+// POST /api/v1/wallet/:userId/consolidate
+func (h *WalletHandler) Consolidate(c *gin.Context) {
+	var req service.ConsolidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	consolidation, err := h.consolidationSvc.Consolidate(ctx, c.Param("userId"), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrWalletNotOwned):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrAssetNotConsolidatable):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	if consolidation == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success":             true,
+			"consolidationNeeded": false,
+			"message":             "No other chain holds the asset",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":             true,
+		"consolidationNeeded": true,
+		"consolidation":       consolidation,
+	})
+}
+
+// GetConsolidation returns a consolidation job with its aggregate status and
+// the status of each of its transfers
+// GET /api/v1/wallet/consolidation/:id
+func (h *WalletHandler) GetConsolidation(c *gin.Context) {
+	consolidation, err := h.consolidationSvc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrConsolidationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"consolidation": consolidation,
+	})
+}
+
+// Withdraw moves the signed-in claimer's ledger balance, or with
+// source=wallet the balance of their own AA wallet, to an external address
+// POST /api/v1/wallet/withdraw
 func (h *WalletHandler) Withdraw(c *gin.Context) {
-	var req struct {
-		UserID  string  `json:"userId" binding:"required"`
-		Amount  float64 `json:"amount" binding:"required,gt=0"`
-		Address string  `json:"address" binding:"required"`
+	var req service.WithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	req.UserID = c.GetString("claimerId")
 
+	h.withdraw(c, &req)
+}
+
+func (h *WalletHandler) withdraw(c *gin.Context, req *service.WithdrawRequest) {
+	ctx := c.Request.Context()
+
+	withdrawal, quote, err := h.ledgerSvc.Withdraw(ctx, req)
+	if err != nil {
+		c.JSON(withdrawalErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Withdrawal request submitted",
+		"withdrawal": withdrawal,
+		"txHash":     withdrawal.TxHash,
+		"quote":      quote,
+	})
+}
+
+func withdrawalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidAddress),
+		errors.Is(err, service.ErrWithdrawalBelowMinimum),
+		errors.Is(err, service.ErrInstantWithdrawalUnavailable),
+		errors.Is(err, service.ErrUnsupportedToken),
+		errors.Is(err, service.ErrNativeTokenUnsupported),
+		service.ErrorCode(err) == "travel_rule_required",
+		service.ErrorCode(err) == "invalid_travel_rule":
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrWalletNotOwned),
+		errors.Is(err, service.ErrAddressSanctioned):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInsufficientBalance):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// QuoteWithdrawal returns the fee, net amount and timing of a withdrawal at
+// each speed
+// GET /api/v1/wallet/withdraw/quote?amount=10&token=USDC
+func (h *WalletHandler) QuoteWithdrawal(c *gin.Context) {
+	var req service.WithdrawalQuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quotes, err := h.ledgerSvc.Quote(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"quotes":  quotes,
+	})
+}
+
+// Balance returns a user's ledger balances and recent ledger entries
+// GET /api/v1/wallet/:userId/balance
+func (h *WalletHandler) Balance(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.Param("userId")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	balances, err := h.ledgerSvc.Balances(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	entries, total, err := h.ledgerSvc.Entries(ctx, userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"balances": balances,
+		"entries":  entries,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// ListWithdrawals returns a user's withdrawals
+// GET /api/v1/wallet/:userId/withdrawals
+func (h *WalletHandler) ListWithdrawals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	withdrawals, err := h.ledgerSvc.ListWithdrawals(c.Request.Context(), c.Param("userId"), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"withdrawals": withdrawals,
+	})
+}
+
+// GetWithdrawal returns the status of a withdrawal
+// GET /api/v1/wallet/withdrawal/:id
+func (h *WalletHandler) GetWithdrawal(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	withdrawal, err := h.ledgerSvc.GetWithdrawal(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"withdrawal": withdrawal,
+	})
+}
+
+// GetPayoutSplits returns how a user's claim payouts are split
+// GET /api/v1/wallet/:userId/payout-splits
+func (h *WalletHandler) GetPayoutSplits(c *gin.Context) {
+	pref, err := h.splitSvc.Get(c.Request.Context(), c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"preference": pref,
+	})
+}
+
+// UpdatePayoutSplits replaces a user's payout splits. They apply to claims
+// made from now on.
+// PUT /api/v1/wallet/:userId/payout-splits
+func (h *WalletHandler) UpdatePayoutSplits(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.PayoutSplitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.UserID = c.Param("userId")
+
+	pref, err := h.splitSvc.Update(ctx, &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrWalletNotOwned):
+			status = http.StatusForbidden
+		case service.ErrorCode(err) == "invalid_payout_splits":
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"preference": pref,
+	})
+}
+
+func enterpriseIDFrom(c *gin.Context) string {
+	if id, exists := c.Get("enterpriseId"); exists {
+		return id.(string)
+	}
+	return "enterprise_default"
+}
+
+// EnterpriseWithdraw withdraws from the calling enterprise's ledger balance or
+// AA wallet. Large withdrawals carry travel rule information.
+// POST /api/v1/enterprise/withdrawals
+func (h *WalletHandler) EnterpriseWithdraw(c *gin.Context) {
+	enterpriseID := enterpriseIDFrom(c)
 
-	// TODO: Implement withdrawal logic
-	// 1. Verify user owns the wallet
-	// 2. Check balance
-	// 3. Execute transfer to external address
+	req := service.WithdrawRequest{UserID: enterpriseID}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = enterpriseID
+	c.Request = c.Request.WithContext(service.WithAccount(c.Request.Context(), enterpriseID))
+
+	h.withdraw(c, &req)
+}
+
+// ListEnterpriseWithdrawals returns the calling enterprise's withdrawals
+// GET /api/v1/enterprise/withdrawals
+func (h *WalletHandler) ListEnterpriseWithdrawals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	withdrawals, err := h.ledgerSvc.ListWithdrawals(c.Request.Context(), enterpriseIDFrom(c), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"withdrawals": withdrawals,
+	})
+}
+
+// TravelRuleExport returns a withdrawal's originator and beneficiary
+// information as an IVMS101 payload for the counterparty VASP
+// GET /api/v1/enterprise/withdrawals/:id/travel-rule
+func (h *WalletHandler) TravelRuleExport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload, err := h.ledgerSvc.TravelRuleExport(ctx, c.Param("id"), enterpriseIDFrom(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrWithdrawalNotFound) || errors.Is(err, service.ErrTravelRuleNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Withdrawal request submitted",
+		"ivms101": payload,
 	})
 }
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type WebhookHandler struct {
+	svc *service.WebhookService
+}
+
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// List returns the enterprise's webhooks
+// GET /api/v1/enterprise/webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	webhooks, err := h.svc.List(c.Request.Context(), enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"webhooks": webhooks,
+	})
+}
+
+// Create registers a webhook. The response carries the signing secret,
+// which is not shown again.
+// POST /api/v1/enterprise/webhooks
+func (h *WebhookHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.svc.Create(ctx, enterpriseIDFrom(c), &req)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"webhook": webhook,
+	})
+}
+
+// Get returns one webhook
+// GET /api/v1/enterprise/webhooks/:id
+func (h *WebhookHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	webhook, err := h.svc.Get(ctx, enterpriseIDFrom(c), c.Param("id"))
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"webhook": webhook,
+	})
+}
+
+// Update replaces a webhook's URL, events, description and enabled flag,
+// and rotates its secret when a new one is given
+// PUT /api/v1/enterprise/webhooks/:id
+func (h *WebhookHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.svc.Update(ctx, enterpriseIDFrom(c), c.Param("id"), &req)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"webhook": webhook,
+	})
+}
+
+// Delete removes a webhook and its delivery log
+// DELETE /api/v1/enterprise/webhooks/:id
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := h.svc.Delete(ctx, enterpriseIDFrom(c), c.Param("id")); err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// Deliveries returns a webhook's delivery log, newest first
+// GET /api/v1/enterprise/webhooks/:id/deliveries?status=failed
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	status := c.Query("status")
+	switch status {
+	case "", "pending", "sending", "success", "failed":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, sending, success or failed"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	deliveries, total, err := h.svc.Deliveries(ctx, enterpriseIDFrom(c), c.Param("id"), status, page, limit)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"deliveries": deliveries,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	})
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidWebhookURL):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
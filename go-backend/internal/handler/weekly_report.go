@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+type WeeklyReportHandler struct {
+	svc *service.WeeklyReportService
+}
+
+func NewWeeklyReportHandler(svc *service.WeeklyReportService) *WeeklyReportHandler {
+	return &WeeklyReportHandler{svc: svc}
+}
+
+// ListSubscribers returns the addresses that receive the enterprise's
+// weekly report
+// GET /api/v1/enterprise/reports/subscribers
+func (h *WeeklyReportHandler) ListSubscribers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	subscribers, err := h.svc.ListSubscribers(ctx, enterpriseIDFrom(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"subscribers": subscribers,
+	})
+}
+
+// AddSubscriber adds an address to the enterprise's weekly report, which
+// receives it once it follows the link emailed to it
+// POST /api/v1/enterprise/reports/subscribers
+func (h *WeeklyReportHandler) AddSubscriber(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.ReportSubscriberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscriber, err := h.svc.AddSubscriber(ctx, enterpriseIDFrom(c), &req)
+	if err != nil {
+		c.JSON(weeklyReportErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"subscriber": subscriber,
+	})
+}
+
+// RemoveSubscriber removes an address from the enterprise's weekly report
+// DELETE /api/v1/enterprise/reports/subscribers/:id
+func (h *WeeklyReportHandler) RemoveSubscriber(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if err := h.svc.RemoveSubscriber(ctx, enterpriseIDFrom(c), c.Param("id")); err != nil {
+		c.JSON(weeklyReportErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// Weekly returns the enterprise's report of the week containing ?week=
+// (YYYY-MM-DD), by default last week, as JSON or with ?format=html as the
+// email subscribers receive
+// GET /api/v1/enterprise/reports/weekly
+func (h *WeeklyReportHandler) Weekly(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	report, err := h.svc.Report(ctx, enterpriseIDFrom(c), c.Query("week"))
+	if err != nil {
+		c.JSON(weeklyReportErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	if c.Query("format") == "html" {
+		page, err := h.svc.RenderReport(i18n.FromContext(ctx), report, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"report":  report,
+	})
+}
+
+// Confirm starts a subscriber's reports through the link emailed to it
+// GET /api/v1/reports/subscribers/:id/confirm
+func (h *WeeklyReportHandler) Confirm(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	page, err := h.svc.Confirm(ctx, c.Param("id"), c.Query("sig"))
+	if err != nil {
+		c.JSON(weeklyReportErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+// Unsubscribe stops a subscriber's reports through the link in each
+// report. POST serves mail clients' one-click unsubscribe.
+// GET|POST /api/v1/reports/subscribers/:id/unsubscribe
+func (h *WeeklyReportHandler) Unsubscribe(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	page, err := h.svc.Unsubscribe(ctx, c.Param("id"), c.Query("sig"))
+	if err != nil {
+		c.JSON(weeklyReportErrorStatus(err), gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+func weeklyReportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrReportSubscriberNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidSubscriptionLink):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidReportWeek):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrEmailNotConfigured):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
@@ -38,9 +39,12 @@ func (h *XCMHandler) GetAssetInfo(c *gin.Context) {
 		addr, err := h.bridge.GetAssetAddress(asset, chain.ChainID)
 		if err == nil {
 			assetInfo = append(assetInfo, gin.H{
-				"chainId":   chain.ChainID,
-				"chainName": chain.Name,
-				"address":   addr,
+				"chainId":    chain.ChainID,
+				"chainName":  chain.Name,
+				"address":    addr,
+				"currencyId": h.bridge.GetAssetCurrencyID(asset, chain.ChainID),
+				"native":     h.bridge.IsNativeAsset(asset, chain.ChainID),
+				"decimals":   h.bridge.AssetDecimals(asset),
 			})
 		}
 	}
@@ -100,7 +104,8 @@ func (h *XCMHandler) InitiateTransfer(c *gin.Context) {
 	amount := new(big.Int)
 	amount.SetString(req.Amount, 10)
 	
-	result, err := h.bridge.TransferAsset(c.Request.Context(), &service.CrossChainTransferRequest{
+	ctx := c.Request.Context()
+	result, err := h.bridge.TransferAsset(ctx, &service.CrossChainTransferRequest{
 		FromChain: service.ChainID(req.FromChain),
 		ToChain:   service.ChainID(req.ToChain),
 		Asset:     req.Asset,
@@ -109,6 +114,10 @@ func (h *XCMHandler) InitiateTransfer(c *gin.Context) {
 		Recipient: req.Recipient,
 	})
 	
+	if errors.Is(err, service.ErrXCMTransferNotSendable) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.LocalizedError(ctx, err), "code": service.ErrorCode(err)})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -196,7 +205,8 @@ func (h *XCMHandler) EstimateFee(c *gin.Context) {
 	})
 }
 
-// HealthCheck checks chain health status
+// HealthCheck checks chain health status, with the health scores of the
+// chain's RPC providers
 // GET /api/v1/xcm/health/:chainId
 func (h *XCMHandler) HealthCheck(c *gin.Context) {
 	var chainId int64
@@ -209,11 +219,18 @@ func (h *XCMHandler) HealthCheck(c *gin.Context) {
 		status = "unhealthy"
 	}
 	
-	gasPrice, _ := h.bridge.GetChainGasPrice(c.Request.Context(), service.ChainID(chainId))
-	
-	c.JSON(http.StatusOK, gin.H{
-		"chainId":  chainId,
-		"status":   status,
-		"gasPrice": gasPrice.String(),
-	})
+	body := gin.H{
+		"chainId": chainId,
+		"status":  status,
+		"rpcs":    h.bridge.RPCStatus(service.ChainID(chainId)),
+	}
+	if h.bridge.IsSubstrateChain(service.ChainID(chainId)) {
+		fee, _ := h.bridge.TransferFee(c.Request.Context(), service.ChainID(chainId))
+		body["transferFee"] = fee.String()
+	} else {
+		gasPrice, _ := h.bridge.GetChainGasPrice(c.Request.Context(), service.ChainID(chainId))
+		body["gasPrice"] = gasPrice.String()
+	}
+
+	c.JSON(http.StatusOK, body)
 }
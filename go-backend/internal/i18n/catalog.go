@@ -0,0 +1,1010 @@
+package i18n
+
+// catalog maps locale -> message key -> message. Keys missing from a locale fall back to English.
+var catalog = map[string]map[string]string{
+	"en": {
+		// API errors
+		"error.red_pocket_not_found":           "red pocket not found",
+		"error.red_pocket_expired":             "red pocket has expired",
+		"error.red_pocket_depleted":            "red pocket is fully claimed",
+		"error.red_pocket_inactive":            "red pocket is %s",
+		"error.already_claimed":                "you have already claimed this red pocket",
+		"error.insufficient_funds":             "insufficient funds in red pocket",
+		"error.claim_in_progress":              "claim in progress, please try again",
+		"error.transfer_failed":                "transfer failed",
+		"error.audience_proof_required":        "this red pocket is limited to a token holder snapshot, wallet address and signature are required",
+		"error.audience_bad_signature":         "wallet signature does not match the provided address",
+		"error.not_in_audience":                "wallet address is not eligible for this red pocket",
+		"error.audience_already_used":          "this wallet address has already claimed this red pocket",
+		"error.id_required":                    "id is required",
+		"error.claim_not_found":                "claim not found",
+		"error.payout_not_retryable":           "payout is not dead-lettered and cannot be retried",
+		"error.insufficient_balance":           "insufficient balance",
+		"error.invalid_address":                "invalid wallet address",
+		"error.withdrawal_not_found":           "withdrawal not found",
+		"error.withdrawal_below_minimum":       "withdrawal amount is below the minimum for this token, or does not cover the fee",
+		"error.instant_withdrawal_unavailable": "instant withdrawals are not available for this token",
+		"error.pocket_not_expired":             "red pocket has not expired yet",
+		"error.nothing_to_refund":              "red pocket has no remaining funds to refund",
+		"error.terms_not_accepted":             "this campaign requires accepting its terms (version %s) before claiming",
+		"error.wallet_not_owned":               "That wallet does not belong to this user",
+		"error.asset_not_consolidatable":       "Only a token available on the target chain can be consolidated; native balances pay for the wallets' gas",
+		"error.xcm_transfer_not_sendable":      "Only transfers from Moonbeam and Astar can be requested here, returned as a call for your own wallet to sign",
+		"error.unsupported_token":              "Token not supported; pass its contract address",
+		"error.address_sanctioned":             "This address cannot receive funds",
+		"error.travel_rule_required":           "Withdrawals of %s or more require originator and beneficiary travel rule information",
+		"error.invalid_travel_rule":            "Invalid travel rule information: %s",
+		"error.claim_password_required":        "This red pocket is password protected",
+		"error.invalid_claim_password":         "Incorrect password",
+		"error.too_many_password_attempts":     "Too many wrong passwords, try again later",
+		"error.captcha_required":               "Please complete the CAPTCHA",
+		"error.captcha_failed":                 "CAPTCHA verification failed, please try again",
+		"error.captcha_unavailable":            "This CAPTCHA provider is not configured",
+		"error.claim_links_unavailable":        "Signed claim links are not configured on this server",
+		"error.claim_link_required":            "Open this red pocket from the claim link you were sent",
+		"error.invalid_claim_link":             "This claim link is not valid",
+		"error.claim_link_expired":             "This claim link has expired",
+		"error.claim_link_wrong_recipient":     "This claim link was sent to someone else",
+		"error.claim_link_used":                "This claim link has already been used",
+		"error.pocket_links_unsigned":          "This red pocket was not created with signed links",
+		"error.invalid_claim_link_count":       "Request between 1 and %v links",
+		"error.invalid_recurrence":             "Recurrence must be daily, weekly or a five-field cron expression",
+		"error.invalid_start_time":             "Start time must not be in the past",
+		"error.red_pocket_not_started":         "This red pocket opens at %s",
+		"error.pocket_not_scheduled":           "Red pocket has already started or was cancelled",
+		"error.red_pocket_paused":              "This red pocket is paused by its creator; try again later",
+		"error.pocket_not_pausable":            "Only an open red pocket can be paused",
+		"error.pocket_not_cancellable":         "Only an open or paused red pocket can be cancelled",
+		"error.pocket_not_extendable":          "Only an open or paused red pocket can be extended",
+		"error.invalid_expiry":                 "Give either expiresAt or extendBy, for an expiry later than the current one",
+		"error.pocket_not_resizable":           "Only an open, paused or scheduled red pocket can be resized, and a lucky draw only before anyone has claimed it",
+		"error.supply_transfer_failed":         "Moving the difference between the red pocket's payout wallet and your enterprise wallet failed; check the wallet's balance and try again",
+		"error.invalid_supply_change":          "Give an amount or count that leaves at least one share, and a minor unit for each, unclaimed; once claimed from, an equal split pocket changes by whole shares",
+		"error.hunt_step_locked":               "This red pocket is a treasure hunt step; claim the step before it first",
+		"error.hunt_step_not_revealed":         "This treasure hunt step is revealed to you at %s",
+		"error.invalid_hunt":                   "Invalid treasure hunt: %s",
+		"error.team_not_found":                 "No team has this code",
+		"error.already_in_team":                "You are already in a team of this campaign",
+		"error.team_milestone_awarded":         "A team has already been awarded this milestone, so it cannot be deleted",
+		"error.too_many_rules":                 "A campaign can have at most %d rules",
+		"error.invalid_rule":                   "Invalid rule: %s",
+		"error.invalid_team_milestone":         "Give a milestone a claim count, an amount, or both",
+		"error.partner_not_found":              "Partner not found",
+		"error.partner_code_taken":             "This partner code is already taken",
+		"error.partner_already_attached":       "A partner is already attached to this enterprise",
+		"error.invalid_partner_key":            "Invalid partner key",
+		"error.invalid_statement_range":        "Invalid statement range: months are YYYY-MM, from no later than to, at most 24 months",
+		"error.invalid_report_format":          "Invalid report format: use csv or xlsx",
+		"error.invalid_report_range":           "Invalid report range: dates are YYYY-MM-DD or RFC 3339 times, from earlier than to",
+		"error.invalid_event_range":            "Invalid event range: times are YYYY-MM-DD or RFC 3339, from earlier than to",
+		"error.report_export_not_found":        "Export not found or expired",
+		"error.invalid_download_link":          "Invalid or expired download link",
+		"error.sandbox_only":                   "Only sandbox enterprises can use this",
+		"error.sandbox_credit_payout":          "Sandbox campaigns can only pay out on chain",
+		"error.invalid_faucet_amount":          "The faucet amount must be positive and within the faucet limit",
+		"error.faucet_limit_reached":           "Daily faucet limit reached, try again tomorrow",
+		"error.enterprise_email_taken":         "An enterprise with this email already exists",
+		"error.invalid_analytics_interval":     "Interval must be hour, day or week",
+		"error.invalid_analytics_breakdown":    "Breakdown must be platform or token",
+		"error.not_lucky_draw":                 "This red pocket is not a verifiable lucky draw",
+		"error.pocket_not_paused":              "This red pocket is not paused",
+		"error.claim_rate_limited":             "Too many claim attempts; try again shortly",
+		"error.invalid_payout_splits":          "Invalid payout splits: %s",
+		"error.invalid_amount":                 "Amount must be positive with no more decimals than the token has",
+		"error.native_token_unsupported":       "Native token pockets need on-chain payouts without batched settlement",
+		"error.native_share_below_fee":         "Each share of a native token red pocket has to be worth more than the %g %s it costs to pay out",
+		"error.not_on_allowlist":               "You are not on this red pocket's allowlist",
+		"error.holder_proof_required":          "This red pocket is limited to token holders, a wallet address and signature are required",
+		"error.token_gate_not_met":             "Your wallet does not hold enough of the required token",
+		"error.account_too_new":                "Your account must be at least %d days old to claim",
+		"error.region_not_eligible":            "This red pocket is not available in your region",
+		"error.claim_window_closed":            "This red pocket can only be claimed during set hours; it opens again at %s",
+		"error.invalid_channel_limit":          "Invalid channel limit: %s",
+		"error.invalid_claim_weight":           "Invalid claim weight: %s",
+		"error.claim_channel_not_listed":       "This red pocket can only be claimed in the channels it was shared to",
+		"error.channel_limit_reached":          "This red pocket's share for this channel has been claimed",
+		"error.invalid_eligibility_rule":       "Invalid eligibility rule: %s",
+		"error.vault_not_found":                "Savings vault not found",
+		"error.vault_unavailable":              "This savings vault is not available for this chain or token",
+		"error.claim_blocked":                  "This claim was blocked by fraud protection",
+		"error.assessment_not_found":           "Flagged claim not found",
+		"error.assessment_reviewed":            "This flagged claim was already reviewed",
+		"error.payout_already_released":        "The payout was already released and can no longer be rejected",
+		"error.offramp_unavailable":            "Cashing out to fiat is not available",
+		"error.offramp_network_unsupported":    "Cashing out to fiat is not supported on this chain",
+		"error.webhook_not_found":              "Webhook not found",
+		"error.api_key_not_found":              "API key not found",
+		"error.api_key_required":               "Tokens are issued to requests signed with an API key",
+		"error.api_key_exists":                 "The enterprise already has an API key; rotate it instead",
+		"error.enterprise_not_found":           "enterprise not found",
+		"error.support_reference":              "Support reference: %s",
+		"error.claim_failure_not_found":        "No claim failure was recorded under this reference",
+		"error.invalid_webhook_url":            "Webhook URL must be an http or https address",
+		"error.digest_not_found":               "No daily digest is set up",
+		"error.invalid_digest_channel":         "Invalid digest channel: %s",
+		"error.email_not_configured":           "Email is not configured on this server",
+		"error.report_subscriber_not_found":    "Report subscriber not found",
+		"error.invalid_subscription_link":      "This link is invalid",
+		"error.invalid_report_week":            "Week must be a date (YYYY-MM-DD) in a completed week",
+		"error.campaign_budget_exceeded":       "Red pocket amount exceeds the campaign's remaining budget",
+		"error.campaign_token_mismatch":        "Red pocket token differs from the campaign's budget token",
+		"error.swap_unavailable":               "Token conversion is not available",
+		"error.service_degraded":               "The service is temporarily read-only while a dependency recovers, please try again shortly",
+		"error.downstream_unavailable":         "A service this request depends on is unavailable, please try again shortly",
+		"error.request_nonce_required":         "Signed withdraw, transfer and bridge requests need a current timestamp and a nonce of 16 to 128 characters",
+		"error.request_expired":                "The request's timestamp is too far from the server time",
+		"error.request_replayed":               "This request has already been received; sign a new one with a fresh nonce",
+		"error.idempotency_key_invalid":        "Idempotency-Key must be at most 255 characters",
+		"error.idempotency_key_in_progress":    "A request with this Idempotency-Key is still being processed, please retry shortly",
+		"error.idempotency_key_reused":         "This Idempotency-Key was already used for a different request",
+		"error.invalid_signature":              "invalid request signature",
+		"error.invalid_api_key":                "invalid API key",
+		"error.travel_rule_not_found":          "No travel rule information for this withdrawal",
+		"error.pocket_not_finished":            "red pocket is still active, summary is available once it finishes",
+		"error.pocket_not_settled":             "red pocket has claims still being paid out, try again shortly",
+		"error.rate_limit_exceeded":            "rate limit exceeded",
+		"error.invalid_login_code":             "This login code is wrong or has expired; request a new one",
+		"error.login_code_throttled":           "A login code was just sent, wait a minute before requesting another",
+		"error.login_platform_unsupported":     "Signing in is only available to Telegram and Discord accounts",
+		"claimer.login_code":                   "Your Red Pocket login code is %s. It expires in %d minutes. Never share it with anyone.",
+		"error.unauthorized":                   "authorization header required",
+		"error.invalid_token":                  "invalid token",
+
+		"diagnose.queued":                 "Your claim is recorded and its payout is queued",
+		"diagnose.processing":             "Your payout is being sent",
+		"diagnose.retrying":               "Sending your payout failed; it is retried automatically",
+		"diagnose.held_for_review":        "Your payout is held for a review before it is sent",
+		"diagnose.blocked":                "Your payout was blocked and will not be sent",
+		"diagnose.refunded":               "Your payout failed and its amount was returned to the red pocket's creator",
+		"diagnose.payout_failed":          "Your payout could not be sent after several attempts",
+		"diagnose.awaiting_confirmations": "Your payout is on chain and waiting for confirmations",
+		"diagnose.resubmitted":            "Your payout dropped out of the chain and was sent again",
+		"diagnose.confirmed":              "Your payout is confirmed",
+		"diagnose.reverted":               "Your payout transaction failed on chain",
+		"diagnose.reorged":                "Your payout dropped out of the chain and could not be sent again",
+		"diagnose.internal_error":         "Your claim failed because of an error on our side",
+		"diagnose.action.none":            "Nothing more needs to be done",
+		"diagnose.action.wait":            "No action needed; check back later",
+		"diagnose.action.retry":           "Try claiming again",
+		"diagnose.action.contact_creator": "Contact the creator of the red pocket with your claim ID or support reference",
+
+		// Telegram bot
+		"bot.telegram.start": `🧧 *Welcome to Protocol Bank Red Pocket Bot!*
+
+I can help you create and manage red pockets for your community.
+
+*Commands:*
+/create - Create a new red pocket
+/balance - Check your wallet balance
+/language - Change the bot language
+/help - Show help message
+
+Visit our dashboard to create campaigns:
+https://redpocket.protocolbanks.com/dashboard`,
+		"bot.telegram.help": `🧧 *Red Pocket Bot Help*
+
+*Available Commands:*
+• /start - Start the bot
+• /create - Create a new red pocket
+• /balance - Check wallet balance
+• /language <code> - Change language (%s)
+• /hunt - Your treasure hunts
+• /help - Show this help
+
+*How to create a red pocket:*
+1. Visit the dashboard
+2. Connect your wallet
+3. Create a campaign
+4. Share the link in your group
+
+*Need support?*
+Contact: @protocolbank_support`,
+		"bot.telegram.create": `🧧 *Create a Red Pocket*
+
+To create a red pocket campaign:
+
+1. 🔗 Visit: https://redpocket.protocolbanks.com/dashboard
+2. 💳 Connect your wallet
+3. 📝 Fill in campaign details
+4. 💰 Deposit funds
+5. 📤 Share the link here!
+
+_Creating red pockets requires a connected wallet for security._`,
+		"bot.telegram.balance": `💰 *Check Your Balance*
+
+To check your wallet balance:
+
+1. Visit: https://redpocket.protocolbanks.com/dashboard/wallet
+2. Connect your wallet
+3. View your balances across all chains
+
+_Your wallet, your keys, your funds._`,
+		"bot.telegram.red_pocket": `🧧 *Red Pocket Alert!*
+
+*%s* sent a red pocket!
+
+💰 Amount: *%.2f %s*
+%s
+[🎁 Claim Now](%s)
+
+_Powered by Protocol Bank_`,
+		"bot.telegram.claimed": `🎉 *%s* claimed a red pocket!
+
+💰 Received: *%.2f %s*
+📦 Remaining: *%d* pockets
+
+_Powered by Protocol Bank_`,
+		"bot.telegram.language_set":   "✅ Language set to *%s*",
+		"bot.telegram.claim_button":   "🧧 Claim",
+		"bot.telegram.claim_success":  "🎉 You received %.2f %s!",
+		"bot.telegram.claim_error":    "Something went wrong, please try again or use the claim link",
+		"bot.telegram.remaining":      "📦 Remaining: *%d* of %d",
+		"bot.telegram.closed":         "🔒 Closed: %d of %d claimed",
+		"bot.telegram.language_usage": "Usage: /language <code>\nSupported: %s",
+		"bot.telegram.hunt_none":      "You have not started a treasure hunt yet. Claim a hunt's first red pocket to begin.",
+		"bot.telegram.hunt_progress":  "🗺 %s: %d of %d steps found",
+		"bot.telegram.hunt_done":      "🏆 %s: all %d steps found!",
+		"bot.telegram.hunt_clue":      "🔎 Next clue: %s",
+		"bot.telegram.hunt_reveal":    "⏳ The next red pocket is revealed at %s",
+		"bot.telegram.hunt_link":      "🎁 Claim the next step: %s",
+
+		// Discord bot
+		"bot.discord.red_pocket_title": "🧧 Red Pocket Alert!",
+		"bot.discord.red_pocket_desc":  "**%s** sent a red pocket!\n\n%s",
+		"bot.discord.amount":           "💰 Amount",
+		"bot.discord.claim":            "🎁 Claim",
+		"bot.discord.click_here":       "[Click Here](%s)",
+		"bot.discord.claimed_title":    "🎉 Red Pocket Claimed!",
+		"bot.discord.claimed_desc":     "**%s** claimed a red pocket!",
+		"bot.discord.received":         "💰 Received",
+		"bot.discord.remaining":        "📦 Remaining",
+		"bot.discord.remaining_value":  "%d pockets",
+		"bot.footer":                   "Powered by Protocol Bank",
+
+		// Slack app
+		"bot.slack.red_pocket_title": "🧧 Red Pocket Alert!",
+		"bot.slack.red_pocket_desc":  "*%s* sent a red pocket!",
+		"bot.slack.amount":           "💰 Amount",
+		"bot.slack.claim_button":     "🎁 Claim Now",
+		"bot.slack.claim_hint":       "Or claim right here: `/redpocket claim %s`",
+		"bot.slack.claimed":          "🎉 %s claimed a red pocket!",
+		"bot.slack.received":         "💰 Received",
+		"bot.slack.remaining":        "📦 Remaining",
+		"bot.slack.remaining_value":  "%d pockets",
+		"bot.slack.claim_success":    "🎉 You received %.2f %s!",
+		"bot.slack.claim_error":      "Something went wrong, please try again or use the claim link",
+		"bot.slack.claim_usage":      "Usage: `/redpocket claim <red pocket ID>`",
+		"bot.slack.language_set":     "✅ Language set to *%s*",
+		"bot.slack.language_usage":   "Usage: `/redpocket language <code>`\nSupported: %s",
+		"bot.slack.help": `🧧 *Red Pocket*
+
+• ` + "`/redpocket claim <ID>`" + ` - Claim a red pocket posted here
+• ` + "`/redpocket language <code>`" + ` - Change the language of this channel (%s)
+• ` + "`/redpocket help`" + ` - Show this help
+
+Create red pockets at https://redpocket.protocolbanks.com/dashboard`,
+
+		"digest.title":       "📊 Daily summary for %s (UTC)",
+		"digest.pockets":     "🧧 Red pockets created: %d",
+		"digest.claims":      "🎁 Claims: %d by %d claimers",
+		"digest.distributed": "💰 Distributed: %s",
+		"digest.top_pocket":  "🏆 Top red pocket: %s from %s, %d claims, %s",
+
+		"reminder.title_24h": "⏰ 24 hours left to claim",
+		"reminder.title_1h":  "⏰ 1 hour left to claim",
+		"reminder.body":      "%s's red pocket still has %s unclaimed (%d of %d left): %s",
+
+		"report.subject":         "Weekly report for %s: week of %s",
+		"report.title":           "%s weekly report",
+		"report.period":          "%s to %s (UTC)",
+		"report.claims":          "Claims",
+		"report.unique_claimers": "Unique claimers",
+		"report.amount":          "Amount claimed",
+		"report.pockets":         "Red pockets created",
+		"report.conversion":      "Claims per red pocket",
+		"report.change_new":      "new",
+		"report.by_day":          "Claims by day",
+		"report.by_platform":     "Claims by platform",
+		"report.top_campaigns":   "Top campaigns",
+		"report.no_activity":     "No red pockets were created or claimed this week.",
+		"report.footer":          "You receive this report because you subscribed to %s's weekly report.",
+		"report.unsubscribe":     "Unsubscribe",
+		"report.confirm_subject": "Confirm your subscription to %s's weekly report",
+		"report.confirm_body":    "You were added to %s's weekly red pocket report. Confirm to start receiving it every Monday.",
+		"report.confirm":         "Confirm subscription",
+		"report.confirmed":       "You are subscribed to %s's weekly report.",
+		"report.unsubscribed":    "You will no longer receive %s's weekly report.",
+	},
+	"zh": {
+		"error.red_pocket_not_found":           "红包不存在",
+		"error.red_pocket_expired":             "红包已过期",
+		"error.red_pocket_depleted":            "红包已被领完",
+		"error.red_pocket_inactive":            "红包当前状态为 %s",
+		"error.already_claimed":                "您已经领取过这个红包",
+		"error.insufficient_funds":             "红包余额不足",
+		"error.claim_in_progress":              "正在领取中，请稍后重试",
+		"error.transfer_failed":                "转账失败",
+		"error.audience_proof_required":        "该红包仅限快照内持币地址领取，请提供钱包地址和签名",
+		"error.audience_bad_signature":         "钱包签名与地址不匹配",
+		"error.not_in_audience":                "该钱包地址不在本红包的领取名单中",
+		"error.audience_already_used":          "该钱包地址已领取过这个红包",
+		"error.id_required":                    "缺少 id 参数",
+		"error.claim_not_found":                "领取记录不存在",
+		"error.payout_not_retryable":           "该打款未进入死信状态，无法重试",
+		"error.insufficient_balance":           "余额不足",
+		"error.invalid_address":                "钱包地址无效",
+		"error.withdrawal_not_found":           "提现记录不存在",
+		"error.withdrawal_below_minimum":       "提现金额低于该代币的最低提现额或不足以支付手续费",
+		"error.instant_withdrawal_unavailable": "该代币暂不支持即时提现",
+		"error.pocket_not_expired":             "红包尚未过期",
+		"error.nothing_to_refund":              "红包没有可退还的剩余金额",
+		"error.terms_not_accepted":             "领取前需同意该活动的条款 (版本 %s)",
+		"error.wallet_not_owned":               "该钱包不属于此用户",
+		"error.asset_not_consolidatable":       "只能归集目标链上支持的代币; 原生代币余额用于支付钱包的 Gas",
+		"error.xcm_transfer_not_sendable":      "此处只能请求从 Moonbeam 或 Astar 发出的转账, 返回供你自己的钱包签名的调用",
+		"error.unsupported_token":              "不支持该代币, 请传入合约地址",
+		"error.address_sanctioned":             "该地址无法接收资金",
+		"error.travel_rule_required":           "%s 及以上的提现需要提供发起人和受益人的旅行规则信息",
+		"error.invalid_travel_rule":            "旅行规则信息无效: %s",
+		"error.claim_password_required":        "该红包需要密码",
+		"error.invalid_claim_password":         "密码错误",
+		"error.too_many_password_attempts":     "密码错误次数过多, 请稍后再试",
+		"error.captcha_required":               "请完成人机验证",
+		"error.captcha_failed":                 "人机验证失败, 请重试",
+		"error.captcha_unavailable":            "该人机验证服务未配置",
+		"error.claim_links_unavailable":        "本服务器未配置签名领取链接",
+		"error.claim_link_required":            "请通过收到的领取链接打开此红包",
+		"error.invalid_claim_link":             "领取链接无效",
+		"error.claim_link_expired":             "领取链接已过期",
+		"error.claim_link_wrong_recipient":     "此领取链接属于其他用户",
+		"error.claim_link_used":                "此领取链接已被使用",
+		"error.pocket_links_unsigned":          "此红包未启用签名领取链接",
+		"error.invalid_claim_link_count":       "每次可生成 1 到 %v 个链接",
+		"error.invalid_recurrence":             "重复规则必须为 daily、weekly 或五段式 cron 表达式",
+		"error.invalid_start_time":             "开始时间不能早于当前时间",
+		"error.red_pocket_not_started":         "该红包将于 %s 开放",
+		"error.pocket_not_scheduled":           "红包已开始或已取消",
+		"error.red_pocket_paused":              "此红包已被发起方暂停, 请稍后再试",
+		"error.pocket_not_pausable":            "只能暂停进行中的红包",
+		"error.pocket_not_cancellable":         "只能取消进行中或已暂停的红包",
+		"error.pocket_not_extendable":          "只能延长进行中或已暂停的红包",
+		"error.invalid_expiry":                 "请提供 expiresAt 或 extendBy 之一, 且新的过期时间须晚于当前过期时间",
+		"error.pocket_not_resizable":           "只能调整进行中、已暂停或定时的红包, 拼手气红包仅限无人领取前",
+		"error.supply_transfer_failed":         "在红包打款钱包与企业钱包之间转移差额失败, 请检查钱包余额后重试",
+		"error.invalid_supply_change":          "请提供金额或份数, 且须至少留下一份未领取、每份不少于一个最小单位; 普通红包已有领取后只能按整份调整",
+		"error.hunt_step_locked":               "该红包是寻宝活动的一关, 请先领取上一关的红包",
+		"error.hunt_step_not_revealed":         "寻宝的这一关将于 %s 向你揭晓",
+		"error.invalid_hunt":                   "寻宝活动设置无效: %s",
+		"error.team_not_found":                 "找不到该邀请码对应的队伍",
+		"error.already_in_team":                "你已经加入了该活动的一支队伍",
+		"error.team_milestone_awarded":         "已有队伍获得该里程碑奖励, 无法删除",
+		"error.too_many_rules":                 "每个活动最多设置 %d 条规则",
+		"error.invalid_rule":                   "规则设置无效: %s",
+		"error.invalid_team_milestone":         "里程碑需要设置领取次数、金额或两者",
+		"error.partner_not_found":              "合作伙伴不存在",
+		"error.partner_code_taken":             "合作伙伴代码已被占用",
+		"error.partner_already_attached":       "该企业已关联合作伙伴",
+		"error.invalid_partner_key":            "合作伙伴密钥无效",
+		"error.invalid_statement_range":        "账单范围无效: 月份格式为 YYYY-MM, 起始不晚于结束, 最多 24 个月",
+		"error.invalid_report_format":          "报表格式无效: 可选 csv 或 xlsx",
+		"error.invalid_report_range":           "报表范围无效: 日期格式为 YYYY-MM-DD 或 RFC 3339 时间, 起始须早于结束",
+		"error.invalid_event_range":            "事件范围无效: 时间格式为 YYYY-MM-DD 或 RFC 3339, 起始须早于结束",
+		"error.report_export_not_found":        "导出不存在或已过期",
+		"error.invalid_download_link":          "下载链接无效或已过期",
+		"error.sandbox_only":                   "仅沙盒企业可以使用此功能",
+		"error.sandbox_credit_payout":          "沙盒活动只能链上发放",
+		"error.invalid_faucet_amount":          "水龙头金额必须为正数且不超过单次上限",
+		"error.faucet_limit_reached":           "已达到每日水龙头次数上限，请明天再试",
+		"error.enterprise_email_taken":         "该邮箱已被其他企业使用",
+		"error.invalid_analytics_interval":     "时间粒度必须为 hour、day 或 week",
+		"error.invalid_analytics_breakdown":    "细分维度必须为 platform 或 token",
+		"error.not_lucky_draw":                 "该红包不是可验证的拼手气红包",
+		"error.pocket_not_paused":              "此红包未暂停",
+		"error.claim_rate_limited":             "领取尝试过于频繁, 请稍后再试",
+		"error.invalid_payout_splits":          "分账设置无效: %s",
+		"error.invalid_amount":                 "金额必须为正数, 且小数位数不能超过该代币的精度",
+		"error.native_token_unsupported":       "原生代币红包仅支持非批量结算的链上打款",
+		"error.native_share_below_fee":         "原生代币红包每份金额须高于打款成本 %g %s",
+		"error.not_on_allowlist":               "你不在该红包的白名单中",
+		"error.holder_proof_required":          "该红包仅限持币用户领取，请提供钱包地址和签名",
+		"error.token_gate_not_met":             "你的钱包未持有足够的指定代币",
+		"error.account_too_new":                "账号注册满 %d 天后才能领取",
+		"error.region_not_eligible":            "该红包在你所在的地区不可领取",
+		"error.claim_window_closed":            "该红包仅在指定时段可领取, 下次开放时间为 %s",
+		"error.invalid_channel_limit":          "频道限额无效: %s",
+		"error.invalid_claim_weight":           "领取加权设置无效: %s",
+		"error.claim_channel_not_listed":       "该红包只能在指定的频道中领取",
+		"error.channel_limit_reached":          "该红包分配给此频道的份额已被领完",
+		"error.invalid_eligibility_rule":       "领取条件设置无效: %s",
+		"error.vault_not_found":                "储蓄金库不存在",
+		"error.vault_unavailable":              "该储蓄金库不支持此链或代币",
+		"error.claim_blocked":                  "该领取已被反作弊系统拦截",
+		"error.assessment_not_found":           "未找到被标记的领取",
+		"error.assessment_reviewed":            "该标记领取已审核",
+		"error.payout_already_released":        "该打款已放行, 无法再拒绝",
+		"error.offramp_unavailable":            "暂不支持提现为法币",
+		"error.offramp_network_unsupported":    "该链暂不支持提现为法币",
+		"error.webhook_not_found":              "未找到 Webhook",
+		"error.api_key_not_found":              "未找到 API key",
+		"error.api_key_required":               "令牌只签发给使用 API key 签名的请求",
+		"error.api_key_exists":                 "该企业已有 API key，请改为轮换",
+		"error.enterprise_not_found":           "未找到企业",
+		"error.support_reference":              "客服参考编号：%s",
+		"error.claim_failure_not_found":        "没有以此参考编号记录的领取失败",
+		"error.invalid_webhook_url":            "Webhook URL 必须是 http 或 https 地址",
+		"error.digest_not_found":               "尚未设置每日摘要",
+		"error.invalid_digest_channel":         "摘要频道无效: %s",
+		"error.email_not_configured":           "服务器未配置邮件发送",
+		"error.report_subscriber_not_found":    "报告订阅人不存在",
+		"error.invalid_subscription_link":      "链接无效",
+		"error.invalid_report_week":            "周必须是已结束的一周内的日期 (YYYY-MM-DD)",
+		"error.campaign_budget_exceeded":       "红包金额超出活动剩余预算",
+		"error.campaign_token_mismatch":        "红包代币与活动预算代币不一致",
+		"error.swap_unavailable":               "代币兑换暂不可用",
+		"error.service_degraded":               "服务依赖正在恢复, 暂时只读, 请稍后再试",
+		"error.downstream_unavailable":         "本请求依赖的服务暂时不可用，请稍后重试",
+		"error.request_nonce_required":         "签名的提现、转账和跨链请求需要携带当前时间戳和 16 到 128 个字符的 nonce",
+		"error.request_expired":                "请求时间戳与服务器时间相差过大",
+		"error.request_replayed":               "该请求已被处理过，请使用新的 nonce 重新签名",
+		"error.idempotency_key_invalid":        "Idempotency-Key 不能超过 255 个字符",
+		"error.idempotency_key_in_progress":    "使用该 Idempotency-Key 的请求仍在处理中，请稍后重试",
+		"error.idempotency_key_reused":         "该 Idempotency-Key 已用于另一个不同的请求",
+		"error.invalid_signature":              "请求签名无效",
+		"error.invalid_api_key":                "API key 无效",
+		"error.travel_rule_not_found":          "该提现没有旅行规则信息",
+		"error.pocket_not_finished":            "红包仍在进行中，结束后才可查看汇总",
+		"error.pocket_not_settled":             "红包仍有领取正在打款，请稍后再试",
+		"error.rate_limit_exceeded":            "请求过于频繁",
+		"error.invalid_login_code":             "登录码错误或已过期，请重新获取",
+		"error.login_code_throttled":           "登录码刚刚已发送，请一分钟后再试",
+		"error.login_platform_unsupported":     "目前仅支持 Telegram 和 Discord 账号登录",
+		"claimer.login_code":                   "您的红包登录码是 %s，%d 分钟内有效。请勿告诉任何人。",
+		"error.unauthorized":                   "缺少授权信息",
+		"error.invalid_token":                  "无效的令牌",
+
+		"diagnose.queued":                 "领取已记录，打款正在排队",
+		"diagnose.processing":             "正在打款",
+		"diagnose.retrying":               "打款失败，系统会自动重试",
+		"diagnose.held_for_review":        "打款在发出前需要审核，暂时冻结",
+		"diagnose.blocked":                "打款已被拦截，不会发出",
+		"diagnose.refunded":               "打款失败，金额已退还给红包发起方",
+		"diagnose.payout_failed":          "多次尝试后仍未能打款",
+		"diagnose.awaiting_confirmations": "打款已上链，正在等待确认",
+		"diagnose.resubmitted":            "打款交易已从链上消失，已重新发送",
+		"diagnose.confirmed":              "打款已确认",
+		"diagnose.reverted":               "打款交易在链上执行失败",
+		"diagnose.reorged":                "打款交易已从链上消失且无法重新发送",
+		"diagnose.internal_error":         "由于我们这边的错误，领取失败",
+		"diagnose.action.none":            "无需其他操作",
+		"diagnose.action.wait":            "无需操作，请稍后再查看",
+		"diagnose.action.retry":           "请重新领取",
+		"diagnose.action.contact_creator": "请携带领取 ID 或客服参考编号联系红包发起方",
+
+		"bot.telegram.start": `🧧 *欢迎使用 Protocol Bank 红包机器人！*
+
+我可以帮助你为社区创建和管理红包。
+
+*命令：*
+/create - 创建新红包
+/balance - 查看钱包余额
+/language - 切换机器人语言
+/help - 显示帮助
+
+访问控制台创建活动：
+https://redpocket.protocolbanks.com/dashboard`,
+		"bot.telegram.help": `🧧 *红包机器人帮助*
+
+*可用命令：*
+• /start - 启动机器人
+• /create - 创建新红包
+• /balance - 查看钱包余额
+• /language <代码> - 切换语言 (%s)
+• /hunt - 我的寻宝
+• /help - 显示本帮助
+
+*如何创建红包：*
+1. 访问控制台
+2. 连接钱包
+3. 创建活动
+4. 将链接分享到群组
+
+*需要帮助？*
+联系：@protocolbank_support`,
+		"bot.telegram.create": `🧧 *创建红包*
+
+创建红包活动的步骤：
+
+1. 🔗 访问：https://redpocket.protocolbanks.com/dashboard
+2. 💳 连接钱包
+3. 📝 填写活动信息
+4. 💰 充值资金
+5. 📤 把链接分享到这里！
+
+_为了安全，创建红包需要连接钱包。_`,
+		"bot.telegram.balance": `💰 *查看余额*
+
+查看钱包余额的步骤：
+
+1. 访问：https://redpocket.protocolbanks.com/dashboard/wallet
+2. 连接钱包
+3. 查看所有链上的余额
+
+_你的钱包，你的私钥，你的资产。_`,
+		"bot.telegram.red_pocket": `🧧 *红包来啦！*
+
+*%s* 发了一个红包！
+
+💰 金额：*%.2f %s*
+%s
+[🎁 立即领取](%s)
+
+_Powered by Protocol Bank_`,
+		"bot.telegram.claimed": `🎉 *%s* 领取了红包！
+
+💰 获得：*%.2f %s*
+📦 剩余：*%d* 个
+
+_Powered by Protocol Bank_`,
+		"bot.telegram.language_set":   "✅ 语言已切换为 *%s*",
+		"bot.telegram.claim_button":   "🧧 领取",
+		"bot.telegram.claim_success":  "🎉 你领到了 %.2f %s！",
+		"bot.telegram.claim_error":    "出错了，请重试或使用领取链接",
+		"bot.telegram.remaining":      "📦 剩余：*%d* / %d 个",
+		"bot.telegram.closed":         "🔒 已结束：%d / %d 个已领取",
+		"bot.telegram.language_usage": "用法：/language <代码>\n支持：%s",
+		"bot.telegram.hunt_none":      "你还没有开始寻宝。领取寻宝活动的第一个红包即可开始。",
+		"bot.telegram.hunt_progress":  "🗺 %s: 已找到 %d / %d 关",
+		"bot.telegram.hunt_done":      "🏆 %s: %d 关全部找到！",
+		"bot.telegram.hunt_clue":      "🔎 下一关线索: %s",
+		"bot.telegram.hunt_reveal":    "⏳ 下一个红包将于 %s 揭晓",
+		"bot.telegram.hunt_link":      "🎁 领取下一关: %s",
+
+		"bot.discord.red_pocket_title": "🧧 红包来啦！",
+		"bot.discord.red_pocket_desc":  "**%s** 发了一个红包！\n\n%s",
+		"bot.discord.amount":           "💰 金额",
+		"bot.discord.claim":            "🎁 领取",
+		"bot.discord.click_here":       "[点击领取](%s)",
+		"bot.discord.claimed_title":    "🎉 红包已被领取！",
+		"bot.discord.claimed_desc":     "**%s** 领取了红包！",
+		"bot.discord.received":         "💰 获得",
+		"bot.discord.remaining":        "📦 剩余",
+		"bot.discord.remaining_value":  "%d 个",
+
+		"bot.slack.red_pocket_title": "🧧 红包来啦！",
+		"bot.slack.red_pocket_desc":  "*%s* 发了一个红包！",
+		"bot.slack.amount":           "💰 金额",
+		"bot.slack.claim_button":     "🎁 立即领取",
+		"bot.slack.claim_hint":       "也可以直接在这里领取：`/redpocket claim %s`",
+		"bot.slack.claimed":          "🎉 %s 领取了红包！",
+		"bot.slack.received":         "💰 获得",
+		"bot.slack.remaining":        "📦 剩余",
+		"bot.slack.remaining_value":  "%d 个",
+		"bot.slack.claim_success":    "🎉 你领到了 %.2f %s！",
+		"bot.slack.claim_error":      "出错了，请重试或使用领取链接",
+		"bot.slack.claim_usage":      "用法：`/redpocket claim <红包 ID>`",
+		"bot.slack.language_set":     "✅ 语言已切换为 *%s*",
+		"bot.slack.language_usage":   "用法：`/redpocket language <代码>`\n支持：%s",
+		"bot.slack.help": `🧧 *红包*
+
+• ` + "`/redpocket claim <ID>`" + ` - 领取本频道的红包
+• ` + "`/redpocket language <代码>`" + ` - 切换本频道的语言 (%s)
+• ` + "`/redpocket help`" + ` - 显示帮助
+
+在 https://redpocket.protocolbanks.com/dashboard 创建红包`,
+
+		"digest.title":       "📊 %s (UTC) 每日摘要",
+		"digest.pockets":     "🧧 新建红包: %d 个",
+		"digest.claims":      "🎁 领取: %d 笔, %d 人",
+		"digest.distributed": "💰 发放金额: %s",
+		"digest.top_pocket":  "🏆 最热红包: %s (%s 发出), %d 笔领取, %s",
+
+		"reminder.title_24h": "⏰ 红包还有 24 小时过期",
+		"reminder.title_1h":  "⏰ 红包还有 1 小时过期",
+		"reminder.body":      "%s 的红包还有 %s 未领取 (剩余 %d/%d 个): %s",
+
+		"report.subject":         "%s 周报: %s 当周",
+		"report.title":           "%s 周报",
+		"report.period":          "%s 至 %s (UTC)",
+		"report.claims":          "领取次数",
+		"report.unique_claimers": "领取人数",
+		"report.amount":          "领取金额",
+		"report.pockets":         "新建红包",
+		"report.conversion":      "每个红包领取次数",
+		"report.change_new":      "新增",
+		"report.by_day":          "每日领取",
+		"report.by_platform":     "各平台领取",
+		"report.top_campaigns":   "热门活动",
+		"report.no_activity":     "本周没有新建或领取红包。",
+		"report.footer":          "你订阅了 %s 的周报, 因此收到此邮件。",
+		"report.unsubscribe":     "退订",
+		"report.confirm_subject": "确认订阅 %s 的周报",
+		"report.confirm_body":    "你已被添加到 %s 的红包周报。确认后每周一都会收到。",
+		"report.confirm":         "确认订阅",
+		"report.confirmed":       "你已订阅 %s 的周报。",
+		"report.unsubscribed":    "你将不再收到 %s 的周报。",
+	},
+	"ja": {
+		"error.red_pocket_not_found":           "お年玉が見つかりません",
+		"error.red_pocket_expired":             "お年玉の有効期限が切れています",
+		"error.red_pocket_depleted":            "お年玉はすべて受け取られました",
+		"error.red_pocket_inactive":            "お年玉のステータス: %s",
+		"error.already_claimed":                "このお年玉はすでに受け取り済みです",
+		"error.insufficient_funds":             "お年玉の残高が不足しています",
+		"error.claim_in_progress":              "受け取り処理中です。しばらくしてから再度お試しください",
+		"error.transfer_failed":                "送金に失敗しました",
+		"error.audience_proof_required":        "このお年玉はスナップショット対象の保有者限定です。ウォレットアドレスと署名が必要です",
+		"error.audience_bad_signature":         "ウォレットの署名がアドレスと一致しません",
+		"error.not_in_audience":                "このウォレットアドレスは対象外です",
+		"error.audience_already_used":          "このウォレットアドレスはすでに受け取り済みです",
+		"error.id_required":                    "id は必須です",
+		"error.claim_not_found":                "受け取り記録が見つかりません",
+		"error.payout_not_retryable":           "この支払いはデッドレターになっていないため再試行できません",
+		"error.insufficient_balance":           "残高が不足しています",
+		"error.invalid_address":                "ウォレットアドレスが無効です",
+		"error.withdrawal_not_found":           "出金記録が見つかりません",
+		"error.withdrawal_below_minimum":       "出金額がこのトークンの最低出金額を下回っているか、手数料に足りません",
+		"error.instant_withdrawal_unavailable": "このトークンは即時出金に対応していません",
+		"error.pocket_not_expired":             "お年玉はまだ期限切れになっていません",
+		"error.nothing_to_refund":              "返金できる残高がありません",
+		"error.terms_not_accepted":             "受け取るにはキャンペーンの規約 (バージョン %s) への同意が必要です",
+		"error.wallet_not_owned":               "このウォレットはこのユーザーのものではありません",
+		"error.asset_not_consolidatable":       "集約できるのは対象チェーンで利用できるトークンのみです。ネイティブ残高はウォレットのガス代に使われます",
+		"error.xcm_transfer_not_sendable":      "ここでリクエストできるのは Moonbeam または Astar からの転送のみで、ご自身のウォレットで署名する呼び出しとして返されます",
+		"error.unsupported_token":              "このトークンはサポートされていません。コントラクトアドレスを指定してください",
+		"error.address_sanctioned":             "このアドレスは資金を受け取れません",
+		"error.travel_rule_required":           "%s 以上の出金には送金人と受取人のトラベルルール情報が必要です",
+		"error.invalid_travel_rule":            "トラベルルール情報が無効です: %s",
+		"error.claim_password_required":        "この紅包はパスワードで保護されています",
+		"error.invalid_claim_password":         "パスワードが正しくありません",
+		"error.too_many_password_attempts":     "パスワードの誤りが多すぎます。しばらくしてから再試行してください",
+		"error.captcha_required":               "CAPTCHA を完了してください",
+		"error.captcha_failed":                 "CAPTCHA の検証に失敗しました。もう一度お試しください",
+		"error.captcha_unavailable":            "この CAPTCHA プロバイダーは設定されていません",
+		"error.claim_links_unavailable":        "このサーバーでは署名付き受け取りリンクが設定されていません",
+		"error.claim_link_required":            "送られた受け取りリンクからこのお年玉を開いてください",
+		"error.invalid_claim_link":             "この受け取りリンクは無効です",
+		"error.claim_link_expired":             "この受け取りリンクは期限切れです",
+		"error.claim_link_wrong_recipient":     "この受け取りリンクは別の人に送られたものです",
+		"error.claim_link_used":                "この受け取りリンクは既に使用されています",
+		"error.pocket_links_unsigned":          "このお年玉は署名付きリンクで作成されていません",
+		"error.invalid_claim_link_count":       "リンクは1〜%v件の範囲で指定してください",
+		"error.invalid_recurrence":             "繰り返しは daily、weekly、または 5 フィールドの cron 式で指定してください",
+		"error.invalid_start_time":             "開始時刻に過去の日時は指定できません",
+		"error.red_pocket_not_started":         "この紅包は %s に開始します",
+		"error.pocket_not_scheduled":           "紅包は既に開始済みまたはキャンセル済みです",
+		"error.red_pocket_paused":              "このお年玉は作成者により一時停止されています。後でもう一度お試しください",
+		"error.pocket_not_pausable":            "受け取り受付中のお年玉のみ一時停止できます",
+		"error.pocket_not_cancellable":         "受け取り受付中または一時停止中のお年玉のみキャンセルできます",
+		"error.pocket_not_extendable":          "延長できるのは受け取り受付中または一時停止中のお年玉のみです",
+		"error.invalid_expiry":                 "expiresAt か extendBy のどちらかを指定し、現在より後の有効期限にしてください",
+		"error.pocket_not_resizable":           "調整できるのは受け取り受付中・一時停止中・予約中のお年玉のみで、ラッキードローは誰も受け取る前に限ります",
+		"error.supply_transfer_failed":         "お年玉の支払いウォレットと企業ウォレットの間で差額を移動できませんでした。ウォレットの残高を確認して再試行してください",
+		"error.invalid_supply_change":          "金額か個数を指定し、未受け取りの分を少なくとも1個、各1最小単位以上残してください。均等配分のお年玉は受け取り開始後は1個単位でのみ変更できます",
+		"error.hunt_step_locked":               "このお年玉は宝探しの一段階です。先に前の段階を受け取ってください",
+		"error.hunt_step_not_revealed":         "宝探しのこの段階は %s に公開されます",
+		"error.invalid_hunt":                   "宝探しの設定が無効です: %s",
+		"error.team_not_found":                 "このコードのチームは見つかりません",
+		"error.already_in_team":                "このキャンペーンのチームにすでに参加しています",
+		"error.team_milestone_awarded":         "このマイルストーンはすでにチームに付与されているため削除できません",
+		"error.too_many_rules":                 "1 つのキャンペーンに設定できるルールは最大 %d 件です",
+		"error.invalid_rule":                   "ルールの設定が無効です: %s",
+		"error.invalid_team_milestone":         "マイルストーンには受け取り回数、金額、またはその両方を指定してください",
+		"error.partner_not_found":              "パートナーが見つかりません",
+		"error.partner_code_taken":             "パートナーコードは既に使用されています",
+		"error.partner_already_attached":       "この企業には既にパートナーが紐付けられています",
+		"error.invalid_partner_key":            "パートナーキーが無効です",
+		"error.invalid_statement_range":        "明細の期間が無効です: 月は YYYY-MM 形式、開始は終了以前、最大 24 か月です",
+		"error.invalid_report_format":          "レポート形式が無効です: csv または xlsx を指定してください",
+		"error.invalid_report_range":           "レポートの期間が無効です: 日付は YYYY-MM-DD または RFC 3339 形式で、開始は終了より前である必要があります",
+		"error.invalid_event_range":            "イベントの期間が無効です: 日時は YYYY-MM-DD または RFC 3339 形式で、開始は終了より前である必要があります",
+		"error.report_export_not_found":        "エクスポートが見つからないか期限切れです",
+		"error.invalid_download_link":          "ダウンロードリンクが無効か期限切れです",
+		"error.sandbox_only":                   "この機能はサンドボックス企業のみ利用できます",
+		"error.sandbox_credit_payout":          "サンドボックスのキャンペーンはオンチェーンでのみ支払えます",
+		"error.invalid_faucet_amount":          "フォーセットの金額は正の値で上限以内である必要があります",
+		"error.faucet_limit_reached":           "フォーセットの1日の上限に達しました。明日もう一度お試しください",
+		"error.enterprise_email_taken":         "このメールアドレスの企業は既に存在します",
+		"error.invalid_analytics_interval":     "間隔は hour、day、week のいずれかである必要があります",
+		"error.invalid_analytics_breakdown":    "内訳は platform または token である必要があります",
+		"error.not_lucky_draw":                 "この紅包は検証可能なランダム配分ではありません",
+		"error.pocket_not_paused":              "このお年玉は一時停止されていません",
+		"error.claim_rate_limited":             "受け取りの試行が多すぎます。しばらくしてからもう一度お試しください",
+		"error.invalid_payout_splits":          "分配設定が無効です: %s",
+		"error.invalid_amount":                 "金額は正の数で、トークンの小数桁数以内にしてください",
+		"error.native_token_unsupported":       "ネイティブトークンのレッドポケットは一括決済なしのオンチェーン支払いのみ対応しています",
+		"error.native_share_below_fee":         "ネイティブトークンのお年玉は1個あたり支払いコスト %g %s を上回る必要があります",
+		"error.not_on_allowlist":               "このお年玉の許可リストに含まれていません",
+		"error.holder_proof_required":          "このお年玉はトークン保有者限定です。ウォレットアドレスと署名が必要です",
+		"error.token_gate_not_met":             "ウォレットに必要なトークンが不足しています",
+		"error.account_too_new":                "受け取るにはアカウント作成から %d 日以上経過している必要があります",
+		"error.region_not_eligible":            "このお年玉はお住まいの地域では受け取れません",
+		"error.claim_window_closed":            "このお年玉は指定された時間帯のみ受け取れます。次は %s に開きます",
+		"error.invalid_channel_limit":          "チャンネル上限が無効です: %s",
+		"error.invalid_claim_weight":           "受け取り倍率の設定が無効です: %s",
+		"error.claim_channel_not_listed":       "このお年玉は共有されたチャンネルでのみ受け取れます",
+		"error.channel_limit_reached":          "このお年玉のこのチャンネル向けの分はすべて受け取られました",
+		"error.invalid_eligibility_rule":       "受け取り条件の設定が無効です: %s",
+		"error.vault_not_found":                "貯蓄ボールトが見つかりません",
+		"error.vault_unavailable":              "この貯蓄ボールトはこのチェーンまたはトークンでは利用できません",
+		"error.claim_blocked":                  "この受け取りは不正対策によりブロックされました",
+		"error.assessment_not_found":           "フラグ付きの受け取りが見つかりません",
+		"error.assessment_reviewed":            "このフラグ付きの受け取りは審査済みです",
+		"error.payout_already_released":        "支払いはすでに実行されたため、却下できません",
+		"error.offramp_unavailable":            "法定通貨への出金は現在利用できません",
+		"error.offramp_network_unsupported":    "このチェーンでは法定通貨への出金に対応していません",
+		"error.webhook_not_found":              "Webhook が見つかりません",
+		"error.api_key_not_found":              "API キーが見つかりません",
+		"error.api_key_required":               "トークンは API キーで署名されたリクエストにのみ発行されます",
+		"error.api_key_exists":                 "この企業にはすでに API キーがあります。ローテーションしてください",
+		"error.enterprise_not_found":           "企業が見つかりません",
+		"error.support_reference":              "サポート参照番号：%s",
+		"error.claim_failure_not_found":        "この参照番号で記録された受け取りの失敗はありません",
+		"error.invalid_webhook_url":            "Webhook URL は http または https のアドレスである必要があります",
+		"error.digest_not_found":               "日次サマリーが設定されていません",
+		"error.invalid_digest_channel":         "サマリーのチャンネルが無効です: %s",
+		"error.email_not_configured":           "このサーバーではメール送信が設定されていません",
+		"error.report_subscriber_not_found":    "レポートの購読者が見つかりません",
+		"error.invalid_subscription_link":      "このリンクは無効です",
+		"error.invalid_report_week":            "週は終了した週の日付 (YYYY-MM-DD) で指定してください",
+		"error.campaign_budget_exceeded":       "紅包の金額がキャンペーンの残り予算を超えています",
+		"error.campaign_token_mismatch":        "紅包のトークンがキャンペーン予算のトークンと異なります",
+		"error.swap_unavailable":               "トークン変換は利用できません",
+		"error.service_degraded":               "依存サービスの復旧中のため一時的に読み取り専用です。しばらくしてから再度お試しください",
+		"error.downstream_unavailable":         "このリクエストが依存するサービスが一時的に利用できません。しばらくしてから再試行してください",
+		"error.request_nonce_required":         "署名付きの出金・送金・ブリッジリクエストには現在のタイムスタンプと 16〜128 文字の nonce が必要です",
+		"error.request_expired":                "リクエストのタイムスタンプがサーバー時刻から離れすぎています",
+		"error.request_replayed":               "このリクエストは既に受信されています。新しい nonce で署名し直してください",
+		"error.idempotency_key_invalid":        "Idempotency-Key は 255 文字以内にしてください",
+		"error.idempotency_key_in_progress":    "この Idempotency-Key のリクエストはまだ処理中です。しばらくしてから再試行してください",
+		"error.idempotency_key_reused":         "この Idempotency-Key は別のリクエストで既に使用されています",
+		"error.invalid_signature":              "リクエスト署名が無効です",
+		"error.invalid_api_key":                "API キーが無効です",
+		"error.travel_rule_not_found":          "この出金にはトラベルルール情報がありません",
+		"error.rate_limit_exceeded":            "リクエストが多すぎます",
+		"error.invalid_login_code":             "ログインコードが間違っているか期限切れです。新しいコードを取得してください",
+		"error.login_code_throttled":           "ログインコードを送信したばかりです。1分後に再度お試しください",
+		"error.login_platform_unsupported":     "ログインは Telegram と Discord のアカウントのみ対応しています",
+		"claimer.login_code":                   "レッドポケットのログインコードは %s です。%d 分間有効です。誰にも教えないでください。",
+
+		"diagnose.queued":                 "受け取りは記録され、送金は順番待ちです",
+		"diagnose.processing":             "送金中です",
+		"diagnose.retrying":               "送金に失敗しました。自動的に再試行されます",
+		"diagnose.held_for_review":        "送金前の審査のため保留されています",
+		"diagnose.blocked":                "送金はブロックされ、送られません",
+		"diagnose.refunded":               "送金に失敗し、金額はお年玉の作成者に返金されました",
+		"diagnose.payout_failed":          "何度か試みましたが送金できませんでした",
+		"diagnose.awaiting_confirmations": "送金はオンチェーンにあり、承認を待っています",
+		"diagnose.resubmitted":            "送金がチェーンから外れたため、再送しました",
+		"diagnose.confirmed":              "送金は確定しました",
+		"diagnose.reverted":               "送金トランザクションがオンチェーンで失敗しました",
+		"diagnose.reorged":                "送金がチェーンから外れ、再送できませんでした",
+		"diagnose.internal_error":         "当方のエラーにより受け取りに失敗しました",
+		"diagnose.action.none":            "これ以上の操作は不要です",
+		"diagnose.action.wait":            "操作は不要です。しばらくしてから確認してください",
+		"diagnose.action.retry":           "もう一度受け取ってください",
+		"diagnose.action.contact_creator": "受け取り ID またはサポート参照番号を添えてお年玉の作成者に連絡してください",
+
+		"digest.title":       "📊 %s (UTC) の日次サマリー",
+		"digest.pockets":     "🧧 作成されたお年玉: %d 件",
+		"digest.claims":      "🎁 受け取り: %d 件、%d 人",
+		"digest.distributed": "💰 配布額: %s",
+		"digest.top_pocket":  "🏆 最も人気のお年玉: %s (%s から)、受け取り %d 件、%s",
+
+		"reminder.title_24h": "⏰ 受け取り期限まであと 24 時間",
+		"reminder.title_1h":  "⏰ 受け取り期限まであと 1 時間",
+		"reminder.body":      "%s のお年玉はまだ %s が受け取られていません (残り %d/%d 件): %s",
+
+		"report.subject":         "%s の週次レポート: %s の週",
+		"report.title":           "%s 週次レポート",
+		"report.period":          "%s〜%s (UTC)",
+		"report.claims":          "受け取り件数",
+		"report.unique_claimers": "受け取り人数",
+		"report.amount":          "受け取り額",
+		"report.pockets":         "作成されたお年玉",
+		"report.conversion":      "お年玉あたりの受け取り",
+		"report.change_new":      "新規",
+		"report.by_day":          "日別の受け取り",
+		"report.by_platform":     "プラットフォーム別の受け取り",
+		"report.top_campaigns":   "上位のキャンペーン",
+		"report.no_activity":     "今週はお年玉の作成も受け取りもありませんでした。",
+		"report.footer":          "%s の週次レポートを購読しているため、このメールが送信されました。",
+		"report.unsubscribe":     "購読を解除",
+		"report.confirm_subject": "%s の週次レポートの購読を確認してください",
+		"report.confirm_body":    "%s のお年玉週次レポートの宛先に追加されました。確認すると毎週月曜日に届きます。",
+		"report.confirm":         "購読を確認",
+		"report.confirmed":       "%s の週次レポートを購読しました。",
+		"report.unsubscribed":    "%s の週次レポートは今後届きません。",
+	},
+	"es": {
+		"error.red_pocket_not_found":           "sobre rojo no encontrado",
+		"error.red_pocket_expired":             "el sobre rojo ha expirado",
+		"error.red_pocket_depleted":            "el sobre rojo ya fue reclamado por completo",
+		"error.red_pocket_inactive":            "el sobre rojo está %s",
+		"error.already_claimed":                "ya has reclamado este sobre rojo",
+		"error.insufficient_funds":             "fondos insuficientes en el sobre rojo",
+		"error.claim_in_progress":              "reclamo en curso, inténtalo de nuevo",
+		"error.transfer_failed":                "la transferencia falló",
+		"error.audience_proof_required":        "este sobre rojo está limitado a una lista de holders, se requieren dirección y firma",
+		"error.audience_bad_signature":         "la firma no coincide con la dirección indicada",
+		"error.not_in_audience":                "la dirección no es elegible para este sobre rojo",
+		"error.audience_already_used":          "esta dirección ya reclamó este sobre rojo",
+		"error.id_required":                    "el id es obligatorio",
+		"error.claim_not_found":                "reclamo no encontrado",
+		"error.payout_not_retryable":           "el pago no está en la cola de fallidos y no se puede reintentar",
+		"error.insufficient_balance":           "saldo insuficiente",
+		"error.invalid_address":                "dirección de billetera inválida",
+		"error.withdrawal_not_found":           "retiro no encontrado",
+		"error.withdrawal_below_minimum":       "el monto está por debajo del mínimo para este token o no cubre la comisión",
+		"error.instant_withdrawal_unavailable": "los retiros instantáneos no están disponibles para este token",
+		"error.pocket_not_expired":             "el sobre rojo aún no ha expirado",
+		"error.nothing_to_refund":              "el sobre rojo no tiene fondos restantes para reembolsar",
+		"error.terms_not_accepted":             "esta campaña requiere aceptar sus términos (versión %s) antes de reclamar",
+		"error.wallet_not_owned":               "Esa billetera no pertenece a este usuario",
+		"error.asset_not_consolidatable":       "Solo se puede consolidar un token disponible en la cadena de destino; los saldos nativos pagan el gas de las billeteras",
+		"error.xcm_transfer_not_sendable":      "Aquí solo se pueden solicitar transferencias desde Moonbeam o Astar, devueltas como una llamada que firma tu propia billetera",
+		"error.unsupported_token":              "Token no admitido; indica la dirección del contrato",
+		"error.address_sanctioned":             "Esta dirección no puede recibir fondos",
+		"error.travel_rule_required":           "Los retiros de %s o más requieren información de la regla de viaje del ordenante y del beneficiario",
+		"error.invalid_travel_rule":            "Información de la regla de viaje no válida: %s",
+		"error.claim_password_required":        "Este sobre rojo está protegido con contraseña",
+		"error.invalid_claim_password":         "Contraseña incorrecta",
+		"error.too_many_password_attempts":     "Demasiadas contraseñas incorrectas, inténtalo más tarde",
+		"error.captcha_required":               "Completa el CAPTCHA",
+		"error.captcha_failed":                 "La verificación CAPTCHA falló, inténtalo de nuevo",
+		"error.captcha_unavailable":            "Este proveedor de CAPTCHA no está configurado",
+		"error.claim_links_unavailable":        "Los enlaces de reclamo firmados no están configurados en este servidor",
+		"error.claim_link_required":            "Abre este sobre rojo desde el enlace de reclamo que recibiste",
+		"error.invalid_claim_link":             "Este enlace de reclamo no es válido",
+		"error.claim_link_expired":             "Este enlace de reclamo ha caducado",
+		"error.claim_link_wrong_recipient":     "Este enlace de reclamo se envió a otra persona",
+		"error.claim_link_used":                "Este enlace de reclamo ya se ha usado",
+		"error.pocket_links_unsigned":          "Este sobre rojo no se creó con enlaces firmados",
+		"error.invalid_claim_link_count":       "Solicita entre 1 y %v enlaces",
+		"error.invalid_recurrence":             "La recurrencia debe ser daily, weekly o una expresión cron de cinco campos",
+		"error.invalid_start_time":             "La hora de inicio no puede estar en el pasado",
+		"error.red_pocket_not_started":         "Este sobre rojo se abre el %s",
+		"error.pocket_not_scheduled":           "El sobre rojo ya comenzó o fue cancelado",
+		"error.red_pocket_paused":              "Este sobre rojo está en pausa por su creador; inténtalo más tarde",
+		"error.pocket_not_pausable":            "Solo se puede pausar un sobre rojo abierto",
+		"error.pocket_not_cancellable":         "Solo se puede cancelar un sobre rojo abierto o en pausa",
+		"error.pocket_not_extendable":          "Solo se puede extender un sobre rojo abierto o pausado",
+		"error.invalid_expiry":                 "Indica expiresAt o extendBy, para una expiración posterior a la actual",
+		"error.pocket_not_resizable":           "Solo se puede ajustar un sobre rojo abierto, pausado o programado, y uno de sorteo solo antes de que alguien lo reclame",
+		"error.supply_transfer_failed":         "No se pudo mover la diferencia entre la billetera de pagos del sobre rojo y la billetera de tu empresa; revisa su saldo e inténtalo de nuevo",
+		"error.invalid_supply_change":          "Indica un importe o número que deje al menos una parte sin reclamar, y una unidad mínima por parte; una vez reclamado, un sobre de reparto igual cambia por partes enteras",
+		"error.hunt_step_locked":               "Este sobre rojo es una etapa de una búsqueda del tesoro; reclama primero la etapa anterior",
+		"error.hunt_step_not_revealed":         "Esta etapa de la búsqueda del tesoro se te revela el %s",
+		"error.invalid_hunt":                   "Búsqueda del tesoro no válida: %s",
+		"error.team_not_found":                 "Ningún equipo tiene este código",
+		"error.already_in_team":                "Ya estás en un equipo de esta campaña",
+		"error.team_milestone_awarded":         "Un equipo ya recibió este hito, así que no se puede eliminar",
+		"error.too_many_rules":                 "Una campaña puede tener como máximo %d reglas",
+		"error.invalid_rule":                   "Regla no válida: %s",
+		"error.invalid_team_milestone":         "Indica para el hito un número de reclamos, un importe o ambos",
+		"error.partner_not_found":              "Socio no encontrado",
+		"error.partner_code_taken":             "El código de socio ya está en uso",
+		"error.partner_already_attached":       "Esta empresa ya tiene un socio asociado",
+		"error.invalid_partner_key":            "Clave de socio no válida",
+		"error.invalid_statement_range":        "Rango de extractos no válido: los meses son AAAA-MM, el inicio no puede ser posterior al final, máximo 24 meses",
+		"error.invalid_report_format":          "Formato de informe no válido: use csv o xlsx",
+		"error.invalid_report_range":           "Rango de informe no válido: las fechas son AAAA-MM-DD u horas RFC 3339, el inicio debe ser anterior al final",
+		"error.invalid_event_range":            "Rango de eventos no válido: las horas son AAAA-MM-DD o RFC 3339, el inicio debe ser anterior al final",
+		"error.report_export_not_found":        "Exportación no encontrada o caducada",
+		"error.invalid_download_link":          "Enlace de descarga no válido o caducado",
+		"error.sandbox_only":                   "Solo las empresas sandbox pueden usar esta función",
+		"error.sandbox_credit_payout":          "Las campañas sandbox solo pueden pagar en cadena",
+		"error.invalid_faucet_amount":          "El importe del faucet debe ser positivo y no superar el límite",
+		"error.faucet_limit_reached":           "Se alcanzó el límite diario del faucet, inténtalo mañana",
+		"error.enterprise_email_taken":         "Ya existe una empresa con este correo electrónico",
+		"error.invalid_analytics_interval":     "El intervalo debe ser hour, day o week",
+		"error.invalid_analytics_breakdown":    "El desglose debe ser platform o token",
+		"error.not_lucky_draw":                 "Este sobre rojo no es un sorteo verificable",
+		"error.pocket_not_paused":              "Este sobre rojo no está en pausa",
+		"error.claim_rate_limited":             "Demasiados intentos de reclamo; inténtalo en un momento",
+		"error.invalid_payout_splits":          "Reparto de pagos no válido: %s",
+		"error.invalid_amount":                 "El importe debe ser positivo y no tener más decimales que el token",
+		"error.native_token_unsupported":       "Los sobres en token nativo requieren pagos on-chain sin liquidación por lotes",
+		"error.native_share_below_fee":         "Cada parte de un sobre rojo en token nativo debe valer más que los %g %s que cuesta pagarla",
+		"error.not_on_allowlist":               "No estás en la lista de permitidos de este sobre rojo",
+		"error.holder_proof_required":          "Este sobre rojo está limitado a holders del token, se requieren dirección y firma",
+		"error.token_gate_not_met":             "Tu wallet no tiene suficiente del token requerido",
+		"error.account_too_new":                "Tu cuenta debe tener al menos %d días para reclamar",
+		"error.region_not_eligible":            "Este sobre rojo no está disponible en tu región",
+		"error.claim_window_closed":            "Este sobre rojo solo se puede reclamar en horarios definidos; vuelve a abrir el %s",
+		"error.invalid_channel_limit":          "Límite de canal no válido: %s",
+		"error.invalid_claim_weight":           "Ponderación de reclamo no válida: %s",
+		"error.claim_channel_not_listed":       "Este sobre rojo solo se puede reclamar en los canales donde se compartió",
+		"error.channel_limit_reached":          "La parte de este sobre rojo para este canal ya se reclamó",
+		"error.invalid_eligibility_rule":       "Regla de elegibilidad no válida: %s",
+		"error.vault_not_found":                "Bóveda de ahorro no encontrada",
+		"error.vault_unavailable":              "Esta bóveda de ahorro no está disponible para esta cadena o token",
+		"error.claim_blocked":                  "Este reclamo fue bloqueado por la protección antifraude",
+		"error.assessment_not_found":           "Reclamo marcado no encontrado",
+		"error.assessment_reviewed":            "Este reclamo marcado ya fue revisado",
+		"error.payout_already_released":        "El pago ya fue liberado y no se puede rechazar",
+		"error.offramp_unavailable":            "El retiro a moneda fiduciaria no está disponible",
+		"error.offramp_network_unsupported":    "El retiro a moneda fiduciaria no está disponible en esta red",
+		"error.webhook_not_found":              "Webhook no encontrado",
+		"error.api_key_not_found":              "Clave de API no encontrada",
+		"error.api_key_required":               "Los tokens solo se emiten a solicitudes firmadas con una clave de API",
+		"error.api_key_exists":                 "La empresa ya tiene una clave de API; rótela en su lugar",
+		"error.enterprise_not_found":           "empresa no encontrada",
+		"error.support_reference":              "Referencia de soporte: %s",
+		"error.claim_failure_not_found":        "No hay ningún fallo de reclamo registrado con esta referencia",
+		"error.invalid_webhook_url":            "La URL del webhook debe ser una dirección http o https",
+		"error.digest_not_found":               "No hay ningún resumen diario configurado",
+		"error.invalid_digest_channel":         "Canal de resumen no válido: %s",
+		"error.email_not_configured":           "El envío de correo no está configurado en este servidor",
+		"error.report_subscriber_not_found":    "Suscriptor del informe no encontrado",
+		"error.invalid_subscription_link":      "Este enlace no es válido",
+		"error.invalid_report_week":            "La semana debe ser una fecha (YYYY-MM-DD) de una semana terminada",
+		"error.campaign_budget_exceeded":       "El importe del sobre rojo supera el presupuesto restante de la campaña",
+		"error.campaign_token_mismatch":        "El token del sobre rojo no coincide con el del presupuesto de la campaña",
+		"error.swap_unavailable":               "La conversión de tokens no está disponible",
+		"error.service_degraded":               "El servicio está temporalmente en solo lectura mientras se recupera una dependencia, inténtalo de nuevo en breve",
+		"error.downstream_unavailable":         "Un servicio del que depende esta solicitud no está disponible, inténtalo de nuevo en breve",
+		"error.request_nonce_required":         "Las solicitudes firmadas de retiro, transferencia y puente necesitan una marca de tiempo actual y un nonce de 16 a 128 caracteres",
+		"error.request_expired":                "La marca de tiempo de la solicitud está demasiado lejos de la hora del servidor",
+		"error.request_replayed":               "Esta solicitud ya se recibió; firma una nueva con un nonce nuevo",
+		"error.idempotency_key_invalid":        "Idempotency-Key debe tener como máximo 255 caracteres",
+		"error.idempotency_key_in_progress":    "Una solicitud con esta Idempotency-Key aún se está procesando; vuelve a intentarlo en breve",
+		"error.idempotency_key_reused":         "Esta Idempotency-Key ya se usó para una solicitud diferente",
+		"error.invalid_signature":              "firma de solicitud no válida",
+		"error.invalid_api_key":                "clave de API no válida",
+		"error.travel_rule_not_found":          "Este retiro no tiene información de la regla de viaje",
+		"error.rate_limit_exceeded":            "límite de solicitudes excedido",
+		"error.invalid_login_code":             "El código de acceso es incorrecto o ha caducado; solicita uno nuevo",
+		"error.login_code_throttled":           "Acabamos de enviar un código de acceso, espera un minuto antes de pedir otro",
+		"error.login_platform_unsupported":     "Solo las cuentas de Telegram y Discord pueden iniciar sesión",
+		"claimer.login_code":                   "Tu código de acceso de Red Pocket es %s. Caduca en %d minutos. No lo compartas con nadie.",
+
+		"diagnose.queued":                 "Tu reclamo está registrado y su pago está en cola",
+		"diagnose.processing":             "Tu pago se está enviando",
+		"diagnose.retrying":               "El envío de tu pago falló; se reintenta automáticamente",
+		"diagnose.held_for_review":        "Tu pago está retenido para una revisión antes de enviarse",
+		"diagnose.blocked":                "Tu pago fue bloqueado y no se enviará",
+		"diagnose.refunded":               "Tu pago falló y su importe se devolvió al creador del sobre rojo",
+		"diagnose.payout_failed":          "No se pudo enviar tu pago tras varios intentos",
+		"diagnose.awaiting_confirmations": "Tu pago está en la cadena y espera confirmaciones",
+		"diagnose.resubmitted":            "Tu pago salió de la cadena y se envió de nuevo",
+		"diagnose.confirmed":              "Tu pago está confirmado",
+		"diagnose.reverted":               "La transacción de tu pago falló en la cadena",
+		"diagnose.reorged":                "Tu pago salió de la cadena y no se pudo enviar de nuevo",
+		"diagnose.internal_error":         "Tu reclamo falló por un error de nuestra parte",
+		"diagnose.action.none":            "No hace falta hacer nada más",
+		"diagnose.action.wait":            "No hace falta hacer nada; vuelve a consultar más tarde",
+		"diagnose.action.retry":           "Intenta reclamar de nuevo",
+		"diagnose.action.contact_creator": "Contacta al creador del sobre rojo con tu ID de reclamo o referencia de soporte",
+
+		"digest.title":       "📊 Resumen diario del %s (UTC)",
+		"digest.pockets":     "🧧 Sobres rojos creados: %d",
+		"digest.claims":      "🎁 Reclamos: %d de %d personas",
+		"digest.distributed": "💰 Distribuido: %s",
+		"digest.top_pocket":  "🏆 Sobre rojo más popular: %s de %s, %d reclamos, %s",
+
+		"reminder.title_24h": "⏰ Quedan 24 horas para reclamar",
+		"reminder.title_1h":  "⏰ Queda 1 hora para reclamar",
+		"reminder.body":      "Al sobre rojo de %s aún le quedan %s sin reclamar (%d de %d): %s",
+
+		"report.subject":         "Informe semanal de %s: semana del %s",
+		"report.title":           "Informe semanal de %s",
+		"report.period":          "Del %s al %s (UTC)",
+		"report.claims":          "Reclamos",
+		"report.unique_claimers": "Personas que reclamaron",
+		"report.amount":          "Importe reclamado",
+		"report.pockets":         "Sobres rojos creados",
+		"report.conversion":      "Reclamos por sobre rojo",
+		"report.change_new":      "nuevo",
+		"report.by_day":          "Reclamos por día",
+		"report.by_platform":     "Reclamos por plataforma",
+		"report.top_campaigns":   "Campañas destacadas",
+		"report.no_activity":     "Esta semana no se crearon ni reclamaron sobres rojos.",
+		"report.footer":          "Recibes este informe porque te suscribiste al informe semanal de %s.",
+		"report.unsubscribe":     "Cancelar suscripción",
+		"report.confirm_subject": "Confirma tu suscripción al informe semanal de %s",
+		"report.confirm_body":    "Te añadieron al informe semanal de sobres rojos de %s. Confirma para recibirlo cada lunes.",
+		"report.confirm":         "Confirmar suscripción",
+		"report.confirmed":       "Te suscribiste al informe semanal de %s.",
+		"report.unsubscribed":    "Ya no recibirás el informe semanal de %s.",
+	},
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const DefaultLocale = "en"
+
+type localeKey struct{}
+
+// Supported returns the locales that have a message catalog
+func Supported() []string {
+	locales := make([]string, 0, len(catalog))
+	for l := range catalog {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Normalize maps a BCP 47 tag (zh-CN, en_US) to a supported locale, or "" if unsupported
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if tag == "" {
+		return ""
+	}
+	if _, ok := catalog[tag]; ok {
+		return tag
+	}
+	base := strings.SplitN(tag, "-", 2)[0]
+	if _, ok := catalog[base]; ok {
+		return base
+	}
+	return ""
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := DefaultLocale, -1.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := Normalize(fields[0])
+		if locale == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > bestQ {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
+
+// T returns the localized message for key, falling back to English and then the key itself
+func T(locale, key string, args ...interface{}) string {
+	msg, ok := catalog[locale][key]
+	if !ok {
+		msg, ok = catalog[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// WithLocale stores the negotiated locale in the context
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale stored in the context, or the default locale
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Tc is T with the locale taken from the context
+func Tc(ctx context.Context, key string, args ...interface{}) string {
+	return T(FromContext(ctx), key, args...)
+}
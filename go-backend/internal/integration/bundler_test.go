@@ -0,0 +1,248 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var entryPointABI = mustABI(`[
+	{"type": "function", "name": "handleOps", "stateMutability": "nonpayable", "outputs": [], "inputs": [
+		{"name": "ops", "type": "tuple[]", "components": [
+			{"name": "sender", "type": "address"},
+			{"name": "nonce", "type": "uint256"},
+			{"name": "initCode", "type": "bytes"},
+			{"name": "callData", "type": "bytes"},
+			{"name": "callGasLimit", "type": "uint256"},
+			{"name": "verificationGasLimit", "type": "uint256"},
+			{"name": "preVerificationGas", "type": "uint256"},
+			{"name": "maxFeePerGas", "type": "uint256"},
+			{"name": "maxPriorityFeePerGas", "type": "uint256"},
+			{"name": "paymasterAndData", "type": "bytes"},
+			{"name": "signature", "type": "bytes"}
+		]},
+		{"name": "beneficiary", "type": "address"}
+	]},
+	{"type": "function", "name": "depositTo", "stateMutability": "payable", "outputs": [], "inputs": [
+		{"name": "account", "type": "address"}
+	]}
+]`)
+
+var userOperationEvent = crypto.Keccak256Hash([]byte("UserOperationEvent(bytes32,address,address,uint256,bool,uint256,uint256)"))
+
+func mustABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// rpcUserOp is a v0.6 user operation as the backend sends it
+type rpcUserOp struct {
+	Sender               string `json:"sender"`
+	Nonce                string `json:"nonce"`
+	InitCode             string `json:"initCode"`
+	CallData             string `json:"callData"`
+	CallGasLimit         string `json:"callGasLimit"`
+	VerificationGasLimit string `json:"verificationGasLimit"`
+	PreVerificationGas   string `json:"preVerificationGas"`
+	MaxFeePerGas         string `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     string `json:"paymasterAndData"`
+	Signature            string `json:"signature"`
+}
+
+// packedUserOp is the handleOps tuple
+type packedUserOp struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+func (op *rpcUserOp) pack() (packedUserOp, error) {
+	p := packedUserOp{Sender: common.HexToAddress(op.Sender)}
+	quantities := []struct {
+		dst **big.Int
+		src string
+	}{
+		{&p.Nonce, op.Nonce}, {&p.CallGasLimit, op.CallGasLimit}, {&p.VerificationGasLimit, op.VerificationGasLimit},
+		{&p.PreVerificationGas, op.PreVerificationGas}, {&p.MaxFeePerGas, op.MaxFeePerGas}, {&p.MaxPriorityFeePerGas, op.MaxPriorityFeePerGas},
+	}
+	for _, q := range quantities {
+		v, ok := new(big.Int).SetString(strings.TrimPrefix(q.src, "0x"), 16)
+		if !ok {
+			return p, fmt.Errorf("invalid quantity %q", q.src)
+		}
+		*q.dst = v
+	}
+	blobs := []struct {
+		dst *[]byte
+		src string
+	}{
+		{&p.InitCode, op.InitCode}, {&p.CallData, op.CallData}, {&p.PaymasterAndData, op.PaymasterAndData}, {&p.Signature, op.Signature},
+	}
+	for _, b := range blobs {
+		v, err := hex.DecodeString(strings.TrimPrefix(b.src, "0x"))
+		if err != nil {
+			return p, fmt.Errorf("invalid bytes %q", b.src)
+		}
+		*b.dst = v
+	}
+	return p, nil
+}
+
+type bundledOp struct {
+	txHash  string
+	success bool
+}
+
+// bundler stands in for Pimlico as both the bundler and the paymaster: each
+// operation is sent on its own in a handleOps transaction from the dev
+// account, and sponsored by the test paymaster. Calls it does not handle go
+// to the node.
+type bundler struct {
+	chain *chain
+
+	mu  sync.Mutex
+	ops map[string]bundledOp // by userOpHash
+}
+
+// startBundler serves the bundler until the test ends and returns its URL
+func startBundler(t *testing.T, c *chain) string {
+	t.Helper()
+	b := &bundler{chain: c, ops: make(map[string]bundledOp)}
+	srv := httptest.NewServer(b)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func (b *bundler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := b.handle(r.Context(), req.Method, req.Params)
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if err != nil {
+		resp["error"] = map[string]interface{}{"code": -32500, "message": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (b *bundler) handle(ctx context.Context, method string, params []json.RawMessage) (interface{}, error) {
+	switch method {
+	case "eth_estimateUserOperationGas":
+		// Enough for a first operation, which also deploys the wallet
+		return map[string]string{
+			"callGasLimit":         "0x30000",
+			"verificationGasLimit": "0x100000",
+			"preVerificationGas":   "0xc350",
+		}, nil
+
+	case "pm_sponsorUserOperation":
+		return map[string]string{"paymasterAndData": b.chain.paymaster}, nil
+
+	case "eth_sendUserOperation":
+		var op rpcUserOp
+		if len(params) == 0 || json.Unmarshal(params[0], &op) != nil {
+			return nil, fmt.Errorf("invalid user operation")
+		}
+		return b.send(ctx, &op)
+
+	case "eth_getUserOperationReceipt":
+		var hash string
+		if len(params) == 0 || json.Unmarshal(params[0], &hash) != nil {
+			return nil, fmt.Errorf("invalid user operation hash")
+		}
+		b.mu.Lock()
+		op, ok := b.ops[strings.ToLower(hash)]
+		b.mu.Unlock()
+		if !ok {
+			return nil, nil
+		}
+		return map[string]interface{}{
+			"receipt": map[string]string{"transactionHash": op.txHash},
+			"success": op.success,
+		}, nil
+	}
+
+	args := make([]interface{}, len(params))
+	for i, p := range params {
+		args[i] = p
+	}
+	return rpc(ctx, b.chain.rpcURL, method, args...)
+}
+
+// send bundles op into a handleOps transaction and returns its hash from the
+// UserOperationEvent it emitted. A failing op is simulated first, so the
+// caller gets the EntryPoint's revert reason.
+func (b *bundler) send(ctx context.Context, op *rpcUserOp) (string, error) {
+	packed, err := op.pack()
+	if err != nil {
+		return "", err
+	}
+	data, err := entryPointABI.Pack("handleOps", []packedUserOp{packed}, common.HexToAddress(b.chain.account))
+	if err != nil {
+		return "", err
+	}
+	call := map[string]string{
+		"from": b.chain.account,
+		"to":   b.chain.entryPoint,
+		"data": "0x" + hex.EncodeToString(data),
+	}
+	if _, err := rpc(ctx, b.chain.rpcURL, "eth_call", call, "latest"); err != nil {
+		return "", fmt.Errorf("handleOps reverted: %w", err)
+	}
+
+	receipt, err := b.chain.sendTx(ctx, b.chain.entryPoint, data, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, l := range receipt.Logs {
+		if !strings.EqualFold(l.Address, b.chain.entryPoint) || len(l.Topics) < 2 || !strings.EqualFold(l.Topics[0], userOperationEvent.Hex()) {
+			continue
+		}
+		// nonce, success, actualGasCost, actualGasUsed
+		event, err := hex.DecodeString(strings.TrimPrefix(l.Data, "0x"))
+		if err != nil || len(event) < 64 {
+			return "", fmt.Errorf("invalid UserOperationEvent data %q", l.Data)
+		}
+		hash := strings.ToLower(l.Topics[1])
+		b.mu.Lock()
+		b.ops[hash] = bundledOp{txHash: receipt.TransactionHash, success: event[63] == 1}
+		b.mu.Unlock()
+		return hash, nil
+	}
+	return "", fmt.Errorf("no UserOperationEvent in %s", receipt.TransactionHash)
+}
@@ -0,0 +1,302 @@
+//go:build integration
+
+// Package integration runs the payout path end to end against a local anvil
+// node: wallets are created for the EntryPoint v0.6 SimpleAccountFactory
+// deployed there, claims are paid by user operations an in-process bundler
+// submits through handleOps, and payouts are followed until the receipt
+// tracker confirms them.
+//
+//	go test -tags integration ./internal/integration/
+//
+// It needs anvil and forge on PATH, or ANVIL_RPC_URL pointing at a running
+// node, the contract libraries installed in testdata/contracts (see its
+// foundry.toml), and INTEGRATION_DATABASE_URL naming a Postgres database
+// the migrations are applied to. Tests skip when any of them is missing.
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// anvilChainID is anvil's default chain ID
+const anvilChainID = 31337
+
+const contractsDir = "testdata/contracts"
+
+// chain is an anvil node with the contracts deployed
+type chain struct {
+	rpcURL string
+	// anvil's first dev account, which anvil signs for: it deploys the
+	// contracts and sends the bundler's handleOps transactions
+	account string
+
+	entryPoint string
+	factory    string
+	token      string
+	paymaster  string
+}
+
+// startChain connects to ANVIL_RPC_URL or starts anvil, and deploys the
+// contracts
+func startChain(t *testing.T) *chain {
+	t.Helper()
+	c := &chain{rpcURL: os.Getenv("ANVIL_RPC_URL")}
+	if c.rpcURL == "" {
+		c.rpcURL = startAnvil(t)
+	}
+
+	var accounts []string
+	c.call(t, &accounts, "eth_accounts")
+	if len(accounts) == 0 {
+		t.Fatal("anvil has no dev accounts")
+	}
+	c.account = accounts[0]
+
+	buildContracts(t)
+	c.entryPoint = c.deploy(t, "AccountAbstraction.sol", "EntryPoint")
+	c.factory = c.deploy(t, "AccountAbstraction.sol", "SimpleAccountFactory", common.HexToAddress(c.entryPoint))
+	c.token = c.deploy(t, "TestToken.sol", "TestToken")
+	c.paymaster = c.deploy(t, "TestPaymaster.sol", "TestPaymaster", common.HexToAddress(c.entryPoint))
+
+	// The paymaster pays for operations out of its EntryPoint deposit
+	depositTo, err := entryPointABI.Pack("depositTo", common.HexToAddress(c.paymaster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.send(t, c.entryPoint, depositTo, big.NewInt(1e18))
+	return c
+}
+
+// startAnvil runs anvil on a free port until the test ends
+func startAnvil(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("anvil")
+	if err != nil {
+		t.Skip("anvil not found: install foundry or set ANVIL_RPC_URL")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := fmt.Sprint(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	cmd := exec.Command(path, "--port", port, "--chain-id", fmt.Sprint(anvilChainID), "--silent")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start anvil: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	url := "http://127.0.0.1:" + port
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := rpc(context.Background(), url, "eth_chainId"); err == nil {
+			return url
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("anvil did not start")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// buildContracts compiles testdata/contracts with forge. Without forge,
+// artifacts built earlier are used.
+func buildContracts(t *testing.T) {
+	t.Helper()
+	if _, err := os.Stat(filepath.Join(contractsDir, "lib", "account-abstraction")); err != nil {
+		t.Skip("contract libraries not installed: see " + filepath.Join(contractsDir, "foundry.toml"))
+	}
+	path, err := exec.LookPath("forge")
+	if err != nil {
+		if _, err := os.Stat(filepath.Join(contractsDir, "out")); err != nil {
+			t.Skip("forge not found and no contract artifacts built")
+		}
+		return
+	}
+	cmd := exec.Command(path, "build", "--root", contractsDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("forge build failed: %v\n%s", err, out)
+	}
+}
+
+// deploy deploys a contract from its forge artifact with ABI-encoded
+// constructor arguments and returns its address
+func (c *chain) deploy(t *testing.T, file, name string, args ...common.Address) string {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(contractsDir, "out", file, name+".json"))
+	if err != nil {
+		t.Fatalf("missing artifact for %s: %v", name, err)
+	}
+	var artifact struct {
+		Bytecode struct {
+			Object string `json:"object"`
+		} `json:"bytecode"`
+	}
+	if err := json.Unmarshal(raw, &artifact); err != nil {
+		t.Fatalf("invalid artifact for %s: %v", name, err)
+	}
+	code, err := hex.DecodeString(strings.TrimPrefix(artifact.Bytecode.Object, "0x"))
+	if err != nil {
+		t.Fatalf("invalid bytecode for %s: %v", name, err)
+	}
+	for _, arg := range args {
+		code = append(code, common.LeftPadBytes(arg.Bytes(), 32)...)
+	}
+
+	receipt := c.send(t, "", code, nil)
+	if receipt.ContractAddress == "" {
+		t.Fatalf("%s was not deployed", name)
+	}
+	return receipt.ContractAddress
+}
+
+type txReceipt struct {
+	TransactionHash string `json:"transactionHash"`
+	Status          string `json:"status"`
+	ContractAddress string `json:"contractAddress"`
+	Logs            []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	} `json:"logs"`
+}
+
+// send sends a transaction from the dev account, to "" for a deployment,
+// and returns its receipt once mined
+func (c *chain) send(t *testing.T, to string, data []byte, value *big.Int) *txReceipt {
+	t.Helper()
+	receipt, err := c.sendTx(context.Background(), to, data, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return receipt
+}
+
+func (c *chain) sendTx(ctx context.Context, to string, data []byte, value *big.Int) (*txReceipt, error) {
+	tx := map[string]string{
+		"from": c.account,
+		"data": "0x" + hex.EncodeToString(data),
+		"gas":  "0x1c9c380", // 30M, the block gas limit
+	}
+	if to != "" {
+		tx["to"] = to
+	}
+	if value != nil {
+		tx["value"] = fmt.Sprintf("0x%x", value)
+	}
+	result, err := rpc(ctx, c.rpcURL, "eth_sendTransaction", tx)
+	if err != nil {
+		return nil, err
+	}
+	var hash string
+	if err := json.Unmarshal(result, &hash); err != nil {
+		return nil, err
+	}
+
+	// anvil mines every transaction as it arrives
+	for i := 0; i < 50; i++ {
+		result, err := rpc(ctx, c.rpcURL, "eth_getTransactionReceipt", hash)
+		if err != nil {
+			return nil, err
+		}
+		var receipt *txReceipt
+		if err := json.Unmarshal(result, &receipt); err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			if receipt.Status != "0x1" {
+				return nil, fmt.Errorf("transaction %s reverted", hash)
+			}
+			return receipt, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil, fmt.Errorf("transaction %s was not mined", hash)
+}
+
+// call makes a JSON-RPC call to the node and decodes its result into out
+func (c *chain) call(t *testing.T, out interface{}, method string, params ...interface{}) {
+	t.Helper()
+	result, err := rpc(context.Background(), c.rpcURL, method, params...)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	if err := json.Unmarshal(result, out); err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+}
+
+// mint mints amount of the test token to owner
+func (c *chain) mint(t *testing.T, owner string, amount *big.Int) {
+	t.Helper()
+	// mint(address,uint256) selector: 0x40c10f19
+	data := append([]byte{0x40, 0xc1, 0x0f, 0x19}, common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	c.send(t, c.token, data, nil)
+}
+
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s %s", e.Code, e.Message, e.Data)
+}
+
+func rpc(ctx context.Context, url, method string, params ...interface{}) (json.RawMessage, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Error != nil {
+		return nil, out.Error
+	}
+	return out.Result, nil
+}
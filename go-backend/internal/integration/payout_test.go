@@ -0,0 +1,266 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/encryption"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"github.com/protocolbank/redpocket-backend/internal/service"
+)
+
+// openDatabase migrates INTEGRATION_DATABASE_URL and connects to it
+func openDatabase(t *testing.T) *repository.PostgresDB {
+	t.Helper()
+	url := os.Getenv("INTEGRATION_DATABASE_URL")
+	if url == "" {
+		t.Skip("INTEGRATION_DATABASE_URL not set")
+	}
+	m, err := repository.NewMigrator(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	db, err := repository.OpenPostgresDB(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+// backend is the payout side of the server, wired as in cmd/server against
+// the anvil chain and the test bundler
+type backend struct {
+	wallets   *service.WalletService
+	campaigns *repository.CampaignRepository
+	claims    *repository.ClaimRepository
+	pockets   *repository.RedPocketRepository
+	payouts   *service.PayoutQueue
+	receipts  *service.ReceiptTracker
+}
+
+func newBackend(t *testing.T, c *chain, bundlerURL string, db *repository.PostgresDB) *backend {
+	t.Helper()
+	cfg := config.Load()
+	cfg.RPCUrl = c.rpcURL
+	cfg.ChainID = anvilChainID
+	cfg.ChainRPCURLs = map[int64][]string{anvilChainID: {c.rpcURL}}
+	cfg.BundlerURL = bundlerURL
+	cfg.PaymasterURL = bundlerURL
+	cfg.EntryPoint = c.entryPoint
+	cfg.AccountFactory = c.factory
+	cfg.EntryPointVersions = nil
+	cfg.USDCAddress = c.token
+	cfg.WalletKeyProvider = ""
+	cfg.SanctionsProvider = ""
+	cfg.SettlementBatchWindow = 0
+	cfg.PayoutWorkers = 1
+	cfg.ReceiptConfirmations = 1
+	cfg.ReceiptHeadInterval = 1
+
+	enc, err := encryption.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimRepo := repository.NewClaimRepository(db)
+	redPocketRepo := repository.NewRedPocketRepository(db)
+	userOpRepo := repository.NewUserOpRepository(db)
+
+	walletSvc := service.NewWalletService(repository.NewWalletRepository(db, enc), userOpRepo, cfg)
+	notifier := service.NewNotifier(cfg)
+	tokenRegistry := service.NewTokenRegistry(repository.NewTokenRepository(db), cfg)
+	savingsSvc := service.NewSavingsService(repository.NewSavingsRepository(db), walletSvc, tokenRegistry, cfg)
+	webhookSvc := service.NewWebhookService(repository.NewWebhookRepository(db), claimRepo, redPocketRepo, cfg)
+	screener := service.NewSanctionsScreener(repository.NewSanctionsRepository(db), notifier, cfg)
+
+	return &backend{
+		wallets:   walletSvc,
+		campaigns: repository.NewCampaignRepository(db),
+		claims:    claimRepo,
+		pockets:   redPocketRepo,
+		payouts: service.NewPayoutQueue(repository.NewPayoutJobRepository(db), repository.NewPayoutBatchRepository(db),
+			claimRepo, redPocketRepo, userOpRepo, walletSvc, savingsSvc, screener, webhookSvc, notifier, cfg),
+		receipts: service.NewReceiptTracker(repository.NewReceiptRepository(db), claimRepo, walletSvc, savingsSvc,
+			service.NewXCMBridge(cfg), webhookSvc, cfg),
+	}
+}
+
+// start runs the payout workers and the receipt tracker until the test ends
+func (b *backend) start(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{}, 2)
+	go func() { b.payouts.Start(ctx); done <- struct{}{} }()
+	go func() { b.receipts.Start(ctx); done <- struct{}{} }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		<-done
+	})
+}
+
+// createPocket creates an open pocket of amount token units in a campaign
+// of the seeded development enterprise
+func (b *backend) createPocket(t *testing.T, token string, amount *big.Int, count int) *model.RedPocket {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	campaign := &model.Campaign{
+		ID:           "campaign_" + uuid.New().String()[:8],
+		EnterpriseID: "enterprise_default",
+		Name:         "integration",
+		TotalBudget:  model.NewUnits(amount).Float(6),
+		Token:        "USDC",
+		TokenAddress: token,
+		ChainID:      anvilChainID,
+		Platform:     "telegram",
+		Status:       "active",
+		PayoutMode:   "onchain",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := b.campaigns.Create(ctx, campaign); err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	rp := &model.RedPocket{
+		ID:              "rp_" + uuid.New().String()[:8],
+		CampaignID:      campaign.ID,
+		SenderName:      "integration",
+		Amount:          campaign.TotalBudget,
+		RemainingAmount: campaign.TotalBudget,
+		Token:           "USDC",
+		TokenAddress:    token,
+		ChainID:         anvilChainID,
+		Platform:        "telegram",
+		TotalCount:      count,
+		ExpiresAt:       now.Add(time.Hour),
+		CreatedAt:       now,
+		Status:          model.PocketActive,
+		Decimals:        6,
+		AmountUnits:     model.NewUnits(amount),
+		RemainingUnits:  model.NewUnits(amount),
+	}
+	if err := b.pockets.Create(ctx, rp); err != nil {
+		t.Fatalf("failed to create red pocket: %v", err)
+	}
+	return rp
+}
+
+// claim records a claim of amount to a new claimer's wallet with its payout
+// job, as RedPocketService.Claim does, and wakes the payout workers
+func (b *backend) claim(t *testing.T, rp *model.RedPocket, platformID string, amount *big.Int) (*model.Claim, *model.Wallet) {
+	t.Helper()
+	ctx := context.Background()
+	userID := "user_telegram_" + platformID
+	wallet, err := b.wallets.GetOrCreate(ctx, userID, rp.ChainID)
+	if err != nil {
+		t.Fatalf("failed to create claimer wallet: %v", err)
+	}
+	claim := &model.Claim{
+		ID:            "claim_" + uuid.New().String()[:8],
+		RedPocketID:   rp.ID,
+		ClaimerID:     userID,
+		PlatformID:    platformID,
+		Platform:      "telegram",
+		WalletAddress: wallet.Address,
+		Amount:        model.NewUnits(amount).Float(rp.Decimals),
+		AmountUnits:   model.NewUnits(amount),
+		Status:        model.ClaimPending,
+		CreatedAt:     time.Now(),
+	}
+	if _, err := b.pockets.ClaimWithPayout(ctx, claim, b.payouts.NewJob(claim.ID), nil); err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	b.payouts.Wake()
+	return claim, wallet
+}
+
+// waitConfirmed waits for the receipt tracker to confirm a claim's payout
+func (b *backend) waitConfirmed(t *testing.T, claimID string) *model.Claim {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Minute)
+	for {
+		claim, err := b.claims.GetByID(context.Background(), claimID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch claim.Status {
+		case model.ClaimConfirmed:
+			return claim
+		case model.ClaimFailed, model.ClaimReverted, model.ClaimReorged, model.ClaimBlocked:
+			t.Fatalf("claim %s ended %s", claimID, claim.Status)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("claim %s still %s", claimID, claim.Status)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func (b *backend) balance(t *testing.T, token, owner string) *big.Int {
+	t.Helper()
+	balance, err := b.wallets.TokenBalance(context.Background(), token, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+// TestClaimPayout pays two claims of a pocket from its payout wallet: the
+// first operation deploys the wallet through the factory, the second runs
+// on the deployed wallet at the next EntryPoint nonce. Each payout is
+// followed until it is confirmed.
+func TestClaimPayout(t *testing.T) {
+	c := startChain(t)
+	db := openDatabase(t)
+	b := newBackend(t, c, startBundler(t, c), db)
+	b.start(t)
+	ctx := context.Background()
+
+	total := big.NewInt(10_000_000) // 10 USDC
+	share := big.NewInt(5_000_000)
+	rp := b.createPocket(t, c.token, total, 2)
+
+	payout, err := b.wallets.GetOrCreate(ctx, service.PayoutWalletID(rp.ID), rp.ChainID)
+	if err != nil {
+		t.Fatalf("failed to create payout wallet: %v", err)
+	}
+	if payout.IsDeployed {
+		t.Fatal("new payout wallet is already deployed")
+	}
+	c.mint(t, payout.Address, total)
+
+	for i, platformID := range []string{"1001", "1002"} {
+		claim, wallet := b.claim(t, rp, platformID, share)
+		confirmed := b.waitConfirmed(t, claim.ID)
+		if confirmed.TxHash == "" {
+			t.Fatalf("claim %d confirmed without a transaction", i+1)
+		}
+		if got := b.balance(t, c.token, wallet.Address); got.Cmp(share) != 0 {
+			t.Fatalf("claimer %d has %s, want %s", i+1, got, share)
+		}
+	}
+
+	if got := b.balance(t, c.token, payout.Address); got.Sign() != 0 {
+		t.Fatalf("payout wallet kept %s after paying every share", got)
+	}
+	deployed, err := b.wallets.GetByAddress(ctx, payout.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deployed.IsDeployed {
+		t.Fatal("payout wallet not marked deployed")
+	}
+}
@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.23;
+
+// Pulls the EntryPoint and the account factory from the account-abstraction
+// library into the build, so their artifacts land in out/
+import {EntryPoint} from "account-abstraction/core/EntryPoint.sol";
+import {SimpleAccountFactory} from "account-abstraction/samples/SimpleAccountFactory.sol";
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.23;
+
+import {BasePaymaster} from "account-abstraction/core/BasePaymaster.sol";
+import {IEntryPoint} from "account-abstraction/interfaces/IEntryPoint.sol";
+import {UserOperation} from "account-abstraction/interfaces/UserOperation.sol";
+
+/// Sponsors every user operation out of its EntryPoint deposit, standing in
+/// for the bundler's sponsorship policy
+contract TestPaymaster is BasePaymaster {
+    constructor(IEntryPoint entryPoint) BasePaymaster(entryPoint) {}
+
+    function _validatePaymasterUserOp(UserOperation calldata, bytes32, uint256)
+        internal
+        pure
+        override
+        returns (bytes memory context, uint256 validationData)
+    {
+        return ("", 0);
+    }
+}
@@ -0,0 +1,131 @@
+// Package logging sets up the structured logger and carries the request ID
+// and trace ID of the request being served in its context, so every line
+// logged while serving it can be found together
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRequestIDLength caps the request IDs taken from callers
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+type traceIDKey struct{}
+
+// Setup makes a JSON (or, with LOG_FORMAT=text, key=value) logger at
+// LOG_LEVEL the default. Lines from the standard log package go through it
+// too, at info level.
+func Setup(cfg *config.Config) {
+	level := slog.LevelInfo
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	if strings.EqualFold(cfg.LogFormat, "text") {
+		h = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		h = slog.NewJSONHandler(os.Stdout, opts)
+	}
+	slog.SetDefault(slog.New(contextHandler{h}))
+}
+
+// contextHandler adds the request and trace IDs in a record's context, and
+// the span ID when the record is logged inside a span
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if id := TraceID(ctx); id != "" {
+		r.AddAttrs(slog.String("trace_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasSpanID() {
+		r.AddAttrs(slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// NewRequestID returns an ID for a request that came without one
+func NewRequestID() string {
+	return uuid.New().String()
+}
+
+// ValidRequestID reports whether a caller's request ID can be used as is:
+// not empty, at most 128 characters, and only letters, digits and . _ : -
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == ':', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithRequestID stores the request ID in the context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in the context, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTraceID stores the trace ID in the context
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceID returns the trace ID of the context's span, else the one stored
+// in the context, or ""
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// TraceIDFromTraceparent returns the trace ID of a W3C traceparent header,
+// "00-<32 hex trace ID>-<16 hex parent ID>-<2 hex flags>", or "" if it is
+// not one
+func TraceIDFromTraceparent(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || parts[0] == "ff" {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if strings.Trim(traceID, "0123456789abcdef") != "" || traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+	return traceID
+}
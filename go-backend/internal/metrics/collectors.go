@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"runtime"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	HTTPRequests = NewCounterVec("redpocket_http_requests_total",
+		"HTTP requests by route, method and status.", "method", "route", "status")
+	HTTPDuration = NewHistogramVec("redpocket_http_request_duration_seconds",
+		"HTTP request latency by route and method.", nil, "method", "route")
+
+	Claims = NewCounterVec("redpocket_claims_total",
+		"Claim attempts by result: success or the error code.", "result")
+	Payouts = NewCounterVec("redpocket_payouts_total",
+		"Claim payout attempts by outcome: success, pending, retry, failed, blocked.", "outcome")
+
+	BridgeTransferDuration = NewHistogramVec("redpocket_bridge_transfer_duration_seconds",
+		"Time from initiating a bridge transfer to its final status.",
+		[]float64{30, 60, 120, 300, 600, 900, 1800, 3600}, "protocol", "status")
+
+	LockAcquisitions = NewCounterVec("redpocket_redis_lock_acquisitions_total",
+		"Redis lock attempts by lock kind and result: acquired, contended, error.", "lock", "result")
+)
+
+func init() {
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+}
+
+// RegisterDBPool exposes the connection pool's stats
+func RegisterDBPool(pool *pgxpool.Pool) {
+	NewGaugeFunc("redpocket_db_pool_acquired_conns", "Connections currently in use.", func() float64 {
+		return float64(pool.Stat().AcquiredConns())
+	})
+	NewGaugeFunc("redpocket_db_pool_idle_conns", "Idle connections.", func() float64 {
+		return float64(pool.Stat().IdleConns())
+	})
+	NewGaugeFunc("redpocket_db_pool_total_conns", "Open connections.", func() float64 {
+		return float64(pool.Stat().TotalConns())
+	})
+	NewGaugeFunc("redpocket_db_pool_max_conns", "Maximum pool size.", func() float64 {
+		return float64(pool.Stat().MaxConns())
+	})
+	NewCounterFunc("redpocket_db_pool_acquires_total", "Connections acquired from the pool.", func() float64 {
+		return float64(pool.Stat().AcquireCount())
+	})
+	NewCounterFunc("redpocket_db_pool_empty_acquires_total", "Acquires that had to wait for a connection.", func() float64 {
+		return float64(pool.Stat().EmptyAcquireCount())
+	})
+	NewCounterFunc("redpocket_db_pool_acquire_seconds_total", "Time spent acquiring connections.", func() float64 {
+		return pool.Stat().AcquireDuration().Seconds()
+	})
+}
@@ -0,0 +1,266 @@
+// Package metrics keeps the service's counters, gauges and histograms and
+// renders them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit request latencies, in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector writes one metric family
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry holds the metrics exposed on /metrics
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// Default is the registry the package-level constructors register with
+var Default = &Registry{}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Render writes every metric in the text exposition format, sorted by name
+func (r *Registry) Render(w io.Writer) {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler serves the default registry. A non-empty token must be presented
+// as a bearer token.
+func Handler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if token != "" && req.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Default.Render(w)
+	})
+}
+
+// series is one label combination of a vector
+type series struct {
+	labelValues []string
+	value       float64
+	// histograms only
+	buckets []uint64
+	count   uint64
+}
+
+type vec struct {
+	metricName string
+	help       string
+	kind       string // counter, gauge, histogram
+	labels     []string
+	bounds     []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newVec(name, help, kind string, labels []string, bounds []float64) *vec {
+	v := &vec{
+		metricName: name,
+		help:       help,
+		kind:       kind,
+		labels:     labels,
+		bounds:     bounds,
+		series:     make(map[string]*series),
+	}
+	Default.register(v)
+	return v
+}
+
+func (v *vec) name() string { return v.metricName }
+
+// get returns the series of labelValues; the caller holds v.mu
+func (v *vec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", v.metricName, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if v.kind == "histogram" {
+			s.buckets = make([]uint64, len(v.bounds))
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *vec) write(w io.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.metricName, v.help, v.metricName, v.kind)
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := v.series[k]
+		if v.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", v.metricName, labelString(v.labels, s.labelValues, "", ""), formatValue(s.value))
+			continue
+		}
+		for i, bound := range v.bounds {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, labelString(v.labels, s.labelValues, "le", formatValue(bound)), s.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, labelString(v.labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.metricName, labelString(v.labels, s.labelValues, "", ""), formatValue(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.metricName, labelString(v.labels, s.labelValues, "", ""), s.count)
+	}
+}
+
+// CounterVec counts events per label combination
+type CounterVec struct{ v *vec }
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{v: newVec(name, help, "counter", labels, nil)}
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.v.mu.Lock()
+	defer c.v.mu.Unlock()
+	c.v.get(labelValues).value += delta
+}
+
+// GaugeVec holds a current value per label combination
+type GaugeVec struct{ v *vec }
+
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{v: newVec(name, help, "gauge", labels, nil)}
+}
+
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	g.v.get(labelValues).value = value
+}
+
+// HistogramVec buckets observations per label combination
+type HistogramVec struct{ v *vec }
+
+// NewHistogramVec takes ascending bucket upper bounds; nil uses DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	return &HistogramVec{v: newVec(name, help, "histogram", labels, buckets)}
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+
+	s := h.v.get(labelValues)
+	for i, bound := range h.v.bounds {
+		if value <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.count++
+	s.value += value
+}
+
+// FuncMetric reads its value at scrape time
+type FuncMetric struct {
+	metricName string
+	help       string
+	kind       string // counter, gauge
+	fn         func() float64
+}
+
+func NewGaugeFunc(name, help string, fn func() float64) *FuncMetric {
+	return newFuncMetric(name, help, "gauge", fn)
+}
+
+// NewCounterFunc exposes a cumulative value kept elsewhere
+func NewCounterFunc(name, help string, fn func() float64) *FuncMetric {
+	return newFuncMetric(name, help, "counter", fn)
+}
+
+func newFuncMetric(name, help, kind string, fn func() float64) *FuncMetric {
+	m := &FuncMetric{metricName: name, help: help, kind: kind, fn: fn}
+	Default.register(m)
+	return m
+}
+
+func (m *FuncMetric) name() string { return m.metricName }
+
+func (m *FuncMetric) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", m.metricName, m.help, m.metricName, m.kind, m.metricName, formatValue(m.fn()))
+}
+
+// labelString renders {a="1",b="2"}, with an extra label appended when
+// extraName is set
+func labelString(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, escapeLabel(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extraName, escapeLabel(extraValue))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprintf("%g", v)
+}
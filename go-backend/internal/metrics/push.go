@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// Pusher periodically pushes the default registry to a Prometheus push
+// gateway, for deployments that cannot be scraped
+type Pusher struct {
+	url        string
+	interval   time.Duration
+	httpClient *http.Client
+}
+
+// NewPusher returns a pusher for PUSHGATEWAY_URL, grouped by job and this
+// instance's hostname
+func NewPusher(cfg *config.Config) *Pusher {
+	if cfg.PushgatewayURL == "" {
+		return &Pusher{}
+	}
+	instance, _ := os.Hostname()
+	if instance == "" {
+		instance = "unknown"
+	}
+	return &Pusher{
+		url: fmt.Sprintf("%s/metrics/job/%s/instance/%s",
+			strings.TrimSuffix(cfg.PushgatewayURL, "/"), url.PathEscape(cfg.PushgatewayJob), url.PathEscape(instance)),
+		interval: time.Duration(cfg.PushgatewayInterval) * time.Second,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Start pushes until ctx is cancelled. Does nothing without a push gateway.
+func (p *Pusher) Start(ctx context.Context) {
+	if p.url == "" {
+		return
+	}
+	interval := p.interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.push(ctx); err != nil {
+				slog.ErrorContext(ctx, "metrics: push failed", "error", err)
+			}
+		}
+	}
+}
+
+func (p *Pusher) push(ctx context.Context) error {
+	var body bytes.Buffer
+	Default.Render(&body)
+
+	// PUT replaces every metric of this group
+	req, err := http.NewRequestWithContext(ctx, "PUT", p.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("push gateway returned %d", resp.StatusCode)
+	}
+	return nil
+}
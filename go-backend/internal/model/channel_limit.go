@@ -0,0 +1,20 @@
+package model
+
+// ChannelLimit caps the claims a red pocket takes from one of the channels
+// it is announced in, by count, by amount, or both; 0 is no cap. Claims
+// carry the channel they were made from, so one large group cannot drain a
+// pocket meant for several.
+type ChannelLimit struct {
+	ChannelID   string  `json:"channelId"`
+	MaxClaims   int     `json:"maxClaims,omitempty"`
+	Budget      float64 `json:"budget,omitempty"` // display value of BudgetUnits
+	BudgetUnits Units   `json:"budgetUnits"`
+}
+
+// ChannelUsage is how much of its limit a channel's claims have taken
+type ChannelUsage struct {
+	ChannelLimit
+	ClaimedCount int     `json:"claimedCount"`
+	Claimed      float64 `json:"claimed"`
+	ClaimedUnits Units   `json:"claimedUnits"`
+}
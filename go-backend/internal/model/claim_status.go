@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// ClaimStatus is where a claim is on its way to being paid. A claim only
+// moves along claimTransitions; ClaimRepository.Transition refuses any other
+// change, and claim_status_history records each one.
+type ClaimStatus string
+
+const (
+	ClaimPending    ClaimStatus = "pending"    // queued for payout
+	ClaimHeld       ClaimStatus = "held"       // queued, waiting for review or for its hold to run out
+	ClaimProcessing ClaimStatus = "processing" // transfer being sent
+	ClaimRetrying   ClaimStatus = "retrying"   // transfer failed before sending, queued to try again
+	// ClaimSubmitted is a transfer that was sent. It is stored and reported
+	// as "success", which API clients and webhooks already read.
+	ClaimSubmitted   ClaimStatus = "success"
+	ClaimResubmitted ClaimStatus = "resubmitted" // transfer replaced, waiting for the replacement
+	ClaimConfirmed   ClaimStatus = "confirmed"   // transfer final on chain
+	ClaimFailed      ClaimStatus = "failed"      // transfer failed for good
+	ClaimBlocked     ClaimStatus = "blocked"     // payout address sanctioned, or cancelled in review
+	ClaimReverted    ClaimStatus = "reverted"    // transfer reverted on chain, needs manual review
+	ClaimReorged     ClaimStatus = "reorged"     // transfer left the chain, needs manual review
+	ClaimRefunded    ClaimStatus = "refunded"    // never paid, amount returned to the pocket's creator
+)
+
+// claimTransitions lists the statuses each status may move to
+var claimTransitions = map[ClaimStatus][]ClaimStatus{
+	ClaimHeld:        {ClaimPending, ClaimBlocked},
+	ClaimPending:     {ClaimProcessing, ClaimRetrying, ClaimSubmitted, ClaimFailed, ClaimBlocked},
+	ClaimRetrying:    {ClaimProcessing, ClaimFailed, ClaimBlocked},
+	ClaimProcessing:  {ClaimSubmitted, ClaimResubmitted, ClaimRetrying, ClaimFailed, ClaimBlocked},
+	ClaimSubmitted:   {ClaimConfirmed, ClaimResubmitted, ClaimReverted, ClaimReorged},
+	ClaimResubmitted: {ClaimSubmitted, ClaimFailed},
+	ClaimFailed:      {ClaimRetrying, ClaimRefunded}, // retrying once its enterprise retries a dead-lettered payout
+}
+
+// CanTransition reports whether a claim may move from s to the status.
+// Staying in the same status is allowed, so repeated updates are harmless.
+func (s ClaimStatus) CanTransition(to ClaimStatus) bool {
+	if s == to {
+		return true
+	}
+	for _, next := range claimTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Final reports whether a claim in the status never moves again
+func (s ClaimStatus) Final() bool {
+	return len(claimTransitions[s]) == 0
+}
+
+// ClaimStatusesBefore returns the statuses a claim may move to the status
+// from, including the status itself
+func ClaimStatusesBefore(to ClaimStatus) []string {
+	from := []string{string(to)}
+	for s, next := range claimTransitions {
+		for _, n := range next {
+			if n == to && s != to {
+				from = append(from, string(s))
+			}
+		}
+	}
+	return from
+}
+
+// ClaimTransition is one status change of a claim
+type ClaimTransition struct {
+	From      ClaimStatus `json:"from,omitempty"` // empty when the claim was recorded
+	To        ClaimStatus `json:"to"`
+	TxHash    string      `json:"txHash,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
@@ -1,31 +1,90 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 )
 
 type RedPocket struct {
-	ID              string    `json:"id" db:"id"`
-	CampaignID      string    `json:"campaignId" db:"campaign_id"`
-	SenderName      string    `json:"senderName" db:"sender_name"`
-	SenderAvatar    string    `json:"senderAvatar,omitempty" db:"sender_avatar"`
-	Amount          float64   `json:"amount" db:"amount"`
-	RemainingAmount float64   `json:"remainingAmount" db:"remaining_amount"`
-	Token           string    `json:"token" db:"token"`
-	TokenAddress    string    `json:"tokenAddress" db:"token_address"`
-	ChainID         int64     `json:"chainId" db:"chain_id"`
-	Platform        string    `json:"platform" db:"platform"`
-	ChannelID       string    `json:"platformChannelId,omitempty" db:"channel_id"`
-	Message         string    `json:"message,omitempty" db:"message"`
-	Tag             string    `json:"tag,omitempty" db:"tag"`
-	TotalCount      int       `json:"totalCount" db:"total_count"`
-	ClaimedCount    int       `json:"claimedCount" db:"claimed_count"`
-	IsLuckyDraw     bool      `json:"isLuckyDraw" db:"is_lucky_draw"`
-	MinAmount       float64   `json:"minAmount,omitempty" db:"min_amount"`
-	MaxAmount       float64   `json:"maxAmount,omitempty" db:"max_amount"`
-	ExpiresAt       time.Time `json:"expiresAt" db:"expires_at"`
-	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
-	Status          string    `json:"status" db:"status"` // active, depleted, expired, cancelled
+	ID              string       `json:"id" db:"id"`
+	CampaignID      string       `json:"campaignId" db:"campaign_id"`
+	SenderName      string       `json:"senderName" db:"sender_name"`
+	SenderAvatar    string       `json:"senderAvatar,omitempty" db:"sender_avatar"`
+	Amount          float64      `json:"amount" db:"amount"`
+	RemainingAmount float64      `json:"remainingAmount" db:"remaining_amount"`
+	Token           string       `json:"token" db:"token"`
+	TokenAddress    string       `json:"tokenAddress" db:"token_address"`
+	ChainID         int64        `json:"chainId" db:"chain_id"`
+	Platform        string       `json:"platform" db:"platform"`
+	ChannelID       string       `json:"platformChannelId,omitempty" db:"channel_id"`
+	Message         string       `json:"message,omitempty" db:"message"`
+	Tag             string       `json:"tag,omitempty" db:"tag"`
+	TotalCount      int          `json:"totalCount" db:"total_count"`
+	ClaimedCount    int          `json:"claimedCount" db:"claimed_count"`
+	IsLuckyDraw     bool         `json:"isLuckyDraw" db:"is_lucky_draw"`
+	MinAmount       float64      `json:"minAmount,omitempty" db:"min_amount"`
+	MaxAmount       float64      `json:"maxAmount,omitempty" db:"max_amount"`
+	ExpiresAt       time.Time    `json:"expiresAt" db:"expires_at"`
+	CreatedAt       time.Time    `json:"createdAt" db:"created_at"`
+	Status          PocketStatus `json:"status" db:"status"`
+	CoverImage      string       `json:"coverImage,omitempty" db:"cover_image"`
+
+	// Claim protection: a bcrypt hash of the claim password, never serialized,
+	// and a CAPTCHA the claimer must solve (hcaptcha, turnstile)
+	ClaimPasswordHash string `json:"-" db:"claim_password_hash"`
+	CaptchaMode       string `json:"captchaMode,omitempty" db:"captcha_mode"`
+
+	// Claims need a signed claim link, so knowing the pocket's ID is not enough
+	SignedLinks bool `json:"signedLinks" db:"signed_links"`
+
+	// Per-channel caps; a pocket with any only takes claims from its listed
+	// channels
+	ChannelLimits []ChannelLimit `json:"channelLimits,omitempty" db:"channel_limits"`
+
+	// Scheduling: scheduled pockets open at StartsAt. Recurring pockets
+	// (daily, weekly or a cron expression in UTC) share SeriesID and each
+	// opening schedules the next instance, until RecurrenceUntil.
+	StartsAt        *time.Time `json:"startsAt,omitempty" db:"starts_at"`
+	Recurrence      string     `json:"recurrence,omitempty" db:"recurrence"`
+	RecurrenceUntil *time.Time `json:"recurrenceUntil,omitempty" db:"recurrence_until"`
+	SeriesID        string     `json:"seriesId,omitempty" db:"series_id"`
+
+	Locale string `json:"locale,omitempty" db:"locale"` // audience language, for discovery
+
+	// Exact amounts in the token's minor units; Amount and RemainingAmount
+	// are display values derived from them
+	Decimals       int   `json:"decimals" db:"decimals"`
+	AmountUnits    Units `json:"amountUnits" db:"amount_units"`
+	RemainingUnits Units `json:"remainingUnits" db:"remaining_units"`
+
+	// Platform fees under the campaign's plan when the pocket was created: a
+	// creation fee charged on top of Amount and a claim fee, flat plus
+	// ClaimFeeBps of each claim, withheld from every claim's payout
+	FeePlan          string  `json:"-" db:"fee_plan"`
+	CreationFee      float64 `json:"creationFee,omitempty" db:"creation_fee"`
+	CreationFeeUnits Units   `json:"creationFeeUnits" db:"creation_fee_units"`
+	ClaimFeeUnits    Units   `json:"claimFeeUnits" db:"claim_fee_units"`
+	ClaimFeeBps      int     `json:"claimFeeBps,omitempty" db:"claim_fee_bps"`
+
+	TestMode bool `json:"testMode,omitempty" db:"test_mode"` // sandbox pocket, paid out by simulated transfers
+
+	// Expiry reminders are posted in the pocket's channel as well as sent to
+	// the enterprise's pocket.expiring webhooks
+	RemindChannel bool `json:"remindChannel,omitempty" db:"remind_channel"`
+
+	// Lucky draws are drawn from DrawSeed, which is kept secret until the
+	// pocket closes; DrawCommitment (its SHA-256) is published up front.
+	// Under DrawScheme the pocket is split into Shares when it is created,
+	// stored one per slot and claimed one each.
+	DrawSeed       string  `json:"-" db:"draw_seed"`
+	DrawCommitment string  `json:"drawCommitment,omitempty" db:"draw_commitment"`
+	DrawScheme     string  `json:"drawScheme,omitempty" db:"draw_scheme"`
+	Shares         []Units `json:"-"`
+}
+
+// PasswordProtected reports whether claims need the red pocket's password
+func (rp *RedPocket) PasswordProtected() bool {
+	return rp.ClaimPasswordHash != ""
 }
 
 type Claim struct {
@@ -37,9 +96,47 @@ type Claim struct {
 	WalletAddress string    `json:"claimerWalletAddress" db:"wallet_address"`
 	Amount        float64   `json:"amount" db:"amount"`
 	TxHash        string    `json:"txHash,omitempty" db:"tx_hash"`
-	Status        string    `json:"status" db:"status"` // pending, processing, success, failed
+	Status        ClaimStatus `json:"status" db:"status"`
 	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
 	CompletedAt   *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+	Attempts      int       `json:"attempts" db:"attempts"`
+
+	// Terms acceptance, recorded when the campaign has terms
+	TermsVersion    string     `json:"termsVersion,omitempty" db:"terms_version"`
+	TermsAcceptedAt *time.Time `json:"termsAcceptedAt,omitempty" db:"terms_accepted_at"`
+	TermsIP         string     `json:"termsIp,omitempty" db:"terms_ip"`
+
+	// Latest sanctions screening of the payout address
+	ScreeningID     string `json:"screeningId,omitempty" db:"screening_id"`
+	ScreeningResult string `json:"screeningResult,omitempty" db:"screening_result"` // clear, hit
+
+	// Claimer's payout splits when the claim was made; the rest goes to WalletAddress
+	PayoutSplits []PayoutSplit `json:"payoutSplits,omitempty" db:"payout_splits"`
+
+	AmountUnits Units `json:"amountUnits" db:"amount_units"` // exact Amount in minor units
+
+	// Share of Amount donated to the campaign's charity, if it has one
+	CharityAddress string  `json:"charityAddress,omitempty" db:"charity_address"`
+	Donation       float64 `json:"donation,omitempty" db:"donation_amount"`
+	DonationUnits  Units   `json:"donationUnits" db:"donation_units"`
+
+	// Claimer's own share deposited into a savings vault instead of their wallet
+	SavingsVaultID string `json:"savingsVaultId,omitempty" db:"savings_vault_id"`
+	SavingsUnits   Units  `json:"savingsUnits" db:"savings_units"`
+
+	LinkID string `json:"linkId,omitempty" db:"link_id"` // one-time claim link the claim used
+
+	ChannelID string `json:"channelId,omitempty" db:"channel_id"` // chat channel the claim was made from
+
+	Multiplier float64 `json:"multiplier,omitempty" db:"multiplier"` // campaign claim weight applied to Amount; 0 when none
+
+	// Platform fee withheld from the claimer's payout, included in Amount
+	Fee      float64 `json:"fee,omitempty" db:"fee_amount"`
+	FeeUnits Units   `json:"feeUnits" db:"fee_units"`
+
+	TestMode bool `json:"testMode,omitempty" db:"test_mode"` // made on a sandbox pocket
+
+	Draw *LuckyDraw `json:"-"` // recorded with the claim, lucky draws only
 }
 
 type Wallet struct {
@@ -51,6 +148,8 @@ type Wallet struct {
 	IsDeployed bool      `json:"isDeployed" db:"is_deployed"`
 	PrivateKey string    `json:"-" db:"private_key"` // encrypted, never expose
 	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+
+	EntryPointVersion string `json:"entryPointVersion" db:"entry_point_version"` // 0.6, 0.7
 }
 
 type Campaign struct {
@@ -70,6 +169,8 @@ type Campaign struct {
 	Status        string    `json:"status" db:"status"`
 	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt     time.Time `json:"updatedAt" db:"updated_at"`
+	PayoutMode    string    `json:"payoutMode" db:"payout_mode"` // onchain, credit
+	TestMode      bool      `json:"testMode,omitempty" db:"test_mode"` // of a sandbox enterprise
 }
 
 type CampaignAnalytics struct {
@@ -79,13 +180,1372 @@ type CampaignAnalytics struct {
 	TotalClaims     int64   `json:"totalClaims"`
 	TotalPockets    int64   `json:"totalPockets"`
 	ActiveCampaigns int64   `json:"activeCampaigns"`
+	TotalDonated    float64 `json:"totalDonated"` // to campaign charities, across tokens
 }
 
 type Enterprise struct {
 	ID        string    `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
 	Email     string    `json:"email" db:"email"`
-	ApiKey    string    `json:"-" db:"api_key"`
 	Status    string    `json:"status" db:"status"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+
+	// The X-API-Key is only stored as its SHA-256 hash. A rotated key keeps
+	// authenticating until APIKeyPreviousExpiresAt.
+	APIKeyHash              string     `json:"-" db:"api_key_hash"`
+	APIKeyCreatedAt         *time.Time `json:"apiKeyCreatedAt,omitempty" db:"api_key_created_at"`
+	APIKeyPreviousHash      string     `json:"-" db:"api_key_previous_hash"`
+	APIKeyPreviousExpiresAt *time.Time `json:"apiKeyPreviousExpiresAt,omitempty" db:"api_key_previous_expires_at"`
+
+	// Partner whose code the enterprise attached, and when
+	PartnerID         string     `json:"partnerId,omitempty" db:"partner_id"`
+	PartnerAttachedAt *time.Time `json:"partnerAttachedAt,omitempty" db:"partner_attached_at"`
+
+	// A sandbox tenant: its campaigns are funded by the faucet and its
+	// pockets pay out through simulated transfers
+	TestMode bool `json:"testMode" db:"test_mode"`
+}
+
+type AudienceSnapshot struct {
+	ID           string     `json:"id" db:"id"`
+	CampaignID   string     `json:"campaignId" db:"campaign_id"`
+	Source       string     `json:"source" db:"source"` // upload, chain
+	TokenAddress string     `json:"tokenAddress,omitempty" db:"token_address"`
+	ChainID      int64      `json:"chainId" db:"chain_id"`
+	BlockNumber  int64      `json:"blockNumber" db:"block_number"`
+	MinBalance   string     `json:"minBalance" db:"min_balance"`
+	HolderCount  int        `json:"holderCount" db:"holder_count"`
+	Status       string     `json:"status" db:"status"` // pending, computing, ready, failed
+	Error        string     `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+}
+
+type AudienceEntry struct {
+	Address string `json:"address" db:"address"`
+	Balance string `json:"balance" db:"balance"`
+}
+
+type AudienceCoverage struct {
+	SnapshotID   string  `json:"snapshotId"`
+	Eligible     int64   `json:"eligible"`
+	Claimed      int64   `json:"claimed"`
+	CoverageRate float64 `json:"coverageRate"`
+}
+
+// PocketSummary is the frozen view of a finished red pocket
+type PocketSummary struct {
+	RedPocketID     string               `json:"redPocketId"`
+	CampaignID      string               `json:"campaignId"`
+	SenderName      string               `json:"senderName"`
+	SenderAvatar    string               `json:"senderAvatar,omitempty"`
+	CoverImage      string               `json:"coverImage,omitempty"`
+	Message         string               `json:"message,omitempty"`
+	Token           string               `json:"token"`
+	TokenAddress    string               `json:"tokenAddress"`
+	ChainID         int64                `json:"chainId"`
+	IsLuckyDraw     bool                 `json:"isLuckyDraw"`
+	TotalAmount     float64              `json:"totalAmount"`
+	ClaimedAmount   float64              `json:"claimedAmount"`
+	RemainingAmount float64              `json:"remainingAmount"`
+	TotalCount      int                  `json:"totalCount"`
+	ClaimedCount    int                  `json:"claimedCount"`
+	FinalStatus     PocketStatus         `json:"finalStatus"` // depleted, expired, cancelled, refunded
+	Claims          []PocketSummaryClaim `json:"claims"`
+	CreatedAt       time.Time            `json:"createdAt"`
+	ExpiresAt       time.Time            `json:"expiresAt"`
+	GeneratedAt     time.Time            `json:"generatedAt"`
+}
+
+type PocketSummaryClaim struct {
+	ClaimID       string     `json:"claimId"`
+	Platform      string     `json:"claimerPlatform"`
+	PlatformID    string     `json:"claimerPlatformId"`
+	WalletAddress string     `json:"claimerWalletAddress"`
+	Amount        float64    `json:"amount"`
+	Status        ClaimStatus `json:"status"`
+	TxHash        string     `json:"txHash,omitempty"`
+	TxURL         string     `json:"txUrl,omitempty"`
+	ClaimedAt     time.Time  `json:"claimedAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+}
+
+// PocketArchive is a stored PocketSummary; Summary holds the exact archived JSON
+type PocketArchive struct {
+	RedPocketID string          `json:"redPocketId" db:"red_pocket_id"`
+	Summary     json.RawMessage `json:"summary" db:"summary"`
+	ContentHash string          `json:"contentHash" db:"content_hash"`
+	IPFSCID     string          `json:"ipfsCid,omitempty" db:"ipfs_cid"`
+	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
+}
+
+type IPFSPin struct {
+	ID         string    `json:"id" db:"id"`
+	Kind       string    `json:"kind" db:"kind"` // cover_image, nft_metadata, pocket_summary
+	RefID      string    `json:"refId,omitempty" db:"ref_id"`
+	Name       string    `json:"name" db:"name"`
+	Provider   string    `json:"provider" db:"provider"`
+	CID        string    `json:"cid,omitempty" db:"cid"`
+	Size       int64     `json:"size" db:"size"`
+	Status     string    `json:"status" db:"status"` // pinning, pinned, unpinned, failed
+	Error      string    `json:"error,omitempty" db:"error"`
+	GatewayURL string    `json:"gatewayUrl,omitempty" db:"-"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// TrackedClaim is a successful claim whose payout has not reached finality yet
+type TrackedClaim struct {
+	ClaimID       string     `json:"claimId"`
+	RedPocketID   string     `json:"redPocketId"`
+	ClaimerID     string     `json:"claimerId"`
+	Amount        float64    `json:"amount"`
+	TxHash        string     `json:"txHash"`
+	ChainID       int64      `json:"chainId"`
+	TokenAddress  string     `json:"tokenAddress"`
+	BlockNumber   int64      `json:"blockNumber"`
+	BlockHash     string     `json:"blockHash,omitempty"`
+	ResubmitCount int        `json:"resubmitCount"`
+	SubmittedAt   *time.Time `json:"submittedAt,omitempty"` // when the current tx hash was sent
+	BatchID       string     `json:"batchId,omitempty"`
+	UserOpHash    string     `json:"userOpHash,omitempty"` // the included user operation, if paid through one
+}
+
+type ClaimReorg struct {
+	ID          int64     `json:"id" db:"id"`
+	ClaimID     string    `json:"claimId" db:"claim_id"`
+	TxHash      string    `json:"txHash" db:"tx_hash"`
+	BlockNumber int64     `json:"blockNumber" db:"block_number"`
+	BlockHash   string    `json:"blockHash,omitempty" db:"block_hash"`
+	Action      string    `json:"action" db:"action"` // resubmitted, escalated
+	NewTxHash   string    `json:"newTxHash,omitempty" db:"new_tx_hash"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// UserOperationRecord is a submitted user operation tracked until inclusion
+type UserOperationRecord struct {
+	UserOpHash  string          `json:"userOpHash" db:"user_op_hash"`
+	ClaimID     string          `json:"claimId,omitempty" db:"claim_id"`
+	BatchID     string          `json:"batchId,omitempty" db:"batch_id"`
+	Sender      string          `json:"sender" db:"sender"`
+	Nonce       string          `json:"nonce" db:"nonce"`
+	ChainID     int64           `json:"chainId" db:"chain_id"`
+	Kind        string          `json:"kind" db:"kind"` // transfer, cancel
+	Attempt     int             `json:"attempt" db:"attempt"`
+	Op          json.RawMessage `json:"op" db:"op"`
+	Status      string          `json:"status" db:"status"` // pending, included, replaced, failed
+	TxHash      string          `json:"txHash,omitempty" db:"tx_hash"`
+	SubmittedAt time.Time       `json:"submittedAt" db:"submitted_at"`
+	IncludedAt  *time.Time      `json:"includedAt,omitempty" db:"included_at"`
+}
+
+// PayoutJob is a queued on-chain transfer for a claim
+type PayoutJob struct {
+	ID        string     `json:"id" db:"id"`
+	ClaimID   string     `json:"claimId" db:"claim_id"`
+	Status    string     `json:"status" db:"status"` // queued, held, running, done, failed, dead
+	Attempts  int        `json:"attempts" db:"attempts"`
+	NextRunAt time.Time  `json:"nextRunAt" db:"next_run_at"`
+	LockedAt  *time.Time `json:"lockedAt,omitempty" db:"locked_at"`
+	LastError string     `json:"lastError,omitempty" db:"last_error"`
+	BatchID   string     `json:"batchId,omitempty" db:"batch_id"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// PayoutBatch is one on-chain settlement covering several claims of a pocket
+type PayoutBatch struct {
+	ID            string     `json:"id" db:"id"`
+	RedPocketID   string     `json:"redPocketId" db:"red_pocket_id"`
+	Sender        string     `json:"sender" db:"sender"`
+	Status        string     `json:"status" db:"status"` // processing, success, failed
+	ClaimCount    int        `json:"claimCount" db:"claim_count"`
+	TransferCount int        `json:"transferCount" db:"transfer_count"` // after netting per recipient
+	TotalAmount   float64    `json:"totalAmount" db:"total_amount"`
+	TxHash        string     `json:"txHash,omitempty" db:"tx_hash"`
+	UserOpHash    string     `json:"userOpHash,omitempty" db:"user_op_hash"`
+	Error         string     `json:"error,omitempty" db:"error"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	SettledAt     *time.Time `json:"settledAt,omitempty" db:"settled_at"`
+}
+
+// PayoutStatus is what claimers poll while their payout is in flight
+type PayoutStatus struct {
+	ClaimID       string       `json:"claimId"`
+	RedPocketID   string       `json:"redPocketId"`
+	Status        ClaimStatus  `json:"status"`
+	Amount        float64      `json:"amount"`
+	WalletAddress string       `json:"walletAddress"`
+	TxHash        string       `json:"txHash,omitempty"`
+	UserOpHash    string       `json:"userOpHash,omitempty"`
+	Attempts      int          `json:"attempts"`
+	LastError     string       `json:"lastError,omitempty"`
+	Batch         *PayoutBatch `json:"batch,omitempty"` // set when paid as part of a batched settlement
+	NextRetryAt   *time.Time   `json:"nextRetryAt,omitempty"`
+	DeadLettered  bool         `json:"deadLettered,omitempty"` // gave up for good; its enterprise can retry it
+	ClaimedAt     time.Time    `json:"claimedAt"`
+	CompletedAt   *time.Time   `json:"completedAt,omitempty"`
+
+	History []*ClaimTransition `json:"history,omitempty"` // status changes, oldest first
+}
+
+// ClaimDiagnosis tells a claimer which step of their claim failed or is
+// pending, and what they can do about it
+type ClaimDiagnosis struct {
+	ClaimID     string     `json:"claimId,omitempty"`
+	Reference   string     `json:"reference,omitempty"` // support reference of a claim that was never recorded
+	Status      string     `json:"status"`              // claim status, or refused / error when never recorded
+	Step        string     `json:"step"`                // claim, lock, eligibility, transfer, receipt
+	Reason      string     `json:"reason"`
+	ErrorClass  string     `json:"errorClass,omitempty"` // of the last transfer error: funds, sponsorship, network, interrupted, other
+	Action      string     `json:"action"`               // none, wait, retry, contact_creator
+	Message     string     `json:"message"`
+	ActionHint  string     `json:"actionHint"`
+	TxHash      string     `json:"txHash,omitempty"`
+	NextRetryAt *time.Time `json:"nextRetryAt,omitempty"`
+}
+
+// LedgerBalance is a user's off-chain balance credited by credit-mode campaigns
+type LedgerBalance struct {
+	UserID       string    `json:"userId" db:"user_id"`
+	ChainID      int64     `json:"chainId" db:"chain_id"`
+	Token        string    `json:"token" db:"token"`
+	TokenAddress string    `json:"tokenAddress" db:"token_address"`
+	Balance      float64   `json:"balance" db:"balance"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// LedgerEntry is one movement of a ledger balance; amounts are signed
+type LedgerEntry struct {
+	ID           string    `json:"id" db:"id"`
+	UserID       string    `json:"userId" db:"user_id"`
+	ChainID      int64     `json:"chainId" db:"chain_id"`
+	Token        string    `json:"token" db:"token"`
+	TokenAddress string    `json:"tokenAddress" db:"token_address"`
+	Amount       float64   `json:"amount" db:"amount"`
+	Kind         string    `json:"kind" db:"kind"` // claim, withdrawal, refund, donation, creation_fee, claim_fee, team_bonus
+	RefID        string    `json:"refId" db:"ref_id"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Withdrawal moves a ledger balance, or the balance of the user's own AA
+// wallet, on chain to an external address
+type Withdrawal struct {
+	ID           string     `json:"id" db:"id"`
+	UserID       string     `json:"userId" db:"user_id"`
+	Source       string     `json:"source" db:"source"`                       // ledger, wallet
+	FromAddress  string     `json:"fromAddress,omitempty" db:"from_address"` // wallet source only
+	ChainID      int64      `json:"chainId" db:"chain_id"`
+	Token        string     `json:"token" db:"token"`
+	TokenAddress string     `json:"tokenAddress" db:"token_address"`
+	Amount       float64    `json:"amount" db:"amount"`
+	ToAddress    string     `json:"toAddress" db:"to_address"`
+	Speed        string     `json:"speed" db:"speed"` // instant, batch
+	Fee          float64    `json:"fee" db:"fee"`     // kept from amount; the recipient gets amount - fee
+	BatchID      string     `json:"batchId,omitempty" db:"batch_id"`
+	Status       string     `json:"status" db:"status"` // awaiting_order (off-ramp), pending, processing, success, failed
+	TxHash       string     `json:"txHash,omitempty" db:"tx_hash"`
+	UserOpHash   string     `json:"userOpHash,omitempty" db:"user_op_hash"`
+	Error        string     `json:"error,omitempty" db:"error"`
+	ScheduledAt  time.Time  `json:"scheduledAt" db:"scheduled_at"` // earliest processing time
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+
+	// Sanctions screening of the withdrawal address
+	ScreeningID     string `json:"screeningId,omitempty" db:"screening_id"`
+	ScreeningResult string `json:"screeningResult,omitempty" db:"screening_result"` // clear, hit
+
+	TravelRule *TravelRuleInfo `json:"travelRule,omitempty" db:"travel_rule"`
+
+	// Set when the withdrawal cashes out through a fiat off-ramp
+	OffRamp *OffRampOrder `json:"offRamp,omitempty"`
+}
+
+// NetAmount is what reaches the withdrawal address
+func (w *Withdrawal) NetAmount() float64 {
+	return w.Amount - w.Fee
+}
+
+// OffRampOrder is the fiat side of a withdrawal cashed out through an
+// off-ramp provider
+type OffRampOrder struct {
+	Provider      string  `json:"provider" db:"offramp_provider"`          // transak
+	OrderID       string  `json:"orderId,omitempty" db:"offramp_order_id"` // set once the user places the order
+	Status        string  `json:"status,omitempty" db:"offramp_status"`    // the provider's order status
+	FiatCurrency  string  `json:"fiatCurrency" db:"fiat_currency"`
+	FiatAmount    float64 `json:"fiatAmount,omitempty" db:"fiat_amount"` // quoted, then what the provider pays out
+	PaymentMethod string  `json:"paymentMethod,omitempty" db:"payment_method"`
+}
+
+// OffRampQuote is what an off-ramp provider pays out in fiat for an amount
+// of tokens
+type OffRampQuote struct {
+	Provider      string  `json:"provider"`
+	QuoteID       string  `json:"quoteId,omitempty"`
+	Token         string  `json:"token"`
+	ChainID       int64   `json:"chainId"`
+	Amount        float64 `json:"amount"`
+	FiatCurrency  string  `json:"fiatCurrency"`
+	FiatAmount    float64 `json:"fiatAmount"` // after fees
+	Fee           float64 `json:"fee"`        // in fiat
+	Rate          float64 `json:"rate"`       // fiat per token
+	PaymentMethod string  `json:"paymentMethod,omitempty"`
+}
+
+// PlatformRevenueAccount is the ledger account platform fees are booked to
+const PlatformRevenueAccount = "platform_revenue"
+
+// PlatformFeeSchedule is what the platform charges enterprises on a plan:
+// flat fees in token units plus basis points of the amount
+type PlatformFeeSchedule struct {
+	Plan           string  `json:"plan" db:"plan"`
+	CreationFee    float64 `json:"creationFee" db:"creation_fee"`
+	CreationFeeBps int     `json:"creationFeeBps" db:"creation_fee_bps"`
+	ClaimFee       float64 `json:"claimFee" db:"claim_fee"`
+	ClaimFeeBps    int     `json:"claimFeeBps" db:"claim_fee_bps"`
+}
+
+// WithdrawalTokenSettings are the limits and fees for withdrawing one token
+type WithdrawalTokenSettings struct {
+	Token          string  `json:"token" db:"token"`
+	MinAmount      float64 `json:"minAmount" db:"min_amount"`
+	BatchFee       float64 `json:"batchFee" db:"batch_fee"`
+	InstantEnabled bool    `json:"instantEnabled" db:"instant_enabled"`
+	InstantFee     float64 `json:"instantFee" db:"instant_fee"`
+	InstantFeeBps  int     `json:"instantFeeBps" db:"instant_fee_bps"`
+}
+
+// WithdrawalQuote is the cost and timing of withdrawing an amount at one speed
+type WithdrawalQuote struct {
+	Speed       string    `json:"speed"`
+	Token       string    `json:"token"`
+	ChainID     int64     `json:"chainId"`
+	Amount      float64   `json:"amount"`
+	Fee         float64   `json:"fee"`
+	NetAmount   float64   `json:"netAmount"`
+	MinAmount   float64   `json:"minAmount"`
+	Available   bool      `json:"available"`
+	Reason      string    `json:"reason,omitempty"` // why the quote is unavailable
+	EstimatedAt time.Time `json:"estimatedAt"`      // when the withdrawal will be processed
+}
+
+// Refund returns the unclaimed remainder of an expired red pocket from its
+// payout wallet to the funding enterprise's wallet
+type Refund struct {
+	ID           string     `json:"id" db:"id"`
+	RedPocketID  string     `json:"redPocketId" db:"red_pocket_id"`
+	RecipientID  string     `json:"recipientId" db:"recipient_id"` // enterprise ID
+	ChainID      int64      `json:"chainId" db:"chain_id"`
+	Token        string     `json:"token" db:"token"`
+	TokenAddress string     `json:"tokenAddress" db:"token_address"`
+	Amount       float64    `json:"amount" db:"amount"`
+	AmountUnits  Units      `json:"amountUnits" db:"amount_units"`
+	FromAddress  string     `json:"fromAddress" db:"from_address"`
+	ToAddress    string     `json:"toAddress" db:"to_address"`
+	Status       string     `json:"status" db:"status"`        // pending, processing, success, failed
+	Trigger      string     `json:"trigger" db:"triggered_by"` // auto, manual, cancel
+	TxHash       string     `json:"txHash,omitempty" db:"tx_hash"`
+	UserOpHash   string     `json:"userOpHash,omitempty" db:"user_op_hash"`
+	Error        string     `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+}
+
+// ClaimPageConfig brands the hosted claim page of a campaign's pockets.
+// Empty fields fall back to the default page.
+type ClaimPageConfig struct {
+	LogoURL         string        `json:"logoUrl,omitempty"`
+	PrimaryColor    string        `json:"primaryColor,omitempty"`
+	BackgroundColor string        `json:"backgroundColor,omitempty"`
+	TextColor       string        `json:"textColor,omitempty"`
+	Title           string        `json:"title,omitempty"`
+	Subtitle        string        `json:"subtitle,omitempty"`
+	ButtonText      string        `json:"buttonText,omitempty"`
+	SuccessMessage  string        `json:"successMessage,omitempty"`
+	CTA             *ClaimPageCTA `json:"cta,omitempty"` // shown after a successful claim
+	Disclaimer      string        `json:"disclaimer,omitempty"`
+	UpdatedAt       *time.Time    `json:"updatedAt,omitempty"`
+}
+
+type ClaimPageCTA struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// PocketEvent is pushed to live pocket streams: a snapshot on connect, then
+// one per claim, per status change and per supply change
+type PocketEvent struct {
+	Type            string       `json:"type"` // snapshot, claim, status, supply
+	RedPocketID     string       `json:"redPocketId"`
+	Status          PocketStatus `json:"status"`
+	ClaimedCount    int          `json:"claimedCount"`
+	TotalCount      int          `json:"totalCount"`
+	RemainingCount  int          `json:"remainingCount"`
+	Amount          float64      `json:"amount"`
+	RemainingAmount float64      `json:"remainingAmount"`
+	ExpiresAt       time.Time    `json:"expiresAt"`
+	ClaimID         string       `json:"claimId,omitempty"`
+	ClaimAmount     float64      `json:"claimAmount,omitempty"`
+	At              time.Time    `json:"at"`
+}
+
+// Change is a row change announced by the database change feed
+type Change struct {
+	Table       string  `json:"table"` // red_pockets, claims
+	Op          string  `json:"op"`    // INSERT, UPDATE
+	ID          string  `json:"id"`
+	RedPocketID string  `json:"redPocketId"`
+	Status      string  `json:"status"`
+	OldStatus   string  `json:"oldStatus,omitempty"`
+	Amount      float64 `json:"amount,omitempty"`
+	Supply      bool    `json:"supply,omitempty"` // a pocket's amount or count changed
+}
+
+// PocketAnnouncement is a chat message a pocket was announced in
+type PocketAnnouncement struct {
+	RedPocketID string    `json:"redPocketId"`
+	Platform    string    `json:"platform"`
+	ChannelID   string    `json:"channelId"`
+	MessageID   string    `json:"messageId"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// LoggedEvent is a change to a pocket, claim, payout job or refund kept in
+// the event log, with the full row as it stood after the change
+type LoggedEvent struct {
+	ID          int64           `json:"id"`
+	Table       string          `json:"table"` // red_pockets, claims, payout_jobs, red_pocket_refunds
+	Op          string          `json:"op"`    // INSERT, UPDATE
+	RowID       string          `json:"rowId"`
+	RedPocketID string          `json:"redPocketId,omitempty"`
+	Status      string          `json:"status,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// EventLogFilter narrows a read of the event log
+type EventLogFilter struct {
+	From        time.Time
+	To          time.Time
+	RedPocketID string
+	AfterID     int64
+	Limit       int
+}
+
+// Webhook is an enterprise URL told about events on its campaigns' pockets
+type Webhook struct {
+	ID           string    `json:"id" db:"id"`
+	EnterpriseID string    `json:"enterpriseId" db:"enterprise_id"`
+	URL          string    `json:"url" db:"url"`
+	Secret       string    `json:"secret,omitempty" db:"secret"` // signs deliveries; only returned when the webhook is created
+	Events       []string  `json:"events" db:"events"`
+	Description  string    `json:"description,omitempty" db:"description"`
+	Enabled      bool      `json:"enabled" db:"enabled"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// APIKey lets an enterprise sign its API requests instead of sending a
+// bearer token
+type APIKey struct {
+	ID           string     `json:"id" db:"id"`
+	EnterpriseID string     `json:"enterpriseId" db:"enterprise_id"`
+	Name         string     `json:"name" db:"name"`
+	Secret       string     `json:"secret,omitempty" db:"secret"` // signs requests; only returned when the key is created
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+}
+
+// ClaimFailure is a failed claim attempt, found by the support reference the
+// claimer was shown
+type ClaimFailure struct {
+	Reference         string    `json:"reference" db:"reference"`
+	RedPocketID       string    `json:"redPocketId" db:"red_pocket_id"`
+	Platform          string    `json:"platform" db:"platform"`
+	PlatformID        string    `json:"platformId" db:"platform_id"`
+	WalletAddress     string    `json:"walletAddress,omitempty" db:"wallet_address"`
+	ClientIP          string    `json:"clientIp,omitempty" db:"client_ip"`
+	Country           string    `json:"country,omitempty" db:"country"`
+	DeviceFingerprint string    `json:"deviceFingerprint,omitempty" db:"device_fingerprint"`
+	Locale            string    `json:"locale" db:"locale"`
+	ErrorCode         string    `json:"errorCode,omitempty" db:"error_code"` // empty for internal errors
+	Error             string    `json:"error" db:"error"`                    // as shown to the claimer, or the internal error
+	RequestID         string    `json:"requestId,omitempty" db:"request_id"` // the request's ID in the logs
+	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
+}
+
+// JWTSigningKey signs enterprise tokens. Its public half is published in
+// the JWKS until it retires.
+type JWTSigningKey struct {
+	KID        string     `json:"kid" db:"kid"`
+	Algorithm  string     `json:"alg" db:"algorithm"`
+	PrivateKey string     `json:"-" db:"private_key"` // PKCS#8 DER, base64
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	RetiresAt  *time.Time `json:"retiresAt,omitempty" db:"retires_at"`
+}
+
+// SlackInstallation is a Slack workspace that installed the app for an
+// enterprise
+type SlackInstallation struct {
+	TeamID       string    `json:"teamId" db:"team_id"`
+	TeamName     string    `json:"teamName" db:"team_name"`
+	EnterpriseID string    `json:"enterpriseId" db:"enterprise_id"`
+	BotUserID    string    `json:"botUserId" db:"bot_user_id"`
+	BotToken     string    `json:"-" db:"bot_token"`
+	Scope        string    `json:"scope" db:"scope"`
+	InstalledBy  string    `json:"installedBy" db:"installed_by"` // Slack user ID
+	InstalledAt  time.Time `json:"installedAt" db:"installed_at"`
+}
+
+// WebhookEvent is the body POSTed to webhooks
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is one event sent, or being retried, to one webhook
+type WebhookDelivery struct {
+	ID             string          `json:"id" db:"id"`
+	WebhookID      string          `json:"webhookId" db:"webhook_id"`
+	EventID        string          `json:"eventId" db:"event_id"`
+	EventType      string          `json:"eventType" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"` // pending, sending, success, failed
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt" db:"next_attempt_at"`
+	ResponseStatus int             `json:"responseStatus,omitempty" db:"response_status"`
+	Error          string          `json:"error,omitempty" db:"error"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty" db:"delivered_at"`
+
+	// The webhook's URL and secret, loaded with deliveries due to be sent
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// CampaignTerms are the terms of service claimers of a campaign must accept
+type CampaignTerms struct {
+	Version   string     `json:"version"`
+	Text      string     `json:"text"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// CampaignVisibility controls whether a campaign's pockets appear on the
+// public discovery feed
+type CampaignVisibility struct {
+	Discoverable bool       `json:"discoverable"`
+	MaskAmounts  bool       `json:"maskAmounts"` // list pockets without their amounts
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+}
+
+// CampaignCharity routes a share of every claim of a campaign to a charity
+type CampaignCharity struct {
+	Address      string     `json:"address,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	Bps          int        `json:"bps"` // share of each claim in basis points; 0 disables
+	TotalDonated float64    `json:"totalDonated"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+}
+
+// Active reports whether claims donate anything
+func (c *CampaignCharity) Active() bool {
+	return c != nil && c.Bps > 0 && c.Address != ""
+}
+
+// CampaignClaimWeights multiply the claim amount of claimers with an
+// attribute. A claimer matching several weights gets the highest multiplier.
+type CampaignClaimWeights struct {
+	Weights   []ClaimWeight `json:"weights"`
+	UpdatedAt *time.Time    `json:"updatedAt,omitempty"`
+}
+
+// ClaimWeight is a claim amount multiplier and the attribute it rewards
+type ClaimWeight struct {
+	Attribute string `json:"attribute"` // discord_role, telegram_admin, nft_tier
+
+	// discord_role: a role in a Discord guild the bot is a member of
+	GuildID string `json:"guildId,omitempty"`
+	RoleID  string `json:"roleId,omitempty"`
+
+	// nft_tier: holding at least MinBalance tokens of TokenAddress on
+	// CHAIN_ID, proven with the same wallet signature as token gates
+	TokenAddress string `json:"tokenAddress,omitempty"`
+	MinBalance   string `json:"minBalance,omitempty"`
+
+	Multiplier float64 `json:"multiplier"` // over 1, at most 10
+}
+
+// DiscoveryPocket is an active pocket as listed on the discovery feed.
+// Amounts are nil when the campaign masks them.
+type DiscoveryPocket struct {
+	ID              string    `json:"id"`
+	CampaignName    string    `json:"campaignName"`
+	SenderName      string    `json:"senderName"`
+	SenderAvatar    string    `json:"senderAvatar,omitempty"`
+	Message         string    `json:"message,omitempty"`
+	CoverImage      string    `json:"coverImage,omitempty"`
+	Tag             string    `json:"tag,omitempty"`
+	Token           string    `json:"token"`
+	ChainID         int64     `json:"chainId"`
+	Platform        string    `json:"platform"`
+	Amount          *float64  `json:"amount,omitempty"`
+	RemainingAmount *float64  `json:"remainingAmount,omitempty"`
+	TotalCount      int       `json:"totalCount"`
+	ClaimedCount    int       `json:"claimedCount"`
+	IsLuckyDraw     bool      `json:"isLuckyDraw"`
+	CaptchaMode     string    `json:"captchaMode,omitempty"`
+	Locale          string    `json:"locale,omitempty"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+
+	// Engagement signals, refreshed every minute
+	ClaimsPerMinute float64 `json:"claimsPerMinute"`
+	UniqueClaimers  int     `json:"uniqueClaimers"`
+	Shares          int     `json:"shares"`
+	Score           float64 `json:"score"`
+}
+
+// BridgeTransfer is a cross-chain transfer, tracked until it lands on the
+// destination chain
+type BridgeTransfer struct {
+	ID            string     `json:"bridgeId" db:"id"`
+	Protocol      string     `json:"protocol" db:"protocol"` // xcm, hyperbridge, snowbridge
+	FromChain     int64      `json:"fromChain" db:"from_chain"`
+	ToChain       int64      `json:"toChain" db:"to_chain"`
+	Asset         string     `json:"asset" db:"asset"`
+	Amount        string     `json:"amount" db:"amount"` // base units
+	Sender        string     `json:"sender" db:"sender"`
+	Recipient     string     `json:"recipient" db:"recipient"`
+	SourceTxHash  string     `json:"sourceTxHash,omitempty" db:"source_tx_hash"`
+	DestTxHash    string     `json:"destTxHash,omitempty" db:"dest_tx_hash"`
+	Status        string     `json:"status" db:"status"` // pending, confirming, relaying, completed, failed
+	EstimatedTime int        `json:"estimatedTimeSeconds" db:"estimated_time"`
+	Error         string     `json:"error,omitempty" db:"error"`
+	NextCheckAt   *time.Time `json:"-" db:"next_check_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+
+	ConsolidationID string `json:"consolidationId,omitempty" db:"consolidation_id"`
+
+	// Set for XCM transfers sent as extrinsics, which are tracked on chain:
+	// the relayer nonce used, and the recipient's balance on the
+	// destination when sent, in base units
+	SourceNonce *int64 `json:"-" db:"source_nonce"`
+	DestBalance string `json:"-" db:"dest_balance"`
+}
+
+// BridgeConsolidation sweeps a user's balances of an asset from every chain
+// to Recipient on ToChain, with a bridge transfer per chain that had funds
+type BridgeConsolidation struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"userId" db:"user_id"`
+	Asset     string    `json:"asset" db:"asset"`
+	ToChain   int64     `json:"toChain" db:"to_chain"`
+	Recipient string    `json:"recipient" db:"recipient"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// AddressScreening is one sanctions check of a payout or withdrawal address
+type AddressScreening struct {
+	ID          string    `json:"id" db:"id"`
+	Address     string    `json:"address" db:"address"`
+	Provider    string    `json:"provider" db:"provider"` // chainalysis, trm, ofac
+	Result      string    `json:"result" db:"result"`     // clear, hit
+	Reference   string    `json:"reference,omitempty" db:"reference"`
+	SubjectType string    `json:"subjectType" db:"subject_type"` // claim, withdrawal
+	SubjectID   string    `json:"subjectId" db:"subject_id"`
+	UserID      string    `json:"userId,omitempty" db:"user_id"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TravelRuleInfo identifies the originator and beneficiary of a withdrawal
+// for the FATF travel rule. It is exported as IVMS101.
+type TravelRuleInfo struct {
+	Originator      TravelRuleParty `json:"originator"`
+	Beneficiary     TravelRuleParty `json:"beneficiary"`
+	BeneficiaryVASP *TravelRuleVASP `json:"beneficiaryVasp,omitempty"` // nil for self-hosted wallets
+}
+
+// TravelRuleParty is a natural or legal person
+type TravelRuleParty struct {
+	Type               string `json:"type"`                // natural, legal
+	FirstName          string `json:"firstName,omitempty"` // natural persons
+	LastName           string `json:"lastName,omitempty"`
+	LegalName          string `json:"legalName,omitempty"` // legal persons
+	StreetName         string `json:"streetName,omitempty"`
+	BuildingNumber     string `json:"buildingNumber,omitempty"`
+	PostCode           string `json:"postCode,omitempty"`
+	TownName           string `json:"townName,omitempty"`
+	Country            string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	NationalID         string `json:"nationalId,omitempty"`
+	NationalIDType     string `json:"nationalIdType,omitempty"` // IVMS101 code: CCPT, IDCD, TXID, LEIX, ...
+	DateOfBirth        string `json:"dateOfBirth,omitempty"`    // YYYY-MM-DD
+	PlaceOfBirth       string `json:"placeOfBirth,omitempty"`
+	CountryOfResidence string `json:"countryOfResidence,omitempty"`
+}
+
+// TravelRuleVASP is the service provider hosting the beneficiary's wallet
+type TravelRuleVASP struct {
+	Name    string `json:"name"`
+	LEI     string `json:"lei,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+// PayoutSplit sends a share of each payout to another address
+type PayoutSplit struct {
+	Address string `json:"address"`
+	Bps     int    `json:"bps"` // share in basis points, 2000 = 20%
+	Label   string `json:"label,omitempty"`
+}
+
+// PayoutPreference is how a claimer's payouts are split. Whatever the splits
+// leave goes to the claimer's own wallet.
+type PayoutPreference struct {
+	UserID    string        `json:"userId" db:"user_id"`
+	Splits    []PayoutSplit `json:"splits" db:"splits"`
+	UpdatedAt *time.Time    `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// Token is a token red pockets can be created in, on one chain
+type Token struct {
+	Symbol      string    `json:"symbol" db:"symbol"`
+	ChainID     int64     `json:"chainId" db:"chain_id"`
+	Address     string    `json:"address,omitempty" db:"address"` // empty for the chain's native token
+	Decimals    int       `json:"decimals" db:"decimals"`
+	PriceFeedID string    `json:"priceFeedId,omitempty" db:"price_feed_id"`
+	Enabled     bool      `json:"enabled" db:"enabled"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Native reports whether this is the chain's native token, sent as value
+// rather than through an ERC-20 transfer
+func (t *Token) Native() bool {
+	return t.Address == ""
+}
+
+// EligibilityRule restricts who can claim. A rule is attached to a campaign
+// or to a red pocket, and a claim must pass every rule of both.
+type EligibilityRule struct {
+	ID          string            `json:"id" db:"id"`
+	CampaignID  string            `json:"campaignId,omitempty" db:"campaign_id"`
+	RedPocketID string            `json:"redPocketId,omitempty" db:"red_pocket_id"`
+	Type        string            `json:"type" db:"type"` // allowlist, token_gate, account_age, geo, claim_window
+	Params      EligibilityParams `json:"params" db:"params"`
+	CreatedAt   time.Time         `json:"createdAt" db:"created_at"`
+	Window      *ClaimWindowState `json:"window,omitempty" db:"-"` // claim_window rules on the claim page
+}
+
+// EligibilityParams are the settings of a rule; each type reads its own
+type EligibilityParams struct {
+	// allowlist: "<platform>:<platform ID>" of the accounts that may claim
+	Accounts []string `json:"accounts,omitempty"`
+
+	// token_gate: the claimer proves a wallet holding at least MinBalance
+	// (minor units, or a count for NFTs) of TokenAddress on CHAIN_ID
+	TokenAddress string `json:"tokenAddress,omitempty"`
+	MinBalance   string `json:"minBalance,omitempty"`
+
+	// account_age: minimum age of the claimer's platform account
+	MinAccountAgeDays int `json:"minAccountAgeDays,omitempty"`
+
+	// geo: ISO 3166-1 alpha-2 countries of the claimer's IP
+	AllowCountries []string `json:"allowCountries,omitempty"`
+	BlockCountries []string `json:"blockCountries,omitempty"`
+
+	// claim_window: claims only on Days ("mon" to "sun", every day when
+	// empty) from StartTime to EndTime ("HH:MM", end exclusive; an end not
+	// after the start runs past midnight) in Timezone (IANA, default UTC)
+	Days      []string `json:"days,omitempty"`
+	StartTime string   `json:"startTime,omitempty"`
+	EndTime   string   `json:"endTime,omitempty"`
+	Timezone  string   `json:"timezone,omitempty"`
+}
+
+// ClaimWindowState is where a claim_window rule stands right now, so claim
+// pages and bots can show when the pocket opens
+type ClaimWindowState struct {
+	Open     bool       `json:"open"`
+	OpensAt  *time.Time `json:"opensAt,omitempty"`  // next opening, when closed
+	ClosesAt *time.Time `json:"closesAt,omitempty"` // when open
+}
+
+// YieldVault is a whitelisted vault claimers can save their payouts in.
+// Address is the vault for erc4626 and the lending pool for aave_v3;
+// ShareAddress is the token the deposit mints.
+type YieldVault struct {
+	ID           string    `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Protocol     string    `json:"protocol" db:"protocol"` // erc4626, aave_v3
+	ChainID      int64     `json:"chainId" db:"chain_id"`
+	Address      string    `json:"address" db:"address"`
+	AssetAddress string    `json:"assetAddress" db:"asset_address"`
+	ShareAddress string    `json:"shareAddress" db:"share_address"`
+	Enabled      bool      `json:"enabled" db:"enabled"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// SavingsPreference is the vault a claimer's payouts are deposited into
+type SavingsPreference struct {
+	UserID    string     `json:"userId" db:"user_id"`
+	VaultID   string     `json:"vaultId" db:"vault_id"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// SavingsPosition is a claimer's holding in one vault. Value is read from
+// the chain and includes the yield earned.
+type SavingsPosition struct {
+	Vault     *YieldVault `json:"vault"`
+	Decimals  int         `json:"decimals"`
+	Deposited Units       `json:"deposited"` // paid claims saved in the vault
+	Withdrawn Units       `json:"withdrawn"`
+	Value     Units       `json:"value"`
+	Earned    Units       `json:"earned"` // value + withdrawn - deposited, never negative
+}
+
+// VaultWithdrawal moves savings from a vault back to the claimer's wallet
+type VaultWithdrawal struct {
+	ID            string     `json:"id" db:"id"`
+	UserID        string     `json:"userId" db:"user_id"`
+	VaultID       string     `json:"vaultId" db:"vault_id"`
+	WalletAddress string     `json:"walletAddress" db:"wallet_address"`
+	AmountUnits   Units      `json:"amountUnits" db:"amount_units"`
+	Status        string     `json:"status" db:"status"` // pending, success, failed
+	TxHash        string     `json:"txHash,omitempty" db:"tx_hash"`
+	UserOpHash    string     `json:"userOpHash,omitempty" db:"user_op_hash"`
+	Error         string     `json:"error,omitempty" db:"error"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+}
+
+// FundingConversion is a DEX swap of a token deposited into a campaign's
+// funding wallet into the campaign's payout token
+type FundingConversion struct {
+	ID               string     `json:"id" db:"id"`
+	CampaignID       string     `json:"campaignId" db:"campaign_id"`
+	ChainID          int64      `json:"chainId" db:"chain_id"`
+	WalletAddress    string     `json:"walletAddress" db:"wallet_address"`
+	SellToken        string     `json:"sellToken" db:"sell_token"`
+	SellTokenAddress string     `json:"sellTokenAddress,omitempty" db:"sell_token_address"` // empty for the native token
+	SellUnits        Units      `json:"sellUnits" db:"sell_units"`
+	BuyToken         string     `json:"buyToken" db:"buy_token"`
+	BuyTokenAddress  string     `json:"buyTokenAddress" db:"buy_token_address"`
+	QuotedUnits      Units      `json:"quotedUnits" db:"quoted_units"`
+	MinBuyUnits      Units      `json:"minBuyUnits" db:"min_buy_units"` // the swap reverts below this
+	Provider         string     `json:"provider" db:"provider"`
+	Status           string     `json:"status" db:"status"` // pending, success, failed
+	TxHash           string     `json:"txHash,omitempty" db:"tx_hash"`
+	UserOpHash       string     `json:"userOpHash,omitempty" db:"user_op_hash"`
+	Error            string     `json:"error,omitempty" db:"error"`
+	CreatedAt        time.Time  `json:"createdAt" db:"created_at"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+}
+
+// PocketDeposit is the on-chain deposit a pocket waits for before it opens,
+// made by its sender into the pocket's payout wallet
+type PocketDeposit struct {
+	RedPocketID    string     `json:"redPocketId" db:"red_pocket_id"`
+	ChainID        int64      `json:"chainId" db:"chain_id"`
+	Address        string     `json:"address" db:"address"`
+	TokenAddress   string     `json:"tokenAddress,omitempty" db:"token_address"` // empty for the native token
+	RequiredUnits  Units      `json:"requiredUnits" db:"required_units"`
+	ReceivedUnits  Units      `json:"receivedUnits" db:"received_units"` // confirmed balance at the last check
+	ConfirmedBlock int64      `json:"confirmedBlock,omitempty" db:"confirmed_block"`
+	Status         string     `json:"status" db:"status"` // pending, funded, expired
+	Deadline       time.Time  `json:"deadline" db:"deadline"`
+	CheckedAt      *time.Time `json:"checkedAt,omitempty" db:"checked_at"`
+	FundedAt       *time.Time `json:"fundedAt,omitempty" db:"funded_at"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// FraudAssessment is the sybil score of one claim attempt and the action it
+// led to. ClaimID is empty for attempts that did not become a claim.
+type FraudAssessment struct {
+	ID                string        `json:"id" db:"id"`
+	RedPocketID       string        `json:"redPocketId" db:"red_pocket_id"`
+	CampaignID        string        `json:"campaignId" db:"campaign_id"`
+	ClaimID           string        `json:"claimId,omitempty" db:"claim_id"`
+	Platform          string        `json:"platform" db:"platform"`
+	PlatformID        string        `json:"platformId" db:"platform_id"`
+	ClientIP          string        `json:"clientIp,omitempty" db:"client_ip"`
+	DeviceFingerprint string        `json:"deviceFingerprint,omitempty" db:"device_fingerprint"`
+	WalletAddress     string        `json:"walletAddress,omitempty" db:"wallet_address"`
+	Score             int           `json:"score" db:"score"` // 0-100
+	Signals           []FraudSignal `json:"signals" db:"signals"`
+	Action            string        `json:"action" db:"action"`                        // allow, captcha, hold, block
+	ReviewStatus      string        `json:"reviewStatus,omitempty" db:"review_status"` // approved, rejected; empty until reviewed
+	ReviewNote        string        `json:"reviewNote,omitempty" db:"review_note"`
+	ReviewedAt        *time.Time    `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	CreatedAt         time.Time     `json:"createdAt" db:"created_at"`
+}
+
+// FraudSignal is one input to a fraud score: what was counted and the
+// points it added
+type FraudSignal struct {
+	Name   string `json:"name"` // ip_reuse, device_reuse, wallet_cluster, velocity
+	Count  int    `json:"count"`
+	Points int    `json:"points"`
+}
+
+// Partner is a reseller whose partner code enterprises attach to their
+// account. Its reporting key is only stored as its SHA-256 hash.
+type Partner struct {
+	ID              string    `json:"id" db:"id"`
+	Name            string    `json:"name" db:"name"`
+	Email           string    `json:"email,omitempty" db:"email"`
+	Code            string    `json:"code" db:"code"`
+	RevenueShareBps int       `json:"revenueShareBps" db:"revenue_share_bps"` // of the platform fees it brings in
+	Status          string    `json:"status" db:"status"`                     // active, suspended
+	APIKeyHash      string    `json:"-" db:"api_key_hash"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+}
+
+// PartnerEnterprise is an enterprise attributed to a partner
+type PartnerEnterprise struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	AttachedAt time.Time `json:"attachedAt"`
+	Campaigns  int       `json:"campaigns"`
+}
+
+// PartnerStatement is a partner's attributed activity in one token over a
+// calendar month (UTC). Volume counts claims that were not failed, blocked
+// or refunded; platform fees are those booked to the platform revenue
+// account.
+type PartnerStatement struct {
+	Month        string  `json:"month"` // YYYY-MM
+	ChainID      int64   `json:"chainId"`
+	Token        string  `json:"token"`
+	Enterprises  int     `json:"enterprises"` // with claims in the month
+	Claims       int64   `json:"claims"`
+	Volume       float64 `json:"volume"`
+	PlatformFees float64 `json:"platformFees"`
+	Commission   float64 `json:"commission"` // the partner's revenue share of PlatformFees
+}
+
+// ClaimerClaim is one of a claimer's claims with the pocket it came from
+type ClaimerClaim struct {
+	ID          string      `json:"id"`
+	RedPocketID string      `json:"redPocketId"`
+	SenderName  string      `json:"senderName,omitempty"`
+	Message     string      `json:"message,omitempty"`
+	ChainID     int64       `json:"chainId"`
+	Token       string      `json:"token"`
+	IsLuckyDraw bool        `json:"isLuckyDraw"`
+	Amount      float64     `json:"amount"`
+	Fee         float64     `json:"fee,omitempty"`      // platform fee withheld from Amount
+	Donation    float64     `json:"donation,omitempty"` // share of Amount donated to charity
+	Received    float64     `json:"received"`           // Amount less Fee and Donation
+	Status      ClaimStatus `json:"status"`
+	TxHash      string      `json:"txHash,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	CompletedAt *time.Time  `json:"completedAt,omitempty"`
+}
+
+// ClaimerTotal is what a claimer has been paid in one token, counting only
+// claims that were sent (success or confirmed)
+type ClaimerTotal struct {
+	ChainID  int64   `json:"chainId"`
+	Token    string  `json:"token"`
+	Claims   int64   `json:"claims"`
+	Amount   float64 `json:"amount"`
+	Received float64 `json:"received"` // Amount less platform fees and donations
+	Largest  float64 `json:"largest"`
+}
+
+// ChainClaimSummary counts a user's claims on one chain and token: Pending
+// ones are queued, being sent or waiting to be confirmed
+type ChainClaimSummary struct {
+	ChainID         int64   `json:"chainId"`
+	Token           string  `json:"token"`
+	Pending         int64   `json:"pending"`
+	PendingAmount   float64 `json:"pendingAmount"`
+	Confirmed       int64   `json:"confirmed"`
+	ConfirmedAmount float64 `json:"confirmedAmount"`
+}
+
+// LeaderboardEntry is one claim on a pocket's leaderboard
+type LeaderboardEntry struct {
+	Rank       int       `json:"rank"`
+	ClaimID    string    `json:"claimId"`
+	Platform   string    `json:"platform"`
+	PlatformID string    `json:"platformId"`
+	Amount     float64   `json:"amount"`
+	Multiple   float64   `json:"multiple,omitempty"` // of the pocket's average share, lucky draws only
+	ClaimedAt  time.Time `json:"claimedAt"`
+}
+
+// PocketLeaderboard ranks a pocket's claims by amount. Claims that were
+// failed, blocked or refunded are left out.
+type PocketLeaderboard struct {
+	RedPocketID   string              `json:"redPocketId"`
+	Token         string              `json:"token"`
+	ChainID       int64               `json:"chainId"`
+	IsLuckyDraw   bool                `json:"isLuckyDraw"`
+	ClaimedCount  int                 `json:"claimedCount"`
+	ClaimedAmount float64             `json:"claimedAmount"`
+	TopClaimers   []*LeaderboardEntry `json:"topClaimers"`
+	Luckiest      *LeaderboardEntry   `json:"luckiest,omitempty"` // biggest win of a lucky draw, earliest first on ties
+	GeneratedAt   time.Time           `json:"generatedAt"`
+}
+
+// ReportFilter narrows an enterprise report to one campaign and to claims
+// (and, for analytics, pockets) created in [From, To)
+type ReportFilter struct {
+	EnterpriseID string     `json:"-"`
+	CampaignID   string     `json:"campaignId,omitempty"`
+	From         *time.Time `json:"from,omitempty"`
+	To           *time.Time `json:"to,omitempty"`
+}
+
+// ReportExport is an enterprise report generated in the background because
+// it has too many rows to stream. Content is kept until ExpiresAt.
+type ReportExport struct {
+	ID          string       `json:"id" db:"id"`
+	Kind        string       `json:"kind" db:"kind"`     // claims, analytics
+	Format      string       `json:"format" db:"format"` // csv, xlsx
+	Filter      ReportFilter `json:"filter"`
+	Status      string       `json:"status" db:"status"` // pending, running, ready, failed
+	Rows        int64        `json:"rows" db:"row_count"`
+	Error       string       `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time    `json:"createdAt" db:"created_at"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty" db:"completed_at"`
+	ExpiresAt   time.Time    `json:"expiresAt" db:"expires_at"`
+	DownloadURL string       `json:"downloadUrl,omitempty" db:"-"` // signed, once ready
+}
+
+// ClaimReportRow is one claim in an enterprise claims report
+type ClaimReportRow struct {
+	ID            string
+	CreatedAt     time.Time
+	CompletedAt   *time.Time
+	CampaignID    string
+	CampaignName  string
+	RedPocketID   string
+	Platform      string
+	PlatformID    string
+	WalletAddress string
+	ChainID       int64
+	Token         string
+	Amount        float64
+	Fee           float64
+	Donation      float64
+	Status        ClaimStatus
+	TxHash        string
+}
+
+// AnalyticsReportRow is one UTC day of a campaign's activity in one token
+type AnalyticsReportRow struct {
+	Day          time.Time
+	CampaignID   string
+	CampaignName string
+	ChainID      int64
+	Token        string
+	Pockets      int64   // created that day
+	Claims       int64   // not failed, blocked or refunded
+	Claimers     int64   // distinct
+	Amount       float64 // claimed
+	Fees         float64 // platform fees withheld from the claims
+	Donations    float64
+}
+
+// FaucetGrant is fake budget the sandbox faucet added to a test campaign
+type FaucetGrant struct {
+	ID           string    `json:"id" db:"id"`
+	EnterpriseID string    `json:"enterpriseId" db:"enterprise_id"`
+	CampaignID   string    `json:"campaignId" db:"campaign_id"`
+	Amount       float64   `json:"amount" db:"amount"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// AnalyticsBucket is one time bucket of an enterprise's activity, for one
+// platform or token when the series is broken down by it
+type AnalyticsBucket struct {
+	Bucket         time.Time `json:"bucket"`
+	Platform       string    `json:"platform,omitempty"`
+	ChainID        int64     `json:"chainId,omitempty"`
+	Token          string    `json:"token,omitempty"`
+	Claims         int64     `json:"claims"`         // not failed, blocked or refunded
+	UniqueClaimers int64     `json:"uniqueClaimers"` // distinct within the bucket
+	Amount         float64   `json:"amount"`         // claimed
+	Pockets        int64     `json:"pockets"`        // created in the bucket
+	ConversionRate float64   `json:"conversionRate"` // claims per pocket created
+	AverageAmount  float64   `json:"averageAmount"`  // per claim
+}
+
+// AnalyticsSeries is an enterprise's activity over time, served from the
+// analytics rollups. Buckets without activity are left out.
+type AnalyticsSeries struct {
+	Interval   string             `json:"interval"` // hour, day or week
+	From       time.Time          `json:"from"`
+	To         time.Time          `json:"to"`
+	CampaignID string             `json:"campaignId,omitempty"`
+	By         string             `json:"by,omitempty"` // platform or token
+	Buckets    []*AnalyticsBucket `json:"buckets"`
+	RolledUpAt *time.Time         `json:"rolledUpAt"` // buckets reflect claims up to this time
+}
+
+// LuckyDraw is the random draw behind a lucky draw claim, with everything
+// needed to recompute it once the pocket's seed is revealed
+type LuckyDraw struct {
+	ClaimID        string      `json:"claimId"`
+	RedPocketID    string      `json:"-"`
+	Platform       string      `json:"platform"`
+	PlatformID     string      `json:"platformId"`
+	RemainingUnits Units       `json:"remainingUnits"` // left in the pocket before the claim
+	RemainingCount int         `json:"remainingCount"` // shares left, the claim's included
+	Random         string      `json:"random"`         // hex HMAC-SHA256 of the seed over "pocketId:platform:platformId"
+	DrawUnits      Units       `json:"drawUnits"`      // the share drawn, before claim weights and channel limits
+	Slot           *int        `json:"slot,omitempty"` // the pre-split share taken, numbered from 0
+	AmountUnits    Units       `json:"amountUnits"`    // what the claim was for
+	Multiplier     float64     `json:"multiplier,omitempty"`
+	Status         ClaimStatus `json:"status"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	Valid          *bool       `json:"valid,omitempty"` // recomputes from the seed; set once revealed
+}
+
+// DrawProof lets anyone check a lucky draw pocket's claims. The seed is
+// revealed once the pocket has closed; until then only its commitment is.
+type DrawProof struct {
+	RedPocketID string       `json:"redPocketId"`
+	Scheme      string       `json:"scheme"`
+	Status      PocketStatus `json:"status"`
+	Commitment  string       `json:"commitment"`     // hex SHA-256 of the seed, published at creation
+	Seed        string       `json:"seed,omitempty"` // hex, once revealed
+	Decimals    int          `json:"decimals"`
+	MinAmount   float64      `json:"minAmount,omitempty"`
+	MaxAmount   float64      `json:"maxAmount,omitempty"`
+	AmountUnits Units        `json:"amountUnits"`
+	Shares      []Units      `json:"shares,omitempty"` // the pre-split shares by slot, once revealed
+	Draws       []*LuckyDraw `json:"draws"`
+	Verified    *bool        `json:"verified,omitempty"` // every draw and the commitment check out; set once revealed
+}
+
+// DigestSubscription is an enterprise's opt-in to a daily summary of its
+// pockets posted to one of its chat channels
+type DigestSubscription struct {
+	EnterpriseID string     `json:"-"`
+	Platform     string     `json:"platform"`  // telegram, discord or slack
+	ChannelID    string     `json:"channelId"` // as pockets carry it: a Telegram chat ID, a Discord channel ID or TEAM_ID:CHANNEL_ID
+	Locale       string     `json:"locale"`
+	Hour         int        `json:"hour"` // UTC hour from which the previous day's digest is posted
+	Enabled      bool       `json:"enabled"`
+	LastSentOn   *time.Time `json:"lastSentOn,omitempty"` // day the last digest covered
+	LastError    string     `json:"lastError,omitempty"`  // why the last digest could not be posted
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// DailyDigest is an enterprise's activity over one UTC day. Failed, blocked
+// and refunded claims are left out.
+type DailyDigest struct {
+	Day            time.Time       `json:"day"`
+	PocketsCreated int             `json:"pocketsCreated"`
+	Claims         int             `json:"claims"`
+	Claimers       int             `json:"claimers"`
+	Distributed    []*DigestAmount `json:"distributed"` // by token, largest first
+	TopPocket      *DigestPocket   `json:"topPocket,omitempty"`
+}
+
+type DigestAmount struct {
+	Token  string  `json:"token"`
+	Amount float64 `json:"amount"`
+}
+
+// DigestPocket is the pocket claimed most often over a digest's day
+type DigestPocket struct {
+	ID         string  `json:"id"`
+	SenderName string  `json:"senderName"`
+	Token      string  `json:"token"`
+	Claims     int     `json:"claims"`
+	Amount     float64 `json:"amount"`
+}
+
+// ReportSubscriber is an address an enterprise emails its weekly report to,
+// once the address has confirmed it wants it
+type ReportSubscriber struct {
+	ID             string     `json:"id"`
+	EnterpriseID   string     `json:"-"`
+	Email          string     `json:"email"`
+	Locale         string     `json:"locale"`
+	Status         string     `json:"status"` // pending until confirmed, active or unsubscribed
+	CreatedAt      time.Time  `json:"createdAt"`
+	ConfirmedAt    *time.Time `json:"confirmedAt,omitempty"`
+	UnsubscribedAt *time.Time `json:"unsubscribedAt,omitempty"`
+}
+
+// WeeklyReport is an enterprise's engagement over one week (Monday to
+// Monday, UTC), from the analytics rollups. Totals and Previous cover the
+// whole week and the one before; Days and Platforms break it down for
+// charts.
+type WeeklyReport struct {
+	EnterpriseID   string                  `json:"-"`
+	EnterpriseName string                  `json:"enterpriseName"`
+	Week           time.Time               `json:"week"`
+	Totals         *AnalyticsBucket        `json:"totals"`
+	Previous       *AnalyticsBucket        `json:"previous"`
+	Days           []*AnalyticsBucket      `json:"days"` // all seven, empty days included
+	Platforms      []*AnalyticsBucket      `json:"platforms"`
+	TopCampaigns   []*WeeklyReportCampaign `json:"topCampaigns"` // most claims first
+}
+
+type WeeklyReportCampaign struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Claims         int64   `json:"claims"`
+	UniqueClaimers int64   `json:"uniqueClaimers"`
+	Amount         float64 `json:"amount"`
+	Pockets        int64   `json:"pockets"`
+}
+
+// TreasureHunt links red pockets of a campaign into a sequence: claiming
+// one step's pocket unlocks the next step for that claimer
+type TreasureHunt struct {
+	ID         string      `json:"id"`
+	CampaignID string      `json:"campaignId"`
+	Name       string      `json:"name"`
+	Steps      []*HuntStep `json:"steps"` // by position, from 1
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// HuntStep is one pocket of a treasure hunt. Claimers of the step before
+// are given its clue at once and its pocket RevealDelay seconds later.
+type HuntStep struct {
+	Position    int    `json:"position"`
+	RedPocketID string `json:"redPocketId"`
+	Clue        string `json:"clue,omitempty"`
+	RevealDelay int    `json:"revealDelay"`
+}
+
+// HuntProgress is the last step of a hunt a claimer has claimed
+type HuntProgress struct {
+	HuntID     string    `json:"huntId"`
+	Platform   string    `json:"platform"`
+	PlatformID string    `json:"platformId"`
+	Step       int       `json:"step"`
+	ClaimedAt  time.Time `json:"claimedAt"` // of Step
+}
+
+// CampaignTeam is a team claimers of a campaign join with its code; their
+// claims on the campaign's pockets count towards it
+type CampaignTeam struct {
+	ID         string    `json:"id"`
+	CampaignID string    `json:"campaignId"`
+	Name       string    `json:"name"`
+	JoinCode   string    `json:"joinCode,omitempty"` // shown to its members only
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// TeamMember is a claimer in a campaign's team
+type TeamMember struct {
+	CampaignID string    `json:"campaignId"`
+	TeamID     string    `json:"teamId"`
+	Platform   string    `json:"platform"`
+	PlatformID string    `json:"platformId"`
+	UserID     string    `json:"userId"`
+	JoinedAt   time.Time `json:"joinedAt"`
+}
+
+// TeamStanding is what a team has claimed, leaving out claims that were
+// failed, blocked or refunded
+type TeamStanding struct {
+	Rank    int     `json:"rank,omitempty"` // on the campaign's leaderboard
+	TeamID  string  `json:"teamId"`
+	Name    string  `json:"name"`
+	Members int64   `json:"members"`
+	Claims  int64   `json:"claims"`
+	Amount  float64 `json:"amount"`
+}
+
+// TeamMilestone pays BonusPool, in the campaign's token, to each of its
+// teams once they reach Claims claims and Amount claimed, of those set
+type TeamMilestone struct {
+	ID         string    `json:"id"`
+	CampaignID string    `json:"campaignId"`
+	Claims     int64     `json:"claims,omitempty"`
+	Amount     float64   `json:"amount,omitempty"`
+	BonusPool  float64   `json:"bonusPool"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// TeamAward is a milestone's bonus pool paid to a team, split equally among
+// the members it had then
+type TeamAward struct {
+	ID          string    `json:"id"`
+	TeamID      string    `json:"teamId"`
+	MilestoneID string    `json:"milestoneId"`
+	BonusPool   float64   `json:"bonusPool"`
+	Members     int       `json:"members"`
+	Share       float64   `json:"share"` // credited to each member's ledger balance
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// CampaignRule automates a campaign: when Event happens and all of its
+// Conditions hold, Action runs with Params, at most MaxRuns times (0 for no
+// limit) and at most once every Cooldown seconds
+type CampaignRule struct {
+	ID         string          `json:"id"`
+	CampaignID string          `json:"campaignId"`
+	Name       string          `json:"name"`
+	Event      string          `json:"event"` // claim, schedule
+	Conditions []RuleCondition `json:"conditions"`
+	Action     string          `json:"action"` // create_pocket, pause_campaign, send_alert, adjust_distribution
+	Params     json.RawMessage `json:"params"`
+	Enabled    bool            `json:"enabled"`
+	MaxRuns    int             `json:"maxRuns"`
+	Cooldown   int             `json:"cooldown"` // seconds
+	Runs       int             `json:"runs"`
+	LastRunAt  *time.Time      `json:"lastRunAt,omitempty"`
+	LastError  string          `json:"lastError,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// RuleCondition compares one of a campaign's metrics with Value
+type RuleCondition struct {
+	Metric string  `json:"metric"` // see RuleMetrics
+	Op     string  `json:"op"`     // gte, gt, lte, lt, eq
+	Value  float64 `json:"value"`
+}
+
+// RuleMetrics are what a campaign's rule conditions are evaluated on.
+// Failed, blocked and refunded claims are left out.
+type RuleMetrics struct {
+	Claims          int64   `json:"claims"`
+	Amount          float64 `json:"amount"`
+	UniqueClaimers  int64   `json:"uniqueClaimers"`
+	ClaimsLastHour  int64   `json:"claimsLastHour"`
+	BudgetUsed      float64 `json:"budgetUsed"`   // percent of the campaign's budget spent
+	ElapsedHours    float64 `json:"elapsedHours"` // since the campaign was created
+	Hour            int     `json:"hour"`         // of the day, UTC
+}
+
+// RuleRun is one time a rule's action was carried out
+type RuleRun struct {
+	ID         string      `json:"id"`
+	RuleID     string      `json:"ruleId"`
+	CampaignID string      `json:"campaignId"`
+	Status     string      `json:"status"` // succeeded, failed
+	Metrics    RuleMetrics `json:"metrics"`
+	Result     string      `json:"result,omitempty"` // e.g. the red pocket created
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// BonusPocket is the red pocket a create_pocket rule creates. Left without
+// a platform and channel, a rule run on a claim drops it where the claim was
+// made.
+type BonusPocket struct {
+	Amount      string `json:"amount"` // decimal token amount
+	Token       string `json:"token"`
+	TotalCount  int    `json:"totalCount"`
+	IsLuckyDraw bool   `json:"isLuckyDraw,omitempty"`
+	SenderName  string `json:"senderName,omitempty"`
+	Message     string `json:"message,omitempty"`
+	ExpiresIn   int64  `json:"expiresIn,omitempty"` // seconds
+	Platform    string `json:"platform,omitempty"`
+	ChannelID   string `json:"platformChannelId,omitempty"`
 }
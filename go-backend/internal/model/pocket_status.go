@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// PocketStatus is where a red pocket is in its life. A pocket only moves
+// along pocketTransitions; RedPocketRepository.Transition refuses any other
+// change, and pocket_status_history records each one.
+type PocketStatus string
+
+const (
+	PocketAwaitingFunding PocketStatus = "awaiting_funding" // waiting for the sender's deposit
+	PocketUnfunded        PocketStatus = "unfunded"         // deposit never arrived
+	PocketScheduled       PocketStatus = "scheduled"        // opens at its start time
+	PocketActive          PocketStatus = "active"           // open for claims
+	PocketPaused          PocketStatus = "paused"           // claims blocked until resumed
+	PocketDepleted        PocketStatus = "depleted"         // every share claimed
+	PocketExpired         PocketStatus = "expired"          // closed at its expiry, remainder not yet returned
+	PocketCancelled       PocketStatus = "cancelled"        // cancelled before it opened, or by its enterprise while open
+	PocketRefunded        PocketStatus = "refunded"         // expired or cancelled and remainder returned to the creator
+)
+
+// pocketTransitions lists the statuses each status may move to. A depleted
+// pocket reopens when one of its claims is returned unpaid.
+var pocketTransitions = map[PocketStatus][]PocketStatus{
+	PocketAwaitingFunding: {PocketScheduled, PocketActive, PocketUnfunded},
+	PocketScheduled:       {PocketActive, PocketCancelled},
+	PocketActive:          {PocketPaused, PocketDepleted, PocketExpired, PocketCancelled},
+	PocketPaused:          {PocketActive, PocketExpired, PocketCancelled},
+	PocketDepleted:        {PocketActive},
+	PocketExpired:         {PocketRefunded},
+	PocketCancelled:       {PocketRefunded},
+}
+
+// CanTransition reports whether a pocket may move from s to the status.
+// Staying in the same status is allowed, so repeated updates are harmless.
+func (s PocketStatus) CanTransition(to PocketStatus) bool {
+	if s == to {
+		return true
+	}
+	for _, next := range pocketTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Final reports whether a pocket in the status never moves again
+func (s PocketStatus) Final() bool {
+	return len(pocketTransitions[s]) == 0
+}
+
+// PocketStatusesBefore returns the statuses a pocket may move to the status
+// from, including the status itself
+func PocketStatusesBefore(to PocketStatus) []string {
+	from := []string{string(to)}
+	for s, next := range pocketTransitions {
+		for _, n := range next {
+			if n == to && s != to {
+				from = append(from, string(s))
+			}
+		}
+	}
+	return from
+}
+
+// PocketTransition is one status change of a red pocket
+type PocketTransition struct {
+	From      PocketStatus `json:"from,omitempty"` // empty when the pocket was created
+	To        PocketStatus `json:"to"`
+	Reason    string       `json:"reason,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Units is a token amount in the token's minor units: wei for ETH and DAI,
+// millionths for USDC. It is stored as NUMERIC and sent as a JSON string so
+// clients do not lose precision. Treat values as immutable.
+type Units struct {
+	i *big.Int
+}
+
+func NewUnits(x *big.Int) Units {
+	return Units{i: new(big.Int).Set(x)}
+}
+
+// Int returns a copy of the amount
+func (u Units) Int() *big.Int {
+	if u.i == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(u.i)
+}
+
+func (u Units) Sign() int {
+	if u.i == nil {
+		return 0
+	}
+	return u.i.Sign()
+}
+
+func (u Units) String() string {
+	return u.Int().String()
+}
+
+// Float converts the amount to whole tokens for display
+func (u Units) Float(decimals int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(u.Int()), new(big.Float).SetInt(Pow10(decimals))).Float64()
+	return f
+}
+
+func (u Units) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON accepts the amount as a string or an integer
+func (u *Units) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("invalid token amount %q", s)
+	}
+	u.i = x
+	return nil
+}
+
+func (u Units) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// Scan reads a NUMERIC column
+func (u *Units) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		u.i = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		u.i = big.NewInt(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Units", src)
+	}
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("invalid token amount %q", s)
+	}
+	u.i = x
+	return nil
+}
+
+// Pow10 returns 10^n
+func Pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
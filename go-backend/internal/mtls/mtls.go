@@ -0,0 +1,84 @@
+// Package mtls builds the TLS configuration of the listeners that require
+// client certificates: the admin listener and gRPC
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// Enabled reports whether a server certificate is configured
+func Enabled(cfg *config.Config) bool {
+	return cfg.MTLSCertFile != ""
+}
+
+// ServerConfig returns a TLS configuration that requires a client
+// certificate signed by MTLS_CLIENT_CA_FILE and, when MTLS_ALLOWED_CLIENTS is
+// set, issued to one of the allowed clients. It returns nil when mTLS is not
+// configured.
+func ServerConfig(cfg *config.Config) (*tls.Config, error) {
+	if !Enabled(cfg) {
+		return nil, nil
+	}
+	if cfg.MTLSKeyFile == "" || cfg.MTLSClientCAFile == "" {
+		return nil, errors.New("MTLS_KEY_FILE and MTLS_CLIENT_CA_FILE are required with MTLS_CERT_FILE")
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.MTLSCertFile, cfg.MTLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(cfg.MTLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates in %s", cfg.MTLSClientCAFile)
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	if len(cfg.MTLSAllowedClients) > 0 {
+		allowed := make(map[string]bool, len(cfg.MTLSAllowedClients))
+		for _, name := range cfg.MTLSAllowedClients {
+			allowed[name] = true
+		}
+		tlsCfg.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+			// Chains were verified against the client CA; check the leaf
+			for _, chain := range chains {
+				if len(chain) > 0 && allowedClient(chain[0], allowed) {
+					return nil
+				}
+			}
+			return errors.New("client certificate is not on the allowlist")
+		}
+	}
+	return tlsCfg, nil
+}
+
+func allowedClient(cert *x509.Certificate, allowed map[string]bool) bool {
+	if allowed[cert.Subject.CommonName] {
+		return true
+	}
+	for _, name := range cert.DNSNames {
+		if allowed[name] {
+			return true
+		}
+	}
+	for _, uri := range cert.URIs {
+		if allowed[uri.String()] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type AnalyticsRepository struct {
+	db *PostgresDB
+}
+
+func NewAnalyticsRepository(db *PostgresDB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// rollupAnalytics aggregates claims and new pockets into buckets of
+// granularity $1 starting with the bucket of $2, one row per enterprise and
+// per campaign, each also broken down by platform and by token. Failed,
+// blocked and refunded claims are left out.
+const rollupAnalytics = `
+	WITH claim_rows AS (
+		SELECT date_trunc($1, c.created_at, 'UTC') AS bucket, camp.enterprise_id, camp.id AS campaign_id,
+			c.platform, rp.chain_id, rp.token, c.claimer_id, c.amount
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE c.created_at >= date_trunc($1, $2::timestamptz, 'UTC')
+			AND c.status NOT IN ('failed', 'blocked', 'refunded')
+	), claim_stats AS (
+		SELECT bucket, enterprise_id, COALESCE(campaign_id, '') AS campaign_id, COALESCE(platform, '') AS platform,
+			COALESCE(chain_id, 0) AS chain_id, COALESCE(token, '') AS token,
+			COUNT(*) AS claims, COUNT(DISTINCT claimer_id) AS claimers, SUM(amount) AS amount
+		FROM claim_rows
+		GROUP BY GROUPING SETS (
+			(bucket, enterprise_id), (bucket, enterprise_id, platform), (bucket, enterprise_id, chain_id, token),
+			(bucket, enterprise_id, campaign_id), (bucket, enterprise_id, campaign_id, platform),
+			(bucket, enterprise_id, campaign_id, chain_id, token)
+		)
+	), pocket_rows AS (
+		SELECT date_trunc($1, rp.created_at, 'UTC') AS bucket, camp.enterprise_id, camp.id AS campaign_id,
+			rp.platform, rp.chain_id, rp.token
+		FROM red_pockets rp
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE rp.created_at >= date_trunc($1, $2::timestamptz, 'UTC')
+	), pocket_stats AS (
+		SELECT bucket, enterprise_id, COALESCE(campaign_id, '') AS campaign_id, COALESCE(platform, '') AS platform,
+			COALESCE(chain_id, 0) AS chain_id, COALESCE(token, '') AS token, COUNT(*) AS pockets
+		FROM pocket_rows
+		GROUP BY GROUPING SETS (
+			(bucket, enterprise_id), (bucket, enterprise_id, platform), (bucket, enterprise_id, chain_id, token),
+			(bucket, enterprise_id, campaign_id), (bucket, enterprise_id, campaign_id, platform),
+			(bucket, enterprise_id, campaign_id, chain_id, token)
+		)
+	)
+	INSERT INTO analytics_rollups (
+		granularity, bucket, enterprise_id, campaign_id, platform, chain_id, token, claims, claimers, amount, pockets
+	)
+	SELECT $1, COALESCE(c.bucket, p.bucket), COALESCE(c.enterprise_id, p.enterprise_id), COALESCE(c.campaign_id, p.campaign_id),
+		COALESCE(c.platform, p.platform), COALESCE(c.chain_id, p.chain_id), COALESCE(c.token, p.token),
+		COALESCE(c.claims, 0), COALESCE(c.claimers, 0), COALESCE(c.amount, 0), COALESCE(p.pockets, 0)
+	FROM claim_stats c
+	FULL JOIN pocket_stats p
+		ON p.bucket = c.bucket AND p.enterprise_id = c.enterprise_id AND p.campaign_id = c.campaign_id
+		AND p.platform = c.platform AND p.chain_id = c.chain_id AND p.token = c.token
+`
+
+// RollupFrom returns where the next rollup of a granularity starts: where
+// the last one left off, or the first pocket ever created when there was
+// none
+func (r *AnalyticsRepository) RollupFrom(ctx context.Context, granularity string) (time.Time, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT recompute_from FROM analytics_rollup_state WHERE granularity = $1),
+			(SELECT MIN(created_at) FROM red_pockets),
+			NOW()
+		)
+	`
+	var from time.Time
+	err := r.db.Pool.QueryRow(ctx, query, granularity).Scan(&from)
+	return from, err
+}
+
+// Rollup recomputes the buckets of a granularity from the bucket of from
+// onwards, and records that the next rollup starts at next. Returns the
+// number of rows written.
+func (r *AnalyticsRepository) Rollup(ctx context.Context, granularity string, from, next time.Time) (int64, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialises rollups of a granularity
+	lock := `
+		INSERT INTO analytics_rollup_state (granularity, recompute_from, rolled_up_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (granularity) DO UPDATE SET recompute_from = $2, rolled_up_at = NOW()
+	`
+	if _, err := tx.Exec(ctx, lock, granularity, next); err != nil {
+		return 0, err
+	}
+	clear := `DELETE FROM analytics_rollups WHERE granularity = $1 AND bucket >= date_trunc($1, $2::timestamptz, 'UTC')`
+	if _, err := tx.Exec(ctx, clear, granularity, from); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, rollupAnalytics, granularity, from)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), tx.Commit(ctx)
+}
+
+// RolledUpAt returns when a granularity was last rolled up, or nil if never
+func (r *AnalyticsRepository) RolledUpAt(ctx context.Context, granularity string) (*time.Time, error) {
+	query := `SELECT MAX(rolled_up_at) FROM analytics_rollup_state WHERE granularity = $1`
+	var at *time.Time
+	err := r.db.Pool.QueryRow(ctx, query, granularity).Scan(&at)
+	return at, err
+}
+
+// TimeSeries returns an enterprise's buckets of a granularity in [from, to),
+// oldest first, for one campaign or all of them when campaignID is empty.
+// byPlatform and byToken pick the breakdown; with neither, one row per
+// bucket covers everything.
+func (r *AnalyticsRepository) TimeSeries(ctx context.Context, enterpriseID, granularity, campaignID string, byPlatform, byToken bool, from, to time.Time) ([]*model.AnalyticsBucket, error) {
+	query := `
+		SELECT bucket, platform, chain_id, token, claims, claimers, amount, pockets
+		FROM analytics_rollups
+		WHERE enterprise_id = $1 AND granularity = $2 AND campaign_id = $3
+			AND (platform <> '') = $4 AND (token <> '') = $5
+			AND bucket >= date_trunc($2, $6::timestamptz, 'UTC') AND bucket < $7
+		ORDER BY bucket, platform, chain_id, token
+	`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID, granularity, campaignID, byPlatform, byToken, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []*model.AnalyticsBucket
+	for rows.Next() {
+		b := &model.AnalyticsBucket{}
+		err := rows.Scan(&b.Bucket, &b.Platform, &b.ChainID, &b.Token, &b.Claims, &b.UniqueClaimers, &b.Amount, &b.Pockets)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// TopCampaigns returns the enterprise's campaigns with the most claims over
+// the bucket of a granularity starting at bucket
+func (r *AnalyticsRepository) TopCampaigns(ctx context.Context, enterpriseID, granularity string, bucket time.Time, limit int) ([]*model.WeeklyReportCampaign, error) {
+	query := `
+		SELECT r.campaign_id, c.name, r.claims, r.claimers, r.amount, r.pockets
+		FROM analytics_rollups r
+		JOIN campaigns c ON c.id = r.campaign_id
+		WHERE r.enterprise_id = $1 AND r.granularity = $2 AND r.bucket = $3
+			AND r.campaign_id <> '' AND r.platform = '' AND r.token = '' AND r.claims > 0
+		ORDER BY r.claims DESC, r.amount DESC, r.campaign_id
+		LIMIT $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID, granularity, bucket, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []*model.WeeklyReportCampaign
+	for rows.Next() {
+		c := &model.WeeklyReportCampaign{}
+		if err := rows.Scan(&c.ID, &c.Name, &c.Claims, &c.UniqueClaimers, &c.Amount, &c.Pockets); err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+	return campaigns, rows.Err()
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/encryption"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// APIKeyRepository stores enterprise API keys with their signing secrets
+// sealed by the encryptor
+type APIKeyRepository struct {
+	db  *PostgresDB
+	enc *encryption.Encryptor
+}
+
+func NewAPIKeyRepository(db *PostgresDB, enc *encryption.Encryptor) *APIKeyRepository {
+	return &APIKeyRepository{db: db, enc: enc}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *model.APIKey) error {
+	sealed, err := r.enc.Encrypt(ctx, k.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt API key secret: %w", err)
+	}
+	query := `
+		INSERT INTO api_keys (id, enterprise_id, name, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = r.db.Pool.Exec(ctx, query, k.ID, k.EnterpriseID, k.Name, sealed, k.CreatedAt)
+	return err
+}
+
+// List returns the enterprise's keys, revoked ones included, without their
+// secrets
+func (r *APIKeyRepository) List(ctx context.Context, enterpriseID string) ([]*model.APIKey, error) {
+	query := `
+		SELECT id, enterprise_id, name, created_at, revoked_at
+		FROM api_keys
+		WHERE enterprise_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*model.APIKey{}
+	for rows.Next() {
+		k := &model.APIKey{}
+		if err := rows.Scan(&k.ID, &k.EnterpriseID, &k.Name, &k.CreatedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// GetActive returns an unrevoked key with its secret, or pgx.ErrNoRows
+func (r *APIKeyRepository) GetActive(ctx context.Context, id string) (*model.APIKey, error) {
+	query := `
+		SELECT id, enterprise_id, name, secret, created_at
+		FROM api_keys
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	k := &model.APIKey{}
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(&k.ID, &k.EnterpriseID, &k.Name, &k.Secret, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := r.enc.Decrypt(ctx, k.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret of API key %s: %w", k.ID, err)
+	}
+	k.Secret = secret
+	return k, nil
+}
+
+// Revoke stops a key from authenticating. Reports false if the enterprise
+// has no such unrevoked key.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, enterpriseID string, at time.Time) (bool, error) {
+	query := `
+		UPDATE api_keys SET revoked_at = $3
+		WHERE id = $1 AND enterprise_id = $2 AND revoked_at IS NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, enterpriseID, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
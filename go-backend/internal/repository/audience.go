@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type AudienceRepository struct {
+	db *PostgresDB
+}
+
+func NewAudienceRepository(db *PostgresDB) *AudienceRepository {
+	return &AudienceRepository{db: db}
+}
+
+// Insert batch size for snapshot entries
+const audienceEntryBatchSize = 5000
+
+func (r *AudienceRepository) CreateSnapshot(ctx context.Context, s *model.AudienceSnapshot) error {
+	query := `
+		INSERT INTO audience_snapshots (
+			id, campaign_id, source, token_address, chain_id, block_number,
+			min_balance, holder_count, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7::text::numeric, $8, $9, $10)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		s.ID, s.CampaignID, s.Source, s.TokenAddress, s.ChainID, s.BlockNumber,
+		s.MinBalance, s.HolderCount, s.Status, s.CreatedAt,
+	)
+	return err
+}
+
+const audienceSnapshotColumns = `
+	id, campaign_id, source, COALESCE(token_address, ''), chain_id, block_number,
+	min_balance::text, holder_count, status, COALESCE(error, ''), created_at, completed_at
+`
+
+func (r *AudienceRepository) GetSnapshot(ctx context.Context, id string) (*model.AudienceSnapshot, error) {
+	query := `SELECT ` + audienceSnapshotColumns + ` FROM audience_snapshots WHERE id = $1`
+	s := &model.AudienceSnapshot{}
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&s.ID, &s.CampaignID, &s.Source, &s.TokenAddress, &s.ChainID, &s.BlockNumber,
+		&s.MinBalance, &s.HolderCount, &s.Status, &s.Error, &s.CreatedAt, &s.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetActiveSnapshot returns the most recent ready snapshot for a campaign
+func (r *AudienceRepository) GetActiveSnapshot(ctx context.Context, campaignID string) (*model.AudienceSnapshot, error) {
+	query := `
+		SELECT ` + audienceSnapshotColumns + `
+		FROM audience_snapshots
+		WHERE campaign_id = $1 AND status = 'ready'
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	s := &model.AudienceSnapshot{}
+	err := r.db.Pool.QueryRow(ctx, query, campaignID).Scan(
+		&s.ID, &s.CampaignID, &s.Source, &s.TokenAddress, &s.ChainID, &s.BlockNumber,
+		&s.MinBalance, &s.HolderCount, &s.Status, &s.Error, &s.CreatedAt, &s.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *AudienceRepository) ListSnapshots(ctx context.Context, campaignID string) ([]*model.AudienceSnapshot, error) {
+	query := `
+		SELECT ` + audienceSnapshotColumns + `
+		FROM audience_snapshots
+		WHERE campaign_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []*model.AudienceSnapshot
+	for rows.Next() {
+		s := &model.AudienceSnapshot{}
+		err := rows.Scan(
+			&s.ID, &s.CampaignID, &s.Source, &s.TokenAddress, &s.ChainID, &s.BlockNumber,
+			&s.MinBalance, &s.HolderCount, &s.Status, &s.Error, &s.CreatedAt, &s.CompletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+func (r *AudienceRepository) UpdateSnapshotStatus(ctx context.Context, id, status, errMsg string, holderCount int) error {
+	query := `
+		UPDATE audience_snapshots
+		SET status = $2, error = NULLIF($3, ''), holder_count = $4,
+			completed_at = CASE WHEN $2 IN ('ready', 'failed') THEN NOW() ELSE completed_at END
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, errMsg, holderCount)
+	return err
+}
+
+// InsertEntries bulk-inserts snapshot entries; duplicate addresses are ignored
+func (r *AudienceRepository) InsertEntries(ctx context.Context, snapshotID string, entries []model.AudienceEntry) error {
+	query := `
+		INSERT INTO audience_entries (snapshot_id, address, balance)
+		SELECT $1, t.address, t.balance::numeric
+		FROM unnest($2::text[], $3::text[]) AS t(address, balance)
+		ON CONFLICT (snapshot_id, address) DO NOTHING
+	`
+	for start := 0; start < len(entries); start += audienceEntryBatchSize {
+		end := start + audienceEntryBatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		addresses := make([]string, 0, end-start)
+		balances := make([]string, 0, end-start)
+		for _, e := range entries[start:end] {
+			addresses = append(addresses, e.Address)
+			balances = append(balances, e.Balance)
+		}
+
+		if _, err := r.db.Pool.Exec(ctx, query, snapshotID, addresses, balances); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *AudienceRepository) IsEligible(ctx context.Context, snapshotID, address string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM audience_entries WHERE snapshot_id = $1 AND address = $2)`
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, query, snapshotID, address).Scan(&exists)
+	return exists, err
+}
+
+func (r *AudienceRepository) HasClaimed(ctx context.Context, redPocketID, address string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM audience_claims WHERE red_pocket_id = $1 AND address = $2)`
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, query, redPocketID, address).Scan(&exists)
+	return exists, err
+}
+
+func (r *AudienceRepository) RecordClaim(ctx context.Context, snapshotID, address, redPocketID, claimID string) error {
+	query := `
+		INSERT INTO audience_claims (snapshot_id, address, red_pocket_id, claim_id, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`
+	_, err := r.db.Pool.Exec(ctx, query, snapshotID, address, redPocketID, claimID)
+	return err
+}
+
+// GetCoverage returns eligible vs claimed address counts for a snapshot
+func (r *AudienceRepository) GetCoverage(ctx context.Context, snapshotID string) (*model.AudienceCoverage, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM audience_entries WHERE snapshot_id = $1) AS eligible,
+			(SELECT COUNT(DISTINCT address) FROM audience_claims WHERE snapshot_id = $1) AS claimed
+	`
+	cov := &model.AudienceCoverage{SnapshotID: snapshotID}
+	if err := r.db.Pool.QueryRow(ctx, query, snapshotID).Scan(&cov.Eligible, &cov.Claimed); err != nil {
+		return nil, err
+	}
+	if cov.Eligible > 0 {
+		cov.CoverageRate = float64(cov.Claimed) / float64(cov.Eligible)
+	}
+	return cov, nil
+}
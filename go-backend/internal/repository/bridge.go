@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type BridgeTransferRepository struct {
+	db *PostgresDB
+}
+
+func NewBridgeTransferRepository(db *PostgresDB) *BridgeTransferRepository {
+	return &BridgeTransferRepository{db: db}
+}
+
+func (r *BridgeTransferRepository) Create(ctx context.Context, t *model.BridgeTransfer) error {
+	query := `
+		INSERT INTO bridge_transfers (
+			id, protocol, from_chain, to_chain, asset, amount, sender, recipient,
+			source_tx_hash, dest_tx_hash, status, estimated_time, error, next_check_at, created_at, updated_at,
+			consolidation_id, source_nonce, dest_balance
+		) VALUES ($1, $2, $3, $4, $5, $6::TEXT::NUMERIC, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, NULLIF($13, ''), $14, $15, $16,
+			NULLIF($17, ''), $18, NULLIF($19, '')::NUMERIC)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		t.ID, t.Protocol, t.FromChain, t.ToChain, t.Asset, t.Amount, t.Sender, t.Recipient,
+		t.SourceTxHash, t.DestTxHash, t.Status, t.EstimatedTime, t.Error, t.NextCheckAt, t.CreatedAt, t.UpdatedAt,
+		t.ConsolidationID, t.SourceNonce, t.DestBalance,
+	)
+	return err
+}
+
+const bridgeTransferColumns = `
+	id, protocol, from_chain, to_chain, asset, amount::TEXT, sender, recipient,
+	COALESCE(source_tx_hash, ''), COALESCE(dest_tx_hash, ''), status, estimated_time, COALESCE(error, ''),
+	next_check_at, created_at, updated_at, COALESCE(consolidation_id, ''),
+	source_nonce, COALESCE(dest_balance::TEXT, '')
+`
+
+func scanBridgeTransfer(row interface{ Scan(...interface{}) error }) (*model.BridgeTransfer, error) {
+	t := &model.BridgeTransfer{}
+	err := row.Scan(
+		&t.ID, &t.Protocol, &t.FromChain, &t.ToChain, &t.Asset, &t.Amount, &t.Sender, &t.Recipient,
+		&t.SourceTxHash, &t.DestTxHash, &t.Status, &t.EstimatedTime, &t.Error,
+		&t.NextCheckAt, &t.CreatedAt, &t.UpdatedAt, &t.ConsolidationID,
+		&t.SourceNonce, &t.DestBalance,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *BridgeTransferRepository) GetByID(ctx context.Context, id string) (*model.BridgeTransfer, error) {
+	query := `SELECT ` + bridgeTransferColumns + ` FROM bridge_transfers WHERE id = $1`
+	return scanBridgeTransfer(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// CountUnfinished returns how many transfers have not reached a final status
+func (r *BridgeTransferRepository) CountUnfinished(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM bridge_transfers WHERE status IN ('pending', 'confirming', 'relaying')`
+	var n int64
+	err := r.db.Pool.QueryRow(ctx, query).Scan(&n)
+	return n, err
+}
+
+// ListDue returns unfinished transfers whose next check is due, oldest first
+func (r *BridgeTransferRepository) ListDue(ctx context.Context, limit int) ([]*model.BridgeTransfer, error) {
+	query := `
+		SELECT ` + bridgeTransferColumns + `
+		FROM bridge_transfers
+		WHERE status IN ('pending', 'confirming', 'relaying') AND next_check_at <= NOW()
+		ORDER BY next_check_at
+		LIMIT $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*model.BridgeTransfer
+	for rows.Next() {
+		t, err := scanBridgeTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+	return transfers, rows.Err()
+}
+
+// Advance moves a transfer from one status to the next. The update only
+// applies while the transfer is still in fromStatus, so when several replicas
+// track the same transfer exactly one advances it; the others get false.
+// A nil nextCheckAt stops tracking.
+func (r *BridgeTransferRepository) Advance(ctx context.Context, id, fromStatus, toStatus, destTxHash, errMsg string, nextCheckAt *time.Time) (bool, error) {
+	query := `
+		UPDATE bridge_transfers
+		SET status = $3,
+			dest_tx_hash = COALESCE(NULLIF($4, ''), dest_tx_hash),
+			error = COALESCE(NULLIF($5, ''), error),
+			next_check_at = $6,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $2
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, fromStatus, toStatus, destTxHash, errMsg, nextCheckAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Reschedule sets when a transfer still in status is next checked, for
+// transfers tracked on chain that have not moved on yet
+func (r *BridgeTransferRepository) Reschedule(ctx context.Context, id, status string, nextCheckAt time.Time) error {
+	query := `UPDATE bridge_transfers SET next_check_at = $3 WHERE id = $1 AND status = $2`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, nextCheckAt)
+	return err
+}
+
+// CreateConsolidation records a consolidation; its transfers are created
+// with its ID after
+func (r *BridgeTransferRepository) CreateConsolidation(ctx context.Context, c *model.BridgeConsolidation) error {
+	query := `
+		INSERT INTO bridge_consolidations (id, user_id, asset, to_chain, recipient, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.Pool.Exec(ctx, query, c.ID, c.UserID, c.Asset, c.ToChain, c.Recipient, c.CreatedAt)
+	return err
+}
+
+// GetConsolidation returns a consolidation with its transfers, oldest first
+func (r *BridgeTransferRepository) GetConsolidation(ctx context.Context, id string) (*model.BridgeConsolidation, []*model.BridgeTransfer, error) {
+	c := &model.BridgeConsolidation{}
+	query := `SELECT id, user_id, asset, to_chain, recipient, created_at FROM bridge_consolidations WHERE id = $1`
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(&c.ID, &c.UserID, &c.Asset, &c.ToChain, &c.Recipient, &c.CreatedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query = `SELECT ` + bridgeTransferColumns + ` FROM bridge_transfers WHERE consolidation_id = $1 ORDER BY created_at`
+	rows, err := r.db.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*model.BridgeTransfer
+	for rows.Next() {
+		t, err := scanBridgeTransfer(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		transfers = append(transfers, t)
+	}
+	return c, transfers, rows.Err()
+}
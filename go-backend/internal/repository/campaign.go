@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/protocolbank/redpocket-backend/internal/model"
 )
 
@@ -20,13 +22,13 @@ func (r *CampaignRepository) Create(ctx context.Context, c *model.Campaign) erro
 		INSERT INTO campaigns (
 			id, enterprise_id, name, description, total_budget, spent_budget,
 			token, token_address, chain_id, platform, total_pockets, total_claims,
-			tag, status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			tag, status, created_at, updated_at, payout_mode, test_mode
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 	_, err := r.db.Pool.Exec(ctx, query,
 		c.ID, c.EnterpriseID, c.Name, c.Description, c.TotalBudget, c.SpentBudget,
 		c.Token, c.TokenAddress, c.ChainID, c.Platform, c.TotalPockets, c.TotalClaims,
-		c.Tag, c.Status, c.CreatedAt, c.UpdatedAt,
+		c.Tag, c.Status, c.CreatedAt, c.UpdatedAt, c.PayoutMode, c.TestMode,
 	)
 	return err
 }
@@ -35,14 +37,14 @@ func (r *CampaignRepository) GetByID(ctx context.Context, id string) (*model.Cam
 	query := `
 		SELECT id, enterprise_id, name, description, total_budget, spent_budget,
 			token, token_address, chain_id, platform, total_pockets, total_claims,
-			tag, status, created_at, updated_at
+			tag, status, created_at, updated_at, payout_mode, test_mode
 		FROM campaigns WHERE id = $1
 	`
 	c := &model.Campaign{}
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&c.ID, &c.EnterpriseID, &c.Name, &c.Description, &c.TotalBudget, &c.SpentBudget,
 		&c.Token, &c.TokenAddress, &c.ChainID, &c.Platform, &c.TotalPockets, &c.TotalClaims,
-		&c.Tag, &c.Status, &c.CreatedAt, &c.UpdatedAt,
+		&c.Tag, &c.Status, &c.CreatedAt, &c.UpdatedAt, &c.PayoutMode, &c.TestMode,
 	)
 	if err != nil {
 		return nil, err
@@ -61,7 +63,7 @@ func (r *CampaignRepository) ListByEnterprise(ctx context.Context, enterpriseID
 	query := `
 		SELECT id, enterprise_id, name, description, total_budget, spent_budget,
 			token, token_address, chain_id, platform, total_pockets, total_claims,
-			tag, status, created_at, updated_at
+			tag, status, created_at, updated_at, payout_mode, test_mode
 		FROM campaigns 
 		WHERE enterprise_id = $1
 		ORDER BY created_at DESC
@@ -79,7 +81,7 @@ func (r *CampaignRepository) ListByEnterprise(ctx context.Context, enterpriseID
 		err := rows.Scan(
 			&c.ID, &c.EnterpriseID, &c.Name, &c.Description, &c.TotalBudget, &c.SpentBudget,
 			&c.Token, &c.TokenAddress, &c.ChainID, &c.Platform, &c.TotalPockets, &c.TotalClaims,
-			&c.Tag, &c.Status, &c.CreatedAt, &c.UpdatedAt,
+			&c.Tag, &c.Status, &c.CreatedAt, &c.UpdatedAt, &c.PayoutMode, &c.TestMode,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -89,15 +91,17 @@ func (r *CampaignRepository) ListByEnterprise(ctx context.Context, enterpriseID
 	return campaigns, total, nil
 }
 
+// Update saves a campaign's settings. spent_budget is left alone: claims
+// settle into it as they succeed.
 func (r *CampaignRepository) Update(ctx context.Context, c *model.Campaign) error {
 	query := `
 		UPDATE campaigns SET
-			name = $2, description = $3, total_budget = $4, spent_budget = $5,
-			total_pockets = $6, total_claims = $7, tag = $8, status = $9, updated_at = $10
+			name = $2, description = $3, total_budget = $4,
+			total_pockets = $5, total_claims = $6, tag = $7, status = $8, updated_at = $9
 		WHERE id = $1
 	`
 	_, err := r.db.Pool.Exec(ctx, query,
-		c.ID, c.Name, c.Description, c.TotalBudget, c.SpentBudget,
+		c.ID, c.Name, c.Description, c.TotalBudget,
 		c.TotalPockets, c.TotalClaims, c.Tag, c.Status, time.Now(),
 	)
 	return err
@@ -136,16 +140,286 @@ func (r *CampaignRepository) GetAnalytics(ctx context.Context, enterpriseID stri
 			COALESCE(SUM(spent_budget), 0) as total_spent,
 			COALESCE(SUM(total_claims), 0) as total_claims,
 			COALESCE(SUM(total_pockets), 0) as total_pockets,
-			COUNT(*) FILTER (WHERE status = 'active') as active_campaigns
+			COUNT(*) FILTER (WHERE status = 'active') as active_campaigns,
+			(
+				SELECT COALESCE(SUM(cl.donation_amount), 0)
+				FROM claims cl
+				JOIN red_pockets rp ON rp.id = cl.red_pocket_id
+				JOIN campaigns c ON c.id = rp.campaign_id
+				WHERE c.enterprise_id = $1 AND cl.donation_units > 0 AND cl.status NOT IN ('failed', 'blocked', 'refunded')
+			) as total_donated
 		FROM campaigns WHERE enterprise_id = $1
 	`
 	a := &model.CampaignAnalytics{}
 	err := r.db.Pool.QueryRow(ctx, query, enterpriseID).Scan(
 		&a.TotalCampaigns, &a.TotalBudget, &a.TotalSpent,
-		&a.TotalClaims, &a.TotalPockets, &a.ActiveCampaigns,
+		&a.TotalClaims, &a.TotalPockets, &a.ActiveCampaigns, &a.TotalDonated,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return a, nil
 }
+
+// GetClaimPage returns the claim page branding of a campaign
+func (r *CampaignRepository) GetClaimPage(ctx context.Context, id string) (*model.ClaimPageConfig, error) {
+	query := `SELECT claim_page FROM campaigns WHERE id = $1`
+	var raw []byte
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&raw); err != nil {
+		return nil, err
+	}
+	page := &model.ClaimPageConfig{}
+	if err := json.Unmarshal(raw, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// SetClaimPage replaces the claim page branding of a campaign. Returns
+// pgx.ErrNoRows if the campaign does not exist.
+func (r *CampaignRepository) SetClaimPage(ctx context.Context, id string, page *model.ClaimPageConfig) error {
+	raw, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE campaigns SET claim_page = $2, updated_at = NOW() WHERE id = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, id, raw)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// GetTerms returns the current terms of a campaign; Version is empty when the
+// campaign has none
+func (r *CampaignRepository) GetTerms(ctx context.Context, id string) (*model.CampaignTerms, error) {
+	query := `SELECT COALESCE(terms_version, ''), COALESCE(terms_text, ''), terms_updated_at FROM campaigns WHERE id = $1`
+	t := &model.CampaignTerms{}
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&t.Version, &t.Text, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetTerms replaces the terms of a campaign; an empty version removes them.
+// Returns pgx.ErrNoRows if the campaign does not exist.
+func (r *CampaignRepository) SetTerms(ctx context.Context, id string, t *model.CampaignTerms) error {
+	query := `
+		UPDATE campaigns
+		SET terms_version = NULLIF($2, ''), terms_text = NULLIF($3, ''), terms_updated_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, t.Version, t.Text, t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// GetVisibility returns the discovery feed settings of a campaign
+func (r *CampaignRepository) GetVisibility(ctx context.Context, id string) (*model.CampaignVisibility, error) {
+	query := `SELECT discoverable, discovery_mask_amounts, visibility_updated_at FROM campaigns WHERE id = $1`
+	v := &model.CampaignVisibility{}
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&v.Discoverable, &v.MaskAmounts, &v.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetVisibility replaces the discovery feed settings of a campaign. Returns
+// pgx.ErrNoRows if the campaign does not exist.
+func (r *CampaignRepository) SetVisibility(ctx context.Context, id string, v *model.CampaignVisibility) error {
+	query := `
+		UPDATE campaigns
+		SET discoverable = $2, discovery_mask_amounts = $3, visibility_updated_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, v.Discoverable, v.MaskAmounts, v.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// GetCharity returns the charity round-up of a campaign
+func (r *CampaignRepository) GetCharity(ctx context.Context, id string) (*model.CampaignCharity, error) {
+	query := `SELECT COALESCE(charity_address, ''), COALESCE(charity_name, ''), charity_bps, charity_updated_at FROM campaigns WHERE id = $1`
+	c := &model.CampaignCharity{}
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&c.Address, &c.Name, &c.Bps, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// TotalDonated sums the donations of a campaign's claims that were not
+// failed or blocked
+func (r *CampaignRepository) TotalDonated(ctx context.Context, id string) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(c.donation_amount), 0)
+		FROM claims c
+		JOIN red_pockets rp ON c.red_pocket_id = rp.id
+		WHERE rp.campaign_id = $1 AND c.donation_units > 0 AND c.status NOT IN ('failed', 'blocked', 'refunded')
+	`
+	var total float64
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(&total)
+	return total, err
+}
+
+// SetCharity replaces the charity round-up of a campaign. Returns
+// pgx.ErrNoRows if the campaign does not exist.
+func (r *CampaignRepository) SetCharity(ctx context.Context, id string, c *model.CampaignCharity) error {
+	query := `
+		UPDATE campaigns
+		SET charity_address = NULLIF($2, ''), charity_name = NULLIF($3, ''), charity_bps = $4, charity_updated_at = $5, updated_at = NOW()
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, c.Address, c.Name, c.Bps, c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// GetClaimWeights returns the claim weights of a campaign
+func (r *CampaignRepository) GetClaimWeights(ctx context.Context, id string) (*model.CampaignClaimWeights, error) {
+	query := `SELECT claim_weights, claim_weights_updated_at FROM campaigns WHERE id = $1`
+	w := &model.CampaignClaimWeights{}
+	var raw []byte
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&raw, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if raw != nil {
+		if err := json.Unmarshal(raw, &w.Weights); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// SetClaimWeights replaces the claim weights of a campaign. Returns
+// pgx.ErrNoRows if the campaign does not exist.
+func (r *CampaignRepository) SetClaimWeights(ctx context.Context, id string, w *model.CampaignClaimWeights) error {
+	var raw []byte
+	if len(w.Weights) > 0 {
+		var err error
+		if raw, err = json.Marshal(w.Weights); err != nil {
+			return err
+		}
+	}
+	query := `UPDATE campaigns SET claim_weights = $2, claim_weights_updated_at = $3, updated_at = NOW() WHERE id = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, id, raw, w.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// GetFeePlan returns the plan of the enterprise that owns a campaign
+func (r *CampaignRepository) GetFeePlan(ctx context.Context, id string) (string, error) {
+	query := `
+		SELECT COALESCE(e.plan, 'standard')
+		FROM campaigns c
+		LEFT JOIN enterprises e ON e.id = c.enterprise_id
+		WHERE c.id = $1
+	`
+	var plan string
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(&plan)
+	return plan, err
+}
+
+// EnterpriseTestMode reports whether an enterprise is a sandbox tenant.
+// Enterprises without a row, such as those only known from a token, are not.
+func (r *CampaignRepository) EnterpriseTestMode(ctx context.Context, enterpriseID string) (bool, error) {
+	var testMode bool
+	query := `SELECT EXISTS(SELECT 1 FROM enterprises WHERE id = $1 AND test_mode)`
+	err := r.db.Pool.QueryRow(ctx, query, enterpriseID).Scan(&testMode)
+	return testMode, err
+}
+
+// TestMode reports whether a campaign belongs to a sandbox enterprise
+func (r *CampaignRepository) TestMode(ctx context.Context, id string) (bool, error) {
+	var testMode bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT test_mode FROM campaigns WHERE id = $1`, id).Scan(&testMode)
+	return testMode, err
+}
+
+// GrantFaucet adds a faucet grant's fake budget to a sandbox campaign of its
+// enterprise, unless the enterprise already had dailyGrants grants in the
+// last day. Reports false when over the limit. Returns pgx.ErrNoRows if the
+// campaign is not the enterprise's or not in test mode.
+func (r *CampaignRepository) GrantFaucet(ctx context.Context, g *model.FaucetGrant, dailyGrants int) (*model.Campaign, bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Grants of one enterprise are serialised on its row
+	var id string
+	err = tx.QueryRow(ctx, `SELECT id FROM enterprises WHERE id = $1 AND test_mode FOR UPDATE`, g.EnterpriseID).Scan(&id)
+	if err != nil {
+		return nil, false, err
+	}
+	var granted int
+	query := `SELECT COUNT(*) FROM sandbox_faucet_grants WHERE enterprise_id = $1 AND created_at > NOW() - INTERVAL '1 day'`
+	if err := tx.QueryRow(ctx, query, g.EnterpriseID).Scan(&granted); err != nil {
+		return nil, false, err
+	}
+	if granted >= dailyGrants {
+		return nil, false, nil
+	}
+
+	update := `
+		UPDATE campaigns SET total_budget = total_budget + $3, updated_at = NOW()
+		WHERE id = $1 AND enterprise_id = $2 AND test_mode AND status <> 'deleted'
+		RETURNING id, enterprise_id, name, description, total_budget, spent_budget,
+			token, token_address, chain_id, platform, total_pockets, total_claims,
+			tag, status, created_at, updated_at, payout_mode, test_mode
+	`
+	c := &model.Campaign{}
+	err = tx.QueryRow(ctx, update, g.CampaignID, g.EnterpriseID, g.Amount).Scan(
+		&c.ID, &c.EnterpriseID, &c.Name, &c.Description, &c.TotalBudget, &c.SpentBudget,
+		&c.Token, &c.TokenAddress, &c.ChainID, &c.Platform, &c.TotalPockets, &c.TotalClaims,
+		&c.Tag, &c.Status, &c.CreatedAt, &c.UpdatedAt, &c.PayoutMode, &c.TestMode,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	insert := `
+		INSERT INTO sandbox_faucet_grants (id, enterprise_id, campaign_id, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.Exec(ctx, insert, g.ID, g.EnterpriseID, g.CampaignID, g.Amount, g.CreatedAt); err != nil {
+		return nil, false, err
+	}
+	return c, true, tx.Commit(ctx)
+}
+
+// GetFeeSchedule returns the platform fees of a plan
+func (r *CampaignRepository) GetFeeSchedule(ctx context.Context, plan string) (*model.PlatformFeeSchedule, error) {
+	query := `
+		SELECT plan, creation_fee, creation_fee_bps, claim_fee, claim_fee_bps
+		FROM platform_fee_schedules WHERE plan = $1
+	`
+	f := &model.PlatformFeeSchedule{}
+	err := r.db.Pool.QueryRow(ctx, query, plan).Scan(&f.Plan, &f.CreationFee, &f.CreationFeeBps, &f.ClaimFee, &f.ClaimFeeBps)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
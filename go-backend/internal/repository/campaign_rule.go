@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// ruleRunningTimeout frees a rule whose evaluation died while carrying out
+// its action
+const ruleRunningTimeout = 5 * time.Minute
+
+// ruleFailureBackoff is the least time between attempts of a rule whose
+// last run failed, whatever its cooldown
+const ruleFailureBackoff = 60
+
+// CampaignRuleRepository stores the automation rules of campaigns and their
+// runs
+type CampaignRuleRepository struct {
+	db *PostgresDB
+}
+
+func NewCampaignRuleRepository(db *PostgresDB) *CampaignRuleRepository {
+	return &CampaignRuleRepository{db: db}
+}
+
+func (r *CampaignRuleRepository) Create(ctx context.Context, rule *model.CampaignRule) error {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO campaign_rules (id, campaign_id, name, event, conditions, action, params, enabled,
+			max_runs, cooldown, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+	`
+	_, err = r.db.Pool.Exec(ctx, query, rule.ID, rule.CampaignID, rule.Name, rule.Event, conditions,
+		rule.Action, []byte(rule.Params), rule.Enabled, rule.MaxRuns, rule.Cooldown, rule.CreatedAt)
+	return err
+}
+
+// Update replaces a rule's definition, keeping its runs
+func (r *CampaignRuleRepository) Update(ctx context.Context, rule *model.CampaignRule) error {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE campaign_rules
+		SET name = $2, event = $3, conditions = $4, action = $5, params = $6, enabled = $7,
+			max_runs = $8, cooldown = $9, last_error = '', updated_at = $10
+		WHERE id = $1
+	`
+	_, err = r.db.Pool.Exec(ctx, query, rule.ID, rule.Name, rule.Event, conditions, rule.Action,
+		[]byte(rule.Params), rule.Enabled, rule.MaxRuns, rule.Cooldown, rule.UpdatedAt)
+	return err
+}
+
+const ruleColumns = `id, campaign_id, name, event, conditions, action, params, enabled, max_runs,
+	cooldown, runs, last_run_at, last_error, created_at, updated_at`
+
+func (r *CampaignRuleRepository) list(ctx context.Context, query string, args ...interface{}) ([]*model.CampaignRule, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*model.CampaignRule{}
+	for rows.Next() {
+		rule := &model.CampaignRule{}
+		var conditions, params []byte
+		if err := rows.Scan(&rule.ID, &rule.CampaignID, &rule.Name, &rule.Event, &conditions, &rule.Action,
+			&params, &rule.Enabled, &rule.MaxRuns, &rule.Cooldown, &rule.Runs, &rule.LastRunAt,
+			&rule.LastError, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+			return nil, err
+		}
+		rule.Params = params
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// List returns the rules of a campaign, oldest first
+func (r *CampaignRuleRepository) List(ctx context.Context, campaignID string) ([]*model.CampaignRule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM campaign_rules WHERE campaign_id = $1 ORDER BY created_at, id`
+	return r.list(ctx, query, campaignID)
+}
+
+// Active returns the enabled rules of a campaign run on event that have
+// runs left
+func (r *CampaignRuleRepository) Active(ctx context.Context, campaignID, event string) ([]*model.CampaignRule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM campaign_rules
+		WHERE campaign_id = $1 AND event = $2 AND enabled AND (max_runs = 0 OR runs < max_runs)
+		ORDER BY created_at, id`
+	return r.list(ctx, query, campaignID, event)
+}
+
+// ScheduledCampaigns returns the campaigns with enabled schedule rules
+// that have runs left
+func (r *CampaignRuleRepository) ScheduledCampaigns(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT DISTINCT campaign_id FROM campaign_rules
+		WHERE event = 'schedule' AND enabled AND (max_runs = 0 OR runs < max_runs)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Count returns how many rules a campaign has
+func (r *CampaignRuleRepository) Count(ctx context.Context, campaignID string) (int, error) {
+	var n int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM campaign_rules WHERE campaign_id = $1`, campaignID).Scan(&n)
+	return n, err
+}
+
+// Delete removes a rule of a campaign with its runs, reporting whether it
+// did
+func (r *CampaignRuleRepository) Delete(ctx context.Context, campaignID, id string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM campaign_rules WHERE id = $1 AND campaign_id = $2`, id, campaignID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Metrics returns what a campaign's rule conditions are evaluated on
+func (r *CampaignRuleRepository) Metrics(ctx context.Context, campaignID string) (*model.RuleMetrics, error) {
+	query := `
+		SELECT
+			COUNT(c.id),
+			COALESCE(SUM(c.amount), 0),
+			COUNT(DISTINCT c.claimer_id),
+			COUNT(c.id) FILTER (WHERE c.created_at > NOW() - INTERVAL '1 hour'),
+			CASE WHEN camp.total_budget > 0 THEN camp.spent_budget / camp.total_budget * 100 ELSE 0 END,
+			EXTRACT(EPOCH FROM NOW() - camp.created_at) / 3600,
+			EXTRACT(HOUR FROM NOW() AT TIME ZONE 'UTC')::INTEGER
+		FROM campaigns camp
+		LEFT JOIN red_pockets rp ON rp.campaign_id = camp.id
+		LEFT JOIN claims c ON c.red_pocket_id = rp.id AND c.status NOT IN ('failed', 'blocked', 'refunded')
+		WHERE camp.id = $1
+		GROUP BY camp.id
+	`
+	m := &model.RuleMetrics{}
+	err := r.db.Pool.QueryRow(ctx, query, campaignID).Scan(&m.Claims, &m.Amount, &m.UniqueClaimers,
+		&m.ClaimsLastHour, &m.BudgetUsed, &m.ElapsedHours, &m.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Begin takes a rule for carrying out its action, reporting whether it
+// did. It is refused while another evaluation has the rule, when the rule
+// has no runs left, within its cooldown, and when the campaign's rules
+// already ran maxPerHour times in the last hour.
+func (r *CampaignRuleRepository) Begin(ctx context.Context, id string, maxPerHour int) (bool, error) {
+	query := `
+		UPDATE campaign_rules rule SET running_since = NOW()
+		WHERE id = $1 AND enabled AND (max_runs = 0 OR runs < max_runs)
+			AND (running_since IS NULL OR running_since < NOW() - $2 * INTERVAL '1 second')
+			AND (last_run_at IS NULL OR last_run_at <= NOW() -
+				GREATEST(cooldown, CASE WHEN last_error <> '' THEN $3 ELSE 0 END) * INTERVAL '1 second')
+			AND (
+				SELECT COUNT(*) FROM campaign_rule_runs run
+				WHERE run.campaign_id = rule.campaign_id AND run.created_at > NOW() - INTERVAL '1 hour'
+			) < $4
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, int(ruleRunningTimeout.Seconds()), ruleFailureBackoff, maxPerHour)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Finish records the run of a rule taken with Begin and frees the rule. A
+// failed run does not count towards the rule's runs.
+func (r *CampaignRuleRepository) Finish(ctx context.Context, run *model.RuleRun) error {
+	metrics, err := json.Marshal(run.Metrics)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO campaign_rule_runs (id, rule_id, campaign_id, status, metrics, result, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	if _, err := tx.Exec(ctx, query, run.ID, run.RuleID, run.CampaignID, run.Status, metrics,
+		run.Result, run.Error, run.CreatedAt); err != nil {
+		return err
+	}
+	query = `
+		UPDATE campaign_rules
+		SET running_since = NULL, last_run_at = $2, last_error = $3,
+			runs = runs + CASE WHEN $4 = 'succeeded' THEN 1 ELSE 0 END
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, run.RuleID, run.CreatedAt, run.Error, run.Status); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Release frees a rule taken with Begin without recording a run
+func (r *CampaignRuleRepository) Release(ctx context.Context, id string) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE campaign_rules SET running_since = NULL WHERE id = $1`, id)
+	return err
+}
+
+// Runs returns the latest runs of a rule, newest first
+func (r *CampaignRuleRepository) Runs(ctx context.Context, ruleID string, limit int) ([]*model.RuleRun, error) {
+	query := `
+		SELECT id, rule_id, campaign_id, status, metrics, result, error, created_at
+		FROM campaign_rule_runs WHERE rule_id = $1
+		ORDER BY created_at DESC LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, ruleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*model.RuleRun{}
+	for rows.Next() {
+		run := &model.RuleRun{}
+		var metrics []byte
+		if err := rows.Scan(&run.ID, &run.RuleID, &run.CampaignID, &run.Status, &metrics,
+			&run.Result, &run.Error, &run.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(metrics, &run.Metrics); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// RunsLastHour returns how many times a campaign's rules ran in the last
+// hour
+func (r *CampaignRuleRepository) RunsLastHour(ctx context.Context, campaignID string) (int, error) {
+	var n int
+	query := `SELECT COUNT(*) FROM campaign_rule_runs WHERE campaign_id = $1 AND created_at > NOW() - INTERVAL '1 hour'`
+	err := r.db.Pool.QueryRow(ctx, query, campaignID).Scan(&n)
+	return n, err
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/protocolbank/redpocket-backend/internal/model"
 )
@@ -15,30 +17,66 @@ func NewClaimRepository(db *PostgresDB) *ClaimRepository {
 }
 
 func (r *ClaimRepository) Create(ctx context.Context, c *model.Claim) error {
+	return insertClaim(ctx, r.db.Pool, c)
+}
+
+func insertClaim(ctx context.Context, db execer, c *model.Claim) error {
+	var splits []byte
+	if len(c.PayoutSplits) > 0 {
+		var err error
+		if splits, err = json.Marshal(c.PayoutSplits); err != nil {
+			return err
+		}
+	}
 	query := `
-		INSERT INTO claims (id, red_pocket_id, claimer_id, platform_id, platform, wallet_address, amount, tx_hash, status, created_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO claims (
+			id, red_pocket_id, claimer_id, platform_id, platform, wallet_address, amount, tx_hash, status, created_at, completed_at, attempts,
+			terms_version, terms_accepted_at, terms_ip, payout_splits, amount_units,
+			charity_address, donation_amount, donation_units, savings_vault_id, savings_units, link_id, channel_id, multiplier,
+			fee_amount, fee_units, test_mode
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, GREATEST($12, 1), NULLIF($13, ''), $14, NULLIF($15, ''), $16, $17,
+			NULLIF($18, ''), $19, $20, NULLIF($21, ''), $22, NULLIF($23, ''), NULLIF($24, ''), $25, $26, $27, $28)
 	`
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err := db.Exec(ctx, query,
 		c.ID, c.RedPocketID, c.ClaimerID, c.PlatformID, c.Platform, c.WalletAddress,
-		c.Amount, c.TxHash, c.Status, c.CreatedAt, c.CompletedAt,
+		c.Amount, c.TxHash, c.Status, c.CreatedAt, c.CompletedAt, c.Attempts,
+		c.TermsVersion, c.TermsAcceptedAt, c.TermsIP, splits, c.AmountUnits,
+		c.CharityAddress, c.Donation, c.DonationUnits, c.SavingsVaultID, c.SavingsUnits, c.LinkID, c.ChannelID, c.Multiplier,
+		c.Fee, c.FeeUnits, c.TestMode,
 	)
 	return err
 }
 
 func (r *ClaimRepository) GetByID(ctx context.Context, id string) (*model.Claim, error) {
 	query := `
-		SELECT id, red_pocket_id, claimer_id, platform_id, platform, wallet_address, amount, tx_hash, status, created_at, completed_at
+		SELECT id, red_pocket_id, claimer_id, platform_id, platform, wallet_address, amount, tx_hash, status, created_at, completed_at, attempts,
+			COALESCE(terms_version, ''), terms_accepted_at, COALESCE(terms_ip, ''),
+			COALESCE(screening_id, ''), COALESCE(screening_result, ''), payout_splits, amount_units,
+			COALESCE(charity_address, ''), donation_amount, donation_units,
+			COALESCE(savings_vault_id, ''), savings_units, COALESCE(channel_id, ''), multiplier,
+			fee_amount, fee_units, test_mode
 		FROM claims WHERE id = $1
 	`
 	c := &model.Claim{}
+	var splits []byte
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&c.ID, &c.RedPocketID, &c.ClaimerID, &c.PlatformID, &c.Platform, &c.WalletAddress,
-		&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt,
+		&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt, &c.Attempts,
+		&c.TermsVersion, &c.TermsAcceptedAt, &c.TermsIP,
+		&c.ScreeningID, &c.ScreeningResult, &splits, &c.AmountUnits,
+		&c.CharityAddress, &c.Donation, &c.DonationUnits,
+		&c.SavingsVaultID, &c.SavingsUnits, &c.ChannelID, &c.Multiplier,
+		&c.Fee, &c.FeeUnits, &c.TestMode,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if splits != nil {
+		if err := json.Unmarshal(splits, &c.PayoutSplits); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
@@ -55,19 +93,106 @@ func (r *ClaimRepository) HasClaimed(ctx context.Context, redPocketID, platformI
 	return exists, err
 }
 
-func (r *ClaimRepository) UpdateStatus(ctx context.Context, id, status, txHash string) error {
+// settleCampaignBudget adds the claims in the changed CTE (red_pocket_id,
+// amount, status, old_status) that became paid (success or confirmed) to
+// their campaign's spent budget, and takes back those that stopped being paid
+const settleCampaignBudget = `
+	UPDATE campaigns camp
+	SET spent_budget = camp.spent_budget + s.amount, updated_at = NOW()
+	FROM (
+		SELECT rp.campaign_id,
+			SUM(CASE WHEN ch.status IN ('success', 'confirmed') THEN ch.amount ELSE 0 END)
+				- SUM(CASE WHEN ch.old_status IN ('success', 'confirmed') THEN ch.amount ELSE 0 END) AS amount
+		FROM changed ch
+		JOIN red_pockets rp ON rp.id = ch.red_pocket_id
+		GROUP BY rp.campaign_id
+	) s
+	WHERE camp.id = s.campaign_id AND s.amount <> 0
+`
+
+// bookClaimFees credits the platform fees withheld from the claims in the
+// changed CTE (id, red_pocket_id, fee_amount, status) that became paid to
+// the platform revenue ledger account, once per claim
+const bookClaimFees = `
+	fee_entries AS (
+		INSERT INTO ledger_entries (id, user_id, chain_id, token, token_address, amount, kind, ref_id, created_at)
+		SELECT 'ledger_' || left(gen_random_uuid()::text, 8), '` + model.PlatformRevenueAccount + `',
+			rp.chain_id, rp.token, rp.token_address, ch.fee_amount, 'claim_fee', ch.id, NOW()
+		FROM changed ch
+		JOIN red_pockets rp ON rp.id = ch.red_pocket_id
+		WHERE ch.fee_amount > 0 AND ch.status IN ('success', 'confirmed')
+		ON CONFLICT (kind, ref_id) DO NOTHING
+		RETURNING user_id, chain_id, token, token_address, amount
+	), fee_balances AS (
+		INSERT INTO ledger_balances (user_id, chain_id, token, token_address, balance, updated_at)
+		SELECT user_id, chain_id, token, MIN(token_address), SUM(amount), NOW()
+		FROM fee_entries
+		GROUP BY user_id, chain_id, token
+		ON CONFLICT (user_id, chain_id, token)
+		DO UPDATE SET balance = ledger_balances.balance + EXCLUDED.balance, updated_at = NOW()
+	)
+`
+
+// Transition moves a claim to a status and records why; a claim settling as
+// success counts towards its campaign's spent budget and has its platform
+// fee booked. The update only applies while the claim is in a status
+// model.ClaimStatus allows the move from, and reports false otherwise or if
+// there is no such claim.
+func (r *ClaimRepository) Transition(ctx context.Context, id string, to model.ClaimStatus, txHash, reason string) (bool, error) {
 	query := `
-		UPDATE claims 
-		SET status = $2, tx_hash = $3, completed_at = CASE WHEN $2 IN ('success', 'failed') THEN NOW() ELSE completed_at END
-		WHERE id = $1
+		WITH changed AS (
+			UPDATE claims c
+			SET status = $2, tx_hash = $3, status_reason = NULLIF($4, ''),
+				completed_at = CASE WHEN $2 IN ('success', 'failed', 'blocked') THEN NOW() ELSE c.completed_at END
+			FROM (SELECT id, status FROM claims WHERE id = $1 FOR UPDATE) old
+			WHERE c.id = old.id AND old.status = ANY($5)
+			RETURNING c.id, c.red_pocket_id, c.amount, c.fee_amount, c.status, old.status AS old_status
+		), settled AS (` + settleCampaignBudget + `), ` + bookClaimFees + `
+		SELECT COUNT(*) FROM changed
 	`
-	_, err := r.db.Pool.Exec(ctx, query, id, status, txHash)
+	var n int
+	err := r.db.Pool.QueryRow(ctx, query, id, to, txHash, reason, model.ClaimStatusesBefore(to)).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// History returns a claim's status changes, oldest first
+func (r *ClaimRepository) History(ctx context.Context, id string) ([]*model.ClaimTransition, error) {
+	query := `
+		SELECT COALESCE(from_status, ''), to_status, COALESCE(tx_hash, ''), COALESCE(reason, ''), created_at
+		FROM claim_status_history
+		WHERE claim_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*model.ClaimTransition
+	for rows.Next() {
+		t := &model.ClaimTransition{}
+		if err := rows.Scan(&t.From, &t.To, &t.TxHash, &t.Reason, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, t)
+	}
+	return history, rows.Err()
+}
+
+// SetScreening records the latest sanctions screening of a claim's payout address
+func (r *ClaimRepository) SetScreening(ctx context.Context, id, screeningID, result string) error {
+	query := `UPDATE claims SET screening_id = $2, screening_result = $3 WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id, screeningID, result)
 	return err
 }
 
 func (r *ClaimRepository) ListByRedPocket(ctx context.Context, redPocketID string, limit, offset int) ([]*model.Claim, error) {
 	query := `
-		SELECT id, red_pocket_id, claimer_id, platform_id, platform, wallet_address, amount, tx_hash, status, created_at, completed_at
+		SELECT id, red_pocket_id, claimer_id, platform_id, platform, wallet_address, amount, tx_hash, status, created_at, completed_at, attempts
 		FROM claims WHERE red_pocket_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
@@ -83,7 +208,7 @@ func (r *ClaimRepository) ListByRedPocket(ctx context.Context, redPocketID strin
 		c := &model.Claim{}
 		err := rows.Scan(
 			&c.ID, &c.RedPocketID, &c.ClaimerID, &c.PlatformID, &c.Platform, &c.WalletAddress,
-			&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt,
+			&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt, &c.Attempts,
 		)
 		if err != nil {
 			return nil, err
@@ -106,7 +231,11 @@ func (r *ClaimRepository) ListByCampaign(ctx context.Context, campaignID string,
 	}
 
 	query := `
-		SELECT c.id, c.red_pocket_id, c.claimer_id, c.platform_id, c.platform, c.wallet_address, c.amount, c.tx_hash, c.status, c.created_at, c.completed_at
+		SELECT c.id, c.red_pocket_id, c.claimer_id, c.platform_id, c.platform, c.wallet_address, c.amount, c.tx_hash, c.status, c.created_at, c.completed_at, c.attempts,
+			COALESCE(c.terms_version, ''), c.terms_accepted_at, COALESCE(c.terms_ip, ''),
+			COALESCE(c.screening_id, ''), COALESCE(c.screening_result, ''),
+			COALESCE(c.charity_address, ''), c.donation_amount, c.donation_units,
+			c.fee_amount, c.fee_units, c.test_mode
 		FROM claims c
 		JOIN red_pockets rp ON c.red_pocket_id = rp.id
 		WHERE rp.campaign_id = $1
@@ -124,7 +253,11 @@ func (r *ClaimRepository) ListByCampaign(ctx context.Context, campaignID string,
 		c := &model.Claim{}
 		err := rows.Scan(
 			&c.ID, &c.RedPocketID, &c.ClaimerID, &c.PlatformID, &c.Platform, &c.WalletAddress,
-			&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt,
+			&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt, &c.Attempts,
+			&c.TermsVersion, &c.TermsAcceptedAt, &c.TermsIP,
+			&c.ScreeningID, &c.ScreeningResult,
+			&c.CharityAddress, &c.Donation, &c.DonationUnits,
+			&c.Fee, &c.FeeUnits, &c.TestMode,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -152,7 +285,9 @@ func (r *ClaimRepository) ListByEnterprise(ctx context.Context, enterpriseID str
 	}
 
 	query := `
-		SELECT c.id, c.red_pocket_id, c.claimer_id, c.platform_id, c.platform, c.wallet_address, c.amount, c.tx_hash, c.status, c.created_at, c.completed_at
+		SELECT c.id, c.red_pocket_id, c.claimer_id, c.platform_id, c.platform, c.wallet_address, c.amount, c.tx_hash, c.status, c.created_at, c.completed_at, c.attempts,
+			COALESCE(c.terms_version, ''), c.terms_accepted_at, COALESCE(c.terms_ip, ''),
+			COALESCE(c.screening_id, ''), COALESCE(c.screening_result, '')
 		FROM claims c
 		ORDER BY c.created_at DESC
 		LIMIT $1 OFFSET $2
@@ -168,7 +303,9 @@ func (r *ClaimRepository) ListByEnterprise(ctx context.Context, enterpriseID str
 		c := &model.Claim{}
 		err := rows.Scan(
 			&c.ID, &c.RedPocketID, &c.ClaimerID, &c.PlatformID, &c.Platform, &c.WalletAddress,
-			&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt,
+			&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt, &c.Attempts,
+			&c.TermsVersion, &c.TermsAcceptedAt, &c.TermsIP,
+			&c.ScreeningID, &c.ScreeningResult,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -177,3 +314,258 @@ func (r *ClaimRepository) ListByEnterprise(ctx context.Context, enterpriseID str
 	}
 	return claims, total, nil
 }
+
+// ListAllByRedPocket returns every claim of a red pocket in claim order
+func (r *ClaimRepository) ListAllByRedPocket(ctx context.Context, redPocketID string) ([]*model.Claim, error) {
+	query := `
+		SELECT id, red_pocket_id, claimer_id, platform_id, platform, wallet_address, amount, COALESCE(tx_hash, ''), status, created_at, completed_at, attempts
+		FROM claims WHERE red_pocket_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, redPocketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []*model.Claim
+	for rows.Next() {
+		c := &model.Claim{}
+		err := rows.Scan(
+			&c.ID, &c.RedPocketID, &c.ClaimerID, &c.PlatformID, &c.Platform, &c.WalletAddress,
+			&c.Amount, &c.TxHash, &c.Status, &c.CreatedAt, &c.CompletedAt, &c.Attempts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, c)
+	}
+	return claims, nil
+}
+
+// MarkResubmitted flags a claim whose payout was replaced and records the
+// attempt number. Reports false if the claim cannot be resubmitted.
+func (r *ClaimRepository) MarkResubmitted(ctx context.Context, id string, attempts int, reason string) (bool, error) {
+	query := `
+		UPDATE claims SET status = 'resubmitted', attempts = $2, status_reason = NULLIF($3, '')
+		WHERE id = $1 AND status = ANY($4)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, attempts, reason, model.ClaimStatusesBefore(model.ClaimResubmitted))
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// TransitionBatch moves every claim paid by a settlement batch to a status
+// as Transition does, settling successful ones against their campaign's
+// budget and booking their platform fees. Claims the move is not allowed from are left as they are, and
+// their number returned.
+func (r *ClaimRepository) TransitionBatch(ctx context.Context, batchID string, to model.ClaimStatus, txHash, reason string) (int, error) {
+	query := `
+		WITH old AS (
+			SELECT id, status FROM claims
+			WHERE id IN (SELECT claim_id FROM payout_jobs WHERE batch_id = $1)
+			FOR UPDATE
+		), changed AS (
+			UPDATE claims c
+			SET status = $2, tx_hash = $3, status_reason = NULLIF($4, ''),
+				completed_at = CASE WHEN $2 IN ('success', 'failed') THEN NOW() ELSE c.completed_at END
+			FROM old
+			WHERE c.id = old.id AND old.status = ANY($5)
+			RETURNING c.id, c.red_pocket_id, c.amount, c.fee_amount, c.status, old.status AS old_status
+		), settled AS (` + settleCampaignBudget + `), ` + bookClaimFees + `
+		SELECT (SELECT COUNT(*) FROM old) - (SELECT COUNT(*) FROM changed)
+	`
+	var refused int
+	err := r.db.Pool.QueryRow(ctx, query, batchID, to, txHash, reason, model.ClaimStatusesBefore(to)).Scan(&refused)
+	return refused, err
+}
+
+// IDsByBatch returns the claims paid by a settlement batch
+func (r *ClaimRepository) IDsByBatch(ctx context.Context, batchID string) ([]string, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT claim_id FROM payout_jobs WHERE batch_id = $1`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MarkResubmittedByBatch flags the claims of a settlement batch whose payout
+// was replaced, leaving those that cannot be resubmitted
+func (r *ClaimRepository) MarkResubmittedByBatch(ctx context.Context, batchID string, attempts int, reason string) error {
+	query := `
+		UPDATE claims c SET status = 'resubmitted', attempts = $2, status_reason = NULLIF($3, '')
+		FROM payout_jobs j
+		WHERE j.claim_id = c.id AND j.batch_id = $1 AND c.status = ANY($4)
+	`
+	_, err := r.db.Pool.Exec(ctx, query, batchID, attempts, reason, model.ClaimStatusesBefore(model.ClaimResubmitted))
+	return err
+}
+
+// LinkUsed reports whether a one-time claim link has been claimed with
+func (r *ClaimRepository) LinkUsed(ctx context.Context, linkID string) (bool, error) {
+	var used bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM claims WHERE link_id = $1)`, linkID).Scan(&used)
+	return used, err
+}
+
+// FirstClaimAt returns when a platform account first claimed anything, or
+// nil if it never has
+func (r *ClaimRepository) FirstClaimAt(ctx context.Context, platform, platformID string) (*time.Time, error) {
+	query := `SELECT MIN(created_at) FROM claims WHERE platform = $1 AND platform_id = $2`
+	var first *time.Time
+	err := r.db.Pool.QueryRow(ctx, query, platform, platformID).Scan(&first)
+	return first, err
+}
+
+// ListByClaimer returns a platform account's claims, newest first, with the
+// pockets they came from
+func (r *ClaimRepository) ListByClaimer(ctx context.Context, platform, platformID string, limit, offset int) ([]*model.ClaimerClaim, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM claims WHERE platform = $1 AND platform_id = $2`
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, countQuery, platform, platformID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT c.id, c.red_pocket_id, rp.sender_name, COALESCE(rp.message, ''), rp.chain_id, rp.token, rp.is_lucky_draw,
+			c.amount, c.fee_amount, c.donation_amount, c.status, COALESCE(c.tx_hash, ''), c.created_at, c.completed_at
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		WHERE c.platform = $1 AND c.platform_id = $2
+		ORDER BY c.created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, platform, platformID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var claims []*model.ClaimerClaim
+	for rows.Next() {
+		c := &model.ClaimerClaim{}
+		err := rows.Scan(
+			&c.ID, &c.RedPocketID, &c.SenderName, &c.Message, &c.ChainID, &c.Token, &c.IsLuckyDraw,
+			&c.Amount, &c.Fee, &c.Donation, &c.Status, &c.TxHash, &c.CreatedAt, &c.CompletedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		claims = append(claims, c)
+	}
+	return claims, total, rows.Err()
+}
+
+// ClaimerTotals sums what a platform account has been paid per token
+func (r *ClaimRepository) ClaimerTotals(ctx context.Context, platform, platformID string) ([]*model.ClaimerTotal, error) {
+	query := `
+		SELECT rp.chain_id, rp.token, COUNT(*), SUM(c.amount),
+			SUM(c.amount - c.fee_amount - c.donation_amount), MAX(c.amount)
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		WHERE c.platform = $1 AND c.platform_id = $2 AND c.status IN ('success', 'confirmed')
+		GROUP BY rp.chain_id, rp.token
+		ORDER BY rp.chain_id, rp.token
+	`
+	rows, err := r.db.Pool.Query(ctx, query, platform, platformID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []*model.ClaimerTotal{}
+	for rows.Next() {
+		t := &model.ClaimerTotal{}
+		if err := rows.Scan(&t.ChainID, &t.Token, &t.Claims, &t.Amount, &t.Received, &t.Largest); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+// ClaimerChainSummary counts and sums a user's claims per chain and token,
+// split into those still on their way and those confirmed on chain
+func (r *ClaimRepository) ClaimerChainSummary(ctx context.Context, claimerID string) ([]*model.ChainClaimSummary, error) {
+	query := `
+		SELECT rp.chain_id, rp.token,
+			COUNT(*) FILTER (WHERE c.status <> 'confirmed'),
+			COALESCE(SUM(c.amount) FILTER (WHERE c.status <> 'confirmed'), 0),
+			COUNT(*) FILTER (WHERE c.status = 'confirmed'),
+			COALESCE(SUM(c.amount) FILTER (WHERE c.status = 'confirmed'), 0)
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		WHERE c.claimer_id = $1
+			AND c.status IN ('pending', 'held', 'processing', 'retrying', 'success', 'resubmitted', 'confirmed')
+		GROUP BY rp.chain_id, rp.token
+		ORDER BY rp.chain_id, rp.token
+	`
+	rows, err := r.db.Pool.Query(ctx, query, claimerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := []*model.ChainClaimSummary{}
+	for rows.Next() {
+		s := &model.ChainClaimSummary{}
+		if err := rows.Scan(&s.ChainID, &s.Token, &s.Pending, &s.PendingAmount, &s.Confirmed, &s.ConfirmedAmount); err != nil {
+			return nil, err
+		}
+		summary = append(summary, s)
+	}
+	return summary, rows.Err()
+}
+
+// Leaderboard returns a pocket's biggest claims, earliest first on ties,
+// leaving out claims that were failed, blocked or refunded
+func (r *ClaimRepository) Leaderboard(ctx context.Context, redPocketID string, limit int) ([]*model.LeaderboardEntry, error) {
+	query := `
+		SELECT id, platform, platform_id, amount, created_at
+		FROM claims
+		WHERE red_pocket_id = $1 AND status NOT IN ('failed', 'blocked', 'refunded')
+		ORDER BY amount DESC, created_at ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, redPocketID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.LeaderboardEntry{}
+	for rows.Next() {
+		e := &model.LeaderboardEntry{Rank: len(entries) + 1}
+		if err := rows.Scan(&e.ClaimID, &e.Platform, &e.PlatformID, &e.Amount, &e.ClaimedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ClaimedTotals counts and sums a pocket's claims that were not failed,
+// blocked or refunded
+func (r *ClaimRepository) ClaimedTotals(ctx context.Context, redPocketID string) (int, float64, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(amount), 0)
+		FROM claims
+		WHERE red_pocket_id = $1 AND status NOT IN ('failed', 'blocked', 'refunded')
+	`
+	var count int
+	var amount float64
+	err := r.db.Pool.QueryRow(ctx, query, redPocketID).Scan(&count, &amount)
+	return count, amount, err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type ClaimFailureRepository struct {
+	db *PostgresDB
+}
+
+func NewClaimFailureRepository(db *PostgresDB) *ClaimFailureRepository {
+	return &ClaimFailureRepository{db: db}
+}
+
+func (r *ClaimFailureRepository) Create(ctx context.Context, f *model.ClaimFailure) error {
+	query := `
+		INSERT INTO claim_failures (reference, red_pocket_id, platform, platform_id, wallet_address, client_ip,
+			country, device_fingerprint, locale, error_code, error, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (reference) DO NOTHING
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		f.Reference, f.RedPocketID, f.Platform, f.PlatformID, f.WalletAddress, f.ClientIP,
+		f.Country, f.DeviceFingerprint, f.Locale, f.ErrorCode, f.Error, f.RequestID, f.CreatedAt,
+	)
+	return err
+}
+
+// GetByReference returns a failure by its support reference, or
+// pgx.ErrNoRows
+func (r *ClaimFailureRepository) GetByReference(ctx context.Context, reference string) (*model.ClaimFailure, error) {
+	query := `
+		SELECT reference, red_pocket_id, platform, platform_id, wallet_address, client_ip,
+			country, device_fingerprint, locale, error_code, error, request_id, created_at
+		FROM claim_failures
+		WHERE reference = $1
+	`
+	f := &model.ClaimFailure{}
+	err := r.db.Pool.QueryRow(ctx, query, reference).Scan(
+		&f.Reference, &f.RedPocketID, &f.Platform, &f.PlatformID, &f.WalletAddress, &f.ClientIP,
+		&f.Country, &f.DeviceFingerprint, &f.Locale, &f.ErrorCode, &f.Error, &f.RequestID, &f.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// DeleteBefore purges failures recorded before cutoff
+func (r *ClaimFailureRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM claim_failures WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
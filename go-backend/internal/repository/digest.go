@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type DigestRepository struct {
+	db *PostgresDB
+}
+
+func NewDigestRepository(db *PostgresDB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+const digestColumns = `enterprise_id, platform, channel_id, locale, send_hour, enabled, last_sent_on, COALESCE(last_error, ''), created_at, updated_at`
+
+func scanDigest(row interface{ Scan(...interface{}) error }) (*model.DigestSubscription, error) {
+	d := &model.DigestSubscription{}
+	err := row.Scan(&d.EnterpriseID, &d.Platform, &d.ChannelID, &d.Locale, &d.Hour, &d.Enabled, &d.LastSentOn, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Get returns the enterprise's digest subscription, or pgx.ErrNoRows
+func (r *DigestRepository) Get(ctx context.Context, enterpriseID string) (*model.DigestSubscription, error) {
+	query := `SELECT ` + digestColumns + ` FROM daily_digests WHERE enterprise_id = $1`
+	return scanDigest(r.db.Pool.QueryRow(ctx, query, enterpriseID))
+}
+
+// Upsert creates or replaces the enterprise's subscription, keeping the day
+// last sent so changing it does not post a day twice
+func (r *DigestRepository) Upsert(ctx context.Context, d *model.DigestSubscription) (*model.DigestSubscription, error) {
+	query := `
+		INSERT INTO daily_digests (enterprise_id, platform, channel_id, locale, send_hour, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (enterprise_id) DO UPDATE SET
+			platform = EXCLUDED.platform, channel_id = EXCLUDED.channel_id, locale = EXCLUDED.locale,
+			send_hour = EXCLUDED.send_hour, enabled = EXCLUDED.enabled, last_error = NULL, updated_at = EXCLUDED.updated_at
+		RETURNING ` + digestColumns
+	return scanDigest(r.db.Pool.QueryRow(ctx, query,
+		d.EnterpriseID, d.Platform, d.ChannelID, d.Locale, d.Hour, d.Enabled, d.UpdatedAt,
+	))
+}
+
+// Delete removes the enterprise's subscription, reporting whether it had one
+func (r *DigestRepository) Delete(ctx context.Context, enterpriseID string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM daily_digests WHERE enterprise_id = $1`, enterpriseID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Due returns enabled subscriptions whose hour has come and that have not
+// covered day yet
+func (r *DigestRepository) Due(ctx context.Context, day time.Time, hour, limit int) ([]*model.DigestSubscription, error) {
+	query := `
+		SELECT ` + digestColumns + `
+		FROM daily_digests
+		WHERE enabled AND send_hour <= $2 AND (last_sent_on IS NULL OR last_sent_on < $1::date)
+		ORDER BY send_hour, enterprise_id
+		LIMIT $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, day, hour, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var digests []*model.DigestSubscription
+	for rows.Next() {
+		d, err := scanDigest(rows)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, d)
+	}
+	return digests, rows.Err()
+}
+
+// MarkSent records that the enterprise's digest of day is being posted,
+// reporting false when another sweep already took it
+func (r *DigestRepository) MarkSent(ctx context.Context, enterpriseID string, day time.Time) (bool, error) {
+	query := `
+		UPDATE daily_digests SET last_sent_on = $2::date, last_error = NULL
+		WHERE enterprise_id = $1 AND (last_sent_on IS NULL OR last_sent_on < $2::date)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, enterpriseID, day)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetError records why the enterprise's last digest could not be posted
+func (r *DigestRepository) SetError(ctx context.Context, enterpriseID, msg string) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE daily_digests SET last_error = $2 WHERE enterprise_id = $1`, enterpriseID, msg)
+	return err
+}
+
+// Stats returns the enterprise's activity between from and to
+func (r *DigestRepository) Stats(ctx context.Context, enterpriseID string, from, to time.Time) (*model.DailyDigest, error) {
+	digest := &model.DailyDigest{Day: from, Distributed: []*model.DigestAmount{}}
+
+	pockets := `
+		SELECT COUNT(*)
+		FROM red_pockets rp
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE camp.enterprise_id = $1 AND rp.created_at >= $2 AND rp.created_at < $3
+	`
+	if err := r.db.Pool.QueryRow(ctx, pockets, enterpriseID, from, to).Scan(&digest.PocketsCreated); err != nil {
+		return nil, err
+	}
+
+	claims := `
+		SELECT COUNT(*), COUNT(DISTINCT c.claimer_id)
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE camp.enterprise_id = $1 AND c.created_at >= $2 AND c.created_at < $3
+			AND c.status NOT IN ('failed', 'blocked', 'refunded')
+	`
+	if err := r.db.Pool.QueryRow(ctx, claims, enterpriseID, from, to).Scan(&digest.Claims, &digest.Claimers); err != nil {
+		return nil, err
+	}
+	if digest.Claims == 0 {
+		return digest, nil
+	}
+
+	byToken := `
+		SELECT rp.token, SUM(c.amount)
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE camp.enterprise_id = $1 AND c.created_at >= $2 AND c.created_at < $3
+			AND c.status NOT IN ('failed', 'blocked', 'refunded')
+		GROUP BY rp.token
+		ORDER BY SUM(c.amount) DESC, rp.token
+	`
+	rows, err := r.db.Pool.Query(ctx, byToken, enterpriseID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := &model.DigestAmount{}
+		if err := rows.Scan(&a.Token, &a.Amount); err != nil {
+			return nil, err
+		}
+		digest.Distributed = append(digest.Distributed, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	top := `
+		SELECT rp.id, rp.sender_name, rp.token, COUNT(*), SUM(c.amount)
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE camp.enterprise_id = $1 AND c.created_at >= $2 AND c.created_at < $3
+			AND c.status NOT IN ('failed', 'blocked', 'refunded')
+		GROUP BY rp.id, rp.sender_name, rp.token
+		ORDER BY COUNT(*) DESC, SUM(c.amount) DESC, rp.id
+		LIMIT 1
+	`
+	p := &model.DigestPocket{}
+	if err := r.db.Pool.QueryRow(ctx, top, enterpriseID, from, to).Scan(&p.ID, &p.SenderName, &p.Token, &p.Claims, &p.Amount); err != nil {
+		return nil, err
+	}
+	digest.TopPocket = p
+	return digest, nil
+}
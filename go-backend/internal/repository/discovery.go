@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// DiscoveryFilter personalizes the discovery feed. Empty fields apply no boost.
+type DiscoveryFilter struct {
+	Sort       string // trending, recent
+	Platform   string // boosts pockets on the viewer's platform
+	Locale     string // boosts pockets in the viewer's language
+	PlatformID string // with Platform, boosts tokens the viewer claimed before
+}
+
+type DiscoveryRepository struct {
+	db *PostgresDB
+}
+
+func NewDiscoveryRepository(db *PostgresDB) *DiscoveryRepository {
+	return &DiscoveryRepository{db: db}
+}
+
+// discoverableWhere matches claimable pockets of discoverable campaigns.
+// Password-protected pockets, pockets needing a signed claim link and
+// sandbox pockets are never listed.
+const discoverableWhere = `
+	FROM red_pockets rp
+	JOIN campaigns c ON c.id = rp.campaign_id
+	LEFT JOIN pocket_engagement e ON e.red_pocket_id = rp.id
+	WHERE c.discoverable
+		AND NOT rp.test_mode
+		AND rp.status = 'active'
+		AND rp.expires_at > NOW()
+		AND rp.claimed_count < rp.total_count
+		AND rp.claim_password_hash = ''
+		AND NOT rp.signed_links
+`
+
+// ListDiscoverable returns the feed. Trending ranks by engagement score,
+// boosted for the viewer's platform, language and previously claimed tokens;
+// pockets not scored yet count as brand new.
+func (r *DiscoveryRepository) ListDiscoverable(ctx context.Context, f DiscoveryFilter, limit, offset int) ([]*model.DiscoveryPocket, int64, error) {
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) `+discoverableWhere).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args := []interface{}{limit, offset, f.Platform, f.Locale, f.PlatformID}
+	order := `
+		COALESCE(e.score, LN(2 + rp.remaining_amount) / POWER(EXTRACT(EPOCH FROM NOW() - rp.created_at) / 3600 + 2, 1.5))
+		* CASE WHEN $3 <> '' AND rp.platform = $3 THEN 1.5 ELSE 1 END
+		* CASE WHEN $4 <> '' AND rp.locale = $4 THEN 1.3 ELSE 1 END
+		* CASE WHEN $5 <> '' AND rp.token IN (
+			SELECT p.token FROM claims cl JOIN red_pockets p ON p.id = cl.red_pocket_id
+			WHERE cl.platform = $3 AND cl.platform_id = $5
+		) THEN 1.5 ELSE 1 END DESC,
+		rp.created_at DESC
+	`
+	if f.Sort == "recent" {
+		order = `rp.created_at DESC`
+		args = args[:2]
+	}
+	query := `
+		SELECT rp.id, c.name, rp.sender_name, rp.sender_avatar, rp.message, rp.cover_image, rp.tag,
+			rp.token, rp.chain_id, rp.platform, rp.amount, rp.remaining_amount,
+			rp.total_count, rp.claimed_count, rp.is_lucky_draw, rp.captcha_mode, rp.locale,
+			rp.expires_at, rp.created_at, c.discovery_mask_amounts,
+			COALESCE(e.claims_per_minute, 0)::FLOAT8, COALESCE(e.unique_claimers, 0), COALESCE(e.shares, 0), COALESCE(e.score, 0)
+	` + discoverableWhere + `
+		ORDER BY ` + order + `
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []*model.DiscoveryPocket{}
+	for rows.Next() {
+		p := &model.DiscoveryPocket{}
+		var amount, remaining float64
+		var masked bool
+		err := rows.Scan(
+			&p.ID, &p.CampaignName, &p.SenderName, &p.SenderAvatar, &p.Message, &p.CoverImage, &p.Tag,
+			&p.Token, &p.ChainID, &p.Platform, &amount, &remaining,
+			&p.TotalCount, &p.ClaimedCount, &p.IsLuckyDraw, &p.CaptchaMode, &p.Locale,
+			&p.ExpiresAt, &p.CreatedAt, &masked,
+			&p.ClaimsPerMinute, &p.UniqueClaimers, &p.Shares, &p.Score,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !masked {
+			p.Amount = &amount
+			p.RemainingAmount = &remaining
+		}
+		results = append(results, p)
+	}
+	return results, total, rows.Err()
+}
+
+// RecordShare counts a share of a pocket once per sharer. Returns false for
+// a repeat share.
+func (r *DiscoveryRepository) RecordShare(ctx context.Context, redPocketID, sharer, platform string) (bool, error) {
+	query := `
+		INSERT INTO pocket_shares (red_pocket_id, sharer, platform, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (red_pocket_id, sharer) DO NOTHING
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, redPocketID, sharer, platform)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RefreshEngagement recomputes the signals and score of every listed pocket
+// and drops rows of pockets that left the feed. Claims per minute is over the
+// last ten minutes; the score weighs claim velocity, claimers and shares by
+// remaining value and decays with age.
+func (r *DiscoveryRepository) RefreshEngagement(ctx context.Context) (int64, error) {
+	query := `
+		INSERT INTO pocket_engagement (red_pocket_id, claims_per_minute, unique_claimers, shares, score, computed_at)
+		SELECT id, cpm, claimers, shares,
+			(1 + cpm * 10 + claimers + shares * 3) * LN(2 + remaining_amount)
+				/ POWER(EXTRACT(EPOCH FROM NOW() - created_at) / 3600 + 2, 1.5),
+			NOW()
+		FROM (
+			SELECT rp.id, rp.remaining_amount, rp.created_at,
+				(SELECT COUNT(*) FROM claims cl
+					WHERE cl.red_pocket_id = rp.id AND cl.created_at > NOW() - INTERVAL '10 minutes') / 10.0 AS cpm,
+				(SELECT COUNT(DISTINCT cl.claimer_id) FROM claims cl WHERE cl.red_pocket_id = rp.id) AS claimers,
+				(SELECT COUNT(*) FROM pocket_shares s WHERE s.red_pocket_id = rp.id) AS shares
+			FROM red_pockets rp
+			JOIN campaigns c ON c.id = rp.campaign_id
+			WHERE c.discoverable AND rp.status = 'active' AND rp.claim_password_hash = '' AND NOT rp.signed_links
+				AND NOT rp.test_mode
+		) signals
+		ON CONFLICT (red_pocket_id) DO UPDATE SET
+			claims_per_minute = EXCLUDED.claims_per_minute,
+			unique_claimers = EXCLUDED.unique_claimers,
+			shares = EXCLUDED.shares,
+			score = EXCLUDED.score,
+			computed_at = EXCLUDED.computed_at
+	`
+	tag, err := r.db.Pool.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	cleanup := `
+		DELETE FROM pocket_engagement e
+		USING red_pockets rp
+		WHERE rp.id = e.red_pocket_id AND rp.status <> 'active'
+	`
+	if _, err := r.db.Pool.Exec(ctx, cleanup); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
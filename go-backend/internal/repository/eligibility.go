@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type EligibilityRepository struct {
+	db *PostgresDB
+}
+
+func NewEligibilityRepository(db *PostgresDB) *EligibilityRepository {
+	return &EligibilityRepository{db: db}
+}
+
+// ListForClaim returns the rules a claim of a red pocket must pass: the
+// campaign's, the pocket's own and those of the series it belongs to
+func (r *EligibilityRepository) ListForClaim(ctx context.Context, campaignID, redPocketID, seriesID string) ([]*model.EligibilityRule, error) {
+	query := `
+		SELECT id, COALESCE(campaign_id, ''), COALESCE(red_pocket_id, ''), type, params, created_at
+		FROM eligibility_rules
+		WHERE campaign_id = $1 OR red_pocket_id = $2 OR red_pocket_id = NULLIF($3, '')
+		ORDER BY created_at, id
+	`
+	return r.list(ctx, query, campaignID, redPocketID, seriesID)
+}
+
+// ListByCampaign returns the rules attached to a campaign itself
+func (r *EligibilityRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*model.EligibilityRule, error) {
+	query := `
+		SELECT id, COALESCE(campaign_id, ''), COALESCE(red_pocket_id, ''), type, params, created_at
+		FROM eligibility_rules WHERE campaign_id = $1
+		ORDER BY created_at, id
+	`
+	return r.list(ctx, query, campaignID)
+}
+
+func (r *EligibilityRepository) list(ctx context.Context, query string, args ...interface{}) ([]*model.EligibilityRule, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*model.EligibilityRule{}
+	for rows.Next() {
+		rule := &model.EligibilityRule{}
+		var params []byte
+		if err := rows.Scan(&rule.ID, &rule.CampaignID, &rule.RedPocketID, &rule.Type, &params, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(params, &rule.Params); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// ReplaceForCampaign swaps a campaign's rules for rules in one transaction
+func (r *EligibilityRepository) ReplaceForCampaign(ctx context.Context, campaignID string, rules []*model.EligibilityRule) error {
+	return r.replace(ctx, `DELETE FROM eligibility_rules WHERE campaign_id = $1`, campaignID, rules)
+}
+
+// ReplaceForRedPocket swaps a red pocket's rules for rules in one transaction
+func (r *EligibilityRepository) ReplaceForRedPocket(ctx context.Context, redPocketID string, rules []*model.EligibilityRule) error {
+	return r.replace(ctx, `DELETE FROM eligibility_rules WHERE red_pocket_id = $1`, redPocketID, rules)
+}
+
+func (r *EligibilityRepository) replace(ctx context.Context, deleteQuery, id string, rules []*model.EligibilityRule) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, deleteQuery, id); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO eligibility_rules (id, campaign_id, red_pocket_id, type, params, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6)
+	`
+	for _, rule := range rules {
+		params, err := json.Marshal(rule.Params)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, query, rule.ID, rule.CampaignID, rule.RedPocketID, rule.Type, params, rule.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
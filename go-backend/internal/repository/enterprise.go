@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type EnterpriseRepository struct {
+	db *PostgresDB
+}
+
+func NewEnterpriseRepository(db *PostgresDB) *EnterpriseRepository {
+	return &EnterpriseRepository{db: db}
+}
+
+// GetByID returns an enterprise without its key hashes, or pgx.ErrNoRows
+func (r *EnterpriseRepository) GetByID(ctx context.Context, id string) (*model.Enterprise, error) {
+	query := `
+		SELECT id, name, email, status, created_at, api_key_created_at, api_key_previous_expires_at,
+			COALESCE(partner_id, ''), partner_attached_at, test_mode
+		FROM enterprises
+		WHERE id = $1
+	`
+	e := &model.Enterprise{}
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&e.ID, &e.Name, &e.Email, &e.Status, &e.CreatedAt, &e.APIKeyCreatedAt, &e.APIKeyPreviousExpiresAt,
+		&e.PartnerID, &e.PartnerAttachedAt, &e.TestMode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetByAPIKeyHash returns the active enterprise whose current key, or
+// previous key still within its grace period, has the hash, or pgx.ErrNoRows
+func (r *EnterpriseRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*model.Enterprise, error) {
+	query := `
+		SELECT id, name, email, status, created_at, test_mode
+		FROM enterprises
+		WHERE status = 'active'
+			AND (api_key_hash = $1 OR (api_key_previous_hash = $1 AND api_key_previous_expires_at > NOW()))
+	`
+	e := &model.Enterprise{}
+	err := r.db.Pool.QueryRow(ctx, query, hash).Scan(&e.ID, &e.Name, &e.Email, &e.Status, &e.CreatedAt, &e.TestMode)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// CreateSandbox records a sandbox enterprise on plan, with its API key.
+// Reports false if the email is taken.
+func (r *EnterpriseRepository) CreateSandbox(ctx context.Context, e *model.Enterprise, plan string) (bool, error) {
+	query := `
+		INSERT INTO enterprises (id, name, email, status, created_at, api_key_hash, api_key_created_at, plan, test_mode)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, TRUE)
+		ON CONFLICT (email) DO NOTHING
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, e.ID, e.Name, e.Email, e.Status, e.CreatedAt, e.APIKeyHash, e.APIKeyCreatedAt, plan)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CreateAPIKey sets the enterprise's key. Reports false if it already has
+// one.
+func (r *EnterpriseRepository) CreateAPIKey(ctx context.Context, id, hash string, at time.Time) (bool, error) {
+	query := `
+		UPDATE enterprises SET api_key_hash = $2, api_key_created_at = $3
+		WHERE id = $1 AND api_key_hash IS NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, hash, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RotateAPIKey replaces the enterprise's key, keeping the replaced one
+// valid until previousUntil. Reports false if it has no key.
+func (r *EnterpriseRepository) RotateAPIKey(ctx context.Context, id, hash string, at, previousUntil time.Time) (bool, error) {
+	query := `
+		UPDATE enterprises SET
+			api_key_previous_hash = api_key_hash,
+			api_key_previous_expires_at = $4,
+			api_key_hash = $2,
+			api_key_created_at = $3
+		WHERE id = $1 AND api_key_hash IS NOT NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, hash, at, previousUntil)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RevokeAPIKey clears the enterprise's current and previous keys. Reports
+// false if it has no key.
+func (r *EnterpriseRepository) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	query := `
+		UPDATE enterprises SET
+			api_key_hash = NULL,
+			api_key_created_at = NULL,
+			api_key_previous_hash = NULL,
+			api_key_previous_expires_at = NULL
+		WHERE id = $1 AND api_key_hash IS NOT NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
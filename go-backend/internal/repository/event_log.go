@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// replayTables are the tables the event log records, in the order a row
+// must be applied in so that the rows it references are there first
+var replayTables = []string{"red_pockets", "claims", "payout_jobs", "red_pocket_refunds"}
+
+const eventLogColumns = `id, table_name, op, row_id, COALESCE(red_pocket_id, ''), COALESCE(status, ''), payload, created_at`
+
+type EventLogRepository struct {
+	db *PostgresDB
+}
+
+func NewEventLogRepository(db *PostgresDB) *EventLogRepository {
+	return &EventLogRepository{db: db}
+}
+
+func scanLoggedEvents(rows pgx.Rows) ([]*model.LoggedEvent, error) {
+	defer rows.Close()
+	var events []*model.LoggedEvent
+	for rows.Next() {
+		e := &model.LoggedEvent{}
+		if err := rows.Scan(&e.ID, &e.Table, &e.Op, &e.RowID, &e.RedPocketID, &e.Status, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// List returns logged events in the order they were recorded
+func (r *EventLogRepository) List(ctx context.Context, f *model.EventLogFilter) ([]*model.LoggedEvent, error) {
+	query := `
+		SELECT ` + eventLogColumns + `
+		FROM event_log
+		WHERE created_at >= $1 AND created_at < $2 AND id > $3
+			AND ($4 = '' OR red_pocket_id = $4)
+		ORDER BY id
+		LIMIT $5
+	`
+	rows, err := r.db.Pool.Query(ctx, query, f.From, f.To, f.AfterID, f.RedPocketID, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLoggedEvents(rows)
+}
+
+// Count returns how many events were recorded between from and to
+func (r *EventLogRepository) Count(ctx context.Context, from, to time.Time) (int, error) {
+	var n int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM event_log WHERE created_at >= $1 AND created_at < $2`, from, to).Scan(&n)
+	return n, err
+}
+
+// DeleteBefore purges events recorded before cutoff
+func (r *EventLogRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM event_log WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// baseStates returns, for every row the events between from and to touch
+// or depend on, its last event before from: the state the range starts from
+func (r *EventLogRepository) baseStates(ctx context.Context, from, to time.Time) ([]*model.LoggedEvent, error) {
+	query := `
+		WITH touched AS (
+			SELECT table_name, row_id, red_pocket_id, payload
+			FROM event_log
+			WHERE created_at >= $1 AND created_at < $2
+		)
+		SELECT DISTINCT ON (table_name, row_id) ` + eventLogColumns + `
+		FROM event_log e
+		WHERE e.created_at < $1 AND (
+			(e.table_name, e.row_id) IN (SELECT table_name, row_id FROM touched)
+			OR (e.table_name = 'red_pockets' AND e.row_id IN (SELECT red_pocket_id FROM touched))
+			OR (e.table_name = 'claims' AND e.row_id IN (
+				SELECT payload->>'claim_id' FROM touched WHERE table_name = 'payout_jobs'))
+		)
+		ORDER BY table_name, row_id, id DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	events, err := scanLoggedEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var ordered []*model.LoggedEvent
+	for _, table := range replayTables {
+		for _, e := range events {
+			if e.Table == table {
+				ordered = append(ordered, e)
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// Replay writes the rows recorded between from and to into target, in the
+// order they were recorded, after first restoring every row they touch or
+// depend on to its state at from. Rows are upserted with the target's
+// triggers and foreign keys off (session_replication_role = replica), so
+// the target's own event log and status histories are left alone and rows
+// outside the log, such as campaigns, need not exist; the target role must
+// be allowed to set it. It returns the number of rows restored and events
+// replayed.
+func (r *EventLogRepository) Replay(ctx context.Context, target *PostgresDB, from, to time.Time, batch int) (int, int, error) {
+	columns, err := replayColumns(ctx, target)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	base, err := r.baseStates(ctx, from, to)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load base states: %w", err)
+	}
+	if err := applyEvents(ctx, target, columns, base); err != nil {
+		return 0, 0, fmt.Errorf("failed to restore base states: %w", err)
+	}
+
+	replayed := 0
+	f := &model.EventLogFilter{From: from, To: to, Limit: batch}
+	for {
+		events, err := r.List(ctx, f)
+		if err != nil {
+			return len(base), replayed, err
+		}
+		if len(events) == 0 {
+			return len(base), replayed, nil
+		}
+		if err := applyEvents(ctx, target, columns, events); err != nil {
+			return len(base), replayed, fmt.Errorf("failed to replay event %d: %w", events[0].ID, err)
+		}
+		replayed += len(events)
+		f.AfterID = events[len(events)-1].ID
+	}
+}
+
+// replayColumns returns the columns of each replayed table in target
+func replayColumns(ctx context.Context, target *PostgresDB) (map[string]map[string]bool, error) {
+	query := `
+		SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+	`
+	rows, err := target.Pool.Query(ctx, query, replayTables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		if columns[table] == nil {
+			columns[table] = make(map[string]bool)
+		}
+		columns[table][column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, table := range replayTables {
+		if columns[table] == nil {
+			return nil, fmt.Errorf("target has no %s table", table)
+		}
+	}
+	return columns, nil
+}
+
+// applyEvents upserts the rows of events into target in one transaction
+func applyEvents(ctx context.Context, target *PostgresDB, columns map[string]map[string]bool, events []*model.LoggedEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	tx, err := target.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SET LOCAL session_replication_role = replica`); err != nil {
+		return err
+	}
+	for _, e := range events {
+		query, err := upsertQuery(e, columns[e.Table])
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, query, e.Payload); err != nil {
+			return fmt.Errorf("%s %s (event %d): %w", e.Table, e.RowID, e.ID, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// upsertQuery builds the upsert of an event's row, taking the columns both
+// the payload and the target table have so schemas a migration apart still
+// replay; columns the payload lacks keep their defaults
+func upsertQuery(e *model.LoggedEvent, tableColumns map[string]bool) (string, error) {
+	if tableColumns == nil {
+		return "", fmt.Errorf("event %d is on %s, which is not replayed", e.ID, e.Table)
+	}
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(e.Payload, &row); err != nil {
+		return "", fmt.Errorf("event %d: %w", e.ID, err)
+	}
+
+	var cols, updates []string
+	for col := range row {
+		if !tableColumns[col] {
+			continue
+		}
+		ident := pgx.Identifier{col}.Sanitize()
+		cols = append(cols, ident)
+		if col != "id" {
+			updates = append(updates, ident+" = EXCLUDED."+ident)
+		}
+	}
+	if len(updates) == 0 {
+		return "", fmt.Errorf("event %d has no columns to replay", e.ID)
+	}
+
+	table := pgx.Identifier{e.Table}.Sanitize()
+	list := strings.Join(cols, ", ")
+	return `INSERT INTO ` + table + ` (` + list + `)
+		SELECT ` + list + ` FROM jsonb_populate_record(NULL::` + table + `, $1)
+		ON CONFLICT (id) DO UPDATE SET ` + strings.Join(updates, ", "), nil
+}
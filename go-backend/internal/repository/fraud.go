@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type FraudRepository struct {
+	db *PostgresDB
+}
+
+func NewFraudRepository(db *PostgresDB) *FraudRepository {
+	return &FraudRepository{db: db}
+}
+
+func (r *FraudRepository) Create(ctx context.Context, a *model.FraudAssessment) error {
+	signals, err := json.Marshal(a.Signals)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO fraud_assessments (id, red_pocket_id, campaign_id, claim_id, platform, platform_id, client_ip,
+			device_fingerprint, wallet_address, score, signals, action, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12, $13)
+	`
+	_, err = r.db.Pool.Exec(ctx, query,
+		a.ID, a.RedPocketID, a.CampaignID, a.ClaimID, a.Platform, a.PlatformID, a.ClientIP,
+		a.DeviceFingerprint, a.WalletAddress, a.Score, signals, a.Action, a.CreatedAt,
+	)
+	return err
+}
+
+const fraudColumns = `
+	f.id, f.red_pocket_id, f.campaign_id, COALESCE(f.claim_id, ''), f.platform, f.platform_id,
+	COALESCE(f.client_ip, ''), COALESCE(f.device_fingerprint, ''), COALESCE(f.wallet_address, ''),
+	f.score, f.signals, f.action, COALESCE(f.review_status, ''), COALESCE(f.review_note, ''),
+	f.reviewed_at, f.created_at
+`
+
+func scanFraudAssessment(row interface{ Scan(...interface{}) error }) (*model.FraudAssessment, error) {
+	a := &model.FraudAssessment{}
+	var signals []byte
+	err := row.Scan(
+		&a.ID, &a.RedPocketID, &a.CampaignID, &a.ClaimID, &a.Platform, &a.PlatformID,
+		&a.ClientIP, &a.DeviceFingerprint, &a.WalletAddress,
+		&a.Score, &signals, &a.Action, &a.ReviewStatus, &a.ReviewNote,
+		&a.ReviewedAt, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(signals, &a.Signals); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// GetByID returns an assessment if it belongs to one of the enterprise's
+// campaigns, or pgx.ErrNoRows
+func (r *FraudRepository) GetByID(ctx context.Context, id, enterpriseID string) (*model.FraudAssessment, error) {
+	query := `
+		SELECT ` + fraudColumns + `
+		FROM fraud_assessments f
+		JOIN campaigns camp ON camp.id = f.campaign_id
+		WHERE f.id = $1 AND camp.enterprise_id = $2
+	`
+	return scanFraudAssessment(r.db.Pool.QueryRow(ctx, query, id, enterpriseID))
+}
+
+// ListFlagged returns the attempts on the enterprise's campaigns that were
+// not simply allowed, newest first. reviewStatus open lists the unreviewed
+// ones; empty lists all.
+func (r *FraudRepository) ListFlagged(ctx context.Context, enterpriseID, reviewStatus string, limit, offset int) ([]*model.FraudAssessment, int64, error) {
+	where := `
+		FROM fraud_assessments f
+		JOIN campaigns camp ON camp.id = f.campaign_id
+		WHERE camp.enterprise_id = $1 AND f.action <> 'allow'
+			AND ($2 = '' OR ($2 = 'open' AND f.review_status IS NULL) OR f.review_status = $2)
+	`
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) `+where, enterpriseID, reviewStatus).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + fraudColumns + where + ` ORDER BY f.created_at DESC LIMIT $3 OFFSET $4`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID, reviewStatus, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	assessments := []*model.FraudAssessment{}
+	for rows.Next() {
+		a, err := scanFraudAssessment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		assessments = append(assessments, a)
+	}
+	return assessments, total, rows.Err()
+}
+
+// Review records the enterprise's decision on an assessment. It reports
+// false if the assessment was already reviewed.
+func (r *FraudRepository) Review(ctx context.Context, a *model.FraudAssessment) (bool, error) {
+	query := `
+		UPDATE fraud_assessments
+		SET review_status = $2, review_note = NULLIF($3, ''), reviewed_at = NOW()
+		WHERE id = $1 AND review_status IS NULL
+		RETURNING reviewed_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, a.ID, a.ReviewStatus, a.ReviewNote)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+	return true, rows.Scan(&a.ReviewedAt)
+}
+
+// ClaimersByIP counts the other claimers seen at ip since since
+func (r *FraudRepository) ClaimersByIP(ctx context.Context, ip, platform, platformID string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT (platform, platform_id)) FROM fraud_assessments
+		WHERE client_ip = $1 AND created_at >= $4 AND NOT (platform = $2 AND platform_id = $3)
+	`
+	var n int
+	err := r.db.Pool.QueryRow(ctx, query, ip, platform, platformID, since).Scan(&n)
+	return n, err
+}
+
+// ClaimersByDevice counts the other claimers seen on a device since since
+func (r *FraudRepository) ClaimersByDevice(ctx context.Context, fingerprint, platform, platformID string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT (platform, platform_id)) FROM fraud_assessments
+		WHERE device_fingerprint = $1 AND created_at >= $4 AND NOT (platform = $2 AND platform_id = $3)
+	`
+	var n int
+	err := r.db.Pool.QueryRow(ctx, query, fingerprint, platform, platformID, since).Scan(&n)
+	return n, err
+}
+
+// PocketsClaimedSince counts the red pockets a claimer tried to claim since since
+func (r *FraudRepository) PocketsClaimedSince(ctx context.Context, platform, platformID string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT red_pocket_id) FROM fraud_assessments
+		WHERE platform = $1 AND platform_id = $2 AND created_at >= $3
+	`
+	var n int
+	err := r.db.Pool.QueryRow(ctx, query, platform, platformID, since).Scan(&n)
+	return n, err
+}
+
+// LinkedClaimers counts the other users tied to userID by an external
+// address: a payout split or withdrawal destination either of them uses, or
+// a wallet both proved holding. proofWallet is the wallet userID proves now.
+func (r *FraudRepository) LinkedClaimers(ctx context.Context, userID, proofWallet string) (int, error) {
+	query := `
+		WITH links AS (
+			SELECT p.user_id, LOWER(s->>'address') AS address
+			FROM payout_preferences p, jsonb_array_elements(p.splits) s
+			UNION
+			SELECT user_id, LOWER(to_address) FROM withdrawals
+			UNION
+			SELECT 'user_' || platform || '_' || platform_id, LOWER(wallet_address)
+			FROM fraud_assessments WHERE wallet_address IS NOT NULL
+		), mine AS (
+			SELECT address FROM links WHERE user_id = $1
+			UNION
+			SELECT LOWER($2) WHERE $2 <> ''
+		)
+		SELECT COUNT(DISTINCT user_id) FROM links
+		WHERE address IN (SELECT address FROM mine) AND user_id <> $1
+	`
+	var n int
+	err := r.db.Pool.QueryRow(ctx, query, userID, proofWallet).Scan(&n)
+	return n, err
+}
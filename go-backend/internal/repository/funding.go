@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type FundingRepository struct {
+	db *PostgresDB
+}
+
+func NewFundingRepository(db *PostgresDB) *FundingRepository {
+	return &FundingRepository{db: db}
+}
+
+// ListFundedCampaigns returns the active campaigns whose funding wallet has
+// been created, i.e. that an enterprise may have deposited into.
+// walletPrefix is prepended to the campaign ID to get the wallet owner ID.
+func (r *FundingRepository) ListFundedCampaigns(ctx context.Context, walletPrefix string) ([]*model.Campaign, error) {
+	query := `
+		SELECT c.id, c.enterprise_id, c.token, COALESCE(c.token_address, ''), c.chain_id
+		FROM campaigns c
+		JOIN wallets w ON w.user_id = $1 || c.id AND w.chain_id = c.chain_id
+		WHERE c.status = 'active'
+		ORDER BY c.created_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, walletPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []*model.Campaign
+	for rows.Next() {
+		c := &model.Campaign{}
+		if err := rows.Scan(&c.ID, &c.EnterpriseID, &c.Token, &c.TokenAddress, &c.ChainID); err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+	return campaigns, rows.Err()
+}
+
+func (r *FundingRepository) CreateConversion(ctx context.Context, c *model.FundingConversion) error {
+	query := `
+		INSERT INTO funding_conversions (
+			id, campaign_id, chain_id, wallet_address, sell_token, sell_token_address, sell_units,
+			buy_token, buy_token_address, quoted_units, min_buy_units, provider, status, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		c.ID, c.CampaignID, c.ChainID, c.WalletAddress, c.SellToken, c.SellTokenAddress, c.SellUnits,
+		c.BuyToken, c.BuyTokenAddress, c.QuotedUnits, c.MinBuyUnits, c.Provider, c.Status, c.CreatedAt,
+	)
+	return err
+}
+
+// UpdateConversion records the outcome of a conversion
+func (r *FundingRepository) UpdateConversion(ctx context.Context, c *model.FundingConversion) error {
+	query := `
+		UPDATE funding_conversions
+		SET status = $2, tx_hash = NULLIF($3, ''), user_op_hash = NULLIF($4, ''), error = NULLIF($5, ''),
+			completed_at = CASE WHEN $2 IN ('success', 'failed') THEN NOW() ELSE completed_at END
+		WHERE id = $1
+		RETURNING completed_at
+	`
+	return r.db.Pool.QueryRow(ctx, query, c.ID, c.Status, c.TxHash, c.UserOpHash, c.Error).Scan(&c.CompletedAt)
+}
+
+// ListConversions returns a campaign's conversions, newest first
+func (r *FundingRepository) ListConversions(ctx context.Context, campaignID string, limit int) ([]*model.FundingConversion, error) {
+	query := `
+		SELECT id, campaign_id, chain_id, wallet_address, sell_token, COALESCE(sell_token_address, ''), sell_units,
+			buy_token, buy_token_address, quoted_units, min_buy_units, provider, status, COALESCE(tx_hash, ''),
+			COALESCE(user_op_hash, ''), COALESCE(error, ''), created_at, completed_at
+		FROM funding_conversions WHERE campaign_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, campaignID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversions := []*model.FundingConversion{}
+	for rows.Next() {
+		c := &model.FundingConversion{}
+		err := rows.Scan(
+			&c.ID, &c.CampaignID, &c.ChainID, &c.WalletAddress, &c.SellToken, &c.SellTokenAddress, &c.SellUnits,
+			&c.BuyToken, &c.BuyTokenAddress, &c.QuotedUnits, &c.MinBuyUnits, &c.Provider, &c.Status, &c.TxHash,
+			&c.UserOpHash, &c.Error, &c.CreatedAt, &c.CompletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		conversions = append(conversions, c)
+	}
+	return conversions, rows.Err()
+}
+
+// ListPendingConversions returns the conversions of a campaign whose swap
+// has not settled yet
+func (r *FundingRepository) ListPendingConversions(ctx context.Context, campaignID string) ([]*model.FundingConversion, error) {
+	query := `
+		SELECT id, sell_token, COALESCE(user_op_hash, ''), created_at
+		FROM funding_conversions WHERE campaign_id = $1 AND status = 'pending'
+	`
+	rows, err := r.db.Pool.Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversions []*model.FundingConversion
+	for rows.Next() {
+		c := &model.FundingConversion{CampaignID: campaignID, Status: "pending"}
+		if err := rows.Scan(&c.ID, &c.SellToken, &c.UserOpHash, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		conversions = append(conversions, c)
+	}
+	return conversions, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type IPFSPinRepository struct {
+	db *PostgresDB
+}
+
+func NewIPFSPinRepository(db *PostgresDB) *IPFSPinRepository {
+	return &IPFSPinRepository{db: db}
+}
+
+func (r *IPFSPinRepository) Create(ctx context.Context, p *model.IPFSPin) error {
+	query := `
+		INSERT INTO ipfs_pins (id, kind, ref_id, name, provider, cid, size, status, error, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7, $8, NULLIF($9, ''), $10, $11)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		p.ID, p.Kind, p.RefID, p.Name, p.Provider, p.CID, p.Size, p.Status, p.Error, p.CreatedAt, p.UpdatedAt,
+	)
+	return err
+}
+
+const ipfsPinColumns = `
+	id, kind, COALESCE(ref_id, ''), name, provider, COALESCE(cid, ''), size, status,
+	COALESCE(error, ''), created_at, updated_at
+`
+
+func (r *IPFSPinRepository) GetByID(ctx context.Context, id string) (*model.IPFSPin, error) {
+	query := `SELECT ` + ipfsPinColumns + ` FROM ipfs_pins WHERE id = $1`
+	p := &model.IPFSPin{}
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&p.ID, &p.Kind, &p.RefID, &p.Name, &p.Provider, &p.CID, &p.Size, &p.Status,
+		&p.Error, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *IPFSPinRepository) ListByRef(ctx context.Context, kind, refID string) ([]*model.IPFSPin, error) {
+	query := `
+		SELECT ` + ipfsPinColumns + `
+		FROM ipfs_pins
+		WHERE kind = $1 AND ref_id = $2
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, kind, refID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pins []*model.IPFSPin
+	for rows.Next() {
+		p := &model.IPFSPin{}
+		err := rows.Scan(
+			&p.ID, &p.Kind, &p.RefID, &p.Name, &p.Provider, &p.CID, &p.Size, &p.Status,
+			&p.Error, &p.CreatedAt, &p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pins = append(pins, p)
+	}
+	return pins, nil
+}
+
+// UpdateStatus records the outcome of a pin request; an empty cid keeps the existing one
+func (r *IPFSPinRepository) UpdateStatus(ctx context.Context, id, status, cid string, size int64, errMsg string) error {
+	query := `
+		UPDATE ipfs_pins
+		SET status = $2, cid = COALESCE(NULLIF($3, ''), cid), size = GREATEST($4, size),
+			error = NULLIF($5, ''), updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, cid, size, errMsg)
+	return err
+}
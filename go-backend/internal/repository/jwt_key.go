@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/encryption"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// JWTKeyRepository stores token signing keys with their private keys sealed
+// by the encryptor
+type JWTKeyRepository struct {
+	db  *PostgresDB
+	enc *encryption.Encryptor
+}
+
+func NewJWTKeyRepository(db *PostgresDB, enc *encryption.Encryptor) *JWTKeyRepository {
+	return &JWTKeyRepository{db: db, enc: enc}
+}
+
+// Rotate adds k as the signing key and retires the keys it replaces at
+// retireAt. Keys that retired before forgetBefore are deleted.
+func (r *JWTKeyRepository) Rotate(ctx context.Context, k *model.JWTSigningKey, retireAt, forgetBefore time.Time) error {
+	sealed, err := r.enc.Encrypt(ctx, k.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE jwt_signing_keys SET retires_at = $1 WHERE retires_at IS NULL`, retireAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM jwt_signing_keys WHERE retires_at < $1`, forgetBefore); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO jwt_signing_keys (kid, algorithm, private_key, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.Exec(ctx, query, k.KID, k.Algorithm, sealed, k.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListUsable returns the keys that have not retired by now, newest first,
+// with their private keys
+func (r *JWTKeyRepository) ListUsable(ctx context.Context, now time.Time) ([]*model.JWTSigningKey, error) {
+	query := `
+		SELECT kid, algorithm, private_key, created_at, retires_at
+		FROM jwt_signing_keys
+		WHERE retires_at IS NULL OR retires_at > $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*model.JWTSigningKey
+	for rows.Next() {
+		k := &model.JWTSigningKey{}
+		if err := rows.Scan(&k.KID, &k.Algorithm, &k.PrivateKey, &k.CreatedAt, &k.RetiresAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		plaintext, err := r.enc.Decrypt(ctx, k.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key %s: %w", k.KID, err)
+		}
+		k.PrivateKey = plaintext
+	}
+	return keys, nil
+}
@@ -0,0 +1,454 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type LedgerRepository struct {
+	db *PostgresDB
+}
+
+func NewLedgerRepository(db *PostgresDB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// Credit records positive entries and adds them to their users' balances in
+// one transaction. Entries are unique per (kind, ref), so crediting the same
+// claim twice is a no-op.
+func (r *LedgerRepository) Credit(ctx context.Context, entries ...*model.LedgerEntry) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, e := range entries {
+		if err := r.credit(ctx, tx, e); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *LedgerRepository) credit(ctx context.Context, tx pgx.Tx, e *model.LedgerEntry) error {
+	query := `
+		INSERT INTO ledger_entries (id, user_id, chain_id, token, token_address, amount, kind, ref_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (kind, ref_id) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query,
+		e.ID, e.UserID, e.ChainID, e.Token, e.TokenAddress, e.Amount, e.Kind, e.RefID, e.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	query = `
+		INSERT INTO ledger_balances (user_id, chain_id, token, token_address, balance, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (user_id, chain_id, token)
+		DO UPDATE SET balance = ledger_balances.balance + EXCLUDED.balance, updated_at = NOW()
+	`
+	_, err = tx.Exec(ctx, query, e.UserID, e.ChainID, e.Token, e.TokenAddress, e.Amount)
+	return err
+}
+
+func (r *LedgerRepository) ListBalances(ctx context.Context, userID string) ([]*model.LedgerBalance, error) {
+	query := `
+		SELECT user_id, chain_id, token, token_address, balance, updated_at
+		FROM ledger_balances WHERE user_id = $1
+		ORDER BY chain_id, token
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []*model.LedgerBalance
+	for rows.Next() {
+		b := &model.LedgerBalance{}
+		if err := rows.Scan(&b.UserID, &b.ChainID, &b.Token, &b.TokenAddress, &b.Balance, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
+
+func (r *LedgerRepository) ListEntries(ctx context.Context, userID string, limit, offset int) ([]*model.LedgerEntry, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM ledger_entries WHERE user_id = $1`
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, countQuery, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, user_id, chain_id, token, token_address, amount, kind, ref_id, created_at
+		FROM ledger_entries WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []*model.LedgerEntry
+	for rows.Next() {
+		e := &model.LedgerEntry{}
+		err := rows.Scan(&e.ID, &e.UserID, &e.ChainID, &e.Token, &e.TokenAddress, &e.Amount, &e.Kind, &e.RefID, &e.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, nil
+}
+
+// CreateWithdrawal debits the balance and records the withdrawal in one
+// transaction. Returns false without writing anything if the balance is short.
+func (r *LedgerRepository) CreateWithdrawal(ctx context.Context, w *model.Withdrawal, entryID string) (bool, error) {
+	travelRule, err := marshalTravelRule(w.TravelRule)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE ledger_balances SET balance = balance - $4, updated_at = NOW()
+		WHERE user_id = $1 AND chain_id = $2 AND token = $3 AND balance >= $4
+		RETURNING token_address
+	`
+	err = tx.QueryRow(ctx, query, w.UserID, w.ChainID, w.Token, w.Amount).Scan(&w.TokenAddress)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	query = `
+		INSERT INTO withdrawals (
+			id, user_id, source, chain_id, token, token_address, amount, to_address, speed, fee, status, scheduled_at, created_at,
+			screening_id, screening_result, travel_rule
+		) VALUES ($1, $2, 'ledger', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, ''), NULLIF($14, ''), $15)
+	`
+	_, err = tx.Exec(ctx, query,
+		w.ID, w.UserID, w.ChainID, w.Token, w.TokenAddress, w.Amount, w.ToAddress, w.Speed, w.Fee, w.Status, w.ScheduledAt, w.CreatedAt,
+		w.ScreeningID, w.ScreeningResult, travelRule,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	query = `
+		INSERT INTO ledger_entries (id, user_id, chain_id, token, token_address, amount, kind, ref_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'withdrawal', $7, $8)
+	`
+	_, err = tx.Exec(ctx, query,
+		entryID, w.UserID, w.ChainID, w.Token, w.TokenAddress, -w.Amount, w.ID, w.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// CreateWalletWithdrawal records a withdrawal from the user's own wallet; no
+// ledger balance is involved
+func (r *LedgerRepository) CreateWalletWithdrawal(ctx context.Context, w *model.Withdrawal) error {
+	query := `
+		INSERT INTO withdrawals (
+			id, user_id, source, from_address, chain_id, token, token_address, amount, to_address, speed, fee, status, scheduled_at, created_at,
+			screening_id, screening_result, travel_rule,
+			offramp_provider, fiat_currency, fiat_amount, payment_method
+		) VALUES ($1, $2, 'wallet', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), NULLIF($15, ''), $16,
+			NULLIF($17, ''), NULLIF($18, ''), $19, NULLIF($20, ''))
+	`
+	travelRule, err := marshalTravelRule(w.TravelRule)
+	if err != nil {
+		return err
+	}
+	offRamp := w.OffRamp
+	if offRamp == nil {
+		offRamp = &model.OffRampOrder{}
+	}
+	var fiatAmount *float64
+	if offRamp.Provider != "" {
+		fiatAmount = &offRamp.FiatAmount
+	}
+	_, err = r.db.Pool.Exec(ctx, query,
+		w.ID, w.UserID, w.FromAddress, w.ChainID, w.Token, w.TokenAddress, w.Amount, w.ToAddress, w.Speed, w.Fee, w.Status, w.ScheduledAt, w.CreatedAt,
+		w.ScreeningID, w.ScreeningResult, travelRule,
+		offRamp.Provider, offRamp.FiatCurrency, fiatAmount, offRamp.PaymentMethod,
+	)
+	return err
+}
+
+const withdrawalColumns = `
+	id, user_id, source, COALESCE(from_address, ''), chain_id, token, token_address, amount, to_address, speed, fee, COALESCE(batch_id, ''), status,
+	COALESCE(tx_hash, ''), COALESCE(user_op_hash, ''), COALESCE(error, ''), scheduled_at, created_at, completed_at,
+	COALESCE(screening_id, ''), COALESCE(screening_result, ''), travel_rule,
+	COALESCE(offramp_provider, ''), COALESCE(offramp_order_id, ''), COALESCE(offramp_status, ''),
+	COALESCE(fiat_currency, ''), COALESCE(fiat_amount, 0), COALESCE(payment_method, '')
+`
+
+func scanWithdrawal(row interface{ Scan(...interface{}) error }) (*model.Withdrawal, error) {
+	w := &model.Withdrawal{}
+	var travelRule []byte
+	offRamp := &model.OffRampOrder{}
+	err := row.Scan(
+		&w.ID, &w.UserID, &w.Source, &w.FromAddress, &w.ChainID, &w.Token, &w.TokenAddress, &w.Amount, &w.ToAddress, &w.Speed, &w.Fee, &w.BatchID, &w.Status,
+		&w.TxHash, &w.UserOpHash, &w.Error, &w.ScheduledAt, &w.CreatedAt, &w.CompletedAt,
+		&w.ScreeningID, &w.ScreeningResult, &travelRule,
+		&offRamp.Provider, &offRamp.OrderID, &offRamp.Status,
+		&offRamp.FiatCurrency, &offRamp.FiatAmount, &offRamp.PaymentMethod,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if offRamp.Provider != "" {
+		w.OffRamp = offRamp
+	}
+	if travelRule != nil {
+		w.TravelRule = &model.TravelRuleInfo{}
+		if err := json.Unmarshal(travelRule, w.TravelRule); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// marshalTravelRule encodes travel rule information for its JSONB column;
+// nil stays NULL
+func marshalTravelRule(info *model.TravelRuleInfo) ([]byte, error) {
+	if info == nil {
+		return nil, nil
+	}
+	return json.Marshal(info)
+}
+
+func (r *LedgerRepository) GetWithdrawal(ctx context.Context, id string) (*model.Withdrawal, error) {
+	query := `SELECT ` + withdrawalColumns + ` FROM withdrawals WHERE id = $1`
+	return scanWithdrawal(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+func (r *LedgerRepository) ListWithdrawals(ctx context.Context, userID string, limit, offset int) ([]*model.Withdrawal, error) {
+	query := `
+		SELECT ` + withdrawalColumns + `
+		FROM withdrawals WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	return r.listWithdrawals(ctx, query, userID, limit, offset)
+}
+
+// ListOpenWithdrawals returns due instant withdrawals and processing ones
+// waiting on a user operation, oldest first
+func (r *LedgerRepository) ListOpenWithdrawals(ctx context.Context, limit int) ([]*model.Withdrawal, error) {
+	query := `
+		SELECT ` + withdrawalColumns + `
+		FROM withdrawals
+		WHERE (status = 'pending' AND speed = 'instant' AND scheduled_at <= NOW())
+			OR (status = 'processing' AND user_op_hash IS NOT NULL)
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+	return r.listWithdrawals(ctx, query, limit)
+}
+
+// ListDueBatchWithdrawals returns batch withdrawals whose window has closed,
+// grouped by chain and token
+func (r *LedgerRepository) ListDueBatchWithdrawals(ctx context.Context, limit int) ([]*model.Withdrawal, error) {
+	query := `
+		SELECT ` + withdrawalColumns + `
+		FROM withdrawals
+		WHERE status = 'pending' AND speed = 'batch' AND scheduled_at <= NOW()
+		ORDER BY chain_id, token_address, created_at
+		LIMIT $1
+	`
+	return r.listWithdrawals(ctx, query, limit)
+}
+
+func (r *LedgerRepository) listWithdrawals(ctx context.Context, query string, args ...interface{}) ([]*model.Withdrawal, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdrawals []*model.Withdrawal
+	for rows.Next() {
+		w, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return withdrawals, nil
+}
+
+// StartWithdrawal moves a pending withdrawal to processing. Returns false if
+// another worker got there first.
+func (r *LedgerRepository) StartWithdrawal(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE withdrawals SET status = 'processing' WHERE id = $1 AND status = 'pending'`
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// StartWithdrawalBatch moves pending withdrawals to processing under one
+// batch and returns the IDs it got; others were taken by another worker
+func (r *LedgerRepository) StartWithdrawalBatch(ctx context.Context, ids []string, batchID string) ([]string, error) {
+	query := `
+		UPDATE withdrawals SET status = 'processing', batch_id = $2
+		WHERE id = ANY($1) AND status = 'pending'
+		RETURNING id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, ids, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var started []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		started = append(started, id)
+	}
+	return started, rows.Err()
+}
+
+// UpdateWithdrawalBatch records the outcome of a batch on all its withdrawals
+func (r *LedgerRepository) UpdateWithdrawalBatch(ctx context.Context, batchID, status, txHash, userOpHash string) error {
+	query := `
+		UPDATE withdrawals
+		SET status = $2, tx_hash = NULLIF($3, ''), user_op_hash = COALESCE(NULLIF($4, ''), user_op_hash),
+			completed_at = CASE WHEN $2 IN ('success', 'failed') THEN NOW() ELSE completed_at END
+		WHERE batch_id = $1 AND status = 'processing'
+	`
+	_, err := r.db.Pool.Exec(ctx, query, batchID, status, txHash, userOpHash)
+	return err
+}
+
+func (r *LedgerRepository) UpdateWithdrawal(ctx context.Context, id, status, txHash, userOpHash string) error {
+	query := `
+		UPDATE withdrawals
+		SET status = $2, tx_hash = NULLIF($3, ''), user_op_hash = COALESCE(NULLIF($4, ''), user_op_hash),
+			completed_at = CASE WHEN $2 IN ('success', 'failed') THEN NOW() ELSE completed_at END
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, txHash, userOpHash)
+	return err
+}
+
+// FailWithdrawal marks a withdrawal failed and refunds its amount to the
+// balance. Wallet withdrawals pass a nil refund: nothing left the wallet.
+func (r *LedgerRepository) FailWithdrawal(ctx context.Context, w *model.Withdrawal, errMsg string, refund *model.LedgerEntry) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE withdrawals SET status = 'failed', error = $2, completed_at = NOW()
+		WHERE id = $1 AND status IN ('awaiting_order', 'pending', 'processing')
+	`
+	tag, err := tx.Exec(ctx, query, w.ID, errMsg)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+	if refund != nil {
+		if err := r.credit(ctx, tx, refund); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// PlaceOffRampOrder records the provider's order on an off-ramp withdrawal
+// waiting for one and releases it to be sent to the order's deposit address.
+// Returns false if the withdrawal was no longer waiting.
+func (r *LedgerRepository) PlaceOffRampOrder(ctx context.Context, w *model.Withdrawal) (bool, error) {
+	query := `
+		UPDATE withdrawals
+		SET status = 'pending', to_address = $2, scheduled_at = NOW(),
+			screening_id = NULLIF($3, ''), screening_result = NULLIF($4, ''),
+			offramp_order_id = $5, offramp_status = $6, fiat_amount = $7
+		WHERE id = $1 AND status = 'awaiting_order'
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		w.ID, w.ToAddress, w.ScreeningID, w.ScreeningResult,
+		w.OffRamp.OrderID, w.OffRamp.Status, w.OffRamp.FiatAmount,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UpdateOffRampOrder records the provider's latest status of an off-ramp order
+func (r *LedgerRepository) UpdateOffRampOrder(ctx context.Context, id string, order *model.OffRampOrder) error {
+	query := `
+		UPDATE withdrawals
+		SET offramp_order_id = COALESCE(NULLIF($2, ''), offramp_order_id), offramp_status = $3,
+			fiat_amount = COALESCE(NULLIF($4::numeric, 0), fiat_amount)
+		WHERE id = $1 AND offramp_provider IS NOT NULL
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, order.OrderID, order.Status, order.FiatAmount)
+	return err
+}
+
+// ListStaleOffRampWithdrawals returns off-ramp withdrawals still waiting for
+// an order that were created before before
+func (r *LedgerRepository) ListStaleOffRampWithdrawals(ctx context.Context, before time.Time, limit int) ([]*model.Withdrawal, error) {
+	query := `
+		SELECT ` + withdrawalColumns + `
+		FROM withdrawals
+		WHERE status = 'awaiting_order' AND created_at < $1
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+	return r.listWithdrawals(ctx, query, before, limit)
+}
+
+// GetTokenSettings returns the withdrawal limits and fees of a token
+func (r *LedgerRepository) GetTokenSettings(ctx context.Context, token string) (*model.WithdrawalTokenSettings, error) {
+	query := `
+		SELECT token, min_amount, batch_fee, instant_enabled, instant_fee, instant_fee_bps
+		FROM withdrawal_token_settings WHERE token = $1
+	`
+	t := &model.WithdrawalTokenSettings{}
+	err := r.db.Pool.QueryRow(ctx, query, token).Scan(
+		&t.Token, &t.MinAmount, &t.BatchFee, &t.InstantEnabled, &t.InstantFee, &t.InstantFeeBps,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
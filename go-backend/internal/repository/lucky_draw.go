@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// ErrShareTaken is returned by ClaimWithPayout when a concurrent claim took
+// the lucky draw share the claim drew
+var ErrShareTaken = errors.New("share taken")
+
+type LuckyDrawRepository struct {
+	db *PostgresDB
+}
+
+func NewLuckyDrawRepository(db *PostgresDB) *LuckyDrawRepository {
+	return &LuckyDrawRepository{db: db}
+}
+
+func insertLuckyDraw(ctx context.Context, db execer, d *model.LuckyDraw) error {
+	query := `
+		INSERT INTO lucky_draws (claim_id, red_pocket_id, remaining_units, remaining_count, random, draw_units, slot, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := db.Exec(ctx, query, d.ClaimID, d.RedPocketID, d.RemainingUnits, d.RemainingCount, d.Random, d.DrawUnits, d.Slot, d.CreatedAt)
+	return err
+}
+
+// insertPocketShares stores the shares a lucky draw pocket was split into,
+// numbered from 0
+func insertPocketShares(ctx context.Context, db execer, rp *model.RedPocket) error {
+	if len(rp.Shares) == 0 {
+		return nil
+	}
+	units := make([]string, len(rp.Shares))
+	for i, u := range rp.Shares {
+		units[i] = u.String()
+	}
+	query := `
+		INSERT INTO pocket_shares (red_pocket_id, slot, units)
+		SELECT $1, t.slot - 1, t.units::numeric
+		FROM unnest($2::text[]) WITH ORDINALITY AS t(units, slot)
+	`
+	_, err := db.Exec(ctx, query, rp.ID, units)
+	return err
+}
+
+// takePocketShare gives a lucky draw share to the claim that drew it, or
+// returns ErrShareTaken if another claim has it
+func takePocketShare(ctx context.Context, db execer, d *model.LuckyDraw) error {
+	query := `
+		UPDATE pocket_shares SET claim_id = $3
+		WHERE red_pocket_id = $1 AND slot = $2 AND claim_id IS NULL
+	`
+	tag, err := db.Exec(ctx, query, d.RedPocketID, *d.Slot, d.ClaimID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrShareTaken
+	}
+	return nil
+}
+
+// ListByPocket returns the draws of a pocket's claims, oldest first, with
+// what each claim came to
+func (r *LuckyDrawRepository) ListByPocket(ctx context.Context, redPocketID string) ([]*model.LuckyDraw, error) {
+	query := `
+		SELECT d.claim_id, d.red_pocket_id, c.platform, c.platform_id, d.remaining_units, d.remaining_count,
+			d.random, d.draw_units, d.slot, c.amount_units, c.multiplier, c.status, d.created_at
+		FROM lucky_draws d
+		JOIN claims c ON c.id = d.claim_id
+		WHERE d.red_pocket_id = $1
+		ORDER BY d.created_at, d.claim_id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, redPocketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var draws []*model.LuckyDraw
+	for rows.Next() {
+		d := &model.LuckyDraw{}
+		err := rows.Scan(
+			&d.ClaimID, &d.RedPocketID, &d.Platform, &d.PlatformID, &d.RemainingUnits, &d.RemainingCount,
+			&d.Random, &d.DrawUnits, &d.Slot, &d.AmountUnits, &d.Multiplier, &d.Status, &d.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		draws = append(draws, d)
+	}
+	return draws, rows.Err()
+}
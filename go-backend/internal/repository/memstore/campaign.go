@@ -0,0 +1,367 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// Campaigns is the store's repository.CampaignStore
+type Campaigns struct {
+	s *Store
+}
+
+func (r *Campaigns) Create(ctx context.Context, c *model.Campaign) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.campaigns[c.ID] = &campaign{Campaign: *c}
+	return nil
+}
+
+// get returns a campaign, or pgx.ErrNoRows if there is none
+func (s *Store) get(id string) (*campaign, error) {
+	camp, ok := s.campaigns[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return camp, nil
+}
+
+func (r *Campaigns) GetByID(ctx context.Context, id string) (*model.Campaign, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	c := camp.Campaign
+	return &c, nil
+}
+
+func (r *Campaigns) ListByEnterprise(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.Campaign, int64, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var campaigns []*model.Campaign
+	for _, camp := range s.campaigns {
+		if camp.EnterpriseID == enterpriseID {
+			c := camp.Campaign
+			campaigns = append(campaigns, &c)
+		}
+	}
+	sort.Slice(campaigns, func(i, j int) bool { return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt) })
+	return page(campaigns, limit, offset), int64(len(campaigns)), nil
+}
+
+// Update saves a campaign's settings, leaving its spent budget alone
+func (r *Campaigns) Update(ctx context.Context, c *model.Campaign) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, ok := s.campaigns[c.ID]
+	if !ok {
+		return nil
+	}
+	camp.Name = c.Name
+	camp.Description = c.Description
+	camp.TotalBudget = c.TotalBudget
+	camp.TotalPockets = c.TotalPockets
+	camp.TotalClaims = c.TotalClaims
+	camp.Tag = c.Tag
+	camp.Status = c.Status
+	camp.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Campaigns) IncrementStats(ctx context.Context, id string, spentAmount float64, claimsCount int) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if camp, ok := s.campaigns[id]; ok {
+		camp.SpentBudget += spentAmount
+		camp.TotalClaims += claimsCount
+		camp.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *Campaigns) IncrementPockets(ctx context.Context, id string) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if camp, ok := s.campaigns[id]; ok {
+		camp.TotalPockets++
+		camp.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *Campaigns) Delete(ctx context.Context, id string) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if camp, ok := s.campaigns[id]; ok {
+		camp.Status = "deleted"
+		camp.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *Campaigns) GetAnalytics(ctx context.Context, enterpriseID string) (*model.CampaignAnalytics, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := &model.CampaignAnalytics{}
+	for _, camp := range s.campaigns {
+		if camp.EnterpriseID != enterpriseID {
+			continue
+		}
+		a.TotalCampaigns++
+		a.TotalBudget += camp.TotalBudget
+		a.TotalSpent += camp.SpentBudget
+		a.TotalClaims += int64(camp.TotalClaims)
+		a.TotalPockets += int64(camp.TotalPockets)
+		if camp.Status == "active" {
+			a.ActiveCampaigns++
+		}
+		a.TotalDonated += s.donated(camp.ID)
+	}
+	return a, nil
+}
+
+// donated sums the donations of a campaign's claims that were not failed,
+// blocked or refunded
+func (s *Store) donated(campaignID string) float64 {
+	var total float64
+	for _, c := range s.claims {
+		if c.DonationUnits.Sign() > 0 && counted(c.Status) && s.inCampaign(c, campaignID) {
+			total += c.Donation
+		}
+	}
+	return total
+}
+
+func (r *Campaigns) GetClaimPage(ctx context.Context, id string) (*model.ClaimPageConfig, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	page := camp.claimPage
+	return &page, nil
+}
+
+func (r *Campaigns) SetClaimPage(ctx context.Context, id string, page *model.ClaimPageConfig) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	camp.claimPage = *page
+	camp.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Campaigns) GetTerms(ctx context.Context, id string) (*model.CampaignTerms, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	t := camp.terms
+	return &t, nil
+}
+
+// SetTerms replaces the terms of a campaign; an empty version removes them
+func (r *Campaigns) SetTerms(ctx context.Context, id string, t *model.CampaignTerms) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	camp.terms = *t
+	if t.Version == "" {
+		camp.terms.Text = ""
+	}
+	camp.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Campaigns) GetVisibility(ctx context.Context, id string) (*model.CampaignVisibility, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	v := camp.visibility
+	return &v, nil
+}
+
+func (r *Campaigns) SetVisibility(ctx context.Context, id string, v *model.CampaignVisibility) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	camp.visibility = *v
+	camp.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetCharity returns the charity round-up of a campaign. TotalDonated is
+// left for TotalDonated to fill in, as the repository does.
+func (r *Campaigns) GetCharity(ctx context.Context, id string) (*model.CampaignCharity, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	c := camp.charity
+	c.TotalDonated = 0
+	return &c, nil
+}
+
+func (r *Campaigns) TotalDonated(ctx context.Context, id string) (float64, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.donated(id), nil
+}
+
+func (r *Campaigns) SetCharity(ctx context.Context, id string, c *model.CampaignCharity) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	camp.charity = *c
+	camp.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Campaigns) GetClaimWeights(ctx context.Context, id string) (*model.CampaignClaimWeights, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	w := camp.weights
+	w.Weights = append([]model.ClaimWeight(nil), camp.weights.Weights...)
+	return &w, nil
+}
+
+func (r *Campaigns) SetClaimWeights(ctx context.Context, id string, w *model.CampaignClaimWeights) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	camp.weights = *w
+	camp.weights.Weights = append([]model.ClaimWeight(nil), w.Weights...)
+	camp.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetFeePlan returns the plan of the enterprise that owns a campaign,
+// standard when SetEnterprise gave it none
+func (r *Campaigns) GetFeePlan(ctx context.Context, id string) (string, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return "", err
+	}
+	if e, ok := s.enterprises[camp.EnterpriseID]; ok && e.plan != "" {
+		return e.plan, nil
+	}
+	return "standard", nil
+}
+
+func (r *Campaigns) EnterpriseTestMode(ctx context.Context, enterpriseID string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.enterprises[enterpriseID]
+	return ok && e.testMode, nil
+}
+
+func (r *Campaigns) TestMode(ctx context.Context, id string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	camp, err := s.get(id)
+	if err != nil {
+		return false, err
+	}
+	return camp.TestMode, nil
+}
+
+// GrantFaucet adds a faucet grant's fake budget to a sandbox campaign of its
+// enterprise unless the enterprise had dailyGrants grants in the last day
+func (r *Campaigns) GrantFaucet(ctx context.Context, g *model.FaucetGrant, dailyGrants int) (*model.Campaign, bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.enterprises[g.EnterpriseID]; !ok || !e.testMode {
+		return nil, false, pgx.ErrNoRows
+	}
+	var granted int
+	since := time.Now().Add(-24 * time.Hour)
+	for _, prev := range s.faucetGrants {
+		if prev.EnterpriseID == g.EnterpriseID && prev.CreatedAt.After(since) {
+			granted++
+		}
+	}
+	if granted >= dailyGrants {
+		return nil, false, nil
+	}
+	camp, ok := s.campaigns[g.CampaignID]
+	if !ok || camp.EnterpriseID != g.EnterpriseID || !camp.TestMode || camp.Status == "deleted" {
+		return nil, false, pgx.ErrNoRows
+	}
+	camp.TotalBudget += g.Amount
+	camp.UpdatedAt = time.Now()
+	grant := *g
+	s.faucetGrants = append(s.faucetGrants, &grant)
+	c := camp.Campaign
+	return &c, true, nil
+}
+
+// GetFeeSchedule returns the platform fees SetFeeSchedule gave a plan
+func (r *Campaigns) GetFeeSchedule(ctx context.Context, plan string) (*model.PlatformFeeSchedule, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.feeSchedules[plan]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	c := *f
+	return &c, nil
+}
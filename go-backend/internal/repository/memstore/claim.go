@@ -0,0 +1,463 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// Claims is the store's repository.ClaimStore
+type Claims struct {
+	s *Store
+}
+
+func (r *Claims) Create(ctx context.Context, c *model.Claim) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.uniqueClaim(c); err != nil {
+		return err
+	}
+	s.insertClaim(c)
+	return nil
+}
+
+// uniqueClaim returns the unique violation Postgres raises for a second
+// claim of a pocket by the same account (uq_claim_user) or a second claim
+// through a one-time link (uq_claims_link)
+func (s *Store) uniqueClaim(c *model.Claim) error {
+	for _, other := range s.claims {
+		if other.RedPocketID == c.RedPocketID && other.PlatformID == c.PlatformID && other.Platform == c.Platform {
+			return &pgconn.PgError{Code: "23505", ConstraintName: "uq_claim_user"}
+		}
+		if c.LinkID != "" && other.LinkID == c.LinkID {
+			return &pgconn.PgError{Code: "23505", ConstraintName: "uq_claims_link"}
+		}
+	}
+	return nil
+}
+
+func (s *Store) insertClaim(c *model.Claim) {
+	stored := copyClaim(c)
+	if stored.Attempts < 1 {
+		stored.Attempts = 1
+	}
+	s.claims[c.ID] = stored
+	s.claimHistory[c.ID] = []*model.ClaimTransition{{To: c.Status, TxHash: c.TxHash, CreatedAt: time.Now()}}
+}
+
+// setClaimStatus moves a claim to the status, recording the change, and
+// settles its amount against its campaign's spent budget as it becomes
+// paid or stops being paid
+func (s *Store) setClaimStatus(c *model.Claim, to model.ClaimStatus, txHash, reason string) {
+	if c.Status != to {
+		s.claimHistory[c.ID] = append(s.claimHistory[c.ID], &model.ClaimTransition{
+			From: c.Status, To: to, TxHash: txHash, Reason: reason, CreatedAt: time.Now(),
+		})
+	}
+	if rp, ok := s.pockets[c.RedPocketID]; ok {
+		if camp, ok := s.campaigns[rp.CampaignID]; ok && paid(c.Status) != paid(to) {
+			if paid(to) {
+				camp.SpentBudget += c.Amount
+			} else {
+				camp.SpentBudget -= c.Amount
+			}
+			camp.UpdatedAt = time.Now()
+		}
+	}
+	c.Status = to
+}
+
+func paid(status model.ClaimStatus) bool {
+	return status == model.ClaimSubmitted || status == model.ClaimConfirmed
+}
+
+// counted reports whether a claim in the status counts towards its
+// pocket's totals: it was not failed, blocked or refunded
+func counted(status model.ClaimStatus) bool {
+	return status != model.ClaimFailed && status != model.ClaimBlocked && status != model.ClaimRefunded
+}
+
+func (r *Claims) GetByID(ctx context.Context, id string) (*model.Claim, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.claims[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return copyClaim(c), nil
+}
+
+func (r *Claims) HasClaimed(ctx context.Context, redPocketID, platformID, platform string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.claims {
+		if c.RedPocketID == redPocketID && c.PlatformID == platformID && c.Platform == platform {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Transition moves a claim to a status if its current status allows it
+func (r *Claims) Transition(ctx context.Context, id string, to model.ClaimStatus, txHash, reason string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.claims[id]
+	if !ok || !c.Status.CanTransition(to) {
+		return false, nil
+	}
+	s.transitionClaim(c, to, txHash, reason)
+	return true, nil
+}
+
+func (s *Store) transitionClaim(c *model.Claim, to model.ClaimStatus, txHash, reason string) {
+	s.setClaimStatus(c, to, txHash, reason)
+	c.TxHash = txHash
+	if to == model.ClaimSubmitted || to == model.ClaimFailed || to == model.ClaimBlocked {
+		now := time.Now()
+		c.CompletedAt = &now
+	}
+}
+
+func (r *Claims) History(ctx context.Context, id string) ([]*model.ClaimTransition, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var history []*model.ClaimTransition
+	for _, t := range s.claimHistory[id] {
+		c := *t
+		history = append(history, &c)
+	}
+	return history, nil
+}
+
+func (r *Claims) SetScreening(ctx context.Context, id, screeningID, result string) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.claims[id]; ok {
+		c.ScreeningID = screeningID
+		c.ScreeningResult = result
+	}
+	return nil
+}
+
+// claimsWhere returns copies of the claims match accepts, newest first or
+// with oldest set oldest first
+func (s *Store) claimsWhere(match func(*model.Claim) bool, oldest bool) []*model.Claim {
+	var claims []*model.Claim
+	for _, c := range s.claims {
+		if match(c) {
+			claims = append(claims, copyClaim(c))
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if oldest {
+			return claims[i].CreatedAt.Before(claims[j].CreatedAt)
+		}
+		return claims[i].CreatedAt.After(claims[j].CreatedAt)
+	})
+	return claims
+}
+
+// inCampaign reports whether a claim is of a pocket of the campaign
+func (s *Store) inCampaign(c *model.Claim, campaignID string) bool {
+	rp, ok := s.pockets[c.RedPocketID]
+	return ok && rp.CampaignID == campaignID
+}
+
+func (r *Claims) ListByRedPocket(ctx context.Context, redPocketID string, limit, offset int) ([]*model.Claim, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claims := s.claimsWhere(func(c *model.Claim) bool { return c.RedPocketID == redPocketID }, false)
+	return page(claims, limit, offset), nil
+}
+
+func (r *Claims) ListByCampaign(ctx context.Context, campaignID string, limit, offset int) ([]*model.Claim, int64, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claims := s.claimsWhere(func(c *model.Claim) bool { return s.inCampaign(c, campaignID) }, false)
+	return page(claims, limit, offset), int64(len(claims)), nil
+}
+
+func (r *Claims) ListByEnterprise(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.Claim, int64, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claims := s.claimsWhere(func(c *model.Claim) bool {
+		rp, ok := s.pockets[c.RedPocketID]
+		if !ok {
+			return false
+		}
+		camp, ok := s.campaigns[rp.CampaignID]
+		return ok && camp.EnterpriseID == enterpriseID
+	}, false)
+	return page(claims, limit, offset), int64(len(claims)), nil
+}
+
+func (r *Claims) ListAllByRedPocket(ctx context.Context, redPocketID string) ([]*model.Claim, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.claimsWhere(func(c *model.Claim) bool { return c.RedPocketID == redPocketID }, true), nil
+}
+
+// MarkResubmitted flags a claim whose payout was replaced. Reports false if
+// the claim cannot be resubmitted.
+func (r *Claims) MarkResubmitted(ctx context.Context, id string, attempts int, reason string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.claims[id]
+	if !ok || !c.Status.CanTransition(model.ClaimResubmitted) {
+		return false, nil
+	}
+	s.setClaimStatus(c, model.ClaimResubmitted, c.TxHash, reason)
+	c.Attempts = attempts
+	return true, nil
+}
+
+// batch returns the claims paid by a settlement batch
+func (s *Store) batch(batchID string) []*model.Claim {
+	var claims []*model.Claim
+	for claimID, j := range s.payoutJobs {
+		if c, ok := s.claims[claimID]; ok && j.BatchID == batchID {
+			claims = append(claims, c)
+		}
+	}
+	return claims
+}
+
+// TransitionBatch moves every claim paid by a settlement batch to a status
+// as Transition does, returning how many were refused
+func (r *Claims) TransitionBatch(ctx context.Context, batchID string, to model.ClaimStatus, txHash, reason string) (int, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var refused int
+	for _, c := range s.batch(batchID) {
+		if !c.Status.CanTransition(to) {
+			refused++
+			continue
+		}
+		s.transitionClaim(c, to, txHash, reason)
+	}
+	return refused, nil
+}
+
+func (r *Claims) IDsByBatch(ctx context.Context, batchID string) ([]string, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for _, c := range s.batch(batchID) {
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
+func (r *Claims) MarkResubmittedByBatch(ctx context.Context, batchID string, attempts int, reason string) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.batch(batchID) {
+		if c.Status.CanTransition(model.ClaimResubmitted) {
+			s.setClaimStatus(c, model.ClaimResubmitted, c.TxHash, reason)
+			c.Attempts = attempts
+		}
+	}
+	return nil
+}
+
+func (r *Claims) LinkUsed(ctx context.Context, linkID string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.claims {
+		if c.LinkID == linkID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Claims) FirstClaimAt(ctx context.Context, platform, platformID string) (*time.Time, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var first *time.Time
+	for _, c := range s.claims {
+		if c.Platform == platform && c.PlatformID == platformID && (first == nil || c.CreatedAt.Before(*first)) {
+			at := c.CreatedAt
+			first = &at
+		}
+	}
+	return first, nil
+}
+
+func (r *Claims) ListByClaimer(ctx context.Context, platform, platformID string, limit, offset int) ([]*model.ClaimerClaim, int64, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claims := s.claimsWhere(func(c *model.Claim) bool {
+		_, ok := s.pockets[c.RedPocketID]
+		return ok && c.Platform == platform && c.PlatformID == platformID
+	}, false)
+
+	var results []*model.ClaimerClaim
+	for _, c := range page(claims, limit, offset) {
+		rp := s.pockets[c.RedPocketID]
+		results = append(results, &model.ClaimerClaim{
+			ID:          c.ID,
+			RedPocketID: c.RedPocketID,
+			SenderName:  rp.SenderName,
+			Message:     rp.Message,
+			ChainID:     rp.ChainID,
+			Token:       rp.Token,
+			IsLuckyDraw: rp.IsLuckyDraw,
+			Amount:      c.Amount,
+			Fee:         c.Fee,
+			Donation:    c.Donation,
+			Status:      c.Status,
+			TxHash:      c.TxHash,
+			CreatedAt:   c.CreatedAt,
+			CompletedAt: c.CompletedAt,
+		})
+	}
+	return results, int64(len(claims)), nil
+}
+
+// ClaimerTotals sums what a platform account has been paid per token
+func (r *Claims) ClaimerTotals(ctx context.Context, platform, platformID string) ([]*model.ClaimerTotal, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type key struct {
+		chainID int64
+		token   string
+	}
+	byToken := map[key]*model.ClaimerTotal{}
+	totals := []*model.ClaimerTotal{}
+	for _, c := range s.claims {
+		rp, ok := s.pockets[c.RedPocketID]
+		if !ok || c.Platform != platform || c.PlatformID != platformID || !paid(c.Status) {
+			continue
+		}
+		t, ok := byToken[key{rp.ChainID, rp.Token}]
+		if !ok {
+			t = &model.ClaimerTotal{ChainID: rp.ChainID, Token: rp.Token}
+			byToken[key{rp.ChainID, rp.Token}] = t
+			totals = append(totals, t)
+		}
+		t.Claims++
+		t.Amount += c.Amount
+		t.Received += c.Amount - c.Fee - c.Donation
+		if c.Amount > t.Largest {
+			t.Largest = c.Amount
+		}
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].ChainID != totals[j].ChainID {
+			return totals[i].ChainID < totals[j].ChainID
+		}
+		return totals[i].Token < totals[j].Token
+	})
+	return totals, nil
+}
+
+// ClaimerChainSummary counts and sums a user's claims per chain and token,
+// split into those still on their way and those confirmed on chain
+func (r *Claims) ClaimerChainSummary(ctx context.Context, claimerID string) ([]*model.ChainClaimSummary, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type key struct {
+		chainID int64
+		token   string
+	}
+	byToken := map[key]*model.ChainClaimSummary{}
+	summary := []*model.ChainClaimSummary{}
+	for _, c := range s.claims {
+		rp, ok := s.pockets[c.RedPocketID]
+		if !ok || c.ClaimerID != claimerID {
+			continue
+		}
+		confirmed := c.Status == model.ClaimConfirmed
+		switch c.Status {
+		case model.ClaimPending, model.ClaimHeld, model.ClaimProcessing, model.ClaimRetrying,
+			model.ClaimSubmitted, model.ClaimResubmitted, model.ClaimConfirmed:
+		default:
+			continue
+		}
+		t, ok := byToken[key{rp.ChainID, rp.Token}]
+		if !ok {
+			t = &model.ChainClaimSummary{ChainID: rp.ChainID, Token: rp.Token}
+			byToken[key{rp.ChainID, rp.Token}] = t
+			summary = append(summary, t)
+		}
+		if confirmed {
+			t.Confirmed++
+			t.ConfirmedAmount += c.Amount
+		} else {
+			t.Pending++
+			t.PendingAmount += c.Amount
+		}
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].ChainID != summary[j].ChainID {
+			return summary[i].ChainID < summary[j].ChainID
+		}
+		return summary[i].Token < summary[j].Token
+	})
+	return summary, nil
+}
+
+// Leaderboard returns a pocket's biggest claims, earliest first on ties,
+// leaving out claims that were failed, blocked or refunded
+func (r *Claims) Leaderboard(ctx context.Context, redPocketID string, limit int) ([]*model.LeaderboardEntry, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claims := s.claimsWhere(func(c *model.Claim) bool { return c.RedPocketID == redPocketID && counted(c.Status) }, true)
+	sort.SliceStable(claims, func(i, j int) bool { return claims[i].Amount > claims[j].Amount })
+
+	entries := []*model.LeaderboardEntry{}
+	for _, c := range page(claims, limit, 0) {
+		entries = append(entries, &model.LeaderboardEntry{
+			Rank:       len(entries) + 1,
+			ClaimID:    c.ID,
+			Platform:   c.Platform,
+			PlatformID: c.PlatformID,
+			Amount:     c.Amount,
+			ClaimedAt:  c.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// ClaimedTotals counts and sums a pocket's claims that were not failed,
+// blocked or refunded
+func (r *Claims) ClaimedTotals(ctx context.Context, redPocketID string) (int, float64, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int
+	var amount float64
+	for _, c := range s.claims {
+		if c.RedPocketID == redPocketID && counted(c.Status) {
+			count++
+			amount += c.Amount
+		}
+	}
+	return count, amount, nil
+}
@@ -0,0 +1,172 @@
+// Package memstore keeps red pockets, claims, wallets and campaigns in
+// memory behind the repository store interfaces, so services can be tested
+// without Postgres. It follows the repositories' rules for claims, channel
+// limits, lucky draw shares, campaign budgets and status changes. Tables
+// only reports join in, such as summaries and the ledger, are not kept.
+package memstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// Store holds the data of every store it hands out. The zero value is not
+// usable; create one with New.
+type Store struct {
+	mu sync.Mutex
+
+	pockets       map[string]*model.RedPocket
+	pocketHistory map[string][]*model.PocketTransition
+	shares        map[string][]*share // by pocket, in slot order
+	channelUsage  map[string]map[string]*model.ChannelUsage
+	deposits      map[string]*model.PocketDeposit
+	reminders     map[reminder]bool
+	locks         map[string]bool
+
+	claims       map[string]*model.Claim
+	claimHistory map[string][]*model.ClaimTransition
+	payoutJobs   map[string]*model.PayoutJob // by claim
+
+	campaigns    map[string]*campaign
+	enterprises  map[string]*enterprise
+	feeSchedules map[string]*model.PlatformFeeSchedule
+	faucetGrants []*model.FaucetGrant
+
+	wallets map[string]*model.Wallet
+}
+
+type share struct {
+	units   model.Units
+	claimID string
+}
+
+type reminder struct {
+	pocketID  string
+	expiresAt time.Time
+	lead      string
+}
+
+// campaign is a campaign with the settings kept in its row
+type campaign struct {
+	model.Campaign
+	claimPage  model.ClaimPageConfig
+	terms      model.CampaignTerms
+	visibility model.CampaignVisibility
+	charity    model.CampaignCharity
+	weights    model.CampaignClaimWeights
+}
+
+type enterprise struct {
+	plan     string
+	testMode bool
+}
+
+func New() *Store {
+	return &Store{
+		pockets:       map[string]*model.RedPocket{},
+		pocketHistory: map[string][]*model.PocketTransition{},
+		shares:        map[string][]*share{},
+		channelUsage:  map[string]map[string]*model.ChannelUsage{},
+		deposits:      map[string]*model.PocketDeposit{},
+		reminders:     map[reminder]bool{},
+		locks:         map[string]bool{},
+		claims:        map[string]*model.Claim{},
+		claimHistory:  map[string][]*model.ClaimTransition{},
+		payoutJobs:    map[string]*model.PayoutJob{},
+		campaigns:     map[string]*campaign{},
+		enterprises:   map[string]*enterprise{},
+		feeSchedules:  map[string]*model.PlatformFeeSchedule{},
+		wallets:       map[string]*model.Wallet{},
+	}
+}
+
+func (s *Store) RedPockets() *RedPockets { return &RedPockets{s} }
+func (s *Store) Claims() *Claims         { return &Claims{s} }
+func (s *Store) Campaigns() *Campaigns   { return &Campaigns{s} }
+func (s *Store) Wallets() *Wallets       { return &Wallets{s} }
+
+var (
+	_ repository.RedPocketStore = (*RedPockets)(nil)
+	_ repository.ClaimStore     = (*Claims)(nil)
+	_ repository.CampaignStore  = (*Campaigns)(nil)
+	_ repository.WalletStore    = (*Wallets)(nil)
+)
+
+// SetEnterprise records an enterprise's plan and whether it is a sandbox
+// tenant, which the enterprises table holds
+func (s *Store) SetEnterprise(id, plan string, testMode bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enterprises[id] = &enterprise{plan: plan, testMode: testMode}
+}
+
+// SetFeeSchedule adds or replaces the platform fees of a plan
+func (s *Store) SetFeeSchedule(f *model.PlatformFeeSchedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *f
+	s.feeSchedules[f.Plan] = &c
+}
+
+// PayoutJob returns the payout job recorded with a claim, nil if there is none
+func (s *Store) PayoutJob(claimID string) *model.PayoutJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.payoutJobs[claimID]
+	if !ok {
+		return nil
+	}
+	c := *j
+	return &c
+}
+
+// SetPayoutBatch assigns a claim's payout job to a settlement batch, as
+// PayoutBatchRepository does
+func (s *Store) SetPayoutBatch(claimID, batchID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.payoutJobs[claimID]; ok {
+		j.BatchID = batchID
+	}
+}
+
+// Deposit returns the deposit recorded with a pocket, nil if there is none
+func (s *Store) Deposit(redPocketID string) *model.PocketDeposit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deposits[redPocketID]
+	if !ok {
+		return nil
+	}
+	c := *d
+	return &c
+}
+
+func copyPocket(rp *model.RedPocket) *model.RedPocket {
+	c := *rp
+	c.ChannelLimits = append([]model.ChannelLimit(nil), rp.ChannelLimits...)
+	c.Shares = append([]model.Units(nil), rp.Shares...)
+	return &c
+}
+
+func copyClaim(c *model.Claim) *model.Claim {
+	cc := *c
+	cc.PayoutSplits = append(cc.PayoutSplits[:0:0], c.PayoutSplits...)
+	cc.Draw = nil
+	return &cc
+}
+
+// page returns the items from offset, at most limit of them
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,574 @@
+package memstore
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// RedPockets is the store's repository.RedPocketStore
+type RedPockets struct {
+	s *Store
+}
+
+// TryClaimLock takes key until release is called; a held key is not
+// acquired again
+func (r *RedPockets) TryClaimLock(ctx context.Context, key string) (func(), bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks[key] {
+		return func() {}, false, nil
+	}
+	s.locks[key] = true
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.locks, key)
+	}, true, nil
+}
+
+func (r *RedPockets) Create(ctx context.Context, rp *model.RedPocket) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insertPocket(rp)
+	return nil
+}
+
+// CreateWithinBudget creates a red pocket if its campaign's budget still
+// covers it and its creation fee, committing budget as the repository does
+func (r *RedPockets) CreateWithinBudget(ctx context.Context, rp *model.RedPocket, deposit *model.PocketDeposit) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	camp, ok := s.campaigns[rp.CampaignID]
+	if !ok {
+		return false, pgx.ErrNoRows
+	}
+	if !s.budgetCovers(&camp.Campaign, new(big.Int).Add(rp.AmountUnits.Int(), rp.CreationFeeUnits.Int()), rp.Decimals) {
+		return false, nil
+	}
+	s.insertPocket(rp)
+	if deposit != nil {
+		d := *deposit
+		s.deposits[rp.ID] = &d
+	} else if rp.CreationFeeUnits.Sign() > 0 {
+		camp.SpentBudget += rp.CreationFee
+		camp.UpdatedAt = time.Now()
+	}
+	return true, nil
+}
+
+// budgetCovers reports whether what a campaign's budget has left to commit
+// covers units of its token at decimals. Budget is committed by what has
+// been spent, payouts in flight and pockets in the campaign's token that can
+// still pay out or are awaiting a deposit whose deadline has not passed.
+func (s *Store) budgetCovers(camp *model.Campaign, units *big.Int, decimals int) bool {
+	scale := new(big.Rat).SetInt(model.Pow10(decimals))
+	left, ok := new(big.Rat).SetString(strconv.FormatFloat(camp.TotalBudget-camp.SpentBudget, 'f', -1, 64))
+	if !ok {
+		return false
+	}
+	left.Mul(left, scale)
+	left.SetInt(new(big.Int).Quo(left.Num(), left.Denom()))
+
+	// commit takes u minor units at from decimals off what is left
+	commit := func(u *big.Int, from int) {
+		left.Sub(left, new(big.Rat).SetFrac(new(big.Int).Mul(u, model.Pow10(decimals)), model.Pow10(from)))
+	}
+	now := time.Now()
+	for _, rp := range s.pockets {
+		if rp.CampaignID != camp.ID || !strings.EqualFold(rp.Token, camp.Token) {
+			continue
+		}
+		switch rp.Status {
+		case model.PocketAwaitingFunding:
+			if d, ok := s.deposits[rp.ID]; ok && (d.Status == "funded" || d.Status == "pending" && d.Deadline.After(now)) {
+				commit(rp.RemainingUnits.Int(), rp.Decimals)
+				commit(rp.CreationFeeUnits.Int(), rp.Decimals)
+			}
+		case model.PocketActive, model.PocketPaused, model.PocketScheduled:
+			commit(rp.RemainingUnits.Int(), rp.Decimals)
+		}
+	}
+	for _, c := range s.claims {
+		rp, ok := s.pockets[c.RedPocketID]
+		if !ok || rp.CampaignID != camp.ID || !strings.EqualFold(rp.Token, camp.Token) {
+			continue
+		}
+		switch c.Status {
+		case model.ClaimSubmitted, model.ClaimConfirmed, model.ClaimFailed, model.ClaimBlocked, model.ClaimRefunded:
+		default:
+			commit(c.AmountUnits.Int(), rp.Decimals)
+		}
+	}
+	return left.Cmp(new(big.Rat).SetInt(units)) >= 0
+}
+
+func (s *Store) insertPocket(rp *model.RedPocket) {
+	s.pockets[rp.ID] = copyPocket(rp)
+	shares := make([]*share, len(rp.Shares))
+	for i, u := range rp.Shares {
+		shares[i] = &share{units: u}
+	}
+	s.shares[rp.ID] = shares
+	s.pocketHistory[rp.ID] = []*model.PocketTransition{{To: rp.Status, CreatedAt: time.Now()}}
+}
+
+// setPocketStatus moves a pocket to the status, recording the change
+func (s *Store) setPocketStatus(rp *model.RedPocket, to model.PocketStatus, reason string) {
+	if rp.Status == to {
+		return
+	}
+	s.pocketHistory[rp.ID] = append(s.pocketHistory[rp.ID], &model.PocketTransition{
+		From: rp.Status, To: to, Reason: reason, CreatedAt: time.Now(),
+	})
+	rp.Status = to
+}
+
+func (r *RedPockets) GetByID(ctx context.Context, id string) (*model.RedPocket, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rp, ok := s.pockets[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return copyPocket(rp), nil
+}
+
+// ClaimAtomic takes a claim of claimUnits from an open pocket, returning
+// pgx.ErrNoRows when it cannot cover it
+func (r *RedPockets) ClaimAtomic(ctx context.Context, id string, claimUnits model.Units) (*model.RedPocket, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rp, err := s.claimable(id, claimUnits)
+	if err != nil {
+		return nil, err
+	}
+	s.takeClaim(rp, claimUnits)
+	return copyPocket(rp), nil
+}
+
+// claimable returns the pocket if it is open and can cover a claim of units
+func (s *Store) claimable(id string, units model.Units) (*model.RedPocket, error) {
+	rp, ok := s.pockets[id]
+	if !ok || rp.Status != model.PocketActive || rp.ClaimedCount >= rp.TotalCount ||
+		rp.RemainingUnits.Int().Cmp(units.Int()) < 0 || !rp.ExpiresAt.After(time.Now()) {
+		return nil, pgx.ErrNoRows
+	}
+	return rp, nil
+}
+
+func (s *Store) takeClaim(rp *model.RedPocket, units model.Units) {
+	rp.ClaimedCount++
+	rp.RemainingUnits = model.NewUnits(new(big.Int).Sub(rp.RemainingUnits.Int(), units.Int()))
+	rp.RemainingAmount = rp.RemainingUnits.Float(rp.Decimals)
+	switch {
+	case rp.ClaimedCount >= rp.TotalCount:
+		s.setPocketStatus(rp, model.PocketDepleted, "last share claimed")
+	case rp.RemainingUnits.Sign() <= 0:
+		s.setPocketStatus(rp, model.PocketDepleted, "remaining amount claimed")
+	}
+}
+
+// ClaimWithPayout is ClaimAtomic that also records the claim, its channel
+// usage, its lucky draw share and its payout job, all or nothing
+func (r *RedPockets) ClaimWithPayout(ctx context.Context, claim *model.Claim, job *model.PayoutJob, limit *model.ChannelLimit) (*model.RedPocket, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rp, err := s.claimable(claim.RedPocketID, claim.AmountUnits)
+	if err != nil {
+		return nil, err
+	}
+	if limit != nil {
+		var count int
+		budget := claim.AmountUnits.Int()
+		if u, ok := s.channelUsage[rp.ID][limit.ChannelID]; ok {
+			count = u.ClaimedCount
+			budget.Add(budget, u.ClaimedUnits.Int())
+		}
+		if (limit.MaxClaims != 0 && count >= limit.MaxClaims) ||
+			(limit.BudgetUnits.Sign() != 0 && budget.Cmp(limit.BudgetUnits.Int()) > 0) {
+			return nil, repository.ErrChannelLimitReached
+		}
+	}
+	var taken *share
+	if d := claim.Draw; d != nil && d.Slot != nil {
+		shares := s.shares[rp.ID]
+		if *d.Slot < 0 || *d.Slot >= len(shares) || shares[*d.Slot].claimID != "" {
+			return nil, repository.ErrShareTaken
+		}
+		taken = shares[*d.Slot]
+	}
+	if err := s.uniqueClaim(claim); err != nil {
+		return nil, err
+	}
+
+	s.takeClaim(rp, claim.AmountUnits)
+	if limit != nil {
+		usage := s.usage(rp.ID, limit.ChannelID)
+		usage.ClaimedCount++
+		usage.ClaimedUnits = model.NewUnits(new(big.Int).Add(usage.ClaimedUnits.Int(), claim.AmountUnits.Int()))
+	}
+	if taken != nil {
+		taken.claimID = claim.ID
+	}
+	s.insertClaim(claim)
+	if job != nil {
+		j := *job
+		s.payoutJobs[claim.ID] = &j
+	}
+	return copyPocket(rp), nil
+}
+
+// usage returns a pocket channel's usage, adding it if there is none
+func (s *Store) usage(id, channelID string) *model.ChannelUsage {
+	byChannel, ok := s.channelUsage[id]
+	if !ok {
+		byChannel = map[string]*model.ChannelUsage{}
+		s.channelUsage[id] = byChannel
+	}
+	u, ok := byChannel[channelID]
+	if !ok {
+		u = &model.ChannelUsage{ChannelLimit: model.ChannelLimit{ChannelID: channelID}, ClaimedUnits: model.NewUnits(new(big.Int))}
+		byChannel[channelID] = u
+	}
+	return u
+}
+
+// ReturnClaim undoes ClaimWithPayout for a claim that will not be paid
+func (r *RedPockets) ReturnClaim(ctx context.Context, claim *model.Claim) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rp, ok := s.pockets[claim.RedPocketID]; ok && rp.ClaimedCount > 0 {
+		rp.ClaimedCount--
+		rp.RemainingUnits = model.NewUnits(new(big.Int).Add(rp.RemainingUnits.Int(), claim.AmountUnits.Int()))
+		rp.RemainingAmount = rp.RemainingUnits.Float(rp.Decimals)
+		if rp.Status == model.PocketDepleted && rp.ExpiresAt.After(time.Now()) {
+			s.setPocketStatus(rp, model.PocketActive, "unpaid claim returned")
+		}
+	}
+	if claim.ChannelID != "" {
+		if u, ok := s.channelUsage[claim.RedPocketID][claim.ChannelID]; ok && u.ClaimedCount > 0 {
+			u.ClaimedCount--
+			u.ClaimedUnits = model.NewUnits(new(big.Int).Sub(u.ClaimedUnits.Int(), claim.AmountUnits.Int()))
+		}
+	}
+	for _, sh := range s.shares[claim.RedPocketID] {
+		if sh.claimID == claim.ID {
+			sh.claimID = ""
+		}
+	}
+	return nil
+}
+
+// NextShare returns the first unclaimed lucky draw share from slot from on,
+// wrapping around to slot 0, or pgx.ErrNoRows when all are claimed
+func (r *RedPockets) NextShare(ctx context.Context, id string, from int) (int, model.Units, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shares := s.shares[id]
+	for i := range shares {
+		slot := (from + i) % len(shares)
+		if from < 0 || from >= len(shares) {
+			slot = i
+		}
+		if shares[slot].claimID == "" {
+			return slot, shares[slot].units, nil
+		}
+	}
+	return 0, model.Units{}, pgx.ErrNoRows
+}
+
+func (r *RedPockets) ChannelUsage(ctx context.Context, id, channelID string) (int, model.Units, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.channelUsage[id][channelID]
+	if !ok {
+		return 0, model.NewUnits(new(big.Int)), nil
+	}
+	return u.ClaimedCount, u.ClaimedUnits, nil
+}
+
+func (r *RedPockets) ListChannelUsage(ctx context.Context, id string) (map[string]*model.ChannelUsage, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage := map[string]*model.ChannelUsage{}
+	for channelID, u := range s.channelUsage[id] {
+		usage[channelID] = &model.ChannelUsage{
+			ChannelLimit: model.ChannelLimit{ChannelID: channelID},
+			ClaimedCount: u.ClaimedCount,
+			ClaimedUnits: u.ClaimedUnits,
+		}
+	}
+	return usage, nil
+}
+
+// Transition moves a red pocket to the status if its current status allows it
+func (r *RedPockets) Transition(ctx context.Context, id string, to model.PocketStatus, reason string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rp, ok := s.pockets[id]
+	if !ok || !rp.Status.CanTransition(to) {
+		return false, nil
+	}
+	s.setPocketStatus(rp, to, reason)
+	return true, nil
+}
+
+func (r *RedPockets) History(ctx context.Context, id string) ([]*model.PocketTransition, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var history []*model.PocketTransition
+	for _, t := range s.pocketHistory[id] {
+		c := *t
+		history = append(history, &c)
+	}
+	return history, nil
+}
+
+// pocketsWhere returns copies of the pockets match accepts, sorted by less
+func (s *Store) pocketsWhere(match func(*model.RedPocket) bool, less func(a, b *model.RedPocket) bool) []*model.RedPocket {
+	var pockets []*model.RedPocket
+	for _, rp := range s.pockets {
+		if match(rp) {
+			pockets = append(pockets, rp)
+		}
+	}
+	sort.Slice(pockets, func(i, j int) bool { return less(pockets[i], pockets[j]) })
+	for i, rp := range pockets {
+		pockets[i] = copyPocket(rp)
+	}
+	return pockets
+}
+
+func newestFirst(a, b *model.RedPocket) bool { return a.CreatedAt.After(b.CreatedAt) }
+
+func soonestStart(a, b *model.RedPocket) bool {
+	if a.StartsAt == nil || b.StartsAt == nil {
+		return b.StartsAt == nil && a.StartsAt != nil
+	}
+	return a.StartsAt.Before(*b.StartsAt)
+}
+
+func (r *RedPockets) ListByCampaign(ctx context.Context, campaignID string, limit, offset int) ([]*model.RedPocket, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pockets := s.pocketsWhere(func(rp *model.RedPocket) bool { return rp.CampaignID == campaignID }, newestFirst)
+	return page(pockets, limit, offset), nil
+}
+
+// ActivateDue opens scheduled pockets whose start time has passed and
+// returns them
+func (r *RedPockets) ActivateDue(ctx context.Context, limit int) ([]*model.RedPocket, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	due := page(s.pocketsWhere(func(rp *model.RedPocket) bool {
+		return rp.Status == model.PocketScheduled && rp.StartsAt != nil && !rp.StartsAt.After(now)
+	}, soonestStart), limit, 0)
+	for i, rp := range due {
+		stored := s.pockets[rp.ID]
+		s.setPocketStatus(stored, model.PocketActive, "start time reached")
+		due[i] = copyPocket(stored)
+	}
+	return due, nil
+}
+
+func (r *RedPockets) ListScheduled(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.RedPocket, int64, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheduled := s.pocketsWhere(func(rp *model.RedPocket) bool {
+		camp, ok := s.campaigns[rp.CampaignID]
+		return rp.Status == model.PocketScheduled && ok && camp.EnterpriseID == enterpriseID
+	}, soonestStart)
+	return page(scheduled, limit, offset), int64(len(scheduled)), nil
+}
+
+func (r *RedPockets) CancelScheduled(ctx context.Context, id string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rp, ok := s.pockets[id]
+	if !ok || rp.Status != model.PocketScheduled {
+		return false, nil
+	}
+	s.setPocketStatus(rp, model.PocketCancelled, "cancelled before opening")
+	return true, nil
+}
+
+// ExpireOld expires open and paused pockets past their expiry, returning
+// their IDs
+func (r *RedPockets) ExpireOld(ctx context.Context) ([]string, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var ids []string
+	for _, rp := range s.pockets {
+		if rp.Status != model.PocketExpired && rp.Status.CanTransition(model.PocketExpired) && rp.ExpiresAt.Before(now) {
+			s.setPocketStatus(rp, model.PocketExpired, "expiry reached")
+			ids = append(ids, rp.ID)
+		}
+	}
+	return ids, nil
+}
+
+// Extend moves an open or paused red pocket's expiry later
+func (r *RedPockets) Extend(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rp, ok := s.pockets[id]
+	if !ok || (rp.Status != model.PocketActive && rp.Status != model.PocketPaused) ||
+		!rp.ExpiresAt.After(time.Now()) || !rp.ExpiresAt.Before(expiresAt) {
+		return false, nil
+	}
+	rp.ExpiresAt = expiresAt
+	return true, nil
+}
+
+// Resize changes the amount and count of a red pocket, as loaded in rp, if
+// it can still be resized, returning repository.ErrBudgetNotCovered when it
+// grows by more than its campaign's budget covers. A funded deposit's
+// required amount follows, once moveDeposit has moved the difference. Draws
+// are not kept, so a pre-split lucky draw counts as undrawn while none of
+// its shares is claimed.
+func (r *RedPockets) Resize(ctx context.Context, rp *model.RedPocket, amountUnits model.Units, totalCount int, moveDeposit func(diff *big.Int) error) (*model.RedPocket, bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	camp, ok := s.campaigns[rp.CampaignID]
+	if !ok {
+		return nil, false, pgx.ErrNoRows
+	}
+	added := new(big.Int).Sub(amountUnits.Int(), rp.AmountUnits.Int())
+	if added.Sign() > 0 && !s.budgetCovers(&camp.Campaign, added, rp.Decimals) {
+		return nil, false, repository.ErrBudgetNotCovered
+	}
+	stored, ok := s.pockets[rp.ID]
+	if !ok || stored.AmountUnits.Int().Cmp(rp.AmountUnits.Int()) != 0 || stored.TotalCount != rp.TotalCount ||
+		!stored.ExpiresAt.After(time.Now()) || totalCount <= stored.ClaimedCount {
+		return nil, false, nil
+	}
+	switch stored.Status {
+	case model.PocketActive, model.PocketPaused, model.PocketScheduled:
+	default:
+		return nil, false, nil
+	}
+	remaining := new(big.Int).Add(stored.RemainingUnits.Int(), added)
+	if remaining.Cmp(big.NewInt(int64(totalCount-stored.ClaimedCount))) < 0 {
+		return nil, false, nil
+	}
+	if rp.Shares != nil {
+		if stored.ClaimedCount > 0 {
+			return nil, false, nil
+		}
+		for _, sh := range s.shares[rp.ID] {
+			if sh.claimID != "" {
+				return nil, false, nil
+			}
+		}
+	}
+	if d, ok := s.deposits[rp.ID]; ok && d.Status == "funded" && added.Sign() != 0 {
+		if err := moveDeposit(added); err != nil {
+			return nil, false, err
+		}
+		d.RequiredUnits = model.NewUnits(new(big.Int).Add(d.RequiredUnits.Int(), added))
+	}
+	if rp.Shares != nil {
+		shares := make([]*share, len(rp.Shares))
+		for i, u := range rp.Shares {
+			shares[i] = &share{units: u}
+		}
+		s.shares[rp.ID] = shares
+	}
+
+	stored.AmountUnits = amountUnits
+	stored.Amount = amountUnits.Float(stored.Decimals)
+	stored.RemainingUnits = model.NewUnits(remaining)
+	stored.RemainingAmount = stored.RemainingUnits.Float(stored.Decimals)
+	stored.TotalCount = totalCount
+	return copyPocket(stored), true, nil
+}
+
+// ExpiringWithin returns the open red pockets due lead's expiry reminder,
+// soonest first
+func (r *RedPockets) ExpiringWithin(ctx context.Context, lead string, after, within time.Duration, minRemaining, limit int) ([]*model.RedPocket, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	due := s.pocketsWhere(func(rp *model.RedPocket) bool {
+		opened := rp.CreatedAt
+		if rp.StartsAt != nil {
+			opened = *rp.StartsAt
+		}
+		remaining := new(big.Int).Mul(rp.RemainingUnits.Int(), big.NewInt(100))
+		required := new(big.Int).Mul(rp.AmountUnits.Int(), big.NewInt(int64(minRemaining)))
+		return rp.Status == model.PocketActive &&
+			rp.ExpiresAt.After(now.Add(after)) && !rp.ExpiresAt.After(now.Add(within)) &&
+			!opened.After(rp.ExpiresAt.Add(-within)) &&
+			remaining.Cmp(required) >= 0 &&
+			!s.reminders[reminder{rp.ID, rp.ExpiresAt, lead}]
+	}, func(a, b *model.RedPocket) bool { return a.ExpiresAt.Before(b.ExpiresAt) })
+	return page(due, limit, 0), nil
+}
+
+// MarkReminded records that a red pocket's reminder for lead before
+// expiresAt is being sent, reporting false when it already was
+func (r *RedPockets) MarkReminded(ctx context.Context, id string, expiresAt time.Time, lead string) (bool, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := reminder{id, expiresAt, lead}
+	if s.reminders[key] {
+		return false, nil
+	}
+	s.reminders[key] = true
+	return true, nil
+}
+
+// ListUnarchived returns IDs of finished red pockets, oldest first. No
+// summaries are kept, so each call lists every finished pocket.
+func (r *RedPockets) ListUnarchived(ctx context.Context, limit int) ([]string, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finished := s.pocketsWhere(func(rp *model.RedPocket) bool {
+		switch rp.Status {
+		case model.PocketDepleted, model.PocketExpired, model.PocketCancelled, model.PocketRefunded:
+			return true
+		}
+		return false
+	}, func(a, b *model.RedPocket) bool { return a.CreatedAt.Before(b.CreatedAt) })
+	var ids []string
+	for _, rp := range page(finished, limit, 0) {
+		ids = append(ids, rp.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,243 @@
+package memstore_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"github.com/protocolbank/redpocket-backend/internal/repository/memstore"
+)
+
+// newPocket creates an open pocket of count shares of shareUnits each, or of
+// the given pre-split shares for a lucky draw
+func newPocket(t *testing.T, s *memstore.Store, count int, shareUnits int64, shares ...int64) *model.RedPocket {
+	t.Helper()
+	total := big.NewInt(shareUnits * int64(count))
+	rp := &model.RedPocket{
+		ID:         "rp_test",
+		CampaignID: "campaign_test",
+		TotalCount: count,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Status:     model.PocketActive,
+		Decimals:   6,
+	}
+	if len(shares) > 0 {
+		rp.IsLuckyDraw = true
+		total = new(big.Int)
+		for _, u := range shares {
+			rp.Shares = append(rp.Shares, model.NewUnits(big.NewInt(u)))
+			total.Add(total, big.NewInt(u))
+		}
+	}
+	rp.AmountUnits = model.NewUnits(total)
+	rp.RemainingUnits = model.NewUnits(total)
+	if err := s.RedPockets().Create(context.Background(), rp); err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+func newClaim(rp *model.RedPocket, n int, platformID string, units *big.Int) *model.Claim {
+	return &model.Claim{
+		ID:          fmt.Sprintf("claim_%d", n),
+		RedPocketID: rp.ID,
+		ClaimerID:   "user_telegram_" + platformID,
+		PlatformID:  platformID,
+		Platform:    "telegram",
+		AmountUnits: model.NewUnits(units),
+		Status:      model.ClaimPending,
+		CreatedAt:   time.Now(),
+	}
+}
+
+func uniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Many more claimers than shares race for a pocket: exactly its shares are
+// taken, each with its payout job, and the rest find it depleted
+func TestClaimWithPayoutConcurrentClaimers(t *testing.T) {
+	s := memstore.New()
+	rp := newPocket(t, s, 5, 1_000_000)
+
+	const claimers = 50
+	var wg sync.WaitGroup
+	errs := make([]error, claimers)
+	for i := 0; i < claimers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			claim := newClaim(rp, i, fmt.Sprint(1000+i), big.NewInt(1_000_000))
+			job := &model.PayoutJob{ID: fmt.Sprintf("payout_%d", i), ClaimID: claim.ID, Status: "queued"}
+			_, errs[i] = s.RedPockets().ClaimWithPayout(context.Background(), claim, job, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	won := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			won++
+			if s.PayoutJob(fmt.Sprintf("claim_%d", i)) == nil {
+				t.Errorf("claim %d has no payout job", i)
+			}
+		case !errors.Is(err, pgx.ErrNoRows):
+			t.Errorf("claim %d: %v", i, err)
+		}
+	}
+	if won != rp.TotalCount {
+		t.Fatalf("%d claims succeeded, want %d", won, rp.TotalCount)
+	}
+
+	got, err := s.RedPockets().GetByID(context.Background(), rp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ClaimedCount != rp.TotalCount || got.RemainingUnits.Sign() != 0 || got.Status != model.PocketDepleted {
+		t.Fatalf("pocket claimed %d, %s left, %s; want %d, 0, depleted",
+			got.ClaimedCount, got.RemainingUnits, got.Status, rp.TotalCount)
+	}
+	claims, err := s.Claims().ListAllByRedPocket(context.Background(), rp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims) != rp.TotalCount {
+		t.Fatalf("%d claims recorded, want %d", len(claims), rp.TotalCount)
+	}
+}
+
+// One account claiming many times at once gets a single share
+func TestClaimWithPayoutNoDoubleClaim(t *testing.T) {
+	s := memstore.New()
+	rp := newPocket(t, s, 5, 1_000_000)
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	errs := make([]error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			claim := newClaim(rp, i, "1001", big.NewInt(1_000_000))
+			_, errs[i] = s.RedPockets().ClaimWithPayout(context.Background(), claim, nil, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	won := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !uniqueViolation(err):
+			t.Errorf("claim %d: %v", i, err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("%d claims succeeded, want 1", won)
+	}
+
+	got, err := s.RedPockets().GetByID(context.Background(), rp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ClaimedCount != 1 || got.RemainingUnits.Int().Cmp(big.NewInt(4_000_000)) != 0 {
+		t.Fatalf("pocket claimed %d with %s left, want 1 with 4000000", got.ClaimedCount, got.RemainingUnits)
+	}
+	claimed, err := s.Claims().HasClaimed(context.Background(), rp.ID, "1001", "telegram")
+	if err != nil || !claimed {
+		t.Fatalf("HasClaimed = %t, %v; want true", claimed, err)
+	}
+}
+
+// Claimers racing for the pre-split shares of a lucky draw each take a
+// different one, drawing again when theirs is taken first, until every
+// share is gone and the pocket's amount is paid out exactly
+func TestClaimWithPayoutLuckyDrawShares(t *testing.T) {
+	s := memstore.New()
+	shares := []int64{1_230_000, 3_400_000, 50_000, 2_000_000, 3_320_000}
+	rp := newPocket(t, s, len(shares), 0, shares...)
+	ctx := context.Background()
+
+	const claimers = 20
+	var wg sync.WaitGroup
+	taken := make([]*model.LuckyDraw, claimers)
+	errs := make([]error, claimers)
+	for i := 0; i < claimers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				slot, units, err := s.RedPockets().NextShare(ctx, rp.ID, i%len(shares))
+				if errors.Is(err, pgx.ErrNoRows) {
+					return
+				}
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				claim := newClaim(rp, i, fmt.Sprint(1000+i), units.Int())
+				claim.Draw = &model.LuckyDraw{ClaimID: claim.ID, Slot: &slot, DrawUnits: units, AmountUnits: units}
+				_, err = s.RedPockets().ClaimWithPayout(ctx, claim, nil, nil)
+				switch {
+				case errors.Is(err, repository.ErrShareTaken):
+					continue
+				case errors.Is(err, pgx.ErrNoRows):
+					return
+				case err != nil:
+					errs[i] = err
+				default:
+					taken[i] = claim.Draw
+				}
+				return
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	slots := map[int]bool{}
+	paid := new(big.Int)
+	for i, d := range taken {
+		if errs[i] != nil {
+			t.Errorf("claimer %d: %v", i, errs[i])
+		}
+		if d == nil {
+			continue
+		}
+		if slots[*d.Slot] {
+			t.Errorf("slot %d taken twice", *d.Slot)
+		}
+		slots[*d.Slot] = true
+		if d.DrawUnits.Int().Cmp(big.NewInt(shares[*d.Slot])) != 0 {
+			t.Errorf("slot %d paid %s, want %d", *d.Slot, d.DrawUnits, shares[*d.Slot])
+		}
+		paid.Add(paid, d.AmountUnits.Int())
+	}
+	if len(slots) != len(shares) {
+		t.Fatalf("%d shares taken, want %d", len(slots), len(shares))
+	}
+	if paid.Cmp(rp.AmountUnits.Int()) != 0 {
+		t.Fatalf("claims paid %s, want the pocket's %s", paid, rp.AmountUnits)
+	}
+	if _, _, err := s.RedPockets().NextShare(ctx, rp.ID, 0); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("NextShare after every share was taken: %v, want no rows", err)
+	}
+
+	got, err := s.RedPockets().GetByID(ctx, rp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RemainingUnits.Sign() != 0 || got.Status != model.PocketDepleted {
+		t.Fatalf("pocket has %s left and is %s, want 0 and depleted", got.RemainingUnits, got.Status)
+	}
+}
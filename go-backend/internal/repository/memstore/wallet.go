@@ -0,0 +1,78 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// Wallets is the store's repository.WalletStore. Private keys are kept as
+// given, with no encryptor to seal them.
+type Wallets struct {
+	s *Store
+}
+
+func (r *Wallets) Create(ctx context.Context, w *model.Wallet) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *w
+	if stored.EntryPointVersion == "" {
+		stored.EntryPointVersion = "0.6"
+	}
+	s.wallets[w.ID] = &stored
+	return nil
+}
+
+// walletWhere returns a copy of the first wallet match accepts, or
+// pgx.ErrNoRows if there is none
+func (s *Store) walletWhere(match func(*model.Wallet) bool) (*model.Wallet, error) {
+	for _, w := range s.wallets {
+		if match(w) {
+			c := *w
+			return &c, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *Wallets) GetByUserID(ctx context.Context, userID string, chainID int64) (*model.Wallet, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.walletWhere(func(w *model.Wallet) bool { return w.UserID == userID && w.ChainID == chainID })
+}
+
+func (r *Wallets) GetByAddress(ctx context.Context, address string) (*model.Wallet, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.walletWhere(func(w *model.Wallet) bool { return w.Address == address })
+}
+
+func (r *Wallets) UpdateDeployed(ctx context.Context, id string, deployed bool) error {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w, ok := s.wallets[id]; ok {
+		w.IsDeployed = deployed
+	}
+	return nil
+}
+
+func (r *Wallets) ListByUser(ctx context.Context, userID string) ([]*model.Wallet, error) {
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var wallets []*model.Wallet
+	for _, w := range s.wallets {
+		if w.UserID == userID {
+			c := *w
+			wallets = append(wallets, &c)
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].CreatedAt.After(wallets[j].CreatedAt) })
+	return wallets, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/protocolbank/redpocket-backend/migrations"
+)
+
+// ErrUntrackedSchema is returned by Migrator.Up for a database whose schema
+// was applied by hand, before versions were recorded. It has to be told
+// which migration it is at with Force first.
+var ErrUntrackedSchema = errors.New("database has tables but no migration version; record the last migration applied with `server migrate force <version>`")
+
+// Migrator applies the embedded migrations and records the version reached
+// in schema_migrations. A migration that fails part way leaves the version
+// dirty, and nothing more is applied until it is fixed by hand and forced.
+// Migrators on several replicas take turns on a Postgres advisory lock.
+type Migrator struct {
+	db     *sql.DB
+	source source.Driver
+	m      *migrate.Migrate
+}
+
+func NewMigrator(databaseURL string) (*Migrator, error) {
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "pgx5", driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, err
+	}
+	return &Migrator{db: db, source: src, m: m}, nil
+}
+
+// Up applies every migration after the recorded version
+func (m *Migrator) Up() error {
+	version, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		var exists bool
+		if err := m.db.QueryRow(`SELECT to_regclass('red_pockets') IS NOT NULL`).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return ErrUntrackedSchema
+		}
+	}
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Version returns the last migration applied, 0 if none, and whether it
+// failed part way
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Latest returns the version of the last embedded migration
+func (m *Migrator) Latest() (uint, error) {
+	version, err := m.source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := m.source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// Force records version as applied, and not dirty, without running
+// anything: for a database migrated by hand, or once a failed migration has
+// been finished or undone by hand
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
@@ -0,0 +1,4055 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Ensure, that RedPocketStoreMock does implement repository.RedPocketStore.
+// If this is not the case, regenerate this file with moq.
+var _ repository.RedPocketStore = &RedPocketStoreMock{}
+
+// RedPocketStoreMock is a mock implementation of repository.RedPocketStore.
+//
+//	func TestSomethingThatUsesRedPocketStore(t *testing.T) {
+//
+//		// make and configure a mocked repository.RedPocketStore
+//		mockedRedPocketStore := &RedPocketStoreMock{
+//			ActivateDueFunc: func(ctx context.Context, limit int) ([]*model.RedPocket, error) {
+//				panic("mock out the ActivateDue method")
+//			},
+//			CancelScheduledFunc: func(ctx context.Context, id string) (bool, error) {
+//				panic("mock out the CancelScheduled method")
+//			},
+//			ChannelUsageFunc: func(ctx context.Context, id string, channelID string) (int, model.Units, error) {
+//				panic("mock out the ChannelUsage method")
+//			},
+//			ClaimAtomicFunc: func(ctx context.Context, id string, claimUnits model.Units) (*model.RedPocket, error) {
+//				panic("mock out the ClaimAtomic method")
+//			},
+//			ClaimWithPayoutFunc: func(ctx context.Context, claim *model.Claim, job *model.PayoutJob, limit *model.ChannelLimit) (*model.RedPocket, error) {
+//				panic("mock out the ClaimWithPayout method")
+//			},
+//			CreateFunc: func(ctx context.Context, rp *model.RedPocket) error {
+//				panic("mock out the Create method")
+//			},
+//			CreateWithinBudgetFunc: func(ctx context.Context, rp *model.RedPocket, deposit *model.PocketDeposit) (bool, error) {
+//				panic("mock out the CreateWithinBudget method")
+//			},
+//			ExpireOldFunc: func(ctx context.Context) ([]string, error) {
+//				panic("mock out the ExpireOld method")
+//			},
+//			ExpiringWithinFunc: func(ctx context.Context, lead string, after time.Duration, within time.Duration, minRemaining int, limit int) ([]*model.RedPocket, error) {
+//				panic("mock out the ExpiringWithin method")
+//			},
+//			ExtendFunc: func(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+//				panic("mock out the Extend method")
+//			},
+//			GetByIDFunc: func(ctx context.Context, id string) (*model.RedPocket, error) {
+//				panic("mock out the GetByID method")
+//			},
+//			HistoryFunc: func(ctx context.Context, id string) ([]*model.PocketTransition, error) {
+//				panic("mock out the History method")
+//			},
+//			ListByCampaignFunc: func(ctx context.Context, campaignID string, limit int, offset int) ([]*model.RedPocket, error) {
+//				panic("mock out the ListByCampaign method")
+//			},
+//			ListChannelUsageFunc: func(ctx context.Context, id string) (map[string]*model.ChannelUsage, error) {
+//				panic("mock out the ListChannelUsage method")
+//			},
+//			ListScheduledFunc: func(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.RedPocket, int64, error) {
+//				panic("mock out the ListScheduled method")
+//			},
+//			ListUnarchivedFunc: func(ctx context.Context, limit int) ([]string, error) {
+//				panic("mock out the ListUnarchived method")
+//			},
+//			MarkRemindedFunc: func(ctx context.Context, id string, expiresAt time.Time, lead string) (bool, error) {
+//				panic("mock out the MarkReminded method")
+//			},
+//			NextShareFunc: func(ctx context.Context, id string, from int) (int, model.Units, error) {
+//				panic("mock out the NextShare method")
+//			},
+//			ResizeFunc: func(ctx context.Context, rp *model.RedPocket, amountUnits model.Units, totalCount int, moveDeposit func(diff *big.Int) error) (*model.RedPocket, bool, error) {
+//				panic("mock out the Resize method")
+//			},
+//			ReturnClaimFunc: func(ctx context.Context, claim *model.Claim) error {
+//				panic("mock out the ReturnClaim method")
+//			},
+//			TransitionFunc: func(ctx context.Context, id string, to model.PocketStatus, reason string) (bool, error) {
+//				panic("mock out the Transition method")
+//			},
+//			TryClaimLockFunc: func(ctx context.Context, key string) (func(), bool, error) {
+//				panic("mock out the TryClaimLock method")
+//			},
+//		}
+//
+//		// use mockedRedPocketStore in code that requires repository.RedPocketStore
+//		// and then make assertions.
+//
+//	}
+type RedPocketStoreMock struct {
+	// ActivateDueFunc mocks the ActivateDue method.
+	ActivateDueFunc func(ctx context.Context, limit int) ([]*model.RedPocket, error)
+
+	// CancelScheduledFunc mocks the CancelScheduled method.
+	CancelScheduledFunc func(ctx context.Context, id string) (bool, error)
+
+	// ChannelUsageFunc mocks the ChannelUsage method.
+	ChannelUsageFunc func(ctx context.Context, id string, channelID string) (int, model.Units, error)
+
+	// ClaimAtomicFunc mocks the ClaimAtomic method.
+	ClaimAtomicFunc func(ctx context.Context, id string, claimUnits model.Units) (*model.RedPocket, error)
+
+	// ClaimWithPayoutFunc mocks the ClaimWithPayout method.
+	ClaimWithPayoutFunc func(ctx context.Context, claim *model.Claim, job *model.PayoutJob, limit *model.ChannelLimit) (*model.RedPocket, error)
+
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, rp *model.RedPocket) error
+
+	// CreateWithinBudgetFunc mocks the CreateWithinBudget method.
+	CreateWithinBudgetFunc func(ctx context.Context, rp *model.RedPocket, deposit *model.PocketDeposit) (bool, error)
+
+	// ExpireOldFunc mocks the ExpireOld method.
+	ExpireOldFunc func(ctx context.Context) ([]string, error)
+
+	// ExpiringWithinFunc mocks the ExpiringWithin method.
+	ExpiringWithinFunc func(ctx context.Context, lead string, after time.Duration, within time.Duration, minRemaining int, limit int) ([]*model.RedPocket, error)
+
+	// ExtendFunc mocks the Extend method.
+	ExtendFunc func(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+
+	// GetByIDFunc mocks the GetByID method.
+	GetByIDFunc func(ctx context.Context, id string) (*model.RedPocket, error)
+
+	// HistoryFunc mocks the History method.
+	HistoryFunc func(ctx context.Context, id string) ([]*model.PocketTransition, error)
+
+	// ListByCampaignFunc mocks the ListByCampaign method.
+	ListByCampaignFunc func(ctx context.Context, campaignID string, limit int, offset int) ([]*model.RedPocket, error)
+
+	// ListChannelUsageFunc mocks the ListChannelUsage method.
+	ListChannelUsageFunc func(ctx context.Context, id string) (map[string]*model.ChannelUsage, error)
+
+	// ListScheduledFunc mocks the ListScheduled method.
+	ListScheduledFunc func(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.RedPocket, int64, error)
+
+	// ListUnarchivedFunc mocks the ListUnarchived method.
+	ListUnarchivedFunc func(ctx context.Context, limit int) ([]string, error)
+
+	// MarkRemindedFunc mocks the MarkReminded method.
+	MarkRemindedFunc func(ctx context.Context, id string, expiresAt time.Time, lead string) (bool, error)
+
+	// NextShareFunc mocks the NextShare method.
+	NextShareFunc func(ctx context.Context, id string, from int) (int, model.Units, error)
+
+	// ResizeFunc mocks the Resize method.
+	ResizeFunc func(ctx context.Context, rp *model.RedPocket, amountUnits model.Units, totalCount int, moveDeposit func(diff *big.Int) error) (*model.RedPocket, bool, error)
+
+	// ReturnClaimFunc mocks the ReturnClaim method.
+	ReturnClaimFunc func(ctx context.Context, claim *model.Claim) error
+
+	// TransitionFunc mocks the Transition method.
+	TransitionFunc func(ctx context.Context, id string, to model.PocketStatus, reason string) (bool, error)
+
+	// TryClaimLockFunc mocks the TryClaimLock method.
+	TryClaimLockFunc func(ctx context.Context, key string) (func(), bool, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ActivateDue holds details about calls to the ActivateDue method.
+		ActivateDue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// CancelScheduled holds details about calls to the CancelScheduled method.
+		CancelScheduled []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// ChannelUsage holds details about calls to the ChannelUsage method.
+		ChannelUsage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// ChannelID is the channelID argument value.
+			ChannelID string
+		}
+		// ClaimAtomic holds details about calls to the ClaimAtomic method.
+		ClaimAtomic []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// ClaimUnits is the claimUnits argument value.
+			ClaimUnits model.Units
+		}
+		// ClaimWithPayout holds details about calls to the ClaimWithPayout method.
+		ClaimWithPayout []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Claim is the claim argument value.
+			Claim *model.Claim
+			// Job is the job argument value.
+			Job *model.PayoutJob
+			// Limit is the limit argument value.
+			Limit *model.ChannelLimit
+		}
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Rp is the rp argument value.
+			Rp *model.RedPocket
+		}
+		// CreateWithinBudget holds details about calls to the CreateWithinBudget method.
+		CreateWithinBudget []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Rp is the rp argument value.
+			Rp *model.RedPocket
+			// Deposit is the deposit argument value.
+			Deposit *model.PocketDeposit
+		}
+		// ExpireOld holds details about calls to the ExpireOld method.
+		ExpireOld []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ExpiringWithin holds details about calls to the ExpiringWithin method.
+		ExpiringWithin []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Lead is the lead argument value.
+			Lead string
+			// After is the after argument value.
+			After time.Duration
+			// Within is the within argument value.
+			Within time.Duration
+			// MinRemaining is the minRemaining argument value.
+			MinRemaining int
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// Extend holds details about calls to the Extend method.
+		Extend []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// ExpiresAt is the expiresAt argument value.
+			ExpiresAt time.Time
+		}
+		// GetByID holds details about calls to the GetByID method.
+		GetByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// History holds details about calls to the History method.
+		History []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// ListByCampaign holds details about calls to the ListByCampaign method.
+		ListByCampaign []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// CampaignID is the campaignID argument value.
+			CampaignID string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// ListChannelUsage holds details about calls to the ListChannelUsage method.
+		ListChannelUsage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// ListScheduled holds details about calls to the ListScheduled method.
+		ListScheduled []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EnterpriseID is the enterpriseID argument value.
+			EnterpriseID string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// ListUnarchived holds details about calls to the ListUnarchived method.
+		ListUnarchived []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// MarkReminded holds details about calls to the MarkReminded method.
+		MarkReminded []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// ExpiresAt is the expiresAt argument value.
+			ExpiresAt time.Time
+			// Lead is the lead argument value.
+			Lead string
+		}
+		// NextShare holds details about calls to the NextShare method.
+		NextShare []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// From is the from argument value.
+			From int
+		}
+		// Resize holds details about calls to the Resize method.
+		Resize []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Rp is the rp argument value.
+			Rp *model.RedPocket
+			// AmountUnits is the amountUnits argument value.
+			AmountUnits model.Units
+			// TotalCount is the totalCount argument value.
+			TotalCount int
+			// MoveDeposit is the moveDeposit argument value.
+			MoveDeposit func(diff *big.Int) error
+		}
+		// ReturnClaim holds details about calls to the ReturnClaim method.
+		ReturnClaim []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Claim is the claim argument value.
+			Claim *model.Claim
+		}
+		// Transition holds details about calls to the Transition method.
+		Transition []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// To is the to argument value.
+			To model.PocketStatus
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// TryClaimLock holds details about calls to the TryClaimLock method.
+		TryClaimLock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+	}
+	lockActivateDue        sync.RWMutex
+	lockCancelScheduled    sync.RWMutex
+	lockChannelUsage       sync.RWMutex
+	lockClaimAtomic        sync.RWMutex
+	lockClaimWithPayout    sync.RWMutex
+	lockCreate             sync.RWMutex
+	lockCreateWithinBudget sync.RWMutex
+	lockExpireOld          sync.RWMutex
+	lockExpiringWithin     sync.RWMutex
+	lockExtend             sync.RWMutex
+	lockGetByID            sync.RWMutex
+	lockHistory            sync.RWMutex
+	lockListByCampaign     sync.RWMutex
+	lockListChannelUsage   sync.RWMutex
+	lockListScheduled      sync.RWMutex
+	lockListUnarchived     sync.RWMutex
+	lockMarkReminded       sync.RWMutex
+	lockNextShare          sync.RWMutex
+	lockResize             sync.RWMutex
+	lockReturnClaim        sync.RWMutex
+	lockTransition         sync.RWMutex
+	lockTryClaimLock       sync.RWMutex
+}
+
+// ActivateDue calls ActivateDueFunc.
+func (mock *RedPocketStoreMock) ActivateDue(ctx context.Context, limit int) ([]*model.RedPocket, error) {
+	if mock.ActivateDueFunc == nil {
+		panic("RedPocketStoreMock.ActivateDueFunc: method is nil but RedPocketStore.ActivateDue was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Limit int
+	}{
+		Ctx:   ctx,
+		Limit: limit,
+	}
+	mock.lockActivateDue.Lock()
+	mock.calls.ActivateDue = append(mock.calls.ActivateDue, callInfo)
+	mock.lockActivateDue.Unlock()
+	return mock.ActivateDueFunc(ctx, limit)
+}
+
+// ActivateDueCalls gets all the calls that were made to ActivateDue.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ActivateDueCalls())
+func (mock *RedPocketStoreMock) ActivateDueCalls() []struct {
+	Ctx   context.Context
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Limit int
+	}
+	mock.lockActivateDue.RLock()
+	calls = mock.calls.ActivateDue
+	mock.lockActivateDue.RUnlock()
+	return calls
+}
+
+// CancelScheduled calls CancelScheduledFunc.
+func (mock *RedPocketStoreMock) CancelScheduled(ctx context.Context, id string) (bool, error) {
+	if mock.CancelScheduledFunc == nil {
+		panic("RedPocketStoreMock.CancelScheduledFunc: method is nil but RedPocketStore.CancelScheduled was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockCancelScheduled.Lock()
+	mock.calls.CancelScheduled = append(mock.calls.CancelScheduled, callInfo)
+	mock.lockCancelScheduled.Unlock()
+	return mock.CancelScheduledFunc(ctx, id)
+}
+
+// CancelScheduledCalls gets all the calls that were made to CancelScheduled.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.CancelScheduledCalls())
+func (mock *RedPocketStoreMock) CancelScheduledCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockCancelScheduled.RLock()
+	calls = mock.calls.CancelScheduled
+	mock.lockCancelScheduled.RUnlock()
+	return calls
+}
+
+// ChannelUsage calls ChannelUsageFunc.
+func (mock *RedPocketStoreMock) ChannelUsage(ctx context.Context, id string, channelID string) (int, model.Units, error) {
+	if mock.ChannelUsageFunc == nil {
+		panic("RedPocketStoreMock.ChannelUsageFunc: method is nil but RedPocketStore.ChannelUsage was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ID        string
+		ChannelID string
+	}{
+		Ctx:       ctx,
+		ID:        id,
+		ChannelID: channelID,
+	}
+	mock.lockChannelUsage.Lock()
+	mock.calls.ChannelUsage = append(mock.calls.ChannelUsage, callInfo)
+	mock.lockChannelUsage.Unlock()
+	return mock.ChannelUsageFunc(ctx, id, channelID)
+}
+
+// ChannelUsageCalls gets all the calls that were made to ChannelUsage.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ChannelUsageCalls())
+func (mock *RedPocketStoreMock) ChannelUsageCalls() []struct {
+	Ctx       context.Context
+	ID        string
+	ChannelID string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ID        string
+		ChannelID string
+	}
+	mock.lockChannelUsage.RLock()
+	calls = mock.calls.ChannelUsage
+	mock.lockChannelUsage.RUnlock()
+	return calls
+}
+
+// ClaimAtomic calls ClaimAtomicFunc.
+func (mock *RedPocketStoreMock) ClaimAtomic(ctx context.Context, id string, claimUnits model.Units) (*model.RedPocket, error) {
+	if mock.ClaimAtomicFunc == nil {
+		panic("RedPocketStoreMock.ClaimAtomicFunc: method is nil but RedPocketStore.ClaimAtomic was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		ID         string
+		ClaimUnits model.Units
+	}{
+		Ctx:        ctx,
+		ID:         id,
+		ClaimUnits: claimUnits,
+	}
+	mock.lockClaimAtomic.Lock()
+	mock.calls.ClaimAtomic = append(mock.calls.ClaimAtomic, callInfo)
+	mock.lockClaimAtomic.Unlock()
+	return mock.ClaimAtomicFunc(ctx, id, claimUnits)
+}
+
+// ClaimAtomicCalls gets all the calls that were made to ClaimAtomic.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ClaimAtomicCalls())
+func (mock *RedPocketStoreMock) ClaimAtomicCalls() []struct {
+	Ctx        context.Context
+	ID         string
+	ClaimUnits model.Units
+} {
+	var calls []struct {
+		Ctx        context.Context
+		ID         string
+		ClaimUnits model.Units
+	}
+	mock.lockClaimAtomic.RLock()
+	calls = mock.calls.ClaimAtomic
+	mock.lockClaimAtomic.RUnlock()
+	return calls
+}
+
+// ClaimWithPayout calls ClaimWithPayoutFunc.
+func (mock *RedPocketStoreMock) ClaimWithPayout(ctx context.Context, claim *model.Claim, job *model.PayoutJob, limit *model.ChannelLimit) (*model.RedPocket, error) {
+	if mock.ClaimWithPayoutFunc == nil {
+		panic("RedPocketStoreMock.ClaimWithPayoutFunc: method is nil but RedPocketStore.ClaimWithPayout was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Claim *model.Claim
+		Job   *model.PayoutJob
+		Limit *model.ChannelLimit
+	}{
+		Ctx:   ctx,
+		Claim: claim,
+		Job:   job,
+		Limit: limit,
+	}
+	mock.lockClaimWithPayout.Lock()
+	mock.calls.ClaimWithPayout = append(mock.calls.ClaimWithPayout, callInfo)
+	mock.lockClaimWithPayout.Unlock()
+	return mock.ClaimWithPayoutFunc(ctx, claim, job, limit)
+}
+
+// ClaimWithPayoutCalls gets all the calls that were made to ClaimWithPayout.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ClaimWithPayoutCalls())
+func (mock *RedPocketStoreMock) ClaimWithPayoutCalls() []struct {
+	Ctx   context.Context
+	Claim *model.Claim
+	Job   *model.PayoutJob
+	Limit *model.ChannelLimit
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Claim *model.Claim
+		Job   *model.PayoutJob
+		Limit *model.ChannelLimit
+	}
+	mock.lockClaimWithPayout.RLock()
+	calls = mock.calls.ClaimWithPayout
+	mock.lockClaimWithPayout.RUnlock()
+	return calls
+}
+
+// Create calls CreateFunc.
+func (mock *RedPocketStoreMock) Create(ctx context.Context, rp *model.RedPocket) error {
+	if mock.CreateFunc == nil {
+		panic("RedPocketStoreMock.CreateFunc: method is nil but RedPocketStore.Create was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Rp  *model.RedPocket
+	}{
+		Ctx: ctx,
+		Rp:  rp,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(ctx, rp)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.CreateCalls())
+func (mock *RedPocketStoreMock) CreateCalls() []struct {
+	Ctx context.Context
+	Rp  *model.RedPocket
+} {
+	var calls []struct {
+		Ctx context.Context
+		Rp  *model.RedPocket
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// CreateWithinBudget calls CreateWithinBudgetFunc.
+func (mock *RedPocketStoreMock) CreateWithinBudget(ctx context.Context, rp *model.RedPocket, deposit *model.PocketDeposit) (bool, error) {
+	if mock.CreateWithinBudgetFunc == nil {
+		panic("RedPocketStoreMock.CreateWithinBudgetFunc: method is nil but RedPocketStore.CreateWithinBudget was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Rp      *model.RedPocket
+		Deposit *model.PocketDeposit
+	}{
+		Ctx:     ctx,
+		Rp:      rp,
+		Deposit: deposit,
+	}
+	mock.lockCreateWithinBudget.Lock()
+	mock.calls.CreateWithinBudget = append(mock.calls.CreateWithinBudget, callInfo)
+	mock.lockCreateWithinBudget.Unlock()
+	return mock.CreateWithinBudgetFunc(ctx, rp, deposit)
+}
+
+// CreateWithinBudgetCalls gets all the calls that were made to CreateWithinBudget.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.CreateWithinBudgetCalls())
+func (mock *RedPocketStoreMock) CreateWithinBudgetCalls() []struct {
+	Ctx     context.Context
+	Rp      *model.RedPocket
+	Deposit *model.PocketDeposit
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Rp      *model.RedPocket
+		Deposit *model.PocketDeposit
+	}
+	mock.lockCreateWithinBudget.RLock()
+	calls = mock.calls.CreateWithinBudget
+	mock.lockCreateWithinBudget.RUnlock()
+	return calls
+}
+
+// ExpireOld calls ExpireOldFunc.
+func (mock *RedPocketStoreMock) ExpireOld(ctx context.Context) ([]string, error) {
+	if mock.ExpireOldFunc == nil {
+		panic("RedPocketStoreMock.ExpireOldFunc: method is nil but RedPocketStore.ExpireOld was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockExpireOld.Lock()
+	mock.calls.ExpireOld = append(mock.calls.ExpireOld, callInfo)
+	mock.lockExpireOld.Unlock()
+	return mock.ExpireOldFunc(ctx)
+}
+
+// ExpireOldCalls gets all the calls that were made to ExpireOld.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ExpireOldCalls())
+func (mock *RedPocketStoreMock) ExpireOldCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockExpireOld.RLock()
+	calls = mock.calls.ExpireOld
+	mock.lockExpireOld.RUnlock()
+	return calls
+}
+
+// ExpiringWithin calls ExpiringWithinFunc.
+func (mock *RedPocketStoreMock) ExpiringWithin(ctx context.Context, lead string, after time.Duration, within time.Duration, minRemaining int, limit int) ([]*model.RedPocket, error) {
+	if mock.ExpiringWithinFunc == nil {
+		panic("RedPocketStoreMock.ExpiringWithinFunc: method is nil but RedPocketStore.ExpiringWithin was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		Lead         string
+		After        time.Duration
+		Within       time.Duration
+		MinRemaining int
+		Limit        int
+	}{
+		Ctx:          ctx,
+		Lead:         lead,
+		After:        after,
+		Within:       within,
+		MinRemaining: minRemaining,
+		Limit:        limit,
+	}
+	mock.lockExpiringWithin.Lock()
+	mock.calls.ExpiringWithin = append(mock.calls.ExpiringWithin, callInfo)
+	mock.lockExpiringWithin.Unlock()
+	return mock.ExpiringWithinFunc(ctx, lead, after, within, minRemaining, limit)
+}
+
+// ExpiringWithinCalls gets all the calls that were made to ExpiringWithin.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ExpiringWithinCalls())
+func (mock *RedPocketStoreMock) ExpiringWithinCalls() []struct {
+	Ctx          context.Context
+	Lead         string
+	After        time.Duration
+	Within       time.Duration
+	MinRemaining int
+	Limit        int
+} {
+	var calls []struct {
+		Ctx          context.Context
+		Lead         string
+		After        time.Duration
+		Within       time.Duration
+		MinRemaining int
+		Limit        int
+	}
+	mock.lockExpiringWithin.RLock()
+	calls = mock.calls.ExpiringWithin
+	mock.lockExpiringWithin.RUnlock()
+	return calls
+}
+
+// Extend calls ExtendFunc.
+func (mock *RedPocketStoreMock) Extend(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	if mock.ExtendFunc == nil {
+		panic("RedPocketStoreMock.ExtendFunc: method is nil but RedPocketStore.Extend was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ID        string
+		ExpiresAt time.Time
+	}{
+		Ctx:       ctx,
+		ID:        id,
+		ExpiresAt: expiresAt,
+	}
+	mock.lockExtend.Lock()
+	mock.calls.Extend = append(mock.calls.Extend, callInfo)
+	mock.lockExtend.Unlock()
+	return mock.ExtendFunc(ctx, id, expiresAt)
+}
+
+// ExtendCalls gets all the calls that were made to Extend.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ExtendCalls())
+func (mock *RedPocketStoreMock) ExtendCalls() []struct {
+	Ctx       context.Context
+	ID        string
+	ExpiresAt time.Time
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ID        string
+		ExpiresAt time.Time
+	}
+	mock.lockExtend.RLock()
+	calls = mock.calls.Extend
+	mock.lockExtend.RUnlock()
+	return calls
+}
+
+// GetByID calls GetByIDFunc.
+func (mock *RedPocketStoreMock) GetByID(ctx context.Context, id string) (*model.RedPocket, error) {
+	if mock.GetByIDFunc == nil {
+		panic("RedPocketStoreMock.GetByIDFunc: method is nil but RedPocketStore.GetByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetByID.Lock()
+	mock.calls.GetByID = append(mock.calls.GetByID, callInfo)
+	mock.lockGetByID.Unlock()
+	return mock.GetByIDFunc(ctx, id)
+}
+
+// GetByIDCalls gets all the calls that were made to GetByID.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.GetByIDCalls())
+func (mock *RedPocketStoreMock) GetByIDCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetByID.RLock()
+	calls = mock.calls.GetByID
+	mock.lockGetByID.RUnlock()
+	return calls
+}
+
+// History calls HistoryFunc.
+func (mock *RedPocketStoreMock) History(ctx context.Context, id string) ([]*model.PocketTransition, error) {
+	if mock.HistoryFunc == nil {
+		panic("RedPocketStoreMock.HistoryFunc: method is nil but RedPocketStore.History was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockHistory.Lock()
+	mock.calls.History = append(mock.calls.History, callInfo)
+	mock.lockHistory.Unlock()
+	return mock.HistoryFunc(ctx, id)
+}
+
+// HistoryCalls gets all the calls that were made to History.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.HistoryCalls())
+func (mock *RedPocketStoreMock) HistoryCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockHistory.RLock()
+	calls = mock.calls.History
+	mock.lockHistory.RUnlock()
+	return calls
+}
+
+// ListByCampaign calls ListByCampaignFunc.
+func (mock *RedPocketStoreMock) ListByCampaign(ctx context.Context, campaignID string, limit int, offset int) ([]*model.RedPocket, error) {
+	if mock.ListByCampaignFunc == nil {
+		panic("RedPocketStoreMock.ListByCampaignFunc: method is nil but RedPocketStore.ListByCampaign was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		CampaignID string
+		Limit      int
+		Offset     int
+	}{
+		Ctx:        ctx,
+		CampaignID: campaignID,
+		Limit:      limit,
+		Offset:     offset,
+	}
+	mock.lockListByCampaign.Lock()
+	mock.calls.ListByCampaign = append(mock.calls.ListByCampaign, callInfo)
+	mock.lockListByCampaign.Unlock()
+	return mock.ListByCampaignFunc(ctx, campaignID, limit, offset)
+}
+
+// ListByCampaignCalls gets all the calls that were made to ListByCampaign.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ListByCampaignCalls())
+func (mock *RedPocketStoreMock) ListByCampaignCalls() []struct {
+	Ctx        context.Context
+	CampaignID string
+	Limit      int
+	Offset     int
+} {
+	var calls []struct {
+		Ctx        context.Context
+		CampaignID string
+		Limit      int
+		Offset     int
+	}
+	mock.lockListByCampaign.RLock()
+	calls = mock.calls.ListByCampaign
+	mock.lockListByCampaign.RUnlock()
+	return calls
+}
+
+// ListChannelUsage calls ListChannelUsageFunc.
+func (mock *RedPocketStoreMock) ListChannelUsage(ctx context.Context, id string) (map[string]*model.ChannelUsage, error) {
+	if mock.ListChannelUsageFunc == nil {
+		panic("RedPocketStoreMock.ListChannelUsageFunc: method is nil but RedPocketStore.ListChannelUsage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockListChannelUsage.Lock()
+	mock.calls.ListChannelUsage = append(mock.calls.ListChannelUsage, callInfo)
+	mock.lockListChannelUsage.Unlock()
+	return mock.ListChannelUsageFunc(ctx, id)
+}
+
+// ListChannelUsageCalls gets all the calls that were made to ListChannelUsage.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ListChannelUsageCalls())
+func (mock *RedPocketStoreMock) ListChannelUsageCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockListChannelUsage.RLock()
+	calls = mock.calls.ListChannelUsage
+	mock.lockListChannelUsage.RUnlock()
+	return calls
+}
+
+// ListScheduled calls ListScheduledFunc.
+func (mock *RedPocketStoreMock) ListScheduled(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.RedPocket, int64, error) {
+	if mock.ListScheduledFunc == nil {
+		panic("RedPocketStoreMock.ListScheduledFunc: method is nil but RedPocketStore.ListScheduled was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		EnterpriseID string
+		Limit        int
+		Offset       int
+	}{
+		Ctx:          ctx,
+		EnterpriseID: enterpriseID,
+		Limit:        limit,
+		Offset:       offset,
+	}
+	mock.lockListScheduled.Lock()
+	mock.calls.ListScheduled = append(mock.calls.ListScheduled, callInfo)
+	mock.lockListScheduled.Unlock()
+	return mock.ListScheduledFunc(ctx, enterpriseID, limit, offset)
+}
+
+// ListScheduledCalls gets all the calls that were made to ListScheduled.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ListScheduledCalls())
+func (mock *RedPocketStoreMock) ListScheduledCalls() []struct {
+	Ctx          context.Context
+	EnterpriseID string
+	Limit        int
+	Offset       int
+} {
+	var calls []struct {
+		Ctx          context.Context
+		EnterpriseID string
+		Limit        int
+		Offset       int
+	}
+	mock.lockListScheduled.RLock()
+	calls = mock.calls.ListScheduled
+	mock.lockListScheduled.RUnlock()
+	return calls
+}
+
+// ListUnarchived calls ListUnarchivedFunc.
+func (mock *RedPocketStoreMock) ListUnarchived(ctx context.Context, limit int) ([]string, error) {
+	if mock.ListUnarchivedFunc == nil {
+		panic("RedPocketStoreMock.ListUnarchivedFunc: method is nil but RedPocketStore.ListUnarchived was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Limit int
+	}{
+		Ctx:   ctx,
+		Limit: limit,
+	}
+	mock.lockListUnarchived.Lock()
+	mock.calls.ListUnarchived = append(mock.calls.ListUnarchived, callInfo)
+	mock.lockListUnarchived.Unlock()
+	return mock.ListUnarchivedFunc(ctx, limit)
+}
+
+// ListUnarchivedCalls gets all the calls that were made to ListUnarchived.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ListUnarchivedCalls())
+func (mock *RedPocketStoreMock) ListUnarchivedCalls() []struct {
+	Ctx   context.Context
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Limit int
+	}
+	mock.lockListUnarchived.RLock()
+	calls = mock.calls.ListUnarchived
+	mock.lockListUnarchived.RUnlock()
+	return calls
+}
+
+// MarkReminded calls MarkRemindedFunc.
+func (mock *RedPocketStoreMock) MarkReminded(ctx context.Context, id string, expiresAt time.Time, lead string) (bool, error) {
+	if mock.MarkRemindedFunc == nil {
+		panic("RedPocketStoreMock.MarkRemindedFunc: method is nil but RedPocketStore.MarkReminded was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ID        string
+		ExpiresAt time.Time
+		Lead      string
+	}{
+		Ctx:       ctx,
+		ID:        id,
+		ExpiresAt: expiresAt,
+		Lead:      lead,
+	}
+	mock.lockMarkReminded.Lock()
+	mock.calls.MarkReminded = append(mock.calls.MarkReminded, callInfo)
+	mock.lockMarkReminded.Unlock()
+	return mock.MarkRemindedFunc(ctx, id, expiresAt, lead)
+}
+
+// MarkRemindedCalls gets all the calls that were made to MarkReminded.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.MarkRemindedCalls())
+func (mock *RedPocketStoreMock) MarkRemindedCalls() []struct {
+	Ctx       context.Context
+	ID        string
+	ExpiresAt time.Time
+	Lead      string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ID        string
+		ExpiresAt time.Time
+		Lead      string
+	}
+	mock.lockMarkReminded.RLock()
+	calls = mock.calls.MarkReminded
+	mock.lockMarkReminded.RUnlock()
+	return calls
+}
+
+// NextShare calls NextShareFunc.
+func (mock *RedPocketStoreMock) NextShare(ctx context.Context, id string, from int) (int, model.Units, error) {
+	if mock.NextShareFunc == nil {
+		panic("RedPocketStoreMock.NextShareFunc: method is nil but RedPocketStore.NextShare was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		ID   string
+		From int
+	}{
+		Ctx:  ctx,
+		ID:   id,
+		From: from,
+	}
+	mock.lockNextShare.Lock()
+	mock.calls.NextShare = append(mock.calls.NextShare, callInfo)
+	mock.lockNextShare.Unlock()
+	return mock.NextShareFunc(ctx, id, from)
+}
+
+// NextShareCalls gets all the calls that were made to NextShare.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.NextShareCalls())
+func (mock *RedPocketStoreMock) NextShareCalls() []struct {
+	Ctx  context.Context
+	ID   string
+	From int
+} {
+	var calls []struct {
+		Ctx  context.Context
+		ID   string
+		From int
+	}
+	mock.lockNextShare.RLock()
+	calls = mock.calls.NextShare
+	mock.lockNextShare.RUnlock()
+	return calls
+}
+
+// Resize calls ResizeFunc.
+func (mock *RedPocketStoreMock) Resize(ctx context.Context, rp *model.RedPocket, amountUnits model.Units, totalCount int, moveDeposit func(diff *big.Int) error) (*model.RedPocket, bool, error) {
+	if mock.ResizeFunc == nil {
+		panic("RedPocketStoreMock.ResizeFunc: method is nil but RedPocketStore.Resize was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Rp          *model.RedPocket
+		AmountUnits model.Units
+		TotalCount  int
+		MoveDeposit func(diff *big.Int) error
+	}{
+		Ctx:         ctx,
+		Rp:          rp,
+		AmountUnits: amountUnits,
+		TotalCount:  totalCount,
+		MoveDeposit: moveDeposit,
+	}
+	mock.lockResize.Lock()
+	mock.calls.Resize = append(mock.calls.Resize, callInfo)
+	mock.lockResize.Unlock()
+	return mock.ResizeFunc(ctx, rp, amountUnits, totalCount, moveDeposit)
+}
+
+// ResizeCalls gets all the calls that were made to Resize.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ResizeCalls())
+func (mock *RedPocketStoreMock) ResizeCalls() []struct {
+	Ctx         context.Context
+	Rp          *model.RedPocket
+	AmountUnits model.Units
+	TotalCount  int
+	MoveDeposit func(diff *big.Int) error
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Rp          *model.RedPocket
+		AmountUnits model.Units
+		TotalCount  int
+		MoveDeposit func(diff *big.Int) error
+	}
+	mock.lockResize.RLock()
+	calls = mock.calls.Resize
+	mock.lockResize.RUnlock()
+	return calls
+}
+
+// ReturnClaim calls ReturnClaimFunc.
+func (mock *RedPocketStoreMock) ReturnClaim(ctx context.Context, claim *model.Claim) error {
+	if mock.ReturnClaimFunc == nil {
+		panic("RedPocketStoreMock.ReturnClaimFunc: method is nil but RedPocketStore.ReturnClaim was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Claim *model.Claim
+	}{
+		Ctx:   ctx,
+		Claim: claim,
+	}
+	mock.lockReturnClaim.Lock()
+	mock.calls.ReturnClaim = append(mock.calls.ReturnClaim, callInfo)
+	mock.lockReturnClaim.Unlock()
+	return mock.ReturnClaimFunc(ctx, claim)
+}
+
+// ReturnClaimCalls gets all the calls that were made to ReturnClaim.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.ReturnClaimCalls())
+func (mock *RedPocketStoreMock) ReturnClaimCalls() []struct {
+	Ctx   context.Context
+	Claim *model.Claim
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Claim *model.Claim
+	}
+	mock.lockReturnClaim.RLock()
+	calls = mock.calls.ReturnClaim
+	mock.lockReturnClaim.RUnlock()
+	return calls
+}
+
+// Transition calls TransitionFunc.
+func (mock *RedPocketStoreMock) Transition(ctx context.Context, id string, to model.PocketStatus, reason string) (bool, error) {
+	if mock.TransitionFunc == nil {
+		panic("RedPocketStoreMock.TransitionFunc: method is nil but RedPocketStore.Transition was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		ID     string
+		To     model.PocketStatus
+		Reason string
+	}{
+		Ctx:    ctx,
+		ID:     id,
+		To:     to,
+		Reason: reason,
+	}
+	mock.lockTransition.Lock()
+	mock.calls.Transition = append(mock.calls.Transition, callInfo)
+	mock.lockTransition.Unlock()
+	return mock.TransitionFunc(ctx, id, to, reason)
+}
+
+// TransitionCalls gets all the calls that were made to Transition.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.TransitionCalls())
+func (mock *RedPocketStoreMock) TransitionCalls() []struct {
+	Ctx    context.Context
+	ID     string
+	To     model.PocketStatus
+	Reason string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		ID     string
+		To     model.PocketStatus
+		Reason string
+	}
+	mock.lockTransition.RLock()
+	calls = mock.calls.Transition
+	mock.lockTransition.RUnlock()
+	return calls
+}
+
+// TryClaimLock calls TryClaimLockFunc.
+func (mock *RedPocketStoreMock) TryClaimLock(ctx context.Context, key string) (func(), bool, error) {
+	if mock.TryClaimLockFunc == nil {
+		panic("RedPocketStoreMock.TryClaimLockFunc: method is nil but RedPocketStore.TryClaimLock was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockTryClaimLock.Lock()
+	mock.calls.TryClaimLock = append(mock.calls.TryClaimLock, callInfo)
+	mock.lockTryClaimLock.Unlock()
+	return mock.TryClaimLockFunc(ctx, key)
+}
+
+// TryClaimLockCalls gets all the calls that were made to TryClaimLock.
+// Check the length with:
+//
+//	len(mockedRedPocketStore.TryClaimLockCalls())
+func (mock *RedPocketStoreMock) TryClaimLockCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockTryClaimLock.RLock()
+	calls = mock.calls.TryClaimLock
+	mock.lockTryClaimLock.RUnlock()
+	return calls
+}
+
+// Ensure, that ClaimStoreMock does implement repository.ClaimStore.
+// If this is not the case, regenerate this file with moq.
+var _ repository.ClaimStore = &ClaimStoreMock{}
+
+// ClaimStoreMock is a mock implementation of repository.ClaimStore.
+//
+//	func TestSomethingThatUsesClaimStore(t *testing.T) {
+//
+//		// make and configure a mocked repository.ClaimStore
+//		mockedClaimStore := &ClaimStoreMock{
+//			ClaimedTotalsFunc: func(ctx context.Context, redPocketID string) (int, float64, error) {
+//				panic("mock out the ClaimedTotals method")
+//			},
+//			ClaimerChainSummaryFunc: func(ctx context.Context, claimerID string) ([]*model.ChainClaimSummary, error) {
+//				panic("mock out the ClaimerChainSummary method")
+//			},
+//			ClaimerTotalsFunc: func(ctx context.Context, platform string, platformID string) ([]*model.ClaimerTotal, error) {
+//				panic("mock out the ClaimerTotals method")
+//			},
+//			CreateFunc: func(ctx context.Context, c *model.Claim) error {
+//				panic("mock out the Create method")
+//			},
+//			FirstClaimAtFunc: func(ctx context.Context, platform string, platformID string) (*time.Time, error) {
+//				panic("mock out the FirstClaimAt method")
+//			},
+//			GetByIDFunc: func(ctx context.Context, id string) (*model.Claim, error) {
+//				panic("mock out the GetByID method")
+//			},
+//			HasClaimedFunc: func(ctx context.Context, redPocketID string, platformID string, platform string) (bool, error) {
+//				panic("mock out the HasClaimed method")
+//			},
+//			HistoryFunc: func(ctx context.Context, id string) ([]*model.ClaimTransition, error) {
+//				panic("mock out the History method")
+//			},
+//			IDsByBatchFunc: func(ctx context.Context, batchID string) ([]string, error) {
+//				panic("mock out the IDsByBatch method")
+//			},
+//			LeaderboardFunc: func(ctx context.Context, redPocketID string, limit int) ([]*model.LeaderboardEntry, error) {
+//				panic("mock out the Leaderboard method")
+//			},
+//			LinkUsedFunc: func(ctx context.Context, linkID string) (bool, error) {
+//				panic("mock out the LinkUsed method")
+//			},
+//			ListAllByRedPocketFunc: func(ctx context.Context, redPocketID string) ([]*model.Claim, error) {
+//				panic("mock out the ListAllByRedPocket method")
+//			},
+//			ListByCampaignFunc: func(ctx context.Context, campaignID string, limit int, offset int) ([]*model.Claim, int64, error) {
+//				panic("mock out the ListByCampaign method")
+//			},
+//			ListByClaimerFunc: func(ctx context.Context, platform string, platformID string, limit int, offset int) ([]*model.ClaimerClaim, int64, error) {
+//				panic("mock out the ListByClaimer method")
+//			},
+//			ListByEnterpriseFunc: func(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.Claim, int64, error) {
+//				panic("mock out the ListByEnterprise method")
+//			},
+//			ListByRedPocketFunc: func(ctx context.Context, redPocketID string, limit int, offset int) ([]*model.Claim, error) {
+//				panic("mock out the ListByRedPocket method")
+//			},
+//			MarkResubmittedFunc: func(ctx context.Context, id string, attempts int, reason string) (bool, error) {
+//				panic("mock out the MarkResubmitted method")
+//			},
+//			MarkResubmittedByBatchFunc: func(ctx context.Context, batchID string, attempts int, reason string) error {
+//				panic("mock out the MarkResubmittedByBatch method")
+//			},
+//			SetScreeningFunc: func(ctx context.Context, id string, screeningID string, result string) error {
+//				panic("mock out the SetScreening method")
+//			},
+//			TransitionFunc: func(ctx context.Context, id string, to model.ClaimStatus, txHash string, reason string) (bool, error) {
+//				panic("mock out the Transition method")
+//			},
+//			TransitionBatchFunc: func(ctx context.Context, batchID string, to model.ClaimStatus, txHash string, reason string) (int, error) {
+//				panic("mock out the TransitionBatch method")
+//			},
+//		}
+//
+//		// use mockedClaimStore in code that requires repository.ClaimStore
+//		// and then make assertions.
+//
+//	}
+type ClaimStoreMock struct {
+	// ClaimedTotalsFunc mocks the ClaimedTotals method.
+	ClaimedTotalsFunc func(ctx context.Context, redPocketID string) (int, float64, error)
+
+	// ClaimerChainSummaryFunc mocks the ClaimerChainSummary method.
+	ClaimerChainSummaryFunc func(ctx context.Context, claimerID string) ([]*model.ChainClaimSummary, error)
+
+	// ClaimerTotalsFunc mocks the ClaimerTotals method.
+	ClaimerTotalsFunc func(ctx context.Context, platform string, platformID string) ([]*model.ClaimerTotal, error)
+
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, c *model.Claim) error
+
+	// FirstClaimAtFunc mocks the FirstClaimAt method.
+	FirstClaimAtFunc func(ctx context.Context, platform string, platformID string) (*time.Time, error)
+
+	// GetByIDFunc mocks the GetByID method.
+	GetByIDFunc func(ctx context.Context, id string) (*model.Claim, error)
+
+	// HasClaimedFunc mocks the HasClaimed method.
+	HasClaimedFunc func(ctx context.Context, redPocketID string, platformID string, platform string) (bool, error)
+
+	// HistoryFunc mocks the History method.
+	HistoryFunc func(ctx context.Context, id string) ([]*model.ClaimTransition, error)
+
+	// IDsByBatchFunc mocks the IDsByBatch method.
+	IDsByBatchFunc func(ctx context.Context, batchID string) ([]string, error)
+
+	// LeaderboardFunc mocks the Leaderboard method.
+	LeaderboardFunc func(ctx context.Context, redPocketID string, limit int) ([]*model.LeaderboardEntry, error)
+
+	// LinkUsedFunc mocks the LinkUsed method.
+	LinkUsedFunc func(ctx context.Context, linkID string) (bool, error)
+
+	// ListAllByRedPocketFunc mocks the ListAllByRedPocket method.
+	ListAllByRedPocketFunc func(ctx context.Context, redPocketID string) ([]*model.Claim, error)
+
+	// ListByCampaignFunc mocks the ListByCampaign method.
+	ListByCampaignFunc func(ctx context.Context, campaignID string, limit int, offset int) ([]*model.Claim, int64, error)
+
+	// ListByClaimerFunc mocks the ListByClaimer method.
+	ListByClaimerFunc func(ctx context.Context, platform string, platformID string, limit int, offset int) ([]*model.ClaimerClaim, int64, error)
+
+	// ListByEnterpriseFunc mocks the ListByEnterprise method.
+	ListByEnterpriseFunc func(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.Claim, int64, error)
+
+	// ListByRedPocketFunc mocks the ListByRedPocket method.
+	ListByRedPocketFunc func(ctx context.Context, redPocketID string, limit int, offset int) ([]*model.Claim, error)
+
+	// MarkResubmittedFunc mocks the MarkResubmitted method.
+	MarkResubmittedFunc func(ctx context.Context, id string, attempts int, reason string) (bool, error)
+
+	// MarkResubmittedByBatchFunc mocks the MarkResubmittedByBatch method.
+	MarkResubmittedByBatchFunc func(ctx context.Context, batchID string, attempts int, reason string) error
+
+	// SetScreeningFunc mocks the SetScreening method.
+	SetScreeningFunc func(ctx context.Context, id string, screeningID string, result string) error
+
+	// TransitionFunc mocks the Transition method.
+	TransitionFunc func(ctx context.Context, id string, to model.ClaimStatus, txHash string, reason string) (bool, error)
+
+	// TransitionBatchFunc mocks the TransitionBatch method.
+	TransitionBatchFunc func(ctx context.Context, batchID string, to model.ClaimStatus, txHash string, reason string) (int, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ClaimedTotals holds details about calls to the ClaimedTotals method.
+		ClaimedTotals []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RedPocketID is the redPocketID argument value.
+			RedPocketID string
+		}
+		// ClaimerChainSummary holds details about calls to the ClaimerChainSummary method.
+		ClaimerChainSummary []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ClaimerID is the claimerID argument value.
+			ClaimerID string
+		}
+		// ClaimerTotals holds details about calls to the ClaimerTotals method.
+		ClaimerTotals []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Platform is the platform argument value.
+			Platform string
+			// PlatformID is the platformID argument value.
+			PlatformID string
+		}
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// C is the c argument value.
+			C *model.Claim
+		}
+		// FirstClaimAt holds details about calls to the FirstClaimAt method.
+		FirstClaimAt []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Platform is the platform argument value.
+			Platform string
+			// PlatformID is the platformID argument value.
+			PlatformID string
+		}
+		// GetByID holds details about calls to the GetByID method.
+		GetByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// HasClaimed holds details about calls to the HasClaimed method.
+		HasClaimed []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RedPocketID is the redPocketID argument value.
+			RedPocketID string
+			// PlatformID is the platformID argument value.
+			PlatformID string
+			// Platform is the platform argument value.
+			Platform string
+		}
+		// History holds details about calls to the History method.
+		History []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// IDsByBatch holds details about calls to the IDsByBatch method.
+		IDsByBatch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// BatchID is the batchID argument value.
+			BatchID string
+		}
+		// Leaderboard holds details about calls to the Leaderboard method.
+		Leaderboard []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RedPocketID is the redPocketID argument value.
+			RedPocketID string
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// LinkUsed holds details about calls to the LinkUsed method.
+		LinkUsed []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// LinkID is the linkID argument value.
+			LinkID string
+		}
+		// ListAllByRedPocket holds details about calls to the ListAllByRedPocket method.
+		ListAllByRedPocket []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RedPocketID is the redPocketID argument value.
+			RedPocketID string
+		}
+		// ListByCampaign holds details about calls to the ListByCampaign method.
+		ListByCampaign []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// CampaignID is the campaignID argument value.
+			CampaignID string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// ListByClaimer holds details about calls to the ListByClaimer method.
+		ListByClaimer []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Platform is the platform argument value.
+			Platform string
+			// PlatformID is the platformID argument value.
+			PlatformID string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// ListByEnterprise holds details about calls to the ListByEnterprise method.
+		ListByEnterprise []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EnterpriseID is the enterpriseID argument value.
+			EnterpriseID string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// ListByRedPocket holds details about calls to the ListByRedPocket method.
+		ListByRedPocket []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// RedPocketID is the redPocketID argument value.
+			RedPocketID string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// MarkResubmitted holds details about calls to the MarkResubmitted method.
+		MarkResubmitted []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// Attempts is the attempts argument value.
+			Attempts int
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// MarkResubmittedByBatch holds details about calls to the MarkResubmittedByBatch method.
+		MarkResubmittedByBatch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// BatchID is the batchID argument value.
+			BatchID string
+			// Attempts is the attempts argument value.
+			Attempts int
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// SetScreening holds details about calls to the SetScreening method.
+		SetScreening []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// ScreeningID is the screeningID argument value.
+			ScreeningID string
+			// Result is the result argument value.
+			Result string
+		}
+		// Transition holds details about calls to the Transition method.
+		Transition []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// To is the to argument value.
+			To model.ClaimStatus
+			// TxHash is the txHash argument value.
+			TxHash string
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// TransitionBatch holds details about calls to the TransitionBatch method.
+		TransitionBatch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// BatchID is the batchID argument value.
+			BatchID string
+			// To is the to argument value.
+			To model.ClaimStatus
+			// TxHash is the txHash argument value.
+			TxHash string
+			// Reason is the reason argument value.
+			Reason string
+		}
+	}
+	lockClaimedTotals          sync.RWMutex
+	lockClaimerChainSummary    sync.RWMutex
+	lockClaimerTotals          sync.RWMutex
+	lockCreate                 sync.RWMutex
+	lockFirstClaimAt           sync.RWMutex
+	lockGetByID                sync.RWMutex
+	lockHasClaimed             sync.RWMutex
+	lockHistory                sync.RWMutex
+	lockIDsByBatch             sync.RWMutex
+	lockLeaderboard            sync.RWMutex
+	lockLinkUsed               sync.RWMutex
+	lockListAllByRedPocket     sync.RWMutex
+	lockListByCampaign         sync.RWMutex
+	lockListByClaimer          sync.RWMutex
+	lockListByEnterprise       sync.RWMutex
+	lockListByRedPocket        sync.RWMutex
+	lockMarkResubmitted        sync.RWMutex
+	lockMarkResubmittedByBatch sync.RWMutex
+	lockSetScreening           sync.RWMutex
+	lockTransition             sync.RWMutex
+	lockTransitionBatch        sync.RWMutex
+}
+
+// ClaimedTotals calls ClaimedTotalsFunc.
+func (mock *ClaimStoreMock) ClaimedTotals(ctx context.Context, redPocketID string) (int, float64, error) {
+	if mock.ClaimedTotalsFunc == nil {
+		panic("ClaimStoreMock.ClaimedTotalsFunc: method is nil but ClaimStore.ClaimedTotals was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		RedPocketID string
+	}{
+		Ctx:         ctx,
+		RedPocketID: redPocketID,
+	}
+	mock.lockClaimedTotals.Lock()
+	mock.calls.ClaimedTotals = append(mock.calls.ClaimedTotals, callInfo)
+	mock.lockClaimedTotals.Unlock()
+	return mock.ClaimedTotalsFunc(ctx, redPocketID)
+}
+
+// ClaimedTotalsCalls gets all the calls that were made to ClaimedTotals.
+// Check the length with:
+//
+//	len(mockedClaimStore.ClaimedTotalsCalls())
+func (mock *ClaimStoreMock) ClaimedTotalsCalls() []struct {
+	Ctx         context.Context
+	RedPocketID string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		RedPocketID string
+	}
+	mock.lockClaimedTotals.RLock()
+	calls = mock.calls.ClaimedTotals
+	mock.lockClaimedTotals.RUnlock()
+	return calls
+}
+
+// ClaimerChainSummary calls ClaimerChainSummaryFunc.
+func (mock *ClaimStoreMock) ClaimerChainSummary(ctx context.Context, claimerID string) ([]*model.ChainClaimSummary, error) {
+	if mock.ClaimerChainSummaryFunc == nil {
+		panic("ClaimStoreMock.ClaimerChainSummaryFunc: method is nil but ClaimStore.ClaimerChainSummary was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ClaimerID string
+	}{
+		Ctx:       ctx,
+		ClaimerID: claimerID,
+	}
+	mock.lockClaimerChainSummary.Lock()
+	mock.calls.ClaimerChainSummary = append(mock.calls.ClaimerChainSummary, callInfo)
+	mock.lockClaimerChainSummary.Unlock()
+	return mock.ClaimerChainSummaryFunc(ctx, claimerID)
+}
+
+// ClaimerChainSummaryCalls gets all the calls that were made to ClaimerChainSummary.
+// Check the length with:
+//
+//	len(mockedClaimStore.ClaimerChainSummaryCalls())
+func (mock *ClaimStoreMock) ClaimerChainSummaryCalls() []struct {
+	Ctx       context.Context
+	ClaimerID string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ClaimerID string
+	}
+	mock.lockClaimerChainSummary.RLock()
+	calls = mock.calls.ClaimerChainSummary
+	mock.lockClaimerChainSummary.RUnlock()
+	return calls
+}
+
+// ClaimerTotals calls ClaimerTotalsFunc.
+func (mock *ClaimStoreMock) ClaimerTotals(ctx context.Context, platform string, platformID string) ([]*model.ClaimerTotal, error) {
+	if mock.ClaimerTotalsFunc == nil {
+		panic("ClaimStoreMock.ClaimerTotalsFunc: method is nil but ClaimStore.ClaimerTotals was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Platform   string
+		PlatformID string
+	}{
+		Ctx:        ctx,
+		Platform:   platform,
+		PlatformID: platformID,
+	}
+	mock.lockClaimerTotals.Lock()
+	mock.calls.ClaimerTotals = append(mock.calls.ClaimerTotals, callInfo)
+	mock.lockClaimerTotals.Unlock()
+	return mock.ClaimerTotalsFunc(ctx, platform, platformID)
+}
+
+// ClaimerTotalsCalls gets all the calls that were made to ClaimerTotals.
+// Check the length with:
+//
+//	len(mockedClaimStore.ClaimerTotalsCalls())
+func (mock *ClaimStoreMock) ClaimerTotalsCalls() []struct {
+	Ctx        context.Context
+	Platform   string
+	PlatformID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Platform   string
+		PlatformID string
+	}
+	mock.lockClaimerTotals.RLock()
+	calls = mock.calls.ClaimerTotals
+	mock.lockClaimerTotals.RUnlock()
+	return calls
+}
+
+// Create calls CreateFunc.
+func (mock *ClaimStoreMock) Create(ctx context.Context, c *model.Claim) error {
+	if mock.CreateFunc == nil {
+		panic("ClaimStoreMock.CreateFunc: method is nil but ClaimStore.Create was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		C   *model.Claim
+	}{
+		Ctx: ctx,
+		C:   c,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(ctx, c)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedClaimStore.CreateCalls())
+func (mock *ClaimStoreMock) CreateCalls() []struct {
+	Ctx context.Context
+	C   *model.Claim
+} {
+	var calls []struct {
+		Ctx context.Context
+		C   *model.Claim
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// FirstClaimAt calls FirstClaimAtFunc.
+func (mock *ClaimStoreMock) FirstClaimAt(ctx context.Context, platform string, platformID string) (*time.Time, error) {
+	if mock.FirstClaimAtFunc == nil {
+		panic("ClaimStoreMock.FirstClaimAtFunc: method is nil but ClaimStore.FirstClaimAt was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Platform   string
+		PlatformID string
+	}{
+		Ctx:        ctx,
+		Platform:   platform,
+		PlatformID: platformID,
+	}
+	mock.lockFirstClaimAt.Lock()
+	mock.calls.FirstClaimAt = append(mock.calls.FirstClaimAt, callInfo)
+	mock.lockFirstClaimAt.Unlock()
+	return mock.FirstClaimAtFunc(ctx, platform, platformID)
+}
+
+// FirstClaimAtCalls gets all the calls that were made to FirstClaimAt.
+// Check the length with:
+//
+//	len(mockedClaimStore.FirstClaimAtCalls())
+func (mock *ClaimStoreMock) FirstClaimAtCalls() []struct {
+	Ctx        context.Context
+	Platform   string
+	PlatformID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Platform   string
+		PlatformID string
+	}
+	mock.lockFirstClaimAt.RLock()
+	calls = mock.calls.FirstClaimAt
+	mock.lockFirstClaimAt.RUnlock()
+	return calls
+}
+
+// GetByID calls GetByIDFunc.
+func (mock *ClaimStoreMock) GetByID(ctx context.Context, id string) (*model.Claim, error) {
+	if mock.GetByIDFunc == nil {
+		panic("ClaimStoreMock.GetByIDFunc: method is nil but ClaimStore.GetByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetByID.Lock()
+	mock.calls.GetByID = append(mock.calls.GetByID, callInfo)
+	mock.lockGetByID.Unlock()
+	return mock.GetByIDFunc(ctx, id)
+}
+
+// GetByIDCalls gets all the calls that were made to GetByID.
+// Check the length with:
+//
+//	len(mockedClaimStore.GetByIDCalls())
+func (mock *ClaimStoreMock) GetByIDCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetByID.RLock()
+	calls = mock.calls.GetByID
+	mock.lockGetByID.RUnlock()
+	return calls
+}
+
+// HasClaimed calls HasClaimedFunc.
+func (mock *ClaimStoreMock) HasClaimed(ctx context.Context, redPocketID string, platformID string, platform string) (bool, error) {
+	if mock.HasClaimedFunc == nil {
+		panic("ClaimStoreMock.HasClaimedFunc: method is nil but ClaimStore.HasClaimed was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		RedPocketID string
+		PlatformID  string
+		Platform    string
+	}{
+		Ctx:         ctx,
+		RedPocketID: redPocketID,
+		PlatformID:  platformID,
+		Platform:    platform,
+	}
+	mock.lockHasClaimed.Lock()
+	mock.calls.HasClaimed = append(mock.calls.HasClaimed, callInfo)
+	mock.lockHasClaimed.Unlock()
+	return mock.HasClaimedFunc(ctx, redPocketID, platformID, platform)
+}
+
+// HasClaimedCalls gets all the calls that were made to HasClaimed.
+// Check the length with:
+//
+//	len(mockedClaimStore.HasClaimedCalls())
+func (mock *ClaimStoreMock) HasClaimedCalls() []struct {
+	Ctx         context.Context
+	RedPocketID string
+	PlatformID  string
+	Platform    string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		RedPocketID string
+		PlatformID  string
+		Platform    string
+	}
+	mock.lockHasClaimed.RLock()
+	calls = mock.calls.HasClaimed
+	mock.lockHasClaimed.RUnlock()
+	return calls
+}
+
+// History calls HistoryFunc.
+func (mock *ClaimStoreMock) History(ctx context.Context, id string) ([]*model.ClaimTransition, error) {
+	if mock.HistoryFunc == nil {
+		panic("ClaimStoreMock.HistoryFunc: method is nil but ClaimStore.History was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockHistory.Lock()
+	mock.calls.History = append(mock.calls.History, callInfo)
+	mock.lockHistory.Unlock()
+	return mock.HistoryFunc(ctx, id)
+}
+
+// HistoryCalls gets all the calls that were made to History.
+// Check the length with:
+//
+//	len(mockedClaimStore.HistoryCalls())
+func (mock *ClaimStoreMock) HistoryCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockHistory.RLock()
+	calls = mock.calls.History
+	mock.lockHistory.RUnlock()
+	return calls
+}
+
+// IDsByBatch calls IDsByBatchFunc.
+func (mock *ClaimStoreMock) IDsByBatch(ctx context.Context, batchID string) ([]string, error) {
+	if mock.IDsByBatchFunc == nil {
+		panic("ClaimStoreMock.IDsByBatchFunc: method is nil but ClaimStore.IDsByBatch was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		BatchID string
+	}{
+		Ctx:     ctx,
+		BatchID: batchID,
+	}
+	mock.lockIDsByBatch.Lock()
+	mock.calls.IDsByBatch = append(mock.calls.IDsByBatch, callInfo)
+	mock.lockIDsByBatch.Unlock()
+	return mock.IDsByBatchFunc(ctx, batchID)
+}
+
+// IDsByBatchCalls gets all the calls that were made to IDsByBatch.
+// Check the length with:
+//
+//	len(mockedClaimStore.IDsByBatchCalls())
+func (mock *ClaimStoreMock) IDsByBatchCalls() []struct {
+	Ctx     context.Context
+	BatchID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		BatchID string
+	}
+	mock.lockIDsByBatch.RLock()
+	calls = mock.calls.IDsByBatch
+	mock.lockIDsByBatch.RUnlock()
+	return calls
+}
+
+// Leaderboard calls LeaderboardFunc.
+func (mock *ClaimStoreMock) Leaderboard(ctx context.Context, redPocketID string, limit int) ([]*model.LeaderboardEntry, error) {
+	if mock.LeaderboardFunc == nil {
+		panic("ClaimStoreMock.LeaderboardFunc: method is nil but ClaimStore.Leaderboard was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		RedPocketID string
+		Limit       int
+	}{
+		Ctx:         ctx,
+		RedPocketID: redPocketID,
+		Limit:       limit,
+	}
+	mock.lockLeaderboard.Lock()
+	mock.calls.Leaderboard = append(mock.calls.Leaderboard, callInfo)
+	mock.lockLeaderboard.Unlock()
+	return mock.LeaderboardFunc(ctx, redPocketID, limit)
+}
+
+// LeaderboardCalls gets all the calls that were made to Leaderboard.
+// Check the length with:
+//
+//	len(mockedClaimStore.LeaderboardCalls())
+func (mock *ClaimStoreMock) LeaderboardCalls() []struct {
+	Ctx         context.Context
+	RedPocketID string
+	Limit       int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		RedPocketID string
+		Limit       int
+	}
+	mock.lockLeaderboard.RLock()
+	calls = mock.calls.Leaderboard
+	mock.lockLeaderboard.RUnlock()
+	return calls
+}
+
+// LinkUsed calls LinkUsedFunc.
+func (mock *ClaimStoreMock) LinkUsed(ctx context.Context, linkID string) (bool, error) {
+	if mock.LinkUsedFunc == nil {
+		panic("ClaimStoreMock.LinkUsedFunc: method is nil but ClaimStore.LinkUsed was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		LinkID string
+	}{
+		Ctx:    ctx,
+		LinkID: linkID,
+	}
+	mock.lockLinkUsed.Lock()
+	mock.calls.LinkUsed = append(mock.calls.LinkUsed, callInfo)
+	mock.lockLinkUsed.Unlock()
+	return mock.LinkUsedFunc(ctx, linkID)
+}
+
+// LinkUsedCalls gets all the calls that were made to LinkUsed.
+// Check the length with:
+//
+//	len(mockedClaimStore.LinkUsedCalls())
+func (mock *ClaimStoreMock) LinkUsedCalls() []struct {
+	Ctx    context.Context
+	LinkID string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		LinkID string
+	}
+	mock.lockLinkUsed.RLock()
+	calls = mock.calls.LinkUsed
+	mock.lockLinkUsed.RUnlock()
+	return calls
+}
+
+// ListAllByRedPocket calls ListAllByRedPocketFunc.
+func (mock *ClaimStoreMock) ListAllByRedPocket(ctx context.Context, redPocketID string) ([]*model.Claim, error) {
+	if mock.ListAllByRedPocketFunc == nil {
+		panic("ClaimStoreMock.ListAllByRedPocketFunc: method is nil but ClaimStore.ListAllByRedPocket was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		RedPocketID string
+	}{
+		Ctx:         ctx,
+		RedPocketID: redPocketID,
+	}
+	mock.lockListAllByRedPocket.Lock()
+	mock.calls.ListAllByRedPocket = append(mock.calls.ListAllByRedPocket, callInfo)
+	mock.lockListAllByRedPocket.Unlock()
+	return mock.ListAllByRedPocketFunc(ctx, redPocketID)
+}
+
+// ListAllByRedPocketCalls gets all the calls that were made to ListAllByRedPocket.
+// Check the length with:
+//
+//	len(mockedClaimStore.ListAllByRedPocketCalls())
+func (mock *ClaimStoreMock) ListAllByRedPocketCalls() []struct {
+	Ctx         context.Context
+	RedPocketID string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		RedPocketID string
+	}
+	mock.lockListAllByRedPocket.RLock()
+	calls = mock.calls.ListAllByRedPocket
+	mock.lockListAllByRedPocket.RUnlock()
+	return calls
+}
+
+// ListByCampaign calls ListByCampaignFunc.
+func (mock *ClaimStoreMock) ListByCampaign(ctx context.Context, campaignID string, limit int, offset int) ([]*model.Claim, int64, error) {
+	if mock.ListByCampaignFunc == nil {
+		panic("ClaimStoreMock.ListByCampaignFunc: method is nil but ClaimStore.ListByCampaign was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		CampaignID string
+		Limit      int
+		Offset     int
+	}{
+		Ctx:        ctx,
+		CampaignID: campaignID,
+		Limit:      limit,
+		Offset:     offset,
+	}
+	mock.lockListByCampaign.Lock()
+	mock.calls.ListByCampaign = append(mock.calls.ListByCampaign, callInfo)
+	mock.lockListByCampaign.Unlock()
+	return mock.ListByCampaignFunc(ctx, campaignID, limit, offset)
+}
+
+// ListByCampaignCalls gets all the calls that were made to ListByCampaign.
+// Check the length with:
+//
+//	len(mockedClaimStore.ListByCampaignCalls())
+func (mock *ClaimStoreMock) ListByCampaignCalls() []struct {
+	Ctx        context.Context
+	CampaignID string
+	Limit      int
+	Offset     int
+} {
+	var calls []struct {
+		Ctx        context.Context
+		CampaignID string
+		Limit      int
+		Offset     int
+	}
+	mock.lockListByCampaign.RLock()
+	calls = mock.calls.ListByCampaign
+	mock.lockListByCampaign.RUnlock()
+	return calls
+}
+
+// ListByClaimer calls ListByClaimerFunc.
+func (mock *ClaimStoreMock) ListByClaimer(ctx context.Context, platform string, platformID string, limit int, offset int) ([]*model.ClaimerClaim, int64, error) {
+	if mock.ListByClaimerFunc == nil {
+		panic("ClaimStoreMock.ListByClaimerFunc: method is nil but ClaimStore.ListByClaimer was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Platform   string
+		PlatformID string
+		Limit      int
+		Offset     int
+	}{
+		Ctx:        ctx,
+		Platform:   platform,
+		PlatformID: platformID,
+		Limit:      limit,
+		Offset:     offset,
+	}
+	mock.lockListByClaimer.Lock()
+	mock.calls.ListByClaimer = append(mock.calls.ListByClaimer, callInfo)
+	mock.lockListByClaimer.Unlock()
+	return mock.ListByClaimerFunc(ctx, platform, platformID, limit, offset)
+}
+
+// ListByClaimerCalls gets all the calls that were made to ListByClaimer.
+// Check the length with:
+//
+//	len(mockedClaimStore.ListByClaimerCalls())
+func (mock *ClaimStoreMock) ListByClaimerCalls() []struct {
+	Ctx        context.Context
+	Platform   string
+	PlatformID string
+	Limit      int
+	Offset     int
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Platform   string
+		PlatformID string
+		Limit      int
+		Offset     int
+	}
+	mock.lockListByClaimer.RLock()
+	calls = mock.calls.ListByClaimer
+	mock.lockListByClaimer.RUnlock()
+	return calls
+}
+
+// ListByEnterprise calls ListByEnterpriseFunc.
+func (mock *ClaimStoreMock) ListByEnterprise(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.Claim, int64, error) {
+	if mock.ListByEnterpriseFunc == nil {
+		panic("ClaimStoreMock.ListByEnterpriseFunc: method is nil but ClaimStore.ListByEnterprise was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		EnterpriseID string
+		Limit        int
+		Offset       int
+	}{
+		Ctx:          ctx,
+		EnterpriseID: enterpriseID,
+		Limit:        limit,
+		Offset:       offset,
+	}
+	mock.lockListByEnterprise.Lock()
+	mock.calls.ListByEnterprise = append(mock.calls.ListByEnterprise, callInfo)
+	mock.lockListByEnterprise.Unlock()
+	return mock.ListByEnterpriseFunc(ctx, enterpriseID, limit, offset)
+}
+
+// ListByEnterpriseCalls gets all the calls that were made to ListByEnterprise.
+// Check the length with:
+//
+//	len(mockedClaimStore.ListByEnterpriseCalls())
+func (mock *ClaimStoreMock) ListByEnterpriseCalls() []struct {
+	Ctx          context.Context
+	EnterpriseID string
+	Limit        int
+	Offset       int
+} {
+	var calls []struct {
+		Ctx          context.Context
+		EnterpriseID string
+		Limit        int
+		Offset       int
+	}
+	mock.lockListByEnterprise.RLock()
+	calls = mock.calls.ListByEnterprise
+	mock.lockListByEnterprise.RUnlock()
+	return calls
+}
+
+// ListByRedPocket calls ListByRedPocketFunc.
+func (mock *ClaimStoreMock) ListByRedPocket(ctx context.Context, redPocketID string, limit int, offset int) ([]*model.Claim, error) {
+	if mock.ListByRedPocketFunc == nil {
+		panic("ClaimStoreMock.ListByRedPocketFunc: method is nil but ClaimStore.ListByRedPocket was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		RedPocketID string
+		Limit       int
+		Offset      int
+	}{
+		Ctx:         ctx,
+		RedPocketID: redPocketID,
+		Limit:       limit,
+		Offset:      offset,
+	}
+	mock.lockListByRedPocket.Lock()
+	mock.calls.ListByRedPocket = append(mock.calls.ListByRedPocket, callInfo)
+	mock.lockListByRedPocket.Unlock()
+	return mock.ListByRedPocketFunc(ctx, redPocketID, limit, offset)
+}
+
+// ListByRedPocketCalls gets all the calls that were made to ListByRedPocket.
+// Check the length with:
+//
+//	len(mockedClaimStore.ListByRedPocketCalls())
+func (mock *ClaimStoreMock) ListByRedPocketCalls() []struct {
+	Ctx         context.Context
+	RedPocketID string
+	Limit       int
+	Offset      int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		RedPocketID string
+		Limit       int
+		Offset      int
+	}
+	mock.lockListByRedPocket.RLock()
+	calls = mock.calls.ListByRedPocket
+	mock.lockListByRedPocket.RUnlock()
+	return calls
+}
+
+// MarkResubmitted calls MarkResubmittedFunc.
+func (mock *ClaimStoreMock) MarkResubmitted(ctx context.Context, id string, attempts int, reason string) (bool, error) {
+	if mock.MarkResubmittedFunc == nil {
+		panic("ClaimStoreMock.MarkResubmittedFunc: method is nil but ClaimStore.MarkResubmitted was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		ID       string
+		Attempts int
+		Reason   string
+	}{
+		Ctx:      ctx,
+		ID:       id,
+		Attempts: attempts,
+		Reason:   reason,
+	}
+	mock.lockMarkResubmitted.Lock()
+	mock.calls.MarkResubmitted = append(mock.calls.MarkResubmitted, callInfo)
+	mock.lockMarkResubmitted.Unlock()
+	return mock.MarkResubmittedFunc(ctx, id, attempts, reason)
+}
+
+// MarkResubmittedCalls gets all the calls that were made to MarkResubmitted.
+// Check the length with:
+//
+//	len(mockedClaimStore.MarkResubmittedCalls())
+func (mock *ClaimStoreMock) MarkResubmittedCalls() []struct {
+	Ctx      context.Context
+	ID       string
+	Attempts int
+	Reason   string
+} {
+	var calls []struct {
+		Ctx      context.Context
+		ID       string
+		Attempts int
+		Reason   string
+	}
+	mock.lockMarkResubmitted.RLock()
+	calls = mock.calls.MarkResubmitted
+	mock.lockMarkResubmitted.RUnlock()
+	return calls
+}
+
+// MarkResubmittedByBatch calls MarkResubmittedByBatchFunc.
+func (mock *ClaimStoreMock) MarkResubmittedByBatch(ctx context.Context, batchID string, attempts int, reason string) error {
+	if mock.MarkResubmittedByBatchFunc == nil {
+		panic("ClaimStoreMock.MarkResubmittedByBatchFunc: method is nil but ClaimStore.MarkResubmittedByBatch was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		BatchID  string
+		Attempts int
+		Reason   string
+	}{
+		Ctx:      ctx,
+		BatchID:  batchID,
+		Attempts: attempts,
+		Reason:   reason,
+	}
+	mock.lockMarkResubmittedByBatch.Lock()
+	mock.calls.MarkResubmittedByBatch = append(mock.calls.MarkResubmittedByBatch, callInfo)
+	mock.lockMarkResubmittedByBatch.Unlock()
+	return mock.MarkResubmittedByBatchFunc(ctx, batchID, attempts, reason)
+}
+
+// MarkResubmittedByBatchCalls gets all the calls that were made to MarkResubmittedByBatch.
+// Check the length with:
+//
+//	len(mockedClaimStore.MarkResubmittedByBatchCalls())
+func (mock *ClaimStoreMock) MarkResubmittedByBatchCalls() []struct {
+	Ctx      context.Context
+	BatchID  string
+	Attempts int
+	Reason   string
+} {
+	var calls []struct {
+		Ctx      context.Context
+		BatchID  string
+		Attempts int
+		Reason   string
+	}
+	mock.lockMarkResubmittedByBatch.RLock()
+	calls = mock.calls.MarkResubmittedByBatch
+	mock.lockMarkResubmittedByBatch.RUnlock()
+	return calls
+}
+
+// SetScreening calls SetScreeningFunc.
+func (mock *ClaimStoreMock) SetScreening(ctx context.Context, id string, screeningID string, result string) error {
+	if mock.SetScreeningFunc == nil {
+		panic("ClaimStoreMock.SetScreeningFunc: method is nil but ClaimStore.SetScreening was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		ID          string
+		ScreeningID string
+		Result      string
+	}{
+		Ctx:         ctx,
+		ID:          id,
+		ScreeningID: screeningID,
+		Result:      result,
+	}
+	mock.lockSetScreening.Lock()
+	mock.calls.SetScreening = append(mock.calls.SetScreening, callInfo)
+	mock.lockSetScreening.Unlock()
+	return mock.SetScreeningFunc(ctx, id, screeningID, result)
+}
+
+// SetScreeningCalls gets all the calls that were made to SetScreening.
+// Check the length with:
+//
+//	len(mockedClaimStore.SetScreeningCalls())
+func (mock *ClaimStoreMock) SetScreeningCalls() []struct {
+	Ctx         context.Context
+	ID          string
+	ScreeningID string
+	Result      string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		ID          string
+		ScreeningID string
+		Result      string
+	}
+	mock.lockSetScreening.RLock()
+	calls = mock.calls.SetScreening
+	mock.lockSetScreening.RUnlock()
+	return calls
+}
+
+// Transition calls TransitionFunc.
+func (mock *ClaimStoreMock) Transition(ctx context.Context, id string, to model.ClaimStatus, txHash string, reason string) (bool, error) {
+	if mock.TransitionFunc == nil {
+		panic("ClaimStoreMock.TransitionFunc: method is nil but ClaimStore.Transition was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		ID     string
+		To     model.ClaimStatus
+		TxHash string
+		Reason string
+	}{
+		Ctx:    ctx,
+		ID:     id,
+		To:     to,
+		TxHash: txHash,
+		Reason: reason,
+	}
+	mock.lockTransition.Lock()
+	mock.calls.Transition = append(mock.calls.Transition, callInfo)
+	mock.lockTransition.Unlock()
+	return mock.TransitionFunc(ctx, id, to, txHash, reason)
+}
+
+// TransitionCalls gets all the calls that were made to Transition.
+// Check the length with:
+//
+//	len(mockedClaimStore.TransitionCalls())
+func (mock *ClaimStoreMock) TransitionCalls() []struct {
+	Ctx    context.Context
+	ID     string
+	To     model.ClaimStatus
+	TxHash string
+	Reason string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		ID     string
+		To     model.ClaimStatus
+		TxHash string
+		Reason string
+	}
+	mock.lockTransition.RLock()
+	calls = mock.calls.Transition
+	mock.lockTransition.RUnlock()
+	return calls
+}
+
+// TransitionBatch calls TransitionBatchFunc.
+func (mock *ClaimStoreMock) TransitionBatch(ctx context.Context, batchID string, to model.ClaimStatus, txHash string, reason string) (int, error) {
+	if mock.TransitionBatchFunc == nil {
+		panic("ClaimStoreMock.TransitionBatchFunc: method is nil but ClaimStore.TransitionBatch was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		BatchID string
+		To      model.ClaimStatus
+		TxHash  string
+		Reason  string
+	}{
+		Ctx:     ctx,
+		BatchID: batchID,
+		To:      to,
+		TxHash:  txHash,
+		Reason:  reason,
+	}
+	mock.lockTransitionBatch.Lock()
+	mock.calls.TransitionBatch = append(mock.calls.TransitionBatch, callInfo)
+	mock.lockTransitionBatch.Unlock()
+	return mock.TransitionBatchFunc(ctx, batchID, to, txHash, reason)
+}
+
+// TransitionBatchCalls gets all the calls that were made to TransitionBatch.
+// Check the length with:
+//
+//	len(mockedClaimStore.TransitionBatchCalls())
+func (mock *ClaimStoreMock) TransitionBatchCalls() []struct {
+	Ctx     context.Context
+	BatchID string
+	To      model.ClaimStatus
+	TxHash  string
+	Reason  string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		BatchID string
+		To      model.ClaimStatus
+		TxHash  string
+		Reason  string
+	}
+	mock.lockTransitionBatch.RLock()
+	calls = mock.calls.TransitionBatch
+	mock.lockTransitionBatch.RUnlock()
+	return calls
+}
+
+// Ensure, that WalletStoreMock does implement repository.WalletStore.
+// If this is not the case, regenerate this file with moq.
+var _ repository.WalletStore = &WalletStoreMock{}
+
+// WalletStoreMock is a mock implementation of repository.WalletStore.
+//
+//	func TestSomethingThatUsesWalletStore(t *testing.T) {
+//
+//		// make and configure a mocked repository.WalletStore
+//		mockedWalletStore := &WalletStoreMock{
+//			CreateFunc: func(ctx context.Context, w *model.Wallet) error {
+//				panic("mock out the Create method")
+//			},
+//			GetByAddressFunc: func(ctx context.Context, address string) (*model.Wallet, error) {
+//				panic("mock out the GetByAddress method")
+//			},
+//			GetByUserIDFunc: func(ctx context.Context, userID string, chainID int64) (*model.Wallet, error) {
+//				panic("mock out the GetByUserID method")
+//			},
+//			ListByUserFunc: func(ctx context.Context, userID string) ([]*model.Wallet, error) {
+//				panic("mock out the ListByUser method")
+//			},
+//			UpdateDeployedFunc: func(ctx context.Context, id string, deployed bool) error {
+//				panic("mock out the UpdateDeployed method")
+//			},
+//		}
+//
+//		// use mockedWalletStore in code that requires repository.WalletStore
+//		// and then make assertions.
+//
+//	}
+type WalletStoreMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, w *model.Wallet) error
+
+	// GetByAddressFunc mocks the GetByAddress method.
+	GetByAddressFunc func(ctx context.Context, address string) (*model.Wallet, error)
+
+	// GetByUserIDFunc mocks the GetByUserID method.
+	GetByUserIDFunc func(ctx context.Context, userID string, chainID int64) (*model.Wallet, error)
+
+	// ListByUserFunc mocks the ListByUser method.
+	ListByUserFunc func(ctx context.Context, userID string) ([]*model.Wallet, error)
+
+	// UpdateDeployedFunc mocks the UpdateDeployed method.
+	UpdateDeployedFunc func(ctx context.Context, id string, deployed bool) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// W is the w argument value.
+			W *model.Wallet
+		}
+		// GetByAddress holds details about calls to the GetByAddress method.
+		GetByAddress []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Address is the address argument value.
+			Address string
+		}
+		// GetByUserID holds details about calls to the GetByUserID method.
+		GetByUserID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserID is the userID argument value.
+			UserID string
+			// ChainID is the chainID argument value.
+			ChainID int64
+		}
+		// ListByUser holds details about calls to the ListByUser method.
+		ListByUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserID is the userID argument value.
+			UserID string
+		}
+		// UpdateDeployed holds details about calls to the UpdateDeployed method.
+		UpdateDeployed []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// Deployed is the deployed argument value.
+			Deployed bool
+		}
+	}
+	lockCreate         sync.RWMutex
+	lockGetByAddress   sync.RWMutex
+	lockGetByUserID    sync.RWMutex
+	lockListByUser     sync.RWMutex
+	lockUpdateDeployed sync.RWMutex
+}
+
+// Create calls CreateFunc.
+func (mock *WalletStoreMock) Create(ctx context.Context, w *model.Wallet) error {
+	if mock.CreateFunc == nil {
+		panic("WalletStoreMock.CreateFunc: method is nil but WalletStore.Create was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		W   *model.Wallet
+	}{
+		Ctx: ctx,
+		W:   w,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(ctx, w)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedWalletStore.CreateCalls())
+func (mock *WalletStoreMock) CreateCalls() []struct {
+	Ctx context.Context
+	W   *model.Wallet
+} {
+	var calls []struct {
+		Ctx context.Context
+		W   *model.Wallet
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// GetByAddress calls GetByAddressFunc.
+func (mock *WalletStoreMock) GetByAddress(ctx context.Context, address string) (*model.Wallet, error) {
+	if mock.GetByAddressFunc == nil {
+		panic("WalletStoreMock.GetByAddressFunc: method is nil but WalletStore.GetByAddress was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Address string
+	}{
+		Ctx:     ctx,
+		Address: address,
+	}
+	mock.lockGetByAddress.Lock()
+	mock.calls.GetByAddress = append(mock.calls.GetByAddress, callInfo)
+	mock.lockGetByAddress.Unlock()
+	return mock.GetByAddressFunc(ctx, address)
+}
+
+// GetByAddressCalls gets all the calls that were made to GetByAddress.
+// Check the length with:
+//
+//	len(mockedWalletStore.GetByAddressCalls())
+func (mock *WalletStoreMock) GetByAddressCalls() []struct {
+	Ctx     context.Context
+	Address string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Address string
+	}
+	mock.lockGetByAddress.RLock()
+	calls = mock.calls.GetByAddress
+	mock.lockGetByAddress.RUnlock()
+	return calls
+}
+
+// GetByUserID calls GetByUserIDFunc.
+func (mock *WalletStoreMock) GetByUserID(ctx context.Context, userID string, chainID int64) (*model.Wallet, error) {
+	if mock.GetByUserIDFunc == nil {
+		panic("WalletStoreMock.GetByUserIDFunc: method is nil but WalletStore.GetByUserID was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		UserID  string
+		ChainID int64
+	}{
+		Ctx:     ctx,
+		UserID:  userID,
+		ChainID: chainID,
+	}
+	mock.lockGetByUserID.Lock()
+	mock.calls.GetByUserID = append(mock.calls.GetByUserID, callInfo)
+	mock.lockGetByUserID.Unlock()
+	return mock.GetByUserIDFunc(ctx, userID, chainID)
+}
+
+// GetByUserIDCalls gets all the calls that were made to GetByUserID.
+// Check the length with:
+//
+//	len(mockedWalletStore.GetByUserIDCalls())
+func (mock *WalletStoreMock) GetByUserIDCalls() []struct {
+	Ctx     context.Context
+	UserID  string
+	ChainID int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		UserID  string
+		ChainID int64
+	}
+	mock.lockGetByUserID.RLock()
+	calls = mock.calls.GetByUserID
+	mock.lockGetByUserID.RUnlock()
+	return calls
+}
+
+// ListByUser calls ListByUserFunc.
+func (mock *WalletStoreMock) ListByUser(ctx context.Context, userID string) ([]*model.Wallet, error) {
+	if mock.ListByUserFunc == nil {
+		panic("WalletStoreMock.ListByUserFunc: method is nil but WalletStore.ListByUser was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		UserID string
+	}{
+		Ctx:    ctx,
+		UserID: userID,
+	}
+	mock.lockListByUser.Lock()
+	mock.calls.ListByUser = append(mock.calls.ListByUser, callInfo)
+	mock.lockListByUser.Unlock()
+	return mock.ListByUserFunc(ctx, userID)
+}
+
+// ListByUserCalls gets all the calls that were made to ListByUser.
+// Check the length with:
+//
+//	len(mockedWalletStore.ListByUserCalls())
+func (mock *WalletStoreMock) ListByUserCalls() []struct {
+	Ctx    context.Context
+	UserID string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		UserID string
+	}
+	mock.lockListByUser.RLock()
+	calls = mock.calls.ListByUser
+	mock.lockListByUser.RUnlock()
+	return calls
+}
+
+// UpdateDeployed calls UpdateDeployedFunc.
+func (mock *WalletStoreMock) UpdateDeployed(ctx context.Context, id string, deployed bool) error {
+	if mock.UpdateDeployedFunc == nil {
+		panic("WalletStoreMock.UpdateDeployedFunc: method is nil but WalletStore.UpdateDeployed was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		ID       string
+		Deployed bool
+	}{
+		Ctx:      ctx,
+		ID:       id,
+		Deployed: deployed,
+	}
+	mock.lockUpdateDeployed.Lock()
+	mock.calls.UpdateDeployed = append(mock.calls.UpdateDeployed, callInfo)
+	mock.lockUpdateDeployed.Unlock()
+	return mock.UpdateDeployedFunc(ctx, id, deployed)
+}
+
+// UpdateDeployedCalls gets all the calls that were made to UpdateDeployed.
+// Check the length with:
+//
+//	len(mockedWalletStore.UpdateDeployedCalls())
+func (mock *WalletStoreMock) UpdateDeployedCalls() []struct {
+	Ctx      context.Context
+	ID       string
+	Deployed bool
+} {
+	var calls []struct {
+		Ctx      context.Context
+		ID       string
+		Deployed bool
+	}
+	mock.lockUpdateDeployed.RLock()
+	calls = mock.calls.UpdateDeployed
+	mock.lockUpdateDeployed.RUnlock()
+	return calls
+}
+
+// Ensure, that CampaignStoreMock does implement repository.CampaignStore.
+// If this is not the case, regenerate this file with moq.
+var _ repository.CampaignStore = &CampaignStoreMock{}
+
+// CampaignStoreMock is a mock implementation of repository.CampaignStore.
+//
+//	func TestSomethingThatUsesCampaignStore(t *testing.T) {
+//
+//		// make and configure a mocked repository.CampaignStore
+//		mockedCampaignStore := &CampaignStoreMock{
+//			CreateFunc: func(ctx context.Context, c *model.Campaign) error {
+//				panic("mock out the Create method")
+//			},
+//			DeleteFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the Delete method")
+//			},
+//			EnterpriseTestModeFunc: func(ctx context.Context, enterpriseID string) (bool, error) {
+//				panic("mock out the EnterpriseTestMode method")
+//			},
+//			GetAnalyticsFunc: func(ctx context.Context, enterpriseID string) (*model.CampaignAnalytics, error) {
+//				panic("mock out the GetAnalytics method")
+//			},
+//			GetByIDFunc: func(ctx context.Context, id string) (*model.Campaign, error) {
+//				panic("mock out the GetByID method")
+//			},
+//			GetCharityFunc: func(ctx context.Context, id string) (*model.CampaignCharity, error) {
+//				panic("mock out the GetCharity method")
+//			},
+//			GetClaimPageFunc: func(ctx context.Context, id string) (*model.ClaimPageConfig, error) {
+//				panic("mock out the GetClaimPage method")
+//			},
+//			GetClaimWeightsFunc: func(ctx context.Context, id string) (*model.CampaignClaimWeights, error) {
+//				panic("mock out the GetClaimWeights method")
+//			},
+//			GetFeePlanFunc: func(ctx context.Context, id string) (string, error) {
+//				panic("mock out the GetFeePlan method")
+//			},
+//			GetFeeScheduleFunc: func(ctx context.Context, plan string) (*model.PlatformFeeSchedule, error) {
+//				panic("mock out the GetFeeSchedule method")
+//			},
+//			GetTermsFunc: func(ctx context.Context, id string) (*model.CampaignTerms, error) {
+//				panic("mock out the GetTerms method")
+//			},
+//			GetVisibilityFunc: func(ctx context.Context, id string) (*model.CampaignVisibility, error) {
+//				panic("mock out the GetVisibility method")
+//			},
+//			GrantFaucetFunc: func(ctx context.Context, g *model.FaucetGrant, dailyGrants int) (*model.Campaign, bool, error) {
+//				panic("mock out the GrantFaucet method")
+//			},
+//			IncrementPocketsFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the IncrementPockets method")
+//			},
+//			IncrementStatsFunc: func(ctx context.Context, id string, spentAmount float64, claimsCount int) error {
+//				panic("mock out the IncrementStats method")
+//			},
+//			ListByEnterpriseFunc: func(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.Campaign, int64, error) {
+//				panic("mock out the ListByEnterprise method")
+//			},
+//			SetCharityFunc: func(ctx context.Context, id string, c *model.CampaignCharity) error {
+//				panic("mock out the SetCharity method")
+//			},
+//			SetClaimPageFunc: func(ctx context.Context, id string, page *model.ClaimPageConfig) error {
+//				panic("mock out the SetClaimPage method")
+//			},
+//			SetClaimWeightsFunc: func(ctx context.Context, id string, w *model.CampaignClaimWeights) error {
+//				panic("mock out the SetClaimWeights method")
+//			},
+//			SetTermsFunc: func(ctx context.Context, id string, t *model.CampaignTerms) error {
+//				panic("mock out the SetTerms method")
+//			},
+//			SetVisibilityFunc: func(ctx context.Context, id string, v *model.CampaignVisibility) error {
+//				panic("mock out the SetVisibility method")
+//			},
+//			TestModeFunc: func(ctx context.Context, id string) (bool, error) {
+//				panic("mock out the TestMode method")
+//			},
+//			TotalDonatedFunc: func(ctx context.Context, id string) (float64, error) {
+//				panic("mock out the TotalDonated method")
+//			},
+//			UpdateFunc: func(ctx context.Context, c *model.Campaign) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedCampaignStore in code that requires repository.CampaignStore
+//		// and then make assertions.
+//
+//	}
+type CampaignStoreMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, c *model.Campaign) error
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, id string) error
+
+	// EnterpriseTestModeFunc mocks the EnterpriseTestMode method.
+	EnterpriseTestModeFunc func(ctx context.Context, enterpriseID string) (bool, error)
+
+	// GetAnalyticsFunc mocks the GetAnalytics method.
+	GetAnalyticsFunc func(ctx context.Context, enterpriseID string) (*model.CampaignAnalytics, error)
+
+	// GetByIDFunc mocks the GetByID method.
+	GetByIDFunc func(ctx context.Context, id string) (*model.Campaign, error)
+
+	// GetCharityFunc mocks the GetCharity method.
+	GetCharityFunc func(ctx context.Context, id string) (*model.CampaignCharity, error)
+
+	// GetClaimPageFunc mocks the GetClaimPage method.
+	GetClaimPageFunc func(ctx context.Context, id string) (*model.ClaimPageConfig, error)
+
+	// GetClaimWeightsFunc mocks the GetClaimWeights method.
+	GetClaimWeightsFunc func(ctx context.Context, id string) (*model.CampaignClaimWeights, error)
+
+	// GetFeePlanFunc mocks the GetFeePlan method.
+	GetFeePlanFunc func(ctx context.Context, id string) (string, error)
+
+	// GetFeeScheduleFunc mocks the GetFeeSchedule method.
+	GetFeeScheduleFunc func(ctx context.Context, plan string) (*model.PlatformFeeSchedule, error)
+
+	// GetTermsFunc mocks the GetTerms method.
+	GetTermsFunc func(ctx context.Context, id string) (*model.CampaignTerms, error)
+
+	// GetVisibilityFunc mocks the GetVisibility method.
+	GetVisibilityFunc func(ctx context.Context, id string) (*model.CampaignVisibility, error)
+
+	// GrantFaucetFunc mocks the GrantFaucet method.
+	GrantFaucetFunc func(ctx context.Context, g *model.FaucetGrant, dailyGrants int) (*model.Campaign, bool, error)
+
+	// IncrementPocketsFunc mocks the IncrementPockets method.
+	IncrementPocketsFunc func(ctx context.Context, id string) error
+
+	// IncrementStatsFunc mocks the IncrementStats method.
+	IncrementStatsFunc func(ctx context.Context, id string, spentAmount float64, claimsCount int) error
+
+	// ListByEnterpriseFunc mocks the ListByEnterprise method.
+	ListByEnterpriseFunc func(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.Campaign, int64, error)
+
+	// SetCharityFunc mocks the SetCharity method.
+	SetCharityFunc func(ctx context.Context, id string, c *model.CampaignCharity) error
+
+	// SetClaimPageFunc mocks the SetClaimPage method.
+	SetClaimPageFunc func(ctx context.Context, id string, page *model.ClaimPageConfig) error
+
+	// SetClaimWeightsFunc mocks the SetClaimWeights method.
+	SetClaimWeightsFunc func(ctx context.Context, id string, w *model.CampaignClaimWeights) error
+
+	// SetTermsFunc mocks the SetTerms method.
+	SetTermsFunc func(ctx context.Context, id string, t *model.CampaignTerms) error
+
+	// SetVisibilityFunc mocks the SetVisibility method.
+	SetVisibilityFunc func(ctx context.Context, id string, v *model.CampaignVisibility) error
+
+	// TestModeFunc mocks the TestMode method.
+	TestModeFunc func(ctx context.Context, id string) (bool, error)
+
+	// TotalDonatedFunc mocks the TotalDonated method.
+	TotalDonatedFunc func(ctx context.Context, id string) (float64, error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(ctx context.Context, c *model.Campaign) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// C is the c argument value.
+			C *model.Campaign
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// EnterpriseTestMode holds details about calls to the EnterpriseTestMode method.
+		EnterpriseTestMode []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EnterpriseID is the enterpriseID argument value.
+			EnterpriseID string
+		}
+		// GetAnalytics holds details about calls to the GetAnalytics method.
+		GetAnalytics []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EnterpriseID is the enterpriseID argument value.
+			EnterpriseID string
+		}
+		// GetByID holds details about calls to the GetByID method.
+		GetByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetCharity holds details about calls to the GetCharity method.
+		GetCharity []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetClaimPage holds details about calls to the GetClaimPage method.
+		GetClaimPage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetClaimWeights holds details about calls to the GetClaimWeights method.
+		GetClaimWeights []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetFeePlan holds details about calls to the GetFeePlan method.
+		GetFeePlan []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetFeeSchedule holds details about calls to the GetFeeSchedule method.
+		GetFeeSchedule []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Plan is the plan argument value.
+			Plan string
+		}
+		// GetTerms holds details about calls to the GetTerms method.
+		GetTerms []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetVisibility holds details about calls to the GetVisibility method.
+		GetVisibility []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GrantFaucet holds details about calls to the GrantFaucet method.
+		GrantFaucet []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// G is the g argument value.
+			G *model.FaucetGrant
+			// DailyGrants is the dailyGrants argument value.
+			DailyGrants int
+		}
+		// IncrementPockets holds details about calls to the IncrementPockets method.
+		IncrementPockets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// IncrementStats holds details about calls to the IncrementStats method.
+		IncrementStats []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// SpentAmount is the spentAmount argument value.
+			SpentAmount float64
+			// ClaimsCount is the claimsCount argument value.
+			ClaimsCount int
+		}
+		// ListByEnterprise holds details about calls to the ListByEnterprise method.
+		ListByEnterprise []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EnterpriseID is the enterpriseID argument value.
+			EnterpriseID string
+			// Limit is the limit argument value.
+			Limit int
+			// Offset is the offset argument value.
+			Offset int
+		}
+		// SetCharity holds details about calls to the SetCharity method.
+		SetCharity []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// C is the c argument value.
+			C *model.CampaignCharity
+		}
+		// SetClaimPage holds details about calls to the SetClaimPage method.
+		SetClaimPage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// Page is the page argument value.
+			Page *model.ClaimPageConfig
+		}
+		// SetClaimWeights holds details about calls to the SetClaimWeights method.
+		SetClaimWeights []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// W is the w argument value.
+			W *model.CampaignClaimWeights
+		}
+		// SetTerms holds details about calls to the SetTerms method.
+		SetTerms []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// T is the t argument value.
+			T *model.CampaignTerms
+		}
+		// SetVisibility holds details about calls to the SetVisibility method.
+		SetVisibility []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// V is the v argument value.
+			V *model.CampaignVisibility
+		}
+		// TestMode holds details about calls to the TestMode method.
+		TestMode []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// TotalDonated holds details about calls to the TotalDonated method.
+		TotalDonated []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// C is the c argument value.
+			C *model.Campaign
+		}
+	}
+	lockCreate             sync.RWMutex
+	lockDelete             sync.RWMutex
+	lockEnterpriseTestMode sync.RWMutex
+	lockGetAnalytics       sync.RWMutex
+	lockGetByID            sync.RWMutex
+	lockGetCharity         sync.RWMutex
+	lockGetClaimPage       sync.RWMutex
+	lockGetClaimWeights    sync.RWMutex
+	lockGetFeePlan         sync.RWMutex
+	lockGetFeeSchedule     sync.RWMutex
+	lockGetTerms           sync.RWMutex
+	lockGetVisibility      sync.RWMutex
+	lockGrantFaucet        sync.RWMutex
+	lockIncrementPockets   sync.RWMutex
+	lockIncrementStats     sync.RWMutex
+	lockListByEnterprise   sync.RWMutex
+	lockSetCharity         sync.RWMutex
+	lockSetClaimPage       sync.RWMutex
+	lockSetClaimWeights    sync.RWMutex
+	lockSetTerms           sync.RWMutex
+	lockSetVisibility      sync.RWMutex
+	lockTestMode           sync.RWMutex
+	lockTotalDonated       sync.RWMutex
+	lockUpdate             sync.RWMutex
+}
+
+// Create calls CreateFunc.
+func (mock *CampaignStoreMock) Create(ctx context.Context, c *model.Campaign) error {
+	if mock.CreateFunc == nil {
+		panic("CampaignStoreMock.CreateFunc: method is nil but CampaignStore.Create was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		C   *model.Campaign
+	}{
+		Ctx: ctx,
+		C:   c,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(ctx, c)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedCampaignStore.CreateCalls())
+func (mock *CampaignStoreMock) CreateCalls() []struct {
+	Ctx context.Context
+	C   *model.Campaign
+} {
+	var calls []struct {
+		Ctx context.Context
+		C   *model.Campaign
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *CampaignStoreMock) Delete(ctx context.Context, id string) error {
+	if mock.DeleteFunc == nil {
+		panic("CampaignStoreMock.DeleteFunc: method is nil but CampaignStore.Delete was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(ctx, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedCampaignStore.DeleteCalls())
+func (mock *CampaignStoreMock) DeleteCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// EnterpriseTestMode calls EnterpriseTestModeFunc.
+func (mock *CampaignStoreMock) EnterpriseTestMode(ctx context.Context, enterpriseID string) (bool, error) {
+	if mock.EnterpriseTestModeFunc == nil {
+		panic("CampaignStoreMock.EnterpriseTestModeFunc: method is nil but CampaignStore.EnterpriseTestMode was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		EnterpriseID string
+	}{
+		Ctx:          ctx,
+		EnterpriseID: enterpriseID,
+	}
+	mock.lockEnterpriseTestMode.Lock()
+	mock.calls.EnterpriseTestMode = append(mock.calls.EnterpriseTestMode, callInfo)
+	mock.lockEnterpriseTestMode.Unlock()
+	return mock.EnterpriseTestModeFunc(ctx, enterpriseID)
+}
+
+// EnterpriseTestModeCalls gets all the calls that were made to EnterpriseTestMode.
+// Check the length with:
+//
+//	len(mockedCampaignStore.EnterpriseTestModeCalls())
+func (mock *CampaignStoreMock) EnterpriseTestModeCalls() []struct {
+	Ctx          context.Context
+	EnterpriseID string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		EnterpriseID string
+	}
+	mock.lockEnterpriseTestMode.RLock()
+	calls = mock.calls.EnterpriseTestMode
+	mock.lockEnterpriseTestMode.RUnlock()
+	return calls
+}
+
+// GetAnalytics calls GetAnalyticsFunc.
+func (mock *CampaignStoreMock) GetAnalytics(ctx context.Context, enterpriseID string) (*model.CampaignAnalytics, error) {
+	if mock.GetAnalyticsFunc == nil {
+		panic("CampaignStoreMock.GetAnalyticsFunc: method is nil but CampaignStore.GetAnalytics was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		EnterpriseID string
+	}{
+		Ctx:          ctx,
+		EnterpriseID: enterpriseID,
+	}
+	mock.lockGetAnalytics.Lock()
+	mock.calls.GetAnalytics = append(mock.calls.GetAnalytics, callInfo)
+	mock.lockGetAnalytics.Unlock()
+	return mock.GetAnalyticsFunc(ctx, enterpriseID)
+}
+
+// GetAnalyticsCalls gets all the calls that were made to GetAnalytics.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetAnalyticsCalls())
+func (mock *CampaignStoreMock) GetAnalyticsCalls() []struct {
+	Ctx          context.Context
+	EnterpriseID string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		EnterpriseID string
+	}
+	mock.lockGetAnalytics.RLock()
+	calls = mock.calls.GetAnalytics
+	mock.lockGetAnalytics.RUnlock()
+	return calls
+}
+
+// GetByID calls GetByIDFunc.
+func (mock *CampaignStoreMock) GetByID(ctx context.Context, id string) (*model.Campaign, error) {
+	if mock.GetByIDFunc == nil {
+		panic("CampaignStoreMock.GetByIDFunc: method is nil but CampaignStore.GetByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetByID.Lock()
+	mock.calls.GetByID = append(mock.calls.GetByID, callInfo)
+	mock.lockGetByID.Unlock()
+	return mock.GetByIDFunc(ctx, id)
+}
+
+// GetByIDCalls gets all the calls that were made to GetByID.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetByIDCalls())
+func (mock *CampaignStoreMock) GetByIDCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetByID.RLock()
+	calls = mock.calls.GetByID
+	mock.lockGetByID.RUnlock()
+	return calls
+}
+
+// GetCharity calls GetCharityFunc.
+func (mock *CampaignStoreMock) GetCharity(ctx context.Context, id string) (*model.CampaignCharity, error) {
+	if mock.GetCharityFunc == nil {
+		panic("CampaignStoreMock.GetCharityFunc: method is nil but CampaignStore.GetCharity was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetCharity.Lock()
+	mock.calls.GetCharity = append(mock.calls.GetCharity, callInfo)
+	mock.lockGetCharity.Unlock()
+	return mock.GetCharityFunc(ctx, id)
+}
+
+// GetCharityCalls gets all the calls that were made to GetCharity.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetCharityCalls())
+func (mock *CampaignStoreMock) GetCharityCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetCharity.RLock()
+	calls = mock.calls.GetCharity
+	mock.lockGetCharity.RUnlock()
+	return calls
+}
+
+// GetClaimPage calls GetClaimPageFunc.
+func (mock *CampaignStoreMock) GetClaimPage(ctx context.Context, id string) (*model.ClaimPageConfig, error) {
+	if mock.GetClaimPageFunc == nil {
+		panic("CampaignStoreMock.GetClaimPageFunc: method is nil but CampaignStore.GetClaimPage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetClaimPage.Lock()
+	mock.calls.GetClaimPage = append(mock.calls.GetClaimPage, callInfo)
+	mock.lockGetClaimPage.Unlock()
+	return mock.GetClaimPageFunc(ctx, id)
+}
+
+// GetClaimPageCalls gets all the calls that were made to GetClaimPage.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetClaimPageCalls())
+func (mock *CampaignStoreMock) GetClaimPageCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetClaimPage.RLock()
+	calls = mock.calls.GetClaimPage
+	mock.lockGetClaimPage.RUnlock()
+	return calls
+}
+
+// GetClaimWeights calls GetClaimWeightsFunc.
+func (mock *CampaignStoreMock) GetClaimWeights(ctx context.Context, id string) (*model.CampaignClaimWeights, error) {
+	if mock.GetClaimWeightsFunc == nil {
+		panic("CampaignStoreMock.GetClaimWeightsFunc: method is nil but CampaignStore.GetClaimWeights was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetClaimWeights.Lock()
+	mock.calls.GetClaimWeights = append(mock.calls.GetClaimWeights, callInfo)
+	mock.lockGetClaimWeights.Unlock()
+	return mock.GetClaimWeightsFunc(ctx, id)
+}
+
+// GetClaimWeightsCalls gets all the calls that were made to GetClaimWeights.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetClaimWeightsCalls())
+func (mock *CampaignStoreMock) GetClaimWeightsCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetClaimWeights.RLock()
+	calls = mock.calls.GetClaimWeights
+	mock.lockGetClaimWeights.RUnlock()
+	return calls
+}
+
+// GetFeePlan calls GetFeePlanFunc.
+func (mock *CampaignStoreMock) GetFeePlan(ctx context.Context, id string) (string, error) {
+	if mock.GetFeePlanFunc == nil {
+		panic("CampaignStoreMock.GetFeePlanFunc: method is nil but CampaignStore.GetFeePlan was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetFeePlan.Lock()
+	mock.calls.GetFeePlan = append(mock.calls.GetFeePlan, callInfo)
+	mock.lockGetFeePlan.Unlock()
+	return mock.GetFeePlanFunc(ctx, id)
+}
+
+// GetFeePlanCalls gets all the calls that were made to GetFeePlan.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetFeePlanCalls())
+func (mock *CampaignStoreMock) GetFeePlanCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetFeePlan.RLock()
+	calls = mock.calls.GetFeePlan
+	mock.lockGetFeePlan.RUnlock()
+	return calls
+}
+
+// GetFeeSchedule calls GetFeeScheduleFunc.
+func (mock *CampaignStoreMock) GetFeeSchedule(ctx context.Context, plan string) (*model.PlatformFeeSchedule, error) {
+	if mock.GetFeeScheduleFunc == nil {
+		panic("CampaignStoreMock.GetFeeScheduleFunc: method is nil but CampaignStore.GetFeeSchedule was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Plan string
+	}{
+		Ctx:  ctx,
+		Plan: plan,
+	}
+	mock.lockGetFeeSchedule.Lock()
+	mock.calls.GetFeeSchedule = append(mock.calls.GetFeeSchedule, callInfo)
+	mock.lockGetFeeSchedule.Unlock()
+	return mock.GetFeeScheduleFunc(ctx, plan)
+}
+
+// GetFeeScheduleCalls gets all the calls that were made to GetFeeSchedule.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetFeeScheduleCalls())
+func (mock *CampaignStoreMock) GetFeeScheduleCalls() []struct {
+	Ctx  context.Context
+	Plan string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Plan string
+	}
+	mock.lockGetFeeSchedule.RLock()
+	calls = mock.calls.GetFeeSchedule
+	mock.lockGetFeeSchedule.RUnlock()
+	return calls
+}
+
+// GetTerms calls GetTermsFunc.
+func (mock *CampaignStoreMock) GetTerms(ctx context.Context, id string) (*model.CampaignTerms, error) {
+	if mock.GetTermsFunc == nil {
+		panic("CampaignStoreMock.GetTermsFunc: method is nil but CampaignStore.GetTerms was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetTerms.Lock()
+	mock.calls.GetTerms = append(mock.calls.GetTerms, callInfo)
+	mock.lockGetTerms.Unlock()
+	return mock.GetTermsFunc(ctx, id)
+}
+
+// GetTermsCalls gets all the calls that were made to GetTerms.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetTermsCalls())
+func (mock *CampaignStoreMock) GetTermsCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetTerms.RLock()
+	calls = mock.calls.GetTerms
+	mock.lockGetTerms.RUnlock()
+	return calls
+}
+
+// GetVisibility calls GetVisibilityFunc.
+func (mock *CampaignStoreMock) GetVisibility(ctx context.Context, id string) (*model.CampaignVisibility, error) {
+	if mock.GetVisibilityFunc == nil {
+		panic("CampaignStoreMock.GetVisibilityFunc: method is nil but CampaignStore.GetVisibility was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetVisibility.Lock()
+	mock.calls.GetVisibility = append(mock.calls.GetVisibility, callInfo)
+	mock.lockGetVisibility.Unlock()
+	return mock.GetVisibilityFunc(ctx, id)
+}
+
+// GetVisibilityCalls gets all the calls that were made to GetVisibility.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GetVisibilityCalls())
+func (mock *CampaignStoreMock) GetVisibilityCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetVisibility.RLock()
+	calls = mock.calls.GetVisibility
+	mock.lockGetVisibility.RUnlock()
+	return calls
+}
+
+// GrantFaucet calls GrantFaucetFunc.
+func (mock *CampaignStoreMock) GrantFaucet(ctx context.Context, g *model.FaucetGrant, dailyGrants int) (*model.Campaign, bool, error) {
+	if mock.GrantFaucetFunc == nil {
+		panic("CampaignStoreMock.GrantFaucetFunc: method is nil but CampaignStore.GrantFaucet was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		G           *model.FaucetGrant
+		DailyGrants int
+	}{
+		Ctx:         ctx,
+		G:           g,
+		DailyGrants: dailyGrants,
+	}
+	mock.lockGrantFaucet.Lock()
+	mock.calls.GrantFaucet = append(mock.calls.GrantFaucet, callInfo)
+	mock.lockGrantFaucet.Unlock()
+	return mock.GrantFaucetFunc(ctx, g, dailyGrants)
+}
+
+// GrantFaucetCalls gets all the calls that were made to GrantFaucet.
+// Check the length with:
+//
+//	len(mockedCampaignStore.GrantFaucetCalls())
+func (mock *CampaignStoreMock) GrantFaucetCalls() []struct {
+	Ctx         context.Context
+	G           *model.FaucetGrant
+	DailyGrants int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		G           *model.FaucetGrant
+		DailyGrants int
+	}
+	mock.lockGrantFaucet.RLock()
+	calls = mock.calls.GrantFaucet
+	mock.lockGrantFaucet.RUnlock()
+	return calls
+}
+
+// IncrementPockets calls IncrementPocketsFunc.
+func (mock *CampaignStoreMock) IncrementPockets(ctx context.Context, id string) error {
+	if mock.IncrementPocketsFunc == nil {
+		panic("CampaignStoreMock.IncrementPocketsFunc: method is nil but CampaignStore.IncrementPockets was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockIncrementPockets.Lock()
+	mock.calls.IncrementPockets = append(mock.calls.IncrementPockets, callInfo)
+	mock.lockIncrementPockets.Unlock()
+	return mock.IncrementPocketsFunc(ctx, id)
+}
+
+// IncrementPocketsCalls gets all the calls that were made to IncrementPockets.
+// Check the length with:
+//
+//	len(mockedCampaignStore.IncrementPocketsCalls())
+func (mock *CampaignStoreMock) IncrementPocketsCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockIncrementPockets.RLock()
+	calls = mock.calls.IncrementPockets
+	mock.lockIncrementPockets.RUnlock()
+	return calls
+}
+
+// IncrementStats calls IncrementStatsFunc.
+func (mock *CampaignStoreMock) IncrementStats(ctx context.Context, id string, spentAmount float64, claimsCount int) error {
+	if mock.IncrementStatsFunc == nil {
+		panic("CampaignStoreMock.IncrementStatsFunc: method is nil but CampaignStore.IncrementStats was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		ID          string
+		SpentAmount float64
+		ClaimsCount int
+	}{
+		Ctx:         ctx,
+		ID:          id,
+		SpentAmount: spentAmount,
+		ClaimsCount: claimsCount,
+	}
+	mock.lockIncrementStats.Lock()
+	mock.calls.IncrementStats = append(mock.calls.IncrementStats, callInfo)
+	mock.lockIncrementStats.Unlock()
+	return mock.IncrementStatsFunc(ctx, id, spentAmount, claimsCount)
+}
+
+// IncrementStatsCalls gets all the calls that were made to IncrementStats.
+// Check the length with:
+//
+//	len(mockedCampaignStore.IncrementStatsCalls())
+func (mock *CampaignStoreMock) IncrementStatsCalls() []struct {
+	Ctx         context.Context
+	ID          string
+	SpentAmount float64
+	ClaimsCount int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		ID          string
+		SpentAmount float64
+		ClaimsCount int
+	}
+	mock.lockIncrementStats.RLock()
+	calls = mock.calls.IncrementStats
+	mock.lockIncrementStats.RUnlock()
+	return calls
+}
+
+// ListByEnterprise calls ListByEnterpriseFunc.
+func (mock *CampaignStoreMock) ListByEnterprise(ctx context.Context, enterpriseID string, limit int, offset int) ([]*model.Campaign, int64, error) {
+	if mock.ListByEnterpriseFunc == nil {
+		panic("CampaignStoreMock.ListByEnterpriseFunc: method is nil but CampaignStore.ListByEnterprise was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		EnterpriseID string
+		Limit        int
+		Offset       int
+	}{
+		Ctx:          ctx,
+		EnterpriseID: enterpriseID,
+		Limit:        limit,
+		Offset:       offset,
+	}
+	mock.lockListByEnterprise.Lock()
+	mock.calls.ListByEnterprise = append(mock.calls.ListByEnterprise, callInfo)
+	mock.lockListByEnterprise.Unlock()
+	return mock.ListByEnterpriseFunc(ctx, enterpriseID, limit, offset)
+}
+
+// ListByEnterpriseCalls gets all the calls that were made to ListByEnterprise.
+// Check the length with:
+//
+//	len(mockedCampaignStore.ListByEnterpriseCalls())
+func (mock *CampaignStoreMock) ListByEnterpriseCalls() []struct {
+	Ctx          context.Context
+	EnterpriseID string
+	Limit        int
+	Offset       int
+} {
+	var calls []struct {
+		Ctx          context.Context
+		EnterpriseID string
+		Limit        int
+		Offset       int
+	}
+	mock.lockListByEnterprise.RLock()
+	calls = mock.calls.ListByEnterprise
+	mock.lockListByEnterprise.RUnlock()
+	return calls
+}
+
+// SetCharity calls SetCharityFunc.
+func (mock *CampaignStoreMock) SetCharity(ctx context.Context, id string, c *model.CampaignCharity) error {
+	if mock.SetCharityFunc == nil {
+		panic("CampaignStoreMock.SetCharityFunc: method is nil but CampaignStore.SetCharity was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+		C   *model.CampaignCharity
+	}{
+		Ctx: ctx,
+		ID:  id,
+		C:   c,
+	}
+	mock.lockSetCharity.Lock()
+	mock.calls.SetCharity = append(mock.calls.SetCharity, callInfo)
+	mock.lockSetCharity.Unlock()
+	return mock.SetCharityFunc(ctx, id, c)
+}
+
+// SetCharityCalls gets all the calls that were made to SetCharity.
+// Check the length with:
+//
+//	len(mockedCampaignStore.SetCharityCalls())
+func (mock *CampaignStoreMock) SetCharityCalls() []struct {
+	Ctx context.Context
+	ID  string
+	C   *model.CampaignCharity
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+		C   *model.CampaignCharity
+	}
+	mock.lockSetCharity.RLock()
+	calls = mock.calls.SetCharity
+	mock.lockSetCharity.RUnlock()
+	return calls
+}
+
+// SetClaimPage calls SetClaimPageFunc.
+func (mock *CampaignStoreMock) SetClaimPage(ctx context.Context, id string, page *model.ClaimPageConfig) error {
+	if mock.SetClaimPageFunc == nil {
+		panic("CampaignStoreMock.SetClaimPageFunc: method is nil but CampaignStore.SetClaimPage was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		ID   string
+		Page *model.ClaimPageConfig
+	}{
+		Ctx:  ctx,
+		ID:   id,
+		Page: page,
+	}
+	mock.lockSetClaimPage.Lock()
+	mock.calls.SetClaimPage = append(mock.calls.SetClaimPage, callInfo)
+	mock.lockSetClaimPage.Unlock()
+	return mock.SetClaimPageFunc(ctx, id, page)
+}
+
+// SetClaimPageCalls gets all the calls that were made to SetClaimPage.
+// Check the length with:
+//
+//	len(mockedCampaignStore.SetClaimPageCalls())
+func (mock *CampaignStoreMock) SetClaimPageCalls() []struct {
+	Ctx  context.Context
+	ID   string
+	Page *model.ClaimPageConfig
+} {
+	var calls []struct {
+		Ctx  context.Context
+		ID   string
+		Page *model.ClaimPageConfig
+	}
+	mock.lockSetClaimPage.RLock()
+	calls = mock.calls.SetClaimPage
+	mock.lockSetClaimPage.RUnlock()
+	return calls
+}
+
+// SetClaimWeights calls SetClaimWeightsFunc.
+func (mock *CampaignStoreMock) SetClaimWeights(ctx context.Context, id string, w *model.CampaignClaimWeights) error {
+	if mock.SetClaimWeightsFunc == nil {
+		panic("CampaignStoreMock.SetClaimWeightsFunc: method is nil but CampaignStore.SetClaimWeights was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+		W   *model.CampaignClaimWeights
+	}{
+		Ctx: ctx,
+		ID:  id,
+		W:   w,
+	}
+	mock.lockSetClaimWeights.Lock()
+	mock.calls.SetClaimWeights = append(mock.calls.SetClaimWeights, callInfo)
+	mock.lockSetClaimWeights.Unlock()
+	return mock.SetClaimWeightsFunc(ctx, id, w)
+}
+
+// SetClaimWeightsCalls gets all the calls that were made to SetClaimWeights.
+// Check the length with:
+//
+//	len(mockedCampaignStore.SetClaimWeightsCalls())
+func (mock *CampaignStoreMock) SetClaimWeightsCalls() []struct {
+	Ctx context.Context
+	ID  string
+	W   *model.CampaignClaimWeights
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+		W   *model.CampaignClaimWeights
+	}
+	mock.lockSetClaimWeights.RLock()
+	calls = mock.calls.SetClaimWeights
+	mock.lockSetClaimWeights.RUnlock()
+	return calls
+}
+
+// SetTerms calls SetTermsFunc.
+func (mock *CampaignStoreMock) SetTerms(ctx context.Context, id string, t *model.CampaignTerms) error {
+	if mock.SetTermsFunc == nil {
+		panic("CampaignStoreMock.SetTermsFunc: method is nil but CampaignStore.SetTerms was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+		T   *model.CampaignTerms
+	}{
+		Ctx: ctx,
+		ID:  id,
+		T:   t,
+	}
+	mock.lockSetTerms.Lock()
+	mock.calls.SetTerms = append(mock.calls.SetTerms, callInfo)
+	mock.lockSetTerms.Unlock()
+	return mock.SetTermsFunc(ctx, id, t)
+}
+
+// SetTermsCalls gets all the calls that were made to SetTerms.
+// Check the length with:
+//
+//	len(mockedCampaignStore.SetTermsCalls())
+func (mock *CampaignStoreMock) SetTermsCalls() []struct {
+	Ctx context.Context
+	ID  string
+	T   *model.CampaignTerms
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+		T   *model.CampaignTerms
+	}
+	mock.lockSetTerms.RLock()
+	calls = mock.calls.SetTerms
+	mock.lockSetTerms.RUnlock()
+	return calls
+}
+
+// SetVisibility calls SetVisibilityFunc.
+func (mock *CampaignStoreMock) SetVisibility(ctx context.Context, id string, v *model.CampaignVisibility) error {
+	if mock.SetVisibilityFunc == nil {
+		panic("CampaignStoreMock.SetVisibilityFunc: method is nil but CampaignStore.SetVisibility was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+		V   *model.CampaignVisibility
+	}{
+		Ctx: ctx,
+		ID:  id,
+		V:   v,
+	}
+	mock.lockSetVisibility.Lock()
+	mock.calls.SetVisibility = append(mock.calls.SetVisibility, callInfo)
+	mock.lockSetVisibility.Unlock()
+	return mock.SetVisibilityFunc(ctx, id, v)
+}
+
+// SetVisibilityCalls gets all the calls that were made to SetVisibility.
+// Check the length with:
+//
+//	len(mockedCampaignStore.SetVisibilityCalls())
+func (mock *CampaignStoreMock) SetVisibilityCalls() []struct {
+	Ctx context.Context
+	ID  string
+	V   *model.CampaignVisibility
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+		V   *model.CampaignVisibility
+	}
+	mock.lockSetVisibility.RLock()
+	calls = mock.calls.SetVisibility
+	mock.lockSetVisibility.RUnlock()
+	return calls
+}
+
+// TestMode calls TestModeFunc.
+func (mock *CampaignStoreMock) TestMode(ctx context.Context, id string) (bool, error) {
+	if mock.TestModeFunc == nil {
+		panic("CampaignStoreMock.TestModeFunc: method is nil but CampaignStore.TestMode was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockTestMode.Lock()
+	mock.calls.TestMode = append(mock.calls.TestMode, callInfo)
+	mock.lockTestMode.Unlock()
+	return mock.TestModeFunc(ctx, id)
+}
+
+// TestModeCalls gets all the calls that were made to TestMode.
+// Check the length with:
+//
+//	len(mockedCampaignStore.TestModeCalls())
+func (mock *CampaignStoreMock) TestModeCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockTestMode.RLock()
+	calls = mock.calls.TestMode
+	mock.lockTestMode.RUnlock()
+	return calls
+}
+
+// TotalDonated calls TotalDonatedFunc.
+func (mock *CampaignStoreMock) TotalDonated(ctx context.Context, id string) (float64, error) {
+	if mock.TotalDonatedFunc == nil {
+		panic("CampaignStoreMock.TotalDonatedFunc: method is nil but CampaignStore.TotalDonated was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockTotalDonated.Lock()
+	mock.calls.TotalDonated = append(mock.calls.TotalDonated, callInfo)
+	mock.lockTotalDonated.Unlock()
+	return mock.TotalDonatedFunc(ctx, id)
+}
+
+// TotalDonatedCalls gets all the calls that were made to TotalDonated.
+// Check the length with:
+//
+//	len(mockedCampaignStore.TotalDonatedCalls())
+func (mock *CampaignStoreMock) TotalDonatedCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockTotalDonated.RLock()
+	calls = mock.calls.TotalDonated
+	mock.lockTotalDonated.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *CampaignStoreMock) Update(ctx context.Context, c *model.Campaign) error {
+	if mock.UpdateFunc == nil {
+		panic("CampaignStoreMock.UpdateFunc: method is nil but CampaignStore.Update was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		C   *model.Campaign
+	}{
+		Ctx: ctx,
+		C:   c,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	return mock.UpdateFunc(ctx, c)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedCampaignStore.UpdateCalls())
+func (mock *CampaignStoreMock) UpdateCalls() []struct {
+	Ctx context.Context
+	C   *model.Campaign
+} {
+	var calls []struct {
+		Ctx context.Context
+		C   *model.Campaign
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type PartnerRepository struct {
+	db *PostgresDB
+}
+
+func NewPartnerRepository(db *PostgresDB) *PartnerRepository {
+	return &PartnerRepository{db: db}
+}
+
+const partnerColumns = `id, name, email, code, revenue_share_bps, status, api_key_hash, created_at`
+
+func scanPartner(row interface{ Scan(...interface{}) error }) (*model.Partner, error) {
+	p := &model.Partner{}
+	err := row.Scan(&p.ID, &p.Name, &p.Email, &p.Code, &p.RevenueShareBps, &p.Status, &p.APIKeyHash, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Create records a partner. Reports false if its code is taken.
+func (r *PartnerRepository) Create(ctx context.Context, p *model.Partner) (bool, error) {
+	query := `
+		INSERT INTO partners (` + partnerColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (code) DO NOTHING
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, p.ID, p.Name, p.Email, p.Code, p.RevenueShareBps, p.Status, p.APIKeyHash, p.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PartnerRepository) GetByID(ctx context.Context, id string) (*model.Partner, error) {
+	query := `SELECT ` + partnerColumns + ` FROM partners WHERE id = $1`
+	return scanPartner(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// GetByCode returns the active partner with a code
+func (r *PartnerRepository) GetByCode(ctx context.Context, code string) (*model.Partner, error) {
+	query := `SELECT ` + partnerColumns + ` FROM partners WHERE code = $1 AND status = 'active'`
+	return scanPartner(r.db.Pool.QueryRow(ctx, query, code))
+}
+
+// GetByAPIKeyHash returns the active partner whose reporting key has the
+// hash, or pgx.ErrNoRows
+func (r *PartnerRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*model.Partner, error) {
+	query := `SELECT ` + partnerColumns + ` FROM partners WHERE api_key_hash = $1 AND status = 'active'`
+	return scanPartner(r.db.Pool.QueryRow(ctx, query, hash))
+}
+
+// Attach attributes an enterprise to a partner. Reports false if the
+// enterprise already has one or does not exist.
+func (r *PartnerRepository) Attach(ctx context.Context, enterpriseID, partnerID string, at time.Time) (bool, error) {
+	query := `
+		UPDATE enterprises SET partner_id = $2, partner_attached_at = $3
+		WHERE id = $1 AND partner_id IS NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, enterpriseID, partnerID, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListEnterprises returns the enterprises attributed to a partner, most
+// recently attached first
+func (r *PartnerRepository) ListEnterprises(ctx context.Context, partnerID string, limit, offset int) ([]*model.PartnerEnterprise, int64, error) {
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM enterprises WHERE partner_id = $1`, partnerID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT e.id, e.name, e.status, e.partner_attached_at,
+			(SELECT COUNT(*) FROM campaigns c WHERE c.enterprise_id = e.id)
+		FROM enterprises e
+		WHERE e.partner_id = $1
+		ORDER BY e.partner_attached_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, partnerID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var enterprises []*model.PartnerEnterprise
+	for rows.Next() {
+		e := &model.PartnerEnterprise{}
+		if err := rows.Scan(&e.ID, &e.Name, &e.Status, &e.AttachedAt, &e.Campaigns); err != nil {
+			return nil, 0, err
+		}
+		enterprises = append(enterprises, e)
+	}
+	return enterprises, total, rows.Err()
+}
+
+// Statements sums a partner's attributed claim volume and platform fees per
+// calendar month (UTC) and token over [from, to), newest month first. Only
+// activity since each enterprise was attached counts, and never that of
+// sandbox campaigns. Commission is left for the caller.
+func (r *PartnerRepository) Statements(ctx context.Context, partnerID string, from, to time.Time) ([]*model.PartnerStatement, error) {
+	query := `
+		WITH attributed AS (
+			SELECT c.id AS campaign_id, e.id AS enterprise_id, e.partner_attached_at AS attached_at
+			FROM enterprises e
+			JOIN campaigns c ON c.enterprise_id = e.id
+			WHERE e.partner_id = $1 AND NOT c.test_mode
+		), volume AS (
+			SELECT date_trunc('month', cl.created_at AT TIME ZONE 'UTC') AS month, rp.chain_id, rp.token,
+				COUNT(DISTINCT a.enterprise_id) AS enterprises, COUNT(*) AS claims, SUM(cl.amount) AS volume
+			FROM claims cl
+			JOIN red_pockets rp ON rp.id = cl.red_pocket_id
+			JOIN attributed a ON a.campaign_id = rp.campaign_id
+			WHERE cl.status NOT IN ('failed', 'blocked', 'refunded')
+				AND cl.created_at >= a.attached_at AND cl.created_at >= $2 AND cl.created_at < $3
+			GROUP BY 1, 2, 3
+		), fees AS (
+			SELECT date_trunc('month', le.created_at AT TIME ZONE 'UTC') AS month, le.chain_id, le.token,
+				SUM(le.amount) AS fees
+			FROM ledger_entries le
+			LEFT JOIN claims cl ON le.kind = 'claim_fee' AND cl.id = le.ref_id
+			JOIN red_pockets rp ON rp.id = COALESCE(cl.red_pocket_id, le.ref_id)
+			JOIN attributed a ON a.campaign_id = rp.campaign_id
+			WHERE le.kind IN ('creation_fee', 'claim_fee') AND le.user_id = '` + model.PlatformRevenueAccount + `'
+				AND le.created_at >= a.attached_at AND le.created_at >= $2 AND le.created_at < $3
+			GROUP BY 1, 2, 3
+		)
+		SELECT to_char(COALESCE(v.month, f.month), 'YYYY-MM'), COALESCE(v.chain_id, f.chain_id), COALESCE(v.token, f.token),
+			COALESCE(v.enterprises, 0), COALESCE(v.claims, 0), COALESCE(v.volume, 0), COALESCE(f.fees, 0)
+		FROM volume v
+		FULL JOIN fees f ON f.month = v.month AND f.chain_id = v.chain_id AND f.token = v.token
+		ORDER BY 1 DESC, 2, 3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, partnerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []*model.PartnerStatement
+	for rows.Next() {
+		s := &model.PartnerStatement{}
+		err := rows.Scan(&s.Month, &s.ChainID, &s.Token, &s.Enterprises, &s.Claims, &s.Volume, &s.PlatformFees)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, s)
+	}
+	return statements, rows.Err()
+}
@@ -0,0 +1,372 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type PayoutJobRepository struct {
+	db *PostgresDB
+}
+
+func NewPayoutJobRepository(db *PostgresDB) *PayoutJobRepository {
+	return &PayoutJobRepository{db: db}
+}
+
+func (r *PayoutJobRepository) Create(ctx context.Context, j *model.PayoutJob) error {
+	return insertPayoutJob(ctx, r.db.Pool, j)
+}
+
+func insertPayoutJob(ctx context.Context, db execer, j *model.PayoutJob) error {
+	query := `
+		INSERT INTO payout_jobs (id, claim_id, status, attempts, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := db.Exec(ctx, query,
+		j.ID, j.ClaimID, j.Status, j.Attempts, j.NextRunAt, j.CreatedAt, j.UpdatedAt,
+	)
+	return err
+}
+
+const payoutJobColumns = `
+	id, claim_id, status, attempts, next_run_at, locked_at, COALESCE(last_error, ''), COALESCE(batch_id, ''),
+	created_at, updated_at
+`
+
+func scanPayoutJob(row interface{ Scan(...interface{}) error }) (*model.PayoutJob, error) {
+	j := &model.PayoutJob{}
+	err := row.Scan(
+		&j.ID, &j.ClaimID, &j.Status, &j.Attempts, &j.NextRunAt, &j.LockedAt, &j.LastError, &j.BatchID,
+		&j.CreatedAt, &j.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (r *PayoutJobRepository) GetByClaim(ctx context.Context, claimID string) (*model.PayoutJob, error) {
+	query := `SELECT ` + payoutJobColumns + ` FROM payout_jobs WHERE claim_id = $1`
+	return scanPayoutJob(r.db.Pool.QueryRow(ctx, query, claimID))
+}
+
+// Dequeue locks the next due job for a worker and counts the attempt. Returns
+// pgx.ErrNoRows when nothing is due. SKIP LOCKED lets several workers (and instances)
+// poll the same table without handing out a job twice.
+func (r *PayoutJobRepository) Dequeue(ctx context.Context) (*model.PayoutJob, error) {
+	query := `
+		UPDATE payout_jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM payout_jobs
+			WHERE status = 'queued' AND next_run_at <= NOW()
+			ORDER BY next_run_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + payoutJobColumns
+	return scanPayoutJob(r.db.Pool.QueryRow(ctx, query))
+}
+
+// DequeueBatch locks the red pocket of the next due job and takes up to limit
+// of its queued jobs, due or not, so claims made during the settlement window
+// go out together. Returns an empty slice when nothing is due.
+func (r *PayoutJobRepository) DequeueBatch(ctx context.Context, limit int) ([]*model.PayoutJob, error) {
+	query := `
+		WITH head AS (
+			SELECT c.red_pocket_id
+			FROM payout_jobs j
+			JOIN claims c ON c.id = j.claim_id
+			WHERE j.status = 'queued' AND j.next_run_at <= NOW()
+			ORDER BY j.next_run_at
+			FOR UPDATE OF j SKIP LOCKED
+			LIMIT 1
+		), batch AS (
+			SELECT j.id
+			FROM payout_jobs j
+			JOIN claims c ON c.id = j.claim_id
+			JOIN head h ON h.red_pocket_id = c.red_pocket_id
+			WHERE j.status = 'queued'
+			ORDER BY j.next_run_at
+			FOR UPDATE OF j SKIP LOCKED
+			LIMIT $1
+		)
+		UPDATE payout_jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id IN (SELECT id FROM batch)
+		RETURNING ` + payoutJobColumns
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*model.PayoutJob
+	for rows.Next() {
+		j, err := scanPayoutJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// SetBatch assigns jobs to the settlement batch paying them
+func (r *PayoutJobRepository) SetBatch(ctx context.Context, ids []string, batchID string) error {
+	query := `UPDATE payout_jobs SET batch_id = $2, updated_at = NOW() WHERE id = ANY($1)`
+	_, err := r.db.Pool.Exec(ctx, query, ids, batchID)
+	return err
+}
+
+func (r *PayoutJobRepository) Complete(ctx context.Context, id string) error {
+	query := `
+		UPDATE payout_jobs SET status = 'done', locked_at = NULL, last_error = NULL, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+// Retry puts a job back in the queue to run again at nextRunAt
+func (r *PayoutJobRepository) Retry(ctx context.Context, id, lastError string, nextRunAt time.Time) error {
+	query := `
+		UPDATE payout_jobs SET status = 'queued', locked_at = NULL, last_error = $2, next_run_at = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, lastError, nextRunAt)
+	return err
+}
+
+func (r *PayoutJobRepository) Fail(ctx context.Context, id, lastError string) error {
+	query := `
+		UPDATE payout_jobs SET status = 'failed', locked_at = NULL, last_error = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, lastError)
+	return err
+}
+
+// DeadLetter parks a job that will not be retried automatically, leaving it
+// for Requeue
+func (r *PayoutJobRepository) DeadLetter(ctx context.Context, id, lastError string) error {
+	query := `
+		UPDATE payout_jobs SET status = 'dead', locked_at = NULL, last_error = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, lastError)
+	return err
+}
+
+// Requeue queues a claim's dead-lettered job to run now with a fresh
+// attempt budget and puts the failed claim back to retrying. It reports
+// false if the job is not dead-lettered or the claim is no longer failed,
+// and returns pgx.ErrNoRows if the claim is not one of the enterprise's.
+func (r *PayoutJobRepository) Requeue(ctx context.Context, claimID, enterpriseID string) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT c.id FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE c.id = $1 AND camp.enterprise_id = $2
+		FOR UPDATE OF c
+	`
+	var id string
+	if err := tx.QueryRow(ctx, query, claimID, enterpriseID).Scan(&id); err != nil {
+		return false, err
+	}
+
+	query = `
+		WITH requeued AS (
+			UPDATE payout_jobs j
+			SET status = 'queued', attempts = 0, next_run_at = NOW(), locked_at = NULL, batch_id = NULL, updated_at = NOW()
+			FROM claims c
+			WHERE j.claim_id = $1 AND j.status = 'dead' AND c.id = j.claim_id AND c.status = 'failed'
+			RETURNING j.claim_id
+		)
+		UPDATE claims SET status = 'retrying', status_reason = 'retried by enterprise', completed_at = NULL
+		WHERE id IN (SELECT claim_id FROM requeued)
+	`
+	tag, err := tx.Exec(ctx, query, claimID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, tx.Commit(ctx)
+}
+
+// ReleaseDue queues the held jobs whose hold has run out, puts their claims
+// back to pending and returns the claim IDs
+func (r *PayoutJobRepository) ReleaseDue(ctx context.Context) ([]string, error) {
+	query := `
+		WITH released AS (
+			UPDATE payout_jobs SET status = 'queued', updated_at = NOW()
+			WHERE status = 'held' AND next_run_at <= NOW()
+			RETURNING claim_id
+		)
+		UPDATE claims SET status = 'pending', status_reason = 'hold ran out'
+		WHERE id IN (SELECT claim_id FROM released)
+		RETURNING id
+	`
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		claimIDs = append(claimIDs, id)
+	}
+	return claimIDs, rows.Err()
+}
+
+// Release queues a claim's held job to run now and puts the claim back to
+// pending. It reports false if the job is not held.
+func (r *PayoutJobRepository) Release(ctx context.Context, claimID string) (bool, error) {
+	query := `
+		WITH released AS (
+			UPDATE payout_jobs SET status = 'queued', next_run_at = NOW(), updated_at = NOW()
+			WHERE claim_id = $1 AND status = 'held'
+			RETURNING claim_id
+		)
+		UPDATE claims SET status = 'pending', status_reason = 'released in review'
+		WHERE id IN (SELECT claim_id FROM released)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, claimID)
+	return tag.RowsAffected() > 0, err
+}
+
+// Cancel fails a claim's held job without running it and blocks the claim.
+// It reports false if the job is not held.
+func (r *PayoutJobRepository) Cancel(ctx context.Context, claimID, reason string) (bool, error) {
+	query := `
+		WITH cancelled AS (
+			UPDATE payout_jobs SET status = 'failed', last_error = $2, updated_at = NOW()
+			WHERE claim_id = $1 AND status = 'held'
+			RETURNING claim_id
+		)
+		UPDATE claims SET status = 'blocked', completed_at = NOW(), status_reason = $2
+		WHERE id IN (SELECT claim_id FROM cancelled)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, claimID, reason)
+	return tag.RowsAffected() > 0, err
+}
+
+// ReclaimStale takes over single-claim jobs whose worker has held them longer
+// than the lease (seconds), e.g. because the process died mid-transfer, by
+// renewing their lease for the caller. The caller decides from the claim's
+// recorded user operations whether the transfer went out.
+func (r *PayoutJobRepository) ReclaimStale(ctx context.Context, leaseSeconds int) ([]*model.PayoutJob, error) {
+	query := `
+		UPDATE payout_jobs
+		SET locked_at = NOW(), updated_at = NOW()
+		WHERE status = 'running' AND batch_id IS NULL AND locked_at < NOW() - make_interval(secs => $1)
+		RETURNING ` + payoutJobColumns
+	rows, err := r.db.Pool.Query(ctx, query, leaseSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*model.PayoutJob
+	for rows.Next() {
+		j, err := scanPayoutJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// FailStale fails jobs whose worker has held them longer than the lease
+// (seconds), e.g. because the process died mid-transfer, and returns their
+// claim IDs. They are not retried: the transfer may already have been sent.
+func (r *PayoutJobRepository) FailStale(ctx context.Context, leaseSeconds int) ([]string, error) {
+	query := `
+		UPDATE payout_jobs
+		SET status = 'failed', locked_at = NULL, last_error = 'worker lease expired', updated_at = NOW()
+		WHERE status = 'running' AND locked_at < NOW() - make_interval(secs => $1)
+		RETURNING claim_id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, leaseSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		claimIDs = append(claimIDs, id)
+	}
+	return claimIDs, rows.Err()
+}
+
+type PayoutBatchRepository struct {
+	db *PostgresDB
+}
+
+func NewPayoutBatchRepository(db *PostgresDB) *PayoutBatchRepository {
+	return &PayoutBatchRepository{db: db}
+}
+
+func (r *PayoutBatchRepository) Create(ctx context.Context, b *model.PayoutBatch) error {
+	query := `
+		INSERT INTO payout_batches (id, red_pocket_id, sender, status, claim_count, transfer_count, total_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		b.ID, b.RedPocketID, b.Sender, b.Status, b.ClaimCount, b.TransferCount, b.TotalAmount, b.CreatedAt,
+	)
+	return err
+}
+
+func (r *PayoutBatchRepository) GetByID(ctx context.Context, id string) (*model.PayoutBatch, error) {
+	query := `
+		SELECT id, red_pocket_id, sender, status, claim_count, transfer_count, total_amount,
+			COALESCE(tx_hash, ''), COALESCE(user_op_hash, ''), COALESCE(error, ''), created_at, settled_at
+		FROM payout_batches WHERE id = $1
+	`
+	b := &model.PayoutBatch{}
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&b.ID, &b.RedPocketID, &b.Sender, &b.Status, &b.ClaimCount, &b.TransferCount, &b.TotalAmount,
+		&b.TxHash, &b.UserOpHash, &b.Error, &b.CreatedAt, &b.SettledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// SetUserOp records the user operation carrying a batch that is still in the mempool
+func (r *PayoutBatchRepository) SetUserOp(ctx context.Context, id, userOpHash string) error {
+	query := `UPDATE payout_batches SET user_op_hash = $2 WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id, userOpHash)
+	return err
+}
+
+// Settle records the final outcome of a batch
+func (r *PayoutBatchRepository) Settle(ctx context.Context, id, status, txHash, errMsg string) error {
+	query := `
+		UPDATE payout_batches
+		SET status = $2, tx_hash = NULLIF($3, ''), error = NULLIF($4, ''), settled_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, txHash, errMsg)
+	return err
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type PayoutPreferenceRepository struct {
+	db *PostgresDB
+}
+
+func NewPayoutPreferenceRepository(db *PostgresDB) *PayoutPreferenceRepository {
+	return &PayoutPreferenceRepository{db: db}
+}
+
+// Get returns a user's payout preference, or pgx.ErrNoRows if they never set one
+func (r *PayoutPreferenceRepository) Get(ctx context.Context, userID string) (*model.PayoutPreference, error) {
+	query := `SELECT user_id, splits, updated_at FROM payout_preferences WHERE user_id = $1`
+	p := &model.PayoutPreference{}
+	var splits []byte
+	if err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&p.UserID, &splits, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(splits, &p.Splits); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Set replaces a user's payout splits
+func (r *PayoutPreferenceRepository) Set(ctx context.Context, p *model.PayoutPreference) error {
+	splits, err := json.Marshal(p.Splits)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO payout_preferences (user_id, splits, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET splits = EXCLUDED.splits, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	return r.db.Pool.QueryRow(ctx, query, p.UserID, splits).Scan(&p.UpdatedAt)
+}
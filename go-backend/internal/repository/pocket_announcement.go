@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// PocketAnnouncementRepository remembers the chat messages pockets were
+// announced in
+type PocketAnnouncementRepository struct {
+	db *PostgresDB
+}
+
+func NewPocketAnnouncementRepository(db *PostgresDB) *PocketAnnouncementRepository {
+	return &PocketAnnouncementRepository{db: db}
+}
+
+// SaveAnnouncement records an announcement once; announcements of unknown
+// pockets are ignored
+func (r *PocketAnnouncementRepository) SaveAnnouncement(ctx context.Context, a *model.PocketAnnouncement) error {
+	query := `
+		INSERT INTO pocket_announcements (red_pocket_id, platform, channel_id, message_id, created_at)
+		SELECT $1, $2, $3, $4, NOW()
+		WHERE EXISTS (SELECT 1 FROM red_pockets WHERE id = $1)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Pool.Exec(ctx, query, a.RedPocketID, a.Platform, a.ChannelID, a.MessageID)
+	return err
+}
+
+// ListAnnouncements returns a pocket's announcements on a platform, oldest
+// first
+func (r *PocketAnnouncementRepository) ListAnnouncements(ctx context.Context, redPocketID, platform string) ([]*model.PocketAnnouncement, error) {
+	query := `
+		SELECT red_pocket_id, platform, channel_id, message_id, created_at
+		FROM pocket_announcements
+		WHERE red_pocket_id = $1 AND platform = $2
+		ORDER BY created_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, redPocketID, platform)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var announcements []*model.PocketAnnouncement
+	for rows.Next() {
+		a := &model.PocketAnnouncement{}
+		if err := rows.Scan(&a.RedPocketID, &a.Platform, &a.ChannelID, &a.MessageID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		announcements = append(announcements, a)
+	}
+	return announcements, rows.Err()
+}
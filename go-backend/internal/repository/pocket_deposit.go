@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type PocketDepositRepository struct {
+	db *PostgresDB
+}
+
+func NewPocketDepositRepository(db *PostgresDB) *PocketDepositRepository {
+	return &PocketDepositRepository{db: db}
+}
+
+const pocketDepositColumns = `
+	red_pocket_id, chain_id, address, COALESCE(token_address, ''), required_units, received_units,
+	COALESCE(confirmed_block, 0), status, deadline, checked_at, funded_at, created_at
+`
+
+func scanPocketDeposit(row interface{ Scan(...interface{}) error }) (*model.PocketDeposit, error) {
+	d := &model.PocketDeposit{}
+	err := row.Scan(
+		&d.RedPocketID, &d.ChainID, &d.Address, &d.TokenAddress, &d.RequiredUnits, &d.ReceivedUnits,
+		&d.ConfirmedBlock, &d.Status, &d.Deadline, &d.CheckedAt, &d.FundedAt, &d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// insertPocketDeposit records the deposit of a pocket being created in tx
+func insertPocketDeposit(ctx context.Context, tx pgx.Tx, d *model.PocketDeposit) error {
+	query := `
+		INSERT INTO pocket_deposits (
+			red_pocket_id, chain_id, address, token_address, required_units, received_units, status, deadline, created_at
+		)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9)
+	`
+	_, err := tx.Exec(ctx, query,
+		d.RedPocketID, d.ChainID, d.Address, d.TokenAddress, d.RequiredUnits, d.ReceivedUnits, d.Status, d.Deadline, d.CreatedAt,
+	)
+	return err
+}
+
+func (r *PocketDepositRepository) GetByRedPocket(ctx context.Context, redPocketID string) (*model.PocketDeposit, error) {
+	query := `SELECT ` + pocketDepositColumns + ` FROM pocket_deposits WHERE red_pocket_id = $1`
+	return scanPocketDeposit(r.db.Pool.QueryRow(ctx, query, redPocketID))
+}
+
+// ListPending returns deposits still awaited, least recently checked first
+func (r *PocketDepositRepository) ListPending(ctx context.Context, limit int) ([]*model.PocketDeposit, error) {
+	query := `
+		SELECT ` + pocketDepositColumns + ` FROM pocket_deposits
+		WHERE status = 'pending'
+		ORDER BY checked_at ASC NULLS FIRST
+		LIMIT $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []*model.PocketDeposit
+	for rows.Next() {
+		d, err := scanPocketDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, d)
+	}
+	return deposits, rows.Err()
+}
+
+// RecordCheck stores the confirmed balance seen at block
+func (r *PocketDepositRepository) RecordCheck(ctx context.Context, redPocketID string, received model.Units, block int64) error {
+	query := `
+		UPDATE pocket_deposits SET received_units = $2, confirmed_block = $3, checked_at = NOW()
+		WHERE red_pocket_id = $1 AND status = 'pending'
+	`
+	_, err := r.db.Pool.Exec(ctx, query, redPocketID, received, block)
+	return err
+}
+
+// MarkFunded settles a pending deposit and opens its pocket: scheduled
+// pockets go back to waiting for their start, the others open now with
+// their expiry pushed back by the time spent waiting, and the pocket's
+// creation fee is booked. Returns nil if the deposit was no longer pending.
+func (r *PocketDepositRepository) MarkFunded(ctx context.Context, redPocketID string, received model.Units, block int64) (*model.RedPocket, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE pocket_deposits
+		SET status = 'funded', received_units = $2, confirmed_block = $3, checked_at = NOW(), funded_at = NOW()
+		WHERE red_pocket_id = $1 AND status = 'pending'
+	`, redPocketID, received, block)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	query := `
+		UPDATE red_pockets
+		SET status = CASE WHEN starts_at IS NULL THEN 'active' ELSE 'scheduled' END,
+			status_reason = 'deposit confirmed',
+			expires_at = CASE WHEN starts_at IS NULL THEN expires_at + (NOW() - created_at) ELSE expires_at END
+		WHERE id = $1 AND status = 'awaiting_funding'
+		RETURNING ` + redPocketColumns
+	rp, err := scanRedPocket(tx.QueryRow(ctx, query, redPocketID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, tx.Commit(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, bookCreationFee, redPocketID); err != nil {
+		return nil, err
+	}
+	return rp, tx.Commit(ctx)
+}
+
+// Expire gives up on a pending deposit and marks its pocket unfunded, which
+// releases the campaign budget it held. Returns nil if the deposit was no
+// longer pending.
+func (r *PocketDepositRepository) Expire(ctx context.Context, redPocketID string) (*model.RedPocket, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE pocket_deposits SET status = 'expired', checked_at = NOW()
+		WHERE red_pocket_id = $1 AND status = 'pending'
+	`, redPocketID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	query := `
+		UPDATE red_pockets SET status = 'unfunded', status_reason = 'deposit never arrived'
+		WHERE id = $1 AND status = 'awaiting_funding'
+		RETURNING ` + redPocketColumns
+	rp, err := scanRedPocket(tx.QueryRow(ctx, query, redPocketID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, tx.Commit(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rp, tx.Commit(ctx)
+}
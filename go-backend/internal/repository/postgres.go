@@ -2,16 +2,52 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/protocolbank/redpocket-backend/internal/tracing"
 )
 
+// execer is satisfied by both the pool and a transaction, so an insert can
+// run on its own or as part of a larger write
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
 type PostgresDB struct {
 	Pool *pgxpool.Pool
+
+	// connConfig replaces the configuration new connections dial with once
+	// the connection string has been rotated
+	connConfig atomic.Pointer[pgx.ConnConfig]
+	redialMu   sync.Mutex
 }
 
+// NewPostgresDB opens the pool and checks that the database answers
 func NewPostgresDB(databaseURL string) (*PostgresDB, error) {
+	db, err := OpenPostgresDB(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenPostgresDB opens the pool without waiting for the database, which
+// connects as it becomes reachable. Only a malformed URL fails.
+func OpenPostgresDB(databaseURL string) (*PostgresDB, error) {
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, err
@@ -23,21 +59,47 @@ func NewPostgresDB(databaseURL string) (*PostgresDB, error) {
 	config.MaxConnLifetime = time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = time.Minute
+	config.ConnConfig.Tracer = tracing.QueryTracer{}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	db := &PostgresDB{}
+	config.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+		if next := db.connConfig.Load(); next != nil {
+			*cc = *next.Copy()
+		}
+		return nil
+	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, err
 	}
+	db.Pool = pool
+	return db, nil
+}
 
-	// Test connection
-	if err := pool.Ping(ctx); err != nil {
-		return nil, err
+// Redial switches the pool to databaseURL, a rotated password or a new
+// connection string altogether, without a restart. Idle connections are
+// closed and those in use are closed when returned, so every query after the
+// switch runs on a connection dialed with the new settings. If the database
+// refuses a connection with them the previous settings are restored.
+func (db *PostgresDB) Redial(ctx context.Context, databaseURL string) error {
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return err
 	}
+	config.ConnConfig.Tracer = tracing.QueryTracer{}
+	db.redialMu.Lock()
+	defer db.redialMu.Unlock()
 
-	return &PostgresDB{Pool: pool}, nil
+	prev := db.connConfig.Load()
+	db.connConfig.Store(config.ConnConfig)
+	db.Pool.Reset()
+	if err := db.Ping(ctx); err != nil {
+		db.connConfig.Store(prev)
+		db.Pool.Reset()
+		return fmt.Errorf("new connection settings were refused, keeping the previous ones: %w", err)
+	}
+	return nil
 }
 
 func (db *PostgresDB) Close() {
@@ -47,3 +109,51 @@ func (db *PostgresDB) Close() {
 func (db *PostgresDB) Ping(ctx context.Context) error {
 	return db.Pool.Ping(ctx)
 }
+
+// TryAdvisoryLock takes a session advisory lock on key if no other session
+// holds it, without waiting. The lock keeps a pooled connection until
+// release unlocks it; if unlocking fails the connection is closed instead,
+// which drops the lock all the same.
+func (db *PostgresDB) TryAdvisoryLock(ctx context.Context, key string) (release func(), acquired bool, err error) {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, key).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Release()
+		return nil, false, err
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, key); err != nil {
+			conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}, true, nil
+}
+
+// Listen passes the payload of every notification on channel to fn until ctx
+// is cancelled or the connection drops. The connection is taken out of the
+// pool for good so its LISTEN never leaks to other queries.
+func (db *PostgresDB) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	pgConn := conn.Hijack()
+	defer pgConn.Close(context.Background())
+
+	if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		n, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(n.Payload)
+	}
+}
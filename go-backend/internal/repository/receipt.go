@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type ReceiptRepository struct {
+	db *PostgresDB
+}
+
+func NewReceiptRepository(db *PostgresDB) *ReceiptRepository {
+	return &ReceiptRepository{db: db}
+}
+
+// ListUnfinalized returns successful claims whose payout is not final yet,
+// oldest first. Sandbox claims were never sent to a chain and are skipped.
+func (r *ReceiptRepository) ListUnfinalized(ctx context.Context, limit int) ([]*model.TrackedClaim, error) {
+	query := `
+		SELECT c.id, c.red_pocket_id, c.claimer_id, c.amount, c.tx_hash, rp.chain_id, rp.token_address,
+			COALESCE(r.block_number, 0), COALESCE(r.block_hash, ''), COALESCE(r.resubmit_count, 0),
+			COALESCE(r.updated_at, c.completed_at), COALESCE(j.batch_id, ''),
+			COALESCE((
+				SELECT u.user_op_hash FROM user_operations u
+				WHERE u.tx_hash = c.tx_hash AND u.status = 'included'
+					AND (u.claim_id = c.id OR u.batch_id = j.batch_id)
+				LIMIT 1
+			), '')
+		FROM claims c
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		LEFT JOIN claim_receipts r ON r.claim_id = c.id
+		LEFT JOIN payout_jobs j ON j.claim_id = c.id
+		WHERE c.status = 'success' AND COALESCE(c.tx_hash, '') <> '' AND r.finalized_at IS NULL
+			AND NOT c.test_mode
+		ORDER BY c.completed_at ASC
+		LIMIT $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []*model.TrackedClaim
+	for rows.Next() {
+		t := &model.TrackedClaim{}
+		err := rows.Scan(
+			&t.ClaimID, &t.RedPocketID, &t.ClaimerID, &t.Amount, &t.TxHash, &t.ChainID, &t.TokenAddress,
+			&t.BlockNumber, &t.BlockHash, &t.ResubmitCount, &t.SubmittedAt, &t.BatchID, &t.UserOpHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, t)
+	}
+	return claims, nil
+}
+
+// HasUnfinalized reports whether a red pocket still has payouts awaiting finality
+func (r *ReceiptRepository) HasUnfinalized(ctx context.Context, redPocketID string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM claims c
+			LEFT JOIN claim_receipts r ON r.claim_id = c.id
+			WHERE c.red_pocket_id = $1 AND c.status = 'success'
+				AND COALESCE(c.tx_hash, '') <> '' AND r.finalized_at IS NULL AND NOT c.test_mode
+		)
+	`
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, query, redPocketID).Scan(&exists)
+	return exists, err
+}
+
+// Observe records where the payout was seen included
+func (r *ReceiptRepository) Observe(ctx context.Context, claimID, txHash string, blockNumber int64, blockHash string, confirmations int) error {
+	query := `
+		INSERT INTO claim_receipts (claim_id, tx_hash, block_number, block_hash, confirmations, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (claim_id) DO UPDATE
+		SET tx_hash = $2, block_number = $3, block_hash = $4, confirmations = $5, updated_at = NOW()
+	`
+	_, err := r.db.Pool.Exec(ctx, query, claimID, txHash, blockNumber, blockHash, confirmations)
+	return err
+}
+
+// Finalize marks a payout final and its claim confirmed
+func (r *ReceiptRepository) Finalize(ctx context.Context, claimID string, confirmations int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE claim_receipts
+		SET confirmations = $2, finalized_at = NOW(), updated_at = NOW()
+		WHERE claim_id = $1
+	`
+	if _, err := tx.Exec(ctx, query, claimID, confirmations); err != nil {
+		return err
+	}
+	// Both count as paid, so the campaign's spent budget does not change
+	query = `
+		UPDATE claims SET status = 'confirmed', status_reason = format('final after %s confirmations', $2::int)
+		WHERE id = $1 AND status = 'success'
+	`
+	if _, err := tx.Exec(ctx, query, claimID, confirmations); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Resubmitted points the claim at its replacement tx and resets inclusion tracking
+func (r *ReceiptRepository) Resubmitted(ctx context.Context, claimID, newTxHash string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE claims SET tx_hash = $2 WHERE id = $1`, claimID, newTxHash); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO claim_receipts (claim_id, tx_hash, resubmit_count, updated_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (claim_id) DO UPDATE
+		SET tx_hash = $2, block_number = 0, block_hash = NULL, confirmations = 0,
+			resubmit_count = claim_receipts.resubmit_count + 1, updated_at = NOW()
+	`
+	if _, err := tx.Exec(ctx, query, claimID, newTxHash); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *ReceiptRepository) LogReorg(ctx context.Context, e *model.ClaimReorg) error {
+	query := `
+		INSERT INTO claim_reorgs (claim_id, tx_hash, block_number, block_hash, action, new_tx_hash, error, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NOW())
+	`
+	_, err := r.db.Pool.Exec(ctx, query, e.ClaimID, e.TxHash, e.BlockNumber, e.BlockHash, e.Action, e.NewTxHash, e.Error)
+	return err
+}
+
+func (r *ReceiptRepository) ListReorgsByEnterprise(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.ClaimReorg, error) {
+	query := `
+		SELECT e.id, e.claim_id, e.tx_hash, e.block_number, COALESCE(e.block_hash, ''), e.action,
+			COALESCE(e.new_tx_hash, ''), COALESCE(e.error, ''), e.created_at
+		FROM claim_reorgs e
+		JOIN claims c ON c.id = e.claim_id
+		JOIN red_pockets rp ON rp.id = c.red_pocket_id
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE camp.enterprise_id = $1
+		ORDER BY e.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reorgs []*model.ClaimReorg
+	for rows.Next() {
+		e := &model.ClaimReorg{}
+		err := rows.Scan(
+			&e.ID, &e.ClaimID, &e.TxHash, &e.BlockNumber, &e.BlockHash, &e.Action,
+			&e.NewTxHash, &e.Error, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reorgs = append(reorgs, e)
+	}
+	return reorgs, nil
+}
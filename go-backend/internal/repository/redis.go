@@ -2,16 +2,21 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/protocolbank/redpocket-backend/internal/metrics"
+	"github.com/protocolbank/redpocket-backend/internal/tracing"
 )
 
 type RedisClient struct {
 	Client *redis.Client
 }
 
-func NewRedisClient(redisURL string) (*RedisClient, error) {
+// OpenRedisClient sets up the client without waiting for Redis, which
+// connects as it becomes reachable. Only a malformed URL fails.
+func OpenRedisClient(redisURL string) (*RedisClient, error) {
 	opt, err := redis.ParseURL(redisURL)
 	if err != nil {
 		return nil, err
@@ -26,14 +31,7 @@ func NewRedisClient(redisURL string) (*RedisClient, error) {
 	opt.WriteTimeout = 3 * time.Second
 
 	client := redis.NewClient(opt)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, err
-	}
-
+	client.AddHook(tracing.RedisHook{})
 	return &RedisClient{Client: client}, nil
 }
 
@@ -45,15 +43,100 @@ func (r *RedisClient) Ping(ctx context.Context) error {
 	return r.Client.Ping(ctx).Err()
 }
 
-// Distributed lock for claim operations
+// Distributed lock for claim operations. Attempts are counted per lock kind,
+// the key up to its first colon.
 func (r *RedisClient) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
-	return r.Client.SetNX(ctx, "lock:"+key, "1", ttl).Result()
+	acquired, err := r.Client.SetNX(ctx, "lock:"+key, "1", ttl).Result()
+
+	kind, _, _ := strings.Cut(key, ":")
+	switch {
+	case err != nil:
+		metrics.LockAcquisitions.Inc(kind, "error")
+	case !acquired:
+		metrics.LockAcquisitions.Inc(kind, "contended")
+	default:
+		metrics.LockAcquisitions.Inc(kind, "acquired")
+	}
+	return acquired, err
 }
 
 func (r *RedisClient) ReleaseLock(ctx context.Context, key string) error {
 	return r.Client.Del(ctx, "lock:"+key).Err()
 }
 
+// Leases are locks held by a named owner, so only the holder can renew or
+// release them
+var renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func (r *RedisClient) AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, "lease:"+key, owner, ttl).Result()
+}
+
+// RenewLease extends a lease and reports whether owner still holds it
+func (r *RedisClient) RenewLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	n, err := renewLeaseScript.Run(ctx, r.Client, []string{"lease:" + key}, owner, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+func (r *RedisClient) ReleaseLease(ctx context.Context, key, owner string) error {
+	return releaseLeaseScript.Run(ctx, r.Client, []string{"lease:" + key}, owner).Err()
+}
+
+// ClaimNonce records a request nonce for ttl and reports whether it was
+// unused
+func (r *RedisClient) ClaimNonce(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, "nonce:"+key, "1", ttl).Result()
+}
+
+// Responses to requests with an Idempotency-Key. ReserveIdempotencyKey
+// stores value only if the key is unused and reports whether it did.
+func (r *RedisClient) ReserveIdempotencyKey(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, "idempotency:"+key, value, ttl).Result()
+}
+
+func (r *RedisClient) GetIdempotencyKey(ctx context.Context, key string) (string, error) {
+	value, err := r.Client.Get(ctx, "idempotency:"+key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return value, err
+}
+
+func (r *RedisClient) SetIdempotencyKey(ctx context.Context, key, value string, ttl time.Duration) error {
+	return r.Client.Set(ctx, "idempotency:"+key, value, ttl).Err()
+}
+
+func (r *RedisClient) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	return r.Client.Del(ctx, "idempotency:"+key).Err()
+}
+
+// One-time claimer login codes, stored hashed
+func (r *RedisClient) SetLoginCode(ctx context.Context, userID, hash string, ttl time.Duration) error {
+	return r.Client.Set(ctx, "logincode:"+userID, hash, ttl).Err()
+}
+
+func (r *RedisClient) GetLoginCode(ctx context.Context, userID string) (string, error) {
+	hash, err := r.Client.Get(ctx, "logincode:"+userID).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return hash, err
+}
+
+func (r *RedisClient) DeleteLoginCode(ctx context.Context, userID string) error {
+	return r.Client.Del(ctx, "logincode:"+userID).Err()
+}
+
 // Rate limiting
 func (r *RedisClient) IncrementRateLimit(ctx context.Context, key string, window time.Duration) (int64, error) {
 	pipe := r.Client.Pipeline()
@@ -65,3 +148,64 @@ func (r *RedisClient) IncrementRateLimit(ctx context.Context, key string, window
 	}
 	return incr.Val(), nil
 }
+
+// Chat locale preferences for bots
+func (r *RedisClient) GetChatLocale(ctx context.Context, chatKey string) (string, error) {
+	locale, err := r.Client.Get(ctx, "locale:"+chatKey).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return locale, err
+}
+
+func (r *RedisClient) SetChatLocale(ctx context.Context, chatKey, locale string) error {
+	return r.Client.Set(ctx, "locale:"+chatKey, locale, 0).Err()
+}
+
+// State shared across replicas
+func (r *RedisClient) GetShared(ctx context.Context, key string) (string, error) {
+	value, err := r.Client.Get(ctx, "shared:"+key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return value, err
+}
+
+func (r *RedisClient) SetShared(ctx context.Context, key, value string) error {
+	return r.Client.Set(ctx, "shared:"+key, value, 0).Err()
+}
+
+func (r *RedisClient) IncrementShared(ctx context.Context, key string) error {
+	return r.Client.Incr(ctx, "shared:"+key).Err()
+}
+
+// TakeShared returns a counter and resets it
+func (r *RedisClient) TakeShared(ctx context.Context, key string) (int64, error) {
+	n, err := r.Client.GetSet(ctx, "shared:"+key, 0).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Read-through caches shared across replicas, empty on a miss
+func (r *RedisClient) GetCached(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.Client.Get(ctx, "cache:"+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (r *RedisClient) SetCached(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.Client.Set(ctx, "cache:"+key, value, ttl).Err()
+}
+
+// Pub/sub for live events shared across replicas
+func (r *RedisClient) Publish(ctx context.Context, channel string, payload []byte) error {
+	return r.Client.Publish(ctx, channel, payload).Err()
+}
+
+func (r *RedisClient) Subscribe(ctx context.Context, channel string) *redis.PubSub {
+	return r.Client.Subscribe(ctx, channel)
+}
@@ -2,11 +2,25 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/protocolbank/redpocket-backend/internal/model"
 )
 
+var (
+	// ErrChannelLimitReached is returned by ClaimWithPayout when the claim's
+	// channel has used up its claims or budget
+	ErrChannelLimitReached = errors.New("channel limit reached")
+
+	// ErrBudgetNotCovered is returned by Resize when the campaign's budget
+	// does not cover what the pocket grows by
+	ErrBudgetNotCovered = errors.New("campaign budget does not cover the change")
+)
+
 type RedPocketRepository struct {
 	db *PostgresDB
 }
@@ -15,91 +29,451 @@ func NewRedPocketRepository(db *PostgresDB) *RedPocketRepository {
 	return &RedPocketRepository{db: db}
 }
 
+// TryClaimLock locks key in Postgres, for claims made while Redis is down
+func (r *RedPocketRepository) TryClaimLock(ctx context.Context, key string) (release func(), acquired bool, err error) {
+	return r.db.TryAdvisoryLock(ctx, key)
+}
+
+const redPocketColumns = `
+	id, campaign_id, sender_name, sender_avatar, amount, remaining_amount,
+	token, token_address, chain_id, platform, channel_id, message, tag,
+	total_count, claimed_count, is_lucky_draw, min_amount, max_amount,
+	expires_at, created_at, status, cover_image, claim_password_hash, captcha_mode,
+	starts_at, recurrence, recurrence_until, series_id, locale,
+	decimals, amount_units, remaining_units, signed_links, channel_limits,
+	fee_plan, creation_fee, creation_fee_units, claim_fee_units, claim_fee_bps,
+	test_mode, draw_seed, draw_commitment, draw_scheme, remind_channel
+`
+
+func scanRedPocket(row interface{ Scan(...interface{}) error }) (*model.RedPocket, error) {
+	rp := &model.RedPocket{}
+	var channelLimits []byte
+	err := row.Scan(
+		&rp.ID, &rp.CampaignID, &rp.SenderName, &rp.SenderAvatar, &rp.Amount, &rp.RemainingAmount,
+		&rp.Token, &rp.TokenAddress, &rp.ChainID, &rp.Platform, &rp.ChannelID, &rp.Message, &rp.Tag,
+		&rp.TotalCount, &rp.ClaimedCount, &rp.IsLuckyDraw, &rp.MinAmount, &rp.MaxAmount,
+		&rp.ExpiresAt, &rp.CreatedAt, &rp.Status, &rp.CoverImage, &rp.ClaimPasswordHash, &rp.CaptchaMode,
+		&rp.StartsAt, &rp.Recurrence, &rp.RecurrenceUntil, &rp.SeriesID, &rp.Locale,
+		&rp.Decimals, &rp.AmountUnits, &rp.RemainingUnits, &rp.SignedLinks, &channelLimits,
+		&rp.FeePlan, &rp.CreationFee, &rp.CreationFeeUnits, &rp.ClaimFeeUnits, &rp.ClaimFeeBps,
+		&rp.TestMode, &rp.DrawSeed, &rp.DrawCommitment, &rp.DrawScheme, &rp.RemindChannel,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if channelLimits != nil {
+		if err := json.Unmarshal(channelLimits, &rp.ChannelLimits); err != nil {
+			return nil, err
+		}
+	}
+	return rp, nil
+}
+
+// marshalChannelLimits stores a pocket without channel limits as NULL
+func marshalChannelLimits(rp *model.RedPocket) ([]byte, error) {
+	if len(rp.ChannelLimits) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(rp.ChannelLimits)
+}
+
+func scanRedPockets(rows pgx.Rows) ([]*model.RedPocket, error) {
+	defer rows.Close()
+
+	var results []*model.RedPocket
+	for rows.Next() {
+		rp, err := scanRedPocket(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rp)
+	}
+	return results, rows.Err()
+}
+
 func (r *RedPocketRepository) Create(ctx context.Context, rp *model.RedPocket) error {
+	channelLimits, err := marshalChannelLimits(rp)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO red_pockets (
-			id, campaign_id, sender_name, sender_avatar, amount, remaining_amount,
-			token, token_address, chain_id, platform, channel_id, message, tag,
-			total_count, claimed_count, is_lucky_draw, min_amount, max_amount,
-			expires_at, created_at, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		INSERT INTO red_pockets (` + redPocketColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44)
 	`
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		rp.ID, rp.CampaignID, rp.SenderName, rp.SenderAvatar, rp.Amount, rp.RemainingAmount,
 		rp.Token, rp.TokenAddress, rp.ChainID, rp.Platform, rp.ChannelID, rp.Message, rp.Tag,
 		rp.TotalCount, rp.ClaimedCount, rp.IsLuckyDraw, rp.MinAmount, rp.MaxAmount,
-		rp.ExpiresAt, rp.CreatedAt, rp.Status,
+		rp.ExpiresAt, rp.CreatedAt, rp.Status, rp.CoverImage, rp.ClaimPasswordHash, rp.CaptchaMode,
+		rp.StartsAt, rp.Recurrence, rp.RecurrenceUntil, rp.SeriesID, rp.Locale,
+		rp.Decimals, rp.AmountUnits, rp.RemainingUnits, rp.SignedLinks, channelLimits,
+		rp.FeePlan, rp.CreationFee, rp.CreationFeeUnits, rp.ClaimFeeUnits, rp.ClaimFeeBps,
+		rp.TestMode, rp.DrawSeed, rp.DrawCommitment, rp.DrawScheme, rp.RemindChannel,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := insertPocketShares(ctx, tx, rp); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *RedPocketRepository) GetByID(ctx context.Context, id string) (*model.RedPocket, error) {
-	query := `
-		SELECT id, campaign_id, sender_name, sender_avatar, amount, remaining_amount,
-			token, token_address, chain_id, platform, channel_id, message, tag,
-			total_count, claimed_count, is_lucky_draw, min_amount, max_amount,
-			expires_at, created_at, status
-		FROM red_pockets WHERE id = $1
+// CreateWithinBudget creates a red pocket if its campaign's budget still
+// covers it and its creation fee, and reports false otherwise. Budget is
+// counted in the pocket's minor units and only against the campaign's own
+// token; it is committed by what has been spent, payouts still in flight and
+// the remaining amounts of pockets that are open, paused, scheduled or
+// awaiting a deposit whose deadline has not passed, so it frees up again when
+// a pocket expires, is refunded, is cancelled or goes unfunded. The campaign
+// row is locked so concurrent creations cannot overspend it. A non-nil deposit is recorded with the
+// pocket and the creation fee is booked once it arrives; without one the fee
+// is booked now. Returns pgx.ErrNoRows if the campaign does not exist.
+func (r *RedPocketRepository) CreateWithinBudget(ctx context.Context, rp *model.RedPocket, deposit *model.PocketDeposit) (bool, error) {
+	channelLimits, err := marshalChannelLimits(rp)
+	if err != nil {
+		return false, err
+	}
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	if err := tx.QueryRow(ctx, `SELECT id FROM campaigns WHERE id = $1 FOR UPDATE`, rp.CampaignID).Scan(&id); err != nil {
+		return false, err
+	}
+
+	var covered bool
+	if err := tx.QueryRow(ctx, budgetCoversQuery, rp.CampaignID, model.NewUnits(new(big.Int).Add(rp.AmountUnits.Int(), rp.CreationFeeUnits.Int())), rp.Decimals).Scan(&covered); err != nil {
+		return false, err
+	}
+	if !covered {
+		return false, nil
+	}
+
+	insert := `
+		INSERT INTO red_pockets (` + redPocketColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44)
 	`
-	rp := &model.RedPocket{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&rp.ID, &rp.CampaignID, &rp.SenderName, &rp.SenderAvatar, &rp.Amount, &rp.RemainingAmount,
-		&rp.Token, &rp.TokenAddress, &rp.ChainID, &rp.Platform, &rp.ChannelID, &rp.Message, &rp.Tag,
-		&rp.TotalCount, &rp.ClaimedCount, &rp.IsLuckyDraw, &rp.MinAmount, &rp.MaxAmount,
-		&rp.ExpiresAt, &rp.CreatedAt, &rp.Status,
+	_, err = tx.Exec(ctx, insert,
+		rp.ID, rp.CampaignID, rp.SenderName, rp.SenderAvatar, rp.Amount, rp.RemainingAmount,
+		rp.Token, rp.TokenAddress, rp.ChainID, rp.Platform, rp.ChannelID, rp.Message, rp.Tag,
+		rp.TotalCount, rp.ClaimedCount, rp.IsLuckyDraw, rp.MinAmount, rp.MaxAmount,
+		rp.ExpiresAt, rp.CreatedAt, rp.Status, rp.CoverImage, rp.ClaimPasswordHash, rp.CaptchaMode,
+		rp.StartsAt, rp.Recurrence, rp.RecurrenceUntil, rp.SeriesID, rp.Locale,
+		rp.Decimals, rp.AmountUnits, rp.RemainingUnits, rp.SignedLinks, channelLimits,
+		rp.FeePlan, rp.CreationFee, rp.CreationFeeUnits, rp.ClaimFeeUnits, rp.ClaimFeeBps,
+		rp.TestMode, rp.DrawSeed, rp.DrawCommitment, rp.DrawScheme, rp.RemindChannel,
 	)
+	if err != nil {
+		return false, err
+	}
+	if err := insertPocketShares(ctx, tx, rp); err != nil {
+		return false, err
+	}
+	if deposit != nil {
+		if err := insertPocketDeposit(ctx, tx, deposit); err != nil {
+			return false, err
+		}
+	} else if _, err := tx.Exec(ctx, bookCreationFee, rp.ID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// budgetCoversQuery reports whether what campaign $1's budget has left to
+// commit covers $2 minor units of its token at $3 decimals, as
+// CreateWithinBudget describes. Pockets and claims in any other token are
+// not counted, and each pocket's units are scaled from its own decimals.
+const budgetCoversQuery = `
+	SELECT FLOOR((camp.total_budget - camp.spent_budget) * POWER(10::NUMERIC, $3::int))
+		- COALESCE((
+			SELECT SUM((rp.remaining_units + CASE WHEN rp.status = 'awaiting_funding' THEN rp.creation_fee_units ELSE 0 END)
+				* POWER(10::NUMERIC, $3::int - rp.decimals))
+			FROM red_pockets rp
+			WHERE rp.campaign_id = camp.id AND UPPER(rp.token) = UPPER(camp.token)
+				AND (rp.status IN ('active', 'paused', 'scheduled')
+					OR (rp.status = 'awaiting_funding' AND EXISTS (
+						SELECT 1 FROM pocket_deposits d
+						WHERE d.red_pocket_id = rp.id
+							AND (d.status = 'funded' OR (d.status = 'pending' AND d.deadline > NOW()))
+					)))
+		), 0)
+		- COALESCE((
+			SELECT SUM(c.amount_units * POWER(10::NUMERIC, $3::int - rp.decimals)) FROM claims c
+			JOIN red_pockets rp ON rp.id = c.red_pocket_id
+			WHERE rp.campaign_id = camp.id AND UPPER(rp.token) = UPPER(camp.token)
+				AND c.status NOT IN ('success', 'confirmed', 'failed', 'blocked', 'refunded')
+		), 0) >= $2::numeric
+	FROM campaigns camp WHERE camp.id = $1
+`
+
+// bookCreationFee credits the creation fee of pocket $1, if it has one, to
+// the platform revenue ledger account and adds it to its campaign's spent
+// budget. A pocket's fee is booked once however often this runs.
+const bookCreationFee = `
+	WITH fee AS (
+		SELECT id, campaign_id, chain_id, token, token_address, creation_fee
+		FROM red_pockets WHERE id = $1 AND creation_fee_units > 0
+	), entry AS (
+		INSERT INTO ledger_entries (id, user_id, chain_id, token, token_address, amount, kind, ref_id, created_at)
+		SELECT 'ledger_' || left(gen_random_uuid()::text, 8), '` + model.PlatformRevenueAccount + `',
+			chain_id, token, token_address, creation_fee, 'creation_fee', id, NOW()
+		FROM fee
+		ON CONFLICT (kind, ref_id) DO NOTHING
+		RETURNING user_id, chain_id, token, token_address, amount
+	), balance AS (
+		INSERT INTO ledger_balances (user_id, chain_id, token, token_address, balance, updated_at)
+		SELECT user_id, chain_id, token, token_address, amount, NOW() FROM entry
+		ON CONFLICT (user_id, chain_id, token)
+		DO UPDATE SET balance = ledger_balances.balance + EXCLUDED.balance, updated_at = NOW()
+	)
+	UPDATE campaigns camp SET spent_budget = camp.spent_budget + e.amount, updated_at = NOW()
+	FROM entry e, fee f
+	WHERE camp.id = f.campaign_id
+`
+
+func (r *RedPocketRepository) GetByID(ctx context.Context, id string) (*model.RedPocket, error) {
+	query := `SELECT ` + redPocketColumns + ` FROM red_pockets WHERE id = $1`
+	return scanRedPocket(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// Atomic claim update with row lock - critical for high concurrency.
+// claimUnits is in the token's minor units; remaining_amount follows for display.
+func (r *RedPocketRepository) ClaimAtomic(ctx context.Context, id string, claimUnits model.Units) (*model.RedPocket, error) {
+	return scanRedPocket(r.db.Pool.QueryRow(ctx, claimAtomicQuery, id, claimUnits))
+}
+
+const claimAtomicQuery = `
+	UPDATE red_pockets 
+	SET claimed_count = claimed_count + 1,
+		remaining_units = remaining_units - $2,
+		remaining_amount = (remaining_units - $2) / POWER(10::NUMERIC, decimals),
+		status = CASE 
+			WHEN claimed_count + 1 >= total_count THEN 'depleted'
+			WHEN remaining_units - $2 <= 0 THEN 'depleted'
+			ELSE status 
+		END,
+		status_reason = CASE
+			WHEN claimed_count + 1 >= total_count THEN 'last share claimed'
+			WHEN remaining_units - $2 <= 0 THEN 'remaining amount claimed'
+			ELSE status_reason
+		END
+	WHERE id = $1 
+		AND status = 'active'
+		AND claimed_count < total_count
+		AND remaining_units >= $2
+		AND expires_at > NOW()
+	RETURNING ` + redPocketColumns
+
+// ClaimWithPayout is ClaimAtomic that also records the claim and its payout
+// job in the same transaction. The job is the outbox entry the payout
+// workers execute, so a crash can no longer leave a pocket charged for a
+// claim that was never recorded, or a claim that is never paid. job is nil
+// for claims settled without a transfer. A claim from a channel with a
+// limit counts against it. Returns pgx.ErrNoRows when the pocket cannot
+// cover the claim, ErrChannelLimitReached when the channel's limit
+// cannot, and ErrShareTaken when its lucky draw share has been taken.
+func (r *RedPocketRepository) ClaimWithPayout(ctx context.Context, claim *model.Claim, job *model.PayoutJob, limit *model.ChannelLimit) (*model.RedPocket, error) {
+	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return rp, nil
+	defer tx.Rollback(ctx)
+
+	rp, err := scanRedPocket(tx.QueryRow(ctx, claimAtomicQuery, claim.RedPocketID, claim.AmountUnits))
+	if err != nil {
+		return nil, err
+	}
+	if limit != nil {
+		query := `
+			INSERT INTO pocket_channel_usage AS u (red_pocket_id, channel_id, claimed_count, claimed_units)
+			VALUES ($1, $2, 1, $3)
+			ON CONFLICT (red_pocket_id, channel_id) DO UPDATE
+			SET claimed_count = u.claimed_count + 1, claimed_units = u.claimed_units + $3
+			WHERE ($4 = 0 OR u.claimed_count < $4)
+				AND ($5::numeric = 0 OR u.claimed_units + $3 <= $5::numeric)
+		`
+		tag, err := tx.Exec(ctx, query, claim.RedPocketID, limit.ChannelID, claim.AmountUnits, limit.MaxClaims, limit.BudgetUnits)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, ErrChannelLimitReached
+		}
+	}
+	if err := insertClaim(ctx, tx, claim); err != nil {
+		return nil, err
+	}
+	if claim.Draw != nil {
+		if err := insertLuckyDraw(ctx, tx, claim.Draw); err != nil {
+			return nil, err
+		}
+		if claim.Draw.Slot != nil {
+			if err := takePocketShare(ctx, tx, claim.Draw); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if job != nil {
+		if err := insertPayoutJob(ctx, tx, job); err != nil {
+			return nil, err
+		}
+	}
+	return rp, tx.Commit(ctx)
 }
 
-// Atomic claim update with row lock - critical for high concurrency
-func (r *RedPocketRepository) ClaimAtomic(ctx context.Context, id string, claimAmount float64) (*model.RedPocket, error) {
+// ReturnClaim undoes ClaimWithPayout for a claim that will not be paid, so
+// its amount can be claimed again, from its channel too, and its lucky draw
+// share is free to be drawn again. A pocket depleted by the claim reopens
+// unless it has expired.
+func (r *RedPocketRepository) ReturnClaim(ctx context.Context, claim *model.Claim) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		UPDATE red_pockets 
-		SET claimed_count = claimed_count + 1,
-			remaining_amount = remaining_amount - $2,
-			status = CASE 
-				WHEN claimed_count + 1 >= total_count THEN 'depleted'
-				WHEN remaining_amount - $2 <= 0 THEN 'depleted'
-				ELSE status 
+		UPDATE red_pockets
+		SET claimed_count = claimed_count - 1,
+			remaining_units = remaining_units + $2,
+			remaining_amount = (remaining_units + $2) / POWER(10::NUMERIC, decimals),
+			status = CASE
+				WHEN status = 'depleted' AND expires_at > NOW() THEN 'active'
+				ELSE status
+			END,
+			status_reason = CASE
+				WHEN status = 'depleted' AND expires_at > NOW() THEN 'unpaid claim returned'
+				ELSE status_reason
 			END
-		WHERE id = $1 
-			AND status = 'active'
-			AND claimed_count < total_count
-			AND remaining_amount >= $2
-			AND expires_at > NOW()
-		RETURNING id, campaign_id, sender_name, sender_avatar, amount, remaining_amount,
-			token, token_address, chain_id, platform, channel_id, message, tag,
-			total_count, claimed_count, is_lucky_draw, min_amount, max_amount,
-			expires_at, created_at, status
+		WHERE id = $1 AND claimed_count > 0
 	`
-	rp := &model.RedPocket{}
-	err := r.db.Pool.QueryRow(ctx, query, id, claimAmount).Scan(
-		&rp.ID, &rp.CampaignID, &rp.SenderName, &rp.SenderAvatar, &rp.Amount, &rp.RemainingAmount,
-		&rp.Token, &rp.TokenAddress, &rp.ChainID, &rp.Platform, &rp.ChannelID, &rp.Message, &rp.Tag,
-		&rp.TotalCount, &rp.ClaimedCount, &rp.IsLuckyDraw, &rp.MinAmount, &rp.MaxAmount,
-		&rp.ExpiresAt, &rp.CreatedAt, &rp.Status,
-	)
+	if _, err := tx.Exec(ctx, query, claim.RedPocketID, claim.AmountUnits); err != nil {
+		return err
+	}
+	if claim.ChannelID != "" {
+		query := `
+			UPDATE pocket_channel_usage
+			SET claimed_count = claimed_count - 1, claimed_units = claimed_units - $3
+			WHERE red_pocket_id = $1 AND channel_id = $2 AND claimed_count > 0
+		`
+		if _, err := tx.Exec(ctx, query, claim.RedPocketID, claim.ChannelID, claim.AmountUnits); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE pocket_shares SET claim_id = NULL WHERE claim_id = $1`, claim.ID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// NextShare returns the first unclaimed lucky draw share of a pocket from
+// slot from on, wrapping around to slot 0. Returns pgx.ErrNoRows when every
+// share has been claimed.
+func (r *RedPocketRepository) NextShare(ctx context.Context, id string, from int) (int, model.Units, error) {
+	query := `
+		SELECT slot, units FROM pocket_shares
+		WHERE red_pocket_id = $1 AND claim_id IS NULL
+		ORDER BY slot < $2, slot
+		LIMIT 1
+	`
+	var slot int
+	var units model.Units
+	err := r.db.Pool.QueryRow(ctx, query, id, from).Scan(&slot, &units)
+	return slot, units, err
+}
+
+// ChannelUsage returns what the claims from a pocket's channel have taken,
+// zero if there are none
+func (r *RedPocketRepository) ChannelUsage(ctx context.Context, id, channelID string) (int, model.Units, error) {
+	query := `
+		SELECT claimed_count, claimed_units FROM pocket_channel_usage
+		WHERE red_pocket_id = $1 AND channel_id = $2
+	`
+	var count int
+	var units model.Units
+	err := r.db.Pool.QueryRow(ctx, query, id, channelID).Scan(&count, &units)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, model.NewUnits(new(big.Int)), nil
+	}
+	return count, units, err
+}
+
+// ListChannelUsage returns what the claims from each of a pocket's channels
+// have taken, by channel
+func (r *RedPocketRepository) ListChannelUsage(ctx context.Context, id string) (map[string]*model.ChannelUsage, error) {
+	query := `
+		SELECT channel_id, claimed_count, claimed_units FROM pocket_channel_usage
+		WHERE red_pocket_id = $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
-	return rp, nil
+	defer rows.Close()
+
+	usage := map[string]*model.ChannelUsage{}
+	for rows.Next() {
+		u := &model.ChannelUsage{}
+		if err := rows.Scan(&u.ChannelID, &u.ClaimedCount, &u.ClaimedUnits); err != nil {
+			return nil, err
+		}
+		usage[u.ChannelID] = u
+	}
+	return usage, rows.Err()
 }
 
-func (r *RedPocketRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	query := `UPDATE red_pockets SET status = $2 WHERE id = $1`
-	_, err := r.db.Pool.Exec(ctx, query, id, status)
-	return err
+// Transition moves a red pocket to the status if its current status allows
+// it (model.PocketStatus), recording why. Returns false if it does not.
+func (r *RedPocketRepository) Transition(ctx context.Context, id string, to model.PocketStatus, reason string) (bool, error) {
+	query := `
+		UPDATE red_pockets SET status = $2, status_reason = NULLIF($3, '')
+		WHERE id = $1 AND status = ANY($4)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, to, reason, model.PocketStatusesBefore(to))
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// History returns a red pocket's status changes, oldest first
+func (r *RedPocketRepository) History(ctx context.Context, id string) ([]*model.PocketTransition, error) {
+	query := `
+		SELECT COALESCE(from_status, ''), to_status, COALESCE(reason, ''), created_at
+		FROM pocket_status_history
+		WHERE red_pocket_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*model.PocketTransition
+	for rows.Next() {
+		t := &model.PocketTransition{}
+		if err := rows.Scan(&t.From, &t.To, &t.Reason, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, t)
+	}
+	return history, rows.Err()
 }
 
 func (r *RedPocketRepository) ListByCampaign(ctx context.Context, campaignID string, limit, offset int) ([]*model.RedPocket, error) {
 	query := `
-		SELECT id, campaign_id, sender_name, sender_avatar, amount, remaining_amount,
-			token, token_address, chain_id, platform, channel_id, message, tag,
-			total_count, claimed_count, is_lucky_draw, min_amount, max_amount,
-			expires_at, created_at, status
+		SELECT ` + redPocketColumns + `
 		FROM red_pockets 
 		WHERE campaign_id = $1
 		ORDER BY created_at DESC
@@ -109,35 +483,257 @@ func (r *RedPocketRepository) ListByCampaign(ctx context.Context, campaignID str
 	if err != nil {
 		return nil, err
 	}
+	return scanRedPockets(rows)
+}
+
+// ActivateDue opens scheduled pockets whose start time has passed and returns
+// them. Each pocket is returned to exactly one caller.
+func (r *RedPocketRepository) ActivateDue(ctx context.Context, limit int) ([]*model.RedPocket, error) {
+	query := `
+		UPDATE red_pockets SET status = 'active', status_reason = 'start time reached'
+		WHERE id IN (
+			SELECT id FROM red_pockets
+			WHERE status = 'scheduled' AND starts_at <= NOW()
+			ORDER BY starts_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) AND status = 'scheduled'
+		RETURNING ` + redPocketColumns
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRedPockets(rows)
+}
+
+// ListScheduled returns an enterprise's pockets that have not started yet,
+// soonest first
+func (r *RedPocketRepository) ListScheduled(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.RedPocket, int64, error) {
+	var total int64
+	const where = `
+		WHERE status = 'scheduled'
+			AND campaign_id IN (SELECT id FROM campaigns WHERE enterprise_id = $1)
+	`
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM red_pockets`+where, enterpriseID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + redPocketColumns + ` FROM red_pockets` + where + `
+		ORDER BY starts_at
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	results, err := scanRedPockets(rows)
+	return results, total, err
+}
+
+// CancelScheduled cancels a pocket that has not started yet. Returns false if
+// it already started or does not exist.
+func (r *RedPocketRepository) CancelScheduled(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE red_pockets SET status = 'cancelled', status_reason = 'cancelled before opening' WHERE id = $1 AND status = 'scheduled'`
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Expire old red pockets - run as cron job. Open and paused pockets expire.
+// Returns the IDs it expired.
+func (r *RedPocketRepository) ExpireOld(ctx context.Context) ([]string, error) {
+	query := `
+		UPDATE red_pockets 
+		SET status = 'expired', status_reason = 'expiry reached'
+		WHERE status <> 'expired' AND status = ANY($2) AND expires_at < $1
+		RETURNING id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, time.Now(), model.PocketStatusesBefore(model.PocketExpired))
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var results []*model.RedPocket
+	var ids []string
 	for rows.Next() {
-		rp := &model.RedPocket{}
-		err := rows.Scan(
-			&rp.ID, &rp.CampaignID, &rp.SenderName, &rp.SenderAvatar, &rp.Amount, &rp.RemainingAmount,
-			&rp.Token, &rp.TokenAddress, &rp.ChainID, &rp.Platform, &rp.ChannelID, &rp.Message, &rp.Tag,
-			&rp.TotalCount, &rp.ClaimedCount, &rp.IsLuckyDraw, &rp.MinAmount, &rp.MaxAmount,
-			&rp.ExpiresAt, &rp.CreatedAt, &rp.Status,
-		)
-		if err != nil {
+		var id string
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		results = append(results, rp)
+		ids = append(ids, id)
 	}
-	return results, nil
+	return ids, rows.Err()
 }
 
-// Expire old red pockets - run as cron job
-func (r *RedPocketRepository) ExpireOld(ctx context.Context) (int64, error) {
+// Extend moves an open or paused red pocket's expiry later. Returns false
+// if it is no longer open or paused, has already expired, or already
+// expires at or after expiresAt.
+func (r *RedPocketRepository) Extend(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
 	query := `
-		UPDATE red_pockets 
-		SET status = 'expired' 
-		WHERE status = 'active' AND expires_at < $1
+		UPDATE red_pockets SET expires_at = $2
+		WHERE id = $1 AND status IN ('active', 'paused') AND expires_at > $3 AND expires_at < $2
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, expiresAt, time.Now())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Resize changes the amount and count of a red pocket, as loaded in rp, to
+// amountUnits and totalCount, adding the difference in amount to what is
+// left to claim. Growing the amount needs its campaign's budget to cover
+// the difference, as for CreateWithinBudget, with the campaign row locked,
+// or ErrBudgetNotCovered is returned. Returns false if the pocket is no
+// longer open, paused or scheduled, has expired, was resized since rp was
+// loaded, or would be left with no unclaimed share or less than a unit for
+// each. A pre-split lucky draw (rp.Shares set to its new split) is only
+// resized before anything has been drawn from it, and its shares are
+// replaced.
+//
+// A pocket paid out of its own deposit has the deposit's required amount
+// changed by the difference too, and moveDeposit is called with it before
+// the change commits, to take it into the pocket's payout wallet or refund
+// it from there; the change is rolled back if that fails.
+func (r *RedPocketRepository) Resize(ctx context.Context, rp *model.RedPocket, amountUnits model.Units, totalCount int, moveDeposit func(diff *big.Int) error) (*model.RedPocket, bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	if err := tx.QueryRow(ctx, `SELECT id FROM campaigns WHERE id = $1 FOR UPDATE`, rp.CampaignID).Scan(&id); err != nil {
+		return nil, false, err
+	}
+	added := new(big.Int).Sub(amountUnits.Int(), rp.AmountUnits.Int())
+	if added.Sign() > 0 {
+		var covered bool
+		if err := tx.QueryRow(ctx, budgetCoversQuery, rp.CampaignID, model.NewUnits(added), rp.Decimals).Scan(&covered); err != nil {
+			return nil, false, err
+		}
+		if !covered {
+			return nil, false, ErrBudgetNotCovered
+		}
+	}
+
+	query := `
+		UPDATE red_pockets rp
+		SET amount_units = $4,
+			amount = $4 / POWER(10::NUMERIC, decimals),
+			remaining_units = remaining_units + $4 - amount_units,
+			remaining_amount = (remaining_units + $4 - amount_units) / POWER(10::NUMERIC, decimals),
+			total_count = $5
+		WHERE id = $1 AND amount_units = $2 AND total_count = $3
+			AND status IN ('active', 'paused', 'scheduled') AND expires_at > NOW()
+			AND $5 > claimed_count
+			AND remaining_units + $4 - amount_units >= $5 - claimed_count
+	`
+	if rp.Shares != nil {
+		query += `
+			AND claimed_count = 0
+			AND NOT EXISTS (SELECT 1 FROM lucky_draws ld WHERE ld.red_pocket_id = rp.id)
+		`
+	}
+	query += ` RETURNING ` + redPocketColumns
+	resized, err := scanRedPocket(tx.QueryRow(ctx, query, rp.ID, rp.AmountUnits, rp.TotalCount, amountUnits, totalCount))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if rp.Shares != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM pocket_shares WHERE red_pocket_id = $1`, rp.ID); err != nil {
+			return nil, false, err
+		}
+		if err := insertPocketShares(ctx, tx, rp); err != nil {
+			return nil, false, err
+		}
+	}
+	if added.Sign() != 0 {
+		tag, err := tx.Exec(ctx, `
+			UPDATE pocket_deposits SET required_units = required_units + $2
+			WHERE red_pocket_id = $1 AND status = 'funded'
+		`, rp.ID, model.NewUnits(added))
+		if err != nil {
+			return nil, false, err
+		}
+		if tag.RowsAffected() == 1 {
+			if err := moveDeposit(added); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return resized, true, tx.Commit(ctx)
+}
+
+// ExpiringWithin returns open red pockets expiring between now+after and
+// now+within with at least minRemaining percent of their amount unclaimed,
+// that were already open when that lead began and have not had the lead's
+// reminder for their current expiry, soonest first
+func (r *RedPocketRepository) ExpiringWithin(ctx context.Context, lead string, after, within time.Duration, minRemaining, limit int) ([]*model.RedPocket, error) {
+	now := time.Now()
+	query := `
+		SELECT ` + redPocketColumns + `
+		FROM red_pockets rp
+		WHERE status = 'active' AND expires_at > $1 AND expires_at <= $2
+			AND COALESCE(starts_at, created_at) <= expires_at - $3 * INTERVAL '1 second'
+			AND remaining_units * 100 >= amount_units * $4
+			AND NOT EXISTS (
+				SELECT 1 FROM pocket_expiry_reminders er
+				WHERE er.red_pocket_id = rp.id AND er.expires_at = rp.expires_at AND er.lead = $5
+			)
+		ORDER BY expires_at
+		LIMIT $6
+	`
+	rows, err := r.db.Pool.Query(ctx, query, now.Add(after), now.Add(within), within.Seconds(), minRemaining, lead, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRedPockets(rows)
+}
+
+// MarkReminded records that a red pocket's reminder for lead before
+// expiresAt is being sent, reporting false when another sweep already took it
+func (r *RedPocketRepository) MarkReminded(ctx context.Context, id string, expiresAt time.Time, lead string) (bool, error) {
+	query := `
+		INSERT INTO pocket_expiry_reminders (red_pocket_id, expires_at, lead)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, expiresAt, lead)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListUnarchived returns IDs of finished red pockets that have no summary yet
+func (r *RedPocketRepository) ListUnarchived(ctx context.Context, limit int) ([]string, error) {
+	query := `
+		SELECT rp.id FROM red_pockets rp
+		LEFT JOIN red_pocket_summaries s ON s.red_pocket_id = rp.id
+		WHERE rp.status IN ('depleted', 'expired', 'cancelled', 'refunded') AND s.red_pocket_id IS NULL
+		ORDER BY rp.created_at ASC
+		LIMIT $1
 	`
-	result, err := r.db.Pool.Exec(ctx, query, time.Now())
+	rows, err := r.db.Pool.Query(ctx, query, limit)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
 	}
-	return result.RowsAffected(), nil
+	return ids, nil
 }
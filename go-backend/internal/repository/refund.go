@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type RefundRepository struct {
+	db *PostgresDB
+}
+
+func NewRefundRepository(db *PostgresDB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+// Create takes the remaining amount of an expired or cancelled red pocket,
+// and the amount of its failed claims, and records them as a refund in one
+// transaction, so they can only be refunded once. The failed claims become
+// refunded. The refund's amount is filled in; returns false without writing
+// anything if the pocket is not expired or cancelled or has nothing left.
+func (r *RefundRepository) Create(ctx context.Context, f *model.Refund) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := createRefund(ctx, tx, f)
+	if err != nil || !created {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// Cancel cancels an open or paused red pocket and queues the refund of its
+// remainder in the same transaction. Claims lock the pocket's row and only
+// take from open pockets, so once it is cancelled no claim can take what the
+// refund counted. Returns whether the pocket was cancelled, and whether a
+// refund was queued; there is none when nothing is left.
+func (r *RefundRepository) Cancel(ctx context.Context, f *model.Refund, reason string) (cancelled, refunded bool, err error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE red_pockets SET status = 'cancelled', status_reason = NULLIF($2, '')
+		WHERE id = $1 AND status IN ('active', 'paused')
+	`
+	tag, err := tx.Exec(ctx, query, f.RedPocketID, reason)
+	if err != nil || tag.RowsAffected() == 0 {
+		return false, false, err
+	}
+	if refunded, err = createRefund(ctx, tx, f); err != nil {
+		return false, false, err
+	}
+	return true, refunded, tx.Commit(ctx)
+}
+
+// createRefund is Create within tx, leaving the commit to the caller
+func createRefund(ctx context.Context, tx pgx.Tx, f *model.Refund) (bool, error) {
+	var remaining float64
+	var remainingUnits model.Units
+	query := `SELECT remaining_amount, remaining_units FROM red_pockets WHERE id = $1 AND status IN ('expired', 'cancelled') FOR UPDATE`
+	err := tx.QueryRow(ctx, query, f.RedPocketID).Scan(&remaining, &remainingUnits)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var unpaid float64
+	var unpaidUnits model.Units
+	query = `
+		WITH refunded AS (
+			UPDATE claims SET status = 'refunded', status_reason = 'returned with refund ' || $2
+			WHERE red_pocket_id = $1 AND status <> 'refunded' AND status = ANY($3)
+			RETURNING amount, amount_units
+		)
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(amount_units), 0) FROM refunded
+	`
+	err = tx.QueryRow(ctx, query, f.RedPocketID, f.ID, model.ClaimStatusesBefore(model.ClaimRefunded)).Scan(&unpaid, &unpaidUnits)
+	if err != nil {
+		return false, err
+	}
+
+	f.Amount = remaining + unpaid
+	f.AmountUnits = model.NewUnits(new(big.Int).Add(remainingUnits.Int(), unpaidUnits.Int()))
+	if f.AmountUnits.Sign() <= 0 {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, `UPDATE red_pockets SET remaining_amount = 0, remaining_units = 0 WHERE id = $1`, f.RedPocketID); err != nil {
+		return false, err
+	}
+
+	query = `
+		INSERT INTO red_pocket_refunds (
+			id, red_pocket_id, recipient_id, chain_id, token, token_address, amount,
+			from_address, to_address, status, triggered_by, created_at, amount_units
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err = tx.Exec(ctx, query,
+		f.ID, f.RedPocketID, f.RecipientID, f.ChainID, f.Token, f.TokenAddress, f.Amount,
+		f.FromAddress, f.ToAddress, f.Status, f.Trigger, f.CreatedAt, f.AmountUnits,
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+const refundColumns = `
+	id, red_pocket_id, recipient_id, chain_id, token, token_address, amount, from_address, to_address,
+	status, triggered_by, COALESCE(tx_hash, ''), COALESCE(user_op_hash, ''), COALESCE(error, ''), created_at, completed_at,
+	amount_units
+`
+
+func scanRefund(row interface{ Scan(...interface{}) error }) (*model.Refund, error) {
+	f := &model.Refund{}
+	err := row.Scan(
+		&f.ID, &f.RedPocketID, &f.RecipientID, &f.ChainID, &f.Token, &f.TokenAddress, &f.Amount, &f.FromAddress, &f.ToAddress,
+		&f.Status, &f.Trigger, &f.TxHash, &f.UserOpHash, &f.Error, &f.CreatedAt, &f.CompletedAt,
+		&f.AmountUnits,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (r *RefundRepository) GetByRedPocket(ctx context.Context, redPocketID string) (*model.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM red_pocket_refunds WHERE red_pocket_id = $1`
+	return scanRefund(r.db.Pool.QueryRow(ctx, query, redPocketID))
+}
+
+// ListRefundable returns IDs of expired red pockets not refunded yet that
+// have a remainder or failed claims
+func (r *RefundRepository) ListRefundable(ctx context.Context, limit int) ([]string, error) {
+	query := `
+		SELECT rp.id FROM red_pockets rp
+		WHERE rp.status = 'expired'
+			AND NOT EXISTS (SELECT 1 FROM red_pocket_refunds f WHERE f.red_pocket_id = rp.id)
+			AND (rp.remaining_units > 0 OR EXISTS (
+				SELECT 1 FROM claims c
+				WHERE c.red_pocket_id = rp.id AND c.status <> 'refunded' AND c.status = ANY($2)
+			))
+		ORDER BY rp.expires_at ASC
+		LIMIT $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, limit, model.ClaimStatusesBefore(model.ClaimRefunded))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListOpen returns pending refunds and processing ones waiting on a user
+// operation, oldest first
+func (r *RefundRepository) ListOpen(ctx context.Context, limit int) ([]*model.Refund, error) {
+	query := `
+		SELECT ` + refundColumns + `
+		FROM red_pocket_refunds
+		WHERE status = 'pending' OR (status = 'processing' AND user_op_hash IS NOT NULL)
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []*model.Refund
+	for rows.Next() {
+		f, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, f)
+	}
+	return refunds, rows.Err()
+}
+
+// Start moves a pending refund to processing. Returns false if another
+// worker got there first.
+func (r *RefundRepository) Start(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE red_pocket_refunds SET status = 'processing' WHERE id = $1 AND status = 'pending'`
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Retry puts a failed refund back to pending
+func (r *RefundRepository) Retry(ctx context.Context, id, trigger string) (bool, error) {
+	query := `
+		UPDATE red_pocket_refunds
+		SET status = 'pending', triggered_by = $2, error = NULL, user_op_hash = NULL, completed_at = NULL
+		WHERE id = $1 AND status = 'failed'
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, trigger)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *RefundRepository) Update(ctx context.Context, id, status, txHash, userOpHash, errMsg string) error {
+	query := `
+		UPDATE red_pocket_refunds
+		SET status = $2, tx_hash = NULLIF($3, ''), user_op_hash = COALESCE(NULLIF($4, ''), user_op_hash), error = NULLIF($5, ''),
+			completed_at = CASE WHEN $2 IN ('success', 'failed') THEN NOW() ELSE completed_at END
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, txHash, userOpHash, errMsg)
+	return err
+}
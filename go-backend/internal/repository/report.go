@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type ReportRepository struct {
+	db *PostgresDB
+}
+
+func NewReportRepository(db *PostgresDB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// reportClaims selects the claims of an enterprise's campaigns that match a
+// report filter ($1 enterprise, $2 campaign or '', $3 from, $4 to)
+const reportClaims = `
+	FROM claims c
+	JOIN red_pockets rp ON rp.id = c.red_pocket_id
+	JOIN campaigns camp ON camp.id = rp.campaign_id
+	WHERE camp.enterprise_id = $1 AND ($2 = '' OR camp.id = $2)
+		AND ($3::timestamptz IS NULL OR c.created_at >= $3) AND ($4::timestamptz IS NULL OR c.created_at < $4)
+`
+
+// analyticsDays is the daily activity per campaign and token behind the
+// analytics report, with the same parameters as reportClaims
+const analyticsDays = `
+	WITH claim_days AS (
+		SELECT date_trunc('day', c.created_at AT TIME ZONE 'UTC') AS day, camp.id AS campaign_id, rp.chain_id, rp.token,
+			COUNT(*) AS claims, COUNT(DISTINCT c.claimer_id) AS claimers,
+			SUM(c.amount) AS amount, SUM(c.fee_amount) AS fees, SUM(c.donation_amount) AS donations
+		` + reportClaims + `
+			AND c.status NOT IN ('failed', 'blocked', 'refunded')
+		GROUP BY 1, 2, 3, 4
+	), pocket_days AS (
+		SELECT date_trunc('day', rp.created_at AT TIME ZONE 'UTC') AS day, camp.id AS campaign_id, rp.chain_id, rp.token,
+			COUNT(*) AS pockets
+		FROM red_pockets rp
+		JOIN campaigns camp ON camp.id = rp.campaign_id
+		WHERE camp.enterprise_id = $1 AND ($2 = '' OR camp.id = $2)
+			AND ($3::timestamptz IS NULL OR rp.created_at >= $3) AND ($4::timestamptz IS NULL OR rp.created_at < $4)
+		GROUP BY 1, 2, 3, 4
+	), days AS (
+		SELECT COALESCE(cd.day, pd.day) AS day, COALESCE(cd.campaign_id, pd.campaign_id) AS campaign_id,
+			COALESCE(cd.chain_id, pd.chain_id) AS chain_id, COALESCE(cd.token, pd.token) AS token,
+			COALESCE(pd.pockets, 0) AS pockets, COALESCE(cd.claims, 0) AS claims, COALESCE(cd.claimers, 0) AS claimers,
+			COALESCE(cd.amount, 0) AS amount, COALESCE(cd.fees, 0) AS fees, COALESCE(cd.donations, 0) AS donations
+		FROM claim_days cd
+		FULL JOIN pocket_days pd
+			ON pd.day = cd.day AND pd.campaign_id = cd.campaign_id AND pd.chain_id = cd.chain_id AND pd.token = cd.token
+	)
+`
+
+func reportArgs(f *model.ReportFilter) []any {
+	return []any{f.EnterpriseID, f.CampaignID, f.From, f.To}
+}
+
+// CountClaims counts the claims a claims report would have
+func (r *ReportRepository) CountClaims(ctx context.Context, f *model.ReportFilter) (int64, error) {
+	var n int64
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) `+reportClaims, reportArgs(f)...).Scan(&n)
+	return n, err
+}
+
+// EachClaim calls fn with every claim matching f, oldest first, without
+// holding them all in memory. It stops at the first error fn returns.
+func (r *ReportRepository) EachClaim(ctx context.Context, f *model.ReportFilter, fn func(*model.ClaimReportRow) error) error {
+	query := `
+		SELECT c.id, c.created_at, c.completed_at, camp.id, camp.name, rp.id, c.platform, c.platform_id, c.wallet_address,
+			rp.chain_id, rp.token, c.amount, c.fee_amount, c.donation_amount, c.status, COALESCE(c.tx_hash, '')
+		` + reportClaims + `
+		ORDER BY c.created_at, c.id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, reportArgs(f)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	row := &model.ClaimReportRow{}
+	for rows.Next() {
+		err := rows.Scan(
+			&row.ID, &row.CreatedAt, &row.CompletedAt, &row.CampaignID, &row.CampaignName, &row.RedPocketID,
+			&row.Platform, &row.PlatformID, &row.WalletAddress,
+			&row.ChainID, &row.Token, &row.Amount, &row.Fee, &row.Donation, &row.Status, &row.TxHash,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CountAnalytics counts the rows an analytics report would have
+func (r *ReportRepository) CountAnalytics(ctx context.Context, f *model.ReportFilter) (int64, error) {
+	var n int64
+	err := r.db.Pool.QueryRow(ctx, analyticsDays+`SELECT COUNT(*) FROM days`, reportArgs(f)...).Scan(&n)
+	return n, err
+}
+
+// EachAnalyticsDay calls fn with each day of activity matching f, oldest
+// first. It stops at the first error fn returns.
+func (r *ReportRepository) EachAnalyticsDay(ctx context.Context, f *model.ReportFilter, fn func(*model.AnalyticsReportRow) error) error {
+	query := analyticsDays + `
+		SELECT d.day, d.campaign_id, camp.name, d.chain_id, d.token,
+			d.pockets, d.claims, d.claimers, d.amount, d.fees, d.donations
+		FROM days d
+		JOIN campaigns camp ON camp.id = d.campaign_id
+		ORDER BY d.day, camp.name, d.campaign_id, d.chain_id, d.token
+	`
+	rows, err := r.db.Pool.Query(ctx, query, reportArgs(f)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	row := &model.AnalyticsReportRow{}
+	for rows.Next() {
+		err := rows.Scan(
+			&row.Day, &row.CampaignID, &row.CampaignName, &row.ChainID, &row.Token,
+			&row.Pockets, &row.Claims, &row.Claimers, &row.Amount, &row.Fees, &row.Donations,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+const reportExportColumns = `
+	id, kind, format, campaign_id, from_at, to_at, status, row_count, error, created_at, completed_at, expires_at
+`
+
+func scanReportExport(row interface{ Scan(...any) error }, e *model.ReportExport) error {
+	return row.Scan(
+		&e.ID, &e.Kind, &e.Format, &e.Filter.CampaignID, &e.Filter.From, &e.Filter.To,
+		&e.Status, &e.Rows, &e.Error, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt,
+	)
+}
+
+// CreateExport queues a report export
+func (r *ReportRepository) CreateExport(ctx context.Context, e *model.ReportExport) error {
+	query := `
+		INSERT INTO report_exports (id, enterprise_id, kind, format, campaign_id, from_at, to_at, status, row_count, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		e.ID, e.Filter.EnterpriseID, e.Kind, e.Format, e.Filter.CampaignID, e.Filter.From, e.Filter.To,
+		e.Status, e.Rows, e.CreatedAt, e.ExpiresAt,
+	)
+	return err
+}
+
+// GetExport returns one of an enterprise's unexpired exports
+func (r *ReportRepository) GetExport(ctx context.Context, id, enterpriseID string) (*model.ReportExport, error) {
+	query := `SELECT ` + reportExportColumns + ` FROM report_exports WHERE id = $1 AND enterprise_id = $2 AND expires_at > NOW()`
+	e := &model.ReportExport{}
+	if err := scanReportExport(r.db.Pool.QueryRow(ctx, query, id, enterpriseID), e); err != nil {
+		return nil, err
+	}
+	e.Filter.EnterpriseID = enterpriseID
+	return e, nil
+}
+
+// ExportContent returns a ready, unexpired export with its file
+func (r *ReportRepository) ExportContent(ctx context.Context, id string) (*model.ReportExport, []byte, error) {
+	query := `SELECT ` + reportExportColumns + `, content FROM report_exports WHERE id = $1 AND status = 'ready' AND expires_at > NOW()`
+	e := &model.ReportExport{}
+	var content []byte
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&e.ID, &e.Kind, &e.Format, &e.Filter.CampaignID, &e.Filter.From, &e.Filter.To,
+		&e.Status, &e.Rows, &e.Error, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt, &content,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, content, nil
+}
+
+// DequeueExport takes the oldest pending export and marks it running.
+// Exports left running for longer than stuckAfter, by a worker that died,
+// are taken again. Returns nil when there is none.
+func (r *ReportRepository) DequeueExport(ctx context.Context, stuckAfter time.Duration) (*model.ReportExport, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM report_exports
+			WHERE (status = 'pending' OR (status = 'running' AND locked_at < $1)) AND expires_at > NOW()
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		UPDATE report_exports e
+		SET status = 'running', locked_at = NOW()
+		FROM due
+		WHERE e.id = due.id
+		RETURNING ` + reportExportColumns + `, e.enterprise_id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, time.Now().Add(-stuckAfter))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	e := &model.ReportExport{}
+	err = rows.Scan(
+		&e.ID, &e.Kind, &e.Format, &e.Filter.CampaignID, &e.Filter.From, &e.Filter.To,
+		&e.Status, &e.Rows, &e.Error, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt, &e.Filter.EnterpriseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// CompleteExport stores a generated export's file
+func (r *ReportRepository) CompleteExport(ctx context.Context, id string, rows int64, content []byte) error {
+	query := `
+		UPDATE report_exports
+		SET status = 'ready', row_count = $2, content = $3, completed_at = NOW(), locked_at = NULL
+		WHERE id = $1 AND status = 'running'
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, rows, content)
+	return err
+}
+
+// FailExport records why an export could not be generated
+func (r *ReportRepository) FailExport(ctx context.Context, id, reason string) error {
+	query := `
+		UPDATE report_exports
+		SET status = 'failed', error = $2, completed_at = NOW(), locked_at = NULL
+		WHERE id = $1 AND status = 'running'
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, reason)
+	return err
+}
+
+// DeleteExpiredExports removes exports past their expiry with their files
+func (r *ReportRepository) DeleteExpiredExports(ctx context.Context) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM report_exports WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type SanctionsRepository struct {
+	db *PostgresDB
+}
+
+func NewSanctionsRepository(db *PostgresDB) *SanctionsRepository {
+	return &SanctionsRepository{db: db}
+}
+
+func (r *SanctionsRepository) CreateScreening(ctx context.Context, s *model.AddressScreening) error {
+	query := `
+		INSERT INTO address_screenings (id, address, provider, result, reference, subject_type, subject_id, user_id, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		s.ID, s.Address, s.Provider, s.Result, s.Reference, s.SubjectType, s.SubjectID, s.UserID, s.CreatedAt,
+	)
+	return err
+}
+
+// IsListed reports whether address is on the synced sanctions list, and
+// which list
+func (r *SanctionsRepository) IsListed(ctx context.Context, address string) (bool, string, error) {
+	query := `SELECT source FROM sanctioned_addresses WHERE address = $1`
+	var source string
+	err := r.db.Pool.QueryRow(ctx, query, strings.ToLower(address)).Scan(&source)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, source, nil
+}
+
+// ReplaceList swaps the addresses of one list for a freshly synced copy in a
+// single transaction, so screening never sees a half-written list
+func (r *SanctionsRepository) ReplaceList(ctx context.Context, source string, addresses []string) error {
+	lower := make([]string, len(addresses))
+	for i, a := range addresses {
+		lower[i] = strings.ToLower(a)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sanctioned_addresses WHERE source = $1`, source); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO sanctioned_addresses (address, source, synced_at)
+		SELECT a, $2, NOW() FROM unnest($1::TEXT[]) AS a
+		ON CONFLICT (address) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, lower, source); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type SavingsRepository struct {
+	db *PostgresDB
+}
+
+func NewSavingsRepository(db *PostgresDB) *SavingsRepository {
+	return &SavingsRepository{db: db}
+}
+
+const vaultColumns = `id, name, protocol, chain_id, address, asset_address, share_address, enabled, created_at`
+
+func scanVault(row interface{ Scan(...interface{}) error }) (*model.YieldVault, error) {
+	v := &model.YieldVault{}
+	err := row.Scan(&v.ID, &v.Name, &v.Protocol, &v.ChainID, &v.Address, &v.AssetAddress, &v.ShareAddress, &v.Enabled, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetVault returns a vault, enabled or not, or pgx.ErrNoRows
+func (r *SavingsRepository) GetVault(ctx context.Context, id string) (*model.YieldVault, error) {
+	query := `SELECT ` + vaultColumns + ` FROM yield_vaults WHERE id = $1`
+	return scanVault(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListVaults returns the enabled vaults of a chain, or of every chain for 0
+func (r *SavingsRepository) ListVaults(ctx context.Context, chainID int64) ([]*model.YieldVault, error) {
+	query := `
+		SELECT ` + vaultColumns + ` FROM yield_vaults
+		WHERE enabled AND ($1 = 0 OR chain_id = $1)
+		ORDER BY chain_id, name
+	`
+	rows, err := r.db.Pool.Query(ctx, query, chainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vaults := []*model.YieldVault{}
+	for rows.Next() {
+		v, err := scanVault(rows)
+		if err != nil {
+			return nil, err
+		}
+		vaults = append(vaults, v)
+	}
+	return vaults, rows.Err()
+}
+
+// GetPreference returns a user's savings vault, or pgx.ErrNoRows if they
+// never chose one
+func (r *SavingsRepository) GetPreference(ctx context.Context, userID string) (*model.SavingsPreference, error) {
+	query := `SELECT user_id, vault_id, updated_at FROM savings_preferences WHERE user_id = $1`
+	p := &model.SavingsPreference{}
+	if err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&p.UserID, &p.VaultID, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPreference sets a user's savings vault
+func (r *SavingsRepository) SetPreference(ctx context.Context, p *model.SavingsPreference) error {
+	query := `
+		INSERT INTO savings_preferences (user_id, vault_id, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET vault_id = EXCLUDED.vault_id, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	return r.db.Pool.QueryRow(ctx, query, p.UserID, p.VaultID).Scan(&p.UpdatedAt)
+}
+
+// DeletePreference stops saving a user's payouts
+func (r *SavingsRepository) DeletePreference(ctx context.Context, userID string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM savings_preferences WHERE user_id = $1`, userID)
+	return err
+}
+
+// VaultIDsByUser returns the vaults a user has saved in or withdrawn from
+func (r *SavingsRepository) VaultIDsByUser(ctx context.Context, userID string) ([]string, error) {
+	query := `
+		SELECT savings_vault_id FROM claims WHERE claimer_id = $1 AND savings_vault_id IS NOT NULL
+		UNION
+		SELECT vault_id FROM vault_withdrawals WHERE user_id = $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Deposited totals what a user's paid claims deposited into a vault
+func (r *SavingsRepository) Deposited(ctx context.Context, userID, vaultID string) (model.Units, error) {
+	query := `SELECT SUM(savings_units) FROM claims WHERE claimer_id = $1 AND savings_vault_id = $2 AND status IN ('success', 'confirmed')`
+	var total model.Units
+	err := r.db.Pool.QueryRow(ctx, query, userID, vaultID).Scan(&total)
+	return total, err
+}
+
+// Withdrawn totals a user's withdrawals from a vault that went through
+func (r *SavingsRepository) Withdrawn(ctx context.Context, userID, vaultID string) (model.Units, error) {
+	query := `SELECT SUM(amount_units) FROM vault_withdrawals WHERE user_id = $1 AND vault_id = $2 AND status = 'success'`
+	var total model.Units
+	err := r.db.Pool.QueryRow(ctx, query, userID, vaultID).Scan(&total)
+	return total, err
+}
+
+func (r *SavingsRepository) CreateWithdrawal(ctx context.Context, w *model.VaultWithdrawal) error {
+	query := `
+		INSERT INTO vault_withdrawals (id, user_id, vault_id, wallet_address, amount_units, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Pool.Exec(ctx, query, w.ID, w.UserID, w.VaultID, w.WalletAddress, w.AmountUnits, w.Status, w.CreatedAt)
+	return err
+}
+
+// UpdateWithdrawal records the outcome of a vault withdrawal
+func (r *SavingsRepository) UpdateWithdrawal(ctx context.Context, w *model.VaultWithdrawal) error {
+	query := `
+		UPDATE vault_withdrawals
+		SET status = $2, tx_hash = NULLIF($3, ''), user_op_hash = NULLIF($4, ''), error = NULLIF($5, ''),
+			completed_at = CASE WHEN $2 IN ('success', 'failed') THEN NOW() ELSE completed_at END
+		WHERE id = $1
+		RETURNING completed_at
+	`
+	return r.db.Pool.QueryRow(ctx, query, w.ID, w.Status, w.TxHash, w.UserOpHash, w.Error).Scan(&w.CompletedAt)
+}
+
+// ListWithdrawals returns a user's vault withdrawals, newest first
+func (r *SavingsRepository) ListWithdrawals(ctx context.Context, userID string, limit int) ([]*model.VaultWithdrawal, error) {
+	query := `
+		SELECT id, user_id, vault_id, wallet_address, amount_units, status, COALESCE(tx_hash, ''),
+			COALESCE(user_op_hash, ''), COALESCE(error, ''), created_at, completed_at
+		FROM vault_withdrawals WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	withdrawals := []*model.VaultWithdrawal{}
+	for rows.Next() {
+		w := &model.VaultWithdrawal{}
+		err := rows.Scan(&w.ID, &w.UserID, &w.VaultID, &w.WalletAddress, &w.AmountUnits, &w.Status, &w.TxHash,
+			&w.UserOpHash, &w.Error, &w.CreatedAt, &w.CompletedAt)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return withdrawals, rows.Err()
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protocolbank/redpocket-backend/internal/encryption"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// SlackInstallationRepository stores Slack workspace installations with their
+// bot tokens sealed by the encryptor
+type SlackInstallationRepository struct {
+	db  *PostgresDB
+	enc *encryption.Encryptor
+}
+
+func NewSlackInstallationRepository(db *PostgresDB, enc *encryption.Encryptor) *SlackInstallationRepository {
+	return &SlackInstallationRepository{db: db, enc: enc}
+}
+
+// SaveSlackInstallation records an installation, replacing an earlier one of
+// the same workspace
+func (r *SlackInstallationRepository) SaveSlackInstallation(ctx context.Context, in *model.SlackInstallation) error {
+	sealed, err := r.enc.Encrypt(ctx, in.BotToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt bot token: %w", err)
+	}
+
+	query := `
+		INSERT INTO slack_installations (team_id, team_name, enterprise_id, bot_user_id, bot_token, scope, installed_by, installed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (team_id) DO UPDATE SET
+			team_name = EXCLUDED.team_name,
+			enterprise_id = EXCLUDED.enterprise_id,
+			bot_user_id = EXCLUDED.bot_user_id,
+			bot_token = EXCLUDED.bot_token,
+			scope = EXCLUDED.scope,
+			installed_by = EXCLUDED.installed_by,
+			installed_at = EXCLUDED.installed_at
+	`
+	_, err = r.db.Pool.Exec(ctx, query,
+		in.TeamID, in.TeamName, in.EnterpriseID, in.BotUserID, sealed, in.Scope, in.InstalledBy, in.InstalledAt,
+	)
+	return err
+}
+
+// GetSlackInstallation returns a workspace's installation with its bot token,
+// or pgx.ErrNoRows
+func (r *SlackInstallationRepository) GetSlackInstallation(ctx context.Context, teamID string) (*model.SlackInstallation, error) {
+	query := `
+		SELECT team_id, team_name, enterprise_id, bot_user_id, bot_token, scope, installed_by, installed_at
+		FROM slack_installations
+		WHERE team_id = $1
+	`
+	in := &model.SlackInstallation{}
+	err := r.db.Pool.QueryRow(ctx, query, teamID).Scan(
+		&in.TeamID, &in.TeamName, &in.EnterpriseID, &in.BotUserID, &in.BotToken, &in.Scope, &in.InstalledBy, &in.InstalledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := r.enc.Decrypt(ctx, in.BotToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt bot token of workspace %s: %w", teamID, err)
+	}
+	in.BotToken = plaintext
+	return in, nil
+}
+
+// ListSlackInstallations returns the enterprise's workspaces without their
+// tokens
+func (r *SlackInstallationRepository) ListSlackInstallations(ctx context.Context, enterpriseID string) ([]*model.SlackInstallation, error) {
+	query := `
+		SELECT team_id, team_name, enterprise_id, bot_user_id, scope, installed_by, installed_at
+		FROM slack_installations
+		WHERE enterprise_id = $1
+		ORDER BY installed_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	installs := []*model.SlackInstallation{}
+	for rows.Next() {
+		in := &model.SlackInstallation{}
+		if err := rows.Scan(&in.TeamID, &in.TeamName, &in.EnterpriseID, &in.BotUserID, &in.Scope, &in.InstalledBy, &in.InstalledAt); err != nil {
+			return nil, err
+		}
+		installs = append(installs, in)
+	}
+	return installs, rows.Err()
+}
+
+// DeleteSlackInstallation forgets a workspace that uninstalled the app or
+// revoked its token
+func (r *SlackInstallationRepository) DeleteSlackInstallation(ctx context.Context, teamID string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM slack_installations WHERE team_id = $1`, teamID)
+	return err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+//go:generate moq -out mocks/stores.go -pkg mocks . RedPocketStore ClaimStore WalletStore CampaignStore
+
+// The stores services depend on instead of the Postgres repositories, so
+// they can run against memstore or the generated mocks in tests. Each
+// method behaves as documented on the repository implementing it.
+
+// RedPocketStore is implemented by RedPocketRepository
+type RedPocketStore interface {
+	TryClaimLock(ctx context.Context, key string) (release func(), acquired bool, err error)
+	Create(ctx context.Context, rp *model.RedPocket) error
+	CreateWithinBudget(ctx context.Context, rp *model.RedPocket, deposit *model.PocketDeposit) (bool, error)
+	GetByID(ctx context.Context, id string) (*model.RedPocket, error)
+	ClaimAtomic(ctx context.Context, id string, claimUnits model.Units) (*model.RedPocket, error)
+	ClaimWithPayout(ctx context.Context, claim *model.Claim, job *model.PayoutJob, limit *model.ChannelLimit) (*model.RedPocket, error)
+	ReturnClaim(ctx context.Context, claim *model.Claim) error
+	NextShare(ctx context.Context, id string, from int) (int, model.Units, error)
+	ChannelUsage(ctx context.Context, id, channelID string) (int, model.Units, error)
+	ListChannelUsage(ctx context.Context, id string) (map[string]*model.ChannelUsage, error)
+	Transition(ctx context.Context, id string, to model.PocketStatus, reason string) (bool, error)
+	History(ctx context.Context, id string) ([]*model.PocketTransition, error)
+	ListByCampaign(ctx context.Context, campaignID string, limit, offset int) ([]*model.RedPocket, error)
+	ActivateDue(ctx context.Context, limit int) ([]*model.RedPocket, error)
+	ListScheduled(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.RedPocket, int64, error)
+	CancelScheduled(ctx context.Context, id string) (bool, error)
+	ExpireOld(ctx context.Context) ([]string, error)
+	Extend(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+	Resize(ctx context.Context, rp *model.RedPocket, amountUnits model.Units, totalCount int, moveDeposit func(diff *big.Int) error) (*model.RedPocket, bool, error)
+	ExpiringWithin(ctx context.Context, lead string, after, within time.Duration, minRemaining, limit int) ([]*model.RedPocket, error)
+	MarkReminded(ctx context.Context, id string, expiresAt time.Time, lead string) (bool, error)
+	ListUnarchived(ctx context.Context, limit int) ([]string, error)
+}
+
+// ClaimStore is implemented by ClaimRepository
+type ClaimStore interface {
+	Create(ctx context.Context, c *model.Claim) error
+	GetByID(ctx context.Context, id string) (*model.Claim, error)
+	HasClaimed(ctx context.Context, redPocketID, platformID, platform string) (bool, error)
+	Transition(ctx context.Context, id string, to model.ClaimStatus, txHash, reason string) (bool, error)
+	History(ctx context.Context, id string) ([]*model.ClaimTransition, error)
+	SetScreening(ctx context.Context, id, screeningID, result string) error
+	ListByRedPocket(ctx context.Context, redPocketID string, limit, offset int) ([]*model.Claim, error)
+	ListByCampaign(ctx context.Context, campaignID string, limit, offset int) ([]*model.Claim, int64, error)
+	ListByEnterprise(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.Claim, int64, error)
+	ListAllByRedPocket(ctx context.Context, redPocketID string) ([]*model.Claim, error)
+	MarkResubmitted(ctx context.Context, id string, attempts int, reason string) (bool, error)
+	TransitionBatch(ctx context.Context, batchID string, to model.ClaimStatus, txHash, reason string) (int, error)
+	IDsByBatch(ctx context.Context, batchID string) ([]string, error)
+	MarkResubmittedByBatch(ctx context.Context, batchID string, attempts int, reason string) error
+	LinkUsed(ctx context.Context, linkID string) (bool, error)
+	FirstClaimAt(ctx context.Context, platform, platformID string) (*time.Time, error)
+	ListByClaimer(ctx context.Context, platform, platformID string, limit, offset int) ([]*model.ClaimerClaim, int64, error)
+	ClaimerTotals(ctx context.Context, platform, platformID string) ([]*model.ClaimerTotal, error)
+	ClaimerChainSummary(ctx context.Context, claimerID string) ([]*model.ChainClaimSummary, error)
+	Leaderboard(ctx context.Context, redPocketID string, limit int) ([]*model.LeaderboardEntry, error)
+	ClaimedTotals(ctx context.Context, redPocketID string) (int, float64, error)
+}
+
+// WalletStore is implemented by WalletRepository. Rotating keys is left to
+// the repository, as only cmd/rotate-keys does it.
+type WalletStore interface {
+	Create(ctx context.Context, w *model.Wallet) error
+	GetByUserID(ctx context.Context, userID string, chainID int64) (*model.Wallet, error)
+	GetByAddress(ctx context.Context, address string) (*model.Wallet, error)
+	UpdateDeployed(ctx context.Context, id string, deployed bool) error
+	ListByUser(ctx context.Context, userID string) ([]*model.Wallet, error)
+}
+
+// CampaignStore is implemented by CampaignRepository
+type CampaignStore interface {
+	Create(ctx context.Context, c *model.Campaign) error
+	GetByID(ctx context.Context, id string) (*model.Campaign, error)
+	ListByEnterprise(ctx context.Context, enterpriseID string, limit, offset int) ([]*model.Campaign, int64, error)
+	Update(ctx context.Context, c *model.Campaign) error
+	IncrementStats(ctx context.Context, id string, spentAmount float64, claimsCount int) error
+	IncrementPockets(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+	GetAnalytics(ctx context.Context, enterpriseID string) (*model.CampaignAnalytics, error)
+	GetClaimPage(ctx context.Context, id string) (*model.ClaimPageConfig, error)
+	SetClaimPage(ctx context.Context, id string, page *model.ClaimPageConfig) error
+	GetTerms(ctx context.Context, id string) (*model.CampaignTerms, error)
+	SetTerms(ctx context.Context, id string, t *model.CampaignTerms) error
+	GetVisibility(ctx context.Context, id string) (*model.CampaignVisibility, error)
+	SetVisibility(ctx context.Context, id string, v *model.CampaignVisibility) error
+	GetCharity(ctx context.Context, id string) (*model.CampaignCharity, error)
+	TotalDonated(ctx context.Context, id string) (float64, error)
+	SetCharity(ctx context.Context, id string, c *model.CampaignCharity) error
+	GetClaimWeights(ctx context.Context, id string) (*model.CampaignClaimWeights, error)
+	SetClaimWeights(ctx context.Context, id string, w *model.CampaignClaimWeights) error
+	GetFeePlan(ctx context.Context, id string) (string, error)
+	EnterpriseTestMode(ctx context.Context, enterpriseID string) (bool, error)
+	TestMode(ctx context.Context, id string) (bool, error)
+	GrantFaucet(ctx context.Context, g *model.FaucetGrant, dailyGrants int) (*model.Campaign, bool, error)
+	GetFeeSchedule(ctx context.Context, plan string) (*model.PlatformFeeSchedule, error)
+}
+
+var (
+	_ RedPocketStore = (*RedPocketRepository)(nil)
+	_ ClaimStore     = (*ClaimRepository)(nil)
+	_ WalletStore    = (*WalletRepository)(nil)
+	_ CampaignStore  = (*CampaignRepository)(nil)
+)
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type SummaryRepository struct {
+	db *PostgresDB
+}
+
+func NewSummaryRepository(db *PostgresDB) *SummaryRepository {
+	return &SummaryRepository{db: db}
+}
+
+// Create stores an archive; an existing archive is never overwritten.
+// Returns false when another writer archived the pocket first.
+func (r *SummaryRepository) Create(ctx context.Context, a *model.PocketArchive) (bool, error) {
+	query := `
+		INSERT INTO red_pocket_summaries (red_pocket_id, summary, content_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (red_pocket_id) DO NOTHING
+	`
+	result, err := r.db.Pool.Exec(ctx, query, a.RedPocketID, []byte(a.Summary), a.ContentHash, a.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *SummaryRepository) GetByRedPocket(ctx context.Context, redPocketID string) (*model.PocketArchive, error) {
+	query := `
+		SELECT red_pocket_id, summary::text, content_hash, COALESCE(ipfs_cid, ''), created_at
+		FROM red_pocket_summaries WHERE red_pocket_id = $1
+	`
+	a := &model.PocketArchive{}
+	var summary string
+	err := r.db.Pool.QueryRow(ctx, query, redPocketID).Scan(
+		&a.RedPocketID, &summary, &a.ContentHash, &a.IPFSCID, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	a.Summary = []byte(summary)
+	return a, nil
+}
+
+// SetIPFSCID records where the archive was pinned; the summary itself stays untouched
+func (r *SummaryRepository) SetIPFSCID(ctx context.Context, redPocketID, cid string) error {
+	query := `UPDATE red_pocket_summaries SET ipfs_cid = $2 WHERE red_pocket_id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, redPocketID, cid)
+	return err
+}
+
+// ListUnpinned returns IDs of archives that have not been pinned to IPFS yet
+func (r *SummaryRepository) ListUnpinned(ctx context.Context, limit int) ([]string, error) {
+	query := `
+		SELECT red_pocket_id FROM red_pocket_summaries
+		WHERE ipfs_cid IS NULL
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+var (
+	// ErrAlreadyInTeam is returned when a claimer is already in a team of
+	// the campaign
+	ErrAlreadyInTeam = errors.New("already in a team of the campaign")
+
+	// ErrJoinCodeTaken is returned by Create when another team has the code
+	ErrJoinCodeTaken = errors.New("team join code taken")
+)
+
+// TeamRepository stores the teams of campaigns, their members, and the
+// milestone bonus pools paid to them
+type TeamRepository struct {
+	db *PostgresDB
+}
+
+func NewTeamRepository(db *PostgresDB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// Create stores a team with its first member, or returns ErrAlreadyInTeam
+// when they are in a team of the campaign already, or ErrJoinCodeTaken
+func (r *TeamRepository) Create(ctx context.Context, t *model.CampaignTeam, founder *model.TeamMember) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO campaign_teams (id, campaign_id, name, join_code, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (join_code) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, t.ID, t.CampaignID, t.Name, t.JoinCode, t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrJoinCodeTaken
+	}
+	if err := addMember(ctx, tx, founder); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Join adds a member to a team, or returns ErrAlreadyInTeam
+func (r *TeamRepository) Join(ctx context.Context, m *model.TeamMember) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addMember(ctx, tx, m); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func addMember(ctx context.Context, tx pgx.Tx, m *model.TeamMember) error {
+	query := `
+		INSERT INTO campaign_team_members (campaign_id, team_id, platform, platform_id, user_id, joined_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (campaign_id, platform, platform_id) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, m.CampaignID, m.TeamID, m.Platform, m.PlatformID, m.UserID, m.JoinedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAlreadyInTeam
+	}
+	return nil
+}
+
+const teamColumns = `id, campaign_id, name, join_code, created_at`
+
+func scanTeam(row pgx.Row) (*model.CampaignTeam, error) {
+	t := &model.CampaignTeam{}
+	if err := row.Scan(&t.ID, &t.CampaignID, &t.Name, &t.JoinCode, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetByID returns a team, or pgx.ErrNoRows
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*model.CampaignTeam, error) {
+	return scanTeam(r.db.Pool.QueryRow(ctx, `SELECT `+teamColumns+` FROM campaign_teams WHERE id = $1`, id))
+}
+
+// GetByCode returns the team with a join code, or pgx.ErrNoRows
+func (r *TeamRepository) GetByCode(ctx context.Context, code string) (*model.CampaignTeam, error) {
+	return scanTeam(r.db.Pool.QueryRow(ctx, `SELECT `+teamColumns+` FROM campaign_teams WHERE join_code = $1`, code))
+}
+
+// ListByMember returns the teams a claimer is in, most recently joined first
+func (r *TeamRepository) ListByMember(ctx context.Context, platform, platformID string) ([]*model.CampaignTeam, error) {
+	query := `
+		SELECT t.id, t.campaign_id, t.name, t.join_code, t.created_at
+		FROM campaign_team_members m
+		JOIN campaign_teams t ON t.id = m.team_id
+		WHERE m.platform = $1 AND m.platform_id = $2
+		ORDER BY m.joined_at DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, platform, platformID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []*model.CampaignTeam{}
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+// MemberTeam returns the ID of the claimer's team in a campaign, or
+// pgx.ErrNoRows when they are in none
+func (r *TeamRepository) MemberTeam(ctx context.Context, campaignID, platform, platformID string) (string, error) {
+	query := `
+		SELECT team_id FROM campaign_team_members
+		WHERE campaign_id = $1 AND platform = $2 AND platform_id = $3
+	`
+	var teamID string
+	err := r.db.Pool.QueryRow(ctx, query, campaignID, platform, platformID).Scan(&teamID)
+	return teamID, err
+}
+
+// Attribute counts a claim towards a team
+func (r *TeamRepository) Attribute(ctx context.Context, claimID, teamID string) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE claims SET team_id = $2 WHERE id = $1`, claimID, teamID)
+	return err
+}
+
+// standingsQuery sums the members and the claims of teams, leaving out
+// claims that were failed, blocked or refunded
+const standingsQuery = `
+	SELECT t.id, t.name,
+		(SELECT COUNT(*) FROM campaign_team_members m WHERE m.team_id = t.id),
+		COUNT(c.id), COALESCE(SUM(c.amount), 0)
+	FROM campaign_teams t
+	LEFT JOIN claims c ON c.team_id = t.id AND c.status NOT IN ('failed', 'blocked', 'refunded')
+`
+
+// Standing returns what a team has claimed, without its rank
+func (r *TeamRepository) Standing(ctx context.Context, teamID string) (*model.TeamStanding, error) {
+	query := standingsQuery + ` WHERE t.id = $1 GROUP BY t.id, t.name`
+	s := &model.TeamStanding{}
+	err := r.db.Pool.QueryRow(ctx, query, teamID).Scan(&s.TeamID, &s.Name, &s.Members, &s.Claims, &s.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Leaderboard returns a campaign's teams by amount claimed, then by claims,
+// earliest created first on ties
+func (r *TeamRepository) Leaderboard(ctx context.Context, campaignID string, limit int) ([]*model.TeamStanding, error) {
+	query := standingsQuery + `
+		WHERE t.campaign_id = $1
+		GROUP BY t.id, t.name, t.created_at
+		ORDER BY COALESCE(SUM(c.amount), 0) DESC, COUNT(c.id) DESC, t.created_at ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, campaignID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []*model.TeamStanding{}
+	for rows.Next() {
+		s := &model.TeamStanding{Rank: len(standings) + 1}
+		if err := rows.Scan(&s.TeamID, &s.Name, &s.Members, &s.Claims, &s.Amount); err != nil {
+			return nil, err
+		}
+		standings = append(standings, s)
+	}
+	return standings, rows.Err()
+}
+
+// CreateMilestone stores a team milestone of a campaign
+func (r *TeamRepository) CreateMilestone(ctx context.Context, m *model.TeamMilestone) error {
+	query := `
+		INSERT INTO campaign_team_milestones (id, campaign_id, claims, amount, bonus_pool, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.Pool.Exec(ctx, query, m.ID, m.CampaignID, m.Claims, m.Amount, m.BonusPool, m.CreatedAt)
+	return err
+}
+
+const milestoneColumns = `id, campaign_id, claims, amount, bonus_pool, created_at`
+
+func (r *TeamRepository) listMilestones(ctx context.Context, query string, args ...interface{}) ([]*model.TeamMilestone, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	milestones := []*model.TeamMilestone{}
+	for rows.Next() {
+		m := &model.TeamMilestone{}
+		if err := rows.Scan(&m.ID, &m.CampaignID, &m.Claims, &m.Amount, &m.BonusPool, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, m)
+	}
+	return milestones, rows.Err()
+}
+
+// ListMilestones returns a campaign's team milestones, smallest first
+func (r *TeamRepository) ListMilestones(ctx context.Context, campaignID string) ([]*model.TeamMilestone, error) {
+	query := `SELECT ` + milestoneColumns + ` FROM campaign_team_milestones
+		WHERE campaign_id = $1 ORDER BY claims, amount, created_at`
+	return r.listMilestones(ctx, query, campaignID)
+}
+
+// DueMilestones returns the milestones of a team's campaign it has reached
+// and not yet been awarded, smallest first
+func (r *TeamRepository) DueMilestones(ctx context.Context, teamID string) ([]*model.TeamMilestone, error) {
+	query := `
+		WITH standing AS (` + standingsQuery + ` WHERE t.id = $1 GROUP BY t.id, t.name)
+		SELECT ms.id, ms.campaign_id, ms.claims, ms.amount, ms.bonus_pool, ms.created_at
+		FROM campaign_team_milestones ms
+		JOIN campaign_teams t ON t.campaign_id = ms.campaign_id AND t.id = $1
+		JOIN standing s (team_id, name, members, claims, amount) ON TRUE
+		WHERE s.claims >= ms.claims AND s.amount >= ms.amount
+			AND NOT EXISTS (
+				SELECT 1 FROM campaign_team_awards a WHERE a.team_id = $1 AND a.milestone_id = ms.id
+			)
+		ORDER BY ms.claims, ms.amount, ms.created_at
+	`
+	return r.listMilestones(ctx, query, teamID)
+}
+
+// DeleteMilestone removes a milestone no team has been awarded yet,
+// reporting whether it did
+func (r *TeamRepository) DeleteMilestone(ctx context.Context, id string) (bool, error) {
+	query := `
+		DELETE FROM campaign_team_milestones ms
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM campaign_team_awards a WHERE a.milestone_id = ms.id)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Award pays a milestone's bonus pool to a team: it is split equally among
+// the team's members, each share rounded down to the ledger's precision,
+// credited to their ledger balances in the campaign's token and added to
+// the campaign's spent budget. The campaign's budget has to cover the
+// pool, as for CreateWithinBudget, or ErrBudgetNotCovered is returned.
+// A team is awarded a milestone once; nil is returned when it already was.
+func (r *TeamRepository) Award(ctx context.Context, a *model.TeamAward) (*model.TeamAward, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var camp model.Campaign
+	query := `
+		SELECT camp.id, camp.chain_id, camp.token, camp.token_address
+		FROM campaigns camp
+		JOIN campaign_teams t ON t.campaign_id = camp.id
+		WHERE t.id = $1
+		FOR UPDATE OF camp
+	`
+	if err := tx.QueryRow(ctx, query, a.TeamID).Scan(&camp.ID, &camp.ChainID, &camp.Token, &camp.TokenAddress); err != nil {
+		return nil, err
+	}
+	var covered bool
+	if err := tx.QueryRow(ctx, budgetCoversQuery, camp.ID, int64(math.Round(a.BonusPool*1e8)), 8).Scan(&covered); err != nil {
+		return nil, err
+	}
+	if !covered {
+		return nil, ErrBudgetNotCovered
+	}
+
+	rows, err := tx.Query(ctx, `SELECT user_id FROM campaign_team_members WHERE team_id = $1 ORDER BY joined_at`, a.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	var members []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		members = append(members, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	a.Members = len(members)
+	a.Share = math.Floor(a.BonusPool/float64(len(members))*1e8) / 1e8
+
+	query = `
+		INSERT INTO campaign_team_awards (id, team_id, milestone_id, bonus_pool, members, share, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (team_id, milestone_id) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, a.ID, a.TeamID, a.MilestoneID, a.BonusPool, a.Members, a.Share, a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	ledger := &LedgerRepository{db: r.db}
+	for i, userID := range members {
+		err := ledger.credit(ctx, tx, &model.LedgerEntry{
+			ID:           "ledger_" + uuid.New().String()[:8],
+			UserID:       userID,
+			ChainID:      camp.ChainID,
+			Token:        camp.Token,
+			TokenAddress: camp.TokenAddress,
+			Amount:       a.Share,
+			Kind:         "team_bonus",
+			RefID:        a.ID + "-" + strconv.Itoa(i+1),
+			CreatedAt:    a.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	query = `UPDATE campaigns SET spent_budget = spent_budget + $2, updated_at = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, camp.ID, a.Share*float64(len(members)), time.Now()); err != nil {
+		return nil, err
+	}
+	return a, tx.Commit(ctx)
+}
+
+// ListAwards returns the bonus pools paid to a team, earliest first
+func (r *TeamRepository) ListAwards(ctx context.Context, teamID string) ([]*model.TeamAward, error) {
+	query := `
+		SELECT id, team_id, milestone_id, bonus_pool, members, share, created_at
+		FROM campaign_team_awards
+		WHERE team_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	awards := []*model.TeamAward{}
+	for rows.Next() {
+		a := &model.TeamAward{}
+		if err := rows.Scan(&a.ID, &a.TeamID, &a.MilestoneID, &a.BonusPool, &a.Members, &a.Share, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		awards = append(awards, a)
+	}
+	return awards, rows.Err()
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type TokenRepository struct {
+	db *PostgresDB
+}
+
+func NewTokenRepository(db *PostgresDB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+const tokenColumns = `symbol, chain_id, address, decimals, price_feed_id, enabled, updated_at`
+
+func scanToken(row interface{ Scan(...interface{}) error }) (*model.Token, error) {
+	t := &model.Token{}
+	if err := row.Scan(&t.Symbol, &t.ChainID, &t.Address, &t.Decimals, &t.PriceFeedID, &t.Enabled, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Get looks a token up by symbol (case-insensitive) on a chain
+func (r *TokenRepository) Get(ctx context.Context, symbol string, chainID int64) (*model.Token, error) {
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE UPPER(symbol) = UPPER($1) AND chain_id = $2`
+	return scanToken(r.db.Pool.QueryRow(ctx, query, symbol, chainID))
+}
+
+// GetByAddress looks a token up by contract address on a chain; an empty
+// address is the native token
+func (r *TokenRepository) GetByAddress(ctx context.Context, address string, chainID int64) (*model.Token, error) {
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE LOWER(address) = LOWER($1) AND chain_id = $2`
+	return scanToken(r.db.Pool.QueryRow(ctx, query, address, chainID))
+}
+
+// List returns the enabled tokens of a chain
+func (r *TokenRepository) List(ctx context.Context, chainID int64) ([]*model.Token, error) {
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE chain_id = $1 AND enabled ORDER BY symbol`
+	rows, err := r.db.Pool.Query(ctx, query, chainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*model.Token
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// ErrPocketInHunt is returned when a pocket is already a step of a hunt
+var ErrPocketInHunt = errors.New("red pocket is already a treasure hunt step")
+
+// TreasureHuntRepository stores treasure hunts and how far each claimer
+// has got through them
+type TreasureHuntRepository struct {
+	db *PostgresDB
+}
+
+func NewTreasureHuntRepository(db *PostgresDB) *TreasureHuntRepository {
+	return &TreasureHuntRepository{db: db}
+}
+
+// Create stores a hunt with its steps, or returns ErrPocketInHunt when one
+// of its pockets is already a step of another
+func (r *TreasureHuntRepository) Create(ctx context.Context, h *model.TreasureHunt) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO treasure_hunts (id, campaign_id, name, created_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(ctx, query, h.ID, h.CampaignID, h.Name, h.CreatedAt); err != nil {
+		return err
+	}
+	query = `
+		INSERT INTO treasure_hunt_steps (hunt_id, position, red_pocket_id, clue, reveal_delay)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (red_pocket_id) DO NOTHING
+	`
+	for _, step := range h.Steps {
+		tag, err := tx.Exec(ctx, query, h.ID, step.Position, step.RedPocketID, step.Clue, step.RevealDelay)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrPocketInHunt
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetByID returns a hunt with its steps, or pgx.ErrNoRows
+func (r *TreasureHuntRepository) GetByID(ctx context.Context, id string) (*model.TreasureHunt, error) {
+	h := &model.TreasureHunt{}
+	query := `SELECT id, campaign_id, name, created_at FROM treasure_hunts WHERE id = $1`
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&h.ID, &h.CampaignID, &h.Name, &h.CreatedAt); err != nil {
+		return nil, err
+	}
+	steps, err := r.steps(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	h.Steps = steps
+	return h, nil
+}
+
+// ListByCampaign returns a campaign's hunts with their steps, newest first
+func (r *TreasureHuntRepository) ListByCampaign(ctx context.Context, campaignID string) ([]*model.TreasureHunt, error) {
+	query := `
+		SELECT id, campaign_id, name, created_at
+		FROM treasure_hunts
+		WHERE campaign_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	var hunts []*model.TreasureHunt
+	for rows.Next() {
+		h := &model.TreasureHunt{}
+		if err := rows.Scan(&h.ID, &h.CampaignID, &h.Name, &h.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		hunts = append(hunts, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, h := range hunts {
+		if h.Steps, err = r.steps(ctx, h.ID); err != nil {
+			return nil, err
+		}
+	}
+	return hunts, nil
+}
+
+func (r *TreasureHuntRepository) steps(ctx context.Context, huntID string) ([]*model.HuntStep, error) {
+	query := `
+		SELECT position, red_pocket_id, clue, reveal_delay
+		FROM treasure_hunt_steps
+		WHERE hunt_id = $1
+		ORDER BY position
+	`
+	rows, err := r.db.Pool.Query(ctx, query, huntID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []*model.HuntStep
+	for rows.Next() {
+		s := &model.HuntStep{}
+		if err := rows.Scan(&s.Position, &s.RedPocketID, &s.Clue, &s.RevealDelay); err != nil {
+			return nil, err
+		}
+		steps = append(steps, s)
+	}
+	return steps, rows.Err()
+}
+
+// Delete removes a hunt with its steps and progress, reporting whether it
+// existed
+func (r *TreasureHuntRepository) Delete(ctx context.Context, id string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM treasure_hunts WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// StepOf returns the hunt step a pocket is, with its hunt ID, and how far
+// the claimer has got through that hunt (nil before their first step).
+// Pockets outside any hunt give pgx.ErrNoRows.
+func (r *TreasureHuntRepository) StepOf(ctx context.Context, redPocketID, platform, platformID string) (string, *model.HuntStep, *model.HuntProgress, error) {
+	query := `
+		SELECT s.hunt_id, s.position, s.red_pocket_id, s.clue, s.reveal_delay, p.step, p.claimed_at
+		FROM treasure_hunt_steps s
+		LEFT JOIN treasure_hunt_progress p
+			ON p.hunt_id = s.hunt_id AND p.platform = $2 AND p.platform_id = $3
+		WHERE s.red_pocket_id = $1
+	`
+	var (
+		huntID    string
+		step      model.HuntStep
+		reached   *int
+		claimedAt *time.Time
+	)
+	err := r.db.Pool.QueryRow(ctx, query, redPocketID, platform, platformID).Scan(
+		&huntID, &step.Position, &step.RedPocketID, &step.Clue, &step.RevealDelay, &reached, &claimedAt,
+	)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if reached == nil {
+		return huntID, &step, nil, nil
+	}
+	return huntID, &step, &model.HuntProgress{
+		HuntID:     huntID,
+		Platform:   platform,
+		PlatformID: platformID,
+		Step:       *reached,
+		ClaimedAt:  *claimedAt,
+	}, nil
+}
+
+// Advance records that the claimer claimed a step, unless they are already
+// past it
+func (r *TreasureHuntRepository) Advance(ctx context.Context, p *model.HuntProgress) error {
+	query := `
+		INSERT INTO treasure_hunt_progress (hunt_id, platform, platform_id, step, claimed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (hunt_id, platform, platform_id) DO UPDATE SET
+			step = EXCLUDED.step, claimed_at = EXCLUDED.claimed_at
+		WHERE treasure_hunt_progress.step < EXCLUDED.step
+	`
+	_, err := r.db.Pool.Exec(ctx, query, p.HuntID, p.Platform, p.PlatformID, p.Step, p.ClaimedAt)
+	return err
+}
+
+// ListProgress returns the hunts a claimer has started, most recently
+// advanced first
+func (r *TreasureHuntRepository) ListProgress(ctx context.Context, platform, platformID string) ([]*model.HuntProgress, error) {
+	query := `
+		SELECT hunt_id, platform, platform_id, step, claimed_at
+		FROM treasure_hunt_progress
+		WHERE platform = $1 AND platform_id = $2
+		ORDER BY claimed_at DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, platform, platformID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var progress []*model.HuntProgress
+	for rows.Next() {
+		p := &model.HuntProgress{}
+		if err := rows.Scan(&p.HuntID, &p.Platform, &p.PlatformID, &p.Step, &p.ClaimedAt); err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+	return progress, rows.Err()
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type UserOpRepository struct {
+	db *PostgresDB
+}
+
+func NewUserOpRepository(db *PostgresDB) *UserOpRepository {
+	return &UserOpRepository{db: db}
+}
+
+func (r *UserOpRepository) Create(ctx context.Context, u *model.UserOperationRecord) error {
+	query := `
+		INSERT INTO user_operations (user_op_hash, claim_id, batch_id, sender, nonce, chain_id, kind, attempt, op, status, submitted_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		u.UserOpHash, u.ClaimID, u.BatchID, u.Sender, u.Nonce, u.ChainID, u.Kind, u.Attempt, []byte(u.Op), u.Status, u.SubmittedAt,
+	)
+	return err
+}
+
+const userOpColumns = `
+	user_op_hash, COALESCE(claim_id, ''), COALESCE(batch_id, ''), sender, nonce, chain_id, kind, attempt, op::text,
+	status, COALESCE(tx_hash, ''), submitted_at, included_at
+`
+
+func scanUserOp(row interface{ Scan(...interface{}) error }) (*model.UserOperationRecord, error) {
+	u := &model.UserOperationRecord{}
+	var op string
+	err := row.Scan(
+		&u.UserOpHash, &u.ClaimID, &u.BatchID, &u.Sender, &u.Nonce, &u.ChainID, &u.Kind, &u.Attempt, &op,
+		&u.Status, &u.TxHash, &u.SubmittedAt, &u.IncludedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	u.Op = []byte(op)
+	return u, nil
+}
+
+func (r *UserOpRepository) GetByHash(ctx context.Context, hash string) (*model.UserOperationRecord, error) {
+	query := `SELECT ` + userOpColumns + ` FROM user_operations WHERE user_op_hash = $1`
+	return scanUserOp(r.db.Pool.QueryRow(ctx, query, hash))
+}
+
+// AttachClaim links a submitted operation to the claim it pays out
+func (r *UserOpRepository) AttachClaim(ctx context.Context, hash, claimID string) error {
+	query := `UPDATE user_operations SET claim_id = $2 WHERE user_op_hash = $1`
+	_, err := r.db.Pool.Exec(ctx, query, hash, claimID)
+	return err
+}
+
+// AttachBatch links a submitted operation to the settlement batch it pays out
+func (r *UserOpRepository) AttachBatch(ctx context.Context, hash, batchID string) error {
+	query := `UPDATE user_operations SET batch_id = $2 WHERE user_op_hash = $1`
+	_, err := r.db.Pool.Exec(ctx, query, hash, batchID)
+	return err
+}
+
+// ListStuck returns pending operations submitted before the cutoff (seconds ago)
+func (r *UserOpRepository) ListStuck(ctx context.Context, olderThanSeconds, limit int) ([]*model.UserOperationRecord, error) {
+	query := `
+		SELECT ` + userOpColumns + `
+		FROM user_operations
+		WHERE status = 'pending' AND submitted_at < NOW() - make_interval(secs => $1)
+		ORDER BY submitted_at ASC
+		LIMIT $2
+	`
+	return r.list(ctx, query, olderThanSeconds, limit)
+}
+
+// ListByNonce returns every operation competing for the same sender nonce
+func (r *UserOpRepository) ListByNonce(ctx context.Context, sender, nonce string) ([]*model.UserOperationRecord, error) {
+	query := `
+		SELECT ` + userOpColumns + `
+		FROM user_operations
+		WHERE sender = $1 AND nonce = $2
+		ORDER BY attempt ASC
+	`
+	return r.list(ctx, query, sender, nonce)
+}
+
+func (r *UserOpRepository) ListByClaim(ctx context.Context, claimID string) ([]*model.UserOperationRecord, error) {
+	query := `
+		SELECT ` + userOpColumns + `
+		FROM user_operations
+		WHERE claim_id = $1
+		ORDER BY attempt ASC
+	`
+	return r.list(ctx, query, claimID)
+}
+
+func (r *UserOpRepository) list(ctx context.Context, query string, args ...interface{}) ([]*model.UserOperationRecord, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ops []*model.UserOperationRecord
+	for rows.Next() {
+		u, err := scanUserOp(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, u)
+	}
+	return ops, nil
+}
+
+// MarkIncluded settles a nonce: the included operation wins and its siblings are replaced
+func (r *UserOpRepository) MarkIncluded(ctx context.Context, hash, txHash string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE user_operations SET status = 'included', tx_hash = $2, included_at = NOW()
+		WHERE user_op_hash = $1
+	`
+	if _, err := tx.Exec(ctx, query, hash, txHash); err != nil {
+		return err
+	}
+	query = `
+		UPDATE user_operations o SET status = 'replaced'
+		FROM user_operations w
+		WHERE w.user_op_hash = $1 AND o.sender = w.sender AND o.nonce = w.nonce
+			AND o.user_op_hash <> w.user_op_hash AND o.status = 'pending'
+	`
+	if _, err := tx.Exec(ctx, query, hash); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UpdateHash renames an operation recorded before it was sent
+func (r *UserOpRepository) UpdateHash(ctx context.Context, hash, newHash string) error {
+	query := `UPDATE user_operations SET user_op_hash = $2 WHERE user_op_hash = $1`
+	_, err := r.db.Pool.Exec(ctx, query, hash, newHash)
+	return err
+}
+
+func (r *UserOpRepository) UpdateStatus(ctx context.Context, hash, status string) error {
+	query := `UPDATE user_operations SET status = $2 WHERE user_op_hash = $1`
+	_, err := r.db.Pool.Exec(ctx, query, hash, status)
+	return err
+}
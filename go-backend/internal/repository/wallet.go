@@ -2,57 +2,67 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/protocolbank/redpocket-backend/internal/encryption"
 	"github.com/protocolbank/redpocket-backend/internal/model"
 )
 
+// WalletRepository stores wallets with their private keys sealed by the
+// encryptor; callers only ever see plaintext keys
 type WalletRepository struct {
-	db *PostgresDB
+	db  *PostgresDB
+	enc *encryption.Encryptor
 }
 
-func NewWalletRepository(db *PostgresDB) *WalletRepository {
-	return &WalletRepository{db: db}
+func NewWalletRepository(db *PostgresDB, enc *encryption.Encryptor) *WalletRepository {
+	return &WalletRepository{db: db, enc: enc}
 }
 
 func (r *WalletRepository) Create(ctx context.Context, w *model.Wallet) error {
+	sealed, err := r.enc.Encrypt(ctx, w.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt private key: %w", err)
+	}
+
 	query := `
-		INSERT INTO wallets (id, user_id, address, chain_id, type, is_deployed, private_key, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO wallets (id, user_id, address, chain_id, type, is_deployed, private_key, created_at, entry_point_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), '0.6'))
 	`
-	_, err := r.db.Pool.Exec(ctx, query,
-		w.ID, w.UserID, w.Address, w.ChainID, w.Type, w.IsDeployed, w.PrivateKey, w.CreatedAt,
+	_, err = r.db.Pool.Exec(ctx, query,
+		w.ID, w.UserID, w.Address, w.ChainID, w.Type, w.IsDeployed, sealed, w.CreatedAt, w.EntryPointVersion,
 	)
 	return err
 }
 
 func (r *WalletRepository) GetByUserID(ctx context.Context, userID string, chainID int64) (*model.Wallet, error) {
 	query := `
-		SELECT id, user_id, address, chain_id, type, is_deployed, private_key, created_at
+		SELECT id, user_id, address, chain_id, type, is_deployed, private_key, created_at, entry_point_version
 		FROM wallets WHERE user_id = $1 AND chain_id = $2
 	`
 	w := &model.Wallet{}
 	err := r.db.Pool.QueryRow(ctx, query, userID, chainID).Scan(
-		&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.Type, &w.IsDeployed, &w.PrivateKey, &w.CreatedAt,
+		&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.Type, &w.IsDeployed, &w.PrivateKey, &w.CreatedAt, &w.EntryPointVersion,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return w, nil
+	return w, r.open(ctx, w)
 }
 
 func (r *WalletRepository) GetByAddress(ctx context.Context, address string) (*model.Wallet, error) {
 	query := `
-		SELECT id, user_id, address, chain_id, type, is_deployed, private_key, created_at
+		SELECT id, user_id, address, chain_id, type, is_deployed, private_key, created_at, entry_point_version
 		FROM wallets WHERE address = $1
 	`
 	w := &model.Wallet{}
 	err := r.db.Pool.QueryRow(ctx, query, address).Scan(
-		&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.Type, &w.IsDeployed, &w.PrivateKey, &w.CreatedAt,
+		&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.Type, &w.IsDeployed, &w.PrivateKey, &w.CreatedAt, &w.EntryPointVersion,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return w, nil
+	return w, r.open(ctx, w)
 }
 
 func (r *WalletRepository) UpdateDeployed(ctx context.Context, id string, deployed bool) error {
@@ -63,7 +73,7 @@ func (r *WalletRepository) UpdateDeployed(ctx context.Context, id string, deploy
 
 func (r *WalletRepository) ListByUser(ctx context.Context, userID string) ([]*model.Wallet, error) {
 	query := `
-		SELECT id, user_id, address, chain_id, type, is_deployed, private_key, created_at
+		SELECT id, user_id, address, chain_id, type, is_deployed, private_key, created_at, entry_point_version
 		FROM wallets WHERE user_id = $1
 		ORDER BY created_at DESC
 	`
@@ -77,12 +87,81 @@ func (r *WalletRepository) ListByUser(ctx context.Context, userID string) ([]*mo
 	for rows.Next() {
 		w := &model.Wallet{}
 		err := rows.Scan(
-			&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.Type, &w.IsDeployed, &w.PrivateKey, &w.CreatedAt,
+			&w.ID, &w.UserID, &w.Address, &w.ChainID, &w.Type, &w.IsDeployed, &w.PrivateKey, &w.CreatedAt, &w.EntryPointVersion,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if err := r.open(ctx, w); err != nil {
+			return nil, err
+		}
 		wallets = append(wallets, w)
 	}
 	return wallets, nil
 }
+
+// RotateKeys re-seals private keys with the active provider and key, a page
+// at a time in id order. With force set every key is re-sealed, which also
+// moves values to the latest version of a remotely managed key. Returns the
+// number of wallets checked and rotated.
+func (r *WalletRepository) RotateKeys(ctx context.Context, batchSize int, force bool) (checked, rotated int, err error) {
+	after := ""
+	for {
+		query := `
+			SELECT id, private_key FROM wallets
+			WHERE id > $1
+			ORDER BY id
+			LIMIT $2
+		`
+		rows, err := r.db.Pool.Query(ctx, query, after, batchSize)
+		if err != nil {
+			return checked, rotated, err
+		}
+		type sealedKey struct{ id, value string }
+		var page []sealedKey
+		for rows.Next() {
+			var k sealedKey
+			if err := rows.Scan(&k.id, &k.value); err != nil {
+				rows.Close()
+				return checked, rotated, err
+			}
+			page = append(page, k)
+		}
+		rows.Close()
+		if len(page) == 0 {
+			return checked, rotated, nil
+		}
+
+		for _, k := range page {
+			checked++
+			if !force && !r.enc.NeedsRotation(k.value) {
+				continue
+			}
+			plaintext, err := r.enc.Decrypt(ctx, k.value)
+			if err != nil {
+				return checked, rotated, fmt.Errorf("wallet %s: %w", k.id, err)
+			}
+			sealed, err := r.enc.Encrypt(ctx, plaintext)
+			if err != nil {
+				return checked, rotated, fmt.Errorf("wallet %s: %w", k.id, err)
+			}
+			// Only replace the value we read, in case it was rotated concurrently
+			query := `UPDATE wallets SET private_key = $3 WHERE id = $1 AND private_key = $2`
+			if _, err := r.db.Pool.Exec(ctx, query, k.id, k.value, sealed); err != nil {
+				return checked, rotated, fmt.Errorf("wallet %s: %w", k.id, err)
+			}
+			rotated++
+		}
+		after = page[len(page)-1].id
+	}
+}
+
+// open decrypts the sealed private key in place
+func (r *WalletRepository) open(ctx context.Context, w *model.Wallet) error {
+	plaintext, err := r.enc.Decrypt(ctx, w.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt private key of wallet %s: %w", w.ID, err)
+	}
+	w.PrivateKey = plaintext
+	return nil
+}
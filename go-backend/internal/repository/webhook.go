@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type WebhookRepository struct {
+	db *PostgresDB
+}
+
+func NewWebhookRepository(db *PostgresDB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, w *model.Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, enterprise_id, url, secret, events, description, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		w.ID, w.EnterpriseID, w.URL, w.Secret, w.Events, w.Description, w.Enabled, w.CreatedAt, w.UpdatedAt,
+	)
+	return err
+}
+
+const webhookColumns = `id, enterprise_id, url, events, COALESCE(description, ''), enabled, created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*model.Webhook, error) {
+	w := &model.Webhook{}
+	err := row.Scan(&w.ID, &w.EnterpriseID, &w.URL, &w.Events, &w.Description, &w.Enabled, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// GetByID returns one of the enterprise's webhooks, without its secret, or
+// pgx.ErrNoRows
+func (r *WebhookRepository) GetByID(ctx context.Context, id, enterpriseID string) (*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND enterprise_id = $2`
+	return scanWebhook(r.db.Pool.QueryRow(ctx, query, id, enterpriseID))
+}
+
+// List returns the enterprise's webhooks, without their secrets
+func (r *WebhookRepository) List(ctx context.Context, enterpriseID string) ([]*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE enterprise_id = $1 ORDER BY created_at`
+	rows, err := r.db.Pool.Query(ctx, query, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*model.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// Update saves a webhook's URL, events, description and enabled flag. The
+// secret is replaced too unless empty. Reports false if the enterprise has
+// no such webhook.
+func (r *WebhookRepository) Update(ctx context.Context, w *model.Webhook) (bool, error) {
+	query := `
+		UPDATE webhooks
+		SET url = $3, events = $4, description = NULLIF($5, ''), enabled = $6,
+			secret = COALESCE(NULLIF($7, ''), secret), updated_at = $8
+		WHERE id = $1 AND enterprise_id = $2
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		w.ID, w.EnterpriseID, w.URL, w.Events, w.Description, w.Enabled, w.Secret, w.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Delete removes a webhook and its delivery log. Reports false if the
+// enterprise has no such webhook.
+func (r *WebhookRepository) Delete(ctx context.Context, id, enterpriseID string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND enterprise_id = $2`, id, enterpriseID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Subscribed returns the IDs of the enabled webhooks of the campaign's
+// enterprise that want eventType
+func (r *WebhookRepository) Subscribed(ctx context.Context, campaignID, eventType string) ([]string, error) {
+	query := `
+		SELECT w.id
+		FROM webhooks w
+		JOIN campaigns camp ON camp.enterprise_id = w.enterprise_id
+		WHERE camp.id = $1 AND w.enabled AND $2 = ANY(w.events)
+	`
+	rows, err := r.db.Pool.Query(ctx, query, campaignID, eventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		d.ID, d.WebhookID, d.EventID, d.EventType, d.Payload, d.Status, d.NextAttemptAt, d.CreatedAt,
+	)
+	return err
+}
+
+const webhookDeliveryColumns = `
+	d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+	COALESCE(d.response_status, 0), COALESCE(d.error, ''), d.created_at, d.delivered_at
+`
+
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }) (*model.WebhookDelivery, error) {
+	d := &model.WebhookDelivery{}
+	err := row.Scan(
+		&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.ResponseStatus, &d.Error, &d.CreatedAt, &d.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ListDeliveries returns a webhook's deliveries, newest first; status
+// filters them unless empty
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID, status string, limit, offset int) ([]*model.WebhookDelivery, int64, error) {
+	where := ` FROM webhook_deliveries d WHERE d.webhook_id = $1 AND ($2 = '' OR d.status = $2)`
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*)`+where, webhookID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + where + ` ORDER BY d.created_at DESC LIMIT $3 OFFSET $4`
+	rows, err := r.db.Pool.Query(ctx, query, webhookID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []*model.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, total, rows.Err()
+}
+
+// DequeueDeliveries takes up to limit due deliveries, with their webhook's
+// URL and secret, and marks them sending. Deliveries left sending for longer
+// than stuckAfter, by a worker that died, are taken again.
+func (r *WebhookRepository) DequeueDeliveries(ctx context.Context, limit int, stuckAfter time.Duration) ([]*model.WebhookDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE (status = 'pending' AND next_attempt_at <= NOW())
+				OR (status = 'sending' AND locked_at < $2)
+			ORDER BY next_attempt_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT $1
+		)
+		UPDATE webhook_deliveries d
+		SET status = 'sending', attempts = d.attempts + 1, locked_at = NOW()
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING ` + webhookDeliveryColumns + `, w.url, w.secret
+	`
+	rows, err := r.db.Pool.Query(ctx, query, limit, time.Now().Add(-stuckAfter))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		d := &model.WebhookDelivery{}
+		err := rows.Scan(
+			&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.ResponseStatus, &d.Error, &d.CreatedAt, &d.DeliveredAt, &d.URL, &d.Secret,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// FinishDelivery records the outcome of an attempt: success or failed end
+// the delivery, pending schedules the next attempt at nextAttemptAt
+func (r *WebhookRepository) FinishDelivery(ctx context.Context, id, status string, responseStatus int, errMsg string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, response_status = NULLIF($3, 0), error = NULLIF($4, ''), next_attempt_at = $5, locked_at = NULL,
+			delivered_at = CASE WHEN $2 = 'success' THEN NOW() ELSE delivered_at END
+		WHERE id = $1 AND status = 'sending'
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, responseStatus, errMsg, nextAttemptAt)
+	return err
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+type WeeklyReportRepository struct {
+	db *PostgresDB
+}
+
+func NewWeeklyReportRepository(db *PostgresDB) *WeeklyReportRepository {
+	return &WeeklyReportRepository{db: db}
+}
+
+const reportSubscriberColumns = `id, enterprise_id, email, locale, status, created_at, confirmed_at, unsubscribed_at`
+
+func scanReportSubscriber(row interface{ Scan(...interface{}) error }) (*model.ReportSubscriber, error) {
+	s := &model.ReportSubscriber{}
+	err := row.Scan(&s.ID, &s.EnterpriseID, &s.Email, &s.Locale, &s.Status, &s.CreatedAt, &s.ConfirmedAt, &s.UnsubscribedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *WeeklyReportRepository) listSubscribers(ctx context.Context, query string, args ...interface{}) ([]*model.ReportSubscriber, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscribers []*model.ReportSubscriber
+	for rows.Next() {
+		s, err := scanReportSubscriber(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, s)
+	}
+	return subscribers, rows.Err()
+}
+
+// ListSubscribers returns every address the enterprise has added, whatever
+// its status
+func (r *WeeklyReportRepository) ListSubscribers(ctx context.Context, enterpriseID string) ([]*model.ReportSubscriber, error) {
+	query := `SELECT ` + reportSubscriberColumns + ` FROM report_subscribers WHERE enterprise_id = $1 ORDER BY created_at, id`
+	return r.listSubscribers(ctx, query, enterpriseID)
+}
+
+// ActiveSubscribers returns the confirmed addresses of the enterprise
+func (r *WeeklyReportRepository) ActiveSubscribers(ctx context.Context, enterpriseID string) ([]*model.ReportSubscriber, error) {
+	query := `SELECT ` + reportSubscriberColumns + ` FROM report_subscribers WHERE enterprise_id = $1 AND status = 'active' ORDER BY created_at, id`
+	return r.listSubscribers(ctx, query, enterpriseID)
+}
+
+// GetSubscriber returns a subscriber, or pgx.ErrNoRows
+func (r *WeeklyReportRepository) GetSubscriber(ctx context.Context, id string) (*model.ReportSubscriber, error) {
+	query := `SELECT ` + reportSubscriberColumns + ` FROM report_subscribers WHERE id = $1`
+	return scanReportSubscriber(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// AddSubscriber adds an address to the enterprise's report, pending its
+// confirmation. An address already added keeps its ID and, if confirmed,
+// stays active; one that unsubscribed is pending again.
+func (r *WeeklyReportRepository) AddSubscriber(ctx context.Context, s *model.ReportSubscriber) (*model.ReportSubscriber, error) {
+	query := `
+		INSERT INTO report_subscribers (id, enterprise_id, email, locale, status, created_at)
+		VALUES ($1, $2, $3, $4, 'pending', $5)
+		ON CONFLICT (enterprise_id, email) DO UPDATE SET
+			locale = EXCLUDED.locale,
+			status = CASE WHEN report_subscribers.status = 'active' THEN 'active' ELSE 'pending' END
+		RETURNING ` + reportSubscriberColumns
+	return scanReportSubscriber(r.db.Pool.QueryRow(ctx, query, s.ID, s.EnterpriseID, s.Email, s.Locale, s.CreatedAt))
+}
+
+// DeleteSubscriber removes one of the enterprise's addresses, reporting
+// whether it had it
+func (r *WeeklyReportRepository) DeleteSubscriber(ctx context.Context, enterpriseID, id string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM report_subscribers WHERE id = $1 AND enterprise_id = $2`, id, enterpriseID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Confirm activates a pending subscriber, reporting false if it is not
+// pending
+func (r *WeeklyReportRepository) Confirm(ctx context.Context, id string) (bool, error) {
+	query := `
+		UPDATE report_subscribers SET status = 'active', confirmed_at = NOW(), unsubscribed_at = NULL
+		WHERE id = $1 AND status = 'pending'
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Unsubscribe stops a subscriber's reports, reporting false if it has
+// already unsubscribed
+func (r *WeeklyReportRepository) Unsubscribe(ctx context.Context, id string) (bool, error) {
+	query := `
+		UPDATE report_subscribers SET status = 'unsubscribed', unsubscribed_at = NOW()
+		WHERE id = $1 AND status <> 'unsubscribed'
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Due returns enterprises with confirmed subscribers that have not been
+// sent the report of week
+func (r *WeeklyReportRepository) Due(ctx context.Context, week time.Time, limit int) ([]string, error) {
+	query := `
+		SELECT DISTINCT s.enterprise_id
+		FROM report_subscribers s
+		WHERE s.status = 'active'
+			AND NOT EXISTS (SELECT 1 FROM weekly_reports w WHERE w.enterprise_id = s.enterprise_id AND w.week = $1::date)
+		ORDER BY s.enterprise_id
+		LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, week, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MarkSent records that the enterprise's report of week is being sent,
+// reporting false when another sweep already took it
+func (r *WeeklyReportRepository) MarkSent(ctx context.Context, enterpriseID string, week time.Time) (bool, error) {
+	query := `
+		INSERT INTO weekly_reports (enterprise_id, week, sent_at) VALUES ($1, $2::date, NOW())
+		ON CONFLICT (enterprise_id, week) DO NOTHING
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, enterpriseID, week)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetRecipients records how many subscribers the report of week reached
+func (r *WeeklyReportRepository) SetRecipients(ctx context.Context, enterpriseID string, week time.Time, n int) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE weekly_reports SET recipients = $3 WHERE enterprise_id = $1 AND week = $2::date`, enterpriseID, week, n)
+	return err
+}
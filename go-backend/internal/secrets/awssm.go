@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/awsjson"
+)
+
+// AWSSecretsManagerProvider reads the secret document from an AWS Secrets
+// Manager secret whose string value is a JSON object
+type AWSSecretsManagerProvider struct {
+	secretID   string
+	region     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+func NewAWSSecretsManagerProvider(secretID, region, endpoint string) *AWSSecretsManagerProvider {
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+	return &AWSSecretsManagerProvider{
+		secretID: secretID,
+		region:   region,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (p *AWSSecretsManagerProvider) Name() string {
+	return "awssm"
+}
+
+func (p *AWSSecretsManagerProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	in := map[string]string{"SecretId": p.secretID}
+	err := awsjson.Call(ctx, p.httpClient, p.endpoint, p.region, "secretsmanager", "secretsmanager.GetSecretValue", in, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]string
+	if err := json.Unmarshal([]byte(out.SecretString), &doc); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object of strings: %w", p.secretID, err)
+	}
+	return doc, nil
+}
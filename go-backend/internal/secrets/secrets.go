@@ -0,0 +1,206 @@
+// Package secrets loads database credentials, the JWT secret, bot tokens and
+// the wallet master key from a secrets manager (HashiCorp Vault KV or AWS
+// Secrets Manager) instead of the environment. The secret is a flat document
+// keyed by the names of the environment variables it replaces. It is read
+// once over the config at startup and then polled, and components that can
+// take a new value while running are told when one is rotated.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// Provider reads the secret document
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// fields are the config values a secret document may set
+func fields(cfg *config.Config) map[string]*string {
+	return map[string]*string{
+		"DATABASE_URL":               &cfg.DatabaseURL,
+		"JWT_SECRET":                 &cfg.JWTSecret,
+		"TELEGRAM_BOT_TOKEN":         &cfg.TelegramBotToken,
+		"DISCORD_BOT_TOKEN":          &cfg.DiscordBotToken,
+		"SLACK_CLIENT_SECRET":        &cfg.SlackClientSecret,
+		"SLACK_SIGNING_SECRET":       &cfg.SlackSigningSecret,
+		"WALLET_ENCRYPTION_KEY":      &cfg.WalletEncryptionKey,
+		"WALLET_ENCRYPTION_OLD_KEYS": &cfg.WalletEncryptionOldKeys,
+		"CLAIM_LINK_SECRET":          &cfg.ClaimLinkSecret,
+		"REPORT_URL_SECRET":          &cfg.ReportURLSecret,
+		"SMTP_PASSWORD":              &cfg.SMTPPassword,
+		"XCM_RELAYER_KEY":            &cfg.XCMRelayerKey,
+	}
+}
+
+// Manager holds the secrets read from the provider and hands rotated values
+// to the components registered for them
+type Manager struct {
+	provider Provider
+	interval time.Duration
+	refresh  chan struct{}
+
+	mu     sync.Mutex
+	values map[string]string
+	hooks  map[string][]func(value string)
+}
+
+// New returns a manager for SECRETS_PROVIDER. Without one, Load and Start do
+// nothing and the environment is used as is.
+func New(cfg *config.Config) (*Manager, error) {
+	m := &Manager{
+		interval: time.Duration(cfg.SecretsRefreshInterval) * time.Second,
+		refresh:  make(chan struct{}, 1),
+		values:   make(map[string]string),
+		hooks:    make(map[string][]func(value string)),
+	}
+	switch cfg.SecretsProvider {
+	case "":
+	case "vault":
+		if cfg.VaultAddr == "" {
+			return nil, fmt.Errorf("VAULT_ADDR is required with SECRETS_PROVIDER=vault")
+		}
+		m.provider = NewVaultProvider(cfg.VaultAddr, cfg.VaultToken, cfg.SecretsVaultMount, cfg.SecretsPath)
+	case "awssm":
+		m.provider = NewAWSSecretsManagerProvider(cfg.SecretsPath, cfg.AWSRegion, cfg.SecretsAWSEndpoint)
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q", cfg.SecretsProvider)
+	}
+	return m, nil
+}
+
+// Load reads the secret document and sets the config values it contains
+func (m *Manager) Load(ctx context.Context, cfg *config.Config) error {
+	if m.provider == nil {
+		return nil
+	}
+	doc, err := m.provider.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", m.provider.Name(), err)
+	}
+
+	targets := fields(cfg)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var loaded []string
+	for name, value := range doc {
+		target, ok := targets[name]
+		if !ok {
+			slog.WarnContext(ctx, "secrets: ignoring unknown secret", "name", name)
+			continue
+		}
+		*target = value
+		m.values[name] = value
+		loaded = append(loaded, name)
+	}
+	sort.Strings(loaded)
+	slog.InfoContext(ctx, "secrets: loaded", "names", loaded, "provider", m.provider.Name())
+	return nil
+}
+
+// OnRotate registers fn to be called with the new value whenever the secret
+// name changes. Secrets without a hook take effect on restart.
+func (m *Manager) OnRotate(name string, fn func(value string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks[name] = append(m.hooks[name], fn)
+}
+
+// Refresh asks Start to read the provider now rather than at the next
+// interval, for rotations announced by the provider or an operator
+func (m *Manager) Refresh() {
+	select {
+	case m.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Start polls the provider for rotated secrets until ctx is cancelled. With
+// no refresh interval it only reads the provider when Refresh is called.
+func (m *Manager) Start(ctx context.Context) {
+	if m.provider == nil {
+		return
+	}
+	var tick <-chan time.Time
+	if m.interval > 0 {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-m.refresh:
+		}
+		doc, err := m.provider.Fetch(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "secrets: failed to refresh", "provider", m.provider.Name(), "error", err)
+			continue
+		}
+		m.apply(doc)
+	}
+}
+
+// apply hands changed secrets to their hooks. A secret removed from the
+// document keeps its last value.
+func (m *Manager) apply(doc map[string]string) {
+	m.mu.Lock()
+	var calls []func()
+	known := fields(&config.Config{})
+	for name, value := range doc {
+		if _, ok := known[name]; !ok {
+			continue
+		}
+		if old, ok := m.values[name]; ok && old == value {
+			continue
+		}
+		m.values[name] = value
+		hooks := m.hooks[name]
+		if len(hooks) == 0 {
+			slog.Info("secrets: rotated, takes effect on restart", "name", name)
+			continue
+		}
+		slog.Info("secrets: rotated", "name", name)
+		for _, fn := range hooks {
+			calls = append(calls, func() { fn(value) })
+		}
+	}
+	m.mu.Unlock()
+
+	for _, call := range calls {
+		call()
+	}
+}
+
+// Value is a string secret that may be rotated while it is in use
+type Value struct {
+	mu sync.RWMutex
+	v  string
+}
+
+func NewValue(v string) *Value {
+	return &Value{v: v}
+}
+
+func (v *Value) Get() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.v
+}
+
+func (v *Value) Set(s string) {
+	v.mu.Lock()
+	v.v = s
+	v.mu.Unlock()
+}
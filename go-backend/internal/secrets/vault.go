@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads the secret document from a HashiCorp Vault KV version
+// 2 secret
+type VaultProvider struct {
+	addr       string
+	token      string
+	mount      string
+	path       string
+	httpClient *http.Client
+}
+
+func NewVaultProvider(addr, token, mount, path string) *VaultProvider {
+	if mount == "" {
+		mount = "secret"
+	}
+	return &VaultProvider{
+		addr:  strings.TrimRight(addr, "/"),
+		token: token,
+		mount: strings.Trim(mount, "/"),
+		path:  strings.Trim(path, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+func (p *VaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.addr, p.mount, p.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var out struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	doc := make(map[string]string, len(out.Data.Data))
+	for name, value := range out.Data.Data {
+		if s, ok := value.(string); ok {
+			doc[name] = s
+		} else {
+			doc[name] = fmt.Sprint(value)
+		}
+	}
+	return doc, nil
+}
@@ -13,29 +13,75 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/tracing"
+)
+
+// EntryPoint versions. Accounts are bound to the EntryPoint they were
+// deployed for, so the version is stored per wallet.
+const (
+	EntryPointV06 = "0.6"
+	EntryPointV07 = "0.7"
 )
 
 // ERC-4337 Account Abstraction Client for Pimlico
 type AAClient struct {
 	bundlerURL   string
 	paymasterURL string
-	entryPoint   string
+	entryPoint   string // v0.6
+	entryPointV7 string
+	policyID     string
 	httpClient   *http.Client
 }
 
-func NewAAClient(bundlerURL, paymasterURL, entryPoint string) *AAClient {
+func NewAAClient(bundlerURL, paymasterURL, entryPoint, entryPointV7, policyID string, downstream config.DownstreamPolicy) *AAClient {
 	return &AAClient{
 		bundlerURL:   bundlerURL,
 		paymasterURL: paymasterURL,
 		entryPoint:   entryPoint,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		entryPointV7: entryPointV7,
+		policyID:     policyID,
+		httpClient:   NewDownstream("bundler", downstream).HTTPClient(),
+	}
+}
+
+// entryPointFor returns the EntryPoint an op is sent to
+func (c *AAClient) entryPointFor(op *UserOperation) string {
+	if op.Version == EntryPointV07 {
+		return c.entryPointV7
 	}
+	return c.entryPoint
 }
 
-// UserOperation represents an ERC-4337 user operation
+// UserOperation represents an ERC-4337 user operation. v0.6 ops carry
+// InitCode and PaymasterAndData; v0.7 ops split them into the factory and
+// paymaster fields and are packed into a PackedUserOperation on chain.
 type UserOperation struct {
+	Version              string `json:"-"` // EntryPointV06 or EntryPointV07
+	Sender               string `json:"sender"`
+	Nonce                string `json:"nonce"`
+	InitCode             string `json:"initCode,omitempty"`
+	Factory              string `json:"factory,omitempty"`
+	FactoryData          string `json:"factoryData,omitempty"`
+	CallData             string `json:"callData"`
+	CallGasLimit         string `json:"callGasLimit"`
+	VerificationGasLimit string `json:"verificationGasLimit"`
+	PreVerificationGas   string `json:"preVerificationGas"`
+	MaxFeePerGas         string `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     string `json:"paymasterAndData,omitempty"`
+
+	Paymaster                     string `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit string `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       string `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 string `json:"paymasterData,omitempty"`
+
+	Signature string `json:"signature"`
+}
+
+// userOperationV06 is the v0.6 RPC shape, where initCode and
+// paymasterAndData are always present
+type userOperationV06 struct {
 	Sender               string `json:"sender"`
 	Nonce                string `json:"nonce"`
 	InitCode             string `json:"initCode"`
@@ -49,6 +95,83 @@ type UserOperation struct {
 	Signature            string `json:"signature"`
 }
 
+// userOperationV07 is the v0.7 RPC shape, where an op without a factory
+// or paymaster leaves their fields out
+type userOperationV07 struct {
+	Sender                        string `json:"sender"`
+	Nonce                         string `json:"nonce"`
+	Factory                       string `json:"factory,omitempty"`
+	FactoryData                   string `json:"factoryData,omitempty"`
+	CallData                      string `json:"callData"`
+	CallGasLimit                  string `json:"callGasLimit"`
+	VerificationGasLimit          string `json:"verificationGasLimit"`
+	PreVerificationGas            string `json:"preVerificationGas"`
+	MaxFeePerGas                  string `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          string `json:"maxPriorityFeePerGas"`
+	Paymaster                     string `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit string `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       string `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 string `json:"paymasterData,omitempty"`
+	Signature                     string `json:"signature"`
+}
+
+// MarshalJSON encodes the op in the RPC shape of its EntryPoint version
+func (op UserOperation) MarshalJSON() ([]byte, error) {
+	if op.Version == EntryPointV07 {
+		return json.Marshal(userOperationV07{
+			Sender:                        op.Sender,
+			Nonce:                         op.Nonce,
+			Factory:                       op.Factory,
+			FactoryData:                   op.FactoryData,
+			CallData:                      op.CallData,
+			CallGasLimit:                  op.CallGasLimit,
+			VerificationGasLimit:          op.VerificationGasLimit,
+			PreVerificationGas:            op.PreVerificationGas,
+			MaxFeePerGas:                  op.MaxFeePerGas,
+			MaxPriorityFeePerGas:          op.MaxPriorityFeePerGas,
+			Paymaster:                     op.Paymaster,
+			PaymasterVerificationGasLimit: op.PaymasterVerificationGasLimit,
+			PaymasterPostOpGasLimit:       op.PaymasterPostOpGasLimit,
+			PaymasterData:                 op.PaymasterData,
+			Signature:                     op.Signature,
+		})
+	}
+	return json.Marshal(userOperationV06{
+		Sender:               op.Sender,
+		Nonce:                op.Nonce,
+		InitCode:             orEmptyHex(op.InitCode),
+		CallData:             op.CallData,
+		CallGasLimit:         op.CallGasLimit,
+		VerificationGasLimit: op.VerificationGasLimit,
+		PreVerificationGas:   op.PreVerificationGas,
+		MaxFeePerGas:         op.MaxFeePerGas,
+		MaxPriorityFeePerGas: op.MaxPriorityFeePerGas,
+		PaymasterAndData:     orEmptyHex(op.PaymasterAndData),
+		Signature:            op.Signature,
+	})
+}
+
+// ClearPaymaster drops the op's sponsorship so it can be sponsored again
+func (op *UserOperation) ClearPaymaster() {
+	op.PaymasterAndData = "0x"
+	op.Paymaster = ""
+	op.PaymasterVerificationGasLimit = ""
+	op.PaymasterPostOpGasLimit = ""
+	op.PaymasterData = ""
+}
+
+// Sponsored reports whether a paymaster pays the op's gas
+func (op *UserOperation) Sponsored() bool {
+	return (op.PaymasterAndData != "" && op.PaymasterAndData != "0x") || op.Paymaster != ""
+}
+
+func orEmptyHex(s string) string {
+	if s == "" {
+		return "0x"
+	}
+	return s
+}
+
 // JSON-RPC request/response
 type jsonRPCRequest struct {
 	JSONRPC string        `json:"jsonrpc"`
@@ -69,28 +192,31 @@ type jsonRPCError struct {
 	Message string `json:"message"`
 }
 
-// GetAccountNonce gets the nonce for an AA wallet
-func (c *AAClient) GetAccountNonce(ctx context.Context, sender string) (*big.Int, error) {
+// GetAccountNonce reads an AA wallet's next nonce from its EntryPoint's
+// getNonce, with key 0. A wallet not deployed yet has nonce 0.
+func (c *AAClient) GetAccountNonce(ctx context.Context, sender, entryPoint string) (*big.Int, error) {
+	// getNonce(address sender, uint192 key) selector: 0x35567e1a
+	data := "0x35567e1a" +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(sender).Bytes(), 32)) +
+		hex.EncodeToString(make([]byte, 32))
+
 	req := jsonRPCRequest{
 		JSONRPC: "2.0",
-		Method:  "eth_getTransactionCount",
-		Params:  []interface{}{sender, "latest"},
+		Method:  "eth_call",
+		Params:  []interface{}{map[string]string{"to": entryPoint, "data": data}, "latest"},
 		ID:      1,
 	}
 
 	resp, err := c.call(ctx, c.bundlerURL, req)
 	if err != nil {
-		return big.NewInt(0), nil // Default to 0 for new accounts
+		return nil, err
 	}
 
 	var nonceHex string
 	if err := json.Unmarshal(resp.Result, &nonceHex); err != nil {
-		return big.NewInt(0), nil
+		return nil, fmt.Errorf("failed to parse nonce: %w", err)
 	}
-
-	nonce := new(big.Int)
-	nonce.SetString(nonceHex[2:], 16)
-	return nonce, nil
+	return parseHexQuantity("nonce", nonceHex)
 }
 
 // BuildERC20TransferCallData builds calldata for ERC20 transfer
@@ -108,35 +234,82 @@ func BuildERC20TransferCallData(tokenAddress, to string, amount *big.Int) string
 	return "0x" + methodID + hex.EncodeToString(paddedTo) + hex.EncodeToString(paddedAmount)
 }
 
-// BuildExecuteCallData builds calldata for AA wallet execute function
-func BuildExecuteCallData(to string, value *big.Int, data string) string {
-	// execute(address,uint256,bytes) selector
-	methodID := "b61d27f6"
-	
-	toAddr := common.HexToAddress(to)
-	paddedTo := common.LeftPadBytes(toAddr.Bytes(), 32)
-	paddedValue := common.LeftPadBytes(value.Bytes(), 32)
-	
-	// Data offset (96 bytes = 0x60)
-	dataOffset := common.LeftPadBytes(big.NewInt(96).Bytes(), 32)
-	
-	// Data bytes
-	dataBytes, _ := hex.DecodeString(data[2:]) // Remove 0x prefix
-	dataLen := common.LeftPadBytes(big.NewInt(int64(len(dataBytes))).Bytes(), 32)
-	
-	// Pad data to 32 bytes boundary
-	paddedData := dataBytes
-	if len(dataBytes)%32 != 0 {
-		padding := make([]byte, 32-len(dataBytes)%32)
-		paddedData = append(dataBytes, padding...)
+// BuildERC20ApproveCallData builds calldata for ERC20 approve
+func BuildERC20ApproveCallData(spender string, amount *big.Int) string {
+	// ERC20 approve(address,uint256) selector: 0x095ea7b3
+	methodID := "095ea7b3"
+
+	spenderAddr := common.HexToAddress(spender)
+	paddedSpender := common.LeftPadBytes(spenderAddr.Bytes(), 32)
+	paddedAmount := common.LeftPadBytes(amount.Bytes(), 32)
+
+	return "0x" + methodID + hex.EncodeToString(paddedSpender) + hex.EncodeToString(paddedAmount)
+}
+
+// BuildExecuteBatchCallData builds calldata for the v0.6 SimpleAccount
+// executeBatch(address[],bytes[])
+func BuildExecuteBatchCallData(targets []string, datas []string) (string, error) {
+	if len(targets) != len(datas) {
+		return "", fmt.Errorf("executeBatch: %d targets but %d calls", len(targets), len(datas))
 	}
-	
-	return "0x" + methodID + 
-		hex.EncodeToString(paddedTo) + 
-		hex.EncodeToString(paddedValue) + 
-		hex.EncodeToString(dataOffset) + 
-		hex.EncodeToString(dataLen) + 
-		hex.EncodeToString(paddedData)
+	dest, calls, err := parseBatch(targets, datas)
+	if err != nil {
+		return "", err
+	}
+	return packCall(simpleAccountABI, "executeBatch", dest, calls)
+}
+
+// BuildExecuteBatchCallDataV07 builds calldata for the v0.7 SimpleAccount
+// executeBatch(address[],uint256[],bytes[]), which also carries a value per
+// call. values may be nil when no call sends native token.
+func BuildExecuteBatchCallDataV07(targets []string, values []*big.Int, datas []string) (string, error) {
+	if len(targets) != len(datas) || len(values) > len(targets) {
+		return "", fmt.Errorf("executeBatch: %d targets, %d values and %d calls", len(targets), len(values), len(datas))
+	}
+	dest, calls, err := parseBatch(targets, datas)
+	if err != nil {
+		return "", err
+	}
+	vals := make([]*big.Int, len(targets))
+	for i := range vals {
+		vals[i] = new(big.Int)
+		if i < len(values) && values[i] != nil {
+			vals[i] = values[i]
+		}
+	}
+	return packCall(simpleAccountV07ABI, "executeBatch", dest, vals, calls)
+}
+
+func parseBatch(targets []string, datas []string) ([]common.Address, [][]byte, error) {
+	dest := make([]common.Address, len(targets))
+	calls := make([][]byte, len(datas))
+	for i := range targets {
+		var err error
+		if dest[i], err = parseAddress("target", targets[i]); err != nil {
+			return nil, nil, err
+		}
+		if calls[i], err = parseHexBytes("call data", datas[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return dest, calls, nil
+}
+
+// BuildExecuteCallData builds calldata for AA wallet execute(address,uint256,bytes),
+// which has the same shape for both EntryPoint versions
+func BuildExecuteCallData(to string, value *big.Int, data string) (string, error) {
+	dest, err := parseAddress("target", to)
+	if err != nil {
+		return "", err
+	}
+	call, err := parseHexBytes("call data", data)
+	if err != nil {
+		return "", err
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	return packCall(simpleAccountABI, "execute", dest, value, call)
 }
 
 // EstimateUserOperationGas estimates gas for a user operation
@@ -144,7 +317,7 @@ func (c *AAClient) EstimateUserOperationGas(ctx context.Context, op *UserOperati
 	req := jsonRPCRequest{
 		JSONRPC: "2.0",
 		Method:  "eth_estimateUserOperationGas",
-		Params:  []interface{}{op, c.entryPoint},
+		Params:  []interface{}{op, c.entryPointFor(op)},
 		ID:      1,
 	}
 
@@ -158,9 +331,11 @@ func (c *AAClient) EstimateUserOperationGas(ctx context.Context, op *UserOperati
 	}
 
 	var gasEstimate struct {
-		CallGasLimit         string `json:"callGasLimit"`
-		VerificationGasLimit string `json:"verificationGasLimit"`
-		PreVerificationGas   string `json:"preVerificationGas"`
+		CallGasLimit                  string `json:"callGasLimit"`
+		VerificationGasLimit          string `json:"verificationGasLimit"`
+		PreVerificationGas            string `json:"preVerificationGas"`
+		PaymasterVerificationGasLimit string `json:"paymasterVerificationGasLimit"`
+		PaymasterPostOpGasLimit       string `json:"paymasterPostOpGasLimit"`
 	}
 	if err := json.Unmarshal(resp.Result, &gasEstimate); err != nil {
 		return op, nil
@@ -169,11 +344,30 @@ func (c *AAClient) EstimateUserOperationGas(ctx context.Context, op *UserOperati
 	op.CallGasLimit = gasEstimate.CallGasLimit
 	op.VerificationGasLimit = gasEstimate.VerificationGasLimit
 	op.PreVerificationGas = gasEstimate.PreVerificationGas
+	if op.Paymaster != "" {
+		op.PaymasterVerificationGasLimit = gasEstimate.PaymasterVerificationGasLimit
+		op.PaymasterPostOpGasLimit = gasEstimate.PaymasterPostOpGasLimit
+	}
 	return op, nil
 }
 
 // SponsorUserOperation gets paymaster sponsorship
 func (c *AAClient) SponsorUserOperation(ctx context.Context, op *UserOperation, chainID int64) (*UserOperation, error) {
+	return c.sponsor(ctx, op, map[string]string{
+		"sponsorshipPolicyId": c.policyID,
+	})
+}
+
+// SponsorUserOperationERC20 gets paymasterAndData from the ERC-20 paymaster,
+// which charges gas to the wallet in token instead of the sponsorship budget.
+// The wallet must have approved the paymaster to spend token.
+func (c *AAClient) SponsorUserOperationERC20(ctx context.Context, op *UserOperation, token string) (*UserOperation, error) {
+	return c.sponsor(ctx, op, map[string]string{
+		"token": token,
+	})
+}
+
+func (c *AAClient) sponsor(ctx context.Context, op *UserOperation, sponsorContext map[string]string) (*UserOperation, error) {
 	if c.paymasterURL == "" {
 		return op, nil
 	}
@@ -183,10 +377,8 @@ func (c *AAClient) SponsorUserOperation(ctx context.Context, op *UserOperation,
 		Method:  "pm_sponsorUserOperation",
 		Params: []interface{}{
 			op,
-			c.entryPoint,
-			map[string]string{
-				"sponsorshipPolicyId": "sp_cheerful_puma", // Pimlico default policy
-			},
+			c.entryPointFor(op),
+			sponsorContext,
 		},
 		ID: 1,
 	}
@@ -196,17 +388,29 @@ func (c *AAClient) SponsorUserOperation(ctx context.Context, op *UserOperation,
 		return op, fmt.Errorf("paymaster sponsorship failed: %w", err)
 	}
 
+	// v0.6 paymasters answer with paymasterAndData, v0.7 ones with the split fields
 	var sponsorResult struct {
-		PaymasterAndData     string `json:"paymasterAndData"`
-		CallGasLimit         string `json:"callGasLimit,omitempty"`
-		VerificationGasLimit string `json:"verificationGasLimit,omitempty"`
-		PreVerificationGas   string `json:"preVerificationGas,omitempty"`
+		PaymasterAndData              string `json:"paymasterAndData"`
+		Paymaster                     string `json:"paymaster"`
+		PaymasterVerificationGasLimit string `json:"paymasterVerificationGasLimit"`
+		PaymasterPostOpGasLimit       string `json:"paymasterPostOpGasLimit"`
+		PaymasterData                 string `json:"paymasterData"`
+		CallGasLimit                  string `json:"callGasLimit,omitempty"`
+		VerificationGasLimit          string `json:"verificationGasLimit,omitempty"`
+		PreVerificationGas            string `json:"preVerificationGas,omitempty"`
 	}
 	if err := json.Unmarshal(resp.Result, &sponsorResult); err != nil {
 		return op, fmt.Errorf("failed to parse sponsor result: %w", err)
 	}
 
-	op.PaymasterAndData = sponsorResult.PaymasterAndData
+	if op.Version == EntryPointV07 {
+		op.Paymaster = sponsorResult.Paymaster
+		op.PaymasterVerificationGasLimit = sponsorResult.PaymasterVerificationGasLimit
+		op.PaymasterPostOpGasLimit = sponsorResult.PaymasterPostOpGasLimit
+		op.PaymasterData = sponsorResult.PaymasterData
+	} else {
+		op.PaymasterAndData = sponsorResult.PaymasterAndData
+	}
 	if sponsorResult.CallGasLimit != "" {
 		op.CallGasLimit = sponsorResult.CallGasLimit
 	}
@@ -223,8 +427,11 @@ func (c *AAClient) SponsorUserOperation(ctx context.Context, op *UserOperation,
 // SignUserOperation signs the user operation
 func SignUserOperation(op *UserOperation, privateKeyHex string, chainID int64, entryPoint string) (*UserOperation, error) {
 	// Compute userOpHash
-	hash := computeUserOpHash(op, chainID, entryPoint)
-	
+	hash, err := computeUserOpHash(op, chainID, entryPoint)
+	if err != nil {
+		return nil, err
+	}
+
 	// Sign with private key
 	privateKey, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
@@ -247,54 +454,139 @@ func SignUserOperation(op *UserOperation, privateKeyHex string, chainID int64, e
 	return op, nil
 }
 
-func computeUserOpHash(op *UserOperation, chainID int64, entryPoint string) []byte {
-	// Pack user operation fields
-	packed := packUserOp(op)
-	opHash := crypto.Keccak256(packed)
-	
-	// Pack with entry point and chain ID
-	entryPointAddr := common.HexToAddress(entryPoint)
-	chainIDBig := big.NewInt(chainID)
-	
-	final := append(opHash, entryPointAddr.Bytes()...)
-	final = append(final, common.LeftPadBytes(chainIDBig.Bytes(), 32)...)
-	
-	return crypto.Keccak256(final)
+// computeUserOpHash hashes a user operation as the EntryPoint's
+// getUserOpHash does: the ABI encoding of the op with its dynamic fields
+// hashed, then that hash with the EntryPoint and chain ID
+func computeUserOpHash(op *UserOperation, chainID int64, entryPoint string) ([]byte, error) {
+	var packed []byte
+	var err error
+	if op.Version == EntryPointV07 {
+		packed, err = packUserOpV07(op)
+	} else {
+		packed, err = packUserOp(op)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := parseAddress("entry point", entryPoint)
+	if err != nil {
+		return nil, err
+	}
+	final, err := userOpHashArgs.Pack(crypto.Keccak256Hash(packed), ep, big.NewInt(chainID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode user operation hash: %w", err)
+	}
+	return crypto.Keccak256(final), nil
 }
 
-func packUserOp(op *UserOperation) []byte {
-	// Simplified packing - in production use proper ABI encoding
-	var packed []byte
-	
-	sender := common.HexToAddress(op.Sender)
-	packed = append(packed, common.LeftPadBytes(sender.Bytes(), 32)...)
-	
-	nonce, _ := new(big.Int).SetString(op.Nonce[2:], 16)
-	packed = append(packed, common.LeftPadBytes(nonce.Bytes(), 32)...)
-	
-	initCode, _ := hex.DecodeString(op.InitCode[2:])
-	packed = append(packed, crypto.Keccak256(initCode)...)
-	
-	callData, _ := hex.DecodeString(op.CallData[2:])
-	packed = append(packed, crypto.Keccak256(callData)...)
-	
-	// Gas values
-	callGas, _ := new(big.Int).SetString(op.CallGasLimit[2:], 16)
-	verificationGas, _ := new(big.Int).SetString(op.VerificationGasLimit[2:], 16)
-	preVerificationGas, _ := new(big.Int).SetString(op.PreVerificationGas[2:], 16)
-	maxFee, _ := new(big.Int).SetString(op.MaxFeePerGas[2:], 16)
-	maxPriority, _ := new(big.Int).SetString(op.MaxPriorityFeePerGas[2:], 16)
-	
-	packed = append(packed, common.LeftPadBytes(callGas.Bytes(), 32)...)
-	packed = append(packed, common.LeftPadBytes(verificationGas.Bytes(), 32)...)
-	packed = append(packed, common.LeftPadBytes(preVerificationGas.Bytes(), 32)...)
-	packed = append(packed, common.LeftPadBytes(maxFee.Bytes(), 32)...)
-	packed = append(packed, common.LeftPadBytes(maxPriority.Bytes(), 32)...)
-	
-	paymasterData, _ := hex.DecodeString(op.PaymasterAndData[2:])
-	packed = append(packed, crypto.Keccak256(paymasterData)...)
-	
-	return packed
+// packUserOp encodes a v0.6 UserOperation for hashing
+func packUserOp(op *UserOperation) ([]byte, error) {
+	sender, err := parseAddress("sender", op.Sender)
+	if err != nil {
+		return nil, err
+	}
+	var quantities [6]*big.Int
+	for i, q := range []struct{ field, value string }{
+		{"nonce", op.Nonce},
+		{"callGasLimit", op.CallGasLimit},
+		{"verificationGasLimit", op.VerificationGasLimit},
+		{"preVerificationGas", op.PreVerificationGas},
+		{"maxFeePerGas", op.MaxFeePerGas},
+		{"maxPriorityFeePerGas", op.MaxPriorityFeePerGas},
+	} {
+		if quantities[i], err = parseHexQuantity(q.field, q.value); err != nil {
+			return nil, err
+		}
+	}
+	var hashed [3]common.Hash
+	for i, b := range []struct{ field, value string }{
+		{"initCode", op.InitCode},
+		{"callData", op.CallData},
+		{"paymasterAndData", op.PaymasterAndData},
+	} {
+		raw, err := parseHexBytes(b.field, b.value)
+		if err != nil {
+			return nil, err
+		}
+		hashed[i] = crypto.Keccak256Hash(raw)
+	}
+
+	packed, err := userOpV06Args.Pack(sender, quantities[0], hashed[0], hashed[1],
+		quantities[1], quantities[2], quantities[3], quantities[4], quantities[5], hashed[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode user operation: %w", err)
+	}
+	return packed, nil
+}
+
+// packUserOpV07 encodes a v0.7 PackedUserOperation for hashing. initCode and
+// paymasterAndData are rebuilt from their split fields, and gas limits and
+// fees are packed as pairs of uint128s.
+func packUserOpV07(op *UserOperation) ([]byte, error) {
+	sender, err := parseAddress("sender", op.Sender)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := parseHexQuantity("nonce", op.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	preVerificationGas, err := parseHexQuantity("preVerificationGas", op.PreVerificationGas)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := parseHexBytes("callData", op.CallData)
+	if err != nil {
+		return nil, err
+	}
+
+	var initCode []byte
+	if op.Factory != "" {
+		factory, err := parseAddress("factory", op.Factory)
+		if err != nil {
+			return nil, err
+		}
+		factoryData, err := parseHexBytes("factoryData", op.FactoryData)
+		if err != nil {
+			return nil, err
+		}
+		initCode = append(factory.Bytes(), factoryData...)
+	}
+
+	var paymasterAndData []byte
+	if op.Paymaster != "" {
+		paymaster, err := parseAddress("paymaster", op.Paymaster)
+		if err != nil {
+			return nil, err
+		}
+		gasLimits, err := packUint128s("paymaster gas limits", op.PaymasterVerificationGasLimit, op.PaymasterPostOpGasLimit)
+		if err != nil {
+			return nil, err
+		}
+		paymasterData, err := parseHexBytes("paymasterData", op.PaymasterData)
+		if err != nil {
+			return nil, err
+		}
+		paymasterAndData = append(paymaster.Bytes(), gasLimits[:]...)
+		paymasterAndData = append(paymasterAndData, paymasterData...)
+	}
+
+	accountGasLimits, err := packUint128s("accountGasLimits", op.VerificationGasLimit, op.CallGasLimit)
+	if err != nil {
+		return nil, err
+	}
+	gasFees, err := packUint128s("gasFees", op.MaxPriorityFeePerGas, op.MaxFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := userOpV07Args.Pack(sender, nonce, crypto.Keccak256Hash(initCode), crypto.Keccak256Hash(callData),
+		accountGasLimits, preVerificationGas, gasFees, crypto.Keccak256Hash(paymasterAndData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode user operation: %w", err)
+	}
+	return packed, nil
 }
 
 // SendUserOperation sends the user operation to the bundler
@@ -302,7 +594,7 @@ func (c *AAClient) SendUserOperation(ctx context.Context, op *UserOperation) (st
 	req := jsonRPCRequest{
 		JSONRPC: "2.0",
 		Method:  "eth_sendUserOperation",
-		Params:  []interface{}{op, c.entryPoint},
+		Params:  []interface{}{op, c.entryPointFor(op)},
 		ID:      1,
 	}
 
@@ -324,24 +616,9 @@ func (c *AAClient) WaitForUserOperationReceipt(ctx context.Context, userOpHash s
 	deadline := time.Now().Add(timeout)
 	
 	for time.Now().Before(deadline) {
-		req := jsonRPCRequest{
-			JSONRPC: "2.0",
-			Method:  "eth_getUserOperationReceipt",
-			Params:  []interface{}{userOpHash},
-			ID:      1,
-		}
-
-		resp, err := c.call(ctx, c.bundlerURL, req)
-		if err == nil && resp.Result != nil {
-			var receipt struct {
-				Receipt struct {
-					TransactionHash string `json:"transactionHash"`
-				} `json:"receipt"`
-				Success bool `json:"success"`
-			}
-			if err := json.Unmarshal(resp.Result, &receipt); err == nil && receipt.Receipt.TransactionHash != "" {
-				return receipt.Receipt.TransactionHash, nil
-			}
+		receipt, err := c.GetUserOperationReceipt(ctx, userOpHash)
+		if err == nil && receipt != nil {
+			return receipt.TxHash, nil
 		}
 
 		time.Sleep(2 * time.Second)
@@ -350,7 +627,52 @@ func (c *AAClient) WaitForUserOperationReceipt(ctx context.Context, userOpHash s
 	return "", fmt.Errorf("timeout waiting for user operation receipt")
 }
 
-func (c *AAClient) call(ctx context.Context, url string, req jsonRPCRequest) (*jsonRPCResponse, error) {
+// UserOperationReceipt is the outcome of an included user operation
+type UserOperationReceipt struct {
+	TxHash  string
+	Success bool
+}
+
+// GetUserOperationReceipt returns the receipt of a user operation, or nil if it is not included yet
+func (c *AAClient) GetUserOperationReceipt(ctx context.Context, userOpHash string) (*UserOperationReceipt, error) {
+	req := jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getUserOperationReceipt",
+		Params:  []interface{}{userOpHash},
+		ID:      1,
+	}
+
+	resp, err := c.call(ctx, c.bundlerURL, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Result == nil {
+		return nil, nil
+	}
+
+	var receipt struct {
+		Receipt struct {
+			TransactionHash string `json:"transactionHash"`
+		} `json:"receipt"`
+		Success bool `json:"success"`
+	}
+	if err := json.Unmarshal(resp.Result, &receipt); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt: %w", err)
+	}
+	if receipt.Receipt.TransactionHash == "" {
+		return nil, nil
+	}
+	return &UserOperationReceipt{TxHash: receipt.Receipt.TransactionHash, Success: receipt.Success}, nil
+}
+
+func (c *AAClient) call(ctx context.Context, url string, req jsonRPCRequest) (_ *jsonRPCResponse, err error) {
+	peer := "bundler"
+	if url == c.paymasterURL && url != c.bundlerURL {
+		peer = "paymaster"
+	}
+	ctx, span := tracing.StartRPC(ctx, peer, req.Method, url)
+	defer func() { tracing.End(span, err) }()
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
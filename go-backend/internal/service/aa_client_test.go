@@ -0,0 +1,235 @@
+package service
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// Expected encodings below were produced independently of go-ethereum's abi
+// package: a word-by-word Solidity ABI encoder and the EntryPoint's
+// getUserOpHash as written in its v0.6 and v0.7 contracts, the same
+// algorithms viem's encodeFunctionData and getUserOperationHash implement.
+
+const (
+	testUSDC      = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	testRecipient = "0x1111111111111111111111111111111111111111"
+	testTarget    = "0x2222222222222222222222222222222222222222"
+	testSender    = "0x3333333333333333333333333333333333333333"
+	testFactory   = "0x9406Cc6185a346906296840746125a0E44976454"
+	testPaymaster = "0x4444444444444444444444444444444444444444"
+
+	testEntryPointV06 = "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+	testEntryPointV07 = "0x0000000071727De22E5E9d8BAf0edAc6f37da032"
+
+	// transfer(0x1111…1111, 1000000)
+	testTransferCall = "0xa9059cbb" +
+		"0000000000000000000000001111111111111111111111111111111111111111" +
+		"00000000000000000000000000000000000000000000000000000000000f4240"
+
+	// execute(USDC, 0, testTransferCall)
+	testExecuteCall = "0xb61d27f6" +
+		"000000000000000000000000833589fcd6edb6e08f4c7c32d4f71b54bda02913" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000060" +
+		"0000000000000000000000000000000000000000000000000000000000000044" +
+		"a9059cbb00000000000000000000000011111111111111111111111111111111" +
+		"1111111100000000000000000000000000000000000000000000000000000000" +
+		"000f424000000000000000000000000000000000000000000000000000000000"
+)
+
+func TestBuildERC20TransferCallData(t *testing.T) {
+	got := BuildERC20TransferCallData(testUSDC, testRecipient, big.NewInt(1_000_000))
+	if got != testTransferCall {
+		t.Fatalf("transfer calldata = %s, want %s", got, testTransferCall)
+	}
+}
+
+func TestBuildExecuteCallData(t *testing.T) {
+	tests := []struct {
+		name  string
+		to    string
+		value *big.Int
+		data  string
+		want  string
+	}{
+		{"token transfer", testUSDC, nil, testTransferCall, testExecuteCall},
+		{"native transfer, 0x call data", testRecipient, big.NewInt(1e15), "0x", "0xb61d27f6" +
+			"0000000000000000000000001111111111111111111111111111111111111111" +
+			"00000000000000000000000000000000000000000000000000038d7ea4c68000" +
+			"0000000000000000000000000000000000000000000000000000000000000060" +
+			"0000000000000000000000000000000000000000000000000000000000000000"},
+		{"native transfer, empty call data", testRecipient, big.NewInt(1e15), "", "0xb61d27f6" +
+			"0000000000000000000000001111111111111111111111111111111111111111" +
+			"00000000000000000000000000000000000000000000000000038d7ea4c68000" +
+			"0000000000000000000000000000000000000000000000000000000000000060" +
+			"0000000000000000000000000000000000000000000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildExecuteCallData(tt.to, tt.value, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("execute calldata = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildExecuteBatchCallData(t *testing.T) {
+	targets := []string{testUSDC, testTarget}
+	calls := []string{testTransferCall, "0x"}
+	// The calls array as both versions encode it: a token transfer and an
+	// empty call
+	callsArray := "" +
+		"0000000000000000000000000000000000000000000000000000000000000002" +
+		"0000000000000000000000000000000000000000000000000000000000000040" +
+		"00000000000000000000000000000000000000000000000000000000000000c0" +
+		"0000000000000000000000000000000000000000000000000000000000000044" +
+		"a9059cbb00000000000000000000000011111111111111111111111111111111" +
+		"1111111100000000000000000000000000000000000000000000000000000000" +
+		"000f424000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000"
+	targetsArray := "" +
+		"0000000000000000000000000000000000000000000000000000000000000002" +
+		"000000000000000000000000833589fcd6edb6e08f4c7c32d4f71b54bda02913" +
+		"0000000000000000000000002222222222222222222222222222222222222222"
+
+	tests := []struct {
+		name  string
+		build func() (string, error)
+		want  string
+	}{
+		{"v0.6", func() (string, error) { return BuildExecuteBatchCallData(targets, calls) }, "0x18dfb3c7" +
+			"0000000000000000000000000000000000000000000000000000000000000040" +
+			"00000000000000000000000000000000000000000000000000000000000000a0" +
+			targetsArray + callsArray},
+		{"v0.6, no calls", func() (string, error) { return BuildExecuteBatchCallData(nil, nil) }, "0x18dfb3c7" +
+			"0000000000000000000000000000000000000000000000000000000000000040" +
+			"0000000000000000000000000000000000000000000000000000000000000060" +
+			"0000000000000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000000"},
+		{"v0.7", func() (string, error) {
+			return BuildExecuteBatchCallDataV07(targets, []*big.Int{nil, big.NewInt(1e15)}, calls)
+		}, "0x47e1da2a" +
+			"0000000000000000000000000000000000000000000000000000000000000060" +
+			"00000000000000000000000000000000000000000000000000000000000000c0" +
+			"0000000000000000000000000000000000000000000000000000000000000120" +
+			targetsArray +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"0000000000000000000000000000000000000000000000000000000000000000" +
+			"00000000000000000000000000000000000000000000000000038d7ea4c68000" +
+			callsArray},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("executeBatch calldata = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildExecuteBatchCallDataRejectsMismatchedCalls(t *testing.T) {
+	if _, err := BuildExecuteBatchCallData([]string{testUSDC}, nil); err == nil {
+		t.Fatal("v0.6 batch with a target but no call was encoded")
+	}
+	if _, err := BuildExecuteBatchCallDataV07([]string{testUSDC}, []*big.Int{nil, nil}, []string{"0x"}); err == nil {
+		t.Fatal("v0.7 batch with more values than targets was encoded")
+	}
+	if _, err := BuildExecuteBatchCallData([]string{"0x1234"}, []string{"0x"}); err == nil {
+		t.Fatal("batch with a short target address was encoded")
+	}
+}
+
+func testUserOp(version string) *UserOperation {
+	return &UserOperation{
+		Version:              version,
+		Sender:               testSender,
+		Nonce:                "0x1",
+		CallData:             testExecuteCall,
+		CallGasLimit:         "0x13880",
+		VerificationGasLimit: "0x186a0",
+		PreVerificationGas:   "0xb708",
+		MaxFeePerGas:         "0x3b9aca00",
+		MaxPriorityFeePerGas: "0x5f5e100",
+	}
+}
+
+func TestComputeUserOpHash(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         func() *UserOperation
+		entryPoint string
+		want       string
+	}{
+		{"v0.6", func() *UserOperation { return testUserOp(EntryPointV06) }, testEntryPointV06,
+			"0xa59e54a0d2eb013f9c9d113e03ce95603e4515b77219597eca6f75beba89aa8a"},
+		{"v0.6, 0x initCode and paymasterAndData", func() *UserOperation {
+			op := testUserOp(EntryPointV06)
+			op.InitCode = "0x"
+			op.PaymasterAndData = "0x"
+			return op
+		}, testEntryPointV06, "0xa59e54a0d2eb013f9c9d113e03ce95603e4515b77219597eca6f75beba89aa8a"},
+		{"v0.6 with initCode and paymaster", func() *UserOperation {
+			op := testUserOp(EntryPointV06)
+			op.InitCode = testFactory + "5fbfb9cf"
+			op.PaymasterAndData = "0x" + strings.Repeat("44", 20) + "aabb"
+			return op
+		}, testEntryPointV06, "0x2b5c10abdb8644be23215b140020dc4e72032b27cc56431d984d548233fcde4f"},
+		{"v0.7", func() *UserOperation { return testUserOp(EntryPointV07) }, testEntryPointV07,
+			"0xb5ca91373aa9d7b9298135497c5a9231903529b8c939ea089a52584b18d93916"},
+		{"v0.7 with factory and paymaster", func() *UserOperation {
+			op := testUserOp(EntryPointV07)
+			op.Factory = testFactory
+			op.FactoryData = "0x5fbfb9cf"
+			op.Paymaster = testPaymaster
+			op.PaymasterVerificationGasLimit = "0x7530"
+			op.PaymasterPostOpGasLimit = "0x4e20"
+			op.PaymasterData = "0xaabb"
+			return op
+		}, testEntryPointV07, "0x7f4271d707165309ff774a4cf9609f6174fbb185342f51ef38364eb3733cf04a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := computeUserOpHash(tt.op(), 8453, tt.entryPoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := "0x" + hex.EncodeToString(hash); got != tt.want {
+				t.Fatalf("userOpHash = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeUserOpHashRejectsBadFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(op *UserOperation)
+	}{
+		{"sender", func(op *UserOperation) { op.Sender = "0x1234" }},
+		{"nonce", func(op *UserOperation) { op.Nonce = "0xzz" }},
+		{"call data", func(op *UserOperation) { op.CallData = "0x123" }},
+		{"v0.7 gas limit over 128 bits", func(op *UserOperation) {
+			op.Version = EntryPointV07
+			op.CallGasLimit = "0x1" + strings.Repeat("0", 32)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := testUserOp(EntryPointV06)
+			tt.modify(op)
+			if _, err := computeUserOpHash(op, 8453, testEntryPointV06); err == nil {
+				t.Fatal("user operation with a bad field was hashed")
+			}
+		})
+	}
+}
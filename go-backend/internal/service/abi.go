@@ -0,0 +1,122 @@
+package service
+
+import (
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Contract ABIs the backend encodes calls for
+//
+//go:embed abis/*.json
+var abiFiles embed.FS
+
+var (
+	// SimpleAccount for EntryPoint v0.6: executeBatch takes no values
+	simpleAccountABI = mustLoadABI("abis/simple_account.json")
+	// SimpleAccount for EntryPoint v0.7: executeBatch carries a value per call
+	simpleAccountV07ABI = mustLoadABI("abis/simple_account_v07.json")
+	// EntryPoint events, the same in v0.6 and v0.7
+	entryPointABI = mustLoadABI("abis/entry_point.json")
+	// Moonbeam's xtokens precompile
+	xtokensABI = mustLoadABI("abis/xtokens.json")
+	// Astar's XCM precompile (v2), xtokens-like with a two-dimensional weight
+	astarXCMABI = mustLoadABI("abis/astar_xcm.json")
+)
+
+// Encodings the EntryPoint hashes user operations with (getUserOpHash)
+var (
+	userOpV06Args = mustArguments("address", "uint256", "bytes32", "bytes32",
+		"uint256", "uint256", "uint256", "uint256", "uint256", "bytes32")
+	userOpV07Args = mustArguments("address", "uint256", "bytes32", "bytes32",
+		"bytes32", "uint256", "bytes32", "bytes32")
+	userOpHashArgs = mustArguments("bytes32", "address", "uint256")
+)
+
+func mustLoadABI(name string) abi.ABI {
+	f, err := abiFiles.Open(name)
+	if err != nil {
+		panic(fmt.Sprintf("abi %s: %v", name, err))
+	}
+	defer f.Close()
+
+	parsed, err := abi.JSON(f)
+	if err != nil {
+		panic(fmt.Sprintf("abi %s: %v", name, err))
+	}
+	return parsed
+}
+
+func mustArguments(types ...string) abi.Arguments {
+	args := make(abi.Arguments, len(types))
+	for i, t := range types {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(fmt.Sprintf("abi type %s: %v", t, err))
+		}
+		args[i] = abi.Argument{Type: typ}
+	}
+	return args
+}
+
+// packCall encodes a call to method and returns it as 0x-prefixed hex
+func packCall(contract abi.ABI, method string, args ...interface{}) (string, error) {
+	data, err := contract.Pack(method, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", method, err)
+	}
+	return "0x" + hex.EncodeToString(data), nil
+}
+
+// parseAddress rejects anything that is not a 20-byte hex address rather
+// than encoding it as some other address
+func parseAddress(field, s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid %s address %q", field, s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// parseHexBytes decodes 0x-prefixed hex; empty and "0x" are no bytes
+func parseHexBytes(field, s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", field, s, err)
+	}
+	return b, nil
+}
+
+// parseHexQuantity decodes a 0x-prefixed hex number; empty is zero
+func parseHexQuantity(field, s string) (*big.Int, error) {
+	digits := strings.TrimPrefix(s, "0x")
+	if digits == "" {
+		return new(big.Int), nil
+	}
+	n, ok := new(big.Int).SetString(digits, 16)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s %q", field, s)
+	}
+	return n, nil
+}
+
+// packUint128s puts two 128-bit values into one word, high then low, as the
+// v0.7 EntryPoint packs gas limits and fees
+func packUint128s(field, high, low string) ([32]byte, error) {
+	var word [32]byte
+	for i, s := range []string{high, low} {
+		n, err := parseHexQuantity(field, s)
+		if err != nil {
+			return word, err
+		}
+		if n.BitLen() > 128 {
+			return word, fmt.Errorf("%s %s does not fit in 128 bits", field, s)
+		}
+		n.FillBytes(word[i*16 : (i+1)*16])
+	}
+	return word, nil
+}
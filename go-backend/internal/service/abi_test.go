@@ -0,0 +1,49 @@
+package service
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestPackUint128s(t *testing.T) {
+	tests := []struct {
+		name      string
+		high, low string
+		want      string
+	}{
+		{"gas limits", "0x186a0", "0x13880",
+			"000000000000000000000000000186a0" + "00000000000000000000000000013880"},
+		{"empty is zero", "", "0x5f5e100",
+			"00000000000000000000000000000000" + "00000000000000000000000005f5e100"},
+		{"max uint128", "0x" + strings.Repeat("f", 32), "0x0",
+			strings.Repeat("f", 32) + "00000000000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			word, err := packUint128s("test", tt.high, tt.low)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(word[:]); got != tt.want {
+				t.Fatalf("packed = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := packUint128s("test", "0x1"+strings.Repeat("0", 32), "0x0"); err == nil {
+		t.Fatal("value over 128 bits was packed")
+	}
+}
+
+func TestParseHexBytesEmpty(t *testing.T) {
+	for _, s := range []string{"", "0x"} {
+		b, err := parseHexBytes("test", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != 0 {
+			t.Fatalf("parseHexBytes(%q) = %x, want no bytes", s, b)
+		}
+	}
+}
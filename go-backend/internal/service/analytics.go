@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrInvalidAnalyticsInterval  = newCodedError("invalid_analytics_interval")
+	ErrInvalidAnalyticsBreakdown = newCodedError("invalid_analytics_breakdown")
+)
+
+// Time-series analytics intervals, finest first
+var analyticsIntervals = []string{"hour", "day", "week"}
+
+// analyticsSpans is the range a time series covers when no from is given
+var analyticsSpans = map[string]time.Duration{
+	"hour": 24 * time.Hour,
+	"day":  30 * 24 * time.Hour,
+	"week": 12 * 7 * 24 * time.Hour,
+}
+
+var analyticsBucketLengths = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// maxAnalyticsBuckets caps how many buckets one time series spans
+const maxAnalyticsBuckets = 1000
+
+// AnalyticsService serves an enterprise's claims, unique claimers, spend and
+// pocket conversion over time. Series are read from per hour, day and week
+// rollups that a background job keeps up to date, so they lag behind live
+// claims by up to ANALYTICS_ROLLUP_INTERVAL.
+type AnalyticsService struct {
+	repo         *repository.AnalyticsRepository
+	campaignRepo repository.CampaignStore
+	cfg          *config.Config
+}
+
+func NewAnalyticsService(repo *repository.AnalyticsRepository, campaignRepo repository.CampaignStore, cfg *config.Config) *AnalyticsService {
+	return &AnalyticsService{
+		repo:         repo,
+		campaignRepo: campaignRepo,
+		cfg:          cfg,
+	}
+}
+
+type TimeSeriesQuery struct {
+	Interval   string // hour, day or week; default day
+	From       string // date or RFC 3339 time, as for reports
+	To         string
+	CampaignID string // all campaigns when empty
+	By         string // platform, token or empty
+}
+
+// TimeSeries returns an enterprise's activity per interval between q.From
+// (default one span before q.To) and q.To (default now)
+func (s *AnalyticsService) TimeSeries(ctx context.Context, enterpriseID string, q *TimeSeriesQuery) (*model.AnalyticsSeries, error) {
+	interval := q.Interval
+	if interval == "" {
+		interval = "day"
+	}
+	span, ok := analyticsSpans[interval]
+	if !ok {
+		return nil, ErrInvalidAnalyticsInterval
+	}
+	if q.By != "" && q.By != "platform" && q.By != "token" {
+		return nil, ErrInvalidAnalyticsBreakdown
+	}
+
+	from, err := parseReportTime(q.From, false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseReportTime(q.To, true)
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		now := time.Now().UTC()
+		to = &now
+	}
+	if from == nil {
+		start := to.Add(-span)
+		from = &start
+	}
+	if !from.Before(*to) || to.Sub(*from) > maxAnalyticsBuckets*analyticsBucketLengths[interval] {
+		return nil, ErrInvalidReportRange
+	}
+
+	if q.CampaignID != "" {
+		campaign, err := s.campaignRepo.GetByID(ctx, q.CampaignID)
+		if err != nil || campaign.EnterpriseID != enterpriseID {
+			return nil, ErrCampaignNotFound
+		}
+	}
+
+	buckets, err := s.repo.TimeSeries(ctx, enterpriseID, interval, q.CampaignID, q.By == "platform", q.By == "token", *from, *to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time series: %w", err)
+	}
+	for _, b := range buckets {
+		analyticsRates(b)
+	}
+	if buckets == nil {
+		buckets = []*model.AnalyticsBucket{}
+	}
+	rolledUpAt, err := s.repo.RolledUpAt(ctx, interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rollup state: %w", err)
+	}
+
+	return &model.AnalyticsSeries{
+		Interval:   interval,
+		From:       *from,
+		To:         *to,
+		CampaignID: q.CampaignID,
+		By:         q.By,
+		Buckets:    buckets,
+		RolledUpAt: rolledUpAt,
+	}, nil
+}
+
+// analyticsRates derives a bucket's conversion rate and average claim
+func analyticsRates(b *model.AnalyticsBucket) {
+	if b.Pockets > 0 {
+		b.ConversionRate = float64(b.Claims) / float64(b.Pockets)
+	}
+	if b.Claims > 0 {
+		b.AverageAmount = b.Amount / float64(b.Claims)
+	}
+}
+
+// Start rolls up every interval every ANALYTICS_ROLLUP_INTERVAL seconds.
+// Blocks until ctx is cancelled.
+func (s *AnalyticsService) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.AnalyticsRollupInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		for _, interval := range analyticsIntervals {
+			s.rollup(ctx, interval)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollup recomputes an interval's buckets from where the last rollup left
+// off. The next one starts ANALYTICS_ROLLUP_SETTLE seconds back, so claims
+// whose status changes after the fact are recounted.
+func (s *AnalyticsService) rollup(ctx context.Context, interval string) {
+	from, err := s.repo.RollupFrom(ctx, interval)
+	if err != nil {
+		slog.ErrorContext(ctx, "analytics: failed to load rollup state", "interval", interval, "error", err)
+		return
+	}
+	next := time.Now().Add(-time.Duration(s.cfg.AnalyticsRollupSettle) * time.Second)
+	if _, err := s.repo.Rollup(ctx, interval, from, next); err != nil {
+		slog.ErrorContext(ctx, "analytics: rollup failed", "interval", interval, "error", err)
+	}
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrAudienceProofRequired = newCodedError("audience_proof_required")
+	ErrAudienceBadSignature  = newCodedError("audience_bad_signature")
+	ErrNotInAudience         = newCodedError("not_in_audience")
+	ErrAudienceAlreadyUsed   = newCodedError("audience_already_used")
+	ErrCampaignNotFound      = errors.New("campaign not found")
+)
+
+// ERC20 Transfer(address,address,uint256) event topic
+const erc20TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// Block range per eth_getLogs request when computing snapshots
+const snapshotLogChunk = 5000
+
+type AudienceService struct {
+	repo         *repository.AudienceRepository
+	campaignRepo repository.CampaignStore
+	xcmBridge    *XCMBridge
+	httpClient   *http.Client
+}
+
+func NewAudienceService(
+	repo *repository.AudienceRepository,
+	campaignRepo repository.CampaignStore,
+	xcmBridge *XCMBridge,
+) *AudienceService {
+	return &AudienceService{
+		repo:         repo,
+		campaignRepo: campaignRepo,
+		xcmBridge:    xcmBridge,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// AuthorizeCampaign ensures the campaign exists and belongs to the enterprise
+func (s *AudienceService) AuthorizeCampaign(ctx context.Context, campaignID, enterpriseID string) error {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
+
+type UploadAudienceRequest struct {
+	Entries     []model.AudienceEntry `json:"entries" binding:"required,min=1"`
+	BlockNumber int64                 `json:"blockNumber"`
+	ChainID     int64                 `json:"chainId"`
+}
+
+// Upload stores an enterprise-provided holder list as a ready snapshot
+func (s *AudienceService) Upload(ctx context.Context, campaignID string, req *UploadAudienceRequest) (*model.AudienceSnapshot, error) {
+	entries := make([]model.AudienceEntry, 0, len(req.Entries))
+	for _, e := range req.Entries {
+		if !common.IsHexAddress(e.Address) {
+			return nil, fmt.Errorf("invalid address: %s", e.Address)
+		}
+		balance := e.Balance
+		if balance == "" {
+			balance = "0"
+		}
+		if _, ok := new(big.Int).SetString(balance, 10); !ok {
+			return nil, fmt.Errorf("invalid balance for %s: %s", e.Address, e.Balance)
+		}
+		entries = append(entries, model.AudienceEntry{Address: normalizeAddress(e.Address), Balance: balance})
+	}
+
+	chainID := req.ChainID
+	if chainID == 0 {
+		chainID = int64(ChainBase)
+	}
+
+	snapshot := &model.AudienceSnapshot{
+		ID:          "snap_" + uuid.New().String()[:8],
+		CampaignID:  campaignID,
+		Source:      "upload",
+		ChainID:     chainID,
+		BlockNumber: req.BlockNumber,
+		MinBalance:  "0",
+		Status:      "pending",
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.CreateSnapshot(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	if err := s.repo.InsertEntries(ctx, snapshot.ID, entries); err != nil {
+		s.repo.UpdateSnapshotStatus(ctx, snapshot.ID, "failed", err.Error(), 0)
+		return nil, fmt.Errorf("failed to store snapshot entries: %w", err)
+	}
+
+	snapshot.Status = "ready"
+	snapshot.HolderCount = len(entries)
+	if err := s.repo.UpdateSnapshotStatus(ctx, snapshot.ID, snapshot.Status, "", snapshot.HolderCount); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+type ComputeAudienceRequest struct {
+	TokenAddress string `json:"tokenAddress" binding:"required"`
+	ChainID      int64  `json:"chainId"`
+	FromBlock    int64  `json:"fromBlock"` // token deployment block, speeds up log scanning
+	BlockNumber  int64  `json:"blockNumber" binding:"required,gt=0"`
+	MinBalance   string `json:"minBalance"` // raw token units
+}
+
+// Compute builds a snapshot of token holders at a block from Transfer logs.
+// The scan runs in the background; poll the snapshot until it is ready.
+func (s *AudienceService) Compute(ctx context.Context, campaignID string, req *ComputeAudienceRequest) (*model.AudienceSnapshot, error) {
+	if !common.IsHexAddress(req.TokenAddress) {
+		return nil, fmt.Errorf("invalid token address: %s", req.TokenAddress)
+	}
+	if req.FromBlock > req.BlockNumber {
+		return nil, errors.New("fromBlock must not be after blockNumber")
+	}
+
+	chainID := req.ChainID
+	if chainID == 0 {
+		chainID = int64(ChainBase)
+	}
+	rpcURL := s.xcmBridge.rpcURL(ChainID(chainID))
+	if rpcURL == "" {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+
+	minBalance := new(big.Int)
+	if req.MinBalance != "" {
+		if _, ok := minBalance.SetString(req.MinBalance, 10); !ok {
+			return nil, fmt.Errorf("invalid minBalance: %s", req.MinBalance)
+		}
+	}
+
+	snapshot := &model.AudienceSnapshot{
+		ID:           "snap_" + uuid.New().String()[:8],
+		CampaignID:   campaignID,
+		Source:       "chain",
+		TokenAddress: normalizeAddress(req.TokenAddress),
+		ChainID:      chainID,
+		BlockNumber:  req.BlockNumber,
+		MinBalance:   minBalance.String(),
+		Status:       "computing",
+		CreatedAt:    time.Now(),
+	}
+	if err := s.repo.CreateSnapshot(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	// Outlives the request, but keeps its request ID for logging
+	go s.computeFromChain(context.WithoutCancel(ctx), snapshot.ID, rpcURL, snapshot.TokenAddress, req.FromBlock, req.BlockNumber, minBalance)
+
+	return snapshot, nil
+}
+
+func (s *AudienceService) computeFromChain(ctx context.Context, snapshotID, rpcURL, token string, fromBlock, toBlock int64, minBalance *big.Int) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	balances, err := s.replayTransfers(ctx, rpcURL, token, fromBlock, toBlock)
+	if err != nil {
+		slog.ErrorContext(ctx, "audience: snapshot failed", "snapshot_id", snapshotID, "error", err)
+		s.repo.UpdateSnapshotStatus(ctx, snapshotID, "failed", err.Error(), 0)
+		return
+	}
+
+	entries := make([]model.AudienceEntry, 0, len(balances))
+	for addr, bal := range balances {
+		if bal.Sign() <= 0 || bal.Cmp(minBalance) < 0 {
+			continue
+		}
+		entries = append(entries, model.AudienceEntry{Address: addr, Balance: bal.String()})
+	}
+
+	if err := s.repo.InsertEntries(ctx, snapshotID, entries); err != nil {
+		s.repo.UpdateSnapshotStatus(ctx, snapshotID, "failed", err.Error(), 0)
+		return
+	}
+	s.repo.UpdateSnapshotStatus(ctx, snapshotID, "ready", "", len(entries))
+}
+
+// replayTransfers folds all Transfer logs of a token up to toBlock into balances
+func (s *AudienceService) replayTransfers(ctx context.Context, rpcURL, token string, fromBlock, toBlock int64) (map[string]*big.Int, error) {
+	zero := normalizeAddress(common.Address{}.Hex())
+	balances := make(map[string]*big.Int)
+
+	credit := func(addr string, amount *big.Int) {
+		if addr == zero {
+			return
+		}
+		bal, ok := balances[addr]
+		if !ok {
+			bal = new(big.Int)
+			balances[addr] = bal
+		}
+		bal.Add(bal, amount)
+	}
+
+	for start := fromBlock; start <= toBlock; start += snapshotLogChunk {
+		end := start + snapshotLogChunk - 1
+		if end > toBlock {
+			end = toBlock
+		}
+
+		result, err := callRPC(ctx, s.httpClient, rpcURL, "eth_getLogs", map[string]interface{}{
+			"address":   token,
+			"fromBlock": fmt.Sprintf("0x%x", start),
+			"toBlock":   fmt.Sprintf("0x%x", end),
+			"topics":    []interface{}{erc20TransferTopic},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("eth_getLogs %d-%d: %w", start, end, err)
+		}
+
+		var logs []struct {
+			Topics []string `json:"topics"`
+			Data   string   `json:"data"`
+		}
+		if err := json.Unmarshal(result, &logs); err != nil {
+			return nil, fmt.Errorf("failed to parse logs: %w", err)
+		}
+
+		for _, l := range logs {
+			// ERC721 transfers index the token ID and have no data; skip them
+			if len(l.Topics) != 3 || len(l.Data) < 3 {
+				continue
+			}
+			from := normalizeAddress(common.HexToAddress(l.Topics[1]).Hex())
+			to := normalizeAddress(common.HexToAddress(l.Topics[2]).Hex())
+			amount := new(big.Int).SetBytes(common.FromHex(l.Data))
+
+			credit(from, new(big.Int).Neg(amount))
+			credit(to, amount)
+		}
+	}
+
+	return balances, nil
+}
+
+func (s *AudienceService) ListSnapshots(ctx context.Context, campaignID string) ([]*model.AudienceSnapshot, error) {
+	return s.repo.ListSnapshots(ctx, campaignID)
+}
+
+func (s *AudienceService) GetSnapshot(ctx context.Context, id string) (*model.AudienceSnapshot, error) {
+	return s.repo.GetSnapshot(ctx, id)
+}
+
+func (s *AudienceService) GetCoverage(ctx context.Context, snapshotID string) (*model.AudienceCoverage, error) {
+	return s.repo.GetCoverage(ctx, snapshotID)
+}
+
+// AudienceClaimMessage is the message a holder signs to prove address ownership at claim time
+func AudienceClaimMessage(redPocketID, platform, platformID string) string {
+	return fmt.Sprintf("Claim red pocket %s for %s:%s", redPocketID, platform, platformID)
+}
+
+// CheckEligibility gates a claim against the campaign's active snapshot.
+// It returns the snapshot used (nil if the campaign has no audience) and the normalized address.
+func (s *AudienceService) CheckEligibility(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) (*model.AudienceSnapshot, string, error) {
+	snapshot, err := s.repo.GetActiveSnapshot(ctx, rp.CampaignID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if req.WalletAddress == "" || req.Signature == "" {
+		return nil, "", ErrAudienceProofRequired
+	}
+	if !common.IsHexAddress(req.WalletAddress) {
+		return nil, "", ErrAudienceBadSignature
+	}
+
+	address := normalizeAddress(req.WalletAddress)
+	signer, err := recoverPersonalSigner(AudienceClaimMessage(rp.ID, req.Platform, req.PlatformID), req.Signature)
+	if err != nil || signer != address {
+		return nil, "", ErrAudienceBadSignature
+	}
+
+	eligible, err := s.repo.IsEligible(ctx, snapshot.ID, address)
+	if err != nil {
+		return nil, "", err
+	}
+	if !eligible {
+		return nil, "", ErrNotInAudience
+	}
+
+	used, err := s.repo.HasClaimed(ctx, rp.ID, address)
+	if err != nil {
+		return nil, "", err
+	}
+	if used {
+		return nil, "", ErrAudienceAlreadyUsed
+	}
+
+	return snapshot, address, nil
+}
+
+func (s *AudienceService) RecordClaim(ctx context.Context, snapshotID, address, redPocketID, claimID string) error {
+	return s.repo.RecordClaim(ctx, snapshotID, address, redPocketID, claimID)
+}
+
+// recoverPersonalSigner recovers the address that produced an EIP-191 personal_sign signature
+func recoverPersonalSigner(message, signatureHex string) (string, error) {
+	sig := common.FromHex(signatureHex)
+	if len(sig) != 65 {
+		return "", errors.New("invalid signature length")
+	}
+	sig = append([]byte(nil), sig...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return "", err
+	}
+	return normalizeAddress(crypto.PubkeyToAddress(*pub).Hex()), nil
+}
+
+func normalizeAddress(addr string) string {
+	return strings.ToLower(common.HexToAddress(addr).Hex())
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// BridgeEvent is a bridge transfer's state as it moves through its stages.
+// Step counts the stages reached out of Steps, for progress bars: 0 while
+// pending, Steps once completed. A failed transfer keeps the step it
+// failed at.
+type BridgeEvent struct {
+	Type           string                `json:"type"` // snapshot, status
+	Transfer       *BridgeTransferStatus `json:"transfer"`
+	PreviousStatus string                `json:"previousStatus,omitempty"`
+	Step           int                   `json:"step"`
+	Steps          int                   `json:"steps"`
+	NextCheckAt    *time.Time            `json:"nextCheckAt,omitempty"` // when the tracker next advances it
+	At             time.Time             `json:"at"`
+}
+
+// BridgeEvents publishes bridge transfer transitions over Redis pub/sub.
+// Transfers are advanced by the bridge job on the leader replica, while
+// their streams may be served by any.
+type BridgeEvents struct {
+	redis *repository.RedisClient
+}
+
+func NewBridgeEvents(redis *repository.RedisClient) *BridgeEvents {
+	return &BridgeEvents{redis: redis}
+}
+
+func bridgeEventsChannel(bridgeID string) string {
+	return "bridge:events:" + bridgeID
+}
+
+// Subscribe streams the transitions of a transfer until ctx is cancelled or
+// the returned close function is called
+func (e *BridgeEvents) Subscribe(ctx context.Context, bridgeID string) (<-chan *BridgeEvent, func(), error) {
+	sub := e.redis.Subscribe(ctx, bridgeEventsChannel(bridgeID))
+	// Wait for the subscription so no transition published after this returns is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan *BridgeEvent, 16)
+	go func() {
+		defer close(events)
+		for msg := range sub.Channel() {
+			ev := &BridgeEvent{}
+			if err := json.Unmarshal([]byte(msg.Payload), ev); err != nil {
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, func() { sub.Close() }, nil
+}
+
+// publish is best effort: a missed transition only holds a progress bar
+// back until the next one, and the final one can be read from the status
+func (e *BridgeEvents) publish(ctx context.Context, ev *BridgeEvent) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	if err := e.redis.Publish(ctx, bridgeEventsChannel(ev.Transfer.BridgeID), payload); err != nil {
+		slog.WarnContext(ctx, "bridge events: failed to publish", "status", ev.Transfer.Status, "bridge_id", ev.Transfer.BridgeID, "error", err)
+	}
+}
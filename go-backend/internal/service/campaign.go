@@ -2,29 +2,35 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/protocolbank/redpocket-backend/internal/config"
 	"github.com/protocolbank/redpocket-backend/internal/model"
 	"github.com/protocolbank/redpocket-backend/internal/repository"
 )
 
 type CampaignService struct {
-	repo     *repository.CampaignRepository
-	claimRepo *repository.ClaimRepository
+	repo     repository.CampaignStore
+	claimRepo repository.ClaimStore
+	analytics *Downstream
 	cfg      *config.Config
 }
 
 func NewCampaignService(
-	repo *repository.CampaignRepository,
-	claimRepo *repository.ClaimRepository,
+	repo repository.CampaignStore,
+	claimRepo repository.ClaimStore,
 	cfg *config.Config,
 ) *CampaignService {
 	return &CampaignService{
 		repo:     repo,
 		claimRepo: claimRepo,
+		analytics: NewDownstream("analytics", cfg.DownstreamAnalytics),
 		cfg:      cfg,
 	}
 }
@@ -38,9 +44,19 @@ type CreateCampaignRequest struct {
 	TokenAddress string  `json:"tokenAddress"`
 	Platform     string  `json:"platform" binding:"required"`
 	Tag          string  `json:"tag"`
+	PayoutMode   string  `json:"payoutMode" binding:"omitempty,oneof=onchain credit"` // default onchain
 }
 
 func (s *CampaignService) Create(ctx context.Context, req *CreateCampaignRequest) (*model.Campaign, error) {
+	testMode, err := s.repo.EnterpriseTestMode(ctx, req.EnterpriseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load enterprise: %w", err)
+	}
+	if testMode && req.PayoutMode == PayoutModeCredit {
+		// Credited balances can be withdrawn on chain for real
+		return nil, ErrSandboxCreditPayout
+	}
+
 	campaign := &model.Campaign{
 		ID:           "campaign_" + uuid.New().String()[:8],
 		EnterpriseID: req.EnterpriseID,
@@ -58,6 +74,11 @@ func (s *CampaignService) Create(ctx context.Context, req *CreateCampaignRequest
 		Status:       "active",
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
+		PayoutMode:   req.PayoutMode,
+		TestMode:     testMode,
+	}
+	if campaign.PayoutMode == "" {
+		campaign.PayoutMode = PayoutModeOnchain
 	}
 
 	if err := s.repo.Create(ctx, campaign); err != nil {
@@ -105,7 +126,13 @@ func (s *CampaignService) GetAllClaims(ctx context.Context, enterpriseID string,
 }
 
 func (s *CampaignService) GetAnalytics(ctx context.Context, enterpriseID string) (*model.CampaignAnalytics, error) {
-	return s.repo.GetAnalytics(ctx, enterpriseID)
+	var analytics *model.CampaignAnalytics
+	err := s.analytics.Do(ctx, func(ctx context.Context) error {
+		var err error
+		analytics, err = s.repo.GetAnalytics(ctx, enterpriseID)
+		return err
+	})
+	return analytics, err
 }
 
 func (s *CampaignService) UpdateStatus(ctx context.Context, id, status string) error {
@@ -120,3 +147,187 @@ func (s *CampaignService) UpdateStatus(ctx context.Context, id, status string) e
 func (s *CampaignService) Delete(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
 }
+
+// ErrInvalidClaimPageURL rejects links that are not plain http(s), so a
+// claim page cannot be made to run script
+var ErrInvalidClaimPageURL = errors.New("claim page links must be http or https URLs")
+
+type ClaimPageRequest struct {
+	LogoURL         string `json:"logoUrl" binding:"omitempty,url,max=512"`
+	PrimaryColor    string `json:"primaryColor" binding:"omitempty,hexcolor"`
+	BackgroundColor string `json:"backgroundColor" binding:"omitempty,hexcolor"`
+	TextColor       string `json:"textColor" binding:"omitempty,hexcolor"`
+	Title           string `json:"title" binding:"max=120"`
+	Subtitle        string `json:"subtitle" binding:"max=280"`
+	ButtonText      string `json:"buttonText" binding:"max=40"`
+	SuccessMessage  string `json:"successMessage" binding:"max=280"`
+	CTA             *struct {
+		Label string `json:"label" binding:"required,max=40"`
+		URL   string `json:"url" binding:"required,url,max=512"`
+	} `json:"cta"`
+	Disclaimer string `json:"disclaimer" binding:"max=4000"`
+}
+
+// GetClaimPage returns the claim page branding of a campaign
+func (s *CampaignService) GetClaimPage(ctx context.Context, id string) (*model.ClaimPageConfig, error) {
+	page, err := s.repo.GetClaimPage(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	return page, err
+}
+
+// UpdateClaimPage replaces the claim page branding of a campaign. An empty
+// request resets the page to the default.
+func (s *CampaignService) UpdateClaimPage(ctx context.Context, id string, req *ClaimPageRequest) (*model.ClaimPageConfig, error) {
+	now := time.Now()
+	page := &model.ClaimPageConfig{
+		LogoURL:         req.LogoURL,
+		PrimaryColor:    req.PrimaryColor,
+		BackgroundColor: req.BackgroundColor,
+		TextColor:       req.TextColor,
+		Title:           req.Title,
+		Subtitle:        req.Subtitle,
+		ButtonText:      req.ButtonText,
+		SuccessMessage:  req.SuccessMessage,
+		Disclaimer:      req.Disclaimer,
+		UpdatedAt:       &now,
+	}
+	if req.CTA != nil {
+		page.CTA = &model.ClaimPageCTA{Label: req.CTA.Label, URL: req.CTA.URL}
+	}
+	for _, link := range []string{page.LogoURL, ctaURL(page.CTA)} {
+		if link == "" {
+			continue
+		}
+		if u, err := url.Parse(link); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return nil, ErrInvalidClaimPageURL
+		}
+	}
+
+	err := s.repo.SetClaimPage(ctx, id, page)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update claim page: %w", err)
+	}
+	return page, nil
+}
+
+func ctaURL(cta *model.ClaimPageCTA) string {
+	if cta == nil {
+		return ""
+	}
+	return cta.URL
+}
+
+type TermsRequest struct {
+	Version string `json:"version" binding:"max=32"` // empty removes the terms
+	Text    string `json:"text" binding:"required_with=Version,max=20000"`
+}
+
+// GetTerms returns the current terms of a campaign
+func (s *CampaignService) GetTerms(ctx context.Context, id string) (*model.CampaignTerms, error) {
+	terms, err := s.repo.GetTerms(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	return terms, err
+}
+
+// UpdateTerms publishes a new terms version. Claims from then on must accept
+// it; earlier claims keep the version they accepted.
+func (s *CampaignService) UpdateTerms(ctx context.Context, id string, req *TermsRequest) (*model.CampaignTerms, error) {
+	now := time.Now()
+	terms := &model.CampaignTerms{Version: req.Version, Text: req.Text, UpdatedAt: &now}
+	if terms.Version == "" {
+		terms.Text = ""
+	}
+
+	err := s.repo.SetTerms(ctx, id, terms)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update terms: %w", err)
+	}
+	return terms, nil
+}
+
+type VisibilityRequest struct {
+	Discoverable bool `json:"discoverable"`
+	MaskAmounts  bool `json:"maskAmounts"`
+}
+
+// GetVisibility returns the discovery feed settings of a campaign
+func (s *CampaignService) GetVisibility(ctx context.Context, id string) (*model.CampaignVisibility, error) {
+	v, err := s.repo.GetVisibility(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	return v, err
+}
+
+// UpdateVisibility opts a campaign's active pockets in or out of the public
+// discovery feed
+func (s *CampaignService) UpdateVisibility(ctx context.Context, id string, req *VisibilityRequest) (*model.CampaignVisibility, error) {
+	now := time.Now()
+	v := &model.CampaignVisibility{Discoverable: req.Discoverable, MaskAmounts: req.MaskAmounts, UpdatedAt: &now}
+
+	err := s.repo.SetVisibility(ctx, id, v)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update visibility: %w", err)
+	}
+	return v, nil
+}
+
+// ErrInvalidCharityAddress rejects a charity round-up without a valid address
+var ErrInvalidCharityAddress = errors.New("charity address must be a valid, non-zero address")
+
+type CharityRequest struct {
+	Address string `json:"address" binding:"required_with=Bps"`
+	Name    string `json:"name" binding:"max=120"`
+	Bps     int    `json:"bps" binding:"min=0,max=10000"` // share of each claim; 0 turns the round-up off
+}
+
+// GetCharity returns the charity round-up of a campaign and what it has
+// raised so far
+func (s *CampaignService) GetCharity(ctx context.Context, id string) (*model.CampaignCharity, error) {
+	charity, err := s.repo.GetCharity(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if charity.TotalDonated, err = s.repo.TotalDonated(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to total donations: %w", err)
+	}
+	return charity, nil
+}
+
+// UpdateCharity sets the share of every future claim of a campaign that is
+// donated to a charity address. Claims already made are not affected.
+func (s *CampaignService) UpdateCharity(ctx context.Context, id string, req *CharityRequest) (*model.CampaignCharity, error) {
+	now := time.Now()
+	charity := &model.CampaignCharity{Name: req.Name, Bps: req.Bps, UpdatedAt: &now}
+	if req.Address != "" {
+		if !common.IsHexAddress(req.Address) || common.HexToAddress(req.Address) == (common.Address{}) {
+			return nil, ErrInvalidCharityAddress
+		}
+		charity.Address = common.HexToAddress(req.Address).Hex()
+	}
+
+	err := s.repo.SetCharity(ctx, id, charity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update charity: %w", err)
+	}
+	return s.GetCharity(ctx, id)
+}
@@ -0,0 +1,755 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// Events rules run on
+const (
+	RuleOnClaim    = "claim"    // after each claim on the campaign's pockets
+	RuleOnSchedule = "schedule" // every ruleSweepInterval
+)
+
+// Actions rules carry out
+const (
+	RuleCreatePocket       = "create_pocket"
+	RulePauseCampaign      = "pause_campaign"
+	RuleSendAlert          = "send_alert"
+	RuleAdjustDistribution = "adjust_distribution"
+)
+
+const (
+	maxCampaignRules  = 20
+	maxRuleConditions = 5
+	// minRepeatCooldown is the least cooldown, in seconds, of a rule that
+	// runs more than once
+	minRepeatCooldown = 60
+	ruleSweepInterval = time.Minute
+	ruleRunHistory    = 20
+)
+
+var (
+	ErrRuleNotFound = errors.New("rule not found")
+	ErrTooManyRules = newCodedError("too_many_rules", maxCampaignRules)
+)
+
+// errInvalidRule says what is wrong with a rule
+func errInvalidRule(reason string) *CodedError {
+	return newCodedError("invalid_rule", reason)
+}
+
+// ruleMetrics are the metrics rule conditions can compare, see
+// model.RuleMetrics
+var ruleMetrics = map[string]func(m *model.RuleMetrics) float64{
+	"claims":           func(m *model.RuleMetrics) float64 { return float64(m.Claims) },
+	"amount":           func(m *model.RuleMetrics) float64 { return m.Amount },
+	"unique_claimers":  func(m *model.RuleMetrics) float64 { return float64(m.UniqueClaimers) },
+	"claims_last_hour": func(m *model.RuleMetrics) float64 { return float64(m.ClaimsLastHour) },
+	"budget_used":      func(m *model.RuleMetrics) float64 { return m.BudgetUsed },
+	"elapsed_hours":    func(m *model.RuleMetrics) float64 { return m.ElapsedHours },
+	"hour":             func(m *model.RuleMetrics) float64 { return float64(m.Hour) },
+}
+
+// RuleEngine automates campaigns with rules: conditions on a campaign's
+// metrics, checked after each claim or every minute, and an action run
+// when they all hold. A rule runs at most its maxRuns times, at most once
+// per cooldown, and a campaign's rules at most RULE_MAX_RUNS_PER_HOUR times
+// an hour, so a rule that keeps matching cannot run away. Rules can be
+// previewed against the campaign's current metrics without running them.
+type RuleEngine struct {
+	repo         *repository.CampaignRuleRepository
+	campaignRepo repository.CampaignStore
+	rpRepo       repository.RedPocketStore
+	pockets      *RedPocketService
+	scheduler    *PocketScheduler
+	webhooks     *WebhookService
+	cfg          *config.Config
+}
+
+func NewRuleEngine(
+	repo *repository.CampaignRuleRepository,
+	campaignRepo repository.CampaignStore,
+	rpRepo repository.RedPocketStore,
+	pockets *RedPocketService,
+	scheduler *PocketScheduler,
+	webhooks *WebhookService,
+	cfg *config.Config,
+) *RuleEngine {
+	return &RuleEngine{
+		repo:         repo,
+		campaignRepo: campaignRepo,
+		rpRepo:       rpRepo,
+		pockets:      pockets,
+		scheduler:    scheduler,
+		webhooks:     webhooks,
+		cfg:          cfg,
+	}
+}
+
+// UseRules runs the campaigns' claim rules after each claim
+func (s *RedPocketService) UseRules(rules *RuleEngine) {
+	s.rules = rules
+}
+
+type RuleRequest struct {
+	Name       string                 `json:"name" binding:"required,max=64"`
+	Event      string                 `json:"event" binding:"required,oneof=claim schedule"`
+	Conditions []RuleConditionRequest `json:"conditions" binding:"dive"`
+	Action     string                 `json:"action" binding:"required,oneof=create_pocket pause_campaign send_alert adjust_distribution"`
+	Params     json.RawMessage        `json:"params"`
+	Enabled    *bool                  `json:"enabled"`                           // default true
+	MaxRuns    *int                   `json:"maxRuns" binding:"omitempty,min=0"` // default 1, 0 for no limit
+	Cooldown   int                    `json:"cooldown" binding:"min=0"`          // seconds
+}
+
+type RuleConditionRequest struct {
+	Metric string  `json:"metric" binding:"required,oneof=claims amount unique_claimers claims_last_hour budget_used elapsed_hours hour"`
+	Op     string  `json:"op" binding:"required,oneof=gte gt lte lt eq"`
+	Value  float64 `json:"value"`
+}
+
+// BonusPocketRequest are the params of create_pocket. Without a platform,
+// a rule run on a claim drops the pocket in the channel of the claim.
+type BonusPocketRequest struct {
+	Amount      json.Number `json:"amount"` // decimal token amount
+	Token       string      `json:"token"`
+	TotalCount  int         `json:"totalCount"`
+	IsLuckyDraw bool        `json:"isLuckyDraw"`
+	SenderName  string      `json:"senderName"`
+	Message     string      `json:"message"`
+	ExpiresIn   int64       `json:"expiresIn"` // seconds, default 7 days
+	Platform    string      `json:"platform"`
+	ChannelID   string      `json:"platformChannelId"`
+}
+
+// PauseCampaignParams are the params of pause_campaign
+type PauseCampaignParams struct {
+	Reason string `json:"reason"` // recorded in the pockets' status history
+}
+
+// AlertParams are the params of send_alert
+type AlertParams struct {
+	Message string `json:"message"`
+}
+
+// RuleDetail is a rule with its latest runs
+type RuleDetail struct {
+	*model.CampaignRule
+	Runs []*model.RuleRun `json:"runs"`
+}
+
+// RulePreview is what a rule would do now, worked out without doing it
+type RulePreview struct {
+	Metrics    *model.RuleMetrics `json:"metrics"`
+	Conditions []ConditionResult  `json:"conditions"`
+	Matched    bool               `json:"matched"`            // every condition holds
+	WouldRun   bool               `json:"wouldRun"`           // matched and not held back
+	HeldBack   string             `json:"heldBack,omitempty"` // disabled, no_runs_left, cooldown or rate_limited
+	Action     *RuleActionPreview `json:"action"`
+}
+
+// ConditionResult is a condition with the metric's current value
+type ConditionResult struct {
+	model.RuleCondition
+	Actual float64 `json:"actual"`
+	Met    bool    `json:"met"`
+}
+
+// RuleActionPreview describes what a rule's action would change
+type RuleActionPreview struct {
+	Type     string              `json:"type"`
+	Pocket   *model.BonusPocket  `json:"pocket,omitempty"`   // create_pocket
+	Pockets  []string            `json:"pockets,omitempty"`  // pause_campaign: the open pockets it would pause
+	Webhooks *int                `json:"webhooks,omitempty"` // send_alert: subscribed to campaign.alert
+	Message  string              `json:"message,omitempty"`
+	Weights  []model.ClaimWeight `json:"weights,omitempty"` // adjust_distribution: replacing Current
+	Current  []model.ClaimWeight `json:"current,omitempty"`
+}
+
+// Create adds a rule to an enterprise's campaign
+func (s *RuleEngine) Create(ctx context.Context, campaignID, enterpriseID string, req *RuleRequest) (*model.CampaignRule, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	n, err := s.repo.Count(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= maxCampaignRules {
+		return nil, ErrTooManyRules
+	}
+	rule, err := s.build(ctx, campaignID, req)
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = "rule_" + uuid.New().String()[:8]
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Update replaces the definition of a rule of an enterprise's campaign. Its
+// runs so far still count towards its maxRuns.
+func (s *RuleEngine) Update(ctx context.Context, campaignID, ruleID, enterpriseID string, req *RuleRequest) (*model.CampaignRule, error) {
+	existing, err := s.rule(ctx, campaignID, ruleID, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.build(ctx, campaignID, req)
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = existing.ID
+	rule.Runs = existing.Runs
+	rule.LastRunAt = existing.LastRunAt
+	rule.CreatedAt = existing.CreatedAt
+	if err := s.repo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update rule: %w", err)
+	}
+	return rule, nil
+}
+
+// List returns the rules of an enterprise's campaign
+func (s *RuleEngine) List(ctx context.Context, campaignID, enterpriseID string) ([]*model.CampaignRule, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, campaignID)
+}
+
+// Get returns a rule of an enterprise's campaign with its latest runs
+func (s *RuleEngine) Get(ctx context.Context, campaignID, ruleID, enterpriseID string) (*RuleDetail, error) {
+	rule, err := s.rule(ctx, campaignID, ruleID, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.repo.Runs(ctx, rule.ID, ruleRunHistory)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleDetail{CampaignRule: rule, Runs: runs}, nil
+}
+
+// Delete removes a rule of an enterprise's campaign with its runs
+func (s *RuleEngine) Delete(ctx context.Context, campaignID, ruleID, enterpriseID string) error {
+	if _, err := s.rule(ctx, campaignID, ruleID, enterpriseID); err != nil {
+		return err
+	}
+	deleted, err := s.repo.Delete(ctx, campaignID, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	if !deleted {
+		return ErrRuleNotFound
+	}
+	return nil
+}
+
+func (s *RuleEngine) rule(ctx context.Context, campaignID, ruleID, enterpriseID string) (*model.CampaignRule, error) {
+	rules, err := s.List(ctx, campaignID, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.ID == ruleID {
+			return rule, nil
+		}
+	}
+	return nil, ErrRuleNotFound
+}
+
+func (s *RuleEngine) authorizeCampaign(ctx context.Context, campaignID, enterpriseID string) error {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
+
+// build validates a requested rule, normalizing its params
+func (s *RuleEngine) build(ctx context.Context, campaignID string, req *RuleRequest) (*model.CampaignRule, error) {
+	if len(req.Conditions) > maxRuleConditions {
+		return nil, errInvalidRule(fmt.Sprintf("at most %d conditions", maxRuleConditions))
+	}
+	maxRuns := 1
+	if req.MaxRuns != nil {
+		maxRuns = *req.MaxRuns
+	}
+	if maxRuns != 1 && req.Cooldown < minRepeatCooldown {
+		return nil, errInvalidRule(fmt.Sprintf("a rule running more than once needs a cooldown of at least %d seconds", minRepeatCooldown))
+	}
+
+	params, err := s.buildParams(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &model.CampaignRule{
+		CampaignID: campaignID,
+		Name:       strings.TrimSpace(req.Name),
+		Event:      req.Event,
+		Conditions: make([]model.RuleCondition, 0, len(req.Conditions)),
+		Action:     req.Action,
+		Params:     raw,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		MaxRuns:    maxRuns,
+		Cooldown:   req.Cooldown,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	for _, c := range req.Conditions {
+		rule.Conditions = append(rule.Conditions, model.RuleCondition{Metric: c.Metric, Op: c.Op, Value: c.Value})
+	}
+	return rule, nil
+}
+
+// buildParams validates the params of a rule's action, returning them in
+// the form they are stored in
+func (s *RuleEngine) buildParams(ctx context.Context, req *RuleRequest) (interface{}, error) {
+	raw := req.Params
+	if len(raw) == 0 {
+		raw = json.RawMessage("{}")
+	}
+	switch req.Action {
+	case RuleCreatePocket:
+		var p BonusPocketRequest
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, errInvalidRule("create_pocket params: " + err.Error())
+		}
+		if p.Token == "" || p.TotalCount <= 0 || p.ExpiresIn < 0 {
+			return nil, errInvalidRule("create_pocket needs an amount, a token and a totalCount over 0")
+		}
+		if len(p.SenderName) > 64 || len(p.Message) > 500 {
+			return nil, errInvalidRule("create_pocket senderName is at most 64 and message at most 500 characters")
+		}
+		if p.Platform == "" && (p.ChannelID != "" || req.Event != RuleOnClaim) {
+			return nil, errInvalidRule("create_pocket needs a platform and channel, unless run on claims")
+		}
+		token, err := s.pockets.tokens.Resolve(ctx, p.Token, s.pockets.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		units, ok := parseUnits(p.Amount.String(), token.Decimals)
+		if !ok || units.Cmp(big.NewInt(int64(p.TotalCount))) < 0 {
+			return nil, ErrInvalidAmount
+		}
+		return &model.BonusPocket{
+			Amount:      p.Amount.String(),
+			Token:       token.Symbol,
+			TotalCount:  p.TotalCount,
+			IsLuckyDraw: p.IsLuckyDraw,
+			SenderName:  p.SenderName,
+			Message:     p.Message,
+			ExpiresIn:   p.ExpiresIn,
+			Platform:    p.Platform,
+			ChannelID:   p.ChannelID,
+		}, nil
+	case RulePauseCampaign:
+		var p PauseCampaignParams
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, errInvalidRule("pause_campaign params: " + err.Error())
+		}
+		if len(p.Reason) > 200 {
+			return nil, errInvalidRule("pause_campaign reason is at most 200 characters")
+		}
+		return &p, nil
+	case RuleSendAlert:
+		var p AlertParams
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, errInvalidRule("send_alert params: " + err.Error())
+		}
+		if p.Message = strings.TrimSpace(p.Message); p.Message == "" || len(p.Message) > 500 {
+			return nil, errInvalidRule("send_alert needs a message of at most 500 characters")
+		}
+		return &p, nil
+	case RuleAdjustDistribution:
+		var p ClaimWeightsRequest
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, errInvalidRule("adjust_distribution params: " + err.Error())
+		}
+		weights, err := buildClaimWeights(p.Weights)
+		if err != nil {
+			return nil, err
+		}
+		return &model.CampaignClaimWeights{Weights: weights}, nil
+	}
+	return nil, errInvalidRule("unknown action " + req.Action)
+}
+
+// Preview works out what a rule, not yet saved, would do now
+func (s *RuleEngine) Preview(ctx context.Context, campaignID, enterpriseID string, req *RuleRequest) (*RulePreview, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	rule, err := s.build(ctx, campaignID, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(ctx, rule)
+}
+
+// DryRun works out what one of a campaign's rules would do now, without
+// running it
+func (s *RuleEngine) DryRun(ctx context.Context, campaignID, ruleID, enterpriseID string) (*RulePreview, error) {
+	rule, err := s.rule(ctx, campaignID, ruleID, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(ctx, rule)
+}
+
+func (s *RuleEngine) preview(ctx context.Context, rule *model.CampaignRule) (*RulePreview, error) {
+	metrics, err := s.repo.Metrics(ctx, rule.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+	p := &RulePreview{Metrics: metrics, Matched: true}
+	for _, c := range rule.Conditions {
+		result := ConditionResult{RuleCondition: c, Actual: ruleMetrics[c.Metric](metrics), Met: conditionHolds(c, metrics)}
+		p.Matched = p.Matched && result.Met
+		p.Conditions = append(p.Conditions, result)
+	}
+
+	runsLastHour, err := s.repo.RunsLastHour(ctx, rule.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !rule.Enabled:
+		p.HeldBack = "disabled"
+	case rule.MaxRuns > 0 && rule.Runs >= rule.MaxRuns:
+		p.HeldBack = "no_runs_left"
+	case rule.LastRunAt != nil && time.Since(*rule.LastRunAt) < time.Duration(rule.Cooldown)*time.Second:
+		p.HeldBack = "cooldown"
+	case runsLastHour >= s.cfg.RuleMaxRunsPerHour:
+		p.HeldBack = "rate_limited"
+	}
+	p.WouldRun = p.Matched && p.HeldBack == ""
+
+	p.Action, err = s.previewAction(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *RuleEngine) previewAction(ctx context.Context, rule *model.CampaignRule) (*RuleActionPreview, error) {
+	preview := &RuleActionPreview{Type: rule.Action}
+	switch rule.Action {
+	case RuleCreatePocket:
+		var pocket model.BonusPocket
+		if err := json.Unmarshal(rule.Params, &pocket); err != nil {
+			return nil, err
+		}
+		preview.Pocket = &pocket
+	case RulePauseCampaign:
+		pockets, err := s.openPockets(ctx, rule.CampaignID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rp := range pockets {
+			preview.Pockets = append(preview.Pockets, rp.ID)
+		}
+	case RuleSendAlert:
+		var p AlertParams
+		if err := json.Unmarshal(rule.Params, &p); err != nil {
+			return nil, err
+		}
+		ids, err := s.webhooks.repo.Subscribed(ctx, rule.CampaignID, WebhookCampaignAlert)
+		if err != nil {
+			return nil, err
+		}
+		n := len(ids)
+		preview.Message, preview.Webhooks = p.Message, &n
+	case RuleAdjustDistribution:
+		var p model.CampaignClaimWeights
+		if err := json.Unmarshal(rule.Params, &p); err != nil {
+			return nil, err
+		}
+		current, err := s.campaignRepo.GetClaimWeights(ctx, rule.CampaignID)
+		if err != nil {
+			return nil, err
+		}
+		preview.Weights, preview.Current = p.Weights, current.Weights
+	}
+	return preview, nil
+}
+
+// openPockets returns a campaign's pockets open for claims
+func (s *RuleEngine) openPockets(ctx context.Context, campaignID string) ([]*model.RedPocket, error) {
+	const page = 100
+	var open []*model.RedPocket
+	for offset := 0; ; offset += page {
+		pockets, err := s.rpRepo.ListByCampaign(ctx, campaignID, page, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, rp := range pockets {
+			if rp.Status == model.PocketActive {
+				open = append(open, rp)
+			}
+		}
+		if len(pockets) < page {
+			return open, nil
+		}
+	}
+}
+
+func conditionHolds(c model.RuleCondition, m *model.RuleMetrics) bool {
+	metric, ok := ruleMetrics[c.Metric]
+	if !ok {
+		return false
+	}
+	v := metric(m)
+	switch c.Op {
+	case "gte":
+		return v >= c.Value
+	case "gt":
+		return v > c.Value
+	case "lte":
+		return v <= c.Value
+	case "lt":
+		return v < c.Value
+	case "eq":
+		return v == c.Value
+	}
+	return false
+}
+
+// take returns the rules whose conditions all hold, each taken for running
+// so that no other evaluation runs it too
+func (s *RuleEngine) take(ctx context.Context, rules []*model.CampaignRule, m *model.RuleMetrics) ([]*model.CampaignRule, error) {
+	var taken []*model.CampaignRule
+	for _, rule := range rules {
+		matched := true
+		for _, c := range rule.Conditions {
+			matched = matched && conditionHolds(c, m)
+		}
+		if !matched {
+			continue
+		}
+		ok, err := s.repo.Begin(ctx, rule.ID, s.cfg.RuleMaxRunsPerHour)
+		if err != nil {
+			return taken, err
+		}
+		if ok {
+			taken = append(taken, rule)
+		}
+	}
+	return taken, nil
+}
+
+// OnClaim runs the claim rules of the pocket's campaign that now match.
+// Their actions run in the background, so the claim does not wait on them.
+func (s *RuleEngine) OnClaim(ctx context.Context, rp *model.RedPocket) error {
+	rules, err := s.repo.Active(ctx, rp.CampaignID, RuleOnClaim)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	metrics, err := s.repo.Metrics(ctx, rp.CampaignID)
+	if err != nil {
+		return err
+	}
+	taken, err := s.take(ctx, rules, metrics)
+	for _, rule := range taken {
+		go s.run(context.WithoutCancel(ctx), rule, metrics, rp)
+	}
+	return err
+}
+
+// Start runs schedule rules until ctx is cancelled
+func (s *RuleEngine) Start(ctx context.Context) {
+	ticker := time.NewTicker(ruleSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *RuleEngine) sweep(ctx context.Context) {
+	campaigns, err := s.repo.ScheduledCampaigns(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "rules: failed to list campaigns with schedule rules", "error", err)
+		return
+	}
+	for _, campaignID := range campaigns {
+		rules, err := s.repo.Active(ctx, campaignID, RuleOnSchedule)
+		if err != nil {
+			slog.ErrorContext(ctx, "rules: failed to list schedule rules", "campaign_id", campaignID, "error", err)
+			continue
+		}
+		metrics, err := s.repo.Metrics(ctx, campaignID)
+		if err != nil {
+			slog.ErrorContext(ctx, "rules: failed to get metrics", "campaign_id", campaignID, "error", err)
+			continue
+		}
+		taken, err := s.take(ctx, rules, metrics)
+		if err != nil {
+			slog.ErrorContext(ctx, "rules: failed to take rules", "campaign_id", campaignID, "error", err)
+		}
+		for _, rule := range taken {
+			s.run(ctx, rule, metrics, nil)
+		}
+	}
+}
+
+// run carries out a taken rule's action and records the run. from is the
+// pocket claimed from, for rules run on claims.
+func (s *RuleEngine) run(ctx context.Context, rule *model.CampaignRule, metrics *model.RuleMetrics, from *model.RedPocket) {
+	run := &model.RuleRun{
+		ID:         "run_" + uuid.New().String()[:8],
+		RuleID:     rule.ID,
+		CampaignID: rule.CampaignID,
+		Status:     "succeeded",
+		Metrics:    *metrics,
+	}
+	result, err := s.execute(ctx, rule, metrics, from)
+	run.Result = result
+	if err != nil {
+		run.Status, run.Error = "failed", err.Error()
+		slog.ErrorContext(ctx, "rule action failed", "rule_id", rule.ID, "campaign_id", rule.CampaignID, "action", rule.Action, "error", err)
+	} else {
+		slog.InfoContext(ctx, "rule ran", "rule_id", rule.ID, "campaign_id", rule.CampaignID, "action", rule.Action, "result", result)
+	}
+	run.CreatedAt = time.Now()
+	if err := s.repo.Finish(ctx, run); err != nil {
+		slog.ErrorContext(ctx, "failed to record rule run", "rule_id", rule.ID, "error", err)
+		if err := s.repo.Release(ctx, rule.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to release rule", "rule_id", rule.ID, "error", err)
+		}
+	}
+}
+
+// execute carries out a rule's action, returning what it did
+func (s *RuleEngine) execute(ctx context.Context, rule *model.CampaignRule, metrics *model.RuleMetrics, from *model.RedPocket) (string, error) {
+	switch rule.Action {
+	case RuleCreatePocket:
+		var p model.BonusPocket
+		if err := json.Unmarshal(rule.Params, &p); err != nil {
+			return "", err
+		}
+		return s.createPocket(ctx, rule, &p, from)
+	case RulePauseCampaign:
+		var p PauseCampaignParams
+		if err := json.Unmarshal(rule.Params, &p); err != nil {
+			return "", err
+		}
+		return s.pauseCampaign(ctx, rule, p.Reason)
+	case RuleSendAlert:
+		var p AlertParams
+		if err := json.Unmarshal(rule.Params, &p); err != nil {
+			return "", err
+		}
+		s.webhooks.Dispatch(ctx, rule.CampaignID, WebhookCampaignAlert, map[string]interface{}{
+			"campaignId": rule.CampaignID,
+			"rule":       map[string]string{"id": rule.ID, "name": rule.Name},
+			"message":    p.Message,
+			"metrics":    metrics,
+		})
+		return "alert sent", nil
+	case RuleAdjustDistribution:
+		var weights model.CampaignClaimWeights
+		if err := json.Unmarshal(rule.Params, &weights); err != nil {
+			return "", err
+		}
+		now := time.Now()
+		weights.UpdatedAt = &now
+		err := s.campaignRepo.SetClaimWeights(ctx, rule.CampaignID, &weights)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrCampaignNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d claim weights set", len(weights.Weights)), nil
+	}
+	return "", fmt.Errorf("unknown action %s", rule.Action)
+}
+
+// createPocket creates a bonus pocket through the standard creation path,
+// out of the campaign's budget, and announces it in its channel
+func (s *RuleEngine) createPocket(ctx context.Context, rule *model.CampaignRule, p *model.BonusPocket, from *model.RedPocket) (string, error) {
+	req := &CreateRedPocketRequest{
+		CampaignID:  rule.CampaignID,
+		SenderName:  p.SenderName,
+		Amount:      json.Number(p.Amount),
+		Token:       p.Token,
+		Platform:    p.Platform,
+		ChannelID:   p.ChannelID,
+		Message:     p.Message,
+		TotalCount:  p.TotalCount,
+		IsLuckyDraw: p.IsLuckyDraw,
+		ExpiresIn:   p.ExpiresIn,
+	}
+	if from != nil {
+		req.Locale = from.Locale
+		if req.Platform == "" {
+			req.Platform, req.ChannelID = from.Platform, from.ChannelID
+		}
+		if req.SenderName == "" {
+			req.SenderName = from.SenderName
+		}
+	}
+
+	bonus, err := s.pockets.create(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	// A pocket created awaiting its deposit is not open to announce yet
+	if bonus.Status == model.PocketActive {
+		if err := s.scheduler.Announce(ctx, bonus); err != nil {
+			slog.ErrorContext(ctx, "failed to announce bonus pocket", "red_pocket_id", bonus.ID, "error", err)
+		}
+	}
+	return bonus.ID, nil
+}
+
+// pauseCampaign pauses every open pocket of the campaign, as the enterprise
+// could one by one
+func (s *RuleEngine) pauseCampaign(ctx context.Context, rule *model.CampaignRule, reason string) (string, error) {
+	pockets, err := s.openPockets(ctx, rule.CampaignID)
+	if err != nil {
+		return "", err
+	}
+	paused := 0
+	for _, rp := range pockets {
+		ok, err := s.rpRepo.Transition(ctx, rp.ID, model.PocketPaused, statusReason("paused by rule "+rule.Name, reason))
+		if err != nil {
+			return fmt.Sprintf("%d pockets paused", paused), fmt.Errorf("failed to pause red pocket %s: %w", rp.ID, err)
+		}
+		if !ok {
+			// Claimed out or expired since it was listed
+			continue
+		}
+		paused++
+		if _, err := s.pockets.publishStatus(ctx, rp.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to publish pocket status", "red_pocket_id", rp.ID, "error", err)
+		}
+	}
+	return fmt.Sprintf("%d pockets paused", paused), nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+var (
+	ErrCaptchaRequired    = newCodedError("captcha_required")
+	ErrCaptchaFailed      = newCodedError("captcha_failed")
+	ErrCaptchaUnavailable = newCodedError("captcha_unavailable")
+)
+
+// CAPTCHA modes of protected red pockets
+const (
+	CaptchaHCaptcha  = "hcaptcha"
+	CaptchaTurnstile = "turnstile"
+)
+
+const (
+	hcaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// CaptchaVerifier checks CAPTCHA tokens with hCaptcha or Cloudflare Turnstile
+type CaptchaVerifier struct {
+	httpClient *http.Client
+	cfg        *config.Config
+}
+
+func NewCaptchaVerifier(cfg *config.Config) *CaptchaVerifier {
+	return &CaptchaVerifier{
+		httpClient: NewDownstream("captcha", cfg.DownstreamClaim).HTTPClient(),
+		cfg:        cfg,
+	}
+}
+
+// Available reports whether mode is configured and can be required on a red pocket
+func (v *CaptchaVerifier) Available(mode string) bool {
+	_, secret := v.provider(mode)
+	return secret != ""
+}
+
+// SiteKey is the public key the claim page renders the widget with
+func (v *CaptchaVerifier) SiteKey(mode string) string {
+	switch mode {
+	case CaptchaHCaptcha:
+		return v.cfg.HCaptchaSiteKey
+	case CaptchaTurnstile:
+		return v.cfg.TurnstileSiteKey
+	}
+	return ""
+}
+
+func (v *CaptchaVerifier) provider(mode string) (verifyURL, secret string) {
+	switch mode {
+	case CaptchaHCaptcha:
+		return hcaptchaVerifyURL, v.cfg.HCaptchaSecret
+	case CaptchaTurnstile:
+		return turnstileVerifyURL, v.cfg.TurnstileSecret
+	}
+	return "", ""
+}
+
+// Verify checks a token solved by the client at remoteIP. Both providers
+// share the siteverify form API.
+func (v *CaptchaVerifier) Verify(ctx context.Context, mode, token, remoteIP string) error {
+	if token == "" {
+		return ErrCaptchaRequired
+	}
+	verifyURL, secret := v.provider(mode)
+	if secret == "" {
+		return ErrCaptchaUnavailable
+	}
+
+	form := url.Values{"secret": {secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha verification returned %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid captcha verification response: %w", err)
+	}
+	if !result.Success {
+		return ErrCaptchaFailed
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+const (
+	// rpcScoreWeight is how much the latest call moves a provider's health
+	// score, an exponential moving average of its outcomes from 0 to 1
+	rpcScoreWeight = 0.3
+	// rpcHealthyScore is the score under which a provider is only tried
+	// after the healthy ones
+	rpcHealthyScore = 0.5
+	// rpcRecheckAfter lets an unhealthy provider back into the rotation for
+	// a trial call once it has not been tried for this long
+	rpcRecheckAfter = 30 * time.Second
+)
+
+// rpcProvider is one of a chain's JSON-RPC endpoints. Each has its own
+// downstream client, so its circuit breaker trips on its failures alone.
+type rpcProvider struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	score     float64
+	failures  int // in a row
+	latency   time.Duration
+	lastErr   string
+	lastTried time.Time
+}
+
+// chainRPC spreads a chain's calls over its providers round-robin, trying
+// the others in turn when one fails
+type chainRPC struct {
+	providers []*rpcProvider
+	next      atomic.Uint64
+}
+
+func newChainRPC(urls []string, policy config.DownstreamPolicy) *chainRPC {
+	if len(urls) > 1 {
+		// Failing over to the next provider takes the place of retrying
+		policy.Retries = 0
+	}
+	c := &chainRPC{}
+	for _, u := range urls {
+		c.providers = append(c.providers, &rpcProvider{
+			url:    u,
+			client: NewDownstream("xcm "+providerHost(u), policy).HTTPClient(),
+			score:  1,
+		})
+	}
+	return c
+}
+
+// order returns the providers to try for a call: the healthy ones, and
+// those due a recheck, starting from the next in the rotation, then the
+// unhealthy ones best first
+func (c *chainRPC) order() []*rpcProvider {
+	n := len(c.providers)
+	start := int(c.next.Add(1) % uint64(n))
+	now := time.Now()
+
+	var ready, unhealthy []*rpcProvider
+	for i := 0; i < n; i++ {
+		p := c.providers[(start+i)%n]
+		p.mu.Lock()
+		ok := p.score >= rpcHealthyScore || now.Sub(p.lastTried) >= rpcRecheckAfter
+		p.mu.Unlock()
+		if ok {
+			ready = append(ready, p)
+		} else {
+			unhealthy = append(unhealthy, p)
+		}
+	}
+	sort.SliceStable(unhealthy, func(i, j int) bool { return unhealthy[i].health() > unhealthy[j].health() })
+	return append(ready, unhealthy...)
+}
+
+// best returns the provider a call would go to first
+func (c *chainRPC) best() *rpcProvider {
+	best := c.providers[0]
+	for _, p := range c.providers[1:] {
+		if p.health() > best.health() {
+			best = p
+		}
+	}
+	return best
+}
+
+func (p *rpcProvider) health() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.score
+}
+
+// record adds a call's outcome to the provider's health score
+func (p *rpcProvider) record(err error, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastTried = time.Now()
+	outcome := 0.0
+	if err == nil {
+		outcome = 1
+		p.failures = 0
+		p.latency = latency
+	} else {
+		p.failures++
+		// Errors quote the URL, which may carry an API key
+		p.lastErr = strings.ReplaceAll(err.Error(), p.url, providerHost(p.url))
+	}
+	p.score = (1-rpcScoreWeight)*p.score + rpcScoreWeight*outcome
+}
+
+// RPCProviderStatus is the health of one of a chain's RPC providers
+type RPCProviderStatus struct {
+	Provider  string  `json:"provider"` // host only, as URLs may carry API keys
+	Score     float64 `json:"score"`
+	Healthy   bool    `json:"healthy"`
+	Failures  int     `json:"consecutiveFailures"`
+	LatencyMs int64   `json:"latencyMs,omitempty"` // of the last successful call
+	LastError string  `json:"lastError,omitempty"`
+}
+
+func (p *rpcProvider) status() RPCProviderStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return RPCProviderStatus{
+		Provider:  providerHost(p.url),
+		Score:     p.score,
+		Healthy:   p.score >= rpcHealthyScore,
+		Failures:  p.failures,
+		LatencyMs: p.latency.Milliseconds(),
+		LastError: p.lastErr,
+	}
+}
+
+func providerHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
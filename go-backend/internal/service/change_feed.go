@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// ChangeFeedChannel is the Postgres channel the red_pockets and claims
+// triggers notify on (migration 032)
+const ChangeFeedChannel = "redpocket_changes"
+
+const (
+	changeFeedMinBackoff = time.Second
+	changeFeedMaxBackoff = time.Minute
+)
+
+// ChangeFeed listens for pocket and claim changes committed to Postgres and
+// hands them to in-process consumers such as live streams and caches. Every
+// replica runs its own listener, so consumers only need to care about their
+// local state.
+type ChangeFeed struct {
+	db *repository.PostgresDB
+
+	mu        sync.RWMutex
+	consumers []func(ctx context.Context, change *model.Change)
+}
+
+func NewChangeFeed(db *repository.PostgresDB) *ChangeFeed {
+	return &ChangeFeed{db: db}
+}
+
+// OnChange registers a consumer. Consumers run one at a time in commit
+// order and should return quickly.
+func (f *ChangeFeed) OnChange(fn func(ctx context.Context, change *model.Change)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.consumers = append(f.consumers, fn)
+}
+
+// Start listens until ctx is cancelled, reconnecting with backoff when the
+// connection drops. Changes committed while disconnected are not replayed.
+func (f *ChangeFeed) Start(ctx context.Context) {
+	backoff := changeFeedMinBackoff
+	for {
+		started := time.Now()
+		err := f.db.Listen(ctx, ChangeFeedChannel, func(payload string) {
+			f.dispatch(ctx, payload)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > changeFeedMaxBackoff {
+			backoff = changeFeedMinBackoff
+		}
+		slog.WarnContext(ctx, "change feed: listener stopped, retrying", "backoff", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, changeFeedMaxBackoff)
+	}
+}
+
+func (f *ChangeFeed) dispatch(ctx context.Context, payload string) {
+	change := &model.Change{}
+	if err := json.Unmarshal([]byte(payload), change); err != nil {
+		slog.ErrorContext(ctx, "change feed: bad payload", "payload", payload, "error", err)
+		return
+	}
+
+	f.mu.RLock()
+	consumers := f.consumers
+	f.mu.RUnlock()
+	for _, fn := range consumers {
+		fn(ctx, change)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// maxChannelLimits caps the channels one red pocket can be limited in
+const maxChannelLimits = 50
+
+var (
+	ErrChannelNotListed    = newCodedError("claim_channel_not_listed")
+	ErrChannelLimitReached = newCodedError("channel_limit_reached")
+)
+
+// errInvalidChannelLimit says what is wrong with a channel limit
+func errInvalidChannelLimit(reason string) *CodedError {
+	return newCodedError("invalid_channel_limit", reason)
+}
+
+// ChannelLimitRequest caps the claims from one channel a red pocket is
+// announced in. ChannelID is as claims carry it: a Telegram chat ID, a
+// Discord channel ID, or TEAM_ID:CHANNEL_ID on Slack.
+type ChannelLimitRequest struct {
+	ChannelID string      `json:"channelId" binding:"required,max=128"`
+	MaxClaims int         `json:"maxClaims" binding:"min=0"`
+	Budget    json.Number `json:"budget"` // decimal token amount; empty is no sub-budget
+}
+
+// buildChannelLimits validates requested channel limits against the red
+// pocket they are for
+func buildChannelLimits(reqs []ChannelLimitRequest, rp *model.RedPocket) ([]model.ChannelLimit, error) {
+	if len(reqs) > maxChannelLimits {
+		return nil, errInvalidChannelLimit(fmt.Sprintf("at most %d channels", maxChannelLimits))
+	}
+
+	limits := make([]model.ChannelLimit, 0, len(reqs))
+	seen := map[string]bool{}
+	for _, req := range reqs {
+		channelID := strings.TrimSpace(req.ChannelID)
+		if channelID == "" || seen[channelID] {
+			return nil, errInvalidChannelLimit(fmt.Sprintf("channel %q is empty or listed twice", req.ChannelID))
+		}
+		seen[channelID] = true
+
+		if req.MaxClaims > rp.TotalCount {
+			return nil, errInvalidChannelLimit(fmt.Sprintf("channel %s: at most %d claims", channelID, rp.TotalCount))
+		}
+		budget := new(big.Int)
+		if req.Budget != "" {
+			var ok bool
+			if budget, ok = parseUnits(req.Budget.String(), rp.Decimals); !ok || budget.Sign() <= 0 {
+				return nil, errInvalidChannelLimit(fmt.Sprintf("channel %s: budget must be a positive token amount", channelID))
+			}
+			if budget.Cmp(rp.AmountUnits.Int()) > 0 {
+				return nil, errInvalidChannelLimit(fmt.Sprintf("channel %s: budget exceeds the red pocket's amount", channelID))
+			}
+		}
+		if req.MaxClaims == 0 && budget.Sign() == 0 {
+			return nil, errInvalidChannelLimit(fmt.Sprintf("channel %s: needs a claim cap or a budget", channelID))
+		}
+
+		units := model.NewUnits(budget)
+		limits = append(limits, model.ChannelLimit{
+			ChannelID:   channelID,
+			MaxClaims:   req.MaxClaims,
+			Budget:      units.Float(rp.Decimals),
+			BudgetUnits: units,
+		})
+	}
+	return limits, nil
+}
+
+// channelLimitFor returns the limit a claim from the channel counts against,
+// nil when the pocket has no channel limits
+func channelLimitFor(rp *model.RedPocket, channelID string) (*model.ChannelLimit, error) {
+	if len(rp.ChannelLimits) == 0 {
+		return nil, nil
+	}
+	for i := range rp.ChannelLimits {
+		if rp.ChannelLimits[i].ChannelID == channelID {
+			return &rp.ChannelLimits[i], nil
+		}
+	}
+	return nil, ErrChannelNotListed
+}
+
+// channelShare caps a claim to what is left of its channel's budget, and
+// refuses it when the channel has used up its claims or budget
+func (s *RedPocketService) channelShare(ctx context.Context, rp *model.RedPocket, limit *model.ChannelLimit, claimUnits *big.Int) (*big.Int, error) {
+	count, used, err := s.rpRepo.ChannelUsage(ctx, rp.ID, limit.ChannelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load channel usage: %w", err)
+	}
+	if limit.MaxClaims > 0 && count >= limit.MaxClaims {
+		return nil, ErrChannelLimitReached
+	}
+	if limit.BudgetUnits.Sign() == 0 {
+		return claimUnits, nil
+	}
+	left := new(big.Int).Sub(limit.BudgetUnits.Int(), used.Int())
+	if left.Sign() <= 0 {
+		return nil, ErrChannelLimitReached
+	}
+	if claimUnits.Cmp(left) > 0 {
+		return left, nil
+	}
+	return claimUnits, nil
+}
+
+// Channels returns each limited channel of a red pocket with what its
+// claims have taken, nil for pockets without channel limits
+func (s *RedPocketService) Channels(ctx context.Context, rp *model.RedPocket) []*model.ChannelUsage {
+	if len(rp.ChannelLimits) == 0 {
+		return nil
+	}
+	usage, err := s.rpRepo.ListChannelUsage(ctx, rp.ID)
+	if err != nil {
+		return nil
+	}
+	channels := make([]*model.ChannelUsage, 0, len(rp.ChannelLimits))
+	for _, limit := range rp.ChannelLimits {
+		u := usage[limit.ChannelID]
+		if u == nil {
+			u = &model.ChannelUsage{ClaimedUnits: model.NewUnits(new(big.Int))}
+		}
+		u.ChannelLimit = limit
+		u.Claimed = u.ClaimedUnits.Float(rp.Decimals)
+		channels = append(channels, u)
+	}
+	return channels
+}
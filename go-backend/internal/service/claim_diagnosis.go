@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// What a claimer can do about a claim
+const (
+	ClaimActionNone           = "none"
+	ClaimActionWait           = "wait"
+	ClaimActionRetry          = "retry"
+	ClaimActionContactCreator = "contact_creator"
+)
+
+// Refusals the claimer can fix and retry, or that clear with time. Other
+// refusals are final, or up to the pocket's creator (refusalCreatorCodes).
+var (
+	refusalRetryCodes = map[string]bool{
+		"captcha_required":        true,
+		"captcha_failed":          true,
+		"claim_password_required": true,
+		"invalid_claim_password":  true,
+		"terms_not_accepted":      true,
+		"holder_proof_required":   true,
+		"audience_proof_required": true,
+		"audience_bad_signature":  true,
+		"claim_link_required":     true,
+	}
+	refusalWaitCodes = map[string]bool{
+		"red_pocket_not_started":     true,
+		"red_pocket_paused":          true,
+		"claim_window_closed":        true,
+		"too_many_password_attempts": true,
+		"captcha_unavailable":        true,
+		"service_degraded":           true,
+		"downstream_unavailable":     true,
+	}
+	refusalCreatorCodes = map[string]bool{
+		"red_pocket_inactive":      true,
+		"not_on_allowlist":         true,
+		"not_in_audience":          true,
+		"region_not_eligible":      true,
+		"account_too_new":          true,
+		"token_gate_not_met":       true,
+		"claim_channel_not_listed": true,
+		"channel_limit_reached":    true,
+		"claim_blocked":            true,
+
+		// A new claim link has to come from the creator
+		"invalid_claim_link":         true,
+		"claim_link_expired":         true,
+		"claim_link_wrong_recipient": true,
+		"claim_link_used":            true,
+	}
+)
+
+// transferErrorClasses classify a payout's last error by what it mentions,
+// first match wins
+var transferErrorClasses = []struct {
+	class   string
+	needles []string
+}{
+	{"funds", []string{"insufficient", "exceeds balance", "transfer amount exceeds"}},
+	{"sponsorship", []string{"paymaster", "sponsor"}},
+	{"interrupted", []string{"interrupted"}},
+	{"network", []string{"timeout", "deadline exceeded", "connection", "unavailable", "rate limit", "429", "502", "503", "504"}},
+}
+
+// ClaimDiagnostics explains to a claimer where their claim is stuck: a
+// recorded claim from its status and payout job, or a claim that was never
+// recorded from the support reference it failed with
+type ClaimDiagnostics struct {
+	payouts  *PayoutQueue
+	receipts *ReceiptTracker
+	failures *ClaimFailureLog
+}
+
+func NewClaimDiagnostics(payouts *PayoutQueue, receipts *ReceiptTracker, failures *ClaimFailureLog) *ClaimDiagnostics {
+	return &ClaimDiagnostics{payouts: payouts, receipts: receipts, failures: failures}
+}
+
+// Diagnose takes a claim ID or the support reference of a failed claim
+func (d *ClaimDiagnostics) Diagnose(ctx context.Context, id string) (*model.ClaimDiagnosis, error) {
+	if !strings.HasPrefix(id, "claim_") {
+		return d.diagnoseFailure(ctx, id)
+	}
+
+	status, err := d.payouts.Status(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	diag := diagnosePayout(status, d.receipts.Enabled())
+	diag.Message = i18n.Tc(ctx, "diagnose."+diag.Reason)
+	diag.ActionHint = i18n.Tc(ctx, "diagnose.action."+diag.Action)
+	return diag, nil
+}
+
+// diagnoseFailure explains a claim refused or failed before it was recorded
+func (d *ClaimDiagnostics) diagnoseFailure(ctx context.Context, reference string) (*model.ClaimDiagnosis, error) {
+	f, err := d.failures.Lookup(ctx, reference)
+	if errors.Is(err, ErrClaimFailureNotFound) {
+		return nil, ErrClaimNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	diag := &model.ClaimDiagnosis{
+		Reference: f.Reference,
+		Status:    "refused",
+		Step:      "eligibility",
+		Reason:    f.ErrorCode,
+		Action:    ClaimActionNone,
+		Message:   f.Error, // as the claimer was shown it
+	}
+	switch {
+	case f.ErrorCode == "":
+		diag.Status = "error"
+		diag.Step = "claim"
+		diag.Reason = "internal_error"
+		diag.Action = ClaimActionRetry
+		diag.Message = i18n.Tc(ctx, "diagnose.internal_error")
+	case f.ErrorCode == ErrorCode(ErrClaimLockFailed), f.ErrorCode == "claim_in_progress":
+		diag.Step = "lock"
+		diag.Action = ClaimActionRetry
+	case refusalRetryCodes[f.ErrorCode]:
+		diag.Action = ClaimActionRetry
+	case refusalWaitCodes[f.ErrorCode]:
+		diag.Action = ClaimActionWait
+	case refusalCreatorCodes[f.ErrorCode]:
+		diag.Action = ClaimActionContactCreator
+	}
+	diag.ActionHint = i18n.Tc(ctx, "diagnose.action."+diag.Action)
+	return diag, nil
+}
+
+// diagnosePayout maps a recorded claim's state to the step it is at. With
+// receipts tracked a paid claim waits for confirmations; without, paid is
+// final.
+func diagnosePayout(status *model.PayoutStatus, tracked bool) *model.ClaimDiagnosis {
+	diag := &model.ClaimDiagnosis{
+		ClaimID: status.ClaimID,
+		Status:  string(status.Status),
+		Step:    "transfer",
+		Action:  ClaimActionWait,
+		TxHash:  status.TxHash,
+	}
+
+	switch status.Status {
+	case model.ClaimPending:
+		diag.Reason = "queued"
+	case model.ClaimRetrying:
+		diag.Reason = "retrying"
+		diag.ErrorClass = classifyTransferError(status.LastError)
+		diag.NextRetryAt = status.NextRetryAt
+		if diag.ErrorClass == "funds" {
+			// Retries only help once the creator tops the pocket up
+			diag.Action = ClaimActionContactCreator
+		}
+	case model.ClaimHeld:
+		diag.Reason = "held_for_review"
+	case model.ClaimBlocked:
+		diag.Reason = "blocked"
+		diag.Action = ClaimActionContactCreator
+	case model.ClaimRefunded:
+		// The amount went back to the creator with the pocket's refund
+		diag.Reason = "refunded"
+		diag.Action = ClaimActionContactCreator
+	case model.ClaimFailed:
+		diag.Action = ClaimActionContactCreator
+		if status.TxHash != "" {
+			// Sent, and the user operation failed on chain
+			diag.Step = "receipt"
+			diag.Reason = "reverted"
+			break
+		}
+		diag.Reason = "payout_failed"
+		diag.ErrorClass = classifyTransferError(status.LastError)
+	case model.ClaimSubmitted:
+		diag.Step = "receipt"
+		diag.Reason = "awaiting_confirmations"
+		if !tracked {
+			diag.Reason = "confirmed"
+			diag.Action = ClaimActionNone
+		}
+	case model.ClaimResubmitted:
+		diag.Step = "receipt"
+		diag.Reason = "resubmitted"
+	case model.ClaimConfirmed:
+		diag.Step = "receipt"
+		diag.Reason = "confirmed"
+		diag.Action = ClaimActionNone
+	case model.ClaimReverted, model.ClaimReorged:
+		// Escalated once resubmitting was not possible
+		diag.Step = "receipt"
+		diag.Reason = string(status.Status)
+		diag.Action = ClaimActionContactCreator
+	default:
+		diag.Reason = "processing"
+	}
+	return diag
+}
+
+func classifyTransferError(msg string) string {
+	if msg == "" {
+		return ""
+	}
+	msg = strings.ToLower(msg)
+	for _, c := range transferErrorClasses {
+		for _, needle := range c.needles {
+			if strings.Contains(msg, needle) {
+				return c.class
+			}
+		}
+	}
+	return "other"
+}
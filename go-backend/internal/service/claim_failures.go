@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/logging"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const (
+	// Failures waiting to be written; beyond this they are only logged
+	claimFailureBuffer = 1024
+	// How often failures past their retention are purged
+	claimFailurePurgeInterval = time.Hour
+)
+
+// Support references are Crockford base32, which has no I, L, O or U, so
+// they survive being read out over the phone
+const referenceAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ErrClaimFailureNotFound = newCodedError("claim_failure_not_found")
+
+// ClaimError is an internal error of a claim, carrying the support reference
+// it was recorded under
+type ClaimError struct {
+	Reference string
+	Err       error
+}
+
+func (e *ClaimError) Error() string { return e.Err.Error() }
+func (e *ClaimError) Unwrap() error { return e.Err }
+
+// SupportReference lets packages that cannot import this one, like the chat
+// bots, find the reference
+func (e *ClaimError) SupportReference() string { return e.Reference }
+
+// SupportReference returns the support reference of a failed claim's error,
+// or ""
+func SupportReference(err error) string {
+	var claimErr *ClaimError
+	if errors.As(err, &claimErr) {
+		return claimErr.Reference
+	}
+	return ""
+}
+
+// ClaimFailureLog gives every failed claim a short support reference the
+// claimer can quote, and records the attempt under it so support can look
+// it up without asking for timestamps and IDs. Failures are logged at once
+// and written to the database in the background, so a burst of refused
+// claims does not slow the claims that succeed.
+type ClaimFailureLog struct {
+	repo      *repository.ClaimFailureRepository
+	retention time.Duration
+	pending   chan *model.ClaimFailure
+}
+
+func NewClaimFailureLog(repo *repository.ClaimFailureRepository, cfg *config.Config) *ClaimFailureLog {
+	return &ClaimFailureLog{
+		repo:      repo,
+		retention: time.Duration(cfg.ClaimFailureRetentionDays) * 24 * time.Hour,
+		pending:   make(chan *model.ClaimFailure, claimFailureBuffer),
+	}
+}
+
+// Record logs a failed claim and returns its support reference. Either resp
+// is a refused claim or err an internal error.
+func (l *ClaimFailureLog) Record(ctx context.Context, req *ClaimRequest, resp *ClaimResponse, err error) string {
+	f := &model.ClaimFailure{
+		Reference:         newSupportReference(),
+		RedPocketID:       req.RedPocketID,
+		Platform:          req.Platform,
+		PlatformID:        req.PlatformID,
+		WalletAddress:     req.WalletAddress,
+		ClientIP:          req.ClientIP,
+		Country:           req.Country,
+		DeviceFingerprint: req.DeviceFingerprint,
+		Locale:            i18n.FromContext(ctx),
+		RequestID:         logging.RequestID(ctx),
+		CreatedAt:         time.Now(),
+	}
+	if err != nil {
+		f.Error = err.Error()
+	} else {
+		f.ErrorCode = resp.ErrorCode
+		f.Error = resp.Error
+	}
+
+	slog.WarnContext(ctx, "claim failure",
+		"reference", f.Reference,
+		"red_pocket_id", f.RedPocketID,
+		"platform", f.Platform,
+		"platform_id", f.PlatformID,
+		"wallet", f.WalletAddress,
+		"client_ip", f.ClientIP,
+		"country", f.Country,
+		"device", f.DeviceFingerprint,
+		"locale", f.Locale,
+		"code", f.ErrorCode,
+		"error", f.Error,
+	)
+
+	select {
+	case l.pending <- f:
+	default:
+		slog.WarnContext(ctx, "claim failure not stored, the write queue is full", "reference", f.Reference)
+	}
+	return f.Reference
+}
+
+// Start writes recorded failures and purges those past their retention
+// until ctx is cancelled
+func (l *ClaimFailureLog) Start(ctx context.Context) {
+	purge := time.NewTicker(claimFailurePurgeInterval)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case f := <-l.pending:
+			if err := l.repo.Create(ctx, f); err != nil {
+				slog.ErrorContext(ctx, "claim failures: failed to store", "reference", f.Reference, "error", err)
+			}
+		case <-purge.C:
+			if l.retention <= 0 {
+				continue
+			}
+			n, err := l.repo.DeleteBefore(ctx, time.Now().Add(-l.retention))
+			if err != nil {
+				slog.ErrorContext(ctx, "claim failures: failed to purge", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "claim failures: purged past retention", "failures", n)
+			}
+		}
+	}
+}
+
+// Lookup returns the failure recorded under a support reference, as read
+// back by the claimer
+func (l *ClaimFailureLog) Lookup(ctx context.Context, reference string) (*model.ClaimFailure, error) {
+	f, err := l.repo.GetByReference(ctx, normalizeReference(reference))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrClaimFailureNotFound
+	}
+	return f, err
+}
+
+func newSupportReference() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	ref := make([]byte, len(b))
+	for i, v := range b {
+		// 256 is a multiple of 32, so every character is equally likely
+		ref[i] = referenceAlphabet[int(v)%len(referenceAlphabet)]
+	}
+	return "CF-" + string(ref)
+}
+
+// normalizeReference undoes what reading a reference aloud or retyping it
+// tends to change: case, spacing and the letters Crockford base32 leaves out
+func normalizeReference(reference string) string {
+	ref := strings.ToUpper(strings.Join(strings.Fields(reference), ""))
+	ref = strings.TrimPrefix(strings.TrimPrefix(ref, "CF-"), "CF")
+	ref = strings.NewReplacer("O", "0", "I", "1", "L", "1", "-", "").Replace(ref)
+	return "CF-" + ref
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// maxLeaderboardSize is how many claims a leaderboard ranks; requests for
+// fewer are cut from the same cached board
+const maxLeaderboardSize = 50
+
+// ClaimHistoryService serves what claimers can see of their claims: their
+// own history with totals per token and each pocket's leaderboard.
+// Leaderboards are cached in Redis for LEADERBOARD_CACHE_TTL seconds, so a
+// pocket everyone is watching costs one query per TTL across all replicas.
+type ClaimHistoryService struct {
+	claimRepo repository.ClaimStore
+	cache     *PocketCache
+	redis     *repository.RedisClient
+	ttl       time.Duration
+}
+
+func NewClaimHistoryService(claimRepo repository.ClaimStore, cache *PocketCache, redis *repository.RedisClient, cfg *config.Config) *ClaimHistoryService {
+	return &ClaimHistoryService{
+		claimRepo: claimRepo,
+		cache:     cache,
+		redis:     redis,
+		ttl:       time.Duration(cfg.LeaderboardTTL) * time.Second,
+	}
+}
+
+// ClaimerHistory is a page of a claimer's claims and what they have been
+// paid overall
+type ClaimerHistory struct {
+	Claims []*model.ClaimerClaim `json:"claims"`
+	Totals []*model.ClaimerTotal `json:"totals"`
+	Total  int64                 `json:"total"`
+}
+
+// UserClaims returns a platform account's claims, newest first, with its
+// totals per token
+func (s *ClaimHistoryService) UserClaims(ctx context.Context, platform, platformID string, page, limit int) (*ClaimerHistory, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	claims, total, err := s.claimRepo.ListByClaimer(ctx, platform, platformID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list claims: %w", err)
+	}
+	if claims == nil {
+		claims = []*model.ClaimerClaim{}
+	}
+	for _, c := range claims {
+		c.Received = math.Round((c.Amount-c.Fee-c.Donation)*1e8) / 1e8
+	}
+	totals, err := s.claimRepo.ClaimerTotals(ctx, platform, platformID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total claims: %w", err)
+	}
+	return &ClaimerHistory{Claims: claims, Totals: totals, Total: total}, nil
+}
+
+// Leaderboard returns a pocket's top limit claimers, biggest first, and for
+// a lucky draw its biggest win
+func (s *ClaimHistoryService) Leaderboard(ctx context.Context, redPocketID string, limit int) (*model.PocketLeaderboard, error) {
+	if limit < 1 || limit > maxLeaderboardSize {
+		limit = 10
+	}
+
+	board, err := s.cachedLeaderboard(ctx, redPocketID)
+	if err != nil {
+		return nil, err
+	}
+	if len(board.TopClaimers) > limit {
+		board.TopClaimers = board.TopClaimers[:limit]
+	}
+	return board, nil
+}
+
+func (s *ClaimHistoryService) cachedLeaderboard(ctx context.Context, redPocketID string) (*model.PocketLeaderboard, error) {
+	key := "leaderboard:" + redPocketID
+	if s.ttl > 0 {
+		data, err := s.redis.GetCached(ctx, key)
+		if err != nil {
+			slog.WarnContext(ctx, "leaderboard: failed to read cache", "red_pocket_id", redPocketID, "error", err)
+		}
+		if len(data) > 0 {
+			board := &model.PocketLeaderboard{}
+			if err := json.Unmarshal(data, board); err == nil {
+				return board, nil
+			}
+		}
+	}
+
+	board, err := s.buildLeaderboard(ctx, redPocketID)
+	if err != nil {
+		return nil, err
+	}
+	if s.ttl > 0 {
+		data, err := json.Marshal(board)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.redis.SetCached(ctx, key, data, s.ttl); err != nil {
+			slog.WarnContext(ctx, "leaderboard: failed to cache", "red_pocket_id", redPocketID, "error", err)
+		}
+	}
+	return board, nil
+}
+
+func (s *ClaimHistoryService) buildLeaderboard(ctx context.Context, redPocketID string) (*model.PocketLeaderboard, error) {
+	rp, err := s.cache.Get(ctx, redPocketID)
+	if err != nil {
+		return nil, ErrRedPocketNotFound
+	}
+
+	entries, err := s.claimRepo.Leaderboard(ctx, rp.ID, maxLeaderboardSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank claims: %w", err)
+	}
+	count, amount, err := s.claimRepo.ClaimedTotals(ctx, rp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total claims: %w", err)
+	}
+
+	board := &model.PocketLeaderboard{
+		RedPocketID:   rp.ID,
+		Token:         rp.Token,
+		ChainID:       rp.ChainID,
+		IsLuckyDraw:   rp.IsLuckyDraw,
+		ClaimedCount:  count,
+		ClaimedAmount: amount,
+		TopClaimers:   entries,
+		GeneratedAt:   time.Now().UTC(),
+	}
+	if rp.IsLuckyDraw && rp.TotalCount > 0 && rp.Amount > 0 {
+		average := rp.Amount / float64(rp.TotalCount)
+		for _, e := range entries {
+			e.Multiple = math.Round(e.Amount/average*100) / 100
+		}
+		if len(entries) > 0 {
+			board.Luckiest = entries[0]
+		}
+	}
+	return board, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// maxClaimLinks caps the links minted by one request
+const maxClaimLinks = 500
+
+var (
+	ErrClaimLinksUnavailable = newCodedError("claim_links_unavailable")
+	ErrClaimLinkRequired     = newCodedError("claim_link_required")
+	ErrInvalidClaimLink      = newCodedError("invalid_claim_link")
+	ErrClaimLinkExpired      = newCodedError("claim_link_expired")
+	ErrClaimLinkRecipient    = newCodedError("claim_link_wrong_recipient")
+	ErrClaimLinkUsed         = newCodedError("claim_link_used")
+	ErrPocketLinksUnsigned   = newCodedError("pocket_links_unsigned")
+	ErrInvalidClaimLinkCount = newCodedError("invalid_claim_link_count", maxClaimLinks)
+)
+
+// ClaimToken is what a signed claim link lets its holder do: claim
+// RedPocketID until ExpiresAt (Unix seconds), only as the recipient if one
+// is set, and only once if it has a LinkID
+type ClaimToken struct {
+	RedPocketID string `json:"p"`
+	ExpiresAt   int64  `json:"e"`
+	Platform    string `json:"pl,omitempty"`
+	PlatformID  string `json:"r,omitempty"`
+	LinkID      string `json:"l,omitempty"`
+}
+
+// ClaimLinks signs and verifies the claim links of pockets created with
+// signedLinks, whose short IDs are otherwise enough to claim them. A token
+// is
+//
+//	base64url(JSON of ClaimToken) "." base64url(HMAC-SHA256 of the first part)
+//
+// keyed with CLAIM_LINK_SECRET. The link shared when the pocket is created
+// lets anyone claim until the pocket expires; the creator can mint links for
+// named recipients, or one-time links, on top.
+type ClaimLinks struct {
+	rpRepo       repository.RedPocketStore
+	campaignRepo repository.CampaignStore
+	claimRepo    repository.ClaimStore
+	secret       []byte
+	cfg          *config.Config
+}
+
+func NewClaimLinks(
+	rpRepo repository.RedPocketStore,
+	campaignRepo repository.CampaignStore,
+	claimRepo repository.ClaimStore,
+	cfg *config.Config,
+) *ClaimLinks {
+	return &ClaimLinks{
+		rpRepo:       rpRepo,
+		campaignRepo: campaignRepo,
+		claimRepo:    claimRepo,
+		secret:       []byte(cfg.ClaimLinkSecret),
+		cfg:          cfg,
+	}
+}
+
+// Available reports whether pockets can be created with signed links
+func (l *ClaimLinks) Available() bool {
+	return len(l.secret) > 0
+}
+
+// URL is the public claim page of a red pocket, carrying the pocket-wide
+// token when the pocket needs signed links
+func (l *ClaimLinks) URL(rp *model.RedPocket) string {
+	if !rp.SignedLinks || !l.Available() {
+		return claimLink(l.cfg, rp.ID)
+	}
+	return l.url(&ClaimToken{RedPocketID: rp.ID, ExpiresAt: rp.ExpiresAt.Unix()})
+}
+
+func (l *ClaimLinks) url(t *ClaimToken) string {
+	return claimLink(l.cfg, t.RedPocketID) + "?t=" + url.QueryEscape(l.sign(t))
+}
+
+func (l *ClaimLinks) sign(t *ClaimToken) string {
+	payload, _ := json.Marshal(t)
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(l.mac(body))
+}
+
+func (l *ClaimLinks) mac(body string) []byte {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
+
+// Verify checks the claim link token of a claim on a pocket that needs one,
+// and returns it; claims on other pockets need none and get nil
+func (l *ClaimLinks) Verify(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) (*ClaimToken, error) {
+	if !rp.SignedLinks {
+		return nil, nil
+	}
+	if req.LinkToken == "" {
+		return nil, ErrClaimLinkRequired
+	}
+	if !l.Available() {
+		// The secret was removed, so no link can be checked
+		return nil, ErrInvalidClaimLink
+	}
+
+	body, sig, ok := strings.Cut(req.LinkToken, ".")
+	if !ok {
+		return nil, ErrInvalidClaimLink
+	}
+	given, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(given, l.mac(body)) {
+		return nil, ErrInvalidClaimLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidClaimLink
+	}
+	t := &ClaimToken{}
+	if err := json.Unmarshal(payload, t); err != nil || t.RedPocketID != rp.ID {
+		return nil, ErrInvalidClaimLink
+	}
+
+	if time.Now().Unix() > t.ExpiresAt {
+		return nil, ErrClaimLinkExpired
+	}
+	if t.PlatformID != "" && (t.Platform != req.Platform || t.PlatformID != req.PlatformID) {
+		return nil, ErrClaimLinkRecipient
+	}
+	if t.LinkID != "" {
+		used, err := l.claimRepo.LinkUsed(ctx, t.LinkID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check claim link: %w", err)
+		}
+		if used {
+			return nil, ErrClaimLinkUsed
+		}
+	}
+	return t, nil
+}
+
+// ClaimLinkRecipient is the platform account a minted link is issued to
+type ClaimLinkRecipient struct {
+	Platform   string `json:"platform" binding:"required"`
+	PlatformID string `json:"platformId" binding:"required"`
+}
+
+// MintClaimLinksRequest asks for one link per recipient, and Count one-time
+// links anyone may use
+type MintClaimLinksRequest struct {
+	Recipients []ClaimLinkRecipient `json:"recipients" binding:"dive"`
+	Count      int                  `json:"count" binding:"min=0"`
+	ExpiresIn  int64                `json:"expiresIn" binding:"min=0"` // seconds, default and at most until the pocket expires
+}
+
+// ClaimLinkGrant is a minted claim link
+type ClaimLinkGrant struct {
+	LinkID     string    `json:"linkId"`
+	URL        string    `json:"url"`
+	Token      string    `json:"token"`
+	Platform   string    `json:"platform,omitempty"`
+	PlatformID string    `json:"platformId,omitempty"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Mint issues one-time claim links for one of the enterprise's pockets that
+// needs signed links. Links bound to a recipient only work for them.
+func (l *ClaimLinks) Mint(ctx context.Context, redPocketID, enterpriseID string, req *MintClaimLinksRequest) ([]*ClaimLinkGrant, error) {
+	total := len(req.Recipients) + req.Count
+	if total < 1 || total > maxClaimLinks {
+		return nil, ErrInvalidClaimLinkCount
+	}
+
+	rp, err := l.rpRepo.GetByID(ctx, redPocketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRedPocketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := l.campaignRepo.GetByID(ctx, rp.CampaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return nil, ErrRedPocketNotFound
+	}
+	if !rp.SignedLinks {
+		return nil, ErrPocketLinksUnsigned
+	}
+	if !l.Available() {
+		return nil, ErrClaimLinksUnavailable
+	}
+	if !time.Now().Before(rp.ExpiresAt) {
+		return nil, ErrRedPocketExpired
+	}
+
+	expiresAt := rp.ExpiresAt
+	if req.ExpiresIn > 0 {
+		if at := time.Now().Add(time.Duration(req.ExpiresIn) * time.Second); at.Before(expiresAt) {
+			expiresAt = at
+		}
+	}
+	expiresAt = expiresAt.Truncate(time.Second)
+
+	grants := make([]*ClaimLinkGrant, 0, total)
+	mint := func(platform, platformID string) {
+		t := &ClaimToken{
+			RedPocketID: rp.ID,
+			ExpiresAt:   expiresAt.Unix(),
+			Platform:    platform,
+			PlatformID:  platformID,
+			LinkID:      "link_" + uuid.New().String(),
+		}
+		grants = append(grants, &ClaimLinkGrant{
+			LinkID:     t.LinkID,
+			URL:        l.url(t),
+			Token:      l.sign(t),
+			Platform:   platform,
+			PlatformID: platformID,
+			ExpiresAt:  expiresAt,
+		})
+	}
+	for _, r := range req.Recipients {
+		mint(r.Platform, r.PlatformID)
+	}
+	for i := 0; i < req.Count; i++ {
+		mint("", "")
+	}
+	return grants, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var ErrClaimRateLimited = newCodedError("claim_rate_limited")
+
+// ClaimRateLimiter stops a single claimer, client or burst from hammering
+// claims, which the global per-IP RateLimit middleware cannot tell apart. It
+// counts attempts in fixed Redis windows: per platform account per minute,
+// per client IP and red pocket per minute, and per red pocket per second.
+type ClaimRateLimiter struct {
+	redis *repository.RedisClient
+	cfg   *config.Config
+}
+
+func NewClaimRateLimiter(redis *repository.RedisClient, cfg *config.Config) *ClaimRateLimiter {
+	return &ClaimRateLimiter{redis: redis, cfg: cfg}
+}
+
+// claimRateLimit is one counter of ClaimRateLimiter
+type claimRateLimit struct {
+	key    string
+	limit  int // 0 is unlimited
+	window time.Duration
+}
+
+// Allow counts a claim attempt and returns how long the claimer has to
+// wait if it is over a limit, or 0. Attempts are let through when Redis
+// cannot count them.
+func (l *ClaimRateLimiter) Allow(ctx context.Context, req *ClaimRequest) time.Duration {
+	limits := []claimRateLimit{
+		{fmt.Sprintf("claimrate:user:%s:%s", req.Platform, req.PlatformID), l.cfg.ClaimRatePerUser, time.Minute},
+	}
+	// Claims from the chat bots carry no client IP
+	if req.ClientIP != "" {
+		limits = append(limits, claimRateLimit{fmt.Sprintf("claimrate:ip:%s:%s", req.RedPocketID, req.ClientIP), l.cfg.ClaimRatePerIPPocket, time.Minute})
+	}
+	limits = append(limits, claimRateLimit{fmt.Sprintf("claimrate:pocket:%s", req.RedPocketID), l.cfg.ClaimRatePocketRPS, time.Second})
+
+	// An attempt over one limit is not counted against the next, so one
+	// claimer cannot use up the pocket's share
+	now := time.Now()
+	for _, lim := range limits {
+		if lim.limit <= 0 {
+			continue
+		}
+		start := now.Truncate(lim.window)
+		count, err := l.redis.IncrementRateLimit(ctx, fmt.Sprintf("%s:%d", lim.key, start.Unix()), lim.window)
+		if err != nil {
+			slog.WarnContext(ctx, "claim rate limit: failed to count", "key", lim.key, "error", err)
+			continue
+		}
+		if count > int64(lim.limit) {
+			return start.Add(lim.window).Sub(now)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// Claimer attributes a campaign can weight claims by
+const (
+	WeightDiscordRole   = "discord_role"
+	WeightTelegramAdmin = "telegram_admin"
+	WeightNFTTier       = "nft_tier"
+)
+
+const (
+	maxClaimWeights    = 20
+	maxClaimMultiplier = 10
+)
+
+// errInvalidClaimWeight says what is wrong with a claim weight
+func errInvalidClaimWeight(reason string) *CodedError {
+	return newCodedError("invalid_claim_weight", reason)
+}
+
+// MemberRoles looks up the roles of a member of a Discord guild, none when
+// they are not a member
+type MemberRoles interface {
+	MemberRoles(ctx context.Context, guildID, userID string) ([]string, error)
+}
+
+type ClaimWeightRequest struct {
+	Attribute    string  `json:"attribute" binding:"required,oneof=discord_role telegram_admin nft_tier"`
+	GuildID      string  `json:"guildId"`
+	RoleID       string  `json:"roleId"`
+	TokenAddress string  `json:"tokenAddress"`
+	MinBalance   string  `json:"minBalance"` // NFTs held, default 1
+	Multiplier   float64 `json:"multiplier" binding:"required"`
+}
+
+type ClaimWeightsRequest struct {
+	Weights []ClaimWeightRequest `json:"weights" binding:"dive"` // empty removes every weight
+}
+
+// buildClaimWeights validates requested claim weights
+func buildClaimWeights(reqs []ClaimWeightRequest) ([]model.ClaimWeight, error) {
+	if len(reqs) > maxClaimWeights {
+		return nil, errInvalidClaimWeight(fmt.Sprintf("at most %d weights", maxClaimWeights))
+	}
+
+	weights := make([]model.ClaimWeight, 0, len(reqs))
+	for i, req := range reqs {
+		if req.Multiplier <= 1 || req.Multiplier > maxClaimMultiplier {
+			return nil, errInvalidClaimWeight(fmt.Sprintf("weight %d: multiplier must be over 1 and at most %d", i+1, maxClaimMultiplier))
+		}
+		w := model.ClaimWeight{Attribute: req.Attribute, Multiplier: math.Round(req.Multiplier*10000) / 10000}
+		switch req.Attribute {
+		case WeightDiscordRole:
+			w.GuildID, w.RoleID = strings.TrimSpace(req.GuildID), strings.TrimSpace(req.RoleID)
+			if !isSnowflake(w.GuildID) || !isSnowflake(w.RoleID) {
+				return nil, errInvalidClaimWeight(fmt.Sprintf("weight %d: discord_role needs a guild ID and a role ID", i+1))
+			}
+		case WeightNFTTier:
+			if !common.IsHexAddress(req.TokenAddress) || common.HexToAddress(req.TokenAddress) == (common.Address{}) {
+				return nil, errInvalidClaimWeight(fmt.Sprintf("weight %d: nft_tier needs a token address", i+1))
+			}
+			w.TokenAddress = common.HexToAddress(req.TokenAddress).Hex()
+			w.MinBalance = req.MinBalance
+			if w.MinBalance == "" {
+				w.MinBalance = "1"
+			}
+			if n, ok := new(big.Int).SetString(w.MinBalance, 10); !ok || n.Sign() <= 0 {
+				return nil, errInvalidClaimWeight(fmt.Sprintf("weight %d: nft_tier minimum balance must be a positive integer", i+1))
+			}
+		}
+		weights = append(weights, w)
+	}
+	return weights, nil
+}
+
+// isSnowflake reports whether id looks like a Discord ID
+func isSnowflake(id string) bool {
+	if id == "" || len(id) > 20 {
+		return false
+	}
+	return strings.Trim(id, "0123456789") == ""
+}
+
+// GetClaimWeights returns the claim weights of an enterprise's campaign
+func (s *CampaignService) GetClaimWeights(ctx context.Context, id, enterpriseID string) (*model.CampaignClaimWeights, error) {
+	if campaign, err := s.repo.GetByID(ctx, id); err != nil || campaign.EnterpriseID != enterpriseID {
+		return nil, ErrCampaignNotFound
+	}
+	weights, err := s.repo.GetClaimWeights(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	return weights, err
+}
+
+// UpdateClaimWeights replaces the claim weights of an enterprise's
+// campaign. They apply to claims made from now on.
+func (s *CampaignService) UpdateClaimWeights(ctx context.Context, id, enterpriseID string, req *ClaimWeightsRequest) (*model.CampaignClaimWeights, error) {
+	if campaign, err := s.repo.GetByID(ctx, id); err != nil || campaign.EnterpriseID != enterpriseID {
+		return nil, ErrCampaignNotFound
+	}
+	list, err := buildClaimWeights(req.Weights)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	weights := &model.CampaignClaimWeights{Weights: list, UpdatedAt: &now}
+	err = s.repo.SetClaimWeights(ctx, id, weights)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update claim weights: %w", err)
+	}
+	return weights, nil
+}
+
+// UseMemberRoles lets discord_role weights look up claimers' guild roles
+func (s *RedPocketService) UseMemberRoles(roles MemberRoles) {
+	s.memberRoles = roles
+}
+
+// claimMultiplier returns the highest multiplier of the campaign's claim
+// weights the claimer matches, 1 when none. Attributes that cannot be
+// looked up do not match rather than failing the claim.
+func (s *RedPocketService) claimMultiplier(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) float64 {
+	weights, err := s.campaignRepo.GetClaimWeights(ctx, rp.CampaignID)
+	if err != nil || len(weights.Weights) == 0 {
+		return 1
+	}
+
+	multiplier := 1.0
+	var roles map[string][]string // guild -> the claimer's roles
+	var wallet string             // proven by the claimer, "" without a valid proof
+	walletChecked := false
+	balances := map[string]*big.Int{}
+	for _, w := range weights.Weights {
+		if w.Multiplier <= multiplier {
+			continue
+		}
+		matched := false
+		switch w.Attribute {
+		case WeightTelegramAdmin:
+			matched = req.Platform == "telegram" && req.ChatAdmin
+		case WeightDiscordRole:
+			if !strings.EqualFold(req.Platform, "discord") || s.memberRoles == nil {
+				continue
+			}
+			if roles == nil {
+				roles = map[string][]string{}
+			}
+			guildRoles, ok := roles[w.GuildID]
+			if !ok {
+				if guildRoles, err = s.memberRoles.MemberRoles(ctx, w.GuildID, req.PlatformID); err != nil {
+					slog.WarnContext(ctx, "claim weight: failed to look up discord roles", "guild_id", w.GuildID, "error", err)
+				}
+				roles[w.GuildID] = guildRoles
+			}
+			matched = slices.Contains(guildRoles, w.RoleID)
+		case WeightNFTTier:
+			if !walletChecked {
+				wallet, _ = provenWallet(rp, req)
+				walletChecked = true
+			}
+			if wallet == "" {
+				continue
+			}
+			balance, ok := balances[w.TokenAddress]
+			if !ok {
+				if balance, err = s.walletSvc.TokenBalance(ctx, w.TokenAddress, wallet); err != nil {
+					slog.WarnContext(ctx, "claim weight: failed to read nft balance", "token", w.TokenAddress, "error", err)
+				}
+				balances[w.TokenAddress] = balance
+			}
+			minBalance, _ := new(big.Int).SetString(w.MinBalance, 10)
+			matched = balance != nil && minBalance != nil && balance.Cmp(minBalance) >= 0
+		}
+		if matched {
+			multiplier = w.Multiplier
+		}
+	}
+	return multiplier
+}
+
+// weighClaimUnits multiplies a claim's share by the claimer's multiplier,
+// capped at the pocket's maximum claim amount for lucky draws and at what
+// is left of the pocket
+func weighClaimUnits(rp *model.RedPocket, share *big.Int, multiplier float64) *big.Int {
+	if multiplier <= 1 {
+		return share
+	}
+	weighted := new(big.Int).Mul(share, big.NewInt(int64(math.Round(multiplier*10000))))
+	weighted.Quo(weighted, big.NewInt(10000))
+
+	if rp.IsLuckyDraw && rp.MaxAmount > 0 {
+		if cap := floatToBigInt(rp.MaxAmount, rp.Decimals); weighted.Cmp(cap) > 0 {
+			weighted = cap
+		}
+	}
+	if weighted.Cmp(share) < 0 {
+		return share
+	}
+	if remaining := rp.RemainingUnits.Int(); weighted.Cmp(remaining) > 0 {
+		return remaining
+	}
+	return weighted
+}
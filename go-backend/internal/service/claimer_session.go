@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/bot"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// Login codes: a new one can be requested once a minute, and one is given
+// up on after a few wrong guesses
+const (
+	loginCodeResendInterval = time.Minute
+	maxLoginCodeAttempts    = 5
+)
+
+var (
+	// ErrInvalidLoginCode is returned for a wrong, used or expired code
+	ErrInvalidLoginCode = newCodedError("invalid_login_code")
+	// ErrLoginCodeThrottled is returned when a code was sent within the
+	// last minute
+	ErrLoginCodeThrottled = newCodedError("login_code_throttled")
+	// ErrLoginPlatformUnsupported is returned for a platform whose bot
+	// cannot message a user directly
+	ErrLoginPlatformUnsupported = newCodedError("login_platform_unsupported")
+)
+
+// ClaimerSessions signs claimers in to the routes that move their funds.
+// Wallets are custodial and claimers are known only by their chat platform
+// account, so the proof of being one is a one-time code the platform's bot
+// sends the account in a direct message. The code buys a short-lived
+// session token, see JWTKeys.IssueClaimer.
+type ClaimerSessions struct {
+	redis    *repository.RedisClient
+	keys     *JWTKeys
+	telegram *bot.TelegramBot
+	discord  *bot.DiscordBot
+	codeTTL  time.Duration
+}
+
+func NewClaimerSessions(redis *repository.RedisClient, keys *JWTKeys, telegram *bot.TelegramBot, discord *bot.DiscordBot, cfg *config.Config) *ClaimerSessions {
+	return &ClaimerSessions{
+		redis:    redis,
+		keys:     keys,
+		telegram: telegram,
+		discord:  discord,
+		codeTTL:  time.Duration(cfg.ClaimerLoginCodeTTL) * time.Second,
+	}
+}
+
+// RequestCode sends a platform account a new login code, replacing any
+// earlier one
+func (s *ClaimerSessions) RequestCode(ctx context.Context, platform, platformID string) error {
+	userID := fmt.Sprintf("user_%s_%s", platform, platformID)
+	var send func(text string) error
+	switch platform {
+	case "telegram":
+		// A private chat with the bot has the user's ID
+		chatID, err := strconv.ParseInt(platformID, 10, 64)
+		if err != nil {
+			return ErrLoginPlatformUnsupported
+		}
+		send = func(text string) error { return s.telegram.SendMessage(chatID, text, "") }
+	case "discord":
+		send = func(text string) error {
+			return s.discord.SendDirectMessage(ctx, platformID, &bot.DiscordMessage{Content: text})
+		}
+	default:
+		return ErrLoginPlatformUnsupported
+	}
+
+	fresh, err := s.redis.ClaimNonce(ctx, "logincode:"+userID, loginCodeResendInterval)
+	if err != nil {
+		return fmt.Errorf("failed to throttle login code: %w", err)
+	}
+	if !fresh {
+		return ErrLoginCodeThrottled
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	if err := s.redis.SetLoginCode(ctx, userID, hashLoginCode(userID, code), s.codeTTL); err != nil {
+		return fmt.Errorf("failed to store login code: %w", err)
+	}
+	if err := send(i18n.Tc(ctx, "claimer.login_code", code, int(s.codeTTL.Minutes()))); err != nil {
+		return fmt.Errorf("failed to send login code: %w", err)
+	}
+	return nil
+}
+
+// VerifyCode exchanges a login code for a session token, using the code up
+func (s *ClaimerSessions) VerifyCode(ctx context.Context, platform, platformID, code string) (string, time.Time, error) {
+	userID := fmt.Sprintf("user_%s_%s", platform, platformID)
+	stored, err := s.redis.GetLoginCode(ctx, userID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read login code: %w", err)
+	}
+	if stored == "" {
+		return "", time.Time{}, ErrInvalidLoginCode
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashLoginCode(userID, code))) != 1 {
+		attempts, err := s.redis.IncrementRateLimit(ctx, "logincode:attempts:"+userID, s.codeTTL)
+		if err != nil || attempts >= maxLoginCodeAttempts {
+			_ = s.redis.DeleteLoginCode(ctx, userID)
+		}
+		return "", time.Time{}, ErrInvalidLoginCode
+	}
+	if err := s.redis.DeleteLoginCode(ctx, userID); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to use up login code: %w", err)
+	}
+	return s.keys.IssueClaimer(userID)
+}
+
+func hashLoginCode(userID, code string) string {
+	sum := sha256.Sum256([]byte(userID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+type accountKey struct{}
+
+// WithAccount marks ctx as authenticated as an account: a claimer's user ID
+// or an enterprise's ID. Services that move an account's funds refuse a
+// request for another account.
+func WithAccount(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, accountKey{}, id)
+}
+
+// accountFrom returns the account ctx was authenticated as, if any
+func accountFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(accountKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// ReplicaSafety says how a feature behaves when several replicas run
+type ReplicaSafety string
+
+const (
+	// Every replica runs it; replicas coordinate through row locks or
+	// conditional updates in Postgres
+	ReplicaSafe ReplicaSafety = "replica-safe"
+	// Every replica runs its own copy on purpose, e.g. caches and listeners
+	ReplicaLocal ReplicaSafety = "per-replica"
+	// One replica at a time runs it while holding a Redis lease
+	ReplicaLeader ReplicaSafety = "leader"
+	// It keeps state that only works inside one process; refused in cluster mode
+	ReplicaSingle ReplicaSafety = "single-process"
+)
+
+const (
+	leaseTTL   = 30 * time.Second
+	leaseRenew = leaseTTL / 3
+)
+
+// FeatureStatus is one line of the replica-safety report
+type FeatureStatus struct {
+	Name   string        `json:"name"`
+	Safety ReplicaSafety `json:"safety"`
+	State  string        `json:"state"` // running, leader, standby, stopped, disabled
+	Note   string        `json:"note,omitempty"`
+}
+
+// ClusterReport describes how this replica runs its features
+type ClusterReport struct {
+	ClusterMode bool            `json:"clusterMode"`
+	ReplicaID   string          `json:"replicaId"`
+	StartedAt   time.Time       `json:"startedAt"`
+	Features    []FeatureStatus `json:"features"`
+}
+
+// Cluster starts background features according to their replica safety. With
+// CLUSTER_MODE off everything runs as a single process would; with it on,
+// leader features only run on the replica holding their lease and
+// single-process features are refused.
+type Cluster struct {
+	redis     *repository.RedisClient
+	enabled   bool
+	replicaID string
+	startedAt time.Time
+
+	mu       sync.Mutex
+	features []*FeatureStatus
+}
+
+func NewCluster(redis *repository.RedisClient, cfg *config.Config) *Cluster {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "replica"
+	}
+	return &Cluster{
+		redis:     redis,
+		enabled:   cfg.ClusterMode,
+		replicaID: host + "-" + uuid.New().String()[:8],
+		startedAt: time.Now(),
+	}
+}
+
+// Enabled reports whether CLUSTER_MODE is on
+func (c *Cluster) Enabled() bool {
+	return c.enabled
+}
+
+// Go runs a feature's loop in the background as its safety allows until ctx
+// is cancelled
+func (c *Cluster) Go(ctx context.Context, name string, safety ReplicaSafety, note string, start func(ctx context.Context)) {
+	status := &FeatureStatus{Name: name, Safety: safety, State: "running", Note: note}
+	c.mu.Lock()
+	c.features = append(c.features, status)
+	c.mu.Unlock()
+
+	switch {
+	case c.enabled && safety == ReplicaSingle:
+		c.setState(status, "disabled")
+		slog.WarnContext(ctx, "cluster: feature cannot run on several replicas, not starting it", "feature", name)
+	case c.enabled && safety == ReplicaLeader:
+		c.setState(status, "standby")
+		go c.lead(ctx, status, start)
+	default:
+		go func() {
+			start(ctx)
+			if ctx.Err() == nil {
+				c.setState(status, "stopped")
+			}
+		}()
+	}
+}
+
+// lead runs start whenever this replica holds the feature's lease, stopping
+// it as soon as a renewal fails. A feature that returns by itself, e.g.
+// because it is not configured, is not restarted.
+func (c *Cluster) lead(ctx context.Context, status *FeatureStatus, start func(ctx context.Context)) {
+	key := "leader:" + status.Name
+	for ctx.Err() == nil {
+		acquired, err := c.redis.AcquireLease(ctx, key, c.replicaID, leaseTTL)
+		if err != nil {
+			slog.ErrorContext(ctx, "cluster: failed to acquire lease", "feature", status.Name, "error", err)
+		}
+		if !acquired {
+			select {
+			case <-ctx.Done():
+			case <-time.After(leaseRenew):
+			}
+			continue
+		}
+
+		slog.InfoContext(ctx, "cluster: leading", "feature", status.Name, "replica_id", c.replicaID)
+		c.setState(status, "leader")
+		leadCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			start(leadCtx)
+			close(done)
+		}()
+		finished := c.holdLease(leadCtx, key, done)
+		stop()
+		<-done
+
+		// Let go so another replica can take over promptly on shutdown
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := c.redis.ReleaseLease(releaseCtx, key, c.replicaID); err != nil {
+			slog.ErrorContext(ctx, "cluster: failed to release lease", "feature", status.Name, "error", err)
+		}
+		cancel()
+
+		if finished {
+			c.setState(status, "stopped")
+			return
+		}
+		c.setState(status, "standby")
+	}
+}
+
+// holdLease renews a lease until ctx is cancelled, the feature returns or the
+// lease is lost. It reports whether the feature returned.
+func (c *Cluster) holdLease(ctx context.Context, key string, done <-chan struct{}) bool {
+	ticker := time.NewTicker(leaseRenew)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-done:
+			return true
+		case <-ticker.C:
+			held, err := c.redis.RenewLease(ctx, key, c.replicaID, leaseTTL)
+			if err != nil {
+				slog.ErrorContext(ctx, "cluster: failed to renew lease", "lease", key, "error", err)
+			}
+			if !held {
+				slog.WarnContext(ctx, "cluster: lost lease", "lease", key)
+				return false
+			}
+		}
+	}
+}
+
+func (c *Cluster) setState(status *FeatureStatus, state string) {
+	c.mu.Lock()
+	status.State = state
+	c.mu.Unlock()
+}
+
+// Report lists every feature started so far and how this replica runs it
+func (c *Cluster) Report() *ClusterReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	features := make([]FeatureStatus, len(c.features))
+	for i, f := range c.features {
+		features[i] = *f
+	}
+	return &ClusterReport{
+		ClusterMode: c.enabled,
+		ReplicaID:   c.replicaID,
+		StartedAt:   c.startedAt,
+		Features:    features,
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrConsolidationNotFound  = errors.New("consolidation not found")
+	ErrAssetNotConsolidatable = newCodedError("asset_not_consolidatable")
+)
+
+// ConsolidationService bridges a user's balances of an asset, spread over
+// the AA wallets they have on each chain, to their wallet on one chain
+type ConsolidationService struct {
+	walletRepo  repository.WalletStore
+	hyperbridge *HyperbridgeService
+}
+
+func NewConsolidationService(walletRepo repository.WalletStore, hyperbridge *HyperbridgeService) *ConsolidationService {
+	return &ConsolidationService{walletRepo: walletRepo, hyperbridge: hyperbridge}
+}
+
+// ConsolidateRequest names the asset to gather and the chain to gather it
+// on, whose wallet receives the transfers
+type ConsolidateRequest struct {
+	Asset         string `json:"asset" binding:"required"`
+	TargetChain   int64  `json:"targetChain" binding:"required"`
+	WalletAddress string `json:"walletAddress"` // optional; must be the user's wallet on TargetChain
+}
+
+// Consolidate sweeps the user's balances of an asset from all their other
+// wallets to their wallet on the target chain. Only tokens are swept:
+// native balances pay for the wallets' gas. Returns nil when no other
+// wallet holds any of the asset. Only the user ctx was authenticated as, by
+// a claimer session, can move their balances.
+func (s *ConsolidationService) Consolidate(ctx context.Context, userID string, req *ConsolidateRequest) (*Consolidation, error) {
+	if account, ok := accountFrom(ctx); !ok || account != userID {
+		return nil, ErrWalletNotOwned
+	}
+	target := ChainID(req.TargetChain)
+	if _, err := s.hyperbridge.xcmBridge.GetAssetAddress(req.Asset, target); err != nil || s.hyperbridge.xcmBridge.IsNativeAsset(req.Asset, target) {
+		return nil, ErrAssetNotConsolidatable
+	}
+
+	recipient, err := s.walletRepo.GetByUserID(ctx, userID, req.TargetChain)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWalletNotOwned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet: %w", err)
+	}
+	if req.WalletAddress != "" && !strings.EqualFold(req.WalletAddress, recipient.Address) {
+		return nil, ErrWalletNotOwned
+	}
+
+	wallets, err := s.walletRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	accounts := make(map[ChainID]string, len(wallets))
+	for _, w := range wallets {
+		accounts[ChainID(w.ChainID)] = w.Address
+	}
+	return s.hyperbridge.Consolidate(ctx, userID, req.Asset, accounts, target, recipient.Address)
+}
+
+// Get returns a consolidation with the status of its transfers
+func (s *ConsolidationService) Get(ctx context.Context, id string) (*Consolidation, error) {
+	return s.hyperbridge.GetConsolidation(ctx, id)
+}
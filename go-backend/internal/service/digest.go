@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/bot"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var ErrDigestNotFound = newCodedError("digest_not_found")
+
+// errInvalidDigestChannel says what is wrong with a digest's channel
+func errInvalidDigestChannel(reason string) *CodedError {
+	return newCodedError("invalid_digest_channel", reason)
+}
+
+const (
+	digestSweepInterval = 5 * time.Minute
+	digestSweepBatch    = 50
+)
+
+// DigestService posts each subscribed enterprise's previous day (UTC) to
+// its Telegram, Discord or Slack channel: pockets created, claims, amounts
+// distributed and the top pocket. Enterprises opt in and pick the channel,
+// language and hour; a day with no pockets and no claims is skipped.
+type DigestService struct {
+	repo     *repository.DigestRepository
+	telegram *bot.TelegramBot
+	discord  *bot.DiscordBot
+	slack    *bot.SlackBot
+}
+
+func NewDigestService(repo *repository.DigestRepository, telegram *bot.TelegramBot, discord *bot.DiscordBot, slack *bot.SlackBot) *DigestService {
+	return &DigestService{repo: repo, telegram: telegram, discord: discord, slack: slack}
+}
+
+type DigestRequest struct {
+	Platform  string `json:"platform" binding:"required,oneof=telegram discord slack"`
+	ChannelID string `json:"channelId" binding:"required,max=128"`
+	Locale    string `json:"locale"`                                // default the request's language
+	Hour      int    `json:"hour" binding:"omitempty,min=0,max=23"` // UTC
+	Enabled   *bool  `json:"enabled"`                               // default true
+}
+
+// Get returns the enterprise's digest subscription
+func (s *DigestService) Get(ctx context.Context, enterpriseID string) (*model.DigestSubscription, error) {
+	d, err := s.repo.Get(ctx, enterpriseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDigestNotFound
+	}
+	return d, err
+}
+
+// Update subscribes the enterprise to the digest, or changes its
+// subscription
+func (s *DigestService) Update(ctx context.Context, enterpriseID string, req *DigestRequest) (*model.DigestSubscription, error) {
+	if _, err := NewChannelNotifier(s.telegram, s.discord, s.slack, req.Platform, req.ChannelID); err != nil {
+		return nil, errInvalidDigestChannel(err.Error())
+	}
+	locale := i18n.Normalize(req.Locale)
+	if locale == "" {
+		locale = i18n.FromContext(ctx)
+	}
+	return s.repo.Upsert(ctx, &model.DigestSubscription{
+		EnterpriseID: enterpriseID,
+		Platform:     req.Platform,
+		ChannelID:    req.ChannelID,
+		Locale:       locale,
+		Hour:         req.Hour,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+		UpdatedAt:    time.Now(),
+	})
+}
+
+// Delete unsubscribes the enterprise
+func (s *DigestService) Delete(ctx context.Context, enterpriseID string) error {
+	ok, err := s.repo.Delete(ctx, enterpriseID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDigestNotFound
+	}
+	return nil
+}
+
+// Start posts due digests until ctx is cancelled
+func (s *DigestService) Start(ctx context.Context) {
+	ticker := time.NewTicker(digestSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx, time.Now().UTC())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *DigestService) sweep(ctx context.Context, now time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := today.AddDate(0, 0, -1)
+
+	due, err := s.repo.Due(ctx, day, now.Hour(), digestSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "digests: failed to list due digests", "error", err)
+		return
+	}
+	for _, d := range due {
+		// Taken before posting, so a digest is posted at most once even
+		// when posting fails
+		ok, err := s.repo.MarkSent(ctx, d.EnterpriseID, day)
+		if err != nil {
+			slog.ErrorContext(ctx, "digests: failed to mark sent", "enterprise_id", d.EnterpriseID, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := s.send(ctx, d, day, today); err != nil {
+			slog.ErrorContext(ctx, "digests: failed to post digest", "enterprise_id", d.EnterpriseID, "day", day.Format("2006-01-02"), "error", err)
+			if err := s.repo.SetError(ctx, d.EnterpriseID, err.Error()); err != nil {
+				slog.ErrorContext(ctx, "digests: failed to record error", "enterprise_id", d.EnterpriseID, "error", err)
+			}
+		}
+	}
+}
+
+func (s *DigestService) send(ctx context.Context, d *model.DigestSubscription, from, to time.Time) error {
+	digest, err := s.repo.Stats(ctx, d.EnterpriseID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to load stats: %w", err)
+	}
+	if digest.PocketsCreated == 0 && digest.Claims == 0 {
+		return nil
+	}
+	notifier, err := NewChannelNotifier(s.telegram, s.discord, s.slack, d.Platform, d.ChannelID)
+	if err != nil {
+		return err
+	}
+	title, message := formatDigest(d.Locale, digest)
+	return notifier.Notify(ctx, title, message)
+}
+
+// formatDigest renders a digest as a title and plain text lines
+func formatDigest(locale string, digest *model.DailyDigest) (string, string) {
+	title := i18n.T(locale, "digest.title", digest.Day.Format("2006-01-02"))
+	lines := []string{
+		i18n.T(locale, "digest.pockets", digest.PocketsCreated),
+		i18n.T(locale, "digest.claims", digest.Claims, digest.Claimers),
+	}
+	if len(digest.Distributed) > 0 {
+		amounts := make([]string, len(digest.Distributed))
+		for i, a := range digest.Distributed {
+			amounts[i] = formatDigestAmount(a.Amount, a.Token)
+		}
+		lines = append(lines, i18n.T(locale, "digest.distributed", strings.Join(amounts, ", ")))
+	}
+	if p := digest.TopPocket; p != nil {
+		lines = append(lines, i18n.T(locale, "digest.top_pocket", p.ID, p.SenderName, p.Claims, formatDigestAmount(p.Amount, p.Token)))
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+func formatDigestAmount(amount float64, token string) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64) + " " + token
+}
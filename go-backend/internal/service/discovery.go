@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const engagementRefreshInterval = time.Minute
+
+// DiscoveryService serves the public feed of live pockets and keeps their
+// engagement scores current
+type DiscoveryService struct {
+	repo   *repository.DiscoveryRepository
+	rpRepo repository.RedPocketStore
+}
+
+func NewDiscoveryService(repo *repository.DiscoveryRepository, rpRepo repository.RedPocketStore) *DiscoveryService {
+	return &DiscoveryService{repo: repo, rpRepo: rpRepo}
+}
+
+// Discover lists active pockets of campaigns that opted into the public feed
+func (s *DiscoveryService) Discover(ctx context.Context, filter repository.DiscoveryFilter, page, limit int) ([]*model.DiscoveryPocket, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return s.repo.ListDiscoverable(ctx, filter, limit, offset)
+}
+
+// Share records that sharer (a platform user or client IP) shared a pocket.
+// Repeat shares by the same sharer are not counted again.
+func (s *DiscoveryService) Share(ctx context.Context, redPocketID, sharer, platform string) error {
+	if _, err := s.rpRepo.GetByID(ctx, redPocketID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRedPocketNotFound
+		}
+		return err
+	}
+	_, err := s.repo.RecordShare(ctx, redPocketID, sharer, platform)
+	return err
+}
+
+// Start refreshes engagement scores until ctx is cancelled
+func (s *DiscoveryService) Start(ctx context.Context) {
+	ticker := time.NewTicker(engagementRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.repo.RefreshEngagement(ctx); err != nil {
+			slog.ErrorContext(ctx, "discovery: failed to refresh engagement", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// ErrDownstreamUnavailable is returned while a dependency's circuit breaker
+// is open
+var ErrDownstreamUnavailable = newCodedError("downstream_unavailable")
+
+// Downstream applies an endpoint class's timeout, retry and circuit-breaker
+// policy to the calls made to one dependency. Each dependency has its own
+// breaker, so one failing provider does not cut off the others of its class.
+type Downstream struct {
+	name   string
+	policy config.DownstreamPolicy
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool // an open breaker let a trial call through
+}
+
+func NewDownstream(name string, policy config.DownstreamPolicy) *Downstream {
+	return &Downstream{name: name, policy: policy}
+}
+
+// Do calls fn with the per-attempt timeout, retrying failed attempts with
+// backoff. Every error fn returns counts as a failure of the dependency.
+func (d *Downstream) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err := d.allow(); err != nil {
+			return err
+		}
+		attemptCtx, cancel := d.attemptContext(ctx)
+		err = fn(attemptCtx)
+		cancel()
+		if ctx.Err() != nil {
+			d.abandon()
+			return err
+		}
+		d.record(err == nil)
+		if err == nil || !d.wait(ctx, attempt) {
+			return err
+		}
+	}
+}
+
+// HTTPClient returns a client whose requests follow the policy. Network
+// errors and 429, 502, 503 and 504 responses count as failures and are
+// retried when the request body can be replayed.
+func (d *Downstream) HTTPClient() *http.Client {
+	return &http.Client{Transport: &downstreamTransport{d: d, base: http.DefaultTransport}}
+}
+
+type downstreamTransport struct {
+	d    *Downstream
+	base http.RoundTripper
+}
+
+func (t *downstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	replayable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if err := t.d.allow(); err != nil {
+			return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+		}
+		attemptCtx, cancel := t.d.attemptContext(ctx)
+		r := req.Clone(attemptCtx)
+		if attempt > 0 {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if ctx.Err() != nil {
+			t.d.abandon()
+			cancel()
+			return resp, err
+		}
+		failed := err != nil || retryableStatus(resp.StatusCode)
+		t.d.record(!failed)
+		if !failed || !replayable || !t.d.wait(ctx, attempt) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// The attempt's deadline covers reading the body too
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+	}
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (d *Downstream) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.policy.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(d.policy.Timeout)*time.Second)
+}
+
+// wait sleeps before retrying after attempt and reports whether to retry
+func (d *Downstream) wait(ctx context.Context, attempt int) bool {
+	if attempt >= d.policy.Retries {
+		return false
+	}
+	backoff := time.Duration(d.policy.RetryBackoff) * time.Millisecond << attempt
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(backoff):
+		return true
+	}
+}
+
+// allow refuses calls while the breaker is open. Once the cooldown is over
+// one trial call goes through; its outcome closes or reopens the breaker.
+func (d *Downstream) allow() error {
+	if d.policy.BreakerThreshold <= 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.failures < d.policy.BreakerThreshold {
+		return nil
+	}
+	if time.Now().Before(d.openUntil) || d.probing {
+		return ErrDownstreamUnavailable
+	}
+	d.probing = true
+	return nil
+}
+
+// abandon forgets a call the caller gave up on, which says nothing about the
+// dependency
+func (d *Downstream) abandon() {
+	d.mu.Lock()
+	d.probing = false
+	d.mu.Unlock()
+}
+
+func (d *Downstream) record(ok bool) {
+	if d.policy.BreakerThreshold <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.probing = false
+	if ok {
+		if d.failures >= d.policy.BreakerThreshold {
+			slog.Info("downstream: recovered, closing circuit breaker", "downstream", d.name)
+		}
+		d.failures = 0
+		return
+	}
+	d.failures++
+	if d.failures >= d.policy.BreakerThreshold {
+		if d.failures == d.policy.BreakerThreshold {
+			slog.Warn("downstream: failing, opening circuit breaker", "downstream", d.name, "failures", d.failures)
+		}
+		d.openUntil = time.Now().Add(time.Duration(d.policy.BreakerCooldown) * time.Second)
+	}
+}
@@ -0,0 +1,514 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// Eligibility rule types
+const (
+	EligibilityAllowlist   = "allowlist"
+	EligibilityTokenGate   = "token_gate"
+	EligibilityAccountAge  = "account_age"
+	EligibilityGeo         = "geo"
+	EligibilityClaimWindow = "claim_window"
+)
+
+const (
+	maxEligibilityRules  = 10
+	maxAllowlistAccounts = 10000
+	maxAccountAgeDays    = 3650
+)
+
+// discordEpoch is where Discord snowflake timestamps start, in Unix milliseconds
+const discordEpoch = 1420070400000
+
+var (
+	ErrNotOnAllowlist      = newCodedError("not_on_allowlist")
+	ErrHolderProofRequired = newCodedError("holder_proof_required")
+	ErrTokenGateNotMet     = newCodedError("token_gate_not_met")
+	ErrRegionNotEligible   = newCodedError("region_not_eligible")
+)
+
+func errAccountTooNew(days int) *CodedError {
+	return newCodedError("account_too_new", days)
+}
+
+// errClaimWindowClosed gives the next opening in the window's timezone, so
+// the claimer sees the local time it opens
+func errClaimWindowClosed(opensAt time.Time) *CodedError {
+	return newCodedError("claim_window_closed", opensAt.Format(time.RFC3339))
+}
+
+// errInvalidEligibilityRule says what is wrong with a rule
+func errInvalidEligibilityRule(reason string) *CodedError {
+	return newCodedError("invalid_eligibility_rule", reason)
+}
+
+// eligibilityCheck implements one rule type: validate checks and normalizes
+// a rule's params when it is saved, check evaluates it against a claim and
+// returns a coded error when the claimer is not eligible. New rule types
+// only need a check registered in NewEligibilityService.
+type eligibilityCheck interface {
+	validate(p *model.EligibilityParams) error
+	check(ctx context.Context, p *model.EligibilityParams, rp *model.RedPocket, req *ClaimRequest) error
+}
+
+// EligibilityService manages the rules that restrict who can claim a
+// campaign's or a red pocket's pockets and evaluates them on every claim
+type EligibilityService struct {
+	repo         *repository.EligibilityRepository
+	campaignRepo repository.CampaignStore
+	checks       map[string]eligibilityCheck
+}
+
+func NewEligibilityService(
+	repo *repository.EligibilityRepository,
+	campaignRepo repository.CampaignStore,
+	claimRepo repository.ClaimStore,
+	walletSvc *WalletService,
+) *EligibilityService {
+	return &EligibilityService{
+		repo:         repo,
+		campaignRepo: campaignRepo,
+		checks: map[string]eligibilityCheck{
+			EligibilityAllowlist:   allowlistCheck{},
+			EligibilityTokenGate:   tokenGateCheck{walletSvc: walletSvc},
+			EligibilityAccountAge:  accountAgeCheck{claimRepo: claimRepo},
+			EligibilityGeo:         geoCheck{},
+			EligibilityClaimWindow: claimWindowCheck{},
+		},
+	}
+}
+
+type EligibilityRuleRequest struct {
+	Type   string                  `json:"type" binding:"required"`
+	Params model.EligibilityParams `json:"params"`
+}
+
+type EligibilityRequest struct {
+	Rules []EligibilityRuleRequest `json:"rules" binding:"dive"` // empty removes every rule
+}
+
+// ListForCampaign returns the rules attached to an enterprise's campaign
+func (s *EligibilityService) ListForCampaign(ctx context.Context, campaignID, enterpriseID string) ([]*model.EligibilityRule, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByCampaign(ctx, campaignID)
+}
+
+// SetForCampaign replaces the rules of an enterprise's campaign. They apply
+// to every claim of its red pockets from now on.
+func (s *EligibilityService) SetForCampaign(ctx context.Context, campaignID, enterpriseID string, req *EligibilityRequest) ([]*model.EligibilityRule, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	rules, err := s.build(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		rule.CampaignID = campaignID
+	}
+	if err := s.repo.ReplaceForCampaign(ctx, campaignID, rules); err != nil {
+		return nil, fmt.Errorf("failed to save eligibility rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *EligibilityService) authorizeCampaign(ctx context.Context, campaignID, enterpriseID string) error {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
+
+// setForRedPocket attaches rules built by build to a new red pocket
+func (s *EligibilityService) setForRedPocket(ctx context.Context, redPocketID string, rules []*model.EligibilityRule) error {
+	for _, rule := range rules {
+		rule.RedPocketID = redPocketID
+	}
+	return s.repo.ReplaceForRedPocket(ctx, redPocketID, rules)
+}
+
+// build validates requested rules and turns them into unsaved rules
+func (s *EligibilityService) build(reqs []EligibilityRuleRequest) ([]*model.EligibilityRule, error) {
+	if len(reqs) > maxEligibilityRules {
+		return nil, errInvalidEligibilityRule(fmt.Sprintf("at most %d rules", maxEligibilityRules))
+	}
+
+	now := time.Now()
+	rules := make([]*model.EligibilityRule, 0, len(reqs))
+	for i, req := range reqs {
+		check, ok := s.checks[req.Type]
+		if !ok {
+			return nil, errInvalidEligibilityRule(fmt.Sprintf("rule %d: unknown type %q", i+1, req.Type))
+		}
+		params := req.Params
+		if err := check.validate(&params); err != nil {
+			return nil, errInvalidEligibilityRule(fmt.Sprintf("rule %d: %v", i+1, err))
+		}
+		rules = append(rules, &model.EligibilityRule{
+			ID:        "rule_" + uuid.New().String()[:8],
+			Type:      req.Type,
+			Params:    params,
+			CreatedAt: now,
+		})
+	}
+	return rules, nil
+}
+
+// Check evaluates every rule of a red pocket and its campaign against a
+// claim. Rules of an unknown type fail closed.
+func (s *EligibilityService) Check(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) error {
+	rules, err := s.repo.ListForClaim(ctx, rp.CampaignID, rp.ID, rp.SeriesID)
+	if err != nil {
+		return fmt.Errorf("failed to load eligibility rules: %w", err)
+	}
+	for _, rule := range rules {
+		check, ok := s.checks[rule.Type]
+		if !ok {
+			return fmt.Errorf("unknown eligibility rule type %q", rule.Type)
+		}
+		if err := check.check(ctx, &rule.Params, rp, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Requirements returns the rules a claim of rp must pass for the claim page.
+// Allowlisted accounts are not disclosed; claim windows say whether they
+// are open now and when they next open or close.
+func (s *EligibilityService) Requirements(ctx context.Context, rp *model.RedPocket) []*model.EligibilityRule {
+	rules, err := s.repo.ListForClaim(ctx, rp.CampaignID, rp.ID, rp.SeriesID)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	for _, rule := range rules {
+		rule.Params.Accounts = nil
+		if rule.Type == EligibilityClaimWindow {
+			if w, err := parseClaimWindow(&rule.Params); err == nil {
+				rule.Window = w.state(now)
+			}
+		}
+	}
+	return rules
+}
+
+// allowlistCheck admits only the listed platform accounts
+type allowlistCheck struct{}
+
+func (allowlistCheck) validate(p *model.EligibilityParams) error {
+	if len(p.Accounts) == 0 || len(p.Accounts) > maxAllowlistAccounts {
+		return fmt.Errorf("allowlist needs 1 to %d accounts", maxAllowlistAccounts)
+	}
+	accounts := make([]string, 0, len(p.Accounts))
+	for _, account := range p.Accounts {
+		platform, id, ok := strings.Cut(strings.TrimSpace(account), ":")
+		if !ok || platform == "" || id == "" {
+			return fmt.Errorf("allowlist account %q is not <platform>:<id>", account)
+		}
+		accounts = append(accounts, allowlistKey(platform, id))
+	}
+	*p = model.EligibilityParams{Accounts: accounts}
+	return nil
+}
+
+func (allowlistCheck) check(ctx context.Context, p *model.EligibilityParams, rp *model.RedPocket, req *ClaimRequest) error {
+	key := allowlistKey(req.Platform, req.PlatformID)
+	for _, account := range p.Accounts {
+		if account == key {
+			return nil
+		}
+	}
+	return ErrNotOnAllowlist
+}
+
+func allowlistKey(platform, platformID string) string {
+	return strings.ToLower(platform) + ":" + platformID
+}
+
+// tokenGateCheck requires a wallet holding a minimum balance of an ERC-20
+// or ERC-721 token. The claimer proves the wallet is theirs with the same
+// signature as for holder snapshots.
+type tokenGateCheck struct {
+	walletSvc *WalletService
+}
+
+func (tokenGateCheck) validate(p *model.EligibilityParams) error {
+	if !common.IsHexAddress(p.TokenAddress) || common.HexToAddress(p.TokenAddress) == (common.Address{}) {
+		return errors.New("token gate needs a token address")
+	}
+	minBalance := p.MinBalance
+	if minBalance == "" {
+		minBalance = "1"
+	}
+	if n, ok := new(big.Int).SetString(minBalance, 10); !ok || n.Sign() <= 0 {
+		return errors.New("token gate minimum balance must be a positive integer in minor units")
+	}
+	*p = model.EligibilityParams{TokenAddress: common.HexToAddress(p.TokenAddress).Hex(), MinBalance: minBalance}
+	return nil
+}
+
+func (c tokenGateCheck) check(ctx context.Context, p *model.EligibilityParams, rp *model.RedPocket, req *ClaimRequest) error {
+	address, err := provenWallet(rp, req)
+	if err != nil {
+		return err
+	}
+
+	balance, err := c.walletSvc.TokenBalance(ctx, p.TokenAddress, address)
+	if err != nil {
+		return fmt.Errorf("failed to read token gate balance: %w", err)
+	}
+	minBalance, _ := new(big.Int).SetString(p.MinBalance, 10)
+	if minBalance == nil || balance.Cmp(minBalance) < 0 {
+		return ErrTokenGateNotMet
+	}
+	return nil
+}
+
+// provenWallet returns the wallet the claimer proved is theirs with a
+// personal_sign of AudienceClaimMessage
+func provenWallet(rp *model.RedPocket, req *ClaimRequest) (string, error) {
+	if req.WalletAddress == "" || req.Signature == "" {
+		return "", ErrHolderProofRequired
+	}
+	if !common.IsHexAddress(req.WalletAddress) {
+		return "", ErrAudienceBadSignature
+	}
+	address := normalizeAddress(req.WalletAddress)
+	signer, err := recoverPersonalSigner(AudienceClaimMessage(rp.ID, req.Platform, req.PlatformID), req.Signature)
+	if err != nil || signer != address {
+		return "", ErrAudienceBadSignature
+	}
+	return address, nil
+}
+
+// accountAgeCheck requires a platform account of a minimum age. Discord
+// accounts are dated by their snowflake ID; other platforms do not expose
+// when an account was made, so their age is counted from its first claim.
+type accountAgeCheck struct {
+	claimRepo repository.ClaimStore
+}
+
+func (accountAgeCheck) validate(p *model.EligibilityParams) error {
+	if p.MinAccountAgeDays < 1 || p.MinAccountAgeDays > maxAccountAgeDays {
+		return fmt.Errorf("minimum account age must be 1 to %d days", maxAccountAgeDays)
+	}
+	*p = model.EligibilityParams{MinAccountAgeDays: p.MinAccountAgeDays}
+	return nil
+}
+
+func (c accountAgeCheck) check(ctx context.Context, p *model.EligibilityParams, rp *model.RedPocket, req *ClaimRequest) error {
+	createdAt, err := c.accountCreatedAt(ctx, req.Platform, req.PlatformID)
+	if err != nil {
+		return fmt.Errorf("failed to date account: %w", err)
+	}
+	minAge := time.Duration(p.MinAccountAgeDays) * 24 * time.Hour
+	if createdAt == nil || time.Since(*createdAt) < minAge {
+		return errAccountTooNew(p.MinAccountAgeDays)
+	}
+	return nil
+}
+
+func (c accountAgeCheck) accountCreatedAt(ctx context.Context, platform, platformID string) (*time.Time, error) {
+	if strings.EqualFold(platform, "discord") {
+		snowflake, err := strconv.ParseUint(platformID, 10, 64)
+		if err != nil {
+			return nil, nil
+		}
+		createdAt := time.UnixMilli(int64(snowflake>>22) + discordEpoch)
+		return &createdAt, nil
+	}
+	return c.claimRepo.FirstClaimAt(ctx, platform, platformID)
+}
+
+// geoCheck admits or blocks countries, by the country the CDN resolved the
+// claimer's IP to. Claims from an unknown country fail an allow list.
+type geoCheck struct{}
+
+func (geoCheck) validate(p *model.EligibilityParams) error {
+	if (len(p.AllowCountries) == 0) == (len(p.BlockCountries) == 0) {
+		return errors.New("geo rule needs either allowed or blocked countries")
+	}
+	allow, err := normalizeCountries(p.AllowCountries)
+	if err != nil {
+		return err
+	}
+	block, err := normalizeCountries(p.BlockCountries)
+	if err != nil {
+		return err
+	}
+	*p = model.EligibilityParams{AllowCountries: allow, BlockCountries: block}
+	return nil
+}
+
+func (geoCheck) check(ctx context.Context, p *model.EligibilityParams, rp *model.RedPocket, req *ClaimRequest) error {
+	country := strings.ToUpper(req.Country)
+	if country == "XX" { // Cloudflare's unknown country
+		country = ""
+	}
+	if len(p.AllowCountries) > 0 {
+		if country == "" || !slices.Contains(p.AllowCountries, country) {
+			return ErrRegionNotEligible
+		}
+		return nil
+	}
+	if slices.Contains(p.BlockCountries, country) {
+		return ErrRegionNotEligible
+	}
+	return nil
+}
+
+func normalizeCountries(countries []string) ([]string, error) {
+	if len(countries) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(countries))
+	for _, country := range countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if len(country) != 2 {
+			return nil, fmt.Errorf("country %q is not an ISO 3166-1 alpha-2 code", country)
+		}
+		out = append(out, country)
+	}
+	return out, nil
+}
+
+// claimWindowCheck admits claims only during recurring weekly windows, such
+// as weekdays 9:00 to 18:00 in the campaign's timezone. A window that runs
+// past midnight belongs to the day it starts on.
+type claimWindowCheck struct{}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func (claimWindowCheck) validate(p *model.EligibilityParams) error {
+	if len(p.Days) == 0 && p.StartTime == "" && p.EndTime == "" {
+		return errors.New("claim window needs days or start and end times")
+	}
+	if (p.StartTime == "") != (p.EndTime == "") {
+		return errors.New("claim window needs both a start and an end time")
+	}
+	normalized := model.EligibilityParams{
+		StartTime: p.StartTime,
+		EndTime:   p.EndTime,
+		Timezone:  strings.TrimSpace(p.Timezone),
+	}
+	if normalized.StartTime == "" {
+		// Whole days
+		normalized.StartTime, normalized.EndTime = "00:00", "00:00"
+	}
+	if normalized.Timezone == "" {
+		normalized.Timezone = "UTC"
+	}
+	for _, day := range p.Days {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if len(day) > 3 {
+			day = day[:3]
+		}
+		if !slices.Contains(weekdayNames, day) {
+			return fmt.Errorf("claim window day %q is not a day of the week", day)
+		}
+		if !slices.Contains(normalized.Days, day) {
+			normalized.Days = append(normalized.Days, day)
+		}
+	}
+	if len(normalized.Days) == len(weekdayNames) {
+		normalized.Days = nil
+	}
+	if _, err := parseClaimWindow(&normalized); err != nil {
+		return err
+	}
+	*p = normalized
+	return nil
+}
+
+func (claimWindowCheck) check(ctx context.Context, p *model.EligibilityParams, rp *model.RedPocket, req *ClaimRequest) error {
+	w, err := parseClaimWindow(p)
+	if err != nil {
+		return fmt.Errorf("invalid claim window: %w", err)
+	}
+	state := w.state(time.Now())
+	if state.Open {
+		return nil
+	}
+	if state.OpensAt == nil {
+		return errors.New("claim window has no days")
+	}
+	return errClaimWindowClosed(*state.OpensAt)
+}
+
+// claimWindow is a claim_window rule's params, parsed
+type claimWindow struct {
+	days       [7]bool // by time.Weekday
+	start, end int     // minutes after midnight
+	loc        *time.Location
+}
+
+func parseClaimWindow(p *model.EligibilityParams) (*claimWindow, error) {
+	w := &claimWindow{}
+	var err error
+	if w.start, err = parseClockTime(p.StartTime); err != nil {
+		return nil, err
+	}
+	if w.end, err = parseClockTime(p.EndTime); err != nil {
+		return nil, err
+	}
+	if w.loc, err = time.LoadLocation(p.Timezone); err != nil {
+		return nil, fmt.Errorf("claim window timezone %q is not an IANA timezone", p.Timezone)
+	}
+	for i, name := range weekdayNames {
+		w.days[i] = len(p.Days) == 0 || slices.Contains(p.Days, name)
+	}
+	return w, nil
+}
+
+func parseClockTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("claim window time %q is not HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// state finds the window now falls in, or the next one to open. Windows are
+// laid out from the day before, which may run past midnight into today,
+// until a week ahead.
+func (w *claimWindow) state(now time.Time) *model.ClaimWindowState {
+	local := now.In(w.loc)
+	for offset := -1; offset <= 7; offset++ {
+		// time.Date rather than adding minutes keeps the clock times right
+		// on days the clocks change
+		y, m, d := local.Year(), local.Month(), local.Day()+offset
+		start := time.Date(y, m, d, w.start/60, w.start%60, 0, 0, w.loc)
+		if !w.days[start.Weekday()] {
+			continue
+		}
+		if w.end <= w.start {
+			d++
+		}
+		end := time.Date(y, m, d, w.end/60, w.end%60, 0, 0, w.loc)
+		if !now.Before(start) && now.Before(end) {
+			return &model.ClaimWindowState{Open: true, ClosesAt: &end}
+		}
+		if start.After(now) {
+			return &model.ClaimWindowState{OpensAt: &start}
+		}
+	}
+	return &model.ClaimWindowState{}
+}
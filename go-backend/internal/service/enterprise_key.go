@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrEnterpriseNotFound = newCodedError("enterprise_not_found")
+	ErrAPIKeyExists       = newCodedError("api_key_exists")
+	// ErrInvalidAPIKey is returned for an X-API-Key that is unknown, revoked,
+	// past its rotation grace period or of a suspended enterprise
+	ErrInvalidAPIKey = newCodedError("invalid_api_key")
+)
+
+// EnterpriseKeys manages the one API key an enterprise may send as
+// X-API-Key instead of a bearer token or a request signature. Keys are
+// stored as their SHA-256 hash, so a key is only shown when it is created
+// or rotated.
+type EnterpriseKeys struct {
+	repo        *repository.EnterpriseRepository
+	gracePeriod time.Duration
+}
+
+func NewEnterpriseKeys(repo *repository.EnterpriseRepository, cfg *config.Config) *EnterpriseKeys {
+	return &EnterpriseKeys{
+		repo:        repo,
+		gracePeriod: time.Duration(cfg.EnterpriseAPIKeyGracePeriod) * time.Second,
+	}
+}
+
+// EnterpriseKey is a newly issued key; Key is not shown again
+type EnterpriseKey struct {
+	Key               string     `json:"key"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	PreviousExpiresAt *time.Time `json:"previousExpiresAt,omitempty"` // when the replaced key stops working
+}
+
+// Get returns the enterprise with when its key was created, if it has one
+func (s *EnterpriseKeys) Get(ctx context.Context, enterpriseID string) (*model.Enterprise, error) {
+	e, err := s.repo.GetByID(ctx, enterpriseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEnterpriseNotFound
+	}
+	return e, err
+}
+
+// Create issues the enterprise's key if it has none
+func (s *EnterpriseKeys) Create(ctx context.Context, enterpriseID string) (*EnterpriseKey, error) {
+	key, hash, err := newEnterpriseKey()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	ok, err := s.repo.CreateAPIKey(ctx, enterpriseID, hash, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	if !ok {
+		return nil, s.missingOr(ctx, enterpriseID, ErrAPIKeyExists)
+	}
+	return &EnterpriseKey{Key: key, CreatedAt: now}, nil
+}
+
+// Rotate replaces the enterprise's key. The replaced key keeps working for
+// ENTERPRISE_API_KEY_GRACE_PERIOD.
+func (s *EnterpriseKeys) Rotate(ctx context.Context, enterpriseID string) (*EnterpriseKey, error) {
+	key, hash, err := newEnterpriseKey()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	previousUntil := now.Add(s.gracePeriod)
+	ok, err := s.repo.RotateAPIKey(ctx, enterpriseID, hash, now, previousUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+	if !ok {
+		return nil, s.missingOr(ctx, enterpriseID, ErrAPIKeyNotFound)
+	}
+	return &EnterpriseKey{Key: key, CreatedAt: now, PreviousExpiresAt: &previousUntil}, nil
+}
+
+// Revoke stops the enterprise's key, and a replaced one still in its grace
+// period, from authenticating
+func (s *EnterpriseKeys) Revoke(ctx context.Context, enterpriseID string) error {
+	ok, err := s.repo.RevokeAPIKey(ctx, enterpriseID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if !ok {
+		return s.missingOr(ctx, enterpriseID, ErrAPIKeyNotFound)
+	}
+	return nil
+}
+
+// Authenticate returns the enterprise a key belongs to
+func (s *EnterpriseKeys) Authenticate(ctx context.Context, key string) (*model.Enterprise, error) {
+	e, err := s.repo.GetByAPIKeyHash(ctx, hashEnterpriseKey(key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	return e, nil
+}
+
+// missingOr tells an enterprise that does not exist apart from one whose
+// key was not in the state an update needed
+func (s *EnterpriseKeys) missingOr(ctx context.Context, enterpriseID string, err error) error {
+	if _, getErr := s.repo.GetByID(ctx, enterpriseID); errors.Is(getErr, pgx.ErrNoRows) {
+		return ErrEnterpriseNotFound
+	}
+	return err
+}
+
+func newEnterpriseKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = "ak_" + hex.EncodeToString(b)
+	return key, hashEnterpriseKey(key), nil
+}
+
+func hashEnterpriseKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
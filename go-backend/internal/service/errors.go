@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+)
+
+// CodedError is a user-facing error with a stable code used for localization.
+// Error() returns the English message so logs and existing callers are unchanged.
+type CodedError struct {
+	Code string
+	Args []interface{}
+}
+
+func (e *CodedError) Error() string {
+	return i18n.T(i18n.DefaultLocale, "error."+e.Code, e.Args...)
+}
+
+func newCodedError(code string, args ...interface{}) *CodedError {
+	return &CodedError{Code: code, Args: args}
+}
+
+// ErrorCode returns the code of a CodedError in the chain, or "" for other errors
+func ErrorCode(err error) string {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ""
+}
+
+// LocalizedError renders err in the locale carried by ctx
+func LocalizedError(ctx context.Context, err error) string {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return i18n.Tc(ctx, "error."+coded.Code, coded.Args...)
+	}
+	return err.Error()
+}
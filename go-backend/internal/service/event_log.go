@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const (
+	// How often events past their retention are purged
+	eventLogPurgeInterval = time.Hour
+
+	defaultEventLogLimit = 200
+	maxEventLogLimit     = 1000
+)
+
+var ErrInvalidEventRange = newCodedError("invalid_event_range")
+
+// EventLog serves the event log, which Postgres triggers fill with every
+// change to a pocket, claim, payout job or refund (migration 055). Support
+// reads it back around an incident, and cmd/replay-events replays a range
+// of it against staging to reproduce the incident and check how the
+// payout and refund jobs recover from it.
+type EventLog struct {
+	repo      *repository.EventLogRepository
+	retention time.Duration
+}
+
+func NewEventLog(repo *repository.EventLogRepository, cfg *config.Config) *EventLog {
+	return &EventLog{
+		repo:      repo,
+		retention: time.Duration(cfg.EventLogRetentionDays) * 24 * time.Hour,
+	}
+}
+
+type EventLogQuery struct {
+	From        string // date or RFC 3339 time; default an hour before To
+	To          string // default now
+	RedPocketID string
+	AfterID     int64 // page past the last event of the previous page
+	Limit       int
+}
+
+// List returns recorded events in the order they were recorded
+func (l *EventLog) List(ctx context.Context, q *EventLogQuery) ([]*model.LoggedEvent, error) {
+	to, err := parseReportTime(q.To, true)
+	if err != nil {
+		return nil, ErrInvalidEventRange
+	}
+	from, err := parseReportTime(q.From, false)
+	if err != nil {
+		return nil, ErrInvalidEventRange
+	}
+	f := &model.EventLogFilter{
+		RedPocketID: q.RedPocketID,
+		AfterID:     q.AfterID,
+		Limit:       q.Limit,
+	}
+	f.To = time.Now()
+	if to != nil {
+		f.To = *to
+	}
+	f.From = f.To.Add(-time.Hour)
+	if from != nil {
+		f.From = *from
+	}
+	if !f.From.Before(f.To) {
+		return nil, ErrInvalidEventRange
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultEventLogLimit
+	}
+	f.Limit = min(f.Limit, maxEventLogLimit)
+
+	events, err := l.repo.List(ctx, f)
+	if events == nil && err == nil {
+		events = []*model.LoggedEvent{}
+	}
+	return events, err
+}
+
+// Start purges events past their retention until ctx is cancelled
+func (l *EventLog) Start(ctx context.Context) {
+	if l.retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(eventLogPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := l.repo.DeleteBefore(ctx, time.Now().Add(-l.retention))
+			if err != nil {
+				slog.ErrorContext(ctx, "event log: failed to purge", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "event log: purged past retention", "events", n)
+			}
+		}
+	}
+}
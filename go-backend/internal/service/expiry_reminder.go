@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/bot"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const (
+	expiryReminderInterval   = time.Minute
+	expiryReminderSweepBatch = 50
+)
+
+// expiryReminderLeads are how long before expiry reminders go out, longest
+// first. A pocket already inside a shorter lead only gets that one.
+var expiryReminderLeads = []struct {
+	name string
+	lead time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"1h", time.Hour},
+}
+
+// ExpiryReminders warns enterprises when an open pocket is 24 hours and
+// again 1 hour from expiry with EXPIRY_REMINDER_MIN_REMAINING percent or more
+// of its amount unclaimed, through their pocket.expiring webhooks, so they
+// can extend it. Pockets created with remindChannel also post the reminder
+// in their channel. Each reminder is sent once per expiry, so an extended
+// pocket is reminded again before its new one.
+type ExpiryReminders struct {
+	rpRepo   repository.RedPocketStore
+	webhooks *WebhookService
+	telegram *bot.TelegramBot
+	discord  *bot.DiscordBot
+	slack    *bot.SlackBot
+	links    *ClaimLinks
+	cfg      *config.Config
+}
+
+func NewExpiryReminders(
+	rpRepo repository.RedPocketStore,
+	webhooks *WebhookService,
+	telegram *bot.TelegramBot,
+	discord *bot.DiscordBot,
+	slack *bot.SlackBot,
+	links *ClaimLinks,
+	cfg *config.Config,
+) *ExpiryReminders {
+	return &ExpiryReminders{
+		rpRepo:   rpRepo,
+		webhooks: webhooks,
+		telegram: telegram,
+		discord:  discord,
+		slack:    slack,
+		links:    links,
+		cfg:      cfg,
+	}
+}
+
+// Start sends due reminders until ctx is cancelled
+func (r *ExpiryReminders) Start(ctx context.Context) {
+	ticker := time.NewTicker(expiryReminderInterval)
+	defer ticker.Stop()
+
+	for {
+		r.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ExpiryReminders) sweep(ctx context.Context) {
+	for i, l := range expiryReminderLeads {
+		var after time.Duration
+		if i+1 < len(expiryReminderLeads) {
+			after = expiryReminderLeads[i+1].lead
+		}
+		due, err := r.rpRepo.ExpiringWithin(ctx, l.name, after, l.lead, r.cfg.ExpiryReminderMinRemaining, expiryReminderSweepBatch)
+		if err != nil {
+			slog.ErrorContext(ctx, "expiry reminders: failed to list expiring pockets", "within", l.name, "error", err)
+			continue
+		}
+		for _, rp := range due {
+			// Taken before sending, so a reminder goes out at most once even
+			// when sending fails
+			ok, err := r.rpRepo.MarkReminded(ctx, rp.ID, rp.ExpiresAt, l.name)
+			if err != nil {
+				slog.ErrorContext(ctx, "expiry reminders: failed to mark reminded", "red_pocket_id", rp.ID, "error", err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			r.remind(ctx, rp, l.name)
+		}
+	}
+}
+
+func (r *ExpiryReminders) remind(ctx context.Context, rp *model.RedPocket, lead string) {
+	r.webhooks.Dispatch(ctx, rp.CampaignID, WebhookPocketExpiring, map[string]interface{}{
+		"redPocket": rp,
+		"lead":      lead,
+	})
+	if !rp.RemindChannel || rp.ChannelID == "" {
+		return
+	}
+
+	notifier, err := NewChannelNotifier(r.telegram, r.discord, r.slack, rp.Platform, rp.ChannelID)
+	if err != nil {
+		slog.ErrorContext(ctx, "expiry reminders: failed to remind", "red_pocket_id", rp.ID, "error", err)
+		return
+	}
+	title := i18n.T(rp.Locale, "reminder.title_"+lead)
+	message := i18n.T(rp.Locale, "reminder.body", rp.SenderName, formatDigestAmount(rp.RemainingAmount, rp.Token),
+		rp.TotalCount-rp.ClaimedCount, rp.TotalCount, r.links.URL(rp))
+	if err := notifier.Notify(ctx, title, message); err != nil {
+		slog.ErrorContext(ctx, "expiry reminders: failed to post in channel", "red_pocket_id", rp.ID, "error", err)
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrClaimBlocked          = newCodedError("claim_blocked")
+	ErrAssessmentNotFound    = newCodedError("assessment_not_found")
+	ErrAssessmentReviewed    = newCodedError("assessment_reviewed")
+	ErrPayoutAlreadyReleased = newCodedError("payout_already_released")
+)
+
+// Fraud actions, from mildest to strictest
+const (
+	FraudAllow   = "allow"
+	FraudCaptcha = "captcha"
+	FraudHold    = "hold"
+	FraudBlock   = "block"
+)
+
+// Points each signal adds at its limit; fewer hits add a proportional share
+const (
+	fraudIPPoints       = 35
+	fraudDevicePoints   = 45
+	fraudWalletPoints   = 30
+	fraudVelocityPoints = 30
+
+	// Other claimers on one device, or tied to one external address, that
+	// score the full points. A device is rarely shared, so the bar is low.
+	fraudDeviceLimit = 2
+	fraudWalletLimit = 2
+)
+
+// FraudService scores claims for sybil farming. Each attempt is scored on
+// how many other claimers share its IP and device, how many are tied to it
+// by external addresses, and how many pockets its claimer went for in the
+// last hour. The score picks an action: allow, require a CAPTCHA, hold the
+// payout for review, or block the claim.
+type FraudService struct {
+	repo      *repository.FraudRepository
+	rpRepo    repository.RedPocketStore
+	claimRepo repository.ClaimStore
+	payouts   *PayoutQueue
+	cache     *PocketCache
+	captcha   *CaptchaVerifier
+	cfg       *config.Config
+}
+
+func NewFraudService(
+	repo *repository.FraudRepository,
+	rpRepo repository.RedPocketStore,
+	claimRepo repository.ClaimStore,
+	payouts *PayoutQueue,
+	cache *PocketCache,
+	captcha *CaptchaVerifier,
+	cfg *config.Config,
+) *FraudService {
+	return &FraudService{
+		repo:      repo,
+		rpRepo:    rpRepo,
+		claimRepo: claimRepo,
+		payouts:   payouts,
+		cache:     cache,
+		captcha:   captcha,
+		cfg:       cfg,
+	}
+}
+
+// Enabled is false when every action is turned off, so nothing is scored
+func (s *FraudService) Enabled() bool {
+	return s.cfg.FraudCaptchaScore > 0 || s.cfg.FraudHoldScore > 0 || s.cfg.FraudBlockScore > 0
+}
+
+// CaptchaMode is the CAPTCHA suspicious claimers are challenged with, or
+// empty if no provider is configured
+func (s *FraudService) CaptchaMode() string {
+	if s.cfg.FraudCaptchaMode != "" {
+		if s.captcha.Available(s.cfg.FraudCaptchaMode) {
+			return s.cfg.FraudCaptchaMode
+		}
+		return ""
+	}
+	for _, mode := range []string{CaptchaTurnstile, CaptchaHCaptcha} {
+		if s.captcha.Available(mode) {
+			return mode
+		}
+	}
+	return ""
+}
+
+// Gate scores a claim attempt and enforces its action. Blocked attempts and
+// failed challenges are recorded and returned with a coded error; otherwise
+// the assessment is returned for Record to store with the claim. The result
+// is nil when scoring is off or failed, which lets the claim through.
+func (s *FraudService) Gate(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) (*model.FraudAssessment, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	a, err := s.assess(ctx, rp, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "fraud: failed to score claim", "red_pocket_id", rp.ID, "platform", req.Platform, "platform_id", req.PlatformID, "error", err)
+		return nil, nil
+	}
+
+	switch a.Action {
+	case FraudBlock:
+		s.Record(ctx, a, "")
+		return a, ErrClaimBlocked
+	case FraudCaptcha:
+		if rp.CaptchaMode != "" {
+			// The pocket's own CAPTCHA was solved before the claim got here
+			return a, nil
+		}
+		mode := s.CaptchaMode()
+		if mode == "" {
+			// Nothing to challenge with, so fall back to the next action up
+			a.Action = FraudHold
+			return a, nil
+		}
+		if err := s.captcha.Verify(ctx, mode, req.CaptchaToken, req.ClientIP); err != nil {
+			s.Record(ctx, a, "")
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+// Record stores an assessment, with the claim it let through if any
+func (s *FraudService) Record(ctx context.Context, a *model.FraudAssessment, claimID string) {
+	a.ClaimID = claimID
+	if err := s.repo.Create(ctx, a); err != nil {
+		slog.ErrorContext(ctx, "fraud: failed to record assessment", "assessment_id", a.ID, "error", err)
+	}
+}
+
+// HoldUntil is when a payout held now goes out unless it is rejected
+func (s *FraudService) HoldUntil() time.Time {
+	return time.Now().Add(time.Duration(s.cfg.FraudHoldSeconds) * time.Second)
+}
+
+func (s *FraudService) assess(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) (*model.FraudAssessment, error) {
+	now := time.Now()
+	since := now.Add(-time.Duration(s.cfg.FraudWindow) * time.Second)
+	a := &model.FraudAssessment{
+		ID:                "fraud_" + uuid.New().String()[:8],
+		RedPocketID:       rp.ID,
+		CampaignID:        rp.CampaignID,
+		Platform:          req.Platform,
+		PlatformID:        req.PlatformID,
+		ClientIP:          req.ClientIP,
+		DeviceFingerprint: req.DeviceFingerprint,
+		WalletAddress:     req.WalletAddress,
+		Signals:           []model.FraudSignal{},
+		CreatedAt:         now,
+	}
+
+	if req.ClientIP != "" {
+		n, err := s.repo.ClaimersByIP(ctx, req.ClientIP, req.Platform, req.PlatformID, since)
+		if err != nil {
+			return nil, fmt.Errorf("ip reuse: %w", err)
+		}
+		addFraudSignal(a, "ip_reuse", n, s.cfg.FraudIPLimit, fraudIPPoints)
+	}
+	if req.DeviceFingerprint != "" {
+		n, err := s.repo.ClaimersByDevice(ctx, req.DeviceFingerprint, req.Platform, req.PlatformID, since)
+		if err != nil {
+			return nil, fmt.Errorf("device reuse: %w", err)
+		}
+		addFraudSignal(a, "device_reuse", n, fraudDeviceLimit, fraudDevicePoints)
+	}
+
+	userID := fmt.Sprintf("user_%s_%s", req.Platform, req.PlatformID)
+	n, err := s.repo.LinkedClaimers(ctx, userID, req.WalletAddress)
+	if err != nil {
+		return nil, fmt.Errorf("wallet clustering: %w", err)
+	}
+	addFraudSignal(a, "wallet_cluster", n, fraudWalletLimit, fraudWalletPoints)
+
+	n, err = s.repo.PocketsClaimedSince(ctx, req.Platform, req.PlatformID, now.Add(-time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("velocity: %w", err)
+	}
+	addFraudSignal(a, "velocity", n, s.cfg.FraudVelocityLimit, fraudVelocityPoints)
+
+	if a.Score > 100 {
+		a.Score = 100
+	}
+	a.Action = s.action(a.Score)
+	return a, nil
+}
+
+// action picks the strictest action whose threshold score reaches
+func (s *FraudService) action(score int) string {
+	switch {
+	case s.cfg.FraudBlockScore > 0 && score >= s.cfg.FraudBlockScore:
+		return FraudBlock
+	case s.cfg.FraudHoldScore > 0 && score >= s.cfg.FraudHoldScore:
+		return FraudHold
+	case s.cfg.FraudCaptchaScore > 0 && score >= s.cfg.FraudCaptchaScore:
+		return FraudCaptcha
+	}
+	return FraudAllow
+}
+
+// addFraudSignal adds a signal scoring max points once count reaches limit
+func addFraudSignal(a *model.FraudAssessment, name string, count, limit, max int) {
+	points := 0
+	switch {
+	case count <= 0 || limit <= 0:
+	case count >= limit:
+		points = max
+	default:
+		points = max * count / limit
+	}
+	a.Signals = append(a.Signals, model.FraudSignal{Name: name, Count: count, Points: points})
+	a.Score += points
+}
+
+type ReviewRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve reject"`
+	Note     string `json:"note" binding:"max=500"`
+}
+
+// ListFlagged returns the enterprise's claim attempts that were challenged,
+// held or blocked. status open lists those not reviewed yet.
+func (s *FraudService) ListFlagged(ctx context.Context, enterpriseID, status string, page, limit int) ([]*model.FraudAssessment, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return s.repo.ListFlagged(ctx, enterpriseID, status, limit, offset)
+}
+
+// Review records the enterprise's decision on a flagged attempt. For a held
+// claim, approving pays it out now and rejecting cancels the payout and
+// returns the amount to the pocket.
+func (s *FraudService) Review(ctx context.Context, enterpriseID, id string, req *ReviewRequest) (*model.FraudAssessment, error) {
+	a, err := s.repo.GetByID(ctx, id, enterpriseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAssessmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assessment: %w", err)
+	}
+	if a.ReviewStatus != "" {
+		return nil, ErrAssessmentReviewed
+	}
+
+	a.ReviewStatus = "approved"
+	if req.Decision == "reject" {
+		a.ReviewStatus = "rejected"
+	}
+
+	if a.Action == FraudHold && a.ClaimID != "" {
+		if err := s.settleHold(ctx, a); err != nil {
+			return nil, err
+		}
+	}
+
+	a.ReviewNote = req.Note
+	ok, err := s.repo.Review(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+	if !ok {
+		return nil, ErrAssessmentReviewed
+	}
+	return a, nil
+}
+
+// settleHold releases or cancels the payout of a reviewed held claim
+func (s *FraudService) settleHold(ctx context.Context, a *model.FraudAssessment) error {
+	if a.ReviewStatus == "approved" {
+		// A hold that already ran out has nothing left to release
+		_, err := s.payouts.Release(ctx, a.ClaimID)
+		return err
+	}
+
+	cancelled, err := s.payouts.Cancel(ctx, a.ClaimID, "rejected in fraud review")
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrPayoutAlreadyReleased
+	}
+	claim, err := s.claimRepo.GetByID(ctx, a.ClaimID)
+	if err != nil {
+		return fmt.Errorf("failed to load claim: %w", err)
+	}
+	if err := s.rpRepo.ReturnClaim(ctx, claim); err != nil {
+		slog.ErrorContext(ctx, "fraud: failed to return rejected claim to its pocket", "claim_id", claim.ID, "error", err)
+	}
+	s.cache.Invalidate(ctx, claim.RedPocketID)
+	return nil
+}
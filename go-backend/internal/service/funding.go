@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrSwapUnavailable = newCodedError("swap_unavailable")
+)
+
+// SwapProvider0x converts through the 0x Swap API
+const SwapProvider0x = "0x"
+
+// FundingWalletPrefix prefixes the campaign ID in the owner ID of a
+// campaign's funding wallet
+const FundingWalletPrefix = "campaign_"
+
+// A conversion not recorded as submitted after this was interrupted
+const fundingSubmitTimeout = 10 * time.Minute
+
+// zeroExNativeToken stands for the native token in 0x requests
+const zeroExNativeToken = "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"
+
+// FundingWalletID is the wallet owner ID of a campaign's funding wallet
+func FundingWalletID(campaignID string) string {
+	return FundingWalletPrefix + campaignID
+}
+
+// FundingService gives each campaign a funding wallet for the enterprise to
+// deposit into and keeps it in the campaign's payout token: deposits of any
+// other registered token, stablecoin or native, are swapped on a DEX. The
+// swap's minimum output is capped at SWAP_MAX_SLIPPAGE_BPS below the quote,
+// so a worse price reverts it instead of filling.
+type FundingService struct {
+	repo         *repository.FundingRepository
+	campaignRepo repository.CampaignStore
+	walletSvc    *WalletService
+	tokens       *TokenRegistry
+	httpClient   *http.Client
+	cfg          *config.Config
+}
+
+func NewFundingService(
+	repo *repository.FundingRepository,
+	campaignRepo repository.CampaignStore,
+	walletSvc *WalletService,
+	tokens *TokenRegistry,
+	cfg *config.Config,
+) *FundingService {
+	return &FundingService{
+		repo:         repo,
+		campaignRepo: campaignRepo,
+		walletSvc:    walletSvc,
+		tokens:       tokens,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		cfg: cfg,
+	}
+}
+
+// CampaignFunding is a campaign's funding wallet and its recent conversions
+type CampaignFunding struct {
+	CampaignID  string                     `json:"campaignId"`
+	Address     string                     `json:"address"` // deposit here
+	ChainID     int64                      `json:"chainId"`
+	Token       string                     `json:"token"`             // the payout token the wallet is kept in
+	Balance     *model.Units               `json:"balance,omitempty"` // of Token, in minor units
+	AutoConvert bool                       `json:"autoConvert"`       // other tokens are swapped into Token
+	Conversions []*model.FundingConversion `json:"conversions"`
+}
+
+// Funding returns the funding wallet of one of the enterprise's campaigns,
+// creating it on first use
+func (s *FundingService) Funding(ctx context.Context, enterpriseID, campaignID string) (*CampaignFunding, error) {
+	campaign, err := s.campaign(ctx, enterpriseID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.tokens.Resolve(ctx, campaign.Token, campaign.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := s.walletSvc.GetOrCreate(ctx, FundingWalletID(campaign.ID), campaign.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding wallet: %w", err)
+	}
+
+	if _, err := s.settlePending(ctx, campaign.ID); err != nil {
+		return nil, err
+	}
+	conversions, err := s.repo.ListConversions(ctx, campaign.ID, 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversions: %w", err)
+	}
+
+	funding := &CampaignFunding{
+		CampaignID:  campaign.ID,
+		Address:     wallet.Address,
+		ChainID:     wallet.ChainID,
+		Token:       token.Symbol,
+		AutoConvert: s.cfg.SwapProvider != "",
+		Conversions: conversions,
+	}
+	if !s.walletSvc.Simulated() {
+		balance, err := s.balance(ctx, token, wallet.Address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read balance: %w", err)
+		}
+		units := model.NewUnits(balance)
+		funding.Balance = &units
+	}
+	return funding, nil
+}
+
+// Convert swaps what the campaign's funding wallet holds in other tokens
+// into its payout token now, rather than at the next sweep
+func (s *FundingService) Convert(ctx context.Context, enterpriseID, campaignID string) ([]*model.FundingConversion, error) {
+	if s.cfg.SwapProvider == "" {
+		return nil, ErrSwapUnavailable
+	}
+	campaign, err := s.campaign(ctx, enterpriseID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return s.convert(ctx, campaign)
+}
+
+func (s *FundingService) campaign(ctx context.Context, enterpriseID, campaignID string) (*model.Campaign, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return nil, ErrCampaignNotFound
+	}
+	return campaign, nil
+}
+
+// Start converts the deposits of every funded campaign each
+// FUNDING_SWEEP_INTERVAL until ctx is cancelled
+func (s *FundingService) Start(ctx context.Context) {
+	if s.cfg.SwapProvider == "" || s.walletSvc.Simulated() {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(s.cfg.FundingSweepInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *FundingService) sweep(ctx context.Context) {
+	campaigns, err := s.repo.ListFundedCampaigns(ctx, FundingWalletPrefix)
+	if err != nil {
+		slog.ErrorContext(ctx, "funding: failed to list funded campaigns", "error", err)
+		return
+	}
+	for _, campaign := range campaigns {
+		if _, err := s.convert(ctx, campaign); err != nil {
+			slog.ErrorContext(ctx, "funding: conversion failed", "campaign_id", campaign.ID, "error", err)
+		}
+	}
+}
+
+// settlePending settles conversions whose user operation has landed and
+// returns the tokens still being sold
+func (s *FundingService) settlePending(ctx context.Context, campaignID string) (map[string]bool, error) {
+	pending, err := s.repo.ListPendingConversions(ctx, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending conversions: %w", err)
+	}
+	selling := make(map[string]bool)
+	for _, c := range pending {
+		if c.UserOpHash == "" {
+			// Being submitted, or the process died before recording the
+			// submission. Selling again is safe either way: only what the
+			// wallet still holds is sold.
+			if time.Since(c.CreatedAt) < fundingSubmitTimeout {
+				selling[c.SellToken] = true
+				continue
+			}
+			c.Status = "failed"
+			c.Error = "interrupted before the swap was recorded as submitted"
+		} else {
+			status, txHash, err := s.walletSvc.UserOpOutcome(ctx, c.UserOpHash)
+			if err != nil || status == "pending" {
+				selling[c.SellToken] = true
+				continue
+			}
+			c.Status = status
+			c.TxHash = txHash
+		}
+		if err := s.repo.UpdateConversion(ctx, c); err != nil {
+			return nil, fmt.Errorf("failed to record conversion: %w", err)
+		}
+	}
+	return selling, nil
+}
+
+// convert swaps each registered token the funding wallet holds, other than
+// the payout token, into the payout token. A token with a swap still in
+// flight is left for the next run.
+func (s *FundingService) convert(ctx context.Context, campaign *model.Campaign) ([]*model.FundingConversion, error) {
+	payout, err := s.tokens.Resolve(ctx, campaign.Token, campaign.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	if payout.Native() {
+		// Swaps are priced and bounded in the ERC-20 payout token
+		return nil, ErrNativeTokenUnsupported
+	}
+	wallet, err := s.walletSvc.GetByUserID(ctx, FundingWalletID(campaign.ID), campaign.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("funding wallet not found: %w", err)
+	}
+	selling, err := s.settlePending(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.tokens.List(ctx, campaign.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tokens: %w", err)
+	}
+	minBuy, _ := parseUnits(strconv.FormatFloat(s.cfg.FundingMinConversion, 'f', -1, 64), payout.Decimals)
+
+	conversions := []*model.FundingConversion{}
+	for _, token := range tokens {
+		if !token.Enabled || strings.EqualFold(token.Symbol, payout.Symbol) || selling[token.Symbol] {
+			continue
+		}
+		balance, err := s.balance(ctx, token, wallet.Address)
+		if err != nil {
+			slog.WarnContext(ctx, "funding: failed to read balance", "campaign_id", campaign.ID, "token", token.Symbol, "error", err)
+			continue
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+
+		quote, err := s.quote(ctx, token, payout, balance, wallet.Address)
+		if err != nil {
+			slog.WarnContext(ctx, "funding: failed to quote", "campaign_id", campaign.ID, "token", token.Symbol, "error", err)
+			continue
+		}
+		if minBuy != nil && quote.BuyAmount.Cmp(minBuy) < 0 {
+			continue // dust, not worth the gas
+		}
+
+		c, err := s.swap(ctx, campaign, wallet, token, payout, balance, quote)
+		if err != nil {
+			return conversions, err
+		}
+		conversions = append(conversions, c)
+	}
+	return conversions, nil
+}
+
+func (s *FundingService) balance(ctx context.Context, token *model.Token, owner string) (*big.Int, error) {
+	if token.Native() {
+		return s.walletSvc.NativeBalance(ctx, owner)
+	}
+	return s.walletSvc.TokenBalance(ctx, token.Address, owner)
+}
+
+// swapQuote is a firm DEX quote: the call that executes it and the least it
+// will buy
+type swapQuote struct {
+	To           string
+	Data         string
+	Value        *big.Int
+	BuyAmount    *big.Int
+	MinBuyAmount *big.Int
+}
+
+func (s *FundingService) quote(ctx context.Context, sell, buy *model.Token, amount *big.Int, taker string) (*swapQuote, error) {
+	var quote *swapQuote
+	var err error
+	switch s.cfg.SwapProvider {
+	case SwapProvider0x:
+		quote, err = s.quote0x(ctx, sell, buy, amount, taker)
+	default:
+		return nil, ErrSwapUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider applies the slippage asked for; still refuse a quote
+	// whose floor is lower than that, or that calls a non-contract address
+	floor := new(big.Int).Mul(quote.BuyAmount, big.NewInt(int64(10000-s.cfg.SwapMaxSlippageBps)))
+	floor.Div(floor, big.NewInt(10000))
+	if quote.MinBuyAmount.Sign() <= 0 || quote.MinBuyAmount.Cmp(floor) < 0 {
+		return nil, fmt.Errorf("quote floor %s is below the %d bps slippage limit of %s", quote.MinBuyAmount, s.cfg.SwapMaxSlippageBps, quote.BuyAmount)
+	}
+	if !common.IsHexAddress(quote.To) {
+		return nil, fmt.Errorf("quote has an invalid target %q", quote.To)
+	}
+	if sell.Native() && quote.Value.Cmp(amount) != 0 {
+		return nil, fmt.Errorf("quote sends %s of the native token, want %s", quote.Value, amount)
+	}
+	if !sell.Native() && quote.Value.Sign() != 0 {
+		return nil, fmt.Errorf("quote sends %s of the native token selling %s", quote.Value, sell.Symbol)
+	}
+	return quote, nil
+}
+
+// quote0x asks the 0x Swap API (v2, AllowanceHolder) for a quote. The
+// transaction target is also the spender to approve.
+func (s *FundingService) quote0x(ctx context.Context, sell, buy *model.Token, amount *big.Int, taker string) (*swapQuote, error) {
+	sellToken := sell.Address
+	if sell.Native() {
+		sellToken = zeroExNativeToken
+	}
+	q := url.Values{}
+	q.Set("chainId", strconv.FormatInt(buy.ChainID, 10))
+	q.Set("sellToken", sellToken)
+	q.Set("buyToken", buy.Address)
+	q.Set("sellAmount", amount.String())
+	q.Set("taker", taker)
+	q.Set("slippageBps", strconv.Itoa(s.cfg.SwapMaxSlippageBps))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(s.cfg.SwapAPIURL, "/")+"/swap/allowance-holder/quote?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("0x-api-key", s.cfg.SwapAPIKey)
+	req.Header.Set("0x-version", "v2")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("0x returned %d: %s", resp.StatusCode, body)
+	}
+
+	var result struct {
+		LiquidityAvailable bool   `json:"liquidityAvailable"`
+		BuyAmount          string `json:"buyAmount"`
+		MinBuyAmount       string `json:"minBuyAmount"`
+		Transaction        struct {
+			To    string `json:"to"`
+			Data  string `json:"data"`
+			Value string `json:"value"`
+		} `json:"transaction"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid 0x response: %w", err)
+	}
+	if !result.LiquidityAvailable {
+		return nil, errors.New("no liquidity")
+	}
+
+	quote := &swapQuote{To: result.Transaction.To, Data: result.Transaction.Data}
+	var ok1, ok2, ok3 bool
+	quote.BuyAmount, ok1 = new(big.Int).SetString(result.BuyAmount, 10)
+	quote.MinBuyAmount, ok2 = new(big.Int).SetString(result.MinBuyAmount, 10)
+	quote.Value, ok3 = new(big.Int).SetString(result.Transaction.Value, 10)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, errors.New("invalid 0x amounts")
+	}
+	return quote, nil
+}
+
+// swap records a conversion and executes the quote: an ERC-20 is approved
+// to the quote's target for exactly the amount sold, in the same user
+// operation as the swap
+func (s *FundingService) swap(ctx context.Context, campaign *model.Campaign, wallet *model.Wallet, sell, buy *model.Token, amount *big.Int, quote *swapQuote) (*model.FundingConversion, error) {
+	c := &model.FundingConversion{
+		ID:               "fconv_" + uuid.New().String()[:8],
+		CampaignID:       campaign.ID,
+		ChainID:          campaign.ChainID,
+		WalletAddress:    wallet.Address,
+		SellToken:        sell.Symbol,
+		SellTokenAddress: sell.Address,
+		SellUnits:        model.NewUnits(amount),
+		BuyToken:         buy.Symbol,
+		BuyTokenAddress:  buy.Address,
+		QuotedUnits:      model.NewUnits(quote.BuyAmount),
+		MinBuyUnits:      model.NewUnits(quote.MinBuyAmount),
+		Provider:         s.cfg.SwapProvider,
+		Status:           "pending",
+		CreatedAt:        time.Now(),
+	}
+	if err := s.repo.CreateConversion(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to record conversion: %w", err)
+	}
+
+	var txHash string
+	var err error
+	if sell.Native() {
+		txHash, err = s.walletSvc.ExecuteValue(ctx, wallet, quote.To, quote.Value, quote.Data)
+	} else {
+		txHash, err = s.walletSvc.Execute(ctx, wallet,
+			[]string{sell.Address, quote.To},
+			[]string{BuildERC20ApproveCallData(quote.To, amount), quote.Data},
+		)
+	}
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		c.UserOpHash = pending.UserOpHash
+	case err != nil:
+		c.Status = "failed"
+		c.Error = err.Error()
+	default:
+		c.Status = "success"
+		c.TxHash = txHash
+	}
+	if err := s.repo.UpdateConversion(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to record conversion: %w", err)
+	}
+	if c.Status == "failed" {
+		slog.ErrorContext(ctx, "funding: swap failed", "campaign_id", campaign.ID, "sell_units", c.SellUnits, "token", sell.Symbol, "error", c.Error)
+	}
+	return c, nil
+}
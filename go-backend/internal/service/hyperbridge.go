@@ -2,11 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/metrics"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
 )
 
 // BridgeProtocol represents supported bridge protocols
@@ -18,12 +27,53 @@ const (
 	ProtocolSnowbridge  BridgeProtocol = "snowbridge"
 )
 
-// HyperbridgeService handles Polkadot Hyperbridge operations
+const (
+	bridgePollInterval = 10 * time.Second
+	bridgeBatchSize    = 100
+)
+
+// XCM extrinsics that are not included, or whose transfer does not arrive,
+// within these fail
+const (
+	xcmInclusionTimeout = 2 * time.Minute
+	xcmArrivalTimeout   = 10 * time.Minute
+)
+
+// bridgeStage is a status a transfer holds for a while before moving on
+type bridgeStage struct {
+	status string
+	lasts  time.Duration
+}
+
+// bridgeStages are the steps of each protocol after the source transaction is
+// sent; a transfer completes when it leaves the last one. XCM extrinsics
+// leave theirs when seen on chain, see trackXCMExtrinsic.
+var bridgeStages = map[BridgeProtocol][]bridgeStage{
+	// Inclusion on source, then the message to the destination
+	ProtocolXCM: {
+		{"confirming", 12 * time.Second},
+		{"relaying", 18 * time.Second},
+	},
+	// Confirming on source, then relaying via Hyperbridge
+	ProtocolHyperbridge: {
+		{"confirming", 30 * time.Second},
+		{"relaying", 60 * time.Second},
+	},
+	// Ethereum finality wait, then cross-chain relay
+	ProtocolSnowbridge: {
+		{"confirming", 5 * time.Minute},
+		{"relaying", 10 * time.Minute},
+	},
+}
+
+// HyperbridgeService handles Polkadot Hyperbridge operations. Transfers are
+// kept in Postgres so tracking survives restarts and works across replicas.
 type HyperbridgeService struct {
-	httpClient    *http.Client
-	xcmBridge     *XCMBridge
-	mu            sync.RWMutex
-	transferCache map[string]*BridgeTransferStatus
+	httpClient *http.Client
+	xcmBridge  *XCMBridge
+	repo       *repository.BridgeTransferRepository
+	events     *BridgeEvents
+	wallets    *WalletService
 }
 
 // BridgeTransferStatus tracks cross-chain transfer status
@@ -43,6 +93,12 @@ type BridgeTransferStatus struct {
 	UpdatedAt     time.Time      `json:"updatedAt"`
 	EstimatedTime int            `json:"estimatedTimeSeconds"`
 	Error         string         `json:"error,omitempty"`
+
+	ConsolidationID string `json:"consolidationId,omitempty"`
+
+	// Of XCM extrinsics, see model.BridgeTransfer
+	sourceNonce *int64
+	destBalance string
 }
 
 // MultiChainBalance holds balance info across multiple chains
@@ -70,13 +126,12 @@ type BridgeQuote struct {
 	Reason        string         `json:"reason,omitempty"`
 }
 
-func NewHyperbridgeService(xcmBridge *XCMBridge) *HyperbridgeService {
+func NewHyperbridgeService(xcmBridge *XCMBridge, repo *repository.BridgeTransferRepository, events *BridgeEvents, cfg *config.Config) *HyperbridgeService {
 	return &HyperbridgeService{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		xcmBridge:     xcmBridge,
-		transferCache: make(map[string]*BridgeTransferStatus),
+		httpClient: NewDownstream("hyperbridge", cfg.DownstreamBridge).HTTPClient(),
+		xcmBridge: xcmBridge,
+		repo:      repo,
+		events:    events,
 	}
 }
 
@@ -95,7 +150,7 @@ func (h *HyperbridgeService) GetMultiChainBalances(ctx context.Context, account
 				ChainID:   chainInfo.ChainID,
 				ChainName: chainInfo.Name,
 				Asset:     asset,
-				Decimals:  6, // USDC/USDT decimals
+				Decimals:  h.xcmBridge.AssetDecimals(asset),
 			}
 
 			// Check if asset exists on this chain
@@ -121,6 +176,46 @@ func (h *HyperbridgeService) GetMultiChainBalances(ctx context.Context, account
 	return results
 }
 
+// GetAccountBalances queries, in parallel, the balance of every asset
+// available on each chain for the account held there, in chain then asset
+// order
+func (h *HyperbridgeService) GetAccountBalances(ctx context.Context, accounts map[ChainID]string) []MultiChainBalance {
+	var results []MultiChainBalance
+	var accountOf []string
+	for _, chain := range h.xcmBridge.GetSupportedChains() {
+		account, ok := accounts[chain.ChainID]
+		if !ok {
+			continue
+		}
+		for _, asset := range h.xcmBridge.AssetsOn(chain.ChainID) {
+			results = append(results, MultiChainBalance{
+				ChainID:   chain.ChainID,
+				ChainName: chain.Name,
+				Asset:     asset,
+				Balance:   "0",
+				Decimals:  h.xcmBridge.AssetDecimals(asset),
+			})
+			accountOf = append(accountOf, account)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(result *MultiChainBalance, account string) {
+			defer wg.Done()
+			balance, err := h.xcmBridge.GetAssetBalance(ctx, result.ChainID, result.Asset, account)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.Balance = balance.String()
+		}(&results[i], accountOf[i])
+	}
+	wg.Wait()
+	return results
+}
+
 // GetBridgeQuotes returns quotes from all available bridge protocols
 func (h *HyperbridgeService) GetBridgeQuotes(ctx context.Context, fromChain, toChain ChainID, asset string, amount *big.Int) []BridgeQuote {
 	quotes := make([]BridgeQuote, 0, 3)
@@ -235,8 +330,38 @@ func (h *HyperbridgeService) SelectBestProtocol(fromChain, toChain ChainID) Brid
 	return ProtocolHyperbridge
 }
 
-// InitiateHyperbridgeTransfer starts a transfer via Hyperbridge
+// InitiateHyperbridgeTransfer starts a transfer via Hyperbridge. The sender
+// must be a wallet of the account ctx was authenticated as, by a claimer
+// session, since the transfer is signed with its key.
 func (h *HyperbridgeService) InitiateHyperbridgeTransfer(ctx context.Context, req *CrossChainTransferRequest) (*BridgeTransferStatus, error) {
+	if err := h.checkSender(ctx, req.Sender); err != nil {
+		return nil, err
+	}
+	return h.initiateTransfer(ctx, req)
+}
+
+// checkSender checks that a transfer's sender is a managed wallet of the
+// account ctx was authenticated as
+func (h *HyperbridgeService) checkSender(ctx context.Context, sender string) error {
+	account, ok := accountFrom(ctx)
+	if !ok || h.wallets == nil {
+		return ErrWalletNotOwned
+	}
+	wallet, err := h.wallets.GetByAddress(ctx, sender)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWalletNotOwned
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load wallet: %w", err)
+	}
+	if wallet.UserID != account {
+		return ErrWalletNotOwned
+	}
+	return nil
+}
+
+// initiateTransfer sends a transfer and records it for tracking
+func (h *HyperbridgeService) initiateTransfer(ctx context.Context, req *CrossChainTransferRequest) (*BridgeTransferStatus, error) {
 	protocol := h.SelectBestProtocol(req.FromChain, req.ToChain)
 	bridgeID := fmt.Sprintf("%s_%d_%d_%d", protocol, time.Now().UnixNano(), req.FromChain, req.ToChain)
 
@@ -253,6 +378,8 @@ func (h *HyperbridgeService) InitiateHyperbridgeTransfer(ctx context.Context, re
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 		EstimatedTime: h.getEstimatedTime(protocol),
+
+		ConsolidationID: req.ConsolidationID,
 	}
 
 	// Execute based on protocol
@@ -271,10 +398,17 @@ func (h *HyperbridgeService) InitiateHyperbridgeTransfer(ctx context.Context, re
 		status.Error = err.Error()
 	}
 
-	// Cache the status
-	h.mu.Lock()
-	h.transferCache[bridgeID] = status
-	h.mu.Unlock()
+	transfer := newBridgeTransfer(status)
+	if status.Status != "failed" {
+		nextCheckAt := status.UpdatedAt.Add(h.stageLasts(protocol, status.Status))
+		transfer.NextCheckAt = &nextCheckAt
+	}
+	if createErr := h.repo.Create(ctx, transfer); createErr != nil {
+		slog.ErrorContext(ctx, "bridge: failed to record transfer", "bridge_id", bridgeID, "error", createErr)
+		if err == nil {
+			err = fmt.Errorf("failed to record transfer: %w", createErr)
+		}
+	}
 
 	return status, err
 }
@@ -292,29 +426,93 @@ func (h *HyperbridgeService) getEstimatedTime(protocol BridgeProtocol) int {
 	}
 }
 
+// UseWallets lets transfers from Moonbeam and Astar be sent by the sender's
+// wallet through the chain's XCM precompile. Transfers from the relay chain
+// and Acala are sent by the XCM relayer, for consolidations only, see
+// SendXCMExtrinsic.
+func (h *HyperbridgeService) UseWallets(wallets *WalletService) {
+	h.wallets = wallets
+}
+
 // executeXCMTransfer handles XCM protocol transfers
 func (h *HyperbridgeService) executeXCMTransfer(ctx context.Context, req *CrossChainTransferRequest, status *BridgeTransferStatus) error {
-	// Build XCM v3 message
-	// In production: use substrate-go or polkadot-api
-	
+	if h.wallets != nil && h.xcmBridge.HasXCMPrecompile(req.FromChain) {
+		txHash, err := h.sendPrecompileTransfer(ctx, req)
+		if err != nil {
+			return err
+		}
+		status.Status = "confirming"
+		status.SourceTxHash = txHash
+		return nil
+	}
+
+	if h.xcmBridge.SendsXCMExtrinsics(req.FromChain) && !sandboxFrom(ctx) {
+		return h.sendXCMExtrinsic(ctx, req, status)
+	}
+
+	// Simulation mode - no relayer key to sign with
 	status.Status = "confirming"
 	status.SourceTxHash = fmt.Sprintf("0x%x", time.Now().UnixNano())
-	
-	// Simulate async confirmation
-	go func() {
-		time.Sleep(30 * time.Second)
-		h.mu.Lock()
-		if s, ok := h.transferCache[status.BridgeID]; ok {
-			s.Status = "completed"
-			s.DestTxHash = fmt.Sprintf("0x%x", time.Now().UnixNano())
-			s.UpdatedAt = time.Now()
-		}
-		h.mu.Unlock()
-	}()
 
+	// Confirmation is tracked by Start, see bridgeStages
+	return nil
+}
+
+// sendXCMExtrinsic submits a transfer from the relay chain or Acala, noting
+// the recipient's balance on the destination first so its arrival can be
+// told from it
+func (h *HyperbridgeService) sendXCMExtrinsic(ctx context.Context, req *CrossChainTransferRequest, status *BridgeTransferStatus) error {
+	before, err := h.xcmBridge.GetAssetBalance(ctx, req.ToChain, req.Asset, req.Recipient)
+	if err != nil {
+		return fmt.Errorf("failed to read recipient balance on chain %d: %w", req.ToChain, err)
+	}
+	sent, err := h.xcmBridge.SendXCMExtrinsic(ctx, req)
+	if err != nil {
+		return err
+	}
+	nonce := int64(sent.Nonce)
+	status.Status = "confirming"
+	status.SourceTxHash = sent.TxHash
+	status.sourceNonce = &nonce
+	status.destBalance = before.String()
 	return nil
 }
 
+// sendPrecompileTransfer makes the XCM precompile call of a transfer from
+// the sender's wallet: as a user operation for AA wallets, which the
+// bundler only takes on its own chain, or as a signed transaction for EOAs
+func (h *HyperbridgeService) sendPrecompileTransfer(ctx context.Context, req *CrossChainTransferRequest) (string, error) {
+	call, err := h.xcmBridge.XCMPrecompileCall(req)
+	if err != nil {
+		return "", err
+	}
+	if h.wallets.simulated(ctx) {
+		// Simulation mode - the call is built but not made
+		return fmt.Sprintf("0x%x", time.Now().UnixNano()), nil
+	}
+
+	wallet, err := h.wallets.GetByAddress(ctx, req.Sender)
+	if err != nil {
+		return "", fmt.Errorf("sender %s is not a managed wallet: %w", req.Sender, err)
+	}
+	if wallet.ChainID != int64(req.FromChain) {
+		return "", fmt.Errorf("wallet %s is on chain %d, not %d", wallet.Address, wallet.ChainID, req.FromChain)
+	}
+	if wallet.Type == "eoa" {
+		return h.xcmBridge.SendEOATransaction(ctx, req.FromChain, wallet.PrivateKey, call.To, call.Data)
+	}
+	if wallet.ChainID != h.wallets.cfg.ChainID {
+		return "", fmt.Errorf("no bundler for chain %d", wallet.ChainID)
+	}
+	txHash, err := h.wallets.Execute(ctx, wallet, []string{call.To}, []string{call.Data})
+	var pending *PendingUserOpError
+	if errors.As(err, &pending) {
+		// Sent; the user operation monitor sees it through
+		return pending.UserOpHash, nil
+	}
+	return txHash, err
+}
+
 // executeHyperbridgeTransfer handles Hyperbridge protocol transfers
 func (h *HyperbridgeService) executeHyperbridgeTransfer(ctx context.Context, req *CrossChainTransferRequest, status *BridgeTransferStatus) error {
 	// Hyperbridge uses ISMP (Interoperable State Machine Protocol)
@@ -327,17 +525,7 @@ func (h *HyperbridgeService) executeHyperbridgeTransfer(ctx context.Context, req
 	status.Status = "confirming"
 	status.SourceTxHash = fmt.Sprintf("0x%x", time.Now().UnixNano())
 
-	// Simulate the multi-step process
-	go func() {
-		// Step 1: Confirming on source
-		time.Sleep(30 * time.Second)
-		h.updateStatus(status.BridgeID, "relaying", "")
-
-		// Step 2: Relaying via Hyperbridge
-		time.Sleep(60 * time.Second)
-		h.updateStatus(status.BridgeID, "completed", fmt.Sprintf("0x%x", time.Now().UnixNano()))
-	}()
-
+	// The multi-step process is tracked by Start, see bridgeStages
 	return nil
 }
 
@@ -350,42 +538,285 @@ func (h *HyperbridgeService) executeSnowbridgeTransfer(ctx context.Context, req
 	status.Status = "confirming"
 	status.SourceTxHash = fmt.Sprintf("0x%x", time.Now().UnixNano())
 
-	go func() {
-		// Ethereum finality wait
-		time.Sleep(5 * time.Minute)
-		h.updateStatus(status.BridgeID, "relaying", "")
+	// Finality and relay are tracked by Start, see bridgeStages
+	return nil
+}
+
+// Start advances unfinished transfers until ctx is cancelled. The first pass
+// resumes whatever was in flight when the service last stopped.
+func (h *HyperbridgeService) Start(ctx context.Context) {
+	if n, err := h.repo.CountUnfinished(ctx); err != nil {
+		slog.ErrorContext(ctx, "bridge: failed to count unfinished transfers", "error", err)
+	} else if n > 0 {
+		slog.InfoContext(ctx, "bridge: resuming tracking of unfinished transfers", "transfers", n)
+	}
 
-		// Cross-chain relay
-		time.Sleep(10 * time.Minute)
-		h.updateStatus(status.BridgeID, "completed", fmt.Sprintf("0x%x", time.Now().UnixNano()))
-	}()
+	ticker := time.NewTicker(bridgePollInterval)
+	defer ticker.Stop()
 
-	return nil
+	for {
+		h.advanceDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
-func (h *HyperbridgeService) updateStatus(bridgeID, status, destTxHash string) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+func (h *HyperbridgeService) advanceDue(ctx context.Context) {
+	transfers, err := h.repo.ListDue(ctx, bridgeBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "bridge: failed to list due transfers", "error", err)
+		return
+	}
+	for _, t := range transfers {
+		if err := h.advance(ctx, t); err != nil {
+			slog.ErrorContext(ctx, "bridge: failed to advance transfer", "bridge_id", t.ID, "error", err)
+		}
+	}
+}
+
+// advance moves a transfer to its next stage, or completes it after the last
+func (h *HyperbridgeService) advance(ctx context.Context, t *model.BridgeTransfer) error {
+	if t.SourceNonce != nil {
+		return h.trackXCMExtrinsic(ctx, t)
+	}
+	next, ok := h.nextStage(BridgeProtocol(t.Protocol), t.Status)
+	if !ok {
+		return h.move(ctx, t, "failed", "", "unknown bridge stage "+t.Protocol+"/"+t.Status)
+	}
+	if next == "completed" {
+		return h.move(ctx, t, next, fmt.Sprintf("0x%x", time.Now().UnixNano()), "")
+	}
+	return h.move(ctx, t, next, "", "")
+}
 
-	if s, ok := h.transferCache[bridgeID]; ok {
-		s.Status = status
-		s.UpdatedAt = time.Now()
-		if destTxHash != "" {
-			s.DestTxHash = destTxHash
+// trackXCMExtrinsic moves an XCM extrinsic on once it is seen on chain: to
+// relaying when the relayer's nonce passes the one it used, then to
+// completed when the recipient's balance on the destination rises above
+// what it was when sent. Otherwise it is checked again, until it times out.
+func (h *HyperbridgeService) trackXCMExtrinsic(ctx context.Context, t *model.BridgeTransfer) error {
+	switch t.Status {
+	case "confirming":
+		nonce, err := h.xcmBridge.RelayerNonce(ctx, ChainID(t.FromChain))
+		if err != nil {
+			return h.recheck(ctx, t, fmt.Errorf("failed to get relayer nonce: %w", err))
+		}
+		if nonce > uint64(*t.SourceNonce) {
+			return h.move(ctx, t, "relaying", "", "")
 		}
+		if time.Since(t.UpdatedAt) > xcmInclusionTimeout {
+			return h.move(ctx, t, "failed", "", "source extrinsic was not included")
+		}
+	case "relaying":
+		balance, err := h.xcmBridge.GetAssetBalance(ctx, ChainID(t.ToChain), t.Asset, t.Recipient)
+		if err != nil {
+			return h.recheck(ctx, t, fmt.Errorf("failed to read recipient balance: %w", err))
+		}
+		before, _ := new(big.Int).SetString(t.DestBalance, 10)
+		if before != nil && balance.Cmp(before) > 0 {
+			return h.move(ctx, t, "completed", "", "")
+		}
+		if time.Since(t.UpdatedAt) > xcmArrivalTimeout {
+			return h.move(ctx, t, "failed", "", fmt.Sprintf("transfer did not arrive on chain %d", t.ToChain))
+		}
+	default:
+		return h.move(ctx, t, "failed", "", "unknown bridge stage "+t.Protocol+"/"+t.Status)
+	}
+	return h.recheck(ctx, t, nil)
+}
+
+// recheck checks a transfer again after the poll interval, returning cause
+func (h *HyperbridgeService) recheck(ctx context.Context, t *model.BridgeTransfer, cause error) error {
+	if err := h.repo.Reschedule(ctx, t.ID, t.Status, time.Now().Add(bridgePollInterval)); err != nil {
+		return err
+	}
+	return cause
+}
+
+// move advances a transfer from its status to next, scheduling its next
+// check unless next is final, and announces the move
+func (h *HyperbridgeService) move(ctx context.Context, t *model.BridgeTransfer, next, destTxHash, errMsg string) error {
+	moved := *t
+	moved.Status = next
+	moved.UpdatedAt = time.Now()
+	moved.NextCheckAt = nil
+	if destTxHash != "" {
+		moved.DestTxHash = destTxHash
+	}
+	if errMsg != "" {
+		moved.Error = errMsg
+	}
+	final := next == "completed" || next == "failed"
+	if !final {
+		nextCheckAt := time.Now().Add(h.stageLasts(BridgeProtocol(t.Protocol), next))
+		moved.NextCheckAt = &nextCheckAt
+	}
+	advanced, err := h.repo.Advance(ctx, t.ID, t.Status, next, destTxHash, errMsg, moved.NextCheckAt)
+	if advanced {
+		if final {
+			metrics.BridgeTransferDuration.Observe(time.Since(t.CreatedAt).Seconds(), t.Protocol, next)
+		}
+		h.announce(ctx, &moved, t.Status)
+	}
+	return err
+}
+
+// announce pushes a transfer's move from previous to its current status to
+// the transfer's streams
+func (h *HyperbridgeService) announce(ctx context.Context, t *model.BridgeTransfer, previous string) {
+	h.events.publish(ctx, newBridgeEvent("status", t, previous))
+}
+
+func newBridgeEvent(kind string, t *model.BridgeTransfer, previous string) *BridgeEvent {
+	step, steps := bridgeProgress(BridgeProtocol(t.Protocol), t.Status, previous)
+	return &BridgeEvent{
+		Type:           kind,
+		Transfer:       bridgeTransferStatus(t),
+		PreviousStatus: previous,
+		Step:           step,
+		Steps:          steps,
+		NextCheckAt:    t.NextCheckAt,
+		At:             time.Now(),
+	}
+}
+
+// bridgeProgress returns how many of a protocol's steps a transfer in status
+// has reached: one per stage, and one for completing. A failed transfer
+// keeps the step of the status it failed from.
+func bridgeProgress(protocol BridgeProtocol, status, previous string) (int, int) {
+	stages := bridgeStages[protocol]
+	steps := len(stages) + 1
+	switch status {
+	case "completed":
+		return steps, steps
+	case "failed":
+		if previous != "" && previous != "failed" {
+			return bridgeProgress(protocol, previous, "")
+		}
+		return 0, steps
 	}
+	for i, stage := range stages {
+		if stage.status == status {
+			return i + 1, steps
+		}
+	}
+	return 0, steps
+}
+
+// nextStage returns the status that follows status. Pending transfers have
+// not reached the first stage yet.
+func (h *HyperbridgeService) nextStage(protocol BridgeProtocol, status string) (string, bool) {
+	stages := bridgeStages[protocol]
+	if len(stages) == 0 {
+		return "", false
+	}
+	if status == "pending" {
+		return stages[0].status, true
+	}
+	for i, stage := range stages {
+		if stage.status != status {
+			continue
+		}
+		if i+1 < len(stages) {
+			return stages[i+1].status, true
+		}
+		return "completed", true
+	}
+	return "", false
+}
+
+// stageLasts returns how long a transfer stays in status before it is advanced
+func (h *HyperbridgeService) stageLasts(protocol BridgeProtocol, status string) time.Duration {
+	for _, stage := range bridgeStages[protocol] {
+		if stage.status == status {
+			return stage.lasts
+		}
+	}
+	return 0
 }
 
 // GetTransferStatus returns the current status of a transfer
-func (h *HyperbridgeService) GetTransferStatus(bridgeID string) (*BridgeTransferStatus, error) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+func (h *HyperbridgeService) GetTransferStatus(ctx context.Context, bridgeID string) (*BridgeTransferStatus, error) {
+	t, err := h.getTransfer(ctx, bridgeID)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeTransferStatus(t), nil
+}
 
-	status, ok := h.transferCache[bridgeID]
-	if !ok {
+// TransferSnapshot describes the current state of a transfer for its stream
+func (h *HyperbridgeService) TransferSnapshot(ctx context.Context, bridgeID string) (*BridgeEvent, error) {
+	t, err := h.getTransfer(ctx, bridgeID)
+	if err != nil {
+		return nil, err
+	}
+	return newBridgeEvent("snapshot", t, ""), nil
+}
+
+// SubscribeTransfer streams the status changes of a transfer as the bridge
+// job advances it, until ctx is cancelled or the returned close function is
+// called
+func (h *HyperbridgeService) SubscribeTransfer(ctx context.Context, bridgeID string) (<-chan *BridgeEvent, func(), error) {
+	return h.events.Subscribe(ctx, bridgeID)
+}
+
+func (h *HyperbridgeService) getTransfer(ctx context.Context, bridgeID string) (*model.BridgeTransfer, error) {
+	t, err := h.repo.GetByID(ctx, bridgeID)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("transfer not found: %s", bridgeID)
 	}
-	return status, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transfer: %w", err)
+	}
+	return t, nil
+}
+
+func newBridgeTransfer(s *BridgeTransferStatus) *model.BridgeTransfer {
+	return &model.BridgeTransfer{
+		ID:            s.BridgeID,
+		Protocol:      string(s.Protocol),
+		FromChain:     int64(s.FromChain),
+		ToChain:       int64(s.ToChain),
+		Asset:         s.Asset,
+		Amount:        s.Amount,
+		Sender:        s.Sender,
+		Recipient:     s.Recipient,
+		SourceTxHash:  s.SourceTxHash,
+		DestTxHash:    s.DestTxHash,
+		Status:        s.Status,
+		EstimatedTime: s.EstimatedTime,
+		Error:         s.Error,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+
+		ConsolidationID: s.ConsolidationID,
+		SourceNonce:     s.sourceNonce,
+		DestBalance:     s.destBalance,
+	}
+}
+
+func bridgeTransferStatus(t *model.BridgeTransfer) *BridgeTransferStatus {
+	return &BridgeTransferStatus{
+		BridgeID:      t.ID,
+		Protocol:      BridgeProtocol(t.Protocol),
+		FromChain:     ChainID(t.FromChain),
+		ToChain:       ChainID(t.ToChain),
+		Asset:         t.Asset,
+		Amount:        t.Amount,
+		Sender:        t.Sender,
+		Recipient:     t.Recipient,
+		SourceTxHash:  t.SourceTxHash,
+		DestTxHash:    t.DestTxHash,
+		Status:        t.Status,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+		EstimatedTime: t.EstimatedTime,
+		Error:         t.Error,
+
+		ConsolidationID: t.ConsolidationID,
+	}
 }
 
 // FindBestSourceChain finds the chain with highest balance for an asset
@@ -423,8 +854,14 @@ func (h *HyperbridgeService) FindBestSourceChain(ctx context.Context, account, a
 	return best, nil
 }
 
-// AutoBridge automatically bridges assets from best source to target chain
+// AutoBridge automatically bridges assets from best source to target chain.
+// The account must be a wallet of the account ctx was authenticated as, see
+// InitiateHyperbridgeTransfer.
 func (h *HyperbridgeService) AutoBridge(ctx context.Context, account, asset string, amount *big.Int, targetChain ChainID) (*BridgeTransferStatus, error) {
+	if err := h.checkSender(ctx, account); err != nil {
+		return nil, err
+	}
+
 	// Find best source chain
 	source, err := h.FindBestSourceChain(ctx, account, asset, amount)
 	if err != nil {
@@ -441,7 +878,7 @@ func (h *HyperbridgeService) AutoBridge(ctx context.Context, account, asset stri
 	}
 
 	// Initiate bridge
-	return h.InitiateHyperbridgeTransfer(ctx, &CrossChainTransferRequest{
+	return h.initiateTransfer(ctx, &CrossChainTransferRequest{
 		FromChain: source.ChainID,
 		ToChain:   targetChain,
 		Asset:     asset,
@@ -450,3 +887,124 @@ func (h *HyperbridgeService) AutoBridge(ctx context.Context, account, asset stri
 		Recipient: account,
 	})
 }
+
+// Consolidation is a consolidation job with the status of all its transfers:
+// in_progress while any is unfinished, then completed, failed, or partial
+// when some completed and some failed
+type Consolidation struct {
+	*model.BridgeConsolidation
+	Status    string                  `json:"status"`
+	Amount    string                  `json:"amount"` // base units, summed over Transfers
+	Transfers []*BridgeTransferStatus `json:"transfers"`
+}
+
+// Consolidate sweeps an asset to recipient on the target chain from every
+// other chain where the account held there has a balance of it, as AutoBridge
+// does for one chain, tracking the transfers as one consolidation. Returns
+// nil when no other chain holds any of the asset. The accounts are the
+// user's own wallets, see ConsolidationService.Consolidate.
+func (h *HyperbridgeService) Consolidate(ctx context.Context, userID, asset string, accounts map[ChainID]string, targetChain ChainID, recipient string) (*Consolidation, error) {
+	sources := make(map[ChainID]string, len(accounts))
+	for chainID, account := range accounts {
+		if chainID == targetChain {
+			continue
+		}
+		if _, err := h.xcmBridge.GetAssetAddress(asset, chainID); err == nil {
+			sources[chainID] = account
+		}
+	}
+
+	var funded []MultiChainBalance
+	for _, b := range h.GetAccountBalances(ctx, sources) {
+		balance, ok := new(big.Int).SetString(b.Balance, 10)
+		if b.Asset == asset && b.Error == "" && ok && balance.Sign() > 0 {
+			funded = append(funded, b)
+		}
+	}
+	if len(funded) == 0 {
+		return nil, nil
+	}
+
+	c := &model.BridgeConsolidation{
+		ID:        "consol_" + uuid.New().String()[:8],
+		UserID:    userID,
+		Asset:     asset,
+		ToChain:   int64(targetChain),
+		Recipient: recipient,
+		CreatedAt: time.Now(),
+	}
+	if err := h.repo.CreateConsolidation(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to record consolidation: %w", err)
+	}
+
+	transfers := make([]*BridgeTransferStatus, 0, len(funded))
+	for _, b := range funded {
+		amount, _ := new(big.Int).SetString(b.Balance, 10)
+		status, err := h.initiateTransfer(ctx, &CrossChainTransferRequest{
+			FromChain:       b.ChainID,
+			ToChain:         targetChain,
+			Asset:           asset,
+			Amount:          amount,
+			Sender:          sources[b.ChainID],
+			Recipient:       recipient,
+			ConsolidationID: c.ID,
+			relayed:         true,
+		})
+		if err != nil {
+			// Failed transfers are recorded as such, and the others still go
+			slog.ErrorContext(ctx, "bridge: consolidation transfer failed", "consolidation_id", c.ID, "chain_id", b.ChainID, "error", err)
+		}
+		transfers = append(transfers, status)
+	}
+	return newConsolidation(c, transfers), nil
+}
+
+// GetConsolidation returns a consolidation with the current status of its
+// transfers
+func (h *HyperbridgeService) GetConsolidation(ctx context.Context, id string) (*Consolidation, error) {
+	c, transfers, err := h.repo.GetConsolidation(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrConsolidationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consolidation: %w", err)
+	}
+	statuses := make([]*BridgeTransferStatus, 0, len(transfers))
+	for _, t := range transfers {
+		statuses = append(statuses, bridgeTransferStatus(t))
+	}
+	return newConsolidation(c, statuses), nil
+}
+
+func newConsolidation(c *model.BridgeConsolidation, transfers []*BridgeTransferStatus) *Consolidation {
+	amount := new(big.Int)
+	var completed, failed int
+	for _, t := range transfers {
+		if a, ok := new(big.Int).SetString(t.Amount, 10); ok {
+			amount.Add(amount, a)
+		}
+		switch t.Status {
+		case "completed":
+			completed++
+		case "failed":
+			failed++
+		}
+	}
+
+	status := "in_progress"
+	switch {
+	case completed+failed < len(transfers):
+	case failed == 0:
+		status = "completed"
+	case completed == 0:
+		status = "failed"
+	default:
+		status = "partial"
+	}
+	return &Consolidation{
+		BridgeConsolidation: c,
+		Status:              status,
+		Amount:              amount.String(),
+		Transfers:           transfers,
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var ErrIPFSDisabled = errors.New("ipfs pinning is not configured")
+
+// Pin kinds
+const (
+	PinKindCoverImage    = "cover_image"
+	PinKindNFTMetadata   = "nft_metadata"
+	PinKindPocketSummary = "pocket_summary"
+)
+
+// PinProvider is a remote IPFS pinning service
+type PinProvider interface {
+	Name() string
+	// PinFile uploads and pins raw content, returning its CID and pinned size
+	PinFile(ctx context.Context, name string, data []byte) (cid string, size int64, err error)
+	// PinStatus reports whether the provider still holds the pin: pinned, pinning, unpinned
+	PinStatus(ctx context.Context, cid string) (string, error)
+}
+
+// IPFSService pins media and snapshots and tracks their pin status
+type IPFSService struct {
+	provider   PinProvider
+	repo       *repository.IPFSPinRepository
+	gatewayURL string
+}
+
+func NewIPFSService(repo *repository.IPFSPinRepository, cfg *config.Config) *IPFSService {
+	var provider PinProvider
+	if cfg.PinataJWT != "" {
+		provider = NewPinataProvider(cfg.PinataAPIURL, cfg.PinataJWT)
+	}
+	return &IPFSService{
+		provider:   provider,
+		repo:       repo,
+		gatewayURL: strings.TrimRight(cfg.IPFSGatewayURL, "/"),
+	}
+}
+
+func (s *IPFSService) Enabled() bool {
+	return s.provider != nil
+}
+
+// GatewayURL returns the HTTP gateway URL for a CID
+func (s *IPFSService) GatewayURL(cid string) string {
+	if cid == "" {
+		return ""
+	}
+	return s.gatewayURL + "/ipfs/" + cid
+}
+
+// Pin uploads data to the pinning provider and records the pin. Failed pins are
+// recorded too so they show up in status tracking.
+func (s *IPFSService) Pin(ctx context.Context, kind, refID, name string, data []byte) (*model.IPFSPin, error) {
+	if !s.Enabled() {
+		return nil, ErrIPFSDisabled
+	}
+
+	pin := &model.IPFSPin{
+		ID:        "pin_" + uuid.New().String()[:8],
+		Kind:      kind,
+		RefID:     refID,
+		Name:      name,
+		Provider:  s.provider.Name(),
+		Size:      int64(len(data)),
+		Status:    "pinning",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, pin); err != nil {
+		return nil, fmt.Errorf("failed to record pin: %w", err)
+	}
+
+	cid, size, err := s.provider.PinFile(ctx, name, data)
+	if err != nil {
+		pin.Status = "failed"
+		pin.Error = err.Error()
+		s.repo.UpdateStatus(ctx, pin.ID, pin.Status, "", 0, pin.Error)
+		return pin, fmt.Errorf("failed to pin %s: %w", name, err)
+	}
+
+	pin.CID = cid
+	pin.Status = "pinned"
+	if size > 0 {
+		pin.Size = size
+	}
+	pin.GatewayURL = s.GatewayURL(cid)
+	if err := s.repo.UpdateStatus(ctx, pin.ID, pin.Status, pin.CID, pin.Size, ""); err != nil {
+		return nil, fmt.Errorf("failed to update pin: %w", err)
+	}
+	return pin, nil
+}
+
+// PinJSON marshals v and pins it as a JSON document
+func (s *IPFSService) PinJSON(ctx context.Context, kind, refID, name string, v interface{}) (*model.IPFSPin, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return s.Pin(ctx, kind, refID, name, data)
+}
+
+func (s *IPFSService) Get(ctx context.Context, id string) (*model.IPFSPin, error) {
+	pin, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pin.GatewayURL = s.GatewayURL(pin.CID)
+	return pin, nil
+}
+
+func (s *IPFSService) ListByRef(ctx context.Context, kind, refID string) ([]*model.IPFSPin, error) {
+	pins, err := s.repo.ListByRef(ctx, kind, refID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pins {
+		p.GatewayURL = s.GatewayURL(p.CID)
+	}
+	return pins, nil
+}
+
+// Refresh re-checks a pin with the provider and stores any status change
+func (s *IPFSService) Refresh(ctx context.Context, id string) (*model.IPFSPin, error) {
+	pin, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Enabled() || pin.CID == "" {
+		return pin, nil
+	}
+
+	status, err := s.provider.PinStatus(ctx, pin.CID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pin status: %w", err)
+	}
+	if status != pin.Status {
+		if err := s.repo.UpdateStatus(ctx, pin.ID, status, "", 0, ""); err != nil {
+			return nil, err
+		}
+		pin.Status = status
+	}
+	return pin, nil
+}
+
+// PinataProvider pins content through the Pinata API
+type PinataProvider struct {
+	apiURL     string
+	jwt        string
+	httpClient *http.Client
+}
+
+func NewPinataProvider(apiURL, jwt string) *PinataProvider {
+	return &PinataProvider{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		jwt:    jwt,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+func (p *PinataProvider) Name() string {
+	return "pinata"
+}
+
+func (p *PinataProvider) PinFile(ctx context.Context, name string, data []byte) (string, int64, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", 0, err
+	}
+	metadata, _ := json.Marshal(map[string]string{"name": name})
+	if err := w.WriteField("pinataMetadata", string(metadata)); err != nil {
+		return "", 0, err
+	}
+	if err := w.Close(); err != nil {
+		return "", 0, err
+	}
+
+	var result struct {
+		IpfsHash string `json:"IpfsHash"`
+		PinSize  int64  `json:"PinSize"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/pinning/pinFileToIPFS", w.FormDataContentType(), &body, &result); err != nil {
+		return "", 0, err
+	}
+	if result.IpfsHash == "" {
+		return "", 0, fmt.Errorf("pinata returned no CID")
+	}
+	return result.IpfsHash, result.PinSize, nil
+}
+
+func (p *PinataProvider) PinStatus(ctx context.Context, cid string) (string, error) {
+	path := "/data/pinList?status=pinned&hashContains=" + url.QueryEscape(cid)
+
+	var result struct {
+		Count int `json:"count"`
+	}
+	if err := p.do(ctx, http.MethodGet, path, "", nil, &result); err != nil {
+		return "", err
+	}
+	if result.Count > 0 {
+		return "pinned", nil
+	}
+	return "unpinned", nil
+}
+
+func (p *PinataProvider) do(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.jwt)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pinata error %d: %s", resp.StatusCode, string(respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
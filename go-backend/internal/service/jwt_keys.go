@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// How often replicas reload the signing keys, and the rotation job checks
+// whether the current one is due for rotation
+const jwtKeyRefreshInterval = time.Minute
+
+var (
+	// ErrAPIKeyRequired is returned when a token is requested with a token
+	// rather than an API key signature, which would let a token renew itself
+	ErrAPIKeyRequired = newCodedError("api_key_required")
+	// ErrNoSigningKey is returned before the first signing key was created
+	ErrNoSigningKey = errors.New("no token signing key yet, try again shortly")
+)
+
+// JWTKeys signs enterprise tokens and verifies them. Tokens are ES256 JWTs
+// whose kid names the signing key. Keys are rotated by the leader every
+// JWT_KEY_ROTATION_INTERVAL and shared through the database; a replaced key
+// keeps verifying for JWT_KEY_GRACE_PERIOD so tokens it signed live out
+// their TTL. Public keys are published as a JWKS for other services.
+//
+// Tokens signed with the shared JWT_SECRET (HS256) are accepted as well
+// while JWT_SHARED_SECRET_ENABLED is on, for issuers not yet moved to the
+// token endpoint.
+type JWTKeys struct {
+	repo         *repository.JWTKeyRepository
+	cfg          *config.Config
+	sharedSecret func() string
+	ttl          time.Duration
+	claimerTTL   time.Duration
+	interval     time.Duration
+	grace        time.Duration
+
+	mu        sync.RWMutex
+	signing   *tokenKey
+	verifying map[string]*tokenKey
+}
+
+type tokenKey struct {
+	kid       string
+	createdAt time.Time
+	private   *ecdsa.PrivateKey
+}
+
+// JWK is a public key of the JWKS
+type JWK struct {
+	KTY string `json:"kty"`
+	CRV string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	KID string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKS is the key set published at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func NewJWTKeys(repo *repository.JWTKeyRepository, cfg *config.Config, sharedSecret func() string) *JWTKeys {
+	ttl := time.Duration(cfg.JWTTokenTTL) * time.Second
+	claimerTTL := time.Duration(cfg.ClaimerSessionTTL) * time.Second
+	grace := time.Duration(cfg.JWTKeyGracePeriod) * time.Second
+	if grace < ttl {
+		grace = ttl
+	}
+	if grace < claimerTTL {
+		grace = claimerTTL
+	}
+	return &JWTKeys{
+		repo:         repo,
+		cfg:          cfg,
+		sharedSecret: sharedSecret,
+		ttl:          ttl,
+		claimerTTL:   claimerTTL,
+		interval:     time.Duration(cfg.JWTKeyRotationInterval) * time.Second,
+		grace:        grace,
+		verifying:    make(map[string]*tokenKey),
+	}
+}
+
+// Start reloads the keys until ctx is cancelled, so keys rotated by the
+// leader sign and verify on every replica
+func (k *JWTKeys) Start(ctx context.Context) {
+	ticker := time.NewTicker(jwtKeyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := k.Load(ctx); err != nil {
+			slog.ErrorContext(ctx, "jwt keys: failed to load", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StartRotation creates the first signing key and rotates it when it is due,
+// until ctx is cancelled. It runs on the leader only.
+func (k *JWTKeys) StartRotation(ctx context.Context) {
+	ticker := time.NewTicker(jwtKeyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := k.rotateIfDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "jwt keys: failed to rotate", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (k *JWTKeys) rotateIfDue(ctx context.Context) error {
+	if err := k.Load(ctx); err != nil {
+		return err
+	}
+	k.mu.RLock()
+	signing := k.signing
+	k.mu.RUnlock()
+	if signing != nil && time.Since(signing.createdAt) < k.interval {
+		return nil
+	}
+	return k.Rotate(ctx)
+}
+
+// Rotate creates a new signing key. The current one keeps verifying for the
+// grace period.
+func (k *JWTKeys) Rotate(ctx context.Context) error {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	key := &model.JWTSigningKey{
+		KID:        "jwk_" + uuid.New().String()[:8],
+		Algorithm:  jwt.SigningMethodES256.Alg(),
+		PrivateKey: base64.StdEncoding.EncodeToString(der),
+		CreatedAt:  now,
+	}
+	retireAt := now.Add(k.grace)
+	if err := k.repo.Rotate(ctx, key, retireAt, now.Add(-k.grace)); err != nil {
+		return fmt.Errorf("failed to store signing key: %w", err)
+	}
+	slog.InfoContext(ctx, "jwt keys: rotated", "kid", key.KID, "retire_at", retireAt.Format(time.RFC3339))
+	return k.Load(ctx)
+}
+
+// Load reads the keys that have not retired
+func (k *JWTKeys) Load(ctx context.Context) error {
+	keys, err := k.repo.ListUsable(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var signing *tokenKey
+	verifying := make(map[string]*tokenKey, len(keys))
+	for _, stored := range keys {
+		tk, err := parseTokenKey(stored)
+		if err != nil {
+			slog.WarnContext(ctx, "jwt keys: skipping key", "kid", stored.KID, "error", err)
+			continue
+		}
+		verifying[tk.kid] = tk
+		// Keys are newest first; the newest unretired one signs
+		if signing == nil && stored.RetiresAt == nil {
+			signing = tk
+		}
+	}
+
+	k.mu.Lock()
+	k.signing = signing
+	k.verifying = verifying
+	k.mu.Unlock()
+	return nil
+}
+
+func parseTokenKey(stored *model.JWTSigningKey) (*tokenKey, error) {
+	if stored.Algorithm != jwt.SigningMethodES256.Alg() {
+		return nil, fmt.Errorf("unsupported algorithm %s", stored.Algorithm)
+	}
+	der, err := base64.StdEncoding.DecodeString(stored.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an ECDSA key")
+	}
+	return &tokenKey{kid: stored.KID, createdAt: stored.CreatedAt, private: private}, nil
+}
+
+// ClaimerAudience is the audience of claimer session tokens, which
+// enterprise routes refuse
+const ClaimerAudience = "claimer"
+
+// Issue signs a token for an enterprise with the current key
+func (k *JWTKeys) Issue(enterpriseID string) (string, time.Time, error) {
+	return k.issue(enterpriseID, nil, k.ttl)
+}
+
+// IssueClaimer signs a claimer session token for a user, see
+// ClaimerSessions
+func (k *JWTKeys) IssueClaimer(userID string) (string, time.Time, error) {
+	return k.issue(userID, jwt.ClaimStrings{ClaimerAudience}, k.claimerTTL)
+}
+
+func (k *JWTKeys) issue(subject string, audience jwt.ClaimStrings, ttl time.Duration) (string, time.Time, error) {
+	k.mu.RLock()
+	signing := k.signing
+	k.mu.RUnlock()
+	if signing == nil {
+		return "", time.Time{}, ErrNoSigningKey
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Issuer:    k.cfg.JWTIssuer,
+		Subject:   subject,
+		Audience:  audience,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	token.Header["kid"] = signing.kid
+	signed, err := token.SignedString(signing.private)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Keyfunc returns the key that verifies token: the public key its kid names,
+// or the shared secret for HMAC tokens
+func (k *JWTKeys) Keyfunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodECDSA:
+		kid, _ := token.Header["kid"].(string)
+		k.mu.RLock()
+		key, ok := k.verifying[kid]
+		k.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return &key.private.PublicKey, nil
+	case *jwt.SigningMethodHMAC:
+		if !k.cfg.JWTSharedSecretEnabled {
+			return nil, errors.New("tokens signed with the shared secret are not accepted")
+		}
+		return []byte(k.sharedSecret()), nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+}
+
+// Parse verifies a token signed by one of the keys or the shared secret
+func (k *JWTKeys) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, k.Keyfunc, jwt.WithValidMethods([]string{
+		jwt.SigningMethodES256.Alg(),
+		jwt.SigningMethodHS256.Alg(),
+		jwt.SigningMethodHS384.Alg(),
+		jwt.SigningMethodHS512.Alg(),
+	}))
+}
+
+// Claimer verifies a claimer session token and returns the user it was
+// issued to. Only the rotating keys sign them: a token signed with the shared
+// secret is never a claimer's.
+func (k *JWTKeys) Claimer(tokenString string) (string, bool) {
+	token, err := jwt.Parse(tokenString, k.Keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithAudience(ClaimerAudience))
+	if err != nil || !token.Valid {
+		return "", false
+	}
+	sub, err := token.Claims.GetSubject()
+	return sub, err == nil && sub != ""
+}
+
+// IsClaimerToken reports whether a verified token is a claimer session
+// token rather than an enterprise's
+func IsClaimerToken(token *jwt.Token) bool {
+	aud, _ := token.Claims.GetAudience()
+	for _, a := range aud {
+		if a == ClaimerAudience {
+			return true
+		}
+	}
+	return false
+}
+
+// JWKS returns the public keys that verify tokens: the current key and
+// those still in their grace period
+func (k *JWTKeys) JWKS() *JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]*tokenKey, 0, len(k.verifying))
+	for _, key := range k.verifying {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].createdAt.After(keys[j].createdAt) })
+
+	set := &JWKS{Keys: []JWK{}}
+	for _, key := range keys {
+		pub := key.private.PublicKey
+		set.Keys = append(set.Keys, JWK{
+			KTY: "EC",
+			CRV: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+			KID: key.kid,
+			Use: "sig",
+			Alg: jwt.SigningMethodES256.Alg(),
+		})
+	}
+	return set
+}
@@ -0,0 +1,732 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrInsufficientBalance = newCodedError("insufficient_balance")
+	ErrInvalidAddress      = newCodedError("invalid_address")
+	ErrWithdrawalNotFound  = newCodedError("withdrawal_not_found")
+
+	ErrWithdrawalBelowMinimum       = newCodedError("withdrawal_below_minimum")
+	ErrInstantWithdrawalUnavailable = newCodedError("instant_withdrawal_unavailable")
+
+	ErrWalletNotOwned   = newCodedError("wallet_not_owned")
+	ErrUnsupportedToken = newCodedError("unsupported_token")
+)
+
+// Campaign payout modes
+const (
+	PayoutModeOnchain = "onchain"
+	PayoutModeCredit  = "credit"
+)
+
+// Withdrawal speeds
+const (
+	WithdrawalSpeedInstant = "instant" // sent right away, for a fee
+	WithdrawalSpeedBatch   = "batch"   // free, sent with others when the window closes
+)
+
+// Withdrawal sources
+const (
+	WithdrawalSourceLedger = "ledger" // credit-mode balance, paid from the treasury
+	WithdrawalSourceWallet = "wallet" // the user's own AA wallet
+)
+
+// LedgerTreasuryWalletID owns the wallet that pays out ledger withdrawals. It
+// has to hold enough of each token to cover the outstanding balances.
+const LedgerTreasuryWalletID = "treasury_ledger"
+
+const (
+	withdrawalPollInterval = 10 * time.Second
+	withdrawalBatchSize    = 50
+)
+
+// LedgerService keeps off-chain balances for credit-mode campaigns. Claims
+// credit the ledger instantly; the chain is only touched when a user withdraws.
+type LedgerService struct {
+	repo         *repository.LedgerRepository
+	campaignRepo repository.CampaignStore
+	walletSvc    *WalletService
+	screener     *SanctionsScreener
+	tokens       *TokenRegistry
+	cfg          *config.Config
+}
+
+func NewLedgerService(
+	repo *repository.LedgerRepository,
+	campaignRepo repository.CampaignStore,
+	walletSvc *WalletService,
+	screener *SanctionsScreener,
+	tokens *TokenRegistry,
+	cfg *config.Config,
+) *LedgerService {
+	return &LedgerService{
+		repo:         repo,
+		campaignRepo: campaignRepo,
+		walletSvc:    walletSvc,
+		screener:     screener,
+		tokens:       tokens,
+		cfg:          cfg,
+	}
+}
+
+// CreditMode reports whether claims of the campaign are paid to the ledger.
+// Pockets without a known campaign are paid on chain.
+func (s *LedgerService) CreditMode(ctx context.Context, campaignID string) bool {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil {
+		return false
+	}
+	return campaign.PayoutMode == PayoutModeCredit
+}
+
+// CharityLedgerID is the ledger account a campaign's credit-mode donations
+// pass through on their way to the charity
+func CharityLedgerID(campaignID string) string {
+	return "charity_" + campaignID
+}
+
+// Credit adds a claim to the claimer's ledger balance. A donation is credited
+// to the campaign's charity account instead and queued for the next
+// withdrawal batch to the charity address, and the platform fee to the
+// platform revenue account.
+func (s *LedgerService) Credit(ctx context.Context, claim *model.Claim, rp *model.RedPocket) error {
+	now := time.Now()
+	net := new(big.Int).Sub(payoutUnits(claim), claim.DonationUnits.Int())
+	entries := []*model.LedgerEntry{{
+		ID:           "ledger_" + uuid.New().String()[:8],
+		UserID:       claim.ClaimerID,
+		ChainID:      rp.ChainID,
+		Token:        rp.Token,
+		TokenAddress: rp.TokenAddress,
+		Amount:       model.NewUnits(net).Float(rp.Decimals),
+		Kind:         "claim",
+		RefID:        claim.ID,
+		CreatedAt:    now,
+	}}
+	if claim.DonationUnits.Sign() > 0 {
+		entries = append(entries, &model.LedgerEntry{
+			ID:           "ledger_" + uuid.New().String()[:8],
+			UserID:       CharityLedgerID(rp.CampaignID),
+			ChainID:      rp.ChainID,
+			Token:        rp.Token,
+			TokenAddress: rp.TokenAddress,
+			Amount:       claim.Donation,
+			Kind:         "donation",
+			RefID:        claim.ID,
+			CreatedAt:    now,
+		})
+	}
+	if claim.FeeUnits.Sign() > 0 {
+		entries = append(entries, &model.LedgerEntry{
+			ID:           "ledger_" + uuid.New().String()[:8],
+			UserID:       model.PlatformRevenueAccount,
+			ChainID:      rp.ChainID,
+			Token:        rp.Token,
+			TokenAddress: rp.TokenAddress,
+			Amount:       claim.Fee,
+			Kind:         "claim_fee",
+			RefID:        claim.ID,
+			CreatedAt:    now,
+		})
+	}
+	if err := s.repo.Credit(ctx, entries...); err != nil {
+		return err
+	}
+
+	if claim.DonationUnits.Sign() > 0 {
+		// The claim is settled either way; a donation that cannot be queued
+		// stays on the charity account
+		if err := s.donate(ctx, claim, rp); err != nil {
+			slog.ErrorContext(ctx, "ledger: donation kept on the charity account", "claim_id", claim.ID, "ledger_id", CharityLedgerID(rp.CampaignID), "error", err)
+		}
+	}
+	return nil
+}
+
+// donate queues a free batch withdrawal of a claim's donation from the
+// charity account to the charity
+func (s *LedgerService) donate(ctx context.Context, claim *model.Claim, rp *model.RedPocket) error {
+	now := time.Now()
+	w := &model.Withdrawal{
+		ID:          "withdraw_" + uuid.New().String()[:8],
+		UserID:      CharityLedgerID(rp.CampaignID),
+		Source:      WithdrawalSourceLedger,
+		ChainID:     rp.ChainID,
+		Token:       rp.Token,
+		Amount:      claim.Donation,
+		ToAddress:   claim.CharityAddress,
+		Speed:       WithdrawalSpeedBatch,
+		Status:      "pending",
+		ScheduledAt: s.nextBatchAt(now),
+		CreatedAt:   now,
+	}
+	if err := s.screen(ctx, w); err != nil {
+		return err
+	}
+	ok, err := s.repo.CreateWithdrawal(ctx, w, "ledger_"+uuid.New().String()[:8])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+func (s *LedgerService) Balances(ctx context.Context, userID string) ([]*model.LedgerBalance, error) {
+	return s.repo.ListBalances(ctx, userID)
+}
+
+func (s *LedgerService) Entries(ctx context.Context, userID string, page, limit int) ([]*model.LedgerEntry, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return s.repo.ListEntries(ctx, userID, limit, offset)
+}
+
+type WithdrawRequest struct {
+	UserID        string  `json:"-"` // the authenticated claimer or enterprise
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Address       string  `json:"address" binding:"required"`
+	Token         string  `json:"token"`                                          // default USDC
+	ChainID       int64   `json:"chainId"`                                        // default CHAIN_ID
+	Speed         string  `json:"speed" binding:"omitempty,oneof=instant batch"`  // default batch; ledger only
+	Source        string  `json:"source" binding:"omitempty,oneof=ledger wallet"` // default ledger
+	WalletAddress string  `json:"walletAddress"`                                  // wallet source: must be the user's wallet
+	TokenAddress  string  `json:"tokenAddress"`                                   // wallet source: default USDC_ADDRESS for USDC
+
+	TravelRule *model.TravelRuleInfo `json:"travelRule"` // required at or above TRAVEL_RULE_THRESHOLD
+}
+
+type WithdrawalQuoteRequest struct {
+	Amount  float64 `form:"amount" binding:"required,gt=0"`
+	Token   string  `form:"token"`   // default USDC
+	ChainID int64   `form:"chainId"` // default CHAIN_ID
+}
+
+// Quote prices withdrawing an amount at each speed
+func (s *LedgerService) Quote(ctx context.Context, req *WithdrawalQuoteRequest) ([]*model.WithdrawalQuote, error) {
+	token, chainID := s.withdrawalDefaults(req.Token, req.ChainID)
+	settings, err := s.tokenSettings(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	quotes := make([]*model.WithdrawalQuote, 0, 2)
+	for _, speed := range []string{WithdrawalSpeedBatch, WithdrawalSpeedInstant} {
+		q, err := s.quote(settings, chainID, req.Amount, speed, now)
+		if err != nil {
+			q.Reason = ErrorCode(err)
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, nil
+}
+
+// Withdraw debits the ledger and queues the on-chain transfer at the
+// requested speed. The returned quote is what was charged. Wallet-source
+// withdrawals are sent from the user's AA wallet instead, see withdrawFromWallet.
+// Only the account ctx was authenticated as can withdraw, see WithAccount.
+func (s *LedgerService) Withdraw(ctx context.Context, req *WithdrawRequest) (*model.Withdrawal, *model.WithdrawalQuote, error) {
+	if account, ok := accountFrom(ctx); !ok || req.UserID == "" || account != req.UserID {
+		return nil, nil, ErrWalletNotOwned
+	}
+	if !common.IsHexAddress(req.Address) {
+		return nil, nil, ErrInvalidAddress
+	}
+	if err := s.checkTravelRule(req.Amount, req.TravelRule); err != nil {
+		return nil, nil, err
+	}
+	if req.Source == WithdrawalSourceWallet {
+		return s.withdrawFromWallet(ctx, req)
+	}
+
+	token, chainID := s.withdrawalDefaults(req.Token, req.ChainID)
+	registered, err := s.withdrawalToken(ctx, token, chainID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	speed := req.Speed
+	if speed == "" {
+		speed = WithdrawalSpeedBatch
+	}
+	settings, err := s.tokenSettings(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	q, err := s.quote(settings, chainID, req.Amount, speed, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w := &model.Withdrawal{
+		ID:           "withdraw_" + uuid.New().String()[:8],
+		UserID:       req.UserID,
+		Source:       WithdrawalSourceLedger,
+		ChainID:      chainID,
+		Token:        token,
+		TokenAddress: registered.Address,
+		Amount:       req.Amount,
+		ToAddress:    common.HexToAddress(req.Address).Hex(),
+		Speed:        speed,
+		Fee:          q.Fee,
+		Status:       "pending",
+		ScheduledAt:  q.EstimatedAt,
+		TravelRule:   req.TravelRule,
+		CreatedAt:    now,
+	}
+	if err := s.screen(ctx, w); err != nil {
+		return nil, nil, err
+	}
+
+	ok, err := s.repo.CreateWithdrawal(ctx, w, "ledger_"+uuid.New().String()[:8])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create withdrawal: %w", err)
+	}
+	if !ok {
+		return nil, nil, ErrInsufficientBalance
+	}
+	return w, q, nil
+}
+
+// withdrawFromWallet sends tokens from the user's own AA wallet right away.
+// The wallet pays no withdrawal fee and no ledger balance is touched; the
+// on-chain balance is checked up front so an underfunded wallet fails fast.
+func (s *LedgerService) withdrawFromWallet(ctx context.Context, req *WithdrawRequest) (*model.Withdrawal, *model.WithdrawalQuote, error) {
+	token, chainID := s.withdrawalDefaults(req.Token, req.ChainID)
+
+	wallet, registered, err := s.walletSource(ctx, req.UserID, chainID, req.WalletAddress, token, req.TokenAddress, req.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	w := &model.Withdrawal{
+		ID:           "withdraw_" + uuid.New().String()[:8],
+		UserID:       req.UserID,
+		Source:       WithdrawalSourceWallet,
+		FromAddress:  wallet.Address,
+		ChainID:      chainID,
+		Token:        token,
+		TokenAddress: common.HexToAddress(registered.Address).Hex(),
+		Amount:       req.Amount,
+		ToAddress:    common.HexToAddress(req.Address).Hex(),
+		Speed:        WithdrawalSpeedInstant,
+		Status:       "pending",
+		ScheduledAt:  now,
+		TravelRule:   req.TravelRule,
+		CreatedAt:    now,
+	}
+	if err := s.screen(ctx, w); err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.CreateWalletWithdrawal(ctx, w); err != nil {
+		return nil, nil, fmt.Errorf("failed to create withdrawal: %w", err)
+	}
+
+	// Send now rather than waiting for the poller; a failed send is recorded
+	// on the withdrawal, not returned
+	if err := s.process(ctx, w); err != nil {
+		slog.ErrorContext(ctx, "ledger: withdrawal failed", "withdrawal_id", w.ID, "error", err)
+	}
+	if sent, err := s.repo.GetWithdrawal(ctx, w.ID); err == nil {
+		w = sent
+	}
+
+	q := &model.WithdrawalQuote{
+		Speed:       WithdrawalSpeedInstant,
+		Token:       token,
+		ChainID:     chainID,
+		Amount:      req.Amount,
+		NetAmount:   req.Amount,
+		EstimatedAt: now,
+		Available:   true,
+	}
+	return w, q, nil
+}
+
+// walletSource returns the user's wallet and the registered token for a
+// withdrawal of amount from it. Only the account ctx was authenticated as
+// can draw on its wallet, and a wallet address given by the caller must be
+// the user's; the on-chain balance is checked so an underfunded wallet fails
+// fast.
+func (s *LedgerService) walletSource(ctx context.Context, userID string, chainID int64, walletAddress, token, tokenAddress string, amount float64) (*model.Wallet, *model.Token, error) {
+	if account, ok := accountFrom(ctx); !ok || account != userID {
+		return nil, nil, ErrWalletNotOwned
+	}
+	wallet, err := s.walletSvc.GetByUserID(ctx, userID, chainID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrWalletNotOwned
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load wallet: %w", err)
+	}
+	if walletAddress != "" && !strings.EqualFold(walletAddress, wallet.Address) {
+		return nil, nil, ErrWalletNotOwned
+	}
+
+	registered, err := s.withdrawalToken(ctx, token, chainID, tokenAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !s.walletSvc.Simulated() {
+		balance, err := s.walletSvc.TokenBalance(ctx, registered.Address, wallet.Address)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read wallet balance: %w", err)
+		}
+		if balance.Cmp(floatToBigInt(amount, registered.Decimals)) < 0 {
+			return nil, nil, ErrInsufficientBalance
+		}
+	}
+	return wallet, registered, nil
+}
+
+// screen checks a withdrawal's destination against sanctions lists and
+// records the result on it. Sanctioned destinations are refused outright.
+func (s *LedgerService) screen(ctx context.Context, w *model.Withdrawal) error {
+	screening, err := s.screener.Screen(ctx, w.ToAddress, "withdrawal", w.ID, w.UserID)
+	if err != nil || screening == nil {
+		return err
+	}
+	w.ScreeningID = screening.ID
+	w.ScreeningResult = screening.Result
+	if screening.Result == ScreeningHit {
+		return ErrAddressSanctioned
+	}
+	return nil
+}
+
+func (s *LedgerService) withdrawalDefaults(token string, chainID int64) (string, int64) {
+	if token == "" {
+		token = "USDC"
+	}
+	if chainID == 0 {
+		chainID = s.cfg.ChainID
+	}
+	return token, chainID
+}
+
+// withdrawalToken looks a withdrawal's token up in the registry. An address
+// given by the caller must match it. Native tokens cannot be withdrawn since
+// withdrawals are batched and wallet balances are read from the token contract.
+func (s *LedgerService) withdrawalToken(ctx context.Context, token string, chainID int64, address string) (*model.Token, error) {
+	registered, err := s.tokens.Resolve(ctx, token, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if registered.Native() {
+		return nil, ErrNativeTokenUnsupported
+	}
+	if address != "" && !strings.EqualFold(address, registered.Address) {
+		return nil, ErrUnsupportedToken
+	}
+	return registered, nil
+}
+
+// tokenSettings returns the token's withdrawal settings, falling back to the
+// config defaults for tokens without a row
+func (s *LedgerService) tokenSettings(ctx context.Context, token string) (*model.WithdrawalTokenSettings, error) {
+	settings, err := s.repo.GetTokenSettings(ctx, token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &model.WithdrawalTokenSettings{
+			Token:          token,
+			MinAmount:      s.cfg.WithdrawalMinAmount,
+			InstantEnabled: true,
+			InstantFee:     s.cfg.WithdrawalInstantFee,
+			InstantFeeBps:  s.cfg.WithdrawalInstantFeeBps,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load withdrawal settings: %w", err)
+	}
+	return settings, nil
+}
+
+// quote prices one speed. The quote is always filled in; the error says why
+// it cannot be used.
+func (s *LedgerService) quote(settings *model.WithdrawalTokenSettings, chainID int64, amount float64, speed string, now time.Time) (*model.WithdrawalQuote, error) {
+	q := &model.WithdrawalQuote{
+		Speed:       speed,
+		Token:       settings.Token,
+		ChainID:     chainID,
+		Amount:      amount,
+		MinAmount:   settings.MinAmount,
+		EstimatedAt: now,
+	}
+	if speed == WithdrawalSpeedInstant {
+		q.Fee = settings.InstantFee + amount*float64(settings.InstantFeeBps)/10000
+	} else {
+		q.Fee = settings.BatchFee
+		q.EstimatedAt = s.nextBatchAt(now)
+	}
+	// Round the fee to the token's 6 decimals so the quote matches what is sent
+	q.Fee = float64(floatToBigInt(q.Fee, 6).Int64()) / 1e6
+	q.NetAmount = amount - q.Fee
+
+	switch {
+	case speed == WithdrawalSpeedInstant && !settings.InstantEnabled:
+		return q, ErrInstantWithdrawalUnavailable
+	case amount < settings.MinAmount || q.NetAmount <= 0:
+		return q, ErrWithdrawalBelowMinimum
+	}
+	q.Available = true
+	return q, nil
+}
+
+// nextBatchAt returns when the current batch window closes. Windows are
+// aligned to multiples of the window length so every instance agrees.
+func (s *LedgerService) nextBatchAt(now time.Time) time.Time {
+	window := time.Duration(s.cfg.WithdrawalBatchWindow) * time.Second
+	if window <= 0 {
+		return now
+	}
+	return now.Truncate(window).Add(window)
+}
+
+func (s *LedgerService) GetWithdrawal(ctx context.Context, id string) (*model.Withdrawal, error) {
+	w, err := s.repo.GetWithdrawal(ctx, id)
+	if err != nil {
+		return nil, ErrWithdrawalNotFound
+	}
+	return w, nil
+}
+
+func (s *LedgerService) ListWithdrawals(ctx context.Context, userID string, page, limit int) ([]*model.Withdrawal, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return s.repo.ListWithdrawals(ctx, userID, limit, offset)
+}
+
+// Start processes withdrawals until ctx is cancelled
+func (s *LedgerService) Start(ctx context.Context) {
+	ticker := time.NewTicker(withdrawalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *LedgerService) poll(ctx context.Context) {
+	s.processBatches(ctx)
+
+	withdrawals, err := s.repo.ListOpenWithdrawals(ctx, withdrawalBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "ledger: failed to list withdrawals", "error", err)
+		return
+	}
+	for _, w := range withdrawals {
+		var err error
+		if w.Status == "pending" {
+			err = s.process(ctx, w)
+		} else {
+			err = s.checkPending(ctx, w)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "ledger: withdrawal failed", "withdrawal_id", w.ID, "error", err)
+		}
+	}
+}
+
+func (s *LedgerService) process(ctx context.Context, w *model.Withdrawal) error {
+	started, err := s.repo.StartWithdrawal(ctx, w.ID)
+	if err != nil || !started {
+		return err
+	}
+
+	sender, err := s.sender(ctx, w)
+	if err != nil {
+		return s.fail(ctx, w, err.Error())
+	}
+
+	decimals, err := s.tokens.Decimals(ctx, w.TokenAddress, w.ChainID)
+	if err != nil {
+		return s.fail(ctx, w, err.Error())
+	}
+
+	// The fee stays in the treasury
+	txHash, err := s.walletSvc.TransferToken(ctx, sender, w.TokenAddress, w.ToAddress, floatToBigInt(w.NetAmount(), decimals))
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		return s.repo.UpdateWithdrawal(ctx, w.ID, "processing", "", pending.UserOpHash)
+	case err != nil:
+		return s.fail(ctx, w, err.Error())
+	}
+	return s.repo.UpdateWithdrawal(ctx, w.ID, "success", txHash, "")
+}
+
+// sender returns the wallet a withdrawal is paid from: the user's wallet for
+// wallet withdrawals, the treasury otherwise
+func (s *LedgerService) sender(ctx context.Context, w *model.Withdrawal) (*model.Wallet, error) {
+	if w.Source != WithdrawalSourceWallet {
+		treasury, err := s.walletSvc.GetOrCreate(ctx, LedgerTreasuryWalletID, w.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("treasury wallet unavailable: %w", err)
+		}
+		return treasury, nil
+	}
+
+	wallet, err := s.walletSvc.GetByUserID(ctx, w.UserID, w.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("user wallet unavailable: %w", err)
+	}
+	if !strings.EqualFold(wallet.Address, w.FromAddress) {
+		return nil, fmt.Errorf("user wallet changed from %s to %s", w.FromAddress, wallet.Address)
+	}
+	return wallet, nil
+}
+
+// processBatches sends due batch withdrawals, one user operation per chain
+// and token
+func (s *LedgerService) processBatches(ctx context.Context) {
+	withdrawals, err := s.repo.ListDueBatchWithdrawals(ctx, s.cfg.WithdrawalBatchMax)
+	if err != nil {
+		slog.ErrorContext(ctx, "ledger: failed to list batch withdrawals", "error", err)
+		return
+	}
+
+	// Rows are ordered by chain and token, so groups are contiguous
+	for start := 0; start < len(withdrawals); {
+		end := start + 1
+		for end < len(withdrawals) &&
+			withdrawals[end].ChainID == withdrawals[start].ChainID &&
+			withdrawals[end].TokenAddress == withdrawals[start].TokenAddress {
+			end++
+		}
+		if err := s.processBatch(ctx, withdrawals[start:end]); err != nil {
+			slog.ErrorContext(ctx, "ledger: withdrawal batch failed", "error", err)
+		}
+		start = end
+	}
+}
+
+func (s *LedgerService) processBatch(ctx context.Context, group []*model.Withdrawal) error {
+	batchID := "wbatch_" + uuid.New().String()[:8]
+	ids := make([]string, len(group))
+	for i, w := range group {
+		ids[i] = w.ID
+	}
+	started, err := s.repo.StartWithdrawalBatch(ctx, ids, batchID)
+	if err != nil || len(started) == 0 {
+		return err
+	}
+	taken := make(map[string]bool, len(started))
+	for _, id := range started {
+		taken[id] = true
+	}
+
+	var batch []*model.Withdrawal
+	for _, w := range group {
+		if taken[w.ID] {
+			batch = append(batch, w)
+		}
+	}
+	head := batch[0]
+
+	failAll := func(reason string) error {
+		for _, w := range batch {
+			if err := s.fail(ctx, w, reason); err != nil {
+				slog.ErrorContext(ctx, "ledger: withdrawal failed", "withdrawal_id", w.ID, "error", err)
+			}
+		}
+		return fmt.Errorf("batch %s failed: %s", batchID, reason)
+	}
+
+	decimals, err := s.tokens.Decimals(ctx, head.TokenAddress, head.ChainID)
+	if err != nil {
+		return failAll(err.Error())
+	}
+	recipients := make([]string, len(batch))
+	amounts := make([]*big.Int, len(batch))
+	for i, w := range batch {
+		recipients[i] = w.ToAddress
+		amounts[i] = floatToBigInt(w.NetAmount(), decimals)
+	}
+
+	treasury, err := s.walletSvc.GetOrCreate(ctx, LedgerTreasuryWalletID, head.ChainID)
+	if err != nil {
+		return failAll("treasury wallet unavailable: " + err.Error())
+	}
+
+	txHash, err := s.walletSvc.BatchTransfer(ctx, treasury, head.TokenAddress, recipients, amounts, "")
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		return s.repo.UpdateWithdrawalBatch(ctx, batchID, "processing", "", pending.UserOpHash)
+	case err != nil:
+		return failAll(err.Error())
+	}
+	slog.InfoContext(ctx, "ledger: sent withdrawal batch", "batch_id", batchID, "withdrawals", len(batch), "token", head.Token, "tx_hash", txHash)
+	return s.repo.UpdateWithdrawalBatch(ctx, batchID, "success", txHash, "")
+}
+
+// checkPending resolves a withdrawal whose user operation was still in the
+// mempool; the UserOpMonitor bumps it meanwhile
+func (s *LedgerService) checkPending(ctx context.Context, w *model.Withdrawal) error {
+	status, txHash, err := s.walletSvc.UserOpOutcome(ctx, w.UserOpHash)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case "success":
+		return s.repo.UpdateWithdrawal(ctx, w.ID, "success", txHash, "")
+	case "failed":
+		return s.fail(ctx, w, "withdrawal cancelled on chain")
+	}
+	return nil
+}
+
+// fail marks a withdrawal failed and returns the amount to the user's balance.
+// Wallet withdrawals have nothing to refund: the tokens never left the wallet.
+func (s *LedgerService) fail(ctx context.Context, w *model.Withdrawal, reason string) error {
+	if w.Source == WithdrawalSourceWallet {
+		slog.WarnContext(ctx, "ledger: wallet withdrawal failed", "withdrawal_id", w.ID, "reason", reason)
+		return s.repo.FailWithdrawal(ctx, w, reason, nil)
+	}
+	slog.WarnContext(ctx, "ledger: withdrawal failed, refunding", "withdrawal_id", w.ID, "amount", w.Amount, "token", w.Token, "user_id", w.UserID, "reason", reason)
+	return s.repo.FailWithdrawal(ctx, w, reason, &model.LedgerEntry{
+		ID:           "ledger_" + uuid.New().String()[:8],
+		UserID:       w.UserID,
+		ChainID:      w.ChainID,
+		Token:        w.Token,
+		TokenAddress: w.TokenAddress,
+		Amount:       w.Amount,
+		Kind:         "refund",
+		RefID:        w.ID,
+		CreatedAt:    time.Now(),
+	})
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var ErrNotLuckyDraw = newCodedError("not_lucky_draw")
+
+// LuckyDrawScheme names how lucky draw pockets are split and drawn, for
+// verifiers. When the pocket is created it is split into totalCount shares,
+// slot i of n from what the slots before it left (rest):
+//
+//	random = HMAC-SHA256(key = seed, "<pocketId>:share:<i>")
+//	R      = random as a 256-bit big-endian integer
+//	max    = min(2 * rest / (n - i), maxAmount), at least min
+//	min    = minAmount (0.01 when unset), at least one unit
+//	share  = min + (max - min) * R / 2^256, rounded down to 2 decimals
+//	         unless that leaves nothing, at most rest, and at most what
+//	         leaves min for each later slot when rest allows
+//
+// and the last slot takes rest, so the shares add up to the pocket's amount
+// exactly. A claim draws
+//
+//	random = HMAC-SHA256(key = seed, "<pocketId>:<platform>:<platformId>")
+//	slot   = R mod totalCount
+//
+// and takes the first unclaimed share from that slot on, wrapping around to
+// slot 0. Units are the token's minor units and divisions round down.
+const LuckyDrawScheme = "hmac-sha256-presplit-v2"
+
+// luckyDrawSchemeV1 is how pockets seeded before shares were pre-split draw:
+// each claim's share comes from what is left of the pocket when it claims,
+// with max = min(2 * remainingUnits / remainingCount, maxAmount), and the
+// last share (remainingCount 1) takes remainingUnits.
+const luckyDrawSchemeV1 = "hmac-sha256-double-mean-v1"
+
+// LuckyDrawService publishes the proofs of lucky draw pockets. Every lucky
+// draw pocket is created with a secret random seed, and its SHA-256 is
+// published as the pocket's drawCommitment. Each claim draws its share from
+// the seed and the claimer, which the server cannot choose, and the inputs
+// of the draw are recorded with the claim. Once the pocket closes the seed
+// is revealed, so anyone can check it against the commitment and recompute
+// every draw.
+type LuckyDrawService struct {
+	repo   *repository.LuckyDrawRepository
+	rpRepo repository.RedPocketStore
+}
+
+func NewLuckyDrawService(repo *repository.LuckyDrawRepository, rpRepo repository.RedPocketStore) *LuckyDrawService {
+	return &LuckyDrawService{repo: repo, rpRepo: rpRepo}
+}
+
+// seedLuckyDraw gives a lucky draw pocket a fresh seed and its commitment,
+// and splits it into its shares
+func seedLuckyDraw(rp *model.RedPocket) error {
+	rp.DrawSeed, rp.DrawCommitment, rp.DrawScheme, rp.Shares = "", "", "", nil
+	if !rp.IsLuckyDraw {
+		return nil
+	}
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return fmt.Errorf("failed to generate draw seed: %w", err)
+	}
+	sum := sha256.Sum256(seed)
+	rp.DrawSeed = hex.EncodeToString(seed)
+	rp.DrawCommitment = hex.EncodeToString(sum[:])
+	rp.DrawScheme = LuckyDrawScheme
+	rp.Shares = splitLuckyDraw(rp, seed)
+	return nil
+}
+
+// splitLuckyDraw splits a lucky draw pocket into its shares, as
+// LuckyDrawScheme describes
+func splitLuckyDraw(rp *model.RedPocket, seed []byte) []model.Units {
+	n := rp.TotalCount
+	if n <= 0 {
+		return nil
+	}
+	minUnits := luckyDrawMinUnits(rp)
+	rest := new(big.Int).Set(rp.AmountUnits.Int())
+	shares := make([]model.Units, n)
+	for i := 0; i < n-1; i++ {
+		mac := hmac.New(sha256.New, seed)
+		mac.Write([]byte(rp.ID + ":share:" + strconv.Itoa(i)))
+		share := luckyDrawUnits(rp, rest, int64(n-i), mac.Sum(nil))
+
+		// Leave the later slots their minimum when there is enough for it
+		reserve := new(big.Int).Mul(minUnits, big.NewInt(int64(n-i-1)))
+		if most := new(big.Int).Sub(rest, reserve); most.Sign() >= 0 && share.Cmp(most) > 0 {
+			share = most
+		}
+		shares[i] = model.NewUnits(share)
+		rest.Sub(rest, share)
+	}
+	shares[n-1] = model.NewUnits(rest)
+	return shares
+}
+
+// drawRandom derives a claimer's randomness from a pocket's seed
+func drawRandom(seed []byte, redPocketID, platform, platformID string) []byte {
+	mac := hmac.New(sha256.New, seed)
+	mac.Write([]byte(redPocketID + ":" + platform + ":" + platformID))
+	return mac.Sum(nil)
+}
+
+// newLuckyDraw starts the draw of a claim on a lucky draw pocket, or returns
+// nil for other pockets. Pockets created before draws were seeded draw from
+// crypto/rand and keep no record.
+func newLuckyDraw(rp *model.RedPocket, platform, platformID string) (*model.LuckyDraw, []byte, error) {
+	if !rp.IsLuckyDraw {
+		return nil, nil, nil
+	}
+	if rp.DrawSeed == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, fmt.Errorf("failed to draw: %w", err)
+		}
+		return nil, random, nil
+	}
+	seed, err := hex.DecodeString(rp.DrawSeed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid draw seed of %s: %w", rp.ID, err)
+	}
+	random := drawRandom(seed, rp.ID, platform, platformID)
+	return &model.LuckyDraw{
+		RedPocketID:    rp.ID,
+		Platform:       platform,
+		PlatformID:     platformID,
+		RemainingUnits: rp.RemainingUnits,
+		RemainingCount: rp.TotalCount - rp.ClaimedCount,
+		Random:         hex.EncodeToString(random),
+	}, random, nil
+}
+
+// luckyDrawMinUnits is the least a lucky draw share comes to
+func luckyDrawMinUnits(rp *model.RedPocket) *big.Int {
+	minAmount := rp.MinAmount
+	if minAmount <= 0 {
+		minAmount = 0.01
+	}
+	minUnits := floatToBigInt(minAmount, rp.Decimals)
+	if minUnits.Sign() <= 0 {
+		minUnits = big.NewInt(1)
+	}
+	return minUnits
+}
+
+// luckyDrawUnits draws a share of what is left of a lucky draw pocket with
+// the "二倍均值法", as LuckyDrawScheme describes for a slot and
+// luckyDrawSchemeV1 for a claim
+func luckyDrawUnits(rp *model.RedPocket, remaining *big.Int, remainingCount int64, random []byte) *big.Int {
+	if remainingCount <= 1 {
+		return new(big.Int).Set(remaining)
+	}
+
+	maxUnits := new(big.Int).Quo(new(big.Int).Lsh(remaining, 1), big.NewInt(remainingCount))
+
+	if rp.MaxAmount > 0 {
+		if cap := floatToBigInt(rp.MaxAmount, rp.Decimals); maxUnits.Cmp(cap) > 0 {
+			maxUnits = cap
+		}
+	}
+
+	minUnits := luckyDrawMinUnits(rp)
+	if maxUnits.Cmp(minUnits) < 0 {
+		maxUnits = minUnits
+	}
+
+	// Uniform between min and max
+	offset := new(big.Int).Sub(maxUnits, minUnits)
+	offset.Mul(offset, new(big.Int).SetBytes(random))
+	offset.Rsh(offset, 256)
+	amount := offset.Add(offset, minUnits)
+
+	// Round down to 2 decimals, unless that leaves nothing
+	if rp.Decimals > 2 {
+		step := model.Pow10(rp.Decimals - 2)
+		if rounded := new(big.Int).Sub(amount, new(big.Int).Mod(amount, step)); rounded.Sign() > 0 {
+			amount = rounded
+		}
+	}
+
+	// Ensure we don't exceed remaining
+	if amount.Cmp(remaining) > 0 {
+		return new(big.Int).Set(remaining)
+	}
+	return amount
+}
+
+// preferredSlot is the slot a claim's draw points at
+func preferredSlot(random []byte, totalCount int) int {
+	return int(new(big.Int).Mod(new(big.Int).SetBytes(random), big.NewInt(int64(totalCount))).Int64())
+}
+
+// luckyDrawShare draws a lucky draw claim's unweighted share in the token's
+// minor units. Pre-split pockets give it the first unclaimed share from the
+// slot it draws, recorded in draw; the last share also takes anything
+// weighted or capped claims left. Older pockets draw from what is left.
+func (s *RedPocketService) luckyDrawShare(ctx context.Context, rp *model.RedPocket, draw *model.LuckyDraw, random []byte) (*big.Int, error) {
+	remaining := rp.RemainingUnits.Int()
+	remainingCount := int64(rp.TotalCount - rp.ClaimedCount)
+	if draw == nil || rp.DrawScheme != LuckyDrawScheme {
+		units := luckyDrawUnits(rp, remaining, remainingCount, random)
+		if draw != nil {
+			draw.DrawUnits = model.NewUnits(units)
+		}
+		return units, nil
+	}
+
+	slot, units, err := s.rpRepo.NextShare(ctx, rp.ID, preferredSlot(random, rp.TotalCount))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRedPocketDepleted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw share: %w", err)
+	}
+	draw.Slot = &slot
+	draw.DrawUnits = units
+	share := units.Int()
+	if remainingCount <= 1 || share.Cmp(remaining) > 0 {
+		return remaining, nil
+	}
+	return share, nil
+}
+
+// drawRevealed reports whether a pocket has closed, so its seed can be
+// published without letting anyone predict a draw
+func drawRevealed(status model.PocketStatus) bool {
+	switch status {
+	case model.PocketDepleted, model.PocketExpired, model.PocketCancelled, model.PocketRefunded, model.PocketUnfunded:
+		return true
+	}
+	return false
+}
+
+// Proof returns the proof of a lucky draw pocket. Once it has closed the
+// seed is revealed and every draw is checked against it.
+func (s *LuckyDrawService) Proof(ctx context.Context, redPocketID string) (*model.DrawProof, error) {
+	rp, err := s.rpRepo.GetByID(ctx, redPocketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRedPocketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !rp.IsLuckyDraw || rp.DrawCommitment == "" {
+		return nil, ErrNotLuckyDraw
+	}
+
+	draws, err := s.repo.ListByPocket(ctx, rp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load draws: %w", err)
+	}
+	if draws == nil {
+		draws = []*model.LuckyDraw{}
+	}
+	scheme := rp.DrawScheme
+	if scheme == "" {
+		scheme = luckyDrawSchemeV1
+	}
+	proof := &model.DrawProof{
+		RedPocketID: rp.ID,
+		Scheme:      scheme,
+		Status:      rp.Status,
+		Commitment:  rp.DrawCommitment,
+		Decimals:    rp.Decimals,
+		MinAmount:   rp.MinAmount,
+		MaxAmount:   rp.MaxAmount,
+		AmountUnits: rp.AmountUnits,
+		Draws:       draws,
+	}
+	if !drawRevealed(rp.Status) {
+		return proof, nil
+	}
+
+	proof.Seed = rp.DrawSeed
+	seed, err := hex.DecodeString(rp.DrawSeed)
+	sum := sha256.Sum256(seed)
+	verified := err == nil && hex.EncodeToString(sum[:]) == rp.DrawCommitment
+	if scheme == LuckyDrawScheme {
+		proof.Shares = splitLuckyDraw(rp, seed)
+	}
+	for _, d := range draws {
+		random := drawRandom(seed, rp.ID, d.Platform, d.PlatformID)
+		valid := hex.EncodeToString(random) == d.Random
+		if scheme == LuckyDrawScheme {
+			valid = valid && validSlot(proof.Shares, draws, d, preferredSlot(random, rp.TotalCount))
+		} else {
+			units := luckyDrawUnits(rp, d.RemainingUnits.Int(), int64(d.RemainingCount), random)
+			valid = valid && units.Cmp(d.DrawUnits.Int()) == 0
+		}
+		d.Valid = &valid
+		verified = verified && valid
+	}
+	proof.Verified = &verified
+	return proof, nil
+}
+
+// validSlot checks that a pre-split draw took its slot's share, and that
+// every slot it passed over from the one it drew was taken by another claim
+func validSlot(shares []model.Units, draws []*model.LuckyDraw, d *model.LuckyDraw, from int) bool {
+	if d.Slot == nil || *d.Slot < 0 || *d.Slot >= len(shares) {
+		return false
+	}
+	if shares[*d.Slot].Int().Cmp(d.DrawUnits.Int()) != 0 {
+		return false
+	}
+	taken := make(map[int]bool, len(draws))
+	for _, other := range draws {
+		if other != d && other.Slot != nil {
+			taken[*other.Slot] = true
+		}
+	}
+	for slot := from; slot != *d.Slot; slot = (slot + 1) % len(shares) {
+		if !taken[slot] {
+			return false
+		}
+	}
+	return true
+}
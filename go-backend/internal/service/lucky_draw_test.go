@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository/mocks"
+)
+
+func presplitPocket(remaining int64, totalCount, claimedCount int) *model.RedPocket {
+	return &model.RedPocket{
+		ID:             "rp_test",
+		IsLuckyDraw:    true,
+		DrawScheme:     LuckyDrawScheme,
+		TotalCount:     totalCount,
+		ClaimedCount:   claimedCount,
+		Decimals:       6,
+		RemainingUnits: model.NewUnits(big.NewInt(remaining)),
+	}
+}
+
+func TestLuckyDrawShareTakesNextShare(t *testing.T) {
+	random := []byte{7}
+	rp := presplitPocket(10_000_000, 5, 1)
+	store := &mocks.RedPocketStoreMock{
+		NextShareFunc: func(ctx context.Context, id string, from int) (int, model.Units, error) {
+			return (from + 1) % 5, model.NewUnits(big.NewInt(1_230_000)), nil
+		},
+	}
+	s := &RedPocketService{rpRepo: store}
+
+	draw := &model.LuckyDraw{}
+	units, err := s.luckyDrawShare(context.Background(), rp, draw, random)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if units.Cmp(big.NewInt(1_230_000)) != 0 {
+		t.Fatalf("share = %s, want 1230000", units)
+	}
+
+	calls := store.NextShareCalls()
+	if len(calls) != 1 || calls[0].ID != rp.ID || calls[0].From != preferredSlot(random, rp.TotalCount) {
+		t.Fatalf("NextShare calls = %+v, want one from slot %d", calls, preferredSlot(random, rp.TotalCount))
+	}
+	if draw.Slot == nil || *draw.Slot != (calls[0].From+1)%5 {
+		t.Fatalf("draw slot = %v, want %d", draw.Slot, (calls[0].From+1)%5)
+	}
+	if draw.DrawUnits.Int().Cmp(big.NewInt(1_230_000)) != 0 {
+		t.Fatalf("draw units = %s, want 1230000", draw.DrawUnits)
+	}
+}
+
+// The last share takes whatever is left, including what weighted or capped
+// claims did not take
+func TestLuckyDrawShareLastTakesRemaining(t *testing.T) {
+	rp := presplitPocket(2_500_000, 5, 4)
+	s := &RedPocketService{rpRepo: &mocks.RedPocketStoreMock{
+		NextShareFunc: func(ctx context.Context, id string, from int) (int, model.Units, error) {
+			return 3, model.NewUnits(big.NewInt(2_000_000)), nil
+		},
+	}}
+
+	draw := &model.LuckyDraw{}
+	units, err := s.luckyDrawShare(context.Background(), rp, draw, []byte{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if units.Cmp(big.NewInt(2_500_000)) != 0 {
+		t.Fatalf("share = %s, want the remaining 2500000", units)
+	}
+	if draw.DrawUnits.Int().Cmp(big.NewInt(2_000_000)) != 0 {
+		t.Fatalf("draw units = %s, want the slot's 2000000", draw.DrawUnits)
+	}
+}
+
+func TestLuckyDrawShareDepleted(t *testing.T) {
+	rp := presplitPocket(0, 5, 5)
+	s := &RedPocketService{rpRepo: &mocks.RedPocketStoreMock{
+		NextShareFunc: func(ctx context.Context, id string, from int) (int, model.Units, error) {
+			return 0, model.Units{}, pgx.ErrNoRows
+		},
+	}}
+
+	draw := &model.LuckyDraw{}
+	if _, err := s.luckyDrawShare(context.Background(), rp, draw, []byte{1}); !errors.Is(err, ErrRedPocketDepleted) {
+		t.Fatalf("err = %v, want ErrRedPocketDepleted", err)
+	}
+	if draw.Slot != nil {
+		t.Fatalf("depleted draw took slot %d", *draw.Slot)
+	}
+}
+
+// Pockets seeded before shares were pre-split draw from what is left
+// without touching the store
+func TestLuckyDrawShareV1(t *testing.T) {
+	rp := presplitPocket(10_000_000, 5, 0)
+	rp.DrawScheme = ""
+	store := &mocks.RedPocketStoreMock{}
+	s := &RedPocketService{rpRepo: store}
+
+	draw := &model.LuckyDraw{}
+	random := make([]byte, 32)
+	units, err := s.luckyDrawShare(context.Background(), rp, draw, random)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := luckyDrawUnits(rp, rp.RemainingUnits.Int(), 5, random); units.Cmp(want) != 0 {
+		t.Fatalf("share = %s, want %s", units, want)
+	}
+	if len(store.NextShareCalls()) != 0 {
+		t.Fatal("v1 draw asked the store for a share")
+	}
+}
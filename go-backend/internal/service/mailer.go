@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// Email is a message with a plain text and an HTML body
+type Email struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string // extra headers, e.g. List-Unsubscribe
+}
+
+// Mailer sends email
+type Mailer interface {
+	Send(ctx context.Context, email *Email) error
+}
+
+// NewMailer returns an SMTP mailer, or nil when SMTP_HOST is not set and
+// email is off. password is read on every send, so a rotated SMTP_PASSWORD
+// takes effect without a restart.
+func NewMailer(cfg *config.Config, password func() string) Mailer {
+	if cfg.SMTPHost == "" {
+		return nil
+	}
+	return &SMTPMailer{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: password,
+		from:     cfg.EmailFrom,
+	}
+}
+
+// SMTPMailer sends email through an SMTP relay, over implicit TLS on port
+// 465 and STARTTLS elsewhere when the relay offers it
+type SMTPMailer struct {
+	host     string
+	port     int
+	username string
+	password func() string
+	from     string
+}
+
+const smtpTimeout = 30 * time.Second
+
+func (m *SMTPMailer) Send(ctx context.Context, email *Email) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid EMAIL_FROM: %w", err)
+	}
+	to, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	msg, err := buildEmail(from, to, email)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	var conn net.Conn
+	if m.port == 465 {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: m.host}}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if m.port != 465 {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				return fmt.Errorf("STARTTLS failed: %w", err)
+			}
+		}
+	}
+	if m.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.username, m.password(), m.host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildEmail renders a multipart/alternative message
+func buildEmail(from, to *mail.Address, email *Email) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", email.Text},
+		{"text/html; charset=UTF-8", email.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if at := strings.LastIndexByte(from.Address, '@'); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+
+	var msg bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&msg, "%s: %s\r\n", k, v) }
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("UTF-8", email.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+hex.EncodeToString(id)+"@"+domain+">")
+	header("MIME-Version", "1.0")
+	for k, v := range email.Headers {
+		header(k, v)
+	}
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
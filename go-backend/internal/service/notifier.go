@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/bot"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// Notifier delivers operational alerts to the ops team, or messages to an
+// enterprise's chat channel
+type Notifier interface {
+	Notify(ctx context.Context, title, message string) error
+}
+
+// NewNotifier returns a webhook notifier when ALERT_WEBHOOK_URL is set, otherwise alerts only go to the log
+func NewNotifier(cfg *config.Config) Notifier {
+	if cfg.AlertWebhookURL == "" {
+		return LogNotifier{}
+	}
+	return &WebhookNotifier{
+		url: cfg.AlertWebhookURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, title, message string) error {
+	slog.ErrorContext(ctx, "alert", "title", title, "message", message)
+	return nil
+}
+
+// WebhookNotifier posts alerts to a Slack or Discord compatible incoming webhook
+type WebhookNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, title, message string) error {
+	slog.ErrorContext(ctx, "alert", "title", title, "message", message)
+
+	text := fmt.Sprintf("*%s*\n%s", title, message)
+	body, _ := json.Marshal(map[string]string{
+		"text":    text, // Slack
+		"content": text, // Discord
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ChannelNotifier posts to a Telegram chat, Discord channel or Slack channel
+// through the bots
+type ChannelNotifier struct {
+	telegram *bot.TelegramBot
+	discord  *bot.DiscordBot
+	slack    *bot.SlackBot
+
+	platform  string
+	channelID string
+}
+
+// NewChannelNotifier returns a notifier for a channel given as pockets carry
+// it, or an error when the channel ID is malformed for its platform
+func NewChannelNotifier(telegram *bot.TelegramBot, discord *bot.DiscordBot, slack *bot.SlackBot, platform, channelID string) (*ChannelNotifier, error) {
+	n := &ChannelNotifier{telegram: telegram, discord: discord, slack: slack, platform: platform, channelID: channelID}
+	switch platform {
+	case "telegram":
+		if _, err := strconv.ParseInt(channelID, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid telegram chat id %q", channelID)
+		}
+	case "discord":
+		if channelID == "" {
+			return nil, fmt.Errorf("discord channel id is required")
+		}
+	case "slack":
+		if _, _, ok := bot.ParseSlackChannel(channelID); !ok {
+			return nil, fmt.Errorf("invalid slack channel %q, expected TEAM_ID:CHANNEL_ID", channelID)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported platform %q", platform)
+	}
+	return n, nil
+}
+
+func (n *ChannelNotifier) Notify(ctx context.Context, title, message string) error {
+	switch n.platform {
+	case "telegram":
+		chatID, _ := strconv.ParseInt(n.channelID, 10, 64)
+		// Plain text, as messages carry pocket and sender names
+		return n.telegram.SendMessage(chatID, title+"\n\n"+message, "")
+	case "discord":
+		return n.discord.SendMessage(n.channelID, &bot.DiscordMessage{
+			Embeds: []bot.DiscordEmbed{{Title: title, Description: message}},
+		})
+	case "slack":
+		teamID, channelID, _ := bot.ParseSlackChannel(n.channelID)
+		return n.slack.PostMessage(ctx, teamID, &bot.SlackMessage{
+			Channel: channelID,
+			Text:    fmt.Sprintf("*%s*\n%s", title, message),
+		})
+	}
+	return fmt.Errorf("unsupported platform %q", n.platform)
+}
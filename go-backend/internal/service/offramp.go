@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrOffRampUnavailable        = newCodedError("offramp_unavailable")
+	ErrOffRampNetworkUnsupported = newCodedError("offramp_network_unsupported")
+
+	ErrInvalidOffRampWebhook = errors.New("invalid off-ramp webhook signature")
+)
+
+// Off-ramp providers
+const (
+	OffRampProviderTransak = "transak"
+)
+
+const (
+	// WithdrawalAwaitingOrder is an off-ramp withdrawal waiting for the user
+	// to place the order with the provider
+	WithdrawalAwaitingOrder = "awaiting_order"
+
+	offRampExpiryInterval = time.Minute
+)
+
+// transakNetworks are Transak's names for the chains it off-ramps from
+var transakNetworks = map[int64]string{
+	1:     "ethereum",
+	10:    "optimism",
+	56:    "bsc",
+	137:   "polygon",
+	8453:  "base",
+	42161: "arbitrum",
+}
+
+// transakAwaitingPayment is the status of a Transak sell order waiting for
+// the tokens
+const transakAwaitingPayment = "AWAITING_PAYMENT_FROM_USER"
+
+// transakClosed are the statuses of orders that ended before the provider
+// got the tokens, or that it refunded
+var transakClosed = map[string]bool{
+	"FAILED":    true,
+	"EXPIRED":   true,
+	"CANCELLED": true,
+	"REFUNDED":  true,
+}
+
+// OffRampService cashes wallet balances out to a bank account or card
+// through a fiat off-ramp provider. The user places the order in the
+// provider's widget; once the provider's webhook gives the order's deposit
+// address, the tokens are sent there from the user's custodial wallet as a
+// wallet withdrawal and the provider pays out the fiat.
+type OffRampService struct {
+	repo       *repository.LedgerRepository
+	ledger     *LedgerService
+	httpClient *http.Client
+	cfg        *config.Config
+}
+
+func NewOffRampService(repo *repository.LedgerRepository, ledger *LedgerService, cfg *config.Config) *OffRampService {
+	return &OffRampService{
+		repo:   repo,
+		ledger: ledger,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		cfg: cfg,
+	}
+}
+
+// Enabled reports whether an off-ramp provider is configured
+func (s *OffRampService) Enabled() bool {
+	return s.cfg.OffRampProvider != ""
+}
+
+type OffRampQuoteRequest struct {
+	Amount        float64 `form:"amount" binding:"required,gt=0"`
+	Token         string  `form:"token"`   // default USDC
+	ChainID       int64   `form:"chainId"` // default CHAIN_ID
+	FiatCurrency  string  `form:"fiatCurrency" binding:"required,len=3"`
+	PaymentMethod string  `form:"paymentMethod"` // provider's payment method ID; default the provider's pick
+	Country       string  `form:"country" binding:"omitempty,len=2"`
+}
+
+// Quote asks the provider how much fiat an amount of tokens cashes out to
+func (s *OffRampService) Quote(ctx context.Context, req *OffRampQuoteRequest) (*model.OffRampQuote, error) {
+	if !s.Enabled() {
+		return nil, ErrOffRampUnavailable
+	}
+	token, chainID := s.ledger.withdrawalDefaults(req.Token, req.ChainID)
+
+	switch s.cfg.OffRampProvider {
+	case OffRampProviderTransak:
+		return s.quoteTransak(ctx, req, token, chainID)
+	}
+	return nil, fmt.Errorf("unknown off-ramp provider: %s", s.cfg.OffRampProvider)
+}
+
+type OffRampOrderRequest struct {
+	UserID        string  `json:"-"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Token         string  `json:"token"`   // default USDC
+	ChainID       int64   `json:"chainId"` // default CHAIN_ID
+	FiatCurrency  string  `json:"fiatCurrency" binding:"required,len=3"`
+	PaymentMethod string  `json:"paymentMethod"`
+	Country       string  `json:"country" binding:"omitempty,len=2"`
+	WalletAddress string  `json:"walletAddress"` // optional; must be the user's wallet
+	TokenAddress  string  `json:"tokenAddress"`  // default the registered address of token
+	RedirectURL   string  `json:"redirectUrl" binding:"omitempty,url"`
+
+	TravelRule *model.TravelRuleInfo `json:"travelRule"` // required at or above TRAVEL_RULE_THRESHOLD
+}
+
+// CreateOrder starts cashing out from the user's custodial wallet. The
+// withdrawal is recorded as awaiting_order with the quoted fiat amount, and
+// the returned URL opens the provider's widget where the user completes KYC
+// and bank details and places the order. Nothing leaves the wallet until the
+// provider's webhook reports the order. Only the user ctx was
+// authenticated as, by a claimer session, can cash out their wallet.
+func (s *OffRampService) CreateOrder(ctx context.Context, req *OffRampOrderRequest) (*model.Withdrawal, string, error) {
+	if !s.Enabled() {
+		return nil, "", ErrOffRampUnavailable
+	}
+	if err := s.ledger.checkTravelRule(req.Amount, req.TravelRule); err != nil {
+		return nil, "", err
+	}
+
+	quote, err := s.Quote(ctx, &OffRampQuoteRequest{
+		Amount:        req.Amount,
+		Token:         req.Token,
+		ChainID:       req.ChainID,
+		FiatCurrency:  req.FiatCurrency,
+		PaymentMethod: req.PaymentMethod,
+		Country:       req.Country,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	wallet, registered, err := s.ledger.walletSource(ctx, req.UserID, quote.ChainID, req.WalletAddress, quote.Token, req.TokenAddress, req.Amount)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	w := &model.Withdrawal{
+		ID:           "withdraw_" + uuid.New().String()[:8],
+		UserID:       req.UserID,
+		Source:       WithdrawalSourceWallet,
+		FromAddress:  wallet.Address,
+		ChainID:      quote.ChainID,
+		Token:        quote.Token,
+		TokenAddress: common.HexToAddress(registered.Address).Hex(),
+		Amount:       req.Amount,
+		Speed:        WithdrawalSpeedInstant,
+		Status:       WithdrawalAwaitingOrder,
+		ScheduledAt:  now,
+		TravelRule:   req.TravelRule,
+		CreatedAt:    now,
+		OffRamp: &model.OffRampOrder{
+			Provider:      s.cfg.OffRampProvider,
+			FiatCurrency:  quote.FiatCurrency,
+			FiatAmount:    quote.FiatAmount,
+			PaymentMethod: quote.PaymentMethod,
+		},
+	}
+	if err := s.repo.CreateWalletWithdrawal(ctx, w); err != nil {
+		return nil, "", fmt.Errorf("failed to create withdrawal: %w", err)
+	}
+
+	return w, s.widgetURL(w, req.RedirectURL), nil
+}
+
+// widgetURL is where the user places the off-ramp order for w. The order
+// carries the withdrawal ID back in the provider's webhooks; refunds go to
+// the user's wallet.
+func (s *OffRampService) widgetURL(w *model.Withdrawal, redirectURL string) string {
+	params := url.Values{}
+	params.Set("apiKey", s.cfg.OffRampAPIKey)
+	params.Set("productsAvailed", "SELL")
+	params.Set("cryptoCurrencyCode", w.Token)
+	params.Set("network", transakNetworks[w.ChainID])
+	params.Set("cryptoAmount", strconv.FormatFloat(w.Amount, 'f', -1, 64))
+	params.Set("fiatCurrency", w.OffRamp.FiatCurrency)
+	if w.OffRamp.PaymentMethod != "" {
+		params.Set("paymentMethod", w.OffRamp.PaymentMethod)
+	}
+	params.Set("walletAddress", w.FromAddress)
+	params.Set("partnerOrderId", w.ID)
+	params.Set("partnerCustomerId", w.UserID)
+	if redirectURL != "" {
+		params.Set("redirectURL", redirectURL)
+	}
+	return strings.TrimRight(s.cfg.OffRampWidgetURL, "/") + "?" + params.Encode()
+}
+
+// offRampEvent is an order update decoded from a provider's webhook
+type offRampEvent struct {
+	WithdrawalID   string
+	Order          model.OffRampOrder
+	Token          string
+	Amount         float64
+	DepositAddress string // where the provider wants the tokens
+	AwaitingFunds  bool   // the order is waiting for the tokens
+	Closed         bool   // the order ended without the provider keeping the tokens
+}
+
+// HandleWebhook verifies and applies a provider's order update. The first
+// update with a deposit address sends the tokens; a closed order drops a
+// withdrawal still waiting for one. Updates for unknown or mismatched
+// withdrawals are logged and ignored so the provider stops retrying them.
+func (s *OffRampService) HandleWebhook(ctx context.Context, body []byte) error {
+	if !s.Enabled() {
+		return ErrOffRampUnavailable
+	}
+
+	var event *offRampEvent
+	var err error
+	switch s.cfg.OffRampProvider {
+	case OffRampProviderTransak:
+		event, err = s.parseTransakWebhook(body)
+	default:
+		err = fmt.Errorf("unknown off-ramp provider: %s", s.cfg.OffRampProvider)
+	}
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return nil
+	}
+
+	w, err := s.repo.GetWithdrawal(ctx, event.WithdrawalID)
+	if err != nil || w.OffRamp == nil {
+		slog.WarnContext(ctx, "offramp: update for unknown withdrawal", "withdrawal_id", event.WithdrawalID, "order_id", event.Order.OrderID)
+		return nil
+	}
+	if w.OffRamp.OrderID != "" && w.OffRamp.OrderID != event.Order.OrderID {
+		slog.WarnContext(ctx, "offramp: ignoring update for another order", "withdrawal_id", w.ID, "order_id", w.OffRamp.OrderID, "update_order_id", event.Order.OrderID)
+		return nil
+	}
+
+	if w.Status == WithdrawalAwaitingOrder {
+		switch {
+		case event.AwaitingFunds:
+			return s.place(ctx, w, event)
+		case event.Closed:
+			if err := s.repo.UpdateOffRampOrder(ctx, w.ID, &event.Order); err != nil {
+				return err
+			}
+			return s.ledger.fail(ctx, w, fmt.Sprintf("off-ramp order %s %s", event.Order.OrderID, strings.ToLower(event.Order.Status)))
+		}
+	}
+	return s.repo.UpdateOffRampOrder(ctx, w.ID, &event.Order)
+}
+
+// place sends a withdrawal's tokens to the deposit address of the order the
+// user placed. The order has to be for the withdrawal's token and amount.
+func (s *OffRampService) place(ctx context.Context, w *model.Withdrawal, event *offRampEvent) error {
+	reason := ""
+	switch {
+	case !strings.EqualFold(event.Token, w.Token):
+		reason = fmt.Sprintf("off-ramp order %s is for %s, not %s", event.Order.OrderID, event.Token, w.Token)
+	case floatToBigInt(event.Amount, 6).Cmp(floatToBigInt(w.Amount, 6)) != 0:
+		reason = fmt.Sprintf("off-ramp order %s is for %v %s, not %v", event.Order.OrderID, event.Amount, w.Token, w.Amount)
+	case !common.IsHexAddress(event.DepositAddress):
+		reason = fmt.Sprintf("off-ramp order %s has no valid deposit address", event.Order.OrderID)
+	}
+	if reason != "" {
+		if err := s.repo.UpdateOffRampOrder(ctx, w.ID, &event.Order); err != nil {
+			return err
+		}
+		return s.ledger.fail(ctx, w, reason)
+	}
+
+	w.ToAddress = common.HexToAddress(event.DepositAddress).Hex()
+	order := event.Order
+	if order.FiatAmount == 0 {
+		order.FiatAmount = w.OffRamp.FiatAmount
+	}
+	w.OffRamp = &order
+	if err := s.ledger.screen(ctx, w); err != nil {
+		if errors.Is(err, ErrAddressSanctioned) {
+			return s.ledger.fail(ctx, w, err.Error())
+		}
+		// Leave the withdrawal waiting; the provider retries the webhook
+		return err
+	}
+
+	placed, err := s.repo.PlaceOffRampOrder(ctx, w)
+	if err != nil || !placed {
+		return err
+	}
+	w.Status = "pending"
+
+	// Send now rather than waiting for the poller; a failed send is recorded
+	// on the withdrawal, not returned
+	if err := s.ledger.process(ctx, w); err != nil {
+		slog.ErrorContext(ctx, "offramp: withdrawal failed", "withdrawal_id", w.ID, "error", err)
+	}
+	return nil
+}
+
+// Start drops off-ramp withdrawals whose order was never placed until ctx is
+// cancelled
+func (s *OffRampService) Start(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(offRampExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		s.expire(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *OffRampService) expire(ctx context.Context) {
+	before := time.Now().Add(-time.Duration(s.cfg.OffRampOrderTTL) * time.Second)
+	withdrawals, err := s.repo.ListStaleOffRampWithdrawals(ctx, before, withdrawalBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "offramp: failed to list stale withdrawals", "error", err)
+		return
+	}
+	for _, w := range withdrawals {
+		if err := s.ledger.fail(ctx, w, "off-ramp order was not placed in time"); err != nil {
+			slog.ErrorContext(ctx, "offramp: failed to expire withdrawal", "withdrawal_id", w.ID, "error", err)
+		}
+	}
+}
+
+// quoteTransak queries Transak's public pricing API for a sell quote
+func (s *OffRampService) quoteTransak(ctx context.Context, req *OffRampQuoteRequest, token string, chainID int64) (*model.OffRampQuote, error) {
+	network, ok := transakNetworks[chainID]
+	if !ok {
+		return nil, ErrOffRampNetworkUnsupported
+	}
+
+	params := url.Values{}
+	params.Set("partnerApiKey", s.cfg.OffRampAPIKey)
+	params.Set("isBuyOrSell", "SELL")
+	params.Set("cryptoCurrency", token)
+	params.Set("network", network)
+	params.Set("cryptoAmount", strconv.FormatFloat(req.Amount, 'f', -1, 64))
+	params.Set("fiatCurrency", strings.ToUpper(req.FiatCurrency))
+	if req.PaymentMethod != "" {
+		params.Set("paymentMethod", req.PaymentMethod)
+	}
+	if req.Country != "" {
+		params.Set("quoteCountryCode", strings.ToUpper(req.Country))
+	}
+
+	endpoint := strings.TrimRight(s.cfg.OffRampAPIURL, "/") + "/api/v1/pricing/public/quotes?" + params.Encode()
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Response struct {
+			QuoteID         string  `json:"quoteId"`
+			ConversionPrice float64 `json:"conversionPrice"`
+			FiatCurrency    string  `json:"fiatCurrency"`
+			FiatAmount      float64 `json:"fiatAmount"`
+			TotalFee        float64 `json:"totalFee"`
+			PaymentMethod   string  `json:"paymentMethod"`
+		} `json:"response"`
+	}
+	if err := s.doJSON(httpReq, &result); err != nil {
+		return nil, fmt.Errorf("off-ramp quote failed: %w", err)
+	}
+
+	if result.Response.FiatCurrency == "" {
+		result.Response.FiatCurrency = strings.ToUpper(req.FiatCurrency)
+	}
+	return &model.OffRampQuote{
+		Provider:      OffRampProviderTransak,
+		QuoteID:       result.Response.QuoteID,
+		Token:         token,
+		ChainID:       chainID,
+		Amount:        req.Amount,
+		FiatCurrency:  result.Response.FiatCurrency,
+		FiatAmount:    result.Response.FiatAmount,
+		Fee:           result.Response.TotalFee,
+		Rate:          result.Response.ConversionPrice,
+		PaymentMethod: result.Response.PaymentMethod,
+	}, nil
+}
+
+// parseTransakWebhook decodes a Transak webhook. The order is sent as a JWT
+// signed with the partner access token. Buy orders are ignored.
+func (s *OffRampService) parseTransakWebhook(body []byte) (*offRampEvent, error) {
+	var envelope struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Data == "" {
+		return nil, ErrInvalidOffRampWebhook
+	}
+
+	var claims struct {
+		EventID     string `json:"eventID"`
+		WebhookData struct {
+			ID                string  `json:"id"`
+			PartnerOrderID    string  `json:"partnerOrderId"`
+			Status            string  `json:"status"`
+			IsBuyOrSell       string  `json:"isBuyOrSell"`
+			CryptoCurrency    string  `json:"cryptoCurrency"`
+			CryptoAmount      float64 `json:"cryptoAmount"`
+			FiatCurrency      string  `json:"fiatCurrency"`
+			FiatAmount        float64 `json:"fiatAmount"`
+			PaymentOptionID   string  `json:"paymentOptionId"`
+			CryptoPaymentData struct {
+				PaymentAddress string `json:"paymentAddress"`
+			} `json:"cryptoPaymentData"`
+		} `json:"webhookData"`
+		jwt.RegisteredClaims
+	}
+	_, err := jwt.ParseWithClaims(envelope.Data, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.cfg.OffRampWebhookSecret), nil
+	})
+	if err != nil {
+		return nil, ErrInvalidOffRampWebhook
+	}
+
+	order := claims.WebhookData
+	if order.IsBuyOrSell != "SELL" || order.PartnerOrderID == "" {
+		return nil, nil
+	}
+	return &offRampEvent{
+		WithdrawalID: order.PartnerOrderID,
+		Order: model.OffRampOrder{
+			Provider:      OffRampProviderTransak,
+			OrderID:       order.ID,
+			Status:        order.Status,
+			FiatCurrency:  order.FiatCurrency,
+			FiatAmount:    order.FiatAmount,
+			PaymentMethod: order.PaymentOptionID,
+		},
+		Token:          order.CryptoCurrency,
+		Amount:         order.CryptoAmount,
+		DepositAddress: order.CryptoPaymentData.PaymentAddress,
+		AwaitingFunds:  order.Status == transakAwaitingPayment,
+		Closed:         transakClosed[order.Status],
+	}, nil
+}
+
+func (s *OffRampService) doJSON(req *http.Request, out interface{}) error {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("provider returned %d: %s", resp.StatusCode, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrPartnerNotFound       = newCodedError("partner_not_found")
+	ErrPartnerCodeTaken      = newCodedError("partner_code_taken")
+	ErrPartnerAttached       = newCodedError("partner_already_attached")
+	ErrInvalidPartnerKey     = newCodedError("invalid_partner_key")
+	ErrInvalidStatementRange = newCodedError("invalid_statement_range")
+)
+
+// maxStatementMonths caps how far back one statements request reaches
+const maxStatementMonths = 24
+
+// PartnerService runs the partner program: partners are created by an
+// operator, enterprises attach a partner's code once, and partners read the
+// enterprises attributed to them and their monthly statements with their
+// reporting key
+type PartnerService struct {
+	repo           *repository.PartnerRepository
+	enterpriseRepo *repository.EnterpriseRepository
+}
+
+func NewPartnerService(repo *repository.PartnerRepository, enterpriseRepo *repository.EnterpriseRepository) *PartnerService {
+	return &PartnerService{repo: repo, enterpriseRepo: enterpriseRepo}
+}
+
+type CreatePartnerRequest struct {
+	Name            string `json:"name" binding:"required,max=120"`
+	Email           string `json:"email" binding:"omitempty,email,max=255"`
+	Code            string `json:"code" binding:"required,alphanum,min=3,max=32"` // stored upper case
+	RevenueShareBps int    `json:"revenueShareBps" binding:"min=0,max=10000"`
+}
+
+// PartnerKey is a newly created partner's reporting key; Key is not shown
+// again
+type PartnerKey struct {
+	Key string `json:"key"`
+}
+
+// Create records a partner and issues its reporting key
+func (s *PartnerService) Create(ctx context.Context, req *CreatePartnerRequest) (*model.Partner, *PartnerKey, error) {
+	key, hash, err := newPartnerKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &model.Partner{
+		ID:              "partner_" + uuid.New().String()[:8],
+		Name:            strings.TrimSpace(req.Name),
+		Email:           req.Email,
+		Code:            strings.ToUpper(req.Code),
+		RevenueShareBps: req.RevenueShareBps,
+		Status:          "active",
+		APIKeyHash:      hash,
+		CreatedAt:       time.Now(),
+	}
+	ok, err := s.repo.Create(ctx, p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create partner: %w", err)
+	}
+	if !ok {
+		return nil, nil, ErrPartnerCodeTaken
+	}
+	return p, &PartnerKey{Key: key}, nil
+}
+
+// Authenticate returns the active partner a reporting key belongs to
+func (s *PartnerService) Authenticate(ctx context.Context, key string) (*model.Partner, error) {
+	p, err := s.repo.GetByAPIKeyHash(ctx, hashEnterpriseKey(key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidPartnerKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up partner key: %w", err)
+	}
+	return p, nil
+}
+
+// Get returns a partner
+func (s *PartnerService) Get(ctx context.Context, id string) (*model.Partner, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPartnerNotFound
+	}
+	return p, err
+}
+
+// EnterprisePartner returns the partner an enterprise is attributed to, nil
+// when it has none
+func (s *PartnerService) EnterprisePartner(ctx context.Context, enterpriseID string) (*model.Partner, error) {
+	e, err := s.enterpriseRepo.GetByID(ctx, enterpriseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEnterpriseNotFound
+	}
+	if err != nil || e.PartnerID == "" {
+		return nil, err
+	}
+	return s.Get(ctx, e.PartnerID)
+}
+
+// Attach attributes an enterprise to the partner with code. An enterprise
+// has at most one partner, attached once, normally when it signs up; only
+// activity from then on counts towards the partner.
+func (s *PartnerService) Attach(ctx context.Context, enterpriseID, code string) (*model.Partner, error) {
+	p, err := s.repo.GetByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPartnerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up partner code: %w", err)
+	}
+	ok, err := s.repo.Attach(ctx, enterpriseID, p.ID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach partner: %w", err)
+	}
+	if !ok {
+		if _, err := s.enterpriseRepo.GetByID(ctx, enterpriseID); errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEnterpriseNotFound
+		}
+		return nil, ErrPartnerAttached
+	}
+	return p, nil
+}
+
+// ListEnterprises returns the enterprises attributed to a partner
+func (s *PartnerService) ListEnterprises(ctx context.Context, partnerID string, page, limit int) ([]*model.PartnerEnterprise, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return s.repo.ListEnterprises(ctx, partnerID, limit, offset)
+}
+
+// Statements returns a partner's monthly statements from month from through
+// month to (YYYY-MM, UTC), newest first, with its commission on the platform
+// fees. Empty bounds default to the last 12 months up to the current one.
+func (s *PartnerService) Statements(ctx context.Context, p *model.Partner, from, to string) ([]*model.PartnerStatement, error) {
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		t, err := time.Parse("2006-01", to)
+		if err != nil {
+			return nil, ErrInvalidStatementRange
+		}
+		end = t
+	}
+	start := end.AddDate(0, -11, 0)
+	if from != "" {
+		t, err := time.Parse("2006-01", from)
+		if err != nil {
+			return nil, ErrInvalidStatementRange
+		}
+		start = t
+	}
+	if start.After(end) || start.AddDate(0, maxStatementMonths, 0).Before(end.AddDate(0, 1, 0)) {
+		return nil, ErrInvalidStatementRange
+	}
+
+	statements, err := s.repo.Statements(ctx, p.ID, start, end.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load statements: %w", err)
+	}
+	for _, st := range statements {
+		st.Commission = math.Round(st.PlatformFees*float64(p.RevenueShareBps)/10000*1e8) / 1e8
+	}
+	return statements, nil
+}
+
+func newPartnerKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate partner key: %w", err)
+	}
+	key = "pk_" + hex.EncodeToString(b)
+	return key, hashEnterpriseKey(key), nil
+}
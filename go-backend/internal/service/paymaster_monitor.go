@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+const (
+	paymasterCheckInterval = 5 * time.Minute
+	// Sponsorship rejections per check interval that count as an exhausted budget
+	sponsorFailureThreshold = 3
+)
+
+// PaymasterMonitor watches the paymaster's EntryPoint deposit and sponsorship
+// rejections. It alerts ops when the deposit runs low and, when the budget is
+// exhausted, switches new payouts to the ERC-20 paymaster until it recovers.
+type PaymasterMonitor struct {
+	walletSvc  *WalletService
+	notifier   Notifier
+	cfg        *config.Config
+	httpClient *http.Client
+	minDeposit *big.Int
+
+	lowAlerted bool
+}
+
+func NewPaymasterMonitor(walletSvc *WalletService, notifier Notifier, cfg *config.Config) *PaymasterMonitor {
+	minDeposit, ok := new(big.Int).SetString(cfg.PaymasterMinDepositWei, 10)
+	if !ok {
+		minDeposit = big.NewInt(0)
+	}
+	return &PaymasterMonitor{
+		walletSvc: walletSvc,
+		notifier:  notifier,
+		cfg:       cfg,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		minDeposit: minDeposit,
+	}
+}
+
+// Start checks the paymaster until ctx is cancelled
+func (m *PaymasterMonitor) Start(ctx context.Context) {
+	if m.walletSvc.Simulated() {
+		slog.InfoContext(ctx, "paymaster monitor: simulation mode, monitor disabled")
+		return
+	}
+
+	ticker := time.NewTicker(paymasterCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+func (m *PaymasterMonitor) check(ctx context.Context) {
+	failures := m.walletSvc.takeSponsorFailures(ctx)
+
+	var deposit *big.Int
+	if m.cfg.PaymasterAddress != "" {
+		d, err := m.deposit(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "paymaster monitor: failed to read deposit", "error", err)
+		} else {
+			deposit = d
+		}
+	}
+
+	low := deposit != nil && deposit.Cmp(m.minDeposit) < 0
+	exhausted := failures >= sponsorFailureThreshold ||
+		(deposit != nil && deposit.Cmp(new(big.Int).Div(m.minDeposit, big.NewInt(4))) < 0)
+
+	if low && !m.lowAlerted {
+		m.alert(ctx, "Paymaster deposit low",
+			fmt.Sprintf("Paymaster %s has %s wei deposited in the EntryPoint (threshold %s). Top it up before claims start failing.",
+				m.cfg.PaymasterAddress, deposit, m.minDeposit))
+	}
+	m.lowAlerted = low
+
+	mode := m.walletSvc.PaymasterMode(ctx)
+	switch {
+	case exhausted && mode == PaymasterModeSponsored:
+		reason := fmt.Sprintf("%d sponsorship rejections in the last %s", failures, paymasterCheckInterval)
+		if deposit != nil {
+			reason += fmt.Sprintf(", deposit %s wei", deposit)
+		}
+		if !m.cfg.PaymasterAutoFallback || m.cfg.ERC20PaymasterAddress == "" {
+			m.alert(ctx, "Paymaster sponsorship exhausted", reason+". ERC-20 fallback is not enabled; claims will fail until the budget is restored.")
+			return
+		}
+		m.walletSvc.SetPaymasterMode(ctx, PaymasterModeERC20)
+		m.alert(ctx, "Switched to ERC-20 paymaster", reason+". New payouts now pay gas in USDC from the recipient wallet.")
+
+	case !exhausted && !low && failures == 0 && mode == PaymasterModeERC20:
+		m.walletSvc.SetPaymasterMode(ctx, PaymasterModeSponsored)
+		m.alert(ctx, "Paymaster sponsorship restored", "Deposit is above threshold and sponsorship is succeeding again; switched back to sponsored payouts.")
+	}
+}
+
+// deposit reads the paymaster's balance in the EntryPoint
+func (m *PaymasterMonitor) deposit(ctx context.Context) (*big.Int, error) {
+	// balanceOf(address) selector: 0x70a08231
+	data := "0x70a08231" + hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(m.cfg.PaymasterAddress).Bytes(), 32))
+
+	result, err := callRPC(ctx, m.httpClient, m.cfg.RPCUrl, "eth_call", map[string]string{
+		"to":   m.cfg.EntryPoint,
+		"data": data,
+	}, "latest")
+	if err != nil {
+		return nil, err
+	}
+
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(hexValue, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance: %s", hexValue)
+	}
+	return balance, nil
+}
+
+func (m *PaymasterMonitor) alert(ctx context.Context, title, message string) {
+	if err := m.notifier.Notify(ctx, title, message); err != nil {
+		slog.ErrorContext(ctx, "paymaster monitor: failed to send alert", "title", title, "error", err)
+	}
+}
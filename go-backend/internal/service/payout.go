@@ -0,0 +1,791 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/metrics"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrClaimNotFound      = newCodedError("claim_not_found")
+	ErrPayoutNotRetryable = newCodedError("payout_not_retryable")
+)
+
+const (
+	payoutPollInterval  = 2 * time.Second
+	payoutStaleInterval = time.Minute
+)
+
+// PayoutQueue runs claim transfers on a worker pool so the claim request only
+// has to record the claim. Jobs live in Postgres and act as an outbox: a
+// claim's job is written in the claim's own transaction, workers on every
+// instance poll the queue, and Wake starts a local worker right away.
+//
+// Delivery is at least once. A job whose worker died is picked up again, and
+// a job is never paid twice: user operations are recorded against their
+// claim before they are sent, and a claim that already has one is handed to
+// the UserOpMonitor instead of being paid again.
+//
+// With a settlement window configured, jobs are held for the window and then
+// settled per pocket: claims are netted per recipient and paid from the
+// pocket's payout wallet in a single batched user operation.
+//
+// A transfer that fails before anything is sent is retried with backoff
+// while the failure looks transient, such as a bundler or RPC outage. One
+// retrying cannot fix, or one that spends PAYOUT_MAX_ATTEMPTS, dead-letters
+// the job: its claim fails, ops are alerted, and the enterprise can Retry it
+// once the cause is fixed.
+type PayoutQueue struct {
+	repo       *repository.PayoutJobRepository
+	batchRepo  *repository.PayoutBatchRepository
+	claimRepo  repository.ClaimStore
+	rpRepo     repository.RedPocketStore
+	userOpRepo *repository.UserOpRepository
+	walletSvc  *WalletService
+	savings    *SavingsService
+	screener   *SanctionsScreener
+	webhooks   *WebhookService
+	notifier   Notifier
+	cfg        *config.Config
+	wake       chan struct{}
+}
+
+func NewPayoutQueue(
+	repo *repository.PayoutJobRepository,
+	batchRepo *repository.PayoutBatchRepository,
+	claimRepo repository.ClaimStore,
+	rpRepo repository.RedPocketStore,
+	userOpRepo *repository.UserOpRepository,
+	walletSvc *WalletService,
+	savings *SavingsService,
+	screener *SanctionsScreener,
+	webhooks *WebhookService,
+	notifier Notifier,
+	cfg *config.Config,
+) *PayoutQueue {
+	return &PayoutQueue{
+		repo:       repo,
+		batchRepo:  batchRepo,
+		claimRepo:  claimRepo,
+		rpRepo:     rpRepo,
+		userOpRepo: userOpRepo,
+		walletSvc:  walletSvc,
+		savings:    savings,
+		screener:   screener,
+		webhooks:   webhooks,
+		notifier:   notifier,
+		cfg:        cfg,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Batched reports whether claims are settled in batches per pocket
+func (q *PayoutQueue) Batched() bool {
+	return q.cfg.SettlementBatchWindow > 0
+}
+
+// PayoutWalletID is the wallet owner ID of a pocket's payout wallet, which
+// its deposit is made to and every claim is paid from, batched or not.
+func PayoutWalletID(redPocketID string) string {
+	return "pocket_" + redPocketID
+}
+
+// NewJob builds the payout job of a pending claim, to be written with the
+// claim. Call Wake once it is committed.
+func (q *PayoutQueue) NewJob(claimID string) *model.PayoutJob {
+	job := &model.PayoutJob{
+		ID:        "payout_" + uuid.New().String()[:8],
+		ClaimID:   claimID,
+		Status:    "queued",
+		NextRunAt: time.Now(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if q.Batched() {
+		// Wait out the window; the first claim to come due takes the rest of its pocket with it
+		job.NextRunAt = job.NextRunAt.Add(time.Duration(q.cfg.SettlementBatchWindow) * time.Second)
+	}
+	return job
+}
+
+// NewHeldJob builds the payout job of a claim flagged by fraud scoring,
+// held until then unless Release or Cancel settles it first. The claim is
+// written with status held.
+func (q *PayoutQueue) NewHeldJob(claimID string, until time.Time) *model.PayoutJob {
+	return &model.PayoutJob{
+		ID:        "payout_" + uuid.New().String()[:8],
+		ClaimID:   claimID,
+		Status:    "held",
+		NextRunAt: until,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Wake starts a local worker on newly queued jobs. Batched jobs wait out the
+// settlement window anyway.
+func (q *PayoutQueue) Wake() {
+	if q.Batched() {
+		return
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Release pays a held claim now. It reports false if the claim is not held.
+func (q *PayoutQueue) Release(ctx context.Context, claimID string) (bool, error) {
+	released, err := q.repo.Release(ctx, claimID)
+	if err != nil || !released {
+		return false, err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return true, nil
+}
+
+// Cancel blocks a held claim instead of paying it. It reports false if the
+// claim is not held.
+func (q *PayoutQueue) Cancel(ctx context.Context, claimID, reason string) (bool, error) {
+	cancelled, err := q.repo.Cancel(ctx, claimID, reason)
+	if err != nil || !cancelled {
+		return false, err
+	}
+	metrics.Payouts.Inc("blocked")
+	return true, nil
+}
+
+// Status reports where a claim's payout is
+func (q *PayoutQueue) Status(ctx context.Context, claimID string) (*model.PayoutStatus, error) {
+	claim, err := q.claimRepo.GetByID(ctx, claimID)
+	if err != nil {
+		return nil, ErrClaimNotFound
+	}
+
+	status := &model.PayoutStatus{
+		ClaimID:       claim.ID,
+		RedPocketID:   claim.RedPocketID,
+		Status:        claim.Status,
+		Amount:        claim.Amount,
+		WalletAddress: claim.WalletAddress,
+		TxHash:        claim.TxHash,
+		Attempts:      claim.Attempts,
+		ClaimedAt:     claim.CreatedAt,
+		CompletedAt:   claim.CompletedAt,
+	}
+
+	job, err := q.repo.GetByClaim(ctx, claimID)
+	switch {
+	case err == nil:
+		status.LastError = job.LastError
+		if job.Status == "queued" && job.Attempts > 0 {
+			status.NextRetryAt = &job.NextRunAt
+		}
+		status.DeadLettered = job.Status == "dead"
+		if job.BatchID != "" {
+			if status.Batch, err = q.batchRepo.GetByID(ctx, job.BatchID); err != nil {
+				return nil, err
+			}
+			status.UserOpHash = status.Batch.UserOpHash
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	}
+
+	ops, err := q.userOpRepo.ListByClaim(ctx, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) > 0 {
+		status.UserOpHash = ops[len(ops)-1].UserOpHash
+	}
+
+	if status.History, err = q.claimRepo.History(ctx, claimID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Retry queues the dead-lettered payout of one of the enterprise's failed
+// claims again, with a fresh attempt budget
+func (q *PayoutQueue) Retry(ctx context.Context, enterpriseID, claimID string) (*model.PayoutStatus, error) {
+	requeued, err := q.repo.Requeue(ctx, claimID, enterpriseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrClaimNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		return nil, ErrPayoutNotRetryable
+	}
+	slog.InfoContext(ctx, "payout queue: claim retried", "claim_id", claimID, "enterprise_id", enterpriseID)
+	metrics.Payouts.Inc("manual_retry")
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return q.Status(ctx, claimID)
+}
+
+// Start runs the worker pool until ctx is cancelled, then waits for in-flight
+// payouts to finish
+func (q *PayoutQueue) Start(ctx context.Context) {
+	workers := q.cfg.PayoutWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	slog.InfoContext(ctx, "payout queue: starting workers", "workers", workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(payoutStaleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			q.reclaimStale(ctx)
+			q.failStale(ctx)
+			q.releaseHeld(ctx)
+		}
+	}
+}
+
+func (q *PayoutQueue) work(ctx context.Context) {
+	ticker := time.NewTicker(payoutPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain everything that is due before sleeping again
+		for ctx.Err() == nil {
+			if q.Batched() {
+				jobs, err := q.repo.DequeueBatch(ctx, q.cfg.SettlementBatchMax)
+				if err != nil {
+					slog.ErrorContext(ctx, "payout queue: failed to dequeue batch", "error", err)
+					break
+				}
+				if len(jobs) == 0 {
+					break
+				}
+				q.processBatch(context.WithoutCancel(ctx), jobs)
+				continue
+			}
+
+			job, err := q.repo.Dequeue(ctx)
+			if errors.Is(err, pgx.ErrNoRows) {
+				break
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "payout queue: failed to dequeue", "error", err)
+				break
+			}
+			// Let an in-flight transfer finish even if we are shutting down
+			q.process(context.WithoutCancel(ctx), job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *PayoutQueue) process(ctx context.Context, job *model.PayoutJob) {
+	cleared, err := q.screen(ctx, []*model.PayoutJob{job})
+	if err != nil {
+		q.retryOrFail(ctx, job, err)
+		return
+	}
+	if len(cleared) == 0 {
+		return
+	}
+
+	txHash, err := q.pay(ctx, job)
+
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		// Submitted but not yet included; the UserOpMonitor settles the claim
+		if err := q.walletSvc.AttachUserOp(ctx, pending.UserOpHash, job.ClaimID); err != nil {
+			slog.ErrorContext(ctx, "payout queue: failed to attach user operation", "user_op_hash", pending.UserOpHash, "claim_id", job.ClaimID, "error", err)
+		}
+		metrics.Payouts.Inc("pending")
+		q.complete(ctx, job)
+
+	case err != nil:
+		q.retryOrFail(ctx, job, err)
+
+	default:
+		metrics.Payouts.Inc("success")
+		q.transition(ctx, job.ClaimID, model.ClaimSubmitted, txHash, "")
+		q.complete(ctx, job)
+	}
+}
+
+// pay executes the transfer for a job's claim
+func (q *PayoutQueue) pay(ctx context.Context, job *model.PayoutJob) (string, error) {
+	claim, err := q.claimRepo.GetByID(ctx, job.ClaimID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", permanent(fmt.Errorf("claim not found: %w", err))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load claim: %w", err)
+	}
+	rp, err := q.rpRepo.GetByID(ctx, claim.RedPocketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", permanent(fmt.Errorf("red pocket not found: %w", err))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load red pocket: %w", err)
+	}
+	sender, err := q.walletSvc.GetOrCreate(ctx, PayoutWalletID(rp.ID), rp.ChainID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get payout wallet: %w", err)
+	}
+
+	// A redelivered job must not pay again
+	switch claim.Status {
+	case model.ClaimSubmitted, model.ClaimConfirmed:
+		return claim.TxHash, nil
+	case model.ClaimFailed, model.ClaimBlocked, model.ClaimReverted, model.ClaimReorged, model.ClaimRefunded:
+		return "", permanent(fmt.Errorf("claim is already %s", claim.Status))
+	}
+	if op, err := q.submittedOp(ctx, claim.ID); err != nil {
+		return "", err
+	} else if op != nil {
+		return op.UserOpHash, &PendingUserOpError{UserOpHash: op.UserOpHash}
+	}
+
+	ok, err := q.claimRepo.Transition(ctx, claim.ID, model.ClaimProcessing, "", fmt.Sprintf("attempt %d", job.Attempts))
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", permanent(fmt.Errorf("claim cannot be processed from %s", claim.Status))
+	}
+
+	ctx = withPayoutClaim(ctx, claim.ID)
+	if rp.TestMode {
+		ctx = withSandbox(ctx)
+	}
+	return payClaim(ctx, q.walletSvc, q.savings, sender, rp.TokenAddress, claim)
+}
+
+// submittedOp returns the claim's latest user operation that was sent, or
+// nil if none was
+func (q *PayoutQueue) submittedOp(ctx context.Context, claimID string) (*model.UserOperationRecord, error) {
+	ops, err := q.userOpRepo.ListByClaim(ctx, claimID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *model.UserOperationRecord
+	for _, op := range ops {
+		if op.Status != "failed" {
+			latest = op
+		}
+	}
+	return latest, nil
+}
+
+// permanentPayoutError is a transfer failure retrying cannot fix
+type permanentPayoutError struct {
+	err error
+}
+
+func permanent(err error) error {
+	return &permanentPayoutError{err: err}
+}
+
+func (e *permanentPayoutError) Error() string { return e.err.Error() }
+func (e *permanentPayoutError) Unwrap() error { return e.err }
+
+// transientPayoutError reports whether retrying a failed transfer may
+// succeed. A pocket wallet short of funds needs its creator to top it up,
+// after which the enterprise retries the payout.
+func transientPayoutError(err error) bool {
+	var perm *permanentPayoutError
+	if errors.As(err, &perm) {
+		return false
+	}
+	return classifyTransferError(err.Error()) != "funds"
+}
+
+// payoutRetryDelay is how long a job waits before its next attempt,
+// doubling per attempt up to PAYOUT_RETRY_MAX_DELAY
+func (q *PayoutQueue) payoutRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(q.cfg.PayoutRetryDelay) * time.Second
+	limit := time.Duration(q.cfg.PayoutRetryMaxDelay) * time.Second
+	for i := 1; i < attempts && (limit <= 0 || delay < limit); i++ {
+		delay *= 2
+	}
+	if limit > 0 && delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// retryOrFail requeues a job whose transfer failed, or dead-letters it and
+// fails its claim when the failure is not transient or the attempt budget
+// is spent. Transfers fail before anything is sent, so a retry cannot
+// double pay.
+func (q *PayoutQueue) retryOrFail(ctx context.Context, job *model.PayoutJob, cause error) {
+	if job.Attempts < q.cfg.PayoutMaxAttempts && transientPayoutError(cause) {
+		delay := q.payoutRetryDelay(job.Attempts)
+		slog.WarnContext(ctx, "payout queue: attempt failed, retrying", "claim_id", job.ClaimID, "attempt", job.Attempts, "delay", delay, "error", cause)
+		if err := q.repo.Retry(ctx, job.ID, cause.Error(), time.Now().Add(delay)); err != nil {
+			slog.ErrorContext(ctx, "payout queue: failed to requeue", "job_id", job.ID, "error", err)
+		}
+		metrics.Payouts.Inc("retry")
+		q.transition(ctx, job.ClaimID, model.ClaimRetrying, "", cause.Error())
+		return
+	}
+
+	slog.ErrorContext(ctx, "payout queue: claim failed", "claim_id", job.ClaimID, "attempts", job.Attempts, "error", cause)
+	metrics.Payouts.Inc("failed")
+	if err := q.repo.DeadLetter(ctx, job.ID, cause.Error()); err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to dead-letter", "job_id", job.ID, "error", err)
+	}
+	q.transition(ctx, job.ClaimID, model.ClaimFailed, "", cause.Error())
+	q.webhooks.PayoutFailed(ctx, job.ClaimID, cause.Error())
+	message := fmt.Sprintf("Claim %s failed after %d attempts and was dead-lettered; its enterprise can retry it once the cause is fixed: %v",
+		job.ClaimID, job.Attempts, cause)
+	if err := q.notifier.Notify(ctx, "Payout dead-lettered", message); err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to send alert", "error", err)
+	}
+}
+
+// processBatch settles the queued claims of one pocket in a single transfer
+// batch, with one transfer per recipient
+func (q *PayoutQueue) processBatch(ctx context.Context, jobs []*model.PayoutJob) {
+	jobs, err := q.screen(ctx, jobs)
+	if err != nil {
+		for _, job := range jobs {
+			q.retryOrFail(ctx, job, err)
+		}
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	batch, err := q.settle(ctx, jobs)
+	if batch == nil {
+		// Nothing was sent
+		for _, job := range jobs {
+			q.retryOrFail(ctx, job, err)
+		}
+		return
+	}
+
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		// The UserOpMonitor settles the batch's claims once the op lands
+		if err := q.batchRepo.SetUserOp(ctx, batch.ID, pending.UserOpHash); err != nil {
+			slog.ErrorContext(ctx, "payout queue: failed to record user operation of batch", "batch_id", batch.ID, "error", err)
+		}
+		metrics.Payouts.Add(float64(len(jobs)), "pending")
+		for _, job := range jobs {
+			q.complete(ctx, job)
+		}
+
+	case err != nil:
+		if err := q.batchRepo.Settle(ctx, batch.ID, "failed", "", err.Error()); err != nil {
+			slog.ErrorContext(ctx, "payout queue: failed to settle batch", "batch_id", batch.ID, "error", err)
+		}
+		for _, job := range jobs {
+			q.retryOrFail(ctx, job, err)
+		}
+
+	default:
+		slog.InfoContext(ctx, "payout queue: batch paid", "batch_id", batch.ID,
+			"claims", batch.ClaimCount, "transfers", batch.TransferCount, "tx_hash", batch.TxHash)
+		if err := q.batchRepo.Settle(ctx, batch.ID, "success", batch.TxHash, ""); err != nil {
+			slog.ErrorContext(ctx, "payout queue: failed to settle batch", "batch_id", batch.ID, "error", err)
+		}
+		if refused, err := q.claimRepo.TransitionBatch(ctx, batch.ID, model.ClaimSubmitted, batch.TxHash, ""); err != nil {
+			slog.ErrorContext(ctx, "payout queue: failed to update claims of batch", "batch_id", batch.ID, "error", err)
+		} else if refused > 0 {
+			slog.WarnContext(ctx, "payout queue: claims of batch could not be marked paid", "batch_id", batch.ID, "claims", refused)
+		}
+		metrics.Payouts.Add(float64(len(jobs)), "success")
+		for _, job := range jobs {
+			q.complete(ctx, job)
+		}
+	}
+}
+
+// settle nets the jobs' claims, split per the claimers' payout splits and
+// campaign donations, per recipient, records the batch and sends it. Saved
+// shares are netted per vault and claimer and deposited in the same batch.
+// The batch is nil if it could not be recorded.
+func (q *PayoutQueue) settle(ctx context.Context, jobs []*model.PayoutJob) (*model.PayoutBatch, error) {
+	var rp *model.RedPocket
+	jobIDs := make([]string, 0, len(jobs))
+	totals := make(map[string]*big.Int)
+	var recipients []string
+	vaults := make(map[string]*model.YieldVault)
+	saved := make(map[[2]string]*VaultDeposit)
+	var deposits []*VaultDeposit
+	var total float64
+
+	for _, job := range jobs {
+		claim, err := q.claimRepo.GetByID(ctx, job.ClaimID)
+		if err != nil {
+			return nil, fmt.Errorf("claim %s not found: %w", job.ClaimID, err)
+		}
+		if rp == nil {
+			if rp, err = q.rpRepo.GetByID(ctx, claim.RedPocketID); err != nil {
+				return nil, fmt.Errorf("red pocket not found: %w", err)
+			}
+		}
+
+		to, amounts := splitPayout(claim, payoutUnits(claim))
+		if claim.SavingsVaultID != "" {
+			vault, ok := vaults[claim.SavingsVaultID]
+			if !ok {
+				if vault, err = q.savings.vault(ctx, claim.SavingsVaultID); err != nil {
+					return nil, err
+				}
+				vaults[vault.ID] = vault
+			}
+			var deposit *VaultDeposit
+			if to, amounts, deposit = takeSavings(claim, to, amounts, vault); deposit != nil {
+				key := [2]string{vault.ID, deposit.Receiver}
+				if prev, ok := saved[key]; ok {
+					prev.Amount.Add(prev.Amount, deposit.Amount)
+				} else {
+					deposit.Amount = new(big.Int).Set(deposit.Amount)
+					saved[key] = deposit
+					deposits = append(deposits, deposit)
+				}
+			}
+		}
+		for i, addr := range to {
+			if _, ok := totals[addr]; !ok {
+				totals[addr] = new(big.Int)
+				recipients = append(recipients, addr)
+			}
+			totals[addr].Add(totals[addr], amounts[i])
+		}
+		total += claim.Amount - claim.Fee
+		jobIDs = append(jobIDs, job.ID)
+	}
+
+	sender, err := q.walletSvc.GetOrCreate(ctx, PayoutWalletID(rp.ID), rp.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payout wallet: %w", err)
+	}
+
+	batch := &model.PayoutBatch{
+		ID:            "batch_" + uuid.New().String()[:8],
+		RedPocketID:   rp.ID,
+		Sender:        sender.Address,
+		Status:        "processing",
+		ClaimCount:    len(jobs),
+		TransferCount: len(recipients) + len(deposits),
+		TotalAmount:   total,
+		CreatedAt:     time.Now(),
+	}
+	if err := q.batchRepo.Create(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to record batch: %w", err)
+	}
+	if err := q.repo.SetBatch(ctx, jobIDs, batch.ID); err != nil {
+		return nil, fmt.Errorf("failed to assign batch: %w", err)
+	}
+	refused, err := q.claimRepo.TransitionBatch(ctx, batch.ID, model.ClaimProcessing, "", "batch "+batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	if refused > 0 {
+		slog.WarnContext(ctx, "payout queue: claims of batch were not pending", "batch_id", batch.ID, "claims", refused)
+	}
+
+	amounts := make([]*big.Int, len(recipients))
+	for i, to := range recipients {
+		amounts[i] = totals[to]
+	}
+	if rp.TestMode {
+		ctx = withSandbox(ctx)
+	}
+	batch.TxHash, err = q.walletSvc.BatchPayout(ctx, sender, rp.TokenAddress, recipients, amounts, deposits, batch.ID)
+	return batch, err
+}
+
+// screen checks the payout addresses of the jobs' claims, split addresses
+// included, against sanctions lists and returns the jobs that may be paid.
+// Claims to a sanctioned address are blocked. On a screening error the jobs
+// not blocked so far are returned with the error, to be retried.
+func (q *PayoutQueue) screen(ctx context.Context, jobs []*model.PayoutJob) ([]*model.PayoutJob, error) {
+	if !q.screener.Enabled() {
+		return jobs, nil
+	}
+
+	cleared := make([]*model.PayoutJob, 0, len(jobs))
+	for i, job := range jobs {
+		claim, err := q.claimRepo.GetByID(ctx, job.ClaimID)
+		if err != nil {
+			return append(cleared, jobs[i:]...), fmt.Errorf("claim %s not found: %w", job.ClaimID, err)
+		}
+		screening, err := q.screenClaim(ctx, claim)
+		if err != nil {
+			return append(cleared, jobs[i:]...), err
+		}
+		if err := q.claimRepo.SetScreening(ctx, claim.ID, screening.ID, screening.Result); err != nil {
+			slog.ErrorContext(ctx, "payout queue: failed to record screening", "claim_id", claim.ID, "error", err)
+		}
+
+		if screening.Result == ScreeningHit {
+			q.block(ctx, job, screening)
+			continue
+		}
+		cleared = append(cleared, job)
+	}
+	return cleared, nil
+}
+
+// screenClaim screens the claim's wallet, split and charity addresses. It
+// returns the first hit, or the wallet's screening when all are clear.
+func (q *PayoutQueue) screenClaim(ctx context.Context, claim *model.Claim) (*model.AddressScreening, error) {
+	screening, err := q.screener.Screen(ctx, claim.WalletAddress, "claim", claim.ID, claim.ClaimerID)
+	if err != nil || screening.Result == ScreeningHit {
+		return screening, err
+	}
+	others := make([]string, 0, len(claim.PayoutSplits)+1)
+	for _, split := range claim.PayoutSplits {
+		others = append(others, split.Address)
+	}
+	if claim.DonationUnits.Sign() > 0 {
+		others = append(others, claim.CharityAddress)
+	}
+	for _, addr := range others {
+		splitScreening, err := q.screener.Screen(ctx, addr, "claim", claim.ID, claim.ClaimerID)
+		if err != nil {
+			return nil, err
+		}
+		if splitScreening.Result == ScreeningHit {
+			return splitScreening, nil
+		}
+	}
+	return screening, nil
+}
+
+// block fails a job whose claim pays a sanctioned address. It is never retried.
+func (q *PayoutQueue) block(ctx context.Context, job *model.PayoutJob, screening *model.AddressScreening) {
+	reason := "payout address is sanctioned (screening " + screening.ID + ")"
+	slog.WarnContext(ctx, "payout queue: claim blocked", "claim_id", job.ClaimID, "reason", reason)
+	metrics.Payouts.Inc("blocked")
+	if err := q.repo.Fail(ctx, job.ID, reason); err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to mark job failed", "job_id", job.ID, "error", err)
+	}
+	q.transition(ctx, job.ClaimID, model.ClaimBlocked, "", reason)
+	q.webhooks.PayoutFailed(ctx, job.ClaimID, reason)
+}
+
+// transition moves a job's claim to a status, logging a move its current
+// status does not allow
+func (q *PayoutQueue) transition(ctx context.Context, claimID string, to model.ClaimStatus, txHash, reason string) {
+	if err := transitionClaim(ctx, q.claimRepo, claimID, to, txHash, reason); err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to transition claim", "claim_id", claimID, "to", to, "error", err)
+	}
+}
+
+// transitionClaim moves a claim to a status, and fails if the claim's
+// current status does not allow it
+func transitionClaim(ctx context.Context, repo repository.ClaimStore, claimID string, to model.ClaimStatus, txHash, reason string) error {
+	ok, err := repo.Transition(ctx, claimID, to, txHash, reason)
+	if err != nil {
+		return fmt.Errorf("failed to move claim %s to %s: %w", claimID, to, err)
+	}
+	if !ok {
+		return fmt.Errorf("claim %s cannot move to %s from its current status", claimID, to)
+	}
+	return nil
+}
+
+func (q *PayoutQueue) complete(ctx context.Context, job *model.PayoutJob) {
+	if err := q.repo.Complete(ctx, job.ID); err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to complete", "job_id", job.ID, "error", err)
+	}
+}
+
+// releaseHeld queues held payouts whose hold ran out without a review
+func (q *PayoutQueue) releaseHeld(ctx context.Context) {
+	claimIDs, err := q.repo.ReleaseDue(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to release held payouts", "error", err)
+		return
+	}
+	if len(claimIDs) > 0 {
+		slog.InfoContext(ctx, "payout queue: released held payouts", "payouts", len(claimIDs))
+	}
+}
+
+// reclaimStale recovers single-claim jobs whose worker died mid-transfer.
+// A claim whose user operation was recorded is left to the UserOpMonitor,
+// which resends or settles it; anything else never reached the bundler and
+// is retried.
+func (q *PayoutQueue) reclaimStale(ctx context.Context) {
+	if q.walletSvc.Simulated() {
+		// Simulated transfers leave no record to tell whether they ran
+		return
+	}
+	jobs, err := q.repo.ReclaimStale(ctx, q.cfg.PayoutJobTimeout)
+	if err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to reclaim stale jobs", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		op, err := q.submittedOp(ctx, job.ClaimID)
+		switch {
+		case err != nil:
+			slog.ErrorContext(ctx, "payout queue: failed to check user operations", "claim_id", job.ClaimID, "error", err)
+		case op != nil:
+			slog.WarnContext(ctx, "payout queue: claim was submitted before its worker died", "claim_id", job.ClaimID, "user_op_hash", op.UserOpHash)
+			q.complete(ctx, job)
+		default:
+			q.retryOrFail(ctx, job, errors.New("payout was interrupted before sending"))
+		}
+	}
+}
+
+// failStale gives up on the stale jobs reclaimStale cannot recover: batch
+// jobs, and any job in simulation mode. The transfer may or may not have
+// been sent, so these need a human rather than a retry.
+func (q *PayoutQueue) failStale(ctx context.Context) {
+	claimIDs, err := q.repo.FailStale(ctx, q.cfg.PayoutJobTimeout)
+	if err != nil {
+		slog.ErrorContext(ctx, "payout queue: failed to check stale jobs", "error", err)
+		return
+	}
+	for _, id := range claimIDs {
+		slog.ErrorContext(ctx, "payout queue: payout was interrupted, check the wallet before paying again", "claim_id", id, "alert", true)
+		q.webhooks.PayoutFailed(ctx, id, "payout was interrupted")
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const (
+	maxPayoutSplits = 5
+	payoutSplitBps  = 10000
+)
+
+// errInvalidPayoutSplits says what is wrong with a set of payout splits
+func errInvalidPayoutSplits(reason string) *CodedError {
+	return newCodedError("invalid_payout_splits", reason)
+}
+
+// PayoutSplitService manages claimers' payout splits, e.g. 20% of every
+// payout to a charity address. Splits are copied onto claims when they are
+// made and applied by the payout queue; credit-mode campaigns credit the
+// whole amount to the ledger.
+type PayoutSplitService struct {
+	repo      *repository.PayoutPreferenceRepository
+	walletSvc *WalletService
+	cfg       *config.Config
+}
+
+func NewPayoutSplitService(repo *repository.PayoutPreferenceRepository, walletSvc *WalletService, cfg *config.Config) *PayoutSplitService {
+	return &PayoutSplitService{repo: repo, walletSvc: walletSvc, cfg: cfg}
+}
+
+type PayoutSplitRequest struct {
+	UserID        string              `json:"-"`
+	WalletAddress string              `json:"walletAddress"` // optional; must be the user's wallet
+	ChainID       int64               `json:"chainId"`       // chain of the wallet, default CHAIN_ID
+	Splits        []model.PayoutSplit `json:"splits"`        // empty clears the splits
+}
+
+// Get returns a user's payout splits; none if they never set any
+func (s *PayoutSplitService) Get(ctx context.Context, userID string) (*model.PayoutPreference, error) {
+	pref, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &model.PayoutPreference{UserID: userID, Splits: []model.PayoutSplit{}}, nil
+	}
+	return pref, err
+}
+
+// Update replaces a user's payout splits. Only the user ctx was
+// authenticated as, by a claimer session, can change them.
+func (s *PayoutSplitService) Update(ctx context.Context, req *PayoutSplitRequest) (*model.PayoutPreference, error) {
+	if account, ok := accountFrom(ctx); !ok || account != req.UserID {
+		return nil, ErrWalletNotOwned
+	}
+	chainID := req.ChainID
+	if chainID == 0 {
+		chainID = s.cfg.ChainID
+	}
+	wallet, err := s.walletSvc.GetByUserID(ctx, req.UserID, chainID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWalletNotOwned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet: %w", err)
+	}
+	if req.WalletAddress != "" && !strings.EqualFold(req.WalletAddress, wallet.Address) {
+		return nil, ErrWalletNotOwned
+	}
+
+	splits, err := validatePayoutSplits(req.Splits, wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+	pref := &model.PayoutPreference{UserID: req.UserID, Splits: splits}
+	if err := s.repo.Set(ctx, pref); err != nil {
+		return nil, fmt.Errorf("failed to save payout splits: %w", err)
+	}
+	return pref, nil
+}
+
+// SplitsFor returns the splits to record on a new claim by userID
+func (s *PayoutSplitService) SplitsFor(ctx context.Context, userID string) ([]model.PayoutSplit, error) {
+	pref, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pref.Splits, nil
+}
+
+// validatePayoutSplits checks splits and normalizes their addresses. The
+// shares may add up to at most 100%; the claimer's own wallet gets the rest
+// and so cannot be a split itself.
+func validatePayoutSplits(splits []model.PayoutSplit, ownAddress string) ([]model.PayoutSplit, error) {
+	if len(splits) > maxPayoutSplits {
+		return nil, errInvalidPayoutSplits(fmt.Sprintf("at most %d splits", maxPayoutSplits))
+	}
+
+	normalized := make([]model.PayoutSplit, 0, len(splits))
+	seen := make(map[common.Address]bool)
+	total := 0
+	for i, split := range splits {
+		if !common.IsHexAddress(split.Address) {
+			return nil, errInvalidPayoutSplits(fmt.Sprintf("splits[%d].address", i))
+		}
+		addr := common.HexToAddress(split.Address)
+		if addr == (common.Address{}) || seen[addr] || strings.EqualFold(addr.Hex(), ownAddress) {
+			return nil, errInvalidPayoutSplits(fmt.Sprintf("splits[%d].address", i))
+		}
+		seen[addr] = true
+		if split.Bps < 1 || split.Bps > payoutSplitBps {
+			return nil, errInvalidPayoutSplits(fmt.Sprintf("splits[%d].bps", i))
+		}
+		if len(split.Label) > 64 {
+			return nil, errInvalidPayoutSplits(fmt.Sprintf("splits[%d].label", i))
+		}
+		total += split.Bps
+		normalized = append(normalized, model.PayoutSplit{Address: addr.Hex(), Bps: split.Bps, Label: split.Label})
+	}
+	if total > payoutSplitBps {
+		return nil, errInvalidPayoutSplits("shares add up to more than 100%")
+	}
+	return normalized, nil
+}
+
+// splitsPayout reports whether a claim pays more than its own wallet
+func splitsPayout(claim *model.Claim) bool {
+	return len(claim.PayoutSplits) > 0 || claim.DonationUnits.Sign() > 0
+}
+
+// splitPayout divides amount between the claim's charity, which gets the
+// claim's donation, its split addresses, which share what is left, and its
+// wallet, which also receives any rounding remainder. Recipients with
+// nothing to receive are left out.
+func splitPayout(claim *model.Claim, amount *big.Int) ([]string, []*big.Int) {
+	recipients := make([]string, 0, len(claim.PayoutSplits)+2)
+	amounts := make([]*big.Int, 0, len(claim.PayoutSplits)+2)
+	if donation := claim.DonationUnits.Int(); donation.Sign() > 0 && donation.Cmp(amount) <= 0 {
+		recipients = append(recipients, claim.CharityAddress)
+		amounts = append(amounts, donation)
+		amount = new(big.Int).Sub(amount, donation)
+	}
+	rest := new(big.Int).Set(amount)
+	for _, split := range claim.PayoutSplits {
+		share := new(big.Int).Mul(amount, big.NewInt(int64(split.Bps)))
+		share.Quo(share, big.NewInt(payoutSplitBps))
+		if share.Sign() == 0 {
+			continue
+		}
+		rest.Sub(rest, share)
+		recipients = append(recipients, split.Address)
+		amounts = append(amounts, share)
+	}
+	if rest.Sign() > 0 {
+		recipients = append(recipients, claim.WalletAddress)
+		amounts = append(amounts, rest)
+	}
+	return recipients, amounts
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+// PocketFees is what the platform charges for a pocket, returned when it is
+// created so the sender sees what they pay before funding it
+type PocketFees struct {
+	Plan              string      `json:"plan"`
+	CreationFee       float64     `json:"creationFee"` // charged on top of the amount
+	CreationFeeUnits  model.Units `json:"creationFeeUnits"`
+	TotalCharged      float64     `json:"totalCharged"` // amount plus creation fee, what a deposit must cover
+	TotalChargedUnits model.Units `json:"totalChargedUnits"`
+	ClaimFee          float64     `json:"claimFee"` // flat, withheld from each claim's payout
+	ClaimFeeUnits     model.Units `json:"claimFeeUnits"`
+	ClaimFeeBps       int         `json:"claimFeeBps"` // of each claim, withheld on top of ClaimFee
+}
+
+// Fees returns what the platform charges for a pocket
+func (s *RedPocketService) Fees(rp *model.RedPocket) *PocketFees {
+	total := new(big.Int).Add(rp.AmountUnits.Int(), rp.CreationFeeUnits.Int())
+	return &PocketFees{
+		Plan:              rp.FeePlan,
+		CreationFee:       rp.CreationFee,
+		CreationFeeUnits:  rp.CreationFeeUnits,
+		TotalCharged:      model.NewUnits(total).Float(rp.Decimals),
+		TotalChargedUnits: model.NewUnits(total),
+		ClaimFee:          rp.ClaimFeeUnits.Float(rp.Decimals),
+		ClaimFeeUnits:     rp.ClaimFeeUnits,
+		ClaimFeeBps:       rp.ClaimFeeBps,
+	}
+}
+
+// feeSchedule returns the platform fees of the plan of the enterprise that
+// owns a campaign. Plans without a schedule use the PLATFORM_* defaults.
+func (s *RedPocketService) feeSchedule(ctx context.Context, campaignID string) (*model.PlatformFeeSchedule, error) {
+	plan, err := s.campaignRepo.GetFeePlan(ctx, campaignID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fee plan: %w", err)
+	}
+	schedule, err := s.campaignRepo.GetFeeSchedule(ctx, plan)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &model.PlatformFeeSchedule{
+			Plan:           plan,
+			CreationFee:    s.cfg.PlatformCreationFee,
+			CreationFeeBps: s.cfg.PlatformCreationFeeBps,
+			ClaimFee:       s.cfg.PlatformClaimFee,
+			ClaimFeeBps:    s.cfg.PlatformClaimFeeBps,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fee schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// applyFees fixes a new pocket's fees under schedule: the creation fee in
+// units of its token and what each claim will be charged
+func applyFees(rp *model.RedPocket, schedule *model.PlatformFeeSchedule) {
+	fee := new(big.Int).Mul(rp.AmountUnits.Int(), big.NewInt(int64(schedule.CreationFeeBps)))
+	fee.Quo(fee, big.NewInt(payoutSplitBps))
+	if schedule.CreationFee > 0 {
+		fee.Add(fee, floatToBigInt(schedule.CreationFee, rp.Decimals))
+	}
+	claimFee := new(big.Int)
+	if schedule.ClaimFee > 0 {
+		claimFee = floatToBigInt(schedule.ClaimFee, rp.Decimals)
+	}
+
+	rp.FeePlan = schedule.Plan
+	rp.CreationFeeUnits = model.NewUnits(fee)
+	rp.CreationFee = rp.CreationFeeUnits.Float(rp.Decimals)
+	rp.ClaimFeeUnits = model.NewUnits(claimFee)
+	rp.ClaimFeeBps = schedule.ClaimFeeBps
+}
+
+// claimFeeUnits is the platform fee withheld from a claim: the pocket's flat
+// claim fee plus its share of the claim, at most what the claimer would
+// receive after donation
+func claimFeeUnits(rp *model.RedPocket, claim, donation *big.Int) *big.Int {
+	fee := new(big.Int).Mul(claim, big.NewInt(int64(rp.ClaimFeeBps)))
+	fee.Quo(fee, big.NewInt(payoutSplitBps))
+	fee.Add(fee, rp.ClaimFeeUnits.Int())
+	if net := new(big.Int).Sub(claim, donation); fee.Cmp(net) > 0 {
+		return net
+	}
+	return fee
+}
+
+// errNativeShareBelowFee gives what the smallest share of a native token
+// pocket has to be worth more than
+func errNativeShareBelowFee(cost float64, token string) *CodedError {
+	return newCodedError("native_share_below_fee", cost, token)
+}
+
+// checkNativeShares makes sure every share of a native token pocket is
+// worth more than it costs to pay out: the claim fee withheld from it and,
+// as a native payout may pay its own gas, the network fee of sending it.
+// The smallest share is usually the last claim's, what is left over.
+func (s *RedPocketService) checkNativeShares(ctx context.Context, rp *model.RedPocket) error {
+	if rp.TokenAddress != "" || rp.TestMode || s.walletSvc.Simulated() {
+		return nil
+	}
+	cost, err := s.walletSvc.NativePayoutFee(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to estimate payout fee: %w", err)
+	}
+	smallest := smallestShare(rp)
+	cost.Add(cost, claimFeeUnits(rp, smallest, new(big.Int)))
+	if smallest.Cmp(cost) <= 0 {
+		return errNativeShareBelowFee(model.NewUnits(cost).Float(rp.Decimals), rp.Token)
+	}
+	return nil
+}
+
+// smallestShare is the least a claim on a new or resized pocket can get:
+// its smallest pre-split share, the lucky draw minimum or an equal share
+func smallestShare(rp *model.RedPocket) *big.Int {
+	if len(rp.Shares) > 0 {
+		smallest := rp.Shares[0].Int()
+		for _, share := range rp.Shares[1:] {
+			if share.Int().Cmp(smallest) < 0 {
+				smallest = share.Int()
+			}
+		}
+		return smallest
+	}
+	equal := new(big.Int).Quo(rp.AmountUnits.Int(), big.NewInt(int64(rp.TotalCount)))
+	if rp.IsLuckyDraw {
+		if minUnits := luckyDrawMinUnits(rp); minUnits.Cmp(equal) < 0 {
+			return minUnits
+		}
+	}
+	return equal
+}
+
+// payoutUnits is what a claim pays out: its amount less the platform fee
+// withheld from it
+func payoutUnits(claim *model.Claim) *big.Int {
+	return new(big.Int).Sub(claim.AmountUnits.Int(), claim.FeeUnits.Int())
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// Redis channel carrying the IDs of pockets whose cached state is stale
+const pocketInvalidationChannel = "pocket:invalidate"
+
+// PocketCache keeps recently read pockets in memory for POCKET_CACHE_TTL
+// seconds. A change to a pocket on any replica purges it everywhere: the
+// replica making the change publishes the pocket's ID over Redis pub/sub,
+// and with the change feed on every replica also purges on the change
+// itself. The TTL bounds staleness should an invalidation be lost.
+type PocketCache struct {
+	rpRepo repository.RedPocketStore
+	redis  *repository.RedisClient
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]pocketCacheEntry
+}
+
+type pocketCacheEntry struct {
+	rp      *model.RedPocket
+	expires time.Time
+}
+
+func NewPocketCache(rpRepo repository.RedPocketStore, redis *repository.RedisClient, cfg *config.Config) *PocketCache {
+	return &PocketCache{
+		rpRepo:  rpRepo,
+		redis:   redis,
+		ttl:     time.Duration(cfg.PocketCacheTTL) * time.Second,
+		entries: make(map[string]pocketCacheEntry),
+	}
+}
+
+// Get returns a pocket, from the cache if it is there. Callers must not
+// modify it.
+func (c *PocketCache) Get(ctx context.Context, id string) (*model.RedPocket, error) {
+	if c.ttl <= 0 {
+		return c.rpRepo.GetByID(ctx, id)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[id]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.rp, nil
+	}
+
+	rp, err := c.rpRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[id] = pocketCacheEntry{rp: rp, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return rp, nil
+}
+
+// Invalidate purges a pocket on this replica and tells the others to
+func (c *PocketCache) Invalidate(ctx context.Context, id string) {
+	c.purge(id)
+	if c.ttl <= 0 {
+		return
+	}
+	if err := c.redis.Publish(ctx, pocketInvalidationChannel, []byte(id)); err != nil {
+		slog.WarnContext(ctx, "pocket cache: failed to publish invalidation", "red_pocket_id", id, "error", err)
+	}
+}
+
+// UseChangeFeed purges pockets as their changes commit, including changes
+// made outside the service layer
+func (c *PocketCache) UseChangeFeed(feed *ChangeFeed) {
+	feed.OnChange(func(ctx context.Context, change *model.Change) {
+		if change.Table == "red_pockets" {
+			c.purge(change.RedPocketID)
+		}
+	})
+}
+
+// Start applies invalidations from other replicas and drops expired entries
+// until ctx is cancelled
+func (c *PocketCache) Start(ctx context.Context) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	sweep := time.NewTicker(time.Minute)
+	defer sweep.Stop()
+
+	for ctx.Err() == nil {
+		sub := c.redis.Subscribe(ctx, pocketInvalidationChannel)
+		if _, err := sub.Receive(ctx); err != nil {
+			sub.Close()
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "pocket cache: failed to subscribe to invalidations", "error", err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+			}
+			continue
+		}
+		// Entries cached while we were not listening may have missed an invalidation
+		c.purgeAll()
+
+		messages := sub.Channel()
+	listen:
+		for {
+			select {
+			case <-ctx.Done():
+				break listen
+			case msg, ok := <-messages:
+				if !ok {
+					break listen
+				}
+				c.purge(msg.Payload)
+			case <-sweep.C:
+				c.sweep()
+			}
+		}
+		sub.Close()
+	}
+}
+
+func (c *PocketCache) purge(id string) {
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+}
+
+func (c *PocketCache) purgeAll() {
+	c.mu.Lock()
+	c.entries = make(map[string]pocketCacheEntry)
+	c.mu.Unlock()
+}
+
+func (c *PocketCache) sweep() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, id)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// PocketEvents publishes live red pocket events over Redis pub/sub so stream
+// subscribers on every replica see claims made on any of them. With the
+// change feed on, stream events come from Postgres instead, which also
+// catches changes made outside the service layer. Creation, claims,
+// depletion, expiry and supply changes also go to the campaign owner's
+// webhooks. Claims, status and supply changes purge the pocket from every
+// replica's cache.
+type PocketEvents struct {
+	redis    *repository.RedisClient
+	rpRepo   repository.RedPocketStore
+	cache    *PocketCache
+	webhooks *WebhookService
+
+	// Local subscribers by pocket, used with the change feed
+	fromFeed bool
+	mu       sync.Mutex
+	subs     map[string]map[chan *model.PocketEvent]struct{}
+}
+
+func NewPocketEvents(redis *repository.RedisClient, rpRepo repository.RedPocketStore, cache *PocketCache, webhooks *WebhookService) *PocketEvents {
+	return &PocketEvents{
+		redis:    redis,
+		rpRepo:   rpRepo,
+		cache:    cache,
+		webhooks: webhooks,
+		subs:     make(map[string]map[chan *model.PocketEvent]struct{}),
+	}
+}
+
+// UseChangeFeed switches stream events from Redis to the change feed. Call
+// it before serving streams.
+func (e *PocketEvents) UseChangeFeed(feed *ChangeFeed) {
+	e.fromFeed = true
+	feed.OnChange(e.handleChange)
+}
+
+func pocketEventsChannel(redPocketID string) string {
+	return "pocket:events:" + redPocketID
+}
+
+// Snapshot describes the current state of a pocket
+func (e *PocketEvents) Snapshot(rp *model.RedPocket) *model.PocketEvent {
+	return newPocketEvent("snapshot", rp)
+}
+
+// PublishCreated announces a new pocket. Nobody can be streaming it yet, so
+// it only goes to webhooks.
+func (e *PocketEvents) PublishCreated(ctx context.Context, rp *model.RedPocket) {
+	e.webhooks.PocketEvent(ctx, WebhookPocketCreated, rp, nil)
+}
+
+// PublishClaim announces a claim; rp is the pocket after the claim
+func (e *PocketEvents) PublishClaim(ctx context.Context, rp *model.RedPocket, claim *model.Claim) {
+	e.cache.Invalidate(ctx, rp.ID)
+	ev := newPocketEvent("claim", rp)
+	ev.ClaimID = claim.ID
+	ev.ClaimAmount = claim.Amount
+	e.publish(ctx, ev)
+	e.webhooks.PocketEvent(ctx, WebhookPocketClaimed, rp, claim)
+}
+
+// PublishStatus announces a status change such as depletion or expiry
+func (e *PocketEvents) PublishStatus(ctx context.Context, rp *model.RedPocket) {
+	e.cache.Invalidate(ctx, rp.ID)
+	e.publish(ctx, newPocketEvent("status", rp))
+	switch rp.Status {
+	case "depleted":
+		e.webhooks.PocketEvent(ctx, WebhookPocketDepleted, rp, nil)
+	case "expired":
+		e.webhooks.PocketEvent(ctx, WebhookPocketExpired, rp, nil)
+	}
+}
+
+// PublishSupply announces that an enterprise topped up or shrank a pocket;
+// rp is the pocket after the change
+func (e *PocketEvents) PublishSupply(ctx context.Context, rp *model.RedPocket) {
+	e.cache.Invalidate(ctx, rp.ID)
+	e.publish(ctx, newPocketEvent("supply", rp))
+	e.webhooks.PocketEvent(ctx, WebhookPocketResized, rp, nil)
+}
+
+// Subscribe streams the events of a pocket until ctx is cancelled or the
+// returned close function is called
+func (e *PocketEvents) Subscribe(ctx context.Context, redPocketID string) (<-chan *model.PocketEvent, func(), error) {
+	if e.fromFeed {
+		events, unsubscribe := e.subscribeLocal(redPocketID)
+		return events, unsubscribe, nil
+	}
+
+	sub := e.redis.Subscribe(ctx, pocketEventsChannel(redPocketID))
+	// Wait for the subscription so no event published after this returns is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan *model.PocketEvent, 16)
+	go func() {
+		defer close(events)
+		for msg := range sub.Channel() {
+			ev := &model.PocketEvent{}
+			if err := json.Unmarshal([]byte(msg.Payload), ev); err != nil {
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, func() { sub.Close() }, nil
+}
+
+// publish is best effort: a missed event only delays a dashboard until its
+// next snapshot
+func (e *PocketEvents) publish(ctx context.Context, ev *model.PocketEvent) {
+	if e.fromFeed {
+		// The change feed announces the same change once it commits
+		return
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	if err := e.redis.Publish(ctx, pocketEventsChannel(ev.RedPocketID), payload); err != nil {
+		slog.WarnContext(ctx, "pocket events: failed to publish", "type", ev.Type, "red_pocket_id", ev.RedPocketID, "error", err)
+	}
+}
+
+func (e *PocketEvents) subscribeLocal(redPocketID string) (<-chan *model.PocketEvent, func()) {
+	events := make(chan *model.PocketEvent, 16)
+
+	e.mu.Lock()
+	if e.subs[redPocketID] == nil {
+		e.subs[redPocketID] = make(map[chan *model.PocketEvent]struct{})
+	}
+	e.subs[redPocketID][events] = struct{}{}
+	e.mu.Unlock()
+
+	return events, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.subs[redPocketID], events)
+		if len(e.subs[redPocketID]) == 0 {
+			delete(e.subs, redPocketID)
+		}
+	}
+}
+
+// handleChange turns committed claims and pocket status and supply changes
+// into stream events for the pocket's local subscribers
+func (e *PocketEvents) handleChange(ctx context.Context, change *model.Change) {
+	var kind string
+	switch {
+	case change.Table == "claims" && change.Op == "INSERT":
+		kind = "claim"
+	case change.Table == "red_pockets" && change.Op == "UPDATE" && change.Status != change.OldStatus:
+		kind = "status"
+	case change.Table == "red_pockets" && change.Op == "UPDATE" && change.Supply:
+		kind = "supply"
+	default:
+		return
+	}
+
+	e.mu.Lock()
+	watched := len(e.subs[change.RedPocketID]) > 0
+	e.mu.Unlock()
+	if !watched {
+		return
+	}
+
+	rp, err := e.rpRepo.GetByID(ctx, change.RedPocketID)
+	if err != nil {
+		slog.ErrorContext(ctx, "pocket events: failed to load red pocket", "red_pocket_id", change.RedPocketID, "kind", kind, "error", err)
+		return
+	}
+	ev := newPocketEvent(kind, rp)
+	if kind == "claim" {
+		ev.ClaimID = change.ID
+		ev.ClaimAmount = change.Amount
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for events := range e.subs[change.RedPocketID] {
+		// Best effort like Redis pub/sub: a stalled stream drops events
+		// rather than holding up the feed
+		select {
+		case events <- ev:
+		default:
+		}
+	}
+}
+
+func newPocketEvent(kind string, rp *model.RedPocket) *model.PocketEvent {
+	return &model.PocketEvent{
+		Type:            kind,
+		RedPocketID:     rp.ID,
+		Status:          rp.Status,
+		ClaimedCount:    rp.ClaimedCount,
+		TotalCount:      rp.TotalCount,
+		RemainingCount:  rp.TotalCount - rp.ClaimedCount,
+		Amount:          rp.Amount,
+		RemainingAmount: rp.RemainingAmount,
+		ExpiresAt:       rp.ExpiresAt,
+		At:              time.Now(),
+	}
+}
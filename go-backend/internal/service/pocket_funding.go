@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const pocketDepositSweepBatch = 100
+
+// PocketFunding makes new pockets wait for their sender's deposit before
+// they open. The deposit goes to the pocket's payout wallet, which payouts
+// and refunds are made from; once the wallet's balance as of
+// POCKET_DEPOSIT_CONFIRMATIONS blocks ago covers the pocket, it opens.
+// Pockets whose deposit has not arrived within POCKET_FUNDING_TIMEOUT are
+// marked unfunded.
+type PocketFunding struct {
+	repo      *repository.PocketDepositRepository
+	rpRepo    repository.RedPocketStore
+	walletSvc *WalletService
+	ledgerSvc *LedgerService
+	events    *PocketEvents
+	cfg       *config.Config
+}
+
+func NewPocketFunding(
+	repo *repository.PocketDepositRepository,
+	rpRepo repository.RedPocketStore,
+	walletSvc *WalletService,
+	ledgerSvc *LedgerService,
+	events *PocketEvents,
+	cfg *config.Config,
+) *PocketFunding {
+	return &PocketFunding{
+		repo:      repo,
+		rpRepo:    rpRepo,
+		walletSvc: walletSvc,
+		ledgerSvc: ledgerSvc,
+		events:    events,
+		cfg:       cfg,
+	}
+}
+
+// Deposit returns the deposit a pocket about to be created must wait for and
+// marks the pocket awaiting it. It returns nil, leaving the pocket alone,
+// when deposits are not required, the campaign pays out of its ledger
+// balance or the pocket is a sandbox one funded by the faucet.
+func (f *PocketFunding) Deposit(ctx context.Context, rp *model.RedPocket) (*model.PocketDeposit, error) {
+	if !f.cfg.PocketDepositRequired || rp.TestMode || f.ledgerSvc.CreditMode(ctx, rp.CampaignID) {
+		return nil, nil
+	}
+	wallet, err := f.walletSvc.GetOrCreate(ctx, PayoutWalletID(rp.ID), rp.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payout wallet: %w", err)
+	}
+
+	now := time.Now()
+	rp.Status = model.PocketAwaitingFunding
+	return &model.PocketDeposit{
+		RedPocketID:   rp.ID,
+		ChainID:       rp.ChainID,
+		Address:       wallet.Address,
+		TokenAddress:  rp.TokenAddress,
+		RequiredUnits: model.NewUnits(new(big.Int).Add(rp.AmountUnits.Int(), rp.CreationFeeUnits.Int())),
+		ReceivedUnits: model.NewUnits(new(big.Int)),
+		Status:        "pending",
+		Deadline:      now.Add(time.Duration(f.cfg.PocketFundingTimeout) * time.Second),
+		CreatedAt:     now,
+	}, nil
+}
+
+// Start checks pending deposits until ctx is cancelled
+func (f *PocketFunding) Start(ctx context.Context) {
+	if !f.cfg.PocketDepositRequired {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(f.cfg.PocketDepositPollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		f.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *PocketFunding) sweep(ctx context.Context) {
+	deposits, err := f.repo.ListPending(ctx, pocketDepositSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "pocket funding: failed to list pending deposits", "error", err)
+		return
+	}
+	if len(deposits) == 0 {
+		return
+	}
+	head, err := f.walletSvc.BlockNumber(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "pocket funding: failed to get block number", "error", err)
+		return
+	}
+	confirmed := head - int64(f.confirmations()) + 1
+
+	for _, d := range deposits {
+		if err := f.check(ctx, d, confirmed); err != nil {
+			slog.ErrorContext(ctx, "pocket funding: failed to check deposit", "red_pocket_id", d.RedPocketID, "error", err)
+		}
+	}
+}
+
+// check reads the deposit address's balance as of the confirmed block and
+// opens the pocket if it is covered, or gives up on it past its deadline
+func (f *PocketFunding) check(ctx context.Context, d *model.PocketDeposit, block int64) error {
+	tag := fmt.Sprintf("0x%x", block)
+	var balance *big.Int
+	var err error
+	if d.TokenAddress == "" {
+		balance, err = f.walletSvc.NativeBalanceAt(ctx, d.Address, tag)
+	} else {
+		balance, err = f.walletSvc.TokenBalanceAt(ctx, d.TokenAddress, d.Address, tag)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read balance: %w", err)
+	}
+	received := model.NewUnits(balance)
+
+	if balance.Cmp(d.RequiredUnits.Int()) >= 0 {
+		rp, err := f.repo.MarkFunded(ctx, d.RedPocketID, received, block)
+		if err != nil {
+			return fmt.Errorf("failed to mark funded: %w", err)
+		}
+		if rp != nil {
+			slog.InfoContext(ctx, "pocket funding: red pocket funded", "red_pocket_id", rp.ID, "block", block, "status", rp.Status)
+			f.events.PublishStatus(ctx, rp)
+		}
+		return nil
+	}
+
+	if time.Now().After(d.Deadline) {
+		rp, err := f.repo.Expire(ctx, d.RedPocketID)
+		if err != nil {
+			return fmt.Errorf("failed to expire: %w", err)
+		}
+		if rp != nil {
+			slog.WarnContext(ctx, "pocket funding: red pocket unfunded", "red_pocket_id", rp.ID, "received_units", received, "required_units", d.RequiredUnits)
+			f.events.PublishStatus(ctx, rp)
+		}
+		return nil
+	}
+
+	return f.repo.RecordCheck(ctx, d.RedPocketID, received, block)
+}
+
+func (f *PocketFunding) confirmations() int {
+	if f.cfg.PocketDepositConfirmations < 1 {
+		return 1
+	}
+	return f.cfg.PocketDepositConfirmations
+}
+
+// DepositTransfer is the transaction that funds a pocket's outstanding amount
+type DepositTransfer struct {
+	To    string `json:"to"`
+	Value string `json:"value"` // hex wei
+	Data  string `json:"data,omitempty"`
+}
+
+// PocketFundingStatus tells a pocket's sender whether and where to deposit
+type PocketFundingStatus struct {
+	RedPocketID   string               `json:"redPocketId"`
+	PocketStatus  model.PocketStatus   `json:"pocketStatus"`
+	Required      bool                 `json:"required"` // false for pockets created without a deposit
+	Deposit       *model.PocketDeposit `json:"deposit,omitempty"`
+	Confirmations int                  `json:"confirmations,omitempty"`
+	Transfer      *DepositTransfer     `json:"transfer,omitempty"` // while the deposit is pending
+}
+
+// Status returns a pocket's deposit and, while it is pending, the transfer
+// that covers what is still missing
+func (f *PocketFunding) Status(ctx context.Context, redPocketID string) (*PocketFundingStatus, error) {
+	rp, err := f.rpRepo.GetByID(ctx, redPocketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRedPocketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	status := &PocketFundingStatus{RedPocketID: rp.ID, PocketStatus: rp.Status}
+
+	deposit, err := f.repo.GetByRedPocket(ctx, rp.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Required = true
+	status.Deposit = deposit
+	status.Confirmations = f.confirmations()
+
+	outstanding := new(big.Int).Sub(deposit.RequiredUnits.Int(), deposit.ReceivedUnits.Int())
+	if deposit.Status == "pending" && outstanding.Sign() > 0 {
+		if deposit.TokenAddress == "" {
+			status.Transfer = &DepositTransfer{To: deposit.Address, Value: fmt.Sprintf("0x%x", outstanding)}
+		} else {
+			status.Transfer = &DepositTransfer{
+				To:    deposit.TokenAddress,
+				Value: "0x0",
+				Data:  BuildERC20TransferCallData(deposit.TokenAddress, deposit.Address, outstanding),
+			}
+		}
+	}
+	return status, nil
+}
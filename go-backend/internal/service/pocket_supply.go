@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrPocketNotResizable  = newCodedError("pocket_not_resizable")
+	ErrInvalidSupplyChange = newCodedError("invalid_supply_change")
+	// ErrSupplyTransferFailed is returned when what a deposited pocket
+	// changes by could not be moved between its payout wallet and the
+	// enterprise's wallet
+	ErrSupplyTransferFailed = newCodedError("supply_transfer_failed")
+)
+
+// PocketAnnouncer edits the chat messages a pocket was announced in to show
+// it as it is now
+type PocketAnnouncer interface {
+	RefreshAnnouncements(ctx context.Context, rp *model.RedPocket, claimLink string) error
+}
+
+// UseAnnouncer lets supply changes edit the pockets' chat announcements
+func (s *RedPocketService) UseAnnouncer(announcer PocketAnnouncer) {
+	s.announcer = announcer
+}
+
+// PocketSupplyRequest changes a red pocket's supply by Amount, a decimal
+// token amount, and Count shares; either may be left out
+type PocketSupplyRequest struct {
+	Amount json.Number `json:"amount"`
+	Count  int         `json:"count" binding:"omitempty,gt=0"`
+}
+
+// TopUp adds to the amount and count of one of the enterprise's open,
+// paused or scheduled red pockets, out of its campaign's budget. No
+// creation fee is charged on what is added. A pocket paid out of its own deposit
+// takes what is added from the enterprise's wallet.
+func (s *RedPocketService) TopUp(ctx context.Context, id, enterpriseID string, req *PocketSupplyRequest) (*model.RedPocket, error) {
+	return s.resize(ctx, id, enterpriseID, req, 1)
+}
+
+// Shrink takes unclaimed amount and shares back from one of the
+// enterprise's open, paused or scheduled red pockets, freeing them in its
+// campaign's budget. At least one share, and a minor unit for each share
+// left, has to stay unclaimed. A pocket paid out of its own deposit refunds
+// what is taken back to the enterprise's wallet.
+func (s *RedPocketService) Shrink(ctx context.Context, id, enterpriseID string, req *PocketSupplyRequest) (*model.RedPocket, error) {
+	return s.resize(ctx, id, enterpriseID, req, -1)
+}
+
+// resize changes a pocket's supply by req, added when sign is 1 and taken
+// away when it is -1. Claims on an equal split pocket take its amount over
+// its count, so once it has been claimed from it changes by whole shares of
+// that size and the amount follows the count. A pre-split lucky draw is
+// split again for its new amount and count from the same seed, so its
+// commitment still holds, and only before anything has been drawn from it.
+func (s *RedPocketService) resize(ctx context.Context, id, enterpriseID string, req *PocketSupplyRequest, sign int) (*model.RedPocket, error) {
+	rp, err := s.enterprisePocket(ctx, id, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case rp.Status == model.PocketExpired:
+		return nil, ErrRedPocketExpired
+	case rp.Status != model.PocketActive && rp.Status != model.PocketPaused && rp.Status != model.PocketScheduled:
+		return nil, ErrPocketNotResizable
+	case !time.Now().Before(rp.ExpiresAt):
+		// Expiry reached, and the sweep has yet to close it
+		return nil, ErrRedPocketExpired
+	case rp.DrawScheme == LuckyDrawScheme && rp.ClaimedCount > 0:
+		return nil, ErrPocketNotResizable
+	}
+
+	amount := new(big.Int)
+	if req.Amount != "" {
+		units, ok := parseUnits(req.Amount.String(), rp.Decimals)
+		if !ok || units.Sign() <= 0 {
+			return nil, ErrInvalidAmount
+		}
+		amount = units
+	}
+	if amount.Sign() == 0 && req.Count == 0 {
+		return nil, ErrInvalidSupplyChange
+	}
+	if !rp.IsLuckyDraw && rp.ClaimedCount > 0 {
+		share := new(big.Int).Quo(rp.AmountUnits.Int(), big.NewInt(int64(rp.TotalCount)))
+		shares := share.Mul(share, big.NewInt(int64(req.Count)))
+		if req.Count == 0 || (amount.Sign() > 0 && amount.Cmp(shares) != 0) {
+			return nil, ErrInvalidSupplyChange
+		}
+		amount = shares
+	}
+	amountUnits := new(big.Int).Add(rp.AmountUnits.Int(), amount.Mul(amount, big.NewInt(int64(sign))))
+	totalCount := rp.TotalCount + sign*req.Count
+	remaining := new(big.Int).Sub(amountUnits, new(big.Int).Sub(rp.AmountUnits.Int(), rp.RemainingUnits.Int()))
+	if totalCount <= rp.ClaimedCount || remaining.Cmp(big.NewInt(int64(totalCount-rp.ClaimedCount))) < 0 {
+		return nil, ErrInvalidSupplyChange
+	}
+
+	split := *rp
+	split.AmountUnits = model.NewUnits(amountUnits)
+	split.TotalCount = totalCount
+	if rp.DrawScheme == LuckyDrawScheme {
+		seed, err := hex.DecodeString(rp.DrawSeed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid draw seed of %s: %w", rp.ID, err)
+		}
+		rp.Shares = splitLuckyDraw(&split, seed)
+		split.Shares = rp.Shares
+	}
+	if err := s.checkNativeShares(ctx, &split); err != nil {
+		return nil, err
+	}
+
+	resized, ok, err := s.rpRepo.Resize(ctx, rp, model.NewUnits(amountUnits), totalCount, func(diff *big.Int) error {
+		return s.moveDeposit(ctx, rp, enterpriseID, diff)
+	})
+	if errors.Is(err, repository.ErrBudgetNotCovered) {
+		return nil, ErrBudgetExceeded
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if errors.Is(err, ErrSupplyTransferFailed) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resize red pocket: %w", err)
+	}
+	if !ok {
+		return nil, ErrPocketNotResizable
+	}
+
+	s.events.PublishSupply(ctx, resized)
+	if s.announcer != nil {
+		if err := s.announcer.RefreshAnnouncements(ctx, resized, s.links.URL(resized)); err != nil {
+			slog.WarnContext(ctx, "red pocket: failed to update announcements", "red_pocket_id", resized.ID, "error", err)
+		}
+	}
+	return resized, nil
+}
+
+// moveDeposit keeps a deposited pocket's payout wallet holding its amount
+// when it is resized: diff units are taken from the enterprise's wallet
+// when it grows, or refunded there when it shrinks. A transfer still in the
+// mempool counts as made; the UserOpMonitor sees it through.
+func (s *RedPocketService) moveDeposit(ctx context.Context, rp *model.RedPocket, enterpriseID string, diff *big.Int) error {
+	pocketWallet, err := s.walletSvc.GetOrCreate(ctx, PayoutWalletID(rp.ID), rp.ChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get payout wallet: %w", err)
+	}
+	enterpriseWallet, err := s.walletSvc.GetOrCreate(ctx, enterpriseID, rp.ChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get enterprise wallet: %w", err)
+	}
+
+	from, to, amount := enterpriseWallet, pocketWallet.Address, diff
+	if diff.Sign() < 0 {
+		from, to, amount = pocketWallet, enterpriseWallet.Address, new(big.Int).Neg(diff)
+	}
+	txHash, err := s.walletSvc.TransferToken(ctx, from, rp.TokenAddress, to, amount)
+	var pending *PendingUserOpError
+	if err != nil && !errors.As(err, &pending) {
+		slog.ErrorContext(ctx, "red pocket: failed to move deposit difference",
+			"red_pocket_id", rp.ID, "units", diff.String(), "error", err)
+		return ErrSupplyTransferFailed
+	}
+	slog.InfoContext(ctx, "red pocket: moved deposit difference",
+		"red_pocket_id", rp.ID, "units", diff.String(), "from", from.Address, "to", to, "tx_hash", txHash)
+	return nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// Readiness states
+const (
+	// Preflight is still waiting for dependencies
+	ReadinessStarting = "starting"
+	// Every dependency answers; reads and writes are served
+	ReadinessReady = "ready"
+	// A dependency is down; reads are served as far as they can be, writes are refused
+	ReadinessDegraded = "degraded"
+	// Only optional dependencies are down; reads and writes are served on their fallbacks
+	ReadinessFallback = "fallback"
+)
+
+const (
+	readinessCheckInterval = 5 * time.Second
+	readinessCheckTimeout  = 3 * time.Second
+	preflightMinBackoff    = 500 * time.Millisecond
+	preflightMaxBackoff    = 10 * time.Second
+)
+
+// ErrServiceDegraded is returned for writes while a dependency is down
+var ErrServiceDegraded = newCodedError("service_degraded")
+
+// Readiness tracks whether the dependencies writes need are up. It starts in
+// ReadinessStarting, leaves it once Preflight returns and then moves between
+// ReadinessReady, ReadinessFallback and ReadinessDegraded as checks pass or
+// fail.
+type Readiness struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	state    string
+	checks   map[string]func(ctx context.Context) error
+	optional map[string]bool
+	down     []string
+	since    time.Time
+}
+
+func NewReadiness(cfg *config.Config) *Readiness {
+	return &Readiness{
+		timeout:  time.Duration(cfg.StartupPreflightTimeout) * time.Second,
+		state:    ReadinessStarting,
+		checks:   make(map[string]func(ctx context.Context) error),
+		optional: make(map[string]bool),
+		since:    time.Now(),
+	}
+}
+
+// Check registers a dependency. Register all of them before Preflight.
+func (r *Readiness) Check(name string, ping func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = ping
+}
+
+// Optional registers a dependency writes can do without. While only optional
+// dependencies are down the state is ReadinessFallback rather than
+// ReadinessDegraded.
+func (r *Readiness) Optional(name string, ping func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = ping
+	r.optional[name] = true
+}
+
+// Required reports whether name is a dependency writes cannot do without
+func (r *Readiness) Required(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.checks[name]
+	return ok && !r.optional[name]
+}
+
+// Preflight waits for every dependency, retrying with backoff for up to
+// STARTUP_PREFLIGHT_TIMEOUT, and reports whether they all came up. If they
+// did not the service starts degraded, or on fallbacks if only optional
+// dependencies are down, instead of exiting.
+func (r *Readiness) Preflight(ctx context.Context) bool {
+	deadline := time.Now().Add(r.timeout)
+	backoff := preflightMinBackoff
+	for {
+		failures := r.probe(ctx)
+		if len(failures) == 0 {
+			r.transition(ReadinessReady, failures)
+			return true
+		}
+		if ctx.Err() != nil || time.Now().Add(backoff).After(deadline) {
+			r.transition(r.stateFor(failures), failures)
+			return false
+		}
+
+		for _, name := range sortedKeys(failures) {
+			slog.WarnContext(ctx, "readiness: waiting, retrying", "dependency", name, "backoff", backoff, "error", failures[name])
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, preflightMaxBackoff)
+	}
+}
+
+// Start re-checks the dependencies until ctx is cancelled
+func (r *Readiness) Start(ctx context.Context) {
+	ticker := time.NewTicker(readinessCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		failures := r.probe(ctx)
+		r.transition(r.stateFor(failures), failures)
+	}
+}
+
+// Ready reports whether writes may be served
+func (r *Readiness) Ready() bool {
+	state := r.State()
+	return state == ReadinessReady || state == ReadinessFallback
+}
+
+func (r *Readiness) State() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.state
+}
+
+// Status returns the state and since when it holds
+func (r *Readiness) Status() (string, time.Time) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.state, r.since
+}
+
+// Down returns the dependencies that failed the last check
+func (r *Readiness) Down() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.down
+}
+
+// stateFor is the state given the dependencies that failed a check
+func (r *Readiness) stateFor(failures map[string]string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state := ReadinessReady
+	for name := range failures {
+		if !r.optional[name] {
+			return ReadinessDegraded
+		}
+		state = ReadinessFallback
+	}
+	return state
+}
+
+func (r *Readiness) probe(ctx context.Context) map[string]string {
+	r.mu.RLock()
+	checks := make(map[string]func(ctx context.Context) error, len(r.checks))
+	for name, ping := range r.checks {
+		checks[name] = ping
+	}
+	r.mu.RUnlock()
+
+	failures := make(map[string]string)
+	for name, ping := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+		if err := ping(checkCtx); err != nil {
+			failures[name] = err.Error()
+		}
+		cancel()
+	}
+	return failures
+}
+
+func (r *Readiness) transition(state string, failures map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.down = sortedKeys(failures)
+	if state == r.state {
+		return
+	}
+	switch state {
+	case ReadinessDegraded:
+		slog.Error("readiness: degraded, refusing writes", "from", r.state, "down", r.down)
+	case ReadinessFallback:
+		slog.Warn("readiness: fallback, serving writes without dependencies", "from", r.state, "down", r.down)
+	default:
+		slog.Info("readiness: state changed", "from", r.state, "to", state)
+	}
+	r.state = state
+	r.since = time.Now()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const receiptBatchSize = 200
+
+// ReceiptTracker follows claim payouts block by block until they are final.
+// A payout is final, and its claim confirmed, once it has the configured
+// number of confirmations and the canonical block at its height still has
+// the hash it was seen in. Payouts that drop out of the chain or revert are
+// re-submitted, and escalated once the retry budget is spent.
+type ReceiptTracker struct {
+	repo       *repository.ReceiptRepository
+	claimRepo  repository.ClaimStore
+	walletSvc  *WalletService
+	savings    *SavingsService
+	xcmBridge  *XCMBridge
+	webhooks   *WebhookService
+	cfg        *config.Config
+	httpClient *http.Client
+}
+
+func NewReceiptTracker(
+	repo *repository.ReceiptRepository,
+	claimRepo repository.ClaimStore,
+	walletSvc *WalletService,
+	savings *SavingsService,
+	xcmBridge *XCMBridge,
+	webhooks *WebhookService,
+	cfg *config.Config,
+) *ReceiptTracker {
+	return &ReceiptTracker{
+		repo:      repo,
+		claimRepo: claimRepo,
+		walletSvc: walletSvc,
+		savings:   savings,
+		xcmBridge: xcmBridge,
+		webhooks:  webhooks,
+		cfg:       cfg,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// Enabled is false in simulation mode, where payout hashes never reach a chain
+func (t *ReceiptTracker) Enabled() bool {
+	return !t.walletSvc.Simulated()
+}
+
+// HasUnfinalized reports whether a red pocket still has payouts that could be reorged
+func (t *ReceiptTracker) HasUnfinalized(ctx context.Context, redPocketID string) (bool, error) {
+	if !t.Enabled() {
+		return false, nil
+	}
+	return t.repo.HasUnfinalized(ctx, redPocketID)
+}
+
+func (t *ReceiptTracker) ListReorgs(ctx context.Context, enterpriseID string, page, limit int) ([]*model.ClaimReorg, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return t.repo.ListReorgsByEnterprise(ctx, enterpriseID, limit, offset)
+}
+
+// Start follows each chain's head until ctx is cancelled, checking the
+// chain's unfinalized payouts whenever a new block appears
+func (t *ReceiptTracker) Start(ctx context.Context) {
+	if !t.Enabled() {
+		slog.InfoContext(ctx, "receipt tracker: simulation mode, payout tracking disabled")
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(t.cfg.ReceiptHeadInterval) * time.Second)
+	defer ticker.Stop()
+
+	// Head each chain's payouts were last checked at
+	checked := make(map[int64]int64)
+	for {
+		t.poll(ctx, checked)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *ReceiptTracker) poll(ctx context.Context, checked map[int64]int64) {
+	claims, err := t.repo.ListUnfinalized(ctx, receiptBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "receipt tracker: failed to list claims", "error", err)
+		return
+	}
+
+	byChain := make(map[int64][]*model.TrackedClaim)
+	for _, c := range claims {
+		byChain[c.ChainID] = append(byChain[c.ChainID], c)
+	}
+	for chainID, chainClaims := range byChain {
+		rpcURL := t.xcmBridge.rpcURL(ChainID(chainID))
+		if rpcURL == "" {
+			slog.WarnContext(ctx, "receipt tracker: payouts on unsupported chain", "chain_id", chainID, "payouts", len(chainClaims))
+			continue
+		}
+		head, err := t.blockNumber(ctx, rpcURL)
+		if err != nil {
+			slog.ErrorContext(ctx, "receipt tracker: failed to get head", "chain_id", chainID, "error", err)
+			continue
+		}
+		if head == checked[chainID] {
+			continue
+		}
+		checked[chainID] = head
+
+		for _, c := range chainClaims {
+			if err := t.check(ctx, c, rpcURL, head); err != nil {
+				slog.ErrorContext(ctx, "receipt tracker: failed to check claim", "claim_id", c.ClaimID, "tx_hash", c.TxHash, "error", err)
+			}
+		}
+	}
+}
+
+type txReceipt struct {
+	BlockNumber string  `json:"blockNumber"`
+	BlockHash   string  `json:"blockHash"`
+	Status      string  `json:"status"`
+	Logs        []txLog `json:"logs"`
+}
+
+type txLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+func (t *ReceiptTracker) check(ctx context.Context, c *model.TrackedClaim, rpcURL string, head int64) error {
+	result, err := callRPC(ctx, t.httpClient, rpcURL, "eth_getTransactionReceipt", c.TxHash)
+	if err != nil {
+		return err
+	}
+
+	var receipt *txReceipt
+	if err := json.Unmarshal(result, &receipt); err != nil {
+		return fmt.Errorf("invalid receipt: %w", err)
+	}
+
+	if receipt == nil || receipt.BlockHash == "" {
+		if c.BlockHash != "" {
+			return t.resubmit(ctx, c, model.ClaimReorged, fmt.Sprintf("tx no longer included, was in block %d (%s)", c.BlockNumber, c.BlockHash))
+		}
+		if c.SubmittedAt != nil && time.Since(*c.SubmittedAt) > time.Duration(t.cfg.ReceiptDropTimeout)*time.Second {
+			return t.resubmit(ctx, c, model.ClaimReorged, fmt.Sprintf("tx not included after %ds", t.cfg.ReceiptDropTimeout))
+		}
+		return nil
+	}
+
+	if receipt.Status == "0x0" {
+		return t.resubmit(ctx, c, model.ClaimReverted, "payout transaction reverted")
+	}
+	// A bundle transaction succeeds even when the user operation inside it
+	// reverted; the EntryPoint's event tells
+	if c.UserOpHash != "" {
+		success, err := t.userOpSucceeded(receipt.Logs, c.UserOpHash)
+		if err != nil {
+			return t.escalate(ctx, c, model.ClaimReverted, err.Error())
+		}
+		if !success {
+			return t.resubmit(ctx, c, model.ClaimReverted, "user operation "+c.UserOpHash+" reverted")
+		}
+	}
+
+	blockNumber, err := parseHexInt64(receipt.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("invalid block number: %w", err)
+	}
+
+	confirmations := int(head - blockNumber + 1)
+	if confirmations < 0 {
+		confirmations = 0
+	}
+
+	if c.BlockHash != "" && !strings.EqualFold(c.BlockHash, receipt.BlockHash) {
+		slog.WarnContext(ctx, "receipt tracker: claim re-included after reorg", "claim_id", c.ClaimID,
+			"from_block", c.BlockNumber, "from_block_hash", c.BlockHash, "to_block", blockNumber, "to_block_hash", receipt.BlockHash)
+	}
+	if err := t.repo.Observe(ctx, c.ClaimID, c.TxHash, blockNumber, receipt.BlockHash, confirmations); err != nil {
+		return err
+	}
+
+	if confirmations < t.cfg.ReceiptConfirmations {
+		return nil
+	}
+
+	// Recheck inclusion at depth: the canonical block at that height must be the one we saw
+	canonical, err := t.blockHash(ctx, rpcURL, blockNumber)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(canonical, receipt.BlockHash) {
+		// Node is mid-reorg; the next poll sees a consistent view
+		return nil
+	}
+	return t.repo.Finalize(ctx, c.ClaimID, confirmations)
+}
+
+// userOpSucceeded reads the outcome of a user operation from the
+// UserOperationEvent an EntryPoint logged for it
+func (t *ReceiptTracker) userOpSucceeded(logs []txLog, userOpHash string) (bool, error) {
+	event := entryPointABI.Events["UserOperationEvent"]
+	for _, l := range logs {
+		if !strings.EqualFold(l.Address, t.cfg.EntryPoint) && !strings.EqualFold(l.Address, t.cfg.EntryPointV07) {
+			continue
+		}
+		if len(l.Topics) < 2 || !strings.EqualFold(l.Topics[0], event.ID.Hex()) || !strings.EqualFold(l.Topics[1], userOpHash) {
+			continue
+		}
+		data, err := parseHexBytes("log data", l.Data)
+		if err != nil {
+			return false, err
+		}
+		values, err := event.Inputs.NonIndexed().Unpack(data)
+		if err != nil {
+			return false, fmt.Errorf("invalid UserOperationEvent: %w", err)
+		}
+		success, ok := values[1].(bool)
+		if !ok {
+			return false, fmt.Errorf("invalid UserOperationEvent success flag")
+		}
+		return success, nil
+	}
+	return false, fmt.Errorf("no UserOperationEvent for %s in the payout transaction", userOpHash)
+}
+
+// resubmit pays a claim again after its payout left the chain or reverted,
+// and escalates it as failStatus once that is not possible. For AA wallets a
+// lost payout's replacement reuses the account nonce the lost operation had,
+// so at most one of them can ever land even if the original is re-mined
+// later; a reverted operation already used its nonce and moved nothing.
+func (t *ReceiptTracker) resubmit(ctx context.Context, c *model.TrackedClaim, failStatus model.ClaimStatus, reason string) error {
+	if c.ResubmitCount >= t.cfg.MaxTxResubmits {
+		return t.escalate(ctx, c, failStatus, reason+"; resubmit limit reached")
+	}
+	if c.BatchID != "" {
+		// Re-paying one claim on its own would split the batch's shared nonce
+		return t.escalate(ctx, c, failStatus, reason+"; part of settlement batch "+c.BatchID)
+	}
+
+	sender, err := t.walletSvc.GetOrCreate(ctx, PayoutWalletID(c.RedPocketID), c.ChainID)
+	if err != nil {
+		return t.escalate(ctx, c, failStatus, reason+"; payout wallet not found: "+err.Error())
+	}
+
+	claim, err := t.claimRepo.GetByID(ctx, c.ClaimID)
+	if err != nil {
+		return t.escalate(ctx, c, failStatus, reason+"; claim not found: "+err.Error())
+	}
+	newTxHash, err := payClaim(ctx, t.walletSvc, t.savings, sender, c.TokenAddress, claim)
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		// Replacement is still in the mempool; the UserOpMonitor settles the
+		// claim and tracking resumes once it has a tx hash again
+		newTxHash = ""
+		if err := t.walletSvc.AttachUserOp(ctx, pending.UserOpHash, c.ClaimID); err != nil {
+			return err
+		}
+		reason += "; replacement pending as user operation " + pending.UserOpHash
+	case err != nil:
+		return t.escalate(ctx, c, failStatus, reason+"; resubmit failed: "+err.Error())
+	}
+
+	if err := t.repo.Resubmitted(ctx, c.ClaimID, newTxHash); err != nil {
+		return fmt.Errorf("resubmitted but failed to record it: %w", err)
+	}
+	if newTxHash == "" {
+		ok, err := t.claimRepo.MarkResubmitted(ctx, c.ClaimID, c.ResubmitCount+2, reason)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			slog.WarnContext(ctx, "receipt tracker: claim cannot be resubmitted from its current status", "claim_id", c.ClaimID)
+		}
+	}
+	slog.WarnContext(ctx, "receipt tracker: payout failed, resubmitted", "claim_id", c.ClaimID, "tx_hash", c.TxHash, "reason", reason, "new_tx_hash", newTxHash)
+
+	return t.repo.LogReorg(ctx, &model.ClaimReorg{
+		ClaimID:     c.ClaimID,
+		TxHash:      c.TxHash,
+		BlockNumber: c.BlockNumber,
+		BlockHash:   c.BlockHash,
+		Action:      "resubmitted",
+		NewTxHash:   newTxHash,
+		Error:       reason,
+	})
+}
+
+// escalate flags the claim for manual handling as status: reorged when its
+// payout left the chain, reverted when it reverted
+func (t *ReceiptTracker) escalate(ctx context.Context, c *model.TrackedClaim, status model.ClaimStatus, reason string) error {
+	slog.ErrorContext(ctx, "receipt tracker: payout needs manual review", "claim_id", c.ClaimID, "tx_hash", c.TxHash, "reason", reason, "alert", true)
+
+	if err := transitionClaim(ctx, t.claimRepo, c.ClaimID, status, c.TxHash, reason); err != nil {
+		return err
+	}
+	t.webhooks.PayoutFailed(ctx, c.ClaimID, reason)
+	return t.repo.LogReorg(ctx, &model.ClaimReorg{
+		ClaimID:     c.ClaimID,
+		TxHash:      c.TxHash,
+		BlockNumber: c.BlockNumber,
+		BlockHash:   c.BlockHash,
+		Action:      "escalated",
+		Error:       reason,
+	})
+}
+
+func (t *ReceiptTracker) blockNumber(ctx context.Context, rpcURL string) (int64, error) {
+	result, err := callRPC(ctx, t.httpClient, rpcURL, "eth_blockNumber")
+	if err != nil {
+		return 0, err
+	}
+	var hex string
+	if err := json.Unmarshal(result, &hex); err != nil {
+		return 0, err
+	}
+	return parseHexInt64(hex)
+}
+
+func (t *ReceiptTracker) blockHash(ctx context.Context, rpcURL string, number int64) (string, error) {
+	result, err := callRPC(ctx, t.httpClient, rpcURL, "eth_getBlockByNumber", fmt.Sprintf("0x%x", number), false)
+	if err != nil {
+		return "", err
+	}
+	var block *struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(result, &block); err != nil {
+		return "", err
+	}
+	if block == nil {
+		return "", fmt.Errorf("block %d not found", number)
+	}
+	return block.Hash, nil
+}
+
+func parseHexInt64(s string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(s, "0x"), 16, 64)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+)
+
+// redisBreaker is a Redis hook that opens a circuit breaker after
+// DOWNSTREAM_REDIS_BREAKER_THRESHOLD connection failures in a row. While it
+// is open commands fail with ErrDownstreamUnavailable at once instead of
+// each waiting out the client's dial timeout and retries.
+type redisBreaker struct {
+	d *Downstream
+}
+
+var _ redis.Hook = redisBreaker{}
+
+// NewRedisBreaker returns the circuit breaker hook for the Redis client
+func NewRedisBreaker(cfg *config.Config) redis.Hook {
+	return redisBreaker{d: NewDownstream("redis", cfg.DownstreamRedis)}
+}
+
+func (b redisBreaker) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, b.d.allow()
+}
+
+func (b redisBreaker) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	b.record(ctx, cmd.Err())
+	return nil
+}
+
+func (b redisBreaker) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, b.d.allow()
+}
+
+func (b redisBreaker) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if err = cmd.Err(); redisDown(err) {
+			break
+		}
+	}
+	b.record(ctx, err)
+	return nil
+}
+
+// record counts a command's outcome. Commands the breaker refused were never
+// sent and say nothing new.
+func (b redisBreaker) record(ctx context.Context, err error) {
+	switch {
+	case errors.Is(err, ErrDownstreamUnavailable):
+	case ctx.Err() != nil:
+		b.d.abandon()
+	default:
+		b.d.record(!redisDown(err))
+	}
+}
+
+// redisDown reports whether err means Redis could not be reached. Error
+// replies, a missing key among them, come from a server that is up.
+func redisDown(err error) bool {
+	var reply redis.Error
+	return err != nil && !errors.As(err, &reply)
+}
+
+// claimLock locks a claimer's claim on a pocket in Redis or, while Redis is
+// unavailable and REDIS_FALLBACK is on, with a Postgres advisory lock. It
+// returns ErrClaimLockFailed when the lock is held or neither can be taken.
+func (s *RedPocketService) claimLock(ctx context.Context, key string) (release func(), err error) {
+	acquired, err := s.redis.AcquireLock(ctx, key, 10*time.Second)
+	if err == nil {
+		if !acquired {
+			return nil, ErrClaimLockFailed
+		}
+		return func() { s.redis.ReleaseLock(ctx, key) }, nil
+	}
+	if !s.cfg.RedisFallback {
+		return nil, ErrClaimLockFailed
+	}
+
+	slog.DebugContext(ctx, "redis unavailable, locking the claim in postgres", "error", err)
+	release, acquired, err = s.rpRepo.TryClaimLock(ctx, key)
+	if err != nil || !acquired {
+		return nil, ErrClaimLockFailed
+	}
+	return release, nil
+}
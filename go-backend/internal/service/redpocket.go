@@ -2,102 +2,354 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
-	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/bot"
 	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/metrics"
 	"github.com/protocolbank/redpocket-backend/internal/model"
 	"github.com/protocolbank/redpocket-backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	ErrRedPocketNotFound  = errors.New("red pocket not found")
-	ErrRedPocketExpired   = errors.New("red pocket has expired")
-	ErrRedPocketDepleted  = errors.New("red pocket is fully claimed")
-	ErrAlreadyClaimed     = errors.New("you have already claimed this red pocket")
-	ErrInsufficientFunds  = errors.New("insufficient funds in red pocket")
-	ErrClaimLockFailed    = errors.New("claim in progress, please try again")
+	ErrRedPocketNotFound = newCodedError("red_pocket_not_found")
+	ErrRedPocketExpired  = newCodedError("red_pocket_expired")
+	ErrRedPocketDepleted = newCodedError("red_pocket_depleted")
+	ErrAlreadyClaimed    = newCodedError("already_claimed")
+	ErrInsufficientFunds = newCodedError("insufficient_funds")
+	ErrClaimLockFailed   = newCodedError("claim_in_progress")
+	ErrTransferFailed    = newCodedError("transfer_failed")
+
+	ErrInvalidStartTime        = newCodedError("invalid_start_time")
+	ErrClaimPasswordRequired   = newCodedError("claim_password_required")
+	ErrInvalidClaimPassword    = newCodedError("invalid_claim_password")
+	ErrTooManyPasswordAttempts = newCodedError("too_many_password_attempts")
+	ErrBudgetExceeded          = newCodedError("campaign_budget_exceeded")
+	ErrCampaignTokenMismatch   = newCodedError("campaign_token_mismatch")
+
+	ErrRedPocketPaused   = newCodedError("red_pocket_paused")
+	ErrPocketNotPausable = newCodedError("pocket_not_pausable")
+	ErrPocketNotPaused   = newCodedError("pocket_not_paused")
+
+	ErrPocketNotExtendable = newCodedError("pocket_not_extendable")
+	ErrInvalidExpiry       = newCodedError("invalid_expiry")
 )
 
+// errRedPocketNotStarted tells claimers when a scheduled pocket opens
+func errRedPocketNotStarted(startsAt time.Time) *CodedError {
+	return newCodedError("red_pocket_not_started", startsAt.UTC().Format(time.RFC3339))
+}
+
+// errTermsNotAccepted asks the claimer to accept the current terms version
+func errTermsNotAccepted(version string) *CodedError {
+	return newCodedError("terms_not_accepted", version)
+}
+
 type RedPocketService struct {
-	rpRepo    *repository.RedPocketRepository
-	claimRepo *repository.ClaimRepository
-	walletSvc *WalletService
-	redis     *repository.RedisClient
-	cfg       *config.Config
+	rpRepo       repository.RedPocketStore
+	claimRepo    repository.ClaimStore
+	campaignRepo repository.CampaignStore
+	walletSvc    *WalletService
+	audienceSvc  *AudienceService
+	eligibility  *EligibilityService
+	fraud        *FraudService
+	payoutQueue  *PayoutQueue
+	splitSvc     *PayoutSplitService
+	savings      *SavingsService
+	tokens       *TokenRegistry
+	ledgerSvc    *LedgerService
+	funding      *PocketFunding
+	events       *PocketEvents
+	cache        *PocketCache
+	captcha      *CaptchaVerifier
+	failures     *ClaimFailureLog
+	links        *ClaimLinks
+	limiter      *ClaimRateLimiter
+	memberRoles  MemberRoles
+	announcer    PocketAnnouncer
+	hunts        *TreasureHuntService
+	teams        *TeamService
+	rules        *RuleEngine
+	redis        *repository.RedisClient
+	cfg          *config.Config
 }
 
 func NewRedPocketService(
-	rpRepo *repository.RedPocketRepository,
-	claimRepo *repository.ClaimRepository,
+	rpRepo repository.RedPocketStore,
+	claimRepo repository.ClaimStore,
+	campaignRepo repository.CampaignStore,
 	walletSvc *WalletService,
+	audienceSvc *AudienceService,
+	eligibility *EligibilityService,
+	fraud *FraudService,
+	payoutQueue *PayoutQueue,
+	splitSvc *PayoutSplitService,
+	savings *SavingsService,
+	tokens *TokenRegistry,
+	ledgerSvc *LedgerService,
+	funding *PocketFunding,
+	events *PocketEvents,
+	cache *PocketCache,
+	captcha *CaptchaVerifier,
+	failures *ClaimFailureLog,
+	links *ClaimLinks,
+	limiter *ClaimRateLimiter,
 	redis *repository.RedisClient,
 	cfg *config.Config,
 ) *RedPocketService {
 	return &RedPocketService{
-		rpRepo:    rpRepo,
-		claimRepo: claimRepo,
-		walletSvc: walletSvc,
-		redis:     redis,
-		cfg:       cfg,
+		rpRepo:       rpRepo,
+		claimRepo:    claimRepo,
+		campaignRepo: campaignRepo,
+		walletSvc:    walletSvc,
+		audienceSvc:  audienceSvc,
+		eligibility:  eligibility,
+		fraud:        fraud,
+		payoutQueue:  payoutQueue,
+		splitSvc:     splitSvc,
+		savings:      savings,
+		tokens:       tokens,
+		ledgerSvc:    ledgerSvc,
+		funding:      funding,
+		events:       events,
+		cache:        cache,
+		captcha:      captcha,
+		failures:     failures,
+		links:        links,
+		limiter:      limiter,
+		redis:        redis,
+		cfg:          cfg,
 	}
 }
 
 type CreateRedPocketRequest struct {
-	CampaignID   string  `json:"campaignId" binding:"required"`
-	SenderName   string  `json:"senderName"`
-	SenderAvatar string  `json:"senderAvatar"`
-	Amount       float64 `json:"amount" binding:"required,gt=0"`
-	Token        string  `json:"token" binding:"required"`
-	TokenAddress string  `json:"tokenAddress"`
-	Platform     string  `json:"platform" binding:"required"`
-	ChannelID    string  `json:"platformChannelId"`
-	Message      string  `json:"message"`
-	Tag          string  `json:"tag"`
-	TotalCount   int     `json:"totalCount" binding:"required,gt=0"`
-	IsLuckyDraw  bool    `json:"isLuckyDraw"`
-	MinAmount    float64 `json:"minAmount"`
-	MaxAmount    float64 `json:"maxAmount"`
-	ExpiresIn    int64   `json:"expiresIn"` // seconds, default 7 days
-}
-
-func (s *RedPocketService) Create(ctx context.Context, req *CreateRedPocketRequest) (*model.RedPocket, error) {
+	CampaignID   string      `json:"campaignId" binding:"required"`
+	SenderName   string      `json:"senderName"`
+	SenderAvatar string      `json:"senderAvatar"`
+	Amount       json.Number `json:"amount" binding:"required"` // decimal token amount, e.g. "1.5"
+	Token        string      `json:"token" binding:"required"`  // symbol in the token registry
+	TokenAddress string      `json:"tokenAddress"`              // optional, must match the registry
+	Platform     string      `json:"platform" binding:"required"`
+	ChannelID    string      `json:"platformChannelId"`
+	Message      string      `json:"message"`
+	CoverImage   string      `json:"coverImage"` // e.g. gatewayUrl of a pinned cover_image
+	Tag          string      `json:"tag"`
+	TotalCount   int         `json:"totalCount" binding:"required,gt=0"`
+	IsLuckyDraw  bool        `json:"isLuckyDraw"`
+	MinAmount    float64     `json:"minAmount"`
+	MaxAmount    float64     `json:"maxAmount"`
+	ExpiresIn    int64       `json:"expiresIn"` // seconds, default 7 days
+
+	// Optional claim protection
+	ClaimPassword string `json:"claimPassword" binding:"omitempty,max=72"` // stored as a bcrypt hash
+	CaptchaMode   string `json:"captchaMode" binding:"omitempty,oneof=hcaptcha turnstile"`
+	SignedLinks   bool   `json:"signedLinks"` // claims need a signed claim link, see ClaimLinks
+
+	// Optional scheduling: open at StartsAt instead of now, and repeat on
+	// Recurrence (daily, weekly or a cron expression in UTC) until RecurrenceUntil
+	StartsAt        *time.Time `json:"startsAt"`
+	Recurrence      string     `json:"recurrence" binding:"max=64"`
+	RecurrenceUntil *time.Time `json:"recurrenceUntil"`
+
+	Locale string `json:"locale"` // audience language; default the request's locale
+
+	// Optional eligibility rules of this red pocket, on top of the campaign's
+	Eligibility []EligibilityRuleRequest `json:"eligibility" binding:"dive"`
+
+	// Optional per-channel claim caps and sub-budgets, for pockets announced
+	// in several channels; claims then have to come from one of them
+	ChannelLimits []ChannelLimitRequest `json:"channelLimits" binding:"dive"`
+
+	// Post expiry reminders in the channel too, not only to the enterprise's
+	// pocket.expiring webhooks
+	RemindChannel bool `json:"remindChannel"`
+}
+
+// Create creates a red pocket in a campaign of the enterprise. It is paid
+// out of the campaign's budget, deposit or ledger balance, so a campaign of
+// another enterprise is not found.
+func (s *RedPocketService) Create(ctx context.Context, enterpriseID string, req *CreateRedPocketRequest) (*model.RedPocket, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, req.CampaignID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && campaign.EnterpriseID != enterpriseID) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign: %w", err)
+	}
+	return s.create(ctx, req)
+}
+
+// create creates a red pocket in the request's campaign, for callers that
+// already act for its enterprise
+func (s *RedPocketService) create(ctx context.Context, req *CreateRedPocketRequest) (*model.RedPocket, error) {
 	expiresIn := req.ExpiresIn
 	if expiresIn == 0 {
 		expiresIn = 7 * 24 * 60 * 60 // 7 days
 	}
+	if req.CaptchaMode != "" && !s.captcha.Available(req.CaptchaMode) {
+		return nil, ErrCaptchaUnavailable
+	}
+	if req.SignedLinks && !s.links.Available() {
+		return nil, ErrClaimLinksUnavailable
+	}
+	now := time.Now()
+	startsAt := now
+	status := model.PocketActive
+	if req.StartsAt != nil {
+		if req.StartsAt.Before(now.Add(-time.Minute)) {
+			return nil, ErrInvalidStartTime
+		}
+		if req.StartsAt.After(now) {
+			startsAt = *req.StartsAt
+			status = model.PocketScheduled
+		}
+	}
+	if req.Recurrence != "" {
+		if _, err := parseRecurrence(req.Recurrence); err != nil {
+			return nil, err
+		}
+		// Every instance of a series is opened by the scheduler, which
+		// creates the next one
+		status = model.PocketScheduled
+	}
+	rules, err := s.eligibility.build(req.Eligibility)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.tokens.Resolve(ctx, req.Token, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	if req.TokenAddress != "" && !strings.EqualFold(req.TokenAddress, token.Address) {
+		return nil, ErrUnsupportedToken
+	}
+	campaign, err := s.campaignRepo.GetByID(ctx, req.CampaignID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign: %w", err)
+	}
+	// A campaign's budget is counted in its own token only
+	if !strings.EqualFold(token.Symbol, campaign.Token) {
+		return nil, ErrCampaignTokenMismatch
+	}
+	// Native transfers cannot share an executeBatch or come out of the ledger
+	if token.Native() && (s.payoutQueue.Batched() || s.ledgerSvc.CreditMode(ctx, req.CampaignID)) {
+		return nil, ErrNativeTokenUnsupported
+	}
+	amountUnits, ok := parseUnits(req.Amount.String(), token.Decimals)
+	if !ok || amountUnits.Cmp(big.NewInt(int64(req.TotalCount))) < 0 {
+		return nil, ErrInvalidAmount
+	}
+	units := model.NewUnits(amountUnits)
+
+	var passwordHash string
+	if req.ClaimPassword != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.ClaimPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash claim password: %w", err)
+		}
+		passwordHash = string(hash)
+	}
 
 	rp := &model.RedPocket{
 		ID:              "rp_" + uuid.New().String()[:8],
 		CampaignID:      req.CampaignID,
 		SenderName:      req.SenderName,
 		SenderAvatar:    req.SenderAvatar,
-		Amount:          req.Amount,
-		RemainingAmount: req.Amount,
-		Token:           req.Token,
-		TokenAddress:    req.TokenAddress,
+		Amount:          units.Float(token.Decimals),
+		RemainingAmount: units.Float(token.Decimals),
+		Token:           token.Symbol,
+		TokenAddress:    token.Address,
 		ChainID:         s.cfg.ChainID,
 		Platform:        req.Platform,
 		ChannelID:       req.ChannelID,
 		Message:         req.Message,
+		CoverImage:      req.CoverImage,
 		Tag:             req.Tag,
 		TotalCount:      req.TotalCount,
 		ClaimedCount:    0,
 		IsLuckyDraw:     req.IsLuckyDraw,
 		MinAmount:       req.MinAmount,
 		MaxAmount:       req.MaxAmount,
-		ExpiresAt:       time.Now().Add(time.Duration(expiresIn) * time.Second),
-		CreatedAt:       time.Now(),
-		Status:          "active",
+		ExpiresAt:       startsAt.Add(time.Duration(expiresIn) * time.Second),
+		CreatedAt:       now,
+		Status:          status,
+
+		ClaimPasswordHash: passwordHash,
+		CaptchaMode:       req.CaptchaMode,
+		SignedLinks:       req.SignedLinks,
+		RemindChannel:     req.RemindChannel,
+
+		Recurrence:      req.Recurrence,
+		RecurrenceUntil: req.RecurrenceUntil,
+
+		Locale: i18n.Normalize(req.Locale),
+
+		Decimals:       token.Decimals,
+		AmountUnits:    units,
+		RemainingUnits: units,
+	}
+	if req.Locale == "" {
+		rp.Locale = i18n.FromContext(ctx)
+	}
+	if rp.ChannelLimits, err = buildChannelLimits(req.ChannelLimits, rp); err != nil {
+		return nil, err
+	}
+	if status == model.PocketScheduled {
+		rp.StartsAt = &startsAt
+	}
+	if rp.Recurrence != "" {
+		rp.SeriesID = rp.ID
+	}
+	rp.TestMode = campaign.TestMode
+	schedule, err := s.feeSchedule(ctx, rp.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+	if rp.TestMode {
+		// Sandbox pockets are never billed
+		schedule = &model.PlatformFeeSchedule{Plan: SandboxPlan}
+	}
+	applyFees(rp, schedule)
+	if err := seedLuckyDraw(rp); err != nil {
+		return nil, err
+	}
+	if err := s.checkNativeShares(ctx, rp); err != nil {
+		return nil, err
+	}
+	deposit, err := s.funding.Deposit(ctx, rp)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.rpRepo.Create(ctx, rp); err != nil {
+	ok, err = s.rpRepo.CreateWithinBudget(ctx, rp, deposit)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to create red pocket: %w", err)
 	}
+	if !ok {
+		return nil, ErrBudgetExceeded
+	}
+	if len(rules) > 0 {
+		if err := s.eligibility.setForRedPocket(ctx, rp.ID, rules); err != nil {
+			return nil, fmt.Errorf("failed to save eligibility rules: %w", err)
+		}
+	}
+	s.events.PublishCreated(ctx, rp)
 
 	return rp, nil
 }
@@ -106,53 +358,244 @@ type ClaimRequest struct {
 	RedPocketID string `json:"redPocketId" binding:"required"`
 	PlatformID  string `json:"platformId" binding:"required"`
 	Platform    string `json:"platform" binding:"required"`
+
+	// Holder proof for snapshot-gated campaigns and token gates: personal_sign of AudienceClaimMessage
+	WalletAddress string `json:"walletAddress"`
+	Signature     string `json:"signature"`
+
+	// Required when the campaign has terms: the version shown to the claimer
+	AcceptedTermsVersion string `json:"acceptedTermsVersion"`
+	ClientIP             string `json:"-"`
+	Country              string `json:"-"` // ISO country of ClientIP, for geo rules
+
+	// Required when the red pocket is password or CAPTCHA protected, or
+	// when fraud scoring asks for a CAPTCHA
+	Password     string `json:"password"`
+	CaptchaToken string `json:"captchaToken"`
+
+	// Required when the red pocket needs signed links: the t parameter of
+	// the claim link
+	LinkToken string `json:"linkToken"`
+
+	// Stable browser or app fingerprint computed by the claim page, scored
+	// for device reuse across claimers
+	DeviceFingerprint string `json:"deviceFingerprint" binding:"max=128"`
+
+	// Chat channel the claim was made from, for pockets with channel
+	// limits: a Telegram chat ID, a Discord channel ID or TEAM_ID:CHANNEL_ID
+	// on Slack
+	ChannelID string `json:"channelId" binding:"max=128"`
+
+	// Set by chat bots: the claimer administers the chat the claim was
+	// made from, for telegram_admin claim weights
+	ChatAdmin bool `json:"-"`
 }
 
 type ClaimResponse struct {
 	Success       bool    `json:"success"`
+	ClaimID       string  `json:"claimId,omitempty"`
 	ClaimedAmount float64 `json:"claimedAmount,omitempty"`
+	Donated       float64 `json:"donated,omitempty"` // share of ClaimedAmount given to the campaign's charity
+	Fee           float64 `json:"fee,omitempty"`     // platform fee withheld from ClaimedAmount
 	WalletAddress string  `json:"walletAddress,omitempty"`
 	TxHash        string  `json:"txHash,omitempty"`
+	Status        string  `json:"status,omitempty"`
+	UserOpHash    string  `json:"userOpHash,omitempty"`
 	Error         string  `json:"error,omitempty"`
+	ErrorCode     string  `json:"errorCode,omitempty"`
+	Reference     string  `json:"reference,omitempty"` // support reference of a failed claim
+	Multiplier    float64 `json:"multiplier,omitempty"` // campaign claim weight ClaimedAmount was multiplied by
+
+	// Set with captcha_required and captcha_failed when fraud scoring asks
+	// for a CAPTCHA the red pocket itself does not have
+	Captcha        string `json:"captcha,omitempty"`
+	CaptchaSiteKey string `json:"captchaSiteKey,omitempty"`
+
+	// Set with claim_rate_limited: seconds until the claimer may try again
+	RetryAfter int `json:"retryAfter,omitempty"`
 }
 
-func (s *RedPocketService) Claim(ctx context.Context, req *ClaimRequest) (*ClaimResponse, error) {
-	// 1. Acquire distributed lock to prevent race conditions
-	lockKey := fmt.Sprintf("claim:%s:%s:%s", req.RedPocketID, req.Platform, req.PlatformID)
-	acquired, err := s.redis.AcquireLock(ctx, lockKey, 10*time.Second)
-	if err != nil || !acquired {
-		return &ClaimResponse{Success: false, Error: ErrClaimLockFailed.Error()}, nil
+// claimFailure builds a failed ClaimResponse localized to the request locale
+func claimFailure(ctx context.Context, err error) *ClaimResponse {
+	return &ClaimResponse{
+		Success:   false,
+		Error:     LocalizedError(ctx, err),
+		ErrorCode: ErrorCode(err),
 	}
-	defer s.redis.ReleaseLock(ctx, lockKey)
+}
 
-	// 2. Check if already claimed
-	claimed, err := s.claimRepo.HasClaimed(ctx, req.RedPocketID, req.PlatformID, req.Platform)
+// claimResult labels a claim outcome for metrics
+func claimResult(resp *ClaimResponse, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case !resp.Success:
+		return resp.ErrorCode
+	}
+	return "success"
+}
+
+func (s *RedPocketService) Claim(ctx context.Context, req *ClaimRequest) (resp *ClaimResponse, err error) {
+	// 0. Turn away claimers over their rate limits before doing any work,
+	// including recording the failure
+	if wait := s.limiter.Allow(ctx, req); wait > 0 {
+		metrics.Claims.Inc(ErrClaimRateLimited.Code)
+		resp := claimFailure(ctx, ErrClaimRateLimited)
+		resp.RetryAfter = int((wait + time.Second - 1) / time.Second)
+		return resp, nil
+	}
+
+	defer func() {
+		metrics.Claims.Inc(claimResult(resp, err))
+
+		// Give failures a reference the claimer can quote to support
+		switch {
+		case err != nil:
+			err = &ClaimError{Reference: s.failures.Record(ctx, req, nil, err), Err: err}
+		case !resp.Success:
+			resp.Reference = s.failures.Record(ctx, req, resp, nil)
+		}
+	}()
+
+	// 1. Get red pocket and check its claim link, password and CAPTCHA
+	// before taking the lock, so guessing cannot hold up the claimer's real
+	// attempt
+	rp, err := s.rpRepo.GetByID(ctx, req.RedPocketID)
 	if err != nil {
+		return claimFailure(ctx, ErrRedPocketNotFound), nil
+	}
+	link, err := s.links.Verify(ctx, rp, req)
+	if err != nil {
+		var coded *CodedError
+		if errors.As(err, &coded) {
+			return claimFailure(ctx, err), nil
+		}
 		return nil, err
 	}
-	if claimed {
-		return &ClaimResponse{Success: false, Error: ErrAlreadyClaimed.Error()}, nil
+	if err := s.checkProtection(ctx, rp, req); err != nil {
+		var coded *CodedError
+		if errors.As(err, &coded) {
+			return claimFailure(ctx, err), nil
+		}
+		return nil, err
 	}
 
-	// 3. Get red pocket
-	rp, err := s.rpRepo.GetByID(ctx, req.RedPocketID)
+	// 2. Acquire distributed lock to prevent race conditions
+	lockKey := fmt.Sprintf("claim:%s:%s:%s", req.RedPocketID, req.Platform, req.PlatformID)
+	unlock, err := s.claimLock(ctx, lockKey)
+	if err != nil {
+		return claimFailure(ctx, err), nil
+	}
+	defer unlock()
+
+	// 3. Check if already claimed
+	claimed, err := s.claimRepo.HasClaimed(ctx, req.RedPocketID, req.PlatformID, req.Platform)
 	if err != nil {
-		return &ClaimResponse{Success: false, Error: ErrRedPocketNotFound.Error()}, nil
+		return nil, err
+	}
+	if claimed {
+		return claimFailure(ctx, ErrAlreadyClaimed), nil
 	}
 
 	// 4. Validate status
+	if rp.Status == "scheduled" && rp.StartsAt != nil {
+		return claimFailure(ctx, errRedPocketNotStarted(*rp.StartsAt)), nil
+	}
+	if rp.Status == model.PocketPaused {
+		return claimFailure(ctx, ErrRedPocketPaused), nil
+	}
 	if rp.Status != "active" {
-		return &ClaimResponse{Success: false, Error: fmt.Sprintf("red pocket is %s", rp.Status)}, nil
+		return claimFailure(ctx, newCodedError("red_pocket_inactive", rp.Status)), nil
 	}
 	if time.Now().After(rp.ExpiresAt) {
-		return &ClaimResponse{Success: false, Error: ErrRedPocketExpired.Error()}, nil
+		return claimFailure(ctx, ErrRedPocketExpired), nil
 	}
 	if rp.ClaimedCount >= rp.TotalCount {
-		return &ClaimResponse{Success: false, Error: ErrRedPocketDepleted.Error()}, nil
+		return claimFailure(ctx, ErrRedPocketDepleted), nil
+	}
+
+	// 4a. Require acceptance of the campaign's current terms, if any
+	terms := s.Terms(ctx, rp)
+	if terms != nil && req.AcceptedTermsVersion != terms.Version {
+		return claimFailure(ctx, errTermsNotAccepted(terms.Version)), nil
+	}
+
+	// 4b. Gate against the campaign's holder snapshot, if any
+	snapshot, audienceAddr, err := s.audienceSvc.CheckEligibility(ctx, rp, req)
+	if err != nil {
+		return claimFailure(ctx, err), nil
+	}
+
+	// 4c. Apply the eligibility rules of the campaign and the red pocket
+	if err := s.eligibility.Check(ctx, rp, req); err != nil {
+		var coded *CodedError
+		if errors.As(err, &coded) {
+			return claimFailure(ctx, err), nil
+		}
+		return nil, err
+	}
+
+	// Treasure hunt steps only take claims from those who unlocked them
+	if s.hunts != nil {
+		if err := s.hunts.CheckUnlocked(ctx, rp, req); err != nil {
+			var coded *CodedError
+			if errors.As(err, &coded) {
+				return claimFailure(ctx, err), nil
+			}
+			return nil, err
+		}
+	}
+
+	// 4d. Pockets with channel limits only take claims from their channels
+	channelLimit, err := channelLimitFor(rp, req.ChannelID)
+	if err != nil {
+		return claimFailure(ctx, err), nil
 	}
 
-	// 5. Calculate claim amount
-	claimAmount := s.calculateClaimAmount(rp)
+	// 4e. Score the attempt for sybil farming; suspicious claimers are
+	// challenged, held for review or turned away
+	assessment, err := s.fraud.Gate(ctx, rp, req)
+	if err != nil {
+		var coded *CodedError
+		if !errors.As(err, &coded) {
+			return nil, err
+		}
+		resp := claimFailure(ctx, err)
+		if errors.Is(err, ErrCaptchaRequired) || errors.Is(err, ErrCaptchaFailed) {
+			resp.Captcha = s.fraud.CaptchaMode()
+			resp.CaptchaSiteKey = s.captcha.SiteKey(resp.Captcha)
+		}
+		return resp, nil
+	}
+
+	// 5. Calculate claim amount, weighted by the claimer's attributes,
+	// within what is left of the channel's budget. Lucky draws draw from the
+	// pocket's seed and the claimer.
+	multiplier := s.claimMultiplier(ctx, rp, req)
+	draw, random, err := newLuckyDraw(rp, req.Platform, req.PlatformID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.baseClaimUnits(ctx, rp, draw, random)
+	if err != nil {
+		var coded *CodedError
+		if errors.As(err, &coded) {
+			return claimFailure(ctx, err), nil
+		}
+		return nil, err
+	}
+	share := weighClaimUnits(rp, base, multiplier)
+	if channelLimit != nil {
+		if share, err = s.channelShare(ctx, rp, channelLimit, share); err != nil {
+			var coded *CodedError
+			if errors.As(err, &coded) {
+				return claimFailure(ctx, err), nil
+			}
+			return nil, err
+		}
+	}
+	claimUnits := model.NewUnits(share)
+	claimAmount := claimUnits.Float(rp.Decimals)
 
 	// 6. Get or create wallet for user
 	userID := fmt.Sprintf("user_%s_%s", req.Platform, req.PlatformID)
@@ -161,13 +604,38 @@ func (s *RedPocketService) Claim(ctx context.Context, req *ClaimRequest) (*Claim
 		return nil, fmt.Errorf("failed to get/create wallet: %w", err)
 	}
 
-	// 7. Atomic update red pocket (prevents overselling)
-	_, err = s.rpRepo.ClaimAtomic(ctx, req.RedPocketID, claimAmount)
-	if err != nil {
-		return &ClaimResponse{Success: false, Error: ErrInsufficientFunds.Error()}, nil
+	// 6a. On-chain payouts follow the claimer's payout splits as they are now
+	creditMode := s.ledgerSvc.CreditMode(ctx, rp.CampaignID)
+	var splits []model.PayoutSplit
+	if !creditMode && rp.TokenAddress != "" {
+		if splits, err = s.splitSvc.SplitsFor(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to load payout splits: %w", err)
+		}
+	}
+
+	// 6b. Campaigns with a charity round-up donate a share of every claim.
+	// Native tokens cannot be paid to two addresses at once and are exempt.
+	donationUnits := model.NewUnits(new(big.Int))
+	var charityAddress string
+	if charity := s.Charity(ctx, rp); charity != nil && rp.TokenAddress != "" {
+		donation := new(big.Int).Mul(claimUnits.Int(), big.NewInt(int64(charity.Bps)))
+		donationUnits = model.NewUnits(donation.Quo(donation, big.NewInt(payoutSplitBps)))
+		charityAddress = charity.Address
 	}
 
-	// 8. Create claim record
+	// 6c. The platform's claim fee is withheld from what the claimer receives
+	feeUnits := model.NewUnits(claimFeeUnits(rp, claimUnits.Int(), donationUnits.Int()))
+
+	// 6d. Claimers saving in a vault for the pocket's token have their own
+	// share deposited there instead of their wallet
+	var vault *model.YieldVault
+	if !creditMode && rp.TokenAddress != "" {
+		if vault, err = s.savings.VaultFor(ctx, userID, rp); err != nil {
+			return nil, fmt.Errorf("failed to load savings vault: %w", err)
+		}
+	}
+
+	// 7. Build claim record
 	claim := &model.Claim{
 		ID:            "claim_" + uuid.New().String()[:8],
 		RedPocketID:   req.RedPocketID,
@@ -176,75 +644,456 @@ func (s *RedPocketService) Claim(ctx context.Context, req *ClaimRequest) (*Claim
 		Platform:      req.Platform,
 		WalletAddress: wallet.Address,
 		Amount:        claimAmount,
-		Status:        "processing",
+		Status:        "pending",
 		CreatedAt:     time.Now(),
+		Attempts:      1,
+		PayoutSplits:  splits,
+		AmountUnits:   claimUnits,
+
+		CharityAddress: charityAddress,
+		Donation:       donationUnits.Float(rp.Decimals),
+		DonationUnits:  donationUnits,
+		SavingsUnits:   model.NewUnits(new(big.Int)),
+
+		Fee:      feeUnits.Float(rp.Decimals),
+		FeeUnits: feeUnits,
+
+		TestMode: rp.TestMode,
 	}
-	if err := s.claimRepo.Create(ctx, claim); err != nil {
-		return nil, fmt.Errorf("failed to create claim: %w", err)
+	if vault != nil {
+		claim.SavingsVaultID = vault.ID
+		claim.SavingsUnits = model.NewUnits(savingsShare(claim))
+	}
+	if link != nil {
+		claim.LinkID = link.LinkID
+	}
+	if channelLimit != nil {
+		claim.ChannelID = channelLimit.ChannelID
+	}
+	if multiplier > 1 {
+		claim.Multiplier = multiplier
+	}
+	if draw != nil {
+		draw.ClaimID = claim.ID
+		draw.CreatedAt = claim.CreatedAt
+		claim.Draw = draw
+	}
+	if terms != nil {
+		claim.TermsVersion = terms.Version
+		claim.TermsAcceptedAt = &claim.CreatedAt
+		claim.TermsIP = req.ClientIP
+	}
+
+	// 7a. On-chain claims carry their payout job; flagged payouts are held
+	// for review and go out once approved or when the hold runs out
+	held := assessment != nil && assessment.Action == FraudHold
+	var job *model.PayoutJob
+	switch {
+	case creditMode:
+	case held:
+		claim.Status = "held"
+		job = s.payoutQueue.NewHeldJob(claim.ID, s.fraud.HoldUntil())
+	default:
+		job = s.payoutQueue.NewJob(claim.ID)
 	}
 
-	// 9. Execute transfer (async in production)
-	// Convert claimAmount to big.Int (assuming 6 decimals for USDC)
-	amountBigInt := floatToBigInt(claimAmount, 6)
-	txHash, err := s.walletSvc.TransferToken(ctx, wallet, rp.TokenAddress, wallet.Address, amountBigInt)
+	// 8. Atomic update red pocket (prevents overselling), recording the claim
+	// and its payout job in the same transaction
+	updated, err := s.rpRepo.ClaimWithPayout(ctx, claim, job, channelLimit)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return claimFailure(ctx, ErrInsufficientFunds), nil
+	}
+	if errors.Is(err, repository.ErrChannelLimitReached) {
+		return claimFailure(ctx, ErrChannelLimitReached), nil
+	}
+	if errors.Is(err, repository.ErrShareTaken) {
+		return claimFailure(ctx, ErrClaimLockFailed), nil
+	}
 	if err != nil {
-		s.claimRepo.UpdateStatus(ctx, claim.ID, "failed", "")
-		return &ClaimResponse{Success: false, Error: "transfer failed"}, nil
+		// A one-time link used by a concurrent claim trips uq_claims_link
+		if claim.LinkID != "" {
+			if used, _ := s.claimRepo.LinkUsed(ctx, claim.LinkID); used {
+				return claimFailure(ctx, ErrClaimLinkUsed), nil
+			}
+		}
+		return nil, fmt.Errorf("failed to create claim: %w", err)
+	}
+	if assessment != nil {
+		s.fraud.Record(ctx, assessment, claim.ID)
+	}
+	if snapshot != nil {
+		if err := s.audienceSvc.RecordClaim(ctx, snapshot.ID, audienceAddr, rp.ID, claim.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to record audience claim", "claim_id", claim.ID, "error", err)
+		}
+	}
+	if s.hunts != nil {
+		if err := s.hunts.Advance(ctx, claim); err != nil {
+			slog.ErrorContext(ctx, "failed to record treasure hunt progress", "claim_id", claim.ID, "error", err)
+		}
+	}
+	if s.teams != nil {
+		if err := s.teams.Attribute(ctx, rp, claim); err != nil {
+			slog.ErrorContext(ctx, "failed to attribute claim to team", "claim_id", claim.ID, "error", err)
+		}
+	}
+	if s.rules != nil {
+		if err := s.rules.OnClaim(ctx, rp); err != nil {
+			slog.ErrorContext(ctx, "failed to run campaign rules", "claim_id", claim.ID, "error", err)
+		}
+	}
+	s.events.PublishClaim(ctx, updated, claim)
+	if updated.Status != rp.Status {
+		s.events.PublishStatus(ctx, updated)
+	}
+
+	// 9a. Credit-mode campaigns settle to the ledger; users withdraw later
+	if creditMode {
+		if err := s.ledgerSvc.Credit(ctx, claim, rp); err != nil {
+			slog.ErrorContext(ctx, "failed to credit claim", "claim_id", claim.ID, "error", err)
+			if err := transitionClaim(ctx, s.claimRepo, claim.ID, model.ClaimFailed, "", "credit failed: "+err.Error()); err != nil {
+				slog.ErrorContext(ctx, "failed to transition claim", "claim_id", claim.ID, "error", err)
+			}
+			return claimFailure(ctx, ErrTransferFailed), nil
+		}
+		if err := transitionClaim(ctx, s.claimRepo, claim.ID, model.ClaimSubmitted, "", "credited to ledger"); err != nil {
+			slog.ErrorContext(ctx, "failed to transition claim", "claim_id", claim.ID, "error", err)
+		}
+		return &ClaimResponse{
+			Success:       true,
+			ClaimID:       claim.ID,
+			ClaimedAmount: claimAmount,
+			Donated:       claim.Donation,
+			Fee:           claim.Fee,
+			Multiplier:    claim.Multiplier,
+			WalletAddress: wallet.Address,
+			Status:        "credited",
+		}, nil
 	}
 
-	// 10. Update claim status
-	s.claimRepo.UpdateStatus(ctx, claim.ID, "success", txHash)
+	// 9b. Held payouts wait for review
+	if held {
+		return &ClaimResponse{
+			Success:       true,
+			ClaimID:       claim.ID,
+			ClaimedAmount: claimAmount,
+			Donated:       claim.Donation,
+			Fee:           claim.Fee,
+			Multiplier:    claim.Multiplier,
+			WalletAddress: wallet.Address,
+			Status:        "held",
+		}, nil
+	}
+
+	// 9c. The payout workers take it from here; clients poll GET /claim/:id
+	s.payoutQueue.Wake()
 
 	return &ClaimResponse{
 		Success:       true,
+		ClaimID:       claim.ID,
 		ClaimedAmount: claimAmount,
+		Donated:       claim.Donation,
+		Fee:           claim.Fee,
+		Multiplier:    claim.Multiplier,
 		WalletAddress: wallet.Address,
-		TxHash:        txHash,
+		Status:        "pending",
 	}, nil
 }
 
-func (s *RedPocketService) calculateClaimAmount(rp *model.RedPocket) float64 {
-	if !rp.IsLuckyDraw {
-		// Equal distribution
-		return rp.Amount / float64(rp.TotalCount)
+// baseClaimUnits is a claim's unweighted share in the token's minor units,
+// drawn for lucky draws. The last claim takes whatever is left, so a pocket
+// pays out exactly its amount. Weighted claims take a multiple of it and may
+// leave the pocket depleted before all its shares are claimed.
+func (s *RedPocketService) baseClaimUnits(ctx context.Context, rp *model.RedPocket, draw *model.LuckyDraw, random []byte) (*big.Int, error) {
+	if rp.IsLuckyDraw {
+		return s.luckyDrawShare(ctx, rp, draw, random)
+	}
+	remaining := rp.RemainingUnits.Int()
+	if rp.TotalCount-rp.ClaimedCount <= 1 {
+		return remaining, nil
 	}
 
-	// Lucky draw - random amount
-	remaining := rp.RemainingAmount
-	remainingCount := rp.TotalCount - rp.ClaimedCount
-
-	if remainingCount <= 1 {
-		return remaining
+	// Equal distribution
+	share := new(big.Int).Quo(rp.AmountUnits.Int(), big.NewInt(int64(rp.TotalCount)))
+	if share.Cmp(remaining) > 0 {
+		return remaining, nil
 	}
+	return share, nil
+}
 
-	// Use "二倍均值法" algorithm for fair random distribution
-	avgRemaining := remaining / float64(remainingCount)
-	maxAmount := avgRemaining * 2
+// checkProtection enforces the red pocket's claim password and CAPTCHA.
+// Password attempts are limited per claimer to stop brute forcing.
+func (s *RedPocketService) checkProtection(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) error {
+	if rp.PasswordProtected() {
+		if req.Password == "" {
+			return ErrClaimPasswordRequired
+		}
+		key := fmt.Sprintf("claimpw:%s:%s:%s", rp.ID, req.Platform, req.PlatformID)
+		attempts, err := s.redis.IncrementRateLimit(ctx, key, 10*time.Minute)
+		if err != nil {
+			// Without a count guesses are not limited, so password
+			// protected pockets wait for Redis
+			slog.WarnContext(ctx, "failed to count password attempts", "error", err)
+			return ErrServiceDegraded
+		}
+		if attempts > int64(s.cfg.ClaimPasswordTries) {
+			return ErrTooManyPasswordAttempts
+		}
+		if bcrypt.CompareHashAndPassword([]byte(rp.ClaimPasswordHash), []byte(req.Password)) != nil {
+			return ErrInvalidClaimPassword
+		}
+	}
 
-	if rp.MaxAmount > 0 && maxAmount > rp.MaxAmount {
-		maxAmount = rp.MaxAmount
+	if rp.CaptchaMode != "" {
+		return s.captcha.Verify(ctx, rp.CaptchaMode, req.CaptchaToken, req.ClientIP)
 	}
+	return nil
+}
 
-	minAmount := rp.MinAmount
-	if minAmount <= 0 {
-		minAmount = 0.01
+// ClaimProtection tells the claim page which challenges to show
+type ClaimProtection struct {
+	SignedLink     bool   `json:"signedLink"` // only claim links carrying a token work
+	Password       bool   `json:"password"`
+	Captcha        string `json:"captcha,omitempty"` // hcaptcha, turnstile
+	CaptchaSiteKey string `json:"captchaSiteKey,omitempty"`
+}
+
+func (s *RedPocketService) ClaimProtection(rp *model.RedPocket) *ClaimProtection {
+	return &ClaimProtection{
+		SignedLink:     rp.SignedLinks,
+		Password:       rp.PasswordProtected(),
+		Captcha:        rp.CaptchaMode,
+		CaptchaSiteKey: s.captcha.SiteKey(rp.CaptchaMode),
 	}
+}
 
-	// Random between min and max
-	amount := minAmount + rand.Float64()*(maxAmount-minAmount)
+// ClaimLink is the public claim page of a red pocket, signed when the
+// pocket needs signed links
+func (s *RedPocketService) ClaimLink(rp *model.RedPocket) string {
+	return s.links.URL(rp)
+}
 
-	// Ensure we don't exceed remaining
-	if amount > remaining {
-		amount = remaining
+func claimLink(cfg *config.Config, id string) string {
+	return strings.TrimRight(cfg.ClaimBaseURL, "/") + "/claim/" + id
+}
+
+// ClaimForChat claims a red pocket for a chat user who tapped its claim
+// button. Such claims carry no claim link, password, CAPTCHA, terms
+// acceptance or wallet proof, so pockets needing one are refused with the reason and the
+// user is left with the claim link. What the bot knows of the user's place
+// in the chat counts toward claim weights.
+func (s *RedPocketService) ClaimForChat(ctx context.Context, platform, platformUserID, channelID, redPocketID string, member bot.ChatMember) (*bot.ChatClaim, error) {
+	resp, err := s.Claim(ctx, &ClaimRequest{
+		RedPocketID: redPocketID,
+		PlatformID:  platformUserID,
+		Platform:    platform,
+		ChannelID:   channelID,
+		ChatAdmin:   member.Admin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rp, err := s.GetLatest(ctx, redPocketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load red pocket: %w", err)
 	}
 
-	return float64(int(amount*100)) / 100 // Round to 2 decimals
+	result := &bot.ChatClaim{RedPocket: rp, ClaimLink: s.ClaimLink(rp)}
+	if resp.Success {
+		result.Amount = resp.ClaimedAmount
+	} else {
+		result.Error = resp.Error + "\n" + i18n.Tc(ctx, "error.support_reference", resp.Reference)
+	}
+	return result, nil
 }
 
 func (s *RedPocketService) Get(ctx context.Context, id string) (*model.RedPocket, error) {
+	return s.cache.Get(ctx, id)
+}
+
+// GetLatest is Get bypassing the pocket cache
+func (s *RedPocketService) GetLatest(ctx context.Context, id string) (*model.RedPocket, error) {
 	return s.rpRepo.GetByID(ctx, id)
 }
 
+// History returns a red pocket's status changes, oldest first
+func (s *RedPocketService) History(ctx context.Context, id string) ([]*model.PocketTransition, error) {
+	return s.rpRepo.History(ctx, id)
+}
+
+// Eligibility returns the rules a claim of rp must pass
+func (s *RedPocketService) Eligibility(ctx context.Context, rp *model.RedPocket) []*model.EligibilityRule {
+	return s.eligibility.Requirements(ctx, rp)
+}
+
+// Terms returns the current terms of the pocket's campaign, or nil if it has none
+func (s *RedPocketService) Terms(ctx context.Context, rp *model.RedPocket) *model.CampaignTerms {
+	terms, err := s.campaignRepo.GetTerms(ctx, rp.CampaignID)
+	if err != nil || terms.Version == "" {
+		return nil
+	}
+	return terms
+}
+
+// Charity returns the charity round-up of the pocket's campaign, or nil if
+// its claims donate nothing
+func (s *RedPocketService) Charity(ctx context.Context, rp *model.RedPocket) *model.CampaignCharity {
+	charity, err := s.campaignRepo.GetCharity(ctx, rp.CampaignID)
+	if err != nil || !charity.Active() {
+		return nil
+	}
+	return charity
+}
+
+// ClaimPage returns the claim page branding of the pocket's campaign, or nil
+// to use the default page
+func (s *RedPocketService) ClaimPage(ctx context.Context, rp *model.RedPocket) *model.ClaimPageConfig {
+	page, err := s.campaignRepo.GetClaimPage(ctx, rp.CampaignID)
+	if err != nil {
+		return nil
+	}
+	return page
+}
+
+// PocketStatusRequest optionally says why a red pocket is paused, resumed or
+// cancelled
+type PocketStatusRequest struct {
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+// Pause blocks claims on one of the enterprise's open red pockets until it is
+// resumed, without cancelling it, e.g. to look into suspected farming.
+// reason is recorded in the pocket's status history.
+func (s *RedPocketService) Pause(ctx context.Context, id, enterpriseID, reason string) (*model.RedPocket, error) {
+	rp, err := s.enterprisePocket(ctx, id, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	if rp.Status == model.PocketPaused {
+		return rp, nil
+	}
+	if rp.Status == model.PocketActive && !time.Now().Before(rp.ExpiresAt) {
+		return nil, ErrRedPocketExpired
+	}
+
+	paused, err := s.rpRepo.Transition(ctx, rp.ID, model.PocketPaused, statusReason("paused by enterprise", reason))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pause red pocket: %w", err)
+	}
+	if !paused {
+		return nil, ErrPocketNotPausable
+	}
+	return s.publishStatus(ctx, rp.ID)
+}
+
+// Resume reopens a paused red pocket of the enterprise for claims. A pocket
+// that expired while paused stays closed.
+func (s *RedPocketService) Resume(ctx context.Context, id, enterpriseID, reason string) (*model.RedPocket, error) {
+	rp, err := s.enterprisePocket(ctx, id, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	if rp.Status == model.PocketActive {
+		return rp, nil
+	}
+	if rp.Status != model.PocketPaused {
+		return nil, ErrPocketNotPaused
+	}
+	if !time.Now().Before(rp.ExpiresAt) {
+		return nil, ErrRedPocketExpired
+	}
+
+	// Checked paused above; a pocket can only leave paused by expiring,
+	// which cannot move back to active
+	resumed, err := s.rpRepo.Transition(ctx, rp.ID, model.PocketActive, statusReason("resumed by enterprise", reason))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume red pocket: %w", err)
+	}
+	if !resumed {
+		return nil, ErrPocketNotPaused
+	}
+	return s.publishStatus(ctx, rp.ID)
+}
+
+// ExtendPocketRequest moves a red pocket's expiry later, to ExpiresAt or by
+// ExtendBy seconds
+type ExtendPocketRequest struct {
+	ExpiresAt *time.Time `json:"expiresAt"`
+	ExtendBy  int64      `json:"extendBy" binding:"omitempty,gt=0"`
+}
+
+// Extend gives one of the enterprise's open or paused red pockets longer
+// before it expires. Claim links already issued keep their own expiry.
+func (s *RedPocketService) Extend(ctx context.Context, id, enterpriseID string, req *ExtendPocketRequest) (*model.RedPocket, error) {
+	if (req.ExpiresAt == nil) == (req.ExtendBy == 0) {
+		return nil, ErrInvalidExpiry
+	}
+	rp, err := s.enterprisePocket(ctx, id, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case rp.Status == model.PocketExpired:
+		return nil, ErrRedPocketExpired
+	case rp.Status != model.PocketActive && rp.Status != model.PocketPaused:
+		return nil, ErrPocketNotExtendable
+	case !time.Now().Before(rp.ExpiresAt):
+		// Expiry reached, and the sweep has yet to close it
+		return nil, ErrRedPocketExpired
+	}
+	expiresAt := rp.ExpiresAt.Add(time.Duration(req.ExtendBy) * time.Second)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(rp.ExpiresAt) {
+		return nil, ErrInvalidExpiry
+	}
+
+	extended, err := s.rpRepo.Extend(ctx, rp.ID, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extend red pocket: %w", err)
+	}
+	if !extended {
+		return nil, ErrPocketNotExtendable
+	}
+	return s.publishStatus(ctx, rp.ID)
+}
+
+// enterprisePocket loads a red pocket of one of the enterprise's campaigns
+func (s *RedPocketService) enterprisePocket(ctx context.Context, id, enterpriseID string) (*model.RedPocket, error) {
+	rp, err := s.rpRepo.GetByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRedPocketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := s.campaignRepo.GetByID(ctx, rp.CampaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return nil, ErrRedPocketNotFound
+	}
+	return rp, nil
+}
+
+// publishStatus announces a status change made outside a claim and returns
+// the pocket as it is now
+func (s *RedPocketService) publishStatus(ctx context.Context, id string) (*model.RedPocket, error) {
+	rp, err := s.rpRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load red pocket: %w", err)
+	}
+	s.events.PublishStatus(ctx, rp)
+	return rp, nil
+}
+
+// statusReason is what a status change is recorded with: what happened, and
+// why if the caller said
+func statusReason(what, why string) string {
+	if why = strings.TrimSpace(why); why != "" {
+		return what + ": " + why
+	}
+	return what
+}
+
 // floatToBigInt converts a float amount to big.Int with specified decimals
 func floatToBigInt(amount float64, decimals int) *big.Int {
 	// Multiply by 10^decimals
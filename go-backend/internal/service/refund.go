@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrPocketNotExpired     = newCodedError("pocket_not_expired")
+	ErrNothingToRefund      = newCodedError("nothing_to_refund")
+	ErrPocketNotCancellable = newCodedError("pocket_not_cancellable")
+)
+
+const (
+	refundSweepInterval = time.Minute
+	refundSweepBatch    = 50
+)
+
+// RefundService returns the unclaimed remainder of expired and cancelled red
+// pockets from the pocket's payout wallet to the wallet of the enterprise
+// that funded it
+type RefundService struct {
+	repo         *repository.RefundRepository
+	rpRepo       repository.RedPocketStore
+	campaignRepo repository.CampaignStore
+	walletSvc    *WalletService
+	events       *PocketEvents
+	cfg          *config.Config
+}
+
+func NewRefundService(
+	repo *repository.RefundRepository,
+	rpRepo repository.RedPocketStore,
+	campaignRepo repository.CampaignStore,
+	walletSvc *WalletService,
+	events *PocketEvents,
+	cfg *config.Config,
+) *RefundService {
+	return &RefundService{
+		repo:         repo,
+		rpRepo:       rpRepo,
+		campaignRepo: campaignRepo,
+		walletSvc:    walletSvc,
+		events:       events,
+		cfg:          cfg,
+	}
+}
+
+// Refund refunds a red pocket right away. A pocket past its expiry is
+// expired first; a failed refund is retried; an existing refund is returned.
+// Cancelled pockets are refunded like expired ones.
+func (s *RefundService) Refund(ctx context.Context, redPocketID string) (*model.Refund, error) {
+	rp, err := s.rpRepo.GetByID(ctx, redPocketID)
+	if err != nil {
+		return nil, ErrRedPocketNotFound
+	}
+	if rp.Status != model.PocketExpired && rp.Status.CanTransition(model.PocketExpired) && time.Now().After(rp.ExpiresAt) {
+		ok, err := s.rpRepo.Transition(ctx, rp.ID, model.PocketExpired, "expiry reached, refund requested")
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rp.Status = model.PocketExpired
+			s.events.PublishStatus(ctx, rp)
+		}
+	}
+	if rp.Status != model.PocketExpired && rp.Status != model.PocketCancelled && rp.Status != model.PocketRefunded {
+		return nil, ErrPocketNotExpired
+	}
+
+	refund, err := s.repo.GetByRedPocket(ctx, rp.ID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		refund, err = s.create(ctx, rp, "manual")
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case refund.Status == "failed":
+		if _, err := s.repo.Retry(ctx, refund.ID, "manual"); err != nil {
+			return nil, err
+		}
+		refund.Status = "pending"
+	default:
+		return refund, nil
+	}
+
+	if err := s.process(ctx, refund); err != nil {
+		slog.ErrorContext(ctx, "refund: failed", "red_pocket_id", rp.ID, "error", err)
+	}
+	return s.repo.GetByRedPocket(ctx, rp.ID)
+}
+
+// Cancel cancels one of the enterprise's open or paused red pockets, posted
+// to the wrong channel say, and queues the refund of what is left of it.
+// Claims still in flight fail once it is cancelled. The refund is nil when
+// nothing is left.
+func (s *RefundService) Cancel(ctx context.Context, id, enterpriseID, reason string) (*model.RedPocket, *model.Refund, error) {
+	rp, err := s.rpRepo.GetByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrRedPocketNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	campaign, err := s.campaignRepo.GetByID(ctx, rp.CampaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return nil, nil, ErrRedPocketNotFound
+	}
+	if rp.Status != model.PocketActive && rp.Status != model.PocketPaused {
+		return nil, nil, ErrPocketNotCancellable
+	}
+
+	refund, err := s.newRefund(ctx, rp, campaign, "cancel")
+	if err != nil {
+		return nil, nil, err
+	}
+	cancelled, refunded, err := s.repo.Cancel(ctx, refund, statusReason("cancelled by enterprise", reason))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to cancel red pocket: %w", err)
+	}
+	if !cancelled {
+		return nil, nil, ErrPocketNotCancellable
+	}
+	if !refunded {
+		refund = nil
+	}
+
+	rp, err = s.rpRepo.GetByID(ctx, rp.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.events.PublishStatus(ctx, rp)
+	return rp, refund, nil
+}
+
+// create takes the pocket's remainder and its failed claims into a new
+// pending refund
+func (s *RefundService) create(ctx context.Context, rp *model.RedPocket, trigger string) (*model.Refund, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, rp.CampaignID)
+	if err != nil {
+		return nil, fmt.Errorf("campaign %s not found: %w", rp.CampaignID, err)
+	}
+	refund, err := s.newRefund(ctx, rp, campaign, trigger)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.Create(ctx, refund)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+	if !ok {
+		return nil, ErrNothingToRefund
+	}
+	return refund, nil
+}
+
+// newRefund prepares a pending refund of a pocket to its campaign's
+// enterprise; the repository fills in the amount
+func (s *RefundService) newRefund(ctx context.Context, rp *model.RedPocket, campaign *model.Campaign, trigger string) (*model.Refund, error) {
+	from, err := s.walletSvc.GetOrCreate(ctx, PayoutWalletID(rp.ID), rp.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payout wallet: %w", err)
+	}
+	to, err := s.walletSvc.GetOrCreate(ctx, campaign.EnterpriseID, rp.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enterprise wallet: %w", err)
+	}
+
+	refund := &model.Refund{
+		ID:           "refund_" + uuid.New().String()[:8],
+		RedPocketID:  rp.ID,
+		RecipientID:  campaign.EnterpriseID,
+		ChainID:      rp.ChainID,
+		Token:        rp.Token,
+		TokenAddress: rp.TokenAddress,
+		FromAddress:  from.Address,
+		ToAddress:    to.Address,
+		Status:       "pending",
+		Trigger:      trigger,
+		CreatedAt:    time.Now(),
+	}
+	return refund, nil
+}
+
+// Start refunds expired pockets, and sends queued refunds of cancelled ones,
+// until ctx is cancelled
+func (s *RefundService) Start(ctx context.Context) {
+	ticker := time.NewTicker(refundSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RefundService) sweep(ctx context.Context) {
+	ids, err := s.repo.ListRefundable(ctx, refundSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "refund: failed to list expired red pockets", "error", err)
+		return
+	}
+	for _, id := range ids {
+		rp, err := s.rpRepo.GetByID(ctx, id)
+		if err != nil {
+			slog.ErrorContext(ctx, "refund: failed", "red_pocket_id", id, "error", err)
+			continue
+		}
+		if _, err := s.create(ctx, rp, "auto"); err != nil && !errors.Is(err, ErrNothingToRefund) {
+			slog.ErrorContext(ctx, "refund: failed", "red_pocket_id", id, "error", err)
+		}
+	}
+
+	refunds, err := s.repo.ListOpen(ctx, refundSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "refund: failed to list refunds", "error", err)
+		return
+	}
+	for _, refund := range refunds {
+		var err error
+		if refund.Status == "pending" {
+			err = s.process(ctx, refund)
+		} else {
+			err = s.checkPending(ctx, refund)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "refund: failed", "refund_id", refund.ID, "error", err)
+		}
+	}
+}
+
+func (s *RefundService) process(ctx context.Context, refund *model.Refund) error {
+	started, err := s.repo.Start(ctx, refund.ID)
+	if err != nil || !started {
+		return err
+	}
+
+	from, err := s.walletSvc.GetOrCreate(ctx, PayoutWalletID(refund.RedPocketID), refund.ChainID)
+	if err != nil {
+		return s.repo.Update(ctx, refund.ID, "failed", "", "", "payout wallet unavailable: "+err.Error())
+	}
+	rp, err := s.rpRepo.GetByID(ctx, refund.RedPocketID)
+	if err != nil {
+		return s.repo.Update(ctx, refund.ID, "failed", "", "", "red pocket unavailable: "+err.Error())
+	}
+	if rp.TestMode {
+		ctx = withSandbox(ctx)
+	}
+
+	txHash, err := s.walletSvc.TransferToken(ctx, from, refund.TokenAddress, refund.ToAddress, refund.AmountUnits.Int())
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		return s.repo.Update(ctx, refund.ID, "processing", "", pending.UserOpHash, "")
+	case err != nil:
+		slog.ErrorContext(ctx, "refund: failed, retry with POST /api/v1/redpocket/:id/refund", "refund_id", refund.ID,
+			"red_pocket_id", refund.RedPocketID, "error", err)
+		return s.repo.Update(ctx, refund.ID, "failed", "", "", err.Error())
+	}
+	slog.InfoContext(ctx, "refund: returned", "refund_id", refund.ID, "amount", refund.Amount, "token", refund.Token,
+		"red_pocket_id", refund.RedPocketID, "recipient_id", refund.RecipientID, "tx_hash", txHash)
+	return s.complete(ctx, refund, txHash)
+}
+
+// complete marks a refund sent and its pocket refunded
+func (s *RefundService) complete(ctx context.Context, refund *model.Refund, txHash string) error {
+	if err := s.repo.Update(ctx, refund.ID, "success", txHash, "", ""); err != nil {
+		return err
+	}
+	ok, err := s.rpRepo.Transition(ctx, refund.RedPocketID, model.PocketRefunded, "remainder returned with refund "+refund.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark red pocket %s refunded: %w", refund.RedPocketID, err)
+	}
+	if !ok {
+		return nil
+	}
+	if rp, err := s.rpRepo.GetByID(ctx, refund.RedPocketID); err == nil {
+		s.events.PublishStatus(ctx, rp)
+	}
+	return nil
+}
+
+// checkPending resolves a refund whose user operation was still in the
+// mempool; the UserOpMonitor bumps it meanwhile
+func (s *RefundService) checkPending(ctx context.Context, refund *model.Refund) error {
+	status, txHash, err := s.walletSvc.UserOpOutcome(ctx, refund.UserOpHash)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case "success":
+		return s.complete(ctx, refund, txHash)
+	case "failed":
+		return s.repo.Update(ctx, refund.ID, "failed", "", "", "refund cancelled on chain")
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// Bounds on a request nonce's length
+const (
+	minNonceLength = 16
+	maxNonceLength = 128
+)
+
+// maxIdempotencyKeyLength bounds an Idempotency-Key
+const maxIdempotencyKeyLength = 255
+
+var (
+	// ErrRequestNonceRequired is returned for a signed request to a withdraw,
+	// transfer or bridge endpoint without a timestamp and nonce
+	ErrRequestNonceRequired = newCodedError("request_nonce_required")
+	// ErrRequestExpired is returned when a signed request's timestamp is
+	// outside REQUEST_SIGNATURE_WINDOW
+	ErrRequestExpired = newCodedError("request_expired")
+	// ErrRequestReplayed is returned when a signed request reuses a nonce
+	ErrRequestReplayed = newCodedError("request_replayed")
+	// ErrIdempotencyKeyInvalid is returned for an Idempotency-Key longer
+	// than 255 characters
+	ErrIdempotencyKeyInvalid = newCodedError("idempotency_key_invalid")
+	// ErrIdempotencyKeyInProgress is returned for a retry that arrives
+	// while the first request with its Idempotency-Key is still running
+	ErrIdempotencyKeyInProgress = newCodedError("idempotency_key_in_progress")
+	// ErrIdempotencyKeyReused is returned when an Idempotency-Key is sent
+	// again with a different body
+	ErrIdempotencyKeyReused = newCodedError("idempotency_key_reused")
+)
+
+// ReplayGuard refuses signed requests that were captured and sent again. A
+// request is accepted once: its timestamp must be within the window of the
+// server clock and its nonce unused by the same API key. Nonces are kept
+// for twice the window, after which the timestamp alone rejects the request.
+//
+// Retries are made safe by an Idempotency-Key instead: the first request
+// with a key runs, and its response is replayed to every later request
+// with the same key and body until IDEMPOTENCY_KEY_TTL passes.
+type ReplayGuard struct {
+	redis          *repository.RedisClient
+	window         time.Duration
+	idempotencyTTL time.Duration
+}
+
+func NewReplayGuard(redis *repository.RedisClient, cfg *config.Config) *ReplayGuard {
+	return &ReplayGuard{
+		redis:          redis,
+		window:         time.Duration(cfg.RequestSignatureWindow) * time.Second,
+		idempotencyTTL: time.Duration(cfg.IdempotencyKeyTTL) * time.Second,
+	}
+}
+
+// IdempotentResponse is the response to the first request with an
+// Idempotency-Key. Status is 0 while that request runs.
+type IdempotentResponse struct {
+	RequestHash string `json:"requestHash"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Check accepts a request from apiKeyID carrying timestamp, in Unix seconds,
+// and nonce, and consumes the nonce
+func (g *ReplayGuard) Check(ctx context.Context, apiKeyID, timestamp, nonce string) error {
+	if timestamp == "" || len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+		return ErrRequestNonceRequired
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrRequestNonceRequired
+	}
+	skew := time.Since(time.Unix(sec, 0))
+	if skew > g.window || skew < -g.window {
+		return ErrRequestExpired
+	}
+
+	fresh, err := g.redis.ClaimNonce(ctx, apiKeyID+":"+nonce, 2*g.window)
+	if err != nil {
+		return fmt.Errorf("failed to record nonce: %w", err)
+	}
+	if !fresh {
+		return ErrRequestReplayed
+	}
+	return nil
+}
+
+// BeginIdempotent reserves an Idempotency-Key within scope, the caller and
+// route, for a request with body. It returns nil when the request should
+// run, or the stored response of the first request with the key.
+func (g *ReplayGuard) BeginIdempotent(ctx context.Context, scope, key string, body []byte) (*IdempotentResponse, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, ErrIdempotencyKeyInvalid
+	}
+	hash := requestHash(body)
+	pending, err := json.Marshal(&IdempotentResponse{RequestHash: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	// A key that expires between the two calls is reserved on the next try
+	for attempt := 0; attempt < 2; attempt++ {
+		reserved, err := g.redis.ReserveIdempotencyKey(ctx, scope+":"+key, string(pending), g.idempotencyTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+		if reserved {
+			return nil, nil
+		}
+		stored, err := g.redis.GetIdempotencyKey(ctx, scope+":"+key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+		}
+		if stored == "" {
+			continue
+		}
+		var resp IdempotentResponse
+		if err := json.Unmarshal([]byte(stored), &resp); err != nil {
+			return nil, fmt.Errorf("invalid idempotent response: %w", err)
+		}
+		switch {
+		case resp.RequestHash != hash:
+			return nil, ErrIdempotencyKeyReused
+		case resp.Status == 0:
+			return nil, ErrIdempotencyKeyInProgress
+		}
+		return &resp, nil
+	}
+	return nil, ErrIdempotencyKeyInProgress
+}
+
+// FinishIdempotent stores the response to a request BeginIdempotent let
+// run. A server error releases the key instead, so the request can be
+// retried with it.
+func (g *ReplayGuard) FinishIdempotent(ctx context.Context, scope, key string, body []byte, resp *IdempotentResponse) error {
+	if resp.Status >= 500 {
+		return g.redis.DeleteIdempotencyKey(ctx, scope+":"+key)
+	}
+	resp.RequestHash = requestHash(body)
+	stored, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return g.redis.SetIdempotencyKey(ctx, scope+":"+key, string(stored), g.idempotencyTTL)
+}
+
+func requestHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
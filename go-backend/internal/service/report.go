@@ -0,0 +1,337 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrInvalidReportFormat  = newCodedError("invalid_report_format")
+	ErrInvalidReportRange   = newCodedError("invalid_report_range")
+	ErrReportExportNotFound = newCodedError("report_export_not_found")
+	ErrInvalidDownloadLink  = newCodedError("invalid_download_link")
+)
+
+// Report kinds and formats
+const (
+	ReportClaims    = "claims"
+	ReportAnalytics = "analytics"
+
+	ReportCSV  = "csv"
+	ReportXLSX = "xlsx"
+)
+
+const (
+	reportPollInterval = 5 * time.Second
+	reportStuckAfter   = 10 * time.Minute
+	// Rows between flushes of a streamed report to the client
+	reportFlushRows = 1000
+)
+
+// ReportService exports an enterprise's claims and daily analytics as CSV
+// or XLSX. Reports up to REPORT_ASYNC_ROWS rows are streamed straight from
+// the database to the client; larger ones are queued, generated in the
+// background and downloaded through a signed URL.
+type ReportService struct {
+	repo         *repository.ReportRepository
+	campaignRepo repository.CampaignStore
+	secret       []byte
+	cfg          *config.Config
+}
+
+func NewReportService(repo *repository.ReportRepository, campaignRepo repository.CampaignStore, cfg *config.Config) *ReportService {
+	secret := cfg.ReportURLSecret
+	if secret == "" {
+		secret = cfg.JWTSecret
+	}
+	return &ReportService{
+		repo:         repo,
+		campaignRepo: campaignRepo,
+		secret:       []byte(secret),
+		cfg:          cfg,
+	}
+}
+
+// Filter builds the filter of an enterprise report. from and to are dates
+// (YYYY-MM-DD, UTC, to inclusive) or RFC 3339 times (to exclusive); either
+// may be empty. A campaign must belong to the enterprise.
+func (s *ReportService) Filter(ctx context.Context, enterpriseID, campaignID, from, to string) (*model.ReportFilter, error) {
+	f := &model.ReportFilter{EnterpriseID: enterpriseID, CampaignID: campaignID}
+	var err error
+	if f.From, err = parseReportTime(from, false); err != nil {
+		return nil, err
+	}
+	if f.To, err = parseReportTime(to, true); err != nil {
+		return nil, err
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return nil, ErrInvalidReportRange
+	}
+	if campaignID != "" {
+		campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+		if err != nil || campaign.EnterpriseID != enterpriseID {
+			return nil, ErrCampaignNotFound
+		}
+	}
+	return f, nil
+}
+
+func parseReportTime(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, ErrInvalidReportRange
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func validReport(kind, format string) error {
+	if kind != ReportClaims && kind != ReportAnalytics {
+		return fmt.Errorf("unknown report kind %q", kind)
+	}
+	if format != ReportCSV && format != ReportXLSX {
+		return ErrInvalidReportFormat
+	}
+	return nil
+}
+
+// Queue queues the report in the background when it has more rows than can
+// be streamed, and returns the queued export. It returns nil when the report
+// is small enough for Stream.
+func (s *ReportService) Queue(ctx context.Context, kind, format string, f *model.ReportFilter) (*model.ReportExport, error) {
+	if err := validReport(kind, format); err != nil {
+		return nil, err
+	}
+	count := s.repo.CountClaims
+	if kind == ReportAnalytics {
+		count = s.repo.CountAnalytics
+	}
+	rows, err := count(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count report rows: %w", err)
+	}
+	if rows <= int64(s.cfg.ReportAsyncRows) {
+		return nil, nil
+	}
+
+	now := time.Now()
+	e := &model.ReportExport{
+		ID:        "export_" + uuid.New().String()[:8],
+		Kind:      kind,
+		Format:    format,
+		Filter:    *f,
+		Status:    "pending",
+		Rows:      rows,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Duration(s.cfg.ReportRetentionHours) * time.Hour),
+	}
+	if err := s.repo.CreateExport(ctx, e); err != nil {
+		return nil, fmt.Errorf("failed to queue export: %w", err)
+	}
+	return e, nil
+}
+
+// Stream writes a report to w row by row, flushing it to the client as it
+// goes when w is an http.Flusher, and returns the number of rows written
+func (s *ReportService) Stream(ctx context.Context, w io.Writer, kind, format string, f *model.ReportFilter) (int64, error) {
+	if err := validReport(kind, format); err != nil {
+		return 0, err
+	}
+	rw, err := newReportWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	wrote := func() error {
+		rows++
+		if rows%reportFlushRows != 0 {
+			return nil
+		}
+		if err := rw.Flush(); err != nil {
+			return err
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	switch kind {
+	case ReportClaims:
+		err = rw.WriteRow("claim_id", "created_at", "completed_at", "campaign_id", "campaign_name", "red_pocket_id",
+			"platform", "platform_id", "wallet_address", "chain_id", "token", "amount", "fee", "donation", "status", "tx_hash")
+		if err == nil {
+			err = s.repo.EachClaim(ctx, f, func(r *model.ClaimReportRow) error {
+				err := rw.WriteRow(r.ID, r.CreatedAt, r.CompletedAt, r.CampaignID, r.CampaignName, r.RedPocketID,
+					r.Platform, r.PlatformID, r.WalletAddress, r.ChainID, r.Token, r.Amount, r.Fee, r.Donation, string(r.Status), r.TxHash)
+				if err != nil {
+					return err
+				}
+				return wrote()
+			})
+		}
+	case ReportAnalytics:
+		err = rw.WriteRow("date", "campaign_id", "campaign_name", "chain_id", "token",
+			"pockets", "claims", "claimers", "amount", "fees", "donations")
+		if err == nil {
+			err = s.repo.EachAnalyticsDay(ctx, f, func(r *model.AnalyticsReportRow) error {
+				err := rw.WriteRow(r.Day.Format("2006-01-02"), r.CampaignID, r.CampaignName, r.ChainID, r.Token,
+					r.Pockets, r.Claims, r.Claimers, r.Amount, r.Fees, r.Donations)
+				if err != nil {
+					return err
+				}
+				return wrote()
+			})
+		}
+	}
+	if err != nil {
+		return rows, err
+	}
+	return rows, rw.Close()
+}
+
+// ReportFileName is what a downloaded report is saved as
+func ReportFileName(kind, format string, at time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", kind, at.UTC().Format("20060102-150405"), format)
+}
+
+// ReportContentType is the media type of a report format
+func ReportContentType(format string) string {
+	if format == ReportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// GetExport returns one of an enterprise's queued exports, with a freshly
+// signed download URL once it is ready
+func (s *ReportService) GetExport(ctx context.Context, id, enterpriseID string) (*model.ReportExport, error) {
+	e, err := s.repo.GetExport(ctx, id, enterpriseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrReportExportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.Status == "ready" {
+		e.DownloadURL = s.downloadURL(e)
+	}
+	return e, nil
+}
+
+func (s *ReportService) downloadURL(e *model.ReportExport) string {
+	expires := time.Now().Add(time.Duration(s.cfg.ReportURLTTL) * time.Second)
+	if e.ExpiresAt.Before(expires) {
+		expires = e.ExpiresAt
+	}
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{"expires": {exp}, "sig": {s.sign(e.ID, exp)}}
+	return strings.TrimRight(s.cfg.APIBaseURL, "/") + "/api/v1/exports/" + e.ID + "/download?" + q.Encode()
+}
+
+func (s *ReportService) sign(id, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id + "." + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Download checks a signed download URL and returns the export and its file
+func (s *ReportService) Download(ctx context.Context, id, expires, sig string) (*model.ReportExport, []byte, error) {
+	given, err := hex.DecodeString(sig)
+	if err != nil {
+		return nil, nil, ErrInvalidDownloadLink
+	}
+	want, _ := hex.DecodeString(s.sign(id, expires))
+	if !hmac.Equal(given, want) {
+		return nil, nil, ErrInvalidDownloadLink
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return nil, nil, ErrInvalidDownloadLink
+	}
+
+	e, content, err := s.repo.ExportContent(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrReportExportNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, content, nil
+}
+
+// Start generates queued exports one at a time and deletes expired ones.
+// Blocks until ctx is cancelled.
+func (s *ReportService) Start(ctx context.Context) {
+	ticker := time.NewTicker(reportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.repo.DeleteExpiredExports(ctx); err != nil {
+			slog.ErrorContext(ctx, "reports: failed to delete expired exports", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "reports: deleted expired exports", "exports", n)
+		}
+		for s.generateNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// generateNext generates the next queued export and reports whether there
+// was one
+func (s *ReportService) generateNext(ctx context.Context) bool {
+	e, err := s.repo.DequeueExport(ctx, reportStuckAfter)
+	if err != nil {
+		slog.ErrorContext(ctx, "reports: failed to dequeue export", "error", err)
+		return false
+	}
+	if e == nil {
+		return false
+	}
+
+	var buf bytes.Buffer
+	rows, err := s.Stream(ctx, &buf, e.Kind, e.Format, &e.Filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "reports: export failed", "export_id", e.ID, "error", err)
+		if err := s.repo.FailExport(ctx, e.ID, err.Error()); err != nil {
+			slog.ErrorContext(ctx, "reports: failed to record failure of export", "export_id", e.ID, "error", err)
+		}
+		return true
+	}
+	if err := s.repo.CompleteExport(ctx, e.ID, rows, buf.Bytes()); err != nil {
+		slog.ErrorContext(ctx, "reports: failed to store export", "export_id", e.ID, "error", err)
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// reportWriter writes a report one row at a time so large reports are never
+// held in memory. Cells are strings, int64, float64, time.Time or
+// *time.Time; a nil time is an empty cell.
+type reportWriter interface {
+	WriteRow(cells ...any) error
+	// Flush hands what has been written so far to the underlying writer
+	Flush() error
+	// Close finishes the file; the underlying writer is left open
+	Close() error
+}
+
+func newReportWriter(format string, w io.Writer) (reportWriter, error) {
+	switch format {
+	case ReportCSV:
+		return &csvReportWriter{w: csv.NewWriter(w)}, nil
+	case ReportXLSX:
+		return newXLSXReportWriter(w)
+	}
+	return nil, ErrInvalidReportFormat
+}
+
+func formatReportCell(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+type csvReportWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (r *csvReportWriter) WriteRow(cells ...any) error {
+	r.record = r.record[:0]
+	for _, cell := range cells {
+		s := formatReportCell(cell)
+		if text, ok := cell.(string); ok && text != "" {
+			// Spreadsheets run cells starting with these as formulas
+			switch text[0] {
+			case '=', '+', '-', '@', '\t', '\r':
+				s = "'" + text
+			}
+		}
+		r.record = append(r.record, s)
+	}
+	return r.w.Write(r.record)
+}
+
+func (r *csvReportWriter) Flush() error {
+	r.w.Flush()
+	return r.w.Error()
+}
+
+func (r *csvReportWriter) Close() error {
+	return r.Flush()
+}
+
+// xlsxReportWriter writes a single-sheet workbook, streaming the sheet into
+// the zip archive as rows arrive. Strings are stored inline rather than in
+// a shared string table so nothing has to be kept until the end.
+type xlsxReportWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Report" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs>` +
+		`</styleSheet>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+func newXLSXReportWriter(w io.Writer) (*xlsxReportWriter, error) {
+	z := zip.NewWriter(w)
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet goes last so it can be streamed
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	if _, err := sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	return &xlsxReportWriter{zip: z, sheet: sheet}, nil
+}
+
+func (r *xlsxReportWriter) WriteRow(cells ...any) error {
+	r.sheet.WriteString("<row>")
+	for _, cell := range cells {
+		switch cell.(type) {
+		case int64, float64:
+			r.sheet.WriteString(`<c><v>`)
+			r.sheet.WriteString(formatReportCell(cell))
+			r.sheet.WriteString(`</v></c>`)
+		default:
+			r.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(r.sheet, []byte(formatReportCell(cell))); err != nil {
+				return err
+			}
+			r.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := r.sheet.WriteString("</row>")
+	return err
+}
+
+func (r *xlsxReportWriter) Flush() error {
+	if err := r.sheet.Flush(); err != nil {
+		return err
+	}
+	return r.zip.Flush()
+}
+
+func (r *xlsxReportWriter) Close() error {
+	if _, err := r.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := r.sheet.Flush(); err != nil {
+		return err
+	}
+	return r.zip.Close()
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var ErrAddressSanctioned = newCodedError("address_sanctioned")
+
+// Sanctions screening providers
+const (
+	SanctionsProviderChainalysis = "chainalysis"
+	SanctionsProviderTRM         = "trm"
+	SanctionsProviderOFAC        = "ofac" // open list synced into sanctioned_addresses
+)
+
+// Screening results
+const (
+	ScreeningClear = "clear"
+	ScreeningHit   = "hit"
+)
+
+const (
+	chainalysisSanctionsURL = "https://public.chainalysis.com/api/v1/address/"
+	trmSanctionsURL         = "https://api.trmlabs.com/public/v1/sanctions/screening"
+)
+
+// SanctionsScreener checks payout and withdrawal destinations against
+// sanctions lists. Every screening is recorded for audit and hits are raised
+// to ops. Provider errors are returned rather than treated as clear, so
+// callers hold the payout until screening works again.
+type SanctionsScreener struct {
+	repo       *repository.SanctionsRepository
+	notifier   Notifier
+	httpClient *http.Client
+	cfg        *config.Config
+}
+
+func NewSanctionsScreener(repo *repository.SanctionsRepository, notifier Notifier, cfg *config.Config) *SanctionsScreener {
+	return &SanctionsScreener{
+		repo:     repo,
+		notifier: notifier,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		cfg: cfg,
+	}
+}
+
+// Enabled reports whether a screening provider is configured
+func (s *SanctionsScreener) Enabled() bool {
+	return s.cfg.SanctionsProvider != ""
+}
+
+// Screen checks address before paying subjectType (claim or withdrawal)
+// subjectID and records the result. Returns nil when screening is disabled.
+func (s *SanctionsScreener) Screen(ctx context.Context, address, subjectType, subjectID, userID string) (*model.AddressScreening, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+
+	var hit bool
+	var reference string
+	var err error
+	switch s.cfg.SanctionsProvider {
+	case SanctionsProviderChainalysis:
+		hit, reference, err = s.checkChainalysis(ctx, address)
+	case SanctionsProviderTRM:
+		hit, reference, err = s.checkTRM(ctx, address)
+	case SanctionsProviderOFAC:
+		hit, reference, err = s.repo.IsListed(ctx, address)
+	default:
+		err = fmt.Errorf("unknown sanctions provider: %s", s.cfg.SanctionsProvider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sanctions screening failed: %w", err)
+	}
+
+	screening := &model.AddressScreening{
+		ID:          "screen_" + uuid.New().String()[:8],
+		Address:     address,
+		Provider:    s.cfg.SanctionsProvider,
+		Result:      ScreeningClear,
+		Reference:   reference,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		UserID:      userID,
+		CreatedAt:   time.Now(),
+	}
+	if hit {
+		screening.Result = ScreeningHit
+	}
+	if err := s.repo.CreateScreening(ctx, screening); err != nil {
+		return nil, fmt.Errorf("failed to record screening: %w", err)
+	}
+
+	if hit {
+		msg := fmt.Sprintf("%s %s (user %s) to %s blocked, matched %s via %s; screening %s",
+			subjectType, subjectID, userID, address, reference, screening.Provider, screening.ID)
+		if err := s.notifier.Notify(ctx, "Sanctioned address blocked", msg); err != nil {
+			slog.ErrorContext(ctx, "sanctions: failed to send alert", "error", err)
+		}
+	}
+	return screening, nil
+}
+
+// checkChainalysis queries the Chainalysis sanctions screening API
+func (s *SanctionsScreener) checkChainalysis(ctx context.Context, address string) (bool, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", chainalysisSanctionsURL+address, nil)
+	if err != nil {
+		return false, "", err
+	}
+	req.Header.Set("X-API-Key", s.cfg.SanctionsAPIKey)
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Identifications []struct {
+			Category string `json:"category"`
+			Name     string `json:"name"`
+		} `json:"identifications"`
+	}
+	if err := s.doJSON(req, &result); err != nil {
+		return false, "", err
+	}
+	if len(result.Identifications) == 0 {
+		return false, "", nil
+	}
+	names := make([]string, len(result.Identifications))
+	for i, id := range result.Identifications {
+		names[i] = id.Name
+	}
+	return true, strings.Join(names, "; "), nil
+}
+
+// checkTRM queries the TRM Labs sanctions screening API
+func (s *SanctionsScreener) checkTRM(ctx context.Context, address string) (bool, string, error) {
+	body, _ := json.Marshal([]map[string]string{{"address": address}})
+	req, err := http.NewRequestWithContext(ctx, "POST", trmSanctionsURL, bytes.NewReader(body))
+	if err != nil {
+		return false, "", err
+	}
+	req.SetBasicAuth(s.cfg.SanctionsAPIKey, s.cfg.SanctionsAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	var results []struct {
+		Address      string `json:"address"`
+		IsSanctioned bool   `json:"isSanctioned"`
+	}
+	if err := s.doJSON(req, &results); err != nil {
+		return false, "", err
+	}
+	for _, r := range results {
+		if r.IsSanctioned {
+			return true, "TRM sanctions screening", nil
+		}
+	}
+	return false, "", nil
+}
+
+func (s *SanctionsScreener) doJSON(req *http.Request, out interface{}) error {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("provider returned %d: %s", resp.StatusCode, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Start syncs the open sanctions list until ctx is cancelled. Only the ofac
+// provider screens against the local list.
+func (s *SanctionsScreener) Start(ctx context.Context) {
+	if s.cfg.SanctionsProvider != SanctionsProviderOFAC {
+		return
+	}
+
+	interval := time.Duration(s.cfg.SanctionsSyncInterval) * time.Second
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil {
+			slog.ErrorContext(ctx, "sanctions: list sync failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync downloads the sanctions list and replaces the local copy
+func (s *SanctionsScreener) Sync(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.cfg.SanctionsListURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("list returned %d", resp.StatusCode)
+	}
+
+	var addresses []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if common.IsHexAddress(line) {
+			addresses = append(addresses, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// An empty download is far more likely a broken list than no sanctions
+	if len(addresses) == 0 {
+		return fmt.Errorf("list at %s has no addresses, keeping the current copy", s.cfg.SanctionsListURL)
+	}
+
+	if err := s.repo.ReplaceList(ctx, SanctionsProviderOFAC, addresses); err != nil {
+		return fmt.Errorf("failed to store list: %w", err)
+	}
+	slog.InfoContext(ctx, "sanctions: synced", "addresses", len(addresses))
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrSandboxOnly          = newCodedError("sandbox_only")
+	ErrSandboxCreditPayout  = newCodedError("sandbox_credit_payout")
+	ErrInvalidFaucetAmount  = newCodedError("invalid_faucet_amount")
+	ErrFaucetLimitReached   = newCodedError("faucet_limit_reached")
+	ErrEnterpriseEmailTaken = newCodedError("enterprise_email_taken")
+)
+
+// SandboxPlan is the fee plan of sandbox enterprises. Their pockets are
+// never charged platform fees, whatever its schedule says.
+const SandboxPlan = "sandbox"
+
+// SandboxService runs sandbox tenants: enterprises for integrating against
+// the API without real money. Their campaigns get budget from a faucet,
+// their pockets need no deposit and pay out through simulated transfers,
+// and everything they create is flagged test mode, which keeps it out of
+// the discovery feed, partner statements, platform fees and receipt
+// tracking.
+type SandboxService struct {
+	enterpriseRepo *repository.EnterpriseRepository
+	campaignRepo   repository.CampaignStore
+	cfg            *config.Config
+}
+
+func NewSandboxService(enterpriseRepo *repository.EnterpriseRepository, campaignRepo repository.CampaignStore, cfg *config.Config) *SandboxService {
+	return &SandboxService{
+		enterpriseRepo: enterpriseRepo,
+		campaignRepo:   campaignRepo,
+		cfg:            cfg,
+	}
+}
+
+type CreateSandboxEnterpriseRequest struct {
+	Name  string `json:"name" binding:"required,max=255"`
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+// CreateEnterprise records a sandbox enterprise and issues its API key
+func (s *SandboxService) CreateEnterprise(ctx context.Context, req *CreateSandboxEnterpriseRequest) (*model.Enterprise, *EnterpriseKey, error) {
+	key, hash, err := newEnterpriseKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	e := &model.Enterprise{
+		ID:              "enterprise_" + uuid.New().String()[:8],
+		Name:            strings.TrimSpace(req.Name),
+		Email:           req.Email,
+		Status:          "active",
+		CreatedAt:       now,
+		APIKeyHash:      hash,
+		APIKeyCreatedAt: &now,
+		TestMode:        true,
+	}
+	ok, err := s.enterpriseRepo.CreateSandbox(ctx, e, SandboxPlan)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create sandbox enterprise: %w", err)
+	}
+	if !ok {
+		return nil, nil, ErrEnterpriseEmailTaken
+	}
+	return e, &EnterpriseKey{Key: key, CreatedAt: now}, nil
+}
+
+type FaucetRequest struct {
+	CampaignID string  `json:"campaignId" binding:"required"`
+	Amount     float64 `json:"amount"` // default SANDBOX_FAUCET_MAX
+}
+
+// Faucet adds fake budget to one of a sandbox enterprise's campaigns, at
+// most SANDBOX_FAUCET_MAX per grant and SANDBOX_FAUCET_DAILY_GRANTS grants
+// a day
+func (s *SandboxService) Faucet(ctx context.Context, enterpriseID string, req *FaucetRequest) (*model.Campaign, *model.FaucetGrant, error) {
+	testMode, err := s.campaignRepo.EnterpriseTestMode(ctx, enterpriseID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load enterprise: %w", err)
+	}
+	if !testMode {
+		return nil, nil, ErrSandboxOnly
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = s.cfg.SandboxFaucetMax
+	}
+	if amount <= 0 || amount > s.cfg.SandboxFaucetMax {
+		return nil, nil, ErrInvalidFaucetAmount
+	}
+
+	grant := &model.FaucetGrant{
+		ID:           "faucet_" + uuid.New().String()[:8],
+		EnterpriseID: enterpriseID,
+		CampaignID:   req.CampaignID,
+		Amount:       amount,
+		CreatedAt:    time.Now(),
+	}
+	campaign, ok, err := s.campaignRepo.GrantFaucet(ctx, grant, s.cfg.SandboxFaucetDailyGrants)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to grant faucet budget: %w", err)
+	}
+	if !ok {
+		return nil, nil, ErrFaucetLimitReached
+	}
+	return campaign, grant, nil
+}
@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrVaultNotFound    = newCodedError("vault_not_found")
+	ErrVaultUnavailable = newCodedError("vault_unavailable")
+)
+
+const (
+	VaultProtocolERC4626 = "erc4626"
+	VaultProtocolAaveV3  = "aave_v3"
+)
+
+// SavingsService lets claimers have their payouts deposited into a
+// whitelisted yield vault, tracks what they hold there and withdraws it
+// back to their wallet. Only the claimer's own share is saved; payout
+// splits and donations are still transferred.
+type SavingsService struct {
+	repo       *repository.SavingsRepository
+	walletSvc  *WalletService
+	tokens     *TokenRegistry
+	cfg        *config.Config
+	httpClient *http.Client
+}
+
+func NewSavingsService(repo *repository.SavingsRepository, walletSvc *WalletService, tokens *TokenRegistry, cfg *config.Config) *SavingsService {
+	return &SavingsService{
+		repo:      repo,
+		walletSvc: walletSvc,
+		tokens:    tokens,
+		cfg:       cfg,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type SavingsRequest struct {
+	UserID        string `json:"-"`
+	WalletAddress string `json:"walletAddress"` // optional; must be the user's wallet
+	ChainID       int64  `json:"chainId"`       // chain of the wallet, default CHAIN_ID
+	VaultID       string `json:"vaultId"`       // empty stops saving
+}
+
+type VaultWithdrawRequest struct {
+	UserID        string      `json:"-"`
+	WalletAddress string      `json:"walletAddress"` // optional; must be the user's wallet
+	VaultID       string      `json:"vaultId" binding:"required"`
+	Amount        json.Number `json:"amount"` // decimal token amount; empty withdraws everything
+}
+
+// Savings is a claimer's savings vault and their positions
+type Savings struct {
+	VaultID     string                   `json:"vaultId,omitempty"`
+	UpdatedAt   *time.Time               `json:"updatedAt,omitempty"`
+	Positions   []*model.SavingsPosition `json:"positions"`
+	Withdrawals []*model.VaultWithdrawal `json:"withdrawals"`
+}
+
+// VaultDeposit is a deposit into a vault made within a payout
+type VaultDeposit struct {
+	Vault    *model.YieldVault
+	Receiver string
+	Amount   *big.Int
+}
+
+// Vaults returns the enabled vaults of a chain, or of every chain for 0
+func (s *SavingsService) Vaults(ctx context.Context, chainID int64) ([]*model.YieldVault, error) {
+	return s.repo.ListVaults(ctx, chainID)
+}
+
+// Get returns a user's savings vault, their positions and recent withdrawals
+func (s *SavingsService) Get(ctx context.Context, userID string) (*Savings, error) {
+	savings := &Savings{}
+	pref, err := s.repo.GetPreference(ctx, userID)
+	switch {
+	case err == nil:
+		savings.VaultID = pref.VaultID
+		savings.UpdatedAt = pref.UpdatedAt
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to load savings preference: %w", err)
+	}
+
+	if savings.Withdrawals, err = s.withdrawals(ctx, userID); err != nil {
+		return nil, err
+	}
+	if savings.Positions, err = s.Positions(ctx, userID); err != nil {
+		return nil, err
+	}
+	return savings, nil
+}
+
+// Update sets or clears the vault a user's payouts are saved in. Only the
+// user ctx was authenticated as can, as for payout splits.
+func (s *SavingsService) Update(ctx context.Context, req *SavingsRequest) (*Savings, error) {
+	chainID := req.ChainID
+	if chainID == 0 {
+		chainID = s.cfg.ChainID
+	}
+	if _, err := s.ownedWallet(ctx, req.UserID, req.WalletAddress, chainID); err != nil {
+		return nil, err
+	}
+
+	if req.VaultID == "" {
+		if err := s.repo.DeletePreference(ctx, req.UserID); err != nil {
+			return nil, fmt.Errorf("failed to clear savings preference: %w", err)
+		}
+		return s.Get(ctx, req.UserID)
+	}
+
+	vault, err := s.vault(ctx, req.VaultID)
+	if err != nil {
+		return nil, err
+	}
+	if !vault.Enabled || vault.ChainID != chainID {
+		return nil, ErrVaultUnavailable
+	}
+	if err := s.repo.SetPreference(ctx, &model.SavingsPreference{UserID: req.UserID, VaultID: vault.ID}); err != nil {
+		return nil, fmt.Errorf("failed to save savings preference: %w", err)
+	}
+	return s.Get(ctx, req.UserID)
+}
+
+// VaultFor returns the vault a new claim of rp by userID should be saved in,
+// or nil to pay the claimer's wallet. A vault that was disabled, or does not
+// take the pocket's token, is skipped rather than failing the claim.
+func (s *SavingsService) VaultFor(ctx context.Context, userID string, rp *model.RedPocket) (*model.YieldVault, error) {
+	pref, err := s.repo.GetPreference(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	vault, err := s.repo.GetVault(ctx, pref.VaultID)
+	if err != nil {
+		return nil, err
+	}
+	if !vault.Enabled || vault.ChainID != rp.ChainID || !strings.EqualFold(vault.AssetAddress, rp.TokenAddress) {
+		return nil, nil
+	}
+	return vault, nil
+}
+
+// Positions returns what a user holds in each vault they have saved in
+func (s *SavingsService) Positions(ctx context.Context, userID string) ([]*model.SavingsPosition, error) {
+	ids, err := s.repo.VaultIDsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load savings vaults: %w", err)
+	}
+
+	positions := make([]*model.SavingsPosition, 0, len(ids))
+	for _, id := range ids {
+		vault, err := s.repo.GetVault(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load vault %s: %w", id, err)
+		}
+		position, err := s.position(ctx, userID, vault)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// position values a user's holding in vault. The value is read from the
+// chain; in simulation mode, or if the node cannot be reached, it falls back
+// to what was deposited less what was withdrawn.
+func (s *SavingsService) position(ctx context.Context, userID string, vault *model.YieldVault) (*model.SavingsPosition, error) {
+	decimals, err := s.tokens.Decimals(ctx, vault.AssetAddress, vault.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	deposited, err := s.repo.Deposited(ctx, userID, vault.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total deposits: %w", err)
+	}
+	withdrawn, err := s.repo.Withdrawn(ctx, userID, vault.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total withdrawals: %w", err)
+	}
+
+	book := new(big.Int).Sub(deposited.Int(), withdrawn.Int())
+	if book.Sign() < 0 {
+		book.SetInt64(0)
+	}
+	value := book
+	if !s.walletSvc.Simulated() {
+		if wallet, err := s.walletSvc.GetByUserID(ctx, userID, vault.ChainID); err == nil {
+			if onchain, err := s.vaultAssets(ctx, vault, wallet.Address); err == nil {
+				value = onchain
+			} else {
+				slog.WarnContext(ctx, "savings: failed to read position", "vault_id", vault.ID, "address", wallet.Address, "error", err)
+			}
+		}
+	}
+
+	earned := new(big.Int).Add(value, withdrawn.Int())
+	earned.Sub(earned, deposited.Int())
+	if earned.Sign() < 0 {
+		earned.SetInt64(0)
+	}
+	return &model.SavingsPosition{
+		Vault:     vault,
+		Decimals:  decimals,
+		Deposited: deposited,
+		Withdrawn: withdrawn,
+		Value:     model.NewUnits(value),
+		Earned:    model.NewUnits(earned),
+	}, nil
+}
+
+// Withdraw moves savings from a vault back to the user's wallet. The
+// withdrawal is recorded as pending while its user operation is in the
+// mempool and settled the next time the user's savings are read.
+func (s *SavingsService) Withdraw(ctx context.Context, req *VaultWithdrawRequest) (*model.VaultWithdrawal, error) {
+	vault, err := s.vault(ctx, req.VaultID)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := s.ownedWallet(ctx, req.UserID, req.WalletAddress, vault.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	position, err := s.position(ctx, req.UserID, vault)
+	if err != nil {
+		return nil, err
+	}
+
+	all := req.Amount == ""
+	amount := position.Value.Int()
+	if !all {
+		parsed, ok := parseUnits(req.Amount.String(), position.Decimals)
+		if !ok || parsed.Sign() <= 0 {
+			return nil, ErrInvalidAmount
+		}
+		amount = parsed
+	}
+	if amount.Sign() == 0 || amount.Cmp(position.Value.Int()) > 0 {
+		return nil, ErrInsufficientBalance
+	}
+
+	var targets, datas []string
+	switch vault.Protocol {
+	case VaultProtocolERC4626:
+		targets = []string{vault.Address}
+		if all && !s.walletSvc.Simulated() {
+			shares, err := s.walletSvc.TokenBalance(ctx, vault.Address, wallet.Address)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read vault shares: %w", err)
+			}
+			datas = []string{buildVaultRedeemCallData(shares, wallet.Address)}
+		} else {
+			datas = []string{buildVaultWithdrawCallData(amount, wallet.Address)}
+		}
+	case VaultProtocolAaveV3:
+		withdrawAmount := amount
+		if all {
+			// type(uint256).max withdraws the whole aToken balance, interest
+			// accrued since it was read included
+			withdrawAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+		}
+		targets = []string{vault.Address}
+		datas = []string{buildAaveWithdrawCallData(vault.AssetAddress, withdrawAmount, wallet.Address)}
+	default:
+		return nil, ErrVaultUnavailable
+	}
+
+	w := &model.VaultWithdrawal{
+		ID:            "vwd_" + uuid.New().String()[:8],
+		UserID:        req.UserID,
+		VaultID:       vault.ID,
+		WalletAddress: wallet.Address,
+		AmountUnits:   model.NewUnits(amount),
+		Status:        "pending",
+		CreatedAt:     time.Now(),
+	}
+	if err := s.repo.CreateWithdrawal(ctx, w); err != nil {
+		return nil, fmt.Errorf("failed to record withdrawal: %w", err)
+	}
+
+	txHash, err := s.walletSvc.Execute(ctx, wallet, targets, datas)
+	var pending *PendingUserOpError
+	switch {
+	case errors.As(err, &pending):
+		w.UserOpHash = pending.UserOpHash
+	case err != nil:
+		w.Status = "failed"
+		w.Error = err.Error()
+	default:
+		w.Status = "success"
+		w.TxHash = txHash
+	}
+	if err := s.repo.UpdateWithdrawal(ctx, w); err != nil {
+		return nil, fmt.Errorf("failed to record withdrawal: %w", err)
+	}
+	if w.Status == "failed" {
+		return w, ErrTransferFailed
+	}
+	return w, nil
+}
+
+// withdrawals returns a user's recent withdrawals, settling the pending
+// ones whose user operation has landed since
+func (s *SavingsService) withdrawals(ctx context.Context, userID string) ([]*model.VaultWithdrawal, error) {
+	withdrawals, err := s.repo.ListWithdrawals(ctx, userID, 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load withdrawals: %w", err)
+	}
+	for _, w := range withdrawals {
+		if w.Status != "pending" || w.UserOpHash == "" {
+			continue
+		}
+		status, txHash, err := s.walletSvc.UserOpOutcome(ctx, w.UserOpHash)
+		if err != nil || status == "pending" {
+			continue
+		}
+		w.Status = status
+		w.TxHash = txHash
+		if err := s.repo.UpdateWithdrawal(ctx, w); err != nil {
+			slog.ErrorContext(ctx, "savings: failed to settle withdrawal", "withdrawal_id", w.ID, "error", err)
+		}
+	}
+	return withdrawals, nil
+}
+
+func (s *SavingsService) vault(ctx context.Context, id string) (*model.YieldVault, error) {
+	vault, err := s.repo.GetVault(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVaultNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+	return vault, nil
+}
+
+// ownedWallet returns the user's wallet on chainID if ctx was authenticated
+// as the user, by a claimer session, and the wallet is at address when one
+// is given
+func (s *SavingsService) ownedWallet(ctx context.Context, userID, address string, chainID int64) (*model.Wallet, error) {
+	if account, ok := accountFrom(ctx); !ok || account != userID {
+		return nil, ErrWalletNotOwned
+	}
+	wallet, err := s.walletSvc.GetByUserID(ctx, userID, chainID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWalletNotOwned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet: %w", err)
+	}
+	if address != "" && !strings.EqualFold(address, wallet.Address) {
+		return nil, ErrWalletNotOwned
+	}
+	return wallet, nil
+}
+
+// vaultAssets reads how much of the vault's asset owner's position is worth
+func (s *SavingsService) vaultAssets(ctx context.Context, vault *model.YieldVault, owner string) (*big.Int, error) {
+	balance, err := s.walletSvc.TokenBalance(ctx, vault.ShareAddress, owner)
+	if err != nil || vault.Protocol != VaultProtocolERC4626 || balance.Sign() == 0 {
+		// aTokens rebase, so their balance already is the asset amount
+		return balance, err
+	}
+
+	// convertToAssets(uint256) selector: 0x07a2d13a
+	data := "0x07a2d13a" + hex.EncodeToString(common.LeftPadBytes(balance.Bytes(), 32))
+	result, err := callRPC(ctx, s.httpClient, s.cfg.RPCUrl, "eth_call", map[string]string{
+		"to":   vault.Address,
+		"data": data,
+	}, "latest")
+	if err != nil {
+		return nil, err
+	}
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return nil, err
+	}
+	assets, ok := new(big.Int).SetString(strings.TrimPrefix(hexValue, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid convertToAssets result: %s", hexValue)
+	}
+	return assets, nil
+}
+
+// savingsShare is the part of a claim's payout its claimer's wallet receives
+// after donations and payout splits: the part that can be saved
+func savingsShare(claim *model.Claim) *big.Int {
+	recipients, amounts := splitPayout(claim, payoutUnits(claim))
+	for i, to := range recipients {
+		if to == claim.WalletAddress {
+			return amounts[i]
+		}
+	}
+	return new(big.Int)
+}
+
+// takeSavings takes the claimer's own share out of a claim's transfers and
+// returns it as a deposit into vault on the claimer's behalf
+func takeSavings(claim *model.Claim, recipients []string, amounts []*big.Int, vault *model.YieldVault) ([]string, []*big.Int, *VaultDeposit) {
+	for i, to := range recipients {
+		if to != claim.WalletAddress {
+			continue
+		}
+		deposit := &VaultDeposit{Vault: vault, Receiver: to, Amount: amounts[i]}
+		recipients = append(recipients[:i:i], recipients[i+1:]...)
+		amounts = append(amounts[:i:i], amounts[i+1:]...)
+		return recipients, amounts, deposit
+	}
+	return recipients, amounts, nil
+}
+
+// payClaim pays a claim from its pocket's payout wallet, see
+// PayoutWalletID: a single transfer to the claimer's wallet, or one batched
+// user operation for payout splits, donations and savings
+func payClaim(ctx context.Context, walletSvc *WalletService, savings *SavingsService, from *model.Wallet, tokenAddress string, claim *model.Claim) (string, error) {
+	if !splitsPayout(claim) && claim.SavingsVaultID == "" {
+		return walletSvc.TransferToken(ctx, from, tokenAddress, claim.WalletAddress, payoutUnits(claim))
+	}
+	recipients, amounts := splitPayout(claim, payoutUnits(claim))
+	var deposits []*VaultDeposit
+	if claim.SavingsVaultID != "" {
+		vault, err := savings.vault(ctx, claim.SavingsVaultID)
+		if err != nil {
+			return "", err
+		}
+		var deposit *VaultDeposit
+		if recipients, amounts, deposit = takeSavings(claim, recipients, amounts, vault); deposit != nil {
+			deposits = append(deposits, deposit)
+		}
+	}
+	return walletSvc.BatchPayout(ctx, from, tokenAddress, recipients, amounts, deposits, "")
+}
+
+// vaultDepositCallData builds the call depositing amount of the vault's
+// asset for receiver. The token approval is made separately, once per batch.
+func vaultDepositCallData(vault *model.YieldVault, amount *big.Int, receiver string) string {
+	if vault.Protocol == VaultProtocolAaveV3 {
+		// supply(address,uint256,address,uint16) selector: 0x617ba037
+		return "0x617ba037" +
+			hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(vault.AssetAddress).Bytes(), 32)) +
+			hex.EncodeToString(common.LeftPadBytes(amount.Bytes(), 32)) +
+			hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(receiver).Bytes(), 32)) +
+			hex.EncodeToString(make([]byte, 32))
+	}
+	// deposit(uint256,address) selector: 0x6e553f65
+	return "0x6e553f65" +
+		hex.EncodeToString(common.LeftPadBytes(amount.Bytes(), 32)) +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(receiver).Bytes(), 32))
+}
+
+// buildVaultWithdrawCallData builds ERC-4626 withdraw(assets, owner, owner)
+func buildVaultWithdrawCallData(assets *big.Int, owner string) string {
+	// withdraw(uint256,address,address) selector: 0xb460af94
+	paddedOwner := hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32))
+	return "0xb460af94" + hex.EncodeToString(common.LeftPadBytes(assets.Bytes(), 32)) + paddedOwner + paddedOwner
+}
+
+// buildVaultRedeemCallData builds ERC-4626 redeem(shares, owner, owner)
+func buildVaultRedeemCallData(shares *big.Int, owner string) string {
+	// redeem(uint256,address,address) selector: 0xba087652
+	paddedOwner := hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32))
+	return "0xba087652" + hex.EncodeToString(common.LeftPadBytes(shares.Bytes(), 32)) + paddedOwner + paddedOwner
+}
+
+// buildAaveWithdrawCallData builds Aave v3 withdraw(asset, amount, to)
+func buildAaveWithdrawCallData(asset string, amount *big.Int, to string) string {
+	// withdraw(address,uint256,address) selector: 0x69328dec
+	return "0x69328dec" +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(asset).Bytes(), 32)) +
+		hex.EncodeToString(common.LeftPadBytes(amount.Bytes(), 32)) +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(to).Bytes(), 32))
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/bot"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var ErrPocketNotScheduled = newCodedError("pocket_not_scheduled")
+
+const (
+	scheduleSweepInterval = 15 * time.Second
+	scheduleSweepBatch    = 50
+)
+
+// PocketScheduler opens scheduled red pockets when their start time comes,
+// announces them in their Telegram, Discord or Slack channel and schedules the next
+// instance of recurring ones
+type PocketScheduler struct {
+	rpRepo       repository.RedPocketStore
+	campaignRepo repository.CampaignStore
+	funding      *PocketFunding
+	events       *PocketEvents
+	telegram     *bot.TelegramBot
+	discord      *bot.DiscordBot
+	slack        *bot.SlackBot
+	links        *ClaimLinks
+	cfg          *config.Config
+}
+
+func NewPocketScheduler(
+	rpRepo repository.RedPocketStore,
+	campaignRepo repository.CampaignStore,
+	funding *PocketFunding,
+	events *PocketEvents,
+	telegram *bot.TelegramBot,
+	discord *bot.DiscordBot,
+	slack *bot.SlackBot,
+	links *ClaimLinks,
+	cfg *config.Config,
+) *PocketScheduler {
+	return &PocketScheduler{
+		rpRepo:       rpRepo,
+		campaignRepo: campaignRepo,
+		funding:      funding,
+		events:       events,
+		telegram:     telegram,
+		discord:      discord,
+		slack:        slack,
+		links:        links,
+		cfg:          cfg,
+	}
+}
+
+// Start opens due pockets until ctx is cancelled
+func (s *PocketScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(scheduleSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PocketScheduler) sweep(ctx context.Context) {
+	pockets, err := s.rpRepo.ActivateDue(ctx, scheduleSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduler: failed to open scheduled red pockets", "error", err)
+		return
+	}
+	for _, rp := range pockets {
+		s.events.PublishStatus(ctx, rp)
+		if err := s.Announce(ctx, rp); err != nil {
+			slog.ErrorContext(ctx, "scheduler: failed to announce", "red_pocket_id", rp.ID, "error", err)
+		}
+		if rp.Recurrence != "" {
+			if err := s.scheduleNext(ctx, rp); err != nil {
+				slog.ErrorContext(ctx, "scheduler: failed to schedule next instance", "red_pocket_id", rp.ID, "error", err)
+			}
+		}
+	}
+}
+
+// Announce posts an open pocket to the channel it was created for
+func (s *PocketScheduler) Announce(ctx context.Context, rp *model.RedPocket) error {
+	if rp.ChannelID == "" {
+		return nil
+	}
+	link := s.links.URL(rp)
+	switch rp.Platform {
+	case "telegram":
+		if !s.telegram.IsConfigured() {
+			return nil
+		}
+		chatID, err := strconv.ParseInt(rp.ChannelID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid telegram chat id %q", rp.ChannelID)
+		}
+		return s.telegram.SendRedPocketNotification(chatID, rp.ID, rp.SenderName, rp.Amount, rp.Token, link, rp.Message)
+	case "discord":
+		if !s.discord.IsConfigured() {
+			return nil
+		}
+		return s.discord.SendRedPocketNotification(rp.ChannelID, rp.SenderName, rp.Amount, rp.Token, link, rp.Message)
+	case "slack":
+		teamID, channelID, ok := bot.ParseSlackChannel(rp.ChannelID)
+		if !ok {
+			return fmt.Errorf("invalid slack channel %q, expected TEAM_ID:CHANNEL_ID", rp.ChannelID)
+		}
+		return s.slack.SendRedPocketNotification(ctx, teamID, channelID, rp.ID, rp.SenderName, rp.Amount, rp.Token, link, rp.Message)
+	}
+	return nil
+}
+
+// scheduleNext creates the following instance of a recurring pocket, lasting
+// as long as this one. The series ends after RecurrenceUntil, or when the
+// campaign's budget cannot cover another instance. When deposits are
+// required each instance waits for its own, and one that goes unfunded ends
+// the series too.
+func (s *PocketScheduler) scheduleNext(ctx context.Context, rp *model.RedPocket) error {
+	rule, err := parseRecurrence(rp.Recurrence)
+	if err != nil {
+		return err
+	}
+	start := rp.CreatedAt
+	if rp.StartsAt != nil {
+		start = *rp.StartsAt
+	}
+	duration := rp.ExpiresAt.Sub(start)
+	next := rule(start)
+	// Skip occurrences missed while the scheduler was down
+	for !next.IsZero() && next.Add(duration).Before(time.Now()) {
+		next = rule(next)
+	}
+	if next.IsZero() || (rp.RecurrenceUntil != nil && next.After(*rp.RecurrenceUntil)) {
+		return nil
+	}
+
+	instance := *rp
+	instance.ID = "rp_" + uuid.New().String()[:8]
+	instance.RemainingAmount = rp.Amount
+	instance.RemainingUnits = rp.AmountUnits
+	instance.ClaimedCount = 0
+	instance.StartsAt = &next
+	instance.ExpiresAt = next.Add(duration)
+	instance.Status = model.PocketScheduled
+	instance.CreatedAt = time.Now()
+	if err := seedLuckyDraw(&instance); err != nil {
+		return err
+	}
+	deposit, err := s.funding.Deposit(ctx, &instance)
+	if err != nil {
+		return err
+	}
+	ok, err := s.rpRepo.CreateWithinBudget(ctx, &instance, deposit)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBudgetExceeded
+	}
+	s.events.PublishCreated(ctx, &instance)
+	return nil
+}
+
+// ListScheduled returns an enterprise's pockets that have not opened yet
+func (s *PocketScheduler) ListScheduled(ctx context.Context, enterpriseID string, page, limit int) ([]*model.RedPocket, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return s.rpRepo.ListScheduled(ctx, enterpriseID, limit, offset)
+}
+
+// Cancel cancels a pocket that has not opened yet. For a recurring pocket
+// this ends the series, as later instances are only created on opening.
+func (s *PocketScheduler) Cancel(ctx context.Context, id, enterpriseID string) error {
+	rp, err := s.rpRepo.GetByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrRedPocketNotFound
+	}
+	if err != nil {
+		return err
+	}
+	campaign, err := s.campaignRepo.GetByID(ctx, rp.CampaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return ErrRedPocketNotFound
+	}
+
+	cancelled, err := s.rpRepo.CancelScheduled(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel red pocket: %w", err)
+	}
+	if !cancelled {
+		return ErrPocketNotScheduled
+	}
+	rp.Status = model.PocketCancelled
+	s.events.PublishStatus(ctx, rp)
+	return nil
+}
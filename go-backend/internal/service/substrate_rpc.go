@@ -0,0 +1,338 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Substrate chains without an EVM, the relay chain and Acala, answer
+// Substrate JSON-RPC rather than eth_*: balances are read from storage and
+// fees are weighed per extrinsic instead of priced per unit of gas.
+
+// systemAccountPrefix is twox128("System") ++ twox128("Account"), the
+// storage prefix of System.Account, which holds native balances
+const systemAccountPrefix = "26aa394eea5630e07c48ae0c9558cef7b99d880ec681799c0cf30e8886371da9"
+
+// tokensAccountsPrefix is twox128("Tokens") ++ twox128("Accounts"), the
+// storage prefix of the ORML Tokens pallet's balances, keyed by account
+// then currency ID
+const tokensAccountsPrefix = "99971b5749ac43e0235e41b0d37869188ee7418a6531173d60d1f6a82d8f4d51"
+
+// substrateChain is how to build a balance transfer on a Substrate chain,
+// for weighing it with payment_queryInfo
+type substrateChain struct {
+	balancesPallet byte // index of the Balances pallet in the runtime
+	transferCall   byte // index of transfer_keep_alive in the pallet
+	// extra are the signed extensions that carry data, in the runtime's
+	// order: an immortal era, nonce 0, tip 0 and any that follow
+	extra []byte
+	// fallbackFee is assumed, in base units, when the fee cannot be queried
+	fallbackFee int64
+	// tokensPallet is set when other assets are held in an ORML Tokens
+	// pallet, by currency ID
+	tokensPallet bool
+
+	// For XCM extrinsics, see SendXCMExtrinsic: the network's SS58 prefix,
+	// the pallet-xcm and transfer_assets indices, the XCM version its
+	// arguments are encoded in, and the signed extensions' data that is
+	// signed without being sent, after the genesis and era block hashes
+	ss58Prefix      uint16
+	xcmPallet       byte
+	xcmTransferCall byte
+	xcmVersion      byte
+	additional      []byte
+}
+
+var substrateChains = map[ChainID]substrateChain{
+	// CheckMetadataHash follows the tip, disabled
+	ChainPolkadot: {balancesPallet: 5, transferCall: 3, extra: []byte{0, 0, 0, 0}, fallbackFee: 160_000_000, // 0.016 DOT
+		ss58Prefix: 0, xcmPallet: 99, xcmTransferCall: 11, xcmVersion: 4, additional: []byte{0}},
+	ChainAcala: {balancesPallet: 10, transferCall: 3, extra: []byte{0, 0, 0}, fallbackFee: 2_000_000_000, tokensPallet: true, // 0.002 ACA
+		ss58Prefix: 10, xcmPallet: 52, xcmTransferCall: 11, xcmVersion: 3},
+}
+
+// isSubstrateChain reports whether a chain only speaks Substrate JSON-RPC
+func (b *XCMBridge) isSubstrateChain(chainID ChainID) bool {
+	return b.isPolkadotChain(chainID) && !b.isEVMChain(chainID)
+}
+
+// IsSubstrateChain reports whether a chain has transfer fees rather than a
+// gas price, see TransferFee
+func (b *XCMBridge) IsSubstrateChain(chainID ChainID) bool {
+	return b.isSubstrateChain(chainID)
+}
+
+// substrateCall sends a Substrate JSON-RPC request to a chain's providers
+// and decodes its result into out
+func (b *XCMBridge) substrateCall(ctx context.Context, chainID ChainID, method string, params []interface{}, out interface{}) error {
+	req := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      1,
+	}
+	resp, err := b.post(ctx, chainID, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("%s: %s (%d)", method, result.Error.Message, result.Error.Code)
+	}
+	return json.Unmarshal(result.Result, out)
+}
+
+// substrateHealth reports whether a Substrate chain's node is synced and,
+// unless it is a dev chain, has peers
+func (b *XCMBridge) substrateHealth(ctx context.Context, chainID ChainID) (bool, error) {
+	var health struct {
+		Peers           int  `json:"peers"`
+		IsSyncing       bool `json:"isSyncing"`
+		ShouldHavePeers bool `json:"shouldHavePeers"`
+	}
+	if err := b.substrateCall(ctx, chainID, "system_health", []interface{}{}, &health); err != nil {
+		return false, err
+	}
+	if health.IsSyncing {
+		return false, errors.New("chain unhealthy: node is syncing")
+	}
+	if health.ShouldHavePeers && health.Peers == 0 {
+		return false, errors.New("chain unhealthy: node has no peers")
+	}
+	return true, nil
+}
+
+// substrateFreeBalance reads an account's free native balance from
+// System.Account. Accounts that were never funded have none.
+func (b *XCMBridge) substrateFreeBalance(ctx context.Context, chainID ChainID, account string) (*big.Int, error) {
+	accountID, err := substrateAccountID(account)
+	if err != nil {
+		return nil, err
+	}
+	info, err := b.substrateAccountInfo(ctx, chainID, accountID)
+	if err != nil || info == nil {
+		return big.NewInt(0), err
+	}
+	free := make([]byte, 16)
+	for i := range free {
+		free[i] = info[31-i]
+	}
+	return new(big.Int).SetBytes(free), nil
+}
+
+// substrateTokenBalance reads an account's free balance of a currency from
+// the Tokens pallet, zero for accounts that never held it. The currency is
+// given by its storage key, twox64 of its SCALE-encoded ID then the ID.
+func (b *XCMBridge) substrateTokenBalance(ctx context.Context, chainID ChainID, currencyKey, account string) (*big.Int, error) {
+	if !substrateChains[chainID].tokensPallet {
+		return nil, fmt.Errorf("chain %d has no tokens pallet", chainID)
+	}
+	currency, err := hex.DecodeString(strings.TrimPrefix(currencyKey, "0x"))
+	if err != nil || len(currency) <= 8 {
+		return nil, fmt.Errorf("invalid currency key: %s", currencyKey)
+	}
+	accountID, err := substrateAccountID(account)
+	if err != nil {
+		return nil, err
+	}
+	h, _ := blake2b.New(16, nil)
+	h.Write(accountID)
+	key := "0x" + tokensAccountsPrefix + hex.EncodeToString(h.Sum(nil)) + hex.EncodeToString(accountID) +
+		hex.EncodeToString(currency)
+
+	var data *string
+	if err := b.substrateCall(ctx, chainID, "state_getStorage", []interface{}{key}, &data); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return big.NewInt(0), nil
+	}
+	// AccountData starts with free as a little-endian u128
+	raw, err := hex.DecodeString(strings.TrimPrefix(*data, "0x"))
+	if err != nil || len(raw) < 16 {
+		return nil, fmt.Errorf("state_getStorage: invalid token account data")
+	}
+	free := make([]byte, 16)
+	for i := range free {
+		free[i] = raw[15-i]
+	}
+	return new(big.Int).SetBytes(free), nil
+}
+
+// substrateAccountInfo reads an account's AccountInfo from System.Account:
+// nonce, consumers, providers and sufficients as u32, then AccountData
+// starting with free as a little-endian u128. It is nil for accounts that
+// were never funded.
+func (b *XCMBridge) substrateAccountInfo(ctx context.Context, chainID ChainID, accountID []byte) ([]byte, error) {
+	h, _ := blake2b.New(16, nil)
+	h.Write(accountID)
+	key := "0x" + systemAccountPrefix + hex.EncodeToString(h.Sum(nil)) + hex.EncodeToString(accountID)
+
+	var data *string
+	if err := b.substrateCall(ctx, chainID, "state_getStorage", []interface{}{key}, &data); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+	info, err := hex.DecodeString(strings.TrimPrefix(*data, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("state_getStorage: %w", err)
+	}
+	if len(info) < 32 {
+		return nil, fmt.Errorf("state_getStorage: account info of %d bytes", len(info))
+	}
+	return info, nil
+}
+
+// TransferFee returns what a balance transfer costs on a Substrate chain,
+// in base units of its native token, weighed by payment_queryInfo on a
+// transfer_keep_alive with a dummy signature. A fee queried within the
+// last GasPriceCacheTTL seconds is reused, in place of the gas price the
+// chain does not have. When the query fails the chain's usual fee is
+// assumed.
+func (b *XCMBridge) TransferFee(ctx context.Context, chainID ChainID) (*big.Int, error) {
+	chain, ok := substrateChains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("chain %d is not a Substrate chain", chainID)
+	}
+	if fee, ok := b.cachedGasPrice(chainID); ok {
+		return fee, nil
+	}
+
+	var info struct {
+		PartialFee json.RawMessage `json:"partialFee"`
+	}
+	extrinsic := "0x" + hex.EncodeToString(chain.dummyTransfer())
+	if err := b.substrateCall(ctx, chainID, "payment_queryInfo", []interface{}{extrinsic}, &info); err != nil {
+		return big.NewInt(chain.fallbackFee), nil
+	}
+	fee, ok := parseSubstrateNumber(info.PartialFee)
+	if !ok {
+		return big.NewInt(chain.fallbackFee), nil
+	}
+	b.cacheGasPrice(chainID, fee)
+	return fee, nil
+}
+
+// dummyTransfer encodes a signed v4 extrinsic moving 1 base unit from and
+// to the zero account. Weighing it does not check the signature.
+func (c substrateChain) dummyTransfer() []byte {
+	var x bytes.Buffer
+	x.WriteByte(0x84)         // signed, version 4
+	x.WriteByte(0x00)         // MultiAddress::Id
+	x.Write(make([]byte, 32)) // signer
+	x.WriteByte(0x01)         // MultiSignature::Sr25519
+	x.Write(make([]byte, 64)) // signature
+	x.Write(c.extra)          // era, nonce, tip, ...
+	x.Write([]byte{c.balancesPallet, c.transferCall})
+	x.WriteByte(0x00)         // MultiAddress::Id
+	x.Write(make([]byte, 32)) // dest
+	x.Write(scaleCompact(1))  // value
+
+	return append(scaleCompact(uint64(x.Len())), x.Bytes()...)
+}
+
+// scaleCompact SCALE-encodes n as a compact integer
+func scaleCompact(n uint64) []byte {
+	switch {
+	case n < 1<<6:
+		return []byte{byte(n << 2)}
+	case n < 1<<14:
+		return binary.LittleEndian.AppendUint16(nil, uint16(n<<2|0b01))
+	case n < 1<<30:
+		return binary.LittleEndian.AppendUint32(nil, uint32(n<<2|0b10))
+	}
+	v := binary.LittleEndian.AppendUint64(nil, n)
+	for len(v) > 4 && v[len(v)-1] == 0 {
+		v = v[:len(v)-1]
+	}
+	return append([]byte{byte(len(v)-4)<<2 | 0b11}, v...)
+}
+
+// parseSubstrateNumber reads a balance answered as a decimal string, a hex
+// string or a plain number, depending on the node's version
+func parseSubstrateNumber(raw json.RawMessage) (*big.Int, bool) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		s = string(raw)
+	}
+	if strings.HasPrefix(s, "0x") {
+		return new(big.Int).SetString(s[2:], 16)
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+// substrateAccountID returns the 32-byte account ID of an SS58 address, of
+// any network, or of its hex public key
+func substrateAccountID(account string) ([]byte, error) {
+	if strings.HasPrefix(account, "0x") {
+		id, err := hex.DecodeString(account[2:])
+		if err != nil || len(id) != 32 {
+			return nil, fmt.Errorf("invalid substrate account: %s", account)
+		}
+		return id, nil
+	}
+
+	raw, ok := decodeBase58(account)
+	if !ok || len(raw) < 35 {
+		return nil, fmt.Errorf("invalid substrate account: %s", account)
+	}
+	// A one-byte network prefix up to 63, two bytes above
+	prefixLen := 1
+	if raw[0]&0x40 != 0 {
+		prefixLen = 2
+	}
+	if len(raw) != prefixLen+32+2 {
+		return nil, fmt.Errorf("invalid substrate account: %s", account)
+	}
+	body := raw[:prefixLen+32]
+	checksum := blake2b.Sum512(append([]byte("SS58PRE"), body...))
+	if !bytes.Equal(checksum[:2], raw[prefixLen+32:]) {
+		return nil, fmt.Errorf("invalid substrate account checksum: %s", account)
+	}
+	return body[prefixLen:], nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func decodeBase58(s string) ([]byte, bool) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range s {
+		i := strings.IndexRune(base58Alphabet, r)
+		if i < 0 {
+			return nil, false
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+	// Leading ones stand for leading zero bytes
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	return append(make([]byte, zeros), n.Bytes()...), true
+}
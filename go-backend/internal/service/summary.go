@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrPocketNotFinished = newCodedError("pocket_not_finished")
+	ErrPocketNotSettled  = newCodedError("pocket_not_settled")
+)
+
+const (
+	summarySweepInterval = time.Minute
+	summarySweepBatch    = 100
+)
+
+// SummaryService archives finished red pockets into immutable JSON snapshots
+// so the claim page can be served without touching live tables.
+type SummaryService struct {
+	rpRepo      repository.RedPocketStore
+	claimRepo   repository.ClaimStore
+	summaryRepo *repository.SummaryRepository
+	xcmBridge   *XCMBridge
+	ipfsSvc     *IPFSService
+	tracker     *ReceiptTracker
+	events      *PocketEvents
+}
+
+func NewSummaryService(
+	rpRepo repository.RedPocketStore,
+	claimRepo repository.ClaimStore,
+	summaryRepo *repository.SummaryRepository,
+	xcmBridge *XCMBridge,
+	ipfsSvc *IPFSService,
+	tracker *ReceiptTracker,
+	events *PocketEvents,
+) *SummaryService {
+	return &SummaryService{
+		rpRepo:      rpRepo,
+		claimRepo:   claimRepo,
+		summaryRepo: summaryRepo,
+		xcmBridge:   xcmBridge,
+		ipfsSvc:     ipfsSvc,
+		tracker:     tracker,
+		events:      events,
+	}
+}
+
+// Get returns the archived summary, archiving on demand if the pocket has
+// finished but the background sweep has not reached it yet.
+func (s *SummaryService) Get(ctx context.Context, redPocketID string) (*model.PocketArchive, error) {
+	archive, err := s.summaryRepo.GetByRedPocket(ctx, redPocketID)
+	if err == nil {
+		return archive, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return s.Archive(ctx, redPocketID)
+}
+
+// Archive builds and stores the summary of a finished red pocket. Archives are
+// write-once: if one already exists it is returned unchanged.
+func (s *SummaryService) Archive(ctx context.Context, redPocketID string) (*model.PocketArchive, error) {
+	rp, err := s.rpRepo.GetByID(ctx, redPocketID)
+	if err != nil {
+		return nil, ErrRedPocketNotFound
+	}
+	if !isFinishedStatus(rp.Status) {
+		return nil, ErrPocketNotFinished
+	}
+
+	claims, err := s.claimRepo.ListAllByRedPocket(ctx, redPocketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list claims: %w", err)
+	}
+
+	summary := s.buildSummary(rp, claims)
+	for _, c := range summary.Claims {
+		switch c.Status {
+		case "pending", "processing", "resubmitted", "reorged", "reverted":
+			return nil, ErrPocketNotSettled
+		}
+	}
+	// Don't freeze tx hashes that could still be reorged out
+	unfinalized, err := s.tracker.HasUnfinalized(ctx, redPocketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check payout finality: %w", err)
+	}
+	if unfinalized {
+		return nil, ErrPocketNotSettled
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+
+	archive := &model.PocketArchive{
+		RedPocketID: redPocketID,
+		Summary:     data,
+		ContentHash: hex.EncodeToString(hash[:]),
+		CreatedAt:   summary.GeneratedAt,
+	}
+	created, err := s.summaryRepo.Create(ctx, archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store summary: %w", err)
+	}
+	if !created {
+		// Lost the race to another writer; theirs is the canonical archive
+		return s.summaryRepo.GetByRedPocket(ctx, redPocketID)
+	}
+	return archive, nil
+}
+
+func (s *SummaryService) buildSummary(rp *model.RedPocket, claims []*model.Claim) *model.PocketSummary {
+	summary := &model.PocketSummary{
+		RedPocketID:     rp.ID,
+		CampaignID:      rp.CampaignID,
+		SenderName:      rp.SenderName,
+		SenderAvatar:    rp.SenderAvatar,
+		CoverImage:      rp.CoverImage,
+		Message:         rp.Message,
+		Token:           rp.Token,
+		TokenAddress:    rp.TokenAddress,
+		ChainID:         rp.ChainID,
+		IsLuckyDraw:     rp.IsLuckyDraw,
+		TotalAmount:     rp.Amount,
+		RemainingAmount: rp.RemainingAmount,
+		TotalCount:      rp.TotalCount,
+		FinalStatus:     rp.Status,
+		Claims:          make([]model.PocketSummaryClaim, 0, len(claims)),
+		CreatedAt:       rp.CreatedAt,
+		ExpiresAt:       rp.ExpiresAt,
+		GeneratedAt:     time.Now().UTC(),
+	}
+
+	for _, c := range claims {
+		summary.Claims = append(summary.Claims, model.PocketSummaryClaim{
+			ClaimID:       c.ID,
+			Platform:      c.Platform,
+			PlatformID:    c.PlatformID,
+			WalletAddress: c.WalletAddress,
+			Amount:        c.Amount,
+			Status:        c.Status,
+			TxHash:        c.TxHash,
+			TxURL:         s.xcmBridge.ExplorerTxURL(ChainID(rp.ChainID), c.TxHash),
+			ClaimedAt:     c.CreatedAt,
+			CompletedAt:   c.CompletedAt,
+		})
+		if c.Status == model.ClaimSubmitted || c.Status == model.ClaimConfirmed {
+			summary.ClaimedAmount += c.Amount
+			summary.ClaimedCount++
+		}
+	}
+	return summary
+}
+
+// pin uploads a stored archive to IPFS and records its CID
+func (s *SummaryService) pin(ctx context.Context, redPocketID string) error {
+	archive, err := s.summaryRepo.GetByRedPocket(ctx, redPocketID)
+	if err != nil {
+		return err
+	}
+	pin, err := s.ipfsSvc.Pin(ctx, PinKindPocketSummary, redPocketID, "redpocket-"+redPocketID+"-summary.json", archive.Summary)
+	if err != nil {
+		return err
+	}
+	return s.summaryRepo.SetIPFSCID(ctx, redPocketID, pin.CID)
+}
+
+// Start periodically expires stale pockets, archives every finished pocket
+// that does not have a summary yet and pins new archives to IPFS when
+// configured. Blocks until ctx is cancelled.
+func (s *SummaryService) Start(ctx context.Context) {
+	ticker := time.NewTicker(summarySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SummaryService) sweep(ctx context.Context) {
+	expired, err := s.rpRepo.ExpireOld(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "summary sweep: failed to expire red pockets", "error", err)
+	}
+	for _, id := range expired {
+		if rp, err := s.rpRepo.GetByID(ctx, id); err == nil {
+			s.events.PublishStatus(ctx, rp)
+		}
+	}
+
+	ids, err := s.rpRepo.ListUnarchived(ctx, summarySweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "summary sweep: failed to list red pockets", "error", err)
+		return
+	}
+	for _, id := range ids {
+		if _, err := s.Archive(ctx, id); err != nil && !errors.Is(err, ErrPocketNotSettled) {
+			slog.ErrorContext(ctx, "summary sweep: failed to archive", "red_pocket_id", id, "error", err)
+		}
+	}
+
+	if !s.ipfsSvc.Enabled() {
+		return
+	}
+	ids, err = s.summaryRepo.ListUnpinned(ctx, summarySweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "summary sweep: failed to list unpinned summaries", "error", err)
+		return
+	}
+	for _, id := range ids {
+		if err := s.pin(ctx, id); err != nil {
+			slog.ErrorContext(ctx, "summary sweep: failed to pin", "red_pocket_id", id, "error", err)
+		}
+	}
+}
+
+func isFinishedStatus(status model.PocketStatus) bool {
+	switch status {
+	case model.PocketDepleted, model.PocketExpired, model.PocketCancelled, model.PocketRefunded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrTeamNotFound          = newCodedError("team_not_found")
+	ErrAlreadyInTeam         = newCodedError("already_in_team")
+	ErrTeamMilestoneNotFound = errors.New("team milestone not found")
+	ErrTeamMilestoneAwarded  = newCodedError("team_milestone_awarded")
+	ErrInvalidTeamMilestone  = newCodedError("invalid_team_milestone")
+)
+
+// maxTeamLeaderboard caps how many teams one leaderboard request returns
+const maxTeamLeaderboard = 100
+
+// TeamService runs team competitions within campaigns: claimers form teams
+// and join them with a code, each member's claims on the campaign's pockets
+// count towards their team, and every team reaching one of the campaign's
+// milestones has its bonus pool split among its members' ledger balances
+type TeamService struct {
+	repo         *repository.TeamRepository
+	campaignRepo repository.CampaignStore
+}
+
+func NewTeamService(repo *repository.TeamRepository, campaignRepo repository.CampaignStore) *TeamService {
+	return &TeamService{repo: repo, campaignRepo: campaignRepo}
+}
+
+// UseTeams attributes claims to the claimers' campaign teams and pays out
+// the milestones they reach
+func (s *RedPocketService) UseTeams(teams *TeamService) {
+	s.teams = teams
+}
+
+type CreateTeamRequest struct {
+	Name       string `json:"name" binding:"required,max=64"`
+	Platform   string `json:"platform" binding:"required"`
+	PlatformID string `json:"platformId" binding:"required"`
+}
+
+type JoinTeamRequest struct {
+	Code       string `json:"code" binding:"required,max=16"`
+	Platform   string `json:"platform" binding:"required"`
+	PlatformID string `json:"platformId" binding:"required"`
+}
+
+type TeamMilestoneRequest struct {
+	Claims    int64   `json:"claims" binding:"min=0"`
+	Amount    float64 `json:"amount" binding:"min=0"`
+	BonusPool float64 `json:"bonusPool" binding:"required,gt=0"`
+}
+
+// TeamDetail is a team's standing with the bonus pools paid to it
+type TeamDetail struct {
+	*model.TeamStanding
+	CampaignID string             `json:"campaignId"`
+	Awards     []*model.TeamAward `json:"awards"`
+}
+
+// Create forms a team in a campaign with the claimer as its first member,
+// returning it with the code others join it by
+func (s *TeamService) Create(ctx context.Context, campaignID string, req *CreateTeamRequest) (*model.CampaignTeam, error) {
+	if _, err := s.campaignRepo.GetByID(ctx, campaignID); err != nil {
+		return nil, ErrCampaignNotFound
+	}
+
+	now := time.Now()
+	team := &model.CampaignTeam{
+		ID:         "team_" + uuid.New().String()[:8],
+		CampaignID: campaignID,
+		Name:       strings.TrimSpace(req.Name),
+		CreatedAt:  now,
+	}
+	founder := s.member(team, req.Platform, req.PlatformID, now)
+	for attempt := 0; ; attempt++ {
+		team.JoinCode = newTeamCode()
+		err := s.repo.Create(ctx, team, founder)
+		if errors.Is(err, repository.ErrJoinCodeTaken) && attempt < 3 {
+			continue
+		}
+		if errors.Is(err, repository.ErrAlreadyInTeam) {
+			return nil, ErrAlreadyInTeam
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create team: %w", err)
+		}
+		return team, nil
+	}
+}
+
+// Join adds the claimer to the team with the code. A claimer is in one team
+// of a campaign and stays in it.
+func (s *TeamService) Join(ctx context.Context, req *JoinTeamRequest) (*model.CampaignTeam, error) {
+	team, err := s.repo.GetByCode(ctx, normalizeTeamCode(req.Code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Join(ctx, s.member(team, req.Platform, req.PlatformID, time.Now()))
+	if errors.Is(err, repository.ErrAlreadyInTeam) {
+		return nil, ErrAlreadyInTeam
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to join team: %w", err)
+	}
+	return team, nil
+}
+
+func (s *TeamService) member(team *model.CampaignTeam, platform, platformID string, joinedAt time.Time) *model.TeamMember {
+	return &model.TeamMember{
+		CampaignID: team.CampaignID,
+		TeamID:     team.ID,
+		Platform:   platform,
+		PlatformID: platformID,
+		UserID:     fmt.Sprintf("user_%s_%s", platform, platformID),
+		JoinedAt:   joinedAt,
+	}
+}
+
+// Leaderboard ranks a campaign's teams by amount claimed, then by claims
+func (s *TeamService) Leaderboard(ctx context.Context, campaignID string, limit int) ([]*model.TeamStanding, error) {
+	if limit < 1 || limit > maxTeamLeaderboard {
+		limit = 10
+	}
+	return s.repo.Leaderboard(ctx, campaignID, limit)
+}
+
+// Get returns a team's standing and the bonus pools paid to it
+func (s *TeamService) Get(ctx context.Context, teamID string) (*TeamDetail, error) {
+	team, err := s.repo.GetByID(ctx, teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	standing, err := s.repo.Standing(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	awards, err := s.repo.ListAwards(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return &TeamDetail{TeamStanding: standing, CampaignID: team.CampaignID, Awards: awards}, nil
+}
+
+// UserTeams returns the teams a claimer is in, with their join codes
+func (s *TeamService) UserTeams(ctx context.Context, platform, platformID string) ([]*model.CampaignTeam, error) {
+	return s.repo.ListByMember(ctx, platform, platformID)
+}
+
+// CreateMilestone adds a milestone to an enterprise's campaign. Its bonus
+// pool comes out of the campaign's budget when a team reaches it.
+func (s *TeamService) CreateMilestone(ctx context.Context, campaignID, enterpriseID string, req *TeamMilestoneRequest) (*model.TeamMilestone, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	if req.Claims == 0 && req.Amount == 0 {
+		return nil, ErrInvalidTeamMilestone
+	}
+
+	m := &model.TeamMilestone{
+		ID:         "milestone_" + uuid.New().String()[:8],
+		CampaignID: campaignID,
+		Claims:     req.Claims,
+		Amount:     req.Amount,
+		BonusPool:  req.BonusPool,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.CreateMilestone(ctx, m); err != nil {
+		return nil, fmt.Errorf("failed to create team milestone: %w", err)
+	}
+	return m, nil
+}
+
+// ListMilestones returns the team milestones of an enterprise's campaign
+func (s *TeamService) ListMilestones(ctx context.Context, campaignID, enterpriseID string) ([]*model.TeamMilestone, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMilestones(ctx, campaignID)
+}
+
+// DeleteMilestone removes a milestone of an enterprise's campaign no team
+// has been awarded yet
+func (s *TeamService) DeleteMilestone(ctx context.Context, campaignID, milestoneID, enterpriseID string) error {
+	milestones, err := s.ListMilestones(ctx, campaignID, enterpriseID)
+	if err != nil {
+		return err
+	}
+	for _, m := range milestones {
+		if m.ID != milestoneID {
+			continue
+		}
+		deleted, err := s.repo.DeleteMilestone(ctx, milestoneID)
+		if err != nil {
+			return fmt.Errorf("failed to delete team milestone: %w", err)
+		}
+		if !deleted {
+			return ErrTeamMilestoneAwarded
+		}
+		return nil
+	}
+	return ErrTeamMilestoneNotFound
+}
+
+func (s *TeamService) authorizeCampaign(ctx context.Context, campaignID, enterpriseID string) error {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
+
+// Attribute counts a claim towards the claimer's team in the pocket's
+// campaign, if they are in one, and pays the team the bonus pools of the
+// milestones it has reached with it. A pool the campaign's budget cannot
+// cover is left for a later claim of the team.
+func (s *TeamService) Attribute(ctx context.Context, rp *model.RedPocket, claim *model.Claim) error {
+	teamID, err := s.repo.MemberTeam(ctx, rp.CampaignID, claim.Platform, claim.PlatformID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Attribute(ctx, claim.ID, teamID); err != nil {
+		return err
+	}
+
+	due, err := s.repo.DueMilestones(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	for _, m := range due {
+		award, err := s.repo.Award(ctx, &model.TeamAward{
+			ID:          "award_" + uuid.New().String()[:8],
+			TeamID:      teamID,
+			MilestoneID: m.ID,
+			BonusPool:   m.BonusPool,
+			CreatedAt:   time.Now(),
+		})
+		if errors.Is(err, repository.ErrBudgetNotCovered) {
+			slog.WarnContext(ctx, "campaign budget does not cover team bonus", "team_id", teamID, "milestone_id", m.ID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to award milestone %s: %w", m.ID, err)
+		}
+		if award != nil {
+			slog.InfoContext(ctx, "team bonus paid", "team_id", teamID, "milestone_id", m.ID, "members", award.Members, "share", award.Share)
+		}
+	}
+	return nil
+}
+
+// newTeamCode returns an 8 character join code
+func newTeamCode() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	code := make([]byte, len(b))
+	for i, v := range b {
+		// 256 is a multiple of 32, so every character is equally likely
+		code[i] = referenceAlphabet[int(v)%len(referenceAlphabet)]
+	}
+	return string(code)
+}
+
+// normalizeTeamCode undoes what retyping a code tends to change, as
+// normalizeReference does
+func normalizeTeamCode(code string) string {
+	code = strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	return strings.NewReplacer("O", "0", "I", "1", "L", "1", "-", "").Replace(code)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrInvalidAmount          = newCodedError("invalid_amount")
+	ErrNativeTokenUnsupported = newCodedError("native_token_unsupported")
+
+	// ErrNativeBatchTransfer is returned for a batch that would send the
+	// native token: the wallet's executeBatch cannot carry value
+	ErrNativeBatchTransfer = errors.New("native token transfers cannot be batched")
+)
+
+// TokenRegistry knows the tokens red pockets can be created in: their
+// address and decimals on each chain and the price feed valuing them
+type TokenRegistry struct {
+	repo *repository.TokenRepository
+	cfg  *config.Config
+}
+
+func NewTokenRegistry(repo *repository.TokenRepository, cfg *config.Config) *TokenRegistry {
+	return &TokenRegistry{repo: repo, cfg: cfg}
+}
+
+// Resolve returns an enabled token by symbol. USDC on CHAIN_ID falls back to
+// USDC_ADDRESS when the registry has no row for it, e.g. on testnets.
+func (r *TokenRegistry) Resolve(ctx context.Context, symbol string, chainID int64) (*model.Token, error) {
+	token, err := r.repo.Get(ctx, symbol, chainID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if strings.EqualFold(symbol, "USDC") && chainID == r.cfg.ChainID && r.cfg.USDCAddress != "" {
+			return &model.Token{Symbol: "USDC", ChainID: chainID, Address: r.cfg.USDCAddress, Decimals: 6, Enabled: true}, nil
+		}
+		return nil, ErrUnsupportedToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token: %w", err)
+	}
+	if !token.Enabled {
+		return nil, ErrUnsupportedToken
+	}
+	return token, nil
+}
+
+// Decimals returns the decimals of the token at address, enabled or not, so
+// transfers queued before a token was disabled still go out
+func (r *TokenRegistry) Decimals(ctx context.Context, address string, chainID int64) (int, error) {
+	token, err := r.repo.GetByAddress(ctx, address, chainID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if chainID == r.cfg.ChainID && strings.EqualFold(address, r.cfg.USDCAddress) {
+			return 6, nil
+		}
+		return 0, ErrUnsupportedToken
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load token: %w", err)
+	}
+	return token.Decimals, nil
+}
+
+// List returns the tokens available on a chain
+func (r *TokenRegistry) List(ctx context.Context, chainID int64) ([]*model.Token, error) {
+	if chainID == 0 {
+		chainID = r.cfg.ChainID
+	}
+	tokens, err := r.repo.List(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		tokens = []*model.Token{}
+	}
+	return tokens, nil
+}
+
+// parseUnits converts a decimal amount such as "1.5" to minor units without
+// going through floating point. It fails on more fractional digits than the
+// token has, exponents and signs.
+func parseUnits(amount string, decimals int) (*big.Int, bool) {
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" && frac == "" || len(frac) > decimals {
+		return nil, false
+	}
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, false
+		}
+	}
+	return new(big.Int).SetString(digits, 10)
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+)
+
+var ErrTravelRuleNotFound = newCodedError("travel_rule_not_found")
+
+// errTravelRuleRequired asks for originator and beneficiary information on a
+// withdrawal at or above threshold
+func errTravelRuleRequired(threshold float64) *CodedError {
+	return newCodedError("travel_rule_required", strconv.FormatFloat(threshold, 'f', -1, 64))
+}
+
+// errInvalidTravelRule names the first travel rule field that failed validation
+func errInvalidTravelRule(field string) *CodedError {
+	return newCodedError("invalid_travel_rule", field)
+}
+
+var (
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	// IVMS101 national identifier types
+	nationalIDTypes = map[string]bool{
+		"ARNU": true, "CCPT": true, "RAID": true, "DRLC": true, "FIIN": true,
+		"TXID": true, "SOCS": true, "IDCD": true, "LEIX": true, "MISC": true,
+	}
+)
+
+// checkTravelRule requires travel rule information on withdrawals at or above
+// TRAVEL_RULE_THRESHOLD and validates it whenever it is given
+func (s *LedgerService) checkTravelRule(amount float64, info *model.TravelRuleInfo) error {
+	if info == nil {
+		if s.cfg.TravelRuleThreshold > 0 && amount >= s.cfg.TravelRuleThreshold {
+			return errTravelRuleRequired(s.cfg.TravelRuleThreshold)
+		}
+		return nil
+	}
+
+	if err := validateTravelRuleParty("originator", &info.Originator); err != nil {
+		return err
+	}
+	if err := validateTravelRuleParty("beneficiary", &info.Beneficiary); err != nil {
+		return err
+	}
+	if v := info.BeneficiaryVASP; v != nil {
+		if v.Name == "" {
+			return errInvalidTravelRule("beneficiaryVasp.name")
+		}
+		if v.Country != "" && !countryCodePattern.MatchString(v.Country) {
+			return errInvalidTravelRule("beneficiaryVasp.country")
+		}
+	}
+	return nil
+}
+
+func validateTravelRuleParty(role string, p *model.TravelRuleParty) error {
+	switch p.Type {
+	case "natural":
+		if p.LastName == "" {
+			return errInvalidTravelRule(role + ".lastName")
+		}
+	case "legal":
+		if p.LegalName == "" {
+			return errInvalidTravelRule(role + ".legalName")
+		}
+	default:
+		return errInvalidTravelRule(role + ".type")
+	}
+
+	for field, code := range map[string]string{
+		".country":            p.Country,
+		".countryOfResidence": p.CountryOfResidence,
+	} {
+		if code != "" && !countryCodePattern.MatchString(code) {
+			return errInvalidTravelRule(role + field)
+		}
+	}
+	if p.NationalID != "" && !nationalIDTypes[p.NationalIDType] {
+		return errInvalidTravelRule(role + ".nationalIdType")
+	}
+	if p.DateOfBirth != "" {
+		if _, err := time.Parse("2006-01-02", p.DateOfBirth); err != nil {
+			return errInvalidTravelRule(role + ".dateOfBirth")
+		}
+	}
+	return nil
+}
+
+// TravelRuleExport returns a withdrawal's travel rule information as IVMS101.
+// Only the owner of the withdrawal may export it.
+func (s *LedgerService) TravelRuleExport(ctx context.Context, withdrawalID, ownerID string) (*IVMS101Payload, error) {
+	w, err := s.repo.GetWithdrawal(ctx, withdrawalID)
+	if err != nil || w.UserID != ownerID {
+		return nil, ErrWithdrawalNotFound
+	}
+	if w.TravelRule == nil {
+		return nil, ErrTravelRuleNotFound
+	}
+
+	originatorAccount := w.FromAddress
+	if originatorAccount == "" {
+		// Ledger withdrawals have no on-chain source account of the user's own
+		originatorAccount = w.UserID
+	}
+	payload := &IVMS101Payload{
+		Originator: IVMS101Originator{
+			OriginatorPersons: []IVMS101Person{ivms101Person(&w.TravelRule.Originator)},
+			AccountNumber:     []string{originatorAccount},
+		},
+		Beneficiary: IVMS101Beneficiary{
+			BeneficiaryPersons: []IVMS101Person{ivms101Person(&w.TravelRule.Beneficiary)},
+			AccountNumber:      []string{w.ToAddress},
+		},
+	}
+	if s.cfg.TravelRuleVASPName != "" {
+		payload.OriginatingVASP = &IVMS101OriginatingVASP{
+			OriginatingVASP: ivms101VASP(&model.TravelRuleVASP{
+				Name:    s.cfg.TravelRuleVASPName,
+				LEI:     s.cfg.TravelRuleVASPLEI,
+				Country: s.cfg.TravelRuleVASPCountry,
+			}),
+		}
+	}
+	if v := w.TravelRule.BeneficiaryVASP; v != nil {
+		payload.BeneficiaryVASP = &IVMS101BeneficiaryVASP{BeneficiaryVASP: ivms101VASP(v)}
+	}
+	return payload, nil
+}
+
+// IVMS101Payload is the interVASP messaging standard identity payload
+type IVMS101Payload struct {
+	Originator      IVMS101Originator       `json:"originator"`
+	Beneficiary     IVMS101Beneficiary      `json:"beneficiary"`
+	OriginatingVASP *IVMS101OriginatingVASP `json:"originatingVASP,omitempty"`
+	BeneficiaryVASP *IVMS101BeneficiaryVASP `json:"beneficiaryVASP,omitempty"`
+}
+
+type IVMS101Originator struct {
+	OriginatorPersons []IVMS101Person `json:"originatorPersons"`
+	AccountNumber     []string        `json:"accountNumber"`
+}
+
+type IVMS101Beneficiary struct {
+	BeneficiaryPersons []IVMS101Person `json:"beneficiaryPersons"`
+	AccountNumber      []string        `json:"accountNumber"`
+}
+
+type IVMS101OriginatingVASP struct {
+	OriginatingVASP IVMS101Person `json:"originatingVASP"`
+}
+
+type IVMS101BeneficiaryVASP struct {
+	BeneficiaryVASP IVMS101Person `json:"beneficiaryVASP"`
+}
+
+// IVMS101Person holds exactly one of a natural or legal person
+type IVMS101Person struct {
+	NaturalPerson *IVMS101NaturalPerson `json:"naturalPerson,omitempty"`
+	LegalPerson   *IVMS101LegalPerson   `json:"legalPerson,omitempty"`
+}
+
+type IVMS101NaturalPerson struct {
+	Name                   IVMS101NaturalPersonName `json:"name"`
+	GeographicAddress      []IVMS101Address         `json:"geographicAddress,omitempty"`
+	NationalIdentification *IVMS101NationalID       `json:"nationalIdentification,omitempty"`
+	DateAndPlaceOfBirth    *IVMS101DateAndPlace     `json:"dateAndPlaceOfBirth,omitempty"`
+	CountryOfResidence     string                   `json:"countryOfResidence,omitempty"`
+}
+
+type IVMS101NaturalPersonName struct {
+	NameIdentifier []IVMS101NaturalPersonNameID `json:"nameIdentifier"`
+}
+
+type IVMS101NaturalPersonNameID struct {
+	PrimaryIdentifier   string `json:"primaryIdentifier"`
+	SecondaryIdentifier string `json:"secondaryIdentifier,omitempty"`
+	NameIdentifierType  string `json:"nameIdentifierType"`
+}
+
+type IVMS101LegalPerson struct {
+	Name                   IVMS101LegalPersonName `json:"name"`
+	GeographicAddress      []IVMS101Address       `json:"geographicAddress,omitempty"`
+	NationalIdentification *IVMS101NationalID     `json:"nationalIdentification,omitempty"`
+	CountryOfRegistration  string                 `json:"countryOfRegistration,omitempty"`
+}
+
+type IVMS101LegalPersonName struct {
+	NameIdentifier []IVMS101LegalPersonNameID `json:"nameIdentifier"`
+}
+
+type IVMS101LegalPersonNameID struct {
+	LegalPersonName               string `json:"legalPersonName"`
+	LegalPersonNameIdentifierType string `json:"legalPersonNameIdentifierType"`
+}
+
+type IVMS101Address struct {
+	AddressType    string `json:"addressType"`
+	StreetName     string `json:"streetName,omitempty"`
+	BuildingNumber string `json:"buildingNumber,omitempty"`
+	PostCode       string `json:"postCode,omitempty"`
+	TownName       string `json:"townName,omitempty"`
+	Country        string `json:"country"`
+}
+
+type IVMS101NationalID struct {
+	NationalIdentifier     string `json:"nationalIdentifier"`
+	NationalIdentifierType string `json:"nationalIdentifierType"`
+}
+
+type IVMS101DateAndPlace struct {
+	DateOfBirth  string `json:"dateOfBirth"`
+	PlaceOfBirth string `json:"placeOfBirth,omitempty"`
+}
+
+func ivms101Person(p *model.TravelRuleParty) IVMS101Person {
+	var address []IVMS101Address
+	if p.Country != "" {
+		address = []IVMS101Address{{
+			AddressType:    "GEOG",
+			StreetName:     p.StreetName,
+			BuildingNumber: p.BuildingNumber,
+			PostCode:       p.PostCode,
+			TownName:       p.TownName,
+			Country:        p.Country,
+		}}
+	}
+	var nationalID *IVMS101NationalID
+	if p.NationalID != "" {
+		nationalID = &IVMS101NationalID{NationalIdentifier: p.NationalID, NationalIdentifierType: p.NationalIDType}
+	}
+
+	if p.Type == "legal" {
+		return IVMS101Person{LegalPerson: &IVMS101LegalPerson{
+			Name: IVMS101LegalPersonName{NameIdentifier: []IVMS101LegalPersonNameID{{
+				LegalPersonName:               p.LegalName,
+				LegalPersonNameIdentifierType: "LEGL",
+			}}},
+			GeographicAddress:      address,
+			NationalIdentification: nationalID,
+			CountryOfRegistration:  p.Country,
+		}}
+	}
+
+	person := &IVMS101NaturalPerson{
+		Name: IVMS101NaturalPersonName{NameIdentifier: []IVMS101NaturalPersonNameID{{
+			PrimaryIdentifier:   p.LastName,
+			SecondaryIdentifier: p.FirstName,
+			NameIdentifierType:  "LEGL",
+		}}},
+		GeographicAddress:      address,
+		NationalIdentification: nationalID,
+		CountryOfResidence:     p.CountryOfResidence,
+	}
+	if p.DateOfBirth != "" {
+		person.DateAndPlaceOfBirth = &IVMS101DateAndPlace{DateOfBirth: p.DateOfBirth, PlaceOfBirth: p.PlaceOfBirth}
+	}
+	return IVMS101Person{NaturalPerson: person}
+}
+
+func ivms101VASP(v *model.TravelRuleVASP) IVMS101Person {
+	party := &model.TravelRuleParty{Type: "legal", LegalName: v.Name, Country: v.Country}
+	if v.LEI != "" {
+		party.NationalID = v.LEI
+		party.NationalIDType = "LEIX"
+	}
+	return ivms101Person(party)
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/bot"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrHuntNotFound   = errors.New("treasure hunt not found")
+	ErrHuntStepLocked = newCodedError("hunt_step_locked")
+)
+
+// errHuntStepNotRevealed gives when the step is revealed to the claimer
+func errHuntStepNotRevealed(revealsAt time.Time) *CodedError {
+	return newCodedError("hunt_step_not_revealed", revealsAt.UTC().Format(time.RFC3339))
+}
+
+// errInvalidHunt says what is wrong with a hunt
+func errInvalidHunt(reason string) *CodedError {
+	return newCodedError("invalid_hunt", reason)
+}
+
+// TreasureHuntService links a campaign's red pockets into treasure hunts.
+// A hunt's first pocket is open to everyone; each later one only takes
+// claims from those who claimed the step before, once its reveal delay has
+// passed since they did.
+type TreasureHuntService struct {
+	repo         *repository.TreasureHuntRepository
+	rpRepo       repository.RedPocketStore
+	campaignRepo repository.CampaignStore
+	links        *ClaimLinks
+}
+
+func NewTreasureHuntService(
+	repo *repository.TreasureHuntRepository,
+	rpRepo repository.RedPocketStore,
+	campaignRepo repository.CampaignStore,
+	links *ClaimLinks,
+) *TreasureHuntService {
+	return &TreasureHuntService{
+		repo:         repo,
+		rpRepo:       rpRepo,
+		campaignRepo: campaignRepo,
+		links:        links,
+	}
+}
+
+// UseHunts gates claims on treasure hunt steps and records claimers'
+// progress through them
+func (s *RedPocketService) UseHunts(hunts *TreasureHuntService) {
+	s.hunts = hunts
+}
+
+type HuntStepRequest struct {
+	RedPocketID string `json:"redPocketId" binding:"required"`
+	Clue        string `json:"clue" binding:"max=1000"`
+	RevealDelay int    `json:"revealDelay" binding:"min=0"` // seconds after the step before is claimed
+}
+
+type CreateHuntRequest struct {
+	Name  string            `json:"name" binding:"required,max=255"`
+	Steps []HuntStepRequest `json:"steps" binding:"required,min=2,dive"` // in order
+}
+
+// HuntStatus is where a claimer stands in a treasure hunt
+type HuntStatus struct {
+	HuntID     string    `json:"huntId"`
+	Name       string    `json:"name"`
+	Step       int       `json:"step"` // steps claimed
+	TotalSteps int       `json:"totalSteps"`
+	Completed  bool      `json:"completed"`
+	Next       *HuntClue `json:"next,omitempty"`
+}
+
+// HuntClue is a claimer's next step: its clue at once, and its pocket once
+// RevealsAt has passed
+type HuntClue struct {
+	Position    int       `json:"position"`
+	Clue        string    `json:"clue,omitempty"`
+	RevealsAt   time.Time `json:"revealsAt"`
+	RedPocketID string    `json:"redPocketId,omitempty"`
+	ClaimLink   string    `json:"claimLink,omitempty"`
+}
+
+// Create links red pockets of an enterprise's campaign into a hunt, in the
+// order given. A pocket can be a step of one hunt only.
+func (s *TreasureHuntService) Create(ctx context.Context, campaignID, enterpriseID string, req *CreateHuntRequest) (*model.TreasureHunt, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+
+	hunt := &model.TreasureHunt{
+		ID:         "hunt_" + uuid.New().String()[:8],
+		CampaignID: campaignID,
+		Name:       req.Name,
+		CreatedAt:  time.Now(),
+	}
+	seen := make(map[string]bool, len(req.Steps))
+	for i, step := range req.Steps {
+		if seen[step.RedPocketID] {
+			return nil, errInvalidHunt(fmt.Sprintf("red pocket %s is more than one step", step.RedPocketID))
+		}
+		seen[step.RedPocketID] = true
+		rp, err := s.rpRepo.GetByID(ctx, step.RedPocketID)
+		if err != nil || rp.CampaignID != campaignID {
+			return nil, errInvalidHunt(fmt.Sprintf("red pocket %s is not in the campaign", step.RedPocketID))
+		}
+		hunt.Steps = append(hunt.Steps, &model.HuntStep{
+			Position:    i + 1,
+			RedPocketID: step.RedPocketID,
+			Clue:        step.Clue,
+			RevealDelay: step.RevealDelay,
+		})
+	}
+
+	err := s.repo.Create(ctx, hunt)
+	if errors.Is(err, repository.ErrPocketInHunt) {
+		return nil, errInvalidHunt("a red pocket is already a step of another hunt")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create treasure hunt: %w", err)
+	}
+	return hunt, nil
+}
+
+// List returns the hunts of an enterprise's campaign
+func (s *TreasureHuntService) List(ctx context.Context, campaignID, enterpriseID string) ([]*model.TreasureHunt, error) {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByCampaign(ctx, campaignID)
+}
+
+// Delete removes a hunt of an enterprise's campaign. Its pockets take
+// claims from anyone again.
+func (s *TreasureHuntService) Delete(ctx context.Context, campaignID, huntID, enterpriseID string) error {
+	if err := s.authorizeCampaign(ctx, campaignID, enterpriseID); err != nil {
+		return err
+	}
+	hunt, err := s.repo.GetByID(ctx, huntID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && hunt.CampaignID != campaignID) {
+		return ErrHuntNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.Delete(ctx, huntID); err != nil {
+		return fmt.Errorf("failed to delete treasure hunt: %w", err)
+	}
+	return nil
+}
+
+func (s *TreasureHuntService) authorizeCampaign(ctx context.Context, campaignID, enterpriseID string) error {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil || campaign.EnterpriseID != enterpriseID {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
+
+// CheckUnlocked returns a coded error when rp is a hunt step the claimer
+// has not unlocked: they have to have claimed the step before, and its
+// reveal delay to have passed since
+func (s *TreasureHuntService) CheckUnlocked(ctx context.Context, rp *model.RedPocket, req *ClaimRequest) error {
+	_, step, progress, err := s.repo.StepOf(ctx, rp.ID, req.Platform, req.PlatformID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && step.Position == 1) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up treasure hunt step: %w", err)
+	}
+	if progress == nil || progress.Step < step.Position-1 {
+		return ErrHuntStepLocked
+	}
+	if progress.Step == step.Position-1 {
+		revealsAt := progress.ClaimedAt.Add(time.Duration(step.RevealDelay) * time.Second)
+		if time.Now().Before(revealsAt) {
+			return errHuntStepNotRevealed(revealsAt)
+		}
+	}
+	return nil
+}
+
+// Advance records a claim of a hunt step as the claimer's progress
+func (s *TreasureHuntService) Advance(ctx context.Context, claim *model.Claim) error {
+	huntID, step, _, err := s.repo.StepOf(ctx, claim.RedPocketID, claim.Platform, claim.PlatformID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.Advance(ctx, &model.HuntProgress{
+		HuntID:     huntID,
+		Platform:   claim.Platform,
+		PlatformID: claim.PlatformID,
+		Step:       step.Position,
+		ClaimedAt:  claim.CreatedAt,
+	})
+}
+
+// Progress returns the hunts a claimer has started, most recently
+// advanced first, with the clue of each next step and its pocket once
+// revealed
+func (s *TreasureHuntService) Progress(ctx context.Context, platform, platformID string) ([]*HuntStatus, error) {
+	progress, err := s.repo.ListProgress(ctx, platform, platformID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*HuntStatus, 0, len(progress))
+	now := time.Now()
+	for _, p := range progress {
+		hunt, err := s.repo.GetByID(ctx, p.HuntID)
+		if err != nil {
+			return nil, err
+		}
+		status := &HuntStatus{
+			HuntID:     hunt.ID,
+			Name:       hunt.Name,
+			Step:       p.Step,
+			TotalSteps: len(hunt.Steps),
+			Completed:  p.Step >= len(hunt.Steps),
+		}
+		if !status.Completed {
+			next := hunt.Steps[p.Step]
+			status.Next = &HuntClue{
+				Position:  next.Position,
+				Clue:      next.Clue,
+				RevealsAt: p.ClaimedAt.Add(time.Duration(next.RevealDelay) * time.Second),
+			}
+			if !now.Before(status.Next.RevealsAt) {
+				if rp, err := s.rpRepo.GetByID(ctx, next.RedPocketID); err == nil {
+					status.Next.RedPocketID = rp.ID
+					status.Next.ClaimLink = s.links.URL(rp)
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// HuntsForChat reports a chat user's hunt progress to the bots
+func (s *TreasureHuntService) HuntsForChat(ctx context.Context, platform, platformUserID string) ([]*bot.ChatHunt, error) {
+	statuses, err := s.Progress(ctx, platform, platformUserID)
+	if err != nil {
+		return nil, err
+	}
+	hunts := make([]*bot.ChatHunt, 0, len(statuses))
+	for _, status := range statuses {
+		hunt := &bot.ChatHunt{
+			Name:       status.Name,
+			Step:       status.Step,
+			TotalSteps: status.TotalSteps,
+		}
+		if status.Next != nil {
+			hunt.Clue = status.Next.Clue
+			hunt.RevealsAt = status.Next.RevealsAt
+			hunt.ClaimLink = status.Next.ClaimLink
+		}
+		hunts = append(hunts, hunt)
+	}
+	return hunts, nil
+}
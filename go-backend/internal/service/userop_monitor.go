@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+const (
+	userOpPollInterval = 30 * time.Second
+	userOpBatchSize    = 100
+	// Extra fee bumps a cancellation gets before ops are paged
+	maxCancelAttempts = 2
+)
+
+// UserOpMonitor watches user operations that were not included within the
+// expected time. Stuck ops are replaced with the same nonce at a higher
+// maxFeePerGas; once the attempt budget is spent the payout is cancelled with
+// a no-op replacement so it cannot land later.
+type UserOpMonitor struct {
+	repo      *repository.UserOpRepository
+	claimRepo repository.ClaimStore
+	batchRepo *repository.PayoutBatchRepository
+	walletSvc *WalletService
+	webhooks  *WebhookService
+	cfg       *config.Config
+}
+
+func NewUserOpMonitor(
+	repo *repository.UserOpRepository,
+	claimRepo repository.ClaimStore,
+	batchRepo *repository.PayoutBatchRepository,
+	walletSvc *WalletService,
+	webhooks *WebhookService,
+	cfg *config.Config,
+) *UserOpMonitor {
+	return &UserOpMonitor{
+		repo:      repo,
+		claimRepo: claimRepo,
+		batchRepo: batchRepo,
+		walletSvc: walletSvc,
+		webhooks:  webhooks,
+		cfg:       cfg,
+	}
+}
+
+// Start polls stuck user operations until ctx is cancelled
+func (m *UserOpMonitor) Start(ctx context.Context) {
+	if m.walletSvc.Simulated() {
+		slog.InfoContext(ctx, "userop monitor: simulation mode, monitor disabled")
+		return
+	}
+
+	ticker := time.NewTicker(userOpPollInterval)
+	defer ticker.Stop()
+
+	for {
+		m.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *UserOpMonitor) poll(ctx context.Context) {
+	ops, err := m.repo.ListStuck(ctx, m.cfg.UserOpStuckAfter, userOpBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "userop monitor: failed to list pending ops", "error", err)
+		return
+	}
+	for _, op := range ops {
+		if err := m.check(ctx, op); err != nil {
+			slog.ErrorContext(ctx, "userop monitor: failed to check op", "user_op_hash", op.UserOpHash, "claim_id", op.ClaimID, "error", err)
+		}
+	}
+}
+
+func (m *UserOpMonitor) check(ctx context.Context, op *model.UserOperationRecord) error {
+	// Any op sharing the nonce may have landed, including ones we already replaced
+	siblings, err := m.repo.ListByNonce(ctx, op.Sender, op.Nonce)
+	if err != nil {
+		return err
+	}
+	for _, sib := range siblings {
+		if sib.Status != "pending" && sib.Status != "replaced" {
+			continue
+		}
+		receipt, err := m.walletSvc.aaClient.GetUserOperationReceipt(ctx, sib.UserOpHash)
+		if err != nil {
+			return err
+		}
+		if receipt != nil {
+			return m.settle(ctx, sib, receipt)
+		}
+	}
+
+	cancel := false
+	switch {
+	case op.Kind == "transfer" && op.Attempt >= m.cfg.MaxUserOpAttempts:
+		cancel = true
+	case op.Kind == "cancel" && op.Attempt >= m.cfg.MaxUserOpAttempts+maxCancelAttempts:
+		slog.ErrorContext(ctx, "userop monitor: cancellation still not included", "user_op_hash", op.UserOpHash,
+			"claim_id", op.ClaimID, "attempts", op.Attempt, "alert", true)
+		return m.repo.UpdateStatus(ctx, op.UserOpHash, "failed")
+	}
+
+	newHash, err := m.walletSvc.ReplaceUserOp(ctx, op, cancel, m.cfg.UserOpFeeBumpPercent)
+	if err != nil {
+		return err
+	}
+	if err := m.repo.UpdateStatus(ctx, op.UserOpHash, "replaced"); err != nil {
+		return err
+	}
+	slog.WarnContext(ctx, "userop monitor: op stuck, replaced", "user_op_hash", op.UserOpHash,
+		"stuck_for", time.Since(op.SubmittedAt).Round(time.Second), "replaced_by", newHash, "attempt", op.Attempt+1, "cancel", cancel)
+
+	reason := fmt.Sprintf("user operation %s replaced by %s", op.UserOpHash, newHash)
+	switch {
+	case op.BatchID != "":
+		return m.claimRepo.MarkResubmittedByBatch(ctx, op.BatchID, op.Attempt+1, reason)
+	case op.ClaimID != "":
+		ok, err := m.claimRepo.MarkResubmitted(ctx, op.ClaimID, op.Attempt+1, reason)
+		if err == nil && !ok {
+			slog.WarnContext(ctx, "userop monitor: claim cannot be resubmitted from its current status", "claim_id", op.ClaimID)
+		}
+		return err
+	}
+	return nil
+}
+
+// settle records the op that won the nonce and resolves its claim
+func (m *UserOpMonitor) settle(ctx context.Context, op *model.UserOperationRecord, receipt *UserOperationReceipt) error {
+	if err := m.repo.MarkIncluded(ctx, op.UserOpHash, receipt.TxHash); err != nil {
+		return err
+	}
+	m.walletSvc.markDeployed(ctx, op.Sender)
+
+	if op.BatchID != "" {
+		return m.settleBatch(ctx, op, receipt)
+	}
+	if op.ClaimID == "" {
+		return nil
+	}
+	if op.Kind == "transfer" && receipt.Success {
+		return transitionClaim(ctx, m.claimRepo, op.ClaimID, model.ClaimSubmitted, receipt.TxHash, "user operation "+op.UserOpHash+" included")
+	}
+	reason := fmt.Sprintf("%s op %s included, success=%t", op.Kind, op.UserOpHash, receipt.Success)
+	slog.WarnContext(ctx, "userop monitor: claim payout not delivered", "claim_id", op.ClaimID, "reason", reason)
+	if err := transitionClaim(ctx, m.claimRepo, op.ClaimID, model.ClaimFailed, receipt.TxHash, reason); err != nil {
+		return err
+	}
+	m.webhooks.PayoutFailed(ctx, op.ClaimID, reason)
+	return nil
+}
+
+// settleBatch resolves every claim paid by a batched settlement op
+func (m *UserOpMonitor) settleBatch(ctx context.Context, op *model.UserOperationRecord, receipt *UserOperationReceipt) error {
+	status, errMsg := "success", ""
+	if op.Kind != "transfer" || !receipt.Success {
+		status, errMsg = "failed", fmt.Sprintf("%s op %s included, success=%t", op.Kind, op.UserOpHash, receipt.Success)
+		slog.WarnContext(ctx, "userop monitor: batch payout not delivered", "batch_id", op.BatchID, "reason", errMsg)
+	}
+	if err := m.batchRepo.Settle(ctx, op.BatchID, status, receipt.TxHash, errMsg); err != nil {
+		return err
+	}
+	to := model.ClaimSubmitted
+	if status == "failed" {
+		to = model.ClaimFailed
+	}
+	refused, err := m.claimRepo.TransitionBatch(ctx, op.BatchID, to, receipt.TxHash, errMsg)
+	if err != nil {
+		return err
+	}
+	if refused > 0 {
+		slog.WarnContext(ctx, "userop monitor: claims of batch cannot move from their current status", "batch_id", op.BatchID, "claims", refused, "to", to)
+	}
+	if status == "failed" {
+		claimIDs, err := m.claimRepo.IDsByBatch(ctx, op.BatchID)
+		if err != nil {
+			return err
+		}
+		for _, id := range claimIDs {
+			m.webhooks.PayoutFailed(ctx, id, errMsg)
+		}
+	}
+	return nil
+}
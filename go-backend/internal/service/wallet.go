@@ -4,9 +4,13 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -18,17 +22,35 @@ import (
 )
 
 type WalletService struct {
-	repo     *repository.WalletRepository
-	cfg      *config.Config
-	aaClient *AAClient
+	repo       repository.WalletStore
+	userOpRepo *repository.UserOpRepository
+	cfg        *config.Config
+	aaClient   *AAClient
+
+	// Set by PaymasterMonitor when the sponsorship budget runs dry. In
+	// cluster mode they are kept in shared as well, and erc20Paymaster holds
+	// the last mode read from there.
+	erc20Paymaster  atomic.Bool
+	sponsorFailures atomic.Int64
+	shared          *repository.RedisClient
 }
 
-func NewWalletService(repo *repository.WalletRepository, cfg *config.Config) *WalletService {
+func NewWalletService(repo repository.WalletStore, userOpRepo *repository.UserOpRepository, cfg *config.Config) *WalletService {
 	var aaClient *AAClient
 	if cfg.BundlerURL != "" {
-		aaClient = NewAAClient(cfg.BundlerURL, cfg.PaymasterURL, cfg.EntryPoint)
+		aaClient = NewAAClient(cfg.BundlerURL, cfg.PaymasterURL, cfg.EntryPoint, cfg.EntryPointV07, cfg.SponsorshipPolicyID, cfg.DownstreamClaim)
 	}
-	return &WalletService{repo: repo, cfg: cfg, aaClient: aaClient}
+	return &WalletService{repo: repo, userOpRepo: userOpRepo, cfg: cfg, aaClient: aaClient}
+}
+
+// PendingUserOpError is returned when a user operation was submitted but not
+// included within the wait window. The UserOpMonitor keeps watching it.
+type PendingUserOpError struct {
+	UserOpHash string
+}
+
+func (e *PendingUserOpError) Error() string {
+	return "user operation pending: " + e.UserOpHash
 }
 
 func (s *WalletService) GetOrCreate(ctx context.Context, userID string, chainID int64) (*model.Wallet, error) {
@@ -61,12 +83,13 @@ func (s *WalletService) createAAWallet(ctx context.Context, userID string, chain
 	ownerAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
 	// Compute AA wallet address (counterfactual)
-	// In production, use actual AA SDK to compute this
-	aaAddress := s.computeAAAddress(ownerAddress, chainID)
+	aaAddress, err := s.computeAAAddress(ctx, ownerAddress, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute wallet address: %w", err)
+	}
 
-	// Encrypt private key before storing
-	encryptedKey := hex.EncodeToString(crypto.FromECDSA(privateKey))
-	// TODO: Use proper encryption (AES-GCM) with KMS in production
+	// The repository seals the key with the configured provider before storing it
+	ownerKey := hex.EncodeToString(crypto.FromECDSA(privateKey))
 
 	wallet := &model.Wallet{
 		ID:         "wallet_" + uuid.New().String()[:8],
@@ -75,8 +98,10 @@ func (s *WalletService) createAAWallet(ctx context.Context, userID string, chain
 		ChainID:    chainID,
 		Type:       "aa",
 		IsDeployed: false, // Will be deployed on first transaction
-		PrivateKey: encryptedKey,
+		PrivateKey: ownerKey,
 		CreatedAt:  time.Now(),
+
+		EntryPointVersion: s.entryPointVersion(chainID),
 	}
 
 	if err := s.repo.Create(ctx, wallet); err != nil {
@@ -86,47 +111,220 @@ func (s *WalletService) createAAWallet(ctx context.Context, userID string, chain
 	return wallet, nil
 }
 
-// Compute counterfactual AA wallet address
-func (s *WalletService) computeAAAddress(owner common.Address, chainID int64) common.Address {
-	// This is a simplified version
-	// In production, use the actual AA factory contract's getAddress method
-	// or compute using CREATE2 formula:
-	// address = keccak256(0xff ++ factory ++ salt ++ keccak256(initCode))[12:]
+// entryPointVersion is the EntryPoint new wallets on chainID are created for
+func (s *WalletService) entryPointVersion(chainID int64) string {
+	if s.cfg.EntryPointVersions[chainID] == EntryPointV07 {
+		return EntryPointV07
+	}
+	return EntryPointV06
+}
+
+// entryPointAddress is the EntryPoint a wallet's ops are signed for
+func (s *WalletService) entryPointAddress(wallet *model.Wallet) string {
+	if wallet.EntryPointVersion == EntryPointV07 {
+		return s.cfg.EntryPointV07
+	}
+	return s.cfg.EntryPoint
+}
+
+// computeAAAddress returns the address the factory deploys owner's wallet
+// at, asking the factory's getAddress. In simulation mode nothing is ever
+// deployed, so the address is only derived from owner.
+func (s *WalletService) computeAAAddress(ctx context.Context, owner common.Address, chainID int64) (common.Address, error) {
+	if s.Simulated() {
+		salt := big.NewInt(0) // Use 0 as default salt
+		data := append(owner.Bytes(), salt.Bytes()...)
+		hash := crypto.Keccak256(data)
+		return common.BytesToAddress(hash[12:]), nil
+	}
+
+	// getAddress(address owner, uint256 salt) selector: 0x8cb84e18, salt 0
+	data := "0x8cb84e18" +
+		hex.EncodeToString(common.LeftPadBytes(owner.Bytes(), 32)) +
+		hex.EncodeToString(make([]byte, 32))
+
+	result, err := callRPC(ctx, s.aaClient.httpClient, s.cfg.RPCUrl, "eth_call", map[string]string{
+		"to":   s.factoryAddress(s.entryPointVersion(chainID)),
+		"data": data,
+	}, "latest")
+	if err != nil {
+		return common.Address{}, err
+	}
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return common.Address{}, err
+	}
+	word, err := parseHexBytes("factory address", hexValue)
+	if err != nil || len(word) != 32 {
+		return common.Address{}, fmt.Errorf("invalid factory address: %s", hexValue)
+	}
+	return common.BytesToAddress(word), nil
+}
+
+// factoryAddress is the SimpleAccount factory wallets for an EntryPoint
+// version are deployed by
+func (s *WalletService) factoryAddress(version string) string {
+	if version == EntryPointV07 {
+		return s.cfg.AccountFactoryV07
+	}
+	return s.cfg.AccountFactory
+}
 
-	// For now, derive a deterministic address from owner
-	salt := big.NewInt(0) // Use 0 as default salt
-	data := append(owner.Bytes(), salt.Bytes()...)
-	hash := crypto.Keccak256(data)
+// Simulated reports whether transfers return fake hashes instead of hitting the chain
+func (s *WalletService) Simulated() bool {
+	return s.aaClient == nil || s.cfg.BundlerURL == ""
+}
 
-	return common.BytesToAddress(hash[12:])
+// simulated is Simulated for a transfer made under ctx: sandbox transfers
+// are always simulated
+func (s *WalletService) simulated(ctx context.Context) bool {
+	return s.Simulated() || sandboxFrom(ctx)
 }
 
 // Transfer tokens using AA (gasless)
 func (s *WalletService) TransferToken(ctx context.Context, wallet *model.Wallet, tokenAddress string, to string, amount *big.Int) (string, error) {
 	// Check if AA client is configured
-	if s.aaClient == nil || s.cfg.BundlerURL == "" {
+	if s.simulated(ctx) {
 		// Simulation mode - return fake tx hash
 		hash := crypto.Keccak256([]byte(fmt.Sprintf("%s:%s:%s:%d", wallet.Address, tokenAddress, amount.String(), time.Now().UnixNano())))
 		return "0x" + hex.EncodeToString(hash), nil
 	}
 
-	// Real AA transaction flow
-	return s.executeAATransaction(ctx, wallet, tokenAddress, to, amount)
+	// Real AA transaction flow; an empty token address sends the native token
+	if tokenAddress == "" {
+		return s.executeAATransaction(ctx, wallet, []string{to}, []*big.Int{amount}, []string{"0x"}, "")
+	}
+	transferCallData := BuildERC20TransferCallData(tokenAddress, to, amount)
+	return s.executeAATransaction(ctx, wallet, []string{tokenAddress}, nil, []string{transferCallData}, "")
 }
 
-// executeAATransaction performs a real ERC-4337 transaction via Pimlico
-func (s *WalletService) executeAATransaction(ctx context.Context, wallet *model.Wallet, tokenAddress string, to string, amount *big.Int) (string, error) {
+// BatchTransfer sends several token transfers from one wallet in a single
+// user operation. batchID links the operation to its settlement batch.
+func (s *WalletService) BatchTransfer(ctx context.Context, wallet *model.Wallet, tokenAddress string, recipients []string, amounts []*big.Int, batchID string) (string, error) {
+	return s.BatchPayout(ctx, wallet, tokenAddress, recipients, amounts, nil, batchID)
+}
+
+// BatchPayout is BatchTransfer with savings: after the transfers, the wallet
+// approves each vault for its deposits and deposits them for their receivers,
+// all in the same user operation.
+func (s *WalletService) BatchPayout(ctx context.Context, wallet *model.Wallet, tokenAddress string, recipients []string, amounts []*big.Int, deposits []*VaultDeposit, batchID string) (string, error) {
+	if len(recipients) != len(amounts) {
+		return "", errors.New("recipients and amounts length mismatch")
+	}
+	if tokenAddress == "" {
+		if len(deposits) > 0 {
+			return "", ErrNativeBatchTransfer
+		}
+		if len(recipients) != 1 {
+			return "", ErrNativeBatchTransfer
+		}
+		return s.TransferToken(ctx, wallet, tokenAddress, recipients[0], amounts[0])
+	}
+	if s.simulated(ctx) {
+		hash := crypto.Keccak256([]byte(fmt.Sprintf("%s:%s:%s:%d", wallet.Address, tokenAddress, batchID, time.Now().UnixNano())))
+		return "0x" + hex.EncodeToString(hash), nil
+	}
+
+	targets := make([]string, len(recipients), len(recipients)+2*len(deposits))
+	datas := make([]string, len(recipients), len(recipients)+2*len(deposits))
+	for i, to := range recipients {
+		targets[i] = tokenAddress
+		datas[i] = BuildERC20TransferCallData(tokenAddress, to, amounts[i])
+	}
+
+	// One approval per vault covering all of its deposits
+	approvals := make(map[string]*big.Int)
+	var vaults []string
+	for _, d := range deposits {
+		if _, ok := approvals[d.Vault.Address]; !ok {
+			approvals[d.Vault.Address] = new(big.Int)
+			vaults = append(vaults, d.Vault.Address)
+		}
+		approvals[d.Vault.Address].Add(approvals[d.Vault.Address], d.Amount)
+	}
+	for _, vault := range vaults {
+		targets = append(targets, tokenAddress)
+		datas = append(datas, BuildERC20ApproveCallData(vault, approvals[vault]))
+	}
+	for _, d := range deposits {
+		targets = append(targets, d.Vault.Address)
+		datas = append(datas, vaultDepositCallData(d.Vault, d.Amount, d.Receiver))
+	}
+	return s.executeAATransaction(ctx, wallet, targets, nil, datas, batchID)
+}
+
+// Execute makes arbitrary contract calls from a wallet in one user operation
+func (s *WalletService) Execute(ctx context.Context, wallet *model.Wallet, targets, datas []string) (string, error) {
+	if s.simulated(ctx) {
+		hash := crypto.Keccak256([]byte(fmt.Sprintf("%s:%s:%d", wallet.Address, strings.Join(datas, ","), time.Now().UnixNano())))
+		return "0x" + hex.EncodeToString(hash), nil
+	}
+	return s.executeAATransaction(ctx, wallet, targets, nil, datas, "")
+}
+
+// ExecuteValue makes one contract call from a wallet, sending value of the
+// native token with it
+func (s *WalletService) ExecuteValue(ctx context.Context, wallet *model.Wallet, target string, value *big.Int, data string) (string, error) {
+	if s.simulated(ctx) {
+		hash := crypto.Keccak256([]byte(fmt.Sprintf("%s:%s:%s:%d", wallet.Address, target, value.String(), time.Now().UnixNano())))
+		return "0x" + hex.EncodeToString(hash), nil
+	}
+	return s.executeAATransaction(ctx, wallet, []string{target}, []*big.Int{value}, []string{data}, "")
+}
+
+// executeAATransaction performs a real ERC-4337 transaction via Pimlico,
+// executing the given calls from the wallet. values carries native token
+// amounts per call (nil for none); only a single call can send value, as
+// executeBatch has no value argument.
+func (s *WalletService) executeAATransaction(ctx context.Context, wallet *model.Wallet, targets []string, values []*big.Int, datas []string, batchID string) (string, error) {
 	// 1. Get nonce for the AA wallet
-	nonce, err := s.aaClient.GetAccountNonce(ctx, wallet.Address)
+	nonce, err := s.aaClient.GetAccountNonce(ctx, wallet.Address, s.entryPointAddress(wallet))
 	if err != nil {
 		return "", fmt.Errorf("failed to get nonce: %w", err)
 	}
 
-	// 2. Build ERC20 transfer calldata
-	transferCallData := BuildERC20TransferCallData(tokenAddress, to, amount)
+	// 2. Gas is paid in USDC in ERC-20 paymaster mode, so the paymaster needs
+	// an allowance first. Native transfers pay their own gas instead, out of
+	// the native token the wallet holds rather than USDC it may not have.
+	erc20 := s.PaymasterMode(ctx) == PaymasterModeERC20
+	if erc20 && sendsValue(values) {
+		ctx = withSelfPaidGas(ctx)
+	} else if erc20 {
+		approved, err := s.hasPaymasterAllowance(ctx, wallet.Address)
+		if err != nil {
+			return "", fmt.Errorf("failed to check paymaster allowance: %w", err)
+		}
+		if !approved {
+			maxAllowance := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+			targets = append([]string{s.cfg.USDCAddress}, targets...)
+			datas = append([]string{BuildERC20ApproveCallData(s.cfg.ERC20PaymasterAddress, maxAllowance)}, datas...)
+			if values != nil {
+				values = append([]*big.Int{nil}, values...)
+			}
+		}
+	}
 
-	// 3. Build execute calldata (AA wallet's execute function)
-	executeCallData := BuildExecuteCallData(tokenAddress, big.NewInt(0), transferCallData)
+	// 3. Build execute calldata (AA wallet's execute or executeBatch function)
+	var executeCallData string
+	if len(targets) == 1 {
+		value := big.NewInt(0)
+		if values != nil && values[0] != nil {
+			value = values[0]
+		}
+		executeCallData, err = BuildExecuteCallData(targets[0], value, datas[0])
+	} else if wallet.EntryPointVersion == EntryPointV07 {
+		executeCallData, err = BuildExecuteBatchCallDataV07(targets, values, datas)
+	} else {
+		for _, v := range values {
+			if v != nil && v.Sign() > 0 {
+				return "", ErrNativeBatchTransfer
+			}
+		}
+		executeCallData, err = BuildExecuteBatchCallData(targets, datas)
+	}
+	if err != nil {
+		return "", err
+	}
 
 	// 4. Get current gas prices from network
 	maxFeePerGas := big.NewInt(1000000000)      // 1 gwei default
@@ -134,6 +332,7 @@ func (s *WalletService) executeAATransaction(ctx context.Context, wallet *model.
 
 	// 5. Build UserOperation
 	userOp := &UserOperation{
+		Version:              wallet.EntryPointVersion,
 		Sender:               wallet.Address,
 		Nonce:                fmt.Sprintf("0x%x", nonce),
 		InitCode:             "0x", // Empty if wallet already deployed
@@ -147,13 +346,18 @@ func (s *WalletService) executeAATransaction(ctx context.Context, wallet *model.
 		Signature:            "0x",
 	}
 
-	// 6. If wallet not deployed, add init code
+	// 6. If wallet not deployed, add init code (factory and factoryData in v0.7)
 	if !wallet.IsDeployed {
-		initCode, err := s.buildInitCode(wallet)
+		factory, factoryData, err := s.buildInitCode(wallet)
 		if err != nil {
 			return "", fmt.Errorf("failed to build init code: %w", err)
 		}
-		userOp.InitCode = initCode
+		if wallet.EntryPointVersion == EntryPointV07 {
+			userOp.InitCode = ""
+			userOp.Factory, userOp.FactoryData = factory, factoryData
+		} else {
+			userOp.InitCode = factory + strings.TrimPrefix(factoryData, "0x")
+		}
 	}
 
 	// 7. Estimate gas
@@ -162,45 +366,446 @@ func (s *WalletService) executeAATransaction(ctx context.Context, wallet *model.
 		return "", fmt.Errorf("failed to estimate gas: %w", err)
 	}
 
-	// 8. Get paymaster sponsorship (gasless for user)
-	userOp, err = s.aaClient.SponsorUserOperation(ctx, userOp, s.cfg.ChainID)
+	// 8-10. Sponsor, sign and send to bundler
+	userOpHash, err := s.submitUserOp(ctx, wallet, userOp, "transfer", 1, payoutClaimFrom(ctx), batchID)
+	if err != nil {
+		return "", err
+	}
+
+	// 11. Wait for receipt (with timeout)
+	txHash, err := s.aaClient.WaitForUserOperationReceipt(ctx, userOpHash, 60*time.Second)
+	if err != nil {
+		// Still in the mempool - the monitor bumps fees if it stays stuck
+		return userOpHash, &PendingUserOpError{UserOpHash: userOpHash}
+	}
+
+	// 12. Mark wallet as deployed if this was first tx
+	if !wallet.IsDeployed {
+		wallet.IsDeployed = true
+		_ = s.repo.UpdateDeployed(ctx, wallet.ID, true)
+	}
+
+	return txHash, nil
+}
+
+// submitUserOp gets paymaster sponsorship, signs and sends a user operation.
+// It is recorded before it is sent, so it can be replaced if it gets stuck
+// and a payout interrupted by a crash is known to have gone out.
+func (s *WalletService) submitUserOp(ctx context.Context, wallet *model.Wallet, userOp *UserOperation, kind string, attempt int, claimID, batchID string) (string, error) {
+	// Get paymaster sponsorship (gasless for user)
+	userOp, err := s.sponsor(ctx, userOp)
 	if err != nil {
 		return "", fmt.Errorf("failed to get sponsorship: %w", err)
 	}
 
-	// 9. Sign the UserOperation
-	userOp, err = SignUserOperation(userOp, wallet.PrivateKey, s.cfg.ChainID, s.cfg.EntryPoint)
+	// Sign the UserOperation
+	userOp, err = SignUserOperation(userOp, wallet.PrivateKey, s.cfg.ChainID, s.entryPointAddress(wallet))
 	if err != nil {
 		return "", fmt.Errorf("failed to sign user operation: %w", err)
 	}
 
-	// 10. Send to bundler
+	opHash, err := computeUserOpHash(userOp, s.cfg.ChainID, s.entryPointAddress(wallet))
+	if err != nil {
+		return "", err
+	}
+
+	opJSON, _ := json.Marshal(userOp)
+	record := &model.UserOperationRecord{
+		UserOpHash:  "0x" + hex.EncodeToString(opHash),
+		ClaimID:     claimID,
+		BatchID:     batchID,
+		Sender:      wallet.Address,
+		Nonce:       userOp.Nonce,
+		ChainID:     wallet.ChainID,
+		Kind:        kind,
+		Attempt:     attempt,
+		Op:          opJSON,
+		Status:      "pending",
+		SubmittedAt: time.Now(),
+	}
+	if err := s.userOpRepo.Create(ctx, record); err != nil {
+		return "", fmt.Errorf("failed to record user operation: %w", err)
+	}
+
+	// Send to bundler
 	userOpHash, err := s.aaClient.SendUserOperation(ctx, userOp)
 	if err != nil {
+		if err := s.userOpRepo.UpdateStatus(ctx, record.UserOpHash, "failed"); err != nil {
+			slog.ErrorContext(ctx, "wallet: failed to mark user operation failed", "user_op_hash", record.UserOpHash, "error", err)
+		}
 		return "", fmt.Errorf("failed to send user operation: %w", err)
 	}
+	if userOpHash != record.UserOpHash {
+		// Track the op under the hash the bundler knows it by
+		if err := s.userOpRepo.UpdateHash(ctx, record.UserOpHash, userOpHash); err != nil {
+			slog.ErrorContext(ctx, "wallet: failed to rename user operation", "user_op_hash", record.UserOpHash, "new_user_op_hash", userOpHash, "error", err)
+		}
+	}
 
-	// 11. Wait for receipt (with timeout)
-	txHash, err := s.aaClient.WaitForUserOperationReceipt(ctx, userOpHash, 60*time.Second)
+	return userOpHash, nil
+}
+
+type payoutClaimKey struct{}
+
+// withPayoutClaim marks ctx as paying out claimID, so the user operation
+// sent under it is recorded against the claim
+func withPayoutClaim(ctx context.Context, claimID string) context.Context {
+	return context.WithValue(ctx, payoutClaimKey{}, claimID)
+}
+
+func payoutClaimFrom(ctx context.Context) string {
+	claimID, _ := ctx.Value(payoutClaimKey{}).(string)
+	return claimID
+}
+
+type selfPaidGasKey struct{}
+
+// withSelfPaidGas marks ctx as sending a user operation whose sender pays
+// its gas in the native token, without a paymaster
+func withSelfPaidGas(ctx context.Context) context.Context {
+	return context.WithValue(ctx, selfPaidGasKey{}, true)
+}
+
+func selfPaidGasFrom(ctx context.Context) bool {
+	selfPaid, _ := ctx.Value(selfPaidGasKey{}).(bool)
+	return selfPaid
+}
+
+// sendsValue reports whether any call sends the native token
+func sendsValue(values []*big.Int) bool {
+	for _, v := range values {
+		if v != nil && v.Sign() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type sandboxKey struct{}
+
+// withSandbox marks ctx as moving a sandbox pocket's test funds, so
+// transfers sent under it are simulated even when the chain is configured
+func withSandbox(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sandboxKey{}, true)
+}
+
+func sandboxFrom(ctx context.Context) bool {
+	sandbox, _ := ctx.Value(sandboxKey{}).(bool)
+	return sandbox
+}
+
+// ReplaceUserOp re-sends a stuck user operation with the same nonce and fees
+// raised by bumpPercent. With cancel set the replacement is a no-op self call,
+// which voids the original payout once included.
+func (s *WalletService) ReplaceUserOp(ctx context.Context, rec *model.UserOperationRecord, cancel bool, bumpPercent int) (string, error) {
+	if s.Simulated() {
+		return "", errors.New("user operations are not available in simulation mode")
+	}
+
+	var userOp UserOperation
+	if err := json.Unmarshal(rec.Op, &userOp); err != nil {
+		return "", fmt.Errorf("invalid stored user operation: %w", err)
+	}
+	wallet, err := s.repo.GetByAddress(ctx, rec.Sender)
 	if err != nil {
-		// Return userOpHash even if we timeout - tx might still succeed
-		return userOpHash, fmt.Errorf("waiting for receipt: %w (userOpHash: %s)", err, userOpHash)
+		return "", fmt.Errorf("wallet not found: %w", err)
 	}
 
-	// 12. Mark wallet as deployed if this was first tx
-	if !wallet.IsDeployed {
-		wallet.IsDeployed = true
+	if !userOp.Sponsored() {
+		ctx = withSelfPaidGas(ctx)
+	}
+	userOp.Version = wallet.EntryPointVersion
+	userOp.MaxFeePerGas = bumpHexQuantity(userOp.MaxFeePerGas, bumpPercent)
+	userOp.MaxPriorityFeePerGas = bumpHexQuantity(userOp.MaxPriorityFeePerGas, bumpPercent)
+	userOp.ClearPaymaster()
+	userOp.Signature = "0x"
+
+	kind := rec.Kind
+	if cancel {
+		kind = "cancel"
+		if userOp.CallData, err = BuildExecuteCallData(rec.Sender, big.NewInt(0), "0x"); err != nil {
+			return "", err
+		}
+	}
+
+	return s.submitUserOp(ctx, wallet, &userOp, kind, rec.Attempt+1, rec.ClaimID, rec.BatchID)
+}
+
+// Paymaster modes
+const (
+	PaymasterModeSponsored = "sponsored"
+	PaymasterModeERC20     = "erc20"
+)
+
+// Redis keys for the paymaster state in cluster mode
+const (
+	paymasterModeKey   = "paymaster:mode"
+	sponsorFailuresKey = "paymaster:sponsor_failures"
+)
+
+// SharePaymasterState keeps the paymaster mode and sponsorship failures in
+// Redis, so the monitor on one replica sees every replica's failures and
+// switches all of them
+func (s *WalletService) SharePaymasterState(redis *repository.RedisClient) {
+	s.shared = redis
+}
+
+func (s *WalletService) PaymasterMode(ctx context.Context) string {
+	if s.shared != nil {
+		if mode, err := s.shared.GetShared(ctx, paymasterModeKey); err != nil {
+			slog.WarnContext(ctx, "wallet: failed to read shared paymaster mode, keeping the local one", "mode", s.localPaymasterMode(), "error", err)
+		} else {
+			s.erc20Paymaster.Store(mode == PaymasterModeERC20 && s.cfg.ERC20PaymasterAddress != "")
+		}
+	}
+	return s.localPaymasterMode()
+}
+
+func (s *WalletService) localPaymasterMode() string {
+	if s.erc20Paymaster.Load() {
+		return PaymasterModeERC20
+	}
+	return PaymasterModeSponsored
+}
+
+// SetPaymasterMode switches new user operations between the sponsorship policy and the ERC-20 paymaster
+func (s *WalletService) SetPaymasterMode(ctx context.Context, mode string) {
+	erc20 := mode == PaymasterModeERC20 && s.cfg.ERC20PaymasterAddress != ""
+	s.erc20Paymaster.Store(erc20)
+	if s.shared == nil {
+		return
+	}
+	if !erc20 {
+		mode = PaymasterModeSponsored
+	}
+	if err := s.shared.SetShared(ctx, paymasterModeKey, mode); err != nil {
+		slog.WarnContext(ctx, "wallet: failed to share paymaster mode", "mode", mode, "error", err)
+	}
+}
+
+// takeSponsorFailures returns and resets the sponsorship failure count
+func (s *WalletService) takeSponsorFailures(ctx context.Context) int64 {
+	failures := s.sponsorFailures.Swap(0)
+	if s.shared != nil {
+		n, err := s.shared.TakeShared(ctx, sponsorFailuresKey)
+		if err != nil {
+			slog.WarnContext(ctx, "wallet: failed to read shared sponsorship failures", "error", err)
+		}
+		failures += n
+	}
+	return failures
+}
+
+func (s *WalletService) countSponsorFailure(ctx context.Context) {
+	if s.shared != nil {
+		err := s.shared.IncrementShared(ctx, sponsorFailuresKey)
+		if err == nil {
+			return
+		}
+		slog.WarnContext(ctx, "wallet: failed to share sponsorship failure", "error", err)
+	}
+	s.sponsorFailures.Add(1)
+}
+
+// sponsor gets paymasterAndData in the active mode. In ERC-20 mode the
+// sponsorship policy is still tried if the token paymaster refuses, e.g. for
+// replacement ops built before the switch.
+func (s *WalletService) sponsor(ctx context.Context, userOp *UserOperation) (*UserOperation, error) {
+	if selfPaidGasFrom(ctx) {
+		return userOp, nil
+	}
+	if s.PaymasterMode(ctx) == PaymasterModeERC20 {
+		op, err := s.aaClient.SponsorUserOperationERC20(ctx, userOp, s.cfg.USDCAddress)
+		if err == nil {
+			return op, nil
+		}
+		slog.WarnContext(ctx, "wallet: erc20 paymaster refused op, trying sponsorship policy", "sender", userOp.Sender, "error", err)
+	}
+
+	op, err := s.aaClient.SponsorUserOperation(ctx, userOp, s.cfg.ChainID)
+	if err != nil {
+		s.countSponsorFailure(ctx)
+		return nil, err
+	}
+	return op, nil
+}
+
+// hasPaymasterAllowance reports whether owner already approved the ERC-20 paymaster for USDC
+func (s *WalletService) hasPaymasterAllowance(ctx context.Context, owner string) (bool, error) {
+	// allowance(address,address) selector: 0xdd62ed3e
+	data := "0xdd62ed3e" +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)) +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(s.cfg.ERC20PaymasterAddress).Bytes(), 32))
+
+	result, err := callRPC(ctx, s.aaClient.httpClient, s.cfg.RPCUrl, "eth_call", map[string]string{
+		"to":   s.cfg.USDCAddress,
+		"data": data,
+	}, "latest")
+	if err != nil {
+		return false, err
+	}
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return false, err
+	}
+	allowance, _ := new(big.Int).SetString(strings.TrimPrefix(hexValue, "0x"), 16)
+	// Anything short of a large standing approval gets re-approved
+	return allowance != nil && allowance.BitLen() > 128, nil
+}
+
+// TokenBalance reads owner's ERC-20 balance of tokenAddress on chain
+func (s *WalletService) TokenBalance(ctx context.Context, tokenAddress, owner string) (*big.Int, error) {
+	return s.TokenBalanceAt(ctx, tokenAddress, owner, "latest")
+}
+
+// TokenBalanceAt reads owner's ERC-20 balance of tokenAddress as of block,
+// a hex block number or tag
+func (s *WalletService) TokenBalanceAt(ctx context.Context, tokenAddress, owner, block string) (*big.Int, error) {
+	// balanceOf(address) selector: 0x70a08231
+	data := "0x70a08231" + hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32))
+
+	result, err := callRPC(ctx, s.aaClient.httpClient, s.cfg.RPCUrl, "eth_call", map[string]string{
+		"to":   tokenAddress,
+		"data": data,
+	}, block)
+	if err != nil {
+		return nil, err
+	}
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(hexValue, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance: %s", hexValue)
+	}
+	return balance, nil
+}
+
+// nativePayoutGas is the gas a payout user operation is given before it is
+// estimated: its call, verification and pre-verification gas
+const nativePayoutGas = 0x50000 + 0x50000 + 0xc350
+
+// GasPrice reads the chain's current gas price in wei
+func (s *WalletService) GasPrice(ctx context.Context) (*big.Int, error) {
+	result, err := callRPC(ctx, s.aaClient.httpClient, s.cfg.RPCUrl, "eth_gasPrice")
+	if err != nil {
+		return nil, err
+	}
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return nil, err
+	}
+	price, ok := new(big.Int).SetString(strings.TrimPrefix(hexValue, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid gas price: %s", hexValue)
+	}
+	return price, nil
+}
+
+// NativePayoutFee is what a native token payout paying its own gas costs,
+// at twice the current gas price to leave room for it to rise
+func (s *WalletService) NativePayoutFee(ctx context.Context) (*big.Int, error) {
+	price, err := s.GasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return price.Mul(price, big.NewInt(2*nativePayoutGas)), nil
+}
+
+// NativeBalance reads owner's balance of the chain's native token
+func (s *WalletService) NativeBalance(ctx context.Context, owner string) (*big.Int, error) {
+	return s.NativeBalanceAt(ctx, owner, "latest")
+}
+
+// NativeBalanceAt reads owner's native balance as of block, a hex block
+// number or tag
+func (s *WalletService) NativeBalanceAt(ctx context.Context, owner, block string) (*big.Int, error) {
+	result, err := callRPC(ctx, s.aaClient.httpClient, s.cfg.RPCUrl, "eth_getBalance", owner, block)
+	if err != nil {
+		return nil, err
+	}
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(hexValue, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance: %s", hexValue)
+	}
+	return balance, nil
+}
+
+// BlockNumber returns the chain's latest block number
+func (s *WalletService) BlockNumber(ctx context.Context) (int64, error) {
+	result, err := callRPC(ctx, s.aaClient.httpClient, s.cfg.RPCUrl, "eth_blockNumber")
+	if err != nil {
+		return 0, err
+	}
+	var hexValue string
+	if err := json.Unmarshal(result, &hexValue); err != nil {
+		return 0, err
+	}
+	return parseHexInt64(hexValue)
+}
+
+// markDeployed records that the wallet at address now exists on chain
+func (s *WalletService) markDeployed(ctx context.Context, address string) {
+	wallet, err := s.repo.GetByAddress(ctx, address)
+	if err == nil && !wallet.IsDeployed {
 		_ = s.repo.UpdateDeployed(ctx, wallet.ID, true)
 	}
+}
 
-	return txHash, nil
+// UserOpOutcome reports how the nonce of a submitted user operation was
+// settled, following any fee-bumped replacements: pending, success or failed.
+// A landed cancellation counts as failed since nothing was transferred.
+func (s *WalletService) UserOpOutcome(ctx context.Context, userOpHash string) (status, txHash string, err error) {
+	rec, err := s.userOpRepo.GetByHash(ctx, userOpHash)
+	if err != nil {
+		return "", "", err
+	}
+	siblings, err := s.userOpRepo.ListByNonce(ctx, rec.Sender, rec.Nonce)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, sib := range siblings {
+		if sib.Status != "included" {
+			continue
+		}
+		if sib.Kind == "transfer" {
+			return "success", sib.TxHash, nil
+		}
+		return "failed", sib.TxHash, nil
+	}
+	// Ops the monitor gave up on may still land, so they stay pending
+	return "pending", "", nil
 }
 
-// buildInitCode builds the init code for deploying a new AA wallet
-func (s *WalletService) buildInitCode(wallet *model.Wallet) (string, error) {
-	// SimpleAccount factory address on Base
-	// This is the standard ERC-4337 SimpleAccount factory
-	factoryAddress := "0x9406Cc6185a346906296840746125a0E44976454"
+// AttachUserOp links a submitted user operation to the claim it pays out
+func (s *WalletService) AttachUserOp(ctx context.Context, userOpHash, claimID string) error {
+	return s.userOpRepo.AttachClaim(ctx, userOpHash, claimID)
+}
+
+// bumpHexQuantity raises a hex quantity by percent; bundlers require at least 10%
+func bumpHexQuantity(hexValue string, percent int) string {
+	if percent < 10 {
+		percent = 10
+	}
+	value, ok := new(big.Int).SetString(strings.TrimPrefix(hexValue, "0x"), 16)
+	if !ok {
+		value = big.NewInt(0)
+	}
+	value.Mul(value, big.NewInt(int64(100+percent)))
+	value.Div(value, big.NewInt(100))
+	return fmt.Sprintf("0x%x", value)
+}
+
+// buildInitCode builds the factory and factory calldata deploying a new AA
+// wallet. v0.6 init code is the two concatenated.
+func (s *WalletService) buildInitCode(wallet *model.Wallet) (string, string, error) {
+	factoryAddress := s.factoryAddress(wallet.EntryPointVersion)
 
 	// createAccount(address owner, uint256 salt) selector: 0x5fbfb9cf
 	methodID := "5fbfb9cf"
@@ -208,11 +813,11 @@ func (s *WalletService) buildInitCode(wallet *model.Wallet) (string, error) {
 	// Decode owner address from wallet's private key
 	privateKeyBytes, err := hex.DecodeString(wallet.PrivateKey)
 	if err != nil {
-		return "", fmt.Errorf("invalid private key: %w", err)
+		return "", "", fmt.Errorf("invalid private key: %w", err)
 	}
 	privateKey, err := crypto.ToECDSA(privateKeyBytes)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse private key: %w", err)
+		return "", "", fmt.Errorf("failed to parse private key: %w", err)
 	}
 	ownerAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
@@ -222,12 +827,15 @@ func (s *WalletService) buildInitCode(wallet *model.Wallet) (string, error) {
 	// Salt = 0
 	salt := common.LeftPadBytes(big.NewInt(0).Bytes(), 32)
 
-	// InitCode = factory address + calldata
-	initCode := factoryAddress + methodID + hex.EncodeToString(paddedOwner) + hex.EncodeToString(salt)
+	factoryData := "0x" + methodID + hex.EncodeToString(paddedOwner) + hex.EncodeToString(salt)
 
-	return initCode, nil
+	return factoryAddress, factoryData, nil
 }
 
 func (s *WalletService) GetByUserID(ctx context.Context, userID string, chainID int64) (*model.Wallet, error) {
 	return s.repo.GetByUserID(ctx, userID, chainID)
 }
+
+func (s *WalletService) GetByAddress(ctx context.Context, address string) (*model.Wallet, error) {
+	return s.repo.GetByAddress(ctx, address)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+// WalletOverviewService puts together what a user holds across chains: the
+// AA wallet they have on each, its balances, and their claims per chain
+type WalletOverviewService struct {
+	walletRepo  repository.WalletStore
+	claimRepo   repository.ClaimStore
+	hyperbridge *HyperbridgeService
+}
+
+func NewWalletOverviewService(walletRepo repository.WalletStore, claimRepo repository.ClaimStore, hyperbridge *HyperbridgeService) *WalletOverviewService {
+	return &WalletOverviewService{
+		walletRepo:  walletRepo,
+		claimRepo:   claimRepo,
+		hyperbridge: hyperbridge,
+	}
+}
+
+// WalletOverview is a user's wallets with their balances, and their claims
+// summed per chain and token
+type WalletOverview struct {
+	UserID  string                     `json:"userId"`
+	Wallets []*ChainWallet             `json:"wallets"`
+	Claims  []*model.ChainClaimSummary `json:"claims"`
+}
+
+// ChainWallet is a user's wallet on one chain with the balance of each
+// asset available there; chains the bridge does not cover have none
+type ChainWallet struct {
+	*model.Wallet
+	Balances []MultiChainBalance `json:"balances"`
+}
+
+// Overview lists a user's wallets, reading their balances on all chains at
+// once, and summarises their pending and confirmed claims per chain
+func (s *WalletOverviewService) Overview(ctx context.Context, userID string) (*WalletOverview, error) {
+	wallets, err := s.walletRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	claims, err := s.claimRepo.ClaimerChainSummary(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarise claims: %w", err)
+	}
+
+	accounts := make(map[ChainID]string, len(wallets))
+	for _, w := range wallets {
+		accounts[ChainID(w.ChainID)] = w.Address
+	}
+	byChain := make(map[ChainID][]MultiChainBalance, len(wallets))
+	for _, b := range s.hyperbridge.GetAccountBalances(ctx, accounts) {
+		byChain[b.ChainID] = append(byChain[b.ChainID], b)
+	}
+
+	overview := &WalletOverview{
+		UserID:  userID,
+		Wallets: make([]*ChainWallet, 0, len(wallets)),
+		Claims:  claims,
+	}
+	for _, w := range wallets {
+		balances := byChain[ChainID(w.ChainID)]
+		if balances == nil {
+			balances = []MultiChainBalance{}
+		}
+		overview.Wallets = append(overview.Wallets, &ChainWallet{Wallet: w, Balances: balances})
+	}
+	return overview, nil
+}
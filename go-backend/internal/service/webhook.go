@@ -0,0 +1,393 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrWebhookNotFound   = newCodedError("webhook_not_found")
+	ErrInvalidWebhookURL = newCodedError("invalid_webhook_url")
+)
+
+// Webhook event types
+const (
+	WebhookPocketCreated  = "pocket.created"
+	WebhookPocketClaimed  = "pocket.claimed"
+	WebhookPocketDepleted = "pocket.depleted"
+	WebhookPocketExpired  = "pocket.expired"
+	WebhookPocketExpiring = "pocket.expiring"
+	WebhookPocketResized  = "pocket.resized"
+	WebhookPayoutFailed   = "payout.failed"
+	WebhookCampaignAlert  = "campaign.alert"
+)
+
+const (
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 20
+	// A delivery still sending after this was dropped by a worker that died
+	webhookStuckAfter = 5 * time.Minute
+)
+
+// WebhookService tells enterprises about events on their campaigns' pockets.
+// Each event is stored as one delivery per subscribed webhook and POSTed by
+// workers on every instance, signed with the webhook's secret. Failed
+// deliveries are retried with exponential backoff up to WEBHOOK_MAX_ATTEMPTS.
+type WebhookService struct {
+	repo       *repository.WebhookRepository
+	claimRepo  repository.ClaimStore
+	rpRepo     repository.RedPocketStore
+	httpClient *http.Client
+	cfg        *config.Config
+	wake       chan struct{}
+}
+
+func NewWebhookService(
+	repo *repository.WebhookRepository,
+	claimRepo repository.ClaimStore,
+	rpRepo repository.RedPocketStore,
+	cfg *config.Config,
+) *WebhookService {
+	return &WebhookService{
+		repo:      repo,
+		claimRepo: claimRepo,
+		rpRepo:    rpRepo,
+		httpClient: &http.Client{
+			Timeout: time.Duration(cfg.WebhookTimeout) * time.Second,
+			// A redirect counts as a failed delivery rather than being followed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:  cfg,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=pocket.created pocket.claimed pocket.depleted pocket.expired pocket.expiring pocket.resized payout.failed campaign.alert"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=128"` // generated on create when empty; kept on update
+	Description string   `json:"description" binding:"max=255"`
+	Enabled     *bool    `json:"enabled"` // default true
+}
+
+// Create registers a webhook. The secret deliveries are signed with is only
+// returned here.
+func (s *WebhookService) Create(ctx context.Context, enterpriseID string, req *WebhookRequest) (*model.Webhook, error) {
+	if err := checkWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+	}
+
+	now := time.Now()
+	w := &model.Webhook{
+		ID:           "webhook_" + uuid.New().String()[:8],
+		EnterpriseID: enterpriseID,
+		URL:          req.URL,
+		Secret:       secret,
+		Events:       dedupeStrings(req.Events),
+		Description:  req.Description,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.Create(ctx, w); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return w, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, enterpriseID string) ([]*model.Webhook, error) {
+	return s.repo.List(ctx, enterpriseID)
+}
+
+func (s *WebhookService) Get(ctx context.Context, enterpriseID, id string) (*model.Webhook, error) {
+	w, err := s.repo.GetByID(ctx, id, enterpriseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook: %w", err)
+	}
+	return w, nil
+}
+
+// Update replaces a webhook's settings. The secret is rotated only when a new
+// one is given.
+func (s *WebhookService) Update(ctx context.Context, enterpriseID, id string, req *WebhookRequest) (*model.Webhook, error) {
+	if err := checkWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	w := &model.Webhook{
+		ID:           id,
+		EnterpriseID: enterpriseID,
+		URL:          req.URL,
+		Secret:       req.Secret,
+		Events:       dedupeStrings(req.Events),
+		Description:  req.Description,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+		UpdatedAt:    time.Now(),
+	}
+	ok, err := s.repo.Update(ctx, w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	return s.Get(ctx, enterpriseID, id)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, enterpriseID, id string) error {
+	ok, err := s.repo.Delete(ctx, id, enterpriseID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if !ok {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Deliveries returns a webhook's delivery log, newest first
+func (s *WebhookService) Deliveries(ctx context.Context, enterpriseID, id, status string, page, limit int) ([]*model.WebhookDelivery, int64, error) {
+	if _, err := s.Get(ctx, enterpriseID, id); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+	return s.repo.ListDeliveries(ctx, id, status, limit, offset)
+}
+
+// checkWebhookURL only allows plain http(s) endpoints
+func checkWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Dispatch queues an event of a campaign for each of its enterprise's
+// webhooks subscribed to eventType. It is best effort: failures are logged,
+// never returned, so they cannot fail the claim or job raising the event.
+func (s *WebhookService) Dispatch(ctx context.Context, campaignID, eventType string, data interface{}) {
+	ids, err := s.repo.Subscribed(ctx, campaignID, eventType)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to find webhooks", "event_type", eventType, "campaign_id", campaignID, "error", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	now := time.Now()
+	event := &model.WebhookEvent{
+		ID:        "evt_" + uuid.New().String()[:8],
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to encode event", "event_type", eventType, "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		d := &model.WebhookDelivery{
+			ID:            "whd_" + uuid.New().String()[:8],
+			WebhookID:     id,
+			EventID:       event.ID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        "pending",
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := s.repo.CreateDelivery(ctx, d); err != nil {
+			slog.ErrorContext(ctx, "webhooks: failed to queue event", "event_id", event.ID, "webhook_id", id, "error", err)
+		}
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// PocketEvent dispatches an event about a pocket
+func (s *WebhookService) PocketEvent(ctx context.Context, eventType string, rp *model.RedPocket, claim *model.Claim) {
+	data := map[string]interface{}{"redPocket": rp}
+	if claim != nil {
+		data["claim"] = claim
+	}
+	s.Dispatch(ctx, rp.CampaignID, eventType, data)
+}
+
+// PayoutFailed dispatches payout.failed for a claim whose payout gave up
+func (s *WebhookService) PayoutFailed(ctx context.Context, claimID, reason string) {
+	claim, err := s.claimRepo.GetByID(ctx, claimID)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to load claim", "claim_id", claimID, "error", err)
+		return
+	}
+	rp, err := s.rpRepo.GetByID(ctx, claim.RedPocketID)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to load red pocket", "red_pocket_id", claim.RedPocketID, "error", err)
+		return
+	}
+	s.Dispatch(ctx, rp.CampaignID, WebhookPayoutFailed, map[string]interface{}{
+		"claim":  claim,
+		"reason": reason,
+	})
+}
+
+// Start delivers queued events until ctx is cancelled
+func (s *WebhookService) Start(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *WebhookService) poll(ctx context.Context) {
+	deliveries, err := s.repo.DequeueDeliveries(ctx, webhookBatchSize, webhookStuckAfter)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to dequeue deliveries", "error", err)
+		return
+	}
+
+	// One slow endpoint should not hold up the others
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func(d *model.WebhookDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, d)
+		}(d)
+	}
+	wg.Wait()
+}
+
+// deliver POSTs one delivery and records the outcome. Any 2xx response is a
+// success; anything else is retried after WEBHOOK_RETRY_DELAY, doubled per
+// attempt, until the attempts run out.
+func (s *WebhookService) deliver(ctx context.Context, d *model.WebhookDelivery) {
+	status, err := s.send(ctx, d)
+	if err == nil {
+		if err := s.repo.FinishDelivery(ctx, d.ID, "success", status, "", d.NextAttemptAt); err != nil {
+			slog.ErrorContext(ctx, "webhooks: failed to record delivery", "delivery_id", d.ID, "error", err)
+		}
+		return
+	}
+
+	result, next := "pending", time.Now().Add(time.Duration(s.cfg.WebhookRetryDelay)*time.Second<<(d.Attempts-1))
+	if d.Attempts >= s.cfg.WebhookMaxAttempts {
+		result, next = "failed", d.NextAttemptAt
+		slog.WarnContext(ctx, "webhooks: delivery failed", "delivery_id", d.ID, "event_type", d.EventType, "webhook_id", d.WebhookID, "attempts", d.Attempts, "error", err)
+	}
+	if err := s.repo.FinishDelivery(ctx, d.ID, result, status, err.Error(), next); err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to record delivery", "delivery_id", d.ID, "error", err)
+	}
+}
+
+// send POSTs the event. The X-RedPocket-Signature header is
+// sha256=HMAC-SHA256(secret, timestamp + "." + body) in hex, with the
+// timestamp from X-RedPocket-Timestamp, so receivers can check both the
+// sender and the age of the request.
+func (s *WebhookService) send(ctx context.Context, d *model.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "RedPocket-Webhooks/1.0")
+	req.Header.Set("X-RedPocket-Event", d.EventType)
+	req.Header.Set("X-RedPocket-Event-Id", d.EventID)
+	req.Header.Set("X-RedPocket-Delivery", d.ID)
+	req.Header.Set("X-RedPocket-Timestamp", timestamp)
+	req.Header.Set("X-RedPocket-Signature", "sha256="+signWebhook(d.Secret, timestamp, d.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func signWebhook(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,599 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/i18n"
+	"github.com/protocolbank/redpocket-backend/internal/model"
+	"github.com/protocolbank/redpocket-backend/internal/repository"
+)
+
+var (
+	ErrEmailNotConfigured       = newCodedError("email_not_configured")
+	ErrReportSubscriberNotFound = newCodedError("report_subscriber_not_found")
+	ErrInvalidSubscriptionLink  = newCodedError("invalid_subscription_link")
+	ErrInvalidReportWeek        = newCodedError("invalid_report_week")
+)
+
+const (
+	weeklyReportSweepInterval = 15 * time.Minute
+	weeklyReportSweepBatch    = 20
+	weeklyReportTopCampaigns  = 5
+)
+
+// WeeklyReportService emails enterprises' weekly engagement reports: claims,
+// unique claimers, amount claimed and pockets created against the week
+// before, claims by day and platform, and the top campaigns. Reports are
+// built from the analytics rollups and go out from WEEKLY_REPORT_HOUR on
+// Mondays (UTC) to the addresses the enterprise added that confirmed they
+// want them. Every report links to unsubscribe.
+type WeeklyReportService struct {
+	repo           *repository.WeeklyReportRepository
+	analytics      *repository.AnalyticsRepository
+	enterpriseRepo *repository.EnterpriseRepository
+	mailer         Mailer
+	secret         []byte
+	cfg            *config.Config
+}
+
+func NewWeeklyReportService(repo *repository.WeeklyReportRepository, analytics *repository.AnalyticsRepository, enterpriseRepo *repository.EnterpriseRepository, mailer Mailer, cfg *config.Config) *WeeklyReportService {
+	secret := cfg.ReportURLSecret
+	if secret == "" {
+		secret = cfg.JWTSecret
+	}
+	return &WeeklyReportService{
+		repo:           repo,
+		analytics:      analytics,
+		enterpriseRepo: enterpriseRepo,
+		mailer:         mailer,
+		secret:         []byte(secret),
+		cfg:            cfg,
+	}
+}
+
+type ReportSubscriberRequest struct {
+	Email  string `json:"email" binding:"required,email,max=255"`
+	Locale string `json:"locale"` // default the request's language
+}
+
+// ListSubscribers returns the addresses the enterprise has added
+func (s *WeeklyReportService) ListSubscribers(ctx context.Context, enterpriseID string) ([]*model.ReportSubscriber, error) {
+	subscribers, err := s.repo.ListSubscribers(ctx, enterpriseID)
+	if subscribers == nil && err == nil {
+		subscribers = []*model.ReportSubscriber{}
+	}
+	return subscribers, err
+}
+
+// AddSubscriber adds an address to the enterprise's weekly report and
+// emails it a link to confirm. Addresses that have already confirmed are
+// left as they are.
+func (s *WeeklyReportService) AddSubscriber(ctx context.Context, enterpriseID string, req *ReportSubscriberRequest) (*model.ReportSubscriber, error) {
+	// Links in emails have to be absolute
+	if s.mailer == nil || s.cfg.APIBaseURL == "" {
+		return nil, ErrEmailNotConfigured
+	}
+	locale := i18n.Normalize(req.Locale)
+	if locale == "" {
+		locale = i18n.FromContext(ctx)
+	}
+	sub, err := s.repo.AddSubscriber(ctx, &model.ReportSubscriber{
+		ID:           "rsub_" + uuid.New().String()[:8],
+		EnterpriseID: enterpriseID,
+		Email:        strings.ToLower(strings.TrimSpace(req.Email)),
+		Locale:       locale,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status != "pending" {
+		return sub, nil
+	}
+
+	e, err := s.enterpriseRepo.GetByID(ctx, enterpriseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load enterprise: %w", err)
+	}
+	link := s.subscriberURL(sub.ID, "confirm")
+	intro := i18n.T(sub.Locale, "report.confirm_body", e.Name)
+	action := i18n.T(sub.Locale, "report.confirm")
+	html, err := renderReportNotice(sub.Locale, intro, action, link)
+	if err != nil {
+		return nil, err
+	}
+	err = s.mailer.Send(ctx, &Email{
+		To:      sub.Email,
+		Subject: i18n.T(sub.Locale, "report.confirm_subject", e.Name),
+		Text:    intro + "\n\n" + action + ": " + link,
+		HTML:    html,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send confirmation: %w", err)
+	}
+	return sub, nil
+}
+
+// RemoveSubscriber removes one of the enterprise's addresses
+func (s *WeeklyReportService) RemoveSubscriber(ctx context.Context, enterpriseID, id string) error {
+	ok, err := s.repo.DeleteSubscriber(ctx, enterpriseID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReportSubscriberNotFound
+	}
+	return nil
+}
+
+// Confirm activates a subscriber through the signed link emailed to it, and
+// returns the page to show
+func (s *WeeklyReportService) Confirm(ctx context.Context, id, sig string) (string, error) {
+	sub, name, err := s.linkSubscriber(ctx, id, "confirm", sig)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.repo.Confirm(ctx, sub.ID); err != nil {
+		return "", err
+	}
+	return renderReportNotice(sub.Locale, i18n.T(sub.Locale, "report.confirmed", name), "", "")
+}
+
+// Unsubscribe stops a subscriber's reports through the signed link in each
+// report, and returns the page to show
+func (s *WeeklyReportService) Unsubscribe(ctx context.Context, id, sig string) (string, error) {
+	sub, name, err := s.linkSubscriber(ctx, id, "unsubscribe", sig)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.repo.Unsubscribe(ctx, sub.ID); err != nil {
+		return "", err
+	}
+	return renderReportNotice(sub.Locale, i18n.T(sub.Locale, "report.unsubscribed", name), "", "")
+}
+
+// linkSubscriber checks a subscriber link's signature and returns the
+// subscriber and its enterprise's name
+func (s *WeeklyReportService) linkSubscriber(ctx context.Context, id, action, sig string) (*model.ReportSubscriber, string, error) {
+	given, err := hex.DecodeString(sig)
+	if err != nil {
+		return nil, "", ErrInvalidSubscriptionLink
+	}
+	want, _ := hex.DecodeString(s.sign(id, action))
+	if !hmac.Equal(given, want) {
+		return nil, "", ErrInvalidSubscriptionLink
+	}
+	sub, err := s.repo.GetSubscriber(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", ErrReportSubscriberNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	e, err := s.enterpriseRepo.GetByID(ctx, sub.EnterpriseID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load enterprise: %w", err)
+	}
+	return sub, e.Name, nil
+}
+
+func (s *WeeklyReportService) sign(id, action string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(action + "." + id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// subscriberURL is a subscriber's signed confirm or unsubscribe link. They
+// do not expire, so an old report can still be unsubscribed from.
+func (s *WeeklyReportService) subscriberURL(id, action string) string {
+	return strings.TrimRight(s.cfg.APIBaseURL, "/") + "/api/v1/reports/subscribers/" + id + "/" + action + "?sig=" + s.sign(id, action)
+}
+
+// weekStart is the Monday (UTC) starting t's week
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Report returns the enterprise's report of the week containing week (a
+// date, YYYY-MM-DD), by default the last complete week
+func (s *WeeklyReportService) Report(ctx context.Context, enterpriseID, week string) (*model.WeeklyReport, error) {
+	current := weekStart(time.Now())
+	start := current.AddDate(0, 0, -7)
+	if week != "" {
+		day, err := time.Parse("2006-01-02", week)
+		if err != nil {
+			return nil, ErrInvalidReportWeek
+		}
+		if start = weekStart(day); !start.Before(current) {
+			return nil, ErrInvalidReportWeek
+		}
+	}
+	return s.build(ctx, enterpriseID, start)
+}
+
+// RenderReport renders a report as the HTML email its subscribers receive,
+// without an unsubscribe link when unsubscribeURL is empty
+func (s *WeeklyReportService) RenderReport(locale string, report *model.WeeklyReport, unsubscribeURL string) (string, error) {
+	return renderWeeklyReport(locale, report, unsubscribeURL)
+}
+
+func (s *WeeklyReportService) build(ctx context.Context, enterpriseID string, week time.Time) (*model.WeeklyReport, error) {
+	e, err := s.enterpriseRepo.GetByID(ctx, enterpriseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load enterprise: %w", err)
+	}
+	end := week.AddDate(0, 0, 7)
+	previous := week.AddDate(0, 0, -7)
+	report := &model.WeeklyReport{
+		EnterpriseID:   enterpriseID,
+		EnterpriseName: e.Name,
+		Week:           week,
+		Totals:         &model.AnalyticsBucket{Bucket: week},
+		Previous:       &model.AnalyticsBucket{Bucket: previous},
+		Days:           make([]*model.AnalyticsBucket, 7),
+		Platforms:      []*model.AnalyticsBucket{},
+		TopCampaigns:   []*model.WeeklyReportCampaign{},
+	}
+
+	weeks, err := s.analytics.TimeSeries(ctx, enterpriseID, "week", "", false, false, previous, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load weeks: %w", err)
+	}
+	for _, b := range weeks {
+		switch {
+		case b.Bucket.Equal(week):
+			report.Totals = b
+		case b.Bucket.Equal(previous):
+			report.Previous = b
+		}
+	}
+
+	days, err := s.analytics.TimeSeries(ctx, enterpriseID, "day", "", false, false, week, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load days: %w", err)
+	}
+	for i := range report.Days {
+		report.Days[i] = &model.AnalyticsBucket{Bucket: week.AddDate(0, 0, i)}
+	}
+	for _, b := range days {
+		if i := int(b.Bucket.Sub(week) / (24 * time.Hour)); i >= 0 && i < len(report.Days) {
+			report.Days[i] = b
+		}
+	}
+
+	platforms, err := s.analytics.TimeSeries(ctx, enterpriseID, "week", "", true, false, week, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load platforms: %w", err)
+	}
+	if platforms != nil {
+		report.Platforms = platforms
+	}
+
+	top, err := s.analytics.TopCampaigns(ctx, enterpriseID, "week", week, weeklyReportTopCampaigns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaigns: %w", err)
+	}
+	if top != nil {
+		report.TopCampaigns = top
+	}
+
+	analyticsRates(report.Totals)
+	analyticsRates(report.Previous)
+	for _, b := range report.Days {
+		analyticsRates(b)
+	}
+	for _, b := range report.Platforms {
+		analyticsRates(b)
+	}
+	return report, nil
+}
+
+// Start emails due reports until ctx is cancelled
+func (s *WeeklyReportService) Start(ctx context.Context) {
+	if s.mailer == nil {
+		return
+	}
+	ticker := time.NewTicker(weeklyReportSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx, time.Now().UTC())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep sends last week's reports once this week has reached
+// WEEKLY_REPORT_HOUR on Monday and the rollups cover all of last week
+func (s *WeeklyReportService) sweep(ctx context.Context, now time.Time) {
+	current := weekStart(now)
+	if now.Before(current.Add(time.Duration(s.cfg.WeeklyReportHour) * time.Hour)) {
+		return
+	}
+	week := current.AddDate(0, 0, -7)
+	for _, granularity := range []string{"day", "week"} {
+		at, err := s.analytics.RolledUpAt(ctx, granularity)
+		if err != nil {
+			slog.ErrorContext(ctx, "weekly reports: failed to load rollup state", "error", err)
+			return
+		}
+		if at == nil || at.Before(current) {
+			return
+		}
+	}
+
+	due, err := s.repo.Due(ctx, week, weeklyReportSweepBatch)
+	if err != nil {
+		slog.ErrorContext(ctx, "weekly reports: failed to list due reports", "error", err)
+		return
+	}
+	for _, enterpriseID := range due {
+		// Taken before sending, so a report goes out at most once even when
+		// sending fails
+		ok, err := s.repo.MarkSent(ctx, enterpriseID, week)
+		if err != nil {
+			slog.ErrorContext(ctx, "weekly reports: failed to mark sent", "enterprise_id", enterpriseID, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		n, err := s.send(ctx, enterpriseID, week)
+		if err != nil {
+			slog.ErrorContext(ctx, "weekly reports: failed to send report", "enterprise_id", enterpriseID, "week", week.Format("2006-01-02"), "error", err)
+		}
+		if err := s.repo.SetRecipients(ctx, enterpriseID, week, n); err != nil {
+			slog.ErrorContext(ctx, "weekly reports: failed to record recipients", "enterprise_id", enterpriseID, "error", err)
+		}
+	}
+}
+
+// send emails an enterprise's report of week to its subscribers, returning
+// how many it reached
+func (s *WeeklyReportService) send(ctx context.Context, enterpriseID string, week time.Time) (int, error) {
+	subscribers, err := s.repo.ActiveSubscribers(ctx, enterpriseID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load subscribers: %w", err)
+	}
+	report, err := s.build(ctx, enterpriseID, week)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, sub := range subscribers {
+		unsubscribe := s.subscriberURL(sub.ID, "unsubscribe")
+		html, err := renderWeeklyReport(sub.Locale, report, unsubscribe)
+		if err != nil {
+			return sent, err
+		}
+		err = s.mailer.Send(ctx, &Email{
+			To:      sub.Email,
+			Subject: i18n.T(sub.Locale, "report.subject", report.EnterpriseName, week.Format("2006-01-02")),
+			Text:    weeklyReportText(sub.Locale, report, unsubscribe),
+			HTML:    html,
+			Headers: map[string]string{
+				"List-Unsubscribe":      "<" + unsubscribe + ">",
+				"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+			},
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "weekly reports: failed to send", "subscriber_id", sub.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// weeklyReportMetric is a row of a report's summary
+type weeklyReportMetric struct {
+	Label  string
+	Value  string
+	Change string
+	Up     bool
+}
+
+// weeklyReportBar is a row of one of a report's bar charts
+type weeklyReportBar struct {
+	Label   string
+	Value   string
+	Percent int
+}
+
+type weeklyReportCampaign struct {
+	Name   string
+	Claims int64
+	Amount string
+}
+
+func weeklyReportMetrics(locale string, report *model.WeeklyReport) []weeklyReportMetric {
+	cur, prev := report.Totals, report.Previous
+	metric := func(key, value string, now, before float64) weeklyReportMetric {
+		m := weeklyReportMetric{Label: i18n.T(locale, key), Value: value, Up: now >= before}
+		switch {
+		case before == 0 && now == 0:
+		case before == 0:
+			m.Change = i18n.T(locale, "report.change_new")
+		default:
+			m.Change = fmt.Sprintf("%+.0f%%", math.Round((now-before)/before*100))
+		}
+		return m
+	}
+	return []weeklyReportMetric{
+		metric("report.claims", fmt.Sprint(cur.Claims), float64(cur.Claims), float64(prev.Claims)),
+		metric("report.unique_claimers", fmt.Sprint(cur.UniqueClaimers), float64(cur.UniqueClaimers), float64(prev.UniqueClaimers)),
+		metric("report.amount", fmt.Sprintf("%.2f", cur.Amount), cur.Amount, prev.Amount),
+		metric("report.pockets", fmt.Sprint(cur.Pockets), float64(cur.Pockets), float64(prev.Pockets)),
+		metric("report.conversion", fmt.Sprintf("%.2f", cur.ConversionRate), cur.ConversionRate, prev.ConversionRate),
+	}
+}
+
+// weeklyReportBars charts claims of each bucket against the largest
+func weeklyReportBars(buckets []*model.AnalyticsBucket, label func(*model.AnalyticsBucket) string) []weeklyReportBar {
+	var most int64
+	for _, b := range buckets {
+		most = max(most, b.Claims)
+	}
+	bars := make([]weeklyReportBar, len(buckets))
+	for i, b := range buckets {
+		bars[i] = weeklyReportBar{Label: label(b), Value: fmt.Sprint(b.Claims)}
+		if most > 0 {
+			bars[i].Percent = int(b.Claims * 100 / most)
+		}
+	}
+	return bars
+}
+
+var weeklyReportTemplate = template.Must(template.New("weekly_report").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="margin:0;padding:24px;background:#f5f5f5;font-family:Helvetica,Arial,sans-serif;color:#222">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="max-width:600px;margin:0 auto;background:#ffffff;border-radius:8px">
+<tr><td style="padding:24px">
+<h1 style="font-size:20px;margin:0 0 4px">{{.Title}}</h1>
+<p style="margin:0 0 20px;color:#666">{{.Period}}</p>
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="margin-bottom:24px">
+{{range .Metrics}}<tr>
+<td style="padding:6px 0;border-bottom:1px solid #eee">{{.Label}}</td>
+<td style="padding:6px 0;border-bottom:1px solid #eee;text-align:right;font-weight:bold">{{.Value}}</td>
+<td style="padding:6px 0 6px 12px;border-bottom:1px solid #eee;text-align:right;width:60px;{{if .Up}}color:#2e7d32{{else}}color:#c62828{{end}}">{{.Change}}</td>
+</tr>
+{{end}}</table>
+{{if .Active}}
+<h2 style="font-size:16px;margin:0 0 8px">{{.DaysTitle}}</h2>
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="margin-bottom:24px">
+{{range .Days}}<tr>
+<td style="padding:3px 8px 3px 0;width:60px;color:#666">{{.Label}}</td>
+<td style="padding:3px 0"><div style="background:#e53935;height:14px;width:{{.Percent}}%"></div></td>
+<td style="padding:3px 0 3px 8px;width:50px;text-align:right">{{.Value}}</td>
+</tr>
+{{end}}</table>
+{{if .Platforms}}
+<h2 style="font-size:16px;margin:0 0 8px">{{.PlatformsTitle}}</h2>
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="margin-bottom:24px">
+{{range .Platforms}}<tr>
+<td style="padding:3px 8px 3px 0;width:80px;color:#666">{{.Label}}</td>
+<td style="padding:3px 0"><div style="background:#fb8c00;height:14px;width:{{.Percent}}%"></div></td>
+<td style="padding:3px 0 3px 8px;width:50px;text-align:right">{{.Value}}</td>
+</tr>
+{{end}}</table>
+{{end}}
+{{if .Campaigns}}
+<h2 style="font-size:16px;margin:0 0 8px">{{.CampaignsTitle}}</h2>
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="margin-bottom:24px">
+{{range .Campaigns}}<tr>
+<td style="padding:6px 0;border-bottom:1px solid #eee">{{.Name}}</td>
+<td style="padding:6px 0;border-bottom:1px solid #eee;text-align:right">{{.Claims}}</td>
+<td style="padding:6px 0 6px 12px;border-bottom:1px solid #eee;text-align:right">{{.Amount}}</td>
+</tr>
+{{end}}</table>
+{{end}}
+{{else}}
+<p style="margin:0 0 24px">{{.NoActivity}}</p>
+{{end}}
+{{if .UnsubscribeURL}}<p style="margin:0;font-size:12px;color:#999">{{.Footer}} <a href="{{.UnsubscribeURL}}" style="color:#999">{{.Unsubscribe}}</a></p>{{end}}
+</td></tr>
+</table>
+</body>
+</html>
+`))
+
+func renderWeeklyReport(locale string, report *model.WeeklyReport, unsubscribeURL string) (string, error) {
+	end := report.Week.AddDate(0, 0, 6)
+	campaigns := make([]weeklyReportCampaign, len(report.TopCampaigns))
+	for i, c := range report.TopCampaigns {
+		campaigns[i] = weeklyReportCampaign{Name: c.Name, Claims: c.Claims, Amount: fmt.Sprintf("%.2f", c.Amount)}
+	}
+	data := map[string]interface{}{
+		"Lang":           locale,
+		"Title":          i18n.T(locale, "report.title", report.EnterpriseName),
+		"Period":         i18n.T(locale, "report.period", report.Week.Format("2006-01-02"), end.Format("2006-01-02")),
+		"Metrics":        weeklyReportMetrics(locale, report),
+		"Active":         report.Totals.Claims > 0 || report.Totals.Pockets > 0,
+		"DaysTitle":      i18n.T(locale, "report.by_day"),
+		"Days":           weeklyReportBars(report.Days, func(b *model.AnalyticsBucket) string { return b.Bucket.Format("01-02") }),
+		"PlatformsTitle": i18n.T(locale, "report.by_platform"),
+		"Platforms":      weeklyReportBars(report.Platforms, func(b *model.AnalyticsBucket) string { return b.Platform }),
+		"CampaignsTitle": i18n.T(locale, "report.top_campaigns"),
+		"Campaigns":      campaigns,
+		"NoActivity":     i18n.T(locale, "report.no_activity"),
+		"Footer":         i18n.T(locale, "report.footer", report.EnterpriseName),
+		"Unsubscribe":    i18n.T(locale, "report.unsubscribe"),
+		"UnsubscribeURL": unsubscribeURL,
+	}
+	var buf bytes.Buffer
+	if err := weeklyReportTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// weeklyReportText is the plain text part of a report's email
+func weeklyReportText(locale string, report *model.WeeklyReport, unsubscribeURL string) string {
+	end := report.Week.AddDate(0, 0, 6)
+	lines := []string{
+		i18n.T(locale, "report.title", report.EnterpriseName),
+		i18n.T(locale, "report.period", report.Week.Format("2006-01-02"), end.Format("2006-01-02")),
+		"",
+	}
+	for _, m := range weeklyReportMetrics(locale, report) {
+		line := m.Label + ": " + m.Value
+		if m.Change != "" {
+			line += " (" + m.Change + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(report.TopCampaigns) > 0 {
+		lines = append(lines, "", i18n.T(locale, "report.top_campaigns"))
+		for _, c := range report.TopCampaigns {
+			lines = append(lines, fmt.Sprintf("- %s: %d, %.2f", c.Name, c.Claims, c.Amount))
+		}
+	}
+	lines = append(lines, "", i18n.T(locale, "report.footer", report.EnterpriseName), i18n.T(locale, "report.unsubscribe")+": "+unsubscribeURL)
+	return strings.Join(lines, "\n")
+}
+
+var reportNoticeTemplate = template.Must(template.New("report_notice").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width"></head>
+<body style="margin:0;padding:24px;background:#f5f5f5;font-family:Helvetica,Arial,sans-serif;color:#222">
+<div style="max-width:480px;margin:0 auto;background:#ffffff;border-radius:8px;padding:24px">
+<p style="margin:0{{if .Link}} 0 20px{{end}}">{{.Message}}</p>
+{{if .Link}}<a href="{{.Link}}" style="display:inline-block;padding:10px 18px;background:#e53935;color:#ffffff;border-radius:4px;text-decoration:none">{{.Action}}</a>{{end}}
+</div>
+</body>
+</html>
+`))
+
+// renderReportNotice renders a short message, with a button to link when
+// link is set: the confirmation email and the confirm and unsubscribe pages
+func renderReportNotice(locale, message, action, link string) (string, error) {
+	var buf bytes.Buffer
+	err := reportNoticeTemplate.Execute(&buf, map[string]string{
+		"Lang":    locale,
+		"Message": message,
+		"Action":  action,
+		"Link":    link,
+	})
+	return buf.String(), err
+}
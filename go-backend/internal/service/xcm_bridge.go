@@ -8,9 +8,13 @@ import (
 	"io"
 	"math/big"
 	"net/http"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/protocolbank/redpocket-backend/internal/config"
+	"github.com/protocolbank/redpocket-backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ChainID represents supported blockchain networks
@@ -26,30 +30,25 @@ const (
 	ChainPolkadot  ChainID = 0 // Relay chain
 )
 
-// XCMMessage represents a cross-chain message
-type XCMMessage struct {
-	Version     int         `json:"version"`
-	MessageType string      `json:"messageType"`
-	Origin      ChainID     `json:"origin"`
-	Destination ChainID     `json:"destination"`
-	Payload     interface{} `json:"payload"`
-	Nonce       uint64      `json:"nonce"`
-}
-
-// AssetTransferPayload for cross-chain asset transfers
-type AssetTransferPayload struct {
-	Asset     string `json:"asset"`
-	Amount    string `json:"amount"`
-	Recipient string `json:"recipient"`
-	Memo      string `json:"memo,omitempty"`
-}
+// ErrXCMTransferNotSendable is returned for XCM transfers asked for from a
+// chain without an XCM precompile, which only the relayer sends from
+var ErrXCMTransferNotSendable = newCodedError("xcm_transfer_not_sendable")
 
 // XCMBridge handles cross-chain operations
 type XCMBridge struct {
-	cfg        *config.Config
-	httpClient *http.Client
-	chainRPCs  map[ChainID]string
-	assetMap   map[string]map[ChainID]string // asset -> chain -> address
+	cfg         *config.Config
+	chainRPCs   map[ChainID]*chainRPC
+	assetMap    map[string]map[ChainID]string // asset -> chain -> address, empty for the native token and Substrate currencies
+	currencyIDs map[string]map[ChainID]string // asset -> Substrate chain -> Tokens pallet currency key
+	decimals    map[string]int                // asset -> decimals, the same on every chain
+
+	gasMu     sync.Mutex
+	gasPrices map[ChainID]cachedGasPrice
+}
+
+type cachedGasPrice struct {
+	price     *big.Int
+	fetchedAt time.Time
 }
 
 // ChainInfo contains chain-specific information
@@ -65,21 +64,20 @@ type ChainInfo struct {
 
 func NewXCMBridge(cfg *config.Config) *XCMBridge {
 	bridge := &XCMBridge{
-		cfg: cfg,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		chainRPCs: make(map[ChainID]string),
-		assetMap:  make(map[string]map[ChainID]string),
-	}
-
-	// Initialize chain RPCs
-	bridge.chainRPCs[ChainBase] = cfg.RPCUrl
-	bridge.chainRPCs[ChainPolygon] = "https://polygon-rpc.com"
-	bridge.chainRPCs[ChainEthereum] = "https://eth.llamarpc.com"
-	bridge.chainRPCs[ChainMoonbeam] = "https://rpc.api.moonbeam.network"
-	bridge.chainRPCs[ChainAcala] = "https://acala-rpc.dwellir.com"
-	bridge.chainRPCs[ChainAstar] = "https://astar.api.onfinality.io/public"
+		cfg:         cfg,
+		chainRPCs:   make(map[ChainID]*chainRPC),
+		assetMap:    make(map[string]map[ChainID]string),
+		currencyIDs: make(map[string]map[ChainID]string),
+		decimals:    make(map[string]int),
+		gasPrices:   make(map[ChainID]cachedGasPrice),
+	}
+
+	// Chain RPC providers, see config.ChainRPCURLs
+	for chainID, urls := range cfg.ChainRPCURLs {
+		if len(urls) > 0 {
+			bridge.chainRPCs[ChainID(chainID)] = newChainRPC(urls, cfg.DownstreamBridge)
+		}
+	}
 
 	// Initialize asset mappings (USDC addresses on different chains)
 	bridge.assetMap["USDC"] = map[ChainID]string{
@@ -95,6 +93,40 @@ func NewXCMBridge(cfg *config.Config) *XCMBridge {
 		ChainEthereum: "0xdAC17F958D2ee523a2206206994597C13D831ec7",
 	}
 
+	// Native tokens of the EVM chains, held without a contract
+	bridge.assetMap["ETH"] = map[ChainID]string{
+		ChainBase:     "",
+		ChainEthereum: "",
+	}
+	bridge.assetMap["MATIC"] = map[ChainID]string{ChainPolygon: ""}
+	bridge.assetMap["GLMR"] = map[ChainID]string{ChainMoonbeam: ""}
+	bridge.assetMap["ASTR"] = map[ChainID]string{ChainAstar: ""}
+	bridge.assetMap["ACA"] = map[ChainID]string{ChainAcala: ""}
+
+	// DOT is native to the relay chain. Moonbeam and Astar hold it as an
+	// ERC-20 precompile of their assets pallet, Acala in its Tokens pallet,
+	// which has no address.
+	bridge.assetMap["DOT"] = map[ChainID]string{
+		ChainPolkadot: "",
+		ChainMoonbeam: "0xFfFFfFff1FcaCBd218EDc0EbA20Fc2308C778080",
+		ChainAstar:    "0xffffffffffffffffffffffffffffffffffffffff",
+		ChainAcala:    "",
+	}
+
+	// Tokens pallet currencies, by the storage key of their CurrencyId:
+	// CurrencyId::Token(DOT) is 0x0002 after its twox64 hash
+	bridge.currencyIDs["DOT"] = map[ChainID]string{
+		ChainAcala: "0xc483de2de1246ea70002",
+	}
+
+	for asset, decimals := range map[string]int{
+		"USDC": 6, "USDT": 6,
+		"ETH": 18, "MATIC": 18, "GLMR": 18, "ASTR": 18,
+		"DOT": 10, "ACA": 12,
+	} {
+		bridge.decimals[asset] = decimals
+	}
+
 	return bridge
 }
 
@@ -102,13 +134,27 @@ func NewXCMBridge(cfg *config.Config) *XCMBridge {
 // GetSupportedChains returns all supported chains
 func (b *XCMBridge) GetSupportedChains() []ChainInfo {
 	return []ChainInfo{
-		{ChainID: ChainBase, Name: "Base", RpcURL: b.chainRPCs[ChainBase], ExplorerURL: "https://basescan.org", IsEVM: true},
-		{ChainID: ChainPolygon, Name: "Polygon", RpcURL: b.chainRPCs[ChainPolygon], ExplorerURL: "https://polygonscan.com", IsEVM: true},
-		{ChainID: ChainEthereum, Name: "Ethereum", RpcURL: b.chainRPCs[ChainEthereum], ExplorerURL: "https://etherscan.io", IsEVM: true},
-		{ChainID: ChainMoonbeam, Name: "Moonbeam", RpcURL: b.chainRPCs[ChainMoonbeam], ExplorerURL: "https://moonbeam.moonscan.io", IsEVM: true, IsPolkadot: true},
-		{ChainID: ChainAcala, Name: "Acala", RpcURL: b.chainRPCs[ChainAcala], ExplorerURL: "https://acala.subscan.io", IsPolkadot: true},
-		{ChainID: ChainAstar, Name: "Astar", RpcURL: b.chainRPCs[ChainAstar], ExplorerURL: "https://astar.subscan.io", IsEVM: true, IsPolkadot: true},
+		{ChainID: ChainBase, Name: "Base", RpcURL: b.rpcURL(ChainBase), ExplorerURL: "https://basescan.org", IsEVM: true},
+		{ChainID: ChainPolygon, Name: "Polygon", RpcURL: b.rpcURL(ChainPolygon), ExplorerURL: "https://polygonscan.com", IsEVM: true},
+		{ChainID: ChainEthereum, Name: "Ethereum", RpcURL: b.rpcURL(ChainEthereum), ExplorerURL: "https://etherscan.io", IsEVM: true},
+		{ChainID: ChainMoonbeam, Name: "Moonbeam", RpcURL: b.rpcURL(ChainMoonbeam), ExplorerURL: "https://moonbeam.moonscan.io", IsEVM: true, IsPolkadot: true},
+		{ChainID: ChainAcala, Name: "Acala", RpcURL: b.rpcURL(ChainAcala), ExplorerURL: "https://acala.subscan.io", IsPolkadot: true},
+		{ChainID: ChainAstar, Name: "Astar", RpcURL: b.rpcURL(ChainAstar), ExplorerURL: "https://astar.subscan.io", IsEVM: true, IsPolkadot: true},
+		{ChainID: ChainPolkadot, Name: "Polkadot", RpcURL: b.rpcURL(ChainPolkadot), ExplorerURL: "https://polkadot.subscan.io", IsPolkadot: true},
+	}
+}
+
+// ExplorerTxURL returns the block explorer link for a transaction, or "" for unknown chains
+func (b *XCMBridge) ExplorerTxURL(chainID ChainID, txHash string) string {
+	if txHash == "" {
+		return ""
+	}
+	for _, chain := range b.GetSupportedChains() {
+		if chain.ChainID == chainID {
+			return chain.ExplorerURL + "/tx/" + txHash
+		}
 	}
+	return ""
 }
 
 // GetAssetAddress returns the token address for an asset on a specific chain
@@ -124,12 +170,52 @@ func (b *XCMBridge) GetAssetAddress(asset string, chainID ChainID) (string, erro
 	return addr, nil
 }
 
-// GetChainGasPrice fetches current gas price for a chain
+// GetAssetCurrencyID returns the Tokens pallet currency key of an asset on a
+// Substrate chain, or "" when the chain does not hold it as a currency
+func (b *XCMBridge) GetAssetCurrencyID(asset string, chainID ChainID) string {
+	return b.currencyIDs[asset][chainID]
+}
+
+// IsNativeAsset reports whether an asset is the native token of a chain,
+// held without a contract or a currency of the Tokens pallet
+func (b *XCMBridge) IsNativeAsset(asset string, chainID ChainID) bool {
+	addr, err := b.GetAssetAddress(asset, chainID)
+	return err == nil && addr == "" && b.GetAssetCurrencyID(asset, chainID) == ""
+}
+
+// AssetDecimals returns the decimals of an asset, 18 for unknown ones
+func (b *XCMBridge) AssetDecimals(asset string) int {
+	if decimals, ok := b.decimals[asset]; ok {
+		return decimals
+	}
+	return 18
+}
+
+// AssetsOn returns the assets available on a chain, sorted by symbol
+func (b *XCMBridge) AssetsOn(chainID ChainID) []string {
+	var assets []string
+	for asset, chainMap := range b.assetMap {
+		if _, ok := chainMap[chainID]; ok {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// GetChainGasPrice fetches current gas price for a chain. A price fetched
+// within the last GasPriceCacheTTL seconds is reused. Substrate chains have
+// no gas price, see TransferFee.
 func (b *XCMBridge) GetChainGasPrice(ctx context.Context, chainID ChainID) (*big.Int, error) {
-	rpcURL, ok := b.chainRPCs[chainID]
-	if !ok {
+	if _, ok := b.chainRPCs[chainID]; !ok {
 		return nil, fmt.Errorf("unsupported chain: %d", chainID)
 	}
+	if b.isSubstrateChain(chainID) {
+		return nil, fmt.Errorf("chain %d has no gas price, its fees are weighed per transfer", chainID)
+	}
+	if price, ok := b.cachedGasPrice(chainID); ok {
+		return price, nil
+	}
 
 	req := map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -138,11 +224,7 @@ func (b *XCMBridge) GetChainGasPrice(ctx context.Context, chainID ChainID) (*big
 		"id":      1,
 	}
 
-	body, _ := json.Marshal(req)
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", rpcURL, bytes.NewReader(body))
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := b.httpClient.Do(httpReq)
+	resp, err := b.post(ctx, chainID, req)
 	if err != nil {
 		return big.NewInt(1000000000), nil // Default 1 gwei
 	}
@@ -157,6 +239,7 @@ func (b *XCMBridge) GetChainGasPrice(ctx context.Context, chainID ChainID) (*big
 	gasPrice := new(big.Int)
 	if result.Result != "" && len(result.Result) > 2 {
 		gasPrice.SetString(result.Result[2:], 16)
+		b.cacheGasPrice(chainID, gasPrice)
 	} else {
 		gasPrice.SetInt64(1000000000)
 	}
@@ -164,6 +247,97 @@ func (b *XCMBridge) GetChainGasPrice(ctx context.Context, chainID ChainID) (*big
 	return gasPrice, nil
 }
 
+func (b *XCMBridge) cachedGasPrice(chainID ChainID) (*big.Int, bool) {
+	ttl := time.Duration(b.cfg.GasPriceCacheTTL) * time.Second
+	b.gasMu.Lock()
+	defer b.gasMu.Unlock()
+	cached, ok := b.gasPrices[chainID]
+	if !ok || time.Since(cached.fetchedAt) >= ttl {
+		return nil, false
+	}
+	return new(big.Int).Set(cached.price), true
+}
+
+func (b *XCMBridge) cacheGasPrice(chainID ChainID, price *big.Int) {
+	if b.cfg.GasPriceCacheTTL <= 0 {
+		return
+	}
+	b.gasMu.Lock()
+	b.gasPrices[chainID] = cachedGasPrice{price: new(big.Int).Set(price), fetchedAt: time.Now()}
+	b.gasMu.Unlock()
+}
+
+// rpcURL returns the URL of a chain's healthiest RPC provider, for callers
+// with their own JSON-RPC client
+func (b *XCMBridge) rpcURL(chainID ChainID) string {
+	rpc, ok := b.chainRPCs[chainID]
+	if !ok {
+		return ""
+	}
+	return rpc.best().url
+}
+
+// RPCStatus returns the health of a chain's RPC providers
+func (b *XCMBridge) RPCStatus(chainID ChainID) []RPCProviderStatus {
+	rpc, ok := b.chainRPCs[chainID]
+	if !ok {
+		return nil
+	}
+	statuses := make([]RPCProviderStatus, 0, len(rpc.providers))
+	for _, p := range rpc.providers {
+		statuses = append(statuses, p.status())
+	}
+	return statuses
+}
+
+// post sends a JSON-RPC request to a chain's providers in turn until one
+// answers, recording each attempt in the provider's health score
+func (b *XCMBridge) post(ctx context.Context, chainID ChainID, req map[string]interface{}) (*http.Response, error) {
+	rpc, ok := b.chainRPCs[chainID]
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, p := range rpc.order() {
+		start := time.Now()
+		resp, err := b.postTo(ctx, chainID, p, req, body)
+		if err == nil && (resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests) {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			err = fmt.Errorf("%s: status %d", providerHost(p.url), resp.StatusCode)
+		}
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, which says nothing about the provider
+			return nil, ctx.Err()
+		}
+		p.record(err, time.Since(start))
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// postTo sends a JSON-RPC request to one provider in a client span
+func (b *XCMBridge) postTo(ctx context.Context, chainID ChainID, p *rpcProvider, req map[string]interface{}, body []byte) (_ *http.Response, err error) {
+	method, _ := req["method"].(string)
+	ctx, span := tracing.StartRPC(ctx, "chain", method, p.url, attribute.Int64("chain.id", int64(chainID)))
+	defer func() { tracing.End(span, err) }()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	return p.client.Do(httpReq)
+}
+
 // SelectOptimalChain selects the most cost-effective chain for a transaction
 func (b *XCMBridge) SelectOptimalChain(ctx context.Context, asset string, preferredChains []ChainID) (ChainID, error) {
 	if len(preferredChains) == 0 {
@@ -206,6 +380,13 @@ type CrossChainTransferRequest struct {
 	Amount    *big.Int
 	Sender    string
 	Recipient string
+
+	ConsolidationID string // of the consolidation the transfer is part of, if any
+
+	// relayed lets a transfer from the relay chain or Acala be signed by the
+	// XCM relayer, see SendXCMExtrinsic. Only flows the server starts set
+	// it, never a transfer a caller asks for.
+	relayed bool
 }
 
 type CrossChainTransferResult struct {
@@ -215,6 +396,10 @@ type CrossChainTransferResult struct {
 	BridgeId      string `json:"bridgeId"`
 	EstimatedTime int    `json:"estimatedTimeSeconds"`
 	Status        string `json:"status"`
+
+	// Call is the XCM precompile call that sends a transfer from Moonbeam
+	// or Astar, for the sender's wallet to make
+	Call *XCMPrecompileCall `json:"call,omitempty"`
 }
 
 // TransferAsset initiates a cross-chain asset transfer
@@ -246,37 +431,25 @@ func (b *XCMBridge) isEVMChain(chainID ChainID) bool {
 	return chainID == ChainBase || chainID == ChainPolygon || chainID == ChainEthereum || chainID == ChainMoonbeam || chainID == ChainAstar
 }
 
-// executeXCMTransfer handles Polkadot ecosystem transfers via XCM
+// executeXCMTransfer handles Polkadot ecosystem transfers via XCM. Nothing
+// is sent here: a transfer from Moonbeam or Astar is returned as its XCM
+// precompile call for the sender's own wallet to make, and transfers from
+// the relay chain or Acala are only signed by the XCM relayer for the
+// server's own flows, see SendXCMExtrinsic. Managed wallets send and track
+// theirs through HyperbridgeService.
 func (b *XCMBridge) executeXCMTransfer(ctx context.Context, req *CrossChainTransferRequest) (*CrossChainTransferResult, error) {
-	// Build XCM message
-	xcmMsg := &XCMMessage{
-		Version:     3, // XCM v3
-		MessageType: "TransferAsset",
-		Origin:      req.FromChain,
-		Destination: req.ToChain,
-		Payload: AssetTransferPayload{
-			Asset:     req.Asset,
-			Amount:    req.Amount.String(),
-			Recipient: req.Recipient,
-		},
-		Nonce: uint64(time.Now().UnixNano()),
+	if !b.HasXCMPrecompile(req.FromChain) {
+		return nil, ErrXCMTransferNotSendable
+	}
+	call, err := b.XCMPrecompileCall(req)
+	if err != nil {
+		return nil, err
 	}
-
-	// In production, this would:
-	// 1. Connect to Polkadot.js API
-	// 2. Build XCM extrinsic
-	// 3. Sign and submit transaction
-	// 4. Wait for confirmation on both chains
-
-	// For now, simulate the transfer
-	bridgeId := fmt.Sprintf("xcm_%d_%d", time.Now().UnixNano(), req.FromChain)
-	
 	return &CrossChainTransferResult{
 		Success:       true,
-		SourceTxHash:  fmt.Sprintf("0x%x", xcmMsg.Nonce),
-		BridgeId:      bridgeId,
 		EstimatedTime: 60, // ~1 minute for XCM
-		Status:        "pending",
+		Status:        "awaiting_signature",
+		Call:          call,
 	}, nil
 }
 
@@ -328,40 +501,47 @@ func (b *XCMBridge) GetTransferStatus(ctx context.Context, bridgeId string) (*Cr
 	}, nil
 }
 
-// GetAssetBalance queries balance on a specific chain
+// GetAssetBalance queries balance on a specific chain; native tokens are
+// read with eth_getBalance, or from System.Account on Substrate chains
 func (b *XCMBridge) GetAssetBalance(ctx context.Context, chainID ChainID, asset string, account string) (*big.Int, error) {
 	tokenAddr, err := b.GetAssetAddress(asset, chainID)
 	if err != nil {
 		return nil, err
 	}
 
-	rpcURL, ok := b.chainRPCs[chainID]
-	if !ok {
+	if _, ok := b.chainRPCs[chainID]; !ok {
 		return nil, fmt.Errorf("unsupported chain: %d", chainID)
 	}
 
-	// ERC20 balanceOf call
-	// balanceOf(address) selector: 0x70a08231
-	callData := "0x70a08231000000000000000000000000" + account[2:] // Remove 0x prefix
+	if b.isSubstrateChain(chainID) {
+		if currency := b.GetAssetCurrencyID(asset, chainID); currency != "" {
+			return b.substrateTokenBalance(ctx, chainID, currency, account)
+		}
+		return b.substrateFreeBalance(ctx, chainID, account)
+	}
 
 	req := map[string]interface{}{
 		"jsonrpc": "2.0",
-		"method":  "eth_call",
-		"params": []interface{}{
+		"method":  "eth_getBalance",
+		"params":  []interface{}{account, "latest"},
+		"id":      1,
+	}
+	if tokenAddr != "" {
+		// ERC20 balanceOf call
+		// balanceOf(address) selector: 0x70a08231
+		callData := "0x70a08231000000000000000000000000" + account[2:] // Remove 0x prefix
+
+		req["method"] = "eth_call"
+		req["params"] = []interface{}{
 			map[string]string{
 				"to":   tokenAddr,
 				"data": callData,
 			},
 			"latest",
-		},
-		"id": 1,
+		}
 	}
 
-	body, _ := json.Marshal(req)
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", rpcURL, bytes.NewReader(body))
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := b.httpClient.Do(httpReq)
+	resp, err := b.post(ctx, chainID, req)
 	if err != nil {
 		return big.NewInt(0), nil
 	}
@@ -394,6 +574,13 @@ func (b *XCMBridge) EstimateCrossChainFee(ctx context.Context, fromChain, toChai
 		gasPrice, _ := b.GetChainGasPrice(ctx, fromChain)
 		gasLimit := big.NewInt(200000)
 		baseFee.Mul(gasPrice, gasLimit)
+	} else if b.isSubstrateChain(fromChain) {
+		// Cross-ecosystem from a Substrate chain: the source transfer's weight fee
+		fee, err := b.TransferFee(ctx, fromChain)
+		if err != nil {
+			return nil, err
+		}
+		baseFee.Set(fee)
 	} else {
 		// Cross-ecosystem: higher fee
 		gasPrice, _ := b.GetChainGasPrice(ctx, fromChain)
@@ -404,11 +591,12 @@ func (b *XCMBridge) EstimateCrossChainFee(ctx context.Context, fromChain, toChai
 	return baseFee, nil
 }
 
-// ChainHealthCheck checks if a chain is healthy and not congested
+// ChainHealthCheck checks if a chain is healthy and not congested, that is
+// if one of its RPC providers answers; a Substrate chain's node must also be
+// synced with peers
 func (b *XCMBridge) ChainHealthCheck(ctx context.Context, chainID ChainID) (bool, error) {
-	rpcURL, ok := b.chainRPCs[chainID]
-	if !ok {
-		return false, fmt.Errorf("unsupported chain: %d", chainID)
+	if b.isSubstrateChain(chainID) {
+		return b.substrateHealth(ctx, chainID)
 	}
 
 	req := map[string]interface{}{
@@ -418,11 +606,7 @@ func (b *XCMBridge) ChainHealthCheck(ctx context.Context, chainID ChainID) (bool
 		"id":      1,
 	}
 
-	body, _ := json.Marshal(req)
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", rpcURL, bytes.NewReader(body))
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := b.httpClient.Do(httpReq)
+	resp, err := b.post(ctx, chainID, req)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// The relay chain and Acala have no EVM for a wallet to call from, so
+// transfers from them are pallet-xcm transfer_assets extrinsics signed by
+// the relayer account, XCM_RELAYER_KEY, and sent from its balance. The key
+// is ed25519, which Substrate accounts accept alongside sr25519.
+
+// xcmLocation is an XCM location: how many levels up, then the interior
+// junctions, each encoded as its variant index and data
+type xcmLocation struct {
+	parents  byte
+	interior [][]byte
+}
+
+// encode SCALE-encodes the location; the Junctions variant is the number of
+// junctions, Here being 0
+func (l xcmLocation) encode() []byte {
+	out := []byte{l.parents, byte(len(l.interior))}
+	for _, j := range l.interior {
+		out = append(out, j...)
+	}
+	return out
+}
+
+func parachainJunction(id uint32) []byte {
+	return append([]byte{0x00}, scaleCompact(uint64(id))...)
+}
+
+// accountID32Junction and accountKey20Junction leave the network out
+func accountID32Junction(id []byte) []byte {
+	return append([]byte{0x01, 0x00}, id...)
+}
+
+func accountKey20Junction(key []byte) []byte {
+	return append([]byte{0x03, 0x00}, key...)
+}
+
+// generalKeyJunction holds up to 32 bytes of key, zero padded
+func generalKeyJunction(key []byte) []byte {
+	data := make([]byte, 32)
+	copy(data, key)
+	return append([]byte{0x06, byte(len(key))}, data...)
+}
+
+// xcmAssetLocations are the locations of the assets that can be sent by
+// extrinsic, as seen from each source chain
+var xcmAssetLocations = map[string]map[ChainID]xcmLocation{
+	"DOT": {
+		ChainPolkadot: {parents: 0},
+		ChainAcala:    {parents: 1},
+	},
+	"ACA": {
+		ChainAcala: {interior: [][]byte{generalKeyJunction([]byte{0x00, 0x00})}}, // CurrencyId::Token(ACA)
+	},
+}
+
+// XCMExtrinsic is a transfer sent from the relay chain or Acala: its
+// extrinsic hash and the relayer nonce it used, which shows when it is
+// included
+type XCMExtrinsic struct {
+	TxHash string
+	Nonce  uint64
+}
+
+// SendsXCMExtrinsics reports whether transfers from a chain are sent as
+// extrinsics signed by the relayer, which needs XCM_RELAYER_KEY
+func (b *XCMBridge) SendsXCMExtrinsics(chainID ChainID) bool {
+	chain, ok := substrateChains[chainID]
+	return ok && chain.xcmPallet != 0 && b.cfg.XCMRelayerKey != ""
+}
+
+// relayerKey parses XCM_RELAYER_KEY, a 32-byte hex seed
+func (b *XCMBridge) relayerKey() (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(b.cfg.XCMRelayerKey, "0x"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid XCM relayer key")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// RelayerAddress returns the relayer's SS58 address on a chain, the sender
+// of the transfers it signs there
+func (b *XCMBridge) RelayerAddress(chainID ChainID) (string, error) {
+	chain, ok := substrateChains[chainID]
+	if !ok {
+		return "", fmt.Errorf("chain %d is not a Substrate chain", chainID)
+	}
+	key, err := b.relayerKey()
+	if err != nil {
+		return "", err
+	}
+	return encodeSS58(chain.ss58Prefix, key.Public().(ed25519.PublicKey)), nil
+}
+
+// RelayerNonce returns the relayer's nonce in a chain's latest block: an
+// extrinsic sent with a lower one has been included
+func (b *XCMBridge) RelayerNonce(ctx context.Context, chainID ChainID) (uint64, error) {
+	key, err := b.relayerKey()
+	if err != nil {
+		return 0, err
+	}
+	info, err := b.substrateAccountInfo(ctx, chainID, key.Public().(ed25519.PublicKey))
+	if err != nil || info == nil {
+		return 0, err
+	}
+	return uint64(binary.LittleEndian.Uint32(info[:4])), nil
+}
+
+// SendXCMExtrinsic signs a transfer_assets extrinsic for a transfer from
+// the relay chain or Acala with the relayer key and submits it. The sender
+// must be the relayer's account, whose balance pays for the transfer, and
+// the transfer one the server started: the relayer's address is public, so
+// a caller naming it as sender proves nothing.
+func (b *XCMBridge) SendXCMExtrinsic(ctx context.Context, req *CrossChainTransferRequest) (*XCMExtrinsic, error) {
+	if !req.relayed {
+		return nil, fmt.Errorf("transfers from chain %d are only sent by the XCM relayer for the server's own flows", req.FromChain)
+	}
+	chain, ok := substrateChains[req.FromChain]
+	if !ok || chain.xcmPallet == 0 {
+		return nil, fmt.Errorf("chain %d cannot send XCM extrinsics", req.FromChain)
+	}
+	key, err := b.relayerKey()
+	if err != nil {
+		return nil, err
+	}
+	signer := key.Public().(ed25519.PublicKey)
+	if sender, err := substrateAccountID(req.Sender); err != nil || !bytes.Equal(sender, signer) {
+		return nil, fmt.Errorf("sender %s is not the XCM relayer account", req.Sender)
+	}
+	call, err := b.xcmTransferCall(chain, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var version struct {
+		SpecVersion        uint32 `json:"specVersion"`
+		TransactionVersion uint32 `json:"transactionVersion"`
+	}
+	if err := b.substrateCall(ctx, req.FromChain, "state_getRuntimeVersion", []interface{}{}, &version); err != nil {
+		return nil, fmt.Errorf("failed to get runtime version: %w", err)
+	}
+	var genesisHex string
+	if err := b.substrateCall(ctx, req.FromChain, "chain_getBlockHash", []interface{}{0}, &genesisHex); err != nil {
+		return nil, fmt.Errorf("failed to get genesis hash: %w", err)
+	}
+	genesis, err := hex.DecodeString(strings.TrimPrefix(genesisHex, "0x"))
+	if err != nil || len(genesis) != 32 {
+		return nil, fmt.Errorf("invalid genesis hash %q", genesisHex)
+	}
+	// Counts the relayer's extrinsics still in the pool, so transfers sent
+	// back to back do not share a nonce
+	var nonce uint64
+	address := encodeSS58(chain.ss58Prefix, signer)
+	if err := b.substrateCall(ctx, req.FromChain, "system_accountNextIndex", []interface{}{address}, &nonce); err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	extra := chain.signedExtra(nonce)
+	payload := append(append([]byte{}, call...), extra...)
+	payload = binary.LittleEndian.AppendUint32(payload, version.SpecVersion)
+	payload = binary.LittleEndian.AppendUint32(payload, version.TransactionVersion)
+	payload = append(payload, genesis...)
+	payload = append(payload, genesis...) // the block the era starts at, genesis for an immortal one
+	payload = append(payload, chain.additional...)
+	if len(payload) > 256 {
+		hashed := blake2b.Sum256(payload)
+		payload = hashed[:]
+	}
+
+	var x bytes.Buffer
+	x.WriteByte(0x84) // signed, version 4
+	x.WriteByte(0x00) // MultiAddress::Id
+	x.Write(signer)
+	x.WriteByte(0x00) // MultiSignature::Ed25519
+	x.Write(ed25519.Sign(key, payload))
+	x.Write(extra)
+	x.Write(call)
+	extrinsic := append(scaleCompact(uint64(x.Len())), x.Bytes()...)
+
+	var txHash string
+	if err := b.substrateCall(ctx, req.FromChain, "author_submitExtrinsic", []interface{}{"0x" + hex.EncodeToString(extrinsic)}, &txHash); err != nil {
+		return nil, fmt.Errorf("failed to submit extrinsic: %w", err)
+	}
+	return &XCMExtrinsic{TxHash: txHash, Nonce: nonce}, nil
+}
+
+// xcmTransferCall encodes pallet-xcm's transfer_assets(dest, beneficiary,
+// assets, fee_asset_item, weight_limit) for a transfer, paying the
+// destination's fees out of the asset sent, without a weight limit
+func (b *XCMBridge) xcmTransferCall(chain substrateChain, req *CrossChainTransferRequest) ([]byte, error) {
+	if !b.isPolkadotChain(req.ToChain) || req.ToChain == req.FromChain {
+		return nil, fmt.Errorf("chain %d cannot receive XCM transfers from chain %d", req.ToChain, req.FromChain)
+	}
+	asset, ok := xcmAssetLocations[req.Asset][req.FromChain]
+	if !ok {
+		return nil, fmt.Errorf("asset %s cannot be sent by XCM from chain %d", req.Asset, req.FromChain)
+	}
+	if req.Amount == nil || req.Amount.Sign() <= 0 || req.Amount.BitLen() > 128 {
+		return nil, fmt.Errorf("invalid transfer amount")
+	}
+
+	dest := xcmLocation{parents: 1}
+	if req.FromChain == ChainPolkadot {
+		dest.parents = 0
+	}
+	if id, ok := parachainIDs[req.ToChain]; ok {
+		dest.interior = [][]byte{parachainJunction(id)}
+	}
+	var beneficiary xcmLocation
+	if req.ToChain == ChainMoonbeam {
+		addr, err := parseAddress("recipient", req.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		beneficiary.interior = [][]byte{accountKey20Junction(addr.Bytes())}
+	} else {
+		id, err := substrateAccountID(req.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		beneficiary.interior = [][]byte{accountID32Junction(id)}
+	}
+
+	v := chain.xcmVersion
+	call := []byte{chain.xcmPallet, chain.xcmTransferCall}
+	call = append(append(call, v), dest.encode()...)
+	call = append(append(call, v), beneficiary.encode()...)
+	call = append(append(call, v), scaleCompact(1)...)
+	if v == 3 {
+		call = append(call, 0x00) // AssetId::Concrete, implied from v4
+	}
+	call = append(call, asset.encode()...)
+	call = append(call, 0x00) // Fungibility::Fungible
+	call = append(call, scaleCompactBig(req.Amount)...)
+	call = binary.LittleEndian.AppendUint32(call, 0) // fee_asset_item
+	call = append(call, 0x00)                        // WeightLimit::Unlimited
+	return call, nil
+}
+
+// signedExtra is the chain's extra with the nonce in place of the dummy
+// transfer's 0, which follows the era
+func (c substrateChain) signedExtra(nonce uint64) []byte {
+	extra := append([]byte{c.extra[0]}, scaleCompact(nonce)...)
+	return append(extra, c.extra[2:]...)
+}
+
+// scaleCompactBig SCALE-encodes n, up to a u128, as a compact integer
+func scaleCompactBig(n *big.Int) []byte {
+	if n.IsUint64() {
+		return scaleCompact(n.Uint64())
+	}
+	be := n.Bytes()
+	le := make([]byte, len(be))
+	for i, v := range be {
+		le[len(be)-1-i] = v
+	}
+	return append([]byte{byte(len(le)-4)<<2 | 0b11}, le...)
+}
+
+// encodeSS58 returns the SS58 address of an account ID for a network prefix
+func encodeSS58(prefix uint16, id []byte) string {
+	body := []byte{byte(prefix)}
+	if prefix >= 64 {
+		body = []byte{byte(prefix&0xfc)>>2 | 0x40, byte(prefix>>8) | byte(prefix&0x03)<<6}
+	}
+	body = append(body, id...)
+	checksum := blake2b.Sum512(append([]byte("SS58PRE"), body...))
+	return encodeBase58(append(body, checksum[:2]...))
+}
+
+func encodeBase58(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	// Leading zero bytes are written as ones
+	for _, v := range b {
+		if v != 0 {
+			break
+		}
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Moonbeam and Astar send XCM transfers from their EVM through precompiles,
+// so a transfer from them is an ordinary contract call that AA wallets and
+// EOAs can make, without a Substrate signer.
+
+const (
+	moonbeamXtokensPrecompile = "0x0000000000000000000000000000000000000804"
+	astarXCMPrecompile        = "0x0000000000000000000000000000000000005004"
+)
+
+// precompileNativeTokens stand for a chain's native token in precompile
+// calls, which take an ERC-20 address
+var precompileNativeTokens = map[ChainID]string{
+	ChainMoonbeam: "0x0000000000000000000000000000000000000802", // balances ERC-20 precompile
+	ChainAstar:    "0x0000000000000000000000000000000000000000",
+}
+
+// parachainIDs are the Polkadot parachain IDs of the bridge's chains; the
+// relay chain has none
+var parachainIDs = map[ChainID]uint32{
+	ChainAcala:    2000,
+	ChainMoonbeam: 2004,
+	ChainAstar:    2006,
+}
+
+// XCMPrecompileCall is the contract call that sends a transfer from
+// Moonbeam or Astar, for the sender's wallet to make
+type XCMPrecompileCall struct {
+	ChainID ChainID `json:"chainId"`
+	To      string  `json:"to"`
+	Data    string  `json:"data"`
+}
+
+// precompileMultilocation is the Multilocation struct of the precompiles:
+// interior junctions are each encoded as a selector byte and its data
+type precompileMultilocation struct {
+	Parents  uint8
+	Interior [][]byte
+}
+
+type precompileWeight struct {
+	RefTime   uint64
+	ProofSize uint64
+}
+
+// unlimitedWeight lets the destination charge what it needs out of the
+// transferred asset
+const unlimitedWeight = math.MaxUint64
+
+// HasXCMPrecompile reports whether transfers from a chain go through its
+// EVM's XCM precompile
+func (b *XCMBridge) HasXCMPrecompile(chainID ChainID) bool {
+	return chainID == ChainMoonbeam || chainID == ChainAstar
+}
+
+// XCMPrecompileCall builds the precompile call that sends a transfer from
+// Moonbeam (xtokens) or Astar (XCM precompile) to another Polkadot chain.
+// The recipient is a 20-byte address on Moonbeam and an SS58 address or
+// 32-byte public key elsewhere.
+func (b *XCMBridge) XCMPrecompileCall(req *CrossChainTransferRequest) (*XCMPrecompileCall, error) {
+	if !b.HasXCMPrecompile(req.FromChain) {
+		return nil, fmt.Errorf("chain %d has no XCM precompile", req.FromChain)
+	}
+	if !b.isPolkadotChain(req.ToChain) || req.ToChain == req.FromChain {
+		return nil, fmt.Errorf("chain %d cannot receive XCM transfers from chain %d", req.ToChain, req.FromChain)
+	}
+	tokenAddr, err := b.GetAssetAddress(req.Asset, req.FromChain)
+	if err != nil {
+		return nil, err
+	}
+	if tokenAddr == "" {
+		tokenAddr = precompileNativeTokens[req.FromChain]
+	}
+	currency, err := parseAddress("currency", tokenAddr)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := xcmDestination(req.ToChain, req.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount == nil || req.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid transfer amount")
+	}
+
+	call := &XCMPrecompileCall{ChainID: req.FromChain}
+	switch req.FromChain {
+	case ChainMoonbeam:
+		call.To = moonbeamXtokensPrecompile
+		call.Data, err = packCall(xtokensABI, "transfer", currency, req.Amount, dest, uint64(unlimitedWeight))
+	case ChainAstar:
+		call.To = astarXCMPrecompile
+		call.Data, err = packCall(astarXCMABI, "transfer", currency, req.Amount, dest,
+			precompileWeight{RefTime: unlimitedWeight, ProofSize: unlimitedWeight})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// xcmDestination is the recipient's location as seen from a parachain: up
+// to the relay chain, then into the destination parachain if it is one
+func xcmDestination(toChain ChainID, recipient string) (precompileMultilocation, error) {
+	dest := precompileMultilocation{Parents: 1}
+	if id, ok := parachainIDs[toChain]; ok {
+		dest.Interior = append(dest.Interior, binary.BigEndian.AppendUint32([]byte{0x00}, id))
+	}
+
+	if toChain == ChainMoonbeam {
+		// Moonbeam accounts are Ethereum addresses: AccountKey20, any network
+		addr, err := parseAddress("recipient", recipient)
+		if err != nil {
+			return dest, err
+		}
+		dest.Interior = append(dest.Interior, append(append([]byte{0x03}, addr.Bytes()...), 0x00))
+		return dest, nil
+	}
+	// AccountId32, any network
+	id, err := substrateAccountID(recipient)
+	if err != nil {
+		return dest, err
+	}
+	dest.Interior = append(dest.Interior, append(append([]byte{0x01}, id...), 0x00))
+	return dest, nil
+}
+
+// SendEOATransaction signs a call with an EOA's key and sends it through
+// the chain's RPC providers, returning the transaction hash
+func (b *XCMBridge) SendEOATransaction(ctx context.Context, chainID ChainID, privateKeyHex, to, data string) (string, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid EOA key: %w", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	target, err := parseAddress("target", to)
+	if err != nil {
+		return "", err
+	}
+	calldata, err := parseHexBytes("call data", data)
+	if err != nil {
+		return "", err
+	}
+
+	nonceHex, err := b.ethCall(ctx, chainID, "eth_getTransactionCount", from.Hex(), "pending")
+	if err != nil {
+		return "", fmt.Errorf("failed to get nonce: %w", err)
+	}
+	nonce, err := hexutil.DecodeUint64(nonceHex)
+	if err != nil {
+		return "", fmt.Errorf("failed to get nonce: %w", err)
+	}
+	gasHex, err := b.ethCall(ctx, chainID, "eth_estimateGas", map[string]string{
+		"from": from.Hex(),
+		"to":   target.Hex(),
+		"data": data,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	gas, err := hexutil.DecodeUint64(gasHex)
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	gasPrice, err := b.GetChainGasPrice(ctx, chainID)
+	if err != nil {
+		return "", err
+	}
+
+	// XCM weight varies with the destination's state
+	raw, hash, err := signLegacyTx(key, chainID, nonce, gasPrice, gas*6/5, target, calldata)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if _, err := b.ethCall(ctx, chainID, "eth_sendRawTransaction", hexutil.Encode(raw)); err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return hash, nil
+}
+
+// signLegacyTx RLP-encodes and signs a legacy transaction with EIP-155
+// replay protection, returning it raw with its hash
+func signLegacyTx(key *ecdsa.PrivateKey, chainID ChainID, nonce uint64, gasPrice *big.Int, gas uint64, to common.Address, data []byte) ([]byte, string, error) {
+	id := big.NewInt(int64(chainID))
+	unsigned, err := rlp.EncodeToBytes([]interface{}{nonce, gasPrice, gas, to, new(big.Int), data, id, uint(0), uint(0)})
+	if err != nil {
+		return nil, "", err
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(unsigned), key)
+	if err != nil {
+		return nil, "", err
+	}
+	v := new(big.Int).Add(new(big.Int).Mul(id, big.NewInt(2)), big.NewInt(35+int64(sig[64])))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	raw, err := rlp.EncodeToBytes([]interface{}{nonce, gasPrice, gas, to, new(big.Int), data, v, r, s})
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, hexutil.Encode(crypto.Keccak256(raw)), nil
+}
+
+// ethCall sends an eth_* request to a chain's providers and returns its
+// string result
+func (b *XCMBridge) ethCall(ctx context.Context, chainID ChainID, method string, params ...interface{}) (string, error) {
+	resp, err := b.post(ctx, chainID, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      1,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("%s: %w", method, err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("%s: %s", method, result.Error.Message)
+	}
+	return result.Result, nil
+}
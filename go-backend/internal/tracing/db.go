@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Database and Redis calls are traced only inside a span, a request or a
+// traced job, so pollers and lock renewals do not each start a trace of
+// their own
+
+// QueryTracer traces pgx queries as spans named after their SQL verb, with
+// the parameterized statement; arguments are never recorded
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	op := sqlOperation(data.SQL)
+	ctx, _ = Tracer().Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(op),
+			semconv.DBStatement(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	End(trace.SpanFromContext(ctx), err)
+}
+
+// sqlOperation returns a statement's first keyword, SELECT, INSERT and so on
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "SQL"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// RedisHook traces Redis commands and pipelines as spans named after the
+// command; arguments are never recorded
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startRedis(ctx, strings.ToUpper(cmd.Name())), nil
+}
+
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	End(trace.SpanFromContext(ctx), redisErr(cmd))
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return startRedis(ctx, "PIPELINE"), nil
+}
+
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if err = redisErr(cmd); err != nil {
+			break
+		}
+	}
+	End(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+func startRedis(ctx context.Context, op string) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	ctx, _ = Tracer().Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperation(op)),
+	)
+	return ctx
+}
+
+// redisErr is a command's error, not counting a missing key
+func redisErr(cmd redis.Cmder) error {
+	if err := cmd.Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter, the W3C trace
+// context propagator, and the spans around database, Redis and outbound RPC
+// calls
+package tracing
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/protocolbank/redpocket-backend/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer the backend's own spans come from
+const instrumentationName = "github.com/protocolbank/redpocket-backend"
+
+// Setup installs the W3C traceparent and baggage propagators and, when an
+// OTLP endpoint is configured, a tracer provider exporting spans to it. The
+// returned function flushes and stops the exporter. Without an endpoint
+// spans are not recorded, but incoming trace context is still carried
+// through to logs and outbound calls.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if cfg.OTLPEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(cfg.OTelServiceName)),
+		resource.WithFromEnv(),
+		resource.WithHost(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sampler follows OTEL_TRACES_SAMPLER, parent-based always-on by default
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer for the backend's own spans
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartRPC starts a client span for a JSON-RPC call to a bundler, paymaster
+// or chain node, named after the method and tagged with the server's host.
+// Trace context is not sent along: these are third-party providers.
+func StartRPC(ctx context.Context, peer, method, endpoint string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("rpc.system", "jsonrpc"),
+		semconv.RPCMethod(method),
+		semconv.PeerService(peer),
+	)
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		attrs = append(attrs, semconv.ServerAddress(u.Hostname()))
+	}
+	return Tracer().Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,40 @@
+-- Audience snapshots: token holder lists used to gate campaign claims
+CREATE TABLE IF NOT EXISTS audience_snapshots (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    source VARCHAR(16) NOT NULL, -- upload, chain
+    token_address VARCHAR(66),
+    chain_id BIGINT NOT NULL DEFAULT 8453,
+    block_number BIGINT NOT NULL DEFAULT 0,
+    min_balance NUMERIC(78, 0) NOT NULL DEFAULT 0,
+    holder_count INT NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_snapshot_status CHECK (status IN ('pending', 'computing', 'ready', 'failed'))
+);
+
+-- One row per eligible address in a snapshot
+CREATE TABLE IF NOT EXISTS audience_entries (
+    snapshot_id VARCHAR(32) NOT NULL REFERENCES audience_snapshots(id) ON DELETE CASCADE,
+    address VARCHAR(42) NOT NULL,
+    balance NUMERIC(78, 0) NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (snapshot_id, address)
+);
+
+-- Claims made by snapshot addresses, used for coverage reporting
+CREATE TABLE IF NOT EXISTS audience_claims (
+    snapshot_id VARCHAR(32) NOT NULL REFERENCES audience_snapshots(id) ON DELETE CASCADE,
+    address VARCHAR(42) NOT NULL,
+    red_pocket_id VARCHAR(32) NOT NULL REFERENCES red_pockets(id),
+    claim_id VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_audience_claim UNIQUE (red_pocket_id, address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audience_snapshots_campaign ON audience_snapshots(campaign_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audience_claims_snapshot ON audience_claims(snapshot_id);
@@ -0,0 +1,8 @@
+-- Immutable archive of a finished red pocket, served to the claim page
+CREATE TABLE IF NOT EXISTS red_pocket_summaries (
+    red_pocket_id VARCHAR(32) PRIMARY KEY REFERENCES red_pockets(id),
+    summary JSONB NOT NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    ipfs_cid VARCHAR(128),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,21 @@
+-- Content pinned to IPFS through the configured pinning provider
+CREATE TABLE IF NOT EXISTS ipfs_pins (
+    id VARCHAR(32) PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL, -- cover_image, nft_metadata, pocket_summary
+    ref_id VARCHAR(64),
+    name VARCHAR(255) NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    cid VARCHAR(128),
+    size BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL DEFAULT 'pinning',
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_pin_status CHECK (status IN ('pinning', 'pinned', 'unpinned', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ipfs_pins_ref ON ipfs_pins(kind, ref_id);
+
+-- Optional cover image (gateway or ipfs:// URL) shown on the claim page
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS cover_image TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,31 @@
+-- Inclusion tracking for claim payout transactions
+CREATE TABLE IF NOT EXISTS claim_receipts (
+    claim_id VARCHAR(32) PRIMARY KEY REFERENCES claims(id),
+    tx_hash VARCHAR(66) NOT NULL,
+    block_number BIGINT NOT NULL DEFAULT 0,
+    block_hash VARCHAR(66),
+    confirmations INT NOT NULL DEFAULT 0,
+    resubmit_count INT NOT NULL DEFAULT 0,
+    finalized_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Payout transactions that disappeared from the canonical chain
+CREATE TABLE IF NOT EXISTS claim_reorgs (
+    id BIGSERIAL PRIMARY KEY,
+    claim_id VARCHAR(32) NOT NULL REFERENCES claims(id),
+    tx_hash VARCHAR(66) NOT NULL,
+    block_number BIGINT NOT NULL DEFAULT 0,
+    block_hash VARCHAR(66),
+    action VARCHAR(16) NOT NULL, -- resubmitted, escalated
+    new_tx_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_reorgs_claim ON claim_reorgs(claim_id);
+
+-- 'reorged': payout vanished and could not be re-submitted automatically
+ALTER TABLE claims DROP CONSTRAINT IF EXISTS chk_claim_status;
+ALTER TABLE claims ADD CONSTRAINT chk_claim_status
+    CHECK (status IN ('pending', 'processing', 'success', 'failed', 'reorged'));
@@ -0,0 +1,26 @@
+-- Submitted ERC-4337 user operations, kept so stuck ones can be replaced
+CREATE TABLE IF NOT EXISTS user_operations (
+    user_op_hash VARCHAR(66) PRIMARY KEY,
+    claim_id VARCHAR(32) REFERENCES claims(id),
+    sender VARCHAR(42) NOT NULL,
+    nonce VARCHAR(80) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    kind VARCHAR(16) NOT NULL DEFAULT 'transfer', -- transfer, cancel
+    attempt INT NOT NULL DEFAULT 1,
+    op JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    tx_hash VARCHAR(66),
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    included_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_user_op_status CHECK (status IN ('pending', 'included', 'replaced', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_operations_pending ON user_operations(submitted_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_user_operations_nonce ON user_operations(sender, nonce);
+
+-- Payout attempts per claim; 'resubmitted' while a fee-bumped replacement is in flight
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 1;
+ALTER TABLE claims DROP CONSTRAINT IF EXISTS chk_claim_status;
+ALTER TABLE claims ADD CONSTRAINT chk_claim_status
+    CHECK (status IN ('pending', 'processing', 'resubmitted', 'success', 'failed', 'reorged'));
@@ -0,0 +1,18 @@
+-- Claim payouts run on a worker pool; claims are recorded as 'pending' and
+-- the on-chain transfer is picked up from this queue
+CREATE TABLE IF NOT EXISTS payout_jobs (
+    id VARCHAR(32) PRIMARY KEY,
+    claim_id VARCHAR(32) NOT NULL UNIQUE REFERENCES claims(id),
+    status VARCHAR(16) NOT NULL DEFAULT 'queued',
+    attempts INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_payout_job_status CHECK (status IN ('queued', 'running', 'done', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_queued ON payout_jobs(next_run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_running ON payout_jobs(locked_at) WHERE status = 'running';
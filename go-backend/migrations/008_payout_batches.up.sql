@@ -0,0 +1,25 @@
+-- Batched settlement: queued claims of a pocket are netted per recipient and
+-- paid from the pocket's payout wallet in one executeBatch user operation
+CREATE TABLE IF NOT EXISTS payout_batches (
+    id VARCHAR(32) PRIMARY KEY,
+    red_pocket_id VARCHAR(32) NOT NULL REFERENCES red_pockets(id),
+    sender VARCHAR(42) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'processing',
+    claim_count INT NOT NULL,
+    transfer_count INT NOT NULL,
+    total_amount DECIMAL(20, 8) NOT NULL,
+    tx_hash VARCHAR(66),
+    user_op_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    settled_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_payout_batch_status CHECK (status IN ('processing', 'success', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_batches_red_pocket ON payout_batches(red_pocket_id);
+
+ALTER TABLE payout_jobs ADD COLUMN IF NOT EXISTS batch_id VARCHAR(32) REFERENCES payout_batches(id);
+CREATE INDEX IF NOT EXISTS idx_payout_jobs_batch ON payout_jobs(batch_id);
+
+ALTER TABLE user_operations ADD COLUMN IF NOT EXISTS batch_id VARCHAR(32) REFERENCES payout_batches(id);
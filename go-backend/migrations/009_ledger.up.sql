@@ -0,0 +1,55 @@
+-- Credit mode: claims of a campaign credit an off-chain ledger balance and
+-- users withdraw on demand
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS payout_mode VARCHAR(16) NOT NULL DEFAULT 'onchain';
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS chk_campaign_payout_mode;
+ALTER TABLE campaigns ADD CONSTRAINT chk_campaign_payout_mode CHECK (payout_mode IN ('onchain', 'credit'));
+
+CREATE TABLE IF NOT EXISTS ledger_balances (
+    user_id VARCHAR(255) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    token VARCHAR(32) NOT NULL,
+    token_address VARCHAR(66) NOT NULL DEFAULT '',
+    balance DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, chain_id, token),
+    CONSTRAINT chk_ledger_balance_non_negative CHECK (balance >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    token VARCHAR(32) NOT NULL,
+    token_address VARCHAR(66) NOT NULL DEFAULT '',
+    amount DECIMAL(20, 8) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    ref_id VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_ledger_entry_kind CHECK (kind IN ('claim', 'withdrawal', 'refund')),
+    CONSTRAINT uq_ledger_entry_ref UNIQUE (kind, ref_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_user ON ledger_entries(user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS withdrawals (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    token VARCHAR(32) NOT NULL,
+    token_address VARCHAR(66) NOT NULL DEFAULT '',
+    amount DECIMAL(20, 8) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    tx_hash VARCHAR(66),
+    user_op_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_withdrawal_status CHECK (status IN ('pending', 'processing', 'success', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_user ON withdrawals(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_withdrawals_open ON withdrawals(created_at) WHERE status IN ('pending', 'processing');
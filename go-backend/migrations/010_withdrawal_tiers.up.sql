@@ -0,0 +1,30 @@
+-- Withdrawal speeds: instant withdrawals go out right away for a fee, batch
+-- withdrawals are free and wait for the next processing window
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS speed VARCHAR(16) NOT NULL DEFAULT 'instant';
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS fee DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS batch_id VARCHAR(32);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS chk_withdrawal_speed;
+ALTER TABLE withdrawals ADD CONSTRAINT chk_withdrawal_speed CHECK (speed IN ('instant', 'batch'));
+
+DROP INDEX IF EXISTS idx_withdrawals_open;
+CREATE INDEX IF NOT EXISTS idx_withdrawals_open ON withdrawals(scheduled_at) WHERE status IN ('pending', 'processing');
+CREATE INDEX IF NOT EXISTS idx_withdrawals_batch ON withdrawals(batch_id) WHERE batch_id IS NOT NULL;
+
+-- Per-token withdrawal limits and fees. Tokens without a row use the
+-- WITHDRAWAL_* defaults from config.
+CREATE TABLE IF NOT EXISTS withdrawal_token_settings (
+    token VARCHAR(32) PRIMARY KEY,
+    min_amount DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    batch_fee DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    instant_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    instant_fee DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    instant_fee_bps INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_withdrawal_fees_non_negative CHECK (min_amount >= 0 AND batch_fee >= 0 AND instant_fee >= 0 AND instant_fee_bps >= 0)
+);
+
+INSERT INTO withdrawal_token_settings (token, min_amount, batch_fee, instant_enabled, instant_fee, instant_fee_bps)
+VALUES ('USDC', 1, 0, TRUE, 0.5, 10)
+ON CONFLICT (token) DO NOTHING;
@@ -0,0 +1,26 @@
+-- Refunds of the unclaimed remainder of expired red pockets to the
+-- enterprise that funded them
+CREATE TABLE IF NOT EXISTS red_pocket_refunds (
+    id VARCHAR(32) PRIMARY KEY,
+    red_pocket_id VARCHAR(32) NOT NULL UNIQUE REFERENCES red_pockets(id),
+    recipient_id VARCHAR(255) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    token VARCHAR(32) NOT NULL,
+    token_address VARCHAR(66) NOT NULL DEFAULT '',
+    amount DECIMAL(20, 8) NOT NULL,
+    from_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    triggered_by VARCHAR(16) NOT NULL DEFAULT 'auto',
+    tx_hash VARCHAR(66),
+    user_op_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_refund_status CHECK (status IN ('pending', 'processing', 'success', 'failed')),
+    CONSTRAINT chk_refund_trigger CHECK (triggered_by IN ('auto', 'manual'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_open ON red_pocket_refunds(created_at) WHERE status IN ('pending', 'processing');
+CREATE INDEX IF NOT EXISTS idx_red_pockets_refundable ON red_pockets(expires_at) WHERE status = 'expired' AND remaining_amount > 0;
@@ -0,0 +1,2 @@
+-- Hosted claim page branding per campaign, served with the public pocket
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS claim_page JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,9 @@
+-- Optional per-campaign terms of service. When a campaign has terms, claims
+-- must accept the current version and the acceptance is recorded on the claim.
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS terms_version VARCHAR(32);
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS terms_text TEXT;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS terms_updated_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS terms_version VARCHAR(32);
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS terms_accepted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS terms_ip VARCHAR(45);
@@ -0,0 +1,6 @@
+-- Withdrawals straight from a user's AA wallet, alongside ledger withdrawals
+-- paid from the treasury
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'ledger';
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS from_address VARCHAR(42);
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS chk_withdrawal_source;
+ALTER TABLE withdrawals ADD CONSTRAINT chk_withdrawal_source CHECK (source IN ('ledger', 'wallet'));
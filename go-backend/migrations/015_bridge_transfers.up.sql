@@ -0,0 +1,27 @@
+-- Cross-chain bridge transfers, tracked until they land on the destination
+-- chain. next_check_at is when the tracker next advances an unfinished transfer.
+CREATE TABLE IF NOT EXISTS bridge_transfers (
+    id VARCHAR(96) PRIMARY KEY,
+    protocol VARCHAR(16) NOT NULL,
+    from_chain BIGINT NOT NULL,
+    to_chain BIGINT NOT NULL,
+    asset VARCHAR(32) NOT NULL,
+    amount NUMERIC(78, 0) NOT NULL,
+    sender VARCHAR(66) NOT NULL,
+    recipient VARCHAR(66) NOT NULL,
+    source_tx_hash VARCHAR(66),
+    dest_tx_hash VARCHAR(66),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    estimated_time INT NOT NULL DEFAULT 0,
+    error TEXT,
+    next_check_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_bridge_protocol CHECK (protocol IN ('xcm', 'hyperbridge', 'snowbridge')),
+    CONSTRAINT chk_bridge_status CHECK (status IN ('pending', 'confirming', 'relaying', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bridge_transfers_due ON bridge_transfers(next_check_at)
+    WHERE status IN ('pending', 'confirming', 'relaying');
+CREATE INDEX IF NOT EXISTS idx_bridge_transfers_sender ON bridge_transfers(sender, created_at DESC);
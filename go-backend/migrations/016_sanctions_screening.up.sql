@@ -0,0 +1,38 @@
+-- Sanctions screening of payout and withdrawal destinations
+
+-- Addresses on the synced open sanctions list (SANCTIONS_PROVIDER=ofac), lowercase
+CREATE TABLE IF NOT EXISTS sanctioned_addresses (
+    address VARCHAR(66) PRIMARY KEY,
+    source VARCHAR(32) NOT NULL,
+    synced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Every screening, kept for audit. Hits are flagged until compliance reviews them.
+CREATE TABLE IF NOT EXISTS address_screenings (
+    id VARCHAR(32) PRIMARY KEY,
+    address VARCHAR(66) NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    result VARCHAR(16) NOT NULL,
+    reference TEXT,
+    subject_type VARCHAR(16) NOT NULL,
+    subject_id VARCHAR(32) NOT NULL,
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_screening_result CHECK (result IN ('clear', 'hit')),
+    CONSTRAINT chk_screening_subject CHECK (subject_type IN ('claim', 'withdrawal'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_address_screenings_subject ON address_screenings(subject_type, subject_id);
+CREATE INDEX IF NOT EXISTS idx_address_screenings_hits ON address_screenings(created_at DESC) WHERE result = 'hit';
+
+-- Latest screening of each claim and withdrawal
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS screening_id VARCHAR(32);
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS screening_result VARCHAR(16);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS screening_id VARCHAR(32);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS screening_result VARCHAR(16);
+
+-- Claims whose destination is sanctioned are blocked instead of paid
+ALTER TABLE claims DROP CONSTRAINT IF EXISTS chk_claim_status;
+ALTER TABLE claims ADD CONSTRAINT chk_claim_status
+    CHECK (status IN ('pending', 'processing', 'resubmitted', 'success', 'failed', 'reorged', 'blocked'));
@@ -0,0 +1,3 @@
+-- Travel rule originator/beneficiary information sent with large withdrawals,
+-- exported as IVMS101
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS travel_rule JSONB;
@@ -0,0 +1,4 @@
+-- Optional claim password (bcrypt hash) and CAPTCHA requirement per red pocket
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS claim_password_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS captcha_mode VARCHAR(20) NOT NULL DEFAULT ''
+    CHECK (captcha_mode IN ('', 'hcaptcha', 'turnstile'));
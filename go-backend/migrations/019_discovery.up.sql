@@ -0,0 +1,6 @@
+-- Opt-in listing of a campaign's active pockets on the public discovery feed
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS discoverable BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS discovery_mask_amounts BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS visibility_updated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_red_pockets_active_created ON red_pockets(created_at DESC) WHERE status = 'active';
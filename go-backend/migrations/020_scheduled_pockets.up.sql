@@ -0,0 +1,15 @@
+-- Scheduled and recurring red pockets. A pocket with a future starts_at is
+-- 'scheduled' until the scheduler opens it; recurring pockets share a
+-- series_id and each opening creates the next instance.
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS starts_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS recurrence VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS recurrence_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS series_id VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE red_pockets DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE red_pockets ADD CONSTRAINT chk_status
+    CHECK (status IN ('scheduled', 'active', 'depleted', 'expired', 'cancelled'));
+
+CREATE INDEX IF NOT EXISTS idx_red_pockets_scheduled ON red_pockets(starts_at) WHERE status = 'scheduled';
+-- One instance per occurrence, even if two schedulers race
+CREATE UNIQUE INDEX IF NOT EXISTS uq_red_pockets_series_start ON red_pockets(series_id, starts_at) WHERE series_id <> '';
@@ -0,0 +1,21 @@
+-- Engagement signals ranking the discovery feed
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS locale VARCHAR(8) NOT NULL DEFAULT '';
+
+-- One row per sharer (platform user, or client IP when anonymous) and pocket
+CREATE TABLE IF NOT EXISTS pocket_shares (
+    red_pocket_id VARCHAR(64) NOT NULL REFERENCES red_pockets(id),
+    sharer VARCHAR(128) NOT NULL,
+    platform VARCHAR(32) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (red_pocket_id, sharer)
+);
+
+-- Recomputed every minute for active pockets of discoverable campaigns
+CREATE TABLE IF NOT EXISTS pocket_engagement (
+    red_pocket_id VARCHAR(64) PRIMARY KEY REFERENCES red_pockets(id),
+    claims_per_minute NUMERIC(12, 4) NOT NULL DEFAULT 0,
+    unique_claimers INT NOT NULL DEFAULT 0,
+    shares INT NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- Claimers can split their payouts across several addresses. The preference
+-- is copied onto each claim when it is made, so changing it later does not
+-- redirect payouts already queued.
+CREATE TABLE IF NOT EXISTS payout_preferences (
+    user_id VARCHAR(255) PRIMARY KEY,
+    splits JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS payout_splits JSONB;
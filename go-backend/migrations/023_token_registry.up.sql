@@ -0,0 +1,50 @@
+-- Tokens red pockets can be created in, per chain. An empty address is the
+-- chain's native token. price_feed_id is the oracle feed (e.g. a Pyth price
+-- ID) used to value the token in USD.
+CREATE TABLE IF NOT EXISTS tokens (
+    symbol VARCHAR(32) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    address VARCHAR(42) NOT NULL DEFAULT '',
+    decimals INT NOT NULL CHECK (decimals BETWEEN 0 AND 36),
+    price_feed_id VARCHAR(128) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (symbol, chain_id)
+);
+
+INSERT INTO tokens (symbol, chain_id, address, decimals) VALUES
+    ('USDC', 8453, '0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913', 6),
+    ('USDT', 8453, '0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2', 6),
+    ('DAI', 8453, '0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb', 18),
+    ('ETH', 8453, '', 18),
+    ('USDC', 1, '0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48', 6),
+    ('USDT', 1, '0xdAC17F958D2ee523a2206206994597C13D831ec7', 6),
+    ('DAI', 1, '0x6B175474E89094C44Da98b954EedeAC495271d0F', 18),
+    ('ETH', 1, '', 18),
+    ('USDC', 137, '0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174', 6),
+    ('USDT', 137, '0xc2132D05D31c914a87C6611C10748AEb04B58e8F', 6),
+    ('USDC', 1284, '0x931715FEE2d06333043d11F658C8CE934aC61D0c', 6),
+    ('DOT', 1284, '0xFfFFfFff1FcaCBd218EDc0EbA20Fc2308C778080', 10)
+ON CONFLICT (symbol, chain_id) DO NOTHING;
+
+-- Exact amounts in minor units. Existing pockets were all paid out with 6
+-- decimals; amount and remaining_amount stay as display values.
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS decimals INT NOT NULL DEFAULT 6;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS amount_units NUMERIC(78, 0);
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS remaining_units NUMERIC(78, 0);
+UPDATE red_pockets SET amount_units = ROUND(amount * 1000000), remaining_units = ROUND(remaining_amount * 1000000)
+WHERE amount_units IS NULL;
+ALTER TABLE red_pockets ALTER COLUMN amount_units SET NOT NULL;
+ALTER TABLE red_pockets ALTER COLUMN remaining_units SET NOT NULL;
+
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS amount_units NUMERIC(78, 0);
+UPDATE claims SET amount_units = ROUND(amount * 1000000) WHERE amount_units IS NULL;
+ALTER TABLE claims ALTER COLUMN amount_units SET NOT NULL;
+
+ALTER TABLE red_pocket_refunds ADD COLUMN IF NOT EXISTS amount_units NUMERIC(78, 0);
+UPDATE red_pocket_refunds SET amount_units = ROUND(amount * 1000000) WHERE amount_units IS NULL;
+ALTER TABLE red_pocket_refunds ALTER COLUMN amount_units SET NOT NULL;
+
+-- A remainder too small to show in remaining_amount is still refunded
+DROP INDEX IF EXISTS idx_red_pockets_refundable;
+CREATE INDEX IF NOT EXISTS idx_red_pockets_refundable ON red_pockets(expires_at) WHERE status = 'expired' AND remaining_units > 0;
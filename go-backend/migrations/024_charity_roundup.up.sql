@@ -0,0 +1,18 @@
+-- Charity round-up: a share of every claim of a campaign goes to a charity
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS charity_address VARCHAR(42);
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS charity_name VARCHAR(120);
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS charity_bps INT NOT NULL DEFAULT 0;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS charity_updated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS chk_campaign_charity_bps;
+ALTER TABLE campaigns ADD CONSTRAINT chk_campaign_charity_bps CHECK (charity_bps BETWEEN 0 AND 10000);
+
+-- Donation taken from a claim, included in amount
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS charity_address VARCHAR(42);
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS donation_amount DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS donation_units NUMERIC(78, 0) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_claims_donations ON claims(red_pocket_id) WHERE donation_units > 0;
+
+-- Credit-mode donations pass through the campaign's charity ledger account
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS chk_ledger_entry_kind;
+ALTER TABLE ledger_entries ADD CONSTRAINT chk_ledger_entry_kind CHECK (kind IN ('claim', 'withdrawal', 'refund', 'donation'));
@@ -0,0 +1,15 @@
+-- Claim eligibility rules. A rule belongs to a campaign or to a red pocket
+-- (the first pocket of a recurring series covers the whole series); every
+-- rule of both must pass for a claim to go through.
+CREATE TABLE IF NOT EXISTS eligibility_rules (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) REFERENCES campaigns(id) ON DELETE CASCADE,
+    red_pocket_id VARCHAR(32) REFERENCES red_pockets(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL, -- allowlist, token_gate, account_age, geo
+    params JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_eligibility_rule_scope CHECK ((campaign_id IS NULL) <> (red_pocket_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_eligibility_rules_campaign ON eligibility_rules(campaign_id) WHERE campaign_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_eligibility_rules_red_pocket ON eligibility_rules(red_pocket_id) WHERE red_pocket_id IS NOT NULL;
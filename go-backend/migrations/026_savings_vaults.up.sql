@@ -0,0 +1,53 @@
+-- Savings: claimers can have their payouts deposited into a whitelisted
+-- yield vault instead of their wallet. protocol decides the calls made:
+-- erc4626 vaults take deposit(assets, receiver) and mint their own shares,
+-- aave_v3 pools take supply(asset, amount, onBehalfOf, 0) and mint share_address
+-- (the aToken). Vaults are seeded disabled; an operator enables one once its
+-- addresses are checked on the chain.
+CREATE TABLE IF NOT EXISTS yield_vaults (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(120) NOT NULL,
+    protocol VARCHAR(16) NOT NULL CHECK (protocol IN ('erc4626', 'aave_v3')),
+    chain_id BIGINT NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    asset_address VARCHAR(42) NOT NULL,
+    share_address VARCHAR(42) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO yield_vaults (id, name, protocol, chain_id, address, asset_address, share_address) VALUES
+    ('aave_base_usdc', 'Aave v3 USDC', 'aave_v3', 8453,
+        '0xA238Dd80C259a72e81d7e4664a9801593F98d1c5', '0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913', '0x4e65fE4DbA92790696d040ac24Aa414708F5c0AB'),
+    ('moonwell_base_usdc', 'Moonwell Flagship USDC', 'erc4626', 8453,
+        '0xc1256Ae5FF1cf2719D4937adb3bbCCab2E00A2Ca', '0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913', '0xc1256Ae5FF1cf2719D4937adb3bbCCab2E00A2Ca')
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS savings_preferences (
+    user_id VARCHAR(255) PRIMARY KEY,
+    vault_id VARCHAR(32) NOT NULL REFERENCES yield_vaults(id),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- The vault a claim's own share is deposited into, copied from the
+-- claimer's preference when the claim is made
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS savings_vault_id VARCHAR(32);
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS savings_units NUMERIC(78, 0) NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_claims_savings ON claims(claimer_id, savings_vault_id) WHERE savings_vault_id IS NOT NULL;
+
+-- Withdrawals from a vault back to the claimer's wallet
+CREATE TABLE IF NOT EXISTS vault_withdrawals (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    vault_id VARCHAR(32) NOT NULL REFERENCES yield_vaults(id),
+    wallet_address VARCHAR(42) NOT NULL,
+    amount_units NUMERIC(78, 0) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, success, failed
+    tx_hash VARCHAR(66),
+    user_op_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_vault_withdrawals_user ON vault_withdrawals(user_id, vault_id);
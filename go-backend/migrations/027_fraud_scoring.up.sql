@@ -0,0 +1,41 @@
+-- Anti-sybil scoring: every claim attempt that gets past the eligibility
+-- checks is scored on IP and device reuse, wallet clustering and claim
+-- velocity. The score picks an action: allow, captcha, hold (delay the
+-- payout) or block. Attempts that were not allowed can be reviewed by the
+-- campaign's enterprise.
+CREATE TABLE IF NOT EXISTS fraud_assessments (
+    id VARCHAR(32) PRIMARY KEY,
+    red_pocket_id VARCHAR(32) NOT NULL REFERENCES red_pockets(id),
+    campaign_id VARCHAR(32) NOT NULL,
+    claim_id VARCHAR(32),
+    platform VARCHAR(32) NOT NULL,
+    platform_id VARCHAR(255) NOT NULL,
+    client_ip VARCHAR(64),
+    device_fingerprint VARCHAR(128),
+    wallet_address VARCHAR(42), -- holder proof wallet, if any
+    score INT NOT NULL,
+    signals JSONB NOT NULL DEFAULT '[]',
+    action VARCHAR(16) NOT NULL,
+    review_status VARCHAR(16),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_fraud_action CHECK (action IN ('allow', 'captcha', 'hold', 'block')),
+    CONSTRAINT chk_fraud_review CHECK (review_status IN ('approved', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_fraud_ip ON fraud_assessments(client_ip, created_at) WHERE client_ip IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_fraud_device ON fraud_assessments(device_fingerprint, created_at) WHERE device_fingerprint IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_fraud_wallet ON fraud_assessments(LOWER(wallet_address)) WHERE wallet_address IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_fraud_claimer ON fraud_assessments(platform, platform_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_fraud_flagged ON fraud_assessments(campaign_id, created_at DESC) WHERE action <> 'allow';
+
+-- Held claims wait for review, or for the hold to run out, before they are paid
+ALTER TABLE claims DROP CONSTRAINT IF EXISTS chk_claim_status;
+ALTER TABLE claims ADD CONSTRAINT chk_claim_status
+    CHECK (status IN ('pending', 'processing', 'resubmitted', 'success', 'failed', 'reorged', 'blocked', 'held'));
+
+ALTER TABLE payout_jobs DROP CONSTRAINT IF EXISTS chk_payout_job_status;
+ALTER TABLE payout_jobs ADD CONSTRAINT chk_payout_job_status
+    CHECK (status IN ('queued', 'running', 'done', 'failed', 'held'));
@@ -0,0 +1,19 @@
+-- Fiat off-ramp: a withdrawal from the user's wallet can cash out through an
+-- off-ramp provider. The withdrawal waits in awaiting_order until the user
+-- places the order in the provider's widget and the provider's webhook gives
+-- the deposit address; the tokens are then sent there like any other
+-- wallet withdrawal. The provider's order status is tracked alongside.
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS offramp_provider VARCHAR(16);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS offramp_order_id VARCHAR(64);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS offramp_status VARCHAR(32);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS fiat_currency VARCHAR(8);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS fiat_amount DECIMAL(20, 2);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS payment_method VARCHAR(64);
+
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS chk_withdrawal_status;
+ALTER TABLE withdrawals ADD CONSTRAINT chk_withdrawal_status
+    CHECK (status IN ('awaiting_order', 'pending', 'processing', 'success', 'failed'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_withdrawals_offramp_order
+    ON withdrawals(offramp_provider, offramp_order_id) WHERE offramp_order_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_withdrawals_awaiting_order ON withdrawals(created_at) WHERE status = 'awaiting_order';
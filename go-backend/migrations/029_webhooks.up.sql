@@ -0,0 +1,38 @@
+-- Enterprise webhooks: campaign owners register URLs to be told about their
+-- pockets' events. Every event is delivered to each matching webhook as a
+-- signed POST, retried with exponential backoff until it succeeds or runs
+-- out of attempts. Deliveries are kept as the webhook's delivery log.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id VARCHAR(32) PRIMARY KEY,
+    enterprise_id VARCHAR(32) NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    events TEXT[] NOT NULL,
+    description VARCHAR(255),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_enterprise ON webhooks(enterprise_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id VARCHAR(32) PRIMARY KEY,
+    webhook_id VARCHAR(32) NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id VARCHAR(32) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    response_status INT,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_webhook_delivery_status CHECK (status IN ('pending', 'sending', 'success', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status IN ('pending', 'sending');
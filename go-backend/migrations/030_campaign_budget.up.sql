@@ -0,0 +1,16 @@
+-- Campaign spent_budget now follows claims as they settle. Backfill it from
+-- the claims already paid.
+UPDATE campaigns camp
+SET spent_budget = s.amount, updated_at = NOW()
+FROM (
+    SELECT rp.campaign_id, SUM(c.amount) AS amount
+    FROM claims c
+    JOIN red_pockets rp ON rp.id = c.red_pocket_id
+    WHERE c.status = 'success'
+    GROUP BY rp.campaign_id
+) s
+WHERE camp.id = s.campaign_id;
+
+-- Budget checks sum the remaining amounts of a campaign's open pockets
+CREATE INDEX IF NOT EXISTS idx_red_pockets_campaign_open ON red_pockets(campaign_id)
+    WHERE status IN ('active', 'scheduled');
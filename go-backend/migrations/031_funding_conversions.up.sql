@@ -0,0 +1,26 @@
+-- Campaign funding: each campaign has a funding wallet enterprises deposit
+-- into. Deposits of any other registered token are swapped on a DEX into the
+-- campaign's payout token, each swap recorded here.
+CREATE TABLE IF NOT EXISTS funding_conversions (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+    sell_token VARCHAR(32) NOT NULL,
+    sell_token_address VARCHAR(42), -- NULL for the native token
+    sell_units NUMERIC(78, 0) NOT NULL,
+    buy_token VARCHAR(32) NOT NULL,
+    buy_token_address VARCHAR(42) NOT NULL,
+    quoted_units NUMERIC(78, 0) NOT NULL,
+    min_buy_units NUMERIC(78, 0) NOT NULL, -- the swap reverts below this
+    provider VARCHAR(16) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, success, failed
+    tx_hash VARCHAR(66),
+    user_op_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_funding_conversions_campaign ON funding_conversions(campaign_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_funding_conversions_pending ON funding_conversions(campaign_id) WHERE status = 'pending';
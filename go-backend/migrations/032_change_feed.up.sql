@@ -0,0 +1,49 @@
+-- Change feed: pocket and claim changes are announced on the
+-- redpocket_changes channel when their transaction commits, so the backend
+-- can push them to streams without polling or publishing from every code path.
+CREATE OR REPLACE FUNCTION notify_red_pocket_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status = OLD.status AND NEW.claimed_count = OLD.claimed_count THEN
+        RETURN NEW;
+    END IF;
+    PERFORM pg_notify('redpocket_changes', json_build_object(
+        'table', TG_TABLE_NAME,
+        'op', TG_OP,
+        'id', NEW.id,
+        'redPocketId', NEW.id,
+        'status', NEW.status,
+        'oldStatus', CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_claim_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status = OLD.status THEN
+        RETURN NEW;
+    END IF;
+    PERFORM pg_notify('redpocket_changes', json_build_object(
+        'table', TG_TABLE_NAME,
+        'op', TG_OP,
+        'id', NEW.id,
+        'redPocketId', NEW.red_pocket_id,
+        'status', NEW.status,
+        'oldStatus', CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        'amount', NEW.amount
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_red_pockets_change ON red_pockets;
+-- Deferred to commit so a claim is announced before the depletion it causes
+CREATE CONSTRAINT TRIGGER trg_red_pockets_change
+    AFTER INSERT OR UPDATE ON red_pockets
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION notify_red_pocket_change();
+
+DROP TRIGGER IF EXISTS trg_claims_change ON claims;
+CREATE TRIGGER trg_claims_change
+    AFTER INSERT OR UPDATE ON claims
+    FOR EACH ROW EXECUTE FUNCTION notify_claim_change();
@@ -0,0 +1,3 @@
+-- Wallets are bound to the EntryPoint they were created for. Existing
+-- wallets are v0.6 accounts.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS entry_point_version VARCHAR(8) NOT NULL DEFAULT '0.6';
@@ -0,0 +1,29 @@
+-- Pocket deposits: with POCKET_DEPOSIT_REQUIRED a new pocket waits in
+-- 'awaiting_funding' until its sender's deposit into the pocket's payout
+-- wallet is confirmed on chain. Pockets whose deposit never arrives end up
+-- 'unfunded'.
+ALTER TABLE red_pockets DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE red_pockets ADD CONSTRAINT chk_status
+    CHECK (status IN ('awaiting_funding', 'unfunded', 'scheduled', 'active', 'depleted', 'expired', 'cancelled'));
+
+CREATE TABLE IF NOT EXISTS pocket_deposits (
+    red_pocket_id VARCHAR(32) PRIMARY KEY REFERENCES red_pockets(id),
+    chain_id BIGINT NOT NULL,
+    address VARCHAR(42) NOT NULL, -- the pocket's payout wallet
+    token_address VARCHAR(42), -- NULL for the native token
+    required_units NUMERIC(78, 0) NOT NULL,
+    received_units NUMERIC(78, 0) NOT NULL DEFAULT 0, -- confirmed balance at the last check
+    confirmed_block BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, funded, expired
+    deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+    checked_at TIMESTAMP WITH TIME ZONE,
+    funded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pocket_deposits_pending ON pocket_deposits(deadline) WHERE status = 'pending';
+
+-- Pockets awaiting their deposit hold campaign budget too
+DROP INDEX IF EXISTS idx_red_pockets_campaign_open;
+CREATE INDEX IF NOT EXISTS idx_red_pockets_campaign_open ON red_pockets(campaign_id)
+    WHERE status IN ('awaiting_funding', 'active', 'scheduled');
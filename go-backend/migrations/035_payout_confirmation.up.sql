@@ -0,0 +1,12 @@
+-- Payout finality on the claim itself: 'confirmed' once the payout has
+-- RECEIPT_CONFIRMATIONS blocks on the canonical chain, 'reverted' when it
+-- reverted on chain and could not be paid again automatically
+ALTER TABLE claims DROP CONSTRAINT IF EXISTS chk_claim_status;
+ALTER TABLE claims ADD CONSTRAINT chk_claim_status
+    CHECK (status IN ('pending', 'processing', 'resubmitted', 'success', 'confirmed', 'failed', 'reverted', 'reorged', 'blocked', 'held'));
+
+-- Payouts already final are confirmed
+UPDATE claims c
+SET status = 'confirmed'
+FROM claim_receipts r
+WHERE r.claim_id = c.id AND c.status = 'success' AND r.finalized_at IS NOT NULL;
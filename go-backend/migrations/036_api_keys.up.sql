@@ -0,0 +1,14 @@
+-- Enterprise API keys: an alternative to bearer tokens where every request
+-- is signed with HMAC-SHA256 over its method, path, timestamp, nonce and
+-- body using the key's secret. Revoked keys are kept so their IDs are not
+-- reused.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(32) PRIMARY KEY,
+    enterprise_id VARCHAR(32) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_enterprise ON api_keys(enterprise_id, created_at);
@@ -0,0 +1,15 @@
+-- Slack workspaces that installed the app, with the bot token issued for
+-- each. Tokens are sealed like wallet keys. Reinstalling a workspace replaces
+-- its row; uninstalling deletes it.
+CREATE TABLE IF NOT EXISTS slack_installations (
+    team_id VARCHAR(32) PRIMARY KEY,
+    team_name VARCHAR(255) NOT NULL DEFAULT '',
+    enterprise_id VARCHAR(32) NOT NULL,
+    bot_user_id VARCHAR(32) NOT NULL DEFAULT '',
+    bot_token TEXT NOT NULL,
+    scope TEXT NOT NULL DEFAULT '',
+    installed_by VARCHAR(32) NOT NULL DEFAULT '',
+    installed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_slack_installations_enterprise ON slack_installations(enterprise_id);
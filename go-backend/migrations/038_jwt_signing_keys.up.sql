@@ -0,0 +1,12 @@
+-- Keys that sign enterprise tokens. The newest unretired key signs; keys it
+-- replaced keep verifying until retires_at, a grace window longer than a
+-- token lives. Private keys are sealed like wallet keys.
+CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+    kid VARCHAR(32) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    retires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_jwt_signing_keys_created ON jwt_signing_keys(created_at);
@@ -0,0 +1,20 @@
+-- Failed claim attempts, keyed by the support reference returned to the
+-- claimer, with what support needs to diagnose them. Rows are purged after
+-- CLAIM_FAILURE_RETENTION_DAYS.
+CREATE TABLE IF NOT EXISTS claim_failures (
+    reference VARCHAR(16) PRIMARY KEY,
+    red_pocket_id VARCHAR(64) NOT NULL,
+    platform VARCHAR(32) NOT NULL DEFAULT '',
+    platform_id VARCHAR(255) NOT NULL DEFAULT '',
+    wallet_address VARCHAR(64) NOT NULL DEFAULT '',
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    country VARCHAR(8) NOT NULL DEFAULT '',
+    device_fingerprint VARCHAR(128) NOT NULL DEFAULT '',
+    locale VARCHAR(8) NOT NULL DEFAULT '',
+    error_code VARCHAR(64) NOT NULL DEFAULT '',
+    error TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_failures_created ON claim_failures(created_at);
+CREATE INDEX IF NOT EXISTS idx_claim_failures_pocket ON claim_failures(red_pocket_id, created_at);
@@ -0,0 +1,15 @@
+-- Enterprise API keys sent as X-API-Key are stored only as their SHA-256
+-- hash. Rotating a key keeps the previous one authenticating until
+-- api_key_previous_expires_at; revoking clears both.
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS api_key_hash VARCHAR(64) UNIQUE;
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS api_key_created_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS api_key_previous_hash VARCHAR(64) UNIQUE;
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS api_key_previous_expires_at TIMESTAMP WITH TIME ZONE;
+
+-- Hash the plaintext keys issued so far, then drop them
+UPDATE enterprises
+SET api_key_hash = encode(sha256(convert_to(api_key, 'UTF8')), 'hex'),
+    api_key_created_at = created_at
+WHERE api_key_hash IS NULL;
+
+ALTER TABLE enterprises DROP COLUMN IF EXISTS api_key;
@@ -0,0 +1,62 @@
+-- Claim state machine: 'retrying' for a payout queued again after a failed
+-- transfer, 'refunded' for an unpaid claim whose amount went back to the
+-- pocket's creator. model.ClaimStatus lists the allowed transitions.
+ALTER TABLE claims DROP CONSTRAINT IF EXISTS chk_claim_status;
+ALTER TABLE claims ADD CONSTRAINT chk_claim_status
+    CHECK (status IN ('pending', 'held', 'processing', 'retrying', 'resubmitted', 'success', 'confirmed', 'failed', 'blocked', 'reverted', 'reorged', 'refunded'));
+
+-- Why the claim last changed status, and when
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Claims already queued again after a failed transfer are retrying
+UPDATE claims c
+SET status = 'retrying'
+FROM payout_jobs j
+WHERE j.claim_id = c.id AND c.status = 'pending' AND j.status = 'queued' AND j.last_error IS NOT NULL;
+
+UPDATE claims SET status_changed_at = COALESCE(completed_at, created_at) WHERE status_changed_at IS NULL;
+
+-- Every status a claim has been in, from when it was recorded
+CREATE TABLE IF NOT EXISTS claim_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    claim_id VARCHAR(32) NOT NULL,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    tx_hash VARCHAR(66),
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_status_history_claim ON claim_status_history(claim_id, id);
+CREATE INDEX IF NOT EXISTS idx_claim_status_history_created ON claim_status_history(created_at, to_status);
+
+-- Recorded by trigger so every code path that moves a claim is covered.
+-- The statement that changes the status sets status_reason; one that does
+-- not leaves no reason rather than the previous one.
+CREATE OR REPLACE FUNCTION record_claim_transition() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status = OLD.status THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND NEW.status_reason IS NOT DISTINCT FROM OLD.status_reason THEN
+        NEW.status_reason := NULL;
+    END IF;
+    NEW.status_changed_at := NOW();
+    INSERT INTO claim_status_history (claim_id, from_status, to_status, tx_hash, reason, created_at)
+    VALUES (
+        NEW.id,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        NEW.status,
+        NULLIF(NEW.tx_hash, ''),
+        NEW.status_reason,
+        NEW.status_changed_at
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_claims_transition ON claims;
+CREATE TRIGGER trg_claims_transition
+    BEFORE INSERT OR UPDATE OF status ON claims
+    FOR EACH ROW EXECUTE FUNCTION record_claim_transition();
@@ -0,0 +1,8 @@
+-- Signed claim links: pockets created with signedLinks only accept claims
+-- carrying an HMAC-signed token, see service.ClaimLinks. A one-time link's
+-- ID is recorded on the claim it was used for, so it cannot claim twice.
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS signed_links BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS link_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_claims_link ON claims(link_id) WHERE link_id IS NOT NULL;
@@ -0,0 +1,65 @@
+-- Pocket state machine: 'paused' for a pocket whose claims are blocked
+-- until it is resumed, 'refunded' for an expired pocket whose remainder
+-- went back to its creator. model.PocketStatus lists the allowed transitions.
+ALTER TABLE red_pockets DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE red_pockets ADD CONSTRAINT chk_status
+    CHECK (status IN ('awaiting_funding', 'unfunded', 'scheduled', 'active', 'paused', 'depleted', 'expired', 'cancelled', 'refunded'));
+
+-- Why the pocket last changed status, and when
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Expired pockets already refunded are refunded
+UPDATE red_pockets rp
+SET status = 'refunded', status_changed_at = f.completed_at
+FROM red_pocket_refunds f
+WHERE f.red_pocket_id = rp.id AND rp.status = 'expired' AND f.status = 'success';
+
+UPDATE red_pockets SET status_changed_at = created_at WHERE status_changed_at IS NULL;
+
+-- Paused pockets hold campaign budget too
+DROP INDEX IF EXISTS idx_red_pockets_campaign_open;
+CREATE INDEX IF NOT EXISTS idx_red_pockets_campaign_open ON red_pockets(campaign_id)
+    WHERE status IN ('awaiting_funding', 'active', 'paused', 'scheduled');
+
+-- Every status a pocket has been in, from when it was recorded
+CREATE TABLE IF NOT EXISTS pocket_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    red_pocket_id VARCHAR(32) NOT NULL,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pocket_status_history_pocket ON pocket_status_history(red_pocket_id, id);
+
+-- Recorded by trigger so every code path that moves a pocket is covered,
+-- including the claim that depletes it. The statement that changes the
+-- status sets status_reason; one that does not leaves no reason rather
+-- than the previous one.
+CREATE OR REPLACE FUNCTION record_pocket_transition() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status = OLD.status THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND NEW.status_reason IS NOT DISTINCT FROM OLD.status_reason THEN
+        NEW.status_reason := NULL;
+    END IF;
+    NEW.status_changed_at := NOW();
+    INSERT INTO pocket_status_history (red_pocket_id, from_status, to_status, reason, created_at)
+    VALUES (
+        NEW.id,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        NEW.status,
+        NEW.status_reason,
+        NEW.status_changed_at
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_red_pockets_transition ON red_pockets;
+CREATE TRIGGER trg_red_pockets_transition
+    BEFORE INSERT OR UPDATE OF status ON red_pockets
+    FOR EACH ROW EXECUTE FUNCTION record_pocket_transition();
@@ -0,0 +1,3 @@
+-- The request a failed claim was made in, so support can go from the
+-- reference a claimer quotes to every log line of the request
+ALTER TABLE claim_failures ADD COLUMN IF NOT EXISTS request_id VARCHAR(128) NOT NULL DEFAULT '';
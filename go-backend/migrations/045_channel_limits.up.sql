@@ -0,0 +1,15 @@
+-- Per-channel claim caps and sub-budgets for pockets announced to several
+-- channels. The limits live with the pocket, so recurring instances keep
+-- them; what each channel's claims have taken is counted in
+-- pocket_channel_usage in the claim's transaction.
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS channel_limits JSONB;
+
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS channel_id VARCHAR(128);
+
+CREATE TABLE IF NOT EXISTS pocket_channel_usage (
+    red_pocket_id VARCHAR(32) NOT NULL,
+    channel_id VARCHAR(128) NOT NULL,
+    claimed_count INTEGER NOT NULL DEFAULT 0,
+    claimed_units NUMERIC(78, 0) NOT NULL DEFAULT 0,
+    PRIMARY KEY (red_pocket_id, channel_id)
+);
@@ -0,0 +1,7 @@
+-- Claim weights: campaigns multiply the claim amount of claimers with a
+-- Discord role, admin rights in the Telegram chat, or an NFT tier
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS claim_weights JSONB;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS claim_weights_updated_at TIMESTAMP WITH TIME ZONE;
+
+-- Multiplier a claim's amount was weighted by; 0 for unweighted claims
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS multiplier DECIMAL(6, 4) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Refunds queued when an enterprise cancels an open or paused pocket
+ALTER TABLE red_pocket_refunds DROP CONSTRAINT IF EXISTS chk_refund_trigger;
+ALTER TABLE red_pocket_refunds ADD CONSTRAINT chk_refund_trigger
+    CHECK (triggered_by IN ('auto', 'manual', 'cancel'));
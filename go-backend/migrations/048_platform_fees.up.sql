@@ -0,0 +1,32 @@
+-- Platform fees: a creation fee charged on top of a pocket's amount and a
+-- claim fee withheld from each claim's payout, both booked to the
+-- platform_revenue ledger account. Schedules are per enterprise plan; plans
+-- without a row use the PLATFORM_* defaults from config.
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS plan VARCHAR(32) NOT NULL DEFAULT 'standard';
+
+CREATE TABLE IF NOT EXISTS platform_fee_schedules (
+    plan VARCHAR(32) PRIMARY KEY,
+    creation_fee DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    creation_fee_bps INT NOT NULL DEFAULT 0,
+    claim_fee DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    claim_fee_bps INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_platform_fees_non_negative CHECK (creation_fee >= 0 AND claim_fee >= 0),
+    CONSTRAINT chk_platform_fee_bps CHECK (creation_fee_bps BETWEEN 0 AND 10000 AND claim_fee_bps BETWEEN 0 AND 10000)
+);
+
+-- The fees a pocket was created under, fixed for its lifetime
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS fee_plan VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS creation_fee DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS creation_fee_units NUMERIC(78, 0) NOT NULL DEFAULT 0;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS claim_fee_units NUMERIC(78, 0) NOT NULL DEFAULT 0;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS claim_fee_bps INT NOT NULL DEFAULT 0;
+
+-- Fee withheld from a claim, included in amount
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS fee_amount DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS fee_units NUMERIC(78, 0) NOT NULL DEFAULT 0;
+
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS chk_ledger_entry_kind;
+ALTER TABLE ledger_entries ADD CONSTRAINT chk_ledger_entry_kind
+    CHECK (kind IN ('claim', 'withdrawal', 'refund', 'donation', 'creation_fee', 'claim_fee'));
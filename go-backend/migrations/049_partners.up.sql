@@ -0,0 +1,24 @@
+-- Partner program: resellers hand enterprises a partner code, and the
+-- volume and platform fees of an enterprise's campaigns from the day it was
+-- attached count towards its partner's monthly statements
+CREATE TABLE IF NOT EXISTS partners (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(120) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    code VARCHAR(32) NOT NULL,
+    revenue_share_bps INT NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    api_key_hash VARCHAR(64) NOT NULL, -- SHA-256 of the partner's reporting key
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_partner_code UNIQUE (code),
+    CONSTRAINT uq_partner_api_key UNIQUE (api_key_hash),
+    CONSTRAINT chk_partner_revenue_share CHECK (revenue_share_bps BETWEEN 0 AND 10000),
+    CONSTRAINT chk_partner_status CHECK (status IN ('active', 'suspended'))
+);
+
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS partner_id VARCHAR(32) REFERENCES partners(id);
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS partner_attached_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_enterprises_partner ON enterprises(partner_id) WHERE partner_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_platform_fees ON ledger_entries(created_at) WHERE kind IN ('creation_fee', 'claim_fee');
@@ -0,0 +1,2 @@
+-- Claimers page through their own claims newest first
+CREATE INDEX IF NOT EXISTS idx_claims_user_created ON claims(platform, platform_id, created_at DESC);
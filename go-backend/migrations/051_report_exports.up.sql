@@ -0,0 +1,27 @@
+-- Enterprise report exports too large to stream in one response: generated
+-- in the background and downloaded through a signed URL until expires_at
+CREATE TABLE IF NOT EXISTS report_exports (
+    id VARCHAR(32) PRIMARY KEY,
+    enterprise_id VARCHAR(32) NOT NULL,
+    kind VARCHAR(16) NOT NULL,   -- claims, analytics
+    format VARCHAR(8) NOT NULL,  -- csv, xlsx
+    campaign_id VARCHAR(32) NOT NULL DEFAULT '',
+    from_at TIMESTAMP WITH TIME ZONE,
+    to_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    row_count BIGINT NOT NULL DEFAULT 0,
+    content BYTEA,
+    error TEXT NOT NULL DEFAULT '',
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    CONSTRAINT chk_report_export_kind CHECK (kind IN ('claims', 'analytics')),
+    CONSTRAINT chk_report_export_format CHECK (format IN ('csv', 'xlsx')),
+    CONSTRAINT chk_report_export_status CHECK (status IN ('pending', 'running', 'ready', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_exports_enterprise ON report_exports(enterprise_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_report_exports_due ON report_exports(created_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_claims_created ON claims(created_at);
@@ -0,0 +1,21 @@
+-- Sandbox tenancy: test enterprises fund their campaigns from a faucet
+-- instead of real deposits, and their pockets pay out through simulated
+-- transfers. Everything they create is flagged test_mode so it can be kept
+-- out of production analytics, billing and receipt tracking.
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Fake budget the faucet added to a sandbox campaign
+CREATE TABLE IF NOT EXISTS sandbox_faucet_grants (
+    id VARCHAR(32) PRIMARY KEY,
+    enterprise_id VARCHAR(32) NOT NULL REFERENCES enterprises(id),
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    amount DECIMAL(20, 8) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_faucet_amount_positive CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_faucet_grants_enterprise ON sandbox_faucet_grants(enterprise_id, created_at);
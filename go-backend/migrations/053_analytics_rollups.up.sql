@@ -0,0 +1,34 @@
+-- Time-series analytics: claims, unique claimers, claimed amount and new
+-- pockets per hour, day and week (UTC, weeks start on Monday), kept by the
+-- analytics rollup job. Each bucket has one row per enterprise, one per
+-- campaign, and one per platform and per token of each; '' and 0 in a
+-- dimension mean all of it, so unique claimers are counted exactly for
+-- every combination that can be queried.
+CREATE TABLE IF NOT EXISTS analytics_rollups (
+    granularity VARCHAR(8) NOT NULL,
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    enterprise_id VARCHAR(32) NOT NULL,
+    campaign_id VARCHAR(32) NOT NULL DEFAULT '',
+    platform VARCHAR(32) NOT NULL DEFAULT '',
+    chain_id BIGINT NOT NULL DEFAULT 0,
+    token VARCHAR(32) NOT NULL DEFAULT '',
+    claims BIGINT NOT NULL DEFAULT 0,
+    claimers BIGINT NOT NULL DEFAULT 0,
+    amount DECIMAL(30, 8) NOT NULL DEFAULT 0,
+    pockets BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (enterprise_id, granularity, campaign_id, platform, chain_id, token, bucket),
+    CONSTRAINT chk_analytics_granularity CHECK (granularity IN ('hour', 'day', 'week'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_rollups_bucket ON analytics_rollups(granularity, bucket);
+
+-- Per granularity, the first bucket the next rollup recomputes; buckets
+-- before it are settled
+CREATE TABLE IF NOT EXISTS analytics_rollup_state (
+    granularity VARCHAR(8) PRIMARY KEY,
+    recompute_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    rolled_up_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_red_pockets_created ON red_pockets(created_at);
@@ -0,0 +1,19 @@
+-- Verifiable lucky draws: each lucky draw pocket gets a secret seed whose
+-- SHA-256 is published when it is created. Every claim's draw is derived
+-- from the seed and the claimer, and recorded with the pocket's state at the
+-- time, so once the pocket closes and the seed is revealed anyone can
+-- recompute it.
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS draw_seed VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS draw_commitment VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS lucky_draws (
+    claim_id VARCHAR(32) PRIMARY KEY REFERENCES claims(id),
+    red_pocket_id VARCHAR(32) NOT NULL REFERENCES red_pockets(id),
+    remaining_units NUMERIC(78, 0) NOT NULL,
+    remaining_count INT NOT NULL,
+    random VARCHAR(64) NOT NULL,
+    draw_units NUMERIC(78, 0) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lucky_draws_pocket ON lucky_draws(red_pocket_id, created_at);
@@ -0,0 +1,80 @@
+-- Event log: every change to a pocket's balance or status, a claim, its
+-- payout job or a refund is kept with the full row as it stood after the
+-- change, so an incident can be read back or replayed against staging with
+-- cmd/replay-events. Recorded by trigger in the writing transaction, like
+-- the status histories, so no code path can skip it.
+CREATE TABLE IF NOT EXISTS event_log (
+    id BIGSERIAL PRIMARY KEY,
+    table_name VARCHAR(32) NOT NULL,
+    op VARCHAR(8) NOT NULL,
+    row_id VARCHAR(64) NOT NULL,
+    red_pocket_id VARCHAR(32),
+    status VARCHAR(32),
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_log_created ON event_log(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_event_log_pocket ON event_log(red_pocket_id, id);
+CREATE INDEX IF NOT EXISTS idx_event_log_row ON event_log(table_name, row_id, id);
+
+-- Secrets are left out of the payload: a lucky draw's seed until it is
+-- revealed, and a pocket's claim password hash
+CREATE OR REPLACE FUNCTION record_event() RETURNS trigger AS $$
+DECLARE
+    payload JSONB := to_jsonb(NEW) - 'draw_seed' - 'claim_password_hash';
+BEGIN
+    IF TG_OP = 'UPDATE' AND payload = to_jsonb(OLD) - 'draw_seed' - 'claim_password_hash' THEN
+        RETURN NULL;
+    END IF;
+    INSERT INTO event_log (table_name, op, row_id, red_pocket_id, status, payload)
+    VALUES (
+        TG_TABLE_NAME,
+        TG_OP,
+        payload->>'id',
+        CASE WHEN TG_TABLE_NAME = 'red_pockets' THEN payload->>'id' ELSE payload->>'red_pocket_id' END,
+        payload->>'status',
+        payload
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Payout jobs only name their claim
+ALTER TABLE payout_jobs ADD COLUMN IF NOT EXISTS red_pocket_id VARCHAR(32);
+UPDATE payout_jobs j SET red_pocket_id = c.red_pocket_id
+FROM claims c WHERE c.id = j.claim_id AND j.red_pocket_id IS NULL;
+
+CREATE OR REPLACE FUNCTION set_payout_job_pocket() RETURNS trigger AS $$
+BEGIN
+    IF NEW.red_pocket_id IS NULL THEN
+        SELECT red_pocket_id INTO NEW.red_pocket_id FROM claims WHERE id = NEW.claim_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_payout_jobs_pocket ON payout_jobs;
+CREATE TRIGGER trg_payout_jobs_pocket
+    BEFORE INSERT ON payout_jobs
+    FOR EACH ROW EXECUTE FUNCTION set_payout_job_pocket();
+
+DROP TRIGGER IF EXISTS trg_red_pockets_event ON red_pockets;
+CREATE TRIGGER trg_red_pockets_event
+    AFTER INSERT OR UPDATE ON red_pockets
+    FOR EACH ROW EXECUTE FUNCTION record_event();
+
+DROP TRIGGER IF EXISTS trg_claims_event ON claims;
+CREATE TRIGGER trg_claims_event
+    AFTER INSERT OR UPDATE ON claims
+    FOR EACH ROW EXECUTE FUNCTION record_event();
+
+DROP TRIGGER IF EXISTS trg_payout_jobs_event ON payout_jobs;
+CREATE TRIGGER trg_payout_jobs_event
+    AFTER INSERT OR UPDATE ON payout_jobs
+    FOR EACH ROW EXECUTE FUNCTION record_event();
+
+DROP TRIGGER IF EXISTS trg_refunds_event ON red_pocket_refunds;
+CREATE TRIGGER trg_refunds_event
+    AFTER INSERT OR UPDATE ON red_pocket_refunds
+    FOR EACH ROW EXECUTE FUNCTION record_event();
@@ -0,0 +1,21 @@
+-- Daily digests: enterprises opt in to a summary of the previous day (UTC)
+-- posted to one of their Telegram, Discord or Slack channels at a chosen
+-- hour. last_sent_on is the day the last digest covered, so each day is
+-- posted once.
+CREATE TABLE IF NOT EXISTS daily_digests (
+    enterprise_id VARCHAR(32) PRIMARY KEY,
+    platform VARCHAR(16) NOT NULL,
+    channel_id VARCHAR(128) NOT NULL,
+    locale VARCHAR(8) NOT NULL DEFAULT 'en',
+    send_hour INT NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_sent_on DATE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_digest_platform CHECK (platform IN ('telegram', 'discord', 'slack')),
+    CONSTRAINT chk_digest_hour CHECK (send_hour BETWEEN 0 AND 23)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_digests_due ON daily_digests(send_hour) WHERE enabled;
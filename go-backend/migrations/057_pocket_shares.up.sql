@@ -0,0 +1,20 @@
+-- Exact lucky draw shares: a lucky draw pocket is split into its total_count
+-- shares when it is created, each a whole number of minor units, so they
+-- add up to the pocket's amount exactly. Each claim takes one unclaimed
+-- share, and a returned claim frees its share again. Pockets seeded before
+-- this keep drawing each share from what is left when it is claimed.
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS draw_scheme VARCHAR(64) NOT NULL DEFAULT '';
+UPDATE red_pockets SET draw_scheme = 'hmac-sha256-double-mean-v1'
+WHERE draw_commitment <> '' AND draw_scheme = '';
+
+CREATE TABLE IF NOT EXISTS pocket_shares (
+    red_pocket_id VARCHAR(32) NOT NULL REFERENCES red_pockets(id),
+    slot INT NOT NULL,
+    units NUMERIC(78, 0) NOT NULL,
+    claim_id VARCHAR(32) REFERENCES claims(id),
+    PRIMARY KEY (red_pocket_id, slot)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pocket_shares_claim ON pocket_shares(claim_id) WHERE claim_id IS NOT NULL;
+
+ALTER TABLE lucky_draws ADD COLUMN IF NOT EXISTS slot INT;
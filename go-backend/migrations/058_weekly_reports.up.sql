@@ -0,0 +1,27 @@
+-- Weekly engagement reports by email. An enterprise adds the addresses of
+-- the members who want the report; each address confirms through a link
+-- emailed to it before any report is sent, and every report carries a link
+-- to unsubscribe. Re-adding an unsubscribed address asks it to confirm
+-- again.
+CREATE TABLE IF NOT EXISTS report_subscribers (
+    id VARCHAR(32) PRIMARY KEY,
+    enterprise_id VARCHAR(32) NOT NULL REFERENCES enterprises(id),
+    email VARCHAR(255) NOT NULL,
+    locale VARCHAR(8) NOT NULL DEFAULT 'en',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, active, unsubscribed
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    unsubscribed_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (enterprise_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscribers_active ON report_subscribers(enterprise_id) WHERE status = 'active';
+
+-- The weeks (starting Monday, UTC) whose report each enterprise was sent
+CREATE TABLE IF NOT EXISTS weekly_reports (
+    enterprise_id VARCHAR(32) NOT NULL REFERENCES enterprises(id),
+    week DATE NOT NULL,
+    recipients INT NOT NULL DEFAULT 0,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (enterprise_id, week)
+);
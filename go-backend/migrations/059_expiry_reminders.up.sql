@@ -0,0 +1,14 @@
+-- Expiry reminders: enterprises hear through the pocket.expiring webhook
+-- when an open pocket is 24 hours and again 1 hour from expiry with much of
+-- its amount unclaimed, and can extend it. remind_channel also posts the
+-- reminder in the pocket's channel. Each reminder is sent once per expiry,
+-- so an extended pocket is reminded again before its new expiry.
+ALTER TABLE red_pockets ADD COLUMN IF NOT EXISTS remind_channel BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS pocket_expiry_reminders (
+    red_pocket_id VARCHAR(32) NOT NULL REFERENCES red_pockets(id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    lead VARCHAR(8) NOT NULL, -- 24h, 1h
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (red_pocket_id, expires_at, lead)
+);
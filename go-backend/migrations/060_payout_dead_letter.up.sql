@@ -0,0 +1,8 @@
+-- Dead-lettered payouts: a job whose transfer failed in a way retrying
+-- cannot fix, or that spent its attempts, is parked as 'dead' and ops are
+-- alerted. Its claim is failed, and the enterprise can queue it again once
+-- the cause is fixed. Jobs failed for other reasons, such as a blocked
+-- payout or an expired lease, stay 'failed' and are not retried.
+ALTER TABLE payout_jobs DROP CONSTRAINT IF EXISTS chk_payout_job_status;
+ALTER TABLE payout_jobs ADD CONSTRAINT chk_payout_job_status
+    CHECK (status IN ('queued', 'running', 'done', 'failed', 'held', 'dead'));
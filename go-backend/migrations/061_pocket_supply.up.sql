@@ -0,0 +1,34 @@
+-- Pocket supply changes: an enterprise can top up an open, paused or
+-- scheduled pocket's amount and count, or take back unclaimed supply. The
+-- change feed announces them as 'supply' changes so streams pick them up.
+CREATE OR REPLACE FUNCTION notify_red_pocket_change() RETURNS trigger AS $$
+DECLARE
+    supply BOOLEAN := TG_OP = 'UPDATE'
+        AND (NEW.total_count <> OLD.total_count OR NEW.amount_units <> OLD.amount_units);
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status = OLD.status AND NEW.claimed_count = OLD.claimed_count AND NOT supply THEN
+        RETURN NEW;
+    END IF;
+    PERFORM pg_notify('redpocket_changes', json_build_object(
+        'table', TG_TABLE_NAME,
+        'op', TG_OP,
+        'id', NEW.id,
+        'redPocketId', NEW.id,
+        'status', NEW.status,
+        'oldStatus', CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        'supply', supply
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Chat messages a pocket was announced in, so they can be edited to show
+-- its new supply
+CREATE TABLE IF NOT EXISTS pocket_announcements (
+    red_pocket_id VARCHAR(32) NOT NULL REFERENCES red_pockets(id),
+    platform VARCHAR(32) NOT NULL,
+    channel_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (red_pocket_id, platform, channel_id, message_id)
+);
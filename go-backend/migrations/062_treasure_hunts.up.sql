@@ -0,0 +1,33 @@
+-- Treasure hunts: red pockets of a campaign linked into a sequence, where
+-- claiming one step's pocket unlocks the next for the claimer, revealed
+-- after the step's delay
+CREATE TABLE IF NOT EXISTS treasure_hunts (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_treasure_hunts_campaign ON treasure_hunts(campaign_id);
+
+-- A pocket is a step of at most one hunt
+CREATE TABLE IF NOT EXISTS treasure_hunt_steps (
+    hunt_id VARCHAR(32) NOT NULL REFERENCES treasure_hunts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK (position > 0),
+    red_pocket_id VARCHAR(32) NOT NULL UNIQUE REFERENCES red_pockets(id),
+    clue TEXT NOT NULL DEFAULT '',
+    reveal_delay INTEGER NOT NULL DEFAULT 0 CHECK (reveal_delay >= 0),
+    PRIMARY KEY (hunt_id, position)
+);
+
+-- The last step each claimer has claimed
+CREATE TABLE IF NOT EXISTS treasure_hunt_progress (
+    hunt_id VARCHAR(32) NOT NULL REFERENCES treasure_hunts(id) ON DELETE CASCADE,
+    platform VARCHAR(32) NOT NULL,
+    platform_id VARCHAR(255) NOT NULL,
+    step INTEGER NOT NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (hunt_id, platform, platform_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_treasure_hunt_progress_user ON treasure_hunt_progress(platform, platform_id);
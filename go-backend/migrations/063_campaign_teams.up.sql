@@ -0,0 +1,60 @@
+-- Teams within a campaign: claimers join one with its code, their claims on
+-- the campaign's pockets count towards it, and a team reaching one of the
+-- campaign's milestones shares its bonus pool among its members
+CREATE TABLE IF NOT EXISTS campaign_teams (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    name VARCHAR(64) NOT NULL,
+    join_code VARCHAR(16) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_teams_campaign ON campaign_teams(campaign_id);
+
+-- A claimer is in at most one team of a campaign
+CREATE TABLE IF NOT EXISTS campaign_team_members (
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    team_id VARCHAR(32) NOT NULL REFERENCES campaign_teams(id),
+    platform VARCHAR(32) NOT NULL,
+    platform_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, platform, platform_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_team_members_team ON campaign_team_members(team_id);
+
+-- The team a claim counts towards, when its claimer was in one of the
+-- pocket's campaign when claiming
+ALTER TABLE claims ADD COLUMN IF NOT EXISTS team_id VARCHAR(32);
+CREATE INDEX IF NOT EXISTS idx_claims_team ON claims(team_id) WHERE team_id IS NOT NULL;
+
+-- A milestone is reached by claim count, amount claimed, or both
+CREATE TABLE IF NOT EXISTS campaign_team_milestones (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    claims INTEGER NOT NULL DEFAULT 0 CHECK (claims >= 0),
+    amount DECIMAL(20, 8) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    bonus_pool DECIMAL(20, 8) NOT NULL CHECK (bonus_pool > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_campaign_team_milestone_target CHECK (claims > 0 OR amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_team_milestones_campaign ON campaign_team_milestones(campaign_id);
+
+-- Each team is awarded a milestone's pool once, split equally among the
+-- members it had then and credited to their ledger balances
+CREATE TABLE IF NOT EXISTS campaign_team_awards (
+    id VARCHAR(32) PRIMARY KEY,
+    team_id VARCHAR(32) NOT NULL REFERENCES campaign_teams(id),
+    milestone_id VARCHAR(32) NOT NULL REFERENCES campaign_team_milestones(id),
+    bonus_pool DECIMAL(20, 8) NOT NULL,
+    members INTEGER NOT NULL,
+    share DECIMAL(20, 8) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_campaign_team_award UNIQUE (team_id, milestone_id)
+);
+
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS chk_ledger_entry_kind;
+ALTER TABLE ledger_entries ADD CONSTRAINT chk_ledger_entry_kind
+    CHECK (kind IN ('claim', 'withdrawal', 'refund', 'donation', 'creation_fee', 'claim_fee', 'team_bonus'));
@@ -0,0 +1,16 @@
+-- Consolidations sweep a user's balances of an asset from every chain to
+-- one, as a bridge transfer per chain with funds tracked together
+CREATE TABLE IF NOT EXISTS bridge_consolidations (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    asset VARCHAR(32) NOT NULL,
+    to_chain BIGINT NOT NULL,
+    recipient VARCHAR(66) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bridge_consolidations_user ON bridge_consolidations(user_id, created_at DESC);
+
+ALTER TABLE bridge_transfers ADD COLUMN IF NOT EXISTS consolidation_id VARCHAR(32);
+CREATE INDEX IF NOT EXISTS idx_bridge_transfers_consolidation ON bridge_transfers(consolidation_id)
+    WHERE consolidation_id IS NOT NULL;
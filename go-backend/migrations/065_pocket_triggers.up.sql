@@ -0,0 +1,16 @@
+-- Bonus pockets a campaign drops once it reaches a number of claims or an
+-- amount claimed. A trigger fires once: fired_at is set when it does and
+-- reset, with last_error, when the bonus pocket could not be created.
+CREATE TABLE IF NOT EXISTS pocket_triggers (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    metric VARCHAR(16) NOT NULL CHECK (metric IN ('claims', 'amount')),
+    threshold DECIMAL(20, 8) NOT NULL CHECK (threshold > 0),
+    pocket JSONB NOT NULL,
+    fired_at TIMESTAMP WITH TIME ZONE,
+    red_pocket_id VARCHAR(32),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pocket_triggers_pending ON pocket_triggers(campaign_id) WHERE fired_at IS NULL;
@@ -0,0 +1,55 @@
+-- Campaign automation rules: when a rule's event happens and all of its
+-- conditions on the campaign's metrics hold, its action runs, at most
+-- max_runs times (0 for no limit) and at most once per cooldown seconds.
+-- running_since is set while an evaluation carries out the action.
+CREATE TABLE IF NOT EXISTS campaign_rules (
+    id VARCHAR(32) PRIMARY KEY,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    name VARCHAR(64) NOT NULL,
+    event VARCHAR(16) NOT NULL CHECK (event IN ('claim', 'schedule')),
+    conditions JSONB NOT NULL DEFAULT '[]',
+    action VARCHAR(32) NOT NULL CHECK (action IN ('create_pocket', 'pause_campaign', 'send_alert', 'adjust_distribution')),
+    params JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    max_runs INTEGER NOT NULL DEFAULT 1 CHECK (max_runs >= 0),
+    cooldown INTEGER NOT NULL DEFAULT 0 CHECK (cooldown >= 0),
+    runs INTEGER NOT NULL DEFAULT 0,
+    running_since TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_rules_campaign ON campaign_rules(campaign_id, event) WHERE enabled;
+
+-- Each time a rule's action was carried out, with the metrics it saw
+CREATE TABLE IF NOT EXISTS campaign_rule_runs (
+    id VARCHAR(32) PRIMARY KEY,
+    rule_id VARCHAR(32) NOT NULL REFERENCES campaign_rules(id) ON DELETE CASCADE,
+    campaign_id VARCHAR(32) NOT NULL REFERENCES campaigns(id),
+    status VARCHAR(16) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    metrics JSONB NOT NULL,
+    result TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_rule_runs_rule ON campaign_rule_runs(rule_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_campaign_rule_runs_campaign ON campaign_rule_runs(campaign_id, created_at DESC);
+
+-- Bonus pocket triggers become create_pocket rules run on claims
+INSERT INTO campaign_rules (id, campaign_id, name, event, conditions, action, params, max_runs, runs, last_run_at, last_error, created_at, updated_at)
+SELECT id, campaign_id, 'Bonus pocket at ' || TRIM(TRAILING '.' FROM TRIM(TRAILING '0' FROM threshold::TEXT)) || ' ' || metric,
+    'claim', jsonb_build_array(jsonb_build_object('metric', metric, 'op', 'gte', 'value', threshold)),
+    'create_pocket', pocket, 1, CASE WHEN fired_at IS NULL THEN 0 ELSE 1 END, fired_at, last_error, created_at, created_at
+FROM pocket_triggers
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO campaign_rule_runs (id, rule_id, campaign_id, status, metrics, result, created_at)
+SELECT 'run_' || SUBSTRING(md5(id) FOR 8), id, campaign_id, 'succeeded', '{}', COALESCE(red_pocket_id, ''), fired_at
+FROM pocket_triggers
+WHERE fired_at IS NOT NULL
+ON CONFLICT (id) DO NOTHING;
+
+DROP TABLE IF EXISTS pocket_triggers;
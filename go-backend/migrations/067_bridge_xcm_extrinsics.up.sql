@@ -0,0 +1,6 @@
+-- XCM transfers sent as extrinsics by the relayer are tracked on chain:
+-- source_nonce is the relayer nonce the extrinsic used, included once the
+-- account's nonce passes it, and dest_balance the recipient's balance on
+-- the destination when it was sent, which rises when the transfer lands.
+ALTER TABLE bridge_transfers ADD COLUMN IF NOT EXISTS source_nonce BIGINT;
+ALTER TABLE bridge_transfers ADD COLUMN IF NOT EXISTS dest_balance NUMERIC(78, 0);
@@ -0,0 +1,8 @@
+-- Native tokens of the Polkadot EVM parachains, and DOT on Astar as the
+-- ERC-20 precompile of its assets pallet, so campaigns on Moonbeam and
+-- Astar can drop them
+INSERT INTO tokens (symbol, chain_id, address, decimals) VALUES
+    ('GLMR', 1284, '', 18),
+    ('ASTR', 592, '', 18),
+    ('DOT', 592, '0xffffffffffffffffffffffffffffffffffffffff', 10)
+ON CONFLICT (symbol, chain_id) DO NOTHING;
@@ -0,0 +1,3 @@
+-- API key secrets are sealed like wallet keys, which no longer fits 128
+-- characters. Secrets issued before stay readable as they are.
+ALTER TABLE api_keys ALTER COLUMN secret TYPE TEXT;
@@ -0,0 +1,9 @@
+// Package migrations embeds the database schema as numbered SQL migrations,
+// NNN_name.up.sql, applied in order by repository.Migrator. Migrations are
+// forward only: a change to the schema is a new file, never an edit.
+package migrations
+
+import "embed"
+
+//go:embed *.up.sql
+var FS embed.FS
@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.3
+// source: redpocket/v1/claim.proto
+
+package redpocketv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ClaimService_GetPayoutStatus_FullMethodName = "/redpocket.v1.ClaimService/GetPayoutStatus"
+	ClaimService_ListClaims_FullMethodName      = "/redpocket.v1.ClaimService/ListClaims"
+)
+
+// ClaimServiceClient is the client API for ClaimService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ClaimService reports on claims and their payouts
+type ClaimServiceClient interface {
+	GetPayoutStatus(ctx context.Context, in *GetPayoutStatusRequest, opts ...grpc.CallOption) (*PayoutStatus, error)
+	// ListClaims lists a campaign's claims, or with no campaign_id all claims of
+	// the enterprise. Requires an enterprise token.
+	ListClaims(ctx context.Context, in *ListClaimsRequest, opts ...grpc.CallOption) (*ListClaimsResponse, error)
+}
+
+type claimServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClaimServiceClient(cc grpc.ClientConnInterface) ClaimServiceClient {
+	return &claimServiceClient{cc}
+}
+
+func (c *claimServiceClient) GetPayoutStatus(ctx context.Context, in *GetPayoutStatusRequest, opts ...grpc.CallOption) (*PayoutStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PayoutStatus)
+	err := c.cc.Invoke(ctx, ClaimService_GetPayoutStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *claimServiceClient) ListClaims(ctx context.Context, in *ListClaimsRequest, opts ...grpc.CallOption) (*ListClaimsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListClaimsResponse)
+	err := c.cc.Invoke(ctx, ClaimService_ListClaims_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClaimServiceServer is the server API for ClaimService service.
+// All implementations must embed UnimplementedClaimServiceServer
+// for forward compatibility.
+//
+// ClaimService reports on claims and their payouts
+type ClaimServiceServer interface {
+	GetPayoutStatus(context.Context, *GetPayoutStatusRequest) (*PayoutStatus, error)
+	// ListClaims lists a campaign's claims, or with no campaign_id all claims of
+	// the enterprise. Requires an enterprise token.
+	ListClaims(context.Context, *ListClaimsRequest) (*ListClaimsResponse, error)
+	mustEmbedUnimplementedClaimServiceServer()
+}
+
+// UnimplementedClaimServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClaimServiceServer struct{}
+
+func (UnimplementedClaimServiceServer) GetPayoutStatus(context.Context, *GetPayoutStatusRequest) (*PayoutStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayoutStatus not implemented")
+}
+func (UnimplementedClaimServiceServer) ListClaims(context.Context, *ListClaimsRequest) (*ListClaimsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClaims not implemented")
+}
+func (UnimplementedClaimServiceServer) mustEmbedUnimplementedClaimServiceServer() {}
+func (UnimplementedClaimServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeClaimServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClaimServiceServer will
+// result in compilation errors.
+type UnsafeClaimServiceServer interface {
+	mustEmbedUnimplementedClaimServiceServer()
+}
+
+func RegisterClaimServiceServer(s grpc.ServiceRegistrar, srv ClaimServiceServer) {
+	// If the following call pancis, it indicates UnimplementedClaimServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ClaimService_ServiceDesc, srv)
+}
+
+func _ClaimService_GetPayoutStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPayoutStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClaimServiceServer).GetPayoutStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClaimService_GetPayoutStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClaimServiceServer).GetPayoutStatus(ctx, req.(*GetPayoutStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClaimService_ListClaims_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClaimsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClaimServiceServer).ListClaims(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClaimService_ListClaims_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClaimServiceServer).ListClaims(ctx, req.(*ListClaimsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ClaimService_ServiceDesc is the grpc.ServiceDesc for ClaimService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ClaimService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "redpocket.v1.ClaimService",
+	HandlerType: (*ClaimServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPayoutStatus",
+			Handler:    _ClaimService_GetPayoutStatus_Handler,
+		},
+		{
+			MethodName: "ListClaims",
+			Handler:    _ClaimService_ListClaims_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "redpocket/v1/claim.proto",
+}
@@ -0,0 +1,1841 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v4.25.3
+// source: redpocket/v1/redpocket.proto
+
+package redpocketv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RedPocket struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CampaignId        string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	SenderName        string                 `protobuf:"bytes,3,opt,name=sender_name,json=senderName,proto3" json:"sender_name,omitempty"`
+	SenderAvatar      string                 `protobuf:"bytes,4,opt,name=sender_avatar,json=senderAvatar,proto3" json:"sender_avatar,omitempty"`
+	Amount            float64                `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	RemainingAmount   float64                `protobuf:"fixed64,6,opt,name=remaining_amount,json=remainingAmount,proto3" json:"remaining_amount,omitempty"`
+	Token             string                 `protobuf:"bytes,7,opt,name=token,proto3" json:"token,omitempty"`
+	TokenAddress      string                 `protobuf:"bytes,8,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"` // empty for the chain's native token
+	ChainId           int64                  `protobuf:"varint,9,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Platform          string                 `protobuf:"bytes,10,opt,name=platform,proto3" json:"platform,omitempty"`
+	ChannelId         string                 `protobuf:"bytes,11,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	Message           string                 `protobuf:"bytes,12,opt,name=message,proto3" json:"message,omitempty"`
+	Tag               string                 `protobuf:"bytes,13,opt,name=tag,proto3" json:"tag,omitempty"`
+	TotalCount        int32                  `protobuf:"varint,14,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	ClaimedCount      int32                  `protobuf:"varint,15,opt,name=claimed_count,json=claimedCount,proto3" json:"claimed_count,omitempty"`
+	IsLuckyDraw       bool                   `protobuf:"varint,16,opt,name=is_lucky_draw,json=isLuckyDraw,proto3" json:"is_lucky_draw,omitempty"`
+	MinAmount         float64                `protobuf:"fixed64,17,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`
+	MaxAmount         float64                `protobuf:"fixed64,18,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"`
+	ExpiresAt         *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Status            string                 `protobuf:"bytes,21,opt,name=status,proto3" json:"status,omitempty"` // awaiting_funding, unfunded, scheduled, active, paused, depleted, expired, cancelled, refunded
+	CoverImage        string                 `protobuf:"bytes,22,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
+	PasswordProtected bool                   `protobuf:"varint,23,opt,name=password_protected,json=passwordProtected,proto3" json:"password_protected,omitempty"`
+	CaptchaMode       string                 `protobuf:"bytes,24,opt,name=captcha_mode,json=captchaMode,proto3" json:"captcha_mode,omitempty"` // hcaptcha, turnstile
+	StartsAt          *timestamppb.Timestamp `protobuf:"bytes,25,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	Recurrence        string                 `protobuf:"bytes,26,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	RecurrenceUntil   *timestamppb.Timestamp `protobuf:"bytes,27,opt,name=recurrence_until,json=recurrenceUntil,proto3" json:"recurrence_until,omitempty"`
+	SeriesId          string                 `protobuf:"bytes,28,opt,name=series_id,json=seriesId,proto3" json:"series_id,omitempty"`
+	Locale            string                 `protobuf:"bytes,29,opt,name=locale,proto3" json:"locale,omitempty"`
+	Decimals          int32                  `protobuf:"varint,30,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	AmountUnits       string                 `protobuf:"bytes,31,opt,name=amount_units,json=amountUnits,proto3" json:"amount_units,omitempty"` // exact amount in minor units
+	RemainingUnits    string                 `protobuf:"bytes,32,opt,name=remaining_units,json=remainingUnits,proto3" json:"remaining_units,omitempty"`
+	SignedLinks       bool                   `protobuf:"varint,33,opt,name=signed_links,json=signedLinks,proto3" json:"signed_links,omitempty"`      // claims need a signed claim link
+	ChannelLimits     []*ChannelLimit        `protobuf:"bytes,34,rep,name=channel_limits,json=channelLimits,proto3" json:"channel_limits,omitempty"` // claims only from these channels
+}
+
+func (x *RedPocket) Reset() {
+	*x = RedPocket{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RedPocket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedPocket) ProtoMessage() {}
+
+func (x *RedPocket) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedPocket.ProtoReflect.Descriptor instead.
+func (*RedPocket) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RedPocket) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RedPocket) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *RedPocket) GetSenderName() string {
+	if x != nil {
+		return x.SenderName
+	}
+	return ""
+}
+
+func (x *RedPocket) GetSenderAvatar() string {
+	if x != nil {
+		return x.SenderAvatar
+	}
+	return ""
+}
+
+func (x *RedPocket) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RedPocket) GetRemainingAmount() float64 {
+	if x != nil {
+		return x.RemainingAmount
+	}
+	return 0
+}
+
+func (x *RedPocket) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RedPocket) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *RedPocket) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *RedPocket) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *RedPocket) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *RedPocket) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RedPocket) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *RedPocket) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *RedPocket) GetClaimedCount() int32 {
+	if x != nil {
+		return x.ClaimedCount
+	}
+	return 0
+}
+
+func (x *RedPocket) GetIsLuckyDraw() bool {
+	if x != nil {
+		return x.IsLuckyDraw
+	}
+	return false
+}
+
+func (x *RedPocket) GetMinAmount() float64 {
+	if x != nil {
+		return x.MinAmount
+	}
+	return 0
+}
+
+func (x *RedPocket) GetMaxAmount() float64 {
+	if x != nil {
+		return x.MaxAmount
+	}
+	return 0
+}
+
+func (x *RedPocket) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *RedPocket) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *RedPocket) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RedPocket) GetCoverImage() string {
+	if x != nil {
+		return x.CoverImage
+	}
+	return ""
+}
+
+func (x *RedPocket) GetPasswordProtected() bool {
+	if x != nil {
+		return x.PasswordProtected
+	}
+	return false
+}
+
+func (x *RedPocket) GetCaptchaMode() string {
+	if x != nil {
+		return x.CaptchaMode
+	}
+	return ""
+}
+
+func (x *RedPocket) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *RedPocket) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *RedPocket) GetRecurrenceUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RecurrenceUntil
+	}
+	return nil
+}
+
+func (x *RedPocket) GetSeriesId() string {
+	if x != nil {
+		return x.SeriesId
+	}
+	return ""
+}
+
+func (x *RedPocket) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *RedPocket) GetDecimals() int32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *RedPocket) GetAmountUnits() string {
+	if x != nil {
+		return x.AmountUnits
+	}
+	return ""
+}
+
+func (x *RedPocket) GetRemainingUnits() string {
+	if x != nil {
+		return x.RemainingUnits
+	}
+	return ""
+}
+
+func (x *RedPocket) GetSignedLinks() bool {
+	if x != nil {
+		return x.SignedLinks
+	}
+	return false
+}
+
+func (x *RedPocket) GetChannelLimits() []*ChannelLimit {
+	if x != nil {
+		return x.ChannelLimits
+	}
+	return nil
+}
+
+type CreateRedPocketRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CampaignId      string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	SenderName      string                 `protobuf:"bytes,2,opt,name=sender_name,json=senderName,proto3" json:"sender_name,omitempty"`
+	SenderAvatar    string                 `protobuf:"bytes,3,opt,name=sender_avatar,json=senderAvatar,proto3" json:"sender_avatar,omitempty"`
+	Amount          string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`                                 // decimal token amount, e.g. "1.5"
+	Token           string                 `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`                                   // symbol in the token registry
+	TokenAddress    string                 `protobuf:"bytes,6,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"` // optional, must match the registry
+	Platform        string                 `protobuf:"bytes,7,opt,name=platform,proto3" json:"platform,omitempty"`
+	ChannelId       string                 `protobuf:"bytes,8,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	Message         string                 `protobuf:"bytes,9,opt,name=message,proto3" json:"message,omitempty"`
+	CoverImage      string                 `protobuf:"bytes,10,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
+	Tag             string                 `protobuf:"bytes,11,opt,name=tag,proto3" json:"tag,omitempty"`
+	TotalCount      int32                  `protobuf:"varint,12,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	IsLuckyDraw     bool                   `protobuf:"varint,13,opt,name=is_lucky_draw,json=isLuckyDraw,proto3" json:"is_lucky_draw,omitempty"`
+	MinAmount       float64                `protobuf:"fixed64,14,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`
+	MaxAmount       float64                `protobuf:"fixed64,15,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"`
+	ExpiresIn       int64                  `protobuf:"varint,16,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"` // seconds, default 7 days
+	ClaimPassword   string                 `protobuf:"bytes,17,opt,name=claim_password,json=claimPassword,proto3" json:"claim_password,omitempty"`
+	CaptchaMode     string                 `protobuf:"bytes,18,opt,name=captcha_mode,json=captchaMode,proto3" json:"captcha_mode,omitempty"`
+	StartsAt        *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	Recurrence      string                 `protobuf:"bytes,20,opt,name=recurrence,proto3" json:"recurrence,omitempty"` // daily, weekly or a cron expression in UTC
+	RecurrenceUntil *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=recurrence_until,json=recurrenceUntil,proto3" json:"recurrence_until,omitempty"`
+	Locale          string                 `protobuf:"bytes,22,opt,name=locale,proto3" json:"locale,omitempty"`
+	// Eligibility rules of this red pocket, on top of the campaign's
+	Eligibility   []*EligibilityRule `protobuf:"bytes,23,rep,name=eligibility,proto3" json:"eligibility,omitempty"`
+	SignedLinks   bool               `protobuf:"varint,24,opt,name=signed_links,json=signedLinks,proto3" json:"signed_links,omitempty"` // claims need a signed claim link; needs CLAIM_LINK_SECRET
+	ChannelLimits []*ChannelLimit    `protobuf:"bytes,25,rep,name=channel_limits,json=channelLimits,proto3" json:"channel_limits,omitempty"`
+}
+
+func (x *CreateRedPocketRequest) Reset() {
+	*x = CreateRedPocketRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateRedPocketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRedPocketRequest) ProtoMessage() {}
+
+func (x *CreateRedPocketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRedPocketRequest.ProtoReflect.Descriptor instead.
+func (*CreateRedPocketRequest) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateRedPocketRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetSenderName() string {
+	if x != nil {
+		return x.SenderName
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetSenderAvatar() string {
+	if x != nil {
+		return x.SenderAvatar
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetCoverImage() string {
+	if x != nil {
+		return x.CoverImage
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *CreateRedPocketRequest) GetIsLuckyDraw() bool {
+	if x != nil {
+		return x.IsLuckyDraw
+	}
+	return false
+}
+
+func (x *CreateRedPocketRequest) GetMinAmount() float64 {
+	if x != nil {
+		return x.MinAmount
+	}
+	return 0
+}
+
+func (x *CreateRedPocketRequest) GetMaxAmount() float64 {
+	if x != nil {
+		return x.MaxAmount
+	}
+	return 0
+}
+
+func (x *CreateRedPocketRequest) GetExpiresIn() int64 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+func (x *CreateRedPocketRequest) GetClaimPassword() string {
+	if x != nil {
+		return x.ClaimPassword
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetCaptchaMode() string {
+	if x != nil {
+		return x.CaptchaMode
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *CreateRedPocketRequest) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetRecurrenceUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RecurrenceUntil
+	}
+	return nil
+}
+
+func (x *CreateRedPocketRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *CreateRedPocketRequest) GetEligibility() []*EligibilityRule {
+	if x != nil {
+		return x.Eligibility
+	}
+	return nil
+}
+
+func (x *CreateRedPocketRequest) GetSignedLinks() bool {
+	if x != nil {
+		return x.SignedLinks
+	}
+	return false
+}
+
+func (x *CreateRedPocketRequest) GetChannelLimits() []*ChannelLimit {
+	if x != nil {
+		return x.ChannelLimits
+	}
+	return nil
+}
+
+// ChannelLimit caps the claims from one channel a red pocket is announced
+// in; 0 or empty is no cap
+type ChannelLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId   string `protobuf:"bytes,1,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"` // Telegram chat ID, Discord channel ID, or TEAM_ID:CHANNEL_ID on Slack
+	MaxClaims   int32  `protobuf:"varint,2,opt,name=max_claims,json=maxClaims,proto3" json:"max_claims,omitempty"`
+	Budget      string `protobuf:"bytes,3,opt,name=budget,proto3" json:"budget,omitempty"`                              // decimal token amount
+	BudgetUnits string `protobuf:"bytes,4,opt,name=budget_units,json=budgetUnits,proto3" json:"budget_units,omitempty"` // exact budget in minor units, in responses
+}
+
+func (x *ChannelLimit) Reset() {
+	*x = ChannelLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelLimit) ProtoMessage() {}
+
+func (x *ChannelLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelLimit.ProtoReflect.Descriptor instead.
+func (*ChannelLimit) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChannelLimit) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ChannelLimit) GetMaxClaims() int32 {
+	if x != nil {
+		return x.MaxClaims
+	}
+	return 0
+}
+
+func (x *ChannelLimit) GetBudget() string {
+	if x != nil {
+		return x.Budget
+	}
+	return ""
+}
+
+func (x *ChannelLimit) GetBudgetUnits() string {
+	if x != nil {
+		return x.BudgetUnits
+	}
+	return ""
+}
+
+// EligibilityRule restricts who can claim; each type reads its own fields
+type EligibilityRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type              string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                                         // allowlist, token_gate, account_age, geo, claim_window
+	Accounts          []string `protobuf:"bytes,2,rep,name=accounts,proto3" json:"accounts,omitempty"`                                                 // allowlist: "<platform>:<platform ID>"
+	TokenAddress      string   `protobuf:"bytes,3,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`                     // token_gate
+	MinBalance        string   `protobuf:"bytes,4,opt,name=min_balance,json=minBalance,proto3" json:"min_balance,omitempty"`                           // token_gate: minor units, or a count for NFTs
+	MinAccountAgeDays int32    `protobuf:"varint,5,opt,name=min_account_age_days,json=minAccountAgeDays,proto3" json:"min_account_age_days,omitempty"` // account_age
+	AllowCountries    []string `protobuf:"bytes,6,rep,name=allow_countries,json=allowCountries,proto3" json:"allow_countries,omitempty"`               // geo: ISO 3166-1 alpha-2
+	BlockCountries    []string `protobuf:"bytes,7,rep,name=block_countries,json=blockCountries,proto3" json:"block_countries,omitempty"`
+	Days              []string `protobuf:"bytes,8,rep,name=days,proto3" json:"days,omitempty"`                            // claim_window: "mon" to "sun", every day when empty
+	StartTime         string   `protobuf:"bytes,9,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // claim_window: "HH:MM"
+	EndTime           string   `protobuf:"bytes,10,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`      // claim_window: "HH:MM", past midnight when not after start_time
+	Timezone          string   `protobuf:"bytes,11,opt,name=timezone,proto3" json:"timezone,omitempty"`                   // claim_window: IANA, default UTC
+}
+
+func (x *EligibilityRule) Reset() {
+	*x = EligibilityRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EligibilityRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EligibilityRule) ProtoMessage() {}
+
+func (x *EligibilityRule) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EligibilityRule.ProtoReflect.Descriptor instead.
+func (*EligibilityRule) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EligibilityRule) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EligibilityRule) GetAccounts() []string {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *EligibilityRule) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *EligibilityRule) GetMinBalance() string {
+	if x != nil {
+		return x.MinBalance
+	}
+	return ""
+}
+
+func (x *EligibilityRule) GetMinAccountAgeDays() int32 {
+	if x != nil {
+		return x.MinAccountAgeDays
+	}
+	return 0
+}
+
+func (x *EligibilityRule) GetAllowCountries() []string {
+	if x != nil {
+		return x.AllowCountries
+	}
+	return nil
+}
+
+func (x *EligibilityRule) GetBlockCountries() []string {
+	if x != nil {
+		return x.BlockCountries
+	}
+	return nil
+}
+
+func (x *EligibilityRule) GetDays() []string {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *EligibilityRule) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *EligibilityRule) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *EligibilityRule) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+type CreateRedPocketResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RedPocket *RedPocket  `protobuf:"bytes,1,opt,name=red_pocket,json=redPocket,proto3" json:"red_pocket,omitempty"`
+	ClaimLink string      `protobuf:"bytes,2,opt,name=claim_link,json=claimLink,proto3" json:"claim_link,omitempty"`
+	Fees      *PocketFees `protobuf:"bytes,3,opt,name=fees,proto3" json:"fees,omitempty"`
+}
+
+func (x *CreateRedPocketResponse) Reset() {
+	*x = CreateRedPocketResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateRedPocketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRedPocketResponse) ProtoMessage() {}
+
+func (x *CreateRedPocketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRedPocketResponse.ProtoReflect.Descriptor instead.
+func (*CreateRedPocketResponse) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateRedPocketResponse) GetRedPocket() *RedPocket {
+	if x != nil {
+		return x.RedPocket
+	}
+	return nil
+}
+
+func (x *CreateRedPocketResponse) GetClaimLink() string {
+	if x != nil {
+		return x.ClaimLink
+	}
+	return ""
+}
+
+func (x *CreateRedPocketResponse) GetFees() *PocketFees {
+	if x != nil {
+		return x.Fees
+	}
+	return nil
+}
+
+// What the platform charges for a pocket under its campaign's plan
+type PocketFees struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Plan              string  `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	CreationFee       float64 `protobuf:"fixed64,2,opt,name=creation_fee,json=creationFee,proto3" json:"creation_fee,omitempty"` // charged on top of the amount
+	CreationFeeUnits  string  `protobuf:"bytes,3,opt,name=creation_fee_units,json=creationFeeUnits,proto3" json:"creation_fee_units,omitempty"`
+	TotalCharged      float64 `protobuf:"fixed64,4,opt,name=total_charged,json=totalCharged,proto3" json:"total_charged,omitempty"` // amount plus creation fee, what a deposit must cover
+	TotalChargedUnits string  `protobuf:"bytes,5,opt,name=total_charged_units,json=totalChargedUnits,proto3" json:"total_charged_units,omitempty"`
+	ClaimFee          float64 `protobuf:"fixed64,6,opt,name=claim_fee,json=claimFee,proto3" json:"claim_fee,omitempty"` // flat, withheld from each claim's payout
+	ClaimFeeUnits     string  `protobuf:"bytes,7,opt,name=claim_fee_units,json=claimFeeUnits,proto3" json:"claim_fee_units,omitempty"`
+	ClaimFeeBps       int32   `protobuf:"varint,8,opt,name=claim_fee_bps,json=claimFeeBps,proto3" json:"claim_fee_bps,omitempty"` // of each claim, withheld on top of claim_fee
+}
+
+func (x *PocketFees) Reset() {
+	*x = PocketFees{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PocketFees) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PocketFees) ProtoMessage() {}
+
+func (x *PocketFees) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PocketFees.ProtoReflect.Descriptor instead.
+func (*PocketFees) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PocketFees) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *PocketFees) GetCreationFee() float64 {
+	if x != nil {
+		return x.CreationFee
+	}
+	return 0
+}
+
+func (x *PocketFees) GetCreationFeeUnits() string {
+	if x != nil {
+		return x.CreationFeeUnits
+	}
+	return ""
+}
+
+func (x *PocketFees) GetTotalCharged() float64 {
+	if x != nil {
+		return x.TotalCharged
+	}
+	return 0
+}
+
+func (x *PocketFees) GetTotalChargedUnits() string {
+	if x != nil {
+		return x.TotalChargedUnits
+	}
+	return ""
+}
+
+func (x *PocketFees) GetClaimFee() float64 {
+	if x != nil {
+		return x.ClaimFee
+	}
+	return 0
+}
+
+func (x *PocketFees) GetClaimFeeUnits() string {
+	if x != nil {
+		return x.ClaimFeeUnits
+	}
+	return ""
+}
+
+func (x *PocketFees) GetClaimFeeBps() int32 {
+	if x != nil {
+		return x.ClaimFeeBps
+	}
+	return 0
+}
+
+type GetRedPocketRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetRedPocketRequest) Reset() {
+	*x = GetRedPocketRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRedPocketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRedPocketRequest) ProtoMessage() {}
+
+func (x *GetRedPocketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRedPocketRequest.ProtoReflect.Descriptor instead.
+func (*GetRedPocketRequest) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetRedPocketRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetRedPocketResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RedPocket *RedPocket     `protobuf:"bytes,1,opt,name=red_pocket,json=redPocket,proto3" json:"red_pocket,omitempty"`
+	Terms     *CampaignTerms `protobuf:"bytes,2,opt,name=terms,proto3" json:"terms,omitempty"` // unset when the campaign has no terms
+}
+
+func (x *GetRedPocketResponse) Reset() {
+	*x = GetRedPocketResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRedPocketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRedPocketResponse) ProtoMessage() {}
+
+func (x *GetRedPocketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRedPocketResponse.ProtoReflect.Descriptor instead.
+func (*GetRedPocketResponse) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetRedPocketResponse) GetRedPocket() *RedPocket {
+	if x != nil {
+		return x.RedPocket
+	}
+	return nil
+}
+
+func (x *GetRedPocketResponse) GetTerms() *CampaignTerms {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+type CampaignTerms struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Text    string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *CampaignTerms) Reset() {
+	*x = CampaignTerms{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CampaignTerms) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CampaignTerms) ProtoMessage() {}
+
+func (x *CampaignTerms) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CampaignTerms.ProtoReflect.Descriptor instead.
+func (*CampaignTerms) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CampaignTerms) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *CampaignTerms) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type ClaimRedPocketRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RedPocketId string `protobuf:"bytes,1,opt,name=red_pocket_id,json=redPocketId,proto3" json:"red_pocket_id,omitempty"`
+	PlatformId  string `protobuf:"bytes,2,opt,name=platform_id,json=platformId,proto3" json:"platform_id,omitempty"`
+	Platform    string `protobuf:"bytes,3,opt,name=platform,proto3" json:"platform,omitempty"`
+	// Holder proof for snapshot-gated campaigns and token gates
+	WalletAddress        string `protobuf:"bytes,4,opt,name=wallet_address,json=walletAddress,proto3" json:"wallet_address,omitempty"`
+	Signature            string `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	AcceptedTermsVersion string `protobuf:"bytes,6,opt,name=accepted_terms_version,json=acceptedTermsVersion,proto3" json:"accepted_terms_version,omitempty"`
+	Password             string `protobuf:"bytes,7,opt,name=password,proto3" json:"password,omitempty"`
+	CaptchaToken         string `protobuf:"bytes,8,opt,name=captcha_token,json=captchaToken,proto3" json:"captcha_token,omitempty"`
+	ClientIp             string `protobuf:"bytes,9,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`                             // the claimer's IP, recorded with terms acceptance
+	ClientCountry        string `protobuf:"bytes,10,opt,name=client_country,json=clientCountry,proto3" json:"client_country,omitempty"`             // ISO country of client_ip, for geo rules
+	DeviceFingerprint    string `protobuf:"bytes,11,opt,name=device_fingerprint,json=deviceFingerprint,proto3" json:"device_fingerprint,omitempty"` // claim page device fingerprint, for fraud scoring
+	LinkToken            string `protobuf:"bytes,12,opt,name=link_token,json=linkToken,proto3" json:"link_token,omitempty"`                         // t parameter of the claim link, for pockets with signed links
+	ChannelId            string `protobuf:"bytes,13,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`                         // chat channel the claim was made from, for pockets with channel limits
+}
+
+func (x *ClaimRedPocketRequest) Reset() {
+	*x = ClaimRedPocketRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClaimRedPocketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimRedPocketRequest) ProtoMessage() {}
+
+func (x *ClaimRedPocketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimRedPocketRequest.ProtoReflect.Descriptor instead.
+func (*ClaimRedPocketRequest) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ClaimRedPocketRequest) GetRedPocketId() string {
+	if x != nil {
+		return x.RedPocketId
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetPlatformId() string {
+	if x != nil {
+		return x.PlatformId
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetWalletAddress() string {
+	if x != nil {
+		return x.WalletAddress
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetAcceptedTermsVersion() string {
+	if x != nil {
+		return x.AcceptedTermsVersion
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetCaptchaToken() string {
+	if x != nil {
+		return x.CaptchaToken
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetClientCountry() string {
+	if x != nil {
+		return x.ClientCountry
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetDeviceFingerprint() string {
+	if x != nil {
+		return x.DeviceFingerprint
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetLinkToken() string {
+	if x != nil {
+		return x.LinkToken
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketRequest) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+type ClaimRedPocketResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ClaimId       string  `protobuf:"bytes,1,opt,name=claim_id,json=claimId,proto3" json:"claim_id,omitempty"`
+	ClaimedAmount float64 `protobuf:"fixed64,2,opt,name=claimed_amount,json=claimedAmount,proto3" json:"claimed_amount,omitempty"`
+	Donated       float64 `protobuf:"fixed64,3,opt,name=donated,proto3" json:"donated,omitempty"` // share of claimed_amount given to the campaign's charity
+	WalletAddress string  `protobuf:"bytes,4,opt,name=wallet_address,json=walletAddress,proto3" json:"wallet_address,omitempty"`
+	Status        string  `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`           // pending, credited, held
+	Multiplier    float64 `protobuf:"fixed64,6,opt,name=multiplier,proto3" json:"multiplier,omitempty"` // campaign claim weight claimed_amount was multiplied by; 0 when none
+	Fee           float64 `protobuf:"fixed64,7,opt,name=fee,proto3" json:"fee,omitempty"`               // platform fee withheld from claimed_amount
+}
+
+func (x *ClaimRedPocketResponse) Reset() {
+	*x = ClaimRedPocketResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_redpocket_v1_redpocket_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClaimRedPocketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimRedPocketResponse) ProtoMessage() {}
+
+func (x *ClaimRedPocketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_redpocket_v1_redpocket_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimRedPocketResponse.ProtoReflect.Descriptor instead.
+func (*ClaimRedPocketResponse) Descriptor() ([]byte, []int) {
+	return file_redpocket_v1_redpocket_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ClaimRedPocketResponse) GetClaimId() string {
+	if x != nil {
+		return x.ClaimId
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketResponse) GetClaimedAmount() float64 {
+	if x != nil {
+		return x.ClaimedAmount
+	}
+	return 0
+}
+
+func (x *ClaimRedPocketResponse) GetDonated() float64 {
+	if x != nil {
+		return x.Donated
+	}
+	return 0
+}
+
+func (x *ClaimRedPocketResponse) GetWalletAddress() string {
+	if x != nil {
+		return x.WalletAddress
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ClaimRedPocketResponse) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *ClaimRedPocketResponse) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+var File_redpocket_v1_redpocket_proto protoreflect.FileDescriptor
+
+var file_redpocket_v1_redpocket_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2f, 0x76, 0x31, 0x2f, 0x72,
+	0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
+	0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xce, 0x09,
+	0x0a, 0x09, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x63,
+	0x61, 0x6d, 0x70, 0x61, 0x69, 0x67, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x63, 0x61, 0x6d, 0x70, 0x61, 0x69, 0x67, 0x6e, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b,
+	0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a,
+	0x0d, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x61, 0x76, 0x61, 0x74, 0x61, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x41, 0x76, 0x61, 0x74,
+	0x61, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65,
+	0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x41,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70,
+	0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74,
+	0x61, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x5f, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x65, 0x64, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x73, 0x5f, 0x6c,
+	0x75, 0x63, 0x6b, 0x79, 0x5f, 0x64, 0x72, 0x61, 0x77, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0b, 0x69, 0x73, 0x4c, 0x75, 0x63, 0x6b, 0x79, 0x44, 0x72, 0x61, 0x77, 0x12, 0x1d, 0x0a, 0x0a,
+	0x6d, 0x69, 0x6e, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x09, 0x6d, 0x69, 0x6e, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6d,
+	0x61, 0x78, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x12, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x09, 0x6d, 0x61, 0x78, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x76, 0x65,
+	0x72, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x70, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18,
+	0x17, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x50,
+	0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x74,
+	0x63, 0x68, 0x61, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x19, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x73, 0x41, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x45, 0x0a, 0x10, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x5f, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0f, 0x72, 0x65, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x73,
+	0x65, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x69, 0x64, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x65, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x73, 0x18, 0x1e, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x73, 0x12, 0x21, 0x0a, 0x0c,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x1f, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12,
+	0x27, 0x0a, 0x0f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x75, 0x6e, 0x69,
+	0x74, 0x73, 0x18, 0x20, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e,
+	0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x69, 0x67, 0x6e,
+	0x65, 0x64, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x18, 0x21, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
+	0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x41, 0x0a, 0x0e, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x22, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52,
+	0x0d, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x22, 0xa5,
+	0x07, 0x0a, 0x16, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x61, 0x6d,
+	0x70, 0x61, 0x69, 0x67, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x63, 0x61, 0x6d, 0x70, 0x61, 0x69, 0x67, 0x6e, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65,
+	0x6e, 0x64, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x73,
+	0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x61, 0x76, 0x61, 0x74, 0x61, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x41, 0x76, 0x61, 0x74, 0x61, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x23,
+	0x0a, 0x0d, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12,
+	0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x76, 0x65,
+	0x72, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0d,
+	0x69, 0x73, 0x5f, 0x6c, 0x75, 0x63, 0x6b, 0x79, 0x5f, 0x64, 0x72, 0x61, 0x77, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x4c, 0x75, 0x63, 0x6b, 0x79, 0x44, 0x72, 0x61, 0x77,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x69, 0x6e, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x69, 0x6e, 0x18, 0x10, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x49, 0x6e, 0x12, 0x25, 0x0a,
+	0x0e, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x50, 0x61, 0x73, 0x73,
+	0x77, 0x6f, 0x72, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x5f,
+	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x61, 0x70, 0x74,
+	0x63, 0x68, 0x61, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x73, 0x5f, 0x61, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x73, 0x41, 0x74,
+	0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x14,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x12, 0x45, 0x0a, 0x10, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x75,
+	0x6e, 0x74, 0x69, 0x6c, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0f, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c,
+	0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x12,
+	0x3f, 0x0a, 0x0b, 0x65, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x17,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52,
+	0x75, 0x6c, 0x65, 0x52, 0x0b, 0x65, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x12, 0x21, 0x0a, 0x0c, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73,
+	0x18, 0x18, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4c, 0x69,
+	0x6e, 0x6b, 0x73, 0x12, 0x41, 0x0a, 0x0e, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x19, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x72, 0x65,
+	0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x0d, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x0c, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6c,
+	0x61, 0x69, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x43,
+	0x6c, 0x61, 0x69, 0x6d, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x12, 0x21, 0x0a,
+	0x0c, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x55, 0x6e, 0x69, 0x74, 0x73,
+	0x22, 0xf4, 0x02, 0x0a, 0x0f, 0x45, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x52, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x61, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x69, 0x6e,
+	0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x6d, 0x69, 0x6e, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x2f, 0x0a, 0x14, 0x6d, 0x69,
+	0x6e, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x61,
+	0x79, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11, 0x6d, 0x69, 0x6e, 0x41, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x41, 0x67, 0x65, 0x44, 0x61, 0x79, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x79,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x74,
+	0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74,
+	0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65, 0x22, 0x9e, 0x01, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0a, 0x72, 0x65, 0x64, 0x5f, 0x70, 0x6f, 0x63, 0x6b, 0x65,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63,
+	0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74,
+	0x52, 0x09, 0x72, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x2c, 0x0a, 0x04, 0x66, 0x65,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f,
+	0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x46, 0x65,
+	0x65, 0x73, 0x52, 0x04, 0x66, 0x65, 0x65, 0x73, 0x22, 0xaf, 0x02, 0x0a, 0x0a, 0x50, 0x6f, 0x63,
+	0x6b, 0x65, 0x74, 0x46, 0x65, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x66, 0x65, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x46, 0x65, 0x65, 0x12, 0x2c,
+	0x0a, 0x12, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x75,
+	0x6e, 0x69, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x46, 0x65, 0x65, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x23, 0x0a, 0x0d,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65,
+	0x64, 0x12, 0x2e, 0x0a, 0x13, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x68, 0x61, 0x72, 0x67,
+	0x65, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x64, 0x55, 0x6e, 0x69, 0x74,
+	0x73, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x5f, 0x66, 0x65, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x46, 0x65, 0x65, 0x12, 0x26,
+	0x0a, 0x0f, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x74,
+	0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x46, 0x65,
+	0x65, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x5f,
+	0x66, 0x65, 0x65, 0x5f, 0x62, 0x70, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x46, 0x65, 0x65, 0x42, 0x70, 0x73, 0x22, 0x25, 0x0a, 0x13, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x22, 0x81, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0a, 0x72, 0x65,
+	0x64, 0x5f, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x09, 0x72, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x12, 0x31, 0x0a, 0x05, 0x74, 0x65, 0x72, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x6d, 0x70, 0x61, 0x69, 0x67, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x73, 0x52, 0x05,
+	0x74, 0x65, 0x72, 0x6d, 0x73, 0x22, 0x3d, 0x0a, 0x0d, 0x43, 0x61, 0x6d, 0x70, 0x61, 0x69, 0x67,
+	0x6e, 0x54, 0x65, 0x72, 0x6d, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x65, 0x78, 0x74, 0x22, 0xe5, 0x03, 0x0a, 0x15, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65,
+	0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22,
+	0x0a, 0x0d, 0x72, 0x65, 0x64, 0x5f, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74,
+	0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
+	0x6d, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12,
+	0x25, 0x0a, 0x0e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x34, 0x0a, 0x16, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64,
+	0x5f, 0x74, 0x65, 0x72, 0x6d, 0x73, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x54, 0x65,
+	0x72, 0x6d, 0x73, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68,
+	0x61, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63,
+	0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x70, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x2d, 0x0a, 0x12, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72,
+	0x70, 0x72, 0x69, 0x6e, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x6c, 0x69, 0x6e, 0x6b, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x22, 0xe5, 0x01, 0x0a,
+	0x16, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x6c, 0x61, 0x69, 0x6d,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6c, 0x61, 0x69, 0x6d,
+	0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x5f, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x65, 0x64, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x6f, 0x6e,
+	0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x64, 0x6f, 0x6e, 0x61,
+	0x74, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x77, 0x61, 0x6c,
+	0x6c, 0x65, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69,
+	0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x66, 0x65, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x03, 0x66, 0x65, 0x65, 0x32, 0xa6, 0x02, 0x0a, 0x10, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5e, 0x0a, 0x0f, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x24, 0x2e, 0x72,
+	0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x25, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x55, 0x0a, 0x0c, 0x47, 0x65, 0x74,
+	0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x21, 0x2e, 0x72, 0x65, 0x64, 0x70,
+	0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x64, 0x50,
+	0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x72,
+	0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x5b, 0x0a, 0x0e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x12, 0x23, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x64, 0x50, 0x6f, 0x63, 0x6b, 0x65, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63,
+	0x6b, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x64, 0x50,
+	0x6f, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x4a, 0x5a,
+	0x48, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x62, 0x61, 0x6e, 0x6b, 0x2f, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x2d, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x72, 0x65, 0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x2f, 0x76, 0x31, 0x3b, 0x72, 0x65,
+	0x64, 0x70, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_redpocket_v1_redpocket_proto_rawDescOnce sync.Once
+	file_redpocket_v1_redpocket_proto_rawDescData = file_redpocket_v1_redpocket_proto_rawDesc
+)
+
+func file_redpocket_v1_redpocket_proto_rawDescGZIP() []byte {
+	file_redpocket_v1_redpocket_proto_rawDescOnce.Do(func() {
+		file_redpocket_v1_redpocket_proto_rawDescData = protoimpl.X.CompressGZIP(file_redpocket_v1_redpocket_proto_rawDescData)
+	})
+	return file_redpocket_v1_redpocket_proto_rawDescData
+}
+
+var file_redpocket_v1_redpocket_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_redpocket_v1_redpocket_proto_goTypes = []interface{}{
+	(*RedPocket)(nil),               // 0: redpocket.v1.RedPocket
+	(*CreateRedPocketRequest)(nil),  // 1: redpocket.v1.CreateRedPocketRequest
+	(*ChannelLimit)(nil),            // 2: redpocket.v1.ChannelLimit
+	(*EligibilityRule)(nil),         // 3: redpocket.v1.EligibilityRule
+	(*CreateRedPocketResponse)(nil), // 4: redpocket.v1.CreateRedPocketResponse
+	(*PocketFees)(nil),              // 5: redpocket.v1.PocketFees
+	(*GetRedPocketRequest)(nil),     // 6: redpocket.v1.GetRedPocketRequest
+	(*GetRedPocketResponse)(nil),    // 7: redpocket.v1.GetRedPocketResponse
+	(*CampaignTerms)(nil),           // 8: redpocket.v1.CampaignTerms
+	(*ClaimRedPocketRequest)(nil),   // 9: redpocket.v1.ClaimRedPocketRequest
+	(*ClaimRedPocketResponse)(nil),  // 10: redpocket.v1.ClaimRedPocketResponse
+	(*timestamppb.Timestamp)(nil),   // 11: google.protobuf.Timestamp
+}
+var file_redpocket_v1_redpocket_proto_depIdxs = []int32{
+	11, // 0: redpocket.v1.RedPocket.expires_at:type_name -> google.protobuf.Timestamp
+	11, // 1: redpocket.v1.RedPocket.created_at:type_name -> google.protobuf.Timestamp
+	11, // 2: redpocket.v1.RedPocket.starts_at:type_name -> google.protobuf.Timestamp
+	11, // 3: redpocket.v1.RedPocket.recurrence_until:type_name -> google.protobuf.Timestamp
+	2,  // 4: redpocket.v1.RedPocket.channel_limits:type_name -> redpocket.v1.ChannelLimit
+	11, // 5: redpocket.v1.CreateRedPocketRequest.starts_at:type_name -> google.protobuf.Timestamp
+	11, // 6: redpocket.v1.CreateRedPocketRequest.recurrence_until:type_name -> google.protobuf.Timestamp
+	3,  // 7: redpocket.v1.CreateRedPocketRequest.eligibility:type_name -> redpocket.v1.EligibilityRule
+	2,  // 8: redpocket.v1.CreateRedPocketRequest.channel_limits:type_name -> redpocket.v1.ChannelLimit
+	0,  // 9: redpocket.v1.CreateRedPocketResponse.red_pocket:type_name -> redpocket.v1.RedPocket
+	5,  // 10: redpocket.v1.CreateRedPocketResponse.fees:type_name -> redpocket.v1.PocketFees
+	0,  // 11: redpocket.v1.GetRedPocketResponse.red_pocket:type_name -> redpocket.v1.RedPocket
+	8,  // 12: redpocket.v1.GetRedPocketResponse.terms:type_name -> redpocket.v1.CampaignTerms
+	1,  // 13: redpocket.v1.RedPocketService.CreateRedPocket:input_type -> redpocket.v1.CreateRedPocketRequest
+	6,  // 14: redpocket.v1.RedPocketService.GetRedPocket:input_type -> redpocket.v1.GetRedPocketRequest
+	9,  // 15: redpocket.v1.RedPocketService.ClaimRedPocket:input_type -> redpocket.v1.ClaimRedPocketRequest
+	4,  // 16: redpocket.v1.RedPocketService.CreateRedPocket:output_type -> redpocket.v1.CreateRedPocketResponse
+	7,  // 17: redpocket.v1.RedPocketService.GetRedPocket:output_type -> redpocket.v1.GetRedPocketResponse
+	10, // 18: redpocket.v1.RedPocketService.ClaimRedPocket:output_type -> redpocket.v1.ClaimRedPocketResponse
+	16, // [16:19] is the sub-list for method output_type
+	13, // [13:16] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_redpocket_v1_redpocket_proto_init() }
+func file_redpocket_v1_redpocket_proto_init() {
+	if File_redpocket_v1_redpocket_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_redpocket_v1_redpocket_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RedPocket); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateRedPocketRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EligibilityRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateRedPocketResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PocketFees); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRedPocketRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRedPocketResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CampaignTerms); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClaimRedPocketRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_redpocket_v1_redpocket_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClaimRedPocketResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_redpocket_v1_redpocket_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_redpocket_v1_redpocket_proto_goTypes,
+		DependencyIndexes: file_redpocket_v1_redpocket_proto_depIdxs,
+		MessageInfos:      file_redpocket_v1_redpocket_proto_msgTypes,
+	}.Build()
+	File_redpocket_v1_redpocket_proto = out.File
+	file_redpocket_v1_redpocket_proto_rawDesc = nil
+	file_redpocket_v1_redpocket_proto_goTypes = nil
+	file_redpocket_v1_redpocket_proto_depIdxs = nil
+}
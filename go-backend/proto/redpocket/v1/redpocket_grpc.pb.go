@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.3
+// source: redpocket/v1/redpocket.proto
+
+package redpocketv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RedPocketService_CreateRedPocket_FullMethodName = "/redpocket.v1.RedPocketService/CreateRedPocket"
+	RedPocketService_GetRedPocket_FullMethodName    = "/redpocket.v1.RedPocketService/GetRedPocket"
+	RedPocketService_ClaimRedPocket_FullMethodName  = "/redpocket.v1.RedPocketService/ClaimRedPocket"
+)
+
+// RedPocketServiceClient is the client API for RedPocketService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RedPocketService creates and claims red pockets, as POST /api/v1/redpocket/create
+// and /claim do
+type RedPocketServiceClient interface {
+	CreateRedPocket(ctx context.Context, in *CreateRedPocketRequest, opts ...grpc.CallOption) (*CreateRedPocketResponse, error)
+	GetRedPocket(ctx context.Context, in *GetRedPocketRequest, opts ...grpc.CallOption) (*GetRedPocketResponse, error)
+	// ClaimRedPocket fails with FAILED_PRECONDITION and an ErrorInfo whose reason
+	// is the REST error code (already_claimed, red_pocket_expired, ...) when the
+	// claim is refused
+	ClaimRedPocket(ctx context.Context, in *ClaimRedPocketRequest, opts ...grpc.CallOption) (*ClaimRedPocketResponse, error)
+}
+
+type redPocketServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRedPocketServiceClient(cc grpc.ClientConnInterface) RedPocketServiceClient {
+	return &redPocketServiceClient{cc}
+}
+
+func (c *redPocketServiceClient) CreateRedPocket(ctx context.Context, in *CreateRedPocketRequest, opts ...grpc.CallOption) (*CreateRedPocketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateRedPocketResponse)
+	err := c.cc.Invoke(ctx, RedPocketService_CreateRedPocket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *redPocketServiceClient) GetRedPocket(ctx context.Context, in *GetRedPocketRequest, opts ...grpc.CallOption) (*GetRedPocketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRedPocketResponse)
+	err := c.cc.Invoke(ctx, RedPocketService_GetRedPocket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *redPocketServiceClient) ClaimRedPocket(ctx context.Context, in *ClaimRedPocketRequest, opts ...grpc.CallOption) (*ClaimRedPocketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClaimRedPocketResponse)
+	err := c.cc.Invoke(ctx, RedPocketService_ClaimRedPocket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RedPocketServiceServer is the server API for RedPocketService service.
+// All implementations must embed UnimplementedRedPocketServiceServer
+// for forward compatibility.
+//
+// RedPocketService creates and claims red pockets, as POST /api/v1/redpocket/create
+// and /claim do
+type RedPocketServiceServer interface {
+	CreateRedPocket(context.Context, *CreateRedPocketRequest) (*CreateRedPocketResponse, error)
+	GetRedPocket(context.Context, *GetRedPocketRequest) (*GetRedPocketResponse, error)
+	// ClaimRedPocket fails with FAILED_PRECONDITION and an ErrorInfo whose reason
+	// is the REST error code (already_claimed, red_pocket_expired, ...) when the
+	// claim is refused
+	ClaimRedPocket(context.Context, *ClaimRedPocketRequest) (*ClaimRedPocketResponse, error)
+	mustEmbedUnimplementedRedPocketServiceServer()
+}
+
+// UnimplementedRedPocketServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRedPocketServiceServer struct{}
+
+func (UnimplementedRedPocketServiceServer) CreateRedPocket(context.Context, *CreateRedPocketRequest) (*CreateRedPocketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRedPocket not implemented")
+}
+func (UnimplementedRedPocketServiceServer) GetRedPocket(context.Context, *GetRedPocketRequest) (*GetRedPocketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRedPocket not implemented")
+}
+func (UnimplementedRedPocketServiceServer) ClaimRedPocket(context.Context, *ClaimRedPocketRequest) (*ClaimRedPocketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimRedPocket not implemented")
+}
+func (UnimplementedRedPocketServiceServer) mustEmbedUnimplementedRedPocketServiceServer() {}
+func (UnimplementedRedPocketServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeRedPocketServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RedPocketServiceServer will
+// result in compilation errors.
+type UnsafeRedPocketServiceServer interface {
+	mustEmbedUnimplementedRedPocketServiceServer()
+}
+
+func RegisterRedPocketServiceServer(s grpc.ServiceRegistrar, srv RedPocketServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRedPocketServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RedPocketService_ServiceDesc, srv)
+}
+
+func _RedPocketService_CreateRedPocket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRedPocketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedPocketServiceServer).CreateRedPocket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RedPocketService_CreateRedPocket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedPocketServiceServer).CreateRedPocket(ctx, req.(*CreateRedPocketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RedPocketService_GetRedPocket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRedPocketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedPocketServiceServer).GetRedPocket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RedPocketService_GetRedPocket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedPocketServiceServer).GetRedPocket(ctx, req.(*GetRedPocketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RedPocketService_ClaimRedPocket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimRedPocketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedPocketServiceServer).ClaimRedPocket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RedPocketService_ClaimRedPocket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedPocketServiceServer).ClaimRedPocket(ctx, req.(*ClaimRedPocketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RedPocketService_ServiceDesc is the grpc.ServiceDesc for RedPocketService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RedPocketService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "redpocket.v1.RedPocketService",
+	HandlerType: (*RedPocketServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateRedPocket",
+			Handler:    _RedPocketService_CreateRedPocket_Handler,
+		},
+		{
+			MethodName: "GetRedPocket",
+			Handler:    _RedPocketService_GetRedPocket_Handler,
+		},
+		{
+			MethodName: "ClaimRedPocket",
+			Handler:    _RedPocketService_ClaimRedPocket_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "redpocket/v1/redpocket.proto",
+}